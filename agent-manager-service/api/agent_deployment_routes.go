@@ -0,0 +1,37 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package api
+
+import (
+	"net/http"
+
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/controllers"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/middleware"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/utils"
+)
+
+// Environments of managed agents live under agent-environments, /environments lists the OpenChoreo environments
+func registerAgentDeploymentRoutes(mux *http.ServeMux, ctrl controllers.AgentDeploymentController, authz *middleware.Authorizer) {
+	authz.HandleFunc(mux, "POST /orgs/{orgName}/agent-environments", utils.PermissionEnvironmentsManage, ctrl.CreateAgentEnvironment)
+	authz.HandleFunc(mux, "GET /orgs/{orgName}/agent-environments", utils.PermissionAgentsRead, ctrl.ListAgentEnvironments)
+	authz.HandleFunc(mux, "GET /orgs/{orgName}/agent-environments/{envName}", utils.PermissionAgentsRead, ctrl.GetAgentEnvironment)
+	authz.HandleFunc(mux, "GET /orgs/{orgName}/agent-environments/{envName}/agents", utils.PermissionAgentsRead, ctrl.ListEnvironmentAgents)
+	authz.HandleFunc(mux, "GET /orgs/{orgName}/agent-environments/{envName}/agents/{agentId}", utils.PermissionAgentsRead, ctrl.GetResolvedAgentConfig)
+	authz.HandleFunc(mux, "POST /orgs/{orgName}/agents/{agentId}/deployments", utils.PermissionAgentsWrite, ctrl.DeployManagedAgent)
+	authz.HandleFunc(mux, "GET /orgs/{orgName}/agents/{agentId}/deployments", utils.PermissionAgentsRead, ctrl.ListAgentDeployments)
+	authz.HandleFunc(mux, "POST /orgs/{orgName}/agents/{agentId}/promotions", utils.PermissionAgentsWrite, ctrl.PromoteManagedAgent)
+}
@@ -21,23 +21,24 @@ import (
 
 	"github.com/wso2/ai-agent-management-platform/agent-manager-service/controllers"
 	"github.com/wso2/ai-agent-management-platform/agent-manager-service/middleware"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/utils"
 )
 
-func registerAgentRoutes(mux *http.ServeMux, ctrl controllers.AgentController) {
-	// All routes now use HandleFuncWithValidation which automatically
-	// extracts path parameters from the pattern and validates them
+func registerAgentRoutes(mux *http.ServeMux, ctrl controllers.AgentController, authz *middleware.Authorizer) {
+	// All routes are registered through the authorizer, which requires the given permission
+	// and validates the path parameters extracted from the pattern
 
-	middleware.HandleFuncWithValidation(mux, "POST /orgs/{orgName}/projects/{projName}/agents", ctrl.CreateAgent)
-	middleware.HandleFuncWithValidation(mux, "GET /orgs/{orgName}/projects/{projName}/agents", ctrl.ListAgents)
-	middleware.HandleFuncWithValidation(mux, "POST /orgs/{orgName}/utils/generate-name", ctrl.GenerateName)
-	middleware.HandleFuncWithValidation(mux, "GET /orgs/{orgName}/projects/{projName}/agents/{agentName}", ctrl.GetAgent)
-	middleware.HandleFuncWithValidation(mux, "DELETE /orgs/{orgName}/projects/{projName}/agents/{agentName}", ctrl.DeleteAgent)
-	middleware.HandleFuncWithValidation(mux, "POST /orgs/{orgName}/projects/{projName}/agents/{agentName}/builds", ctrl.BuildAgent)
-	middleware.HandleFuncWithValidation(mux, "GET /orgs/{orgName}/projects/{projName}/agents/{agentName}/builds", ctrl.ListAgentBuilds)
-	middleware.HandleFuncWithValidation(mux, "GET /orgs/{orgName}/projects/{projName}/agents/{agentName}/builds/{buildName}", ctrl.GetBuild)
-	middleware.HandleFuncWithValidation(mux, "GET /orgs/{orgName}/projects/{projName}/agents/{agentName}/builds/{buildName}/build-logs", ctrl.GetBuildLogs)
-	middleware.HandleFuncWithValidation(mux, "POST /orgs/{orgName}/projects/{projName}/agents/{agentName}/deployments", ctrl.DeployAgent)
-	middleware.HandleFuncWithValidation(mux, "GET /orgs/{orgName}/projects/{projName}/agents/{agentName}/deployments", ctrl.GetAgentDeployments)
-	middleware.HandleFuncWithValidation(mux, "GET /orgs/{orgName}/projects/{projName}/agents/{agentName}/endpoints", ctrl.GetAgentEndpoints)
-	middleware.HandleFuncWithValidation(mux, "GET /orgs/{orgName}/projects/{projName}/agents/{agentName}/configurations", ctrl.GetAgentConfigurations)
+	authz.HandleFunc(mux, "POST /orgs/{orgName}/projects/{projName}/agents", utils.PermissionAgentsWrite, ctrl.CreateAgent)
+	authz.HandleFunc(mux, "GET /orgs/{orgName}/projects/{projName}/agents", utils.PermissionAgentsRead, ctrl.ListAgents)
+	authz.HandleFunc(mux, "POST /orgs/{orgName}/utils/generate-name", utils.PermissionAgentsRead, ctrl.GenerateName)
+	authz.HandleFunc(mux, "GET /orgs/{orgName}/projects/{projName}/agents/{agentName}", utils.PermissionAgentsRead, ctrl.GetAgent)
+	authz.HandleFunc(mux, "DELETE /orgs/{orgName}/projects/{projName}/agents/{agentName}", utils.PermissionAgentsWrite, ctrl.DeleteAgent)
+	authz.HandleFunc(mux, "POST /orgs/{orgName}/projects/{projName}/agents/{agentName}/builds", utils.PermissionAgentsWrite, ctrl.BuildAgent)
+	authz.HandleFunc(mux, "GET /orgs/{orgName}/projects/{projName}/agents/{agentName}/builds", utils.PermissionAgentsRead, ctrl.ListAgentBuilds)
+	authz.HandleFunc(mux, "GET /orgs/{orgName}/projects/{projName}/agents/{agentName}/builds/{buildName}", utils.PermissionAgentsRead, ctrl.GetBuild)
+	authz.HandleFunc(mux, "GET /orgs/{orgName}/projects/{projName}/agents/{agentName}/builds/{buildName}/build-logs", utils.PermissionAgentsRead, ctrl.GetBuildLogs)
+	authz.HandleFunc(mux, "POST /orgs/{orgName}/projects/{projName}/agents/{agentName}/deployments", utils.PermissionAgentsWrite, ctrl.DeployAgent)
+	authz.HandleFunc(mux, "GET /orgs/{orgName}/projects/{projName}/agents/{agentName}/deployments", utils.PermissionAgentsRead, ctrl.GetAgentDeployments)
+	authz.HandleFunc(mux, "GET /orgs/{orgName}/projects/{projName}/agents/{agentName}/endpoints", utils.PermissionAgentsRead, ctrl.GetAgentEndpoints)
+	authz.HandleFunc(mux, "GET /orgs/{orgName}/projects/{projName}/agents/{agentName}/configurations", utils.PermissionAgentsRead, ctrl.GetAgentConfigurations)
 }
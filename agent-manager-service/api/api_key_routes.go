@@ -0,0 +1,31 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package api
+
+import (
+	"net/http"
+
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/controllers"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/middleware"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/utils"
+)
+
+func registerAPIKeyRoutes(mux *http.ServeMux, ctrl controllers.APIKeyController, authz *middleware.Authorizer) {
+	authz.HandleFunc(mux, "POST /orgs/{orgName}/api-keys", utils.PermissionKeysManage, ctrl.CreateAPIKey)
+	authz.HandleFunc(mux, "GET /orgs/{orgName}/api-keys", utils.PermissionKeysManage, ctrl.ListAPIKeys)
+	authz.HandleFunc(mux, "DELETE /orgs/{orgName}/api-keys/{apiKeyId}", utils.PermissionKeysManage, ctrl.RevokeAPIKey)
+}
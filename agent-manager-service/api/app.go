@@ -18,11 +18,15 @@ package api
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/wso2/ai-agent-management-platform/agent-manager-service/config"
 	"github.com/wso2/ai-agent-management-platform/agent-manager-service/middleware"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/middleware/correlation"
 	"github.com/wso2/ai-agent-management-platform/agent-manager-service/middleware/logger"
 	"github.com/wso2/ai-agent-management-platform/agent-manager-service/wiring"
+	"github.com/wso2/ai-agent-management-platform/libs/go-common/compress"
+	"github.com/wso2/ai-agent-management-platform/libs/go-common/tracing"
 )
 
 // MakeHTTPHandler creates a new HTTP handler with middleware and routes
@@ -31,32 +35,110 @@ func MakeHTTPHandler(params *wiring.AppParams) http.Handler {
 
 	// Register health check
 	registerHealthCheck(mux)
+	registerMetrics(mux, params.RateLimiter)
 
 	// Create a sub-mux for API v1 routes
 	apiMux := http.NewServeMux()
-	registerAgentRoutes(apiMux, params.AgentController)
-	registerInfraRoutes(apiMux, params.InfraResourceController)
-	registerObservabilityRoutes(apiMux, params.ObservabilityController)
+	registerAgentRoutes(apiMux, params.AgentController, params.Authorizer)
+	registerManagedAgentRoutes(apiMux, params.ManagedAgentController, params.Authorizer)
+	registerPromptTemplateRoutes(apiMux, params.PromptTemplateController, params.Authorizer)
+	registerToolRoutes(apiMux, params.ToolController, params.Authorizer)
+	registerAPIKeyRoutes(apiMux, params.APIKeyController, params.Authorizer)
+	registerAgentDeploymentRoutes(apiMux, params.AgentDeploymentController, params.Authorizer)
+	registerCredentialRoutes(apiMux, params.CredentialController, params.Authorizer)
+	registerWebhookRoutes(apiMux, params.WebhookController, params.Authorizer)
+	registerAgentBudgetRoutes(apiMux, params.AgentBudgetController, params.Authorizer)
+	registerEvaluationRoutes(apiMux, params.EvaluationController, params.Authorizer)
+	registerTraceViewRoutes(apiMux, params.TraceViewController, params.Authorizer)
+	registerInfraRoutes(apiMux, params.InfraResourceController, params.Authorizer)
+	registerObservabilityRoutes(apiMux, params.ObservabilityController, params.Authorizer)
+	registerAuditLogRoutes(apiMux, params.AuditLogController, params.Authorizer)
+	defaultLimits, routeLimits := requestLimits(config.GetConfig())
 
-	// Apply middleware in reverse order (last middleware is applied first)
+	spec, err := NewSpec(params.Authorizer)
+	if err != nil {
+		// Operations are declared in code, like conflicting mux patterns this is a programming error
+		panic(err)
+	}
+	mux.Handle("GET /openapi.json", spec)
+
+	// Apply middleware in reverse order (last middleware is applied first). From the outermost:
+	//   - AddCorrelationID binds the correlation id every later middleware logs with
+	//   - Tracing starts the server span before anything can reject the request, so that CORS, auth,
+	//     limit and rate limit rejections are traced too, and adds its trace id for the request log.
+	//     The span is named after the route template of apiMux, never the path holding org and agent names
+	//   - RequestLogger logs every request with its trace id, including rejected and recovered ones
+	//   - Compression, panic recovery, request limits and CORS run before authentication, so that
+	//     preflight requests are answered without credentials
+	//   - Authentication follows, then rate limiting, organization scoping, idempotency and auditing,
+	//     which need the principal; authorization is checked by each route
+	//   - Request bodies are validated against the document served at /openapi.json right before the route decodes them
 	apiHandler := http.Handler(apiMux)
-	apiHandler = params.AuthMiddleware(apiHandler)
-	apiHandler = middleware.AddCorrelationID()(apiHandler)
-	apiHandler = logger.RequestLogger()(apiHandler)
-	apiHandler = middleware.CORS(config.GetConfig().CORSAllowedOrigin)(apiHandler)
+	apiHandler = middleware.ValidateRequestBodies(apiMux, spec)(apiHandler)
+	// Replayed responses of idempotent retries are not audited again
+	apiHandler = middleware.Audit(apiMux, params.Authorizer, params.AuditService)(apiHandler)
+	apiHandler = middleware.Idempotency(apiMux, params.IdempotencyService, secretResponseRoutes...)(apiHandler)
+	apiHandler = middleware.OrgScope(params.InfraResourceManager)(apiHandler)
+	apiHandler = middleware.RateLimit(params.RateLimiter)(apiHandler)
+	apiHandler = middleware.LogPrincipal()(apiHandler)
+	apiHandler = middleware.APIKeyAuth(params.APIKeyService, config.GetConfig().AuthHeader, params.AuthMiddleware)(apiHandler)
+	apiHandler = middleware.CORS(corsOptions(config.GetConfig().CORS))(apiHandler)
+	apiHandler = middleware.LimitRequests(apiMux, defaultLimits, routeLimits)(apiHandler)
 	apiHandler = middleware.RecovererOnPanic()(apiHandler)
+	if cfg := config.GetConfig().Compression; cfg.Enabled {
+		apiHandler = compress.Responses(compress.Config{MinSize: cfg.MinBytes})(apiHandler)
+	}
+	// The request logger needs the correlation id and logs the response of recovered panics
+	apiHandler = logger.RequestLogger()(apiHandler)
+	apiHandler = tracing.Middleware(apiMux, tracing.MiddlewareConfig{Prefix: "/api/v1", WithTraceID: correlation.WithTraceID})(apiHandler)
+	apiHandler = middleware.AddCorrelationID()(apiHandler)
 
 	// Create a mux for internal API routes
 	internalApiMux := http.NewServeMux()
-	registerInternalRoutes(internalApiMux, params.BuildCIController)
+	registerInternalRoutes(internalApiMux, params.BuildCIController, params.ManagedAgentController, params.CredentialController)
+	registerLogLevelRoutes(internalApiMux)
 	internalApiHandler := http.Handler(internalApiMux)
 	internalApiHandler = middleware.APIKeyMiddleware()(internalApiHandler) // Add API key middleware for internal routes
-	internalApiHandler = middleware.AddCorrelationID()(internalApiHandler)
-	internalApiHandler = logger.RequestLogger()(internalApiHandler)
+	internalApiHandler = middleware.LimitRequests(internalApiMux, defaultLimits, nil)(internalApiHandler)
 	internalApiHandler = middleware.RecovererOnPanic()(internalApiHandler)
+	internalApiHandler = logger.RequestLogger()(internalApiHandler)
+	internalApiHandler = tracing.Middleware(internalApiMux, tracing.MiddlewareConfig{Prefix: "/internal", WithTraceID: correlation.WithTraceID})(internalApiHandler)
+	internalApiHandler = middleware.AddCorrelationID()(internalApiHandler)
 
 	mux.Handle("/api/v1/", http.StripPrefix("/api/v1", apiHandler))
 	mux.Handle("/internal/", http.StripPrefix("/internal", internalApiHandler))
 
 	return mux
 }
+
+// secretResponseRoutes answer with secrets that are only ever stored hashed, their responses are not recorded to
+// replay idempotent retries
+var secretResponseRoutes = []string{"POST /orgs/{orgName}/api-keys"}
+
+// corsOptions returns the CORS options of the API, settings left empty keep the built in defaults
+func corsOptions(cfg config.APICORSConfig) middleware.CORSOptions {
+	return middleware.CORSOptions{
+		AllowedOrigins:   cfg.AllowedOrigins,
+		AllowedMethods:   cfg.AllowedMethods,
+		AllowedHeaders:   cfg.AllowedHeaders,
+		ExposedHeaders:   cfg.ExposedHeaders,
+		MaxAge:           cfg.MaxAgeSeconds,
+		AllowCredentials: cfg.AllowCredentials,
+	}
+}
+
+// requestLimits returns the default request limits and those configured for individual API routes
+func requestLimits(cfg *config.Config) (middleware.RequestLimits, map[string]middleware.RequestLimits) {
+	defaults := middleware.RequestLimits{
+		MaxBodyBytes: cfg.MaxRequestBodyBytes,
+		Timeout:      time.Duration(cfg.RequestTimeoutSeconds) * time.Second,
+	}
+	routes := make(map[string]middleware.RequestLimits, len(cfg.RouteLimits))
+	for route, limits := range cfg.RouteLimits {
+		routes[route] = middleware.RequestLimits{
+			MaxBodyBytes: limits.MaxBodyBytes,
+			Timeout:      time.Duration(limits.TimeoutSeconds) * time.Second,
+		}
+	}
+	return defaults, routes
+}
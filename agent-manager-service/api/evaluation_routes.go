@@ -0,0 +1,32 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package api
+
+import (
+	"net/http"
+
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/controllers"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/middleware"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/utils"
+)
+
+func registerEvaluationRoutes(mux *http.ServeMux, ctrl controllers.EvaluationController, authz *middleware.Authorizer) {
+	authz.HandleFunc(mux, "POST /orgs/{orgName}/evaluations", utils.PermissionEvaluationsWrite, ctrl.CreateEvaluation)
+	authz.HandleFunc(mux, "GET /orgs/{orgName}/evaluations", utils.PermissionEvaluationsRead, ctrl.ListEvaluations)
+	authz.HandleFunc(mux, "GET /orgs/{orgName}/evaluations/{evaluationId}", utils.PermissionEvaluationsRead, ctrl.GetEvaluation)
+	authz.HandleFunc(mux, "GET /orgs/{orgName}/evaluations/{evaluationId}/results", utils.PermissionEvaluationsRead, ctrl.ListEvaluationResults)
+}
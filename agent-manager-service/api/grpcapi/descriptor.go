@@ -0,0 +1,153 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package grpcapi
+
+import (
+	"fmt"
+
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// The descriptor of agentmanager.proto is built here rather than generated, it must be kept in step with the file
+
+const (
+	protoFile   = "agentmanager/v1/agentmanager.proto"
+	protoPkg    = "agentmanager.v1"
+	ServiceName = protoPkg + ".AgentManagerService"
+)
+
+// Fully qualified names of the messages of the service
+const (
+	listRequestName   = protoPkg + ".ListRequest"
+	getRequestName    = protoPkg + ".GetRequest"
+	createRequestName = protoPkg + ".CreateRequest"
+	updateRequestName = protoPkg + ".UpdateRequest"
+	deleteRequestName = protoPkg + ".DeleteRequest"
+	resourceName      = protoPkg + ".Resource"
+	emptyName         = "google.protobuf.Empty"
+	structName        = "google.protobuf.Struct"
+	fieldMaskName     = "google.protobuf.FieldMask"
+)
+
+// buildFiles registers the descriptor of the service with the well-known types it imports
+func buildFiles() (*protoregistry.Files, protoreflect.ServiceDescriptor, error) {
+	files := new(protoregistry.Files)
+	for _, dependency := range []protoreflect.FileDescriptor{
+		emptypb.File_google_protobuf_empty_proto,
+		fieldmaskpb.File_google_protobuf_field_mask_proto,
+		structpb.File_google_protobuf_struct_proto,
+	} {
+		if err := files.RegisterFile(dependency); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	file, err := protodesc.NewFile(fileDescriptorProto(), files)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid descriptor of %s: %w", protoFile, err)
+	}
+	if err := files.RegisterFile(file); err != nil {
+		return nil, nil, err
+	}
+	return files, file.Services().ByName("AgentManagerService"), nil
+}
+
+func fileDescriptorProto() *descriptorpb.FileDescriptorProto {
+	return &descriptorpb.FileDescriptorProto{
+		Name:    ptr(protoFile),
+		Package: ptr(protoPkg),
+		Syntax:  ptr("proto3"),
+		Dependency: []string{
+			"google/protobuf/empty.proto",
+			"google/protobuf/field_mask.proto",
+			"google/protobuf/struct.proto",
+		},
+		MessageType: []*descriptorpb.DescriptorProto{
+			message("ListRequest",
+				scalar("org_name", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING),
+				scalar("parent_id", 2, descriptorpb.FieldDescriptorProto_TYPE_STRING),
+				scalar("page_size", 3, descriptorpb.FieldDescriptorProto_TYPE_INT32),
+				messageField("params", 4, structName)),
+			message("GetRequest",
+				scalar("org_name", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING),
+				scalar("id", 2, descriptorpb.FieldDescriptorProto_TYPE_STRING)),
+			message("CreateRequest",
+				scalar("org_name", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING),
+				scalar("parent_id", 2, descriptorpb.FieldDescriptorProto_TYPE_STRING),
+				messageField("resource", 3, structName)),
+			message("UpdateRequest",
+				scalar("org_name", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING),
+				scalar("id", 2, descriptorpb.FieldDescriptorProto_TYPE_STRING),
+				messageField("resource", 3, structName),
+				messageField("update_mask", 4, fieldMaskName),
+				scalar("etag", 5, descriptorpb.FieldDescriptorProto_TYPE_STRING)),
+			message("DeleteRequest",
+				scalar("org_name", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING),
+				scalar("id", 2, descriptorpb.FieldDescriptorProto_TYPE_STRING)),
+			message("Resource",
+				messageField("resource", 1, structName),
+				scalar("etag", 2, descriptorpb.FieldDescriptorProto_TYPE_STRING)),
+		},
+		Service: []*descriptorpb.ServiceDescriptorProto{{
+			Name:   ptr("AgentManagerService"),
+			Method: methodDescriptorProtos(),
+		}},
+	}
+}
+
+// methodDescriptorProtos describes the methods of the operations table
+func methodDescriptorProtos() []*descriptorpb.MethodDescriptorProto {
+	methods := make([]*descriptorpb.MethodDescriptorProto, 0, len(operations))
+	for _, op := range operations {
+		methods = append(methods, &descriptorpb.MethodDescriptorProto{
+			Name:            ptr(op.method),
+			InputType:       ptr("." + op.kind.request()),
+			OutputType:      ptr("." + op.kind.response()),
+			ServerStreaming: ptr(op.kind == kindList),
+		})
+	}
+	return methods
+}
+
+func message(name string, fields ...*descriptorpb.FieldDescriptorProto) *descriptorpb.DescriptorProto {
+	return &descriptorpb.DescriptorProto{Name: ptr(name), Field: fields}
+}
+
+func scalar(name string, number int32, typ descriptorpb.FieldDescriptorProto_Type) *descriptorpb.FieldDescriptorProto {
+	return &descriptorpb.FieldDescriptorProto{
+		Name:   ptr(name),
+		Number: ptr(number),
+		Label:  descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+		Type:   typ.Enum(),
+	}
+}
+
+func messageField(name string, number int32, typeName string) *descriptorpb.FieldDescriptorProto {
+	field := scalar(name, number, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE)
+	field.TypeName = ptr("." + typeName)
+	return field
+}
+
+func ptr[T any](value T) *T {
+	return &value
+}
@@ -0,0 +1,205 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package grpcapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/middleware/correlation"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/utils"
+)
+
+// response is a response of the REST API handler
+type response struct {
+	status int
+	header http.Header
+	body   []byte
+}
+
+// responseBuffer keeps the response written by the REST API handler
+type responseBuffer struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (b *responseBuffer) Header() http.Header {
+	return b.header
+}
+
+func (b *responseBuffer) WriteHeader(statusCode int) {
+	if b.status == 0 {
+		b.status = statusCode
+	}
+}
+
+func (b *responseBuffer) Write(data []byte) (int, error) {
+	b.WriteHeader(http.StatusOK)
+	return b.body.Write(data)
+}
+
+// call sends a request through the REST API handler with the metadata of the call as headers
+// Error responses are returned as the status of the call
+func (s *server) call(ctx context.Context, method, path string, query url.Values, body interface{}, header http.Header) (*response, error) {
+	var reader io.Reader = http.NoBody
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid resource: %v", err)
+		}
+		reader = bytes.NewReader(encoded)
+	}
+	target := path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target, reader)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid request: %v", err)
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	for key, values := range md {
+		if !forwardedMetadata(key) {
+			continue
+		}
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	// Rate limits apply to the address of the gRPC client
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		req.RemoteAddr = p.Addr.String()
+	}
+
+	buffer := &responseBuffer{header: http.Header{}}
+	s.handler.ServeHTTP(buffer, req)
+	if buffer.status == 0 {
+		buffer.status = http.StatusOK
+	}
+	resp := &response{status: buffer.status, header: buffer.header, body: buffer.body.Bytes()}
+
+	if id := resp.header.Get(correlation.Header); id != "" {
+		// Fails once the headers of a stream were sent, the first page of a list carries the id
+		_ = grpc.SetHeader(ctx, metadata.Pairs(correlation.Header, id))
+	}
+	if resp.status >= http.StatusBadRequest {
+		if err := ctx.Err(); err != nil {
+			return nil, status.FromContextError(err).Err()
+		}
+		return nil, statusOf(resp)
+	}
+	return resp, nil
+}
+
+// forwardedMetadata reports whether a metadata key is passed on as a request header,
+// the keys of the gRPC protocol and binary values are not
+func forwardedMetadata(key string) bool {
+	switch {
+	case strings.HasPrefix(key, ":"), strings.HasPrefix(key, "grpc-"), strings.HasSuffix(key, "-bin"):
+		return false
+	case key == "content-type", key == "content-length", key == "te":
+		return false
+	}
+	return true
+}
+
+// statusOf converts a problem response of the REST API to the status of the call,
+// the rejected fields of a request are attached as BadRequest details
+func statusOf(resp *response) error {
+	var problem utils.ProblemDetails
+	_ = json.Unmarshal(resp.body, &problem)
+	message := problem.Detail
+	if message == "" {
+		message = problem.Title
+	}
+	if message == "" {
+		message = http.StatusText(resp.status)
+	}
+
+	code := codeOf(resp.status)
+	if resp.status == http.StatusConflict && len(problem.Dependents) > 0 {
+		// Deleting a resource other resources still reference
+		code = codes.FailedPrecondition
+	}
+	st := status.New(code, message)
+	if len(problem.Errors) > 0 {
+		badRequest := &errdetails.BadRequest{}
+		for _, fieldErr := range problem.Errors {
+			badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+				Field:       fieldErr.Field,
+				Description: fieldErr.Message,
+			})
+		}
+		if detailed, err := st.WithDetails(badRequest); err == nil {
+			st = detailed
+		}
+	}
+	return st.Err()
+}
+
+// codeOf maps the status of a REST response to a gRPC code
+func codeOf(statusCode int) codes.Code {
+	switch statusCode {
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		return codes.InvalidArgument
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound, http.StatusGone:
+		return codes.NotFound
+	case http.StatusConflict:
+		return codes.AlreadyExists
+	case http.StatusPreconditionFailed:
+		// The resource changed since the version the update was based on
+		return codes.Aborted
+	case http.StatusPreconditionRequired:
+		return codes.FailedPrecondition
+	case http.StatusRequestEntityTooLarge, http.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	case http.StatusNotImplemented:
+		return codes.Unimplemented
+	case http.StatusServiceUnavailable:
+		return codes.Unavailable
+	case http.StatusGatewayTimeout:
+		return codes.DeadlineExceeded
+	}
+	if statusCode >= http.StatusInternalServerError {
+		return codes.Internal
+	}
+	return codes.FailedPrecondition
+}
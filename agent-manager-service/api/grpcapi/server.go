@@ -0,0 +1,520 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Package grpcapi serves the agents, prompts, tools and deployments of the REST API over gRPC.
+// Every call is dispatched to the REST handler of the same operation, so both APIs validate, authorize,
+// rate limit and audit requests alike.
+package grpcapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/reflection"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/dynamicpb"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/api/openapi"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/utils"
+)
+
+type kind int
+
+const (
+	kindList kind = iota
+	kindGet
+	kindCreate
+	kindUpdate
+	kindDelete
+)
+
+func (k kind) request() string {
+	switch k {
+	case kindList:
+		return listRequestName
+	case kindGet:
+		return getRequestName
+	case kindCreate:
+		return createRequestName
+	case kindUpdate:
+		return updateRequestName
+	default:
+		return deleteRequestName
+	}
+}
+
+func (k kind) response() string {
+	if k == kindDelete {
+		return emptyName
+	}
+	return resourceName
+}
+
+// operation maps a method of the service to the REST route serving it
+type operation struct {
+	method string
+	kind   kind
+	// Path of the collection under the organization, {parent} is replaced by the parent_id of the request
+	collection string
+	// Field of the REST list response holding the page of resources
+	listKey string
+	// Resources carry a version whose ETag guards their updates
+	versioned bool
+	// Pattern of the REST route serving updates, resources read back for masked updates are pruned
+	// to the fields of its request schema
+	route string
+}
+
+var operations = []operation{
+	{method: "ListAgents", kind: kindList, collection: "agents", listKey: "agents", versioned: true},
+	{method: "GetAgent", kind: kindGet, collection: "agents", versioned: true},
+	{method: "CreateAgent", kind: kindCreate, collection: "agents", versioned: true},
+	{method: "UpdateAgent", kind: kindUpdate, collection: "agents", versioned: true,
+		route: "PUT /orgs/{orgName}/agents/{agentId}"},
+	{method: "DeleteAgent", kind: kindDelete, collection: "agents"},
+
+	{method: "ListPrompts", kind: kindList, collection: "prompts", listKey: "prompts", versioned: true},
+	{method: "GetPrompt", kind: kindGet, collection: "prompts", versioned: true},
+	{method: "CreatePrompt", kind: kindCreate, collection: "prompts", versioned: true},
+	{method: "UpdatePrompt", kind: kindUpdate, collection: "prompts", versioned: true,
+		route: "PUT /orgs/{orgName}/prompts/{promptId}"},
+	{method: "DeletePrompt", kind: kindDelete, collection: "prompts"},
+
+	{method: "ListTools", kind: kindList, collection: "tools", listKey: "tools", versioned: true},
+	{method: "GetTool", kind: kindGet, collection: "tools", versioned: true},
+	{method: "CreateTool", kind: kindCreate, collection: "tools", versioned: true},
+	{method: "UpdateTool", kind: kindUpdate, collection: "tools", versioned: true,
+		route: "PUT /orgs/{orgName}/tools/{toolId}"},
+	{method: "DeleteTool", kind: kindDelete, collection: "tools"},
+
+	{method: "ListDeployments", kind: kindList, collection: "agents/{parent}/deployments", listKey: "deployments"},
+	{method: "CreateDeployment", kind: kindCreate, collection: "agents/{parent}/deployments"},
+}
+
+// server dispatches the calls of the service to the REST API handler
+type server struct {
+	handler  http.Handler
+	spec     *openapi.Spec
+	resource protoreflect.MessageDescriptor
+}
+
+// NewServer creates a gRPC server of the service whose calls are served by handler, the handler of the REST API
+// described by spec. Server reflection is enabled so that clients like grpcurl can discover the service
+func NewServer(handler http.Handler, spec *openapi.Spec, opts ...grpc.ServerOption) (*grpc.Server, error) {
+	files, service, err := buildFiles()
+	if err != nil {
+		return nil, err
+	}
+	s := &server{handler: handler, spec: spec}
+	descriptor, err := files.FindDescriptorByName(resourceName)
+	if err != nil {
+		return nil, err
+	}
+	s.resource = descriptor.(protoreflect.MessageDescriptor)
+
+	grpcServer := grpc.NewServer(opts...)
+	grpcServer.RegisterService(s.serviceDesc(service), s)
+	reflectionServer := reflection.NewServerV1(reflection.ServerOptions{
+		Services:           grpcServer,
+		DescriptorResolver: files,
+		ExtensionResolver:  protoregistry.GlobalTypes,
+	})
+	reflectionpb.RegisterServerReflectionServer(grpcServer, reflectionServer)
+	return grpcServer, nil
+}
+
+// serviceDesc describes the methods of the operations table, list methods stream their resources
+func (s *server) serviceDesc(service protoreflect.ServiceDescriptor) *grpc.ServiceDesc {
+	desc := &grpc.ServiceDesc{
+		ServiceName: ServiceName,
+		HandlerType: (*any)(nil),
+		Metadata:    protoFile,
+	}
+	for _, op := range operations {
+		input := service.Methods().ByName(protoreflect.Name(op.method)).Input()
+		if op.kind == kindList {
+			desc.Streams = append(desc.Streams, grpc.StreamDesc{
+				StreamName:    op.method,
+				ServerStreams: true,
+				Handler: func(_ any, stream grpc.ServerStream) error {
+					in := dynamicpb.NewMessage(input)
+					if err := stream.RecvMsg(in); err != nil {
+						return err
+					}
+					return s.list(stream.Context(), op, in, stream.SendMsg)
+				},
+			})
+			continue
+		}
+		desc.Methods = append(desc.Methods, grpc.MethodDesc{
+			MethodName: op.method,
+			Handler: func(_ any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+				in := dynamicpb.NewMessage(input)
+				if err := dec(in); err != nil {
+					return nil, err
+				}
+				call := func(ctx context.Context, req any) (any, error) {
+					return s.unary(ctx, op, req.(*dynamicpb.Message))
+				}
+				if interceptor == nil {
+					return call(ctx, in)
+				}
+				info := &grpc.UnaryServerInfo{Server: s, FullMethod: "/" + ServiceName + "/" + op.method}
+				return interceptor(ctx, in, info, call)
+			},
+		})
+	}
+	return desc
+}
+
+func (s *server) unary(ctx context.Context, op operation, in *dynamicpb.Message) (any, error) {
+	req := request{in}
+	collection, err := collectionPath(op, req)
+	if err != nil {
+		return nil, err
+	}
+	var id string
+	if op.kind != kindCreate {
+		if id = req.str("id"); id == "" {
+			return nil, status.Error(codes.InvalidArgument, "id is required")
+		}
+		id = "/" + url.PathEscape(id)
+	}
+
+	switch op.kind {
+	case kindGet:
+		resp, err := s.call(ctx, http.MethodGet, collection+id, nil, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		return s.resourceOf(resp.body, resp.header.Get("ETag"))
+	case kindCreate:
+		resource, err := req.object("resource")
+		if err != nil {
+			return nil, err
+		}
+		resp, err := s.call(ctx, http.MethodPost, collection, nil, resource, nil)
+		if err != nil {
+			return nil, err
+		}
+		return s.resourceOf(resp.body, resp.header.Get("ETag"))
+	case kindUpdate:
+		return s.update(ctx, op, collection+id, req)
+	default:
+		if _, err := s.call(ctx, http.MethodDelete, collection+id, nil, nil, nil); err != nil {
+			return nil, err
+		}
+		return &emptypb.Empty{}, nil
+	}
+}
+
+// update replaces a resource, or with an update mask reads it, copies the masked fields and writes it back
+// guarded by the ETag it was read with, so that changes made meanwhile are not overwritten
+func (s *server) update(ctx context.Context, op operation, path string, req request) (any, error) {
+	resource, err := req.object("resource")
+	if err != nil {
+		return nil, err
+	}
+	etag := req.str("etag")
+	paths := req.paths("update_mask")
+	if len(paths) > 0 {
+		current, err := s.call(ctx, http.MethodGet, path, nil, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		currentETag := current.header.Get("ETag")
+		if etag != "" && etag != "*" && etag != currentETag {
+			return nil, status.Errorf(codes.Aborted, "the resource changed since version %s was read, it is now %s", etag, currentETag)
+		}
+		var merged map[string]interface{}
+		if err := json.Unmarshal(current.body, &merged); err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to decode the current resource: %v", err)
+		}
+		if err := applyMask(merged, resource, paths); err != nil {
+			return nil, err
+		}
+		// Fields such as the id and timestamps are read but not written
+		s.spec.Prune(op.route, merged)
+		resource, etag = merged, currentETag
+	}
+
+	header := http.Header{}
+	if etag != "" {
+		header.Set("If-Match", etag)
+	}
+	resp, err := s.call(ctx, http.MethodPut, path, nil, resource, header)
+	if err != nil {
+		return nil, err
+	}
+	return s.resourceOf(resp.body, resp.header.Get("ETag"))
+}
+
+// list streams every resource of a collection, reading it page by page with the paging of the REST API.
+// Collections returning a nextCursor are followed by cursor, the others by offset up to their total
+func (s *server) list(ctx context.Context, op operation, in *dynamicpb.Message, send func(any) error) error {
+	req := request{in}
+	collection, err := collectionPath(op, req)
+	if err != nil {
+		return err
+	}
+	params, err := req.object("params")
+	if err != nil {
+		return err
+	}
+	query := url.Values{}
+	for key, value := range params {
+		query.Set(key, fmt.Sprint(value))
+	}
+	if pageSize := req.int("page_size"); pageSize != 0 {
+		query.Set("limit", strconv.FormatInt(pageSize, 10))
+	}
+
+	for offset, cursor := 0, ""; ; {
+		if cursor != "" {
+			query.Set("cursor", cursor)
+			query.Del("offset")
+		} else {
+			query.Set("offset", strconv.Itoa(offset))
+		}
+		resp, err := s.call(ctx, http.MethodGet, collection, query, nil, nil)
+		if err != nil {
+			return err
+		}
+		var page map[string]json.RawMessage
+		var items []json.RawMessage
+		var total int
+		var next string
+		if err := json.Unmarshal(resp.body, &page); err != nil {
+			return status.Errorf(codes.Internal, "failed to decode the page at offset %d: %v", offset, err)
+		}
+		if err := json.Unmarshal(page[op.listKey], &items); err != nil {
+			return status.Errorf(codes.Internal, "failed to decode the page at offset %d: %v", offset, err)
+		}
+		_ = json.Unmarshal(page["total"], &total)
+		_ = json.Unmarshal(page["nextCursor"], &next)
+
+		for _, item := range items {
+			etag := ""
+			if op.versioned {
+				etag = versionETag(item)
+			}
+			message, err := s.resourceOf(item, etag)
+			if err != nil {
+				return err
+			}
+			if err := send(message); err != nil {
+				return err
+			}
+		}
+		offset += len(items)
+		if next != "" {
+			cursor = next
+			continue
+		}
+		// Resources created or deleted meanwhile may shift the following pages of collections paged by offset
+		if cursor != "" || len(items) == 0 || offset >= total {
+			return nil
+		}
+	}
+}
+
+// resourceOf wraps a JSON resource of the REST API in a Resource message
+func (s *server) resourceOf(body []byte, etag string) (proto.Message, error) {
+	var fields map[string]interface{}
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to decode the resource: %v", err)
+	}
+	resource, err := structpb.NewStruct(fields)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to convert the resource: %v", err)
+	}
+	encoded, err := proto.Marshal(resource)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to encode the resource: %v", err)
+	}
+
+	message := dynamicpb.NewMessage(s.resource)
+	resourceFields := s.resource.Fields()
+	if err := proto.Unmarshal(encoded, message.Mutable(resourceFields.ByName("resource")).Message().Interface()); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to encode the resource: %v", err)
+	}
+	if etag != "" {
+		message.Set(resourceFields.ByName("etag"), protoreflect.ValueOfString(etag))
+	}
+	return message, nil
+}
+
+// versionETag returns the ETag of the version of a listed resource, empty when it has none
+func versionETag(item json.RawMessage) string {
+	var versioned struct {
+		Version int32 `json:"version"`
+	}
+	if err := json.Unmarshal(item, &versioned); err != nil || versioned.Version < 1 {
+		return ""
+	}
+	return utils.FormatVersionETag(versioned.Version)
+}
+
+// collectionPath returns the REST path of the collection of an operation in the organization of a request
+func collectionPath(op operation, req request) (string, error) {
+	orgName := req.str("org_name")
+	if orgName == "" {
+		return "", status.Error(codes.InvalidArgument, "org_name is required")
+	}
+	collection := op.collection
+	if strings.Contains(collection, "{parent}") {
+		parentID := req.str("parent_id")
+		if parentID == "" {
+			return "", status.Error(codes.InvalidArgument, "parent_id is required")
+		}
+		collection = strings.ReplaceAll(collection, "{parent}", url.PathEscape(parentID))
+	}
+	return "/api/v1/orgs/" + url.PathEscape(orgName) + "/" + collection, nil
+}
+
+// applyMask copies the fields of source named by dot separated paths into target, removing those source lacks
+func applyMask(target, source map[string]interface{}, paths []string) error {
+	for _, path := range paths {
+		parts, err := maskFields(target, source, path)
+		if err != nil {
+			return err
+		}
+
+		value, found := interface{}(source), true
+		for _, part := range parts {
+			object, ok := value.(map[string]interface{})
+			if !ok {
+				found = false
+				break
+			}
+			if value, found = object[part]; !found {
+				break
+			}
+		}
+
+		parent := target
+		for _, part := range parts[:len(parts)-1] {
+			child, ok := parent[part].(map[string]interface{})
+			if !ok {
+				if parent[part] != nil {
+					return status.Errorf(codes.InvalidArgument, "update_mask path %q is not within an object", path)
+				}
+				child = map[string]interface{}{}
+				parent[part] = child
+			}
+			parent = child
+		}
+		last := parts[len(parts)-1]
+		if found {
+			parent[last] = value
+		} else {
+			delete(parent, last)
+		}
+	}
+	return nil
+}
+
+// maskFields splits a mask path into the JSON fields it names. Fields of neither object are taken as snake_case
+// names of camelCase fields, since JSON encoded masks reach the server in snake_case
+func maskFields(target, source map[string]interface{}, path string) ([]string, error) {
+	parts := strings.Split(path, ".")
+	for i, part := range parts {
+		if part == "" {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid update_mask path %q", path)
+		}
+		_, inTarget := target[part]
+		_, inSource := source[part]
+		if !inTarget && !inSource {
+			parts[i] = camelCase(part)
+		}
+		target, _ = target[parts[i]].(map[string]interface{})
+		source, _ = source[parts[i]].(map[string]interface{})
+	}
+	return parts, nil
+}
+
+// camelCase converts a snake_case name to lowerCamelCase
+func camelCase(name string) string {
+	words := strings.Split(name, "_")
+	for i := 1; i < len(words); i++ {
+		if words[i] != "" {
+			words[i] = strings.ToUpper(words[i][:1]) + words[i][1:]
+		}
+	}
+	return strings.Join(words, "")
+}
+
+// request reads the fields of a request message by name
+type request struct {
+	message *dynamicpb.Message
+}
+
+func (r request) field(name string) protoreflect.FieldDescriptor {
+	return r.message.Descriptor().Fields().ByName(protoreflect.Name(name))
+}
+
+func (r request) str(name string) string {
+	return r.message.Get(r.field(name)).String()
+}
+
+func (r request) int(name string) int64 {
+	return r.message.Get(r.field(name)).Int()
+}
+
+// object returns a google.protobuf.Struct field as JSON values, nil when it is not set
+func (r request) object(name string) (map[string]interface{}, error) {
+	field := r.field(name)
+	if !r.message.Has(field) {
+		return nil, nil
+	}
+	encoded, err := proto.Marshal(r.message.Get(field).Message().Interface())
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid %s: %v", name, err)
+	}
+	var object structpb.Struct
+	if err := proto.Unmarshal(encoded, &object); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid %s: %v", name, err)
+	}
+	return object.AsMap(), nil
+}
+
+// paths returns the paths of a google.protobuf.FieldMask field
+func (r request) paths(name string) []string {
+	field := r.field(name)
+	if !r.message.Has(field) {
+		return nil
+	}
+	mask := r.message.Get(field).Message()
+	list := mask.Get(mask.Descriptor().Fields().ByName("paths")).List()
+	paths := make([]string, 0, list.Len())
+	for i := 0; i < list.Len(); i++ {
+		paths = append(paths, list.Get(i).String())
+	}
+	return paths
+}
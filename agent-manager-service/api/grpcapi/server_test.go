@@ -0,0 +1,387 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package grpcapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"testing"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/api/openapi"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/models"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/utils"
+)
+
+// fakeAgents serves the agent routes of the REST API from memory, versioned like the real ones
+type fakeAgents struct {
+	mu       sync.Mutex
+	agents   []map[string]interface{}
+	requests []*http.Request
+	bodies   []map[string]interface{}
+}
+
+func (f *fakeAgents) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/orgs/{orgName}/agents", func(w http.ResponseWriter, r *http.Request) {
+		f.record(r)
+		limit, offset := 10, 0
+		if value := r.URL.Query().Get("limit"); value != "" {
+			limit, _ = strconv.Atoi(value)
+		}
+		if value := r.URL.Query().Get("offset"); value != "" {
+			offset, _ = strconv.Atoi(value)
+		}
+		// The cursor of the fake is the position of the next page
+		if value := r.URL.Query().Get("cursor"); value != "" {
+			offset, _ = strconv.Atoi(value)
+		}
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		page := f.agents[min(offset, len(f.agents)):min(offset+limit, len(f.agents))]
+		response := map[string]interface{}{"agents": page, "limit": limit, "offset": offset}
+		if offset+limit < len(f.agents) {
+			response["nextCursor"] = strconv.Itoa(offset + limit)
+		}
+		writeJSON(w, http.StatusOK, response)
+	})
+	mux.HandleFunc("GET /api/v1/orgs/{orgName}/agents/{agentId}", func(w http.ResponseWriter, r *http.Request) {
+		f.record(r)
+		agent := f.find(r.PathValue("agentId"))
+		if agent == nil {
+			utils.WriteProblemResponse(w, r, http.StatusNotFound, "Agent not found")
+			return
+		}
+		w.Header().Set("ETag", utils.FormatVersionETag(int32(agent["version"].(float64))))
+		writeJSON(w, http.StatusOK, agent)
+	})
+	mux.HandleFunc("PUT /api/v1/orgs/{orgName}/agents/{agentId}", func(w http.ResponseWriter, r *http.Request) {
+		body := f.record(r)
+		agent := f.find(r.PathValue("agentId"))
+		if agent == nil {
+			utils.WriteProblemResponse(w, r, http.StatusNotFound, "Agent not found")
+			return
+		}
+		if body["name"] == "" {
+			utils.WriteProblemResponse(w, r, http.StatusBadRequest, "Invalid agent", utils.FieldError{Field: "name", Message: "is required"})
+			return
+		}
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		version := int32(agent["version"].(float64))
+		if r.Header.Get("If-Match") != utils.FormatVersionETag(version) {
+			utils.WriteProblemResponse(w, r, http.StatusPreconditionFailed, "The agent was changed by another request")
+			return
+		}
+		for key, value := range body {
+			agent[key] = value
+		}
+		agent["version"] = float64(version + 1)
+		w.Header().Set("ETag", utils.FormatVersionETag(version+1))
+		writeJSON(w, http.StatusOK, agent)
+	})
+	mux.HandleFunc("DELETE /api/v1/orgs/{orgName}/agents/{agentId}", func(w http.ResponseWriter, r *http.Request) {
+		f.record(r)
+		w.WriteHeader(http.StatusNoContent)
+	})
+	return mux
+}
+
+func (f *fakeAgents) record(r *http.Request) map[string]interface{} {
+	var body map[string]interface{}
+	if data, _ := io.ReadAll(r.Body); len(data) > 0 {
+		_ = json.Unmarshal(data, &body)
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.requests = append(f.requests, r)
+	f.bodies = append(f.bodies, body)
+	return body
+}
+
+func (f *fakeAgents) find(id string) map[string]interface{} {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, agent := range f.agents {
+		if agent["id"] == id {
+			return agent
+		}
+	}
+	return nil
+}
+
+func writeJSON(w http.ResponseWriter, statusCode int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func newAgent(id string, version int) map[string]interface{} {
+	return map[string]interface{}{
+		"id":          id,
+		"name":        "agent-" + id,
+		"description": "support agent",
+		"framework":   "langchain",
+		"modelConfig": map[string]interface{}{"model": "gpt-4o", "temperature": 0.7},
+		"version":     float64(version),
+	}
+}
+
+// dial serves the service over an in-memory connection
+func dial(t *testing.T, handler http.Handler) *grpc.ClientConn {
+	t.Helper()
+	spec, err := openapi.New(openapi.Info{}, []openapi.Operation{
+		{Pattern: "PUT /orgs/{orgName}/agents/{agentId}", Request: models.ManagedAgentRequest{}},
+	})
+	if err != nil {
+		t.Fatalf("openapi.New() error = %v", err)
+	}
+	server, err := NewServer(handler, spec)
+	if err != nil {
+		t.Fatalf("NewServer() error = %v", err)
+	}
+	listener := bufconn.Listen(1 << 20)
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return conn
+}
+
+// newMessage builds a message of the service from its JSON form
+func newMessage(t *testing.T, name string, value string) *dynamicpb.Message {
+	t.Helper()
+	files, _, err := buildFiles()
+	if err != nil {
+		t.Fatalf("buildFiles() error = %v", err)
+	}
+	descriptor, err := files.FindDescriptorByName(protoreflect.FullName(name))
+	if err != nil {
+		t.Fatalf("FindDescriptorByName(%s) error = %v", name, err)
+	}
+	message := dynamicpb.NewMessage(descriptor.(protoreflect.MessageDescriptor))
+	if err := protojson.Unmarshal([]byte(value), message); err != nil {
+		t.Fatalf("protojson.Unmarshal(%s) error = %v", value, err)
+	}
+	return message
+}
+
+// resourceJSON returns the resource and ETag of a Resource message
+func resourceJSON(t *testing.T, message *dynamicpb.Message) (map[string]interface{}, string) {
+	t.Helper()
+	encoded, err := protojson.Marshal(message)
+	if err != nil {
+		t.Fatalf("protojson.Marshal() error = %v", err)
+	}
+	var resource struct {
+		Resource map[string]interface{} `json:"resource"`
+		ETag     string                 `json:"etag"`
+	}
+	if err := json.Unmarshal(encoded, &resource); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	return resource.Resource, resource.ETag
+}
+
+func method(name string) string {
+	return "/" + ServiceName + "/" + name
+}
+
+func TestUpdateWithFieldMask(t *testing.T) {
+	fake := &fakeAgents{agents: []map[string]interface{}{newAgent("a1", 3)}}
+	conn := dial(t, fake.handler())
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer token")
+
+	// The mask reaches the server in snake_case when the request is sent as JSON, e.g. by grpcurl
+	req := newMessage(t, updateRequestName, `{"orgName":"acme","id":"a1","updateMask":"modelConfig.temperature",
+		"resource":{"name":"renamed","modelConfig":{"temperature":0.2}}}`)
+	reply := newMessage(t, resourceName, `{}`)
+	if err := conn.Invoke(ctx, method("UpdateAgent"), req, reply); err != nil {
+		t.Fatalf("UpdateAgent() error = %v", err)
+	}
+
+	resource, etag := resourceJSON(t, reply)
+	if etag != `"4"` {
+		t.Errorf("etag = %s, want the ETag of the new version", etag)
+	}
+	if resource["name"] != "agent-a1" {
+		t.Errorf("name = %v, fields outside the mask must be kept", resource["name"])
+	}
+	modelConfig := resource["modelConfig"].(map[string]interface{})
+	if modelConfig["temperature"] != 0.2 || modelConfig["model"] != "gpt-4o" {
+		t.Errorf("modelConfig = %v, want the temperature updated and the model kept", modelConfig)
+	}
+
+	fake.mu.Lock()
+	put, body := fake.requests[len(fake.requests)-1], fake.bodies[len(fake.bodies)-1]
+	fake.mu.Unlock()
+	// The version is written back as the version the update is based on
+	if _, ok := body["id"]; ok || body["version"] != float64(3) {
+		t.Errorf("update body = %v, want the fields read but not written left out and the version read kept", body)
+	}
+	if put.Method != http.MethodPut || put.Header.Get("If-Match") != `"3"` {
+		t.Errorf("update sent %s with If-Match %q, want PUT guarded by the version read", put.Method, put.Header.Get("If-Match"))
+	}
+	if put.Header.Get("Authorization") != "Bearer token" {
+		t.Errorf("Authorization = %q, want the token of the call metadata", put.Header.Get("Authorization"))
+	}
+
+	// An ETag older than the current version is rejected before anything is written
+	stale := newMessage(t, updateRequestName, `{"orgName":"acme","id":"a1","updateMask":"description",
+		"etag":"\"3\"","resource":{"description":"changed"}}`)
+	err := conn.Invoke(ctx, method("UpdateAgent"), stale, newMessage(t, resourceName, `{}`))
+	if status.Code(err) != codes.Aborted {
+		t.Errorf("UpdateAgent() with a stale etag error = %v, want Aborted", err)
+	}
+	if fake.find("a1")["description"] != "support agent" {
+		t.Error("update with a stale etag was written")
+	}
+}
+
+func TestErrorsCarryTheProblemOfTheRESTAPI(t *testing.T) {
+	fake := &fakeAgents{agents: []map[string]interface{}{newAgent("a1", 1)}}
+	conn := dial(t, fake.handler())
+	ctx := context.Background()
+
+	err := conn.Invoke(ctx, method("GetAgent"), newMessage(t, getRequestName, `{"orgName":"acme","id":"missing"}`),
+		newMessage(t, resourceName, `{}`))
+	if st := status.Convert(err); st.Code() != codes.NotFound || st.Message() != "Agent not found" {
+		t.Errorf("GetAgent() of a missing agent error = %v, want NotFound with the problem detail", err)
+	}
+
+	// Without a mask the request is passed on as it is, invalid fields are reported as details
+	invalid := newMessage(t, updateRequestName, `{"orgName":"acme","id":"a1","etag":"\"1\"","resource":{"name":""}}`)
+	err = conn.Invoke(ctx, method("UpdateAgent"), invalid, newMessage(t, resourceName, `{}`))
+	st := status.Convert(err)
+	if st.Code() != codes.InvalidArgument {
+		t.Fatalf("UpdateAgent() of an invalid agent error = %v, want InvalidArgument", err)
+	}
+	var violations []*errdetails.BadRequest_FieldViolation
+	for _, detail := range st.Details() {
+		if badRequest, ok := detail.(*errdetails.BadRequest); ok {
+			violations = badRequest.FieldViolations
+		}
+	}
+	if len(violations) != 1 || violations[0].Field != "name" {
+		t.Errorf("field violations = %v, want the rejected name", violations)
+	}
+
+	err = conn.Invoke(ctx, method("GetAgent"), newMessage(t, getRequestName, `{"id":"a1"}`), newMessage(t, resourceName, `{}`))
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("GetAgent() without an organization error = %v, want InvalidArgument", err)
+	}
+
+	if err := conn.Invoke(ctx, method("DeleteAgent"), newMessage(t, deleteRequestName, `{"orgName":"acme","id":"a1"}`), &emptypb.Empty{}); err != nil {
+		t.Errorf("DeleteAgent() error = %v", err)
+	}
+}
+
+func TestListStreamsEveryPage(t *testing.T) {
+	fake := &fakeAgents{}
+	for i := 1; i <= 5; i++ {
+		fake.agents = append(fake.agents, newAgent(fmt.Sprintf("a%d", i), i))
+	}
+	conn := dial(t, fake.handler())
+
+	desc := &grpc.StreamDesc{StreamName: "ListAgents", ServerStreams: true}
+	stream, err := conn.NewStream(context.Background(), desc, method("ListAgents"))
+	if err != nil {
+		t.Fatalf("NewStream() error = %v", err)
+	}
+	if err := stream.SendMsg(newMessage(t, listRequestName, `{"orgName":"acme","pageSize":2,"params":{"search":"agent"}}`)); err != nil {
+		t.Fatalf("SendMsg() error = %v", err)
+	}
+	if err := stream.CloseSend(); err != nil {
+		t.Fatalf("CloseSend() error = %v", err)
+	}
+
+	var ids, etags []string
+	for {
+		reply := newMessage(t, resourceName, `{}`)
+		if err := stream.RecvMsg(reply); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("RecvMsg() error = %v", err)
+		}
+		resource, etag := resourceJSON(t, reply)
+		ids = append(ids, resource["id"].(string))
+		etags = append(etags, etag)
+	}
+	if fmt.Sprint(ids) != "[a1 a2 a3 a4 a5]" || etags[4] != `"5"` {
+		t.Errorf("streamed %v with ETags %v, want every agent with its version", ids, etags)
+	}
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	var pages []string
+	for _, req := range fake.requests {
+		if req.URL.Query().Get("search") != "agent" || req.URL.Query().Get("limit") != "2" {
+			t.Errorf("page request %s, want the filters and page size of the call", req.URL)
+		}
+		pages = append(pages, req.URL.Query().Get("offset")+"/"+req.URL.Query().Get("cursor"))
+	}
+	// The first page is read from the start, the others from the cursor of the previous page
+	if fmt.Sprint(pages) != "[0/ /2 /4]" {
+		t.Errorf("pages read at offset/cursor %v, want [0/ /2 /4]", pages)
+	}
+}
+
+func TestReflectionDescribesTheService(t *testing.T) {
+	conn := dial(t, http.NotFoundHandler())
+	stream, err := reflectionpb.NewServerReflectionClient(conn).ServerReflectionInfo(context.Background())
+	if err != nil {
+		t.Fatalf("ServerReflectionInfo() error = %v", err)
+	}
+	err = stream.Send(&reflectionpb.ServerReflectionRequest{
+		MessageRequest: &reflectionpb.ServerReflectionRequest_FileContainingSymbol{FileContainingSymbol: ServiceName},
+	})
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	resp, err := stream.Recv()
+	if err != nil {
+		t.Fatalf("Recv() error = %v", err)
+	}
+	files := resp.GetFileDescriptorResponse().GetFileDescriptorProto()
+	if len(files) == 0 {
+		t.Fatalf("reflection response = %v, want the descriptor of the service", resp)
+	}
+}
@@ -33,7 +33,7 @@ func registerHealthCheck(mux *http.ServeMux) {
 
 		var dbRes *int
 		if result := db.DB(ctx).Raw("SELECT 1").Scan(&dbRes); result.Error != nil {
-			utils.WriteErrorResponse(w, http.StatusInternalServerError, "database connection error")
+			utils.WriteProblemResponse(w, r, http.StatusInternalServerError, "database connection error")
 			return
 		}
 		response := map[string]interface{}{
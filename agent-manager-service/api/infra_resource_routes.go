@@ -21,19 +21,20 @@ import (
 
 	"github.com/wso2/ai-agent-management-platform/agent-manager-service/controllers"
 	"github.com/wso2/ai-agent-management-platform/agent-manager-service/middleware"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/utils"
 )
 
-func registerInfraRoutes(mux *http.ServeMux, ctrl controllers.InfraResourceController) {
-	// All routes now use HandleFuncWithValidation which automatically
-	// extracts path parameters from the pattern and validates them
-	middleware.HandleFuncWithValidation(mux, "GET /orgs", ctrl.ListOrganizations)
-	middleware.HandleFuncWithValidation(mux, "GET /orgs/{orgName}", ctrl.GetOrganization)
-	middleware.HandleFuncWithValidation(mux, "GET /orgs/{orgName}/data-planes", ctrl.GetDataplanes)
-	middleware.HandleFuncWithValidation(mux, "GET /orgs/{orgName}/deployment-pipelines", ctrl.ListOrgDeploymentPipelines)
-	middleware.HandleFuncWithValidation(mux, "GET /orgs/{orgName}/environments", ctrl.ListOrgEnvironments)
-	middleware.HandleFuncWithValidation(mux, "GET /orgs/{orgName}/projects", ctrl.ListProjects)
-	middleware.HandleFuncWithValidation(mux, "POST /orgs/{orgName}/projects", ctrl.CreateProject)
-	middleware.HandleFuncWithValidation(mux, "GET /orgs/{orgName}/projects/{projName}", ctrl.GetProject)
-	middleware.HandleFuncWithValidation(mux, "GET /orgs/{orgName}/projects/{projName}/deployment-pipeline", ctrl.GetProjectDeploymentPipeline)
-	middleware.HandleFuncWithValidation(mux, "DELETE /orgs/{orgName}/projects/{projName}", ctrl.DeleteProject)
+func registerInfraRoutes(mux *http.ServeMux, ctrl controllers.InfraResourceController, authz *middleware.Authorizer) {
+	// All routes are registered through the authorizer, which requires the given permission
+	// and validates the path parameters extracted from the pattern
+	authz.HandleFunc(mux, "GET /orgs", utils.PermissionOrgsRead, ctrl.ListOrganizations)
+	authz.HandleFunc(mux, "GET /orgs/{orgName}", utils.PermissionOrgsRead, ctrl.GetOrganization)
+	authz.HandleFunc(mux, "GET /orgs/{orgName}/data-planes", utils.PermissionOrgsRead, ctrl.GetDataplanes)
+	authz.HandleFunc(mux, "GET /orgs/{orgName}/deployment-pipelines", utils.PermissionOrgsRead, ctrl.ListOrgDeploymentPipelines)
+	authz.HandleFunc(mux, "GET /orgs/{orgName}/environments", utils.PermissionOrgsRead, ctrl.ListOrgEnvironments)
+	authz.HandleFunc(mux, "GET /orgs/{orgName}/projects", utils.PermissionProjectsRead, ctrl.ListProjects)
+	authz.HandleFunc(mux, "POST /orgs/{orgName}/projects", utils.PermissionProjectsWrite, ctrl.CreateProject)
+	authz.HandleFunc(mux, "GET /orgs/{orgName}/projects/{projName}", utils.PermissionProjectsRead, ctrl.GetProject)
+	authz.HandleFunc(mux, "GET /orgs/{orgName}/projects/{projName}/deployment-pipeline", utils.PermissionProjectsRead, ctrl.GetProjectDeploymentPipeline)
+	authz.HandleFunc(mux, "DELETE /orgs/{orgName}/projects/{projName}", utils.PermissionProjectsWrite, ctrl.DeleteProject)
 }
@@ -22,6 +22,12 @@ import (
 	"github.com/wso2/ai-agent-management-platform/agent-manager-service/controllers"
 )
 
-func registerInternalRoutes(mux *http.ServeMux, ctrl controllers.BuildCIController) {
+func registerInternalRoutes(mux *http.ServeMux, ctrl controllers.BuildCIController, managedAgentCtrl controllers.ManagedAgentController, credentialCtrl controllers.CredentialController) {
 	mux.HandleFunc("POST /builds/callback", ctrl.HandleBuildCallback)
+	// The traces observer copies the labels of agents onto the spans they emit
+	mux.HandleFunc("GET /agents/{agentId}/labels", managedAgentCtrl.GetManagedAgentLabels)
+	// The traces observer links spans that only report an agent name to the agents of that name
+	mux.HandleFunc("GET /agents", managedAgentCtrl.FindManagedAgentsByName)
+	// Agent runtimes fetch the secrets their tools authenticate with, secrets never leave through the public API
+	mux.HandleFunc("GET /credentials/{credentialId}/secret", credentialCtrl.ResolveCredentialSecret)
 }
@@ -0,0 +1,67 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/middleware/logger"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/utils"
+	"github.com/wso2/ai-agent-management-platform/libs/go-common/logging"
+)
+
+type logLevelRequest struct {
+	Level string `json:"level"`
+}
+
+type logLevelResponse struct {
+	Level    string `json:"level"`
+	Previous string `json:"previous,omitempty"`
+	// Debug records dropped by sampling since the start
+	SampledOut uint64 `json:"sampledOut"`
+}
+
+// registerLogLevelRoutes lets operators raise the log level of a replica while it runs,
+// the level holds until it is changed again, the service receives SIGHUP or restarts
+func registerLogLevelRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /log-level", func(w http.ResponseWriter, r *http.Request) {
+		utils.WriteSuccessResponse(w, http.StatusOK, logLevelResponse{
+			Level:      logging.Level().String(),
+			SampledOut: logging.GetStats().SampledOut,
+		})
+	})
+	mux.HandleFunc("PUT /log-level", func(w http.ResponseWriter, r *http.Request) {
+		var req logLevelRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			utils.WriteProblemResponse(w, r, http.StatusBadRequest, "Invalid request body")
+			return
+		}
+		level, err := logging.ParseLevel(req.Level)
+		if err != nil || req.Level == "" {
+			utils.WriteProblemResponse(w, r, http.StatusBadRequest, "level must be one of DEBUG, INFO, WARN or ERROR")
+			return
+		}
+		previous := logging.SetLevel(level)
+		logger.GetLogger(r.Context()).Warn("log level changed", "level", level.String(), "previous", previous.String())
+		utils.WriteSuccessResponse(w, http.StatusOK, logLevelResponse{
+			Level:      level.String(),
+			Previous:   previous.String(),
+			SampledOut: logging.GetStats().SampledOut,
+		})
+	})
+}
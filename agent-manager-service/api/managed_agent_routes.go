@@ -0,0 +1,44 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package api
+
+import (
+	"net/http"
+
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/controllers"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/middleware"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/utils"
+)
+
+func registerManagedAgentRoutes(mux *http.ServeMux, ctrl controllers.ManagedAgentController, authz *middleware.Authorizer) {
+	authz.HandleFunc(mux, "POST /orgs/{orgName}/agents", utils.PermissionAgentsWrite, ctrl.CreateManagedAgent)
+	authz.HandleFunc(mux, "GET /orgs/{orgName}/agents", utils.PermissionAgentsRead, ctrl.ListManagedAgents)
+	authz.HandleFunc(mux, "GET /orgs/{orgName}/agents/{agentId}", utils.PermissionAgentsRead, ctrl.GetManagedAgent)
+	authz.HandleFunc(mux, "PUT /orgs/{orgName}/agents/{agentId}", utils.PermissionAgentsWrite, ctrl.UpdateManagedAgent)
+	authz.HandleFunc(mux, "PATCH /orgs/{orgName}/agents/{agentId}", utils.PermissionAgentsWrite, ctrl.PatchManagedAgent)
+	authz.HandleFunc(mux, "DELETE /orgs/{orgName}/agents/{agentId}", utils.PermissionAgentsWrite, ctrl.DeleteManagedAgent)
+	authz.HandleFunc(mux, "POST /orgs/{orgName}/agents/{agentId}/restore", utils.PermissionTrashManage, ctrl.RestoreManagedAgent)
+	authz.HandleFunc(mux, "GET /orgs/{orgName}/agents/{agentId}/versions", utils.PermissionAgentsRead, ctrl.ListManagedAgentVersions)
+	authz.HandleFunc(mux, "GET /orgs/{orgName}/agents/{agentId}/versions/{version}", utils.PermissionAgentsRead, ctrl.GetManagedAgentVersion)
+	authz.HandleFunc(mux, "GET /orgs/{orgName}/agents/{agentId}/versions/{version}/diff/{otherVersion}", utils.PermissionAgentsRead, ctrl.DiffManagedAgentVersions)
+	authz.HandleFunc(mux, "GET /orgs/{orgName}/agents/{agentId}/tools", utils.PermissionAgentsRead, ctrl.GetManagedAgentTools)
+	authz.HandleFunc(mux, "POST /orgs/{orgName}/agents/{agentId}/rollback", utils.PermissionAgentsWrite, ctrl.RollbackManagedAgent)
+	authz.HandleFunc(mux, "GET /orgs/{orgName}/agents/{agentId}/export", utils.PermissionAgentsRead, ctrl.ExportManagedAgent)
+	authz.HandleFunc(mux, "POST /orgs/{orgName}/agents/import", utils.PermissionAgentsWrite, ctrl.ImportManagedAgent)
+	authz.HandleFunc(mux, "POST /orgs/{orgName}/agents:batchUpdate", utils.PermissionAgentsWrite, ctrl.BatchUpdateManagedAgents)
+	authz.HandleFunc(mux, "GET /frameworks", utils.PermissionAgentsRead, ctrl.ListAgentFrameworks)
+}
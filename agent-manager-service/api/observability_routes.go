@@ -21,11 +21,15 @@ import (
 
 	"github.com/wso2/ai-agent-management-platform/agent-manager-service/controllers"
 	"github.com/wso2/ai-agent-management-platform/agent-manager-service/middleware"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/utils"
 )
 
-func registerObservabilityRoutes(mux *http.ServeMux, ctrl controllers.ObservabilityController) {
-	// All routes now use HandleFuncWithValidation which automatically
-	// extracts path parameters from the pattern and validates them
-	middleware.HandleFuncWithValidation(mux, "GET /orgs/{orgName}/projects/{projName}/agents/{agentName}/traces", ctrl.ListTraces)
-	middleware.HandleFuncWithValidation(mux, "GET /orgs/{orgName}/projects/{projName}/agents/{agentName}/trace/{traceId}", ctrl.GetTrace)
+func registerObservabilityRoutes(mux *http.ServeMux, ctrl controllers.ObservabilityController, authz *middleware.Authorizer) {
+	// All routes are registered through the authorizer, which requires the given permission
+	// and validates the path parameters extracted from the pattern
+	authz.HandleFunc(mux, "GET /orgs/{orgName}/projects/{projName}/agents/{agentName}/traces", utils.PermissionTracesRead, ctrl.ListTraces)
+	authz.HandleFunc(mux, "GET /orgs/{orgName}/projects/{projName}/agents/{agentName}/trace/{traceId}", utils.PermissionTracesRead, ctrl.GetTrace)
+	// Traces the trace observer linked to a managed agent, by its resource attribute or by the agent name it reports
+	authz.HandleFunc(mux, "GET /orgs/{orgName}/agents/{agentId}/traces", utils.PermissionTracesRead, ctrl.ListAgentTraces)
+	authz.HandleFunc(mux, "GET /orgs/{orgName}/agents/{agentId}/metrics", utils.PermissionTracesRead, ctrl.GetAgentMetrics)
 }
@@ -0,0 +1,367 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Package openapi describes the REST API as an OpenAPI 3.1 document generated from the Go types its handlers
+// decode requests into and encode responses from, and validates request bodies against the same schemas
+package openapi
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Schema is the subset of JSON Schema 2020-12 the document is written in
+type Schema struct {
+	Ref    string
+	Type   string
+	Format string
+	// Nullable schemas also accept null
+	Nullable    bool
+	Description string
+	Enum        []string
+	Default     interface{}
+	Minimum     *float64
+	Maximum     *float64
+	Items       *Schema
+	Properties  map[string]*Schema
+	// AdditionalProperties is the schema of the values of maps, objects without it reject unknown fields
+	AdditionalProperties *Schema
+}
+
+// String returns a schema of strings
+func String() *Schema {
+	return &Schema{Type: "string"}
+}
+
+// Integer returns a schema of integers
+func Integer() *Schema {
+	return &Schema{Type: "integer"}
+}
+
+// Boolean returns a schema of booleans
+func Boolean() *Schema {
+	return &Schema{Type: "boolean"}
+}
+
+// DateTime returns a schema of RFC 3339 timestamps
+func DateTime() *Schema {
+	return &Schema{Type: "string", Format: "date-time"}
+}
+
+// UUID returns a schema of UUIDs
+func UUID() *Schema {
+	return &Schema{Type: "string", Format: "uuid"}
+}
+
+// Enum returns a schema of the given strings
+func Enum(values ...string) *Schema {
+	return &Schema{Type: "string", Enum: values}
+}
+
+// WithDefault sets the value a missing parameter or field takes
+func (s *Schema) WithDefault(value interface{}) *Schema {
+	s.Default = value
+	return s
+}
+
+// WithRange bounds a numeric schema, either bound is skipped when nil
+func (s *Schema) WithRange(minimum, maximum *float64) *Schema {
+	s.Minimum, s.Maximum = minimum, maximum
+	return s
+}
+
+// Bound returns a pointer to a bound of WithRange
+func Bound(value float64) *float64 {
+	return &value
+}
+
+func (s *Schema) MarshalJSON() ([]byte, error) {
+	doc := map[string]interface{}{}
+	if s.Ref != "" {
+		if s.Nullable {
+			doc["anyOf"] = []interface{}{map[string]string{"$ref": s.Ref}, map[string]string{"type": "null"}}
+		} else {
+			doc["$ref"] = s.Ref
+		}
+	}
+	if s.Type != "" {
+		if s.Nullable {
+			doc["type"] = []string{s.Type, "null"}
+		} else {
+			doc["type"] = s.Type
+		}
+	}
+	if s.Format != "" {
+		doc["format"] = s.Format
+	}
+	if s.Description != "" {
+		doc["description"] = s.Description
+	}
+	if len(s.Enum) > 0 {
+		doc["enum"] = s.Enum
+	}
+	if s.Default != nil {
+		doc["default"] = s.Default
+	}
+	if s.Minimum != nil {
+		doc["minimum"] = *s.Minimum
+	}
+	if s.Maximum != nil {
+		doc["maximum"] = *s.Maximum
+	}
+	if s.Items != nil {
+		doc["items"] = s.Items
+	}
+	if s.Type == "object" {
+		if s.Properties != nil {
+			doc["properties"] = s.Properties
+		}
+		if s.AdditionalProperties != nil {
+			doc["additionalProperties"] = s.AdditionalProperties
+		} else {
+			doc["additionalProperties"] = false
+		}
+	}
+	return json.Marshal(doc)
+}
+
+var (
+	timeType            = reflect.TypeOf(time.Time{})
+	uuidType            = reflect.TypeOf(uuid.UUID{})
+	rawMessageType      = reflect.TypeOf(json.RawMessage{})
+	jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// generator derives schemas from Go types the way encoding/json encodes and decodes them,
+// named struct types become components referenced by the schemas using them
+type generator struct {
+	components map[string]*Schema
+	names      map[reflect.Type]string
+}
+
+func newGenerator() *generator {
+	return &generator{components: map[string]*Schema{}, names: map[reflect.Type]string{}}
+}
+
+func (g *generator) schemaOf(t reflect.Type) (*Schema, error) {
+	switch {
+	case t == timeType:
+		return DateTime(), nil
+	case t == uuidType:
+		return UUID(), nil
+	case t == rawMessageType:
+		return &Schema{}, nil
+	case t.Kind() != reflect.Pointer && t.Kind() != reflect.Interface && reflect.PointerTo(t).Implements(jsonUnmarshalerType):
+		// Types decoding themselves accept whatever they decide to
+		return &Schema{}, nil
+	case t.Kind() != reflect.Pointer && t.Kind() != reflect.Interface && reflect.PointerTo(t).Implements(textUnmarshalerType):
+		return String(), nil
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return Boolean(), nil
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int, reflect.Int64:
+		schema := Integer()
+		if bits := t.Bits(); bits < 64 {
+			schema.Minimum, schema.Maximum = Bound(-math.Pow(2, float64(bits-1))), Bound(math.Pow(2, float64(bits-1))-1)
+		}
+		schema.Format = integerFormat(t.Bits())
+		return schema, nil
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint, reflect.Uint64, reflect.Uintptr:
+		schema := Integer()
+		schema.Minimum = Bound(0)
+		if bits := t.Bits(); bits < 64 {
+			schema.Maximum = Bound(math.Pow(2, float64(bits)) - 1)
+		}
+		return schema, nil
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}, nil
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}, nil
+	case reflect.String:
+		return String(), nil
+	case reflect.Interface:
+		return &Schema{}, nil
+	case reflect.Pointer:
+		return g.nullable(t.Elem())
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 && !reflect.PointerTo(t.Elem()).Implements(textUnmarshalerType) {
+			return &Schema{Type: "string", Format: "byte", Nullable: true}, nil
+		}
+		items, err := g.schemaOf(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return &Schema{Type: "array", Items: items, Nullable: true}, nil
+	case reflect.Array:
+		items, err := g.schemaOf(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return &Schema{Type: "array", Items: items}, nil
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String && !reflect.PointerTo(t.Key()).Implements(textUnmarshalerType) {
+			return nil, fmt.Errorf("map keys of %s are not strings", t)
+		}
+		values, err := g.schemaOf(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return &Schema{Type: "object", AdditionalProperties: values, Nullable: true}, nil
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.structSchema(t)
+		}
+		return g.component(t)
+	}
+	return nil, fmt.Errorf("%s cannot be encoded as JSON", t)
+}
+
+// nullable returns the schema of t that also accepts null
+func (g *generator) nullable(t reflect.Type) (*Schema, error) {
+	schema, err := g.schemaOf(t)
+	if err != nil || schema.Type == "" && schema.Ref == "" {
+		return schema, err
+	}
+	nullable := *schema
+	nullable.Nullable = true
+	return &nullable, nil
+}
+
+// component returns a reference to the component of a named struct type, generating it on first use
+func (g *generator) component(t reflect.Type) (*Schema, error) {
+	name, ok := g.names[t]
+	if !ok {
+		name = componentName(t)
+		for taken := 0; g.components[name] != nil; taken++ {
+			// Types of different packages sharing a name are told apart by the package
+			pkg := t.PkgPath()[strings.LastIndex(t.PkgPath(), "/")+1:]
+			name = strings.ToUpper(pkg[:1]) + pkg[1:] + componentName(t)
+			if taken > 0 {
+				name = fmt.Sprintf("%s%d", name, taken+1)
+			}
+		}
+		g.names[t] = name
+		// Reserved before the fields are generated, so that recursive types refer to themselves
+		g.components[name] = &Schema{}
+		schema, err := g.structSchema(t)
+		if err != nil {
+			delete(g.components, name)
+			delete(g.names, t)
+			return nil, err
+		}
+		g.components[name] = schema
+	}
+	return &Schema{Ref: "#/components/schemas/" + name}, nil
+}
+
+func componentName(t reflect.Type) string {
+	// Instances of generic types are named like Page[pkg/path.Item]
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return '_'
+	}, t.Name())
+}
+
+// structSchema returns the object a struct is encoded as, fields of embedded structs are promoted
+// unless the struct declares a field of the same name
+func (g *generator) structSchema(t reflect.Type) (*Schema, error) {
+	schema := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	if err := g.addFields(schema, t, map[string]bool{}); err != nil {
+		return nil, err
+	}
+	return schema, nil
+}
+
+func (g *generator) addFields(schema *Schema, t reflect.Type, declared map[string]bool) error {
+	var embedded []reflect.Type
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+		fieldType := field.Type
+		if field.Anonymous && name == "" {
+			if fieldType.Kind() == reflect.Pointer {
+				fieldType = fieldType.Elem()
+			}
+			if fieldType.Kind() == reflect.Struct {
+				embedded = append(embedded, fieldType)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		if declared[name] {
+			continue
+		}
+		var property *Schema
+		var err error
+		if strings.Contains(","+options+",", ",string,") && isScalar(fieldType) {
+			property = String()
+		} else {
+			property, err = g.schemaOf(fieldType)
+		}
+		if err != nil {
+			return fmt.Errorf("field %s of %s: %w", field.Name, t, err)
+		}
+		declared[name] = true
+		schema.Properties[name] = property
+	}
+	for _, embeddedType := range embedded {
+		if err := g.addFields(schema, embeddedType, declared); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func integerFormat(bits int) string {
+	if bits < 32 {
+		return ""
+	}
+	return fmt.Sprintf("int%d", bits)
+}
+
+func isScalar(t reflect.Type) bool {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Bool, reflect.String, reflect.Float32, reflect.Float64,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,306 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// Info describes the API as a whole
+type Info struct {
+	Title       string
+	Version     string
+	Description string
+	// BasePath is the path the route patterns are relative to, e.g. /api/v1
+	BasePath string
+	// Problem is a value of the type error responses are encoded from
+	Problem interface{}
+}
+
+// Operation describes a route of the API
+type Operation struct {
+	// Pattern the route is registered with, e.g. "GET /orgs/{orgName}/agents/{agentId}"
+	Pattern string
+	// ID is the operationId, derived from the pattern when empty
+	ID          string
+	Summary     string
+	Description string
+	// Permission the caller must hold, empty for routes open to everyone
+	Permission string
+	Query      []Parameter
+	// Request is a value of the type the request body is decoded into, nil for routes without a body
+	Request interface{}
+	// MergePatch marks a request body that is a JSON merge patch (RFC 7386) of Request, in which any member may be
+	// null to remove it
+	MergePatch bool
+	// Response is a value of the type the response body is encoded from, nil for responses without a body
+	Response interface{}
+	// Status of successful responses, 200 when zero
+	Status int
+}
+
+// Parameter is a query parameter of an operation
+type Parameter struct {
+	Name        string
+	Description string
+	Required    bool
+	Schema      *Schema
+}
+
+// Spec is the document of an API together with the schemas request bodies are validated against
+type Spec struct {
+	document []byte
+	// Request body schemas by route pattern
+	requests map[string]*Schema
+	// Route patterns whose request bodies are merge patches
+	mergePatches map[string]bool
+	components   map[string]*Schema
+}
+
+var pathParamPattern = regexp.MustCompile(`\{([^}.]+)(\.\.\.)?\}`)
+
+// New generates the document of the operations, it fails on types that cannot be described as JSON
+// and on operations registered twice or sharing an id
+func New(info Info, operations []Operation) (*Spec, error) {
+	g := newGenerator()
+	spec := &Spec{requests: map[string]*Schema{}, mergePatches: map[string]bool{}}
+	paths := map[string]map[string]interface{}{}
+	ids := map[string]string{}
+
+	var problem *Schema
+	if info.Problem != nil {
+		var err error
+		if problem, err = g.schemaOf(reflect.TypeOf(info.Problem)); err != nil {
+			return nil, fmt.Errorf("problem: %w", err)
+		}
+	}
+
+	for _, op := range operations {
+		method, path, ok := strings.Cut(op.Pattern, " ")
+		if !ok || method == "" {
+			return nil, fmt.Errorf("operation %q has no method", op.Pattern)
+		}
+		if _, ok := spec.requests[op.Pattern]; ok {
+			return nil, fmt.Errorf("operation %q is described twice", op.Pattern)
+		}
+		spec.requests[op.Pattern] = nil
+
+		id := op.ID
+		if id == "" {
+			id = operationId(op.Pattern)
+		}
+		if other, ok := ids[id]; ok {
+			return nil, fmt.Errorf("operations %q and %q share the id %q", other, op.Pattern, id)
+		}
+		ids[id] = op.Pattern
+		doc := map[string]interface{}{"operationId": id}
+		if op.Summary != "" {
+			doc["summary"] = op.Summary
+		}
+		if op.Description != "" {
+			doc["description"] = op.Description
+		}
+		if op.Permission != "" {
+			doc["x-required-permission"] = op.Permission
+		}
+
+		var parameters []interface{}
+		for _, match := range pathParamPattern.FindAllStringSubmatch(path, -1) {
+			parameters = append(parameters, map[string]interface{}{
+				"name": match[1], "in": "path", "required": true, "schema": String(),
+			})
+		}
+		for _, param := range op.Query {
+			schema := param.Schema
+			if schema == nil {
+				schema = String()
+			}
+			parameter := map[string]interface{}{"name": param.Name, "in": "query", "schema": schema}
+			if param.Description != "" {
+				parameter["description"] = param.Description
+			}
+			if param.Required {
+				parameter["required"] = true
+			}
+			parameters = append(parameters, parameter)
+		}
+		if len(parameters) > 0 {
+			doc["parameters"] = parameters
+		}
+
+		if op.Request != nil {
+			schema, err := g.schemaOf(reflect.TypeOf(op.Request))
+			if err != nil {
+				return nil, fmt.Errorf("request of %q: %w", op.Pattern, err)
+			}
+			spec.requests[op.Pattern] = schema
+			contentType := "application/json"
+			if op.MergePatch {
+				spec.mergePatches[op.Pattern] = true
+				contentType = "application/merge-patch+json"
+			}
+			doc["requestBody"] = map[string]interface{}{
+				"required": true,
+				"content":  map[string]interface{}{contentType: map[string]interface{}{"schema": schema}},
+			}
+		}
+
+		status := op.Status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		response := map[string]interface{}{"description": http.StatusText(status)}
+		if op.Response != nil {
+			schema, err := g.schemaOf(reflect.TypeOf(op.Response))
+			if err != nil {
+				return nil, fmt.Errorf("response of %q: %w", op.Pattern, err)
+			}
+			response["content"] = map[string]interface{}{"application/json": map[string]interface{}{"schema": schema}}
+		}
+		responses := map[string]interface{}{fmt.Sprint(status): response}
+		if problem != nil {
+			responses["default"] = map[string]interface{}{
+				"description": "Error",
+				"content":     map[string]interface{}{"application/problem+json": map[string]interface{}{"schema": problem}},
+			}
+		}
+		doc["responses"] = responses
+
+		// Wildcards such as {path...} are plain parameters in OpenAPI
+		path = pathParamPattern.ReplaceAllString(path, "{$1}")
+		if paths[path] == nil {
+			paths[path] = map[string]interface{}{}
+		}
+		paths[path][strings.ToLower(method)] = doc
+	}
+
+	document := map[string]interface{}{
+		"openapi": "3.1.0",
+		"info": map[string]interface{}{
+			"title":       info.Title,
+			"version":     info.Version,
+			"description": info.Description,
+		},
+		"paths":      paths,
+		"components": map[string]interface{}{"schemas": g.components},
+	}
+	if info.BasePath != "" {
+		document["servers"] = []interface{}{map[string]string{"url": info.BasePath}}
+	}
+	var err error
+	if spec.document, err = json.Marshal(document); err != nil {
+		return nil, err
+	}
+	spec.components = g.components
+	return spec, nil
+}
+
+var nonAlphanumeric = regexp.MustCompile(`[^A-Za-z0-9]+`)
+
+// operationId derives an id from the pattern of operations without one, e.g. get_orgs_orgName_agents_agentId
+func operationId(pattern string) string {
+	method, path, _ := strings.Cut(pattern, " ")
+	return strings.Trim(nonAlphanumeric.ReplaceAllString(strings.ToLower(method)+"_"+path, "_"), "_")
+}
+
+// ServeHTTP writes the document
+func (s *Spec) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	_, _ = w.Write(s.document)
+}
+
+// ErrNotJSON is returned by Validate for bodies that are not a JSON document
+var ErrNotJSON = errors.New("body is not a JSON document")
+
+// Validate checks the body of a request to the route registered with pattern against the schema of its type.
+// Routes without a request schema accept any body, merge patches may set any member of an object to null
+func (s *Spec) Validate(pattern string, body []byte) ([]Violation, error) {
+	schema := s.requests[pattern]
+	if schema == nil {
+		return nil, nil
+	}
+	value, err := decode(body)
+	if err != nil {
+		return nil, err
+	}
+	v := &validator{components: s.components}
+	if s.mergePatches[pattern] {
+		v.validatePatch(schema, value, location{})
+	} else {
+		v.validate(schema, value, location{})
+	}
+	return v.violations, nil
+}
+
+// Prune drops the fields of a request body to the route registered with pattern that its schema does not know,
+// so that a resource read from the API can be sent back as the body of an update
+func (s *Spec) Prune(pattern string, body map[string]interface{}) {
+	if schema := s.requests[pattern]; schema != nil {
+		s.prune(schema, body)
+	}
+}
+
+func (s *Spec) prune(schema *Schema, value interface{}) {
+	for schema.Ref != "" {
+		schema = s.components[strings.TrimPrefix(schema.Ref, "#/components/schemas/")]
+	}
+	switch value := value.(type) {
+	case map[string]interface{}:
+		if schema.Type != "object" {
+			return
+		}
+		for name, field := range value {
+			property, ok := schema.Properties[name]
+			if !ok {
+				property = schema.AdditionalProperties
+			}
+			if property == nil {
+				delete(value, name)
+				continue
+			}
+			s.prune(property, field)
+		}
+	case []interface{}:
+		if schema.Items != nil {
+			for _, item := range value {
+				s.prune(schema.Items, item)
+			}
+		}
+	}
+}
+
+// decode reads a single JSON document keeping numbers as written
+func decode(body []byte) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, ErrNotJSON
+	}
+	if decoder.More() {
+		return nil, ErrNotJSON
+	}
+	return value, nil
+}
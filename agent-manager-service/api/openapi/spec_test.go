@@ -0,0 +1,272 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package openapi
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type testTool struct {
+	Name    string `json:"name"`
+	Timeout int32  `json:"timeoutSeconds,omitempty"`
+}
+
+type testBase struct {
+	Description *string `json:"description"`
+}
+
+type testRequest struct {
+	testBase
+	Name      string            `json:"name"`
+	Tools     []testTool        `json:"tools"`
+	Labels    map[string]string `json:"labels,omitempty"`
+	ExpiresAt *time.Time        `json:"expiresAt"`
+	Owner     uuid.UUID         `json:"owner"`
+	Count     int64             `json:"count,string"`
+	Internal  string            `json:"-"`
+}
+
+const testPattern = "PUT /orgs/{orgName}/items/{path...}"
+
+func newTestSpec(t *testing.T) *Spec {
+	t.Helper()
+	spec, err := New(Info{Title: "Test", Version: "1"}, []Operation{
+		{Pattern: testPattern, Permission: "item:write", Request: testRequest{}, Response: testTool{}},
+		{Pattern: "GET /orgs/{orgName}/items", Query: []Parameter{{Name: "limit", Schema: Integer()}}},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	return spec
+}
+
+func TestDocumentDescribesOperations(t *testing.T) {
+	var doc struct {
+		OpenAPI    string                                       `json:"openapi"`
+		Paths      map[string]map[string]map[string]interface{} `json:"paths"`
+		Components struct {
+			Schemas map[string]map[string]interface{} `json:"schemas"`
+		} `json:"components"`
+	}
+	if err := json.Unmarshal(newTestSpec(t).document, &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc.OpenAPI != "3.1.0" {
+		t.Errorf("openapi = %q, want 3.1.0", doc.OpenAPI)
+	}
+	put := doc.Paths["/orgs/{orgName}/items/{path}"]["put"]
+	if put == nil {
+		t.Fatalf("paths = %v, want the wildcard as a plain parameter", doc.Paths)
+	}
+	if put["operationId"] != "put_orgs_orgName_items_path" || put["x-required-permission"] != "item:write" {
+		t.Errorf("operation = %v", put)
+	}
+	if doc.Paths["/orgs/{orgName}/items"]["get"] == nil {
+		t.Errorf("paths = %v, want the list operation", doc.Paths)
+	}
+
+	request := doc.Components.Schemas["testRequest"]
+	if request["additionalProperties"] != false {
+		t.Errorf("additionalProperties = %v, want false", request["additionalProperties"])
+	}
+	properties, _ := request["properties"].(map[string]interface{})
+	want := map[string]interface{}{
+		"description": map[string]interface{}{"type": []interface{}{"string", "null"}},
+		"expiresAt":   map[string]interface{}{"type": []interface{}{"string", "null"}, "format": "date-time"},
+		"owner":       map[string]interface{}{"type": "string", "format": "uuid"},
+		"count":       map[string]interface{}{"type": "string"},
+		"tools": map[string]interface{}{
+			"type":  []interface{}{"array", "null"},
+			"items": map[string]interface{}{"$ref": "#/components/schemas/testTool"},
+		},
+		"labels": map[string]interface{}{
+			"type":                 []interface{}{"object", "null"},
+			"additionalProperties": map[string]interface{}{"type": "string"},
+		},
+	}
+	for name, schema := range want {
+		if !reflect.DeepEqual(properties[name], schema) {
+			t.Errorf("property %s = %v, want %v", name, properties[name], schema)
+		}
+	}
+	if _, ok := properties["Internal"]; ok {
+		t.Error("fields ignored by encoding/json should not be described")
+	}
+}
+
+func TestValidateReportsEachViolation(t *testing.T) {
+	spec := newTestSpec(t)
+	tests := []struct {
+		name string
+		body string
+		want []Violation
+	}{
+		{
+			name: "valid",
+			body: `{"name":"a","description":null,"tools":[{"name":"t","timeoutSeconds":5}],"labels":{"a":"b"},
+				"expiresAt":"2026-01-02T03:04:05Z","owner":"6f1c2d9e-8a47-4a53-9f0e-2b6d7c1e4a10","count":"3"}`,
+		},
+		{
+			name: "unknown fields",
+			body: `{"name":"a","extra":1,"Internal":"x","tools":[{"name":"t","retries":2}]}`,
+			want: []Violation{
+				{Field: "Internal", Pointer: "/Internal", Constraint: "additionalProperties", Message: "is not a known field"},
+				{Field: "extra", Pointer: "/extra", Constraint: "additionalProperties", Message: "is not a known field"},
+				{Field: "tools[0].retries", Pointer: "/tools/0/retries", Constraint: "additionalProperties", Message: "is not a known field"},
+			},
+		},
+		{
+			name: "wrong types",
+			body: `{"name":1,"tools":[{"name":"t"},{"name":true,"timeoutSeconds":3000000000}],"labels":{"a":2},"count":3}`,
+			want: []Violation{
+				{Field: "count", Pointer: "/count", Constraint: "type", Message: "must be of type string"},
+				{Field: "labels.a", Pointer: "/labels/a", Constraint: "type", Message: "must be of type string"},
+				{Field: "name", Pointer: "/name", Constraint: "type", Message: "must be of type string"},
+				{Field: "tools[1].name", Pointer: "/tools/1/name", Constraint: "type", Message: "must be of type string"},
+				{Field: "tools[1].timeoutSeconds", Pointer: "/tools/1/timeoutSeconds", Constraint: "maximum", Message: "must be between -2147483648 and 2147483647"},
+			},
+		},
+		{
+			name: "nulls",
+			body: `{"name":null,"description":null,"tools":null,"expiresAt":null}`,
+			want: []Violation{{Field: "name", Pointer: "/name", Constraint: "type", Message: "must not be null"}},
+		},
+		{
+			name: "formats",
+			body: `{"expiresAt":"tomorrow","owner":"me","tools":[{"timeoutSeconds":1.5}]}`,
+			want: []Violation{
+				{Field: "expiresAt", Pointer: "/expiresAt", Constraint: "format", Message: "must be a date-time in RFC 3339 format"},
+				{Field: "owner", Pointer: "/owner", Constraint: "format", Message: "must be a UUID"},
+				{Field: "tools[0].timeoutSeconds", Pointer: "/tools/0/timeoutSeconds", Constraint: "type", Message: "must be of type integer"},
+			},
+		},
+		{
+			name: "escaped keys",
+			body: `{"labels":{"team/a~b":1}}`,
+			want: []Violation{{Field: "labels.team/a~b", Pointer: "/labels/team~1a~0b", Constraint: "type", Message: "must be of type string"}},
+		},
+		{
+			name: "not an object",
+			body: `[1]`,
+			want: []Violation{{Field: "", Pointer: "", Constraint: "type", Message: "must be of type object"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := spec.Validate(testPattern, []byte(tt.body))
+			if err != nil {
+				t.Fatalf("Validate() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Validate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateLeavesOtherBodiesToTheHandler(t *testing.T) {
+	spec := newTestSpec(t)
+	if _, err := spec.Validate(testPattern, []byte(`{"name":"a"} {}`)); !errors.Is(err, ErrNotJSON) {
+		t.Errorf("Validate() error = %v, want ErrNotJSON", err)
+	}
+	if got, err := spec.Validate("GET /orgs/{orgName}/items", []byte(`{"any":1}`)); got != nil || err != nil {
+		t.Errorf("Validate() = %v, %v, want routes without a request schema to accept any body", got, err)
+	}
+}
+
+func TestValidateAllowsNullsInMergePatches(t *testing.T) {
+	const patchPattern = "PATCH /orgs/{orgName}/items/{path...}"
+	spec, err := New(Info{Title: "Test", Version: "1"}, []Operation{
+		{Pattern: patchPattern, Request: testRequest{}, MergePatch: true},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	body := `{"name":null,"labels":{"a":null,"b":2},"tools":[{"name":null}],"extra":null}`
+	got, err := spec.Validate(patchPattern, []byte(body))
+	if err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	// Members of objects may be removed, items of arrays replace the whole array and are checked as in a PUT
+	want := []Violation{
+		{Field: "extra", Pointer: "/extra", Constraint: "additionalProperties", Message: "is not a known field"},
+		{Field: "labels.b", Pointer: "/labels/b", Constraint: "type", Message: "must be of type string"},
+		{Field: "tools[0].name", Pointer: "/tools/0/name", Constraint: "type", Message: "must not be null"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Validate() = %v, want %v", got, want)
+	}
+
+	var doc struct {
+		Paths map[string]map[string]struct {
+			RequestBody struct {
+				Content map[string]interface{} `json:"content"`
+			} `json:"requestBody"`
+		} `json:"paths"`
+	}
+	if err := json.Unmarshal(spec.document, &doc); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := doc.Paths["/orgs/{orgName}/items/{path}"]["patch"].RequestBody.Content["application/merge-patch+json"]; !ok {
+		t.Errorf("document = %s, want the body described as a merge patch", spec.document)
+	}
+}
+
+func TestPruneKeepsWritableFields(t *testing.T) {
+	spec := newTestSpec(t)
+	body := map[string]interface{}{
+		"id":     "1",
+		"name":   "a",
+		"tools":  []interface{}{map[string]interface{}{"name": "t", "createdAt": "2026-01-02T03:04:05Z"}},
+		"labels": map[string]interface{}{"team": "x"},
+	}
+	spec.Prune(testPattern, body)
+	want := map[string]interface{}{
+		"name":   "a",
+		"tools":  []interface{}{map[string]interface{}{"name": "t"}},
+		"labels": map[string]interface{}{"team": "x"},
+	}
+	if !reflect.DeepEqual(body, want) {
+		t.Errorf("Prune() = %v, want %v", body, want)
+	}
+}
+
+func TestNewRejectsAmbiguousOperations(t *testing.T) {
+	tests := []struct {
+		name       string
+		operations []Operation
+	}{
+		{name: "no method", operations: []Operation{{Pattern: "/items"}}},
+		{name: "same pattern", operations: []Operation{{Pattern: "GET /items"}, {Pattern: "GET /items"}}},
+		{name: "same id", operations: []Operation{{Pattern: "GET /items", ID: "list"}, {Pattern: "GET /things", ID: "list"}}},
+		{name: "map keys", operations: []Operation{{Pattern: "POST /items", Request: map[int]string{}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := New(Info{}, tt.operations); err == nil {
+				t.Error("New() error = nil, want an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,241 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package openapi
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// maxViolations bounds the violations reported for a single body
+const maxViolations = 50
+
+// Violation is a value of a request body that does not match its schema
+type Violation struct {
+	// Path of the value, e.g. modelConfig.temperature or tools[1].name, empty for the body itself
+	Field string
+	// RFC 6901 JSON pointer of the value, e.g. /modelConfig/temperature or /tools/1/name
+	Pointer string
+	// Schema keyword the value failed, e.g. type or additionalProperties
+	Constraint string
+	Message    string
+}
+
+// location is the path of a value in the body in both the forms of a Violation
+type location struct {
+	field   string
+	pointer string
+}
+
+func (l location) property(name string) location {
+	field := name
+	if l.field != "" {
+		field = l.field + "." + name
+	}
+	return location{field: field, pointer: l.pointer + "/" + pointerEscaper.Replace(name)}
+}
+
+func (l location) index(i int) location {
+	return location{field: fmt.Sprintf("%s[%d]", l.field, i), pointer: l.pointer + "/" + strconv.Itoa(i)}
+}
+
+var pointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+
+type validator struct {
+	components map[string]*Schema
+	violations []Violation
+}
+
+// validate checks a value decoded with json.Decoder.UseNumber against schema
+func (v *validator) validate(schema *Schema, value interface{}, at location) {
+	if len(v.violations) >= maxViolations {
+		return
+	}
+	if schema.Ref != "" {
+		if value == nil && schema.Nullable {
+			return
+		}
+		v.validate(v.components[strings.TrimPrefix(schema.Ref, "#/components/schemas/")], value, at)
+		return
+	}
+	if schema.Type == "" {
+		return
+	}
+	if value == nil {
+		if !schema.Nullable {
+			v.add(at, "type", "must not be null")
+		}
+		return
+	}
+
+	switch schema.Type {
+	case "object":
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			v.add(at, "type", "must be of type object")
+			return
+		}
+		names := make([]string, 0, len(object))
+		for name := range object {
+			names = append(names, name)
+		}
+		// Violations are reported in a stable order
+		sort.Strings(names)
+		for _, name := range names {
+			property, ok := schema.Properties[name]
+			if !ok {
+				property = schema.AdditionalProperties
+			}
+			if property == nil {
+				v.add(at.property(name), "additionalProperties", "is not a known field")
+				continue
+			}
+			v.validate(property, object[name], at.property(name))
+		}
+	case "array":
+		items, ok := value.([]interface{})
+		if !ok {
+			v.add(at, "type", "must be of type array")
+			return
+		}
+		for i, item := range items {
+			v.validate(schema.Items, item, at.index(i))
+		}
+	case "string":
+		text, ok := value.(string)
+		if !ok {
+			v.add(at, "type", "must be of type string")
+			return
+		}
+		v.validateString(schema, text, at)
+	case "integer":
+		number, ok := value.(json.Number)
+		if !ok {
+			v.add(at, "type", "must be of type integer")
+			return
+		}
+		integer, ok := new(big.Int).SetString(number.String(), 10)
+		if !ok {
+			v.add(at, "type", "must be of type integer")
+			return
+		}
+		v.validateRange(schema, new(big.Float).SetInt(integer), at)
+	case "number":
+		number, ok := value.(json.Number)
+		if !ok {
+			v.add(at, "type", "must be of type number")
+			return
+		}
+		if _, err := strconv.ParseFloat(number.String(), 64); err != nil {
+			v.add(at, "type", "must be a number within the range of a double")
+			return
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			v.add(at, "type", "must be of type boolean")
+		}
+	}
+}
+
+// validatePatch checks a merge patch against the schema of the value it patches. Members of objects may be null,
+// which removes them, anything else is a replacement checked with validate
+func (v *validator) validatePatch(schema *Schema, value interface{}, at location) {
+	for schema.Ref != "" {
+		schema = v.components[strings.TrimPrefix(schema.Ref, "#/components/schemas/")]
+	}
+	object, ok := value.(map[string]interface{})
+	if !ok || schema.Type != "object" {
+		v.validate(schema, value, at)
+		return
+	}
+	names := make([]string, 0, len(object))
+	for name := range object {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		property, ok := schema.Properties[name]
+		if !ok {
+			property = schema.AdditionalProperties
+		}
+		if property == nil {
+			v.add(at.property(name), "additionalProperties", "is not a known field")
+			continue
+		}
+		if object[name] != nil {
+			v.validatePatch(property, object[name], at.property(name))
+		}
+	}
+}
+
+func (v *validator) validateString(schema *Schema, text string, at location) {
+	if len(schema.Enum) > 0 {
+		for _, allowed := range schema.Enum {
+			if text == allowed {
+				return
+			}
+		}
+		v.add(at, "enum", "must be one of "+strings.Join(schema.Enum, ", "))
+		return
+	}
+	switch schema.Format {
+	case "date-time":
+		if _, err := time.Parse(time.RFC3339, text); err != nil {
+			v.add(at, "format", "must be a date-time in RFC 3339 format")
+		}
+	case "uuid":
+		if _, err := uuid.Parse(text); err != nil {
+			v.add(at, "format", "must be a UUID")
+		}
+	case "byte":
+		if _, err := base64.StdEncoding.DecodeString(text); err != nil {
+			v.add(at, "format", "must be base64 encoded")
+		}
+	}
+}
+
+func (v *validator) validateRange(schema *Schema, number *big.Float, at location) {
+	below := schema.Minimum != nil && number.Cmp(big.NewFloat(*schema.Minimum)) < 0
+	above := schema.Maximum != nil && number.Cmp(big.NewFloat(*schema.Maximum)) > 0
+	switch {
+	case !below && !above:
+	case schema.Minimum != nil && schema.Maximum != nil:
+		keyword := "maximum"
+		if below {
+			keyword = "minimum"
+		}
+		v.add(at, keyword, fmt.Sprintf("must be between %.0f and %.0f", *schema.Minimum, *schema.Maximum))
+	case below:
+		v.add(at, "minimum", fmt.Sprintf("must be at least %.0f", *schema.Minimum))
+	default:
+		v.add(at, "maximum", fmt.Sprintf("must be at most %.0f", *schema.Maximum))
+	}
+}
+
+func (v *validator) add(at location, constraint string, message string) {
+	if len(v.violations) < maxViolations {
+		v.violations = append(v.violations, Violation{Field: at.field, Pointer: at.pointer, Constraint: constraint, Message: message})
+	}
+}
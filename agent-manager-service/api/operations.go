@@ -0,0 +1,992 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/api/openapi"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/middleware"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/models"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/spec"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/utils"
+)
+
+const apiDescription = "Every operation requires the permission named by its x-required-permission. Tokens are granted the " +
+	"permissions of the roles in their roles claim, admin holds all of them, editor all but projects:write and viewer the " +
+	"read permissions. Only admin holds trash:manage, which lists and restores deleted resources, and environments:manage, " +
+	"which creates the environments managed agents are deployed to, credentials:rotate, which re-encrypts credentials with " +
+	"the active master key, and webhooks:manage, which subscribes URLs to agent events. Tokens without a roles claim assume " +
+	"the configured default roles. API keys hold the permissions of their scopes. Requests lacking the permission are " +
+	"rejected with 403 naming it. Requests under /orgs/{orgName} only reach data of that organization. Organizations the " +
+	"caller does not own, or other than the one in the org claim of the token or of an API key, are answered with 404 as " +
+	"are ids of resources that belong to another organization. Each client, identified by its API key, else the subject of " +
+	"its token, else its address, is granted a token bucket. Requests beyond it are answered with 429 and a Retry-After " +
+	"header giving the seconds to wait. API keys can carry a rate limit of their own in place of the server wide one. " +
+	"Every response carries an x-correlation-id header, the id sent by the client in the same header when it is made of at " +
+	"most 128 letters, digits, '-', '_', '.' and ':', otherwise a generated one. Quote it when reporting a failed request. " +
+	"Request bodies above the configured size are rejected with 413 and requests taking longer than the configured timeout " +
+	"are answered with 503, their work is cancelled. Individual routes can be configured with other limits. Request bodies " +
+	"are validated against the schemas of this document, fields it does not list and values of the wrong type are " +
+	"rejected with 400 naming each of them in errors. Responses are compressed with gzip or deflate when the client sends " +
+	"a matching Accept-Encoding header. POST, PUT, PATCH and DELETE requests can carry an Idempotency-Key header of at " +
+	"most 255 characters to be retried safely. The status and body of the first execution are kept for the configured " +
+	"time and a retry with the same key, route and body gets them back with an Idempotent-Replayed header instead of " +
+	"running again. Retries while the first execution runs are answered with 409 and Retry-After, a key reused with a " +
+	"different body with 422. Server errors are not kept, a retry after one runs the request again. Successful POST, PUT, " +
+	"PATCH and DELETE requests under /orgs/{orgName} are recorded in an audit log holding the caller, the resource, the " +
+	"action and, for updates, the changed fields with secrets redacted."
+
+var (
+	limitParam = openapi.Parameter{
+		Name:        "limit",
+		Description: "Maximum number of results to return",
+		Schema:      openapi.Integer().WithRange(openapi.Bound(1), openapi.Bound(utils.MaxLimit)).WithDefault(utils.DefaultLimit),
+	}
+	offsetParam = openapi.Parameter{
+		Name:        "offset",
+		Description: "Number of results to skip",
+		Schema:      openapi.Integer().WithRange(openapi.Bound(0), nil).WithDefault(0),
+	}
+	sortParam = openapi.Parameter{
+		Name:        "sort",
+		Description: "Field the results are sorted by, ties are broken by id",
+		Schema:      openapi.Enum(models.ListSortName, models.ListSortCreatedAt, models.ListSortUpdatedAt).WithDefault(models.ListSortName),
+	}
+	sortOrderParam = openapi.Parameter{
+		Name:        "sortOrder",
+		Description: "Order of the sort",
+		Schema:      openapi.Enum(models.ListOrderAsc, models.ListOrderDesc).WithDefault(models.ListOrderAsc),
+	}
+	cursorParam = openapi.Parameter{
+		Name:        "cursor",
+		Description: "nextCursor of the previous page, continues the listing after the last result of that page so results created or deleted meanwhile are neither skipped nor repeated. It must be sent with the sort and sortOrder of the previous page and takes precedence over offset",
+		Schema:      openapi.String(),
+	}
+	countParam = openapi.Parameter{
+		Name:        "count",
+		Description: "Also returns the number of results of the whole listing in total, which takes a query of its own",
+		Schema:      openapi.Boolean().WithDefault(false),
+	}
+)
+
+// operations describes the routes registered through the authorizer with the types their handlers decode
+// request bodies into and encode responses from. The schemas of the served document and the validation of
+// request bodies are both generated from these types
+var operations = []openapi.Operation{
+	// Agents built and deployed through OpenChoreo
+	{
+		Pattern:  "POST /orgs/{orgName}/projects/{projName}/agents",
+		ID:       "createAgent",
+		Summary:  "Create a new agent",
+		Request:  spec.CreateAgentRequest{},
+		Response: spec.AgentResponse{},
+		Status:   http.StatusAccepted,
+	},
+	{
+		Pattern: "GET /orgs/{orgName}/projects/{projName}/agents",
+		ID:      "listAgents",
+		Summary: "List all agents in a project of an organization",
+		Query: []openapi.Parameter{
+			limitParam,
+			offsetParam,
+		},
+		Response: spec.AgentListResponse{},
+	},
+	{
+		Pattern:     "POST /orgs/{orgName}/utils/generate-name",
+		ID:          "getNameByDisplayName",
+		Summary:     "Get name by display name",
+		Description: "Retrieve name using display name for a specific resource",
+		Request:     spec.ResourceNameRequest{},
+		Response:    spec.ResourceNameResponse{},
+	},
+	{
+		Pattern:  "GET /orgs/{orgName}/projects/{projName}/agents/{agentName}",
+		ID:       "getAgent",
+		Summary:  "Get agent details",
+		Response: spec.AgentResponse{},
+	},
+	{
+		Pattern: "DELETE /orgs/{orgName}/projects/{projName}/agents/{agentName}",
+		ID:      "deleteAgent",
+		Summary: "Delete agent",
+		Status:  http.StatusNoContent,
+	},
+	{
+		Pattern: "POST /orgs/{orgName}/projects/{projName}/agents/{agentName}/builds",
+		ID:      "buildAgent",
+		Summary: "Build an agent",
+		Query: []openapi.Parameter{
+			{Name: "commitId", Schema: openapi.String()},
+		},
+		Response: models.BuildResponse{},
+		Status:   http.StatusAccepted,
+	},
+	{
+		Pattern: "GET /orgs/{orgName}/projects/{projName}/agents/{agentName}/builds",
+		ID:      "getAgentBuilds",
+		Summary: "Get Builds of an Agent",
+		Query: []openapi.Parameter{
+			limitParam,
+			offsetParam,
+		},
+		Response: spec.BuildsListResponse{},
+	},
+	{
+		Pattern:  "GET /orgs/{orgName}/projects/{projName}/agents/{agentName}/builds/{buildName}",
+		ID:       "getBuild",
+		Summary:  "Get build details",
+		Response: spec.BuildDetailsResponse{},
+	},
+	{
+		Pattern:  "GET /orgs/{orgName}/projects/{projName}/agents/{agentName}/builds/{buildName}/build-logs",
+		ID:       "getBuildLogs",
+		Summary:  "Get build logs",
+		Response: spec.BuildLogsResponse{},
+	},
+	{
+		Pattern:  "POST /orgs/{orgName}/projects/{projName}/agents/{agentName}/deployments",
+		ID:       "deployAgent",
+		Summary:  "Deploy an agent",
+		Request:  spec.DeployAgentRequest{},
+		Response: spec.DeploymentResponse{},
+		Status:   http.StatusAccepted,
+	},
+	{
+		Pattern:     "GET /orgs/{orgName}/projects/{projName}/agents/{agentName}/deployments",
+		ID:          "listAgentDeployments",
+		Summary:     "List agent deployments with detailed information",
+		Description: "Retrieves detailed deployment information for a specific agent across all environments",
+		Response:    map[string]spec.DeploymentDetailsResponse{},
+	},
+	{
+		Pattern:     "GET /orgs/{orgName}/projects/{projName}/agents/{agentName}/endpoints",
+		ID:          "getAgentEndpoints",
+		Summary:     "Get agent endpoints for a specific environment",
+		Description: "Retrieves endpoint configurations for an agent in a specific environment",
+		Query: []openapi.Parameter{
+			{Name: "environment", Description: "Environment name", Required: true, Schema: openapi.String()},
+		},
+		Response: map[string]spec.EndpointConfiguration{},
+	},
+	{
+		Pattern:     "GET /orgs/{orgName}/projects/{projName}/agents/{agentName}/configurations",
+		ID:          "getAgentConfigurations",
+		Summary:     "Get agent configurations for a specific environment",
+		Description: "Retrieves configuration settings for an agent in a specific environment",
+		Query: []openapi.Parameter{
+			{Name: "environment", Description: "Environment name", Required: true, Schema: openapi.String()},
+		},
+		Response: spec.ConfigurationResponse{},
+	},
+	// Organizations, projects and their environments
+	{
+		Pattern: "GET /orgs",
+		ID:      "listOrganizations",
+		Summary: "List all organizations",
+		Query: []openapi.Parameter{
+			limitParam,
+			offsetParam,
+		},
+		Response: spec.OrganizationListResponse{},
+	},
+	{
+		Pattern:  "GET /orgs/{orgName}",
+		ID:       "getOrganization",
+		Summary:  "Get organization details",
+		Response: spec.OrganizationResponse{},
+	},
+	{
+		Pattern:     "GET /orgs/{orgName}/data-planes",
+		ID:          "listDataPlanes",
+		Summary:     "List all data planes in an organization",
+		Description: "Retrieves all data planes available in the specified organization",
+		Response:    []spec.DataPlane{},
+	},
+	{
+		Pattern: "GET /orgs/{orgName}/deployment-pipelines",
+		ID:      "listDeploymentPipelines",
+		Summary: "List all deployment pipelines in an organization",
+		Query: []openapi.Parameter{
+			limitParam,
+			offsetParam,
+		},
+		Response: spec.DeploymentPipelineListResponse{},
+	},
+	{
+		Pattern:     "GET /orgs/{orgName}/environments",
+		ID:          "listEnvironments",
+		Summary:     "List all environments in an organization",
+		Description: "Retrieves all environments available in the specified organization",
+		Response:    []spec.Environment{},
+	},
+	{
+		Pattern: "GET /orgs/{orgName}/projects",
+		ID:      "listProjects",
+		Summary: "List all projects in an organization",
+		Query: []openapi.Parameter{
+			limitParam,
+			offsetParam,
+		},
+		Response: spec.ProjectListResponse{},
+	},
+	{
+		Pattern:  "POST /orgs/{orgName}/projects",
+		ID:       "createProject",
+		Summary:  "Create a new project",
+		Request:  spec.CreateProjectRequest{},
+		Response: spec.ProjectResponse{},
+		Status:   http.StatusAccepted,
+	},
+	{
+		Pattern:  "GET /orgs/{orgName}/projects/{projName}",
+		ID:       "getProject",
+		Summary:  "Get project details",
+		Response: spec.ProjectResponse{},
+	},
+	{
+		Pattern:     "GET /orgs/{orgName}/projects/{projName}/deployment-pipeline",
+		ID:          "getDeploymentPipeline",
+		Summary:     "Get deployment pipeline for a project",
+		Description: "Retrieves the deployment pipeline configuration for the specified project within an organization",
+		Response:    spec.DeploymentPipelineResponse{},
+	},
+	{
+		Pattern: "DELETE /orgs/{orgName}/projects/{projName}",
+		ID:      "deleteProject",
+		Summary: "Delete a project",
+		Status:  http.StatusNoContent,
+	},
+	// Managed agents
+	{
+		Pattern:     "POST /orgs/{orgName}/agents",
+		ID:          "createManagedAgent",
+		Summary:     "Create a managed agent",
+		Description: "Creates an agent record holding the framework, model configuration, system prompt, tools and labels of an agent. Names are unique within the organization.",
+		Request:     models.ManagedAgentRequest{},
+		Response:    models.ManagedAgentResponse{},
+		Status:      http.StatusCreated,
+	},
+	{
+		Pattern: "GET /orgs/{orgName}/agents",
+		ID:      "listManagedAgents",
+		Summary: "List managed agents",
+		Query: []openapi.Parameter{
+			{Name: "search", Description: "Case-insensitive substring of the agent name", Schema: openapi.String()},
+			{Name: "labelSelector", Description: "Comma separated label requirements the agents must all meet: key=value, key!=value, key in (v1,v2), key notin (v1,v2), key to require a label and !key to exclude it. key!=value and notin also match agents without the label", Schema: openapi.String()},
+			{Name: "includeDeleted", Description: "Also lists deleted agents that are not yet purged, requires trash:manage", Schema: openapi.Boolean().WithDefault(false)},
+			limitParam,
+			offsetParam,
+			sortParam,
+			sortOrderParam,
+			cursorParam,
+			countParam,
+		},
+		Response: models.ManagedAgentListResponse{},
+	},
+	{
+		Pattern: "GET /orgs/{orgName}/agents/{agentId}",
+		ID:      "getManagedAgent",
+		Summary: "Get a managed agent",
+		Query: []openapi.Parameter{
+			{Name: "includeDeleted", Description: "Also returns the agent when it is deleted but not yet purged, so references from traces still resolve", Schema: openapi.Boolean().WithDefault(false)},
+		},
+		Response: models.ManagedAgentResponse{},
+	},
+	{
+		Pattern:     "PUT /orgs/{orgName}/agents/{agentId}",
+		ID:          "updateManagedAgent",
+		Summary:     "Replace a managed agent",
+		Description: "Replaces the agent and increments its version. The update only succeeds if the agent is still at the version in If-Match or in the version field of the body, \"*\" lets the last write win. A stale update is rejected with the current agent, with 412 for If-Match and 409 for the body version.",
+		Request:     models.ManagedAgentRequest{},
+		Response:    models.ManagedAgentResponse{},
+	},
+	{
+		Pattern:     "PATCH /orgs/{orgName}/agents/{agentId}",
+		ID:          "patchManagedAgent",
+		Summary:     "Patch a managed agent",
+		Description: "Changes some fields of the agent and increments its version. The body is a JSON merge patch (RFC 7386) validated as the result of applying it: members replace those of the agent, objects such as labels and modelConfig are merged key by key, null removes a member and arrays such as tools are replaced as a whole. Removing enabled or retentionDays keeps their current value, as omitting them from a replace does. When If-Match or version is sent the patch only succeeds if the agent is still at that version, otherwise it applies to the current version. A stale patch is rejected with the current agent, with 412 for If-Match and 409 for the body version.",
+		Request:     models.ManagedAgentRequest{},
+		MergePatch:  true,
+		Response:    models.ManagedAgentResponse{},
+	},
+	{
+		Pattern:     "DELETE /orgs/{orgName}/agents/{agentId}",
+		ID:          "deleteManagedAgent",
+		Summary:     "Delete a managed agent",
+		Description: "The agent is kept for the configured retention, during which it can be restored, and then purged. Its name can be reused right away.",
+		Status:      http.StatusNoContent,
+	},
+	{
+		Pattern:     "POST /orgs/{orgName}/agents/{agentId}/restore",
+		ID:          "restoreManagedAgent",
+		Summary:     "Restore a deleted managed agent",
+		Description: "Undoes the deletion of an agent that is not yet purged, keeping its version.",
+		Response:    models.ManagedAgentResponse{},
+	},
+	{
+		Pattern:     "GET /orgs/{orgName}/agents/{agentId}/versions",
+		ID:          "listManagedAgentVersions",
+		Summary:     "List the configuration versions of a managed agent",
+		Description: "Versions are returned newest first. Every create, update and rollback records a version.",
+		Query: []openapi.Parameter{
+			limitParam,
+			offsetParam,
+		},
+		Response: models.ManagedAgentVersionListResponse{},
+	},
+	{
+		Pattern:  "GET /orgs/{orgName}/agents/{agentId}/versions/{version}",
+		ID:       "getManagedAgentVersion",
+		Summary:  "Get a configuration version of a managed agent",
+		Response: models.ManagedAgentVersionResponse{},
+	},
+	{
+		Pattern:     "GET /orgs/{orgName}/agents/{agentId}/versions/{version}/diff/{otherVersion}",
+		ID:          "diffManagedAgentVersions",
+		Summary:     "Compare two configuration versions of a managed agent",
+		Description: "Lists the fields that differ going from version to otherVersion.",
+		Response:    models.ManagedAgentVersionDiffResponse{},
+	},
+	{
+		Pattern:     "GET /orgs/{orgName}/agents/{agentId}/tools",
+		ID:          "getManagedAgentTools",
+		Summary:     "Resolve the tools of a managed agent",
+		Description: "Registered tools referenced by toolId are resolved into tool definitions with their parameters schema, tools declared by name only carry just the name.",
+		Response:    models.AgentToolsResponse{},
+	},
+	{
+		Pattern:     "POST /orgs/{orgName}/agents/{agentId}/rollback",
+		ID:          "rollbackManagedAgent",
+		Summary:     "Roll back a managed agent to an earlier version",
+		Description: "Creates a new version with the configuration of the given version and makes it active. When If-Match is sent the rollback only succeeds if the agent is still at that version.",
+		Query: []openapi.Parameter{
+			{Name: "to", Description: "Version to restore", Required: true, Schema: openapi.Integer().WithRange(openapi.Bound(1), nil)},
+		},
+		Response: models.ManagedAgentResponse{},
+	},
+	{
+		Pattern:     "GET /orgs/{orgName}/agents/{agentId}/export",
+		ID:          "exportManagedAgent",
+		Summary:     "Export a managed agent as a portable bundle",
+		Description: "Returns the agent configuration together with the prompt version and the registered tools it references, which refer to each other by name so the bundle can be imported into another organization or environment. Exporting an agent that uses a prompt also requires prompts:read.",
+		Response:    models.AgentBundle{},
+	},
+	{
+		Pattern:     "POST /orgs/{orgName}/agents/import",
+		ID:          "importManagedAgent",
+		Summary:     "Import a managed agent from a bundle",
+		Description: "Creates the agent of the bundle, or updates the agent of the same name, in a single transaction. Prompts and tools are matched by name. A missing prompt or tool is created, a prompt whose template differs gets a new version and a registered tool with a different definition is a conflict, as other agents may use it. Importing a bundle that includes a prompt also requires prompts:write and one that includes tools requires tools:write. Bundles of every schema version up to the current one are accepted.",
+		Query: []openapi.Parameter{
+			{Name: "dryRun", Description: "Return the planned changes and conflicts without applying them", Schema: openapi.Boolean().WithDefault(false)},
+		},
+		Request:  models.AgentBundle{},
+		Response: models.AgentImportResponse{},
+	},
+	{
+		Pattern:     "POST /orgs/{orgName}/agents:batchUpdate",
+		ID:          "batchUpdateManagedAgents",
+		Summary:     "Apply operations to many managed agents",
+		Description: "Applies each operation in a transaction of its own, so a failed operation leaves its agent as it was and does not affect the others. Operations run concurrently and an agent can only be changed by one operation of a batch. Updates record a new version of the agent unless it already is in the requested state. The response reports the outcome of every operation in the order of the request, a dry run tries every operation and rolls it back. The number of operations is limited by AGENT_BATCH_MAX_OPERATIONS.",
+		Request:     models.AgentBatchRequest{},
+		Response:    models.AgentBatchResponse{},
+	},
+	{
+		Pattern:     "GET /frameworks",
+		ID:          "listAgentFrameworks",
+		Summary:     "List the supported agent frameworks",
+		Description: "Lists the frameworks managed agents may declare with every version of the JSON Schema their modelConfig is validated against, e.g. to render configuration forms",
+		Response:    models.AgentFrameworkListResponse{},
+	},
+	// Agent environments and deployments
+	{
+		Pattern:     "POST /orgs/{orgName}/agent-environments",
+		ID:          "createAgentEnvironment",
+		Summary:     "Create an agent environment",
+		Description: "Environments such as dev, staging and prod that managed agents are deployed to. They are separate from the OpenChoreo environments listed under /orgs/{orgName}/environments.",
+		Request:     models.AgentEnvironmentRequest{},
+		Response:    models.AgentEnvironmentResponse{},
+		Status:      http.StatusCreated,
+	},
+	{
+		Pattern:  "GET /orgs/{orgName}/agent-environments",
+		ID:       "listAgentEnvironments",
+		Summary:  "List agent environments",
+		Response: models.AgentEnvironmentListResponse{},
+	},
+	{
+		Pattern:  "GET /orgs/{orgName}/agent-environments/{envName}",
+		ID:       "getAgentEnvironment",
+		Summary:  "Get an agent environment",
+		Response: models.AgentEnvironmentResponse{},
+	},
+	{
+		Pattern:     "GET /orgs/{orgName}/agent-environments/{envName}/agents",
+		ID:          "listEnvironmentAgents",
+		Summary:     "List the agents deployed to an environment",
+		Description: "Agents are returned by name with the deployment they currently run, deleted agents are left out.",
+		Query: []openapi.Parameter{
+			limitParam,
+			offsetParam,
+		},
+		Response: models.EnvironmentAgentListResponse{},
+	},
+	{
+		Pattern:     "GET /orgs/{orgName}/agent-environments/{envName}/agents/{agentId}",
+		ID:          "getResolvedAgentConfig",
+		Summary:     "Get the resolved configuration of an agent in an environment",
+		Description: "Returns the configuration of the version deployed to the environment with the referenced prompt version and registered tools resolved. Runtimes poll it with If-None-Match, the ETag changes whenever the resolved configuration does.",
+		Response:    models.ResolvedAgentConfig{},
+	},
+	{
+		Pattern:     "POST /orgs/{orgName}/agents/{agentId}/deployments",
+		ID:          "deployManagedAgent",
+		Summary:     "Deploy a version of a managed agent to an environment",
+		Description: "Makes the version the one the agent runs in the environment. Rolling back is deploying an earlier version, every deployment is kept in the history of the agent.",
+		Request:     models.AgentDeploymentRequest{},
+		Response:    models.AgentDeploymentResponse{},
+		Status:      http.StatusCreated,
+	},
+	{
+		Pattern:     "GET /orgs/{orgName}/agents/{agentId}/deployments",
+		ID:          "listManagedAgentDeployments",
+		Summary:     "List the deployment history of a managed agent",
+		Description: "Deployments and promotions are returned newest first, the first one of an environment is the version the agent runs there.",
+		Query: []openapi.Parameter{
+			{Name: "environment", Description: "Only lists the deployments to this environment", Schema: openapi.String()},
+			limitParam,
+			offsetParam,
+		},
+		Response: models.AgentDeploymentListResponse{},
+	},
+	{
+		Pattern:     "POST /orgs/{orgName}/agents/{agentId}/promotions",
+		ID:          "promoteManagedAgent",
+		Summary:     "Promote a managed agent from one environment to another",
+		Description: "Deploys the version the agent runs in the from environment to the to environment and records the environment it was promoted from and who promoted it. When version is given the promotion only succeeds while the agent still runs that version in the from environment.",
+		Request:     models.AgentPromotionRequest{},
+		Response:    models.AgentDeploymentResponse{},
+		Status:      http.StatusCreated,
+	},
+	// Agent budgets
+	{
+		Pattern:     "GET /orgs/{orgName}/agents/{agentId}/budget",
+		ID:          "getAgentBudget",
+		Summary:     "Get the budget of a managed agent",
+		Description: "Returns the budget together with the cost and tokens the agent used in the current day and month, for the periods the budget limits. Periods start at midnight in the time zone of the budget.",
+		Response:    models.AgentBudgetResponse{},
+	},
+	{
+		Pattern:     "PUT /orgs/{orgName}/agents/{agentId}/budget",
+		ID:          "saveAgentBudget",
+		Summary:     "Set the budget of a managed agent",
+		Description: "Creates or replaces the budget. An agent.budget.threshold_reached event is raised once for each alert threshold the usage of a period reaches, replacing the budget raises the alerts of the current periods again.",
+		Request:     models.AgentBudgetRequest{},
+		Response:    models.AgentBudgetResponse{},
+	},
+	{
+		Pattern: "DELETE /orgs/{orgName}/agents/{agentId}/budget",
+		ID:      "deleteAgentBudget",
+		Summary: "Remove the budget of a managed agent",
+		Status:  http.StatusNoContent,
+	},
+	// Traces and metrics of agents
+	{
+		Pattern:     "GET /orgs/{orgName}/projects/{projName}/agents/{agentName}/traces",
+		ID:          "listTraces",
+		Summary:     "List traces for an agent",
+		Description: "Retrieves a paginated list of traces for the specified agent with optional filtering. Note: If either startTime or endTime is provided, both must be provided together. Both timestamps must be in RFC3339 format (e.g., 2025-12-20T10:00:00Z).",
+		Query: []openapi.Parameter{
+			{Name: "environment", Description: "Environment name (e.g., Development, Production)", Required: true, Schema: openapi.String()},
+			{Name: "limit", Description: "Maximum number of traces to return", Schema: openapi.Integer().WithRange(openapi.Bound(1), openapi.Bound(100)).WithDefault(10)},
+			offsetParam,
+			{Name: "startTime", Description: "Filter traces starting from this time (RFC3339 format, e.g., 2025-12-20T10:00:00Z). Must be provided together with endTime.", Schema: openapi.DateTime()},
+			{Name: "endTime", Description: "Filter traces up to this time (RFC3339 format, e.g., 2025-12-20T10:00:00Z). Must be provided together with startTime.", Schema: openapi.DateTime()},
+			{Name: "sortOrder", Description: "Sort order for traces", Schema: openapi.Enum("asc", "desc").WithDefault("desc")},
+		},
+		Response: models.TraceOverviewResponse{},
+	},
+	{
+		Pattern:     "GET /orgs/{orgName}/projects/{projName}/agents/{agentName}/trace/{traceId}",
+		ID:          "getTrace",
+		Summary:     "Get trace details",
+		Description: "Retrieves detailed information about a specific trace including all spans",
+		Query: []openapi.Parameter{
+			{Name: "environment", Description: "Environment name (e.g., Development, Production)", Required: true, Schema: openapi.String()},
+		},
+		Response: models.TraceResponse{},
+	},
+	{
+		Pattern:     "GET /orgs/{orgName}/agents/{agentId}/traces",
+		ID:          "listManagedAgentTraces",
+		Summary:     "List the traces of a managed agent",
+		Description: "Lists the traces the trace observer linked to the agent. Spans are linked by the agent id resource attribute, or by the agent name they report when it matches the name of exactly one agent of the organization ignoring case. Spans of unmanaged agents and of names shared by several agents are not linked to any agent. Traces of a deleted agent can be listed until the agent is purged. If either startTime or endTime is provided, both must be provided together. A saved trace view given as view supplies the filters and sort of the listing, parameters given as well override those of the view and a parameter given empty clears its filter. Views referencing a filter that is no longer supported are rejected with 400 rather than listing every trace.",
+		Query: []openapi.Parameter{
+			{Name: "limit", Description: "Maximum number of traces to return", Schema: openapi.Integer().WithRange(openapi.Bound(1), openapi.Bound(100)).WithDefault(10)},
+			offsetParam,
+			{Name: "startTime", Description: "Filter traces starting from this time (RFC3339), must be provided together with endTime", Schema: openapi.DateTime()},
+			{Name: "endTime", Description: "Filter traces up to this time (RFC3339), must be provided together with startTime", Schema: openapi.DateTime()},
+			{Name: "sortOrder", Description: "Sort order for traces", Schema: openapi.Enum("asc", "desc").WithDefault("desc")},
+			{Name: "sort", Description: "Field traces are sorted by", Schema: openapi.Enum("startTime", "duration", "tokens").WithDefault("startTime")},
+			{Name: "view", Description: "Saved trace view whose filters and sort the listing starts from", Schema: openapi.UUID()},
+			{Name: "status", Description: "Only traces that succeeded or that have a failed span", Schema: openapi.Enum("ok", "error")},
+			{Name: "minDuration", Description: "Only traces lasting at least this long, such as 500ms or 2s", Schema: openapi.String()},
+			{Name: "model", Description: "Only traces calling this model", Schema: openapi.String()},
+			{Name: "framework", Description: "Only traces of agents built with this framework", Schema: openapi.String()},
+			{Name: "annotationLabel", Description: "Only traces annotated with this label", Schema: openapi.String()},
+			{Name: "hasGuardrailViolation", Description: "Only traces with, or without, a guardrail violation", Schema: openapi.Boolean()},
+		},
+		Response: models.TraceOverviewResponse{},
+	},
+	{
+		Pattern:     "GET /orgs/{orgName}/agents/{agentId}/metrics",
+		ID:          "getManagedAgentMetrics",
+		Summary:     "Get the trace metrics of a managed agent",
+		Description: "Summarizes the latency, errors, token usage and cost of the traces the trace observer linked to the agent, over the whole time range and per interval of it.",
+		Query: []openapi.Parameter{
+			{Name: "startTime", Description: "Start of the time range (RFC3339)", Required: true, Schema: openapi.DateTime()},
+			{Name: "endTime", Description: "End of the time range (RFC3339)", Required: true, Schema: openapi.DateTime()},
+			{Name: "interval", Description: "Width of the intervals the metrics are broken down by", Schema: openapi.Enum("1m", "5m", "1h", "1d").WithDefault("1h")},
+		},
+		Response: models.AgentMetricsResponse{},
+	},
+	// Prompt templates
+	{
+		Pattern:     "POST /orgs/{orgName}/prompts",
+		ID:          "createPromptTemplate",
+		Summary:     "Create a prompt template",
+		Description: "Creates a named prompt template with {{variable_name}} placeholders as version 1.",
+		Request:     models.PromptTemplateRequest{},
+		Response:    models.PromptTemplateResponse{},
+		Status:      http.StatusCreated,
+	},
+	{
+		Pattern: "GET /orgs/{orgName}/prompts",
+		ID:      "listPromptTemplates",
+		Summary: "List prompt templates",
+		Query: []openapi.Parameter{
+			{Name: "search", Description: "Case-insensitive substring of the prompt name", Schema: openapi.String()},
+			{Name: "labelSelector", Description: "Comma separated label requirements the prompts must all meet: key=value, key!=value, key in (v1,v2), key notin (v1,v2), key to require a label and !key to exclude it. key!=value and notin also match prompts without the label", Schema: openapi.String()},
+			{Name: "includeDeleted", Description: "Also lists deleted prompts that are not yet purged, requires trash:manage", Schema: openapi.Boolean().WithDefault(false)},
+			limitParam,
+			offsetParam,
+			sortParam,
+			sortOrderParam,
+			cursorParam,
+			countParam,
+		},
+		Response: models.PromptTemplateListResponse{},
+	},
+	{
+		Pattern: "GET /orgs/{orgName}/prompts/{promptId}",
+		ID:      "getPromptTemplate",
+		Summary: "Get a prompt template",
+		Query: []openapi.Parameter{
+			{Name: "includeDeleted", Description: "Also returns the prompt when it is deleted but not yet purged, so references from agents still resolve", Schema: openapi.Boolean().WithDefault(false)},
+		},
+		Response: models.PromptTemplateResponse{},
+	},
+	{
+		Pattern:     "PUT /orgs/{orgName}/prompts/{promptId}",
+		ID:          "updatePromptTemplate",
+		Summary:     "Update a prompt template",
+		Description: "Records the new template as the next version. Agents keep the version they reference. The update only succeeds if the prompt is still at the version in If-Match or in the version field of the body, \"*\" lets the last write win. A stale update is rejected with the current prompt, with 412 for If-Match and 409 for the body version.",
+		Request:     models.PromptTemplateRequest{},
+		Response:    models.PromptTemplateResponse{},
+	},
+	{
+		Pattern:     "PATCH /orgs/{orgName}/prompts/{promptId}",
+		ID:          "patchPromptTemplate",
+		Summary:     "Patch a prompt template",
+		Description: "Records the patched template as the next version. The body is a JSON merge patch (RFC 7386) validated as the result of applying it: members replace those of the prompt, objects such as labels are merged key by key, null removes a member and arrays such as variables are replaced as a whole. When If-Match or version is sent the patch only succeeds if the prompt is still at that version, otherwise it applies to the current version. A stale patch is rejected with the current prompt, with 412 for If-Match and 409 for the body version.",
+		Request:     models.PromptTemplateRequest{},
+		MergePatch:  true,
+		Response:    models.PromptTemplateResponse{},
+	},
+	{
+		Pattern:     "DELETE /orgs/{orgName}/prompts/{promptId}",
+		ID:          "deletePromptTemplate",
+		Summary:     "Delete a prompt template",
+		Description: "Deleting a prompt that agents reference is rejected, the problem lists the referencing agents in dependents. The prompt is kept for the configured retention, during which it can be restored, and then purged. Its name can be reused right away.",
+		Status:      http.StatusNoContent,
+	},
+	{
+		Pattern:     "POST /orgs/{orgName}/prompts/{promptId}/restore",
+		ID:          "restorePromptTemplate",
+		Summary:     "Restore a deleted prompt template",
+		Description: "Undoes the deletion of a prompt that is not yet purged, keeping its version.",
+		Response:    models.PromptTemplateResponse{},
+	},
+	{
+		Pattern:     "GET /orgs/{orgName}/prompts/{promptId}/versions",
+		ID:          "listPromptTemplateVersions",
+		Summary:     "List the versions of a prompt template",
+		Description: "Versions are returned newest first.",
+		Query: []openapi.Parameter{
+			limitParam,
+			offsetParam,
+		},
+		Response: models.PromptTemplateVersionListResponse{},
+	},
+	{
+		Pattern:  "GET /orgs/{orgName}/prompts/{promptId}/versions/{version}",
+		ID:       "getPromptTemplateVersion",
+		Summary:  "Get a version of a prompt template",
+		Response: models.PromptTemplateVersionResponse{},
+	},
+	{
+		Pattern:     "POST /orgs/{orgName}/prompts/{promptId}/render",
+		ID:          "renderPromptTemplate",
+		Summary:     "Render a prompt template",
+		Description: "Substitutes the variables into the template. Every variable without a default must be supplied and unknown variables are rejected.",
+		Request:     models.PromptRenderRequest{},
+		Response:    models.PromptRenderResponse{},
+	},
+	// Tools
+	{
+		Pattern:     "POST /orgs/{orgName}/tools",
+		ID:          "createTool",
+		Summary:     "Register a tool",
+		Description: "Registers a tool whose parameters are a JSON Schema (draft 2020-12) object schema.",
+		Request:     models.ToolRequest{},
+		Response:    models.ToolResponse{},
+		Status:      http.StatusCreated,
+	},
+	{
+		Pattern: "GET /orgs/{orgName}/tools",
+		ID:      "listTools",
+		Summary: "List tools",
+		Query: []openapi.Parameter{
+			{Name: "search", Description: "Case-insensitive substring of the tool name", Schema: openapi.String()},
+			{Name: "tags", Description: "Comma separated tags the tools must all carry", Schema: openapi.String()},
+			{Name: "labelSelector", Description: "Comma separated label requirements the tools must all meet: key=value, key!=value, key in (v1,v2), key notin (v1,v2), key to require a label and !key to exclude it. key!=value and notin also match tools without the label", Schema: openapi.String()},
+			limitParam,
+			offsetParam,
+			sortParam,
+			sortOrderParam,
+			cursorParam,
+			countParam,
+		},
+		Response: models.ToolListResponse{},
+	},
+	{
+		Pattern:  "GET /orgs/{orgName}/tools/{toolId}",
+		ID:       "getTool",
+		Summary:  "Get a tool",
+		Response: models.ToolResponse{},
+	},
+	{
+		Pattern:     "PUT /orgs/{orgName}/tools/{toolId}",
+		ID:          "updateTool",
+		Summary:     "Update a tool",
+		Description: "The update only succeeds if the tool is still at the version in If-Match or in the version field of the body, \"*\" lets the last write win. A stale update is rejected with the current tool, with 412 for If-Match and 409 for the body version.",
+		Request:     models.ToolRequest{},
+		Response:    models.ToolResponse{},
+	},
+	{
+		Pattern:     "PATCH /orgs/{orgName}/tools/{toolId}",
+		ID:          "patchTool",
+		Summary:     "Patch a tool",
+		Description: "The body is a JSON merge patch (RFC 7386) validated as the result of applying it: members replace those of the tool, objects such as parameters, endpoint and labels are merged key by key, null removes a member and arrays such as tags are replaced as a whole. When If-Match or version is sent the patch only succeeds if the tool is still at that version, otherwise it applies to the current version. A stale patch is rejected with the current tool, with 412 for If-Match and 409 for the body version.",
+		Request:     models.ToolRequest{},
+		MergePatch:  true,
+		Response:    models.ToolResponse{},
+	},
+	{
+		Pattern:     "DELETE /orgs/{orgName}/tools/{toolId}",
+		ID:          "deleteTool",
+		Summary:     "Delete a tool",
+		Description: "Deleting a tool that agents reference is rejected, the problem lists the referencing agents in dependents.",
+		Status:      http.StatusNoContent,
+	},
+	// API keys
+	{
+		Pattern:     "POST /orgs/{orgName}/api-keys",
+		ID:          "createAPIKey",
+		Summary:     "Create an API key",
+		Description: "Creates an API key acting as the calling user in the organization with the given scopes, which must be permissions the caller holds. The key is returned only in this response, send it as \"Authorization: Bearer amp_...\" to authenticate. Expired and revoked keys are rejected with 401.",
+		Request:     models.APIKeyRequest{},
+		Response:    models.APIKeyCreateResponse{},
+		Status:      http.StatusCreated,
+	},
+	{
+		Pattern:     "GET /orgs/{orgName}/api-keys",
+		ID:          "listAPIKeys",
+		Summary:     "List API keys",
+		Description: "Lists the active API keys of the calling user in the organization without their secrets.",
+		Query: []openapi.Parameter{
+			limitParam,
+			offsetParam,
+		},
+		Response: models.APIKeyListResponse{},
+	},
+	{
+		Pattern:     "DELETE /orgs/{orgName}/api-keys/{apiKeyId}",
+		ID:          "revokeAPIKey",
+		Summary:     "Revoke an API key",
+		Description: "Requests bearing a revoked key are rejected with 401.",
+		Status:      http.StatusNoContent,
+	},
+	// Credentials
+	{
+		Pattern:     "POST /orgs/{orgName}/credentials",
+		ID:          "createCredential",
+		Summary:     "Create a credential",
+		Description: "Stores a secret tools authenticate with, encrypted at rest. The secret is never returned by this API, responses carry a masked preview instead. Agent runtimes fetch it from the internal API with the service API key.",
+		Request:     models.CredentialRequest{},
+		Response:    models.CredentialResponse{},
+		Status:      http.StatusCreated,
+	},
+	{
+		Pattern:     "GET /orgs/{orgName}/credentials",
+		ID:          "listCredentials",
+		Summary:     "List credentials",
+		Description: "Lists the credentials of the organization by name without their secrets.",
+		Query: []openapi.Parameter{
+			limitParam,
+			offsetParam,
+		},
+		Response: models.CredentialListResponse{},
+	},
+	{
+		Pattern:     "POST /orgs/{orgName}/credentials:rotateKey",
+		ID:          "rotateCredentialKey",
+		Summary:     "Rotate the credential encryption key",
+		Description: "Re-encrypts the data keys of every credential and webhook secret of the organization that is not under the active master key, the secrets themselves are left as they are. Rotation is all or nothing and can be repeated, once every organization reports nothing rotated the previous master keys can be removed from the configuration.",
+		Response:    models.CredentialKeyRotationResponse{},
+	},
+	{
+		Pattern:     "GET /orgs/{orgName}/credentials/{credentialId}",
+		ID:          "getCredential",
+		Summary:     "Get a credential",
+		Description: "Returns the credential without its secret.",
+		Response:    models.CredentialResponse{},
+	},
+	{
+		Pattern:     "PUT /orgs/{orgName}/credentials/{credentialId}",
+		ID:          "updateCredential",
+		Summary:     "Update a credential",
+		Description: "Replaces the name and description of the credential, and its secret when the request carries one.",
+		Request:     models.CredentialRequest{},
+		Response:    models.CredentialResponse{},
+	},
+	{
+		Pattern: "DELETE /orgs/{orgName}/credentials/{credentialId}",
+		ID:      "deleteCredential",
+		Summary: "Delete a credential",
+		Status:  http.StatusNoContent,
+	},
+	// Webhooks
+	{
+		Pattern:     "POST /orgs/{orgName}/webhooks",
+		ID:          "createWebhook",
+		Summary:     "Create a webhook",
+		Description: "Subscribes a URL to agent lifecycle events. Each event is POSTed as a WebhookEvent with an X-Amp-Event header naming its type, an X-Amp-Delivery header with the delivery id and an X-Amp-Signature header carrying sha256= and the hex encoded HMAC-SHA256 of the body keyed with the webhook secret. A delivery succeeds when the webhook answers with a 2xx status, failed attempts are retried with exponential backoff up to the configured number of attempts. The secret is encrypted at rest with the credential encryption keys and never returned.",
+		Request:     models.WebhookRequest{},
+		Response:    models.WebhookResponse{},
+		Status:      http.StatusCreated,
+	},
+	{
+		Pattern:     "GET /orgs/{orgName}/webhooks",
+		ID:          "listWebhooks",
+		Summary:     "List webhooks",
+		Description: "Lists the webhooks of the organization, by default in the order they were created.",
+		Query: []openapi.Parameter{
+			limitParam,
+			offsetParam,
+			{Name: "sort", Description: "Field the webhooks are sorted by, ties are broken by id", Schema: openapi.Enum(models.ListSortCreatedAt, models.ListSortUpdatedAt).WithDefault(models.ListSortCreatedAt)},
+			sortOrderParam,
+			cursorParam,
+			countParam,
+		},
+		Response: models.WebhookListResponse{},
+	},
+	{
+		Pattern:     "GET /orgs/{orgName}/webhooks/{webhookId}",
+		ID:          "getWebhook",
+		Summary:     "Get a webhook",
+		Description: "Returns the webhook without its secret.",
+		Response:    models.WebhookResponse{},
+	},
+	{
+		Pattern:     "PUT /orgs/{orgName}/webhooks/{webhookId}",
+		ID:          "updateWebhook",
+		Summary:     "Update a webhook",
+		Description: "Replaces the URL, description and events of the webhook, and its secret and active flag when the request carries them. Queued deliveries are sent to the new URL and signed with the new secret.",
+		Request:     models.WebhookRequest{},
+		Response:    models.WebhookResponse{},
+	},
+	{
+		Pattern:     "PATCH /orgs/{orgName}/webhooks/{webhookId}",
+		ID:          "patchWebhook",
+		Summary:     "Patch a webhook",
+		Description: "The body is a JSON merge patch (RFC 7386) validated as the result of applying it: members replace those of the webhook, null removes a member and arrays such as events are replaced as a whole. A secret in the patch replaces the current one and removing active keeps it unchanged. Webhooks are not versioned, the last write wins.",
+		Request:     models.WebhookRequest{},
+		MergePatch:  true,
+		Response:    models.WebhookResponse{},
+	},
+	{
+		Pattern:     "DELETE /orgs/{orgName}/webhooks/{webhookId}",
+		ID:          "deleteWebhook",
+		Summary:     "Delete a webhook",
+		Description: "Deletes the webhook together with its deliveries, queued deliveries are never sent.",
+		Status:      http.StatusNoContent,
+	},
+	{
+		Pattern:     "POST /orgs/{orgName}/webhooks/{webhookId}/test",
+		ID:          "testWebhook",
+		Summary:     "Test a webhook",
+		Description: "Sends a ping event to the webhook right away, whether or not it is active, and returns the delivery. The ping is attempted once and never retried, a webhook that rejects it is still answered with 200 and a failed delivery.",
+		Response:    models.WebhookDeliveryResponse{},
+	},
+	{
+		Pattern:     "GET /orgs/{orgName}/webhooks/{webhookId}/deliveries",
+		ID:          "listWebhookDeliveries",
+		Summary:     "List webhook deliveries",
+		Description: "Lists the deliveries of the webhook with their attempts, newest first. Finished deliveries are kept for the configured retention.",
+		Query: []openapi.Parameter{
+			limitParam,
+			offsetParam,
+		},
+		Response: models.WebhookDeliveryListResponse{},
+	},
+	{
+		Pattern:     "POST /orgs/{orgName}/webhooks/{webhookId}/deliveries/{deliveryId}/redeliver",
+		ID:          "redeliverWebhookDelivery",
+		Summary:     "Redeliver a failed webhook delivery",
+		Description: "Queues the event of a failed delivery again as a new delivery with the same event id and payload, referring to the failed one in redeliveryOf. It is sent by the dispatcher with the usual retries.",
+		Response:    models.WebhookDeliveryResponse{},
+		Status:      http.StatusAccepted,
+	},
+	// Evaluations
+	{
+		Pattern:     "POST /orgs/{orgName}/evaluations",
+		ID:          "createEvaluation",
+		Summary:     "Start an evaluation run",
+		Description: "Queues a run scoring the traces a managed agent recorded in a time range, oldest first. Runs are processed in the background and resume from their last checkpoint when interrupted, poll the run for its progress. Builtin evaluators score each trace 1 or 0, http evaluators are sent each trace as an EvaluatorHTTPRequest and answer with an EvaluatorHTTPResponse. Traces an evaluator fails on are recorded with an error.",
+		Request:     models.EvaluationRequest{},
+		Response:    models.EvaluationResponse{},
+		Status:      http.StatusAccepted,
+	},
+	{
+		Pattern:     "GET /orgs/{orgName}/evaluations",
+		ID:          "listEvaluations",
+		Summary:     "List evaluation runs",
+		Description: "Lists the evaluation runs of the organization, newest first.",
+		Query: []openapi.Parameter{
+			limitParam,
+			offsetParam,
+		},
+		Response: models.EvaluationListResponse{},
+	},
+	{
+		Pattern:     "GET /orgs/{orgName}/evaluations/{evaluationId}",
+		ID:          "getEvaluation",
+		Summary:     "Get an evaluation run",
+		Description: "Returns the status and progress of the run with the summary of the traces evaluated so far.",
+		Response:    models.EvaluationResponse{},
+	},
+	{
+		Pattern:     "GET /orgs/{orgName}/evaluations/{evaluationId}/results",
+		ID:          "listEvaluationResults",
+		Summary:     "List the results of an evaluation run",
+		Description: "Lists the score of each trace evaluated so far in the order the traces were evaluated.",
+		Query: []openapi.Parameter{
+			limitParam,
+			offsetParam,
+		},
+		Response: models.EvaluationResultListResponse{},
+	},
+	// Trace views
+	{
+		Pattern:     "POST /orgs/{orgName}/trace-views",
+		ID:          "createTraceView",
+		Summary:     "Save a trace view",
+		Description: "Saves a named set of trace list filters and sort order, listing the traces of an agent with the view parameter applies them. Views are private to the user who saved them unless shared with the organization. Filters are keyed by the query parameter they set, views referencing any other filter are rejected.",
+		Request:     models.TraceViewRequest{},
+		Response:    models.TraceViewResponse{},
+		Status:      http.StatusCreated,
+	},
+	{
+		Pattern:     "GET /orgs/{orgName}/trace-views",
+		ID:          "listTraceViews",
+		Summary:     "List trace views",
+		Description: "Lists the views the user saved and those shared with the organization by name.",
+		Query: []openapi.Parameter{
+			limitParam,
+			offsetParam,
+		},
+		Response: models.TraceViewListResponse{},
+	},
+	{
+		Pattern:  "GET /orgs/{orgName}/trace-views/{viewId}",
+		ID:       "getTraceView",
+		Summary:  "Get a trace view",
+		Response: models.TraceViewResponse{},
+	},
+	{
+		Pattern:     "DELETE /orgs/{orgName}/trace-views/{viewId}",
+		ID:          "deleteTraceView",
+		Summary:     "Delete a trace view",
+		Description: "Only the user who saved a view can delete it.",
+		Status:      http.StatusNoContent,
+	},
+	// Audit logs
+	{
+		Pattern:     "GET /orgs/{orgName}/audit-logs",
+		ID:          "listAuditLogs",
+		Summary:     "List audit logs",
+		Description: "Lists the successful POST, PUT, PATCH and DELETE requests made in the organization, newest first. Entries are written in the background shortly after the request completes and are never updated or deleted.",
+		Query: []openapi.Parameter{
+			{Name: "actor", Description: "IdP id of the user who made the requests", Schema: openapi.UUID()},
+			{Name: "resourceType", Description: "Type of the resources acted on, e.g. agents, prompts or tools", Schema: openapi.String()},
+			{Name: "resourceId", Schema: openapi.String()},
+			{Name: "action", Description: "create, update, delete or an operation such as rollback", Schema: openapi.String()},
+			{Name: "from", Description: "Entries recorded at or after this time", Schema: openapi.DateTime()},
+			{Name: "to", Description: "Entries recorded before this time", Schema: openapi.DateTime()},
+			limitParam,
+			offsetParam,
+		},
+		Response: models.AuditLogListResponse{},
+	},
+}
+
+// NewSpec describes the API with the permissions of the routes registered with authz
+func NewSpec(authz *middleware.Authorizer) (*openapi.Spec, error) {
+	permissions := map[string]utils.Permission{}
+	for _, route := range authz.Routes() {
+		permissions[route.Pattern] = route.Permission
+	}
+	described := make([]openapi.Operation, 0, len(operations))
+	for _, op := range operations {
+		permission, ok := permissions[op.Pattern]
+		if !ok {
+			return nil, fmt.Errorf("operation %q has no registered route", op.Pattern)
+		}
+		op.Permission = string(permission)
+		described = append(described, op)
+	}
+	return openapi.New(openapi.Info{
+		Title:       "Agent Manager Service API",
+		Version:     "1.0.0",
+		Description: apiDescription,
+		BasePath:    "/api/v1",
+		Problem:     utils.ProblemDetails{},
+	}, described)
+}
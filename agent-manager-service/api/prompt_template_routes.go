@@ -0,0 +1,38 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package api
+
+import (
+	"net/http"
+
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/controllers"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/middleware"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/utils"
+)
+
+func registerPromptTemplateRoutes(mux *http.ServeMux, ctrl controllers.PromptTemplateController, authz *middleware.Authorizer) {
+	authz.HandleFunc(mux, "POST /orgs/{orgName}/prompts", utils.PermissionPromptsWrite, ctrl.CreatePromptTemplate)
+	authz.HandleFunc(mux, "GET /orgs/{orgName}/prompts", utils.PermissionPromptsRead, ctrl.ListPromptTemplates)
+	authz.HandleFunc(mux, "GET /orgs/{orgName}/prompts/{promptId}", utils.PermissionPromptsRead, ctrl.GetPromptTemplate)
+	authz.HandleFunc(mux, "PUT /orgs/{orgName}/prompts/{promptId}", utils.PermissionPromptsWrite, ctrl.UpdatePromptTemplate)
+	authz.HandleFunc(mux, "PATCH /orgs/{orgName}/prompts/{promptId}", utils.PermissionPromptsWrite, ctrl.PatchPromptTemplate)
+	authz.HandleFunc(mux, "DELETE /orgs/{orgName}/prompts/{promptId}", utils.PermissionPromptsWrite, ctrl.DeletePromptTemplate)
+	authz.HandleFunc(mux, "POST /orgs/{orgName}/prompts/{promptId}/restore", utils.PermissionTrashManage, ctrl.RestorePromptTemplate)
+	authz.HandleFunc(mux, "GET /orgs/{orgName}/prompts/{promptId}/versions", utils.PermissionPromptsRead, ctrl.ListPromptTemplateVersions)
+	authz.HandleFunc(mux, "GET /orgs/{orgName}/prompts/{promptId}/versions/{version}", utils.PermissionPromptsRead, ctrl.GetPromptTemplateVersion)
+	authz.HandleFunc(mux, "POST /orgs/{orgName}/prompts/{promptId}/render", utils.PermissionPromptsRead, ctrl.RenderPromptTemplate)
+}
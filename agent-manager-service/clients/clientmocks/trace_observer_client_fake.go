@@ -42,6 +42,14 @@ type TraceObserverClientMock struct {
 		Ctx    context.Context
 		Params traceobserversvc.TraceDetailsByIdParams
 	}
+
+	// GetTraceMetrics
+	GetTraceMetricsFunc  func(ctx context.Context, params traceobserversvc.TraceMetricsParams) (*traceobserversvc.TraceMetricsResponse, error)
+	getTraceMetricsMutex sync.RWMutex
+	getTraceMetricsCalls []struct {
+		Ctx    context.Context
+		Params traceobserversvc.TraceMetricsParams
+	}
 }
 
 func (m *TraceObserverClientMock) ListTraces(ctx context.Context, params traceobserversvc.ListTracesParams) (*traceobserversvc.TraceOverviewResponse, error) {
@@ -97,3 +105,30 @@ func (m *TraceObserverClientMock) TraceDetailsByIdCalls() []struct {
 	defer m.traceDetailsByIdMutex.RUnlock()
 	return m.traceDetailsByIdCalls
 }
+
+func (m *TraceObserverClientMock) GetTraceMetrics(ctx context.Context, params traceobserversvc.TraceMetricsParams) (*traceobserversvc.TraceMetricsResponse, error) {
+	m.getTraceMetricsMutex.Lock()
+	m.getTraceMetricsCalls = append(m.getTraceMetricsCalls, struct {
+		Ctx    context.Context
+		Params traceobserversvc.TraceMetricsParams
+	}{
+		Ctx:    ctx,
+		Params: params,
+	})
+	m.getTraceMetricsMutex.Unlock()
+
+	if m.GetTraceMetricsFunc != nil {
+		return m.GetTraceMetricsFunc(ctx, params)
+	}
+
+	return &traceobserversvc.TraceMetricsResponse{}, nil
+}
+
+func (m *TraceObserverClientMock) GetTraceMetricsCalls() []struct {
+	Ctx    context.Context
+	Params traceobserversvc.TraceMetricsParams
+} {
+	m.getTraceMetricsMutex.RLock()
+	defer m.getTraceMetricsMutex.RUnlock()
+	return m.getTraceMetricsCalls
+}
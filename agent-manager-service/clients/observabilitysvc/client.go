@@ -24,6 +24,7 @@ import (
 
 	"github.com/wso2/ai-agent-management-platform/agent-manager-service/clients/requests"
 	"github.com/wso2/ai-agent-management-platform/agent-manager-service/config"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/middleware/correlation"
 	"github.com/wso2/ai-agent-management-platform/agent-manager-service/models"
 )
 
@@ -43,7 +44,8 @@ type observabilitySvcClient struct {
 
 func NewObservabilitySvcClient() ObservabilitySvcClient {
 	httpClient := &http.Client{
-		Timeout: time.Second * 15,
+		Timeout:   time.Second * 15,
+		Transport: correlation.NewTransport(nil),
 	}
 	return &observabilitySvcClient{
 		httpClient: httpClient,
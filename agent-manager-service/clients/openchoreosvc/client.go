@@ -32,6 +32,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/wso2/ai-agent-management-platform/agent-manager-service/config"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/middleware/correlation"
 	"github.com/wso2/ai-agent-management-platform/agent-manager-service/models"
 	"github.com/wso2/ai-agent-management-platform/agent-manager-service/spec"
 	"github.com/wso2/ai-agent-management-platform/agent-manager-service/utils"
@@ -86,6 +87,9 @@ func NewOpenChoreoSvcClient() (OpenChoreoSvcClient, error) {
 		return nil, fmt.Errorf("failed to get kubernetes config: %w", err)
 	}
 
+	// Calls made while serving a request carry its correlation id
+	config.Wrap(correlation.NewTransport)
+
 	// Create a scheme and register the OpenChoreo v1alpha1 types
 	sch := runtime.NewScheme()
 	if err := scheme.AddToScheme(sch); err != nil {
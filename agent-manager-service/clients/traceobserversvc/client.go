@@ -27,14 +27,19 @@ import (
 	"time"
 
 	"github.com/wso2/ai-agent-management-platform/agent-manager-service/config"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/middleware/correlation"
 )
 
 // TraceObserverClient is the interface for interacting with the trace observer service
 type TraceObserverClient interface {
 	ListTraces(ctx context.Context, params ListTracesParams) (*TraceOverviewResponse, error)
 	TraceDetailsById(ctx context.Context, params TraceDetailsByIdParams) (*TraceResponse, error)
+	GetTraceMetrics(ctx context.Context, params TraceMetricsParams) (*TraceMetricsResponse, error)
 }
 
+// OrgIdHeader carries the organization the trace observer scopes a query to
+const OrgIdHeader = "X-Org-Id"
+
 type traceObserverClient struct {
 	baseURL    string
 	httpClient *http.Client
@@ -46,7 +51,8 @@ func NewTraceObserverClient() TraceObserverClient {
 	return &traceObserverClient{
 		baseURL: cfg.TraceObserver.URL,
 		httpClient: &http.Client{
-			Timeout: 15 * time.Second,
+			Timeout:   15 * time.Second,
+			Transport: correlation.NewTransport(nil),
 		},
 	}
 }
@@ -55,10 +61,15 @@ func NewTraceObserverClient() TraceObserverClient {
 func (c *traceObserverClient) ListTraces(ctx context.Context, params ListTracesParams) (*TraceOverviewResponse, error) {
 	// Build query parameters
 	queryParams := url.Values{}
-	queryParams.Add("componentUid", params.ComponentUid)
+	if params.ComponentUid != "" {
+		queryParams.Add("componentUid", params.ComponentUid)
+	}
 	if params.EnvironmentUid != "" {
 		queryParams.Add("environmentUid", params.EnvironmentUid)
 	}
+	if params.AgentID != "" {
+		queryParams.Add("agentId", params.AgentID)
+	}
 	if params.StartTime != "" {
 		queryParams.Add("startTime", params.StartTime)
 	}
@@ -68,6 +79,13 @@ func (c *traceObserverClient) ListTraces(ctx context.Context, params ListTracesP
 	queryParams.Add("limit", strconv.Itoa(params.Limit))
 	queryParams.Add("offset", strconv.Itoa(params.Offset))
 	queryParams.Add("sortOrder", params.SortOrder)
+	if params.Cursor != "" {
+		queryParams.Add("cursor", params.Cursor)
+	}
+	if params.Sort != "" {
+		queryParams.Add("sort", params.Sort)
+	}
+	addTraceFilters(queryParams, params.Filters)
 
 	// Build URL - endpoint is /api/v1/traces
 	requestURL := fmt.Sprintf("%s/api/v1/traces?%s", c.baseURL, queryParams.Encode())
@@ -77,6 +95,7 @@ func (c *traceObserverClient) ListTraces(ctx context.Context, params ListTracesP
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
+	req.Header.Set(OrgIdHeader, params.OrgID)
 
 	// Execute request
 	resp, err := c.httpClient.Do(req)
@@ -121,6 +140,7 @@ func (c *traceObserverClient) TraceDetailsById(ctx context.Context, params Trace
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
+	req.Header.Set(OrgIdHeader, params.OrgID)
 
 	// Execute request
 	resp, err := c.httpClient.Do(req)
@@ -146,3 +166,64 @@ func (c *traceObserverClient) TraceDetailsById(ctx context.Context, params Trace
 
 	return &response, nil
 }
+
+// GetTraceMetrics retrieves the trace metrics of a managed agent together with their summary over the time range
+func (c *traceObserverClient) GetTraceMetrics(ctx context.Context, params TraceMetricsParams) (*TraceMetricsResponse, error) {
+	queryParams := url.Values{}
+	queryParams.Add("agentId", params.AgentID)
+	queryParams.Add("startTime", params.StartTime)
+	queryParams.Add("endTime", params.EndTime)
+	queryParams.Add("interval", params.Interval)
+	queryParams.Add("summary", "true")
+
+	requestURL := fmt.Sprintf("%s/api/v1/metrics/traces?%s", c.baseURL, queryParams.Encode())
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, requestURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set(OrgIdHeader, params.OrgID)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, &HTTPError{
+			StatusCode: resp.StatusCode,
+			Message:    string(body),
+		}
+	}
+
+	var response TraceMetricsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return &response, nil
+}
+
+// addTraceFilters adds the filters that are set to the query of a trace listing
+func addTraceFilters(queryParams url.Values, filters TraceFilters) {
+	if filters.Status != "" {
+		queryParams.Add("status", filters.Status)
+	}
+	if filters.MinDuration > 0 {
+		queryParams.Add("minDuration", filters.MinDuration.String())
+	}
+	if filters.Model != "" {
+		queryParams.Add("model", filters.Model)
+	}
+	if filters.Framework != "" {
+		queryParams.Add("framework", filters.Framework)
+	}
+	if filters.AnnotationLabel != "" {
+		queryParams.Add("annotationLabel", filters.AnnotationLabel)
+	}
+	if filters.HasGuardrailViolation != nil {
+		queryParams.Add("hasGuardrailViolation", strconv.FormatBool(*filters.HasGuardrailViolation))
+	}
+}
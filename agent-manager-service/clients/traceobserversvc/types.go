@@ -33,6 +33,8 @@ func (e *HTTPError) Error() string {
 
 // ListTracesParams holds parameters for listing trace overviews
 type ListTracesParams struct {
+	// OrgID is the organization the traces are read from
+	OrgID          string
 	ServiceName    string
 	ComponentUid   string
 	EnvironmentUid string
@@ -41,10 +43,30 @@ type ListTracesParams struct {
 	Limit          int
 	Offset         int
 	SortOrder      string
+	// AgentID restricts the traces to those linked to a managed agent, the component is optional then
+	AgentID string
+	// Cursor is the NextCursor of the previous page, it takes precedence over Offset
+	Cursor string
+	// Sort is the field traces are sorted by, the start time when empty
+	Sort    string
+	Filters TraceFilters
+}
+
+// TraceFilters narrows a trace listing, filters left empty are not applied
+type TraceFilters struct {
+	// ok or error
+	Status                string
+	MinDuration           time.Duration
+	Model                 string
+	Framework             string
+	AnnotationLabel       string
+	HasGuardrailViolation *bool
 }
 
 // TraceDetailsByIdParams holds parameters for getting trace details by ID
 type TraceDetailsByIdParams struct {
+	// OrgID is the organization the trace is read from
+	OrgID          string
 	TraceID        string
 	ServiceName    string
 	ComponentUid   string
@@ -76,13 +98,15 @@ type TokenUsage struct {
 
 // TraceStatus represents the status of a trace
 type TraceStatus struct {
-	ErrorCount int `json:"errorCount"` // Number of spans with errors (0 means no errors)
+	ErrorCount     int `json:"errorCount"`     // Number of spans with errors (0 means no errors)
+	ToolErrorCount int `json:"toolErrorCount"` // Number of tool spans with errors
 }
 
 // TraceOverviewResponse represents the response for trace overview queries
 type TraceOverviewResponse struct {
 	Traces     []TraceOverview `json:"traces"`
 	TotalCount int             `json:"totalCount"`
+	NextCursor string          `json:"nextCursor,omitempty"` // Cursor of the next page, empty on the last page
 }
 
 // Span represents a single trace span
@@ -121,10 +145,12 @@ type SpanStatus struct {
 
 // LLMTokenUsage represents token usage for a single LLM span
 type LLMTokenUsage struct {
-	InputTokens          int `json:"inputTokens"`
-	OutputTokens         int `json:"outputTokens"`
-	CacheReadInputTokens int `json:"cacheReadInputTokens,omitempty"`
-	TotalTokens          int `json:"totalTokens"`
+	InputTokens           int `json:"inputTokens"`
+	OutputTokens          int `json:"outputTokens"`
+	CacheReadInputTokens  int `json:"cacheReadInputTokens,omitempty"`
+	CacheWriteInputTokens int `json:"cacheWriteInputTokens,omitempty"` // Input tokens written to the prompt cache
+	ReasoningTokens       int `json:"reasoningTokens,omitempty"`       // Reasoning/thinking tokens, included in OutputTokens
+	TotalTokens           int `json:"totalTokens"`
 }
 
 // PromptMessage represents a single message in a conversation
@@ -143,9 +169,26 @@ type ToolCall struct {
 
 // ToolDefinition represents a tool/function available to the LLM
 type ToolDefinition struct {
-	Name        string `json:"name"`                  // Function name
-	Description string `json:"description,omitempty"` // Function description
-	Parameters  string `json:"parameters,omitempty"`  // JSON schema of parameters
+	Name            string          `json:"name"`                      // Function name
+	Description     string          `json:"description,omitempty"`     // Function description
+	Parameters      string          `json:"parameters,omitempty"`      // JSON schema of parameters
+	ParameterSchema *ToolParameters `json:"parameterSchema,omitempty"` // Parameters parsed from the schema
+}
+
+// ToolParameters is the parsed parameter schema of a tool
+type ToolParameters struct {
+	Properties []ToolParameter `json:"properties"`          // Parameters in the order the schema declares them
+	Truncated  bool            `json:"truncated,omitempty"` // Parameters past the size limit were dropped
+}
+
+// ToolParameter is a parameter of a tool
+type ToolParameter struct {
+	Name        string        `json:"name"`
+	Type        string        `json:"type,omitempty"` // JSON schema type, e.g. "string", "array<string>" or "string | null"
+	Description string        `json:"description,omitempty"`
+	Required    bool          `json:"required"`
+	Default     interface{}   `json:"default,omitempty"`
+	Enum        []interface{} `json:"enum,omitempty"`
 }
 
 // TraceResponse represents the response for trace queries
@@ -155,3 +198,48 @@ type TraceResponse struct {
 	TokenUsage *TokenUsage  `json:"tokenUsage,omitempty"` // Aggregated token usage from GenAI spans
 	Status     *TraceStatus `json:"status,omitempty"`     // Trace status including error information
 }
+
+// TraceMetricsParams holds parameters for getting the trace metrics of a managed agent
+type TraceMetricsParams struct {
+	// OrgID is the organization the traces are read from
+	OrgID     string
+	AgentID   string
+	StartTime string
+	EndTime   string
+	Interval  string // 1m, 5m, 1h or 1d
+}
+
+// TraceMetricsResponse represents trace metric time series together with their summary over the time range
+type TraceMetricsResponse struct {
+	Interval  string               `json:"interval"`
+	StartTime time.Time            `json:"startTime"`
+	EndTime   time.Time            `json:"endTime"`
+	Series    []TraceMetricsSeries `json:"series"`
+	Summary   *TraceMetricsPoint   `json:"summary,omitempty"`
+}
+
+// TraceMetricsSeries is the time series of all matching traces
+type TraceMetricsSeries struct {
+	Group  string              `json:"group,omitempty"`
+	Points []TraceMetricsPoint `json:"points"`
+}
+
+// TraceMetricsPoint holds the metrics of a single time bucket
+type TraceMetricsPoint struct {
+	Timestamp     time.Time          `json:"timestamp"`
+	TraceCount    int                `json:"traceCount"`
+	ErrorCount    int                `json:"errorCount"` // Traces with at least one failed span
+	ErrorRate     float64            `json:"errorRate"`
+	DurationNanos LatencyPercentiles `json:"durationNanos"` // Trace duration percentiles
+	InputTokens   int                `json:"inputTokens"`
+	OutputTokens  int                `json:"outputTokens"`
+	TotalTokens   int                `json:"totalTokens"`
+	Cost          float64            `json:"cost"` // Cost of priced models in USD
+}
+
+// LatencyPercentiles holds the p50, p95 and p99 of a duration
+type LatencyPercentiles struct {
+	P50 int64 `json:"p50"`
+	P95 int64 `json:"p95"`
+	P99 int64 `json:"p99"`
+}
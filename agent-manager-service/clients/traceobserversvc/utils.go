@@ -27,3 +27,11 @@ func IsNotFound(err error) bool {
 	}
 	return false
 }
+
+// IsBadRequest checks if the error is a 400 Bad Request error, returned for queries the trace observer rejects
+func IsBadRequest(err error) bool {
+	if httpErr, ok := err.(*HTTPError); ok {
+		return httpErr.StatusCode == http.StatusBadRequest
+	}
+	return false
+}
@@ -24,21 +24,37 @@ type Config struct {
 	AuthHeader          string
 	AutoMaxProcsEnabled bool
 	LogLevel            string
-	POSTGRESQL          POSTGRESQL
-	KubeConfig          string
+	// File holding the log level, re-read on SIGHUP
+	LogLevelFile string
+	// Sampling of debug records with the same message
+	LogSampling LogSamplingConfig
+	// Traces of the service itself
+	SelfTracing SelfTracingConfig
+	POSTGRESQL  POSTGRESQL
+	KubeConfig  string
 	// HTTP Server timeout configurations
 	ReadTimeoutSeconds  int
 	WriteTimeoutSeconds int
 	IdleTimeoutSeconds  int
 	MaxHeaderBytes      int
+	// Deadline of a request, its context is cancelled and 503 is returned beyond it
+	RequestTimeoutSeconds int
+	// Largest accepted request body, larger bodies are rejected with 413
+	MaxRequestBodyBytes int64
+	// Limits replacing the two above on individual routes, keyed by route pattern
+	RouteLimits map[string]RouteLimitConfig
+	// Port of the gRPC API, which is not served when 0
+	GRPCServerPort int
 	// Database operation timeout configuration
 	DbOperationTimeoutSeconds int
 	HealthCheckTimeoutSeconds int
 
 	APIKeyHeader string
 	APIKeyValue  string
-	// CORSAllowedOrigin is the single allowed origin for CORS; use "*" to allow all
-	CORSAllowedOrigin string
+	// How often the last use of user API keys is written to the database
+	APIKeyLastUsedFlushIntervalSeconds int
+	// Cross-Origin Resource Sharing of the API
+	CORS APICORSConfig
 
 	// OpenTelemetry configuration
 	OTEL OTELConfig
@@ -49,6 +65,42 @@ type Config struct {
 	// Trace Observer service configuration (for distributed tracing)
 	TraceObserver TraceObserverConfig
 
+	// Bearer token validation, tokens are trusted as asserted by the gateway when no JWKS URL is set
+	JWT JWTConfig
+
+	// Role based access control
+	RBAC RBACConfig
+
+	// Per client request rate limiting of the API
+	RateLimit RateLimitConfig
+
+	// Compression of API responses
+	Compression CompressionConfig
+
+	// Replay of requests retried with an Idempotency-Key
+	Idempotency IdempotencyConfig
+
+	// Audit log of mutating requests
+	Audit AuditConfig
+
+	// Batch updates of managed agents
+	AgentBatch AgentBatchConfig
+
+	// Retention of deleted agents and prompts
+	Trash TrashConfig
+
+	// Encryption of the credentials tools authenticate with
+	Credentials CredentialsConfig
+
+	// Delivery of agent lifecycle events to webhooks
+	Webhook WebhookConfig
+
+	// Checks of agent usage against budgets
+	Budget BudgetConfig
+
+	// Background evaluation of stored traces
+	Evaluation EvaluationConfig
+
 	IsLocalDevEnv bool
 
 	// Default Chat API configuration
@@ -56,7 +108,7 @@ type Config struct {
 	DefaultGatewayPort int
 }
 
-type AgentWorkload  struct {
+type AgentWorkload struct {
 	CORS CORSConfig
 }
 
@@ -79,6 +131,19 @@ type CORSConfig struct {
 	AllowHeaders string
 }
 
+type APICORSConfig struct {
+	// Allowed origins, "*" allows all but "null", which must be listed itself, patterns such as
+	// https://*.example.dev the subdomains of a domain
+	AllowedOrigins []string
+	// Methods, request headers and exposed response headers, the built in lists are used when empty
+	AllowedMethods []string
+	AllowedHeaders []string
+	ExposedHeaders []string
+	// Seconds browsers may cache a preflight
+	MaxAgeSeconds    int
+	AllowCredentials bool
+}
+
 type ObserverConfig struct {
 	// Observer service URL
 	URL      string
@@ -91,6 +156,133 @@ type TraceObserverConfig struct {
 	URL string
 }
 
+type JWTConfig struct {
+	// URL of the JSON Web Key Set the issuer signs tokens with
+	JWKSURL  string
+	Issuer   string
+	Audience string
+	// Tolerance applied to exp and nbf
+	ClockSkewSeconds int
+	// How long fetched keys are used before the key set is fetched again
+	JWKSRefreshIntervalSeconds int
+	// API paths served without a token, a trailing * matches any suffix
+	UnauthenticatedPaths []string
+}
+
+type RBACConfig struct {
+	// Permissions granted on top of the built in roles, keyed by role, unknown roles are added
+	RolePermissions map[string][]string
+	// Roles assumed for tokens that carry no roles claim
+	DefaultRoles []string
+}
+
+type RouteLimitConfig struct {
+	// Zero disables the bound, e.g. for long running routes
+	TimeoutSeconds int   `json:"timeoutSeconds"`
+	MaxBodyBytes   int64 `json:"maxBodyBytes"`
+}
+
+type IdempotencyConfig struct {
+	// How long the response of a request is replayed to retries with the same key
+	KeyTTLSeconds int
+	// How long a retry waits on a first execution before it is presumed lost and the request runs again
+	LockTimeoutSeconds int
+	// How often expired keys are removed from the database
+	PurgeIntervalSeconds int
+}
+
+type AuditConfig struct {
+	// Entries waiting to be written, entries recorded while the queue is full are lost
+	QueueSize int
+}
+
+type AgentBatchConfig struct {
+	// Operations a single batch may contain
+	MaxOperations int
+	// Operations of a batch applied at once
+	Workers int
+}
+
+type TrashConfig struct {
+	// How long deleted agents and prompts can be restored before they are purged
+	RetentionSeconds int
+	// How often deleted agents and prompts past the retention are purged
+	PurgeIntervalSeconds int
+}
+
+type CredentialsConfig struct {
+	// Base64 encoded 256 bit master keys by key id, credentials and webhook secrets cannot be stored when empty
+	EncryptionKeys map[string]string `json:"-"`
+	// Key new credentials are encrypted with and rotation re-encrypts existing credentials with
+	ActiveKeyID string
+}
+
+type WebhookConfig struct {
+	// Time a webhook has to answer a delivery
+	TimeoutSeconds int
+	// Attempts of a delivery before it is given up as failed
+	MaxAttempts int
+	// Delay before the first retry, doubled on every further retry up to the max
+	RetryBaseSeconds int
+	RetryMaxSeconds  int
+	// How often due deliveries are sent and how many at once
+	DispatchIntervalSeconds int
+	DispatchBatchSize       int
+	// How long finished deliveries are kept
+	DeliveryRetentionSeconds int
+	// Whether webhooks may target localhost and loopback, private or link-local addresses
+	AllowPrivateNetworks bool
+}
+
+type BudgetConfig struct {
+	// How often the usage of agents with a budget is compared against their limits
+	CheckIntervalSeconds int
+}
+
+type EvaluationConfig struct {
+	// How often workers look for evaluation runs to process
+	PollIntervalSeconds int
+	// Time an http evaluator has to score a trace
+	EvaluatorTimeoutSeconds int
+	// Whether http evaluators may run on localhost and loopback, private or link-local addresses
+	AllowPrivateNetworks bool
+}
+
+type SelfTracingConfig struct {
+	// OTLP/HTTP traces endpoint, self-tracing is disabled when empty
+	OTLPEndpoint string
+	// Share of requests traced unless the caller sampled the trace
+	SampleRatio float64
+}
+
+type LogSamplingConfig struct {
+	// Debug records with the same message written per interval
+	Initial int
+	// Every n-th further debug record is written, none when 0
+	Thereafter int
+	// Debug records are not sampled when 0
+	IntervalSeconds int
+}
+
+type CompressionConfig struct {
+	Enabled bool
+	// Responses shorter than this are sent uncompressed
+	MinBytes int
+}
+
+type RateLimitConfig struct {
+	Enabled bool
+	// Sustained rate and burst granted to each client, API keys can override both
+	RequestsPerSecond float64
+	Burst             int
+	// Proxies whose X-Forwarded-For is trusted when keying unauthenticated clients by address, addresses or CIDRs
+	TrustedProxies []string
+	// Clients tracked at once, the least recently seen is forgotten beyond it
+	MaxClients int
+	// How long the bucket of an idle client is kept
+	ClientTTLSeconds int
+}
+
 type POSTGRESQL struct {
 	Host     string
 	Port     int
@@ -17,9 +17,12 @@
 package config
 
 import (
+	"encoding/base64"
 	"fmt"
 	"log/slog"
 	"os"
+	"slices"
+	"strings"
 
 	"github.com/joho/godotenv"
 )
@@ -54,9 +57,20 @@ func loadEnvs() {
 	r := &configReader{}
 	config.ServerHost = r.readOptionalString("SERVER_HOST", "")
 	config.ServerPort = int(r.readOptionalInt64("SERVER_PORT", 8080))
+	config.GRPCServerPort = int(r.readOptionalInt64("GRPC_SERVER_PORT", 0))
 	config.AuthHeader = r.readOptionalString("AUTH_HEADER", "Authorization")
 	config.AutoMaxProcsEnabled = r.readOptionalBool("AUTO_MAX_PROCS_ENABLED", true)
-	config.CORSAllowedOrigin = r.readOptionalString("CORS_ALLOWED_ORIGIN", "http://localhost:3000")
+	// CORS_ALLOWED_ORIGIN predates the list of origins and is kept as its default
+	allowedOrigins := r.readOptionalStringList("CORS_ALLOWED_ORIGINS", []string{r.readOptionalString("CORS_ALLOWED_ORIGIN", "http://localhost:3000")})
+	config.CORS = APICORSConfig{
+		AllowedOrigins: allowedOrigins,
+		AllowedMethods: r.readOptionalStringList("CORS_ALLOWED_METHODS", nil),
+		AllowedHeaders: r.readOptionalStringList("CORS_ALLOWED_HEADERS", nil),
+		ExposedHeaders: r.readOptionalStringList("CORS_EXPOSED_HEADERS", nil),
+		MaxAgeSeconds:  int(r.readOptionalInt64("CORS_MAX_AGE_SECONDS", 86400)),
+		// Listed origins always got credentials, a "*" never did
+		AllowCredentials: r.readOptionalBool("CORS_ALLOW_CREDENTIALS", !slices.Contains(allowedOrigins, "*")),
+	}
 
 	agentWorkloadConfig.CORS = CORSConfig{
 		AllowOrigin:  r.readOptionalString("AGENT_WORKLOAD_CORS_ALLOWED_ORIGIN", "http://localhost:3000"),
@@ -66,6 +80,29 @@ func loadEnvs() {
 
 	// Logging configuration
 	config.LogLevel = r.readOptionalString("LOG_LEVEL", "INFO")
+	config.LogLevelFile = r.readOptionalString("LOG_LEVEL_FILE", "")
+	config.LogSampling = LogSamplingConfig{
+		Initial:         int(r.readOptionalInt64("LOG_SAMPLING_INITIAL", 100)),
+		Thereafter:      int(r.readOptionalInt64("LOG_SAMPLING_THEREAFTER", 100)),
+		IntervalSeconds: int(r.readOptionalInt64("LOG_SAMPLING_INTERVAL_SECONDS", 1)),
+	}
+	switch strings.ToUpper(config.LogLevel) {
+	case "DEBUG", "INFO", "WARN", "WARNING", "ERROR":
+	default:
+		r.errors = append(r.errors, fmt.Errorf("LOG_LEVEL must be one of DEBUG, INFO, WARN or ERROR, got %q", config.LogLevel))
+	}
+	if config.LogSampling.Initial < 0 || config.LogSampling.Thereafter < 0 || config.LogSampling.IntervalSeconds < 0 {
+		r.errors = append(r.errors, fmt.Errorf("LOG_SAMPLING_INITIAL, LOG_SAMPLING_THEREAFTER and LOG_SAMPLING_INTERVAL_SECONDS must not be negative"))
+	}
+
+	// Self-tracing configuration, OTEL_EXPORTER_OTLP_ENDPOINT configures the agent workloads instead
+	config.SelfTracing = SelfTracingConfig{
+		OTLPEndpoint: r.readOptionalString("SELF_TRACING_OTLP_ENDPOINT", ""),
+		SampleRatio:  r.readOptionalFloat64("SELF_TRACING_SAMPLE_RATIO", 0),
+	}
+	if config.SelfTracing.SampleRatio < 0 || config.SelfTracing.SampleRatio > 1 {
+		r.errors = append(r.errors, fmt.Errorf("SELF_TRACING_SAMPLE_RATIO must be between 0 and 1, got %v", config.SelfTracing.SampleRatio))
+	}
 
 	// read database configs
 	config.POSTGRESQL = POSTGRESQL{
@@ -93,6 +130,9 @@ func loadEnvs() {
 	config.WriteTimeoutSeconds = int(r.readOptionalInt64("HTTP_WRITE_TIMEOUT_SECONDS", 30))
 	config.IdleTimeoutSeconds = int(r.readOptionalInt64("HTTP_IDLE_TIMEOUT_SECONDS", 60))
 	config.MaxHeaderBytes = int(r.readOptionalInt64("HTTP_MAX_HEADER_BYTES", 65536)) // 1024 * 64
+	config.RequestTimeoutSeconds = int(r.readOptionalInt64("HTTP_REQUEST_TIMEOUT_SECONDS", 25))
+	config.MaxRequestBodyBytes = r.readOptionalInt64("HTTP_MAX_REQUEST_BODY_BYTES", 1048576) // 1024 * 1024
+	r.readOptionalJSON("HTTP_ROUTE_LIMITS", &config.RouteLimits)
 
 	// Database operation timeout configuration
 	config.DbOperationTimeoutSeconds = int(r.readOptionalInt64("DB_OPERATION_TIMEOUT_SECONDS", 10))
@@ -105,6 +145,7 @@ func loadEnvs() {
 
 	config.APIKeyHeader = r.readOptionalString("API_KEY_HEADER", "X-API-KEY")
 	config.APIKeyValue = r.readRequiredString("API_KEY_VALUE")
+	config.APIKeyLastUsedFlushIntervalSeconds = int(r.readOptionalInt64("API_KEY_LAST_USED_FLUSH_INTERVAL_SECONDS", 30))
 
 	// OpenTelemetry configuration
 	// Use Version from ldflags or environment variable override
@@ -133,6 +174,119 @@ func loadEnvs() {
 		URL: r.readOptionalString("TRACE_OBSERVER_URL", "http://localhost:9098"),
 	}
 
+	config.JWT = JWTConfig{
+		JWKSURL:                    r.readOptionalString("JWT_JWKS_URL", ""),
+		Issuer:                     r.readOptionalString("JWT_ISSUER", ""),
+		Audience:                   r.readOptionalString("JWT_AUDIENCE", ""),
+		ClockSkewSeconds:           int(r.readOptionalInt64("JWT_CLOCK_SKEW_SECONDS", 60)),
+		JWKSRefreshIntervalSeconds: int(r.readOptionalInt64("JWT_JWKS_REFRESH_INTERVAL_SECONDS", 300)),
+		UnauthenticatedPaths:       r.readOptionalStringList("AUTH_UNAUTHENTICATED_PATHS", nil),
+	}
+	if config.JWT.JWKSURL != "" && (config.JWT.Issuer == "" || config.JWT.Audience == "") {
+		r.errors = append(r.errors, fmt.Errorf("JWT_ISSUER and JWT_AUDIENCE are required when JWT_JWKS_URL is set"))
+	}
+	if config.JWT.ClockSkewSeconds < 0 {
+		r.errors = append(r.errors, fmt.Errorf("JWT_CLOCK_SKEW_SECONDS must not be negative, got %d", config.JWT.ClockSkewSeconds))
+	}
+	if config.JWT.JWKSRefreshIntervalSeconds <= 0 {
+		r.errors = append(r.errors, fmt.Errorf("JWT_JWKS_REFRESH_INTERVAL_SECONDS must be greater than 0, got %d", config.JWT.JWKSRefreshIntervalSeconds))
+	}
+
+	config.RBAC = RBACConfig{
+		// Tokens issued before roles were introduced keep full access until the issuer adds the claim
+		DefaultRoles: r.readOptionalStringList("RBAC_DEFAULT_ROLES", []string{"admin"}),
+	}
+	r.readOptionalJSON("RBAC_ROLE_PERMISSIONS", &config.RBAC.RolePermissions)
+
+	config.RateLimit = RateLimitConfig{
+		Enabled:           r.readOptionalBool("RATE_LIMIT_ENABLED", true),
+		RequestsPerSecond: r.readOptionalFloat64("RATE_LIMIT_REQUESTS_PER_SECOND", 20),
+		Burst:             int(r.readOptionalInt64("RATE_LIMIT_BURST", 100)),
+		TrustedProxies:    r.readOptionalStringList("RATE_LIMIT_TRUSTED_PROXIES", nil),
+		MaxClients:        int(r.readOptionalInt64("RATE_LIMIT_MAX_CLIENTS", 10000)),
+		ClientTTLSeconds:  int(r.readOptionalInt64("RATE_LIMIT_CLIENT_TTL_SECONDS", 600)),
+	}
+	validateRateLimitConfigs(config, r)
+
+	config.Compression = CompressionConfig{
+		Enabled:  r.readOptionalBool("RESPONSE_COMPRESSION_ENABLED", true),
+		MinBytes: int(r.readOptionalInt64("RESPONSE_COMPRESSION_MIN_BYTES", 1024)),
+	}
+	if config.Compression.MinBytes < 0 {
+		r.errors = append(r.errors, fmt.Errorf("RESPONSE_COMPRESSION_MIN_BYTES must not be negative, got %d", config.Compression.MinBytes))
+	}
+
+	config.Idempotency = IdempotencyConfig{
+		KeyTTLSeconds:        int(r.readOptionalInt64("IDEMPOTENCY_KEY_TTL_SECONDS", 86400)),
+		LockTimeoutSeconds:   int(r.readOptionalInt64("IDEMPOTENCY_LOCK_TIMEOUT_SECONDS", 60)),
+		PurgeIntervalSeconds: int(r.readOptionalInt64("IDEMPOTENCY_PURGE_INTERVAL_SECONDS", 3600)),
+	}
+	validateIdempotencyConfigs(config, r)
+
+	config.Audit = AuditConfig{
+		QueueSize: int(r.readOptionalInt64("AUDIT_QUEUE_SIZE", 10000)),
+	}
+	if config.Audit.QueueSize <= 0 {
+		r.errors = append(r.errors, fmt.Errorf("AUDIT_QUEUE_SIZE must be greater than 0, got %d", config.Audit.QueueSize))
+	}
+
+	config.AgentBatch = AgentBatchConfig{
+		MaxOperations: int(r.readOptionalInt64("AGENT_BATCH_MAX_OPERATIONS", 100)),
+		Workers:       int(r.readOptionalInt64("AGENT_BATCH_WORKERS", 8)),
+	}
+	if config.AgentBatch.MaxOperations <= 0 {
+		r.errors = append(r.errors, fmt.Errorf("AGENT_BATCH_MAX_OPERATIONS must be greater than 0, got %d", config.AgentBatch.MaxOperations))
+	}
+	if config.AgentBatch.Workers <= 0 {
+		r.errors = append(r.errors, fmt.Errorf("AGENT_BATCH_WORKERS must be greater than 0, got %d", config.AgentBatch.Workers))
+	}
+
+	config.Trash = TrashConfig{
+		RetentionSeconds:     int(r.readOptionalInt64("DELETED_RESOURCE_RETENTION_SECONDS", 2592000)),
+		PurgeIntervalSeconds: int(r.readOptionalInt64("DELETED_RESOURCE_PURGE_INTERVAL_SECONDS", 3600)),
+	}
+	if config.Trash.RetentionSeconds <= 0 {
+		r.errors = append(r.errors, fmt.Errorf("DELETED_RESOURCE_RETENTION_SECONDS must be greater than 0, got %d", config.Trash.RetentionSeconds))
+	}
+	if config.Trash.PurgeIntervalSeconds <= 0 {
+		r.errors = append(r.errors, fmt.Errorf("DELETED_RESOURCE_PURGE_INTERVAL_SECONDS must be greater than 0, got %d", config.Trash.PurgeIntervalSeconds))
+	}
+
+	r.readOptionalJSON("CREDENTIAL_ENCRYPTION_KEYS", &config.Credentials.EncryptionKeys)
+	config.Credentials.ActiveKeyID = r.readOptionalString("CREDENTIAL_ACTIVE_KEY_ID", "")
+	validateCredentialConfigs(config, r)
+
+	config.Webhook = WebhookConfig{
+		TimeoutSeconds:           int(r.readOptionalInt64("WEBHOOK_TIMEOUT_SECONDS", 10)),
+		MaxAttempts:              int(r.readOptionalInt64("WEBHOOK_MAX_ATTEMPTS", 8)),
+		RetryBaseSeconds:         int(r.readOptionalInt64("WEBHOOK_RETRY_BASE_SECONDS", 30)),
+		RetryMaxSeconds:          int(r.readOptionalInt64("WEBHOOK_RETRY_MAX_SECONDS", 3600)),
+		DispatchIntervalSeconds:  int(r.readOptionalInt64("WEBHOOK_DISPATCH_INTERVAL_SECONDS", 5)),
+		DispatchBatchSize:        int(r.readOptionalInt64("WEBHOOK_DISPATCH_BATCH_SIZE", 20)),
+		DeliveryRetentionSeconds: int(r.readOptionalInt64("WEBHOOK_DELIVERY_RETENTION_SECONDS", 2592000)),
+		AllowPrivateNetworks:     r.readOptionalBool("WEBHOOK_ALLOW_PRIVATE_NETWORKS", false),
+	}
+	validateWebhookConfigs(config, r)
+
+	config.Budget = BudgetConfig{
+		CheckIntervalSeconds: int(r.readOptionalInt64("BUDGET_CHECK_INTERVAL_SECONDS", 300)),
+	}
+	if config.Budget.CheckIntervalSeconds <= 0 {
+		r.errors = append(r.errors, fmt.Errorf("BUDGET_CHECK_INTERVAL_SECONDS must be greater than 0, got %d", config.Budget.CheckIntervalSeconds))
+	}
+
+	config.Evaluation = EvaluationConfig{
+		PollIntervalSeconds:     int(r.readOptionalInt64("EVALUATION_POLL_INTERVAL_SECONDS", 10)),
+		EvaluatorTimeoutSeconds: int(r.readOptionalInt64("EVALUATION_EVALUATOR_TIMEOUT_SECONDS", 30)),
+		AllowPrivateNetworks:    r.readOptionalBool("EVALUATION_ALLOW_PRIVATE_NETWORKS", false),
+	}
+	if config.Evaluation.PollIntervalSeconds <= 0 {
+		r.errors = append(r.errors, fmt.Errorf("EVALUATION_POLL_INTERVAL_SECONDS must be greater than 0, got %d", config.Evaluation.PollIntervalSeconds))
+	}
+	if config.Evaluation.EvaluatorTimeoutSeconds <= 0 {
+		r.errors = append(r.errors, fmt.Errorf("EVALUATION_EVALUATOR_TIMEOUT_SECONDS must be greater than 0, got %d", config.Evaluation.EvaluatorTimeoutSeconds))
+	}
+
 	config.IsLocalDevEnv = r.readOptionalBool("IS_LOCAL_DEV_ENV", false)
 	config.DefaultGatewayPort = int(r.readOptionalInt64("DEFAULT_GATEWAY_PORT", 9080))
 
@@ -144,10 +298,68 @@ func loadEnvs() {
 	slog.Info("configReader: configs loaded")
 }
 
+func validateIdempotencyConfigs(cfg *Config, r *configReader) {
+	if cfg.Idempotency.KeyTTLSeconds <= 0 {
+		r.errors = append(r.errors, fmt.Errorf("IDEMPOTENCY_KEY_TTL_SECONDS must be greater than 0, got %d", cfg.Idempotency.KeyTTLSeconds))
+	}
+	if cfg.Idempotency.LockTimeoutSeconds <= 0 {
+		r.errors = append(r.errors, fmt.Errorf("IDEMPOTENCY_LOCK_TIMEOUT_SECONDS must be greater than 0, got %d", cfg.Idempotency.LockTimeoutSeconds))
+	}
+	if cfg.Idempotency.PurgeIntervalSeconds <= 0 {
+		r.errors = append(r.errors, fmt.Errorf("IDEMPOTENCY_PURGE_INTERVAL_SECONDS must be greater than 0, got %d", cfg.Idempotency.PurgeIntervalSeconds))
+	}
+}
+
+func validateCredentialConfigs(cfg *Config, r *configReader) {
+	if len(cfg.Credentials.EncryptionKeys) == 0 {
+		if cfg.Credentials.ActiveKeyID != "" {
+			r.errors = append(r.errors, fmt.Errorf("CREDENTIAL_ACTIVE_KEY_ID is set but CREDENTIAL_ENCRYPTION_KEYS is empty"))
+		}
+		return
+	}
+	if _, ok := cfg.Credentials.EncryptionKeys[cfg.Credentials.ActiveKeyID]; !ok {
+		r.errors = append(r.errors, fmt.Errorf("CREDENTIAL_ACTIVE_KEY_ID must name one of CREDENTIAL_ENCRYPTION_KEYS, got %q", cfg.Credentials.ActiveKeyID))
+	}
+	for keyId, key := range cfg.Credentials.EncryptionKeys {
+		// The key itself is never logged
+		if decoded, err := base64.StdEncoding.DecodeString(key); err != nil || len(decoded) != 32 {
+			r.errors = append(r.errors, fmt.Errorf("CREDENTIAL_ENCRYPTION_KEYS key %q must be 32 bytes encoded in base64", keyId))
+		}
+	}
+}
+
+func validateWebhookConfigs(cfg *Config, r *configReader) {
+	positive := []struct {
+		name  string
+		value int
+	}{
+		{"WEBHOOK_TIMEOUT_SECONDS", cfg.Webhook.TimeoutSeconds},
+		{"WEBHOOK_MAX_ATTEMPTS", cfg.Webhook.MaxAttempts},
+		{"WEBHOOK_RETRY_BASE_SECONDS", cfg.Webhook.RetryBaseSeconds},
+		{"WEBHOOK_DISPATCH_INTERVAL_SECONDS", cfg.Webhook.DispatchIntervalSeconds},
+		{"WEBHOOK_DISPATCH_BATCH_SIZE", cfg.Webhook.DispatchBatchSize},
+		{"WEBHOOK_DELIVERY_RETENTION_SECONDS", cfg.Webhook.DeliveryRetentionSeconds},
+	}
+	for _, setting := range positive {
+		if setting.value <= 0 {
+			r.errors = append(r.errors, fmt.Errorf("%s must be greater than 0, got %d", setting.name, setting.value))
+		}
+	}
+	if cfg.Webhook.RetryMaxSeconds < cfg.Webhook.RetryBaseSeconds {
+		r.errors = append(r.errors, fmt.Errorf("WEBHOOK_RETRY_MAX_SECONDS (%d) must not be less than WEBHOOK_RETRY_BASE_SECONDS (%d)",
+			cfg.Webhook.RetryMaxSeconds, cfg.Webhook.RetryBaseSeconds))
+	}
+}
+
 func validateHTTPServerConfigs(cfg *Config, r *configReader) {
 	if cfg.ServerPort < 1 || cfg.ServerPort > 65535 {
 		r.errors = append(r.errors, fmt.Errorf("SERVER_PORT must be between 1 and 65535, got %d", cfg.ServerPort))
 	}
+	if cfg.GRPCServerPort < 0 || cfg.GRPCServerPort > 65535 {
+		r.errors = append(r.errors, fmt.Errorf("GRPC_SERVER_PORT must be between 0 and 65535, got %d", cfg.GRPCServerPort))
+	} else if cfg.GRPCServerPort == cfg.ServerPort {
+		r.errors = append(r.errors, fmt.Errorf("GRPC_SERVER_PORT must differ from SERVER_PORT (%d)", cfg.ServerPort))
+	}
 	if cfg.ReadTimeoutSeconds <= 0 {
 		r.errors = append(r.errors, fmt.Errorf("HTTP_READ_TIMEOUT_SECONDS must be greater than 0, got %d", cfg.ReadTimeoutSeconds))
 	}
@@ -161,7 +373,39 @@ func validateHTTPServerConfigs(cfg *Config, r *configReader) {
 	if cfg.IdleTimeoutSeconds <= 0 {
 		r.errors = append(r.errors, fmt.Errorf("HTTP_IDLE_TIMEOUT_SECONDS must be greater than 0, got %d", cfg.IdleTimeoutSeconds))
 	}
+	if cfg.APIKeyLastUsedFlushIntervalSeconds <= 0 {
+		r.errors = append(r.errors, fmt.Errorf("API_KEY_LAST_USED_FLUSH_INTERVAL_SECONDS must be greater than 0, got %d", cfg.APIKeyLastUsedFlushIntervalSeconds))
+	}
 	if cfg.MaxHeaderBytes < 1024 || cfg.MaxHeaderBytes > 1048576 { // 1KB to 1MB
 		r.errors = append(r.errors, fmt.Errorf("HTTP_MAX_HEADER_BYTES must be between 1024 and 1048576, got %d", cfg.MaxHeaderBytes))
 	}
+	if cfg.RequestTimeoutSeconds <= 0 {
+		r.errors = append(r.errors, fmt.Errorf("HTTP_REQUEST_TIMEOUT_SECONDS must be greater than 0, got %d", cfg.RequestTimeoutSeconds))
+	}
+	if cfg.MaxRequestBodyBytes <= 0 {
+		r.errors = append(r.errors, fmt.Errorf("HTTP_MAX_REQUEST_BODY_BYTES must be greater than 0, got %d", cfg.MaxRequestBodyBytes))
+	}
+	for route, limits := range cfg.RouteLimits {
+		if limits.TimeoutSeconds < 0 || limits.MaxBodyBytes < 0 {
+			r.errors = append(r.errors, fmt.Errorf("HTTP_ROUTE_LIMITS of %q must not be negative", route))
+		}
+	}
+}
+
+func validateRateLimitConfigs(cfg *Config, r *configReader) {
+	if !cfg.RateLimit.Enabled {
+		return
+	}
+	if cfg.RateLimit.RequestsPerSecond <= 0 {
+		r.errors = append(r.errors, fmt.Errorf("RATE_LIMIT_REQUESTS_PER_SECOND must be greater than 0, got %v", cfg.RateLimit.RequestsPerSecond))
+	}
+	if cfg.RateLimit.Burst < 1 {
+		r.errors = append(r.errors, fmt.Errorf("RATE_LIMIT_BURST must be at least 1, got %d", cfg.RateLimit.Burst))
+	}
+	if cfg.RateLimit.MaxClients < 1 {
+		r.errors = append(r.errors, fmt.Errorf("RATE_LIMIT_MAX_CLIENTS must be at least 1, got %d", cfg.RateLimit.MaxClients))
+	}
+	if cfg.RateLimit.ClientTTLSeconds <= 0 {
+		r.errors = append(r.errors, fmt.Errorf("RATE_LIMIT_CLIENT_TTL_SECONDS must be greater than 0, got %d", cfg.RateLimit.ClientTTLSeconds))
+	}
 }
@@ -17,10 +17,12 @@
 package config
 
 import (
+	"encoding/json"
 	"fmt"
 	"log/slog"
 	"os"
 	"strconv"
+	"strings"
 )
 
 type configReader struct {
@@ -67,6 +69,19 @@ func (c *configReader) readOptionalInt64(envVarName string, defaultValue int64)
 	return value
 }
 
+func (c *configReader) readOptionalFloat64(envVarName string, defaultValue float64) float64 {
+	v := os.Getenv(envVarName)
+	if v == "" {
+		return defaultValue
+	}
+	value, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		c.errors = append(c.errors, fmt.Errorf("optional environment variable %s is not a valid number [%w]", envVarName, err))
+		return 0
+	}
+	return value
+}
+
 func (c *configReader) readNullableInt64(envVarName string) *int64 {
 	v := os.Getenv(envVarName)
 	if v == "" {
@@ -91,3 +106,29 @@ func (c *configReader) readOptionalBool(envVarName string, defaultValue bool) bo
 	}
 	return value
 }
+
+// readOptionalStringList reads a comma separated list, blank entries are dropped
+func (c *configReader) readOptionalStringList(envVarName string, defaultValue []string) []string {
+	v := os.Getenv(envVarName)
+	if v == "" {
+		return defaultValue
+	}
+	var values []string
+	for _, item := range strings.Split(v, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			values = append(values, item)
+		}
+	}
+	return values
+}
+
+// readOptionalJSON decodes a JSON document into target, which is left untouched when the variable is unset
+func (c *configReader) readOptionalJSON(envVarName string, target interface{}) {
+	v := os.Getenv(envVarName)
+	if v == "" {
+		return
+	}
+	if err := json.Unmarshal([]byte(v), target); err != nil {
+		c.errors = append(c.errors, fmt.Errorf("optional environment variable %s is not valid JSON [%w]", envVarName, err))
+	}
+}
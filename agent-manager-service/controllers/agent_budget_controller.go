@@ -0,0 +1,130 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/middleware/jwtassertion"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/middleware/logger"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/models"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/services"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/utils"
+)
+
+type AgentBudgetController interface {
+	GetAgentBudget(w http.ResponseWriter, r *http.Request)
+	SaveAgentBudget(w http.ResponseWriter, r *http.Request)
+	DeleteAgentBudget(w http.ResponseWriter, r *http.Request)
+}
+
+type agentBudgetController struct {
+	agentBudgetService services.AgentBudgetService
+}
+
+// NewAgentBudgetController returns a new AgentBudgetController instance.
+func NewAgentBudgetController(agentBudgetService services.AgentBudgetService) AgentBudgetController {
+	return &agentBudgetController{
+		agentBudgetService: agentBudgetService,
+	}
+}
+
+func (c *agentBudgetController) GetAgentBudget(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.GetLogger(ctx)
+	agentId, ok := parseAgentId(w, r)
+	if !ok {
+		return
+	}
+
+	orgName := r.PathValue(utils.PathParamOrgName)
+	tokenClaims := jwtassertion.GetTokenClaims(ctx)
+	userIdpId := tokenClaims.Sub
+
+	budget, usage, err := c.agentBudgetService.GetAgentBudget(ctx, userIdpId, orgName, agentId)
+	if err != nil {
+		log.Error("GetAgentBudget: failed to get agent budget", "error", err)
+		utils.WriteErrorProblem(w, r, err, "Failed to get agent budget")
+		return
+	}
+	response := utils.ConvertToAgentBudgetResponse(budget)
+	response.Usage = usage
+	utils.WriteSuccessResponse(w, http.StatusOK, response)
+}
+
+func (c *agentBudgetController) SaveAgentBudget(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.GetLogger(ctx)
+	agentId, ok := parseAgentId(w, r)
+	if !ok {
+		return
+	}
+	payload, ok := decodeAgentBudgetRequest(w, r)
+	if !ok {
+		return
+	}
+
+	orgName := r.PathValue(utils.PathParamOrgName)
+	tokenClaims := jwtassertion.GetTokenClaims(ctx)
+	userIdpId := tokenClaims.Sub
+
+	budget, err := c.agentBudgetService.SaveAgentBudget(ctx, userIdpId, orgName, agentId, payload)
+	if err != nil {
+		log.Error("SaveAgentBudget: failed to save agent budget", "error", err)
+		utils.WriteErrorProblem(w, r, err, "Failed to save agent budget")
+		return
+	}
+	utils.WriteSuccessResponse(w, http.StatusOK, utils.ConvertToAgentBudgetResponse(budget))
+}
+
+func (c *agentBudgetController) DeleteAgentBudget(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.GetLogger(ctx)
+	agentId, ok := parseAgentId(w, r)
+	if !ok {
+		return
+	}
+
+	orgName := r.PathValue(utils.PathParamOrgName)
+	tokenClaims := jwtassertion.GetTokenClaims(ctx)
+	userIdpId := tokenClaims.Sub
+
+	if err := c.agentBudgetService.DeleteAgentBudget(ctx, userIdpId, orgName, agentId); err != nil {
+		log.Error("DeleteAgentBudget: failed to delete agent budget", "error", err)
+		utils.WriteErrorProblem(w, r, err, "Failed to delete agent budget")
+		return
+	}
+	utils.WriteSuccessResponse(w, http.StatusNoContent, "")
+}
+
+// decodeAgentBudgetRequest decodes and validates the request body, writing a problem response when it is rejected
+func decodeAgentBudgetRequest(w http.ResponseWriter, r *http.Request) (*models.AgentBudgetRequest, bool) {
+	log := logger.GetLogger(r.Context())
+	var payload models.AgentBudgetRequest
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		log.Error("failed to decode agent budget request body", "error", err)
+		utils.WriteBodyProblem(w, r, err)
+		return nil, false
+	}
+	if err := utils.ValidateAgentBudgetRequest(payload); err != nil {
+		log.Error("invalid agent budget payload", "error", err)
+		utils.WriteErrorProblem(w, r, err, "Invalid agent budget")
+		return nil, false
+	}
+	return &payload, true
+}
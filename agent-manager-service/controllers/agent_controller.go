@@ -18,7 +18,6 @@ package controllers
 
 import (
 	"encoding/json"
-	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
@@ -73,23 +72,21 @@ func (c *agentController) GetAgent(w http.ResponseWriter, r *http.Request) {
 	agent, err := c.agentService.GetAgent(ctx, userIdpId, orgName, projName, agentName)
 	if err != nil {
 		log.Error("GetAgent: failed to get agent", "error", err)
-		if errors.Is(err, utils.ErrOrganizationNotFound) {
-			utils.WriteErrorResponse(w, http.StatusNotFound, "Organization not found")
-			return
-		}
-		if errors.Is(err, utils.ErrProjectNotFound) {
-			utils.WriteErrorResponse(w, http.StatusNotFound, "Project not found")
-			return
-		}
-		if errors.Is(err, utils.ErrAgentNotFound) {
-			utils.WriteErrorResponse(w, http.StatusNotFound, "Agent not found")
-			return
-		}
-		utils.WriteErrorResponse(w, http.StatusInternalServerError, "Failed to get agent")
+		utils.WriteErrorProblem(w, r, err, "Failed to get agent")
 		return
 	}
 
 	agentResponse := utils.ConvertToAgentResponse(agent)
+	// The agent has no version of its own, its ETag follows its content
+	etag, err := utils.HashETag(agentResponse)
+	if err != nil {
+		log.Error("GetAgent: failed to compute agent ETag", "error", err)
+		utils.WriteProblemResponse(w, r, http.StatusInternalServerError, "Failed to get agent")
+		return
+	}
+	if utils.WriteNotModified(w, r, etag) {
+		return
+	}
 	utils.WriteSuccessResponse(w, http.StatusOK, agentResponse)
 }
 
@@ -114,14 +111,14 @@ func (c *agentController) ListAgents(w http.ResponseWriter, r *http.Request) {
 	limit, err := strconv.Atoi(limitStr)
 	if err != nil || limit < utils.MinLimit || limit > utils.MaxLimit {
 		log.Error("ListAgents: invalid limit parameter", "limit", limitStr)
-		utils.WriteErrorResponse(w, http.StatusBadRequest, fmt.Sprintf("Invalid limit parameter: must be between %d and %d", utils.MinLimit, utils.MaxLimit))
+		utils.WriteProblemResponse(w, r, http.StatusBadRequest, fmt.Sprintf("Invalid limit parameter: must be between %d and %d", utils.MinLimit, utils.MaxLimit))
 		return
 	}
 
 	offset, err := strconv.Atoi(offsetStr)
 	if err != nil || offset < utils.MinOffset {
 		log.Error("ListAgents: invalid offset parameter", "offset", offsetStr)
-		utils.WriteErrorResponse(w, http.StatusBadRequest, fmt.Sprintf("Invalid offset parameter: must be %d or greater", utils.MinOffset))
+		utils.WriteProblemResponse(w, r, http.StatusBadRequest, fmt.Sprintf("Invalid offset parameter: must be %d or greater", utils.MinOffset))
 		return
 	}
 
@@ -132,15 +129,7 @@ func (c *agentController) ListAgents(w http.ResponseWriter, r *http.Request) {
 	agents, total, err := c.agentService.ListAgents(ctx, userIdpId, orgName, projName, int32(limit), int32(offset))
 	if err != nil {
 		log.Error("ListAgents: failed to list agents", "error", err)
-		if errors.Is(err, utils.ErrOrganizationNotFound) {
-			utils.WriteErrorResponse(w, http.StatusNotFound, "Organization not found")
-			return
-		}
-		if errors.Is(err, utils.ErrProjectNotFound) {
-			utils.WriteErrorResponse(w, http.StatusNotFound, "Project not found")
-			return
-		}
-		utils.WriteErrorResponse(w, http.StatusInternalServerError, "Failed to list agents")
+		utils.WriteErrorProblem(w, r, err, "Failed to list agents")
 		return
 	}
 
@@ -171,32 +160,20 @@ func (c *agentController) CreateAgent(w http.ResponseWriter, r *http.Request) {
 	var payload spec.CreateAgentRequest
 	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
 		log.Error("CreateAgent: failed to decode request body", "error", err)
-		utils.WriteErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		utils.WriteBodyProblem(w, r, err)
 		return
 	}
 
 	if err := utils.ValidateAgentCreatePayload(payload); err != nil {
 		log.Error("CreateAgent: invalid agent payload", "error", err)
-		utils.WriteErrorResponse(w, http.StatusBadRequest, err.Error())
+		utils.WriteProblemResponse(w, r, http.StatusBadRequest, err.Error())
 		return
 	}
 
 	err := c.agentService.CreateAgent(ctx, userIdpId, orgName, projName, &payload)
 	if err != nil {
 		log.Error("CreateAgent: failed to create agent", "error", err)
-		if errors.Is(err, utils.ErrOrganizationNotFound) {
-			utils.WriteErrorResponse(w, http.StatusNotFound, "Organization not found")
-			return
-		}
-		if errors.Is(err, utils.ErrProjectNotFound) {
-			utils.WriteErrorResponse(w, http.StatusNotFound, "Project not found")
-			return
-		}
-		if errors.Is(err, utils.ErrAgentAlreadyExists) {
-			utils.WriteErrorResponse(w, http.StatusConflict, "Agent already exists")
-			return
-		}
-		utils.WriteErrorResponse(w, http.StatusInternalServerError, "Failed to create agent")
+		utils.WriteErrorProblem(w, r, err, "Failed to create agent")
 		return
 	}
 	response := &spec.AgentResponse{
@@ -228,15 +205,7 @@ func (c *agentController) DeleteAgent(w http.ResponseWriter, r *http.Request) {
 	err := c.agentService.DeleteAgent(ctx, userIdpId, orgName, projName, agentName)
 	if err != nil {
 		log.Error("DeleteAgent: failed to delete agent", "error", err)
-		if errors.Is(err, utils.ErrOrganizationNotFound) {
-			utils.WriteErrorResponse(w, http.StatusNotFound, "Organization not found")
-			return
-		}
-		if errors.Is(err, utils.ErrProjectNotFound) {
-			utils.WriteErrorResponse(w, http.StatusNotFound, "Project not found")
-			return
-		}
-		utils.WriteErrorResponse(w, http.StatusInternalServerError, "Failed to delete agent")
+		utils.WriteErrorProblem(w, r, err, "Failed to delete agent")
 		return
 	}
 	utils.WriteSuccessResponse(w, http.StatusNoContent, "")
@@ -264,19 +233,7 @@ func (c *agentController) BuildAgent(w http.ResponseWriter, r *http.Request) {
 	build, err := c.agentService.BuildAgent(ctx, userIdpId, orgName, projName, agentName, commitId)
 	if err != nil {
 		log.Error("BuildAgent: failed to build agent", "error", err)
-		if errors.Is(err, utils.ErrOrganizationNotFound) {
-			utils.WriteErrorResponse(w, http.StatusNotFound, "Organization not found")
-			return
-		}
-		if errors.Is(err, utils.ErrProjectNotFound) {
-			utils.WriteErrorResponse(w, http.StatusNotFound, "Project not found")
-			return
-		}
-		if errors.Is(err, utils.ErrAgentNotFound) {
-			utils.WriteErrorResponse(w, http.StatusNotFound, "Agent not found")
-			return
-		}
-		utils.WriteErrorResponse(w, http.StatusInternalServerError, "Failed to build agent")
+		utils.WriteErrorProblem(w, r, err, "Failed to build agent")
 		return
 	}
 	utils.WriteSuccessResponse(w, http.StatusAccepted, build)
@@ -298,23 +255,7 @@ func (c *agentController) GetBuildLogs(w http.ResponseWriter, r *http.Request) {
 	buildLogs, err := c.agentService.GetBuildLogs(ctx, userIdpId, orgName, projName, agentName, buildName)
 	if err != nil {
 		log.Error("GetBuildLogs: failed to get build logs", "error", err)
-		if errors.Is(err, utils.ErrOrganizationNotFound) {
-			utils.WriteErrorResponse(w, http.StatusNotFound, "Organization not found")
-			return
-		}
-		if errors.Is(err, utils.ErrProjectNotFound) {
-			utils.WriteErrorResponse(w, http.StatusNotFound, "Project not found")
-			return
-		}
-		if errors.Is(err, utils.ErrAgentNotFound) {
-			utils.WriteErrorResponse(w, http.StatusNotFound, "Agent not found")
-			return
-		}
-		if errors.Is(err, utils.ErrBuildNotFound) {
-			utils.WriteErrorResponse(w, http.StatusNotFound, "Build not found")
-			return
-		}
-		utils.WriteErrorResponse(w, http.StatusInternalServerError, "Failed to get build logs")
+		utils.WriteErrorProblem(w, r, err, "Failed to get build logs")
 		return
 	}
 	buildLogsResponse := utils.ConvertToBuildLogsResponse(*buildLogs)
@@ -338,32 +279,20 @@ func (c *agentController) DeployAgent(w http.ResponseWriter, r *http.Request) {
 	var payload spec.DeployAgentRequest
 	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
 		log.Error("DeployAgent: failed to decode request body", "error", err)
-		utils.WriteErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		utils.WriteBodyProblem(w, r, err)
 		return
 	}
 
 	if payload.ImageId == "" {
 		log.Error("DeployAgent: imageId is required in request body")
-		utils.WriteErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		utils.WriteProblemResponse(w, r, http.StatusBadRequest, "Invalid request body")
 		return
 	}
 
 	deployedEnv, err := c.agentService.DeployAgent(ctx, userIdpId, orgName, projName, agentName, &payload)
 	if err != nil {
 		log.Error("DeployAgent: failed to deploy agent", "error", err)
-		if errors.Is(err, utils.ErrOrganizationNotFound) {
-			utils.WriteErrorResponse(w, http.StatusNotFound, "Organization not found")
-			return
-		}
-		if errors.Is(err, utils.ErrProjectNotFound) {
-			utils.WriteErrorResponse(w, http.StatusNotFound, "Project not found")
-			return
-		}
-		if errors.Is(err, utils.ErrAgentNotFound) {
-			utils.WriteErrorResponse(w, http.StatusNotFound, "Agent not found")
-			return
-		}
-		utils.WriteErrorResponse(w, http.StatusInternalServerError, "Failed to deploy agent")
+		utils.WriteErrorProblem(w, r, err, "Failed to deploy agent")
 		return
 	}
 
@@ -399,14 +328,14 @@ func (c *agentController) ListAgentBuilds(w http.ResponseWriter, r *http.Request
 	limit, err := strconv.Atoi(limitStr)
 	if err != nil || limit < utils.MinLimit || limit > utils.MaxLimit {
 		log.Error("ListAgentBuilds: invalid limit parameter", "limit", limitStr)
-		utils.WriteErrorResponse(w, http.StatusBadRequest, fmt.Sprintf("Invalid limit parameter: must be between %d and %d", utils.MinLimit, utils.MaxLimit))
+		utils.WriteProblemResponse(w, r, http.StatusBadRequest, fmt.Sprintf("Invalid limit parameter: must be between %d and %d", utils.MinLimit, utils.MaxLimit))
 		return
 	}
 
 	offset, err := strconv.Atoi(offsetStr)
 	if err != nil || offset < utils.MinOffset {
 		log.Error("ListAgentBuilds: invalid offset parameter", "offset", offsetStr)
-		utils.WriteErrorResponse(w, http.StatusBadRequest, fmt.Sprintf("Invalid offset parameter: must be %d or greater", utils.MinOffset))
+		utils.WriteProblemResponse(w, r, http.StatusBadRequest, fmt.Sprintf("Invalid offset parameter: must be %d or greater", utils.MinOffset))
 		return
 	}
 
@@ -417,19 +346,7 @@ func (c *agentController) ListAgentBuilds(w http.ResponseWriter, r *http.Request
 	builds, total, err := c.agentService.ListAgentBuilds(ctx, userIdpId, orgName, projName, agentName, int32(limit), int32(offset))
 	if err != nil {
 		log.Error("ListAgentBuilds: failed to list agent builds", "error", err)
-		if errors.Is(err, utils.ErrOrganizationNotFound) {
-			utils.WriteErrorResponse(w, http.StatusNotFound, "Organization not found")
-			return
-		}
-		if errors.Is(err, utils.ErrProjectNotFound) {
-			utils.WriteErrorResponse(w, http.StatusNotFound, "Project not found")
-			return
-		}
-		if errors.Is(err, utils.ErrAgentNotFound) {
-			utils.WriteErrorResponse(w, http.StatusNotFound, "Agent not found")
-			return
-		}
-		utils.WriteErrorResponse(w, http.StatusInternalServerError, "Failed to list agent builds")
+		utils.WriteErrorProblem(w, r, err, "Failed to list agent builds")
 		return
 	}
 
@@ -459,29 +376,21 @@ func (c *agentController) GenerateName(w http.ResponseWriter, r *http.Request) {
 	var payload spec.ResourceNameRequest
 	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
 		log.Error("GenerateName: failed to decode request body", "error", err)
-		utils.WriteErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		utils.WriteBodyProblem(w, r, err)
 		return
 	}
 
 	err := utils.ValidateResourceNameRequest(payload)
 	if err != nil {
 		log.Error("GenerateName: invalid resource name payload", "error", err)
-		utils.WriteErrorResponse(w, http.StatusBadRequest, "Invalid resource name payload")
+		utils.WriteProblemResponse(w, r, http.StatusBadRequest, "Invalid resource name payload")
 		return
 	}
 
 	candidateName, err := c.agentService.GenerateName(ctx, userIdpId, orgName, payload)
 	if err != nil {
 		log.Error("GenerateAgentName: failed to generate agent name", "error", err)
-		if errors.Is(err, utils.ErrOrganizationNotFound) {
-			utils.WriteErrorResponse(w, http.StatusNotFound, "Organization not found")
-			return
-		}
-		if errors.Is(err, utils.ErrProjectNotFound) {
-			utils.WriteErrorResponse(w, http.StatusNotFound, "Project not found")
-			return
-		}
-		utils.WriteErrorResponse(w, http.StatusInternalServerError, "Failed to check agent name availability")
+		utils.WriteErrorProblem(w, r, err, "Failed to check agent name availability")
 		return
 	}
 
@@ -510,23 +419,7 @@ func (c *agentController) GetBuild(w http.ResponseWriter, r *http.Request) {
 	build, err := c.agentService.GetBuild(ctx, userIdpId, orgName, projName, agentName, buildName)
 	if err != nil {
 		log.Error("GetBuild: failed to get build", "error", err)
-		if errors.Is(err, utils.ErrOrganizationNotFound) {
-			utils.WriteErrorResponse(w, http.StatusNotFound, "Organization not found")
-			return
-		}
-		if errors.Is(err, utils.ErrProjectNotFound) {
-			utils.WriteErrorResponse(w, http.StatusNotFound, "Project not found")
-			return
-		}
-		if errors.Is(err, utils.ErrAgentNotFound) {
-			utils.WriteErrorResponse(w, http.StatusNotFound, "Agent not found")
-			return
-		}
-		if errors.Is(err, utils.ErrBuildNotFound) {
-			utils.WriteErrorResponse(w, http.StatusNotFound, "Build not found")
-			return
-		}
-		utils.WriteErrorResponse(w, http.StatusInternalServerError, "Failed to get build")
+		utils.WriteErrorProblem(w, r, err, "Failed to get build")
 		return
 	}
 
@@ -550,19 +443,7 @@ func (c *agentController) GetAgentDeployments(w http.ResponseWriter, r *http.Req
 	deployments, err := c.agentService.GetAgentDeployments(ctx, userIdpId, orgName, projName, agentName)
 	if err != nil {
 		log.Error("GetAgentDeployments: failed to get deployments", "error", err)
-		if errors.Is(err, utils.ErrOrganizationNotFound) {
-			utils.WriteErrorResponse(w, http.StatusNotFound, "Organization not found")
-			return
-		}
-		if errors.Is(err, utils.ErrProjectNotFound) {
-			utils.WriteErrorResponse(w, http.StatusNotFound, "Project not found")
-			return
-		}
-		if errors.Is(err, utils.ErrAgentNotFound) {
-			utils.WriteErrorResponse(w, http.StatusNotFound, "Agent not found")
-			return
-		}
-		utils.WriteErrorResponse(w, http.StatusInternalServerError, "Failed to get deployments")
+		utils.WriteErrorProblem(w, r, err, "Failed to get deployments")
 		return
 	}
 
@@ -581,7 +462,7 @@ func (c *agentController) GetAgentEndpoints(w http.ResponseWriter, r *http.Reque
 	environment := r.URL.Query().Get("environment")
 	if environment == "" {
 		log.Error("GetAgentEndpoints: missing required query parameter 'environment'")
-		utils.WriteErrorResponse(w, http.StatusBadRequest, "Missing required query parameter 'environment'")
+		utils.WriteProblemResponse(w, r, http.StatusBadRequest, "Missing required query parameter 'environment'")
 		return
 	}
 
@@ -592,19 +473,7 @@ func (c *agentController) GetAgentEndpoints(w http.ResponseWriter, r *http.Reque
 	endpoints, err := c.agentService.GetAgentEndpoints(ctx, userIdpId, orgName, projName, agentName, environment)
 	if err != nil {
 		log.Error("GetAgentEndpoints: failed to get agent endpoints", "error", err)
-		if errors.Is(err, utils.ErrOrganizationNotFound) {
-			utils.WriteErrorResponse(w, http.StatusNotFound, "Organization not found")
-			return
-		}
-		if errors.Is(err, utils.ErrProjectNotFound) {
-			utils.WriteErrorResponse(w, http.StatusNotFound, "Project not found")
-			return
-		}
-		if errors.Is(err, utils.ErrAgentNotFound) {
-			utils.WriteErrorResponse(w, http.StatusNotFound, "Agent not found")
-			return
-		}
-		utils.WriteErrorResponse(w, http.StatusInternalServerError, "Failed to get agent endpoints")
+		utils.WriteErrorProblem(w, r, err, "Failed to get agent endpoints")
 		return
 	}
 
@@ -624,7 +493,7 @@ func (c *agentController) GetAgentConfigurations(w http.ResponseWriter, r *http.
 	environment := r.URL.Query().Get("environment")
 	if environment == "" {
 		log.Error("GetAgentConfigurations: missing required query parameter 'environment'")
-		utils.WriteErrorResponse(w, http.StatusBadRequest, "Missing required query parameter 'environment'")
+		utils.WriteProblemResponse(w, r, http.StatusBadRequest, "Missing required query parameter 'environment'")
 		return
 	}
 
@@ -635,19 +504,7 @@ func (c *agentController) GetAgentConfigurations(w http.ResponseWriter, r *http.
 	configurations, err := c.agentService.GetAgentConfigurations(ctx, userIdpId, orgName, projName, agentName, environment)
 	if err != nil {
 		log.Error("GetAgentConfigurations: failed to get configurations", "error", err)
-		if errors.Is(err, utils.ErrOrganizationNotFound) {
-			utils.WriteErrorResponse(w, http.StatusNotFound, "Organization not found")
-			return
-		}
-		if errors.Is(err, utils.ErrProjectNotFound) {
-			utils.WriteErrorResponse(w, http.StatusNotFound, "Project not found")
-			return
-		}
-		if errors.Is(err, utils.ErrAgentNotFound) {
-			utils.WriteErrorResponse(w, http.StatusNotFound, "Agent not found")
-			return
-		}
-		utils.WriteErrorResponse(w, http.StatusInternalServerError, "Failed to get configurations")
+		utils.WriteErrorProblem(w, r, err, "Failed to get configurations")
 		return
 	}
 
@@ -0,0 +1,279 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"path"
+
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/middleware/jwtassertion"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/middleware/logger"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/models"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/services"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/utils"
+)
+
+type AgentDeploymentController interface {
+	ListAgentEnvironments(w http.ResponseWriter, r *http.Request)
+	GetAgentEnvironment(w http.ResponseWriter, r *http.Request)
+	CreateAgentEnvironment(w http.ResponseWriter, r *http.Request)
+	ListEnvironmentAgents(w http.ResponseWriter, r *http.Request)
+	// GetResolvedAgentConfig serves runtimes polling the configuration of an agent, answering 304 while it is unchanged
+	GetResolvedAgentConfig(w http.ResponseWriter, r *http.Request)
+	DeployManagedAgent(w http.ResponseWriter, r *http.Request)
+	PromoteManagedAgent(w http.ResponseWriter, r *http.Request)
+	ListAgentDeployments(w http.ResponseWriter, r *http.Request)
+}
+
+type agentDeploymentController struct {
+	agentDeploymentService services.AgentDeploymentService
+}
+
+// NewAgentDeploymentController returns a new AgentDeploymentController instance.
+func NewAgentDeploymentController(agentDeploymentService services.AgentDeploymentService) AgentDeploymentController {
+	return &agentDeploymentController{
+		agentDeploymentService: agentDeploymentService,
+	}
+}
+
+func (c *agentDeploymentController) ListAgentEnvironments(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.GetLogger(ctx)
+	orgName := r.PathValue(utils.PathParamOrgName)
+
+	tokenClaims := jwtassertion.GetTokenClaims(ctx)
+	userIdpId := tokenClaims.Sub
+
+	environments, err := c.agentDeploymentService.ListAgentEnvironments(ctx, userIdpId, orgName)
+	if err != nil {
+		log.Error("ListAgentEnvironments: failed to list agent environments", "error", err)
+		utils.WriteErrorProblem(w, r, err, "Failed to list environments")
+		return
+	}
+	response := &models.AgentEnvironmentListResponse{
+		Environments: utils.ConvertToAgentEnvironmentListResponse(environments),
+	}
+	utils.WriteSuccessResponse(w, http.StatusOK, response)
+}
+
+func (c *agentDeploymentController) GetAgentEnvironment(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.GetLogger(ctx)
+	orgName := r.PathValue(utils.PathParamOrgName)
+	envName := r.PathValue(utils.PathParamEnvName)
+
+	tokenClaims := jwtassertion.GetTokenClaims(ctx)
+	userIdpId := tokenClaims.Sub
+
+	environment, err := c.agentDeploymentService.GetAgentEnvironment(ctx, userIdpId, orgName, envName)
+	if err != nil {
+		log.Error("GetAgentEnvironment: failed to get agent environment", "error", err)
+		utils.WriteErrorProblem(w, r, err, "Failed to get environment")
+		return
+	}
+	utils.WriteSuccessResponse(w, http.StatusOK, utils.ConvertToAgentEnvironmentResponse(environment))
+}
+
+func (c *agentDeploymentController) CreateAgentEnvironment(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.GetLogger(ctx)
+	orgName := r.PathValue(utils.PathParamOrgName)
+
+	var payload models.AgentEnvironmentRequest
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		log.Error("CreateAgentEnvironment: failed to decode request body", "error", err)
+		utils.WriteBodyProblem(w, r, err)
+		return
+	}
+	if err := utils.ValidateAgentEnvironmentRequest(payload); err != nil {
+		log.Error("CreateAgentEnvironment: invalid environment payload", "error", err)
+		utils.WriteErrorProblem(w, r, err, "Invalid environment")
+		return
+	}
+
+	tokenClaims := jwtassertion.GetTokenClaims(ctx)
+	userIdpId := tokenClaims.Sub
+
+	environment, err := c.agentDeploymentService.CreateAgentEnvironment(ctx, userIdpId, orgName, &payload)
+	if err != nil {
+		log.Error("CreateAgentEnvironment: failed to create agent environment", "error", err)
+		utils.WriteErrorProblem(w, r, err, "Failed to create environment")
+		return
+	}
+	w.Header().Set("Location", path.Join(r.URL.Path, environment.Name))
+	utils.WriteSuccessResponse(w, http.StatusCreated, utils.ConvertToAgentEnvironmentResponse(environment))
+}
+
+func (c *agentDeploymentController) ListEnvironmentAgents(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.GetLogger(ctx)
+	orgName := r.PathValue(utils.PathParamOrgName)
+	envName := r.PathValue(utils.PathParamEnvName)
+	limit, offset, ok := parsePromptPagination(w, r)
+	if !ok {
+		return
+	}
+
+	tokenClaims := jwtassertion.GetTokenClaims(ctx)
+	userIdpId := tokenClaims.Sub
+
+	agents, total, err := c.agentDeploymentService.ListEnvironmentAgents(ctx, userIdpId, orgName, envName, limit, offset)
+	if err != nil {
+		log.Error("ListEnvironmentAgents: failed to list environment agents", "error", err)
+		utils.WriteErrorProblem(w, r, err, "Failed to list environment agents")
+		return
+	}
+	response := &models.EnvironmentAgentListResponse{
+		Environment: envName,
+		Agents:      utils.ConvertToEnvironmentAgentListResponse(agents),
+		Total:       total,
+		Limit:       int32(limit),
+		Offset:      int32(offset),
+	}
+	utils.WriteSuccessResponse(w, http.StatusOK, response)
+}
+
+func (c *agentDeploymentController) GetResolvedAgentConfig(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.GetLogger(ctx)
+	orgName := r.PathValue(utils.PathParamOrgName)
+	envName := r.PathValue(utils.PathParamEnvName)
+	agentId, ok := parseAgentId(w, r)
+	if !ok {
+		return
+	}
+
+	tokenClaims := jwtassertion.GetTokenClaims(ctx)
+	userIdpId := tokenClaims.Sub
+
+	config, err := c.agentDeploymentService.GetResolvedAgentConfig(ctx, userIdpId, orgName, envName, agentId)
+	if err != nil {
+		log.Error("GetResolvedAgentConfig: failed to resolve agent config", "error", err)
+		utils.WriteErrorProblem(w, r, err, "Failed to resolve agent config")
+		return
+	}
+	// The config changes with the deployment as well as with the prompt and tools it references, its ETag follows its content
+	etag, err := utils.HashETag(config)
+	if err != nil {
+		log.Error("GetResolvedAgentConfig: failed to compute config ETag", "error", err)
+		utils.WriteProblemResponse(w, r, http.StatusInternalServerError, "Failed to resolve agent config")
+		return
+	}
+	if utils.WriteNotModified(w, r, etag) {
+		return
+	}
+	utils.WriteSuccessResponse(w, http.StatusOK, config)
+}
+
+func (c *agentDeploymentController) DeployManagedAgent(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.GetLogger(ctx)
+	orgName := r.PathValue(utils.PathParamOrgName)
+	agentId, ok := parseAgentId(w, r)
+	if !ok {
+		return
+	}
+
+	var payload models.AgentDeploymentRequest
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		log.Error("DeployManagedAgent: failed to decode request body", "error", err)
+		utils.WriteBodyProblem(w, r, err)
+		return
+	}
+	if err := utils.ValidateAgentDeploymentRequest(payload); err != nil {
+		log.Error("DeployManagedAgent: invalid deployment payload", "error", err)
+		utils.WriteErrorProblem(w, r, err, "Invalid deployment")
+		return
+	}
+
+	tokenClaims := jwtassertion.GetTokenClaims(ctx)
+	userIdpId := tokenClaims.Sub
+
+	deployment, err := c.agentDeploymentService.DeployManagedAgent(ctx, userIdpId, orgName, agentId, &payload)
+	if err != nil {
+		log.Error("DeployManagedAgent: failed to deploy managed agent", "error", err)
+		utils.WriteErrorProblem(w, r, err, "Failed to deploy agent")
+		return
+	}
+	utils.WriteSuccessResponse(w, http.StatusCreated, utils.ConvertToAgentDeploymentResponse(deployment))
+}
+
+func (c *agentDeploymentController) PromoteManagedAgent(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.GetLogger(ctx)
+	orgName := r.PathValue(utils.PathParamOrgName)
+	agentId, ok := parseAgentId(w, r)
+	if !ok {
+		return
+	}
+
+	var payload models.AgentPromotionRequest
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		log.Error("PromoteManagedAgent: failed to decode request body", "error", err)
+		utils.WriteBodyProblem(w, r, err)
+		return
+	}
+	if err := utils.ValidateAgentPromotionRequest(payload); err != nil {
+		log.Error("PromoteManagedAgent: invalid promotion payload", "error", err)
+		utils.WriteErrorProblem(w, r, err, "Invalid promotion")
+		return
+	}
+
+	tokenClaims := jwtassertion.GetTokenClaims(ctx)
+	userIdpId := tokenClaims.Sub
+
+	deployment, err := c.agentDeploymentService.PromoteManagedAgent(ctx, userIdpId, orgName, agentId, &payload)
+	if err != nil {
+		log.Error("PromoteManagedAgent: failed to promote managed agent", "error", err)
+		utils.WriteErrorProblem(w, r, err, "Failed to promote agent")
+		return
+	}
+	utils.WriteSuccessResponse(w, http.StatusCreated, utils.ConvertToAgentDeploymentResponse(deployment))
+}
+
+func (c *agentDeploymentController) ListAgentDeployments(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.GetLogger(ctx)
+	orgName := r.PathValue(utils.PathParamOrgName)
+	agentId, ok := parseAgentId(w, r)
+	if !ok {
+		return
+	}
+	limit, offset, ok := parsePromptPagination(w, r)
+	if !ok {
+		return
+	}
+	envName := r.URL.Query().Get("environment")
+
+	tokenClaims := jwtassertion.GetTokenClaims(ctx)
+	userIdpId := tokenClaims.Sub
+
+	deployments, total, err := c.agentDeploymentService.ListAgentDeployments(ctx, userIdpId, orgName, agentId, envName, limit, offset)
+	if err != nil {
+		log.Error("ListAgentDeployments: failed to list agent deployments", "error", err)
+		utils.WriteErrorProblem(w, r, err, "Failed to list agent deployments")
+		return
+	}
+	response := &models.AgentDeploymentListResponse{
+		Deployments: utils.ConvertToAgentDeploymentListResponse(deployments),
+		Total:       total,
+		Limit:       int32(limit),
+		Offset:      int32(offset),
+	}
+	utils.WriteSuccessResponse(w, http.StatusOK, response)
+}
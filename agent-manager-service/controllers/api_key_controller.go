@@ -0,0 +1,153 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/middleware"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/middleware/jwtassertion"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/middleware/logger"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/models"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/services"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/utils"
+)
+
+type APIKeyController interface {
+	ListAPIKeys(w http.ResponseWriter, r *http.Request)
+	CreateAPIKey(w http.ResponseWriter, r *http.Request)
+	RevokeAPIKey(w http.ResponseWriter, r *http.Request)
+}
+
+type apiKeyController struct {
+	apiKeyService services.APIKeyService
+}
+
+// NewAPIKeyController returns a new APIKeyController instance.
+func NewAPIKeyController(apiKeyService services.APIKeyService) APIKeyController {
+	return &apiKeyController{
+		apiKeyService: apiKeyService,
+	}
+}
+
+func (c *apiKeyController) ListAPIKeys(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.GetLogger(ctx)
+	limit, offset, ok := parsePromptPagination(w, r)
+	if !ok {
+		return
+	}
+
+	orgName := r.PathValue(utils.PathParamOrgName)
+	tokenClaims := jwtassertion.GetTokenClaims(ctx)
+	userIdpId := tokenClaims.Sub
+
+	apiKeys, total, err := c.apiKeyService.ListAPIKeys(ctx, userIdpId, orgName, limit, offset)
+	if err != nil {
+		log.Error("ListAPIKeys: failed to list api keys", "error", err)
+		writeAPIKeyError(w, r, err, "Failed to list API keys")
+		return
+	}
+
+	response := &models.APIKeyListResponse{
+		APIKeys: utils.ConvertToAPIKeyListResponse(apiKeys),
+		Total:   total,
+		Limit:   int32(limit),
+		Offset:  int32(offset),
+	}
+	utils.WriteSuccessResponse(w, http.StatusOK, response)
+}
+
+func (c *apiKeyController) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.GetLogger(ctx)
+
+	var payload models.APIKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		log.Error("CreateAPIKey: failed to decode request body", "error", err)
+		utils.WriteBodyProblem(w, r, err)
+		return
+	}
+	if err := utils.ValidateAPIKeyRequest(payload, time.Now()); err != nil {
+		log.Error("CreateAPIKey: invalid api key payload", "error", err)
+		writeAPIKeyError(w, r, err, "Invalid API key")
+		return
+	}
+	// Keys cannot be granted more than their owner may do
+	granted := middleware.GetPermissions(ctx)
+	for _, scope := range payload.Scopes {
+		if !granted[utils.Permission(scope)] {
+			utils.WriteProblemResponse(w, r, http.StatusForbidden,
+				fmt.Sprintf("Missing permission %s, which the API key would be granted", scope))
+			return
+		}
+	}
+
+	orgName := r.PathValue(utils.PathParamOrgName)
+	tokenClaims := jwtassertion.GetTokenClaims(ctx)
+	userIdpId := tokenClaims.Sub
+
+	apiKey, key, err := c.apiKeyService.CreateAPIKey(ctx, userIdpId, orgName, &payload)
+	if err != nil {
+		log.Error("CreateAPIKey: failed to create api key", "error", err)
+		writeAPIKeyError(w, r, err, "Failed to create API key")
+		return
+	}
+	response := &models.APIKeyCreateResponse{
+		APIKeyResponse: utils.ConvertToAPIKeyResponse(apiKey),
+		Key:            key,
+	}
+	w.Header().Set("Location", fmt.Sprintf("%s/%s", r.URL.Path, apiKey.ID))
+	w.Header().Set("Cache-Control", "no-store")
+	utils.WriteSuccessResponse(w, http.StatusCreated, response)
+}
+
+func (c *apiKeyController) RevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.GetLogger(ctx)
+	keyId, err := uuid.Parse(r.PathValue(utils.PathParamAPIKeyId))
+	if err != nil {
+		utils.WriteProblemResponse(w, r, http.StatusNotFound, "API key not found")
+		return
+	}
+
+	orgName := r.PathValue(utils.PathParamOrgName)
+	tokenClaims := jwtassertion.GetTokenClaims(ctx)
+	userIdpId := tokenClaims.Sub
+
+	if err := c.apiKeyService.RevokeAPIKey(ctx, userIdpId, orgName, keyId); err != nil {
+		log.Error("RevokeAPIKey: failed to revoke api key", "error", err)
+		writeAPIKeyError(w, r, err, "Failed to revoke API key")
+		return
+	}
+	utils.WriteSuccessResponse(w, http.StatusNoContent, "")
+}
+
+func writeAPIKeyError(w http.ResponseWriter, r *http.Request, err error, fallback string) {
+	var validationErr *utils.ValidationError
+	if errors.As(err, &validationErr) {
+		utils.WriteProblemResponse(w, r, http.StatusBadRequest, "Invalid API key", validationErr.Errors...)
+		return
+	}
+	utils.WriteErrorProblem(w, r, err, fallback)
+}
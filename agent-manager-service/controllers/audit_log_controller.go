@@ -0,0 +1,120 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package controllers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/middleware/jwtassertion"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/middleware/logger"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/models"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/services"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/utils"
+)
+
+type AuditLogController interface {
+	ListAuditLogs(w http.ResponseWriter, r *http.Request)
+}
+
+type auditLogController struct {
+	auditService services.AuditService
+}
+
+// NewAuditLogController returns a new AuditLogController instance.
+func NewAuditLogController(auditService services.AuditService) AuditLogController {
+	return &auditLogController{
+		auditService: auditService,
+	}
+}
+
+func (c *auditLogController) ListAuditLogs(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.GetLogger(ctx)
+	limit, offset, ok := parsePromptPagination(w, r)
+	if !ok {
+		return
+	}
+	filter, ok := parseAuditLogFilter(w, r)
+	if !ok {
+		return
+	}
+
+	orgName := r.PathValue(utils.PathParamOrgName)
+	tokenClaims := jwtassertion.GetTokenClaims(ctx)
+	userIdpId := tokenClaims.Sub
+
+	auditLogs, total, err := c.auditService.ListAuditLogs(ctx, userIdpId, orgName, filter, limit, offset)
+	if err != nil {
+		log.Error("ListAuditLogs: failed to list audit logs", "error", err)
+		utils.WriteErrorProblem(w, r, err, "Failed to list audit logs")
+		return
+	}
+
+	response := &models.AuditLogListResponse{
+		AuditLogs: utils.ConvertToAuditLogListResponse(auditLogs),
+		Total:     total,
+		Limit:     int32(limit),
+		Offset:    int32(offset),
+	}
+	utils.WriteSuccessResponse(w, http.StatusOK, response)
+}
+
+// parseAuditLogFilter reads the filters of an audit log listing, writing a problem response when one is invalid
+func parseAuditLogFilter(w http.ResponseWriter, r *http.Request) (models.AuditLogFilter, bool) {
+	query := r.URL.Query()
+	filter := models.AuditLogFilter{
+		ResourceType: query.Get("resourceType"),
+		ResourceID:   query.Get("resourceId"),
+		Action:       query.Get("action"),
+	}
+	var fieldErrors []utils.FieldError
+	if actor := query.Get("actor"); actor != "" {
+		actorIdpId, err := uuid.Parse(actor)
+		if err != nil {
+			fieldErrors = append(fieldErrors, utils.FieldError{Field: "actor", Message: "must be a user id"})
+		} else {
+			filter.ActorIdpId = &actorIdpId
+		}
+	}
+	for _, bound := range []struct {
+		name   string
+		target **time.Time
+	}{{"from", &filter.From}, {"to", &filter.To}} {
+		value := query.Get(bound.name)
+		if value == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			fieldErrors = append(fieldErrors, utils.FieldError{Field: bound.name, Message: "must be an RFC 3339 timestamp"})
+			continue
+		}
+		*bound.target = &parsed
+	}
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		fieldErrors = append(fieldErrors, utils.FieldError{Field: "to", Message: "must be after from"})
+	}
+	if len(fieldErrors) > 0 {
+		logger.GetLogger(r.Context()).Error("invalid audit log filter", "errors", fieldErrors)
+		utils.WriteProblemResponse(w, r, http.StatusBadRequest, "Invalid audit log filter", fieldErrors...)
+		return models.AuditLogFilter{}, false
+	}
+	return filter, true
+}
@@ -0,0 +1,232 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/middleware/jwtassertion"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/middleware/logger"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/models"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/services"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/utils"
+)
+
+type CredentialController interface {
+	ListCredentials(w http.ResponseWriter, r *http.Request)
+	GetCredential(w http.ResponseWriter, r *http.Request)
+	CreateCredential(w http.ResponseWriter, r *http.Request)
+	UpdateCredential(w http.ResponseWriter, r *http.Request)
+	DeleteCredential(w http.ResponseWriter, r *http.Request)
+	RotateCredentialKeys(w http.ResponseWriter, r *http.Request)
+	// ResolveCredentialSecret serves the internal API agent runtimes fetch secrets from, it is not scoped to an organization
+	ResolveCredentialSecret(w http.ResponseWriter, r *http.Request)
+}
+
+type credentialController struct {
+	credentialService services.CredentialService
+}
+
+// NewCredentialController returns a new CredentialController instance.
+func NewCredentialController(credentialService services.CredentialService) CredentialController {
+	return &credentialController{
+		credentialService: credentialService,
+	}
+}
+
+func (c *credentialController) ListCredentials(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.GetLogger(ctx)
+	limit, offset, ok := parsePromptPagination(w, r)
+	if !ok {
+		return
+	}
+
+	orgName := r.PathValue(utils.PathParamOrgName)
+	tokenClaims := jwtassertion.GetTokenClaims(ctx)
+	userIdpId := tokenClaims.Sub
+
+	credentials, total, err := c.credentialService.ListCredentials(ctx, userIdpId, orgName, limit, offset)
+	if err != nil {
+		log.Error("ListCredentials: failed to list credentials", "error", err)
+		utils.WriteErrorProblem(w, r, err, "Failed to list credentials")
+		return
+	}
+	response := &models.CredentialListResponse{
+		Credentials: utils.ConvertToCredentialListResponse(credentials),
+		Total:       total,
+		Limit:       int32(limit),
+		Offset:      int32(offset),
+	}
+	utils.WriteSuccessResponse(w, http.StatusOK, response)
+}
+
+func (c *credentialController) GetCredential(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.GetLogger(ctx)
+	credentialId, ok := parseCredentialId(w, r)
+	if !ok {
+		return
+	}
+
+	orgName := r.PathValue(utils.PathParamOrgName)
+	tokenClaims := jwtassertion.GetTokenClaims(ctx)
+	userIdpId := tokenClaims.Sub
+
+	credential, err := c.credentialService.GetCredential(ctx, userIdpId, orgName, credentialId)
+	if err != nil {
+		log.Error("GetCredential: failed to get credential", "error", err)
+		utils.WriteErrorProblem(w, r, err, "Failed to get credential")
+		return
+	}
+	utils.WriteSuccessResponse(w, http.StatusOK, utils.ConvertToCredentialResponse(credential))
+}
+
+func (c *credentialController) CreateCredential(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.GetLogger(ctx)
+	payload, ok := decodeCredentialRequest(w, r, true)
+	if !ok {
+		return
+	}
+
+	orgName := r.PathValue(utils.PathParamOrgName)
+	tokenClaims := jwtassertion.GetTokenClaims(ctx)
+	userIdpId := tokenClaims.Sub
+
+	credential, err := c.credentialService.CreateCredential(ctx, userIdpId, orgName, payload)
+	if err != nil {
+		log.Error("CreateCredential: failed to create credential", "error", err)
+		utils.WriteErrorProblem(w, r, err, "Failed to create credential")
+		return
+	}
+	w.Header().Set("Location", fmt.Sprintf("%s/%s", r.URL.Path, credential.ID))
+	utils.WriteSuccessResponse(w, http.StatusCreated, utils.ConvertToCredentialResponse(credential))
+}
+
+func (c *credentialController) UpdateCredential(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.GetLogger(ctx)
+	credentialId, ok := parseCredentialId(w, r)
+	if !ok {
+		return
+	}
+	payload, ok := decodeCredentialRequest(w, r, false)
+	if !ok {
+		return
+	}
+
+	orgName := r.PathValue(utils.PathParamOrgName)
+	tokenClaims := jwtassertion.GetTokenClaims(ctx)
+	userIdpId := tokenClaims.Sub
+
+	credential, err := c.credentialService.UpdateCredential(ctx, userIdpId, orgName, credentialId, payload)
+	if err != nil {
+		log.Error("UpdateCredential: failed to update credential", "error", err)
+		utils.WriteErrorProblem(w, r, err, "Failed to update credential")
+		return
+	}
+	utils.WriteSuccessResponse(w, http.StatusOK, utils.ConvertToCredentialResponse(credential))
+}
+
+func (c *credentialController) DeleteCredential(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.GetLogger(ctx)
+	credentialId, ok := parseCredentialId(w, r)
+	if !ok {
+		return
+	}
+
+	orgName := r.PathValue(utils.PathParamOrgName)
+	tokenClaims := jwtassertion.GetTokenClaims(ctx)
+	userIdpId := tokenClaims.Sub
+
+	if err := c.credentialService.DeleteCredential(ctx, userIdpId, orgName, credentialId); err != nil {
+		log.Error("DeleteCredential: failed to delete credential", "error", err)
+		utils.WriteErrorProblem(w, r, err, "Failed to delete credential")
+		return
+	}
+	utils.WriteSuccessResponse(w, http.StatusNoContent, "")
+}
+
+func (c *credentialController) RotateCredentialKeys(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.GetLogger(ctx)
+	orgName := r.PathValue(utils.PathParamOrgName)
+	tokenClaims := jwtassertion.GetTokenClaims(ctx)
+	userIdpId := tokenClaims.Sub
+
+	rotation, err := c.credentialService.RotateCredentialKeys(ctx, userIdpId, orgName)
+	if err != nil {
+		log.Error("RotateCredentialKeys: failed to rotate credential keys", "error", err)
+		utils.WriteErrorProblem(w, r, err, "Failed to rotate credential keys")
+		return
+	}
+	utils.WriteSuccessResponse(w, http.StatusOK, rotation)
+}
+
+func (c *credentialController) ResolveCredentialSecret(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.GetLogger(ctx)
+	credentialId, ok := parseCredentialId(w, r)
+	if !ok {
+		return
+	}
+
+	credential, secret, err := c.credentialService.ResolveCredentialSecret(ctx, credentialId)
+	if err != nil {
+		log.Error("ResolveCredentialSecret: failed to resolve credential secret", "credentialId", credentialId, "error", err)
+		utils.WriteErrorProblem(w, r, err, "Failed to resolve credential")
+		return
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	utils.WriteSuccessResponse(w, http.StatusOK, &models.CredentialSecretResponse{
+		ID:     credential.ID.String(),
+		Name:   credential.Name,
+		Secret: secret,
+	})
+}
+
+func parseCredentialId(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	credentialId, err := uuid.Parse(r.PathValue(utils.PathParamCredentialId))
+	if err != nil {
+		utils.WriteProblemResponse(w, r, http.StatusNotFound, "Credential not found")
+		return uuid.Nil, false
+	}
+	return credentialId, true
+}
+
+// decodeCredentialRequest decodes and validates the request body, writing a problem response when it is rejected
+func decodeCredentialRequest(w http.ResponseWriter, r *http.Request, requireSecret bool) (*models.CredentialRequest, bool) {
+	log := logger.GetLogger(r.Context())
+	var payload models.CredentialRequest
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		log.Error("failed to decode credential request body", "error", err)
+		utils.WriteBodyProblem(w, r, err)
+		return nil, false
+	}
+	if err := utils.ValidateCredentialRequest(payload, requireSecret); err != nil {
+		log.Error("invalid credential payload", "error", err)
+		utils.WriteErrorProblem(w, r, err, "Invalid credential")
+		return nil, false
+	}
+	return &payload, true
+}
@@ -0,0 +1,166 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/middleware/jwtassertion"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/middleware/logger"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/models"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/services"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/utils"
+)
+
+type EvaluationController interface {
+	CreateEvaluation(w http.ResponseWriter, r *http.Request)
+	ListEvaluations(w http.ResponseWriter, r *http.Request)
+	GetEvaluation(w http.ResponseWriter, r *http.Request)
+	ListEvaluationResults(w http.ResponseWriter, r *http.Request)
+}
+
+type evaluationController struct {
+	evaluationService services.EvaluationService
+}
+
+// NewEvaluationController returns a new EvaluationController instance.
+func NewEvaluationController(evaluationService services.EvaluationService) EvaluationController {
+	return &evaluationController{
+		evaluationService: evaluationService,
+	}
+}
+
+func (c *evaluationController) CreateEvaluation(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.GetLogger(ctx)
+	var payload models.EvaluationRequest
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		log.Error("CreateEvaluation: failed to decode request body", "error", err)
+		utils.WriteBodyProblem(w, r, err)
+		return
+	}
+	if err := utils.ValidateEvaluationRequest(payload); err != nil {
+		log.Error("CreateEvaluation: invalid evaluation payload", "error", err)
+		utils.WriteErrorProblem(w, r, err, "Invalid evaluation")
+		return
+	}
+
+	orgName := r.PathValue(utils.PathParamOrgName)
+	tokenClaims := jwtassertion.GetTokenClaims(ctx)
+	userIdpId := tokenClaims.Sub
+
+	run, err := c.evaluationService.CreateEvaluation(ctx, userIdpId, orgName, &payload)
+	if err != nil {
+		log.Error("CreateEvaluation: failed to create evaluation", "error", err)
+		utils.WriteErrorProblem(w, r, err, "Failed to create evaluation")
+		return
+	}
+	w.Header().Set("Location", fmt.Sprintf("%s/%s", r.URL.Path, run.ID))
+	utils.WriteSuccessResponse(w, http.StatusAccepted, utils.ConvertToEvaluationResponse(run))
+}
+
+func (c *evaluationController) ListEvaluations(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.GetLogger(ctx)
+	limit, offset, ok := parsePromptPagination(w, r)
+	if !ok {
+		return
+	}
+
+	orgName := r.PathValue(utils.PathParamOrgName)
+	tokenClaims := jwtassertion.GetTokenClaims(ctx)
+	userIdpId := tokenClaims.Sub
+
+	runs, total, err := c.evaluationService.ListEvaluations(ctx, userIdpId, orgName, limit, offset)
+	if err != nil {
+		log.Error("ListEvaluations: failed to list evaluations", "error", err)
+		utils.WriteErrorProblem(w, r, err, "Failed to list evaluations")
+		return
+	}
+	response := &models.EvaluationListResponse{
+		Evaluations: utils.ConvertToEvaluationListResponse(runs),
+		Total:       total,
+		Limit:       int32(limit),
+		Offset:      int32(offset),
+	}
+	utils.WriteSuccessResponse(w, http.StatusOK, response)
+}
+
+func (c *evaluationController) GetEvaluation(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.GetLogger(ctx)
+	runId, ok := parseEvaluationId(w, r)
+	if !ok {
+		return
+	}
+
+	orgName := r.PathValue(utils.PathParamOrgName)
+	tokenClaims := jwtassertion.GetTokenClaims(ctx)
+	userIdpId := tokenClaims.Sub
+
+	run, err := c.evaluationService.GetEvaluation(ctx, userIdpId, orgName, runId)
+	if err != nil {
+		log.Error("GetEvaluation: failed to get evaluation", "error", err)
+		utils.WriteErrorProblem(w, r, err, "Failed to get evaluation")
+		return
+	}
+	utils.WriteSuccessResponse(w, http.StatusOK, utils.ConvertToEvaluationResponse(run))
+}
+
+func (c *evaluationController) ListEvaluationResults(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.GetLogger(ctx)
+	runId, ok := parseEvaluationId(w, r)
+	if !ok {
+		return
+	}
+	limit, offset, ok := parsePromptPagination(w, r)
+	if !ok {
+		return
+	}
+
+	orgName := r.PathValue(utils.PathParamOrgName)
+	tokenClaims := jwtassertion.GetTokenClaims(ctx)
+	userIdpId := tokenClaims.Sub
+
+	results, total, err := c.evaluationService.ListEvaluationResults(ctx, userIdpId, orgName, runId, limit, offset)
+	if err != nil {
+		log.Error("ListEvaluationResults: failed to list evaluation results", "error", err)
+		utils.WriteErrorProblem(w, r, err, "Failed to list evaluation results")
+		return
+	}
+	response := &models.EvaluationResultListResponse{
+		Results: utils.ConvertToEvaluationResultListResponse(results),
+		Total:   total,
+		Limit:   int32(limit),
+		Offset:  int32(offset),
+	}
+	utils.WriteSuccessResponse(w, http.StatusOK, response)
+}
+
+func parseEvaluationId(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	runId, err := uuid.Parse(r.PathValue(utils.PathParamEvaluationId))
+	if err != nil {
+		utils.WriteProblemResponse(w, r, http.StatusNotFound, "Evaluation not found")
+		return uuid.Nil, false
+	}
+	return runId, true
+}
@@ -75,21 +75,21 @@ func (c *infraResourceController) ListOrganizations(w http.ResponseWriter, r *ht
 	limit, err := strconv.Atoi(limitStr)
 	if err != nil || limit < utils.MinLimit || limit > utils.MaxLimit {
 		log.Error("ListOrganizations: invalid limit parameter", "limit", limitStr)
-		utils.WriteErrorResponse(w, http.StatusBadRequest, fmt.Sprintf("Invalid limit parameter: must be between %d and %d", utils.MinLimit, utils.MaxLimit))
+		utils.WriteProblemResponse(w, r, http.StatusBadRequest, fmt.Sprintf("Invalid limit parameter: must be between %d and %d", utils.MinLimit, utils.MaxLimit))
 		return
 	}
 
 	offset, err := strconv.Atoi(offsetStr)
 	if err != nil || offset < utils.MinOffset {
 		log.Error("ListOrganizations: invalid offset parameter", "offset", offsetStr)
-		utils.WriteErrorResponse(w, http.StatusBadRequest, fmt.Sprintf("Invalid offset parameter: must be %d or greater", utils.MinOffset))
+		utils.WriteProblemResponse(w, r, http.StatusBadRequest, fmt.Sprintf("Invalid offset parameter: must be %d or greater", utils.MinOffset))
 		return
 	}
 
-	orgs, total, err := c.infraResourceManager.ListOrganizations(ctx, userIdpId, limit, offset)
+	orgs, total, err := c.infraResourceManager.ListOrganizations(ctx, userIdpId, jwtassertion.GetOrg(ctx), limit, offset)
 	if err != nil {
 		log.Error("ListOrganizations: failed to list organizations", "error", err)
-		utils.WriteErrorResponse(w, http.StatusInternalServerError, "Failed to list organizations")
+		utils.WriteProblemResponse(w, r, http.StatusInternalServerError, "Failed to list organizations")
 		return
 	}
 
@@ -117,11 +117,7 @@ func (c *infraResourceController) GetOrganization(w http.ResponseWriter, r *http
 	org, err := c.infraResourceManager.GetOrganization(ctx, userIdpId, orgName)
 	if err != nil {
 		log.Error("GetOrganization: failed to get organization", "error", err)
-		if errors.Is(err, utils.ErrOrganizationNotFound) {
-			utils.WriteErrorResponse(w, http.StatusNotFound, "Organization not found")
-			return
-		}
-		utils.WriteErrorResponse(w, http.StatusInternalServerError, "Failed to get organization")
+		utils.WriteErrorProblem(w, r, err, "Failed to get organization")
 		return
 	}
 
@@ -150,14 +146,14 @@ func (c *infraResourceController) ListProjects(w http.ResponseWriter, r *http.Re
 	limit, err := strconv.Atoi(limitStr)
 	if err != nil || limit < utils.MinLimit || limit > utils.MaxLimit {
 		log.Error("ListProjects: invalid limit parameter", "limit", limitStr)
-		utils.WriteErrorResponse(w, http.StatusBadRequest, fmt.Sprintf("Invalid limit parameter: must be between %d and %d", utils.MinLimit, utils.MaxLimit))
+		utils.WriteProblemResponse(w, r, http.StatusBadRequest, fmt.Sprintf("Invalid limit parameter: must be between %d and %d", utils.MinLimit, utils.MaxLimit))
 		return
 	}
 
 	offset, err := strconv.Atoi(offsetStr)
 	if err != nil || offset < utils.MinOffset {
 		log.Error("ListProjects: invalid offset parameter", "offset", offsetStr)
-		utils.WriteErrorResponse(w, http.StatusBadRequest, fmt.Sprintf("Invalid offset parameter: must be %d or greater", utils.MinOffset))
+		utils.WriteProblemResponse(w, r, http.StatusBadRequest, fmt.Sprintf("Invalid offset parameter: must be %d or greater", utils.MinOffset))
 		return
 	}
 
@@ -168,11 +164,7 @@ func (c *infraResourceController) ListProjects(w http.ResponseWriter, r *http.Re
 	projects, total, err := c.infraResourceManager.ListProjects(ctx, userIdpId, orgName, limit, offset)
 	if err != nil {
 		log.Error("ListProjects: failed to list projects", "error", err)
-		if errors.Is(err, utils.ErrOrganizationNotFound) {
-			utils.WriteErrorResponse(w, http.StatusNotFound, "Organization not found")
-			return
-		}
-		utils.WriteErrorResponse(w, http.StatusInternalServerError, "Failed to list projects")
+		utils.WriteErrorProblem(w, r, err, "Failed to list projects")
 		return
 	}
 	projectList := utils.ConvertToProjectListResponse(projects)
@@ -200,44 +192,40 @@ func (c *infraResourceController) CreateProject(w http.ResponseWriter, r *http.R
 	var payload spec.CreateProjectRequest
 	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
 		log.Error("CreateProject: failed to decode request body", "error", err)
-		utils.WriteErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		utils.WriteBodyProblem(w, r, err)
 		return
 	}
 
 	if err := utils.ValidateResourceName(payload.Name, "project"); err != nil {
 		log.Error("CreateProject: invalid project name", "projectName", payload.Name, "error", err)
-		utils.WriteErrorResponse(w, http.StatusBadRequest, "Invalid project name")
+		utils.WriteProblemResponse(w, r, http.StatusBadRequest, "Invalid project name")
 		return
 	}
 
 	if err := utils.ValidateResourceDisplayName(payload.DisplayName, "project"); err != nil {
 		log.Error("CreateProject: invalid project display name", "projectDisplayName", payload.DisplayName, "error", err)
-		utils.WriteErrorResponse(w, http.StatusBadRequest, "Invalid project display name")
+		utils.WriteProblemResponse(w, r, http.StatusBadRequest, "Invalid project display name")
 		return
 	}
 
 	if payload.DeploymentPipeline == "" {
 		log.Error("CreateProject: missing deployment pipeline in request body")
-		utils.WriteErrorResponse(w, http.StatusBadRequest, "Missing deployment pipeline in request body")
+		utils.WriteProblemResponse(w, r, http.StatusBadRequest, "Missing deployment pipeline in request body")
 		return
 	}
 
 	project, err := c.infraResourceManager.CreateProject(ctx, userIdpId, orgName, payload)
 	if err != nil {
 		log.Error("CreateProject: failed to create project", "error", err)
-		if errors.Is(err, utils.ErrOrganizationNotFound) {
-			utils.WriteErrorResponse(w, http.StatusNotFound, "Organization not found")
-			return
-		}
-		if errors.Is(err, utils.ErrProjectAlreadyExists) {
-			utils.WriteErrorResponse(w, http.StatusConflict, "Project already exists")
-			return
-		}
 		if errors.Is(err, utils.ErrDeploymentPipelineNotFound) {
-			utils.WriteErrorResponse(w, http.StatusBadRequest, "Deployment pipeline not found")
+			// The pipeline is named by the request body rather than the path
+			utils.WriteProblemResponse(w, r, http.StatusBadRequest, "Deployment pipeline not found", utils.FieldError{
+				Field:   "deploymentPipeline",
+				Message: "must name an existing deployment pipeline",
+			})
 			return
 		}
-		utils.WriteErrorResponse(w, http.StatusInternalServerError, "Failed to create project")
+		utils.WriteErrorProblem(w, r, err, "Failed to create project")
 		return
 	}
 	projectResponse := spec.ProjectResponse{
@@ -267,15 +255,7 @@ func (c *infraResourceController) DeleteProject(w http.ResponseWriter, r *http.R
 	err := c.infraResourceManager.DeleteProject(ctx, userIdpId, orgName, projectName)
 	if err != nil {
 		log.Error("DeleteProject: failed to delete project", "error", err)
-		if errors.Is(err, utils.ErrOrganizationNotFound) {
-			utils.WriteErrorResponse(w, http.StatusNotFound, "Organization not found")
-			return
-		}
-		if errors.Is(err, utils.ErrProjectHasAssociatedAgents) {
-			utils.WriteErrorResponse(w, http.StatusConflict, "Project has associated agents")
-			return
-		}
-		utils.WriteErrorResponse(w, http.StatusInternalServerError, "Failed to delete project")
+		utils.WriteErrorProblem(w, r, err, "Failed to delete project")
 		return
 	}
 
@@ -307,25 +287,21 @@ func (c *infraResourceController) ListOrgDeploymentPipelines(w http.ResponseWrit
 	limit, err := strconv.Atoi(limitStr)
 	if err != nil || limit < utils.MinLimit || limit > utils.MaxLimit {
 		log.Error("ListOrgDeploymentPipelines: invalid limit parameter", "limit", limitStr)
-		utils.WriteErrorResponse(w, http.StatusBadRequest, fmt.Sprintf("Invalid limit parameter: must be between %d and %d", utils.MinLimit, utils.MaxLimit))
+		utils.WriteProblemResponse(w, r, http.StatusBadRequest, fmt.Sprintf("Invalid limit parameter: must be between %d and %d", utils.MinLimit, utils.MaxLimit))
 		return
 	}
 
 	offset, err := strconv.Atoi(offsetStr)
 	if err != nil || offset < utils.MinOffset {
 		log.Error("ListOrgDeploymentPipelines: invalid offset parameter", "offset", offsetStr)
-		utils.WriteErrorResponse(w, http.StatusBadRequest, fmt.Sprintf("Invalid offset parameter: must be %d or greater", utils.MinOffset))
+		utils.WriteProblemResponse(w, r, http.StatusBadRequest, fmt.Sprintf("Invalid offset parameter: must be %d or greater", utils.MinOffset))
 		return
 	}
 
 	deploymentPipelines, total, err := c.infraResourceManager.ListOrgDeploymentPipelines(ctx, userIdpId, orgName, limit, offset)
 	if err != nil {
 		log.Error("ListOrgDeploymentPipelines: failed to get deployment pipelines", "error", err)
-		if errors.Is(err, utils.ErrOrganizationNotFound) {
-			utils.WriteErrorResponse(w, http.StatusNotFound, "Organization not found")
-			return
-		}
-		utils.WriteErrorResponse(w, http.StatusInternalServerError, "Failed to get deployment pipelines")
+		utils.WriteErrorProblem(w, r, err, "Failed to get deployment pipelines")
 		return
 	}
 
@@ -348,15 +324,7 @@ func (c *infraResourceController) GetProject(w http.ResponseWriter, r *http.Requ
 	project, err := c.infraResourceManager.GetProject(ctx, userIdpId, orgName, projectName)
 	if err != nil {
 		log.Error("GetProject: failed to get project", "error", err)
-		if errors.Is(err, utils.ErrOrganizationNotFound) {
-			utils.WriteErrorResponse(w, http.StatusNotFound, "Organization not found")
-			return
-		}
-		if errors.Is(err, utils.ErrProjectNotFound) {
-			utils.WriteErrorResponse(w, http.StatusNotFound, "Project not found")
-			return
-		}
-		utils.WriteErrorResponse(w, http.StatusInternalServerError, "Failed to get project")
+		utils.WriteErrorProblem(w, r, err, "Failed to get project")
 		return
 	}
 
@@ -379,11 +347,7 @@ func (c *infraResourceController) ListOrgEnvironments(w http.ResponseWriter, r *
 	environments, err := c.infraResourceManager.ListOrgEnvironments(ctx, userIdpId, orgName)
 	if err != nil {
 		log.Error("ListOrgEnvironments: failed to get environments", "error", err)
-		if errors.Is(err, utils.ErrOrganizationNotFound) {
-			utils.WriteErrorResponse(w, http.StatusNotFound, "Organization not found")
-			return
-		}
-		utils.WriteErrorResponse(w, http.StatusInternalServerError, "Failed to get environments")
+		utils.WriteErrorProblem(w, r, err, "Failed to get environments")
 		return
 	}
 	environmentsListResponse := utils.ConvertToEnvironmentListResponse(environments)
@@ -405,15 +369,7 @@ func (c *infraResourceController) GetProjectDeploymentPipeline(w http.ResponseWr
 	deploymentPipeline, err := c.infraResourceManager.GetProjectDeploymentPipeline(ctx, userIdpId, orgName, projectName)
 	if err != nil {
 		log.Error("GetProjectDeploymentPipeline: failed to get deployment pipeline", "error", err)
-		if errors.Is(err, utils.ErrOrganizationNotFound) {
-			utils.WriteErrorResponse(w, http.StatusNotFound, "Organization not found")
-			return
-		}
-		if errors.Is(err, utils.ErrProjectNotFound) {
-			utils.WriteErrorResponse(w, http.StatusNotFound, "Project not found")
-			return
-		}
-		utils.WriteErrorResponse(w, http.StatusInternalServerError, "Failed to get deployment pipeline")
+		utils.WriteErrorProblem(w, r, err, "Failed to get deployment pipeline")
 		return
 	}
 
@@ -436,11 +392,7 @@ func (c *infraResourceController) GetDataplanes(w http.ResponseWriter, r *http.R
 	dataplanes, err := c.infraResourceManager.GetDataplanes(ctx, userIdpId, orgName)
 	if err != nil {
 		log.Error("GetDataplanes: failed to get dataplanes", "error", err)
-		if errors.Is(err, utils.ErrOrganizationNotFound) {
-			utils.WriteErrorResponse(w, http.StatusNotFound, "Organization not found")
-			return
-		}
-		utils.WriteErrorResponse(w, http.StatusInternalServerError, "Failed to get dataplanes")
+		utils.WriteErrorProblem(w, r, err, "Failed to get dataplanes")
 		return
 	}
 	dataplaneListResponse := utils.ConvertToDataPlaneListResponse(dataplanes)
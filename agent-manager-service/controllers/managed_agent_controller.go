@@ -0,0 +1,864 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/config"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/middleware"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/middleware/jwtassertion"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/middleware/logger"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/models"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/services"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/utils"
+)
+
+type ManagedAgentController interface {
+	ListManagedAgents(w http.ResponseWriter, r *http.Request)
+	GetManagedAgent(w http.ResponseWriter, r *http.Request)
+	CreateManagedAgent(w http.ResponseWriter, r *http.Request)
+	UpdateManagedAgent(w http.ResponseWriter, r *http.Request)
+	// PatchManagedAgent applies a JSON merge patch to the agent
+	PatchManagedAgent(w http.ResponseWriter, r *http.Request)
+	DeleteManagedAgent(w http.ResponseWriter, r *http.Request)
+	RestoreManagedAgent(w http.ResponseWriter, r *http.Request)
+	ListManagedAgentVersions(w http.ResponseWriter, r *http.Request)
+	GetManagedAgentVersion(w http.ResponseWriter, r *http.Request)
+	RollbackManagedAgent(w http.ResponseWriter, r *http.Request)
+	DiffManagedAgentVersions(w http.ResponseWriter, r *http.Request)
+	GetManagedAgentTools(w http.ResponseWriter, r *http.Request)
+	ExportManagedAgent(w http.ResponseWriter, r *http.Request)
+	ImportManagedAgent(w http.ResponseWriter, r *http.Request)
+	BatchUpdateManagedAgents(w http.ResponseWriter, r *http.Request)
+	// ListAgentFrameworks serves the schemas of the model configuration of each framework, e.g. to render forms
+	ListAgentFrameworks(w http.ResponseWriter, r *http.Request)
+	// GetManagedAgentLabels serves the internal API, it is not scoped to an organization
+	GetManagedAgentLabels(w http.ResponseWriter, r *http.Request)
+	// FindManagedAgentsByName serves the internal API, the traces observer links observed agent names to agents with it
+	FindManagedAgentsByName(w http.ResponseWriter, r *http.Request)
+}
+
+type managedAgentController struct {
+	managedAgentService services.ManagedAgentService
+	batch               config.AgentBatchConfig
+}
+
+// NewManagedAgentController returns a new ManagedAgentController instance.
+func NewManagedAgentController(managedAgentService services.ManagedAgentService, cfg config.Config) ManagedAgentController {
+	return &managedAgentController{
+		managedAgentService: managedAgentService,
+		batch:               cfg.AgentBatch,
+	}
+}
+
+func (c *managedAgentController) ListManagedAgents(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.GetLogger(ctx)
+	orgName := r.PathValue(utils.PathParamOrgName)
+
+	query := r.URL.Query()
+	page, ok := parseListPage(w, r, models.ListSortName, models.ListSortName, models.ListSortCreatedAt, models.ListSortUpdatedAt)
+	if !ok {
+		return
+	}
+	selector, ok := parseLabelSelector(w, r)
+	if !ok {
+		return
+	}
+	includeDeleted, ok := parseIncludeDeleted(w, r)
+	if !ok {
+		return
+	}
+
+	tokenClaims := jwtassertion.GetTokenClaims(ctx)
+	userIdpId := tokenClaims.Sub
+
+	filter := models.ManagedAgentFilter{
+		Search:         query.Get("search"),
+		Selector:       selector,
+		IncludeDeleted: includeDeleted,
+		Page:           page,
+	}
+	agents, next, total, err := c.managedAgentService.ListManagedAgents(ctx, userIdpId, orgName, filter)
+	if err != nil {
+		log.Error("ListManagedAgents: failed to list managed agents", "error", err)
+		writeManagedAgentError(w, r, err, "Failed to list agents")
+		return
+	}
+	counted, nextCursor, err := listPageFields(page, next, total)
+	if err != nil {
+		log.Error("ListManagedAgents: failed to encode the next cursor", "error", err)
+		utils.WriteProblemResponse(w, r, http.StatusInternalServerError, "Failed to list agents")
+		return
+	}
+
+	response := &models.ManagedAgentListResponse{
+		Agents:     utils.ConvertToManagedAgentListResponse(agents),
+		Total:      counted,
+		Limit:      int32(page.Limit),
+		Offset:     int32(page.Offset),
+		NextCursor: nextCursor,
+	}
+	utils.WriteSuccessResponse(w, http.StatusOK, response)
+}
+
+func (c *managedAgentController) GetManagedAgent(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.GetLogger(ctx)
+	orgName := r.PathValue(utils.PathParamOrgName)
+	agentId, ok := parseAgentId(w, r)
+	if !ok {
+		return
+	}
+	// Any reader may fetch a deleted agent by id, so references to it from traces still resolve
+	includeDeleted, ok := parseBoolParam(w, r, "includeDeleted")
+	if !ok {
+		return
+	}
+
+	tokenClaims := jwtassertion.GetTokenClaims(ctx)
+	userIdpId := tokenClaims.Sub
+
+	agent, err := c.managedAgentService.GetManagedAgent(ctx, userIdpId, orgName, agentId, includeDeleted)
+	if err != nil {
+		log.Error("GetManagedAgent: failed to get managed agent", "error", err)
+		writeManagedAgentError(w, r, err, "Failed to get agent")
+		return
+	}
+	if utils.WriteNotModified(w, r, utils.FormatVersionETag(agent.Version)) {
+		return
+	}
+	writeManagedAgent(w, http.StatusOK, agent)
+}
+
+func (c *managedAgentController) GetManagedAgentLabels(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.GetLogger(ctx)
+	agentId, ok := parseAgentId(w, r)
+	if !ok {
+		return
+	}
+
+	agent, err := c.managedAgentService.GetManagedAgentLabels(ctx, agentId)
+	if err != nil {
+		log.Error("GetManagedAgentLabels: failed to get managed agent", "error", err)
+		writeManagedAgentError(w, r, err, "Failed to get agent labels")
+		return
+	}
+	utils.WriteSuccessResponse(w, http.StatusOK, utils.ConvertToManagedAgentLabelsResponse(agent))
+}
+
+func (c *managedAgentController) FindManagedAgentsByName(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.GetLogger(ctx)
+	orgId, err := uuid.Parse(r.URL.Query().Get("orgId"))
+	if err != nil {
+		utils.WriteProblemResponse(w, r, http.StatusBadRequest, "Invalid orgId parameter: must be a UUID")
+		return
+	}
+	name := r.URL.Query().Get("name")
+	if name == "" {
+		utils.WriteProblemResponse(w, r, http.StatusBadRequest, "Missing parameter: name is required")
+		return
+	}
+
+	agents, err := c.managedAgentService.FindManagedAgentsByName(ctx, orgId, name)
+	if err != nil {
+		log.Error("FindManagedAgentsByName: failed to find managed agents", "error", err)
+		writeManagedAgentError(w, r, err, "Failed to find agents")
+		return
+	}
+	utils.WriteSuccessResponse(w, http.StatusOK, utils.ConvertToManagedAgentLabelsListResponse(agents))
+}
+
+func (c *managedAgentController) CreateManagedAgent(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.GetLogger(ctx)
+	orgName := r.PathValue(utils.PathParamOrgName)
+
+	tokenClaims := jwtassertion.GetTokenClaims(ctx)
+	userIdpId := tokenClaims.Sub
+
+	payload, ok := decodeManagedAgentRequest(w, r)
+	if !ok {
+		return
+	}
+
+	agent, err := c.managedAgentService.CreateManagedAgent(ctx, userIdpId, orgName, payload)
+	if err != nil {
+		log.Error("CreateManagedAgent: failed to create managed agent", "error", err)
+		writeManagedAgentError(w, r, err, "Failed to create agent")
+		return
+	}
+	w.Header().Set("Location", fmt.Sprintf("%s/%s", r.URL.Path, agent.ID))
+	writeManagedAgent(w, http.StatusCreated, agent)
+}
+
+func (c *managedAgentController) UpdateManagedAgent(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.GetLogger(ctx)
+	orgName := r.PathValue(utils.PathParamOrgName)
+	agentId, ok := parseAgentId(w, r)
+	if !ok {
+		return
+	}
+
+	tokenClaims := jwtassertion.GetTokenClaims(ctx)
+	userIdpId := tokenClaims.Sub
+
+	payload, ok := decodeManagedAgentRequest(w, r)
+	if !ok {
+		return
+	}
+	expectedVersion, ok := requireExpectedVersion(w, r, payload.Version)
+	if !ok {
+		return
+	}
+
+	agent, err := c.managedAgentService.UpdateManagedAgent(ctx, userIdpId, orgName, agentId, payload, expectedVersion)
+	if err != nil {
+		log.Error("UpdateManagedAgent: failed to update managed agent", "error", err)
+		writeManagedAgentError(w, r, err, "Failed to update agent")
+		return
+	}
+	writeManagedAgent(w, http.StatusOK, agent)
+}
+
+func (c *managedAgentController) PatchManagedAgent(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.GetLogger(ctx)
+	orgName := r.PathValue(utils.PathParamOrgName)
+	agentId, ok := parseAgentId(w, r)
+	if !ok {
+		return
+	}
+
+	tokenClaims := jwtassertion.GetTokenClaims(ctx)
+	userIdpId := tokenClaims.Sub
+
+	patch, ok := decodeMergePatch(w, r)
+	if !ok {
+		return
+	}
+	expectedVersion, ok := mergePatchVersion(w, r, patch)
+	if !ok {
+		return
+	}
+
+	agent, err := c.managedAgentService.PatchManagedAgent(ctx, userIdpId, orgName, agentId, patch, expectedVersion)
+	if err != nil {
+		log.Error("PatchManagedAgent: failed to patch managed agent", "error", err)
+		writeManagedAgentError(w, r, err, "Failed to update agent")
+		return
+	}
+	writeManagedAgent(w, http.StatusOK, agent)
+}
+
+func (c *managedAgentController) DeleteManagedAgent(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.GetLogger(ctx)
+	orgName := r.PathValue(utils.PathParamOrgName)
+	agentId, ok := parseAgentId(w, r)
+	if !ok {
+		return
+	}
+
+	tokenClaims := jwtassertion.GetTokenClaims(ctx)
+	userIdpId := tokenClaims.Sub
+
+	if err := c.managedAgentService.DeleteManagedAgent(ctx, userIdpId, orgName, agentId); err != nil {
+		log.Error("DeleteManagedAgent: failed to delete managed agent", "error", err)
+		writeManagedAgentError(w, r, err, "Failed to delete agent")
+		return
+	}
+	utils.WriteSuccessResponse(w, http.StatusNoContent, "")
+}
+
+func (c *managedAgentController) RestoreManagedAgent(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.GetLogger(ctx)
+	orgName := r.PathValue(utils.PathParamOrgName)
+	agentId, ok := parseAgentId(w, r)
+	if !ok {
+		return
+	}
+
+	tokenClaims := jwtassertion.GetTokenClaims(ctx)
+	userIdpId := tokenClaims.Sub
+
+	agent, err := c.managedAgentService.RestoreManagedAgent(ctx, userIdpId, orgName, agentId)
+	if err != nil {
+		log.Error("RestoreManagedAgent: failed to restore managed agent", "error", err)
+		writeManagedAgentError(w, r, err, "Failed to restore agent")
+		return
+	}
+	writeManagedAgent(w, http.StatusOK, agent)
+}
+
+func (c *managedAgentController) ListManagedAgentVersions(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.GetLogger(ctx)
+	orgName := r.PathValue(utils.PathParamOrgName)
+	agentId, ok := parseAgentId(w, r)
+	if !ok {
+		return
+	}
+
+	// Parse query parameters
+	limitStr := r.URL.Query().Get("limit")
+	if limitStr == "" {
+		limitStr = strconv.Itoa(utils.DefaultLimit)
+	}
+	offsetStr := r.URL.Query().Get("offset")
+	if offsetStr == "" {
+		offsetStr = strconv.Itoa(utils.DefaultOffset)
+	}
+	limit, err := strconv.Atoi(limitStr)
+	if err != nil || limit < utils.MinLimit || limit > utils.MaxLimit {
+		log.Error("ListManagedAgentVersions: invalid limit parameter", "limit", limitStr)
+		utils.WriteProblemResponse(w, r, http.StatusBadRequest, fmt.Sprintf("Invalid limit parameter: must be between %d and %d", utils.MinLimit, utils.MaxLimit))
+		return
+	}
+	offset, err := strconv.Atoi(offsetStr)
+	if err != nil || offset < utils.MinOffset {
+		log.Error("ListManagedAgentVersions: invalid offset parameter", "offset", offsetStr)
+		utils.WriteProblemResponse(w, r, http.StatusBadRequest, fmt.Sprintf("Invalid offset parameter: must be %d or greater", utils.MinOffset))
+		return
+	}
+
+	tokenClaims := jwtassertion.GetTokenClaims(ctx)
+	userIdpId := tokenClaims.Sub
+
+	agent, versions, total, err := c.managedAgentService.ListManagedAgentVersions(ctx, userIdpId, orgName, agentId, limit, offset)
+	if err != nil {
+		log.Error("ListManagedAgentVersions: failed to list managed agent versions", "error", err)
+		writeManagedAgentError(w, r, err, "Failed to list agent versions")
+		return
+	}
+
+	summaries := make([]models.ManagedAgentVersionSummary, 0, len(versions))
+	for _, version := range versions {
+		summaries = append(summaries, utils.ConvertToManagedAgentVersionSummary(version))
+	}
+	response := &models.ManagedAgentVersionListResponse{
+		ActiveVersion: agent.Version,
+		Versions:      summaries,
+		Total:         total,
+		Limit:         int32(limit),
+		Offset:        int32(offset),
+	}
+	utils.WriteSuccessResponse(w, http.StatusOK, response)
+}
+
+func (c *managedAgentController) GetManagedAgentVersion(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.GetLogger(ctx)
+	orgName := r.PathValue(utils.PathParamOrgName)
+	agentId, ok := parseAgentId(w, r)
+	if !ok {
+		return
+	}
+	version, ok := parseVersion(w, r, r.PathValue(utils.PathParamVersion), "version")
+	if !ok {
+		return
+	}
+
+	tokenClaims := jwtassertion.GetTokenClaims(ctx)
+	userIdpId := tokenClaims.Sub
+
+	agentVersion, err := c.managedAgentService.GetManagedAgentVersion(ctx, userIdpId, orgName, agentId, version)
+	if err != nil {
+		log.Error("GetManagedAgentVersion: failed to get managed agent version", "error", err)
+		writeManagedAgentError(w, r, err, "Failed to get agent version")
+		return
+	}
+	utils.WriteSuccessResponse(w, http.StatusOK, utils.ConvertToManagedAgentVersionResponse(agentVersion))
+}
+
+func (c *managedAgentController) RollbackManagedAgent(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.GetLogger(ctx)
+	orgName := r.PathValue(utils.PathParamOrgName)
+	agentId, ok := parseAgentId(w, r)
+	if !ok {
+		return
+	}
+	toVersion, ok := parseVersion(w, r, r.URL.Query().Get("to"), "to")
+	if !ok {
+		return
+	}
+	expectedVersion, ok := parseIfMatch(w, r)
+	if !ok {
+		return
+	}
+
+	tokenClaims := jwtassertion.GetTokenClaims(ctx)
+	userIdpId := tokenClaims.Sub
+
+	agent, err := c.managedAgentService.RollbackManagedAgent(ctx, userIdpId, orgName, agentId, toVersion, expectedVersion)
+	if err != nil {
+		log.Error("RollbackManagedAgent: failed to roll back managed agent", "error", err)
+		writeManagedAgentError(w, r, err, "Failed to roll back agent")
+		return
+	}
+	writeManagedAgent(w, http.StatusOK, agent)
+}
+
+func (c *managedAgentController) DiffManagedAgentVersions(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.GetLogger(ctx)
+	orgName := r.PathValue(utils.PathParamOrgName)
+	agentId, ok := parseAgentId(w, r)
+	if !ok {
+		return
+	}
+	fromVersion, ok := parseVersion(w, r, r.PathValue(utils.PathParamVersion), "version")
+	if !ok {
+		return
+	}
+	toVersion, ok := parseVersion(w, r, r.PathValue(utils.PathParamOtherVersion), "otherVersion")
+	if !ok {
+		return
+	}
+
+	tokenClaims := jwtassertion.GetTokenClaims(ctx)
+	userIdpId := tokenClaims.Sub
+
+	changes, err := c.managedAgentService.DiffManagedAgentVersions(ctx, userIdpId, orgName, agentId, fromVersion, toVersion)
+	if err != nil {
+		log.Error("DiffManagedAgentVersions: failed to diff managed agent versions", "error", err)
+		writeManagedAgentError(w, r, err, "Failed to diff agent versions")
+		return
+	}
+	response := &models.ManagedAgentVersionDiffResponse{
+		FromVersion: fromVersion,
+		ToVersion:   toVersion,
+		Changes:     changes,
+	}
+	utils.WriteSuccessResponse(w, http.StatusOK, response)
+}
+
+func (c *managedAgentController) GetManagedAgentTools(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.GetLogger(ctx)
+	orgName := r.PathValue(utils.PathParamOrgName)
+	agentId, ok := parseAgentId(w, r)
+	if !ok {
+		return
+	}
+
+	tokenClaims := jwtassertion.GetTokenClaims(ctx)
+	userIdpId := tokenClaims.Sub
+
+	tools, err := c.managedAgentService.ListManagedAgentTools(ctx, userIdpId, orgName, agentId)
+	if err != nil {
+		log.Error("GetManagedAgentTools: failed to resolve managed agent tools", "error", err)
+		writeManagedAgentError(w, r, err, "Failed to get agent tools")
+		return
+	}
+	response := &models.AgentToolsResponse{
+		AgentID: agentId.String(),
+		Tools:   tools,
+	}
+	utils.WriteSuccessResponse(w, http.StatusOK, response)
+}
+
+func (c *managedAgentController) ListAgentFrameworks(w http.ResponseWriter, r *http.Request) {
+	utils.WriteSuccessResponse(w, http.StatusOK, utils.ConvertToAgentFrameworkListResponse())
+}
+
+func (c *managedAgentController) ExportManagedAgent(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.GetLogger(ctx)
+	orgName := r.PathValue(utils.PathParamOrgName)
+	agentId, ok := parseAgentId(w, r)
+	if !ok {
+		return
+	}
+
+	tokenClaims := jwtassertion.GetTokenClaims(ctx)
+	userIdpId := tokenClaims.Sub
+
+	bundle, err := c.managedAgentService.ExportManagedAgent(ctx, userIdpId, orgName, agentId)
+	if err != nil {
+		log.Error("ExportManagedAgent: failed to export managed agent", "error", err)
+		writeManagedAgentError(w, r, err, "Failed to export agent")
+		return
+	}
+	// The bundle carries the prompt template, which agents:read alone does not reveal
+	if bundle.Prompt != nil && !requirePermission(w, r, utils.PermissionPromptsRead, "exporting an agent that uses a prompt") {
+		return
+	}
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", bundle.Agent.Name+".agent.json"))
+	utils.WriteSuccessResponse(w, http.StatusOK, bundle)
+}
+
+func (c *managedAgentController) ImportManagedAgent(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.GetLogger(ctx)
+	orgName := r.PathValue(utils.PathParamOrgName)
+
+	dryRun, ok := parseBoolParam(w, r, "dryRun")
+	if !ok {
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		log.Error("ImportManagedAgent: failed to read request body", "error", err)
+		utils.WriteBodyProblem(w, r, err)
+		return
+	}
+	bundle, err := utils.DecodeAgentBundle(body)
+	if err == nil {
+		err = utils.ValidateAgentBundle(bundle)
+	}
+	if err != nil {
+		log.Error("ImportManagedAgent: invalid bundle", "error", err)
+		var validationErr *utils.ValidationError
+		if errors.As(err, &validationErr) {
+			utils.WriteProblemResponse(w, r, http.StatusBadRequest, "Invalid bundle", validationErr.Errors...)
+			return
+		}
+		utils.WriteBodyProblem(w, r, err)
+		return
+	}
+	// Imports may create the prompt and tools of the bundle
+	if bundle.Prompt != nil && !requirePermission(w, r, utils.PermissionPromptsWrite, "importing a bundle with a prompt") {
+		return
+	}
+	if len(bundle.Tools) > 0 && !requirePermission(w, r, utils.PermissionToolsWrite, "importing a bundle with tools") {
+		return
+	}
+
+	tokenClaims := jwtassertion.GetTokenClaims(ctx)
+	userIdpId := tokenClaims.Sub
+
+	plan, agent, err := c.managedAgentService.ImportManagedAgent(ctx, userIdpId, orgName, bundle, dryRun)
+	if err != nil {
+		log.Error("ImportManagedAgent: failed to import managed agent", "error", err)
+		writeManagedAgentError(w, r, err, "Failed to import agent")
+		return
+	}
+	response := &models.AgentImportResponse{
+		AgentImportPlan: *plan,
+		DryRun:          dryRun,
+	}
+	status := http.StatusOK
+	if agent != nil {
+		agentResponse := utils.ConvertToManagedAgentResponse(agent)
+		response.Agent = &agentResponse
+		w.Header().Set("ETag", utils.FormatVersionETag(agent.Version))
+		if plan.Changes[len(plan.Changes)-1].Action == utils.ImportActionCreate {
+			w.Header().Set("Location", fmt.Sprintf("%s/%s", path.Dir(r.URL.Path), agent.ID))
+			status = http.StatusCreated
+		}
+	}
+	utils.WriteSuccessResponse(w, status, response)
+}
+
+// BatchUpdateManagedAgents applies every operation of a batch in a transaction of its own, so a failed operation
+// leaves its agent as it was and does not affect the others, and reports the outcome of each
+func (c *managedAgentController) BatchUpdateManagedAgents(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.GetLogger(ctx)
+	orgName := r.PathValue(utils.PathParamOrgName)
+
+	var payload models.AgentBatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		log.Error("BatchUpdateManagedAgents: failed to decode request body", "error", err)
+		utils.WriteBodyProblem(w, r, err)
+		return
+	}
+	agentIds, err := utils.ValidateAgentBatchRequest(payload, c.batch.MaxOperations)
+	if err != nil {
+		log.Error("BatchUpdateManagedAgents: invalid batch", "error", err)
+		var validationErr *utils.ValidationError
+		if errors.As(err, &validationErr) {
+			utils.WriteProblemResponse(w, r, http.StatusBadRequest, "Invalid batch", validationErr.Errors...)
+			return
+		}
+		utils.WriteProblemResponse(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	tokenClaims := jwtassertion.GetTokenClaims(ctx)
+	userIdpId := tokenClaims.Sub
+
+	results := make([]models.AgentBatchResult, len(payload.Operations))
+	pending := make(chan int)
+	var wg sync.WaitGroup
+	for range min(c.batch.Workers, len(payload.Operations)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range pending {
+				op := payload.Operations[i]
+				result := models.AgentBatchResult{AgentID: op.AgentID, Action: op.Action}
+				agent, changes, err := c.managedAgentService.ApplyManagedAgentOperation(ctx, userIdpId, orgName, agentIds[i], op, payload.DryRun)
+				if err != nil {
+					log.Error("BatchUpdateManagedAgents: operation failed", "agentId", op.AgentID, "action", op.Action, "error", err)
+					result.Status = utils.BatchStatusFailed
+					result.StatusCode, result.Error = describeBatchFailure(err)
+				} else {
+					result.Status = utils.BatchStatusSucceeded
+					result.StatusCode = http.StatusOK
+					result.Changes = changes
+					if agent != nil {
+						result.Version = agent.Version
+					}
+				}
+				results[i] = result
+			}
+		}()
+	}
+	for i := range payload.Operations {
+		pending <- i
+	}
+	close(pending)
+	wg.Wait()
+
+	response := &models.AgentBatchResponse{DryRun: payload.DryRun, Results: results}
+	for _, result := range results {
+		if result.Status == utils.BatchStatusSucceeded {
+			response.Succeeded++
+		} else {
+			response.Failed++
+		}
+	}
+	utils.WriteSuccessResponse(w, http.StatusOK, response)
+}
+
+// describeBatchFailure returns the status and detail a failed operation of a batch would have been answered with
+// on its own, with the rejected fields appended to the detail
+func describeBatchFailure(err error) (int, string) {
+	status, detail, fieldErrors := utils.DescribeError(err, "Failed to apply the operation")
+	var validationErr *utils.ValidationError
+	if errors.As(err, &validationErr) {
+		detail = "Invalid agent"
+	}
+	if len(fieldErrors) == 0 {
+		return status, detail
+	}
+	messages := make([]string, 0, len(fieldErrors))
+	for _, fieldErr := range fieldErrors {
+		messages = append(messages, fmt.Sprintf("%s: %s", fieldErr.Field, fieldErr.Message))
+	}
+	return status, detail + ": " + strings.Join(messages, "; ")
+}
+
+// requirePermission rejects callers without a permission the request needs beyond the one of the route
+func requirePermission(w http.ResponseWriter, r *http.Request, permission utils.Permission, reason string) bool {
+	if middleware.GetPermissions(r.Context())[permission] {
+		return true
+	}
+	utils.WriteProblemResponse(w, r, http.StatusForbidden, fmt.Sprintf("Missing permission %s, which %s requires", permission, reason))
+	return false
+}
+
+// parseBoolParam reads an optional boolean query parameter, false when absent
+func parseBoolParam(w http.ResponseWriter, r *http.Request, name string) (bool, bool) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return false, true
+	}
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		utils.WriteProblemResponse(w, r, http.StatusBadRequest, fmt.Sprintf("Invalid %s parameter: must be true or false", name))
+		return false, false
+	}
+	return parsed, true
+}
+
+// parseIncludeDeleted reads the includeDeleted parameter of list requests, listing deleted resources needs PermissionTrashManage
+func parseIncludeDeleted(w http.ResponseWriter, r *http.Request) (bool, bool) {
+	includeDeleted, ok := parseBoolParam(w, r, "includeDeleted")
+	if !ok {
+		return false, false
+	}
+	if includeDeleted && !requirePermission(w, r, utils.PermissionTrashManage, "listing deleted resources") {
+		return false, false
+	}
+	return includeDeleted, true
+}
+
+// parseLabelSelector reads the labelSelector parameter of list requests
+func parseLabelSelector(w http.ResponseWriter, r *http.Request) (models.LabelSelector, bool) {
+	selector, err := utils.ParseLabelSelector(r.URL.Query().Get("labelSelector"))
+	if err != nil {
+		logger.GetLogger(r.Context()).Error("invalid labelSelector parameter", "error", err)
+		utils.WriteProblemResponse(w, r, http.StatusBadRequest, "Invalid labelSelector parameter: "+err.Error())
+		return nil, false
+	}
+	return selector, true
+}
+
+// parseIfMatch reads the optional If-Match header, without it the last write wins
+func parseIfMatch(w http.ResponseWriter, r *http.Request) (*int32, bool) {
+	ifMatch := r.Header.Get("If-Match")
+	if ifMatch == "" || ifMatch == "*" {
+		return nil, true
+	}
+	version, err := utils.ParseVersionETag(ifMatch)
+	if err != nil {
+		logger.GetLogger(r.Context()).Error("invalid If-Match header", "error", err)
+		utils.WriteProblemResponse(w, r, http.StatusBadRequest, err.Error())
+		return nil, false
+	}
+	return &version, true
+}
+
+// requireExpectedVersion reads the version an update is based on, which must not overwrite a version the client
+// has not seen. It is taken from the If-Match header or the version field of the body, a "*" lets the last write win
+func requireExpectedVersion(w http.ResponseWriter, r *http.Request, bodyVersion *int32) (*int32, bool) {
+	if r.Header.Get("If-Match") == "" && bodyVersion == nil {
+		utils.WriteProblemResponse(w, r, http.StatusPreconditionRequired,
+			"The version being updated is required, send its ETag in the If-Match header or its number in version")
+		return nil, false
+	}
+	return parseExpectedVersion(w, r, bodyVersion)
+}
+
+// parseExpectedVersion reads the optional version a write is based on from the If-Match header or the version
+// field of the body, without either the last write wins
+func parseExpectedVersion(w http.ResponseWriter, r *http.Request, bodyVersion *int32) (*int32, bool) {
+	if r.Header.Get("If-Match") == "" {
+		if bodyVersion != nil && *bodyVersion < 1 {
+			utils.WriteProblemResponse(w, r, http.StatusBadRequest, "Invalid request body",
+				utils.FieldError{Field: "version", Constraint: utils.ConstraintMinimum, Message: "must be a positive version number"})
+			return nil, false
+		}
+		return bodyVersion, true
+	}
+	expectedVersion, ok := parseIfMatch(w, r)
+	if !ok {
+		return nil, false
+	}
+	if expectedVersion != nil && bodyVersion != nil && *expectedVersion != *bodyVersion {
+		utils.WriteProblemResponse(w, r, http.StatusBadRequest, "Invalid request body",
+			utils.FieldError{Field: "version", Constraint: utils.ConstraintConflict, Message: "must match the version of the If-Match header"})
+		return nil, false
+	}
+	return expectedVersion, true
+}
+
+// decodeMergePatch reads the JSON merge patch (RFC 7386) in the body of a PATCH request, numbers are kept as written
+func decodeMergePatch(w http.ResponseWriter, r *http.Request) (map[string]interface{}, bool) {
+	decoder := json.NewDecoder(r.Body)
+	decoder.UseNumber()
+	var patch map[string]interface{}
+	if err := decoder.Decode(&patch); err != nil {
+		logger.GetLogger(r.Context()).Error("failed to decode merge patch", "error", err)
+		utils.WriteBodyProblem(w, r, err)
+		return nil, false
+	}
+	if patch == nil {
+		utils.WriteProblemResponse(w, r, http.StatusBadRequest, "Invalid request body, a merge patch must be a JSON object")
+		return nil, false
+	}
+	return patch, true
+}
+
+// mergePatchVersion takes the version a patch is based on from the If-Match header or the version member of the
+// patch, which is removed from it. Without either the patch is applied to the version current when it is written
+func mergePatchVersion(w http.ResponseWriter, r *http.Request, patch map[string]interface{}) (*int32, bool) {
+	var bodyVersion *int32
+	if value, ok := patch["version"]; ok {
+		delete(patch, "version")
+		if value != nil {
+			number, _ := value.(json.Number)
+			version, err := strconv.ParseInt(number.String(), 10, 32)
+			if err != nil || version < 1 {
+				utils.WriteProblemResponse(w, r, http.StatusBadRequest, "Invalid request body",
+					utils.FieldError{Field: "version", Constraint: utils.ConstraintMinimum, Message: "must be a positive version number"})
+				return nil, false
+			}
+			expected := int32(version)
+			bodyVersion = &expected
+		}
+	}
+	return parseExpectedVersion(w, r, bodyVersion)
+}
+
+// parseVersion parses a positive agent version number taken from the named path or query parameter
+func parseVersion(w http.ResponseWriter, r *http.Request, value string, name string) (int32, bool) {
+	version, err := strconv.ParseInt(value, 10, 32)
+	if err != nil || version < 1 {
+		utils.WriteProblemResponse(w, r, http.StatusBadRequest, fmt.Sprintf("Invalid %s parameter: must be a positive version number", name))
+		return 0, false
+	}
+	return int32(version), true
+}
+
+// parseAgentId reads the agent ID path parameter, an ID that is not a UUID cannot match any agent
+func parseAgentId(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	agentId, err := uuid.Parse(r.PathValue(utils.PathParamAgentId))
+	if err != nil {
+		utils.WriteProblemResponse(w, r, http.StatusNotFound, "Agent not found")
+		return uuid.Nil, false
+	}
+	return agentId, true
+}
+
+// decodeManagedAgentRequest decodes and validates the request body, writing a problem response when it is rejected
+func decodeManagedAgentRequest(w http.ResponseWriter, r *http.Request) (*models.ManagedAgentRequest, bool) {
+	log := logger.GetLogger(r.Context())
+
+	var payload models.ManagedAgentRequest
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		log.Error("failed to decode managed agent request body", "error", err)
+		utils.WriteBodyProblem(w, r, err)
+		return nil, false
+	}
+
+	if err := utils.ValidateManagedAgentRequest(payload); err != nil {
+		log.Error("invalid managed agent payload", "error", err)
+		var validationErr *utils.ValidationError
+		if errors.As(err, &validationErr) {
+			utils.WriteProblemResponse(w, r, http.StatusBadRequest, "Invalid agent", validationErr.Errors...)
+			return nil, false
+		}
+		utils.WriteProblemResponse(w, r, http.StatusBadRequest, err.Error())
+		return nil, false
+	}
+	return &payload, true
+}
+
+func writeManagedAgent(w http.ResponseWriter, statusCode int, agent *models.ManagedAgent) {
+	w.Header().Set("ETag", utils.FormatVersionETag(agent.Version))
+	utils.WriteSuccessResponse(w, statusCode, utils.ConvertToManagedAgentResponse(agent))
+}
+
+func writeManagedAgentError(w http.ResponseWriter, r *http.Request, err error, fallback string) {
+	var validationErr *utils.ValidationError
+	if errors.As(err, &validationErr) {
+		utils.WriteProblemResponse(w, r, http.StatusBadRequest, "Invalid agent", validationErr.Errors...)
+		return
+	}
+	utils.WriteErrorProblem(w, r, err, fallback)
+}
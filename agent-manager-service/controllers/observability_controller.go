@@ -18,10 +18,16 @@ package controllers
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
+	"net/url"
+	"slices"
 	"strconv"
 	"time"
 
+	"github.com/google/uuid"
+
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/middleware/jwtassertion"
 	"github.com/wso2/ai-agent-management-platform/agent-manager-service/middleware/logger"
 	"github.com/wso2/ai-agent-management-platform/agent-manager-service/services"
 	"github.com/wso2/ai-agent-management-platform/agent-manager-service/utils"
@@ -30,16 +36,20 @@ import (
 type ObservabilityController interface {
 	ListTraces(w http.ResponseWriter, r *http.Request)
 	GetTrace(w http.ResponseWriter, r *http.Request)
+	ListAgentTraces(w http.ResponseWriter, r *http.Request)
+	GetAgentMetrics(w http.ResponseWriter, r *http.Request)
 }
 
 type observabilityController struct {
 	observabilityService services.ObservabilityManagerService
+	traceViewService     services.TraceViewService
 }
 
 // NewObservabilityController returns a new ObservabilityController instance.
-func NewObservabilityController(observabilityService services.ObservabilityManagerService) ObservabilityController {
+func NewObservabilityController(observabilityService services.ObservabilityManagerService, traceViewService services.TraceViewService) ObservabilityController {
 	return &observabilityController{
 		observabilityService: observabilityService,
+		traceViewService:     traceViewService,
 	}
 }
 
@@ -66,14 +76,14 @@ func (c *observabilityController) ListTraces(w http.ResponseWriter, r *http.Requ
 	limit, err := strconv.Atoi(limitStr)
 	if err != nil || limit < 1 || limit > 100 {
 		log.Error("ListTraces: invalid limit parameter", "limit", limitStr)
-		utils.WriteErrorResponse(w, http.StatusBadRequest, "Invalid limit parameter: must be between 1 and 100")
+		utils.WriteProblemResponse(w, r, http.StatusBadRequest, "Invalid limit parameter: must be between 1 and 100")
 		return
 	}
 
 	offset, err := strconv.Atoi(offsetStr)
 	if err != nil || offset < 0 {
 		log.Error("ListTraces: invalid offset parameter", "offset", offsetStr)
-		utils.WriteErrorResponse(w, http.StatusBadRequest, "Invalid offset parameter: must be 0 or greater")
+		utils.WriteProblemResponse(w, r, http.StatusBadRequest, "Invalid offset parameter: must be 0 or greater")
 		return
 	}
 
@@ -81,7 +91,7 @@ func (c *observabilityController) ListTraces(w http.ResponseWriter, r *http.Requ
 	environment := r.URL.Query().Get("environment")
 	if environment == "" {
 		log.Error("ListTraces: environment is required")
-		utils.WriteErrorResponse(w, http.StatusBadRequest, "Missing parameter: environment is required")
+		utils.WriteProblemResponse(w, r, http.StatusBadRequest, "Missing parameter: environment is required")
 		return
 	}
 
@@ -92,26 +102,26 @@ func (c *observabilityController) ListTraces(w http.ResponseWriter, r *http.Requ
 	if startTime != "" || endTime != "" {
 		if startTime == "" {
 			log.Error("ListTraces: startTime is required")
-			utils.WriteErrorResponse(w, http.StatusBadRequest, "Missing parameter: startTime is required")
+			utils.WriteProblemResponse(w, r, http.StatusBadRequest, "Missing parameter: startTime is required")
 			return
 		}
 		if endTime == "" {
 			log.Error("ListTraces: endTime is required")
-			utils.WriteErrorResponse(w, http.StatusBadRequest, "Missing parameter: endTime is required")
+			utils.WriteProblemResponse(w, r, http.StatusBadRequest, "Missing parameter: endTime is required")
 			return
 		}
 
 		// Validate RFC3339 format for startTime
 		if _, err := time.Parse(time.RFC3339, startTime); err != nil {
 			log.Error("ListTraces: invalid startTime format", "startTime", startTime, "error", err)
-			utils.WriteErrorResponse(w, http.StatusBadRequest, "Invalid startTime format: must be RFC3339 (e.g., 2025-12-20T10:00:00Z)")
+			utils.WriteProblemResponse(w, r, http.StatusBadRequest, "Invalid startTime format: must be RFC3339 (e.g., 2025-12-20T10:00:00Z)")
 			return
 		}
 
 		// Validate RFC3339 format for endTime
 		if _, err := time.Parse(time.RFC3339, endTime); err != nil {
 			log.Error("ListTraces: invalid endTime format", "endTime", endTime, "error", err)
-			utils.WriteErrorResponse(w, http.StatusBadRequest, "Invalid endTime format: must be RFC3339 (e.g., 2025-12-20T10:00:00Z)")
+			utils.WriteProblemResponse(w, r, http.StatusBadRequest, "Invalid endTime format: must be RFC3339 (e.g., 2025-12-20T10:00:00Z)")
 			return
 		}
 	}
@@ -122,7 +132,7 @@ func (c *observabilityController) ListTraces(w http.ResponseWriter, r *http.Requ
 	}
 	if sortOrder != "asc" && sortOrder != "desc" {
 		log.Error("ListTraces: invalid sortOrder parameter", "sortOrder", sortOrder)
-		utils.WriteErrorResponse(w, http.StatusBadRequest, "Invalid sortOrder parameter: must be 'asc' or 'desc'")
+		utils.WriteProblemResponse(w, r, http.StatusBadRequest, "Invalid sortOrder parameter: must be 'asc' or 'desc'")
 		return
 	}
 
@@ -143,7 +153,7 @@ func (c *observabilityController) ListTraces(w http.ResponseWriter, r *http.Requ
 	response, err := c.observabilityService.ListTraces(ctx, params)
 	if err != nil {
 		log.Error("ListTraces: failed to list traces", "serviceName", agentName, "error", err)
-		utils.WriteErrorResponse(w, http.StatusInternalServerError, "Failed to retrieve traces")
+		utils.WriteProblemResponse(w, r, http.StatusInternalServerError, "Failed to retrieve traces")
 		return
 	}
 
@@ -164,7 +174,7 @@ func (c *observabilityController) GetTrace(w http.ResponseWriter, r *http.Reques
 	// Validate traceID
 	if traceID == "" {
 		log.Error("GetTrace: traceId is required")
-		utils.WriteErrorResponse(w, http.StatusBadRequest, "Missing parameter: traceId is required")
+		utils.WriteProblemResponse(w, r, http.StatusBadRequest, "Missing parameter: traceId is required")
 		return
 	}
 
@@ -172,7 +182,7 @@ func (c *observabilityController) GetTrace(w http.ResponseWriter, r *http.Reques
 	environment := r.URL.Query().Get("environment")
 	if environment == "" {
 		log.Error("GetTrace: environment is required")
-		utils.WriteErrorResponse(w, http.StatusBadRequest, "Missing parameter: environment is required")
+		utils.WriteProblemResponse(w, r, http.StatusBadRequest, "Missing parameter: environment is required")
 		return
 	}
 
@@ -190,15 +200,225 @@ func (c *observabilityController) GetTrace(w http.ResponseWriter, r *http.Reques
 	if err != nil {
 		// Check if it's a "not found" error
 		if errors.Is(err, services.ErrTraceNotFound) {
-			utils.WriteErrorResponse(w, http.StatusNotFound, "Trace not found")
+			utils.WriteProblemResponse(w, r, http.StatusNotFound, "Trace not found")
 			return
 		}
 		// Other errors are internal server errors
 		log.Error("GetTrace: failed to get trace details", "traceId", traceID, "agentName", agentName, "error", err)
-		utils.WriteErrorResponse(w, http.StatusInternalServerError, "Failed to retrieve trace details")
+		utils.WriteProblemResponse(w, r, http.StatusInternalServerError, "Failed to retrieve trace details")
 		return
 	}
 
 	log.Info("GetTrace: successfully retrieved trace details", "traceId", traceID, "agentName", agentName, "spanCount", response.TotalCount)
 	utils.WriteSuccessResponse(w, http.StatusOK, response)
 }
+
+// agentMetricsIntervals lists the intervals the trace observer buckets metrics by
+var agentMetricsIntervals = map[string]bool{"1m": true, "5m": true, "1h": true, "1d": true}
+
+func (c *observabilityController) ListAgentTraces(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.GetLogger(ctx)
+	orgName := r.PathValue(utils.PathParamOrgName)
+	agentId, ok := parseAgentId(w, r)
+	if !ok {
+		return
+	}
+
+	limit, offset, ok := parseTracesPage(w, r)
+	if !ok {
+		return
+	}
+	startTime, endTime, ok := parseTracesTimeRange(w, r, false)
+	if !ok {
+		return
+	}
+	userIdpId := jwtassertion.GetTokenClaims(ctx).Sub
+	query, ok := c.applyTraceView(w, r, userIdpId, orgName)
+	if !ok {
+		return
+	}
+	sortOrder := query.Get("sortOrder")
+	if sortOrder == "" {
+		sortOrder = "desc"
+	}
+	if sortOrder != "asc" && sortOrder != "desc" {
+		utils.WriteProblemResponse(w, r, http.StatusBadRequest, "Invalid sortOrder parameter: must be 'asc' or 'desc'")
+		return
+	}
+	sort := query.Get("sort")
+	if sort != "" && !slices.Contains(utils.TraceSorts, sort) {
+		utils.WriteProblemResponse(w, r, http.StatusBadRequest, "Invalid sort parameter: must be 'startTime', 'duration' or 'tokens'")
+		return
+	}
+
+	req := services.AgentTracesRequest{
+		OrgName:   orgName,
+		AgentID:   agentId,
+		StartTime: startTime,
+		EndTime:   endTime,
+		Limit:     limit,
+		Offset:    offset,
+		SortOrder: sortOrder,
+		Sort:      sort,
+	}
+	if !parseTraceListFilters(w, r, query, &req) {
+		return
+	}
+	response, err := c.observabilityService.ListAgentTraces(ctx, userIdpId, req)
+	if err != nil {
+		log.Error("ListAgentTraces: failed to list agent traces", "agentId", agentId, "error", err)
+		utils.WriteErrorProblem(w, r, err, "Failed to retrieve traces")
+		return
+	}
+	utils.WriteSuccessResponse(w, http.StatusOK, response)
+}
+
+func (c *observabilityController) GetAgentMetrics(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.GetLogger(ctx)
+	orgName := r.PathValue(utils.PathParamOrgName)
+	agentId, ok := parseAgentId(w, r)
+	if !ok {
+		return
+	}
+
+	startTime, endTime, ok := parseTracesTimeRange(w, r, true)
+	if !ok {
+		return
+	}
+	interval := r.URL.Query().Get("interval")
+	if interval == "" {
+		interval = "1h"
+	}
+	if !agentMetricsIntervals[interval] {
+		utils.WriteProblemResponse(w, r, http.StatusBadRequest, "Invalid interval parameter: must be '1m', '5m', '1h' or '1d'")
+		return
+	}
+
+	userIdpId := jwtassertion.GetTokenClaims(ctx).Sub
+	response, err := c.observabilityService.GetAgentMetrics(ctx, userIdpId, services.AgentMetricsRequest{
+		OrgName:   orgName,
+		AgentID:   agentId,
+		StartTime: startTime,
+		EndTime:   endTime,
+		Interval:  interval,
+	})
+	if err != nil {
+		if errors.Is(err, services.ErrTraceQueryRejected) {
+			utils.WriteProblemResponse(w, r, http.StatusBadRequest, "The time range holds too many intervals, narrow it or widen the interval")
+			return
+		}
+		log.Error("GetAgentMetrics: failed to get agent metrics", "agentId", agentId, "error", err)
+		utils.WriteErrorProblem(w, r, err, "Failed to retrieve agent metrics")
+		return
+	}
+	utils.WriteSuccessResponse(w, http.StatusOK, response)
+}
+
+// applyTraceView returns the query of a trace listing merged into the saved view its view parameter selects,
+// writing a problem response when the view is unknown or references filters that no longer exist
+func (c *observabilityController) applyTraceView(w http.ResponseWriter, r *http.Request, userIdpId uuid.UUID, orgName string) (url.Values, bool) {
+	query := r.URL.Query()
+	viewIdStr := query.Get(utils.TraceViewQueryParam)
+	if viewIdStr == "" {
+		return query, true
+	}
+	viewId, err := uuid.Parse(viewIdStr)
+	if err != nil {
+		utils.WriteProblemResponse(w, r, http.StatusBadRequest, "Invalid view parameter: must be a UUID")
+		return nil, false
+	}
+	view, err := c.traceViewService.GetTraceView(r.Context(), userIdpId, orgName, viewId)
+	if err != nil {
+		logger.GetLogger(r.Context()).Error("failed to get trace view", "viewId", viewId, "error", err)
+		utils.WriteErrorProblem(w, r, err, "Failed to retrieve traces")
+		return nil, false
+	}
+	merged, err := utils.MergeTraceViewQuery(view, query)
+	if err != nil {
+		utils.WriteErrorProblem(w, r, err, "Failed to retrieve traces")
+		return nil, false
+	}
+	return merged, true
+}
+
+// parseTraceListFilters parses the trace list filters of a query into the request, writing a problem response
+// when one is invalid
+func parseTraceListFilters(w http.ResponseWriter, r *http.Request, query url.Values, req *services.AgentTracesRequest) bool {
+	for _, filter := range utils.TraceFilters {
+		if value := query.Get(filter); value != "" {
+			if err := utils.ValidateTraceFilter(filter, value); err != nil {
+				utils.WriteProblemResponse(w, r, http.StatusBadRequest, fmt.Sprintf("Invalid %s parameter: %v", filter, err))
+				return false
+			}
+		}
+	}
+	req.Status = query.Get(utils.TraceFilterStatus)
+	req.Model = query.Get(utils.TraceFilterModel)
+	req.Framework = query.Get(utils.TraceFilterFramework)
+	req.AnnotationLabel = query.Get(utils.TraceFilterAnnotationLabel)
+	if value := query.Get(utils.TraceFilterMinDuration); value != "" {
+		req.MinDuration, _ = time.ParseDuration(value)
+	}
+	if value := query.Get(utils.TraceFilterHasGuardrailViolation); value != "" {
+		hasViolation, _ := strconv.ParseBool(value)
+		req.HasGuardrailViolation = &hasViolation
+	}
+	return true
+}
+
+// parseTracesPage parses the limit and offset of a trace listing, writing a problem response when they are invalid
+func parseTracesPage(w http.ResponseWriter, r *http.Request) (int, int, bool) {
+	limit, offset := 10, 0
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		parsed, err := strconv.Atoi(limitStr)
+		if err != nil || parsed < 1 || parsed > 100 {
+			utils.WriteProblemResponse(w, r, http.StatusBadRequest, "Invalid limit parameter: must be between 1 and 100")
+			return 0, 0, false
+		}
+		limit = parsed
+	}
+	if offsetStr := r.URL.Query().Get("offset"); offsetStr != "" {
+		parsed, err := strconv.Atoi(offsetStr)
+		if err != nil || parsed < 0 {
+			utils.WriteProblemResponse(w, r, http.StatusBadRequest, "Invalid offset parameter: must be 0 or greater")
+			return 0, 0, false
+		}
+		offset = parsed
+	}
+	return limit, offset, true
+}
+
+// parseTracesTimeRange parses the RFC3339 startTime and endTime parameters, which are given together or not at all
+// unless required. Writes a problem response when they are invalid
+func parseTracesTimeRange(w http.ResponseWriter, r *http.Request, required bool) (string, string, bool) {
+	startTime := r.URL.Query().Get("startTime")
+	endTime := r.URL.Query().Get("endTime")
+	if startTime == "" && endTime == "" && !required {
+		return "", "", true
+	}
+	if startTime == "" {
+		utils.WriteProblemResponse(w, r, http.StatusBadRequest, "Missing parameter: startTime is required")
+		return "", "", false
+	}
+	if endTime == "" {
+		utils.WriteProblemResponse(w, r, http.StatusBadRequest, "Missing parameter: endTime is required")
+		return "", "", false
+	}
+	start, err := time.Parse(time.RFC3339, startTime)
+	if err != nil {
+		utils.WriteProblemResponse(w, r, http.StatusBadRequest, "Invalid startTime format: must be RFC3339 (e.g., 2025-12-20T10:00:00Z)")
+		return "", "", false
+	}
+	end, err := time.Parse(time.RFC3339, endTime)
+	if err != nil {
+		utils.WriteProblemResponse(w, r, http.StatusBadRequest, "Invalid endTime format: must be RFC3339 (e.g., 2025-12-20T10:00:00Z)")
+		return "", "", false
+	}
+	if start.After(end) {
+		utils.WriteProblemResponse(w, r, http.StatusBadRequest, "Invalid time range: startTime must not be after endTime")
+		return "", "", false
+	}
+	return startTime, endTime, true
+}
@@ -0,0 +1,514 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/middleware/jwtassertion"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/middleware/logger"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/models"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/services"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/utils"
+)
+
+type PromptTemplateController interface {
+	ListPromptTemplates(w http.ResponseWriter, r *http.Request)
+	GetPromptTemplate(w http.ResponseWriter, r *http.Request)
+	CreatePromptTemplate(w http.ResponseWriter, r *http.Request)
+	UpdatePromptTemplate(w http.ResponseWriter, r *http.Request)
+	// PatchPromptTemplate applies a JSON merge patch to the prompt, recording a new version
+	PatchPromptTemplate(w http.ResponseWriter, r *http.Request)
+	DeletePromptTemplate(w http.ResponseWriter, r *http.Request)
+	RestorePromptTemplate(w http.ResponseWriter, r *http.Request)
+	ListPromptTemplateVersions(w http.ResponseWriter, r *http.Request)
+	GetPromptTemplateVersion(w http.ResponseWriter, r *http.Request)
+	RenderPromptTemplate(w http.ResponseWriter, r *http.Request)
+}
+
+type promptTemplateController struct {
+	promptTemplateService services.PromptTemplateService
+}
+
+// NewPromptTemplateController returns a new PromptTemplateController instance.
+func NewPromptTemplateController(promptTemplateService services.PromptTemplateService) PromptTemplateController {
+	return &promptTemplateController{
+		promptTemplateService: promptTemplateService,
+	}
+}
+
+func (c *promptTemplateController) ListPromptTemplates(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.GetLogger(ctx)
+	orgName := r.PathValue(utils.PathParamOrgName)
+
+	page, ok := parseListPage(w, r, models.ListSortName, models.ListSortName, models.ListSortCreatedAt, models.ListSortUpdatedAt)
+	if !ok {
+		return
+	}
+	selector, ok := parseLabelSelector(w, r)
+	if !ok {
+		return
+	}
+	includeDeleted, ok := parseIncludeDeleted(w, r)
+	if !ok {
+		return
+	}
+
+	tokenClaims := jwtassertion.GetTokenClaims(ctx)
+	userIdpId := tokenClaims.Sub
+
+	filter := models.PromptTemplateFilter{
+		Search:         r.URL.Query().Get("search"),
+		Selector:       selector,
+		IncludeDeleted: includeDeleted,
+		Page:           page,
+	}
+	prompts, next, total, err := c.promptTemplateService.ListPromptTemplates(ctx, userIdpId, orgName, filter)
+	if err != nil {
+		log.Error("ListPromptTemplates: failed to list prompts", "error", err)
+		writePromptTemplateError(w, r, err, "Failed to list prompts")
+		return
+	}
+	counted, nextCursor, err := listPageFields(page, next, total)
+	if err != nil {
+		log.Error("ListPromptTemplates: failed to encode the next cursor", "error", err)
+		utils.WriteProblemResponse(w, r, http.StatusInternalServerError, "Failed to list prompts")
+		return
+	}
+
+	response := &models.PromptTemplateListResponse{
+		Prompts:    utils.ConvertToPromptTemplateListResponse(prompts),
+		Total:      counted,
+		Limit:      int32(page.Limit),
+		Offset:     int32(page.Offset),
+		NextCursor: nextCursor,
+	}
+	utils.WriteSuccessResponse(w, http.StatusOK, response)
+}
+
+func (c *promptTemplateController) GetPromptTemplate(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.GetLogger(ctx)
+	orgName := r.PathValue(utils.PathParamOrgName)
+	promptId, ok := parsePromptId(w, r)
+	if !ok {
+		return
+	}
+	// Any reader may fetch a deleted prompt by id, so agents referencing it still resolve
+	includeDeleted, ok := parseBoolParam(w, r, "includeDeleted")
+	if !ok {
+		return
+	}
+
+	tokenClaims := jwtassertion.GetTokenClaims(ctx)
+	userIdpId := tokenClaims.Sub
+
+	prompt, err := c.promptTemplateService.GetPromptTemplate(ctx, userIdpId, orgName, promptId, includeDeleted)
+	if err != nil {
+		log.Error("GetPromptTemplate: failed to get prompt", "error", err)
+		writePromptTemplateError(w, r, err, "Failed to get prompt")
+		return
+	}
+	if utils.WriteNotModified(w, r, utils.FormatVersionETag(prompt.Version)) {
+		return
+	}
+	writePromptTemplate(w, http.StatusOK, prompt)
+}
+
+func (c *promptTemplateController) CreatePromptTemplate(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.GetLogger(ctx)
+	orgName := r.PathValue(utils.PathParamOrgName)
+
+	tokenClaims := jwtassertion.GetTokenClaims(ctx)
+	userIdpId := tokenClaims.Sub
+
+	payload, ok := decodePromptTemplateRequest(w, r)
+	if !ok {
+		return
+	}
+
+	prompt, err := c.promptTemplateService.CreatePromptTemplate(ctx, userIdpId, orgName, payload)
+	if err != nil {
+		log.Error("CreatePromptTemplate: failed to create prompt", "error", err)
+		writePromptTemplateError(w, r, err, "Failed to create prompt")
+		return
+	}
+	w.Header().Set("Location", fmt.Sprintf("%s/%s", r.URL.Path, prompt.ID))
+	writePromptTemplate(w, http.StatusCreated, prompt)
+}
+
+func (c *promptTemplateController) UpdatePromptTemplate(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.GetLogger(ctx)
+	orgName := r.PathValue(utils.PathParamOrgName)
+	promptId, ok := parsePromptId(w, r)
+	if !ok {
+		return
+	}
+
+	tokenClaims := jwtassertion.GetTokenClaims(ctx)
+	userIdpId := tokenClaims.Sub
+
+	payload, ok := decodePromptTemplateRequest(w, r)
+	if !ok {
+		return
+	}
+	expectedVersion, ok := requireExpectedVersion(w, r, payload.Version)
+	if !ok {
+		return
+	}
+
+	prompt, err := c.promptTemplateService.UpdatePromptTemplate(ctx, userIdpId, orgName, promptId, payload, expectedVersion)
+	if err != nil {
+		log.Error("UpdatePromptTemplate: failed to update prompt", "error", err)
+		writePromptTemplateError(w, r, err, "Failed to update prompt")
+		return
+	}
+	writePromptTemplate(w, http.StatusOK, prompt)
+}
+
+func (c *promptTemplateController) PatchPromptTemplate(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.GetLogger(ctx)
+	orgName := r.PathValue(utils.PathParamOrgName)
+	promptId, ok := parsePromptId(w, r)
+	if !ok {
+		return
+	}
+
+	tokenClaims := jwtassertion.GetTokenClaims(ctx)
+	userIdpId := tokenClaims.Sub
+
+	patch, ok := decodeMergePatch(w, r)
+	if !ok {
+		return
+	}
+	expectedVersion, ok := mergePatchVersion(w, r, patch)
+	if !ok {
+		return
+	}
+
+	prompt, err := c.promptTemplateService.PatchPromptTemplate(ctx, userIdpId, orgName, promptId, patch, expectedVersion)
+	if err != nil {
+		log.Error("PatchPromptTemplate: failed to patch prompt", "error", err)
+		// The patched prompt is validated as a whole rather than its variables alone
+		var validationErr *utils.ValidationError
+		if errors.As(err, &validationErr) {
+			utils.WriteProblemResponse(w, r, http.StatusBadRequest, "Invalid prompt", validationErr.Errors...)
+			return
+		}
+		writePromptTemplateError(w, r, err, "Failed to update prompt")
+		return
+	}
+	writePromptTemplate(w, http.StatusOK, prompt)
+}
+
+func (c *promptTemplateController) DeletePromptTemplate(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.GetLogger(ctx)
+	orgName := r.PathValue(utils.PathParamOrgName)
+	promptId, ok := parsePromptId(w, r)
+	if !ok {
+		return
+	}
+
+	tokenClaims := jwtassertion.GetTokenClaims(ctx)
+	userIdpId := tokenClaims.Sub
+
+	if err := c.promptTemplateService.DeletePromptTemplate(ctx, userIdpId, orgName, promptId); err != nil {
+		log.Error("DeletePromptTemplate: failed to delete prompt", "error", err)
+		writePromptTemplateError(w, r, err, "Failed to delete prompt")
+		return
+	}
+	utils.WriteSuccessResponse(w, http.StatusNoContent, "")
+}
+
+func (c *promptTemplateController) RestorePromptTemplate(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.GetLogger(ctx)
+	orgName := r.PathValue(utils.PathParamOrgName)
+	promptId, ok := parsePromptId(w, r)
+	if !ok {
+		return
+	}
+
+	tokenClaims := jwtassertion.GetTokenClaims(ctx)
+	userIdpId := tokenClaims.Sub
+
+	prompt, err := c.promptTemplateService.RestorePromptTemplate(ctx, userIdpId, orgName, promptId)
+	if err != nil {
+		log.Error("RestorePromptTemplate: failed to restore prompt", "error", err)
+		writePromptTemplateError(w, r, err, "Failed to restore prompt")
+		return
+	}
+	writePromptTemplate(w, http.StatusOK, prompt)
+}
+
+func (c *promptTemplateController) ListPromptTemplateVersions(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.GetLogger(ctx)
+	orgName := r.PathValue(utils.PathParamOrgName)
+	promptId, ok := parsePromptId(w, r)
+	if !ok {
+		return
+	}
+	limit, offset, ok := parsePromptPagination(w, r)
+	if !ok {
+		return
+	}
+
+	tokenClaims := jwtassertion.GetTokenClaims(ctx)
+	userIdpId := tokenClaims.Sub
+
+	versions, total, err := c.promptTemplateService.ListPromptTemplateVersions(ctx, userIdpId, orgName, promptId, limit, offset)
+	if err != nil {
+		log.Error("ListPromptTemplateVersions: failed to list prompt versions", "error", err)
+		writePromptTemplateError(w, r, err, "Failed to list prompt versions")
+		return
+	}
+
+	responses := make([]models.PromptTemplateVersionResponse, 0, len(versions))
+	for _, version := range versions {
+		responses = append(responses, utils.ConvertToPromptTemplateVersionResponse(version))
+	}
+	response := &models.PromptTemplateVersionListResponse{
+		Versions: responses,
+		Total:    total,
+		Limit:    int32(limit),
+		Offset:   int32(offset),
+	}
+	utils.WriteSuccessResponse(w, http.StatusOK, response)
+}
+
+func (c *promptTemplateController) GetPromptTemplateVersion(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.GetLogger(ctx)
+	orgName := r.PathValue(utils.PathParamOrgName)
+	promptId, ok := parsePromptId(w, r)
+	if !ok {
+		return
+	}
+	version, ok := parseVersion(w, r, r.PathValue(utils.PathParamVersion), "version")
+	if !ok {
+		return
+	}
+
+	tokenClaims := jwtassertion.GetTokenClaims(ctx)
+	userIdpId := tokenClaims.Sub
+
+	promptVersion, err := c.promptTemplateService.GetPromptTemplateVersion(ctx, userIdpId, orgName, promptId, version)
+	if err != nil {
+		log.Error("GetPromptTemplateVersion: failed to get prompt version", "error", err)
+		writePromptTemplateError(w, r, err, "Failed to get prompt version")
+		return
+	}
+	utils.WriteSuccessResponse(w, http.StatusOK, utils.ConvertToPromptTemplateVersionResponse(promptVersion))
+}
+
+func (c *promptTemplateController) RenderPromptTemplate(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.GetLogger(ctx)
+	orgName := r.PathValue(utils.PathParamOrgName)
+	promptId, ok := parsePromptId(w, r)
+	if !ok {
+		return
+	}
+
+	var payload models.PromptRenderRequest
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		log.Error("RenderPromptTemplate: failed to decode request body", "error", err)
+		utils.WriteBodyProblem(w, r, err)
+		return
+	}
+	if payload.Version != nil && *payload.Version < 1 {
+		utils.WriteProblemResponse(w, r, http.StatusBadRequest, "Invalid request body", utils.FieldError{
+			Field:      "version",
+			Constraint: utils.ConstraintMinimum,
+			Message:    "must be a positive version number",
+		})
+		return
+	}
+
+	tokenClaims := jwtassertion.GetTokenClaims(ctx)
+	userIdpId := tokenClaims.Sub
+
+	promptVersion, text, err := c.promptTemplateService.RenderPromptTemplate(ctx, userIdpId, orgName, promptId, &payload)
+	if err != nil {
+		log.Error("RenderPromptTemplate: failed to render prompt", "error", err)
+		writePromptTemplateError(w, r, err, "Failed to render prompt")
+		return
+	}
+	response := &models.PromptRenderResponse{
+		PromptID: promptVersion.PromptID.String(),
+		Version:  promptVersion.Version,
+		Text:     text,
+	}
+	utils.WriteSuccessResponse(w, http.StatusOK, response)
+}
+
+// parsePromptId reads the prompt ID path parameter, an ID that is not a UUID cannot match any prompt
+func parsePromptId(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	promptId, err := uuid.Parse(r.PathValue(utils.PathParamPromptId))
+	if err != nil {
+		utils.WriteProblemResponse(w, r, http.StatusNotFound, "Prompt not found")
+		return uuid.Nil, false
+	}
+	return promptId, true
+}
+
+func parsePromptPagination(w http.ResponseWriter, r *http.Request) (int, int, bool) {
+	log := logger.GetLogger(r.Context())
+
+	limitStr := r.URL.Query().Get("limit")
+	if limitStr == "" {
+		limitStr = strconv.Itoa(utils.DefaultLimit)
+	}
+	offsetStr := r.URL.Query().Get("offset")
+	if offsetStr == "" {
+		offsetStr = strconv.Itoa(utils.DefaultOffset)
+	}
+	limit, err := strconv.Atoi(limitStr)
+	if err != nil || limit < utils.MinLimit || limit > utils.MaxLimit {
+		log.Error("invalid limit parameter", "limit", limitStr)
+		utils.WriteProblemResponse(w, r, http.StatusBadRequest, fmt.Sprintf("Invalid limit parameter: must be between %d and %d", utils.MinLimit, utils.MaxLimit))
+		return 0, 0, false
+	}
+	offset, err := strconv.Atoi(offsetStr)
+	if err != nil || offset < utils.MinOffset {
+		log.Error("invalid offset parameter", "offset", offsetStr)
+		utils.WriteProblemResponse(w, r, http.StatusBadRequest, fmt.Sprintf("Invalid offset parameter: must be %d or greater", utils.MinOffset))
+		return 0, 0, false
+	}
+	return limit, offset, true
+}
+
+// parseListPage reads the paging parameters of agent, prompt, tool and webhook listings. A cursor continues the
+// listing after the last result of the previous page and takes precedence over offset
+func parseListPage(w http.ResponseWriter, r *http.Request, defaultSort string, sorts ...string) (models.ListPage, bool) {
+	log := logger.GetLogger(r.Context())
+	query := r.URL.Query()
+
+	limit, offset, ok := parsePromptPagination(w, r)
+	if !ok {
+		return models.ListPage{}, false
+	}
+	sort := query.Get("sort")
+	if sort == "" {
+		sort = defaultSort
+	}
+	if !slices.Contains(sorts, sort) {
+		log.Error("invalid sort parameter", "sort", sort)
+		utils.WriteProblemResponse(w, r, http.StatusBadRequest, fmt.Sprintf("Invalid sort parameter: must be one of %s", strings.Join(sorts, ", ")))
+		return models.ListPage{}, false
+	}
+	order := query.Get("sortOrder")
+	if order == "" {
+		order = models.ListOrderAsc
+	}
+	if order != models.ListOrderAsc && order != models.ListOrderDesc {
+		log.Error("invalid sortOrder parameter", "sortOrder", order)
+		utils.WriteProblemResponse(w, r, http.StatusBadRequest, "Invalid sortOrder parameter: must be 'asc' or 'desc'")
+		return models.ListPage{}, false
+	}
+	count, ok := parseBoolParam(w, r, "count")
+	if !ok {
+		return models.ListPage{}, false
+	}
+
+	page := models.ListPage{Sort: sort, Order: order, Limit: limit, Offset: offset, Count: count}
+	if value := query.Get("cursor"); value != "" {
+		cursor, err := utils.DecodeListCursor(value, sort, order)
+		if err != nil {
+			log.Error("invalid cursor parameter", "error", err)
+			utils.WriteProblemResponse(w, r, http.StatusBadRequest, "Invalid cursor parameter: "+err.Error())
+			return models.ListPage{}, false
+		}
+		page.Cursor = cursor
+		page.Offset = 0
+	}
+	return page, true
+}
+
+// listPageFields returns the total of a listing when the request counted it and the encoded cursor of its next page
+func listPageFields(page models.ListPage, next *models.ListCursor, total int32) (*int32, string, error) {
+	var counted *int32
+	if page.Count {
+		counted = &total
+	}
+	if next == nil {
+		return counted, "", nil
+	}
+	cursor, err := utils.EncodeListCursor(*next)
+	if err != nil {
+		return nil, "", err
+	}
+	return counted, cursor, nil
+}
+
+// decodePromptTemplateRequest decodes and validates the request body, writing a problem response when it is rejected
+func decodePromptTemplateRequest(w http.ResponseWriter, r *http.Request) (*models.PromptTemplateRequest, bool) {
+	log := logger.GetLogger(r.Context())
+
+	var payload models.PromptTemplateRequest
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		log.Error("failed to decode prompt request body", "error", err)
+		utils.WriteBodyProblem(w, r, err)
+		return nil, false
+	}
+
+	if err := utils.ValidatePromptTemplateRequest(payload); err != nil {
+		log.Error("invalid prompt payload", "error", err)
+		var validationErr *utils.ValidationError
+		if errors.As(err, &validationErr) {
+			utils.WriteProblemResponse(w, r, http.StatusBadRequest, "Invalid prompt", validationErr.Errors...)
+			return nil, false
+		}
+		utils.WriteProblemResponse(w, r, http.StatusBadRequest, err.Error())
+		return nil, false
+	}
+	return &payload, true
+}
+
+func writePromptTemplate(w http.ResponseWriter, statusCode int, prompt *models.PromptTemplate) {
+	w.Header().Set("ETag", utils.FormatVersionETag(prompt.Version))
+	utils.WriteSuccessResponse(w, statusCode, utils.ConvertToPromptTemplateResponse(prompt))
+}
+
+func writePromptTemplateError(w http.ResponseWriter, r *http.Request, err error, fallback string) {
+	var validationErr *utils.ValidationError
+	var dependentsErr *utils.DependentsError
+	switch {
+	case errors.As(err, &validationErr):
+		utils.WriteProblemResponse(w, r, http.StatusBadRequest, "Invalid variables", validationErr.Errors...)
+	case errors.As(err, &dependentsErr):
+		utils.WriteDependentsProblemResponse(w, r, http.StatusConflict,
+			fmt.Sprintf("The prompt is referenced by %d agent(s), update or delete them first", len(dependentsErr.Dependents)),
+			dependentsErr.Dependents)
+	default:
+		utils.WriteErrorProblem(w, r, err, fallback)
+	}
+}
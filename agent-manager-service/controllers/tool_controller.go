@@ -0,0 +1,288 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/middleware/jwtassertion"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/middleware/logger"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/models"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/services"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/utils"
+)
+
+type ToolController interface {
+	ListTools(w http.ResponseWriter, r *http.Request)
+	GetTool(w http.ResponseWriter, r *http.Request)
+	CreateTool(w http.ResponseWriter, r *http.Request)
+	UpdateTool(w http.ResponseWriter, r *http.Request)
+	// PatchTool applies a JSON merge patch to the tool
+	PatchTool(w http.ResponseWriter, r *http.Request)
+	DeleteTool(w http.ResponseWriter, r *http.Request)
+}
+
+type toolController struct {
+	toolService services.ToolService
+}
+
+// NewToolController returns a new ToolController instance.
+func NewToolController(toolService services.ToolService) ToolController {
+	return &toolController{
+		toolService: toolService,
+	}
+}
+
+func (c *toolController) ListTools(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.GetLogger(ctx)
+	orgName := r.PathValue(utils.PathParamOrgName)
+
+	page, ok := parseListPage(w, r, models.ListSortName, models.ListSortName, models.ListSortCreatedAt, models.ListSortUpdatedAt)
+	if !ok {
+		return
+	}
+	query := r.URL.Query()
+	tags, err := utils.ParseTagSelector(query.Get("tags"))
+	if err != nil {
+		log.Error("ListTools: invalid tags parameter", "error", err)
+		utils.WriteProblemResponse(w, r, http.StatusBadRequest, "Invalid tags parameter: "+err.Error())
+		return
+	}
+	selector, ok := parseLabelSelector(w, r)
+	if !ok {
+		return
+	}
+
+	tokenClaims := jwtassertion.GetTokenClaims(ctx)
+	userIdpId := tokenClaims.Sub
+
+	filter := models.ToolFilter{
+		Search:   query.Get("search"),
+		Tags:     tags,
+		Selector: selector,
+		Page:     page,
+	}
+	tools, next, total, err := c.toolService.ListTools(ctx, userIdpId, orgName, filter)
+	if err != nil {
+		log.Error("ListTools: failed to list tools", "error", err)
+		writeToolError(w, r, err, "Failed to list tools")
+		return
+	}
+	counted, nextCursor, err := listPageFields(page, next, total)
+	if err != nil {
+		log.Error("ListTools: failed to encode the next cursor", "error", err)
+		utils.WriteProblemResponse(w, r, http.StatusInternalServerError, "Failed to list tools")
+		return
+	}
+
+	response := &models.ToolListResponse{
+		Tools:      utils.ConvertToToolListResponse(tools),
+		Total:      counted,
+		Limit:      int32(page.Limit),
+		Offset:     int32(page.Offset),
+		NextCursor: nextCursor,
+	}
+	utils.WriteSuccessResponse(w, http.StatusOK, response)
+}
+
+func (c *toolController) GetTool(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.GetLogger(ctx)
+	orgName := r.PathValue(utils.PathParamOrgName)
+	toolId, ok := parseToolId(w, r)
+	if !ok {
+		return
+	}
+
+	tokenClaims := jwtassertion.GetTokenClaims(ctx)
+	userIdpId := tokenClaims.Sub
+
+	tool, err := c.toolService.GetTool(ctx, userIdpId, orgName, toolId)
+	if err != nil {
+		log.Error("GetTool: failed to get tool", "error", err)
+		writeToolError(w, r, err, "Failed to get tool")
+		return
+	}
+	if utils.WriteNotModified(w, r, utils.FormatVersionETag(tool.Version)) {
+		return
+	}
+	writeTool(w, http.StatusOK, tool)
+}
+
+func (c *toolController) CreateTool(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.GetLogger(ctx)
+	orgName := r.PathValue(utils.PathParamOrgName)
+
+	tokenClaims := jwtassertion.GetTokenClaims(ctx)
+	userIdpId := tokenClaims.Sub
+
+	payload, ok := decodeToolRequest(w, r)
+	if !ok {
+		return
+	}
+
+	tool, err := c.toolService.CreateTool(ctx, userIdpId, orgName, payload)
+	if err != nil {
+		log.Error("CreateTool: failed to create tool", "error", err)
+		writeToolError(w, r, err, "Failed to create tool")
+		return
+	}
+	w.Header().Set("Location", fmt.Sprintf("%s/%s", r.URL.Path, tool.ID))
+	writeTool(w, http.StatusCreated, tool)
+}
+
+func (c *toolController) UpdateTool(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.GetLogger(ctx)
+	orgName := r.PathValue(utils.PathParamOrgName)
+	toolId, ok := parseToolId(w, r)
+	if !ok {
+		return
+	}
+
+	tokenClaims := jwtassertion.GetTokenClaims(ctx)
+	userIdpId := tokenClaims.Sub
+
+	payload, ok := decodeToolRequest(w, r)
+	if !ok {
+		return
+	}
+	expectedVersion, ok := requireExpectedVersion(w, r, payload.Version)
+	if !ok {
+		return
+	}
+
+	tool, err := c.toolService.UpdateTool(ctx, userIdpId, orgName, toolId, payload, expectedVersion)
+	if err != nil {
+		log.Error("UpdateTool: failed to update tool", "error", err)
+		writeToolError(w, r, err, "Failed to update tool")
+		return
+	}
+	writeTool(w, http.StatusOK, tool)
+}
+
+func (c *toolController) PatchTool(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.GetLogger(ctx)
+	orgName := r.PathValue(utils.PathParamOrgName)
+	toolId, ok := parseToolId(w, r)
+	if !ok {
+		return
+	}
+
+	tokenClaims := jwtassertion.GetTokenClaims(ctx)
+	userIdpId := tokenClaims.Sub
+
+	patch, ok := decodeMergePatch(w, r)
+	if !ok {
+		return
+	}
+	expectedVersion, ok := mergePatchVersion(w, r, patch)
+	if !ok {
+		return
+	}
+
+	tool, err := c.toolService.PatchTool(ctx, userIdpId, orgName, toolId, patch, expectedVersion)
+	if err != nil {
+		log.Error("PatchTool: failed to patch tool", "error", err)
+		var validationErr *utils.ValidationError
+		if errors.As(err, &validationErr) {
+			utils.WriteProblemResponse(w, r, http.StatusBadRequest, "Invalid tool", validationErr.Errors...)
+			return
+		}
+		writeToolError(w, r, err, "Failed to update tool")
+		return
+	}
+	writeTool(w, http.StatusOK, tool)
+}
+
+func (c *toolController) DeleteTool(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.GetLogger(ctx)
+	orgName := r.PathValue(utils.PathParamOrgName)
+	toolId, ok := parseToolId(w, r)
+	if !ok {
+		return
+	}
+
+	tokenClaims := jwtassertion.GetTokenClaims(ctx)
+	userIdpId := tokenClaims.Sub
+
+	if err := c.toolService.DeleteTool(ctx, userIdpId, orgName, toolId); err != nil {
+		log.Error("DeleteTool: failed to delete tool", "error", err)
+		writeToolError(w, r, err, "Failed to delete tool")
+		return
+	}
+	utils.WriteSuccessResponse(w, http.StatusNoContent, "")
+}
+
+// parseToolId reads the tool ID path parameter, an ID that is not a UUID cannot match any tool
+func parseToolId(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	toolId, err := uuid.Parse(r.PathValue(utils.PathParamToolId))
+	if err != nil {
+		utils.WriteProblemResponse(w, r, http.StatusNotFound, "Tool not found")
+		return uuid.Nil, false
+	}
+	return toolId, true
+}
+
+// decodeToolRequest decodes and validates the request body, writing a problem response when it is rejected
+func decodeToolRequest(w http.ResponseWriter, r *http.Request) (*models.ToolRequest, bool) {
+	log := logger.GetLogger(r.Context())
+
+	var payload models.ToolRequest
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		log.Error("failed to decode tool request body", "error", err)
+		utils.WriteBodyProblem(w, r, err)
+		return nil, false
+	}
+
+	if err := utils.ValidateToolRequest(payload); err != nil {
+		log.Error("invalid tool payload", "error", err)
+		var validationErr *utils.ValidationError
+		if errors.As(err, &validationErr) {
+			utils.WriteProblemResponse(w, r, http.StatusBadRequest, "Invalid tool", validationErr.Errors...)
+			return nil, false
+		}
+		utils.WriteProblemResponse(w, r, http.StatusBadRequest, err.Error())
+		return nil, false
+	}
+	return &payload, true
+}
+
+func writeTool(w http.ResponseWriter, statusCode int, tool *models.Tool) {
+	w.Header().Set("ETag", utils.FormatVersionETag(tool.Version))
+	utils.WriteSuccessResponse(w, statusCode, utils.ConvertToToolResponse(tool))
+}
+
+func writeToolError(w http.ResponseWriter, r *http.Request, err error, fallback string) {
+	var dependentsErr *utils.DependentsError
+	if errors.As(err, &dependentsErr) {
+		utils.WriteDependentsProblemResponse(w, r, http.StatusConflict,
+			fmt.Sprintf("The tool is referenced by %d agent(s), update or delete them first", len(dependentsErr.Dependents)),
+			dependentsErr.Dependents)
+		return
+	}
+	utils.WriteErrorProblem(w, r, err, fallback)
+}
@@ -0,0 +1,155 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/middleware/jwtassertion"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/middleware/logger"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/models"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/services"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/utils"
+)
+
+type TraceViewController interface {
+	ListTraceViews(w http.ResponseWriter, r *http.Request)
+	GetTraceView(w http.ResponseWriter, r *http.Request)
+	CreateTraceView(w http.ResponseWriter, r *http.Request)
+	DeleteTraceView(w http.ResponseWriter, r *http.Request)
+}
+
+type traceViewController struct {
+	traceViewService services.TraceViewService
+}
+
+// NewTraceViewController returns a new TraceViewController instance.
+func NewTraceViewController(traceViewService services.TraceViewService) TraceViewController {
+	return &traceViewController{
+		traceViewService: traceViewService,
+	}
+}
+
+func (c *traceViewController) ListTraceViews(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.GetLogger(ctx)
+	limit, offset, ok := parsePromptPagination(w, r)
+	if !ok {
+		return
+	}
+
+	orgName := r.PathValue(utils.PathParamOrgName)
+	tokenClaims := jwtassertion.GetTokenClaims(ctx)
+	userIdpId := tokenClaims.Sub
+
+	views, total, err := c.traceViewService.ListTraceViews(ctx, userIdpId, orgName, limit, offset)
+	if err != nil {
+		log.Error("ListTraceViews: failed to list trace views", "error", err)
+		utils.WriteErrorProblem(w, r, err, "Failed to list trace views")
+		return
+	}
+	response := &models.TraceViewListResponse{
+		Views:  utils.ConvertToTraceViewListResponse(views),
+		Total:  total,
+		Limit:  int32(limit),
+		Offset: int32(offset),
+	}
+	utils.WriteSuccessResponse(w, http.StatusOK, response)
+}
+
+func (c *traceViewController) GetTraceView(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.GetLogger(ctx)
+	viewId, ok := parseTraceViewId(w, r)
+	if !ok {
+		return
+	}
+
+	orgName := r.PathValue(utils.PathParamOrgName)
+	tokenClaims := jwtassertion.GetTokenClaims(ctx)
+	userIdpId := tokenClaims.Sub
+
+	view, err := c.traceViewService.GetTraceView(ctx, userIdpId, orgName, viewId)
+	if err != nil {
+		log.Error("GetTraceView: failed to get trace view", "error", err)
+		utils.WriteErrorProblem(w, r, err, "Failed to get trace view")
+		return
+	}
+	utils.WriteSuccessResponse(w, http.StatusOK, utils.ConvertToTraceViewResponse(view))
+}
+
+func (c *traceViewController) CreateTraceView(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.GetLogger(ctx)
+	var payload models.TraceViewRequest
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		log.Error("CreateTraceView: failed to decode request body", "error", err)
+		utils.WriteBodyProblem(w, r, err)
+		return
+	}
+	if err := utils.ValidateTraceViewRequest(payload); err != nil {
+		log.Error("CreateTraceView: invalid trace view payload", "error", err)
+		utils.WriteErrorProblem(w, r, err, "Invalid trace view")
+		return
+	}
+
+	orgName := r.PathValue(utils.PathParamOrgName)
+	tokenClaims := jwtassertion.GetTokenClaims(ctx)
+	userIdpId := tokenClaims.Sub
+
+	view, err := c.traceViewService.CreateTraceView(ctx, userIdpId, orgName, &payload)
+	if err != nil {
+		log.Error("CreateTraceView: failed to create trace view", "error", err)
+		utils.WriteErrorProblem(w, r, err, "Failed to create trace view")
+		return
+	}
+	w.Header().Set("Location", fmt.Sprintf("%s/%s", r.URL.Path, view.ID))
+	utils.WriteSuccessResponse(w, http.StatusCreated, utils.ConvertToTraceViewResponse(view))
+}
+
+func (c *traceViewController) DeleteTraceView(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.GetLogger(ctx)
+	viewId, ok := parseTraceViewId(w, r)
+	if !ok {
+		return
+	}
+
+	orgName := r.PathValue(utils.PathParamOrgName)
+	tokenClaims := jwtassertion.GetTokenClaims(ctx)
+	userIdpId := tokenClaims.Sub
+
+	if err := c.traceViewService.DeleteTraceView(ctx, userIdpId, orgName, viewId); err != nil {
+		log.Error("DeleteTraceView: failed to delete trace view", "error", err)
+		utils.WriteErrorProblem(w, r, err, "Failed to delete trace view")
+		return
+	}
+	utils.WriteSuccessResponse(w, http.StatusNoContent, "")
+}
+
+func parseTraceViewId(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	viewId, err := uuid.Parse(r.PathValue(utils.PathParamTraceViewId))
+	if err != nil {
+		utils.WriteProblemResponse(w, r, http.StatusNotFound, "Trace view not found")
+		return uuid.Nil, false
+	}
+	return viewId, true
+}
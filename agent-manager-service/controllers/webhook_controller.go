@@ -0,0 +1,308 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/middleware/jwtassertion"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/middleware/logger"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/models"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/services"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/utils"
+)
+
+type WebhookController interface {
+	ListWebhooks(w http.ResponseWriter, r *http.Request)
+	GetWebhook(w http.ResponseWriter, r *http.Request)
+	CreateWebhook(w http.ResponseWriter, r *http.Request)
+	UpdateWebhook(w http.ResponseWriter, r *http.Request)
+	// PatchWebhook applies a JSON merge patch to the webhook
+	PatchWebhook(w http.ResponseWriter, r *http.Request)
+	DeleteWebhook(w http.ResponseWriter, r *http.Request)
+	ListWebhookDeliveries(w http.ResponseWriter, r *http.Request)
+	RedeliverWebhookDelivery(w http.ResponseWriter, r *http.Request)
+	TestWebhook(w http.ResponseWriter, r *http.Request)
+}
+
+type webhookController struct {
+	webhookService services.WebhookService
+}
+
+// NewWebhookController returns a new WebhookController instance.
+func NewWebhookController(webhookService services.WebhookService) WebhookController {
+	return &webhookController{
+		webhookService: webhookService,
+	}
+}
+
+func (c *webhookController) ListWebhooks(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.GetLogger(ctx)
+	// Webhooks have no name, they are listed in the order they were created unless asked otherwise
+	page, ok := parseListPage(w, r, models.ListSortCreatedAt, models.ListSortCreatedAt, models.ListSortUpdatedAt)
+	if !ok {
+		return
+	}
+
+	orgName := r.PathValue(utils.PathParamOrgName)
+	tokenClaims := jwtassertion.GetTokenClaims(ctx)
+	userIdpId := tokenClaims.Sub
+
+	webhooks, next, total, err := c.webhookService.ListWebhooks(ctx, userIdpId, orgName, page)
+	if err != nil {
+		log.Error("ListWebhooks: failed to list webhooks", "error", err)
+		utils.WriteErrorProblem(w, r, err, "Failed to list webhooks")
+		return
+	}
+	counted, nextCursor, err := listPageFields(page, next, total)
+	if err != nil {
+		log.Error("ListWebhooks: failed to encode the next cursor", "error", err)
+		utils.WriteErrorProblem(w, r, err, "Failed to list webhooks")
+		return
+	}
+	response := &models.WebhookListResponse{
+		Webhooks:   utils.ConvertToWebhookListResponse(webhooks),
+		Total:      counted,
+		Limit:      int32(page.Limit),
+		Offset:     int32(page.Offset),
+		NextCursor: nextCursor,
+	}
+	utils.WriteSuccessResponse(w, http.StatusOK, response)
+}
+
+func (c *webhookController) GetWebhook(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.GetLogger(ctx)
+	webhookId, ok := parseWebhookId(w, r)
+	if !ok {
+		return
+	}
+
+	orgName := r.PathValue(utils.PathParamOrgName)
+	tokenClaims := jwtassertion.GetTokenClaims(ctx)
+	userIdpId := tokenClaims.Sub
+
+	webhook, err := c.webhookService.GetWebhook(ctx, userIdpId, orgName, webhookId)
+	if err != nil {
+		log.Error("GetWebhook: failed to get webhook", "error", err)
+		utils.WriteErrorProblem(w, r, err, "Failed to get webhook")
+		return
+	}
+	utils.WriteSuccessResponse(w, http.StatusOK, utils.ConvertToWebhookResponse(webhook))
+}
+
+func (c *webhookController) CreateWebhook(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.GetLogger(ctx)
+	payload, ok := decodeWebhookRequest(w, r, true)
+	if !ok {
+		return
+	}
+
+	orgName := r.PathValue(utils.PathParamOrgName)
+	tokenClaims := jwtassertion.GetTokenClaims(ctx)
+	userIdpId := tokenClaims.Sub
+
+	webhook, err := c.webhookService.CreateWebhook(ctx, userIdpId, orgName, payload)
+	if err != nil {
+		log.Error("CreateWebhook: failed to create webhook", "error", err)
+		utils.WriteErrorProblem(w, r, err, "Failed to create webhook")
+		return
+	}
+	w.Header().Set("Location", fmt.Sprintf("%s/%s", r.URL.Path, webhook.ID))
+	utils.WriteSuccessResponse(w, http.StatusCreated, utils.ConvertToWebhookResponse(webhook))
+}
+
+func (c *webhookController) UpdateWebhook(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.GetLogger(ctx)
+	webhookId, ok := parseWebhookId(w, r)
+	if !ok {
+		return
+	}
+	payload, ok := decodeWebhookRequest(w, r, false)
+	if !ok {
+		return
+	}
+
+	orgName := r.PathValue(utils.PathParamOrgName)
+	tokenClaims := jwtassertion.GetTokenClaims(ctx)
+	userIdpId := tokenClaims.Sub
+
+	webhook, err := c.webhookService.UpdateWebhook(ctx, userIdpId, orgName, webhookId, payload)
+	if err != nil {
+		log.Error("UpdateWebhook: failed to update webhook", "error", err)
+		utils.WriteErrorProblem(w, r, err, "Failed to update webhook")
+		return
+	}
+	utils.WriteSuccessResponse(w, http.StatusOK, utils.ConvertToWebhookResponse(webhook))
+}
+
+func (c *webhookController) PatchWebhook(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.GetLogger(ctx)
+	webhookId, ok := parseWebhookId(w, r)
+	if !ok {
+		return
+	}
+	patch, ok := decodeMergePatch(w, r)
+	if !ok {
+		return
+	}
+
+	orgName := r.PathValue(utils.PathParamOrgName)
+	tokenClaims := jwtassertion.GetTokenClaims(ctx)
+	userIdpId := tokenClaims.Sub
+
+	webhook, err := c.webhookService.PatchWebhook(ctx, userIdpId, orgName, webhookId, patch)
+	if err != nil {
+		log.Error("PatchWebhook: failed to patch webhook", "error", err)
+		utils.WriteErrorProblem(w, r, err, "Failed to update webhook")
+		return
+	}
+	utils.WriteSuccessResponse(w, http.StatusOK, utils.ConvertToWebhookResponse(webhook))
+}
+
+func (c *webhookController) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.GetLogger(ctx)
+	webhookId, ok := parseWebhookId(w, r)
+	if !ok {
+		return
+	}
+
+	orgName := r.PathValue(utils.PathParamOrgName)
+	tokenClaims := jwtassertion.GetTokenClaims(ctx)
+	userIdpId := tokenClaims.Sub
+
+	if err := c.webhookService.DeleteWebhook(ctx, userIdpId, orgName, webhookId); err != nil {
+		log.Error("DeleteWebhook: failed to delete webhook", "error", err)
+		utils.WriteErrorProblem(w, r, err, "Failed to delete webhook")
+		return
+	}
+	utils.WriteSuccessResponse(w, http.StatusNoContent, "")
+}
+
+func (c *webhookController) ListWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.GetLogger(ctx)
+	webhookId, ok := parseWebhookId(w, r)
+	if !ok {
+		return
+	}
+	limit, offset, ok := parsePromptPagination(w, r)
+	if !ok {
+		return
+	}
+
+	orgName := r.PathValue(utils.PathParamOrgName)
+	tokenClaims := jwtassertion.GetTokenClaims(ctx)
+	userIdpId := tokenClaims.Sub
+
+	deliveries, total, err := c.webhookService.ListWebhookDeliveries(ctx, userIdpId, orgName, webhookId, limit, offset)
+	if err != nil {
+		log.Error("ListWebhookDeliveries: failed to list webhook deliveries", "error", err)
+		utils.WriteErrorProblem(w, r, err, "Failed to list webhook deliveries")
+		return
+	}
+	response := &models.WebhookDeliveryListResponse{
+		Deliveries: utils.ConvertToWebhookDeliveryListResponse(deliveries),
+		Total:      total,
+		Limit:      int32(limit),
+		Offset:     int32(offset),
+	}
+	utils.WriteSuccessResponse(w, http.StatusOK, response)
+}
+
+func (c *webhookController) RedeliverWebhookDelivery(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.GetLogger(ctx)
+	webhookId, ok := parseWebhookId(w, r)
+	if !ok {
+		return
+	}
+	deliveryId, err := uuid.Parse(r.PathValue(utils.PathParamDeliveryId))
+	if err != nil {
+		utils.WriteProblemResponse(w, r, http.StatusNotFound, "Webhook delivery not found")
+		return
+	}
+
+	orgName := r.PathValue(utils.PathParamOrgName)
+	tokenClaims := jwtassertion.GetTokenClaims(ctx)
+	userIdpId := tokenClaims.Sub
+
+	delivery, err := c.webhookService.RedeliverWebhookDelivery(ctx, userIdpId, orgName, webhookId, deliveryId)
+	if err != nil {
+		log.Error("RedeliverWebhookDelivery: failed to redeliver webhook delivery", "error", err)
+		utils.WriteErrorProblem(w, r, err, "Failed to redeliver webhook delivery")
+		return
+	}
+	utils.WriteSuccessResponse(w, http.StatusAccepted, utils.ConvertToWebhookDeliveryResponse(delivery))
+}
+
+func (c *webhookController) TestWebhook(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := logger.GetLogger(ctx)
+	webhookId, ok := parseWebhookId(w, r)
+	if !ok {
+		return
+	}
+
+	orgName := r.PathValue(utils.PathParamOrgName)
+	tokenClaims := jwtassertion.GetTokenClaims(ctx)
+	userIdpId := tokenClaims.Sub
+
+	// A delivery the webhook rejected is still answered with 200, its attempt tells how the webhook responded
+	delivery, err := c.webhookService.TestWebhook(ctx, userIdpId, orgName, webhookId)
+	if err != nil {
+		log.Error("TestWebhook: failed to test webhook", "error", err)
+		utils.WriteErrorProblem(w, r, err, "Failed to test webhook")
+		return
+	}
+	utils.WriteSuccessResponse(w, http.StatusOK, utils.ConvertToWebhookDeliveryResponse(delivery))
+}
+
+func parseWebhookId(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	webhookId, err := uuid.Parse(r.PathValue(utils.PathParamWebhookId))
+	if err != nil {
+		utils.WriteProblemResponse(w, r, http.StatusNotFound, "Webhook not found")
+		return uuid.Nil, false
+	}
+	return webhookId, true
+}
+
+// decodeWebhookRequest decodes and validates the request body, writing a problem response when it is rejected
+func decodeWebhookRequest(w http.ResponseWriter, r *http.Request, requireSecret bool) (*models.WebhookRequest, bool) {
+	log := logger.GetLogger(r.Context())
+	var payload models.WebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		log.Error("failed to decode webhook request body", "error", err)
+		utils.WriteBodyProblem(w, r, err)
+		return nil, false
+	}
+	if err := utils.ValidateWebhookRequest(payload, requireSecret); err != nil {
+		log.Error("invalid webhook payload", "error", err)
+		utils.WriteErrorProblem(w, r, err, "Invalid webhook")
+		return nil, false
+	}
+	return &payload, true
+}
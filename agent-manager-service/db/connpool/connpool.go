@@ -118,18 +118,8 @@ func (c *connPool) retry(ctx context.Context, fn string, op func() error) {
 	var err error
 	maxAttempts := c.retryParams.MaxRetries + 1
 	for attempts := 1; attempts <= maxAttempts; attempts++ {
-		// Check context before attempting operation
-		select {
-		// Context was cancelled (timeout/user cancellation)
-		case <-ctx.Done():
-			slog.Warn("connPool operation canceled",
-				"log_type", "connPool",
-				"fn", fn,
-				"context_error", ctx.Err())
-			return
-		default:
-		}
-
+		// The operation runs even with a cancelled context, so it fails with the context error rather than
+		// leaving its results unset
 		err = op()
 		if err == nil || !isRetryableError(err) {
 			return
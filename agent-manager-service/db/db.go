@@ -87,6 +87,14 @@ func initDbConn(cfg config.POSTGRESQL) *gorm.DB {
 		slog.Error("initDbConn: gorm.Open failed", "error", err)
 		os.Exit(1)
 	}
+	if err := registerOrgScope(gormDB); err != nil {
+		slog.Error("initDbConn: failed to enforce organization scoping", "error", err)
+		os.Exit(1)
+	}
+	if err := registerTracing(gormDB); err != nil {
+		slog.Error("initDbConn: failed to register tracing", "error", err)
+		os.Exit(1)
+	}
 	slog.Info("database connected")
 	return gormDB
 }
@@ -0,0 +1,123 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/db/orgscope"
+)
+
+// Column that ties a row to its organization
+const orgIdColumn = "org_id"
+
+// ErrCrossOrgWrite is returned when a statement bound to an organization writes a row of another one
+var ErrCrossOrgWrite = errors.New("row belongs to another organization")
+
+// WithOrgScope binds ctx to an organization. Statements run with it only read, update and delete rows of
+// tables with an org_id column that belong to the organization, and cannot write rows of another one.
+// Raw SQL is not rewritten
+func WithOrgScope(ctx context.Context, orgId uuid.UUID) context.Context {
+	return orgscope.With(ctx, orgId)
+}
+
+// OrgScope returns the organization ctx is bound to
+func OrgScope(ctx context.Context) (uuid.UUID, bool) {
+	return orgscope.FromContext(ctx)
+}
+
+// registerOrgScope installs the callbacks that enforce WithOrgScope
+func registerOrgScope(gormDB *gorm.DB) error {
+	callbacks := gormDB.Callback()
+	if err := callbacks.Query().Before("gorm:query").Register("org_scope:query", scopeToOrg); err != nil {
+		return fmt.Errorf("failed to register org scope query callback: %w", err)
+	}
+	if err := callbacks.Row().Before("gorm:row").Register("org_scope:row", scopeToOrg); err != nil {
+		return fmt.Errorf("failed to register org scope row callback: %w", err)
+	}
+	if err := callbacks.Delete().Before("gorm:delete").Register("org_scope:delete", scopeToOrg); err != nil {
+		return fmt.Errorf("failed to register org scope delete callback: %w", err)
+	}
+	if err := callbacks.Update().Before("gorm:update").Register("org_scope:update", func(tx *gorm.DB) {
+		checkRowOrg(tx, true)
+		scopeToOrg(tx)
+	}); err != nil {
+		return fmt.Errorf("failed to register org scope update callback: %w", err)
+	}
+	if err := callbacks.Create().Before("gorm:create").Register("org_scope:create", func(tx *gorm.DB) {
+		checkRowOrg(tx, false)
+	}); err != nil {
+		return fmt.Errorf("failed to register org scope create callback: %w", err)
+	}
+	return nil
+}
+
+func boundOrgColumn(tx *gorm.DB) (uuid.UUID, bool) {
+	orgId, ok := OrgScope(tx.Statement.Context)
+	if !ok || tx.Statement.Schema == nil || tx.Statement.Schema.LookUpField(orgIdColumn) == nil {
+		return uuid.Nil, false
+	}
+	return orgId, true
+}
+
+// scopeToOrg restricts the statement to the rows of the organization it is bound to
+func scopeToOrg(tx *gorm.DB) {
+	orgId, ok := boundOrgColumn(tx)
+	if !ok || tx.Error != nil {
+		return
+	}
+	tx.Statement.AddClause(clause.Where{Exprs: []clause.Expression{
+		clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: orgIdColumn}, Value: orgId},
+	}})
+}
+
+// checkRowOrg rejects rows written with the org_id of another organization, updates leave a zero org_id untouched
+func checkRowOrg(tx *gorm.DB, allowZero bool) {
+	orgId, ok := boundOrgColumn(tx)
+	if !ok || tx.Error != nil {
+		return
+	}
+	field := tx.Statement.Schema.LookUpField(orgIdColumn)
+	check := func(row reflect.Value) {
+		row = reflect.Indirect(row)
+		if row.Kind() != reflect.Struct {
+			return
+		}
+		value, isZero := field.ValueOf(tx.Statement.Context, row)
+		if isZero && allowZero {
+			return
+		}
+		if rowOrgId, ok := value.(uuid.UUID); !ok || rowOrgId != orgId {
+			_ = tx.AddError(ErrCrossOrgWrite)
+		}
+	}
+	switch rows := reflect.Indirect(tx.Statement.ReflectValue); rows.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rows.Len(); i++ {
+			check(rows.Index(i))
+		}
+	case reflect.Struct:
+		check(rows)
+	}
+}
@@ -0,0 +1,40 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package orgscope
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+type ctxKey struct{}
+
+// With binds ctx to an organization, see db.WithOrgScope for how statements run with it are scoped.
+// It lives apart from package db so that binding a request does not open the database connection
+func With(ctx context.Context, orgId uuid.UUID) context.Context {
+	return context.WithValue(ctx, ctxKey{}, orgId)
+}
+
+// FromContext returns the organization ctx is bound to
+func FromContext(ctx context.Context) (uuid.UUID, bool) {
+	if ctx == nil {
+		return uuid.Nil, false
+	}
+	orgId, ok := ctx.Value(ctxKey{}).(uuid.UUID)
+	return orgId, ok
+}
@@ -0,0 +1,112 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/tracing"
+	commontracing "github.com/wso2/ai-agent-management-platform/libs/go-common/tracing"
+)
+
+// spanInstanceKey holds the span of a statement between its before and after callbacks
+const spanInstanceKey = "tracing:span"
+
+type statementSpan struct {
+	span      trace.Span
+	operation string
+	// Context of the statement before the span started, restored when it ends
+	parent context.Context
+}
+
+// registerTracing installs the callbacks recording a client span for every statement
+func registerTracing(gormDB *gorm.DB) error {
+	callbacks := gormDB.Callback()
+	err := errors.Join(
+		callbacks.Create().Before("*").Register("tracing:before_create", startStatementSpan("create")),
+		callbacks.Create().After("*").Register("tracing:after_create", endStatementSpan),
+		callbacks.Query().Before("*").Register("tracing:before_query", startStatementSpan("query")),
+		callbacks.Query().After("*").Register("tracing:after_query", endStatementSpan),
+		callbacks.Update().Before("*").Register("tracing:before_update", startStatementSpan("update")),
+		callbacks.Update().After("*").Register("tracing:after_update", endStatementSpan),
+		callbacks.Delete().Before("*").Register("tracing:before_delete", startStatementSpan("delete")),
+		callbacks.Delete().After("*").Register("tracing:after_delete", endStatementSpan),
+		callbacks.Row().Before("*").Register("tracing:before_row", startStatementSpan("row")),
+		callbacks.Row().After("*").Register("tracing:after_row", endStatementSpan),
+		callbacks.Raw().Before("*").Register("tracing:before_raw", startStatementSpan("raw")),
+		callbacks.Raw().After("*").Register("tracing:after_raw", endStatementSpan),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to register tracing callbacks: %w", err)
+	}
+	return nil
+}
+
+// startStatementSpan starts the span of a statement as a child of the span of its context
+func startStatementSpan(operation string) func(tx *gorm.DB) {
+	return func(tx *gorm.DB) {
+		parent := tx.Statement.Context
+		if parent == nil {
+			parent = context.Background()
+		}
+		ctx, span := commontracing.Tracer().Start(parent, operation,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(semconv.DBSystemPostgreSQL, semconv.DBOperationName(operation)),
+		)
+		tx.Statement.Context = ctx
+		tx.InstanceSet(spanInstanceKey, statementSpan{span: span, operation: operation, parent: parent})
+	}
+}
+
+// endStatementSpan records the sanitized statement and its outcome, missing records are not errors
+func endStatementSpan(tx *gorm.DB) {
+	value, ok := tx.InstanceGet(spanInstanceKey)
+	if !ok {
+		return
+	}
+	current, ok := value.(statementSpan)
+	if !ok {
+		return
+	}
+	tx.Statement.Context = current.parent
+	span := current.span
+	defer span.End()
+	if !span.IsRecording() {
+		return
+	}
+
+	if table := tx.Statement.Table; table != "" {
+		span.SetName(current.operation + " " + table)
+		span.SetAttributes(semconv.DBCollectionName(table))
+	}
+	if statement := tx.Statement.SQL.String(); statement != "" {
+		span.SetAttributes(semconv.DBQueryText(tracing.SanitizeSQL(statement)))
+	}
+	span.SetAttributes(attribute.Int64("db.rows_affected", tx.RowsAffected))
+	if tx.Error != nil && !errors.Is(tx.Error, gorm.ErrRecordNotFound) {
+		span.RecordError(tx.Error)
+		span.SetStatus(codes.Error, tx.Error.Error())
+	}
+}
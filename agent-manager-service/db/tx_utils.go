@@ -19,8 +19,11 @@ package db
 import (
 	"context"
 	"errors"
+	"log/slog"
+	"math/rand"
 	"time"
 
+	"github.com/jackc/pgx/v5/pgconn"
 	"gorm.io/gorm"
 
 	"github.com/wso2/ai-agent-management-platform/agent-manager-service/config"
@@ -49,6 +52,62 @@ func CtxWithTx(ctx context.Context, tx *gorm.DB) context.Context {
 	return context.WithValue(ctx, ctxTX{}, tx)
 }
 
+// maxTxAttempts bounds the runs of a transaction aborted by a deadlock or a serialization failure
+const maxTxAttempts = 3
+
+// txRetryBackoff returns the pause before running an aborted transaction again, jittered so that the transactions
+// that deadlocked each other do not collide again
+var txRetryBackoff = func(attempt int) time.Duration {
+	base := time.Duration(attempt) * 50 * time.Millisecond
+	return base + time.Duration(rand.Int63n(int64(base)))
+}
+
+// WithTx runs fn in a transaction that is committed when fn returns nil and rolled back otherwise, fn reaches
+// the transaction through DB(txCtx). Within a transaction WithTx runs fn in a savepoint of it instead.
+// A transaction the database aborted on a deadlock or a serialization failure is run again, up to
+// maxTxAttempts times, so fn must only have effects on the database
+func WithTx(ctx context.Context, fn func(txCtx context.Context) error) error {
+	run := func(tx *gorm.DB) error {
+		return fn(CtxWithTx(ctx, tx))
+	}
+	if _, ok := ctx.Value(ctxTX{}).(*gorm.DB); ok {
+		// The enclosing transaction is aborted by a conflict as well, it is the one to run again
+		return DB(ctx).Transaction(run)
+	}
+
+	for attempt := 1; ; attempt++ {
+		err := DB(ctx).Transaction(run)
+		if err == nil || attempt == maxTxAttempts || !IsTxConflictError(err) {
+			return err
+		}
+		slog.WarnContext(ctx, "Transaction aborted by a conflict, running it again", "attempt", attempt, "error", err)
+		select {
+		case <-time.After(txRetryBackoff(attempt)):
+		case <-ctx.Done():
+			return err
+		}
+	}
+}
+
+// IsTxConflictError reports whether err was caused by the database aborting a transaction on a deadlock or a
+// serialization failure, after which the transaction can be run again
+func IsTxConflictError(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && (pgErr.Code == "40001" || pgErr.Code == "40P01")
+}
+
 func IsRecordNotFoundError(err error) bool {
 	return errors.Is(err, gorm.ErrRecordNotFound)
 }
+
+// IsUniqueViolationError reports whether err was caused by a unique constraint violation
+func IsUniqueViolationError(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
+
+// IsForeignKeyViolationError reports whether err was caused by a foreign key constraint violation
+func IsForeignKeyViolationError(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23503"
+}
@@ -0,0 +1,57 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package dbmigrations
+
+import (
+	"gorm.io/gorm"
+)
+
+// create table managed_agent_versions and record the current configuration of existing agents as their first version
+var migration009 = migration{
+	ID: 9,
+	Migrate: func(db *gorm.DB) error {
+		createTable := `CREATE TABLE managed_agent_versions
+(
+   agent_id         UUID NOT NULL,
+   version          INTEGER NOT NULL,
+   name             VARCHAR(100) NOT NULL,
+   description      TEXT,
+   framework        VARCHAR(50) NOT NULL,
+   model_config     JSONB NOT NULL,
+   system_prompt    TEXT,
+   tools            JSONB NOT NULL DEFAULT '[]',
+   labels           JSONB NOT NULL DEFAULT '{}',
+   rolled_back_from INTEGER,
+   created_by       UUID,
+   created_at       TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+   PRIMARY KEY (agent_id, version),
+   CONSTRAINT fk_managed_agent_versions_agent_id FOREIGN KEY (agent_id) REFERENCES managed_agents(id) ON DELETE CASCADE
+)`
+
+		backfill := `INSERT INTO managed_agent_versions
+   (agent_id, version, name, description, framework, model_config, system_prompt, tools, labels, created_at)
+SELECT id, version, name, description, framework, model_config, system_prompt, tools, labels, updated_at
+FROM managed_agents`
+
+		return db.Transaction(func(tx *gorm.DB) error {
+			if err := runSQL(tx, createTable, backfill); err != nil {
+				return err
+			}
+			return nil
+		})
+	},
+}
@@ -0,0 +1,77 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package dbmigrations
+
+import (
+	"gorm.io/gorm"
+)
+
+// create tables prompt_templates and prompt_template_versions and let managed agents reference a prompt version
+var migration010 = migration{
+	ID: 10,
+	Migrate: func(db *gorm.DB) error {
+		createTemplatesTable := `CREATE TABLE prompt_templates
+(
+   id          UUID PRIMARY KEY,
+   org_id      UUID NOT NULL,
+   name        VARCHAR(100) NOT NULL,
+   description TEXT,
+   template    TEXT NOT NULL,
+   variables   JSONB NOT NULL DEFAULT '[]',
+   version     INTEGER NOT NULL DEFAULT 1,
+   created_at  TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+   updated_at  TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+   CONSTRAINT fk_prompt_templates_org_id FOREIGN KEY (org_id) REFERENCES organizations(id) ON DELETE CASCADE
+)`
+
+		createNameIndex := `CREATE UNIQUE INDEX uk_prompt_templates_org_name ON prompt_templates(org_id, name)`
+
+		createVersionsTable := `CREATE TABLE prompt_template_versions
+(
+   prompt_id   UUID NOT NULL,
+   version     INTEGER NOT NULL,
+   description TEXT,
+   template    TEXT NOT NULL,
+   variables   JSONB NOT NULL DEFAULT '[]',
+   created_by  UUID,
+   created_at  TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+   PRIMARY KEY (prompt_id, version),
+   CONSTRAINT fk_prompt_template_versions_prompt_id FOREIGN KEY (prompt_id) REFERENCES prompt_templates(id) ON DELETE CASCADE
+)`
+
+		// Agents reference an exact prompt version, the foreign key keeps referenced prompts from being deleted
+		alterManagedAgents := `ALTER TABLE managed_agents
+   ADD COLUMN prompt_id      UUID,
+   ADD COLUMN prompt_version INTEGER,
+   ADD CONSTRAINT chk_managed_agents_prompt_ref CHECK ((prompt_id IS NULL) = (prompt_version IS NULL)),
+   ADD CONSTRAINT fk_managed_agents_prompt FOREIGN KEY (prompt_id, prompt_version) REFERENCES prompt_template_versions(prompt_id, version)`
+
+		createPromptIndex := `CREATE INDEX idx_managed_agents_prompt_id ON managed_agents(prompt_id) WHERE prompt_id IS NOT NULL`
+
+		// Agent versions keep the reference as history only, so they do not block prompt deletion
+		alterManagedAgentVersions := `ALTER TABLE managed_agent_versions
+   ADD COLUMN prompt_id      UUID,
+   ADD COLUMN prompt_version INTEGER`
+
+		return db.Transaction(func(tx *gorm.DB) error {
+			if err := runSQL(tx, createTemplatesTable, createNameIndex, createVersionsTable, alterManagedAgents, createPromptIndex, alterManagedAgentVersions); err != nil {
+				return err
+			}
+			return nil
+		})
+	},
+}
@@ -0,0 +1,54 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package dbmigrations
+
+import (
+	"gorm.io/gorm"
+)
+
+// create table tools
+var migration011 = migration{
+	ID: 11,
+	Migrate: func(db *gorm.DB) error {
+		createTable := `CREATE TABLE tools
+(
+   id          UUID PRIMARY KEY,
+   org_id      UUID NOT NULL,
+   name        VARCHAR(64) NOT NULL,
+   description TEXT,
+   parameters  JSONB NOT NULL,
+   endpoint    JSONB NOT NULL,
+   tags        JSONB NOT NULL DEFAULT '[]',
+   version     INTEGER NOT NULL DEFAULT 1,
+   created_at  TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+   updated_at  TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+   CONSTRAINT fk_tools_org_id FOREIGN KEY (org_id) REFERENCES organizations(id) ON DELETE CASCADE
+)`
+
+		createNameIndex := `CREATE UNIQUE INDEX uk_tools_org_name ON tools(org_id, name)`
+		createTagsIndex := `CREATE INDEX idx_tools_tags ON tools USING GIN (tags jsonb_path_ops)`
+		// Finds the agents referencing a tool by id
+		createAgentToolsIndex := `CREATE INDEX idx_managed_agents_tools ON managed_agents USING GIN (tools jsonb_path_ops)`
+
+		return db.Transaction(func(tx *gorm.DB) error {
+			if err := runSQL(tx, createTable, createNameIndex, createTagsIndex, createAgentToolsIndex); err != nil {
+				return err
+			}
+			return nil
+		})
+	},
+}
@@ -0,0 +1,52 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package dbmigrations
+
+import (
+	"gorm.io/gorm"
+)
+
+// create table api_keys
+var migration012 = migration{
+	ID: 12,
+	Migrate: func(db *gorm.DB) error {
+		createTable := `CREATE TABLE api_keys
+(
+   id           UUID PRIMARY KEY,
+   user_idp_id  UUID NOT NULL,
+   name         VARCHAR(100) NOT NULL,
+   key_prefix   VARCHAR(16) NOT NULL,
+   key_hash     CHAR(64) NOT NULL,
+   scopes       JSONB NOT NULL DEFAULT '[]',
+   expires_at   TIMESTAMPTZ,
+   last_used_at TIMESTAMPTZ,
+   revoked_at   TIMESTAMPTZ,
+   created_at   TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+)`
+
+		createHashIndex := `CREATE UNIQUE INDEX uk_api_keys_key_hash ON api_keys(key_hash)`
+		// Revoked keys are kept for auditing and free their name
+		createNameIndex := `CREATE UNIQUE INDEX uk_api_keys_user_name ON api_keys(user_idp_id, name) WHERE revoked_at IS NULL`
+
+		return db.Transaction(func(tx *gorm.DB) error {
+			if err := runSQL(tx, createTable, createHashIndex, createNameIndex); err != nil {
+				return err
+			}
+			return nil
+		})
+	},
+}
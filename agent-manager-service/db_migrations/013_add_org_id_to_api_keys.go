@@ -0,0 +1,43 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package dbmigrations
+
+import (
+	"gorm.io/gorm"
+)
+
+// scope api keys to an organization
+var migration013 = migration{
+	ID: 13,
+	Migrate: func(db *gorm.DB) error {
+		addColumn := `ALTER TABLE api_keys ADD COLUMN org_id UUID`
+		// Existing keys move to the oldest organization of their owner, or the default organization of migration 006
+		backfill := `UPDATE api_keys SET org_id = COALESCE(
+   (SELECT o.id FROM organizations o WHERE o.user_idp_id = api_keys.user_idp_id ORDER BY o.created_at ASC LIMIT 1),
+   'af779290-c22d-4100-aefd-484d81fff60e'
+) WHERE org_id IS NULL`
+		addConstraints := `ALTER TABLE api_keys
+   ALTER COLUMN org_id SET NOT NULL,
+   ADD CONSTRAINT fk_api_keys_org_id FOREIGN KEY (org_id) REFERENCES organizations(id) ON DELETE CASCADE`
+		dropNameIndex := `DROP INDEX uk_api_keys_user_name`
+		createNameIndex := `CREATE UNIQUE INDEX uk_api_keys_org_user_name ON api_keys(org_id, user_idp_id, name) WHERE revoked_at IS NULL`
+
+		return db.Transaction(func(tx *gorm.DB) error {
+			return runSQL(tx, addColumn, backfill, addConstraints, dropNameIndex, createNameIndex)
+		})
+	},
+}
@@ -0,0 +1,48 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package dbmigrations
+
+import (
+	"gorm.io/gorm"
+)
+
+// create table idempotency_keys
+var migration015 = migration{
+	ID: 15,
+	Migrate: func(db *gorm.DB) error {
+		// status_code stays NULL while the first execution is in progress
+		createTable := `CREATE TABLE idempotency_keys
+(
+   principal        VARCHAR(64) NOT NULL,
+   idempotency_key  VARCHAR(255) NOT NULL,
+   route            TEXT NOT NULL,
+   request_hash     CHAR(64) NOT NULL,
+   status_code      INTEGER,
+   response_headers JSONB,
+   response_body    BYTEA,
+   created_at       TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+   expires_at       TIMESTAMPTZ NOT NULL,
+   PRIMARY KEY (principal, idempotency_key, route)
+)`
+
+		createExpiryIndex := `CREATE INDEX idx_idempotency_keys_expires_at ON idempotency_keys(expires_at)`
+
+		return db.Transaction(func(tx *gorm.DB) error {
+			return runSQL(tx, createTable, createExpiryIndex)
+		})
+	},
+}
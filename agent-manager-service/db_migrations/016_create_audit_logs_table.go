@@ -0,0 +1,55 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package dbmigrations
+
+import (
+	"gorm.io/gorm"
+)
+
+// create table audit_logs
+var migration016 = migration{
+	ID: 16,
+	Migrate: func(db *gorm.DB) error {
+		// Entries outlive the resources and organizations they refer to, hence no foreign keys
+		createTable := `CREATE TABLE audit_logs
+(
+   id             UUID PRIMARY KEY,
+   org_id         UUID NOT NULL,
+   actor_idp_id   UUID NOT NULL,
+   api_key_id     UUID,
+   action         VARCHAR(64) NOT NULL,
+   resource_type  VARCHAR(64) NOT NULL,
+   resource_id    VARCHAR(255) NOT NULL,
+   route          TEXT NOT NULL,
+   status_code    INTEGER NOT NULL,
+   changes        JSONB,
+   correlation_id VARCHAR(128) NOT NULL,
+   created_at     TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+)`
+
+		createTimeIndex := `CREATE INDEX idx_audit_logs_org_created_at ON audit_logs(org_id, created_at DESC)`
+		createActorIndex := `CREATE INDEX idx_audit_logs_org_actor ON audit_logs(org_id, actor_idp_id)`
+		createResourceIndex := `CREATE INDEX idx_audit_logs_org_resource ON audit_logs(org_id, resource_type, resource_id)`
+		// The log is append only
+		rejectUpdates := `CREATE RULE audit_logs_no_update AS ON UPDATE TO audit_logs DO INSTEAD NOTHING`
+		rejectDeletes := `CREATE RULE audit_logs_no_delete AS ON DELETE TO audit_logs DO INSTEAD NOTHING`
+
+		return db.Transaction(func(tx *gorm.DB) error {
+			return runSQL(tx, createTable, createTimeIndex, createActorIndex, createResourceIndex, rejectUpdates, rejectDeletes)
+		})
+	},
+}
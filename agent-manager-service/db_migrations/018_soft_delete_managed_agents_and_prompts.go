@@ -0,0 +1,45 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package dbmigrations
+
+import (
+	"gorm.io/gorm"
+)
+
+// soft delete managed agents and prompts, deleted ones no longer hold on to their name
+var migration018 = migration{
+	ID: 18,
+	Migrate: func(db *gorm.DB) error {
+		alterManagedAgents := `ALTER TABLE managed_agents ADD COLUMN deleted_at TIMESTAMPTZ`
+		alterPromptTemplates := `ALTER TABLE prompt_templates ADD COLUMN deleted_at TIMESTAMPTZ`
+
+		dropAgentNameIndex := `DROP INDEX uk_managed_agents_org_name`
+		createAgentNameIndex := `CREATE UNIQUE INDEX uk_managed_agents_org_name ON managed_agents(org_id, name) WHERE deleted_at IS NULL`
+		dropPromptNameIndex := `DROP INDEX uk_prompt_templates_org_name`
+		createPromptNameIndex := `CREATE UNIQUE INDEX uk_prompt_templates_org_name ON prompt_templates(org_id, name) WHERE deleted_at IS NULL`
+
+		// The purge job looks up the deleted rows
+		createAgentDeletedIndex := `CREATE INDEX idx_managed_agents_deleted_at ON managed_agents(deleted_at) WHERE deleted_at IS NOT NULL`
+		createPromptDeletedIndex := `CREATE INDEX idx_prompt_templates_deleted_at ON prompt_templates(deleted_at) WHERE deleted_at IS NOT NULL`
+
+		return db.Transaction(func(tx *gorm.DB) error {
+			return runSQL(tx, alterManagedAgents, alterPromptTemplates,
+				dropAgentNameIndex, createAgentNameIndex, dropPromptNameIndex, createPromptNameIndex,
+				createAgentDeletedIndex, createPromptDeletedIndex)
+		})
+	},
+}
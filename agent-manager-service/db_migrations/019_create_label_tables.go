@@ -0,0 +1,71 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package dbmigrations
+
+import (
+	"gorm.io/gorm"
+)
+
+// label prompts and tools like agents, and keep the labels of all three normalized for label selector queries
+var migration019 = migration{
+	ID: 19,
+	Migrate: func(db *gorm.DB) error {
+		alterPromptTemplates := `ALTER TABLE prompt_templates ADD COLUMN labels JSONB NOT NULL DEFAULT '{}'`
+		alterTools := `ALTER TABLE tools ADD COLUMN labels JSONB NOT NULL DEFAULT '{}'`
+
+		createAgentLabels := `CREATE TABLE managed_agent_labels
+(
+   agent_id UUID NOT NULL,
+   key      VARCHAR(317) NOT NULL,
+   value    VARCHAR(63) NOT NULL,
+   PRIMARY KEY (agent_id, key),
+   CONSTRAINT fk_managed_agent_labels_agent_id FOREIGN KEY (agent_id) REFERENCES managed_agents(id) ON DELETE CASCADE
+)`
+		createPromptLabels := `CREATE TABLE prompt_template_labels
+(
+   prompt_id UUID NOT NULL,
+   key       VARCHAR(317) NOT NULL,
+   value     VARCHAR(63) NOT NULL,
+   PRIMARY KEY (prompt_id, key),
+   CONSTRAINT fk_prompt_template_labels_prompt_id FOREIGN KEY (prompt_id) REFERENCES prompt_templates(id) ON DELETE CASCADE
+)`
+		createToolLabels := `CREATE TABLE tool_labels
+(
+   tool_id UUID NOT NULL,
+   key     VARCHAR(317) NOT NULL,
+   value   VARCHAR(63) NOT NULL,
+   PRIMARY KEY (tool_id, key),
+   CONSTRAINT fk_tool_labels_tool_id FOREIGN KEY (tool_id) REFERENCES tools(id) ON DELETE CASCADE
+)`
+		createAgentLabelsIndex := `CREATE INDEX idx_managed_agent_labels_key_value ON managed_agent_labels(key, value)`
+		createPromptLabelsIndex := `CREATE INDEX idx_prompt_template_labels_key_value ON prompt_template_labels(key, value)`
+		createToolLabelsIndex := `CREATE INDEX idx_tool_labels_key_value ON tool_labels(key, value)`
+
+		backfillAgentLabels := `INSERT INTO managed_agent_labels (agent_id, key, value)
+SELECT a.id, l.key, l.value FROM managed_agents a, jsonb_each_text(a.labels) l`
+
+		// Label selectors are answered from the label tables
+		dropAgentLabelsIndex := `DROP INDEX IF EXISTS idx_managed_agents_labels`
+
+		return db.Transaction(func(tx *gorm.DB) error {
+			return runSQL(tx, alterPromptTemplates, alterTools,
+				createAgentLabels, createPromptLabels, createToolLabels,
+				createAgentLabelsIndex, createPromptLabelsIndex, createToolLabelsIndex,
+				backfillAgentLabels, dropAgentLabelsIndex)
+		})
+	},
+}
@@ -0,0 +1,62 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package dbmigrations
+
+import (
+	"gorm.io/gorm"
+)
+
+// create tables agent_environments and agent_deployments
+var migration020 = migration{
+	ID: 20,
+	Migrate: func(db *gorm.DB) error {
+		createEnvironmentsTable := `CREATE TABLE agent_environments
+(
+   id          UUID PRIMARY KEY,
+   org_id      UUID NOT NULL,
+   name        VARCHAR(25) NOT NULL,
+   description TEXT,
+   created_at  TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+   CONSTRAINT fk_agent_environments_org_id FOREIGN KEY (org_id) REFERENCES organizations(id) ON DELETE CASCADE
+)`
+
+		createEnvironmentNameIndex := `CREATE UNIQUE INDEX uk_agent_environments_org_name ON agent_environments(org_id, name)`
+
+		// Deployments are append only, the latest deployment of an agent to an environment is the one it runs.
+		// The version foreign key keeps deployed versions from disappearing
+		createDeploymentsTable := `CREATE TABLE agent_deployments
+(
+   id             UUID PRIMARY KEY,
+   agent_id       UUID NOT NULL,
+   environment_id UUID NOT NULL,
+   version        INTEGER NOT NULL,
+   promoted_from  VARCHAR(25),
+   deployed_by    UUID,
+   deployed_at    TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+   CONSTRAINT fk_agent_deployments_agent_id FOREIGN KEY (agent_id) REFERENCES managed_agents(id) ON DELETE CASCADE,
+   CONSTRAINT fk_agent_deployments_environment_id FOREIGN KEY (environment_id) REFERENCES agent_environments(id) ON DELETE CASCADE,
+   CONSTRAINT fk_agent_deployments_version FOREIGN KEY (agent_id, version) REFERENCES managed_agent_versions(agent_id, version) ON DELETE CASCADE
+)`
+
+		createAgentIndex := `CREATE INDEX idx_agent_deployments_agent_env ON agent_deployments(agent_id, environment_id, deployed_at DESC)`
+		createEnvironmentIndex := `CREATE INDEX idx_agent_deployments_env ON agent_deployments(environment_id, agent_id, deployed_at DESC)`
+
+		return db.Transaction(func(tx *gorm.DB) error {
+			return runSQL(tx, createEnvironmentsTable, createEnvironmentNameIndex, createDeploymentsTable, createAgentIndex, createEnvironmentIndex)
+		})
+	},
+}
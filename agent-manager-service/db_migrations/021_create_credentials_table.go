@@ -0,0 +1,52 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package dbmigrations
+
+import (
+	"gorm.io/gorm"
+)
+
+// create table credentials
+var migration021 = migration{
+	ID: 21,
+	Migrate: func(db *gorm.DB) error {
+		// Values are encrypted with a data key of their own, encrypted_key holds that data key encrypted with
+		// the master key key_id names, so rotating master keys rewrites encrypted_key only
+		createCredentialsTable := `CREATE TABLE credentials
+(
+   id              UUID PRIMARY KEY,
+   org_id          UUID NOT NULL,
+   name            VARCHAR(25) NOT NULL,
+   description     TEXT,
+   key_id          VARCHAR(64) NOT NULL,
+   encrypted_key   BYTEA NOT NULL,
+   encrypted_value BYTEA NOT NULL,
+   preview         VARCHAR(16) NOT NULL,
+   created_by      UUID,
+   created_at      TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+   updated_at      TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+   CONSTRAINT fk_credentials_org_id FOREIGN KEY (org_id) REFERENCES organizations(id) ON DELETE CASCADE
+)`
+
+		createNameIndex := `CREATE UNIQUE INDEX uk_credentials_org_name ON credentials(org_id, name)`
+		createKeyIndex := `CREATE INDEX idx_credentials_key_id ON credentials(key_id)`
+
+		return db.Transaction(func(tx *gorm.DB) error {
+			return runSQL(tx, createCredentialsTable, createNameIndex, createKeyIndex)
+		})
+	},
+}
@@ -0,0 +1,75 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package dbmigrations
+
+import (
+	"gorm.io/gorm"
+)
+
+// create tables webhooks and webhook_deliveries
+var migration022 = migration{
+	ID: 22,
+	Migrate: func(db *gorm.DB) error {
+		// Signing secrets are encrypted like credentials and rotate with them
+		createWebhooksTable := `CREATE TABLE webhooks
+(
+   id               UUID PRIMARY KEY,
+   org_id           UUID NOT NULL,
+   url              TEXT NOT NULL,
+   description      TEXT,
+   events           JSONB NOT NULL,
+   active           BOOLEAN NOT NULL DEFAULT TRUE,
+   key_id           VARCHAR(64) NOT NULL,
+   encrypted_key    BYTEA NOT NULL,
+   encrypted_secret BYTEA NOT NULL,
+   created_by       UUID,
+   created_at       TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+   updated_at       TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+   CONSTRAINT fk_webhooks_org_id FOREIGN KEY (org_id) REFERENCES organizations(id) ON DELETE CASCADE
+)`
+
+		createWebhookOrgIndex := `CREATE INDEX idx_webhooks_org_id ON webhooks(org_id)`
+		createWebhookKeyIndex := `CREATE INDEX idx_webhooks_key_id ON webhooks(key_id)`
+
+		// The payload is kept as sent, so redeliveries carry the same bytes and signature. Pending deliveries are
+		// claimed by moving next_attempt_at past the send timeout, a dispatcher that dies mid send leaves them due again
+		createDeliveriesTable := `CREATE TABLE webhook_deliveries
+(
+   id              UUID PRIMARY KEY,
+   webhook_id      UUID NOT NULL,
+   event_id        UUID NOT NULL,
+   event_type      VARCHAR(64) NOT NULL,
+   payload         BYTEA NOT NULL,
+   status          VARCHAR(16) NOT NULL,
+   attempts        JSONB NOT NULL DEFAULT '[]',
+   next_attempt_at TIMESTAMPTZ,
+   redelivery_of   UUID,
+   created_at      TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+   completed_at    TIMESTAMPTZ,
+   CONSTRAINT fk_webhook_deliveries_webhook_id FOREIGN KEY (webhook_id) REFERENCES webhooks(id) ON DELETE CASCADE
+)`
+
+		createDeliveryWebhookIndex := `CREATE INDEX idx_webhook_deliveries_webhook ON webhook_deliveries(webhook_id, created_at DESC)`
+		createDeliveryDueIndex := `CREATE INDEX idx_webhook_deliveries_due ON webhook_deliveries(next_attempt_at) WHERE status = 'pending'`
+		createDeliveryCompletedIndex := `CREATE INDEX idx_webhook_deliveries_completed ON webhook_deliveries(completed_at) WHERE completed_at IS NOT NULL`
+
+		return db.Transaction(func(tx *gorm.DB) error {
+			return runSQL(tx, createWebhooksTable, createWebhookOrgIndex, createWebhookKeyIndex, createDeliveriesTable,
+				createDeliveryWebhookIndex, createDeliveryDueIndex, createDeliveryCompletedIndex)
+		})
+	},
+}
@@ -0,0 +1,66 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package dbmigrations
+
+import (
+	"gorm.io/gorm"
+)
+
+// create tables agent_budgets and agent_budget_alerts
+var migration024 = migration{
+	ID: 24,
+	Migrate: func(db *gorm.DB) error {
+		// Budgets go with their agent, soft deleted agents keep theirs until they are purged. Enforcement only
+		// records the intent for now, budgets raise alerts whatever it is
+		createBudgetsTable := `CREATE TABLE agent_budgets
+(
+   agent_id           UUID PRIMARY KEY,
+   org_id             UUID NOT NULL,
+   timezone           VARCHAR(64) NOT NULL,
+   daily_max_cost     DOUBLE PRECISION,
+   daily_max_tokens   BIGINT,
+   monthly_max_cost   DOUBLE PRECISION,
+   monthly_max_tokens BIGINT,
+   alert_thresholds   JSONB NOT NULL,
+   enforcement        VARCHAR(16) NOT NULL,
+   created_at         TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+   updated_at         TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+   CONSTRAINT fk_agent_budgets_agent_id FOREIGN KEY (agent_id) REFERENCES managed_agents(id) ON DELETE CASCADE
+)`
+
+		createBudgetOrgIndex := `CREATE INDEX idx_agent_budgets_org_id ON agent_budgets(org_id)`
+
+		// One row per threshold reached in a period, so each alert is raised once however often budgets are checked
+		createAlertsTable := `CREATE TABLE agent_budget_alerts
+(
+   agent_id     UUID NOT NULL,
+   period       VARCHAR(8) NOT NULL,
+   period_start TIMESTAMPTZ NOT NULL,
+   metric       VARCHAR(8) NOT NULL,
+   threshold    INTEGER NOT NULL,
+   alerted_at   TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+   PRIMARY KEY (agent_id, period, period_start, metric, threshold),
+   CONSTRAINT fk_agent_budget_alerts_agent_id FOREIGN KEY (agent_id) REFERENCES agent_budgets(agent_id) ON DELETE CASCADE
+)`
+
+		createAlertPeriodIndex := `CREATE INDEX idx_agent_budget_alerts_period_start ON agent_budget_alerts(period_start)`
+
+		return db.Transaction(func(tx *gorm.DB) error {
+			return runSQL(tx, createBudgetsTable, createBudgetOrgIndex, createAlertsTable, createAlertPeriodIndex)
+		})
+	},
+}
@@ -0,0 +1,77 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package dbmigrations
+
+import (
+	"gorm.io/gorm"
+)
+
+// create tables evaluation_runs and evaluation_results
+var migration025 = migration{
+	ID: 25,
+	Migrate: func(db *gorm.DB) error {
+		// The summary columns aggregate evaluation_results and are written together with the cursor checkpoint,
+		// a run resumed after a restart continues from the page after the last checkpoint
+		createRunsTable := `CREATE TABLE evaluation_runs
+(
+   id               UUID PRIMARY KEY,
+   org_id           UUID NOT NULL,
+   name             VARCHAR(100) NOT NULL DEFAULT '',
+   agent_id         UUID NOT NULL,
+   filter           JSONB NOT NULL,
+   evaluator        JSONB NOT NULL,
+   status           VARCHAR(16) NOT NULL,
+   cursor           TEXT NOT NULL DEFAULT '',
+   total_traces     INTEGER NOT NULL DEFAULT 0,
+   evaluated_traces INTEGER NOT NULL DEFAULT 0,
+   passed_traces    INTEGER NOT NULL DEFAULT 0,
+   failed_traces    INTEGER NOT NULL DEFAULT 0,
+   errored_traces   INTEGER NOT NULL DEFAULT 0,
+   score_sum        DOUBLE PRECISION NOT NULL DEFAULT 0,
+   error            TEXT NOT NULL DEFAULT '',
+   lease_id         UUID,
+   lease_until      TIMESTAMPTZ,
+   created_by       UUID NOT NULL,
+   created_at       TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+   started_at       TIMESTAMPTZ,
+   completed_at     TIMESTAMPTZ,
+   updated_at       TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+   CONSTRAINT fk_evaluation_runs_agent_id FOREIGN KEY (agent_id) REFERENCES managed_agents(id) ON DELETE CASCADE
+)`
+
+		createRunOrgIndex := `CREATE INDEX idx_evaluation_runs_org_id_created_at ON evaluation_runs(org_id, created_at DESC)`
+		// Workers only look for runs that are not finished
+		createRunActiveIndex := `CREATE INDEX idx_evaluation_runs_active ON evaluation_runs(created_at) WHERE status IN ('pending', 'running')`
+
+		createResultsTable := `CREATE TABLE evaluation_results
+(
+   run_id       UUID NOT NULL,
+   trace_id     VARCHAR(128) NOT NULL,
+   score        DOUBLE PRECISION,
+   passed       BOOLEAN NOT NULL,
+   reason       TEXT NOT NULL DEFAULT '',
+   error        TEXT NOT NULL DEFAULT '',
+   evaluated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+   PRIMARY KEY (run_id, trace_id),
+   CONSTRAINT fk_evaluation_results_run_id FOREIGN KEY (run_id) REFERENCES evaluation_runs(id) ON DELETE CASCADE
+)`
+
+		return db.Transaction(func(tx *gorm.DB) error {
+			return runSQL(tx, createRunsTable, createRunOrgIndex, createRunActiveIndex, createResultsTable)
+		})
+	},
+}
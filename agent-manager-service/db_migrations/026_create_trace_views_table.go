@@ -0,0 +1,50 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package dbmigrations
+
+import (
+	"gorm.io/gorm"
+)
+
+// create table trace_views
+var migration026 = migration{
+	ID: 26,
+	Migrate: func(db *gorm.DB) error {
+		// Filters are stored by the query parameter they set, so views referencing filters the trace list dropped
+		// can be told apart and rejected when used
+		createTraceViewsTable := `CREATE TABLE trace_views
+(
+   id          UUID PRIMARY KEY,
+   org_id      UUID NOT NULL,
+   created_by  UUID NOT NULL,
+   name        VARCHAR(100) NOT NULL,
+   visibility  VARCHAR(16) NOT NULL,
+   filters     JSONB NOT NULL,
+   sort        VARCHAR(32),
+   sort_order  VARCHAR(8),
+   created_at  TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+   CONSTRAINT fk_trace_views_org_id FOREIGN KEY (org_id) REFERENCES organizations(id) ON DELETE CASCADE
+)`
+
+		createNameIndex := `CREATE UNIQUE INDEX uk_trace_views_org_creator_name ON trace_views(org_id, created_by, name)`
+		createVisibilityIndex := `CREATE INDEX idx_trace_views_org_visibility ON trace_views(org_id, visibility)`
+
+		return db.Transaction(func(tx *gorm.DB) error {
+			return runSQL(tx, createTraceViewsTable, createNameIndex, createVisibilityIndex)
+		})
+	},
+}
@@ -0,0 +1,42 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package dbmigrations
+
+import (
+	"gorm.io/gorm"
+)
+
+// Listings page by the key of the last result of the previous page, these indexes serve the keysets of listings
+// sorted by creation or update time. Listings sorted by name use the unique name indexes
+var migration028 = migration{
+	ID: 28,
+	Migrate: func(db *gorm.DB) error {
+		createAgentCreatedIndex := `CREATE INDEX idx_managed_agents_org_created_at ON managed_agents(org_id, created_at, id)`
+		createAgentUpdatedIndex := `CREATE INDEX idx_managed_agents_org_updated_at ON managed_agents(org_id, updated_at, id)`
+		createPromptCreatedIndex := `CREATE INDEX idx_prompt_templates_org_created_at ON prompt_templates(org_id, created_at, id)`
+		createPromptUpdatedIndex := `CREATE INDEX idx_prompt_templates_org_updated_at ON prompt_templates(org_id, updated_at, id)`
+		createToolCreatedIndex := `CREATE INDEX idx_tools_org_created_at ON tools(org_id, created_at, id)`
+		createToolUpdatedIndex := `CREATE INDEX idx_tools_org_updated_at ON tools(org_id, updated_at, id)`
+		createWebhookCreatedIndex := `CREATE INDEX idx_webhooks_org_created_at ON webhooks(org_id, created_at, id)`
+		createWebhookUpdatedIndex := `CREATE INDEX idx_webhooks_org_updated_at ON webhooks(org_id, updated_at, id)`
+
+		return db.Transaction(func(tx *gorm.DB) error {
+			return runSQL(tx, createAgentCreatedIndex, createAgentUpdatedIndex, createPromptCreatedIndex, createPromptUpdatedIndex,
+				createToolCreatedIndex, createToolUpdatedIndex, createWebhookCreatedIndex, createWebhookUpdatedIndex)
+		})
+	},
+}
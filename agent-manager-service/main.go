@@ -21,51 +21,85 @@ import (
 	"flag"
 	"fmt"
 	"log/slog"
+	"net"
 	"net/http"
 	"os"
 	"time"
 
 	"github.com/wso2/ai-agent-management-platform/agent-manager-service/api"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/api/grpcapi"
 	"github.com/wso2/ai-agent-management-platform/agent-manager-service/config"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/middleware/logger"
+	"github.com/wso2/ai-agent-management-platform/libs/go-common/logging"
 
 	"go.uber.org/automaxprocs/maxprocs"
+	"google.golang.org/grpc"
 
 	dbmigrations "github.com/wso2/ai-agent-management-platform/agent-manager-service/db_migrations"
 	"github.com/wso2/ai-agent-management-platform/agent-manager-service/signals"
 	"github.com/wso2/ai-agent-management-platform/agent-manager-service/wiring"
+	"github.com/wso2/ai-agent-management-platform/libs/go-common/tracing"
 )
 
 func setupLogger(cfg *config.Config) {
-	var level slog.Level
-	switch cfg.LogLevel {
-	case "DEBUG":
-		level = slog.LevelDebug
-	case "INFO":
-		level = slog.LevelInfo
-	case "WARN":
-		level = slog.LevelWarn
-	case "ERROR":
-		level = slog.LevelError
-	default:
-		level = slog.LevelInfo // default to INFO
+	jsonHandler, err := logging.NewHandler(os.Stdout, logging.Options{
+		Level: cfg.LogLevel,
+		Sampling: logging.Sampling{
+			Initial:    cfg.LogSampling.Initial,
+			Thereafter: cfg.LogSampling.Thereafter,
+			Interval:   time.Duration(cfg.LogSampling.IntervalSeconds) * time.Second,
+		},
+	})
+	if err != nil {
+		slog.Error("failed to configure logger", "error", err)
+		os.Exit(1)
 	}
+	slog.SetDefault(slog.New(logger.NewContextHandler(jsonHandler)))
 
-	// Create handler options
-	opts := &slog.HandlerOptions{
-		Level: level,
+	if cfg.LogLevelFile != "" {
+		if _, err := logging.ReloadLevel(cfg.LogLevelFile, cfg.LogLevel); err != nil {
+			slog.Warn("failed to read log level file, keeping the configured level", "file", cfg.LogLevelFile, "error", err)
+		}
 	}
-	handler := slog.NewJSONHandler(os.Stdout, opts)
-	logger := slog.New(handler)
-	slog.SetDefault(logger)
 
 	slog.Info("Logger configured",
-		"level", level.String())
+		"level", logging.Level().String())
+}
+
+// reloadLogLevel re-reads the log level file on every reload, or restores the configured level when there is none,
+// undoing changes made through the internal API
+func reloadLogLevel(cfg *config.Config, reloadCh <-chan struct{}) {
+	for range reloadCh {
+		previous := logging.Level()
+		level, err := logging.ReloadLevel(cfg.LogLevelFile, cfg.LogLevel)
+		if err != nil {
+			slog.Error("failed to reload log level", "file", cfg.LogLevelFile, "error", err)
+			continue
+		}
+		slog.Warn("log level reloaded", "level", level.String(), "previous", previous.String())
+	}
+}
+
+// stopGRPCServer waits for the calls in flight, streams still open when ctx is done are cut off
+func stopGRPCServer(ctx context.Context, grpcServer *grpc.Server) {
+	stopped := make(chan struct{})
+	go func() {
+		grpcServer.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		slog.Error("forced grpc shutdown after timeout")
+		grpcServer.Stop()
+	}
 }
 
 func main() {
 	cfg := config.GetConfig()
 
 	setupLogger(cfg)
+	go reloadLogLevel(cfg, signals.SetupReloadHandler())
 
 	if config.GetConfig().AutoMaxProcsEnabled {
 		if _, err := maxprocs.Set(maxprocs.Logger(func(format string, args ...interface{}) {
@@ -91,6 +125,16 @@ func main() {
 	if !*serverFlag {
 		return
 	}
+	// Record traces of the service itself when an exporter endpoint is configured
+	shutdownTracing, err := tracing.Setup(context.Background(), tracing.Config{
+		Endpoint:    cfg.SelfTracing.OTLPEndpoint,
+		SampleRatio: cfg.SelfTracing.SampleRatio,
+		ServiceName: "agent-manager-service",
+	})
+	if err != nil {
+		slog.Error("failed to set up self-tracing", "error", err)
+		os.Exit(1)
+	}
 	dependencies, err := wiring.InitializeAppParams(cfg)
 	if err != nil {
 		slog.Error("failed to initialize app dependencies", "error", err)
@@ -107,9 +151,52 @@ func main() {
 		MaxHeaderBytes: cfg.MaxHeaderBytes,
 	}
 
+	// The gRPC API dispatches its calls to the handler of the REST API
+	var grpcServer *grpc.Server
+	if cfg.GRPCServerPort != 0 {
+		spec, err := api.NewSpec(dependencies.Authorizer)
+		if err != nil {
+			slog.Error("failed to describe the api", "error", err)
+			os.Exit(1)
+		}
+		grpcServer, err = grpcapi.NewServer(handler, spec)
+		if err != nil {
+			slog.Error("failed to create grpc server", "error", err)
+			os.Exit(1)
+		}
+		listener, err := net.Listen("tcp", fmt.Sprintf("%s:%d", cfg.ServerHost, cfg.GRPCServerPort))
+		if err != nil {
+			slog.Error("failed to listen for grpc", "port", cfg.GRPCServerPort, "error", err)
+			os.Exit(1)
+		}
+		go func() {
+			if err := grpcServer.Serve(listener); err != nil {
+				slog.Error("grpc server stopped", "error", err)
+			}
+		}()
+		slog.Info("grpc api is running", "address", listener.Addr().String())
+	}
+
 	stopCh := signals.SetupSignalHandler()
 
+	flusherCtx, stopFlusher := context.WithCancel(context.Background())
+	go dependencies.APIKeyService.RunLastUsedFlusher(flusherCtx, time.Duration(cfg.APIKeyLastUsedFlushIntervalSeconds)*time.Second)
+	go dependencies.IdempotencyService.RunPurger(flusherCtx, time.Duration(cfg.Idempotency.PurgeIntervalSeconds)*time.Second)
+	go dependencies.TrashService.RunPurger(flusherCtx, time.Duration(cfg.Trash.PurgeIntervalSeconds)*time.Second)
+	go dependencies.WebhookService.RunDispatcher(flusherCtx, time.Duration(cfg.Webhook.DispatchIntervalSeconds)*time.Second)
+	go dependencies.AgentBudgetService.RunChecker(flusherCtx, time.Duration(cfg.Budget.CheckIntervalSeconds)*time.Second)
+	go dependencies.EvaluationService.RunWorker(flusherCtx, time.Duration(cfg.Evaluation.PollIntervalSeconds)*time.Second)
+
+	auditCtx, stopAudit := context.WithCancel(context.Background())
+	auditDone := make(chan struct{})
+	go func() {
+		defer close(auditDone)
+		dependencies.AuditService.Run(auditCtx)
+	}()
+
+	shutdownDone := make(chan struct{})
 	go func() {
+		defer close(shutdownDone)
 		<-stopCh
 		ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
 		defer cancel()
@@ -117,6 +204,21 @@ func main() {
 		if err := server.Shutdown(ctx); err != nil {
 			slog.Error("forced shutdown after timeout", "error", err)
 		}
+		if grpcServer != nil {
+			stopGRPCServer(ctx, grpcServer)
+		}
+		// Write the API key uses recorded by the requests that were still in flight
+		stopFlusher()
+		if err := dependencies.APIKeyService.FlushLastUsed(ctx); err != nil {
+			slog.Error("failed to record api key uses on shutdown", "error", err)
+		}
+		// Write the audit log entries still queued
+		stopAudit()
+		<-auditDone
+		// Export the spans recorded while draining
+		if err := shutdownTracing(ctx); err != nil {
+			slog.Error("failed to flush self-tracing spans", "error", err)
+		}
 	}()
 
 	slog.Info("agent-manager-service is running", "address", server.Addr)
@@ -124,4 +226,5 @@ func main() {
 		slog.Error("failed to start server", "error", err)
 		os.Exit(1)
 	}
+	<-shutdownDone
 }
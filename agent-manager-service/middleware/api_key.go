@@ -29,7 +29,7 @@ func APIKeyMiddleware() func(http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			apiKey := r.Header.Get(config.GetConfig().APIKeyHeader)
 			if apiKey != config.GetConfig().APIKeyValue {
-				utils.WriteErrorResponse(w, http.StatusUnauthorized, "unauthorized: invalid API key")
+				utils.WriteProblemResponse(w, r, http.StatusUnauthorized, "unauthorized: invalid API key")
 				return
 			}
 			next.ServeHTTP(w, r)
@@ -0,0 +1,68 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/middleware/jwtassertion"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/middleware/logger"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/models"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/utils"
+)
+
+// APIKeyAuthenticator resolves the API key a client presents
+type APIKeyAuthenticator interface {
+	AuthenticateAPIKey(ctx context.Context, key string) (*models.APIKeyPrincipal, error)
+}
+
+// APIKeyAuth authenticates requests bearing an API key in the auth header and hands every other request to tokenAuth
+func APIKeyAuth(authenticator APIKeyAuthenticator, header string, tokenAuth jwtassertion.Middleware) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		tokenHandler := tokenAuth(next)
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			credential := strings.TrimPrefix(r.Header.Get(header), "Bearer ")
+			if !utils.IsAPIKey(credential) {
+				tokenHandler.ServeHTTP(w, r)
+				return
+			}
+
+			ctx := r.Context()
+			principal, err := authenticator.AuthenticateAPIKey(ctx, credential)
+			if err != nil {
+				switch {
+				case errors.Is(err, utils.ErrAPIKeyExpired):
+					utils.WriteProblemResponse(w, r, http.StatusUnauthorized, "The API key has expired")
+				case errors.Is(err, utils.ErrAPIKeyRevoked):
+					utils.WriteProblemResponse(w, r, http.StatusUnauthorized, "The API key has been revoked")
+				case errors.Is(err, utils.ErrAPIKeyInvalid):
+					utils.WriteProblemResponse(w, r, http.StatusUnauthorized, "Invalid API key")
+				default:
+					logger.GetLogger(ctx).Error("failed to authenticate api key", "error", err)
+					utils.WriteProblemResponse(w, r, http.StatusInternalServerError, "Failed to authenticate the API key")
+				}
+				return
+			}
+			ctx = jwtassertion.WithAPIKeyPrincipal(ctx, principal.KeyID, principal.UserIdpId, principal.OrgName, principal.Scopes)
+			ctx = withRateLimit(ctx, principal.RateLimit)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
@@ -0,0 +1,176 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/db/orgscope"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/middleware/correlation"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/middleware/jwtassertion"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/models"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/utils"
+)
+
+// AuditRecorder writes audit entries without blocking the request
+type AuditRecorder interface {
+	Record(ctx context.Context, auditLog *models.AuditLog)
+}
+
+// Audit records the successful requests to the routes of mux that require a permission other than a read one,
+// together with the caller, the resource and the changes the services serving them report. Entries are handed to
+// service to be written in the background. It runs after OrgScope, requests not bound to an organization are not
+// recorded.
+func Audit(mux *http.ServeMux, authz *Authorizer, service AuditRecorder) func(http.Handler) http.Handler {
+	audited := map[string]bool{}
+	// Paths with routes to their items, e.g. /orgs/{orgName}/tools for /orgs/{orgName}/tools/{toolId}
+	collections := map[string]bool{}
+	for _, route := range authz.Routes() {
+		method, routePath, _ := strings.Cut(route.Pattern, " ")
+		if isMutating(method) && !strings.HasSuffix(string(route.Permission), ":read") {
+			audited[route.Pattern] = true
+		}
+		for i := strings.Index(routePath, "/{"); i > 0; {
+			collections[routePath[:i]] = true
+			next := strings.Index(routePath[i+1:], "/{")
+			if next < 0 {
+				break
+			}
+			i += next + 1
+		}
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+			_, pattern := mux.Handler(r)
+			orgId, bound := orgscope.FromContext(ctx)
+			actor, authenticated := jwtassertion.GetSubject(ctx)
+			if !audited[pattern] || !bound || !authenticated {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			auditLog := &models.AuditLog{
+				ID:         uuid.New(),
+				OrgID:      orgId,
+				ActorIdpId: actor,
+				Route:      pattern,
+			}
+			if keyId, ok := jwtassertion.GetAPIKeyId(ctx); ok {
+				auditLog.APIKeyID = &keyId
+			}
+			auditLog.CorrelationID, _ = correlation.FromContext(ctx)
+
+			recorder := &statusRecorder{ResponseWriter: w}
+			next.ServeHTTP(recorder, r.WithContext(utils.WithAuditLog(ctx, auditLog)))
+			if recorder.status == 0 {
+				recorder.status = http.StatusOK
+			}
+			if recorder.status < http.StatusOK || recorder.status >= http.StatusMultipleChoices {
+				return
+			}
+
+			auditLog.StatusCode = recorder.status
+			resourceType, resourceID, action := auditedResource(r.Method, pattern, r.URL.Path, w.Header().Get("Location"), collections)
+			auditLog.ResourceType, auditLog.Action = resourceType, action
+			// Services name the resource when the request does not
+			if auditLog.ResourceID == "" {
+				auditLog.ResourceID = resourceID
+			}
+			auditLog.CreatedAt = time.Now()
+			service.Record(ctx, auditLog)
+		})
+	}
+}
+
+// auditedResource names the resource a request to pattern acted on and what it did. Requests to a resource path
+// create, update or delete it, requests to one of collections create a resource, named by the Location header when
+// there is one, and POST requests to other paths below a resource or collection, e.g. /agents/{agentId}/rollback
+// or /agents/import, perform that operation on it, as do custom methods like /agents:batchUpdate
+func auditedResource(method string, pattern string, requestPath string, location string, collections map[string]bool) (string, string, string) {
+	_, patternPath, _ := strings.Cut(pattern, " ")
+	segments := strings.Split(strings.Trim(patternPath, "/"), "/")
+	values := strings.Split(strings.Trim(requestPath, "/"), "/")
+	last := len(segments) - 1
+	isParam := func(i int) bool { return i >= 0 && strings.HasPrefix(segments[i], "{") }
+	if len(values) != len(segments) || last < 1 {
+		return segments[last], "", methodAction(method)
+	}
+
+	switch {
+	case strings.Contains(segments[last], ":"):
+		resourceType, action, _ := strings.Cut(segments[last], ":")
+		return resourceType, "", action
+	case isParam(last):
+		return segments[last-1], values[last], methodAction(method)
+	case collections[patternPath]:
+		if location == "" {
+			return segments[last], "", methodAction(method)
+		}
+		return segments[last], path.Base(location), methodAction(method)
+	case collections[path.Dir(patternPath)]:
+		if location == "" {
+			return segments[last-1], "", segments[last]
+		}
+		return segments[last-1], path.Base(location), segments[last]
+	case isParam(last-1) && last >= 2:
+		return segments[last-2], values[last-1], segments[last]
+	default:
+		return segments[last], "", methodAction(method)
+	}
+}
+
+func methodAction(method string) string {
+	switch method {
+	case http.MethodPut, http.MethodPatch:
+		return "update"
+	case http.MethodDelete:
+		return "delete"
+	default:
+		return "create"
+	}
+}
+
+// statusRecorder passes a response through while keeping its status
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (rec *statusRecorder) WriteHeader(status int) {
+	if rec.status == 0 {
+		rec.status = status
+	}
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+func (rec *statusRecorder) Write(p []byte) (int, error) {
+	if rec.status == 0 {
+		rec.status = http.StatusOK
+	}
+	return rec.ResponseWriter.Write(p)
+}
+
+func (rec *statusRecorder) Unwrap() http.ResponseWriter {
+	return rec.ResponseWriter
+}
@@ -0,0 +1,81 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package middleware
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestAuditedResource(t *testing.T) {
+	collections := map[string]bool{"/orgs/{orgName}/prompts": true, "/orgs/{orgName}/api-keys": true}
+	tests := []struct {
+		name       string
+		method     string
+		pattern    string
+		path       string
+		location   string
+		wantType   string
+		wantID     string
+		wantAction string
+	}{
+		{
+			name: "update of a resource", method: http.MethodPut,
+			pattern: "PUT /orgs/{orgName}/tools/{toolId}", path: "/orgs/acme/tools/t1",
+			wantType: "tools", wantID: "t1", wantAction: "update",
+		},
+		{
+			name: "deletion of a resource", method: http.MethodDelete,
+			pattern: "DELETE /orgs/{orgName}/api-keys/{apiKeyId}", path: "/orgs/acme/api-keys/k1",
+			wantType: "api-keys", wantID: "k1", wantAction: "delete",
+		},
+		{
+			name: "creation in a collection", method: http.MethodPost,
+			pattern: "POST /orgs/{orgName}/prompts", path: "/orgs/acme/prompts", location: "/api/v1/orgs/acme/prompts/p1",
+			wantType: "prompts", wantID: "p1", wantAction: "create",
+		},
+		{
+			name: "operation on a resource", method: http.MethodPost,
+			pattern: "POST /orgs/{orgName}/agents/{agentId}/rollback", path: "/orgs/acme/agents/a1/rollback",
+			wantType: "agents", wantID: "a1", wantAction: "rollback",
+		},
+		{
+			name: "operation on a collection", method: http.MethodPost,
+			pattern: "POST /orgs/{orgName}/prompts/import", path: "/orgs/acme/prompts/import",
+			wantType: "prompts", wantAction: "import",
+		},
+		{
+			name: "custom method on a collection", method: http.MethodPost,
+			pattern: "POST /orgs/{orgName}/agents:batchUpdate", path: "/orgs/acme/agents:batchUpdate",
+			wantType: "agents", wantAction: "batchUpdate",
+		},
+		{
+			name: "creation without a Location", method: http.MethodPost,
+			pattern: "POST /orgs/{orgName}/api-keys", path: "/orgs/acme/api-keys",
+			wantType: "api-keys", wantAction: "create",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resourceType, resourceID, action := auditedResource(tt.method, tt.pattern, tt.path, tt.location, collections)
+			if resourceType != tt.wantType || resourceID != tt.wantID || action != tt.wantAction {
+				t.Errorf("auditedResource() = (%q, %q, %q), want (%q, %q, %q)",
+					resourceType, resourceID, action, tt.wantType, tt.wantID, tt.wantAction)
+			}
+		})
+	}
+}
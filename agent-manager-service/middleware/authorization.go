@@ -0,0 +1,142 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/middleware/jwtassertion"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/utils"
+)
+
+// ProtectedRoute is a route registered together with the permission it requires
+type ProtectedRoute struct {
+	Pattern    string
+	Permission utils.Permission
+}
+
+// Authorizer resolves the permissions of callers and guards routes with them
+type Authorizer struct {
+	rolePermissions map[string]map[utils.Permission]bool
+	defaultRoles    []string
+	routes          []ProtectedRoute
+}
+
+type permissionsCtx struct{}
+
+var permissionsKey permissionsCtx
+
+// NewAuthorizer grants the built in roles their permissions, extended by extraRolePermissions which may also
+// introduce new roles. defaultRoles apply to tokens that carry no roles claim
+func NewAuthorizer(extraRolePermissions map[string][]string, defaultRoles []string) (*Authorizer, error) {
+	rolePermissions := map[string]map[utils.Permission]bool{}
+	for role, permissions := range utils.DefaultRolePermissions() {
+		rolePermissions[role] = map[utils.Permission]bool{}
+		for _, permission := range permissions {
+			rolePermissions[role][permission] = true
+		}
+	}
+	for role, permissions := range extraRolePermissions {
+		if strings.TrimSpace(role) == "" {
+			return nil, fmt.Errorf("role names must not be empty")
+		}
+		if rolePermissions[role] == nil {
+			rolePermissions[role] = map[utils.Permission]bool{}
+		}
+		for _, permission := range permissions {
+			if !utils.IsKnownPermission(permission) {
+				return nil, fmt.Errorf("role %q is granted unknown permission %q", role, permission)
+			}
+			rolePermissions[role][utils.Permission(permission)] = true
+		}
+	}
+	for _, role := range defaultRoles {
+		if rolePermissions[role] == nil {
+			return nil, fmt.Errorf("default role %q is not defined", role)
+		}
+	}
+	return &Authorizer{
+		rolePermissions: rolePermissions,
+		defaultRoles:    defaultRoles,
+	}, nil
+}
+
+// Permissions returns what the caller may do. API keys hold the permissions of their scopes,
+// tokens those of their roles
+func (a *Authorizer) Permissions(ctx context.Context) map[utils.Permission]bool {
+	granted := map[utils.Permission]bool{}
+	if _, ok := jwtassertion.GetAPIKeyId(ctx); ok {
+		for _, scope := range jwtassertion.GetScopes(ctx) {
+			granted[utils.Permission(scope)] = true
+		}
+		return granted
+	}
+	if jwtassertion.GetTokenClaims(ctx) == nil {
+		return granted
+	}
+	roles := jwtassertion.GetRoles(ctx)
+	if len(roles) == 0 {
+		roles = a.defaultRoles
+	}
+	for _, role := range roles {
+		// Roles this service does not know grant nothing
+		for permission := range a.rolePermissions[role] {
+			granted[permission] = true
+		}
+	}
+	return granted
+}
+
+// RequirePermission rejects callers without permission with a 403 naming it
+func (a *Authorizer) RequirePermission(permission utils.Permission, handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		granted := a.Permissions(ctx)
+		if !granted[permission] {
+			utils.WriteProblemResponse(w, r, http.StatusForbidden,
+				fmt.Sprintf("Missing permission %s", permission))
+			return
+		}
+		handler(w, r.WithContext(context.WithValue(ctx, permissionsKey, granted)))
+	}
+}
+
+// HandleFunc registers a route that requires permission, with the path parameter validation of HandleFuncWithValidation
+func (a *Authorizer) HandleFunc(mux *http.ServeMux, pattern string, permission utils.Permission, handler http.HandlerFunc) {
+	a.routes = append(a.routes, ProtectedRoute{Pattern: pattern, Permission: permission})
+	HandleFuncWithValidation(mux, pattern, a.RequirePermission(permission, handler))
+}
+
+// Routes returns the routes registered through HandleFunc, sorted by pattern
+func (a *Authorizer) Routes() []ProtectedRoute {
+	routes := append([]ProtectedRoute{}, a.routes...)
+	sort.Slice(routes, func(i, j int) bool { return routes[i].Pattern < routes[j].Pattern })
+	return routes
+}
+
+// GetPermissions returns the permissions of the caller resolved by RequirePermission
+func GetPermissions(ctx context.Context) map[utils.Permission]bool {
+	granted, ok := ctx.Value(permissionsKey).(map[utils.Permission]bool)
+	if !ok {
+		return map[utils.Permission]bool{}
+	}
+	return granted
+}
@@ -0,0 +1,114 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package correlation
+
+import (
+	"context"
+	"encoding/hex"
+	"net/http"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Header carries the id tying the logs of a request together, it is accepted from clients,
+// returned in responses and forwarded to the services called while serving the request
+const Header = "x-correlation-id"
+
+type ctxKey struct{}
+
+var correlationId ctxKey
+
+// ContextKey is the key the correlation id is bound to the request context under
+func ContextKey() any {
+	return correlationId
+}
+
+// WithID binds the correlation id of the request being served to ctx
+func WithID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, correlationId, id)
+}
+
+// FromContext returns the correlation id bound to ctx
+func FromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(correlationId).(string)
+	return id, ok && id != ""
+}
+
+// transport forwards the correlation id bound to the context of outbound requests
+// TraceparentHeader carries the W3C trace context of a request
+const TraceparentHeader = "traceparent"
+
+type traceIdCtxKey struct{}
+
+var traceId traceIdCtxKey
+
+// WithTraceID adds the id of the trace the request belongs to
+func WithTraceID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, traceId, id)
+}
+
+// TraceIDFromContext returns the id of the trace the request belongs to
+func TraceIDFromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(traceId).(string)
+	return id, ok && id != ""
+}
+
+// TraceIDFromTraceparent returns the trace id of a W3C traceparent header
+func TraceIDFromTraceparent(header string) (string, bool) {
+	parts := strings.Split(strings.TrimSpace(header), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || len(parts[1]) != 32 {
+		return "", false
+	}
+	if _, err := hex.DecodeString(parts[1]); err != nil || parts[1] == strings.Repeat("0", 32) {
+		return "", false
+	}
+	return strings.ToLower(parts[1]), true
+}
+
+type transport struct {
+	base http.RoundTripper
+}
+
+// NewTransport wraps base, http.DefaultTransport when nil, to forward the correlation id and the
+// trace context of the request being served to the called service
+func NewTransport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &transport{base: base}
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	id, ok := FromContext(req.Context())
+	forwardID := ok && req.Header.Get(Header) == ""
+	forwardTrace := trace.SpanContextFromContext(req.Context()).IsValid() && req.Header.Get(TraceparentHeader) == ""
+	if !forwardID && !forwardTrace {
+		return t.base.RoundTrip(req)
+	}
+	// A RoundTripper must not modify the request it is given
+	req = req.Clone(req.Context())
+	if forwardID {
+		req.Header.Set(Header, id)
+	}
+	if forwardTrace {
+		otel.GetTextMapPropagator().Inject(req.Context(), propagation.HeaderCarrier(req.Header))
+	}
+	return t.base.RoundTrip(req)
+}
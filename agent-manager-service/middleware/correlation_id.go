@@ -17,36 +17,54 @@
 package middleware
 
 import (
-	"context"
 	"net/http"
 
 	"github.com/google/uuid"
 
-	"github.com/wso2/ai-agent-management-platform/agent-manager-service/utils"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/middleware/correlation"
 )
 
 const (
-	CorrelationIDHeader string = "x-correlation-id"
+	CorrelationIDHeader string = correlation.Header
 )
 
+// Longest correlation id accepted from a client
+const maxCorrelationIDLength = 128
+
 // AddCorrelationID middleware adds or generates a correlation ID for request tracing
 func AddCorrelationID() func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			// Get or generate correlation ID
+			// Get or generate correlation ID, ids that could forge log lines are replaced
 			correlationID := r.Header.Get(CorrelationIDHeader)
-			if correlationID == "" {
+			if !isValidCorrelationID(correlationID) {
 				correlationID = uuid.New().String()
 			}
 
 			// Set response header
 			w.Header().Set(CorrelationIDHeader, correlationID)
 
-			// Add to request context
-			ctx := context.WithValue(r.Context(), utils.CorrelationIdCtxKey(), correlationID)
+			// Add to request context, with the trace the caller propagated
+			ctx := correlation.WithID(r.Context(), correlationID)
+			if traceID, ok := correlation.TraceIDFromTraceparent(r.Header.Get(correlation.TraceparentHeader)); ok {
+				ctx = correlation.WithTraceID(ctx, traceID)
+			}
 			r = r.WithContext(ctx)
 
 			next.ServeHTTP(w, r)
 		})
 	}
 }
+
+func isValidCorrelationID(id string) bool {
+	if id == "" || len(id) > maxCorrelationIDLength {
+		return false
+	}
+	for _, c := range id {
+		isAlphanumeric := (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+		if !isAlphanumeric && c != '-' && c != '_' && c != '.' && c != ':' {
+			return false
+		}
+	}
+	return true
+}
@@ -18,33 +18,110 @@ package middleware
 
 import (
 	"net/http"
+	"strconv"
+	"strings"
 )
 
-// CORS enables Cross-Origin Resource Sharing for the provided origin.
-// It sets the necessary headers and short-circuits OPTIONS preflight requests.
-func CORS(allowedOrigin string) func(http.Handler) http.Handler {
+// CORSOptions configures Cross-Origin Resource Sharing, empty lists and a zero MaxAge take the defaults
+type CORSOptions struct {
+	// Origins allowed to call the API, "*", exact origins or patterns such as https://*.example.dev (see originPattern)
+	AllowedOrigins []string
+	AllowedMethods []string
+	// Request headers allowed in preflights, "*" allows whatever headers a preflight asks for
+	AllowedHeaders []string
+	// Response headers browser scripts may read
+	ExposedHeaders []string
+	// Seconds browsers may cache a preflight, negative omits the header
+	MaxAge int
+	// Lets browsers send cookies and Authorization headers. Browsers refuse credentials for a literal "*", so
+	// with credentials allowed the bare "*" names the origin that asked instead
+	AllowCredentials bool
+}
+
+// DefaultCORSOptions allows the given origins with the methods and headers the console uses
+func DefaultCORSOptions(allowedOrigins ...string) CORSOptions {
+	return CORSOptions{
+		AllowedOrigins: allowedOrigins,
+		AllowedMethods: []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowedHeaders: []string{"Authorization", "Content-Type", "X-Requested-With", "Accept", "Origin", CorrelationIDHeader, IdempotencyKeyHeader, "If-Match", "If-None-Match"},
+		ExposedHeaders: []string{CorrelationIDHeader, IdempotentReplayedHeader, "ETag"},
+		MaxAge:         86400,
+	}
+}
+
+// corsVary lists the request headers CORS responses vary on
+var corsVary = []string{"Origin", "Access-Control-Request-Method", "Access-Control-Request-Headers"}
+
+// CORS enables Cross-Origin Resource Sharing for the allowed origins.
+// It sets the necessary headers and short-circuits preflight requests, OPTIONS requests naming no
+// Access-Control-Request-Method reach the handlers. Disallowed origins get no allow headers: their preflights
+// are refused with 403 and their actual requests are left to be blocked by the browser.
+func CORS(opts CORSOptions) func(http.Handler) http.Handler {
+	defaults := DefaultCORSOptions()
+	if len(opts.AllowedMethods) == 0 {
+		opts.AllowedMethods = defaults.AllowedMethods
+	}
+	if len(opts.AllowedHeaders) == 0 {
+		opts.AllowedHeaders = defaults.AllowedHeaders
+	}
+	if len(opts.ExposedHeaders) == 0 {
+		opts.ExposedHeaders = defaults.ExposedHeaders
+	}
+	if opts.MaxAge == 0 {
+		opts.MaxAge = defaults.MaxAge
+	}
+	allowed := make([]originPattern, 0, len(opts.AllowedOrigins))
+	for _, origin := range opts.AllowedOrigins {
+		allowed = append(allowed, parseOriginPattern(origin))
+	}
+	methods := strings.Join(opts.AllowedMethods, ", ")
+	headers := strings.Join(opts.AllowedHeaders, ", ")
+	echoHeaders := len(opts.AllowedHeaders) == 1 && opts.AllowedHeaders[0] == "*"
+	exposed := strings.Join(opts.ExposedHeaders, ", ")
+
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			origin := r.Header.Get("Origin")
+			preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
 
 			// Always set Vary headers for proper caching behavior
-			w.Header().Add("Vary", "Origin")
-			w.Header().Add("Vary", "Access-Control-Request-Method")
-			w.Header().Add("Vary", "Access-Control-Request-Headers")
-
-			if origin != "" && (allowedOrigin == "*" || origin == allowedOrigin) {
-				w.Header().Set("Access-Control-Allow-Origin", allowedOrigin)
-				// Allow credentials if using cookies or Authorization header
-				if allowedOrigin != "*" {
-					w.Header().Set("Access-Control-Allow-Credentials", "true")
+			addVary(w.Header(), corsVary...)
+
+			pattern, ok := matchOrigin(allowed, origin)
+			if ok {
+				if pattern.any && !opts.AllowCredentials {
+					w.Header().Set("Access-Control-Allow-Origin", "*")
+				} else {
+					// Patterns match many origins, the response names the origin that asked and varies on it
+					w.Header().Set("Access-Control-Allow-Origin", origin)
+					if opts.AllowCredentials {
+						w.Header().Set("Access-Control-Allow-Credentials", "true")
+					}
+				}
+				if preflight {
+					w.Header().Set("Access-Control-Allow-Methods", methods)
+					if echoHeaders {
+						if requested := r.Header.Get("Access-Control-Request-Headers"); requested != "" {
+							w.Header().Set("Access-Control-Allow-Headers", requested)
+						}
+					} else {
+						w.Header().Set("Access-Control-Allow-Headers", headers)
+					}
+					if opts.MaxAge > 0 {
+						w.Header().Set("Access-Control-Max-Age", strconv.Itoa(opts.MaxAge))
+					}
+				} else if exposed != "" {
+					// Browsers only read the exposed headers of actual responses
+					w.Header().Set("Access-Control-Expose-Headers", exposed)
 				}
-				w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
-				w.Header().Set("Access-Control-Allow-Headers", "Authorization, Content-Type, X-Requested-With, Accept, Origin, x-correlation-id")
-				w.Header().Set("Access-Control-Max-Age", "86400")
 			}
 
 			// Handle preflight request
-			if r.Method == http.MethodOptions {
+			if preflight {
+				if !ok {
+					w.WriteHeader(http.StatusForbidden)
+					return
+				}
 				w.WriteHeader(http.StatusNoContent)
 				return
 			}
@@ -53,3 +130,36 @@ func CORS(allowedOrigin string) func(http.Handler) http.Handler {
 		})
 	}
 }
+
+// matchOrigin returns the first allowed origin pattern matching origin
+func matchOrigin(allowed []originPattern, origin string) (originPattern, bool) {
+	if origin == "" {
+		return originPattern{}, false
+	}
+	for _, pattern := range allowed {
+		if pattern.matches(origin) {
+			return pattern, true
+		}
+	}
+	return originPattern{}, false
+}
+
+// addVary adds the values missing from the Vary header, so headers an upstream middleware already set are not
+// listed twice
+func addVary(header http.Header, values ...string) {
+	present := map[string]bool{}
+	for _, line := range header.Values("Vary") {
+		for _, value := range strings.Split(line, ",") {
+			present[strings.ToLower(strings.TrimSpace(value))] = true
+		}
+	}
+	if present["*"] {
+		return
+	}
+	for _, value := range values {
+		if !present[strings.ToLower(value)] {
+			header.Add("Vary", value)
+			present[strings.ToLower(value)] = true
+		}
+	}
+}
@@ -0,0 +1,137 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package middleware
+
+import (
+	"net/url"
+	"strings"
+)
+
+// originPattern matches the Origin header of requests against an allowed origin, which is either "*", an exact
+// origin or a pattern. In the host of a pattern a "*" label stands for exactly one label and a "**" label for one
+// or more, so https://*.example.dev matches https://pr-1.example.dev but neither https://example.dev nor
+// https://a.b.example.dev. A "*" port matches any port or none. A pattern without a scheme matches http and https.
+// The "null" origin of sandboxed iframes and file:// pages is only matched by an explicit "null", not even by "*".
+type originPattern struct {
+	any  bool
+	null bool
+	// scheme is empty when both http and https match
+	scheme string
+	labels []string
+	port   string
+}
+
+func parseOriginPattern(pattern string) originPattern {
+	pattern = strings.ToLower(strings.TrimSpace(pattern))
+	switch pattern {
+	case "*":
+		return originPattern{any: true}
+	case "null":
+		return originPattern{null: true}
+	}
+	scheme, hostPort, found := strings.Cut(pattern, "://")
+	if !found {
+		scheme, hostPort = "", pattern
+	}
+	host, port := splitHostPort(hostPort)
+	host = strings.TrimSuffix(strings.TrimPrefix(host, "["), "]")
+	// An explicit default port is dropped like in origins, so https://app.example.dev:443 matches https://app.example.dev
+	if port == defaultPort(scheme) {
+		port = ""
+	}
+	return originPattern{scheme: scheme, labels: strings.Split(host, "."), port: port}
+}
+
+// splitHostPort splits host[:port], unlike net.SplitHostPort it accepts a missing or "*" port
+func splitHostPort(hostPort string) (string, string) {
+	if i := strings.LastIndexByte(hostPort, ':'); i >= 0 && !strings.Contains(hostPort[i:], "]") {
+		return hostPort[:i], hostPort[i+1:]
+	}
+	return hostPort, ""
+}
+
+func (p originPattern) matches(origin string) bool {
+	if origin == "null" || p.null {
+		return origin == "null" && p.null
+	}
+	if p.any {
+		return true
+	}
+	u, err := url.Parse(origin)
+	if err != nil || u.Host == "" || u.User != nil || (u.Path != "" && u.Path != "/") || u.RawQuery != "" || u.Fragment != "" {
+		return false
+	}
+	scheme := strings.ToLower(u.Scheme)
+	if p.scheme == "" {
+		if scheme != "http" && scheme != "https" {
+			return false
+		}
+	} else if scheme != p.scheme {
+		return false
+	}
+
+	port := u.Port()
+	if port == defaultPort(scheme) {
+		port = ""
+	}
+	// A pattern without a scheme only knows its default port once the origin's scheme is known
+	patternPort := p.port
+	if patternPort == defaultPort(scheme) {
+		patternPort = ""
+	}
+	if patternPort != "*" && port != patternPort {
+		return false
+	}
+	return matchLabels(p.labels, strings.Split(strings.ToLower(u.Hostname()), "."))
+}
+
+func defaultPort(scheme string) string {
+	switch scheme {
+	case "http":
+		return "80"
+	case "https":
+		return "443"
+	default:
+		return ""
+	}
+}
+
+// matchLabels matches host labels against pattern labels, where "*" matches one label and "**" one or more
+func matchLabels(pattern []string, host []string) bool {
+	if len(pattern) == 0 {
+		return len(host) == 0
+	}
+	if len(host) == 0 {
+		return false
+	}
+	switch pattern[0] {
+	case "**":
+		for i := 1; i <= len(host); i++ {
+			if host[i-1] == "" {
+				return false
+			}
+			if matchLabels(pattern[1:], host[i:]) {
+				return true
+			}
+		}
+		return false
+	case "*":
+		return host[0] != "" && matchLabels(pattern[1:], host[1:])
+	default:
+		return pattern[0] == host[0] && matchLabels(pattern[1:], host[1:])
+	}
+}
@@ -0,0 +1,126 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestOriginPatternMatches(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string
+		origin  string
+		want    bool
+	}{
+		{"any origin", "*", "https://app.example.dev", true},
+		{"any origin excludes null", "*", "null", false},
+		{"explicit null", "null", "null", true},
+		{"explicit null does not match other origins", "null", "https://app.example.dev", false},
+		{"explicit null ignores case", "NULL", "null", true},
+
+		{"exact match", "http://localhost:3000", "http://localhost:3000", true},
+		{"exact match with another port", "http://localhost:3000", "http://localhost:3001", false},
+		{"exact match with another scheme", "http://localhost:3000", "https://localhost:3000", false},
+		{"exact match ignores host case", "https://App.Example.dev", "https://app.EXAMPLE.dev", true},
+		{"exact match with explicit default port", "https://app.example.dev", "https://app.example.dev:443", true},
+		{"pattern with explicit default port", "https://app.example.dev:443", "https://app.example.dev", true},
+		{"pattern with explicit default port and origin port", "https://app.example.dev:443", "https://app.example.dev:443", true},
+		{"pattern with explicit http default port", "http://localhost:80", "http://localhost", true},
+		{"pattern with another scheme's default port", "http://localhost:443", "http://localhost", false},
+		{"exact match without the pattern's port", "https://app.example.dev:8443", "https://app.example.dev", false},
+
+		{"one label wildcard", "https://*.example.dev", "https://pr-123.app.example.dev", false},
+		{"one label wildcard matches a subdomain", "https://*.example.dev", "https://pr-123.example.dev", true},
+		{"one label wildcard needs a label", "https://*.example.dev", "https://example.dev", false},
+		{"one label wildcard ignores host case", "https://*.example.dev", "https://PR-7.Example.Dev", true},
+		{"one label wildcard is not a suffix match", "https://*.example.dev", "https://evil-example.dev", false},
+		{"one label wildcard is not a prefix match", "https://*.example.dev", "https://pr-1.example.dev.evil.com", false},
+		{"one label wildcard with another scheme", "https://*.example.dev", "http://pr-1.example.dev", false},
+		{"one label wildcard with a port", "https://*.example.dev", "https://pr-1.example.dev:8443", false},
+		{"inner wildcard", "https://pr-1.*.example.dev", "https://pr-1.app.example.dev", true},
+
+		{"nested wildcard matches one label", "https://**.example.dev", "https://pr-1.example.dev", true},
+		{"nested wildcard matches nested labels", "https://**.example.dev", "https://pr-1.app.example.dev", true},
+		{"nested wildcard needs a label", "https://**.example.dev", "https://example.dev", false},
+		{"nested wildcard is not a suffix match", "https://**.example.dev", "https://evil-example.dev", false},
+		{"nested wildcard rejects empty labels", "https://**.example.dev", "https://a..example.dev", false},
+
+		{"port wildcard matches a port", "http://*.example.dev:*", "http://pr-1.example.dev:8080", true},
+		{"port wildcard matches no port", "http://*.example.dev:*", "http://pr-1.example.dev", true},
+		{"port wildcard on an exact host", "http://localhost:*", "http://localhost:5173", true},
+		{"port wildcard on an exact host with another host", "http://localhost:*", "http://localhost.evil.com:5173", false},
+
+		{"no scheme matches http", "*.example.dev", "http://pr-1.example.dev", true},
+		{"no scheme matches https", "*.example.dev", "https://pr-1.example.dev", true},
+		{"no scheme with https default port", "app.example.dev:443", "https://app.example.dev", true},
+		{"no scheme with https default port over http", "app.example.dev:443", "http://app.example.dev", false},
+		{"no scheme does not match other schemes", "*.example.dev", "ftp://pr-1.example.dev", false},
+
+		{"ipv6 host", "http://[::1]:3000", "http://[::1]:3000", true},
+		{"ipv6 host with another port", "http://[::1]:3000", "http://[::1]:3001", false},
+
+		{"null origin", "https://*.example.dev", "null", false},
+		{"origin with a path", "https://*.example.dev", "https://pr-1.example.dev/app", false},
+		{"origin with credentials", "https://*.example.dev", "https://user@pr-1.example.dev", false},
+		{"malformed origin", "https://*.example.dev", "https://%zz.example.dev", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseOriginPattern(tt.pattern).matches(tt.origin); got != tt.want {
+				t.Errorf("pattern %q matches %q = %v, want %v", tt.pattern, tt.origin, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCORSPatternPreflight(t *testing.T) {
+	opts := DefaultCORSOptions("https://*.example.dev")
+	opts.AllowCredentials = true
+	handler := CORS(opts)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("preflight reached the handler")
+	}))
+
+	tests := []struct {
+		origin      string
+		wantAllowed bool
+		wantStatus  int
+	}{
+		{"https://pr-123.example.dev", true, http.StatusNoContent},
+		{"https://evil-example.dev", false, http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodOptions, "/orgs", nil)
+		req.Header.Set("Origin", tt.origin)
+		req.Header.Set("Access-Control-Request-Method", http.MethodGet)
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+
+		if rr.Code != tt.wantStatus {
+			t.Errorf("%s: status = %d, want %d", tt.origin, rr.Code, tt.wantStatus)
+		}
+		allowOrigin := rr.Header().Get("Access-Control-Allow-Origin")
+		if tt.wantAllowed && (allowOrigin != tt.origin || rr.Header().Get("Access-Control-Allow-Credentials") != "true") {
+			t.Errorf("%s: Access-Control-Allow-Origin = %q, want the origin with credentials", tt.origin, allowOrigin)
+		}
+		if !tt.wantAllowed && (allowOrigin != "" || rr.Header().Get("Access-Control-Allow-Methods") != "") {
+			t.Errorf("%s: allow headers were set for a disallowed origin", tt.origin)
+		}
+	}
+}
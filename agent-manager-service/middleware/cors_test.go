@@ -0,0 +1,273 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func serveCORS(opts CORSOptions, method string, headers map[string]string) *httptest.ResponseRecorder {
+	handler := CORS(opts)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"3"`)
+		w.WriteHeader(http.StatusOK)
+	}))
+	req := httptest.NewRequest(method, "/orgs", nil)
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	return rr
+}
+
+func TestCORSDefaults(t *testing.T) {
+	origin := map[string]string{"Origin": "http://localhost:3000", "Access-Control-Request-Method": http.MethodPost}
+	rr := serveCORS(CORSOptions{AllowedOrigins: []string{"http://localhost:3000"}, AllowCredentials: true}, http.MethodOptions, origin)
+
+	want := map[string]string{
+		"Access-Control-Allow-Origin":      "http://localhost:3000",
+		"Access-Control-Allow-Credentials": "true",
+		"Access-Control-Allow-Methods":     "GET, POST, PUT, PATCH, DELETE, OPTIONS",
+		"Access-Control-Allow-Headers":     "Authorization, Content-Type, X-Requested-With, Accept, Origin, x-correlation-id, Idempotency-Key, If-Match, If-None-Match",
+		"Access-Control-Max-Age":           "86400",
+	}
+	for header, value := range want {
+		if got := rr.Header().Get(header); got != value {
+			t.Errorf("%s = %q, want %q", header, got, value)
+		}
+	}
+	if rr.Code != http.StatusNoContent {
+		t.Errorf("status = %d, want %d", rr.Code, http.StatusNoContent)
+	}
+}
+
+func TestCORSDefaultWildcard(t *testing.T) {
+	// CORS_ALLOWED_ORIGIN=* with every other setting left to its default
+	opts := DefaultCORSOptions("*")
+	if opts.AllowCredentials {
+		t.Fatal("credentials are allowed by default")
+	}
+	for _, method := range []string{http.MethodGet, http.MethodOptions} {
+		rr := serveCORS(opts, method, map[string]string{"Origin": "https://evil.example.com", "Access-Control-Request-Method": http.MethodPost})
+		if got := rr.Header().Get("Access-Control-Allow-Origin"); got != "*" {
+			t.Errorf("%s: Access-Control-Allow-Origin = %q, want *", method, got)
+		}
+		if got := rr.Header().Get("Access-Control-Allow-Credentials"); got != "" {
+			t.Errorf("%s: Access-Control-Allow-Credentials = %q, want none", method, got)
+		}
+	}
+}
+
+func TestCORSOriginsAndCredentials(t *testing.T) {
+	tests := []struct {
+		name            string
+		allowed         string
+		credentials     bool
+		origin          string
+		wantOrigin      string
+		wantCredentials string
+	}{
+		{"any origin without credentials", "*", false, "https://app.example.dev", "*", ""},
+		{"any origin with credentials echoes the origin", "*", true, "https://app.example.dev", "https://app.example.dev", "true"},
+		{"any origin with credentials echoes each origin", "*", true, "http://localhost:5173", "http://localhost:5173", "true"},
+		{"exact origin without credentials", "https://app.example.dev", false, "https://app.example.dev", "https://app.example.dev", ""},
+		{"exact origin with credentials", "https://app.example.dev", true, "https://app.example.dev", "https://app.example.dev", "true"},
+		{"pattern with credentials", "https://*.example.dev", true, "https://pr-1.example.dev", "https://pr-1.example.dev", "true"},
+		{"disallowed origin with credentials", "https://app.example.dev", true, "https://evil.example.dev", "", ""},
+		{"null origin is not any origin", "*", false, "null", "", ""},
+		{"null origin is not any origin with credentials", "*", true, "null", "", ""},
+		{"null origin opted in", "null", false, "null", "null", ""},
+		{"null origin opted in with credentials", "null", true, "null", "null", "true"},
+		{"null opt-in allows no other origin", "null", true, "https://app.example.dev", "", ""},
+		{"no origin", "*", true, "", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := DefaultCORSOptions(tt.allowed)
+			opts.AllowCredentials = tt.credentials
+			headers := map[string]string{}
+			if tt.origin != "" {
+				headers["Origin"] = tt.origin
+			}
+
+			for _, method := range []string{http.MethodGet, http.MethodOptions} {
+				if method == http.MethodOptions {
+					headers["Access-Control-Request-Method"] = http.MethodPost
+				}
+				rr := serveCORS(opts, method, headers)
+				if got := rr.Header().Get("Access-Control-Allow-Origin"); got != tt.wantOrigin {
+					t.Errorf("%s: Access-Control-Allow-Origin = %q, want %q", method, got, tt.wantOrigin)
+				}
+				if got := rr.Header().Get("Access-Control-Allow-Credentials"); got != tt.wantCredentials {
+					t.Errorf("%s: Access-Control-Allow-Credentials = %q, want %q", method, got, tt.wantCredentials)
+				}
+				if rr.Header().Get("Access-Control-Allow-Origin") == "*" && rr.Header().Get("Access-Control-Allow-Credentials") != "" {
+					t.Errorf("%s: credentials were allowed for *", method)
+				}
+				if vary := rr.Header().Values("Vary"); len(vary) == 0 || vary[0] != "Origin" {
+					t.Errorf("%s: Vary = %q, want Origin", method, vary)
+				}
+			}
+		})
+	}
+}
+
+func TestCORSConfiguredHeaders(t *testing.T) {
+	opts := CORSOptions{
+		AllowedOrigins: []string{"https://console.example.dev", "https://*.preview.example.dev"},
+		AllowedMethods: []string{"GET", "PUT"},
+		AllowedHeaders: []string{"Authorization", "x-amp-org-id", "If-Match"},
+		ExposedHeaders: []string{"ETag", CorrelationIDHeader},
+		MaxAge:         -1,
+	}
+
+	t.Run("Preflights should list the configured methods and headers", func(t *testing.T) {
+		rr := serveCORS(opts, http.MethodOptions, map[string]string{
+			"Origin":                        "https://pr-9.preview.example.dev",
+			"Access-Control-Request-Method": http.MethodPut,
+		})
+		if got := rr.Header().Get("Access-Control-Allow-Methods"); got != "GET, PUT" {
+			t.Errorf("Access-Control-Allow-Methods = %q", got)
+		}
+		if got := rr.Header().Get("Access-Control-Allow-Headers"); got != "Authorization, x-amp-org-id, If-Match" {
+			t.Errorf("Access-Control-Allow-Headers = %q", got)
+		}
+		if got := rr.Header().Get("Access-Control-Max-Age"); got != "" {
+			t.Errorf("Access-Control-Max-Age = %q, want none", got)
+		}
+		if got := rr.Header().Get("Access-Control-Allow-Credentials"); got != "" {
+			t.Errorf("Access-Control-Allow-Credentials = %q, want none", got)
+		}
+	})
+
+	t.Run("Actual responses should expose the configured headers", func(t *testing.T) {
+		rr := serveCORS(opts, http.MethodGet, map[string]string{"Origin": "https://console.example.dev"})
+		if rr.Code != http.StatusOK {
+			t.Fatalf("status = %d, want %d", rr.Code, http.StatusOK)
+		}
+		if got := rr.Header().Get("Access-Control-Expose-Headers"); got != "ETag, x-correlation-id" {
+			t.Errorf("Access-Control-Expose-Headers = %q", got)
+		}
+		if got := rr.Header().Get("Access-Control-Allow-Origin"); got != "https://console.example.dev" {
+			t.Errorf("Access-Control-Allow-Origin = %q", got)
+		}
+	})
+
+	t.Run("Disallowed origins should get no CORS headers", func(t *testing.T) {
+		rr := serveCORS(opts, http.MethodGet, map[string]string{"Origin": "https://evil.example.dev"})
+		if got := rr.Header().Get("Access-Control-Expose-Headers"); got != "" {
+			t.Errorf("Access-Control-Expose-Headers = %q", got)
+		}
+		if got := rr.Header().Get("Access-Control-Allow-Origin"); got != "" {
+			t.Errorf("Access-Control-Allow-Origin = %q", got)
+		}
+	})
+}
+
+func TestCORSEchoesRequestedHeaders(t *testing.T) {
+	opts := DefaultCORSOptions("https://console.example.dev")
+	opts.AllowedHeaders = []string{"*"}
+
+	rr := serveCORS(opts, http.MethodOptions, map[string]string{
+		"Origin":                         "https://console.example.dev",
+		"Access-Control-Request-Method":  http.MethodPut,
+		"Access-Control-Request-Headers": "if-match, x-amp-org-id",
+	})
+	if got := rr.Header().Get("Access-Control-Allow-Headers"); got != "if-match, x-amp-org-id" {
+		t.Errorf("Access-Control-Allow-Headers = %q, want the requested headers", got)
+	}
+}
+
+func TestCORSPassesPlainOptionsRequests(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("OPTIONS /orgs", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Allow", "GET, POST, OPTIONS")
+		w.WriteHeader(http.StatusOK)
+	})
+	handler := CORS(DefaultCORSOptions("https://console.example.dev"))(mux)
+
+	tests := []struct {
+		name    string
+		headers map[string]string
+		want    int
+	}{
+		{"Requests without an origin should reach the route", map[string]string{}, http.StatusOK},
+		{"Cross-origin requests that are not preflights should reach the route", map[string]string{"Origin": "https://console.example.dev"}, http.StatusOK},
+		{"Preflights should be answered by the middleware", map[string]string{
+			"Origin":                        "https://console.example.dev",
+			"Access-Control-Request-Method": http.MethodPost,
+		}, http.StatusNoContent},
+		{"Preflights of disallowed origins should be refused", map[string]string{
+			"Origin":                        "https://evil.example.dev",
+			"Access-Control-Request-Method": http.MethodPost,
+		}, http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodOptions, "/orgs", nil)
+			for key, value := range tt.headers {
+				req.Header.Set(key, value)
+			}
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			if rr.Code != tt.want {
+				t.Fatalf("status = %d, want %d", rr.Code, tt.want)
+			}
+			routed := rr.Header().Get("Allow") != ""
+			if routed != (tt.want == http.StatusOK) {
+				t.Errorf("Allow = %q, want it set only by the route", rr.Header().Get("Allow"))
+			}
+			if tt.want == http.StatusForbidden && (rr.Header().Get("Access-Control-Allow-Origin") != "" || rr.Header().Get("Access-Control-Allow-Methods") != "") {
+				t.Error("allow headers were set for a disallowed origin")
+			}
+		})
+	}
+}
+
+func TestCORSDoesNotDuplicateVary(t *testing.T) {
+	upstream := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("Vary", "Accept-Encoding, origin")
+			next.ServeHTTP(w, r)
+		})
+	}
+	handler := upstream(CORS(DefaultCORSOptions("https://console.example.dev"))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})))
+
+	req := httptest.NewRequest(http.MethodGet, "/orgs", nil)
+	req.Header.Set("Origin", "https://console.example.dev")
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	want := []string{"Accept-Encoding, origin", "Access-Control-Request-Method", "Access-Control-Request-Headers"}
+	if got := rr.Header().Values("Vary"); !reflect.DeepEqual(got, want) {
+		t.Errorf("Vary = %q, want %q", got, want)
+	}
+
+	// Wrapping the middleware twice adds nothing the second time
+	cors := CORS(DefaultCORSOptions("https://console.example.dev"))
+	twice := cors(cors(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})))
+	rr = httptest.NewRecorder()
+	twice.ServeHTTP(rr, req)
+	if got := rr.Header().Values("Vary"); len(got) != 3 {
+		t.Errorf("Vary = %q, want each value once", got)
+	}
+}
@@ -0,0 +1,195 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/google/uuid"
+
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/middleware/jwtassertion"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/middleware/logger"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/models"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/utils"
+)
+
+const (
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotentReplayedHeader marks a response replayed from the first execution of a request
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+	maxIdempotencyKeyLength  = 255
+	// idempotencyRetryAfter is the wait in seconds suggested to a retry arriving while the first execution runs
+	idempotencyRetryAfter = "1"
+)
+
+// replayedHeaders are the response headers recorded with the response of a request
+var replayedHeaders = []string{"Content-Type", "Location", "ETag"}
+
+// IdempotencyStore claims idempotency keys and records the responses of the requests that claimed them,
+// see services.IdempotencyService
+type IdempotencyStore interface {
+	Begin(ctx context.Context, principal string, key string, route string, requestHash string) (*models.IdempotencyKey, error)
+	Complete(ctx context.Context, key *models.IdempotencyKey, statusCode int, headers map[string]string, body []byte) error
+	Release(ctx context.Context, key *models.IdempotencyKey) error
+}
+
+// Idempotency makes POST, PUT, PATCH and DELETE requests carrying an Idempotency-Key safe to retry. The response
+// of the first execution is recorded per caller, key and route, and retries with the same body get it back without
+// running the handler again. Retries arriving while the first execution runs are answered with 409 and Retry-After,
+// a key reused with a different body with 422. Server errors are not recorded, so a retry runs the request again.
+// Responses of the unrecorded routes of mux carry secrets that are only stored hashed, such as created API keys,
+// their requests run without an idempotency record whatever key they carry.
+// It runs after authentication, requests without a caller are left untouched.
+func Idempotency(mux *http.ServeMux, service IdempotencyStore, unrecordedRoutes ...string) func(http.Handler) http.Handler {
+	unrecorded := make(map[string]bool, len(unrecordedRoutes))
+	for _, route := range unrecordedRoutes {
+		unrecorded[route] = true
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(IdempotencyKeyHeader)
+			principal := idempotencyPrincipal(r.Context())
+			if key == "" || principal == "" || !isMutating(r.Method) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if _, pattern := mux.Handler(r); unrecorded[pattern] {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if len(key) > maxIdempotencyKeyLength {
+				utils.WriteProblemResponse(w, r, http.StatusBadRequest,
+					fmt.Sprintf("%s must be at most %d characters", IdempotencyKeyHeader, maxIdempotencyKeyLength))
+				return
+			}
+
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				utils.WriteBodyProblem(w, r, err)
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+			hash := sha256.Sum256(body)
+
+			ctx := r.Context()
+			claim, err := service.Begin(ctx, principal, key, r.Method+" "+r.URL.Path, hex.EncodeToString(hash[:]))
+			if err != nil {
+				if errors.Is(err, utils.ErrIdempotencyKeyInProgress) {
+					w.Header().Set("Retry-After", idempotencyRetryAfter)
+				}
+				utils.WriteErrorProblem(w, r, err, "Failed to process the Idempotency-Key")
+				return
+			}
+			if claim.StatusCode != nil {
+				replay(w, claim)
+				return
+			}
+
+			// The outcome is recorded even when the request timed out or the client went away
+			storeCtx := context.WithoutCancel(ctx)
+			recorder := &idempotencyRecorder{ResponseWriter: w}
+			completed := false
+			// A panicking handler frees the key for a retry
+			defer func() {
+				if !completed {
+					_ = service.Release(storeCtx, claim)
+				}
+			}()
+			next.ServeHTTP(recorder, r)
+			completed = true
+
+			if recorder.status == 0 {
+				recorder.status = http.StatusOK
+			}
+			if recorder.status >= http.StatusInternalServerError {
+				_ = service.Release(storeCtx, claim)
+				return
+			}
+			headers := make(map[string]string, len(replayedHeaders))
+			for _, name := range replayedHeaders {
+				if value := w.Header().Get(name); value != "" {
+					headers[name] = value
+				}
+			}
+			if err := service.Complete(storeCtx, claim, recorder.status, headers, recorder.body.Bytes()); err != nil {
+				logger.GetLogger(ctx).Error("failed to record idempotent response", "error", err)
+			}
+		})
+	}
+}
+
+// idempotencyPrincipal identifies the caller a key belongs to, empty for unauthenticated requests
+func idempotencyPrincipal(ctx context.Context) string {
+	if keyId, ok := jwtassertion.GetAPIKeyId(ctx); ok {
+		return "key:" + keyId.String()
+	}
+	if subject, ok := jwtassertion.GetSubject(ctx); ok && subject != uuid.Nil {
+		return "sub:" + subject.String()
+	}
+	return ""
+}
+
+func isMutating(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// replay answers a retry with the response recorded for its key
+func replay(w http.ResponseWriter, key *models.IdempotencyKey) {
+	for name, value := range key.ResponseHeaders {
+		w.Header().Set(name, value)
+	}
+	w.Header().Set(IdempotentReplayedHeader, "true")
+	w.WriteHeader(*key.StatusCode)
+	_, _ = w.Write(key.ResponseBody)
+}
+
+// idempotencyRecorder passes a response through while keeping a copy of its status and body
+type idempotencyRecorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (rec *idempotencyRecorder) WriteHeader(status int) {
+	if rec.status == 0 {
+		rec.status = status
+	}
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+func (rec *idempotencyRecorder) Write(p []byte) (int, error) {
+	if rec.status == 0 {
+		rec.status = http.StatusOK
+	}
+	rec.body.Write(p)
+	return rec.ResponseWriter.Write(p)
+}
+
+func (rec *idempotencyRecorder) Unwrap() http.ResponseWriter {
+	return rec.ResponseWriter
+}
@@ -33,6 +33,9 @@ type TokenClaims struct {
 	Sub   uuid.UUID `json:"sub"`
 	Scope string    `json:"scope"`
 	Exp   int       `json:"exp"`
+	// Organization the token was issued for
+	Org   string   `json:"org,omitempty"`
+	Roles []string `json:"roles,omitempty"`
 }
 
 type tokenClaimsCtxKey struct{}
@@ -45,6 +48,10 @@ type jwtTokenCtx struct{}
 
 var jwtToken jwtTokenCtx
 
+type apiKeyIdCtx struct{}
+
+var apiKeyId apiKeyIdCtx
+
 type ctxKeyName string
 
 const (
@@ -56,7 +63,7 @@ func JWTAuthMiddleware(header string) func(http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			tokenString := r.Header.Get(header)
 			if tokenString == "" {
-				utils.WriteErrorResponse(w, http.StatusUnauthorized, fmt.Sprintf("missing header: %s", header))
+				utils.WriteProblemResponse(w, r, http.StatusUnauthorized, fmt.Sprintf("missing header: %s", header))
 				return
 			}
 			// replace "Bearer " prefix
@@ -64,7 +71,7 @@ func JWTAuthMiddleware(header string) func(http.Handler) http.Handler {
 			// we don't need to validate the token, just extract the claims
 			claims, err := extractClaimsFromJWT(tokenString)
 			if err != nil {
-				utils.WriteErrorResponse(w, http.StatusUnauthorized, fmt.Sprintf("invalid jwt: %v", err))
+				utils.WriteProblemResponse(w, r, http.StatusUnauthorized, fmt.Sprintf("invalid jwt: %v", err))
 				return
 			}
 			ctx := r.Context()
@@ -85,6 +92,42 @@ func GetTokenClaims(ctx context.Context) *TokenClaims {
 	return claims
 }
 
+// GetSubject returns the IdP id of the authenticated user
+func GetSubject(ctx context.Context) (uuid.UUID, bool) {
+	claims := GetTokenClaims(ctx)
+	if claims == nil {
+		return uuid.Nil, false
+	}
+	return claims.Sub, true
+}
+
+// GetOrg returns the organization claim of the token, empty when the token carries none
+func GetOrg(ctx context.Context) string {
+	claims := GetTokenClaims(ctx)
+	if claims == nil {
+		return ""
+	}
+	return claims.Org
+}
+
+// GetScopes returns the scopes granted to the request
+func GetScopes(ctx context.Context) []string {
+	scopes, ok := ctx.Value(scopesKey).(string)
+	if !ok {
+		return nil
+	}
+	return strings.Fields(scopes)
+}
+
+// GetRoles returns the roles claim of the token
+func GetRoles(ctx context.Context) []string {
+	claims := GetTokenClaims(ctx)
+	if claims == nil {
+		return nil
+	}
+	return claims.Roles
+}
+
 func GetJWTFromContext(ctx context.Context) string {
 	token, ok := ctx.Value(jwtToken).(string)
 	if !ok {
@@ -93,6 +136,22 @@ func GetJWTFromContext(ctx context.Context) string {
 	return token
 }
 
+// WithAPIKeyPrincipal stores the identity of a request authenticated with an API key,
+// GetTokenClaims and HasAllScopes then answer as for a token with the same subject, organization and scopes
+func WithAPIKeyPrincipal(ctx context.Context, keyId uuid.UUID, userIdpId uuid.UUID, orgName string, scopes []string) context.Context {
+	scope := strings.Join(scopes, " ")
+	ctx = context.WithValue(ctx, assertionTokenClaimsKey, &TokenClaims{Sub: userIdpId, Scope: scope, Org: orgName})
+	ctx = context.WithValue(ctx, apiKeyId, keyId)
+	ctx = context.WithValue(ctx, scopesKey, scope)
+	return ctx
+}
+
+// GetAPIKeyId returns the id of the API key the request was authenticated with
+func GetAPIKeyId(ctx context.Context) (uuid.UUID, bool) {
+	keyId, ok := ctx.Value(apiKeyId).(uuid.UUID)
+	return keyId, ok
+}
+
 func HasAllScopes(ctx context.Context, requiredScopes []string) bool {
 	scopes, ok := ctx.Value(scopesKey).(string)
 	if !ok {
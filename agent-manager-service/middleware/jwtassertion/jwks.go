@@ -0,0 +1,251 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package jwtassertion
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// DefaultMinRefetchInterval keeps tokens with unknown key ids from making every request fetch the key set
+const DefaultMinRefetchInterval = 30 * time.Second
+
+// Largest key set document accepted from the issuer
+const maxJWKSBytes = 1 << 20
+
+// Smallest RSA modulus accepted for signature verification
+const minRSAKeyBits = 2048
+
+var (
+	errUnknownKey        = errors.New("no signing key with the token's key id")
+	errRefetchThrottled  = errors.New("the key set was fetched too recently")
+	errKeySetUnavailable = errors.New("the signing keys of the issuer are unavailable")
+)
+
+// KeySet caches the signing keys an issuer publishes as a JSON Web Key Set
+type KeySet struct {
+	url                string
+	client             *http.Client
+	refreshInterval    time.Duration
+	minRefetchInterval time.Duration
+
+	mu        sync.RWMutex
+	keys      map[string]crypto.PublicKey
+	fetchedAt time.Time
+
+	// Serializes fetches so concurrent requests share one
+	fetchMu     sync.Mutex
+	lastAttempt time.Time
+}
+
+// NewKeySet returns a key set fetched from url on first use and again once refreshInterval has passed.
+// A token signed with an unknown key id triggers an early fetch, at most once per minRefetchInterval,
+// so keys the issuer rotates in are picked up without waiting for the next refresh
+func NewKeySet(url string, client *http.Client, refreshInterval time.Duration, minRefetchInterval time.Duration) *KeySet {
+	return &KeySet{
+		url:                url,
+		client:             client,
+		refreshInterval:    refreshInterval,
+		minRefetchInterval: minRefetchInterval,
+		keys:               map[string]crypto.PublicKey{},
+	}
+}
+
+// Key returns the signing key with the given key id
+func (k *KeySet) Key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	k.mu.RLock()
+	key, found := k.keys[kid]
+	fetchedAt := k.fetchedAt
+	k.mu.RUnlock()
+
+	stale := time.Since(fetchedAt) >= k.refreshInterval
+	if found && !stale {
+		return key, nil
+	}
+	if err := k.refresh(ctx, fetchedAt); err != nil {
+		switch {
+		case errors.Is(err, errRefetchThrottled) && found:
+			return key, nil
+		case errors.Is(err, errRefetchThrottled):
+			return nil, errUnknownKey
+		case found:
+			// Keep verifying with the cached keys while the issuer is unreachable
+			slog.Warn("failed to refresh the JWKS, using cached keys", "url", k.url, "error", err)
+			return key, nil
+		default:
+			slog.Error("failed to fetch the JWKS", "url", k.url, "error", err)
+			return nil, errKeySetUnavailable
+		}
+	}
+
+	k.mu.RLock()
+	key, found = k.keys[kid]
+	k.mu.RUnlock()
+	if !found {
+		return nil, errUnknownKey
+	}
+	return key, nil
+}
+
+// refresh fetches the key set unless it changed since seen or the last attempt was too recent
+func (k *KeySet) refresh(ctx context.Context, seen time.Time) error {
+	k.fetchMu.Lock()
+	defer k.fetchMu.Unlock()
+
+	k.mu.RLock()
+	fetchedAt := k.fetchedAt
+	k.mu.RUnlock()
+	if fetchedAt.After(seen) {
+		return nil
+	}
+	if !k.lastAttempt.IsZero() && time.Since(k.lastAttempt) < k.minRefetchInterval {
+		return errRefetchThrottled
+	}
+	k.lastAttempt = time.Now()
+
+	keys, err := k.fetch(ctx)
+	if err != nil {
+		return err
+	}
+	k.mu.Lock()
+	k.keys = keys
+	k.fetchedAt = time.Now()
+	k.mu.Unlock()
+	return nil
+}
+
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+func (k *KeySet) fetch(ctx context.Context) (map[string]crypto.PublicKey, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, k.url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create JWKS request: %w", err)
+	}
+	resp, err := k.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch JWKS: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch JWKS: unexpected status %d", resp.StatusCode)
+	}
+
+	var document struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxJWKSBytes)).Decode(&document); err != nil {
+		return nil, fmt.Errorf("failed to decode JWKS: %w", err)
+	}
+	keys := make(map[string]crypto.PublicKey, len(document.Keys))
+	for _, jwk := range document.Keys {
+		if jwk.Kid == "" || (jwk.Use != "" && jwk.Use != "sig") {
+			continue
+		}
+		key, err := parseJSONWebKey(jwk)
+		if err != nil {
+			slog.Warn("skipping unusable JWKS key", "url", k.url, "kid", jwk.Kid, "error", err)
+			continue
+		}
+		keys[jwk.Kid] = key
+	}
+	return keys, nil
+}
+
+func parseJSONWebKey(jwk jsonWebKey) (crypto.PublicKey, error) {
+	switch jwk.Kty {
+	case "RSA":
+		n, err := decodeBase64URLInt(jwk.N)
+		if err != nil {
+			return nil, fmt.Errorf("invalid modulus: %w", err)
+		}
+		e, err := decodeBase64URLInt(jwk.E)
+		if err != nil || !e.IsInt64() || e.Int64() < 3 || e.Int64() > 1<<31-1 {
+			return nil, errors.New("invalid exponent")
+		}
+		if n.BitLen() < minRSAKeyBits {
+			return nil, fmt.Errorf("RSA keys must be at least %d bits", minRSAKeyBits)
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		var ecdhCurve ecdh.Curve
+		switch jwk.Crv {
+		case "P-256":
+			curve, ecdhCurve = elliptic.P256(), ecdh.P256()
+		case "P-384":
+			curve, ecdhCurve = elliptic.P384(), ecdh.P384()
+		case "P-521":
+			curve, ecdhCurve = elliptic.P521(), ecdh.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", jwk.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(jwk.X)
+		if err != nil {
+			return nil, fmt.Errorf("invalid x coordinate: %w", err)
+		}
+		y, err := base64.RawURLEncoding.DecodeString(jwk.Y)
+		if err != nil {
+			return nil, fmt.Errorf("invalid y coordinate: %w", err)
+		}
+		size := (curve.Params().BitSize + 7) / 8
+		if len(x) != size || len(y) != size {
+			return nil, errors.New("coordinates do not match the curve size")
+		}
+		// Rejects points that are not on the curve
+		point := append(append([]byte{4}, x...), y...)
+		if _, err := ecdhCurve.NewPublicKey(point); err != nil {
+			return nil, fmt.Errorf("invalid point: %w", err)
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", jwk.Kty)
+	}
+}
+
+func decodeBase64URLInt(value string) (*big.Int, error) {
+	decoded, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+	if len(decoded) == 0 {
+		return nil, errors.New("empty value")
+	}
+	return new(big.Int).SetBytes(decoded), nil
+}
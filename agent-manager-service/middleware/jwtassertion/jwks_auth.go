@@ -0,0 +1,305 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package jwtassertion
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/utils"
+)
+
+// Realm announced in WWW-Authenticate challenges
+const authRealm = "agent-manager"
+
+type signingAlgorithm struct {
+	hash crypto.Hash
+	// One of RSA, RSA-PSS or EC
+	family string
+	// Curve size in bytes of EC algorithms
+	curveBytes int
+}
+
+// Asymmetric algorithms only, so a public key can never be used as an HMAC secret
+var signingAlgorithms = map[string]signingAlgorithm{
+	"RS256": {hash: crypto.SHA256, family: "RSA"},
+	"RS384": {hash: crypto.SHA384, family: "RSA"},
+	"RS512": {hash: crypto.SHA512, family: "RSA"},
+	"PS256": {hash: crypto.SHA256, family: "RSA-PSS"},
+	"PS384": {hash: crypto.SHA384, family: "RSA-PSS"},
+	"PS512": {hash: crypto.SHA512, family: "RSA-PSS"},
+	"ES256": {hash: crypto.SHA256, family: "EC", curveBytes: 32},
+	"ES384": {hash: crypto.SHA384, family: "EC", curveBytes: 48},
+	"ES512": {hash: crypto.SHA512, family: "EC", curveBytes: 66},
+}
+
+// TokenValidator verifies bearer tokens signed with the keys of an issuer
+type TokenValidator struct {
+	keys      *KeySet
+	issuer    string
+	audience  string
+	clockSkew time.Duration
+}
+
+// NewTokenValidator returns a validator accepting tokens of issuer for audience, exp and nbf are checked with clockSkew tolerance
+func NewTokenValidator(keys *KeySet, issuer string, audience string, clockSkew time.Duration) *TokenValidator {
+	return &TokenValidator{
+		keys:      keys,
+		issuer:    issuer,
+		audience:  audience,
+		clockSkew: clockSkew,
+	}
+}
+
+type tokenHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+type registeredClaims struct {
+	Iss string       `json:"iss"`
+	Aud audience     `json:"aud"`
+	Exp *json.Number `json:"exp"`
+	Nbf *json.Number `json:"nbf"`
+}
+
+// audience is the aud claim, which is either a single string or an array of strings
+type audience []string
+
+func (a *audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = audience{single}
+		return nil
+	}
+	var multiple []string
+	if err := json.Unmarshal(data, &multiple); err != nil {
+		return errors.New("aud must be a string or an array of strings")
+	}
+	*a = multiple
+	return nil
+}
+
+// Validate verifies the signature and registered claims of a token and returns its claims
+func (v *TokenValidator) Validate(ctx context.Context, token string) (*TokenClaims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("malformed token")
+	}
+	headerJSON, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, errors.New("malformed token header")
+	}
+	var header tokenHeader
+	if err := json.Unmarshal(headerJSON, &header); err != nil {
+		return nil, errors.New("malformed token header")
+	}
+	alg, ok := signingAlgorithms[header.Alg]
+	if !ok {
+		return nil, fmt.Errorf("unsupported signing algorithm %s", header.Alg)
+	}
+	if header.Kid == "" {
+		return nil, errors.New("token header has no key id")
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, errors.New("malformed token signature")
+	}
+	key, err := v.keys.Key(ctx, header.Kid)
+	if err != nil {
+		return nil, err
+	}
+	if err := verifySignature(alg, key, parts[0]+"."+parts[1], signature); err != nil {
+		return nil, err
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, errors.New("malformed token payload")
+	}
+	var registered registeredClaims
+	if err := json.Unmarshal(payload, &registered); err != nil {
+		return nil, fmt.Errorf("malformed token claims: %w", err)
+	}
+	if err := v.validateRegisteredClaims(registered, time.Now()); err != nil {
+		return nil, err
+	}
+	var claims TokenClaims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, fmt.Errorf("malformed token claims: %w", err)
+	}
+	return &claims, nil
+}
+
+func (v *TokenValidator) validateRegisteredClaims(claims registeredClaims, now time.Time) error {
+	if claims.Iss != v.issuer {
+		return errors.New("token was issued by an untrusted issuer")
+	}
+	audienceMatches := false
+	for _, aud := range claims.Aud {
+		if aud == v.audience {
+			audienceMatches = true
+			break
+		}
+	}
+	if !audienceMatches {
+		return errors.New("token is not intended for this service")
+	}
+	if claims.Exp == nil {
+		return errors.New("token has no expiry")
+	}
+	exp, err := parseNumericDate(*claims.Exp)
+	if err != nil {
+		return errors.New("token expiry is not a numeric date")
+	}
+	if !now.Before(exp.Add(v.clockSkew)) {
+		return errors.New("token has expired")
+	}
+	if claims.Nbf != nil {
+		nbf, err := parseNumericDate(*claims.Nbf)
+		if err != nil {
+			return errors.New("token not-before is not a numeric date")
+		}
+		if now.Add(v.clockSkew).Before(nbf) {
+			return errors.New("token is not valid yet")
+		}
+	}
+	return nil
+}
+
+func parseNumericDate(value json.Number) (time.Time, error) {
+	seconds, err := value.Float64()
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(0, 0).Add(time.Duration(seconds * float64(time.Second))), nil
+}
+
+func verifySignature(alg signingAlgorithm, key crypto.PublicKey, signingInput string, signature []byte) error {
+	hasher := alg.hash.New()
+	hasher.Write([]byte(signingInput))
+	digest := hasher.Sum(nil)
+
+	errInvalid := errors.New("token signature is invalid")
+	switch alg.family {
+	case "RSA", "RSA-PSS":
+		rsaKey, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return errors.New("token algorithm does not match the signing key")
+		}
+		if alg.family == "RSA" {
+			err := rsa.VerifyPKCS1v15(rsaKey, alg.hash, digest, signature)
+			if err != nil {
+				return errInvalid
+			}
+			return nil
+		}
+		if err := rsa.VerifyPSS(rsaKey, alg.hash, digest, signature, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash}); err != nil {
+			return errInvalid
+		}
+		return nil
+	case "EC":
+		ecKey, ok := key.(*ecdsa.PublicKey)
+		if !ok || (ecKey.Curve.Params().BitSize+7)/8 != alg.curveBytes {
+			return errors.New("token algorithm does not match the signing key")
+		}
+		if len(signature) != 2*alg.curveBytes {
+			return errInvalid
+		}
+		r := new(big.Int).SetBytes(signature[:alg.curveBytes])
+		s := new(big.Int).SetBytes(signature[alg.curveBytes:])
+		if !ecdsa.Verify(ecKey, digest, r, s) {
+			return errInvalid
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported algorithm family %s", alg.family)
+	}
+}
+
+// JWKSAuthMiddleware authenticates requests with a bearer token verified by validator and stores its claims in the
+// request context. Requests to unauthenticatedPaths and CORS preflights are passed on without a token
+func JWKSAuthMiddleware(header string, validator *TokenValidator, unauthenticatedPaths []string) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if isCORSPreflight(r) || matchesAnyPath(r.URL.Path, unauthenticatedPaths) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			value := r.Header.Get(header)
+			if value == "" {
+				writeUnauthorized(w, r, "", "Missing bearer token")
+				return
+			}
+			tokenString, ok := strings.CutPrefix(value, "Bearer ")
+			if !ok {
+				writeUnauthorized(w, r, "invalid_request", "The bearer token must be sent as: Bearer <token>")
+				return
+			}
+			claims, err := validator.Validate(r.Context(), tokenString)
+			if err != nil {
+				writeUnauthorized(w, r, "invalid_token", err.Error())
+				return
+			}
+
+			ctx := r.Context()
+			ctx = context.WithValue(ctx, assertionTokenClaimsKey, claims)
+			ctx = context.WithValue(ctx, jwtToken, tokenString)
+			ctx = context.WithValue(ctx, scopesKey, claims.Scope)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+func isCORSPreflight(r *http.Request) bool {
+	return r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+}
+
+// matchesAnyPath reports whether path equals one of the patterns, a pattern ending in * matches by prefix
+func matchesAnyPath(path string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+			if strings.HasPrefix(path, prefix) {
+				return true
+			}
+		} else if path == pattern {
+			return true
+		}
+	}
+	return false
+}
+
+// writeUnauthorized writes a 401 with a RFC 6750 challenge, errorCode is omitted when no credentials were sent
+func writeUnauthorized(w http.ResponseWriter, r *http.Request, errorCode string, description string) {
+	challenge := fmt.Sprintf("Bearer realm=%q", authRealm)
+	if errorCode != "" {
+		challenge += fmt.Sprintf(", error=%q, error_description=%q", errorCode, description)
+	}
+	w.Header().Set("WWW-Authenticate", challenge)
+	utils.WriteProblemResponse(w, r, http.StatusUnauthorized, description)
+}
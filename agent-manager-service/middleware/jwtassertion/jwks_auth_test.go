@@ -0,0 +1,310 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package jwtassertion
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testIssuer   = "https://idp.example.com"
+	testAudience = "agent-manager"
+)
+
+type testSigningKey struct {
+	kid string
+	key crypto.Signer
+}
+
+func newRSASigningKey(t *testing.T, kid string) testSigningKey {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	return testSigningKey{kid: kid, key: key}
+}
+
+func (k testSigningKey) jwk() map[string]string {
+	switch pub := k.key.Public().(type) {
+	case *rsa.PublicKey:
+		return map[string]string{
+			"kty": "RSA",
+			"kid": k.kid,
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+		}
+	case *ecdsa.PublicKey:
+		return map[string]string{
+			"kty": "EC",
+			"kid": k.kid,
+			"crv": "P-256",
+			"x":   base64.RawURLEncoding.EncodeToString(pub.X.FillBytes(make([]byte, 32))),
+			"y":   base64.RawURLEncoding.EncodeToString(pub.Y.FillBytes(make([]byte, 32))),
+		}
+	}
+	return nil
+}
+
+// sign returns a token over claims, signed by k but announcing kid
+func (k testSigningKey) sign(t *testing.T, kid string, claims map[string]interface{}) string {
+	t.Helper()
+	alg := "RS256"
+	if _, ok := k.key.(*ecdsa.PrivateKey); ok {
+		alg = "ES256"
+	}
+	header, err := json.Marshal(map[string]string{"alg": alg, "kid": kid, "typ": "JWT"})
+	require.NoError(t, err)
+	payload, err := json.Marshal(claims)
+	require.NoError(t, err)
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := crypto.SHA256.New()
+	digest.Write([]byte(signingInput))
+
+	var signature []byte
+	switch key := k.key.(type) {
+	case *rsa.PrivateKey:
+		signature, err = rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest.Sum(nil))
+		require.NoError(t, err)
+	case *ecdsa.PrivateKey:
+		r, s, err := ecdsa.Sign(rand.Reader, key, digest.Sum(nil))
+		require.NoError(t, err)
+		signature = append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	}
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+// fakeIssuer serves a JWKS document whose keys can be rotated and counts how often it is fetched
+type fakeIssuer struct {
+	mu      sync.Mutex
+	keys    []testSigningKey
+	fetches atomic.Int32
+}
+
+func (f *fakeIssuer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.fetches.Add(1)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	jwks := make([]map[string]string, 0, len(f.keys))
+	for _, key := range f.keys {
+		jwks = append(jwks, key.jwk())
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"keys": jwks})
+}
+
+func (f *fakeIssuer) rotate(keys ...testSigningKey) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.keys = keys
+}
+
+func validClaims(sub uuid.UUID) map[string]interface{} {
+	return map[string]interface{}{
+		"iss":   testIssuer,
+		"aud":   testAudience,
+		"sub":   sub.String(),
+		"exp":   time.Now().Add(time.Hour).Unix(),
+		"scope": "agents:read agents:write",
+		"org":   "acme",
+		"roles": []string{"admin"},
+	}
+}
+
+func newTestAuthHandler(t *testing.T, issuer *fakeIssuer, minRefetchInterval time.Duration, unauthenticatedPaths ...string) http.Handler {
+	t.Helper()
+	server := httptest.NewServer(issuer)
+	t.Cleanup(server.Close)
+	keySet := NewKeySet(server.URL, server.Client(), time.Hour, minRefetchInterval)
+	validator := NewTokenValidator(keySet, testIssuer, testAudience, time.Minute)
+	return JWKSAuthMiddleware("Authorization", validator, unauthenticatedPaths)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sub, _ := GetSubject(r.Context())
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"sub":    sub,
+			"org":    GetOrg(r.Context()),
+			"scopes": GetScopes(r.Context()),
+			"roles":  GetRoles(r.Context()),
+		})
+	}))
+}
+
+func sendWithToken(handler http.Handler, token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/orgs/acme/agents", nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	return rr
+}
+
+func TestJWKSAuthMiddlewareInjectsClaims(t *testing.T) {
+	key := newRSASigningKey(t, "key-1")
+	handler := newTestAuthHandler(t, &fakeIssuer{keys: []testSigningKey{key}}, 0)
+	sub := uuid.New()
+
+	rr := sendWithToken(handler, key.sign(t, key.kid, validClaims(sub)))
+	require.Equal(t, http.StatusOK, rr.Code)
+	var got struct {
+		Sub    uuid.UUID `json:"sub"`
+		Org    string    `json:"org"`
+		Scopes []string  `json:"scopes"`
+		Roles  []string  `json:"roles"`
+	}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &got))
+	require.Equal(t, sub, got.Sub)
+	require.Equal(t, "acme", got.Org)
+	require.Equal(t, []string{"agents:read", "agents:write"}, got.Scopes)
+	require.Equal(t, []string{"admin"}, got.Roles)
+}
+
+func TestJWKSAuthMiddlewareRejectsInvalidTokens(t *testing.T) {
+	key := newRSASigningKey(t, "key-1")
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	ec := testSigningKey{kid: "key-ec", key: ecKey}
+	forger := newRSASigningKey(t, "key-1")
+	handler := newTestAuthHandler(t, &fakeIssuer{keys: []testSigningKey{key, ec}}, 0)
+
+	with := func(name string, value interface{}) map[string]interface{} {
+		claims := validClaims(uuid.New())
+		if value == nil {
+			delete(claims, name)
+		} else {
+			claims[name] = value
+		}
+		return claims
+	}
+	tests := []struct {
+		name     string
+		token    string
+		wantCode int
+	}{
+		{"a valid ES256 token", ec.sign(t, ec.kid, validClaims(uuid.New())), http.StatusOK},
+		{"an audience array containing the service", key.sign(t, key.kid, with("aud", []string{"other", testAudience})), http.StatusOK},
+		{"a token expired within the clock skew", key.sign(t, key.kid, with("exp", time.Now().Add(-30*time.Second).Unix())), http.StatusOK},
+		{"a token expired beyond the clock skew", key.sign(t, key.kid, with("exp", time.Now().Add(-2*time.Minute).Unix())), http.StatusUnauthorized},
+		{"a token valid within the clock skew", key.sign(t, key.kid, with("nbf", time.Now().Add(30*time.Second).Unix())), http.StatusOK},
+		{"a token not valid before the clock skew", key.sign(t, key.kid, with("nbf", time.Now().Add(2*time.Minute).Unix())), http.StatusUnauthorized},
+		{"a token without expiry", key.sign(t, key.kid, with("exp", nil)), http.StatusUnauthorized},
+		{"another audience", key.sign(t, key.kid, with("aud", "billing")), http.StatusUnauthorized},
+		{"another issuer", key.sign(t, key.kid, with("iss", "https://evil.example.com")), http.StatusUnauthorized},
+		{"a forged signature", forger.sign(t, key.kid, validClaims(uuid.New())), http.StatusUnauthorized},
+		{"a key of another family", key.sign(t, ec.kid, validClaims(uuid.New())), http.StatusUnauthorized},
+		{"an unsigned token", unsignedToken(t, validClaims(uuid.New())), http.StatusUnauthorized},
+		{"a malformed token", "not-a-jwt", http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := sendWithToken(handler, tt.token)
+			require.Equal(t, tt.wantCode, rr.Code, rr.Body.String())
+			if tt.wantCode == http.StatusUnauthorized {
+				require.True(t, strings.HasPrefix(rr.Header().Get("WWW-Authenticate"), `Bearer realm="agent-manager", error="invalid_token"`))
+			}
+		})
+	}
+}
+
+func unsignedToken(t *testing.T, claims map[string]interface{}) string {
+	t.Helper()
+	payload, err := json.Marshal(claims)
+	require.NoError(t, err)
+	return base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none","kid":"key-1"}`)) + "." +
+		base64.RawURLEncoding.EncodeToString(payload) + "."
+}
+
+func TestJWKSAuthMiddlewareChallengesMissingTokens(t *testing.T) {
+	key := newRSASigningKey(t, "key-1")
+	handler := newTestAuthHandler(t, &fakeIssuer{keys: []testSigningKey{key}}, 0, "/public/*", "/status")
+
+	rr := sendWithToken(handler, "")
+	require.Equal(t, http.StatusUnauthorized, rr.Code)
+	require.Equal(t, `Bearer realm="agent-manager"`, rr.Header().Get("WWW-Authenticate"))
+
+	for _, path := range []string{"/public/docs", "/status"} {
+		rr = httptest.NewRecorder()
+		handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, path, nil))
+		require.Equal(t, http.StatusOK, rr.Code, path)
+	}
+
+	req := httptest.NewRequest(http.MethodOptions, "/orgs/acme/agents", nil)
+	req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+	rr = httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+}
+
+func TestJWKSAuthMiddlewareFollowsKeyRotation(t *testing.T) {
+	oldKey := newRSASigningKey(t, "key-1")
+	newKey := newRSASigningKey(t, "key-2")
+	issuer := &fakeIssuer{keys: []testSigningKey{oldKey}}
+	handler := newTestAuthHandler(t, issuer, 0)
+
+	require.Equal(t, http.StatusOK, sendWithToken(handler, oldKey.sign(t, oldKey.kid, validClaims(uuid.New()))).Code)
+	require.Equal(t, int32(1), issuer.fetches.Load())
+
+	// The issuer rotates while requests are in flight, the first token with the new kid refetches the key set once
+	issuer.rotate(newKey)
+	var wg sync.WaitGroup
+	codes := make([]int, 20)
+	for i := range codes {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			codes[i] = sendWithToken(handler, newKey.sign(t, newKey.kid, validClaims(uuid.New()))).Code
+		}(i)
+	}
+	wg.Wait()
+	for _, code := range codes {
+		require.Equal(t, http.StatusOK, code)
+	}
+	require.Equal(t, int32(2), issuer.fetches.Load())
+
+	// Keys the issuer no longer publishes are dropped with the refetch
+	require.Equal(t, http.StatusUnauthorized, sendWithToken(handler, oldKey.sign(t, oldKey.kid, validClaims(uuid.New()))).Code)
+}
+
+func TestJWKSAuthMiddlewareThrottlesUnknownKeyRefetches(t *testing.T) {
+	key := newRSASigningKey(t, "key-1")
+	issuer := &fakeIssuer{keys: []testSigningKey{key}}
+	handler := newTestAuthHandler(t, issuer, time.Hour)
+
+	require.Equal(t, http.StatusOK, sendWithToken(handler, key.sign(t, key.kid, validClaims(uuid.New()))).Code)
+	for i := 0; i < 5; i++ {
+		token := key.sign(t, fmt.Sprintf("forged-%d", i), validClaims(uuid.New()))
+		require.Equal(t, http.StatusUnauthorized, sendWithToken(handler, token).Code)
+	}
+	require.Equal(t, int32(1), issuer.fetches.Load())
+	require.Equal(t, http.StatusOK, sendWithToken(handler, key.sign(t, key.kid, validClaims(uuid.New()))).Code)
+}
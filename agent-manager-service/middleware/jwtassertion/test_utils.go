@@ -28,11 +28,25 @@ import (
 // NewMockMiddleware creates a mock JWT middleware for testing
 func NewMockMiddleware(t *testing.T, orgId uuid.UUID, userIdpId uuid.UUID) Middleware {
 	t.Helper()
+	return NewMockMiddlewareWithRoles(t, orgId, userIdpId)
+}
+
+// NewMockMiddlewareWithRoles creates a mock JWT middleware for a token carrying the given roles claim
+func NewMockMiddlewareWithRoles(t *testing.T, orgId uuid.UUID, userIdpId uuid.UUID, roles ...string) Middleware {
+	t.Helper()
+	return NewMockMiddlewareWithClaims(t, TokenClaims{Sub: userIdpId, Roles: roles})
+}
 
-	tokenClaims := &TokenClaims{
-		Sub:   userIdpId,
-		Scope: "scopes",
-		Exp:   int(time.Now().Add(time.Hour).Unix()),
+// NewMockMiddlewareWithClaims creates a mock JWT middleware for a token carrying the given claims
+func NewMockMiddlewareWithClaims(t *testing.T, claims TokenClaims) Middleware {
+	t.Helper()
+
+	tokenClaims := &claims
+	if tokenClaims.Scope == "" {
+		tokenClaims.Scope = "scopes"
+	}
+	if tokenClaims.Exp == 0 {
+		tokenClaims.Exp = int(time.Now().Add(time.Hour).Unix())
 	}
 
 	return func(next http.Handler) http.Handler {
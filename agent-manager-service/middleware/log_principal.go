@@ -0,0 +1,41 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/middleware/jwtassertion"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/middleware/logger"
+)
+
+// LogPrincipal records the authenticated caller in the access log of the request, it runs after authentication
+func LogPrincipal() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+			if subject, ok := jwtassertion.GetSubject(ctx); ok {
+				apiKeyId := ""
+				if keyId, ok := jwtassertion.GetAPIKeyId(ctx); ok {
+					apiKeyId = keyId.String()
+				}
+				logger.SetPrincipal(ctx, subject.String(), apiKeyId)
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
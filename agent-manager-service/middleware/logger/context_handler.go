@@ -0,0 +1,66 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package logger
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/middleware/correlation"
+)
+
+// CorrelationIDKey is the attribute the correlation id of a request is logged under
+const CorrelationIDKey = "correlation_id"
+
+// TraceIDKey is the attribute the trace id propagated with a request is logged under
+const TraceIDKey = "trace_id"
+
+type contextHandler struct {
+	slog.Handler
+	// Set once the id is among the attributes of the logger, as on the request logger
+	hasCorrelationID bool
+	hasTraceID       bool
+}
+
+// NewContextHandler adds the correlation and trace ids bound to the context of a record to records logged through h,
+// loggers not derived from the request logger then only need to log with the request context
+func NewContextHandler(h slog.Handler) slog.Handler {
+	return contextHandler{Handler: h}
+}
+
+func (h contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if id, ok := correlation.FromContext(ctx); ok && !h.hasCorrelationID {
+		record.AddAttrs(slog.String(CorrelationIDKey, id))
+	}
+	if id, ok := correlation.TraceIDFromContext(ctx); ok && !h.hasTraceID {
+		record.AddAttrs(slog.String(TraceIDKey, id))
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	next := contextHandler{Handler: h.Handler.WithAttrs(attrs), hasCorrelationID: h.hasCorrelationID, hasTraceID: h.hasTraceID}
+	for _, attr := range attrs {
+		next.hasCorrelationID = next.hasCorrelationID || attr.Key == CorrelationIDKey
+		next.hasTraceID = next.hasTraceID || attr.Key == TraceIDKey
+	}
+	return next
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{Handler: h.Handler.WithGroup(name), hasCorrelationID: h.hasCorrelationID, hasTraceID: h.hasTraceID}
+}
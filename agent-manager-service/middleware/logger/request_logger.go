@@ -20,12 +20,35 @@ import (
 	"context"
 	"log/slog"
 	"net/http"
+	"net/url"
+	"strings"
+	"time"
 
-	"github.com/wso2/ai-agent-management-platform/agent-manager-service/utils"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/middleware/correlation"
 )
 
 type loggerKey struct{}
 
+type requestEntryKey struct{}
+
+// Query parameters logged as redacted, parameters naming a token, secret or password are redacted as well
+var sensitiveQueryParams = map[string]bool{
+	"api_key":   true,
+	"apikey":    true,
+	"key":       true,
+	"code":      true,
+	"sig":       true,
+	"signature": true,
+}
+
+const redacted = "REDACTED"
+
+// requestEntry collects what inner middleware learns about a request for its access log
+type requestEntry struct {
+	principal string
+	apiKeyId  string
+}
+
 // WithLogger adds a logger to the context
 func WithLogger(ctx context.Context, logger *slog.Logger) context.Context {
 	return context.WithValue(ctx, loggerKey{}, logger)
@@ -40,24 +63,116 @@ func GetLogger(ctx context.Context) *slog.Logger {
 	return slog.Default()
 }
 
+// SetPrincipal records the caller of the request in its access log, apiKeyId is empty for token callers
+func SetPrincipal(ctx context.Context, principal string, apiKeyId string) {
+	if entry, ok := ctx.Value(requestEntryKey{}).(*requestEntry); ok {
+		entry.principal = principal
+		entry.apiKeyId = apiKeyId
+	}
+}
+
+// RequestLogger binds a logger carrying the correlation id to the request and logs the request once it is served.
+// Bodies are never logged and sensitive query parameters are redacted
 func RequestLogger() func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			correlationID := "unknown"
-			if id := r.Context().Value(utils.CorrelationIdCtxKey()); id != nil {
-				if idStr, ok := id.(string); ok {
-					correlationID = idStr
-				}
+			start := time.Now()
+			correlationID, ok := correlation.FromContext(r.Context())
+			if !ok {
+				correlationID = "unknown"
 			}
-			// Use the globally configured logger
-			reqLogger := slog.Default().With(
+			loggerAttrs := []any{
 				slog.String("method", r.Method),
 				slog.String("path", r.URL.Path),
-				slog.String("correlation_id", correlationID),
-			)
+				slog.String(CorrelationIDKey, correlationID),
+			}
+			if traceID, ok := correlation.TraceIDFromContext(r.Context()); ok {
+				loggerAttrs = append(loggerAttrs, slog.String(TraceIDKey, traceID))
+			}
+			// Use the globally configured logger
+			reqLogger := slog.Default().With(loggerAttrs...)
+			entry := &requestEntry{}
 			ctx := WithLogger(r.Context(), reqLogger)
+			ctx = context.WithValue(ctx, requestEntryKey{}, entry)
+
+			recorder := &responseRecorder{ResponseWriter: w}
+			next.ServeHTTP(recorder, r.WithContext(ctx))
 
-			next.ServeHTTP(w, r.WithContext(ctx))
+			attrs := []slog.Attr{
+				slog.Int("status", recorder.statusCode()),
+				slog.Int64("duration_ms", time.Since(start).Milliseconds()),
+				slog.Int64("response_bytes", recorder.bytes),
+			}
+			if r.URL.RawQuery != "" {
+				attrs = append(attrs, slog.String("query", RedactQuery(r.URL.Query())))
+			}
+			if entry.principal != "" {
+				attrs = append(attrs, slog.String("principal", entry.principal))
+			}
+			if entry.apiKeyId != "" {
+				attrs = append(attrs, slog.String("api_key_id", entry.apiKeyId))
+			}
+			reqLogger.LogAttrs(ctx, slog.LevelInfo, "request completed", attrs...)
 		})
 	}
 }
+
+// RedactQuery encodes query with the values of sensitive parameters replaced
+func RedactQuery(query url.Values) string {
+	redactedQuery := make(url.Values, len(query))
+	for name, values := range query {
+		if !isSensitiveQueryParam(name) {
+			redactedQuery[name] = values
+			continue
+		}
+		redactedValues := make([]string, len(values))
+		for i := range values {
+			redactedValues[i] = redacted
+		}
+		redactedQuery[name] = redactedValues
+	}
+	return redactedQuery.Encode()
+}
+
+func isSensitiveQueryParam(name string) bool {
+	name = strings.ToLower(name)
+	return sensitiveQueryParams[name] ||
+		strings.Contains(name, "token") ||
+		strings.Contains(name, "secret") ||
+		strings.Contains(name, "password")
+}
+
+// responseRecorder captures the status and size of a response
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int64
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	n, err := r.ResponseWriter.Write(b)
+	r.bytes += int64(n)
+	return n, err
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer
+func (r *responseRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+func (r *responseRecorder) statusCode() int {
+	if r.status == 0 {
+		return http.StatusOK
+	}
+	return r.status
+}
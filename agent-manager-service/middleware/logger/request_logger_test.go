@@ -0,0 +1,124 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/middleware/correlation"
+)
+
+// captureLogs routes the default logger to a buffer for the duration of the test
+func captureLogs(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	previous := slog.Default()
+	slog.SetDefault(slog.New(NewContextHandler(slog.NewJSONHandler(&buf, nil))))
+	t.Cleanup(func() { slog.SetDefault(previous) })
+	return &buf
+}
+
+func decodeLogLines(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+	var lines []map[string]any
+	decoder := json.NewDecoder(buf)
+	for decoder.More() {
+		var line map[string]any
+		require.NoError(t, decoder.Decode(&line))
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func TestRequestLoggerLogsCompletedRequests(t *testing.T) {
+	buf := captureLogs(t)
+	service := slog.Default()
+	handler := RequestLogger()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		SetPrincipal(r.Context(), "user-1", "key-1")
+		service.InfoContext(r.Context(), "serving")
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"id":"1"}`))
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/orgs/acme/agents?limit=5&access_token=abc&apiKey=xyz", bytes.NewBufferString(`{"secret":"s3cr3t"}`))
+	req = req.WithContext(correlation.WithID(req.Context(), "req-42"))
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	lines := decodeLogLines(t, buf)
+	require.Len(t, lines, 2)
+	require.Equal(t, "req-42", lines[0][CorrelationIDKey], "loggers logging with the request context carry the correlation id")
+
+	completed := lines[1]
+	require.Equal(t, "request completed", completed["msg"])
+	require.Equal(t, "req-42", completed[CorrelationIDKey])
+	require.Equal(t, http.MethodPost, completed["method"])
+	require.Equal(t, "/orgs/acme/agents", completed["path"])
+	require.EqualValues(t, http.StatusCreated, completed["status"])
+	require.EqualValues(t, len(`{"id":"1"}`), completed["response_bytes"])
+	require.Contains(t, completed, "duration_ms")
+	require.Equal(t, "user-1", completed["principal"])
+	require.Equal(t, "key-1", completed["api_key_id"])
+	require.NotContains(t, buf.String(), "s3cr3t")
+
+	query, err := url.ParseQuery(completed["query"].(string))
+	require.NoError(t, err)
+	require.Equal(t, "5", query.Get("limit"))
+	require.Equal(t, redacted, query.Get("access_token"))
+	require.Equal(t, redacted, query.Get("apiKey"))
+}
+
+func TestContextHandlerDoesNotRepeatTheCorrelationID(t *testing.T) {
+	buf := captureLogs(t)
+	handler := RequestLogger()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		GetLogger(r.Context()).InfoContext(r.Context(), "serving")
+	}))
+	req := httptest.NewRequest(http.MethodGet, "/orgs", nil)
+	req = req.WithContext(correlation.WithID(req.Context(), "req-42"))
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	firstLine := bytes.SplitN(buf.Bytes(), []byte("\n"), 2)[0]
+	require.Equal(t, 1, bytes.Count(firstLine, []byte(`"correlation_id"`)))
+}
+
+func TestContextHandlerAddsTheTraceID(t *testing.T) {
+	buf := captureLogs(t)
+	service := slog.Default()
+	handler := RequestLogger()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		service.InfoContext(r.Context(), "serving")
+		GetLogger(r.Context()).InfoContext(r.Context(), "serving with the request logger")
+	}))
+	req := httptest.NewRequest(http.MethodGet, "/orgs", nil)
+	ctx := correlation.WithID(req.Context(), "req-42")
+	req = req.WithContext(correlation.WithTraceID(ctx, "4bf92f3577b34da6a3ce929d0e0e4736"))
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	secondLine := bytes.SplitN(buf.Bytes(), []byte("\n"), 3)[1]
+	require.Equal(t, 1, bytes.Count(secondLine, []byte(`"trace_id"`)))
+	lines := decodeLogLines(t, buf)
+	require.Len(t, lines, 3)
+	for _, line := range lines {
+		require.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", line[TraceIDKey])
+	}
+}
@@ -0,0 +1,74 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/db/orgscope"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/middleware/jwtassertion"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/middleware/logger"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/models"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/utils"
+)
+
+// OrgResolver resolves the organization named in a request for the caller
+type OrgResolver interface {
+	ResolveOrganization(ctx context.Context, userIdpId uuid.UUID, tokenOrgName string, orgName string) (*models.Organization, error)
+}
+
+// OrgScope binds requests to /orgs/{orgName}/... to the organization, so the database only serves its rows.
+// Organizations the caller may not access are reported as not found
+func OrgScope(resolver OrgResolver) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+			orgName, ok := orgNameFromPath(r.URL.Path)
+			tokenClaims := jwtassertion.GetTokenClaims(ctx)
+			// Paths served without a token are left to their handlers
+			if !ok || tokenClaims == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+			org, err := resolver.ResolveOrganization(ctx, tokenClaims.Sub, tokenClaims.Org, orgName)
+			if err != nil {
+				if errors.Is(err, utils.ErrOrganizationNotFound) {
+					utils.WriteProblemResponse(w, r, http.StatusNotFound, "Organization not found")
+					return
+				}
+				logger.GetLogger(ctx).Error("failed to resolve organization", "orgName", orgName, "error", err)
+				utils.WriteProblemResponse(w, r, http.StatusInternalServerError, "Failed to resolve the organization")
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(orgscope.With(ctx, org.ID)))
+		})
+	}
+}
+
+// orgNameFromPath returns the organization of paths shaped /orgs/{orgName}[/...]
+func orgNameFromPath(path string) (string, bool) {
+	segments := strings.SplitN(strings.TrimPrefix(path, "/"), "/", 3)
+	if len(segments) < 2 || segments[0] != "orgs" || segments[1] == "" {
+		return "", false
+	}
+	return segments[1], true
+}
@@ -0,0 +1,86 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/db/orgscope"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/middleware/jwtassertion"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/models"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/utils"
+)
+
+type orgResolverFunc func(ctx context.Context, userIdpId uuid.UUID, tokenOrgName string, orgName string) (*models.Organization, error)
+
+func (f orgResolverFunc) ResolveOrganization(ctx context.Context, userIdpId uuid.UUID, tokenOrgName string, orgName string) (*models.Organization, error) {
+	return f(ctx, userIdpId, tokenOrgName, orgName)
+}
+
+func TestOrgScope(t *testing.T) {
+	orgId := uuid.New()
+	resolver := orgResolverFunc(func(_ context.Context, _ uuid.UUID, _ string, orgName string) (*models.Organization, error) {
+		switch orgName {
+		case "acme":
+			return &models.Organization{ID: orgId}, nil
+		case "broken":
+			return nil, errors.New("connection refused")
+		}
+		return nil, utils.ErrOrganizationNotFound
+	})
+	tests := []struct {
+		name       string
+		path       string
+		withToken  bool
+		wantStatus int
+		wantBound  bool
+	}{
+		{"organization path", "/orgs/acme/agents", true, http.StatusOK, true},
+		{"organization itself", "/orgs/acme", true, http.StatusOK, true},
+		{"unknown organization", "/orgs/other/agents", true, http.StatusNotFound, false},
+		{"resolver failure", "/orgs/broken/agents", true, http.StatusInternalServerError, false},
+		{"path outside organizations", "/health", true, http.StatusOK, false},
+		{"request without a token", "/orgs/acme/agents", false, http.StatusOK, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var bound bool
+			var boundOrgId uuid.UUID
+			handler := OrgScope(resolver)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				boundOrgId, bound = orgscope.FromContext(r.Context())
+			}))
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			if tt.withToken {
+				req = req.WithContext(jwtassertion.WithAPIKeyPrincipal(req.Context(), uuid.New(), uuid.New(), "acme", nil))
+			}
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+			if rr.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rr.Code, tt.wantStatus)
+			}
+			if bound != tt.wantBound || (bound && boundOrgId != orgId) {
+				t.Errorf("bound = %v to %s, want %v to %s", bound, boundOrgId, tt.wantBound, orgId)
+			}
+		})
+	}
+}
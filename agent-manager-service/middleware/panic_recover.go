@@ -24,16 +24,16 @@ import (
 	"github.com/wso2/ai-agent-management-platform/agent-manager-service/utils"
 )
 
+// RecovererOnPanic answers requests whose handler panicked with a 500 problem carrying the correlation id,
+// so users can report it, and logs the panic with its stack
 func RecovererOnPanic() func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			defer func() {
 				if rec := recover(); rec != nil {
-					correlationId := "unknown"
-					if id := r.Context().Value(utils.CorrelationIdCtxKey()); id != nil {
-						if idStr, ok := id.(string); ok {
-							correlationId = idStr
-						}
+					// Handlers abort responses on purpose with this panic, the server handles it
+					if rec == http.ErrAbortHandler {
+						panic(rec)
 					}
 
 					operation := "unknown"
@@ -43,14 +43,14 @@ func RecovererOnPanic() func(http.Handler) http.Handler {
 						}
 					}
 
-					slog.Error("recoverOnPanic",
-						"correlationID", correlationId,
+					// The correlation id is added from the context
+					slog.ErrorContext(r.Context(), "recoverOnPanic",
 						"operation", operation,
 						"log_type", "err_response",
 						"panic", rec,
 						"stack", string(debug.Stack()))
 
-					utils.WriteErrorResponse(w, http.StatusInternalServerError, "internal server error")
+					utils.WriteProblemResponse(w, r, http.StatusInternalServerError, "Internal server error, quote the correlation id when reporting it")
 				}
 			}()
 			next.ServeHTTP(w, r)
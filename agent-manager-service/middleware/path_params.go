@@ -32,7 +32,7 @@ func WithPathParamValidation(handler http.HandlerFunc, requiredParams ...string)
 		for _, paramName := range requiredParams {
 			value := r.PathValue(paramName)
 			if strings.TrimSpace(value) == "" {
-				utils.WriteErrorResponse(w, http.StatusBadRequest, "Missing required path parameter: "+paramName)
+				utils.WriteProblemResponse(w, r, http.StatusBadRequest, "Missing required path parameter: "+paramName)
 				return
 			}
 		}
@@ -0,0 +1,240 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/time/rate"
+
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/middleware/jwtassertion"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/models"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/utils"
+)
+
+type RateLimiterConfig struct {
+	RequestsPerSecond float64
+	Burst             int
+	// Addresses or CIDRs of the proxies whose X-Forwarded-For is trusted
+	TrustedProxies []string
+	MaxClients     int
+	ClientTTL      time.Duration
+}
+
+// RateLimiter grants every client a token bucket, clients are keyed by API key, then token subject, then address
+type RateLimiter struct {
+	limit          rate.Limit
+	burst          int
+	trustedProxies []netip.Prefix
+	buckets        *utils.LRUCache[string, *rate.Limiter]
+	now            func() time.Time
+
+	allowed atomic.Uint64
+	limited atomic.Uint64
+}
+
+type rateLimitCtx struct{}
+
+var rateLimitKey rateLimitCtx
+
+// NewRateLimiter returns a limiter measuring time with now, time.Now when nil
+func NewRateLimiter(cfg RateLimiterConfig, now func() time.Time) (*RateLimiter, error) {
+	if now == nil {
+		now = time.Now
+	}
+	proxies := make([]netip.Prefix, 0, len(cfg.TrustedProxies))
+	for _, proxy := range cfg.TrustedProxies {
+		prefix, err := parseProxy(proxy)
+		if err != nil {
+			return nil, err
+		}
+		proxies = append(proxies, prefix)
+	}
+	return &RateLimiter{
+		limit:          rate.Limit(cfg.RequestsPerSecond),
+		burst:          cfg.Burst,
+		trustedProxies: proxies,
+		buckets:        utils.NewLRUCache[string, *rate.Limiter](cfg.MaxClients, cfg.ClientTTL, now),
+		now:            now,
+	}, nil
+}
+
+func parseProxy(proxy string) (netip.Prefix, error) {
+	if strings.Contains(proxy, "/") {
+		prefix, err := netip.ParsePrefix(proxy)
+		if err != nil {
+			return netip.Prefix{}, fmt.Errorf("invalid trusted proxy %q: %w", proxy, err)
+		}
+		return prefix.Masked(), nil
+	}
+	addr, err := netip.ParseAddr(proxy)
+	if err != nil {
+		return netip.Prefix{}, fmt.Errorf("invalid trusted proxy %q: %w", proxy, err)
+	}
+	addr = addr.Unmap()
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
+
+// withRateLimit overrides the rate limit of the client of the request
+func withRateLimit(ctx context.Context, limit *models.RateLimit) context.Context {
+	if limit == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, rateLimitKey, limit)
+}
+
+// RateLimit rejects requests of clients that exhausted their bucket with 429, every request passes when limiter is nil
+func RateLimit(limiter *RateLimiter) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if limiter == nil {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			override, _ := r.Context().Value(rateLimitKey).(*models.RateLimit)
+			allowed, retryAfter := limiter.Allow(limiter.ClientKey(r), override)
+			if !allowed {
+				w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+				utils.WriteProblemResponse(w, r, http.StatusTooManyRequests, "Rate limit exceeded, retry after the time given in the Retry-After header")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// Allow takes a token from the bucket of client, override replaces the configured rate when not nil.
+// A rejected request reports how long until a token is available
+func (l *RateLimiter) Allow(client string, override *models.RateLimit) (bool, time.Duration) {
+	limit, burst := l.limit, l.burst
+	if override != nil {
+		limit, burst = rate.Limit(override.RequestsPerSecond), override.Burst
+	}
+	now := l.now()
+	bucket := l.buckets.GetOrAdd(client, func() *rate.Limiter {
+		return rate.NewLimiter(limit, burst)
+	})
+	// The override of a key can change while its bucket is live
+	if bucket.Limit() != limit {
+		bucket.SetLimitAt(now, limit)
+	}
+	if bucket.Burst() != burst {
+		bucket.SetBurstAt(now, burst)
+	}
+
+	reservation := bucket.ReserveN(now, 1)
+	if reservation.OK() {
+		delay := reservation.DelayFrom(now)
+		if delay == 0 {
+			l.allowed.Add(1)
+			return true, 0
+		}
+		reservation.CancelAt(now)
+		l.limited.Add(1)
+		return false, max(delay, time.Second)
+	}
+	l.limited.Add(1)
+	return false, time.Second
+}
+
+// ClientKey identifies the client a request is counted against
+func (l *RateLimiter) ClientKey(r *http.Request) string {
+	ctx := r.Context()
+	if keyId, ok := jwtassertion.GetAPIKeyId(ctx); ok {
+		return "key:" + keyId.String()
+	}
+	if subject, ok := jwtassertion.GetSubject(ctx); ok && subject != uuid.Nil {
+		return "sub:" + subject.String()
+	}
+	return "ip:" + l.clientIP(r)
+}
+
+// clientIP returns the peer address, or when the peer is a trusted proxy
+// the nearest X-Forwarded-For hop that was not added by a trusted proxy
+func (l *RateLimiter) clientIP(r *http.Request) string {
+	addr, err := parsePeerAddr(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	if !l.isTrustedProxy(addr) {
+		return addr.String()
+	}
+	var hops []string
+	for _, header := range r.Header.Values("X-Forwarded-For") {
+		hops = append(hops, strings.Split(header, ",")...)
+	}
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+		if err != nil {
+			break
+		}
+		addr = hop.Unmap()
+		if !l.isTrustedProxy(addr) {
+			break
+		}
+	}
+	return addr.String()
+}
+
+func parsePeerAddr(remoteAddr string) (netip.Addr, error) {
+	if addrPort, err := netip.ParseAddrPort(remoteAddr); err == nil {
+		return addrPort.Addr().Unmap(), nil
+	}
+	addr, err := netip.ParseAddr(remoteAddr)
+	if err != nil {
+		return netip.Addr{}, err
+	}
+	return addr.Unmap(), nil
+}
+
+func (l *RateLimiter) isTrustedProxy(addr netip.Addr) bool {
+	for _, prefix := range l.trustedProxies {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// Counts returns the number of requests allowed and rejected since start
+func (l *RateLimiter) Counts() (allowed uint64, limited uint64) {
+	return l.allowed.Load(), l.limited.Load()
+}
+
+// RateLimitMetrics serves the request counters of limiter in the Prometheus text format
+func RateLimitMetrics(limiter *RateLimiter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var allowed, limited uint64
+		if limiter != nil {
+			allowed, limited = limiter.Counts()
+		}
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		_, _ = fmt.Fprintf(w, "# HELP agent_manager_rate_limit_requests_total API requests checked by the rate limiter.\n"+
+			"# TYPE agent_manager_rate_limit_requests_total counter\n"+
+			"agent_manager_rate_limit_requests_total{result=\"allowed\"} %d\n"+
+			"agent_manager_rate_limit_requests_total{result=\"limited\"} %d\n", allowed, limited)
+	}
+}
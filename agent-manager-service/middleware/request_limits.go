@@ -0,0 +1,158 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/utils"
+)
+
+// timeoutWriteGrace is the time left after a request timeout to write the timeout response
+const timeoutWriteGrace = 5 * time.Second
+
+// RequestLimits bounds the body size and processing time of a request, a zero value disables the bound
+type RequestLimits struct {
+	MaxBodyBytes int64
+	Timeout      time.Duration
+}
+
+// LimitRequests applies the default limits to every request, except for the routes of mux whose pattern has
+// an entry in routes. Bodies announced above the limit are rejected with 413 before any of them is read, and
+// requests exceeding the timeout are answered with 503 while the context of their handler is cancelled, which
+// stops its database and downstream calls. Responses are buffered until the handler returns.
+func LimitRequests(mux *http.ServeMux, defaults RequestLimits, routes map[string]RequestLimits) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			limits := defaults
+			if len(routes) > 0 {
+				if _, pattern := mux.Handler(r); pattern != "" {
+					if override, ok := routes[pattern]; ok {
+						limits = override
+					}
+				}
+			}
+
+			if limits.MaxBodyBytes > 0 {
+				if r.ContentLength > limits.MaxBodyBytes {
+					utils.WriteProblemResponse(w, r, http.StatusRequestEntityTooLarge,
+						fmt.Sprintf("Request body exceeds the limit of %d bytes", limits.MaxBodyBytes))
+					return
+				}
+				r.Body = http.MaxBytesReader(w, r.Body, limits.MaxBodyBytes)
+			}
+
+			// The server wide write timeout would cut off routes allowed to run longer
+			controller := http.NewResponseController(w)
+			if limits.Timeout <= 0 {
+				_ = controller.SetWriteDeadline(time.Time{})
+				next.ServeHTTP(w, r)
+				return
+			}
+			_ = controller.SetWriteDeadline(time.Now().Add(limits.Timeout + timeoutWriteGrace))
+			serveWithTimeout(next, w, r, limits.Timeout)
+		})
+	}
+}
+
+// serveWithTimeout runs next with a context cancelled after timeout and answers 503 unless it returned by then,
+// a panic of next is raised again on the calling goroutine with the stack it occurred in
+func serveWithTimeout(next http.Handler, w http.ResponseWriter, r *http.Request, timeout time.Duration) {
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	tw := &timeoutWriter{header: make(http.Header)}
+	done := make(chan struct{})
+	panicked := make(chan any, 1)
+	go func() {
+		defer func() {
+			if rec := recover(); rec != nil {
+				if rec != http.ErrAbortHandler {
+					rec = fmt.Sprintf("%v\n\n%s", rec, debug.Stack())
+				}
+				panicked <- rec
+			}
+		}()
+		next.ServeHTTP(tw, r.WithContext(ctx))
+		close(done)
+	}()
+
+	select {
+	case rec := <-panicked:
+		panic(rec)
+	case <-done:
+		tw.mu.Lock()
+		defer tw.mu.Unlock()
+		dst := w.Header()
+		for key, values := range tw.header {
+			dst[key] = values
+		}
+		if tw.status == 0 {
+			tw.status = http.StatusOK
+		}
+		w.WriteHeader(tw.status)
+		_, _ = w.Write(tw.body.Bytes())
+	case <-ctx.Done():
+		tw.mu.Lock()
+		defer tw.mu.Unlock()
+		tw.timedOut = true
+		// Nobody is left to answer when the client went away
+		if ctx.Err() == context.DeadlineExceeded {
+			utils.WriteProblemResponse(w, r, http.StatusServiceUnavailable, "The request timed out, retry later")
+		}
+	}
+}
+
+// timeoutWriter buffers the response of a handler so it can be dropped once the request timed out
+type timeoutWriter struct {
+	mu       sync.Mutex
+	header   http.Header
+	body     bytes.Buffer
+	status   int
+	timedOut bool
+}
+
+func (tw *timeoutWriter) Header() http.Header {
+	return tw.header
+}
+
+func (tw *timeoutWriter) Write(p []byte) (int, error) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+	if tw.status == 0 {
+		tw.status = http.StatusOK
+	}
+	return tw.body.Write(p)
+}
+
+func (tw *timeoutWriter) WriteHeader(status int) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.timedOut || tw.status != 0 {
+		return
+	}
+	tw.status = status
+}
@@ -0,0 +1,72 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package middleware
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/api/openapi"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/utils"
+)
+
+// ValidateRequestBodies rejects bodies of requests to the routes of mux that do not match the schema spec gives
+// the route, answering 400 with a field error for each unknown field and value of the wrong type. Empty bodies and
+// bodies that are not JSON are passed on for the handler to reject
+func ValidateRequestBodies(mux *http.ServeMux, spec *openapi.Spec) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Body == nil || r.Body == http.NoBody || r.ContentLength == 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+			_, pattern := mux.Handler(r)
+			if pattern == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				utils.WriteBodyProblem(w, r, err)
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+			if len(body) == 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			violations, err := spec.Validate(pattern, body)
+			if err != nil || len(violations) == 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+			fieldErrors := make([]utils.FieldError, 0, len(violations))
+			for _, violation := range violations {
+				fieldErrors = append(fieldErrors, utils.FieldError{
+					Field:      violation.Field,
+					Pointer:    violation.Pointer,
+					Constraint: violation.Constraint,
+					Message:    violation.Message,
+				})
+			}
+			utils.WriteProblemResponse(w, r, http.StatusBadRequest, "Invalid request body", fieldErrors...)
+		})
+	}
+}
@@ -0,0 +1,100 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package middleware
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/api/openapi"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/utils"
+)
+
+type validatedAgent struct {
+	Name     string `json:"name"`
+	Replicas int32  `json:"replicas"`
+}
+
+func TestValidateRequestBodies(t *testing.T) {
+	spec, err := openapi.New(openapi.Info{}, []openapi.Operation{
+		{Pattern: "POST /orgs/{orgName}/agents", Request: validatedAgent{}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var received string
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /orgs/{orgName}/agents", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received = string(body)
+		w.WriteHeader(http.StatusCreated)
+	})
+	mux.HandleFunc("POST /orgs/{orgName}/agents/{agentId}/restart", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+	})
+	handler := ValidateRequestBodies(mux, spec)(mux)
+
+	tests := []struct {
+		name   string
+		path   string
+		body   string
+		want   int
+		errors []utils.FieldError
+	}{
+		{name: "valid body", path: "/orgs/acme/agents", body: `{"name":"a","replicas":2}`, want: http.StatusCreated},
+		{name: "not JSON is left to the handler", path: "/orgs/acme/agents", body: `name=a`, want: http.StatusCreated},
+		{name: "route without a schema", path: "/orgs/acme/agents/a/restart", body: `{"force":true}`, want: http.StatusAccepted},
+		{
+			name: "each violation is listed",
+			path: "/orgs/acme/agents",
+			body: `{"name":"a","replicas":"2","owner":"me"}`,
+			want: http.StatusBadRequest,
+			errors: []utils.FieldError{
+				{Field: "owner", Pointer: "/owner", Constraint: "additionalProperties", Message: "is not a known field"},
+				{Field: "replicas", Pointer: "/replicas", Constraint: "type", Message: "must be of type integer"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			received = ""
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(tt.body)))
+			if rr.Code != tt.want {
+				t.Fatalf("status = %d, want %d: %s", rr.Code, tt.want, rr.Body.String())
+			}
+			if tt.want == http.StatusCreated && received != tt.body {
+				t.Errorf("handler read %q, want the body passed on unchanged", received)
+			}
+			if tt.errors == nil {
+				return
+			}
+			var problem utils.ProblemDetails
+			if err := json.Unmarshal(rr.Body.Bytes(), &problem); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(problem.Errors, tt.errors) {
+				t.Errorf("errors = %v, want %v", problem.Errors, tt.errors)
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// API Request DTO
+type AgentBudgetRequest struct {
+	// IANA time zone whose midnights start the daily and monthly periods, UTC when omitted
+	Timezone string        `json:"timezone,omitempty"`
+	Daily    *BudgetLimits `json:"daily,omitempty"`
+	Monthly  *BudgetLimits `json:"monthly,omitempty"`
+	// Percentages of a limit that raise an alert when reached, 80 and 100 when omitted
+	AlertThresholds []int32 `json:"alertThresholds,omitempty"`
+	// What happens when a limit is reached, only none (alerts only) is supported for now
+	Enforcement string `json:"enforcement,omitempty"`
+}
+
+// BudgetLimits caps the usage of an agent over a period, limits that are omitted are not tracked
+type BudgetLimits struct {
+	// Cost of priced models in USD
+	MaxCost   *float64 `json:"maxCost,omitempty"`
+	MaxTokens *int64   `json:"maxTokens,omitempty"`
+}
+
+// API Response DTO
+type AgentBudgetResponse struct {
+	AgentID         string        `json:"agentId"`
+	Timezone        string        `json:"timezone"`
+	Daily           *BudgetLimits `json:"daily,omitempty"`
+	Monthly         *BudgetLimits `json:"monthly,omitempty"`
+	AlertThresholds []int32       `json:"alertThresholds"`
+	Enforcement     string        `json:"enforcement"`
+	CreatedAt       time.Time     `json:"createdAt"`
+	UpdatedAt       time.Time     `json:"updatedAt"`
+	// Usage of the current periods with limits, only returned when the budget is read
+	Usage []BudgetPeriodUsage `json:"usage,omitempty"`
+}
+
+// BudgetPeriodUsage is what an agent consumed in the current day or month against the limits of the period
+type BudgetPeriodUsage struct {
+	// day or month
+	Period string `json:"period"`
+	// Bounds of the period, with the offset of the budget time zone
+	StartTime time.Time `json:"startTime"`
+	EndTime   time.Time `json:"endTime"`
+	Cost      float64   `json:"cost"`
+	Tokens    int64     `json:"tokens"`
+	MaxCost   *float64  `json:"maxCost,omitempty"`
+	MaxTokens *int64    `json:"maxTokens,omitempty"`
+	// Share of each limit used in percent, omitted for limits that are not set
+	CostPercent   *float64 `json:"costPercent,omitempty"`
+	TokensPercent *float64 `json:"tokensPercent,omitempty"`
+	// Whether a limit of the period is reached
+	Exceeded bool `json:"exceeded"`
+}
+
+// AgentBudgetAlertEvent is the data of the webhook event raised when usage reaches an alert threshold
+type AgentBudgetAlertEvent struct {
+	AgentID     string    `json:"agentId"`
+	AgentName   string    `json:"agentName"`
+	Period      string    `json:"period"`
+	PeriodStart time.Time `json:"periodStart"`
+	PeriodEnd   time.Time `json:"periodEnd"`
+	// cost or tokens
+	Metric    string  `json:"metric"`
+	Threshold int32   `json:"threshold"`
+	Limit     float64 `json:"limit"`
+	Used      float64 `json:"used"`
+	Percent   float64 `json:"percent"`
+	// Enforcement of the budget, alerts do not block the agent whatever it is
+	Enforcement string `json:"enforcement"`
+}
+
+// DB Model
+type AgentBudget struct {
+	AgentID          uuid.UUID `gorm:"column:agent_id;primaryKey"`
+	OrgID            uuid.UUID `gorm:"column:org_id"`
+	Timezone         string    `gorm:"column:timezone"`
+	DailyMaxCost     *float64  `gorm:"column:daily_max_cost"`
+	DailyMaxTokens   *int64    `gorm:"column:daily_max_tokens"`
+	MonthlyMaxCost   *float64  `gorm:"column:monthly_max_cost"`
+	MonthlyMaxTokens *int64    `gorm:"column:monthly_max_tokens"`
+	AlertThresholds  []int32   `gorm:"column:alert_thresholds;type:jsonb;serializer:json"`
+	Enforcement      string    `gorm:"column:enforcement"`
+	CreatedAt        time.Time `gorm:"column:created_at"`
+	UpdatedAt        time.Time `gorm:"column:updated_at"`
+	// Name of the agent, only read when budgets are listed for checking
+	AgentName string `gorm:"column:agent_name;->"`
+}
+
+// DB Model of a threshold reached in a budget period, recorded so its alert is raised once
+type AgentBudgetAlert struct {
+	AgentID     uuid.UUID `gorm:"column:agent_id;primaryKey"`
+	Period      string    `gorm:"column:period;primaryKey"`
+	PeriodStart time.Time `gorm:"column:period_start;primaryKey"`
+	Metric      string    `gorm:"column:metric;primaryKey"`
+	Threshold   int32     `gorm:"column:threshold;primaryKey"`
+	AlertedAt   time.Time `gorm:"column:alerted_at"`
+}
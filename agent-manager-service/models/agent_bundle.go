@@ -0,0 +1,103 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package models
+
+import "time"
+
+// AgentBundleSchemaVersion is the version of the bundle format written by exports. Imports also accept bundles
+// of every earlier version
+const AgentBundleSchemaVersion = 1
+
+// AgentBundle is a self-contained copy of a managed agent together with the prompt version and the registered
+// tools it references, so it can be imported into another organization or environment. Its parts reference
+// each other by name
+type AgentBundle struct {
+	SchemaVersion int              `json:"schemaVersion"`
+	ExportedAt    time.Time        `json:"exportedAt"`
+	Agent         AgentBundleAgent `json:"agent"`
+	// Prompt version the agent uses instead of an inline systemPrompt
+	Prompt *AgentBundlePrompt `json:"prompt,omitempty"`
+	// Registered tools the agent references
+	Tools []ToolRequest `json:"tools,omitempty"`
+}
+
+// AgentBundleAgent is the configuration of an exported agent
+type AgentBundleAgent struct {
+	Name         string                 `json:"name"`
+	Description  string                 `json:"description,omitempty"`
+	Framework    string                 `json:"framework"`
+	ModelConfig  map[string]interface{} `json:"modelConfig"`
+	SystemPrompt string                 `json:"systemPrompt,omitempty"`
+	// Name of the prompt of the bundle the agent uses
+	Prompt string                     `json:"prompt,omitempty"`
+	Tools  []AgentBundleToolReference `json:"tools,omitempty"`
+	Labels map[string]string          `json:"labels,omitempty"`
+	// Version of the framework schema modelConfig is validated against, the latest when omitted
+	ConfigSchemaVersion *int32 `json:"configSchemaVersion,omitempty"`
+	// Set on agents stored without validation
+	AllowUnvalidated bool `json:"allowUnvalidated,omitempty"`
+}
+
+// AgentBundleToolReference is a tool of an exported agent, either a tool of the bundle or an ad hoc tool
+type AgentBundleToolReference struct {
+	// Name of a tool of the bundle
+	Tool string `json:"tool,omitempty"`
+	// Name of an ad hoc tool
+	Name string `json:"name,omitempty"`
+}
+
+// AgentBundlePrompt is the prompt version an exported agent uses
+type AgentBundlePrompt struct {
+	PromptTemplateRequest
+	// Version of the prompt in the organization it was exported from
+	Version int32 `json:"version"`
+}
+
+// AgentImportChange is a change an import makes, or would make in a dry run, to a resource of the organization
+type AgentImportChange struct {
+	// One of agent, prompt or tool
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+	// Id of the resource, not yet known for resources a dry run would create
+	ID string `json:"id,omitempty"`
+	// One of create, update or unchanged, prompts are updated by adding a version
+	Action string `json:"action"`
+	// Version of the agent or prompt after the import
+	Version int32 `json:"version,omitempty"`
+	// Configuration changes of an updated agent
+	Changes []ConfigChange `json:"changes,omitempty"`
+}
+
+// AgentImportConflict is a part of a bundle that cannot be imported without overwriting a different resource
+type AgentImportConflict struct {
+	// Path of the conflicting part of the bundle, e.g. tools[0]
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// AgentImportPlan lists what an import changes and the conflicts that prevent it
+type AgentImportPlan struct {
+	Changes   []AgentImportChange   `json:"changes"`
+	Conflicts []AgentImportConflict `json:"conflicts"`
+}
+
+type AgentImportResponse struct {
+	AgentImportPlan
+	DryRun bool `json:"dryRun"`
+	// The imported agent, not set for dry runs
+	Agent *ManagedAgentResponse `json:"agent,omitempty"`
+}
@@ -0,0 +1,139 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// API Request DTO
+type AgentEnvironmentRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+// API Response DTO
+type AgentEnvironmentResponse struct {
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+type AgentEnvironmentListResponse struct {
+	Environments []AgentEnvironmentResponse `json:"environments"`
+}
+
+// API Request DTO
+type AgentDeploymentRequest struct {
+	Environment string `json:"environment"`
+	Version     int32  `json:"version"`
+}
+
+// AgentPromotionRequest deploys the version an agent runs in one environment to another
+type AgentPromotionRequest struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	// Version the agent is expected to run in the source environment, the promotion fails when another version
+	// was deployed there meanwhile
+	Version *int32 `json:"version,omitempty"`
+}
+
+// API Response DTO
+type AgentDeploymentResponse struct {
+	ID          string `json:"id"`
+	AgentID     string `json:"agentId"`
+	Environment string `json:"environment"`
+	Version     int32  `json:"version"`
+	// Environment the version was promoted from, not set for direct deployments
+	PromotedFrom string `json:"promotedFrom,omitempty"`
+	// IdP id of the user who deployed or promoted the version
+	DeployedBy string    `json:"deployedBy,omitempty"`
+	DeployedAt time.Time `json:"deployedAt"`
+}
+
+type AgentDeploymentListResponse struct {
+	Deployments []AgentDeploymentResponse `json:"deployments"`
+	Total       int32                     `json:"total"`
+	Limit       int32                     `json:"limit"`
+	Offset      int32                     `json:"offset"`
+}
+
+// EnvironmentAgentResponse is an agent deployed to an environment with the deployment it currently runs
+type EnvironmentAgentResponse struct {
+	AgentID    string                  `json:"agentId"`
+	Name       string                  `json:"name"`
+	Deployment AgentDeploymentResponse `json:"deployment"`
+}
+
+type EnvironmentAgentListResponse struct {
+	Environment string                     `json:"environment"`
+	Agents      []EnvironmentAgentResponse `json:"agents"`
+	Total       int32                      `json:"total"`
+	Limit       int32                      `json:"limit"`
+	Offset      int32                      `json:"offset"`
+}
+
+// ResolvedAgentConfig is the configuration an agent runs with in an environment. The prompt and the registered
+// tools it references are resolved, so runtimes need no further lookups
+type ResolvedAgentConfig struct {
+	AgentID      string                 `json:"agentId"`
+	Environment  string                 `json:"environment"`
+	Version      int32                  `json:"version"`
+	DeploymentID string                 `json:"deploymentId"`
+	Name         string                 `json:"name"`
+	Description  string                 `json:"description,omitempty"`
+	Framework    string                 `json:"framework"`
+	ModelConfig  map[string]interface{} `json:"modelConfig"`
+	// Inline system prompt, or the template of the prompt version the agent references
+	SystemPrompt string           `json:"systemPrompt,omitempty"`
+	PromptRef    *PromptReference `json:"promptRef,omitempty"`
+	// Placeholders of the referenced prompt template
+	PromptVariables []PromptVariable      `json:"promptVariables,omitempty"`
+	Tools           []AgentToolDefinition `json:"tools"`
+	Labels          map[string]string     `json:"labels"`
+	Enabled         bool                  `json:"enabled"`
+}
+
+// DB Model
+type AgentEnvironment struct {
+	ID          uuid.UUID `gorm:"column:id;primaryKey"`
+	OrgID       uuid.UUID `gorm:"column:org_id"`
+	Name        string    `gorm:"column:name"`
+	Description string    `gorm:"column:description"`
+	CreatedAt   time.Time `gorm:"column:created_at"`
+}
+
+// DB Model of the deployment of an agent version to an environment
+type AgentDeployment struct {
+	ID            uuid.UUID  `gorm:"column:id;primaryKey"`
+	AgentID       uuid.UUID  `gorm:"column:agent_id"`
+	EnvironmentID uuid.UUID  `gorm:"column:environment_id"`
+	Version       int32      `gorm:"column:version"`
+	PromotedFrom  *string    `gorm:"column:promoted_from"`
+	DeployedBy    *uuid.UUID `gorm:"column:deployed_by"`
+	DeployedAt    time.Time  `gorm:"column:deployed_at"`
+	// Name of the environment, read along with the deployment
+	Environment string `gorm:"column:environment;->"`
+}
+
+// EnvironmentAgent is an agent with its current deployment to an environment
+type EnvironmentAgent struct {
+	AgentDeployment
+	AgentName string `gorm:"column:agent_name;->"`
+}
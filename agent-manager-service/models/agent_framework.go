@@ -0,0 +1,35 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package models
+
+// AgentFrameworkResponse is a framework agents may declare with every version of its model configuration schema
+type AgentFrameworkResponse struct {
+	Name string `json:"name"`
+	// Schema version new agents are validated against
+	LatestSchemaVersion int32                          `json:"latestSchemaVersion"`
+	Schemas             []AgentFrameworkSchemaResponse `json:"schemas"`
+}
+
+type AgentFrameworkSchemaResponse struct {
+	Version int32 `json:"version"`
+	// JSON Schema (draft 2020-12) of the modelConfig of the framework's agents
+	Schema map[string]interface{} `json:"schema"`
+}
+
+type AgentFrameworkListResponse struct {
+	Frameworks []AgentFrameworkResponse `json:"frameworks"`
+}
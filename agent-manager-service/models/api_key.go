@@ -0,0 +1,93 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// API Request DTO
+type APIKeyRequest struct {
+	Name   string   `json:"name"`
+	Scopes []string `json:"scopes"`
+	// The key never expires when omitted
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+	// Requests made with the key are limited to the server wide rate when omitted
+	RateLimit *RateLimit `json:"rateLimit,omitempty"`
+}
+
+// RateLimit is the sustained request rate and burst granted to a client
+type RateLimit struct {
+	RequestsPerSecond float64 `json:"requestsPerSecond"`
+	Burst             int     `json:"burst"`
+}
+
+// API Response DTO, the secret is never returned after creation
+type APIKeyResponse struct {
+	ID     string   `json:"id"`
+	Name   string   `json:"name"`
+	Prefix string   `json:"prefix"`
+	Scopes []string `json:"scopes"`
+	// Last use is recorded in batches and can lag behind by the flush interval
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty"`
+	ExpiresAt  *time.Time `json:"expiresAt,omitempty"`
+	RateLimit  *RateLimit `json:"rateLimit,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt"`
+}
+
+// API Response DTO of a created key, the only response that carries the secret
+type APIKeyCreateResponse struct {
+	APIKeyResponse
+	Key string `json:"key"`
+}
+
+type APIKeyListResponse struct {
+	APIKeys []APIKeyResponse `json:"apiKeys"`
+	Total   int32            `json:"total"`
+	Limit   int32            `json:"limit"`
+	Offset  int32            `json:"offset"`
+}
+
+// APIKeyPrincipal is the identity a request authenticated with an API key acts as
+type APIKeyPrincipal struct {
+	KeyID     uuid.UUID
+	UserIdpId uuid.UUID
+	// Organization the key is restricted to
+	OrgName string
+	Scopes  []string
+	// Rate limit overriding the server wide one, nil when the key has none
+	RateLimit *RateLimit
+}
+
+// DB Model
+type APIKey struct {
+	ID        uuid.UUID `gorm:"column:id;primaryKey"`
+	OrgID     uuid.UUID `gorm:"column:org_id"`
+	UserIdpId uuid.UUID `gorm:"column:user_idp_id"`
+	Name      string    `gorm:"column:name"`
+	KeyPrefix string    `gorm:"column:key_prefix"`
+	// Hex encoded SHA-256 of the key
+	KeyHash    string     `gorm:"column:key_hash"`
+	Scopes     []string   `gorm:"column:scopes;type:jsonb;serializer:json"`
+	ExpiresAt  *time.Time `gorm:"column:expires_at"`
+	LastUsedAt *time.Time `gorm:"column:last_used_at"`
+	RevokedAt  *time.Time `gorm:"column:revoked_at"`
+	RateLimit  *RateLimit `gorm:"column:rate_limit;type:jsonb;serializer:json"`
+	CreatedAt  time.Time  `gorm:"column:created_at"`
+}
@@ -0,0 +1,81 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AuditFieldChange is the value of a field before and after an update
+type AuditFieldChange struct {
+	From interface{} `json:"from"`
+	To   interface{} `json:"to"`
+}
+
+// AuditLogFilter narrows a listing of audit logs, zero values match every entry
+type AuditLogFilter struct {
+	ActorIdpId   *uuid.UUID
+	ResourceType string
+	ResourceID   string
+	Action       string
+	// Entries recorded at or after From and before To
+	From *time.Time
+	To   *time.Time
+}
+
+// API Response DTO
+type AuditLogResponse struct {
+	ID string `json:"id"`
+	// IdP id of the user who made the request
+	Actor string `json:"actor"`
+	// Set when the request was authenticated with an API key of the actor
+	APIKeyID     *string `json:"apiKeyId,omitempty"`
+	Action       string  `json:"action"`
+	ResourceType string  `json:"resourceType"`
+	ResourceID   string  `json:"resourceId"`
+	Route        string  `json:"route"`
+	StatusCode   int     `json:"statusCode"`
+	// Fields changed by an update, secrets are redacted
+	Changes       map[string]AuditFieldChange `json:"changes,omitempty"`
+	CorrelationID string                      `json:"correlationId"`
+	Timestamp     time.Time                   `json:"timestamp"`
+}
+
+type AuditLogListResponse struct {
+	AuditLogs []AuditLogResponse `json:"auditLogs"`
+	Total     int32              `json:"total"`
+	Limit     int32              `json:"limit"`
+	Offset    int32              `json:"offset"`
+}
+
+// DB Model, entries are never updated or deleted
+type AuditLog struct {
+	ID            uuid.UUID                   `gorm:"column:id;primaryKey"`
+	OrgID         uuid.UUID                   `gorm:"column:org_id"`
+	ActorIdpId    uuid.UUID                   `gorm:"column:actor_idp_id"`
+	APIKeyID      *uuid.UUID                  `gorm:"column:api_key_id"`
+	Action        string                      `gorm:"column:action"`
+	ResourceType  string                      `gorm:"column:resource_type"`
+	ResourceID    string                      `gorm:"column:resource_id"`
+	Route         string                      `gorm:"column:route"`
+	StatusCode    int                         `gorm:"column:status_code"`
+	Changes       map[string]AuditFieldChange `gorm:"column:changes;type:jsonb;serializer:json"`
+	CorrelationID string                      `gorm:"column:correlation_id"`
+	CreatedAt     time.Time                   `gorm:"column:created_at"`
+}
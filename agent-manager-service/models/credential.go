@@ -0,0 +1,81 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// API Request DTO
+type CredentialRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	// Required on create, an update without it keeps the current secret
+	Secret string `json:"secret,omitempty"`
+}
+
+// API Response DTO, the secret is never returned, only a masked preview of it
+type CredentialResponse struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Preview     string `json:"preview"`
+	// Master key the secret is encrypted with, rotation moves every credential to the active key
+	KeyID     string    `json:"keyId"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+type CredentialListResponse struct {
+	Credentials []CredentialResponse `json:"credentials"`
+	Total       int32                `json:"total"`
+	Limit       int32                `json:"limit"`
+	Offset      int32                `json:"offset"`
+}
+
+// CredentialSecretResponse is the decrypted secret handed to agent runtimes
+type CredentialSecretResponse struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Secret string `json:"secret"`
+}
+
+type CredentialKeyRotationResponse struct {
+	// Master key every credential of the organization is now encrypted with
+	KeyID string `json:"keyId"`
+	// Credentials moved from a previous key
+	Rotated int32 `json:"rotated"`
+	// Webhook secrets moved from a previous key
+	RotatedWebhooks int32 `json:"rotatedWebhooks"`
+}
+
+// DB Model
+type Credential struct {
+	ID             uuid.UUID  `gorm:"column:id;primaryKey"`
+	OrgID          uuid.UUID  `gorm:"column:org_id"`
+	Name           string     `gorm:"column:name"`
+	Description    string     `gorm:"column:description"`
+	KeyID          string     `gorm:"column:key_id"`
+	EncryptedKey   []byte     `gorm:"column:encrypted_key"`
+	EncryptedValue []byte     `gorm:"column:encrypted_value"`
+	Preview        string     `gorm:"column:preview"`
+	CreatedBy      *uuid.UUID `gorm:"column:created_by"`
+	CreatedAt      time.Time  `gorm:"column:created_at"`
+	UpdatedAt      time.Time  `gorm:"column:updated_at"`
+}
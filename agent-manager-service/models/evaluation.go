@@ -0,0 +1,173 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// API Request DTO
+type EvaluationRequest struct {
+	Name      string                `json:"name,omitempty"`
+	Filter    EvaluationTraceFilter `json:"filter"`
+	Evaluator EvaluatorDefinition   `json:"evaluator"`
+}
+
+// EvaluationTraceFilter selects the traces of a managed agent a run evaluates, oldest first
+type EvaluationTraceFilter struct {
+	AgentID   string     `json:"agentId"`
+	StartTime *time.Time `json:"startTime,omitempty"`
+	// End of the time range, the time the run is created when omitted
+	EndTime *time.Time `json:"endTime,omitempty"`
+	// Most traces evaluated, 1000 when omitted
+	MaxTraces int32 `json:"maxTraces,omitempty"`
+}
+
+// EvaluatorDefinition is how each trace is scored, builtin rules score 1 or 0
+type EvaluatorDefinition struct {
+	// output_non_empty, no_tool_errors, regex or http
+	Type string `json:"type"`
+	// Regular expression the field must match, regex only
+	Pattern string `json:"pattern,omitempty"`
+	// input or output, regex only and output when omitted
+	Field string `json:"field,omitempty"`
+	// Endpoint traces are POSTed to, http only
+	URL string `json:"url,omitempty"`
+	// Lowest score an http evaluator passes a trace with when its response has no verdict, 0.5 when omitted
+	PassThreshold *float64 `json:"passThreshold,omitempty"`
+}
+
+// API Response DTO
+type EvaluationResponse struct {
+	ID        string                `json:"id"`
+	Name      string                `json:"name,omitempty"`
+	Status    string                `json:"status"`
+	Filter    EvaluationTraceFilter `json:"filter"`
+	Evaluator EvaluatorDefinition   `json:"evaluator"`
+	Progress  EvaluationProgress    `json:"progress"`
+	Summary   EvaluationSummary     `json:"summary"`
+	// Why the run failed
+	Error       string     `json:"error,omitempty"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	StartedAt   *time.Time `json:"startedAt,omitempty"`
+	CompletedAt *time.Time `json:"completedAt,omitempty"`
+}
+
+type EvaluationProgress struct {
+	// Traces matching the filter up to maxTraces, known once the run started
+	TotalTraces     int32   `json:"totalTraces"`
+	EvaluatedTraces int32   `json:"evaluatedTraces"`
+	Percent         float64 `json:"percent"`
+}
+
+// EvaluationSummary aggregates the results of the traces evaluated so far
+type EvaluationSummary struct {
+	Passed int32 `json:"passed"`
+	Failed int32 `json:"failed"`
+	// Traces the evaluator could not score
+	Errored int32 `json:"errored"`
+	// Share of the scored traces that passed, omitted until a trace is scored
+	PassRate     *float64 `json:"passRate,omitempty"`
+	AverageScore *float64 `json:"averageScore,omitempty"`
+}
+
+type EvaluationListResponse struct {
+	Evaluations []EvaluationResponse `json:"evaluations"`
+	Total       int32                `json:"total"`
+	Limit       int32                `json:"limit"`
+	Offset      int32                `json:"offset"`
+}
+
+type EvaluationResultResponse struct {
+	TraceID     string    `json:"traceId"`
+	Score       *float64  `json:"score,omitempty"`
+	Passed      bool      `json:"passed"`
+	Reason      string    `json:"reason,omitempty"`
+	Error       string    `json:"error,omitempty"`
+	EvaluatedAt time.Time `json:"evaluatedAt"`
+}
+
+type EvaluationResultListResponse struct {
+	Results []EvaluationResultResponse `json:"results"`
+	Total   int32                      `json:"total"`
+	Limit   int32                      `json:"limit"`
+	Offset  int32                      `json:"offset"`
+}
+
+// HTTPEvaluatorRequest is the body POSTed to http evaluators for each trace
+type HTTPEvaluatorRequest struct {
+	EvaluationID string      `json:"evaluationId"`
+	TraceID      string      `json:"traceId"`
+	Input        interface{} `json:"input"`
+	Output       interface{} `json:"output"`
+}
+
+// HTTPEvaluatorResponse is what http evaluators answer with, a score from 0 to 1 and optionally a verdict
+type HTTPEvaluatorResponse struct {
+	Score  *float64 `json:"score"`
+	Passed *bool    `json:"passed,omitempty"`
+	Reason string   `json:"reason,omitempty"`
+}
+
+// EvaluationOutcome is the score of a single trace, Error is set instead when it could not be scored
+type EvaluationOutcome struct {
+	Score  *float64
+	Passed bool
+	Reason string
+	Error  string
+}
+
+// DB Model
+type EvaluationRun struct {
+	ID        uuid.UUID             `gorm:"column:id;primaryKey"`
+	OrgID     uuid.UUID             `gorm:"column:org_id"`
+	Name      string                `gorm:"column:name"`
+	AgentID   uuid.UUID             `gorm:"column:agent_id"`
+	Filter    EvaluationTraceFilter `gorm:"column:filter;type:jsonb;serializer:json"`
+	Evaluator EvaluatorDefinition   `gorm:"column:evaluator;type:jsonb;serializer:json"`
+	Status    string                `gorm:"column:status"`
+	// Checkpoint of the run, the trace observer cursor of the next page to evaluate
+	Cursor          string  `gorm:"column:cursor"`
+	TotalTraces     int32   `gorm:"column:total_traces"`
+	EvaluatedTraces int32   `gorm:"column:evaluated_traces"`
+	PassedTraces    int32   `gorm:"column:passed_traces"`
+	FailedTraces    int32   `gorm:"column:failed_traces"`
+	ErroredTraces   int32   `gorm:"column:errored_traces"`
+	ScoreSum        float64 `gorm:"column:score_sum"`
+	Error           string  `gorm:"column:error"`
+	// Worker holding the run until the lease runs out
+	LeaseID     *uuid.UUID `gorm:"column:lease_id"`
+	LeaseUntil  *time.Time `gorm:"column:lease_until"`
+	CreatedBy   uuid.UUID  `gorm:"column:created_by"`
+	CreatedAt   time.Time  `gorm:"column:created_at"`
+	StartedAt   *time.Time `gorm:"column:started_at"`
+	CompletedAt *time.Time `gorm:"column:completed_at"`
+	UpdatedAt   time.Time  `gorm:"column:updated_at"`
+}
+
+// DB Model
+type EvaluationResult struct {
+	RunID       uuid.UUID `gorm:"column:run_id;primaryKey"`
+	TraceID     string    `gorm:"column:trace_id;primaryKey"`
+	Score       *float64  `gorm:"column:score"`
+	Passed      bool      `gorm:"column:passed"`
+	Reason      string    `gorm:"column:reason"`
+	Error       string    `gorm:"column:error"`
+	EvaluatedAt time.Time `gorm:"column:evaluated_at"`
+}
@@ -0,0 +1,36 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package models
+
+import "time"
+
+// DB Model of the outcome of a request sent with an Idempotency-Key
+type IdempotencyKey struct {
+	// Client the key belongs to, keys of different clients never collide
+	Principal string `gorm:"column:principal;primaryKey"`
+	Key       string `gorm:"column:idempotency_key;primaryKey"`
+	// Method and path the key was used on
+	Route string `gorm:"column:route;primaryKey"`
+	// Hex encoded SHA-256 of the request body
+	RequestHash string `gorm:"column:request_hash"`
+	// Nil while the first execution is in progress
+	StatusCode      *int              `gorm:"column:status_code"`
+	ResponseHeaders map[string]string `gorm:"column:response_headers;type:jsonb;serializer:json"`
+	ResponseBody    []byte            `gorm:"column:response_body"`
+	CreatedAt       time.Time         `gorm:"column:created_at"`
+	ExpiresAt       time.Time         `gorm:"column:expires_at"`
+}
@@ -0,0 +1,37 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package models
+
+// Operators of label selector requirements
+const (
+	LabelOperatorIn           = "in"
+	LabelOperatorNotIn        = "notin"
+	LabelOperatorExists       = "exists"
+	LabelOperatorDoesNotExist = "!"
+)
+
+// LabelRequirement is a condition on one label of a resource. key=value and key!=value are parsed as in and notin
+// with a single value. notin and ! also match resources without the label
+type LabelRequirement struct {
+	Key      string
+	Operator string
+	// Values of in and notin requirements
+	Values []string
+}
+
+// LabelSelector matches the resources meeting all of its requirements
+type LabelSelector []LabelRequirement
@@ -0,0 +1,64 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Fields agents, prompts, tools and webhooks can be listed by
+const (
+	ListSortName      = "name"
+	ListSortCreatedAt = "createdAt"
+	ListSortUpdatedAt = "updatedAt"
+)
+
+// Orders of listings
+const (
+	ListOrderAsc  = "asc"
+	ListOrderDesc = "desc"
+)
+
+// ListPage selects a page of a listing
+type ListPage struct {
+	// Field the listing is sorted by, the id breaks ties
+	Sort string
+	// Order of the sort, asc or desc
+	Order string
+	Limit int
+	// Results to skip, ignored when the page continues from a cursor
+	Offset int
+	// Position the page continues from, the last result of the previous page
+	Cursor *ListCursor
+	// Count the results of the whole listing along with the page
+	Count bool
+}
+
+// ListCursor is the decoded form of an opaque list cursor, the sort key of the last result of a page.
+// Pages continue after the key rather than after a number of results, so results created or deleted
+// meanwhile neither shift the following pages nor make them repeat results
+type ListCursor struct {
+	Sort  string `json:"sort"`
+	Order string `json:"order"`
+	// Name of the last result, when sorted by name
+	Name string `json:"name,omitempty"`
+	// Creation or update time of the last result, when sorted by either
+	Time *time.Time `json:"time,omitempty"`
+	ID   uuid.UUID  `json:"id"`
+}
@@ -0,0 +1,204 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// API Request DTO
+type ManagedAgentRequest struct {
+	Name         string                 `json:"name"`
+	Description  string                 `json:"description,omitempty"`
+	Framework    string                 `json:"framework"`
+	ModelConfig  map[string]interface{} `json:"modelConfig"`
+	SystemPrompt string                 `json:"systemPrompt,omitempty"`
+	PromptRef    *PromptReference       `json:"promptRef,omitempty"`
+	Tools        []ToolReference        `json:"tools,omitempty"`
+	Labels       map[string]string      `json:"labels,omitempty"`
+	// Disabled agents are kept but not served, updates that omit it keep the current state and new agents are enabled
+	Enabled *bool `json:"enabled,omitempty"`
+	// Version of the framework schema modelConfig is validated against, updates that omit it keep the version
+	// the agent was validated against and new agents use the latest
+	ConfigSchemaVersion *int32 `json:"configSchemaVersion,omitempty"`
+	// Stores the agent without validating modelConfig, required for frameworks without a schema
+	AllowUnvalidated bool `json:"allowUnvalidated,omitempty"`
+	// Days the traces of the agent are kept before the traces observer deletes them, updates that omit it keep
+	// the current retention and 0 returns the agent to the retention of the traces observer
+	RetentionDays *int32 `json:"retentionDays,omitempty"`
+	// Version the update is based on, an alternative to the If-Match header that is ignored on create
+	Version *int32 `json:"version,omitempty"`
+}
+
+// PromptReference pins a version of a prompt template, agents use it instead of an inline systemPrompt
+type PromptReference struct {
+	PromptID string `json:"promptId"`
+	Version  int32  `json:"version"`
+}
+
+// ToolReference is a tool the agent is allowed to call, either a registered tool referenced by id
+// or an ad hoc tool referenced by name
+type ToolReference struct {
+	ToolID string `json:"toolId,omitempty"`
+	Name   string `json:"name,omitempty"`
+}
+
+// API Response DTO
+type ManagedAgentResponse struct {
+	ID           string                 `json:"id"`
+	Name         string                 `json:"name"`
+	Description  string                 `json:"description,omitempty"`
+	Framework    string                 `json:"framework"`
+	ModelConfig  map[string]interface{} `json:"modelConfig"`
+	SystemPrompt string                 `json:"systemPrompt,omitempty"`
+	PromptRef    *PromptReference       `json:"promptRef,omitempty"`
+	Tools        []ToolReference        `json:"tools"`
+	Labels       map[string]string      `json:"labels"`
+	Enabled      bool                   `json:"enabled"`
+	Version      int32                  `json:"version"`
+	CreatedAt    time.Time              `json:"createdAt"`
+	UpdatedAt    time.Time              `json:"updatedAt"`
+	// Set on deleted agents, which can be restored until they are purged
+	DeletedAt *time.Time `json:"deletedAt,omitempty"`
+	// Version of the framework schema modelConfig was validated against, omitted for agents stored without validation
+	ConfigSchemaVersion *int32 `json:"configSchemaVersion,omitempty"`
+	// Days the traces of the agent are kept, omitted for agents following the retention of the traces observer
+	RetentionDays *int32 `json:"retentionDays,omitempty"`
+}
+
+type ManagedAgentListResponse struct {
+	Agents []ManagedAgentResponse `json:"agents"`
+	// Total of the listing, only counted when the request asks for it
+	Total  *int32 `json:"total,omitempty"`
+	Limit  int32  `json:"limit"`
+	Offset int32  `json:"offset"`
+	// Cursor of the next page, empty on the last page
+	NextCursor string `json:"nextCursor,omitempty"`
+}
+
+// ManagedAgentLabelsResponse tells internal services such as the traces observer which organization an agent
+// belongs to, how it is labelled and how long its traces are kept
+type ManagedAgentLabelsResponse struct {
+	AgentID       string            `json:"agentId"`
+	OrgID         string            `json:"orgId"`
+	Labels        map[string]string `json:"labels"`
+	RetentionDays *int32            `json:"retentionDays,omitempty"`
+}
+
+// ManagedAgentLabelsListResponse lists the agents an observed agent name may refer to
+type ManagedAgentLabelsListResponse struct {
+	Agents []ManagedAgentLabelsResponse `json:"agents"`
+}
+
+// API Response DTO
+type ManagedAgentVersionSummary struct {
+	Version int32 `json:"version"`
+	// IdP id of the user who created the version, empty for versions recorded before authors were tracked
+	Author string `json:"author,omitempty"`
+	// Version whose configuration was restored to create this version
+	RolledBackFrom *int32    `json:"rolledBackFrom,omitempty"`
+	CreatedAt      time.Time `json:"createdAt"`
+}
+
+type ManagedAgentVersionListResponse struct {
+	ActiveVersion int32                        `json:"activeVersion"`
+	Versions      []ManagedAgentVersionSummary `json:"versions"`
+	Total         int32                        `json:"total"`
+	Limit         int32                        `json:"limit"`
+	Offset        int32                        `json:"offset"`
+}
+
+type ManagedAgentVersionResponse struct {
+	ManagedAgentVersionSummary
+	AgentID string              `json:"agentId"`
+	Config  ManagedAgentRequest `json:"config"`
+}
+
+// ConfigChange is a single difference between two agent configurations
+type ConfigChange struct {
+	// Path of the changed field, e.g. modelConfig.temperature, tools[1].name or labels.team
+	Path string `json:"path"`
+	// One of added, removed or changed
+	Type string      `json:"type"`
+	From interface{} `json:"from,omitempty"`
+	To   interface{} `json:"to,omitempty"`
+}
+
+type ManagedAgentVersionDiffResponse struct {
+	FromVersion int32          `json:"fromVersion"`
+	ToVersion   int32          `json:"toVersion"`
+	Changes     []ConfigChange `json:"changes"`
+}
+
+// ManagedAgentFilter narrows down a managed agent listing
+type ManagedAgentFilter struct {
+	// Case-insensitive substring of the agent name
+	Search string
+	// Label requirements the agent must meet
+	Selector LabelSelector
+	// List deleted agents along with the others
+	IncludeDeleted bool
+	Page           ListPage
+}
+
+// DB Model
+type ManagedAgent struct {
+	ID            uuid.UUID              `gorm:"column:id;primaryKey"`
+	OrgID         uuid.UUID              `gorm:"column:org_id"`
+	Name          string                 `gorm:"column:name"`
+	Description   string                 `gorm:"column:description"`
+	Framework     string                 `gorm:"column:framework"`
+	ModelConfig   map[string]interface{} `gorm:"column:model_config;type:jsonb;serializer:json"`
+	SystemPrompt  string                 `gorm:"column:system_prompt"`
+	PromptID      *uuid.UUID             `gorm:"column:prompt_id"`
+	PromptVersion *int32                 `gorm:"column:prompt_version"`
+	Tools         []ToolReference        `gorm:"column:tools;type:jsonb;serializer:json"`
+	Labels        map[string]string      `gorm:"column:labels;type:jsonb;serializer:json"`
+	Enabled       bool                   `gorm:"column:enabled"`
+	Version       int32                  `gorm:"column:version"`
+	CreatedAt     time.Time              `gorm:"column:created_at"`
+	UpdatedAt     time.Time              `gorm:"column:updated_at"`
+	DeletedAt     gorm.DeletedAt         `gorm:"column:deleted_at"`
+	// Nil for agents stored without validation
+	ConfigSchemaVersion *int32 `gorm:"column:config_schema_version"`
+	// Nil for agents following the retention of the traces observer
+	RetentionDays *int32 `gorm:"column:retention_days"`
+}
+
+// DB Model of an immutable snapshot of a managed agent configuration
+type ManagedAgentVersion struct {
+	AgentID        uuid.UUID              `gorm:"column:agent_id;primaryKey"`
+	Version        int32                  `gorm:"column:version;primaryKey"`
+	Name           string                 `gorm:"column:name"`
+	Description    string                 `gorm:"column:description"`
+	Framework      string                 `gorm:"column:framework"`
+	ModelConfig    map[string]interface{} `gorm:"column:model_config;type:jsonb;serializer:json"`
+	SystemPrompt   string                 `gorm:"column:system_prompt"`
+	PromptID       *uuid.UUID             `gorm:"column:prompt_id"`
+	PromptVersion  *int32                 `gorm:"column:prompt_version"`
+	Tools          []ToolReference        `gorm:"column:tools;type:jsonb;serializer:json"`
+	Labels         map[string]string      `gorm:"column:labels;type:jsonb;serializer:json"`
+	Enabled        bool                   `gorm:"column:enabled"`
+	RolledBackFrom *int32                 `gorm:"column:rolled_back_from"`
+	CreatedBy      *uuid.UUID             `gorm:"column:created_by"`
+	CreatedAt      time.Time              `gorm:"column:created_at"`
+	// Nil for versions stored without validation
+	ConfigSchemaVersion *int32 `gorm:"column:config_schema_version"`
+}
@@ -0,0 +1,62 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package models
+
+// API Request DTO
+type AgentBatchRequest struct {
+	Operations []AgentBatchOperation `json:"operations"`
+	// Report the outcome of every operation without applying any
+	DryRun bool `json:"dryRun,omitempty"`
+}
+
+// AgentBatchOperation is a change to a single managed agent
+type AgentBatchOperation struct {
+	AgentID string `json:"agentId"`
+	// One of enable, disable, addLabels, removeLabels, setModel or delete
+	Action string `json:"action"`
+	// Labels set by addLabels, replacing the values of existing keys
+	Labels map[string]string `json:"labels,omitempty"`
+	// Keys of the labels removed by removeLabels
+	LabelKeys []string `json:"labelKeys,omitempty"`
+	// Model settings merged into the model configuration by setModel, null removes a setting
+	ModelConfig map[string]interface{} `json:"modelConfig,omitempty"`
+}
+
+// AgentBatchResult is the outcome of an operation of a batch
+type AgentBatchResult struct {
+	AgentID string `json:"agentId"`
+	Action  string `json:"action"`
+	// One of succeeded or failed
+	Status string `json:"status"`
+	// Status the operation would have been answered with on its own
+	StatusCode int `json:"statusCode"`
+	// Why the operation failed
+	Error string `json:"error,omitempty"`
+	// Version of the agent after the operation, not set for deletes
+	Version int32 `json:"version,omitempty"`
+	// Configuration changes the operation made, empty when the agent already was in the requested state
+	Changes []ConfigChange `json:"changes,omitempty"`
+}
+
+// API Response DTO
+type AgentBatchResponse struct {
+	DryRun    bool `json:"dryRun"`
+	Succeeded int  `json:"succeeded"`
+	Failed    int  `json:"failed"`
+	// Outcome of every operation, in the order of the request
+	Results []AgentBatchResult `json:"results"`
+}
@@ -0,0 +1,137 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// API Request DTO
+type PromptTemplateRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	// Prompt text with {{variable_name}} placeholders
+	Template  string            `json:"template"`
+	Variables []PromptVariable  `json:"variables,omitempty"`
+	Labels    map[string]string `json:"labels,omitempty"`
+	// Version the update is based on, an alternative to the If-Match header that is ignored on create
+	Version *int32 `json:"version,omitempty"`
+}
+
+// PromptVariable documents a placeholder of a prompt template, placeholders without a default must be supplied when rendering
+type PromptVariable struct {
+	Name        string  `json:"name"`
+	Description string  `json:"description,omitempty"`
+	Default     *string `json:"default,omitempty"`
+}
+
+// API Response DTO
+type PromptTemplateResponse struct {
+	ID          string            `json:"id"`
+	Name        string            `json:"name"`
+	Description string            `json:"description,omitempty"`
+	Template    string            `json:"template"`
+	Variables   []PromptVariable  `json:"variables"`
+	Labels      map[string]string `json:"labels"`
+	Version     int32             `json:"version"`
+	CreatedAt   time.Time         `json:"createdAt"`
+	UpdatedAt   time.Time         `json:"updatedAt"`
+	// Set on deleted prompts, which can be restored until they are purged
+	DeletedAt *time.Time `json:"deletedAt,omitempty"`
+}
+
+type PromptTemplateListResponse struct {
+	Prompts []PromptTemplateResponse `json:"prompts"`
+	// Total of the listing, only counted when the request asks for it
+	Total  *int32 `json:"total,omitempty"`
+	Limit  int32  `json:"limit"`
+	Offset int32  `json:"offset"`
+	// Cursor of the next page, empty on the last page
+	NextCursor string `json:"nextCursor,omitempty"`
+}
+
+// PromptTemplateFilter narrows down a prompt listing
+type PromptTemplateFilter struct {
+	// Case-insensitive substring of the prompt name
+	Search string
+	// Label requirements the prompt must meet
+	Selector LabelSelector
+	// List deleted prompts along with the others
+	IncludeDeleted bool
+	Page           ListPage
+}
+
+type PromptTemplateVersionResponse struct {
+	PromptID    string           `json:"promptId"`
+	Version     int32            `json:"version"`
+	Description string           `json:"description,omitempty"`
+	Template    string           `json:"template"`
+	Variables   []PromptVariable `json:"variables"`
+	// IdP id of the user who created the version
+	Author    string    `json:"author,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type PromptTemplateVersionListResponse struct {
+	Versions []PromptTemplateVersionResponse `json:"versions"`
+	Total    int32                           `json:"total"`
+	Limit    int32                           `json:"limit"`
+	Offset   int32                           `json:"offset"`
+}
+
+// API Request DTO
+type PromptRenderRequest struct {
+	Variables map[string]string `json:"variables"`
+	// Version to render, the latest version when omitted
+	Version *int32 `json:"version,omitempty"`
+}
+
+// API Response DTO
+type PromptRenderResponse struct {
+	PromptID string `json:"promptId"`
+	Version  int32  `json:"version"`
+	Text     string `json:"text"`
+}
+
+// DB Model
+type PromptTemplate struct {
+	ID          uuid.UUID         `gorm:"column:id;primaryKey"`
+	OrgID       uuid.UUID         `gorm:"column:org_id"`
+	Name        string            `gorm:"column:name"`
+	Description string            `gorm:"column:description"`
+	Template    string            `gorm:"column:template"`
+	Variables   []PromptVariable  `gorm:"column:variables;type:jsonb;serializer:json"`
+	Labels      map[string]string `gorm:"column:labels;type:jsonb;serializer:json"`
+	Version     int32             `gorm:"column:version"`
+	CreatedAt   time.Time         `gorm:"column:created_at"`
+	UpdatedAt   time.Time         `gorm:"column:updated_at"`
+	DeletedAt   gorm.DeletedAt    `gorm:"column:deleted_at"`
+}
+
+// DB Model of an immutable snapshot of a prompt template
+type PromptTemplateVersion struct {
+	PromptID    uuid.UUID        `gorm:"column:prompt_id;primaryKey"`
+	Version     int32            `gorm:"column:version;primaryKey"`
+	Description string           `gorm:"column:description"`
+	Template    string           `gorm:"column:template"`
+	Variables   []PromptVariable `gorm:"column:variables;type:jsonb;serializer:json"`
+	CreatedBy   *uuid.UUID       `gorm:"column:created_by"`
+	CreatedAt   time.Time        `gorm:"column:created_at"`
+}
@@ -0,0 +1,122 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/clients/traceobserversvc"
+)
+
+// API Request DTO
+type ToolRequest struct {
+	// Name the model calls the tool by
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	// JSON Schema (draft 2020-12) of the tool arguments
+	Parameters map[string]interface{} `json:"parameters"`
+	Endpoint   ToolEndpoint           `json:"endpoint"`
+	Tags       []string               `json:"tags,omitempty"`
+	Labels     map[string]string      `json:"labels,omitempty"`
+	// Version the update is based on, an alternative to the If-Match header that is ignored on create
+	Version *int32 `json:"version,omitempty"`
+}
+
+// ToolEndpoint describes how a tool call is executed
+type ToolEndpoint struct {
+	// One of http, mcp or function
+	Type string `json:"type"`
+	// URL of http and mcp tools
+	URL string `json:"url,omitempty"`
+	// HTTP method of http tools, POST when omitted
+	Method string `json:"method,omitempty"`
+	// Credential http and mcp tools authenticate with, none when omitted
+	Auth *ToolEndpointAuth `json:"auth,omitempty"`
+}
+
+// ToolEndpointAuth references the credential the runtime sends with tool calls
+type ToolEndpointAuth struct {
+	CredentialID string `json:"credentialId"`
+	// Request header carrying the secret, Authorization when omitted
+	Header string `json:"header,omitempty"`
+}
+
+// API Response DTO
+type ToolResponse struct {
+	ID          string                 `json:"id"`
+	Name        string                 `json:"name"`
+	Description string                 `json:"description,omitempty"`
+	Parameters  map[string]interface{} `json:"parameters"`
+	Endpoint    ToolEndpoint           `json:"endpoint"`
+	Tags        []string               `json:"tags"`
+	Labels      map[string]string      `json:"labels"`
+	Version     int32                  `json:"version"`
+	CreatedAt   time.Time              `json:"createdAt"`
+	UpdatedAt   time.Time              `json:"updatedAt"`
+}
+
+type ToolListResponse struct {
+	Tools []ToolResponse `json:"tools"`
+	// Total of the listing, only counted when the request asks for it
+	Total  *int32 `json:"total,omitempty"`
+	Limit  int32  `json:"limit"`
+	Offset int32  `json:"offset"`
+	// Cursor of the next page, empty on the last page
+	NextCursor string `json:"nextCursor,omitempty"`
+}
+
+// AgentToolDefinition is a tool of an agent in the shape the traces observer extracts from spans,
+// so declared tools can be matched against observed tool calls by name
+type AgentToolDefinition struct {
+	traceobserversvc.ToolDefinition
+	// Registry id of the tool, empty for tools declared by name only
+	ToolID string   `json:"toolId,omitempty"`
+	Tags   []string `json:"tags,omitempty"`
+}
+
+type AgentToolsResponse struct {
+	AgentID string                `json:"agentId"`
+	Tools   []AgentToolDefinition `json:"tools"`
+}
+
+// ToolFilter narrows down a tool listing
+type ToolFilter struct {
+	// Case-insensitive substring of the tool name
+	Search string
+	// Tags the tool must carry
+	Tags []string
+	// Label requirements the tool must meet
+	Selector LabelSelector
+	Page     ListPage
+}
+
+// DB Model
+type Tool struct {
+	ID          uuid.UUID              `gorm:"column:id;primaryKey"`
+	OrgID       uuid.UUID              `gorm:"column:org_id"`
+	Name        string                 `gorm:"column:name"`
+	Description string                 `gorm:"column:description"`
+	Parameters  map[string]interface{} `gorm:"column:parameters;type:jsonb;serializer:json"`
+	Endpoint    ToolEndpoint           `gorm:"column:endpoint;type:jsonb;serializer:json"`
+	Tags        []string               `gorm:"column:tags;type:jsonb;serializer:json"`
+	Labels      map[string]string      `gorm:"column:labels;type:jsonb;serializer:json"`
+	Version     int32                  `gorm:"column:version"`
+	CreatedAt   time.Time              `gorm:"column:created_at"`
+	UpdatedAt   time.Time              `gorm:"column:updated_at"`
+}
@@ -0,0 +1,67 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// API Request DTO
+type TraceViewRequest struct {
+	Name string `json:"name"`
+	// private or org, private when omitted
+	Visibility string `json:"visibility,omitempty"`
+	// Trace list filters by query parameter
+	Filters   map[string]string `json:"filters,omitempty"`
+	Sort      string            `json:"sort,omitempty"`
+	SortOrder string            `json:"sortOrder,omitempty"`
+}
+
+// API Response DTO
+type TraceViewResponse struct {
+	ID         string            `json:"id"`
+	Name       string            `json:"name"`
+	Visibility string            `json:"visibility"`
+	Filters    map[string]string `json:"filters"`
+	Sort       string            `json:"sort,omitempty"`
+	SortOrder  string            `json:"sortOrder,omitempty"`
+	// IdP id of the user who saved the view
+	CreatedBy string    `json:"createdBy"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type TraceViewListResponse struct {
+	Views  []TraceViewResponse `json:"views"`
+	Total  int32               `json:"total"`
+	Limit  int32               `json:"limit"`
+	Offset int32               `json:"offset"`
+}
+
+// DB Model
+type TraceView struct {
+	ID         uuid.UUID         `gorm:"column:id;primaryKey"`
+	OrgID      uuid.UUID         `gorm:"column:org_id"`
+	CreatedBy  uuid.UUID         `gorm:"column:created_by"`
+	Name       string            `gorm:"column:name"`
+	Visibility string            `gorm:"column:visibility"`
+	Filters    map[string]string `gorm:"column:filters;type:jsonb;serializer:json"`
+	Sort       string            `gorm:"column:sort"`
+	SortOrder  string            `gorm:"column:sort_order"`
+	CreatedAt  time.Time         `gorm:"column:created_at"`
+}
@@ -101,9 +101,26 @@ type ToolCall struct {
 
 // ToolDefinition represents a tool/function available to the LLM
 type ToolDefinition struct {
-	Name        string `json:"name"`                  // Function name
-	Description string `json:"description,omitempty"` // Function description
-	Parameters  string `json:"parameters,omitempty"`  // JSON schema of parameters
+	Name            string          `json:"name"`                      // Function name
+	Description     string          `json:"description,omitempty"`     // Function description
+	Parameters      string          `json:"parameters,omitempty"`      // JSON schema of parameters
+	ParameterSchema *ToolParameters `json:"parameterSchema,omitempty"` // Parameters parsed from the schema
+}
+
+// ToolParameters is the parsed parameter schema of a tool
+type ToolParameters struct {
+	Properties []ToolParameter `json:"properties"`          // Parameters in the order the schema declares them
+	Truncated  bool            `json:"truncated,omitempty"` // Parameters past the size limit were dropped
+}
+
+// ToolParameter is a parameter of a tool
+type ToolParameter struct {
+	Name        string        `json:"name"`
+	Type        string        `json:"type,omitempty"` // JSON schema type, e.g. "string", "array<string>" or "string | null"
+	Description string        `json:"description,omitempty"`
+	Required    bool          `json:"required"`
+	Default     interface{}   `json:"default,omitempty"`
+	Enum        []interface{} `json:"enum,omitempty"`
 }
 
 // SpanEvent represents an event within a span (for future use)
@@ -126,3 +143,38 @@ type TraceResponse struct {
 	TokenUsage *TokenUsage  `json:"tokenUsage,omitempty"` // Aggregated token usage from GenAI spans
 	Status     *TraceStatus `json:"status,omitempty"`     // Trace status including error information
 }
+
+// AgentMetrics holds the latency, error and cost metrics of the traces linked to a managed agent
+type AgentMetrics struct {
+	TraceCount    int                `json:"traceCount"`
+	ErrorCount    int                `json:"errorCount"` // Traces with at least one failed span
+	ErrorRate     float64            `json:"errorRate"`
+	DurationNanos LatencyPercentiles `json:"durationNanos"` // Trace duration percentiles
+	InputTokens   int                `json:"inputTokens"`
+	OutputTokens  int                `json:"outputTokens"`
+	TotalTokens   int                `json:"totalTokens"`
+	Cost          float64            `json:"cost"` // Cost of priced models in USD
+}
+
+// LatencyPercentiles holds the p50, p95 and p99 of a duration
+type LatencyPercentiles struct {
+	P50 int64 `json:"p50"`
+	P95 int64 `json:"p95"`
+	P99 int64 `json:"p99"`
+}
+
+// AgentMetricsPoint holds the metrics of the traces that started in a time bucket
+type AgentMetricsPoint struct {
+	Timestamp time.Time `json:"timestamp"`
+	AgentMetrics
+}
+
+// AgentMetricsResponse summarizes the traces of a managed agent over a time range and per interval of it
+type AgentMetricsResponse struct {
+	AgentID   string              `json:"agentId"`
+	Interval  string              `json:"interval"`
+	StartTime time.Time           `json:"startTime"`
+	EndTime   time.Time           `json:"endTime"`
+	Summary   AgentMetrics        `json:"summary"`
+	Points    []AgentMetricsPoint `json:"points"`
+}
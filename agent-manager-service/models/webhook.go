@@ -0,0 +1,134 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// API Request DTO
+type WebhookRequest struct {
+	// Absolute http or https URL events are POSTed to
+	URL         string `json:"url"`
+	Description string `json:"description,omitempty"`
+	// Key the payloads are signed with, required on create, an update without it keeps the current secret
+	Secret string `json:"secret,omitempty"`
+	// Event types delivered to the webhook
+	Events []string `json:"events"`
+	// Inactive webhooks receive no events, true when omitted on create and unchanged when omitted on update
+	Active *bool `json:"active,omitempty"`
+}
+
+// API Response DTO, the secret is never returned
+type WebhookResponse struct {
+	ID          string    `json:"id"`
+	URL         string    `json:"url"`
+	Description string    `json:"description,omitempty"`
+	Events      []string  `json:"events"`
+	Active      bool      `json:"active"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+type WebhookListResponse struct {
+	Webhooks []WebhookResponse `json:"webhooks"`
+	// Total of the listing, only counted when the request asks for it
+	Total  *int32 `json:"total,omitempty"`
+	Limit  int32  `json:"limit"`
+	Offset int32  `json:"offset"`
+	// Cursor of the next page, empty on the last page
+	NextCursor string `json:"nextCursor,omitempty"`
+}
+
+// WebhookEvent is the JSON body POSTed to webhooks
+type WebhookEvent struct {
+	ID         string      `json:"id"`
+	Type       string      `json:"type"`
+	OccurredAt time.Time   `json:"occurredAt"`
+	OrgName    string      `json:"orgName"`
+	Data       interface{} `json:"data"`
+}
+
+// WebhookDeliveryAttempt is one try to deliver an event
+type WebhookDeliveryAttempt struct {
+	AttemptedAt time.Time `json:"attemptedAt"`
+	// Status code the webhook answered with, 0 when no response was received
+	StatusCode int    `json:"statusCode,omitempty"`
+	Error      string `json:"error,omitempty"`
+	DurationMs int64  `json:"durationMs"`
+}
+
+type WebhookDeliveryResponse struct {
+	ID        string `json:"id"`
+	EventID   string `json:"eventId"`
+	EventType string `json:"eventType"`
+	// One of pending, succeeded or failed
+	Status   string                   `json:"status"`
+	Attempts []WebhookDeliveryAttempt `json:"attempts"`
+	// Next try of a pending delivery
+	NextAttemptAt *time.Time `json:"nextAttemptAt,omitempty"`
+	// Delivery this one redelivers
+	RedeliveryOf *string         `json:"redeliveryOf,omitempty"`
+	Payload      json.RawMessage `json:"payload"`
+	CreatedAt    time.Time       `json:"createdAt"`
+	CompletedAt  *time.Time      `json:"completedAt,omitempty"`
+}
+
+type WebhookDeliveryListResponse struct {
+	Deliveries []WebhookDeliveryResponse `json:"deliveries"`
+	Total      int32                     `json:"total"`
+	Limit      int32                     `json:"limit"`
+	Offset     int32                     `json:"offset"`
+}
+
+// DB Model
+type Webhook struct {
+	ID              uuid.UUID  `gorm:"column:id;primaryKey"`
+	OrgID           uuid.UUID  `gorm:"column:org_id"`
+	URL             string     `gorm:"column:url"`
+	Description     string     `gorm:"column:description"`
+	Events          []string   `gorm:"column:events;type:jsonb;serializer:json"`
+	Active          bool       `gorm:"column:active"`
+	KeyID           string     `gorm:"column:key_id"`
+	EncryptedKey    []byte     `gorm:"column:encrypted_key"`
+	EncryptedSecret []byte     `gorm:"column:encrypted_secret"`
+	CreatedBy       *uuid.UUID `gorm:"column:created_by"`
+	CreatedAt       time.Time  `gorm:"column:created_at"`
+	UpdatedAt       time.Time  `gorm:"column:updated_at"`
+}
+
+// DB Model
+type WebhookDelivery struct {
+	ID            uuid.UUID                `gorm:"column:id;primaryKey"`
+	WebhookID     uuid.UUID                `gorm:"column:webhook_id"`
+	EventID       uuid.UUID                `gorm:"column:event_id"`
+	EventType     string                   `gorm:"column:event_type"`
+	Payload       []byte                   `gorm:"column:payload"`
+	Status        string                   `gorm:"column:status"`
+	Attempts      []WebhookDeliveryAttempt `gorm:"column:attempts;type:jsonb;serializer:json"`
+	NextAttemptAt *time.Time               `gorm:"column:next_attempt_at"`
+	RedeliveryOf  *uuid.UUID               `gorm:"column:redelivery_of"`
+	CreatedAt     time.Time                `gorm:"column:created_at"`
+	CompletedAt   *time.Time               `gorm:"column:completed_at"`
+}
+
+func (WebhookDelivery) TableName() string {
+	return "webhook_deliveries"
+}
@@ -0,0 +1,116 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/db"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/models"
+)
+
+type AgentBudgetRepository interface {
+	GetAgentBudget(ctx context.Context, orgId uuid.UUID, agentId uuid.UUID) (*models.AgentBudget, error)
+	// SaveAgentBudget creates the budget of an agent or replaces its limits, thresholds and enforcement.
+	// The alerts raised for the budget are cleared, so the new limits are alerted on from scratch
+	SaveAgentBudget(ctx context.Context, budget *models.AgentBudget) error
+	// DeleteAgentBudget removes the budget together with its alerts and reports whether it existed
+	DeleteAgentBudget(ctx context.Context, orgId uuid.UUID, agentId uuid.UUID) (bool, error)
+	// ListAgentBudgets returns the budgets of the agents that are not deleted in every organization, with the agent names
+	ListAgentBudgets(ctx context.Context) ([]*models.AgentBudget, error)
+	// RecordAgentBudgetAlert records a threshold reached in a period and reports whether it was not recorded before
+	RecordAgentBudgetAlert(ctx context.Context, alert *models.AgentBudgetAlert) (bool, error)
+	// PurgeAgentBudgetAlerts removes the alerts of the periods started before the given time
+	PurgeAgentBudgetAlerts(ctx context.Context, periodStartBefore time.Time) (int64, error)
+}
+
+type agentBudgetRepository struct{}
+
+func NewAgentBudgetRepository() AgentBudgetRepository {
+	return &agentBudgetRepository{}
+}
+
+func (r *agentBudgetRepository) GetAgentBudget(ctx context.Context, orgId uuid.UUID, agentId uuid.UUID) (*models.AgentBudget, error) {
+	var budget models.AgentBudget
+	if err := db.DB(ctx).Where("org_id = ? AND agent_id = ?", orgId, agentId).First(&budget).Error; err != nil {
+		return nil, fmt.Errorf("agentBudgetRepository.GetAgentBudget: %w", err)
+	}
+	return &budget, nil
+}
+
+func (r *agentBudgetRepository) SaveAgentBudget(ctx context.Context, budget *models.AgentBudget) error {
+	err := db.DB(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "agent_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"timezone", "daily_max_cost", "daily_max_tokens", "monthly_max_cost",
+				"monthly_max_tokens", "alert_thresholds", "enforcement", "updated_at"}),
+		}).Create(budget).Error; err != nil {
+			return err
+		}
+		// The stored row keeps its creation time when it is replaced
+		if err := tx.Where("agent_id = ?", budget.AgentID).First(budget).Error; err != nil {
+			return err
+		}
+		return tx.Where("agent_id = ?", budget.AgentID).Delete(&models.AgentBudgetAlert{}).Error
+	})
+	if err != nil {
+		return fmt.Errorf("agentBudgetRepository.SaveAgentBudget: %w", err)
+	}
+	return nil
+}
+
+func (r *agentBudgetRepository) DeleteAgentBudget(ctx context.Context, orgId uuid.UUID, agentId uuid.UUID) (bool, error) {
+	result := db.DB(ctx).Where("org_id = ? AND agent_id = ?", orgId, agentId).Delete(&models.AgentBudget{})
+	if result.Error != nil {
+		return false, fmt.Errorf("agentBudgetRepository.DeleteAgentBudget: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *agentBudgetRepository) ListAgentBudgets(ctx context.Context) ([]*models.AgentBudget, error) {
+	var budgets []*models.AgentBudget
+	if err := db.DB(ctx).
+		Select("agent_budgets.*, managed_agents.name AS agent_name").
+		Joins("JOIN managed_agents ON managed_agents.id = agent_budgets.agent_id AND managed_agents.deleted_at IS NULL").
+		Order("agent_budgets.agent_id ASC").
+		Find(&budgets).Error; err != nil {
+		return nil, fmt.Errorf("agentBudgetRepository.ListAgentBudgets: %w", err)
+	}
+	return budgets, nil
+}
+
+func (r *agentBudgetRepository) RecordAgentBudgetAlert(ctx context.Context, alert *models.AgentBudgetAlert) (bool, error) {
+	result := db.DB(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(alert)
+	if result.Error != nil {
+		return false, fmt.Errorf("agentBudgetRepository.RecordAgentBudgetAlert: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *agentBudgetRepository) PurgeAgentBudgetAlerts(ctx context.Context, periodStartBefore time.Time) (int64, error) {
+	result := db.DB(ctx).Where("period_start < ?", periodStartBefore).Delete(&models.AgentBudgetAlert{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("agentBudgetRepository.PurgeAgentBudgetAlerts: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
@@ -0,0 +1,118 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package repositories
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/db"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/models"
+)
+
+type AgentDeploymentRepository interface {
+	CreateAgentDeployment(ctx context.Context, deployment *models.AgentDeployment) error
+	// ListAgentDeployments lists the deployments of an agent newest first, only those to one environment when environmentId is set
+	ListAgentDeployments(ctx context.Context, agentId uuid.UUID, environmentId *uuid.UUID, limit int, offset int) ([]*models.AgentDeployment, int64, error)
+	// GetCurrentAgentDeployment returns the latest deployment of an agent to an environment
+	GetCurrentAgentDeployment(ctx context.Context, agentId uuid.UUID, environmentId uuid.UUID) (*models.AgentDeployment, error)
+	// ListEnvironmentAgents returns the current deployment of every agent deployed to an environment by agent name,
+	// deleted agents are left out
+	ListEnvironmentAgents(ctx context.Context, environmentId uuid.UUID, limit int, offset int) ([]*models.EnvironmentAgent, int64, error)
+}
+
+type agentDeploymentRepository struct{}
+
+func NewAgentDeploymentRepository() AgentDeploymentRepository {
+	return &agentDeploymentRepository{}
+}
+
+// deploymentsWithEnvironment reads deployments together with the name of their environment
+func deploymentsWithEnvironment(ctx context.Context) *gorm.DB {
+	return db.DB(ctx).Model(&models.AgentDeployment{}).
+		Select("agent_deployments.*, agent_environments.name AS environment").
+		Joins("JOIN agent_environments ON agent_environments.id = agent_deployments.environment_id")
+}
+
+func (r *agentDeploymentRepository) CreateAgentDeployment(ctx context.Context, deployment *models.AgentDeployment) error {
+	if err := db.DB(ctx).Create(deployment).Error; err != nil {
+		return fmt.Errorf("agentDeploymentRepository.CreateAgentDeployment: %w", err)
+	}
+	return nil
+}
+
+func (r *agentDeploymentRepository) ListAgentDeployments(ctx context.Context, agentId uuid.UUID, environmentId *uuid.UUID, limit int, offset int) ([]*models.AgentDeployment, int64, error) {
+	query := db.DB(ctx).Model(&models.AgentDeployment{}).Where("agent_id = ?", agentId)
+	if environmentId != nil {
+		query = query.Where("environment_id = ?", *environmentId)
+	}
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("agentDeploymentRepository.ListAgentDeployments: %w", err)
+	}
+
+	listQuery := deploymentsWithEnvironment(ctx).Where("agent_deployments.agent_id = ?", agentId)
+	if environmentId != nil {
+		listQuery = listQuery.Where("agent_deployments.environment_id = ?", *environmentId)
+	}
+	var deployments []*models.AgentDeployment
+	if err := listQuery.
+		Order("agent_deployments.deployed_at DESC, agent_deployments.id DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&deployments).Error; err != nil {
+		return nil, 0, fmt.Errorf("agentDeploymentRepository.ListAgentDeployments: %w", err)
+	}
+	return deployments, total, nil
+}
+
+func (r *agentDeploymentRepository) GetCurrentAgentDeployment(ctx context.Context, agentId uuid.UUID, environmentId uuid.UUID) (*models.AgentDeployment, error) {
+	var deployment models.AgentDeployment
+	if err := deploymentsWithEnvironment(ctx).
+		Where("agent_deployments.agent_id = ? AND agent_deployments.environment_id = ?", agentId, environmentId).
+		Order("agent_deployments.deployed_at DESC, agent_deployments.id DESC").
+		First(&deployment).Error; err != nil {
+		return nil, fmt.Errorf("agentDeploymentRepository.GetCurrentAgentDeployment: %w", err)
+	}
+	return &deployment, nil
+}
+
+func (r *agentDeploymentRepository) ListEnvironmentAgents(ctx context.Context, environmentId uuid.UUID, limit int, offset int) ([]*models.EnvironmentAgent, int64, error) {
+	current := db.DB(ctx).Table("agent_deployments d").
+		Select("DISTINCT ON (d.agent_id) d.*, e.name AS environment, a.name AS agent_name").
+		Joins("JOIN agent_environments e ON e.id = d.environment_id").
+		Joins("JOIN managed_agents a ON a.id = d.agent_id AND a.deleted_at IS NULL").
+		Where("d.environment_id = ?", environmentId).
+		Order("d.agent_id, d.deployed_at DESC, d.id DESC")
+
+	var total int64
+	if err := db.DB(ctx).Table("(?) AS current", current).Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("agentDeploymentRepository.ListEnvironmentAgents: %w", err)
+	}
+	var agents []*models.EnvironmentAgent
+	if err := db.DB(ctx).Table("(?) AS current", current).
+		Order("agent_name ASC").
+		Limit(limit).
+		Offset(offset).
+		Find(&agents).Error; err != nil {
+		return nil, 0, fmt.Errorf("agentDeploymentRepository.ListEnvironmentAgents: %w", err)
+	}
+	return agents, total, nil
+}
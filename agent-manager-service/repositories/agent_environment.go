@@ -0,0 +1,62 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package repositories
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/db"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/models"
+)
+
+type AgentEnvironmentRepository interface {
+	ListAgentEnvironments(ctx context.Context, orgId uuid.UUID) ([]*models.AgentEnvironment, error)
+	GetAgentEnvironmentByName(ctx context.Context, orgId uuid.UUID, name string) (*models.AgentEnvironment, error)
+	CreateAgentEnvironment(ctx context.Context, environment *models.AgentEnvironment) error
+}
+
+type agentEnvironmentRepository struct{}
+
+func NewAgentEnvironmentRepository() AgentEnvironmentRepository {
+	return &agentEnvironmentRepository{}
+}
+
+func (r *agentEnvironmentRepository) ListAgentEnvironments(ctx context.Context, orgId uuid.UUID) ([]*models.AgentEnvironment, error) {
+	var environments []*models.AgentEnvironment
+	if err := db.DB(ctx).Where("org_id = ?", orgId).Order("name ASC").Find(&environments).Error; err != nil {
+		return nil, fmt.Errorf("agentEnvironmentRepository.ListAgentEnvironments: %w", err)
+	}
+	return environments, nil
+}
+
+func (r *agentEnvironmentRepository) GetAgentEnvironmentByName(ctx context.Context, orgId uuid.UUID, name string) (*models.AgentEnvironment, error) {
+	var environment models.AgentEnvironment
+	if err := db.DB(ctx).Where("org_id = ? AND name = ?", orgId, name).First(&environment).Error; err != nil {
+		return nil, fmt.Errorf("agentEnvironmentRepository.GetAgentEnvironmentByName: %w", err)
+	}
+	return &environment, nil
+}
+
+func (r *agentEnvironmentRepository) CreateAgentEnvironment(ctx context.Context, environment *models.AgentEnvironment) error {
+	if err := db.DB(ctx).Create(environment).Error; err != nil {
+		return fmt.Errorf("agentEnvironmentRepository.CreateAgentEnvironment: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/db"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/models"
+)
+
+type APIKeyRepository interface {
+	// ListAPIKeys returns the keys of the user in the organization that are not revoked
+	ListAPIKeys(ctx context.Context, orgId uuid.UUID, userIdpId uuid.UUID, limit int, offset int) ([]*models.APIKey, int64, error)
+	GetAPIKeyByName(ctx context.Context, orgId uuid.UUID, userIdpId uuid.UUID, name string) (*models.APIKey, error)
+	// GetAPIKeyByHash returns the key with the given hash, revoked keys included
+	GetAPIKeyByHash(ctx context.Context, keyHash string) (*models.APIKey, error)
+	CreateAPIKey(ctx context.Context, apiKey *models.APIKey) error
+	// RevokeAPIKey revokes a key of the user and reports whether there was an unrevoked key to revoke
+	RevokeAPIKey(ctx context.Context, orgId uuid.UUID, userIdpId uuid.UUID, keyId uuid.UUID, revokedAt time.Time) (bool, error)
+	// UpdateLastUsedAt moves the last use of a key forward, an older timestamp leaves it untouched
+	UpdateLastUsedAt(ctx context.Context, keyId uuid.UUID, lastUsedAt time.Time) error
+}
+
+type apiKeyRepository struct{}
+
+func NewAPIKeyRepository() APIKeyRepository {
+	return &apiKeyRepository{}
+}
+
+func (r *apiKeyRepository) ListAPIKeys(ctx context.Context, orgId uuid.UUID, userIdpId uuid.UUID, limit int, offset int) ([]*models.APIKey, int64, error) {
+	query := db.DB(ctx).Model(&models.APIKey{}).Where("org_id = ? AND user_idp_id = ? AND revoked_at IS NULL", orgId, userIdpId)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("apiKeyRepository.ListAPIKeys: %w", err)
+	}
+
+	var apiKeys []*models.APIKey
+	if err := query.
+		Order("created_at DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&apiKeys).Error; err != nil {
+		return nil, 0, fmt.Errorf("apiKeyRepository.ListAPIKeys: %w", err)
+	}
+	return apiKeys, total, nil
+}
+
+func (r *apiKeyRepository) GetAPIKeyByName(ctx context.Context, orgId uuid.UUID, userIdpId uuid.UUID, name string) (*models.APIKey, error) {
+	var apiKey models.APIKey
+	if err := db.DB(ctx).Where("org_id = ? AND user_idp_id = ? AND name = ? AND revoked_at IS NULL", orgId, userIdpId, name).First(&apiKey).Error; err != nil {
+		return nil, fmt.Errorf("apiKeyRepository.GetAPIKeyByName: %w", err)
+	}
+	return &apiKey, nil
+}
+
+func (r *apiKeyRepository) GetAPIKeyByHash(ctx context.Context, keyHash string) (*models.APIKey, error) {
+	var apiKey models.APIKey
+	if err := db.DB(ctx).Where("key_hash = ?", keyHash).First(&apiKey).Error; err != nil {
+		return nil, fmt.Errorf("apiKeyRepository.GetAPIKeyByHash: %w", err)
+	}
+	return &apiKey, nil
+}
+
+func (r *apiKeyRepository) CreateAPIKey(ctx context.Context, apiKey *models.APIKey) error {
+	if err := db.DB(ctx).Create(apiKey).Error; err != nil {
+		return fmt.Errorf("apiKeyRepository.CreateAPIKey: %w", err)
+	}
+	return nil
+}
+
+func (r *apiKeyRepository) RevokeAPIKey(ctx context.Context, orgId uuid.UUID, userIdpId uuid.UUID, keyId uuid.UUID, revokedAt time.Time) (bool, error) {
+	result := db.DB(ctx).Model(&models.APIKey{}).
+		Where("org_id = ? AND user_idp_id = ? AND id = ? AND revoked_at IS NULL", orgId, userIdpId, keyId).
+		Update("revoked_at", revokedAt)
+	if result.Error != nil {
+		return false, fmt.Errorf("apiKeyRepository.RevokeAPIKey: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *apiKeyRepository) UpdateLastUsedAt(ctx context.Context, keyId uuid.UUID, lastUsedAt time.Time) error {
+	if err := db.DB(ctx).Model(&models.APIKey{}).
+		Where("id = ? AND (last_used_at IS NULL OR last_used_at < ?)", keyId, lastUsedAt).
+		Update("last_used_at", lastUsedAt).Error; err != nil {
+		return fmt.Errorf("apiKeyRepository.UpdateLastUsedAt: %w", err)
+	}
+	return nil
+}
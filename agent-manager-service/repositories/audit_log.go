@@ -0,0 +1,83 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package repositories
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/db"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/models"
+)
+
+type AuditLogRepository interface {
+	CreateAuditLogs(ctx context.Context, auditLogs []*models.AuditLog) error
+	// ListAuditLogs returns the entries of the organization matching filter, newest first
+	ListAuditLogs(ctx context.Context, orgId uuid.UUID, filter models.AuditLogFilter, limit int, offset int) ([]*models.AuditLog, int64, error)
+}
+
+type auditLogRepository struct{}
+
+func NewAuditLogRepository() AuditLogRepository {
+	return &auditLogRepository{}
+}
+
+func (r *auditLogRepository) CreateAuditLogs(ctx context.Context, auditLogs []*models.AuditLog) error {
+	if err := db.DB(ctx).Create(auditLogs).Error; err != nil {
+		return fmt.Errorf("auditLogRepository.CreateAuditLogs: %w", err)
+	}
+	return nil
+}
+
+func (r *auditLogRepository) ListAuditLogs(ctx context.Context, orgId uuid.UUID, filter models.AuditLogFilter, limit int, offset int) ([]*models.AuditLog, int64, error) {
+	query := db.DB(ctx).Model(&models.AuditLog{}).Where("org_id = ?", orgId)
+	if filter.ActorIdpId != nil {
+		query = query.Where("actor_idp_id = ?", *filter.ActorIdpId)
+	}
+	if filter.ResourceType != "" {
+		query = query.Where("resource_type = ?", filter.ResourceType)
+	}
+	if filter.ResourceID != "" {
+		query = query.Where("resource_id = ?", filter.ResourceID)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.From != nil {
+		query = query.Where("created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("created_at < ?", *filter.To)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("auditLogRepository.ListAuditLogs: %w", err)
+	}
+
+	var auditLogs []*models.AuditLog
+	if err := query.
+		Order("created_at DESC, id").
+		Limit(limit).
+		Offset(offset).
+		Find(&auditLogs).Error; err != nil {
+		return nil, 0, fmt.Errorf("auditLogRepository.ListAuditLogs: %w", err)
+	}
+	return auditLogs, total, nil
+}
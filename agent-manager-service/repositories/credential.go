@@ -0,0 +1,149 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package repositories
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm/clause"
+
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/db"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/models"
+)
+
+type CredentialRepository interface {
+	ListCredentials(ctx context.Context, orgId uuid.UUID, limit int, offset int) ([]*models.Credential, int64, error)
+	GetCredentialById(ctx context.Context, orgId uuid.UUID, credentialId uuid.UUID) (*models.Credential, error)
+	// GetCredential returns the credential with the given id whatever organization it belongs to
+	GetCredential(ctx context.Context, credentialId uuid.UUID) (*models.Credential, error)
+	GetCredentialByName(ctx context.Context, orgId uuid.UUID, name string) (*models.Credential, error)
+	CreateCredential(ctx context.Context, credential *models.Credential) error
+	// UpdateCredential replaces the name, description and sealed secret of a credential and reports whether it exists
+	UpdateCredential(ctx context.Context, credential *models.Credential) (bool, error)
+	DeleteCredential(ctx context.Context, orgId uuid.UUID, credentialId uuid.UUID) (bool, error)
+	// LockCredentialsNotUnderKey returns the credentials of the organization encrypted with another master key
+	// than keyId, locked until the transaction ends
+	LockCredentialsNotUnderKey(ctx context.Context, orgId uuid.UUID, keyId string) ([]*models.Credential, error)
+	// UpdateCredentialKey replaces the master key and encrypted data key of a credential, the secret is left untouched
+	UpdateCredentialKey(ctx context.Context, credentialId uuid.UUID, keyId string, encryptedKey []byte) error
+}
+
+type credentialRepository struct{}
+
+func NewCredentialRepository() CredentialRepository {
+	return &credentialRepository{}
+}
+
+func (r *credentialRepository) ListCredentials(ctx context.Context, orgId uuid.UUID, limit int, offset int) ([]*models.Credential, int64, error) {
+	query := db.DB(ctx).Model(&models.Credential{}).Where("org_id = ?", orgId)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("credentialRepository.ListCredentials: %w", err)
+	}
+
+	var credentials []*models.Credential
+	if err := query.
+		Order("name ASC").
+		Limit(limit).
+		Offset(offset).
+		Find(&credentials).Error; err != nil {
+		return nil, 0, fmt.Errorf("credentialRepository.ListCredentials: %w", err)
+	}
+	return credentials, total, nil
+}
+
+func (r *credentialRepository) GetCredentialById(ctx context.Context, orgId uuid.UUID, credentialId uuid.UUID) (*models.Credential, error) {
+	var credential models.Credential
+	if err := db.DB(ctx).Where("org_id = ? AND id = ?", orgId, credentialId).First(&credential).Error; err != nil {
+		return nil, fmt.Errorf("credentialRepository.GetCredentialById: %w", err)
+	}
+	return &credential, nil
+}
+
+func (r *credentialRepository) GetCredential(ctx context.Context, credentialId uuid.UUID) (*models.Credential, error) {
+	var credential models.Credential
+	if err := db.DB(ctx).Where("id = ?", credentialId).First(&credential).Error; err != nil {
+		return nil, fmt.Errorf("credentialRepository.GetCredential: %w", err)
+	}
+	return &credential, nil
+}
+
+func (r *credentialRepository) GetCredentialByName(ctx context.Context, orgId uuid.UUID, name string) (*models.Credential, error) {
+	var credential models.Credential
+	if err := db.DB(ctx).Where("org_id = ? AND name = ?", orgId, name).First(&credential).Error; err != nil {
+		return nil, fmt.Errorf("credentialRepository.GetCredentialByName: %w", err)
+	}
+	return &credential, nil
+}
+
+func (r *credentialRepository) CreateCredential(ctx context.Context, credential *models.Credential) error {
+	if err := db.DB(ctx).Create(credential).Error; err != nil {
+		return fmt.Errorf("credentialRepository.CreateCredential: %w", err)
+	}
+	return nil
+}
+
+func (r *credentialRepository) UpdateCredential(ctx context.Context, credential *models.Credential) (bool, error) {
+	result := db.DB(ctx).Model(&models.Credential{}).
+		Where("org_id = ? AND id = ?", credential.OrgID, credential.ID).
+		Select("name", "description", "key_id", "encrypted_key", "encrypted_value", "preview", "updated_at").
+		Updates(&models.Credential{
+			Name:           credential.Name,
+			Description:    credential.Description,
+			KeyID:          credential.KeyID,
+			EncryptedKey:   credential.EncryptedKey,
+			EncryptedValue: credential.EncryptedValue,
+			Preview:        credential.Preview,
+			UpdatedAt:      credential.UpdatedAt,
+		})
+	if result.Error != nil {
+		return false, fmt.Errorf("credentialRepository.UpdateCredential: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *credentialRepository) DeleteCredential(ctx context.Context, orgId uuid.UUID, credentialId uuid.UUID) (bool, error) {
+	result := db.DB(ctx).Where("org_id = ? AND id = ?", orgId, credentialId).Delete(&models.Credential{})
+	if result.Error != nil {
+		return false, fmt.Errorf("credentialRepository.DeleteCredential: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *credentialRepository) LockCredentialsNotUnderKey(ctx context.Context, orgId uuid.UUID, keyId string) ([]*models.Credential, error) {
+	var credentials []*models.Credential
+	if err := db.DB(ctx).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("org_id = ? AND key_id <> ?", orgId, keyId).
+		Order("id ASC").
+		Find(&credentials).Error; err != nil {
+		return nil, fmt.Errorf("credentialRepository.LockCredentialsNotUnderKey: %w", err)
+	}
+	return credentials, nil
+}
+
+func (r *credentialRepository) UpdateCredentialKey(ctx context.Context, credentialId uuid.UUID, keyId string, encryptedKey []byte) error {
+	if err := db.DB(ctx).Model(&models.Credential{}).
+		Where("id = ?", credentialId).
+		Updates(map[string]interface{}{"key_id": keyId, "encrypted_key": encryptedKey}).Error; err != nil {
+		return fmt.Errorf("credentialRepository.UpdateCredentialKey: %w", err)
+	}
+	return nil
+}
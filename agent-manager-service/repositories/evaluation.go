@@ -0,0 +1,193 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/db"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/models"
+)
+
+type EvaluationRepository interface {
+	CreateEvaluationRun(ctx context.Context, run *models.EvaluationRun) error
+	GetEvaluationRun(ctx context.Context, orgId uuid.UUID, runId uuid.UUID) (*models.EvaluationRun, error)
+	// ListEvaluationRuns returns the runs of the organization, newest first
+	ListEvaluationRuns(ctx context.Context, orgId uuid.UUID, limit int, offset int) ([]*models.EvaluationRun, int64, error)
+	ListEvaluationResults(ctx context.Context, runId uuid.UUID, limit int, offset int) ([]*models.EvaluationResult, int64, error)
+	// ClaimEvaluationRun leases the oldest unfinished run whose lease ran out to leaseId until leaseUntil and marks
+	// it running, it returns nil when there is none
+	ClaimEvaluationRun(ctx context.Context, leaseId uuid.UUID, now time.Time, leaseUntil time.Time) (*models.EvaluationRun, error)
+	// SaveEvaluationCheckpoint stores the results of a page, moves the cursor past it, refreshes the summary and
+	// extends the lease, all at once. It reports false when the run is no longer leased to leaseId
+	SaveEvaluationCheckpoint(ctx context.Context, run *models.EvaluationRun, leaseId uuid.UUID, results []*models.EvaluationResult, leaseUntil time.Time) (bool, error)
+	// FinishEvaluationRun moves a run leased to leaseId to a final status and releases it
+	FinishEvaluationRun(ctx context.Context, runId uuid.UUID, leaseId uuid.UUID, status string, errMessage string) error
+	// ReleaseEvaluationRun gives up the lease of a run so it is claimed again on the next poll
+	ReleaseEvaluationRun(ctx context.Context, runId uuid.UUID, leaseId uuid.UUID) error
+}
+
+type evaluationRepository struct{}
+
+func NewEvaluationRepository() EvaluationRepository {
+	return &evaluationRepository{}
+}
+
+func (r *evaluationRepository) CreateEvaluationRun(ctx context.Context, run *models.EvaluationRun) error {
+	if err := db.DB(ctx).Create(run).Error; err != nil {
+		return fmt.Errorf("evaluationRepository.CreateEvaluationRun: %w", err)
+	}
+	return nil
+}
+
+func (r *evaluationRepository) GetEvaluationRun(ctx context.Context, orgId uuid.UUID, runId uuid.UUID) (*models.EvaluationRun, error) {
+	var run models.EvaluationRun
+	if err := db.DB(ctx).Where("org_id = ? AND id = ?", orgId, runId).First(&run).Error; err != nil {
+		return nil, fmt.Errorf("evaluationRepository.GetEvaluationRun: %w", err)
+	}
+	return &run, nil
+}
+
+func (r *evaluationRepository) ListEvaluationRuns(ctx context.Context, orgId uuid.UUID, limit int, offset int) ([]*models.EvaluationRun, int64, error) {
+	query := db.DB(ctx).Model(&models.EvaluationRun{}).Where("org_id = ?", orgId)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("evaluationRepository.ListEvaluationRuns: %w", err)
+	}
+
+	var runs []*models.EvaluationRun
+	if err := query.
+		Order("created_at DESC, id DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&runs).Error; err != nil {
+		return nil, 0, fmt.Errorf("evaluationRepository.ListEvaluationRuns: %w", err)
+	}
+	return runs, total, nil
+}
+
+func (r *evaluationRepository) ListEvaluationResults(ctx context.Context, runId uuid.UUID, limit int, offset int) ([]*models.EvaluationResult, int64, error) {
+	query := db.DB(ctx).Model(&models.EvaluationResult{}).Where("run_id = ?", runId)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("evaluationRepository.ListEvaluationResults: %w", err)
+	}
+
+	var results []*models.EvaluationResult
+	if err := query.
+		Order("evaluated_at ASC, trace_id ASC").
+		Limit(limit).
+		Offset(offset).
+		Find(&results).Error; err != nil {
+		return nil, 0, fmt.Errorf("evaluationRepository.ListEvaluationResults: %w", err)
+	}
+	return results, total, nil
+}
+
+func (r *evaluationRepository) ClaimEvaluationRun(ctx context.Context, leaseId uuid.UUID, now time.Time, leaseUntil time.Time) (*models.EvaluationRun, error) {
+	var runs []*models.EvaluationRun
+	if err := db.DB(ctx).Raw(`UPDATE evaluation_runs
+SET status = 'running', lease_id = ?, lease_until = ?, started_at = COALESCE(started_at, ?), updated_at = ?
+WHERE id = (
+   SELECT id FROM evaluation_runs
+   WHERE status IN ('pending', 'running') AND (lease_until IS NULL OR lease_until <= ?)
+   ORDER BY created_at ASC
+   LIMIT 1
+   FOR UPDATE SKIP LOCKED
+)
+RETURNING *`, leaseId, leaseUntil, now, now, now).Scan(&runs).Error; err != nil {
+		return nil, fmt.Errorf("evaluationRepository.ClaimEvaluationRun: %w", err)
+	}
+	if len(runs) == 0 {
+		return nil, nil
+	}
+	return runs[0], nil
+}
+
+func (r *evaluationRepository) SaveEvaluationCheckpoint(ctx context.Context, run *models.EvaluationRun, leaseId uuid.UUID, results []*models.EvaluationResult, leaseUntil time.Time) (bool, error) {
+	owned := false
+	err := db.DB(ctx).Transaction(func(tx *gorm.DB) error {
+		// Locking the run first keeps a worker that lost its lease from adding results
+		var locked models.EvaluationRun
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND lease_id = ?", run.ID, leaseId).
+			Limit(1).Find(&locked).Error; err != nil {
+			return err
+		}
+		if locked.ID == uuid.Nil {
+			return nil
+		}
+		owned = true
+		if len(results) > 0 {
+			// Results of a page evaluated again after a crash keep their first score
+			if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(results).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Exec(`UPDATE evaluation_runs
+SET cursor = ?, total_traces = ?, lease_until = ?, updated_at = ?,
+   evaluated_traces = summary.evaluated, passed_traces = summary.passed, failed_traces = summary.failed,
+   errored_traces = summary.errored, score_sum = summary.score_sum
+FROM (
+   SELECT COUNT(*) AS evaluated,
+      COUNT(*) FILTER (WHERE error = '' AND passed) AS passed,
+      COUNT(*) FILTER (WHERE error = '' AND NOT passed) AS failed,
+      COUNT(*) FILTER (WHERE error <> '') AS errored,
+      COALESCE(SUM(score) FILTER (WHERE error = ''), 0) AS score_sum
+   FROM evaluation_results WHERE run_id = ?
+) AS summary
+WHERE id = ?`, run.Cursor, run.TotalTraces, leaseUntil, time.Now(), run.ID, run.ID).Error
+	})
+	if err != nil {
+		return false, fmt.Errorf("evaluationRepository.SaveEvaluationCheckpoint: %w", err)
+	}
+	return owned, nil
+}
+
+func (r *evaluationRepository) FinishEvaluationRun(ctx context.Context, runId uuid.UUID, leaseId uuid.UUID, status string, errMessage string) error {
+	now := time.Now()
+	if err := db.DB(ctx).Model(&models.EvaluationRun{}).
+		Where("id = ? AND lease_id = ?", runId, leaseId).
+		Updates(map[string]interface{}{
+			"status":       status,
+			"error":        errMessage,
+			"lease_id":     nil,
+			"lease_until":  nil,
+			"completed_at": now,
+			"updated_at":   now,
+		}).Error; err != nil {
+		return fmt.Errorf("evaluationRepository.FinishEvaluationRun: %w", err)
+	}
+	return nil
+}
+
+func (r *evaluationRepository) ReleaseEvaluationRun(ctx context.Context, runId uuid.UUID, leaseId uuid.UUID) error {
+	if err := db.DB(ctx).Model(&models.EvaluationRun{}).
+		Where("id = ? AND lease_id = ?", runId, leaseId).
+		Updates(map[string]interface{}{"lease_id": nil, "lease_until": nil, "updated_at": time.Now()}).Error; err != nil {
+		return fmt.Errorf("evaluationRepository.ReleaseEvaluationRun: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm/clause"
+
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/db"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/models"
+)
+
+type IdempotencyRepository interface {
+	// CreateIdempotencyKey claims a key and reports whether it was free, an existing claim is left untouched
+	CreateIdempotencyKey(ctx context.Context, key *models.IdempotencyKey) (bool, error)
+	GetIdempotencyKey(ctx context.Context, principal string, key string, route string) (*models.IdempotencyKey, error)
+	// CompleteIdempotencyKey records the response of the execution that claimed the key
+	CompleteIdempotencyKey(ctx context.Context, key *models.IdempotencyKey) error
+	// DeleteIdempotencyKey frees a key unless it was claimed again after createdAt
+	DeleteIdempotencyKey(ctx context.Context, principal string, key string, route string, createdAt time.Time) error
+	// DeleteExpiredIdempotencyKeys removes the keys expired before now and returns how many were removed
+	DeleteExpiredIdempotencyKeys(ctx context.Context, now time.Time) (int64, error)
+}
+
+type idempotencyRepository struct{}
+
+func NewIdempotencyRepository() IdempotencyRepository {
+	return &idempotencyRepository{}
+}
+
+func (r *idempotencyRepository) CreateIdempotencyKey(ctx context.Context, key *models.IdempotencyKey) (bool, error) {
+	result := db.DB(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(key)
+	if result.Error != nil {
+		return false, fmt.Errorf("idempotencyRepository.CreateIdempotencyKey: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *idempotencyRepository) GetIdempotencyKey(ctx context.Context, principal string, key string, route string) (*models.IdempotencyKey, error) {
+	var idempotencyKey models.IdempotencyKey
+	if err := db.DB(ctx).
+		Where("principal = ? AND idempotency_key = ? AND route = ?", principal, key, route).
+		First(&idempotencyKey).Error; err != nil {
+		return nil, fmt.Errorf("idempotencyRepository.GetIdempotencyKey: %w", err)
+	}
+	return &idempotencyKey, nil
+}
+
+func (r *idempotencyRepository) CompleteIdempotencyKey(ctx context.Context, key *models.IdempotencyKey) error {
+	// the primary key of the model scopes the update, created_at skips a claim taken over in the meantime
+	if err := db.DB(ctx).Model(key).
+		Select("status_code", "response_headers", "response_body").
+		Where("created_at = ?", key.CreatedAt).
+		Updates(key).Error; err != nil {
+		return fmt.Errorf("idempotencyRepository.CompleteIdempotencyKey: %w", err)
+	}
+	return nil
+}
+
+func (r *idempotencyRepository) DeleteIdempotencyKey(ctx context.Context, principal string, key string, route string, createdAt time.Time) error {
+	if err := db.DB(ctx).
+		Where("principal = ? AND idempotency_key = ? AND route = ? AND created_at = ?", principal, key, route, createdAt).
+		Delete(&models.IdempotencyKey{}).Error; err != nil {
+		return fmt.Errorf("idempotencyRepository.DeleteIdempotencyKey: %w", err)
+	}
+	return nil
+}
+
+func (r *idempotencyRepository) DeleteExpiredIdempotencyKeys(ctx context.Context, now time.Time) (int64, error) {
+	result := db.DB(ctx).Where("expires_at <= ?", now).Delete(&models.IdempotencyKey{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("idempotencyRepository.DeleteExpiredIdempotencyKeys: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
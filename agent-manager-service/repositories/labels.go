@@ -0,0 +1,77 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package repositories
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/models"
+)
+
+// labelTable is the normalized copy of the labels of agents, prompts or tools, one row per label
+type labelTable struct {
+	// Name of the label table
+	name string
+	// Column of the label table referencing the labelled resource
+	resourceColumn string
+	// Table of the labelled resources
+	resourceTable string
+}
+
+var (
+	managedAgentLabels   = labelTable{name: "managed_agent_labels", resourceColumn: "agent_id", resourceTable: "managed_agents"}
+	promptTemplateLabels = labelTable{name: "prompt_template_labels", resourceColumn: "prompt_id", resourceTable: "prompt_templates"}
+	toolLabels           = labelTable{name: "tool_labels", resourceColumn: "tool_id", resourceTable: "tools"}
+)
+
+// replace sets the labels of the resource to exactly the given ones
+func (t labelTable) replace(tx *gorm.DB, resourceId uuid.UUID, labels map[string]string) error {
+	if err := tx.Exec(fmt.Sprintf("DELETE FROM %s WHERE %s = ?", t.name, t.resourceColumn), resourceId).Error; err != nil {
+		return err
+	}
+	if len(labels) == 0 {
+		return nil
+	}
+	rows := make([]map[string]interface{}, 0, len(labels))
+	for key, value := range labels {
+		rows = append(rows, map[string]interface{}{t.resourceColumn: resourceId, "key": key, "value": value})
+	}
+	return tx.Table(t.name).Create(rows).Error
+}
+
+// filter narrows down a query on the labelled resources to the ones matching the selector
+func (t labelTable) filter(query *gorm.DB, selector models.LabelSelector) (*gorm.DB, error) {
+	labelled := fmt.Sprintf("SELECT 1 FROM %s l WHERE l.%s = %s.id AND l.key = ?", t.name, t.resourceColumn, t.resourceTable)
+	for _, requirement := range selector {
+		switch requirement.Operator {
+		case models.LabelOperatorIn:
+			query = query.Where("EXISTS ("+labelled+" AND l.value IN ?)", requirement.Key, requirement.Values)
+		case models.LabelOperatorNotIn:
+			query = query.Where("NOT EXISTS ("+labelled+" AND l.value IN ?)", requirement.Key, requirement.Values)
+		case models.LabelOperatorExists:
+			query = query.Where("EXISTS ("+labelled+")", requirement.Key)
+		case models.LabelOperatorDoesNotExist:
+			query = query.Where("NOT EXISTS ("+labelled+")", requirement.Key)
+		default:
+			return nil, fmt.Errorf("unsupported label operator %q", requirement.Operator)
+		}
+	}
+	return query, nil
+}
@@ -0,0 +1,97 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package repositories
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/models"
+)
+
+// listSortColumns are the columns of the fields listings are sorted by
+var listSortColumns = map[string]string{
+	models.ListSortName:      "name",
+	models.ListSortCreatedAt: "created_at",
+	models.ListSortUpdatedAt: "updated_at",
+}
+
+// listKey is the sort key of a listed resource
+type listKey struct {
+	name      string
+	createdAt time.Time
+	updatedAt time.Time
+	id        uuid.UUID
+}
+
+// listPage reads a page of a listing query, with the total of the listing when the page asks for it.
+// One result more than the limit is read to tell whether another page follows, its cursor is the key of
+// the last result returned
+func listPage[T any](query *gorm.DB, page models.ListPage, keyOf func(item *T) listKey) ([]*T, *models.ListCursor, int64, error) {
+	column, ok := listSortColumns[page.Sort]
+	if !ok {
+		return nil, nil, 0, fmt.Errorf("unsupported sort %q", page.Sort)
+	}
+
+	var total int64
+	if page.Count {
+		if err := query.Count(&total).Error; err != nil {
+			return nil, nil, 0, err
+		}
+	}
+
+	direction, comparison := "ASC", ">"
+	if page.Order == models.ListOrderDesc {
+		direction, comparison = "DESC", "<"
+	}
+	if cursor := page.Cursor; cursor != nil {
+		var value interface{} = cursor.Name
+		if cursor.Time != nil {
+			value = *cursor.Time
+		}
+		query = query.Where(fmt.Sprintf("(%s, id) %s (?, ?)", column, comparison), value, cursor.ID)
+	} else {
+		query = query.Offset(page.Offset)
+	}
+
+	var items []*T
+	if err := query.
+		Order(fmt.Sprintf("%s %s, id %s", column, direction, direction)).
+		Limit(page.Limit + 1).
+		Find(&items).Error; err != nil {
+		return nil, nil, 0, err
+	}
+	if len(items) <= page.Limit {
+		return items, nil, total, nil
+	}
+
+	items = items[:page.Limit]
+	key := keyOf(items[len(items)-1])
+	next := &models.ListCursor{Sort: page.Sort, Order: page.Order, ID: key.id}
+	switch page.Sort {
+	case models.ListSortName:
+		next.Name = key.name
+	case models.ListSortCreatedAt:
+		next.Time = &key.createdAt
+	case models.ListSortUpdatedAt:
+		next.Time = &key.updatedAt
+	}
+	return items, next, total, nil
+}
@@ -0,0 +1,226 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/db"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/models"
+)
+
+type ManagedAgentRepository interface {
+	ListManagedAgents(ctx context.Context, orgId uuid.UUID, filter models.ManagedAgentFilter) ([]*models.ManagedAgent, *models.ListCursor, int64, error)
+	GetManagedAgentById(ctx context.Context, orgId uuid.UUID, agentId uuid.UUID) (*models.ManagedAgent, error)
+	// GetManagedAgentByIdWithDeleted also finds the agent when it was deleted and not yet purged
+	GetManagedAgentByIdWithDeleted(ctx context.Context, orgId uuid.UUID, agentId uuid.UUID) (*models.ManagedAgent, error)
+	// GetManagedAgentByIdInAnyOrg finds the agent in whichever organization it belongs to, deleted or not
+	GetManagedAgentByIdInAnyOrg(ctx context.Context, agentId uuid.UUID) (*models.ManagedAgent, error)
+	GetManagedAgentByName(ctx context.Context, orgId uuid.UUID, name string) (*models.ManagedAgent, error)
+	// ListManagedAgentsByNameFold returns the agents of the organization whose name equals the given one ignoring case
+	ListManagedAgentsByNameFold(ctx context.Context, orgId uuid.UUID, name string) ([]*models.ManagedAgent, error)
+	CreateManagedAgent(ctx context.Context, agent *models.ManagedAgent) error
+	// UpdateManagedAgent replaces the agent if it is still at expectedVersion and reports whether it did
+	UpdateManagedAgent(ctx context.Context, agent *models.ManagedAgent, expectedVersion int32) (bool, error)
+	// DeleteManagedAgent soft deletes the agent, it is left out of every other query until restored
+	DeleteManagedAgent(ctx context.Context, orgId uuid.UUID, agentId uuid.UUID) (bool, error)
+	// RestoreManagedAgent undoes the deletion of the agent and reports whether it was deleted
+	RestoreManagedAgent(ctx context.Context, orgId uuid.UUID, agentId uuid.UUID) (bool, error)
+	// PurgeDeletedManagedAgents removes the agents deleted before the given time for good
+	PurgeDeletedManagedAgents(ctx context.Context, deletedBefore time.Time) (int64, error)
+	// ListManagedAgentsByPrompt returns the agents of the organization that reference any version of the prompt
+	ListManagedAgentsByPrompt(ctx context.Context, orgId uuid.UUID, promptId uuid.UUID) ([]*models.ManagedAgent, error)
+	// ListManagedAgentsByTool returns the agents of the organization that reference the registered tool
+	ListManagedAgentsByTool(ctx context.Context, orgId uuid.UUID, toolId uuid.UUID) ([]*models.ManagedAgent, error)
+}
+
+type managedAgentRepository struct{}
+
+func NewManagedAgentRepository() ManagedAgentRepository {
+	return &managedAgentRepository{}
+}
+
+func (r *managedAgentRepository) ListManagedAgents(ctx context.Context, orgId uuid.UUID, filter models.ManagedAgentFilter) ([]*models.ManagedAgent, *models.ListCursor, int64, error) {
+	query := db.DB(ctx).Model(&models.ManagedAgent{}).Where("org_id = ?", orgId)
+	if filter.IncludeDeleted {
+		query = query.Unscoped()
+	}
+	if filter.Search != "" {
+		query = query.Where(`name ILIKE ? ESCAPE '\'`, "%"+escapeLikePattern(filter.Search)+"%")
+	}
+	query, err := managedAgentLabels.filter(query, filter.Selector)
+	if err != nil {
+		return nil, nil, 0, fmt.Errorf("managedAgentRepository.ListManagedAgents: %w", err)
+	}
+
+	agents, next, total, err := listPage(query, filter.Page, func(agent *models.ManagedAgent) listKey {
+		return listKey{name: agent.Name, createdAt: agent.CreatedAt, updatedAt: agent.UpdatedAt, id: agent.ID}
+	})
+	if err != nil {
+		return nil, nil, 0, fmt.Errorf("managedAgentRepository.ListManagedAgents: %w", err)
+	}
+	return agents, next, total, nil
+}
+
+func (r *managedAgentRepository) GetManagedAgentById(ctx context.Context, orgId uuid.UUID, agentId uuid.UUID) (*models.ManagedAgent, error) {
+	var agent models.ManagedAgent
+	if err := db.DB(ctx).Where("org_id = ? AND id = ?", orgId, agentId).First(&agent).Error; err != nil {
+		return nil, fmt.Errorf("managedAgentRepository.GetManagedAgentById: %w", err)
+	}
+	return &agent, nil
+}
+
+func (r *managedAgentRepository) GetManagedAgentByIdWithDeleted(ctx context.Context, orgId uuid.UUID, agentId uuid.UUID) (*models.ManagedAgent, error) {
+	var agent models.ManagedAgent
+	if err := db.DB(ctx).Unscoped().Where("org_id = ? AND id = ?", orgId, agentId).First(&agent).Error; err != nil {
+		return nil, fmt.Errorf("managedAgentRepository.GetManagedAgentByIdWithDeleted: %w", err)
+	}
+	return &agent, nil
+}
+
+func (r *managedAgentRepository) GetManagedAgentByIdInAnyOrg(ctx context.Context, agentId uuid.UUID) (*models.ManagedAgent, error) {
+	var agent models.ManagedAgent
+	if err := db.DB(ctx).Unscoped().Where("id = ?", agentId).First(&agent).Error; err != nil {
+		return nil, fmt.Errorf("managedAgentRepository.GetManagedAgentByIdInAnyOrg: %w", err)
+	}
+	return &agent, nil
+}
+
+func (r *managedAgentRepository) GetManagedAgentByName(ctx context.Context, orgId uuid.UUID, name string) (*models.ManagedAgent, error) {
+	var agent models.ManagedAgent
+	if err := db.DB(ctx).Where("org_id = ? AND name = ?", orgId, name).First(&agent).Error; err != nil {
+		return nil, fmt.Errorf("managedAgentRepository.GetManagedAgentByName: %w", err)
+	}
+	return &agent, nil
+}
+
+func (r *managedAgentRepository) ListManagedAgentsByNameFold(ctx context.Context, orgId uuid.UUID, name string) ([]*models.ManagedAgent, error) {
+	var agents []*models.ManagedAgent
+	if err := db.DB(ctx).Where("org_id = ? AND LOWER(name) = LOWER(?)", orgId, name).Order("name ASC").Find(&agents).Error; err != nil {
+		return nil, fmt.Errorf("managedAgentRepository.ListManagedAgentsByNameFold: %w", err)
+	}
+	return agents, nil
+}
+
+func (r *managedAgentRepository) CreateManagedAgent(ctx context.Context, agent *models.ManagedAgent) error {
+	err := db.DB(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(agent).Error; err != nil {
+			return err
+		}
+		return managedAgentLabels.replace(tx, agent.ID, agent.Labels)
+	})
+	if err != nil {
+		return fmt.Errorf("managedAgentRepository.CreateManagedAgent: %w", err)
+	}
+	return nil
+}
+
+func (r *managedAgentRepository) UpdateManagedAgent(ctx context.Context, agent *models.ManagedAgent, expectedVersion int32) (bool, error) {
+	var updated bool
+	err := db.DB(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.ManagedAgent{}).
+			Where("org_id = ? AND id = ? AND version = ?", agent.OrgID, agent.ID, expectedVersion).
+			Select("name", "description", "framework", "model_config", "system_prompt", "prompt_id", "prompt_version", "tools", "labels", "enabled", "version", "updated_at", "config_schema_version", "retention_days").
+			Updates(&models.ManagedAgent{
+				Name:                agent.Name,
+				Description:         agent.Description,
+				Framework:           agent.Framework,
+				ModelConfig:         agent.ModelConfig,
+				SystemPrompt:        agent.SystemPrompt,
+				PromptID:            agent.PromptID,
+				PromptVersion:       agent.PromptVersion,
+				Tools:               agent.Tools,
+				Labels:              agent.Labels,
+				Enabled:             agent.Enabled,
+				Version:             expectedVersion + 1,
+				UpdatedAt:           agent.UpdatedAt,
+				ConfigSchemaVersion: agent.ConfigSchemaVersion,
+				RetentionDays:       agent.RetentionDays,
+			})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		updated = true
+		return managedAgentLabels.replace(tx, agent.ID, agent.Labels)
+	})
+	if err != nil {
+		return false, fmt.Errorf("managedAgentRepository.UpdateManagedAgent: %w", err)
+	}
+	if !updated {
+		return false, nil
+	}
+	agent.Version = expectedVersion + 1
+	return true, nil
+}
+
+func (r *managedAgentRepository) DeleteManagedAgent(ctx context.Context, orgId uuid.UUID, agentId uuid.UUID) (bool, error) {
+	result := db.DB(ctx).Where("org_id = ? AND id = ?", orgId, agentId).Delete(&models.ManagedAgent{})
+	if result.Error != nil {
+		return false, fmt.Errorf("managedAgentRepository.DeleteManagedAgent: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *managedAgentRepository) RestoreManagedAgent(ctx context.Context, orgId uuid.UUID, agentId uuid.UUID) (bool, error) {
+	result := db.DB(ctx).Unscoped().Model(&models.ManagedAgent{}).
+		Where("org_id = ? AND id = ? AND deleted_at IS NOT NULL", orgId, agentId).
+		Update("deleted_at", nil)
+	if result.Error != nil {
+		return false, fmt.Errorf("managedAgentRepository.RestoreManagedAgent: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *managedAgentRepository) PurgeDeletedManagedAgents(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	result := db.DB(ctx).Unscoped().Where("deleted_at < ?", deletedBefore).Delete(&models.ManagedAgent{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("managedAgentRepository.PurgeDeletedManagedAgents: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+func (r *managedAgentRepository) ListManagedAgentsByPrompt(ctx context.Context, orgId uuid.UUID, promptId uuid.UUID) ([]*models.ManagedAgent, error) {
+	var agents []*models.ManagedAgent
+	if err := db.DB(ctx).Where("org_id = ? AND prompt_id = ?", orgId, promptId).Order("name ASC").Find(&agents).Error; err != nil {
+		return nil, fmt.Errorf("managedAgentRepository.ListManagedAgentsByPrompt: %w", err)
+	}
+	return agents, nil
+}
+
+func (r *managedAgentRepository) ListManagedAgentsByTool(ctx context.Context, orgId uuid.UUID, toolId uuid.UUID) ([]*models.ManagedAgent, error) {
+	reference, err := json.Marshal([]models.ToolReference{{ToolID: toolId.String()}})
+	if err != nil {
+		return nil, fmt.Errorf("managedAgentRepository.ListManagedAgentsByTool: %w", err)
+	}
+	var agents []*models.ManagedAgent
+	if err := db.DB(ctx).Where("org_id = ? AND tools @> ?::jsonb", orgId, string(reference)).Order("name ASC").Find(&agents).Error; err != nil {
+		return nil, fmt.Errorf("managedAgentRepository.ListManagedAgentsByTool: %w", err)
+	}
+	return agents, nil
+}
+
+// escapeLikePattern escapes the LIKE wildcards of a user supplied search term
+func escapeLikePattern(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
@@ -0,0 +1,73 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package repositories
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/db"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/models"
+)
+
+type ManagedAgentVersionRepository interface {
+	CreateManagedAgentVersion(ctx context.Context, version *models.ManagedAgentVersion) error
+	ListManagedAgentVersions(ctx context.Context, agentId uuid.UUID, limit int, offset int) ([]*models.ManagedAgentVersion, int64, error)
+	GetManagedAgentVersion(ctx context.Context, agentId uuid.UUID, version int32) (*models.ManagedAgentVersion, error)
+}
+
+type managedAgentVersionRepository struct{}
+
+func NewManagedAgentVersionRepository() ManagedAgentVersionRepository {
+	return &managedAgentVersionRepository{}
+}
+
+func (r *managedAgentVersionRepository) CreateManagedAgentVersion(ctx context.Context, version *models.ManagedAgentVersion) error {
+	if err := db.DB(ctx).Create(version).Error; err != nil {
+		return fmt.Errorf("managedAgentVersionRepository.CreateManagedAgentVersion: %w", err)
+	}
+	return nil
+}
+
+func (r *managedAgentVersionRepository) ListManagedAgentVersions(ctx context.Context, agentId uuid.UUID, limit int, offset int) ([]*models.ManagedAgentVersion, int64, error) {
+	query := db.DB(ctx).Model(&models.ManagedAgentVersion{}).Where("agent_id = ?", agentId)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("managedAgentVersionRepository.ListManagedAgentVersions: %w", err)
+	}
+
+	var versions []*models.ManagedAgentVersion
+	if err := query.
+		Order("version DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&versions).Error; err != nil {
+		return nil, 0, fmt.Errorf("managedAgentVersionRepository.ListManagedAgentVersions: %w", err)
+	}
+	return versions, total, nil
+}
+
+func (r *managedAgentVersionRepository) GetManagedAgentVersion(ctx context.Context, agentId uuid.UUID, version int32) (*models.ManagedAgentVersion, error) {
+	var agentVersion models.ManagedAgentVersion
+	if err := db.DB(ctx).Where("agent_id = ? AND version = ?", agentId, version).First(&agentVersion).Error; err != nil {
+		return nil, fmt.Errorf("managedAgentVersionRepository.GetManagedAgentVersion: %w", err)
+	}
+	return &agentVersion, nil
+}
@@ -0,0 +1,209 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/db"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/models"
+)
+
+type PromptTemplateRepository interface {
+	ListPromptTemplates(ctx context.Context, orgId uuid.UUID, filter models.PromptTemplateFilter) ([]*models.PromptTemplate, *models.ListCursor, int64, error)
+	GetPromptTemplateById(ctx context.Context, orgId uuid.UUID, promptId uuid.UUID) (*models.PromptTemplate, error)
+	// GetPromptTemplateByIdWithDeleted also finds the prompt when it was deleted and not yet purged
+	GetPromptTemplateByIdWithDeleted(ctx context.Context, orgId uuid.UUID, promptId uuid.UUID) (*models.PromptTemplate, error)
+	GetPromptTemplateByName(ctx context.Context, orgId uuid.UUID, name string) (*models.PromptTemplate, error)
+	CreatePromptTemplate(ctx context.Context, prompt *models.PromptTemplate) error
+	// UpdatePromptTemplate replaces the prompt if it is still at expectedVersion and reports whether it did
+	UpdatePromptTemplate(ctx context.Context, prompt *models.PromptTemplate, expectedVersion int32) (bool, error)
+	// DeletePromptTemplate soft deletes the prompt, it is left out of every other query until restored
+	DeletePromptTemplate(ctx context.Context, orgId uuid.UUID, promptId uuid.UUID) (bool, error)
+	// RestorePromptTemplate undoes the deletion of the prompt and reports whether it was deleted
+	RestorePromptTemplate(ctx context.Context, orgId uuid.UUID, promptId uuid.UUID) (bool, error)
+	// PurgeDeletedPromptTemplates removes the prompts deleted before the given time for good, except those
+	// agents still reference, e.g. deleted agents that are not yet purged
+	PurgeDeletedPromptTemplates(ctx context.Context, deletedBefore time.Time) (int64, error)
+	CreatePromptTemplateVersion(ctx context.Context, version *models.PromptTemplateVersion) error
+	ListPromptTemplateVersions(ctx context.Context, promptId uuid.UUID, limit int, offset int) ([]*models.PromptTemplateVersion, int64, error)
+	GetPromptTemplateVersion(ctx context.Context, promptId uuid.UUID, version int32) (*models.PromptTemplateVersion, error)
+}
+
+type promptTemplateRepository struct{}
+
+func NewPromptTemplateRepository() PromptTemplateRepository {
+	return &promptTemplateRepository{}
+}
+
+func (r *promptTemplateRepository) ListPromptTemplates(ctx context.Context, orgId uuid.UUID, filter models.PromptTemplateFilter) ([]*models.PromptTemplate, *models.ListCursor, int64, error) {
+	query := db.DB(ctx).Model(&models.PromptTemplate{}).Where("org_id = ?", orgId)
+	if filter.IncludeDeleted {
+		query = query.Unscoped()
+	}
+	if filter.Search != "" {
+		query = query.Where(`name ILIKE ? ESCAPE '\'`, "%"+escapeLikePattern(filter.Search)+"%")
+	}
+	query, err := promptTemplateLabels.filter(query, filter.Selector)
+	if err != nil {
+		return nil, nil, 0, fmt.Errorf("promptTemplateRepository.ListPromptTemplates: %w", err)
+	}
+
+	prompts, next, total, err := listPage(query, filter.Page, func(prompt *models.PromptTemplate) listKey {
+		return listKey{name: prompt.Name, createdAt: prompt.CreatedAt, updatedAt: prompt.UpdatedAt, id: prompt.ID}
+	})
+	if err != nil {
+		return nil, nil, 0, fmt.Errorf("promptTemplateRepository.ListPromptTemplates: %w", err)
+	}
+	return prompts, next, total, nil
+}
+
+func (r *promptTemplateRepository) GetPromptTemplateById(ctx context.Context, orgId uuid.UUID, promptId uuid.UUID) (*models.PromptTemplate, error) {
+	var prompt models.PromptTemplate
+	if err := db.DB(ctx).Where("org_id = ? AND id = ?", orgId, promptId).First(&prompt).Error; err != nil {
+		return nil, fmt.Errorf("promptTemplateRepository.GetPromptTemplateById: %w", err)
+	}
+	return &prompt, nil
+}
+
+func (r *promptTemplateRepository) GetPromptTemplateByIdWithDeleted(ctx context.Context, orgId uuid.UUID, promptId uuid.UUID) (*models.PromptTemplate, error) {
+	var prompt models.PromptTemplate
+	if err := db.DB(ctx).Unscoped().Where("org_id = ? AND id = ?", orgId, promptId).First(&prompt).Error; err != nil {
+		return nil, fmt.Errorf("promptTemplateRepository.GetPromptTemplateByIdWithDeleted: %w", err)
+	}
+	return &prompt, nil
+}
+
+func (r *promptTemplateRepository) GetPromptTemplateByName(ctx context.Context, orgId uuid.UUID, name string) (*models.PromptTemplate, error) {
+	var prompt models.PromptTemplate
+	if err := db.DB(ctx).Where("org_id = ? AND name = ?", orgId, name).First(&prompt).Error; err != nil {
+		return nil, fmt.Errorf("promptTemplateRepository.GetPromptTemplateByName: %w", err)
+	}
+	return &prompt, nil
+}
+
+func (r *promptTemplateRepository) CreatePromptTemplate(ctx context.Context, prompt *models.PromptTemplate) error {
+	err := db.DB(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(prompt).Error; err != nil {
+			return err
+		}
+		return promptTemplateLabels.replace(tx, prompt.ID, prompt.Labels)
+	})
+	if err != nil {
+		return fmt.Errorf("promptTemplateRepository.CreatePromptTemplate: %w", err)
+	}
+	return nil
+}
+
+func (r *promptTemplateRepository) UpdatePromptTemplate(ctx context.Context, prompt *models.PromptTemplate, expectedVersion int32) (bool, error) {
+	var updated bool
+	err := db.DB(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.PromptTemplate{}).
+			Where("org_id = ? AND id = ? AND version = ?", prompt.OrgID, prompt.ID, expectedVersion).
+			Select("name", "description", "template", "variables", "labels", "version", "updated_at").
+			Updates(&models.PromptTemplate{
+				Name:        prompt.Name,
+				Description: prompt.Description,
+				Template:    prompt.Template,
+				Variables:   prompt.Variables,
+				Labels:      prompt.Labels,
+				Version:     expectedVersion + 1,
+				UpdatedAt:   prompt.UpdatedAt,
+			})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		updated = true
+		return promptTemplateLabels.replace(tx, prompt.ID, prompt.Labels)
+	})
+	if err != nil {
+		return false, fmt.Errorf("promptTemplateRepository.UpdatePromptTemplate: %w", err)
+	}
+	if !updated {
+		return false, nil
+	}
+	prompt.Version = expectedVersion + 1
+	return true, nil
+}
+
+func (r *promptTemplateRepository) DeletePromptTemplate(ctx context.Context, orgId uuid.UUID, promptId uuid.UUID) (bool, error) {
+	result := db.DB(ctx).Where("org_id = ? AND id = ?", orgId, promptId).Delete(&models.PromptTemplate{})
+	if result.Error != nil {
+		return false, fmt.Errorf("promptTemplateRepository.DeletePromptTemplate: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *promptTemplateRepository) RestorePromptTemplate(ctx context.Context, orgId uuid.UUID, promptId uuid.UUID) (bool, error) {
+	result := db.DB(ctx).Unscoped().Model(&models.PromptTemplate{}).
+		Where("org_id = ? AND id = ? AND deleted_at IS NOT NULL", orgId, promptId).
+		Update("deleted_at", nil)
+	if result.Error != nil {
+		return false, fmt.Errorf("promptTemplateRepository.RestorePromptTemplate: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *promptTemplateRepository) PurgeDeletedPromptTemplates(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	result := db.DB(ctx).Unscoped().
+		Where("deleted_at < ?", deletedBefore).
+		Where("NOT EXISTS (SELECT 1 FROM managed_agents WHERE managed_agents.prompt_id = prompt_templates.id)").
+		Delete(&models.PromptTemplate{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("promptTemplateRepository.PurgeDeletedPromptTemplates: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+func (r *promptTemplateRepository) CreatePromptTemplateVersion(ctx context.Context, version *models.PromptTemplateVersion) error {
+	if err := db.DB(ctx).Create(version).Error; err != nil {
+		return fmt.Errorf("promptTemplateRepository.CreatePromptTemplateVersion: %w", err)
+	}
+	return nil
+}
+
+func (r *promptTemplateRepository) ListPromptTemplateVersions(ctx context.Context, promptId uuid.UUID, limit int, offset int) ([]*models.PromptTemplateVersion, int64, error) {
+	query := db.DB(ctx).Model(&models.PromptTemplateVersion{}).Where("prompt_id = ?", promptId)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("promptTemplateRepository.ListPromptTemplateVersions: %w", err)
+	}
+
+	var versions []*models.PromptTemplateVersion
+	if err := query.
+		Order("version DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&versions).Error; err != nil {
+		return nil, 0, fmt.Errorf("promptTemplateRepository.ListPromptTemplateVersions: %w", err)
+	}
+	return versions, total, nil
+}
+
+func (r *promptTemplateRepository) GetPromptTemplateVersion(ctx context.Context, promptId uuid.UUID, version int32) (*models.PromptTemplateVersion, error) {
+	var promptVersion models.PromptTemplateVersion
+	if err := db.DB(ctx).Where("prompt_id = ? AND version = ?", promptId, version).First(&promptVersion).Error; err != nil {
+		return nil, fmt.Errorf("promptTemplateRepository.GetPromptTemplateVersion: %w", err)
+	}
+	return &promptVersion, nil
+}
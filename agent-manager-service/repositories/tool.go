@@ -0,0 +1,167 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/db"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/models"
+)
+
+type ToolRepository interface {
+	ListTools(ctx context.Context, orgId uuid.UUID, filter models.ToolFilter) ([]*models.Tool, *models.ListCursor, int64, error)
+	GetToolById(ctx context.Context, orgId uuid.UUID, toolId uuid.UUID) (*models.Tool, error)
+	// GetToolsByIds returns the tools of the organization with the given ids, ids without a tool are skipped
+	GetToolsByIds(ctx context.Context, orgId uuid.UUID, toolIds []uuid.UUID) ([]*models.Tool, error)
+	GetToolByName(ctx context.Context, orgId uuid.UUID, name string) (*models.Tool, error)
+	CreateTool(ctx context.Context, tool *models.Tool) error
+	// UpdateTool replaces the tool if it is still at expectedVersion and reports whether it did
+	UpdateTool(ctx context.Context, tool *models.Tool, expectedVersion int32) (bool, error)
+	DeleteTool(ctx context.Context, orgId uuid.UUID, toolId uuid.UUID) (bool, error)
+	// ListToolsByCredential returns the tools of the organization that authenticate with the credential
+	ListToolsByCredential(ctx context.Context, orgId uuid.UUID, credentialId uuid.UUID) ([]*models.Tool, error)
+}
+
+type toolRepository struct{}
+
+func NewToolRepository() ToolRepository {
+	return &toolRepository{}
+}
+
+func (r *toolRepository) ListTools(ctx context.Context, orgId uuid.UUID, filter models.ToolFilter) ([]*models.Tool, *models.ListCursor, int64, error) {
+	query := db.DB(ctx).Model(&models.Tool{}).Where("org_id = ?", orgId)
+	if filter.Search != "" {
+		query = query.Where(`name ILIKE ? ESCAPE '\'`, "%"+escapeLikePattern(filter.Search)+"%")
+	}
+	if len(filter.Tags) > 0 {
+		tags, err := json.Marshal(filter.Tags)
+		if err != nil {
+			return nil, nil, 0, fmt.Errorf("toolRepository.ListTools: %w", err)
+		}
+		query = query.Where("tags @> ?::jsonb", string(tags))
+	}
+	query, err := toolLabels.filter(query, filter.Selector)
+	if err != nil {
+		return nil, nil, 0, fmt.Errorf("toolRepository.ListTools: %w", err)
+	}
+
+	tools, next, total, err := listPage(query, filter.Page, func(tool *models.Tool) listKey {
+		return listKey{name: tool.Name, createdAt: tool.CreatedAt, updatedAt: tool.UpdatedAt, id: tool.ID}
+	})
+	if err != nil {
+		return nil, nil, 0, fmt.Errorf("toolRepository.ListTools: %w", err)
+	}
+	return tools, next, total, nil
+}
+
+func (r *toolRepository) GetToolById(ctx context.Context, orgId uuid.UUID, toolId uuid.UUID) (*models.Tool, error) {
+	var tool models.Tool
+	if err := db.DB(ctx).Where("org_id = ? AND id = ?", orgId, toolId).First(&tool).Error; err != nil {
+		return nil, fmt.Errorf("toolRepository.GetToolById: %w", err)
+	}
+	return &tool, nil
+}
+
+func (r *toolRepository) GetToolsByIds(ctx context.Context, orgId uuid.UUID, toolIds []uuid.UUID) ([]*models.Tool, error) {
+	var tools []*models.Tool
+	if len(toolIds) == 0 {
+		return tools, nil
+	}
+	if err := db.DB(ctx).Where("org_id = ? AND id IN ?", orgId, toolIds).Find(&tools).Error; err != nil {
+		return nil, fmt.Errorf("toolRepository.GetToolsByIds: %w", err)
+	}
+	return tools, nil
+}
+
+func (r *toolRepository) GetToolByName(ctx context.Context, orgId uuid.UUID, name string) (*models.Tool, error) {
+	var tool models.Tool
+	if err := db.DB(ctx).Where("org_id = ? AND name = ?", orgId, name).First(&tool).Error; err != nil {
+		return nil, fmt.Errorf("toolRepository.GetToolByName: %w", err)
+	}
+	return &tool, nil
+}
+
+func (r *toolRepository) CreateTool(ctx context.Context, tool *models.Tool) error {
+	err := db.DB(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(tool).Error; err != nil {
+			return err
+		}
+		return toolLabels.replace(tx, tool.ID, tool.Labels)
+	})
+	if err != nil {
+		return fmt.Errorf("toolRepository.CreateTool: %w", err)
+	}
+	return nil
+}
+
+func (r *toolRepository) UpdateTool(ctx context.Context, tool *models.Tool, expectedVersion int32) (bool, error) {
+	var updated bool
+	err := db.DB(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Tool{}).
+			Where("org_id = ? AND id = ? AND version = ?", tool.OrgID, tool.ID, expectedVersion).
+			Select("name", "description", "parameters", "endpoint", "tags", "labels", "version", "updated_at").
+			Updates(&models.Tool{
+				Name:        tool.Name,
+				Description: tool.Description,
+				Parameters:  tool.Parameters,
+				Endpoint:    tool.Endpoint,
+				Tags:        tool.Tags,
+				Labels:      tool.Labels,
+				Version:     expectedVersion + 1,
+				UpdatedAt:   tool.UpdatedAt,
+			})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		updated = true
+		return toolLabels.replace(tx, tool.ID, tool.Labels)
+	})
+	if err != nil {
+		return false, fmt.Errorf("toolRepository.UpdateTool: %w", err)
+	}
+	if !updated {
+		return false, nil
+	}
+	tool.Version = expectedVersion + 1
+	return true, nil
+}
+
+func (r *toolRepository) DeleteTool(ctx context.Context, orgId uuid.UUID, toolId uuid.UUID) (bool, error) {
+	result := db.DB(ctx).Where("org_id = ? AND id = ?", orgId, toolId).Delete(&models.Tool{})
+	if result.Error != nil {
+		return false, fmt.Errorf("toolRepository.DeleteTool: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *toolRepository) ListToolsByCredential(ctx context.Context, orgId uuid.UUID, credentialId uuid.UUID) ([]*models.Tool, error) {
+	reference, err := json.Marshal(map[string]interface{}{"auth": map[string]string{"credentialId": credentialId.String()}})
+	if err != nil {
+		return nil, fmt.Errorf("toolRepository.ListToolsByCredential: %w", err)
+	}
+	var tools []*models.Tool
+	if err := db.DB(ctx).Where("org_id = ? AND endpoint @> ?::jsonb", orgId, string(reference)).Order("name ASC").Find(&tools).Error; err != nil {
+		return nil, fmt.Errorf("toolRepository.ListToolsByCredential: %w", err)
+	}
+	return tools, nil
+}
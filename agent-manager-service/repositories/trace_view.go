@@ -0,0 +1,91 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package repositories
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/db"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/models"
+)
+
+// TraceViewRepository stores saved trace views, users see the views they saved and those shared with the organization
+type TraceViewRepository interface {
+	ListTraceViews(ctx context.Context, orgId uuid.UUID, userIdpId uuid.UUID, limit int, offset int) ([]*models.TraceView, int64, error)
+	GetTraceView(ctx context.Context, orgId uuid.UUID, userIdpId uuid.UUID, viewId uuid.UUID) (*models.TraceView, error)
+	CreateTraceView(ctx context.Context, view *models.TraceView) error
+	// DeleteTraceView deletes a view the user saved and reports whether it existed
+	DeleteTraceView(ctx context.Context, orgId uuid.UUID, userIdpId uuid.UUID, viewId uuid.UUID) (bool, error)
+}
+
+type traceViewRepository struct{}
+
+func NewTraceViewRepository() TraceViewRepository {
+	return &traceViewRepository{}
+}
+
+// visibleTraceViews scopes a query to the views of the organization the user saved or that are shared with it
+func visibleTraceViews(query *gorm.DB, orgId uuid.UUID, userIdpId uuid.UUID) *gorm.DB {
+	return query.Where("org_id = ? AND (created_by = ? OR visibility = 'org')", orgId, userIdpId)
+}
+
+func (r *traceViewRepository) ListTraceViews(ctx context.Context, orgId uuid.UUID, userIdpId uuid.UUID, limit int, offset int) ([]*models.TraceView, int64, error) {
+	query := visibleTraceViews(db.DB(ctx).Model(&models.TraceView{}), orgId, userIdpId)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("traceViewRepository.ListTraceViews: %w", err)
+	}
+
+	var views []*models.TraceView
+	if err := query.
+		Order("name ASC").
+		Order("id ASC").
+		Limit(limit).
+		Offset(offset).
+		Find(&views).Error; err != nil {
+		return nil, 0, fmt.Errorf("traceViewRepository.ListTraceViews: %w", err)
+	}
+	return views, total, nil
+}
+
+func (r *traceViewRepository) GetTraceView(ctx context.Context, orgId uuid.UUID, userIdpId uuid.UUID, viewId uuid.UUID) (*models.TraceView, error) {
+	var view models.TraceView
+	if err := visibleTraceViews(db.DB(ctx), orgId, userIdpId).Where("id = ?", viewId).First(&view).Error; err != nil {
+		return nil, fmt.Errorf("traceViewRepository.GetTraceView: %w", err)
+	}
+	return &view, nil
+}
+
+func (r *traceViewRepository) CreateTraceView(ctx context.Context, view *models.TraceView) error {
+	if err := db.DB(ctx).Create(view).Error; err != nil {
+		return fmt.Errorf("traceViewRepository.CreateTraceView: %w", err)
+	}
+	return nil
+}
+
+func (r *traceViewRepository) DeleteTraceView(ctx context.Context, orgId uuid.UUID, userIdpId uuid.UUID, viewId uuid.UUID) (bool, error) {
+	result := db.DB(ctx).Where("org_id = ? AND created_by = ? AND id = ?", orgId, userIdpId, viewId).Delete(&models.TraceView{})
+	if result.Error != nil {
+		return false, fmt.Errorf("traceViewRepository.DeleteTraceView: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
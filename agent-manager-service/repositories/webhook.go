@@ -0,0 +1,242 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm/clause"
+
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/db"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/models"
+)
+
+type WebhookRepository interface {
+	ListWebhooks(ctx context.Context, orgId uuid.UUID, page models.ListPage) ([]*models.Webhook, *models.ListCursor, int64, error)
+	GetWebhookById(ctx context.Context, orgId uuid.UUID, webhookId uuid.UUID) (*models.Webhook, error)
+	// GetWebhook returns the webhook with the given id whatever organization it belongs to
+	GetWebhook(ctx context.Context, webhookId uuid.UUID) (*models.Webhook, error)
+	CreateWebhook(ctx context.Context, webhook *models.Webhook) error
+	// UpdateWebhook replaces the URL, description, events, active flag and sealed secret of a webhook and reports whether it exists
+	UpdateWebhook(ctx context.Context, webhook *models.Webhook) (bool, error)
+	// DeleteWebhook removes the webhook together with its deliveries
+	DeleteWebhook(ctx context.Context, orgId uuid.UUID, webhookId uuid.UUID) (bool, error)
+	// ListSubscribedWebhooks returns the active webhooks of the organization subscribed to the event type
+	ListSubscribedWebhooks(ctx context.Context, orgId uuid.UUID, eventType string) ([]*models.Webhook, error)
+	// LockWebhooksNotUnderKey returns the webhooks of the organization whose secret is encrypted with another
+	// master key than keyId, locked until the transaction ends
+	LockWebhooksNotUnderKey(ctx context.Context, orgId uuid.UUID, keyId string) ([]*models.Webhook, error)
+	// UpdateWebhookKey replaces the master key and encrypted data key of a webhook, the secret is left untouched
+	UpdateWebhookKey(ctx context.Context, webhookId uuid.UUID, keyId string, encryptedKey []byte) error
+
+	CreateWebhookDeliveries(ctx context.Context, deliveries []*models.WebhookDelivery) error
+	// ListWebhookDeliveries returns the deliveries of a webhook, newest first
+	ListWebhookDeliveries(ctx context.Context, webhookId uuid.UUID, limit int, offset int) ([]*models.WebhookDelivery, int64, error)
+	GetWebhookDelivery(ctx context.Context, webhookId uuid.UUID, deliveryId uuid.UUID) (*models.WebhookDelivery, error)
+	// ClaimDueWebhookDeliveries returns up to limit pending deliveries due at now and moves their next attempt to
+	// leaseUntil, so other dispatchers skip them while they are sent
+	ClaimDueWebhookDeliveries(ctx context.Context, now time.Time, leaseUntil time.Time, limit int) ([]*models.WebhookDelivery, error)
+	// UpdateWebhookDelivery records the status, attempts and next attempt of a delivery
+	UpdateWebhookDelivery(ctx context.Context, delivery *models.WebhookDelivery) error
+	// PurgeWebhookDeliveries removes the deliveries completed before the given time
+	PurgeWebhookDeliveries(ctx context.Context, completedBefore time.Time) (int64, error)
+}
+
+type webhookRepository struct{}
+
+func NewWebhookRepository() WebhookRepository {
+	return &webhookRepository{}
+}
+
+func (r *webhookRepository) ListWebhooks(ctx context.Context, orgId uuid.UUID, page models.ListPage) ([]*models.Webhook, *models.ListCursor, int64, error) {
+	query := db.DB(ctx).Model(&models.Webhook{}).Where("org_id = ?", orgId)
+
+	webhooks, next, total, err := listPage(query, page, func(webhook *models.Webhook) listKey {
+		return listKey{createdAt: webhook.CreatedAt, updatedAt: webhook.UpdatedAt, id: webhook.ID}
+	})
+	if err != nil {
+		return nil, nil, 0, fmt.Errorf("webhookRepository.ListWebhooks: %w", err)
+	}
+	return webhooks, next, total, nil
+}
+
+func (r *webhookRepository) GetWebhookById(ctx context.Context, orgId uuid.UUID, webhookId uuid.UUID) (*models.Webhook, error) {
+	var webhook models.Webhook
+	if err := db.DB(ctx).Where("org_id = ? AND id = ?", orgId, webhookId).First(&webhook).Error; err != nil {
+		return nil, fmt.Errorf("webhookRepository.GetWebhookById: %w", err)
+	}
+	return &webhook, nil
+}
+
+func (r *webhookRepository) GetWebhook(ctx context.Context, webhookId uuid.UUID) (*models.Webhook, error) {
+	var webhook models.Webhook
+	if err := db.DB(ctx).Where("id = ?", webhookId).First(&webhook).Error; err != nil {
+		return nil, fmt.Errorf("webhookRepository.GetWebhook: %w", err)
+	}
+	return &webhook, nil
+}
+
+func (r *webhookRepository) CreateWebhook(ctx context.Context, webhook *models.Webhook) error {
+	if err := db.DB(ctx).Create(webhook).Error; err != nil {
+		return fmt.Errorf("webhookRepository.CreateWebhook: %w", err)
+	}
+	return nil
+}
+
+func (r *webhookRepository) UpdateWebhook(ctx context.Context, webhook *models.Webhook) (bool, error) {
+	result := db.DB(ctx).Model(&models.Webhook{}).
+		Where("org_id = ? AND id = ?", webhook.OrgID, webhook.ID).
+		Select("url", "description", "events", "active", "key_id", "encrypted_key", "encrypted_secret", "updated_at").
+		Updates(&models.Webhook{
+			URL:             webhook.URL,
+			Description:     webhook.Description,
+			Events:          webhook.Events,
+			Active:          webhook.Active,
+			KeyID:           webhook.KeyID,
+			EncryptedKey:    webhook.EncryptedKey,
+			EncryptedSecret: webhook.EncryptedSecret,
+			UpdatedAt:       webhook.UpdatedAt,
+		})
+	if result.Error != nil {
+		return false, fmt.Errorf("webhookRepository.UpdateWebhook: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *webhookRepository) DeleteWebhook(ctx context.Context, orgId uuid.UUID, webhookId uuid.UUID) (bool, error) {
+	result := db.DB(ctx).Where("org_id = ? AND id = ?", orgId, webhookId).Delete(&models.Webhook{})
+	if result.Error != nil {
+		return false, fmt.Errorf("webhookRepository.DeleteWebhook: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *webhookRepository) ListSubscribedWebhooks(ctx context.Context, orgId uuid.UUID, eventType string) ([]*models.Webhook, error) {
+	subscription, err := json.Marshal([]string{eventType})
+	if err != nil {
+		return nil, fmt.Errorf("webhookRepository.ListSubscribedWebhooks: %w", err)
+	}
+	var webhooks []*models.Webhook
+	if err := db.DB(ctx).
+		Where("org_id = ? AND active AND events @> ?::jsonb", orgId, string(subscription)).
+		Order("id ASC").
+		Find(&webhooks).Error; err != nil {
+		return nil, fmt.Errorf("webhookRepository.ListSubscribedWebhooks: %w", err)
+	}
+	return webhooks, nil
+}
+
+func (r *webhookRepository) LockWebhooksNotUnderKey(ctx context.Context, orgId uuid.UUID, keyId string) ([]*models.Webhook, error) {
+	var webhooks []*models.Webhook
+	if err := db.DB(ctx).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("org_id = ? AND key_id <> ?", orgId, keyId).
+		Order("id ASC").
+		Find(&webhooks).Error; err != nil {
+		return nil, fmt.Errorf("webhookRepository.LockWebhooksNotUnderKey: %w", err)
+	}
+	return webhooks, nil
+}
+
+func (r *webhookRepository) UpdateWebhookKey(ctx context.Context, webhookId uuid.UUID, keyId string, encryptedKey []byte) error {
+	if err := db.DB(ctx).Model(&models.Webhook{}).
+		Where("id = ?", webhookId).
+		Updates(map[string]interface{}{"key_id": keyId, "encrypted_key": encryptedKey}).Error; err != nil {
+		return fmt.Errorf("webhookRepository.UpdateWebhookKey: %w", err)
+	}
+	return nil
+}
+
+func (r *webhookRepository) CreateWebhookDeliveries(ctx context.Context, deliveries []*models.WebhookDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+	if err := db.DB(ctx).Create(deliveries).Error; err != nil {
+		return fmt.Errorf("webhookRepository.CreateWebhookDeliveries: %w", err)
+	}
+	return nil
+}
+
+func (r *webhookRepository) ListWebhookDeliveries(ctx context.Context, webhookId uuid.UUID, limit int, offset int) ([]*models.WebhookDelivery, int64, error) {
+	query := db.DB(ctx).Model(&models.WebhookDelivery{}).Where("webhook_id = ?", webhookId)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("webhookRepository.ListWebhookDeliveries: %w", err)
+	}
+
+	var deliveries []*models.WebhookDelivery
+	if err := query.
+		Order("created_at DESC, id DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&deliveries).Error; err != nil {
+		return nil, 0, fmt.Errorf("webhookRepository.ListWebhookDeliveries: %w", err)
+	}
+	return deliveries, total, nil
+}
+
+func (r *webhookRepository) GetWebhookDelivery(ctx context.Context, webhookId uuid.UUID, deliveryId uuid.UUID) (*models.WebhookDelivery, error) {
+	var delivery models.WebhookDelivery
+	if err := db.DB(ctx).Where("webhook_id = ? AND id = ?", webhookId, deliveryId).First(&delivery).Error; err != nil {
+		return nil, fmt.Errorf("webhookRepository.GetWebhookDelivery: %w", err)
+	}
+	return &delivery, nil
+}
+
+func (r *webhookRepository) ClaimDueWebhookDeliveries(ctx context.Context, now time.Time, leaseUntil time.Time, limit int) ([]*models.WebhookDelivery, error) {
+	var deliveries []*models.WebhookDelivery
+	if err := db.DB(ctx).Raw(`UPDATE webhook_deliveries SET next_attempt_at = ?
+WHERE id IN (
+   SELECT id FROM webhook_deliveries
+   WHERE status = 'pending' AND next_attempt_at <= ?
+   ORDER BY next_attempt_at ASC
+   LIMIT ?
+   FOR UPDATE SKIP LOCKED
+)
+RETURNING *`, leaseUntil, now, limit).Scan(&deliveries).Error; err != nil {
+		return nil, fmt.Errorf("webhookRepository.ClaimDueWebhookDeliveries: %w", err)
+	}
+	return deliveries, nil
+}
+
+func (r *webhookRepository) UpdateWebhookDelivery(ctx context.Context, delivery *models.WebhookDelivery) error {
+	if err := db.DB(ctx).Model(&models.WebhookDelivery{}).
+		Where("id = ?", delivery.ID).
+		Select("status", "attempts", "next_attempt_at", "completed_at").
+		Updates(&models.WebhookDelivery{
+			Status:        delivery.Status,
+			Attempts:      delivery.Attempts,
+			NextAttemptAt: delivery.NextAttemptAt,
+			CompletedAt:   delivery.CompletedAt,
+		}).Error; err != nil {
+		return fmt.Errorf("webhookRepository.UpdateWebhookDelivery: %w", err)
+	}
+	return nil
+}
+
+func (r *webhookRepository) PurgeWebhookDeliveries(ctx context.Context, completedBefore time.Time) (int64, error) {
+	result := db.DB(ctx).Where("completed_at < ?", completedBefore).Delete(&models.WebhookDelivery{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("webhookRepository.PurgeWebhookDeliveries: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
@@ -0,0 +1,119 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/db"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/models"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/utils"
+)
+
+// errDryRun rolls back the transaction of an operation that is only tried
+var errDryRun = errors.New("dry run")
+
+func (s *managedAgentService) ApplyManagedAgentOperation(ctx context.Context, userIdpId uuid.UUID, orgName string, agentId uuid.UUID, op models.AgentBatchOperation, dryRun bool) (*models.ManagedAgent, []models.ConfigChange, error) {
+	s.logger.InfoContext(ctx, "Applying managed agent operation", "agentId", agentId, "action", op.Action, "dryRun", dryRun, "orgName", orgName, "userIdpId", userIdpId)
+	org, err := s.getOrganization(ctx, userIdpId, orgName)
+	if err != nil {
+		return nil, nil, err
+	}
+	// Operations of a batch run concurrently, the audit entry records the batch rather than each agent
+	ctx = withoutAuditLog(ctx)
+
+	var agent *models.ManagedAgent
+	var changes []models.ConfigChange
+	err = db.WithTx(ctx, func(txCtx context.Context) error {
+		var err error
+		if op.Action == utils.BatchActionDelete {
+			err = s.deleteManagedAgent(txCtx, org.ID, agentId)
+		} else {
+			agent, changes, err = s.updateManagedAgentByOperation(txCtx, userIdpId, org.ID, agentId, op)
+		}
+		if err != nil {
+			return err
+		}
+		if dryRun {
+			return errDryRun
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errDryRun) {
+		s.logger.ErrorContext(ctx, "Failed to apply managed agent operation", "agentId", agentId, "action", op.Action, "orgId", org.ID, "error", err)
+		return nil, nil, err
+	}
+	if !dryRun {
+		switch {
+		case op.Action == utils.BatchActionDelete:
+			s.WebhookService.PublishEvent(ctx, org, utils.WebhookEventAgentDeleted, deletedAgentEvent(agentId))
+		case len(changes) > 0:
+			s.WebhookService.PublishEvent(ctx, org, utils.WebhookEventAgentUpdated, utils.ConvertToManagedAgentResponse(agent))
+		}
+	}
+	s.logger.InfoContext(ctx, "Managed agent operation applied successfully", "agentId", agentId, "action", op.Action, "dryRun", dryRun, "orgName", orgName)
+	return agent, changes, nil
+}
+
+// deletedAgentEvent is the data of agent.deleted events, the agent can still be read while it is restorable
+func deletedAgentEvent(agentId uuid.UUID) map[string]string {
+	return map[string]string{"id": agentId.String()}
+}
+
+func (s *managedAgentService) deleteManagedAgent(ctx context.Context, orgId uuid.UUID, agentId uuid.UUID) error {
+	deleted, err := s.ManagedAgentRepository.DeleteManagedAgent(ctx, orgId, agentId)
+	if err != nil {
+		return fmt.Errorf("failed to delete managed agent %s: %w", agentId, err)
+	}
+	if !deleted {
+		return utils.ErrAgentNotFound
+	}
+	return nil
+}
+
+// updateManagedAgentByOperation records the configuration an operation results in as a new version, an agent
+// already in the requested state is left at its version
+func (s *managedAgentService) updateManagedAgentByOperation(ctx context.Context, userIdpId uuid.UUID, orgId uuid.UUID, agentId uuid.UUID, op models.AgentBatchOperation) (*models.ManagedAgent, []models.ConfigChange, error) {
+	current, err := s.getManagedAgent(ctx, orgId, agentId)
+	if err != nil {
+		return nil, nil, err
+	}
+	config := utils.ManagedAgentConfig(current)
+	next, err := utils.ApplyAgentBatchOperation(config, op)
+	if err != nil {
+		return nil, nil, err
+	}
+	changes, err := utils.DiffManagedAgentConfigs(config, next)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to diff managed agent %s with the result of %s: %w", agentId, op.Action, err)
+	}
+	if len(changes) == 0 {
+		return current, nil, nil
+	}
+	// The changes were computed on the version read above, a concurrent write in between fails the operation
+	updated, err := s.saveManagedAgentVersion(ctx, userIdpId, orgId, agentId, &current.Version, func(txCtx context.Context, current *models.ManagedAgent) (*models.ManagedAgentRequest, *int32, error) {
+		return &next, nil, nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return updated, changes, nil
+}
@@ -0,0 +1,358 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/clients/traceobserversvc"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/db"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/models"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/repositories"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/utils"
+)
+
+// Interval the usage of a period is read from the trace observer with, only the summary of the period is used
+const budgetUsageInterval = "1d"
+
+// How long alerts are kept, past the end of any period that started before
+const budgetAlertRetention = 62 * 24 * time.Hour
+
+type AgentBudgetService interface {
+	// GetAgentBudget returns the budget of an agent together with its usage in the current periods
+	GetAgentBudget(ctx context.Context, userIdpId uuid.UUID, orgName string, agentId uuid.UUID) (*models.AgentBudget, []models.BudgetPeriodUsage, error)
+	// SaveAgentBudget creates or replaces the budget of an agent, thresholds still reached are alerted on again
+	SaveAgentBudget(ctx context.Context, userIdpId uuid.UUID, orgName string, agentId uuid.UUID, req *models.AgentBudgetRequest) (*models.AgentBudget, error)
+	DeleteAgentBudget(ctx context.Context, userIdpId uuid.UUID, orgName string, agentId uuid.UUID) error
+	// CheckBudgets compares the usage of every agent with a budget against its limits and publishes an event when
+	// an alert threshold is reached, once per threshold and period
+	CheckBudgets(ctx context.Context) error
+	// RunChecker checks budgets every interval until ctx is done
+	RunChecker(ctx context.Context, interval time.Duration)
+}
+
+type agentBudgetService struct {
+	OrganizationRepository repositories.OrganizationRepository
+	ManagedAgentRepository repositories.ManagedAgentRepository
+	AgentBudgetRepository  repositories.AgentBudgetRepository
+	traceObserverClient    traceobserversvc.TraceObserverClient
+	WebhookService         WebhookService
+	logger                 *slog.Logger
+}
+
+func NewAgentBudgetService(
+	orgRepo repositories.OrganizationRepository,
+	managedAgentRepo repositories.ManagedAgentRepository,
+	agentBudgetRepo repositories.AgentBudgetRepository,
+	traceObserverClient traceobserversvc.TraceObserverClient,
+	webhookService WebhookService,
+	logger *slog.Logger,
+) AgentBudgetService {
+	return &agentBudgetService{
+		OrganizationRepository: orgRepo,
+		ManagedAgentRepository: managedAgentRepo,
+		AgentBudgetRepository:  agentBudgetRepo,
+		traceObserverClient:    traceObserverClient,
+		WebhookService:         webhookService,
+		logger:                 logger,
+	}
+}
+
+// getManagedAgent returns an agent of the organization that is not deleted
+func (s *agentBudgetService) getManagedAgent(ctx context.Context, userIdpId uuid.UUID, orgName string, agentId uuid.UUID) (*models.ManagedAgent, error) {
+	org, err := s.OrganizationRepository.GetOrganizationByOrgName(ctx, userIdpId, orgName)
+	if err != nil {
+		if db.IsRecordNotFoundError(err) {
+			s.logger.ErrorContext(ctx, "Organization not found", "orgName", orgName, "userIdpId", userIdpId)
+			return nil, utils.ErrOrganizationNotFound
+		}
+		s.logger.ErrorContext(ctx, "Failed to find organization", "orgName", orgName, "userIdpId", userIdpId, "error", err)
+		return nil, fmt.Errorf("failed to find organization %s: %w", orgName, err)
+	}
+	agent, err := s.ManagedAgentRepository.GetManagedAgentById(ctx, org.ID, agentId)
+	if err != nil {
+		if db.IsRecordNotFoundError(err) {
+			return nil, utils.ErrAgentNotFound
+		}
+		s.logger.ErrorContext(ctx, "Failed to find managed agent", "agentId", agentId, "orgId", org.ID, "error", err)
+		return nil, fmt.Errorf("failed to find managed agent %s: %w", agentId, err)
+	}
+	return agent, nil
+}
+
+func (s *agentBudgetService) GetAgentBudget(ctx context.Context, userIdpId uuid.UUID, orgName string, agentId uuid.UUID) (*models.AgentBudget, []models.BudgetPeriodUsage, error) {
+	s.logger.InfoContext(ctx, "Getting agent budget", "agentId", agentId, "orgName", orgName, "userIdpId", userIdpId)
+	agent, err := s.getManagedAgent(ctx, userIdpId, orgName, agentId)
+	if err != nil {
+		return nil, nil, err
+	}
+	budget, err := s.AgentBudgetRepository.GetAgentBudget(ctx, agent.OrgID, agent.ID)
+	if err != nil {
+		if db.IsRecordNotFoundError(err) {
+			return nil, nil, utils.ErrAgentBudgetNotFound
+		}
+		s.logger.ErrorContext(ctx, "Failed to find agent budget", "agentId", agentId, "error", err)
+		return nil, nil, fmt.Errorf("failed to find budget of agent %s: %w", agentId, err)
+	}
+	usage, err := s.budgetUsage(ctx, budget, time.Now())
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to read agent budget usage", "agentId", agentId, "error", err)
+		return nil, nil, err
+	}
+	return budget, usage, nil
+}
+
+func (s *agentBudgetService) SaveAgentBudget(ctx context.Context, userIdpId uuid.UUID, orgName string, agentId uuid.UUID, req *models.AgentBudgetRequest) (*models.AgentBudget, error) {
+	s.logger.InfoContext(ctx, "Saving agent budget", "agentId", agentId, "orgName", orgName, "userIdpId", userIdpId)
+	agent, err := s.getManagedAgent(ctx, userIdpId, orgName, agentId)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	budget := &models.AgentBudget{
+		AgentID:         agent.ID,
+		OrgID:           agent.OrgID,
+		Timezone:        req.Timezone,
+		AlertThresholds: utils.NormalizeBudgetAlertThresholds(req.AlertThresholds),
+		Enforcement:     req.Enforcement,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+	if budget.Timezone == "" {
+		budget.Timezone = utils.DefaultBudgetTimezone
+	}
+	if budget.Enforcement == "" {
+		budget.Enforcement = utils.BudgetEnforcementNone
+	}
+	if req.Daily != nil {
+		budget.DailyMaxCost, budget.DailyMaxTokens = req.Daily.MaxCost, req.Daily.MaxTokens
+	}
+	if req.Monthly != nil {
+		budget.MonthlyMaxCost, budget.MonthlyMaxTokens = req.Monthly.MaxCost, req.Monthly.MaxTokens
+	}
+	if err := s.AgentBudgetRepository.SaveAgentBudget(ctx, budget); err != nil {
+		s.logger.ErrorContext(ctx, "Failed to save agent budget", "agentId", agentId, "error", err)
+		return nil, fmt.Errorf("failed to save budget of agent %s: %w", agentId, err)
+	}
+	s.logger.InfoContext(ctx, "Agent budget saved successfully", "agentId", agentId, "orgName", orgName)
+	return budget, nil
+}
+
+func (s *agentBudgetService) DeleteAgentBudget(ctx context.Context, userIdpId uuid.UUID, orgName string, agentId uuid.UUID) error {
+	s.logger.InfoContext(ctx, "Deleting agent budget", "agentId", agentId, "orgName", orgName, "userIdpId", userIdpId)
+	agent, err := s.getManagedAgent(ctx, userIdpId, orgName, agentId)
+	if err != nil {
+		return err
+	}
+	deleted, err := s.AgentBudgetRepository.DeleteAgentBudget(ctx, agent.OrgID, agent.ID)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to delete agent budget", "agentId", agentId, "error", err)
+		return fmt.Errorf("failed to delete budget of agent %s: %w", agentId, err)
+	}
+	if !deleted {
+		return utils.ErrAgentBudgetNotFound
+	}
+	s.logger.InfoContext(ctx, "Agent budget deleted successfully", "agentId", agentId, "orgName", orgName)
+	return nil
+}
+
+// budgetUsage reads what the agent consumed in the current day and month of the budget time zone, periods
+// without limits are skipped
+func (s *agentBudgetService) budgetUsage(ctx context.Context, budget *models.AgentBudget, now time.Time) ([]models.BudgetPeriodUsage, error) {
+	loc, err := time.LoadLocation(budget.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid time zone %q of budget of agent %s: %w", budget.Timezone, budget.AgentID, err)
+	}
+	periods := []struct {
+		name      string
+		maxCost   *float64
+		maxTokens *int64
+	}{
+		{utils.BudgetPeriodDay, budget.DailyMaxCost, budget.DailyMaxTokens},
+		{utils.BudgetPeriodMonth, budget.MonthlyMaxCost, budget.MonthlyMaxTokens},
+	}
+
+	usage := []models.BudgetPeriodUsage{}
+	for _, period := range periods {
+		if period.maxCost == nil && period.maxTokens == nil {
+			continue
+		}
+		start, end := utils.BudgetPeriodBounds(period.name, now, loc)
+		metrics, err := s.traceObserverClient.GetTraceMetrics(ctx, traceobserversvc.TraceMetricsParams{
+			OrgID:     budget.OrgID.String(),
+			AgentID:   budget.AgentID.String(),
+			StartTime: start.UTC().Format(time.RFC3339Nano),
+			// The observer includes the end of the range, which already belongs to the next period
+			EndTime:  end.Add(-time.Nanosecond).UTC().Format(time.RFC3339Nano),
+			Interval: budgetUsageInterval,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s usage of agent %s: %w", period.name, budget.AgentID, err)
+		}
+
+		current := models.BudgetPeriodUsage{
+			Period:    period.name,
+			StartTime: start,
+			EndTime:   end,
+			MaxCost:   period.maxCost,
+			MaxTokens: period.maxTokens,
+		}
+		if metrics.Summary != nil {
+			current.Cost = metrics.Summary.Cost
+			current.Tokens = int64(metrics.Summary.TotalTokens)
+		}
+		if period.maxCost != nil {
+			current.CostPercent = budgetPercent(current.Cost, *period.maxCost)
+			current.Exceeded = current.Exceeded || current.Cost >= *period.maxCost
+		}
+		if period.maxTokens != nil {
+			current.TokensPercent = budgetPercent(float64(current.Tokens), float64(*period.maxTokens))
+			current.Exceeded = current.Exceeded || current.Tokens >= *period.maxTokens
+		}
+		usage = append(usage, current)
+	}
+	return usage, nil
+}
+
+// budgetPercent returns the share of the limit used in percent, rounded to two decimals
+func budgetPercent(used float64, limit float64) *float64 {
+	percent := math.Round(used/limit*10000) / 100
+	return &percent
+}
+
+func (s *agentBudgetService) CheckBudgets(ctx context.Context) error {
+	budgets, err := s.AgentBudgetRepository.ListAgentBudgets(ctx)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to list agent budgets", "error", err)
+		return fmt.Errorf("failed to list agent budgets: %w", err)
+	}
+
+	now := time.Now()
+	orgs := map[uuid.UUID]*models.Organization{}
+	var firstErr error
+	for _, budget := range budgets {
+		if err := s.checkBudget(ctx, budget, now, orgs); err != nil {
+			s.logger.ErrorContext(ctx, "Failed to check agent budget", "agentId", budget.AgentID, "error", err)
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+
+	if purged, err := s.AgentBudgetRepository.PurgeAgentBudgetAlerts(ctx, now.Add(-budgetAlertRetention)); err != nil {
+		s.logger.ErrorContext(ctx, "Failed to purge agent budget alerts", "error", err)
+	} else if purged > 0 {
+		s.logger.InfoContext(ctx, "Purged agent budget alerts", "count", purged)
+	}
+	return firstErr
+}
+
+func (s *agentBudgetService) checkBudget(ctx context.Context, budget *models.AgentBudget, now time.Time, orgs map[uuid.UUID]*models.Organization) error {
+	usage, err := s.budgetUsage(ctx, budget, now)
+	if err != nil {
+		return err
+	}
+	for _, period := range usage {
+		if period.MaxCost != nil {
+			if err := s.alert(ctx, budget, period, utils.BudgetMetricCost, period.Cost, *period.MaxCost, orgs); err != nil {
+				return err
+			}
+		}
+		if period.MaxTokens != nil {
+			if err := s.alert(ctx, budget, period, utils.BudgetMetricTokens, float64(period.Tokens), float64(*period.MaxTokens), orgs); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// alert records the thresholds of the limit the usage reaches and publishes one event for the highest of those
+// not recorded before, so usage jumping past several thresholds at once raises a single alert
+func (s *agentBudgetService) alert(ctx context.Context, budget *models.AgentBudget, period models.BudgetPeriodUsage, metric string, used float64, limit float64, orgs map[uuid.UUID]*models.Organization) error {
+	reached := utils.BudgetThresholdsReached(budget.AlertThresholds, used, limit)
+	if len(reached) == 0 {
+		return nil
+	}
+	org, ok := orgs[budget.OrgID]
+	if !ok {
+		var err error
+		if org, err = s.OrganizationRepository.GetOrganizationById(ctx, budget.OrgID); err != nil {
+			return fmt.Errorf("failed to find organization %s: %w", budget.OrgID, err)
+		}
+		orgs[budget.OrgID] = org
+	}
+
+	return db.WithTx(ctx, func(txCtx context.Context) error {
+		var highest int32
+		for _, threshold := range reached {
+			recorded, err := s.AgentBudgetRepository.RecordAgentBudgetAlert(txCtx, &models.AgentBudgetAlert{
+				AgentID:     budget.AgentID,
+				Period:      period.Period,
+				PeriodStart: period.StartTime.UTC(),
+				Metric:      metric,
+				Threshold:   threshold,
+				AlertedAt:   time.Now(),
+			})
+			if err != nil {
+				return fmt.Errorf("failed to record %s alert of agent %s: %w", metric, budget.AgentID, err)
+			}
+			if recorded {
+				highest = threshold
+			}
+		}
+		if highest == 0 {
+			return nil
+		}
+		s.logger.InfoContext(ctx, "Agent budget threshold reached", "agentId", budget.AgentID, "period", period.Period,
+			"metric", metric, "threshold", highest, "used", used, "limit", limit)
+		s.WebhookService.PublishEvent(txCtx, org, utils.WebhookEventAgentBudgetThreshold, models.AgentBudgetAlertEvent{
+			AgentID:     budget.AgentID.String(),
+			AgentName:   budget.AgentName,
+			Period:      period.Period,
+			PeriodStart: period.StartTime,
+			PeriodEnd:   period.EndTime,
+			Metric:      metric,
+			Threshold:   highest,
+			Limit:       limit,
+			Used:        used,
+			Percent:     *budgetPercent(used, limit),
+			Enforcement: budget.Enforcement,
+		})
+		return nil
+	})
+}
+
+func (s *agentBudgetService) RunChecker(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			_ = s.CheckBudgets(ctx)
+		}
+	}
+}
@@ -0,0 +1,466 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/db"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/models"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/utils"
+)
+
+// agentImport is the plan of an import together with the resources it writes when applied
+type agentImport struct {
+	models.AgentImportPlan
+	orgId uuid.UUID
+	// Prompt to create, or to add a version to when promptVersion is set, nil when a matching version exists
+	prompt        *models.PromptTemplate
+	promptVersion int32
+	newTools      []*models.Tool
+	// Existing agent of the same name, nil when the agent is created
+	agent   *models.ManagedAgent
+	request models.ManagedAgentRequest
+}
+
+func (s *managedAgentService) ExportManagedAgent(ctx context.Context, userIdpId uuid.UUID, orgName string, agentId uuid.UUID) (*models.AgentBundle, error) {
+	s.logger.InfoContext(ctx, "Exporting managed agent", "agentId", agentId, "orgName", orgName, "userIdpId", userIdpId)
+	org, err := s.getOrganization(ctx, userIdpId, orgName)
+	if err != nil {
+		return nil, err
+	}
+	agent, err := s.getManagedAgent(ctx, org.ID, agentId)
+	if err != nil {
+		return nil, err
+	}
+
+	bundle := &models.AgentBundle{
+		SchemaVersion: models.AgentBundleSchemaVersion,
+		ExportedAt:    time.Now().UTC(),
+		Agent: models.AgentBundleAgent{
+			Name:                agent.Name,
+			Description:         agent.Description,
+			Framework:           agent.Framework,
+			ModelConfig:         agent.ModelConfig,
+			SystemPrompt:        agent.SystemPrompt,
+			Labels:              agent.Labels,
+			ConfigSchemaVersion: agent.ConfigSchemaVersion,
+			AllowUnvalidated:    agent.ConfigSchemaVersion == nil,
+		},
+	}
+	if agent.PromptID != nil && agent.PromptVersion != nil {
+		prompt, err := s.PromptTemplateRepository.GetPromptTemplateById(ctx, org.ID, *agent.PromptID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find prompt %s of managed agent %s: %w", agent.PromptID, agentId, err)
+		}
+		version, err := s.PromptTemplateRepository.GetPromptTemplateVersion(ctx, prompt.ID, *agent.PromptVersion)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find version %d of prompt %s: %w", *agent.PromptVersion, prompt.ID, err)
+		}
+		bundle.Agent.Prompt = prompt.Name
+		bundle.Prompt = &models.AgentBundlePrompt{
+			PromptTemplateRequest: models.PromptTemplateRequest{
+				Name:        prompt.Name,
+				Description: version.Description,
+				Template:    version.Template,
+				Variables:   version.Variables,
+				Labels:      prompt.Labels,
+			},
+			Version: version.Version,
+		}
+	}
+
+	referenced := utils.ParseToolReferenceIds(agent.Tools)
+	toolIds := make([]uuid.UUID, 0, len(referenced))
+	for _, toolId := range referenced {
+		toolIds = append(toolIds, toolId)
+	}
+	found, err := s.ToolRepository.GetToolsByIds(ctx, org.ID, toolIds)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find tools of managed agent %s: %w", agentId, err)
+	}
+	tools := make(map[uuid.UUID]*models.Tool, len(found))
+	for _, tool := range found {
+		tools[tool.ID] = tool
+	}
+	for i, reference := range agent.Tools {
+		toolId, registered := referenced[i]
+		if !registered {
+			bundle.Agent.Tools = append(bundle.Agent.Tools, models.AgentBundleToolReference{Name: reference.Name})
+			continue
+		}
+		tool, ok := tools[toolId]
+		if !ok {
+			s.logger.WarnContext(ctx, "Agent references a tool that no longer exists", "agentId", agentId, "toolId", toolId)
+			continue
+		}
+		bundle.Agent.Tools = append(bundle.Agent.Tools, models.AgentBundleToolReference{Tool: tool.Name})
+		bundle.Tools = append(bundle.Tools, models.ToolRequest{
+			Name:        tool.Name,
+			Description: tool.Description,
+			Parameters:  tool.Parameters,
+			Endpoint:    tool.Endpoint,
+			Tags:        tool.Tags,
+			Labels:      tool.Labels,
+		})
+	}
+	return bundle, nil
+}
+
+func (s *managedAgentService) ImportManagedAgent(ctx context.Context, userIdpId uuid.UUID, orgName string, bundle *models.AgentBundle, dryRun bool) (*models.AgentImportPlan, *models.ManagedAgent, error) {
+	s.logger.InfoContext(ctx, "Importing managed agent", "agentName", bundle.Agent.Name, "dryRun", dryRun, "orgName", orgName, "userIdpId", userIdpId)
+	org, err := s.getOrganization(ctx, userIdpId, orgName)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var plan *agentImport
+	var imported *models.ManagedAgent
+	err = db.WithTx(ctx, func(txCtx context.Context) error {
+		var err error
+		if plan, err = s.planAgentImport(txCtx, org.ID, bundle); err != nil {
+			return err
+		}
+		if dryRun {
+			return nil
+		}
+		if len(plan.Conflicts) > 0 {
+			return &utils.ImportConflictError{Conflicts: plan.Conflicts}
+		}
+		imported, err = s.applyAgentImport(txCtx, userIdpId, orgName, plan)
+		return err
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to import managed agent", "agentName", bundle.Agent.Name, "orgId", org.ID, "error", err)
+		return nil, nil, err
+	}
+	if dryRun {
+		for i := range plan.Changes {
+			if plan.Changes[i].Action == utils.ImportActionCreate {
+				plan.Changes[i].ID = ""
+			}
+		}
+		return &plan.AgentImportPlan, nil, nil
+	}
+	recordAuditResource(ctx, imported.ID.String())
+	// Created agents were published by CreateManagedAgent as part of the import
+	if plan.Changes[len(plan.Changes)-1].Action == utils.ImportActionUpdate {
+		s.WebhookService.PublishEvent(ctx, org, utils.WebhookEventAgentUpdated, utils.ConvertToManagedAgentResponse(imported))
+	}
+	s.logger.InfoContext(ctx, "Managed agent imported successfully", "agentId", imported.ID, "version", imported.Version, "orgName", orgName)
+	return &plan.AgentImportPlan, imported, nil
+}
+
+// planAgentImport matches the prompt, tools and agent of a bundle with the resources of the organization by name
+func (s *managedAgentService) planAgentImport(ctx context.Context, orgId uuid.UUID, bundle *models.AgentBundle) (*agentImport, error) {
+	plan := &agentImport{
+		AgentImportPlan: models.AgentImportPlan{
+			Changes:   []models.AgentImportChange{},
+			Conflicts: []models.AgentImportConflict{},
+		},
+		orgId: orgId,
+	}
+	plan.request = models.ManagedAgentRequest{
+		Name:                bundle.Agent.Name,
+		Description:         bundle.Agent.Description,
+		Framework:           bundle.Agent.Framework,
+		ModelConfig:         bundle.Agent.ModelConfig,
+		SystemPrompt:        bundle.Agent.SystemPrompt,
+		Labels:              bundle.Agent.Labels,
+		ConfigSchemaVersion: bundle.Agent.ConfigSchemaVersion,
+		AllowUnvalidated:    bundle.Agent.AllowUnvalidated,
+	}
+	if bundle.Prompt != nil {
+		ref, err := s.planPromptImport(ctx, plan, bundle.Prompt)
+		if err != nil {
+			return nil, err
+		}
+		plan.request.PromptRef = ref
+	}
+	toolIds, err := s.planToolImports(ctx, plan, bundle.Tools)
+	if err != nil {
+		return nil, err
+	}
+	for _, reference := range bundle.Agent.Tools {
+		if reference.Tool == "" {
+			plan.request.Tools = append(plan.request.Tools, models.ToolReference{Name: reference.Name})
+			continue
+		}
+		plan.request.Tools = append(plan.request.Tools, models.ToolReference{ToolID: toolIds[reference.Tool].String()})
+	}
+	if err := s.planAgentUpsert(ctx, plan); err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+// planPromptImport references a version of the prompt of the same name with the bundled template, a prompt
+// without one gets a new version, which leaves the agents pinned to earlier versions untouched
+func (s *managedAgentService) planPromptImport(ctx context.Context, plan *agentImport, bundled *models.AgentBundlePrompt) (*models.PromptReference, error) {
+	variables := utils.NormalizePromptVariables(bundled.Template, bundled.Variables)
+	now := time.Now()
+	existing, err := s.PromptTemplateRepository.GetPromptTemplateByName(ctx, plan.orgId, bundled.Name)
+	if err != nil {
+		if !db.IsRecordNotFoundError(err) {
+			return nil, fmt.Errorf("failed to find prompt %s: %w", bundled.Name, err)
+		}
+		plan.prompt = &models.PromptTemplate{
+			ID:          uuid.New(),
+			OrgID:       plan.orgId,
+			Name:        bundled.Name,
+			Description: bundled.Description,
+			Template:    bundled.Template,
+			Variables:   variables,
+			Labels:      bundled.Labels,
+			Version:     1,
+			CreatedAt:   now,
+			UpdatedAt:   now,
+		}
+		plan.Changes = append(plan.Changes, models.AgentImportChange{
+			Kind: "prompt", Name: bundled.Name, ID: plan.prompt.ID.String(), Action: utils.ImportActionCreate, Version: 1,
+		})
+		return &models.PromptReference{PromptID: plan.prompt.ID.String(), Version: 1}, nil
+	}
+
+	matching, err := s.findPromptVersion(ctx, existing.ID, bundled.Template, variables)
+	if err != nil {
+		return nil, err
+	}
+	if matching != nil {
+		plan.Changes = append(plan.Changes, models.AgentImportChange{
+			Kind: "prompt", Name: existing.Name, ID: existing.ID.String(), Action: utils.ImportActionUnchanged, Version: matching.Version,
+		})
+		return &models.PromptReference{PromptID: existing.ID.String(), Version: matching.Version}, nil
+	}
+	plan.prompt = &models.PromptTemplate{
+		ID:          existing.ID,
+		OrgID:       existing.OrgID,
+		Name:        existing.Name,
+		Description: bundled.Description,
+		Template:    bundled.Template,
+		Variables:   variables,
+		Labels:      existing.Labels,
+		CreatedAt:   existing.CreatedAt,
+		UpdatedAt:   now,
+	}
+	plan.promptVersion = existing.Version
+	plan.Changes = append(plan.Changes, models.AgentImportChange{
+		Kind: "prompt", Name: existing.Name, ID: existing.ID.String(), Action: utils.ImportActionUpdate, Version: existing.Version + 1,
+	})
+	return &models.PromptReference{PromptID: existing.ID.String(), Version: existing.Version + 1}, nil
+}
+
+// findPromptVersion returns the latest version of a prompt with the given template and variables, nil when there is none
+func (s *managedAgentService) findPromptVersion(ctx context.Context, promptId uuid.UUID, template string, variables []models.PromptVariable) (*models.PromptTemplateVersion, error) {
+	for offset := 0; ; offset += utils.MaxLimit {
+		versions, total, err := s.PromptTemplateRepository.ListPromptTemplateVersions(ctx, promptId, utils.MaxLimit, offset)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list versions of prompt %s: %w", promptId, err)
+		}
+		for _, version := range versions {
+			if version.Template == template && reflect.DeepEqual(utils.NormalizePromptVariables(version.Template, version.Variables), variables) {
+				return version, nil
+			}
+		}
+		if int64(offset+len(versions)) >= total || len(versions) == 0 {
+			return nil, nil
+		}
+	}
+}
+
+// planToolImports reuses the tools of the same name with the bundled definition and creates the missing ones.
+// Tools of the same name with another definition are conflicts, as other agents may use them
+func (s *managedAgentService) planToolImports(ctx context.Context, plan *agentImport, bundled []models.ToolRequest) (map[string]uuid.UUID, error) {
+	toolIds := make(map[string]uuid.UUID, len(bundled))
+	now := time.Now()
+	for i, tool := range bundled {
+		existing, err := s.ToolRepository.GetToolByName(ctx, plan.orgId, tool.Name)
+		switch {
+		case err == nil:
+			toolIds[tool.Name] = existing.ID
+			same, err := sameToolDefinition(existing, tool)
+			if err != nil {
+				return nil, err
+			}
+			if !same {
+				plan.Conflicts = append(plan.Conflicts, models.AgentImportConflict{
+					Field:   fmt.Sprintf("tools[%d]", i),
+					Message: fmt.Sprintf("tool %s exists in the organization with a different definition, align or rename one of them", tool.Name),
+				})
+				continue
+			}
+			plan.Changes = append(plan.Changes, models.AgentImportChange{
+				Kind: "tool", Name: tool.Name, ID: existing.ID.String(), Action: utils.ImportActionUnchanged,
+			})
+		case db.IsRecordNotFoundError(err):
+			// Credential ids are specific to the organization the bundle was exported from
+			exists, err := toolCredentialExists(ctx, s.CredentialRepository, plan.orgId, tool.Endpoint)
+			if err != nil {
+				return nil, err
+			}
+			if !exists {
+				plan.Conflicts = append(plan.Conflicts, models.AgentImportConflict{
+					Field:   fmt.Sprintf("tools[%d].endpoint.auth.credentialId", i),
+					Message: fmt.Sprintf("credential %s does not exist in the organization, point the tool at a credential of the organization", tool.Endpoint.Auth.CredentialID),
+				})
+				continue
+			}
+			created := &models.Tool{
+				ID:          uuid.New(),
+				OrgID:       plan.orgId,
+				Name:        tool.Name,
+				Description: tool.Description,
+				Parameters:  tool.Parameters,
+				Endpoint:    tool.Endpoint,
+				Tags:        tool.Tags,
+				Labels:      tool.Labels,
+				Version:     1,
+				CreatedAt:   now,
+				UpdatedAt:   now,
+			}
+			plan.newTools = append(plan.newTools, created)
+			toolIds[tool.Name] = created.ID
+			plan.Changes = append(plan.Changes, models.AgentImportChange{
+				Kind: "tool", Name: tool.Name, ID: created.ID.String(), Action: utils.ImportActionCreate,
+			})
+		default:
+			return nil, fmt.Errorf("failed to find tool %s: %w", tool.Name, err)
+		}
+	}
+	return toolIds, nil
+}
+
+// sameToolDefinition compares a registered tool with a bundled one through their JSON forms, labels are
+// not part of the definition
+func sameToolDefinition(existing *models.Tool, bundled models.ToolRequest) (bool, error) {
+	definition := func(tool models.ToolRequest) ([]byte, error) {
+		if len(tool.Tags) == 0 {
+			tool.Tags = nil
+		}
+		tool.Labels = nil
+		return json.Marshal(tool)
+	}
+	existingJSON, err := definition(models.ToolRequest{
+		Name:        existing.Name,
+		Description: existing.Description,
+		Parameters:  existing.Parameters,
+		Endpoint:    existing.Endpoint,
+		Tags:        existing.Tags,
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to encode tool %s: %w", existing.Name, err)
+	}
+	bundledJSON, err := definition(bundled)
+	if err != nil {
+		return false, fmt.Errorf("failed to encode tool %s: %w", bundled.Name, err)
+	}
+	return bytes.Equal(existingJSON, bundledJSON), nil
+}
+
+// planAgentUpsert updates the agent of the same name, or creates the agent when there is none
+func (s *managedAgentService) planAgentUpsert(ctx context.Context, plan *agentImport) error {
+	existing, err := s.ManagedAgentRepository.GetManagedAgentByName(ctx, plan.orgId, plan.request.Name)
+	if err != nil {
+		if !db.IsRecordNotFoundError(err) {
+			return fmt.Errorf("failed to find managed agent %s: %w", plan.request.Name, err)
+		}
+		plan.Changes = append(plan.Changes, models.AgentImportChange{
+			Kind: "agent", Name: plan.request.Name, Action: utils.ImportActionCreate, Version: 1,
+		})
+		return nil
+	}
+	plan.agent = existing
+	// Bundles do not carry whether the agent is enabled, an import keeps it as it is
+	plan.request.Enabled = &existing.Enabled
+	// Bundles without a schema version keep the one the agent was validated against
+	if plan.request.ConfigSchemaVersion == nil && !plan.request.AllowUnvalidated && plan.request.Framework == existing.Framework {
+		plan.request.ConfigSchemaVersion = existing.ConfigSchemaVersion
+		plan.request.AllowUnvalidated = existing.ConfigSchemaVersion == nil
+	}
+	changes, err := utils.DiffManagedAgentConfigs(utils.ManagedAgentConfig(existing), plan.request)
+	if err != nil {
+		return fmt.Errorf("failed to diff managed agent %s with the bundle: %w", existing.ID, err)
+	}
+	change := models.AgentImportChange{
+		Kind: "agent", Name: existing.Name, ID: existing.ID.String(), Action: utils.ImportActionUnchanged, Version: existing.Version,
+	}
+	if len(changes) > 0 {
+		change.Action = utils.ImportActionUpdate
+		change.Version = existing.Version + 1
+		change.Changes = changes
+	}
+	plan.Changes = append(plan.Changes, change)
+	return nil
+}
+
+// applyAgentImport writes the planned prompt, tools and agent
+func (s *managedAgentService) applyAgentImport(ctx context.Context, userIdpId uuid.UUID, orgName string, plan *agentImport) (*models.ManagedAgent, error) {
+	if prompt := plan.prompt; prompt != nil {
+		if plan.promptVersion == 0 {
+			if err := s.PromptTemplateRepository.CreatePromptTemplate(ctx, prompt); err != nil {
+				if db.IsUniqueViolationError(err) {
+					return nil, utils.ErrPromptAlreadyExists
+				}
+				return nil, fmt.Errorf("failed to create prompt %s: %w", prompt.Name, err)
+			}
+		} else {
+			ok, err := s.PromptTemplateRepository.UpdatePromptTemplate(ctx, prompt, plan.promptVersion)
+			if err != nil {
+				return nil, fmt.Errorf("failed to update prompt %s: %w", prompt.ID, err)
+			}
+			if !ok {
+				return nil, utils.ErrPromptVersionMismatch
+			}
+		}
+		if err := s.PromptTemplateRepository.CreatePromptTemplateVersion(ctx, newPromptTemplateVersion(prompt, userIdpId)); err != nil {
+			return nil, fmt.Errorf("failed to record version %d of prompt %s: %w", prompt.Version, prompt.ID, err)
+		}
+	}
+	for _, tool := range plan.newTools {
+		if err := s.ToolRepository.CreateTool(ctx, tool); err != nil {
+			if db.IsUniqueViolationError(err) {
+				return nil, utils.ErrToolAlreadyExists
+			}
+			return nil, fmt.Errorf("failed to create tool %s: %w", tool.Name, err)
+		}
+	}
+
+	agentChange := &plan.Changes[len(plan.Changes)-1]
+	switch {
+	case plan.agent == nil:
+		created, err := s.CreateManagedAgent(ctx, userIdpId, orgName, &plan.request)
+		if err != nil {
+			return nil, err
+		}
+		agentChange.ID = created.ID.String()
+		return created, nil
+	case agentChange.Action == utils.ImportActionUnchanged:
+		return plan.agent, nil
+	default:
+		return s.saveManagedAgentVersion(ctx, userIdpId, plan.orgId, plan.agent.ID, &plan.agent.Version,
+			func(txCtx context.Context, current *models.ManagedAgent) (*models.ManagedAgentRequest, *int32, error) {
+				return &plan.request, nil, nil
+			})
+	}
+}
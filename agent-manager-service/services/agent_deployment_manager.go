@@ -0,0 +1,380 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/db"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/models"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/repositories"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/utils"
+)
+
+type AgentDeploymentService interface {
+	ListAgentEnvironments(ctx context.Context, userIdpId uuid.UUID, orgName string) ([]*models.AgentEnvironment, error)
+	GetAgentEnvironment(ctx context.Context, userIdpId uuid.UUID, orgName string, envName string) (*models.AgentEnvironment, error)
+	CreateAgentEnvironment(ctx context.Context, userIdpId uuid.UUID, orgName string, req *models.AgentEnvironmentRequest) (*models.AgentEnvironment, error)
+	// DeployManagedAgent deploys a version of an agent to an environment, rolling back is deploying an earlier version
+	DeployManagedAgent(ctx context.Context, userIdpId uuid.UUID, orgName string, agentId uuid.UUID, req *models.AgentDeploymentRequest) (*models.AgentDeployment, error)
+	// PromoteManagedAgent deploys the version an agent runs in one environment to another
+	PromoteManagedAgent(ctx context.Context, userIdpId uuid.UUID, orgName string, agentId uuid.UUID, req *models.AgentPromotionRequest) (*models.AgentDeployment, error)
+	// ListAgentDeployments lists the deployment history of an agent newest first, only to one environment when envName is set
+	ListAgentDeployments(ctx context.Context, userIdpId uuid.UUID, orgName string, agentId uuid.UUID, envName string, limit int, offset int) ([]*models.AgentDeployment, int32, error)
+	// ListEnvironmentAgents lists the agents deployed to an environment with the version each currently runs
+	ListEnvironmentAgents(ctx context.Context, userIdpId uuid.UUID, orgName string, envName string, limit int, offset int) ([]*models.EnvironmentAgent, int32, error)
+	// GetResolvedAgentConfig returns the configuration of the version of an agent deployed to an environment
+	// with its prompt and tools resolved
+	GetResolvedAgentConfig(ctx context.Context, userIdpId uuid.UUID, orgName string, envName string, agentId uuid.UUID) (*models.ResolvedAgentConfig, error)
+}
+
+type agentDeploymentService struct {
+	OrganizationRepository        repositories.OrganizationRepository
+	AgentEnvironmentRepository    repositories.AgentEnvironmentRepository
+	AgentDeploymentRepository     repositories.AgentDeploymentRepository
+	ManagedAgentRepository        repositories.ManagedAgentRepository
+	ManagedAgentVersionRepository repositories.ManagedAgentVersionRepository
+	PromptTemplateRepository      repositories.PromptTemplateRepository
+	ToolRepository                repositories.ToolRepository
+	WebhookService                WebhookService
+	logger                        *slog.Logger
+}
+
+func NewAgentDeploymentService(
+	orgRepo repositories.OrganizationRepository,
+	agentEnvironmentRepo repositories.AgentEnvironmentRepository,
+	agentDeploymentRepo repositories.AgentDeploymentRepository,
+	managedAgentRepo repositories.ManagedAgentRepository,
+	managedAgentVersionRepo repositories.ManagedAgentVersionRepository,
+	promptTemplateRepo repositories.PromptTemplateRepository,
+	toolRepo repositories.ToolRepository,
+	webhookService WebhookService,
+	logger *slog.Logger,
+) AgentDeploymentService {
+	return &agentDeploymentService{
+		OrganizationRepository:        orgRepo,
+		AgentEnvironmentRepository:    agentEnvironmentRepo,
+		AgentDeploymentRepository:     agentDeploymentRepo,
+		ManagedAgentRepository:        managedAgentRepo,
+		ManagedAgentVersionRepository: managedAgentVersionRepo,
+		PromptTemplateRepository:      promptTemplateRepo,
+		ToolRepository:                toolRepo,
+		WebhookService:                webhookService,
+		logger:                        logger,
+	}
+}
+
+func (s *agentDeploymentService) getOrganization(ctx context.Context, userIdpId uuid.UUID, orgName string) (*models.Organization, error) {
+	org, err := s.OrganizationRepository.GetOrganizationByOrgName(ctx, userIdpId, orgName)
+	if err != nil {
+		if db.IsRecordNotFoundError(err) {
+			s.logger.ErrorContext(ctx, "Organization not found", "orgName", orgName, "userIdpId", userIdpId)
+			return nil, utils.ErrOrganizationNotFound
+		}
+		s.logger.ErrorContext(ctx, "Failed to find organization", "orgName", orgName, "userIdpId", userIdpId, "error", err)
+		return nil, fmt.Errorf("failed to find organization %s: %w", orgName, err)
+	}
+	return org, nil
+}
+
+func (s *agentDeploymentService) getEnvironment(ctx context.Context, orgId uuid.UUID, envName string) (*models.AgentEnvironment, error) {
+	environment, err := s.AgentEnvironmentRepository.GetAgentEnvironmentByName(ctx, orgId, envName)
+	if err != nil {
+		if db.IsRecordNotFoundError(err) {
+			return nil, utils.ErrEnvironmentNotFound
+		}
+		s.logger.ErrorContext(ctx, "Failed to find environment", "envName", envName, "orgId", orgId, "error", err)
+		return nil, fmt.Errorf("failed to find environment %s: %w", envName, err)
+	}
+	return environment, nil
+}
+
+func (s *agentDeploymentService) getManagedAgent(ctx context.Context, orgId uuid.UUID, agentId uuid.UUID) (*models.ManagedAgent, error) {
+	agent, err := s.ManagedAgentRepository.GetManagedAgentById(ctx, orgId, agentId)
+	if err != nil {
+		if db.IsRecordNotFoundError(err) {
+			return nil, utils.ErrAgentNotFound
+		}
+		s.logger.ErrorContext(ctx, "Failed to find managed agent", "agentId", agentId, "orgId", orgId, "error", err)
+		return nil, fmt.Errorf("failed to find managed agent %s: %w", agentId, err)
+	}
+	return agent, nil
+}
+
+func (s *agentDeploymentService) getManagedAgentVersion(ctx context.Context, agentId uuid.UUID, version int32) (*models.ManagedAgentVersion, error) {
+	agentVersion, err := s.ManagedAgentVersionRepository.GetManagedAgentVersion(ctx, agentId, version)
+	if err != nil {
+		if db.IsRecordNotFoundError(err) {
+			return nil, utils.ErrAgentVersionNotFound
+		}
+		s.logger.ErrorContext(ctx, "Failed to find managed agent version", "agentId", agentId, "version", version, "error", err)
+		return nil, fmt.Errorf("failed to find version %d of managed agent %s: %w", version, agentId, err)
+	}
+	return agentVersion, nil
+}
+
+// getCurrentDeployment fails with ErrAgentNotDeployed when the agent was never deployed to the environment
+func (s *agentDeploymentService) getCurrentDeployment(ctx context.Context, agentId uuid.UUID, environment *models.AgentEnvironment) (*models.AgentDeployment, error) {
+	deployment, err := s.AgentDeploymentRepository.GetCurrentAgentDeployment(ctx, agentId, environment.ID)
+	if err != nil {
+		if db.IsRecordNotFoundError(err) {
+			return nil, utils.ErrAgentNotDeployed
+		}
+		s.logger.ErrorContext(ctx, "Failed to find agent deployment", "agentId", agentId, "envName", environment.Name, "error", err)
+		return nil, fmt.Errorf("failed to find deployment of managed agent %s to %s: %w", agentId, environment.Name, err)
+	}
+	return deployment, nil
+}
+
+func (s *agentDeploymentService) ListAgentEnvironments(ctx context.Context, userIdpId uuid.UUID, orgName string) ([]*models.AgentEnvironment, error) {
+	s.logger.InfoContext(ctx, "Listing agent environments", "orgName", orgName, "userIdpId", userIdpId)
+	org, err := s.getOrganization(ctx, userIdpId, orgName)
+	if err != nil {
+		return nil, err
+	}
+	environments, err := s.AgentEnvironmentRepository.ListAgentEnvironments(ctx, org.ID)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to list agent environments", "orgId", org.ID, "error", err)
+		return nil, fmt.Errorf("failed to list agent environments: %w", err)
+	}
+	return environments, nil
+}
+
+func (s *agentDeploymentService) GetAgentEnvironment(ctx context.Context, userIdpId uuid.UUID, orgName string, envName string) (*models.AgentEnvironment, error) {
+	s.logger.InfoContext(ctx, "Getting agent environment", "envName", envName, "orgName", orgName, "userIdpId", userIdpId)
+	org, err := s.getOrganization(ctx, userIdpId, orgName)
+	if err != nil {
+		return nil, err
+	}
+	return s.getEnvironment(ctx, org.ID, envName)
+}
+
+func (s *agentDeploymentService) CreateAgentEnvironment(ctx context.Context, userIdpId uuid.UUID, orgName string, req *models.AgentEnvironmentRequest) (*models.AgentEnvironment, error) {
+	s.logger.InfoContext(ctx, "Creating agent environment", "envName", req.Name, "orgName", orgName, "userIdpId", userIdpId)
+	org, err := s.getOrganization(ctx, userIdpId, orgName)
+	if err != nil {
+		return nil, err
+	}
+	environment := &models.AgentEnvironment{
+		ID:          uuid.New(),
+		OrgID:       org.ID,
+		Name:        req.Name,
+		Description: req.Description,
+		CreatedAt:   time.Now(),
+	}
+	if err := s.AgentEnvironmentRepository.CreateAgentEnvironment(ctx, environment); err != nil {
+		if db.IsUniqueViolationError(err) {
+			return nil, utils.ErrEnvironmentAlreadyExists
+		}
+		s.logger.ErrorContext(ctx, "Failed to create agent environment", "envName", req.Name, "orgId", org.ID, "error", err)
+		return nil, fmt.Errorf("failed to create agent environment %s: %w", req.Name, err)
+	}
+	s.logger.InfoContext(ctx, "Agent environment created successfully", "envName", environment.Name, "orgId", org.ID)
+	return environment, nil
+}
+
+func (s *agentDeploymentService) DeployManagedAgent(ctx context.Context, userIdpId uuid.UUID, orgName string, agentId uuid.UUID, req *models.AgentDeploymentRequest) (*models.AgentDeployment, error) {
+	s.logger.InfoContext(ctx, "Deploying managed agent", "agentId", agentId, "envName", req.Environment, "version", req.Version, "orgName", orgName, "userIdpId", userIdpId)
+	org, err := s.getOrganization(ctx, userIdpId, orgName)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.getManagedAgent(ctx, org.ID, agentId); err != nil {
+		return nil, err
+	}
+	environment, err := s.getEnvironment(ctx, org.ID, req.Environment)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.getManagedAgentVersion(ctx, agentId, req.Version); err != nil {
+		return nil, err
+	}
+
+	deployment := newAgentDeployment(agentId, environment, req.Version, nil, userIdpId)
+	if err := s.AgentDeploymentRepository.CreateAgentDeployment(ctx, deployment); err != nil {
+		s.logger.ErrorContext(ctx, "Failed to deploy managed agent", "agentId", agentId, "envName", environment.Name, "error", err)
+		return nil, fmt.Errorf("failed to deploy managed agent %s to %s: %w", agentId, environment.Name, err)
+	}
+	s.WebhookService.PublishEvent(ctx, org, utils.WebhookEventAgentDeployed, utils.ConvertToAgentDeploymentResponse(deployment))
+	s.logger.InfoContext(ctx, "Managed agent deployed successfully", "agentId", agentId, "envName", environment.Name, "version", deployment.Version)
+	return deployment, nil
+}
+
+func (s *agentDeploymentService) PromoteManagedAgent(ctx context.Context, userIdpId uuid.UUID, orgName string, agentId uuid.UUID, req *models.AgentPromotionRequest) (*models.AgentDeployment, error) {
+	s.logger.InfoContext(ctx, "Promoting managed agent", "agentId", agentId, "from", req.From, "to", req.To, "orgName", orgName, "userIdpId", userIdpId)
+	org, err := s.getOrganization(ctx, userIdpId, orgName)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.getManagedAgent(ctx, org.ID, agentId); err != nil {
+		return nil, err
+	}
+	source, err := s.getEnvironment(ctx, org.ID, req.From)
+	if err != nil {
+		return nil, err
+	}
+	target, err := s.getEnvironment(ctx, org.ID, req.To)
+	if err != nil {
+		return nil, err
+	}
+
+	var deployment *models.AgentDeployment
+	err = db.WithTx(ctx, func(txCtx context.Context) error {
+		current, err := s.getCurrentDeployment(txCtx, agentId, source)
+		if err != nil {
+			if errors.Is(err, utils.ErrAgentNotDeployed) {
+				return utils.ErrNothingToPromote
+			}
+			return err
+		}
+		if req.Version != nil && *req.Version != current.Version {
+			return utils.ErrPromotionVersionMismatch
+		}
+		deployment = newAgentDeployment(agentId, target, current.Version, &source.Name, userIdpId)
+		if err := s.AgentDeploymentRepository.CreateAgentDeployment(txCtx, deployment); err != nil {
+			s.logger.ErrorContext(ctx, "Failed to promote managed agent", "agentId", agentId, "from", source.Name, "to", target.Name, "error", err)
+			return fmt.Errorf("failed to promote managed agent %s from %s to %s: %w", agentId, source.Name, target.Name, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.WebhookService.PublishEvent(ctx, org, utils.WebhookEventAgentDeployed, utils.ConvertToAgentDeploymentResponse(deployment))
+	s.logger.InfoContext(ctx, "Managed agent promoted successfully", "agentId", agentId, "from", source.Name, "to", target.Name, "version", deployment.Version)
+	return deployment, nil
+}
+
+func newAgentDeployment(agentId uuid.UUID, environment *models.AgentEnvironment, version int32, promotedFrom *string, deployedBy uuid.UUID) *models.AgentDeployment {
+	return &models.AgentDeployment{
+		ID:            uuid.New(),
+		AgentID:       agentId,
+		EnvironmentID: environment.ID,
+		Version:       version,
+		PromotedFrom:  promotedFrom,
+		DeployedBy:    &deployedBy,
+		DeployedAt:    time.Now(),
+		Environment:   environment.Name,
+	}
+}
+
+func (s *agentDeploymentService) ListAgentDeployments(ctx context.Context, userIdpId uuid.UUID, orgName string, agentId uuid.UUID, envName string, limit int, offset int) ([]*models.AgentDeployment, int32, error) {
+	s.logger.InfoContext(ctx, "Listing agent deployments", "agentId", agentId, "envName", envName, "orgName", orgName, "limit", limit, "offset", offset, "userIdpId", userIdpId)
+	org, err := s.getOrganization(ctx, userIdpId, orgName)
+	if err != nil {
+		return nil, 0, err
+	}
+	if _, err := s.getManagedAgent(ctx, org.ID, agentId); err != nil {
+		return nil, 0, err
+	}
+	var environmentId *uuid.UUID
+	if envName != "" {
+		environment, err := s.getEnvironment(ctx, org.ID, envName)
+		if err != nil {
+			return nil, 0, err
+		}
+		environmentId = &environment.ID
+	}
+	deployments, total, err := s.AgentDeploymentRepository.ListAgentDeployments(ctx, agentId, environmentId, limit, offset)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to list agent deployments", "agentId", agentId, "error", err)
+		return nil, 0, fmt.Errorf("failed to list deployments of managed agent %s: %w", agentId, err)
+	}
+	return deployments, int32(total), nil
+}
+
+func (s *agentDeploymentService) ListEnvironmentAgents(ctx context.Context, userIdpId uuid.UUID, orgName string, envName string, limit int, offset int) ([]*models.EnvironmentAgent, int32, error) {
+	s.logger.InfoContext(ctx, "Listing environment agents", "envName", envName, "orgName", orgName, "limit", limit, "offset", offset, "userIdpId", userIdpId)
+	org, err := s.getOrganization(ctx, userIdpId, orgName)
+	if err != nil {
+		return nil, 0, err
+	}
+	environment, err := s.getEnvironment(ctx, org.ID, envName)
+	if err != nil {
+		return nil, 0, err
+	}
+	agents, total, err := s.AgentDeploymentRepository.ListEnvironmentAgents(ctx, environment.ID, limit, offset)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to list environment agents", "envName", envName, "orgId", org.ID, "error", err)
+		return nil, 0, fmt.Errorf("failed to list agents deployed to %s: %w", envName, err)
+	}
+	return agents, int32(total), nil
+}
+
+func (s *agentDeploymentService) GetResolvedAgentConfig(ctx context.Context, userIdpId uuid.UUID, orgName string, envName string, agentId uuid.UUID) (*models.ResolvedAgentConfig, error) {
+	s.logger.InfoContext(ctx, "Resolving agent config", "agentId", agentId, "envName", envName, "orgName", orgName, "userIdpId", userIdpId)
+	org, err := s.getOrganization(ctx, userIdpId, orgName)
+	if err != nil {
+		return nil, err
+	}
+	environment, err := s.getEnvironment(ctx, org.ID, envName)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.getManagedAgent(ctx, org.ID, agentId); err != nil {
+		return nil, err
+	}
+	deployment, err := s.getCurrentDeployment(ctx, agentId, environment)
+	if err != nil {
+		return nil, err
+	}
+	version, err := s.getManagedAgentVersion(ctx, agentId, deployment.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	config := &models.ResolvedAgentConfig{
+		AgentID:      agentId.String(),
+		Environment:  environment.Name,
+		Version:      version.Version,
+		DeploymentID: deployment.ID.String(),
+		Name:         version.Name,
+		Description:  version.Description,
+		Framework:    version.Framework,
+		ModelConfig:  version.ModelConfig,
+		SystemPrompt: version.SystemPrompt,
+		PromptRef:    utils.ConvertToPromptReference(version.PromptID, version.PromptVersion),
+		Labels:       version.Labels,
+		Enabled:      version.Enabled,
+	}
+	if config.Labels == nil {
+		config.Labels = map[string]string{}
+	}
+	if version.PromptID != nil && version.PromptVersion != nil {
+		promptVersion, err := s.PromptTemplateRepository.GetPromptTemplateVersion(ctx, *version.PromptID, *version.PromptVersion)
+		if err != nil {
+			if db.IsRecordNotFoundError(err) {
+				s.logger.WarnContext(ctx, "Deployed agent version references a purged prompt", "agentId", agentId, "version", version.Version, "promptId", version.PromptID)
+				return nil, utils.ErrAgentReferenceDeleted
+			}
+			return nil, fmt.Errorf("failed to find version %d of prompt %s: %w", *version.PromptVersion, version.PromptID, err)
+		}
+		config.SystemPrompt = promptVersion.Template
+		config.PromptVariables = promptVersion.Variables
+	}
+	config.Tools, err = resolveAgentTools(ctx, s.logger, s.ToolRepository, org.ID, agentId, version.Tools)
+	if err != nil {
+		return nil, err
+	}
+	return config, nil
+}
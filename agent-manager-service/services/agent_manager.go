@@ -24,7 +24,6 @@ import (
 	"time"
 
 	"github.com/google/uuid"
-	"gorm.io/gorm"
 
 	observabilitysvc "github.com/wso2/ai-agent-management-platform/agent-manager-service/clients/observabilitysvc"
 	clients "github.com/wso2/ai-agent-management-platform/agent-manager-service/clients/openchoreosvc"
@@ -83,36 +82,36 @@ func NewAgentManagerService(
 }
 
 func (s *agentManagerService) GetAgent(ctx context.Context, userIdpId uuid.UUID, orgName string, projectName string, agentName string) (*models.AgentResponse, error) {
-	s.logger.Info("Getting agent", "agentName", agentName, "orgName", orgName, "projectName", projectName, "userIdpId", userIdpId)
+	s.logger.InfoContext(ctx, "Getting agent", "agentName", agentName, "orgName", orgName, "projectName", projectName, "userIdpId", userIdpId)
 	// Validate organization exists
 	_, err := s.OrganizationRepository.GetOrganizationByOrgName(ctx, userIdpId, orgName)
 	if err != nil {
 		if db.IsRecordNotFoundError(err) {
-			s.logger.Error("Organization not found", "orgName", orgName, "userIdpId", userIdpId)
+			s.logger.ErrorContext(ctx, "Organization not found", "orgName", orgName, "userIdpId", userIdpId)
 			return nil, utils.ErrOrganizationNotFound
 		}
-		s.logger.Error("Failed to find organization", "orgName", orgName, "userIdpId", userIdpId, "error", err)
+		s.logger.ErrorContext(ctx, "Failed to find organization", "orgName", orgName, "userIdpId", userIdpId, "error", err)
 		return nil, fmt.Errorf("failed to find organization %s: %w", orgName, err)
 	}
 	ocAgentComponent, err := s.OpenChoreoSvcClient.GetAgentComponent(ctx, orgName, projectName, agentName)
 	if err != nil {
-		s.logger.Error("Failed to fetch agent from OpenChoreo", "agentName", agentName, "orgName", orgName, "projectName", projectName, "error", err)
+		s.logger.ErrorContext(ctx, "Failed to fetch agent from OpenChoreo", "agentName", agentName, "orgName", orgName, "projectName", projectName, "error", err)
 		return nil, fmt.Errorf("failed to fetch agent from oc: %w", err)
 	}
 	if ocAgentComponent.Provisioning.Type == string(utils.ExternalAgent) {
-		s.logger.Info("Fetched external agent successfully", "agentName", ocAgentComponent.Name, "orgName", orgName, "projectName", projectName, "provisioningType", ocAgentComponent.Provisioning.Type)
+		s.logger.InfoContext(ctx, "Fetched external agent successfully", "agentName", ocAgentComponent.Name, "orgName", orgName, "projectName", projectName, "provisioningType", ocAgentComponent.Provisioning.Type)
 		return s.convertExternalAgentToAgentResponse(ocAgentComponent), nil
 	}
-	s.logger.Info("Fetched agent successfully from oc", "agentName", ocAgentComponent.Name, "orgName", orgName, "projectName", projectName, "provisioningType", string(utils.InternalAgent))
+	s.logger.InfoContext(ctx, "Fetched agent successfully from oc", "agentName", ocAgentComponent.Name, "orgName", orgName, "projectName", projectName, "provisioningType", string(utils.InternalAgent))
 	return s.convertManagedAgentToAgentResponse(ocAgentComponent), nil
 }
 
 func (s *agentManagerService) ListAgents(ctx context.Context, userIdpId uuid.UUID, orgName string, projName string, limit int32, offset int32) ([]*models.AgentResponse, int32, error) {
-	s.logger.Info("Listing agents", "orgName", orgName, "projectName", projName, "limit", limit, "offset", offset, "userIdpId", userIdpId)
+	s.logger.InfoContext(ctx, "Listing agents", "orgName", orgName, "projectName", projName, "limit", limit, "offset", offset, "userIdpId", userIdpId)
 	// Validate organization exists
 	org, err := s.OrganizationRepository.GetOrganizationByOrgName(ctx, userIdpId, orgName)
 	if err != nil {
-		s.logger.Error("Failed to find organization", "orgName", orgName, "userIdpId", userIdpId, "error", err)
+		s.logger.ErrorContext(ctx, "Failed to find organization", "orgName", orgName, "userIdpId", userIdpId, "error", err)
 		if db.IsRecordNotFoundError(err) {
 			return nil, 0, utils.ErrOrganizationNotFound
 		}
@@ -121,16 +120,16 @@ func (s *agentManagerService) ListAgents(ctx context.Context, userIdpId uuid.UUI
 	project, err := s.ProjectRepository.GetProjectByName(ctx, org.ID, projName)
 	if err != nil {
 		if db.IsRecordNotFoundError(err) {
-			s.logger.Error("Project not found", "projectName", projName, "orgId", org.ID)
+			s.logger.ErrorContext(ctx, "Project not found", "projectName", projName, "orgId", org.ID)
 			return nil, 0, utils.ErrProjectNotFound
 		}
-		s.logger.Error("Failed to find project", "projectName", projName, "orgId", org.ID, "error", err)
+		s.logger.ErrorContext(ctx, "Failed to find project", "projectName", projName, "orgId", org.ID, "error", err)
 		return nil, 0, fmt.Errorf("failed to find project %s: %w", projName, err)
 	}
 	// Fetch all agents from the database
 	agents, err := s.OpenChoreoSvcClient.ListAgentComponents(ctx, orgName, projName)
 	if err != nil {
-		s.logger.Error("Failed to list agents from repository", "orgId", org.ID, "projectId", project.ID, "error", err)
+		s.logger.ErrorContext(ctx, "Failed to list agents from repository", "orgId", org.ID, "projectId", project.ID, "error", err)
 		return nil, 0, fmt.Errorf("failed to list external agents: %w", err)
 	}
 	var allAgents []*models.AgentResponse
@@ -153,16 +152,16 @@ func (s *agentManagerService) ListAgents(ctx context.Context, userIdpId uuid.UUI
 		}
 		paginatedAgents = allAgents[offset:endIndex]
 	}
-	s.logger.Info("Listed agents successfully", "orgName", orgName, "projName", projName, "totalAgents", total, "returnedAgents", len(paginatedAgents))
+	s.logger.InfoContext(ctx, "Listed agents successfully", "orgName", orgName, "projName", projName, "totalAgents", total, "returnedAgents", len(paginatedAgents))
 	return paginatedAgents, total, nil
 }
 
 func (s *agentManagerService) CreateAgent(ctx context.Context, userIdpId uuid.UUID, orgName string, projectName string, req *spec.CreateAgentRequest) error {
-	s.logger.Info("Creating agent", "agentName", req.Name, "orgName", orgName, "projectName", projectName, "provisioningType", req.Provisioning.Type, "userIdpId", userIdpId)
+	s.logger.InfoContext(ctx, "Creating agent", "agentName", req.Name, "orgName", orgName, "projectName", projectName, "provisioningType", req.Provisioning.Type, "userIdpId", userIdpId)
 	// Validate organization exists
 	org, err := s.OrganizationRepository.GetOrganizationByOrgName(ctx, userIdpId, orgName)
 	if err != nil {
-		s.logger.Error("Failed to find organization", "orgName", orgName, "userIdpId", userIdpId, "error", err)
+		s.logger.ErrorContext(ctx, "Failed to find organization", "orgName", orgName, "userIdpId", userIdpId, "error", err)
 		if db.IsRecordNotFoundError(err) {
 			return utils.ErrOrganizationNotFound
 		}
@@ -171,22 +170,22 @@ func (s *agentManagerService) CreateAgent(ctx context.Context, userIdpId uuid.UU
 	// Validate project exists in OpenChoreo
 	_, err = s.OpenChoreoSvcClient.GetProject(ctx, projectName, orgName)
 	if err != nil {
-		s.logger.Error("Failed to find project", "projectName", projectName, "orgId", org.ID, "error", err)
+		s.logger.ErrorContext(ctx, "Failed to find project", "projectName", projectName, "orgId", org.ID, "error", err)
 		return err
 	}
 	// Check if agent already exists
 	agent, err := s.OpenChoreoSvcClient.GetAgentComponent(ctx, orgName, projectName, req.Name)
 	if err != nil && err != utils.ErrAgentNotFound {
-		s.logger.Error("Failed to check existing agents", "agentName", req.Name, "orgId", org.ID, "project", projectName, "error", err)
+		s.logger.ErrorContext(ctx, "Failed to check existing agents", "agentName", req.Name, "orgId", org.ID, "project", projectName, "error", err)
 		return fmt.Errorf("failed to check existing agents: %w", err)
 	}
 	if agent != nil {
-		s.logger.Warn("Agent already exists", "agentName", req.Name, "orgId", org.ID, "project", projectName)
+		s.logger.WarnContext(ctx, "Agent already exists", "agentName", req.Name, "orgId", org.ID, "project", projectName)
 		return utils.ErrAgentAlreadyExists
 	}
 	project, err := s.ProjectRepository.GetProjectByName(ctx, org.ID, projectName)
 	if err != nil {
-		s.logger.Error("Failed to find project", "projectName", projectName, "orgId", org.ID, "error", err)
+		s.logger.ErrorContext(ctx, "Failed to find project", "projectName", projectName, "orgId", org.ID, "error", err)
 		if db.IsRecordNotFoundError(err) {
 			return utils.ErrProjectNotFound
 		}
@@ -195,47 +194,47 @@ func (s *agentManagerService) CreateAgent(ctx context.Context, userIdpId uuid.UU
 	// Save agent record in database first
 	err = s.saveAgentRecord(ctx, org.ID, project.ID, req)
 	if err != nil {
-		s.logger.Error("Failed to save agent record", "agentName", req.Name, "error", err)
+		s.logger.ErrorContext(ctx, "Failed to save agent record", "agentName", req.Name, "error", err)
 		return err
 	}
 	err = s.createOpenChoreoAgentComponent(ctx, orgName, projectName, req)
 	if err != nil {
-		s.logger.Error("OpenChoreo creation failed, initiating rollback", "agentName", req.Name, "error", err)
+		s.logger.ErrorContext(ctx, "OpenChoreo creation failed, initiating rollback", "agentName", req.Name, "error", err)
 		// OpenChoreo creation failed, rollback database record
 		if deleteErr := s.deleteAgentRecord(ctx, org.ID, project.ID, req.Name, false); deleteErr != nil {
-			s.logger.Error("Critical: Agent exists in database but not in OpenChoreo, manual cleanup required",
+			s.logger.ErrorContext(ctx, "Critical: Agent exists in database but not in OpenChoreo, manual cleanup required",
 				"agentName", req.Name, "orgName", orgName, "projectName", projectName, "error", deleteErr)
 		}
 		return err
 	}
 
-	s.logger.Info("Agent created successfully", "agentName", req.Name, "orgName", orgName, "projectName", projectName, "provisioningType", req.Provisioning.Type)
+	s.logger.InfoContext(ctx, "Agent created successfully", "agentName", req.Name, "orgName", orgName, "projectName", projectName, "provisioningType", req.Provisioning.Type)
 	return nil
 }
 
 func (s *agentManagerService) GenerateName(ctx context.Context, userIdpId uuid.UUID, orgName string, payload spec.ResourceNameRequest) (string, error) {
-	s.logger.Info("Generating resource name", "resourceType", payload.ResourceType, "displayName", payload.DisplayName, "orgName", orgName, "userIdpId", userIdpId)
+	s.logger.InfoContext(ctx, "Generating resource name", "resourceType", payload.ResourceType, "displayName", payload.DisplayName, "orgName", orgName, "userIdpId", userIdpId)
 	// Validate organization exists
 	org, err := s.OrganizationRepository.GetOrganizationByOrgName(ctx, userIdpId, orgName)
 	if err != nil {
 		if db.IsRecordNotFoundError(err) {
-			s.logger.Error("Organization not found", "orgName", orgName, "userIdpId", userIdpId)
+			s.logger.ErrorContext(ctx, "Organization not found", "orgName", orgName, "userIdpId", userIdpId)
 			return "", utils.ErrOrganizationNotFound
 		}
-		s.logger.Error("Failed to find organization", "orgName", orgName, "userIdpId", userIdpId, "error", err)
+		s.logger.ErrorContext(ctx, "Failed to find organization", "orgName", orgName, "userIdpId", userIdpId, "error", err)
 		return "", fmt.Errorf("failed to find organization %s: %w", orgName, err)
 	}
 
 	// Generate candidate name from display name
 	candidateName := utils.GenerateCandidateName(payload.DisplayName)
-	s.logger.Debug("Generated candidate name", "candidateName", candidateName, "displayName", payload.DisplayName)
+	s.logger.DebugContext(ctx, "Generated candidate name", "candidateName", candidateName, "displayName", payload.DisplayName)
 
 	if payload.ResourceType == string(utils.ResourceTypeAgent) {
 		projectName := utils.StrPointerAsStr(payload.ProjectName, "")
 		// Validates the project name by checking its existence
 		project, err := s.ProjectRepository.GetProjectByName(ctx, org.ID, projectName)
 		if err != nil {
-			s.logger.Error("Failed to find project", "projectName", projectName, "orgId", org.ID, "error", err)
+			s.logger.ErrorContext(ctx, "Failed to find project", "projectName", projectName, "orgId", org.ID, "error", err)
 			if db.IsRecordNotFoundError(err) {
 				return "", utils.ErrProjectNotFound
 			}
@@ -246,21 +245,21 @@ func (s *agentManagerService) GenerateName(ctx context.Context, userIdpId uuid.U
 		_, err = s.AgentRepository.GetAgentByName(ctx, org.ID, project.ID, candidateName)
 		if err != nil && db.IsRecordNotFoundError(err) {
 			// Name is available, return it
-			s.logger.Info("Generated unique agent name from display name", "agentName", candidateName, "orgName", orgName, "projectName", projectName)
+			s.logger.InfoContext(ctx, "Generated unique agent name from display name", "agentName", candidateName, "orgName", orgName, "projectName", projectName)
 			return candidateName, nil
 		}
 		if err != nil {
-			s.logger.Error("Failed to check agent name availability", "name", candidateName, "orgId", org.ID, "projectId", project.ID, "error", err)
+			s.logger.ErrorContext(ctx, "Failed to check agent name availability", "name", candidateName, "orgId", org.ID, "projectId", project.ID, "error", err)
 			return "", fmt.Errorf("failed to check agent name availability: %w", err)
 		}
 
 		// Name is taken, generate unique name with suffix
 		uniqueName, err := s.generateUniqueAgentName(ctx, org.ID, project.ID, candidateName)
 		if err != nil {
-			s.logger.Error("Failed to generate unique agent name", "baseName", candidateName, "orgId", org.ID, "projectId", project.ID, "error", err)
+			s.logger.ErrorContext(ctx, "Failed to generate unique agent name", "baseName", candidateName, "orgId", org.ID, "projectId", project.ID, "error", err)
 			return "", fmt.Errorf("failed to generate unique agent name: %w", err)
 		}
-		s.logger.Info("Generated unique agent name", "agentName", uniqueName, "orgName", orgName, "projectName", projectName)
+		s.logger.InfoContext(ctx, "Generated unique agent name", "agentName", uniqueName, "orgName", orgName, "projectName", projectName)
 		return uniqueName, nil
 	}
 	if payload.ResourceType == string(utils.ResourceTypeProject) {
@@ -268,20 +267,20 @@ func (s *agentManagerService) GenerateName(ctx context.Context, userIdpId uuid.U
 		_, err = s.ProjectRepository.GetProjectByName(ctx, org.ID, candidateName)
 		if err != nil && db.IsRecordNotFoundError(err) {
 			// Name is available, return it
-			s.logger.Info("Generated unique project name", "projectName", candidateName, "orgName", orgName)
+			s.logger.InfoContext(ctx, "Generated unique project name", "projectName", candidateName, "orgName", orgName)
 			return candidateName, nil
 		}
 		if err != nil {
-			s.logger.Error("Failed to check project name availability", "name", candidateName, "orgId", org.ID, "error", err)
+			s.logger.ErrorContext(ctx, "Failed to check project name availability", "name", candidateName, "orgId", org.ID, "error", err)
 			return "", fmt.Errorf("failed to check project name availability: %w", err)
 		}
 		// Name is taken, generate unique name with suffix
 		uniqueName, err := s.generateUniqueProjectName(ctx, org.ID, candidateName)
 		if err != nil {
-			s.logger.Error("Failed to generate unique project name", "baseName", candidateName, "orgId", org.ID, "error", err)
+			s.logger.ErrorContext(ctx, "Failed to generate unique project name", "baseName", candidateName, "orgId", org.ID, "error", err)
 			return "", fmt.Errorf("failed to generate unique project name: %w", err)
 		}
-		s.logger.Info("Generated unique project name", "projectName", uniqueName, "orgName", orgName)
+		s.logger.InfoContext(ctx, "Generated unique project name", "projectName", uniqueName, "orgName", orgName)
 		return uniqueName, nil
 	}
 	return "", errors.New("invalid resource type for name generation")
@@ -297,7 +296,7 @@ func (s *agentManagerService) generateUniqueProjectName(ctx context.Context, org
 			return true, nil
 		}
 		if err != nil {
-			s.logger.Error("Failed to check project name availability", "name", name, "orgId", orgId, "error", err)
+			s.logger.ErrorContext(ctx, "Failed to check project name availability", "name", name, "orgId", orgId, "error", err)
 			return false, fmt.Errorf("failed to check project name availability: %w", err)
 		}
 		// Name is taken
@@ -307,7 +306,7 @@ func (s *agentManagerService) generateUniqueProjectName(ctx context.Context, org
 	// Use the common unique name generation logic from utils
 	uniqueName, err := utils.GenerateUniqueNameWithSuffix(baseName, nameChecker)
 	if err != nil {
-		s.logger.Error("Failed to generate unique project name", "baseName", baseName, "orgId", orgId, "error", err)
+		s.logger.ErrorContext(ctx, "Failed to generate unique project name", "baseName", baseName, "orgId", orgId, "error", err)
 		return "", fmt.Errorf("failed to generate unique project name: %w", err)
 	}
 
@@ -343,9 +342,7 @@ func (s *agentManagerService) saveAgentRecord(ctx context.Context, orgId uuid.UU
 	agentId := uuid.New()
 
 	// Execute database operations in a transaction
-	return db.DB(ctx).Transaction(func(tx *gorm.DB) error {
-		txCtx := db.CtxWithTx(ctx, tx)
-
+	return db.WithTx(ctx, func(txCtx context.Context) error {
 		// Create agent record in the database
 		newAgent := &models.Agent{
 			ID:               agentId,
@@ -359,7 +356,7 @@ func (s *agentManagerService) saveAgentRecord(ctx context.Context, orgId uuid.UU
 			UpdatedAt:        time.Now(),
 		}
 		if err := s.AgentRepository.CreateAgent(txCtx, newAgent); err != nil {
-			s.logger.Error("Failed to create agent record in database", "agentName", req.Name, "agentId", agentId, "error", err)
+			s.logger.ErrorContext(ctx, "Failed to create agent record in database", "agentName", req.Name, "agentId", agentId, "error", err)
 			return fmt.Errorf("failed to create agent record: %w", err)
 		}
 
@@ -368,7 +365,7 @@ func (s *agentManagerService) saveAgentRecord(ctx context.Context, orgId uuid.UU
 			// Build workload spec from request
 			workloadSpec, err := buildWorkloadSpec(req)
 			if err != nil {
-				s.logger.Error("Failed to build workload spec", "agentName", req.Name, "error", err)
+				s.logger.ErrorContext(ctx, "Failed to build workload spec", "agentName", req.Name, "error", err)
 				return fmt.Errorf("failed to build workload spec: %w", err)
 			}
 
@@ -378,7 +375,7 @@ func (s *agentManagerService) saveAgentRecord(ctx context.Context, orgId uuid.UU
 			}
 
 			if err := s.InternalAgentRepository.CreateInternalAgent(txCtx, internalAgent); err != nil {
-				s.logger.Error("Failed to create internal agent record", "agentName", req.Name, "agentId", agentId, "error", err)
+				s.logger.ErrorContext(ctx, "Failed to create internal agent record", "agentName", req.Name, "agentId", agentId, "error", err)
 				return fmt.Errorf("failed to create internal agent record: %w", err)
 			}
 		}
@@ -390,37 +387,37 @@ func (s *agentManagerService) saveAgentRecord(ctx context.Context, orgId uuid.UU
 // createOpenChoreoAgentComponent handles the creation of a managed agent
 func (s *agentManagerService) createOpenChoreoAgentComponent(ctx context.Context, orgName, projectName string, req *spec.CreateAgentRequest) error {
 	// Create agent component in Open Choreo
-	s.logger.Debug("Creating agent component in OpenChoreo", "agentName", req.Name, "orgName", orgName, "projectName", projectName)
+	s.logger.DebugContext(ctx, "Creating agent component in OpenChoreo", "agentName", req.Name, "orgName", orgName, "projectName", projectName)
 	if err := s.OpenChoreoSvcClient.CreateAgentComponent(ctx, orgName, projectName, req); err != nil {
-		s.logger.Error("Failed to create agent component in OpenChoreo", "agentName", req.Name, "orgName", orgName, "projectName", projectName, "error", err)
+		s.logger.ErrorContext(ctx, "Failed to create agent component in OpenChoreo", "agentName", req.Name, "orgName", orgName, "projectName", projectName, "error", err)
 		return fmt.Errorf("failed to create agent component: agentName %s, error: %w", req.Name, err)
 	}
 	if req.Provisioning.Type == string(utils.ExternalAgent) {
-		s.logger.Info("External agent component created successfully in OpenChoreo", "agentName", req.Name, "orgName", orgName, "projectName", projectName)
+		s.logger.InfoContext(ctx, "External agent component created successfully in OpenChoreo", "agentName", req.Name, "orgName", orgName, "projectName", projectName)
 		return nil
 	}
 	// For internal agents, trigger build after creation
-	s.logger.Debug("Agent component created, triggering build", "agentName", req.Name, "orgName", orgName, "projectName", projectName)
+	s.logger.DebugContext(ctx, "Agent component created, triggering build", "agentName", req.Name, "orgName", orgName, "projectName", projectName)
 	// Trigger build in Open Choreo with the latest commit
 	build, err := s.OpenChoreoSvcClient.TriggerBuild(ctx, orgName, projectName, req.Name, "")
 	if err != nil {
 		// Clean up the component if build trigger fails
-		s.logger.Info("Cleaning up component after build trigger failure", "agentName", req.Name)
+		s.logger.InfoContext(ctx, "Cleaning up component after build trigger failure", "agentName", req.Name)
 		if deleteErr := s.OpenChoreoSvcClient.DeleteAgentComponent(ctx, orgName, projectName, req.Name); deleteErr != nil {
-			s.logger.Error("Failed to clean up component after build trigger failure", "agentName", req.Name, "deleteError", deleteErr)
+			s.logger.ErrorContext(ctx, "Failed to clean up component after build trigger failure", "agentName", req.Name, "deleteError", deleteErr)
 		}
 		return fmt.Errorf("failed to trigger build: agentName %s, error: %w", req.Name, err)
 	}
-	s.logger.Info("Agent component created and build triggered successfully", "agentName", req.Name, "orgName", orgName, "projectName", projectName, "buildName", build.Name)
+	s.logger.InfoContext(ctx, "Agent component created and build triggered successfully", "agentName", req.Name, "orgName", orgName, "projectName", projectName, "buildName", build.Name)
 	return nil
 }
 
 func (s *agentManagerService) DeleteAgent(ctx context.Context, userIdpId uuid.UUID, orgName string, projectName string, agentName string) error {
-	s.logger.Info("Deleting agent", "agentName", agentName, "orgName", orgName, "projectName", projectName, "userIdpId", userIdpId)
+	s.logger.InfoContext(ctx, "Deleting agent", "agentName", agentName, "orgName", orgName, "projectName", projectName, "userIdpId", userIdpId)
 	// Validate organization exists
 	org, err := s.OrganizationRepository.GetOrganizationByOrgName(ctx, userIdpId, orgName)
 	if err != nil {
-		s.logger.Error("Failed to find organization", "orgName", orgName, "userIdpId", userIdpId, "error", err)
+		s.logger.ErrorContext(ctx, "Failed to find organization", "orgName", orgName, "userIdpId", userIdpId, "error", err)
 		if db.IsRecordNotFoundError(err) {
 			return utils.ErrOrganizationNotFound
 		}
@@ -428,7 +425,7 @@ func (s *agentManagerService) DeleteAgent(ctx context.Context, userIdpId uuid.UU
 	}
 	project, err := s.ProjectRepository.GetProjectByName(ctx, org.ID, projectName)
 	if err != nil {
-		s.logger.Error("Failed to find project", "projectName", projectName, "orgId", org.ID, "error", err)
+		s.logger.ErrorContext(ctx, "Failed to find project", "projectName", projectName, "orgId", org.ID, "error", err)
 		if db.IsRecordNotFoundError(err) {
 			return utils.ErrProjectNotFound
 		}
@@ -438,7 +435,7 @@ func (s *agentManagerService) DeleteAgent(ctx context.Context, userIdpId uuid.UU
 	_, err = s.AgentRepository.GetAgentByName(ctx, org.ID, project.ID, agentName)
 	if err != nil {
 		// DELETE is idempotent
-		s.logger.Error("Failed to check existing agents", "agentName", agentName, "orgId", org.ID, "projectId", project.ID, "error", err)
+		s.logger.ErrorContext(ctx, "Failed to check existing agents", "agentName", agentName, "orgId", org.ID, "projectId", project.ID, "error", err)
 		if db.IsRecordNotFoundError(err) {
 			return nil
 		}
@@ -446,7 +443,7 @@ func (s *agentManagerService) DeleteAgent(ctx context.Context, userIdpId uuid.UU
 	}
 	err = s.handleAgentDeletion(ctx, org.ID, project.ID, orgName, projectName, agentName)
 	if err != nil {
-		s.logger.Error("Failed to delete oc agent", "agentName", agentName, "error", err)
+		s.logger.ErrorContext(ctx, "Failed to delete oc agent", "agentName", agentName, "error", err)
 		return err
 	}
 	return nil
@@ -454,9 +451,9 @@ func (s *agentManagerService) DeleteAgent(ctx context.Context, userIdpId uuid.UU
 
 func (s *agentManagerService) handleAgentDeletion(ctx context.Context, orgId uuid.UUID, projectId uuid.UUID, orgName string, projectName string, agentName string) error {
 	// Soft delete agent from database
-	s.logger.Debug("Handling project deletion", "orgName", orgName, "projectName", projectName)
+	s.logger.DebugContext(ctx, "Handling project deletion", "orgName", orgName, "projectName", projectName)
 	if err := s.AgentRepository.SoftDeleteAgentByName(ctx, orgId, projectId, agentName); err != nil {
-		s.logger.Error("Failed to soft delete agent from repository", "agentName", agentName, "orgId", orgId, "projectId", projectId, "error", err)
+		s.logger.ErrorContext(ctx, "Failed to soft delete agent from repository", "agentName", agentName, "orgId", orgId, "projectId", projectId, "error", err)
 		return fmt.Errorf("failed to delete agent %s from repository: %w", projectName, err)
 	}
 	// Delete agent from OpenChoreo
@@ -465,15 +462,15 @@ func (s *agentManagerService) handleAgentDeletion(ctx context.Context, orgId uui
 		// Delete agent from OpenChoreo failed, rollback database changes
 		err := s.AgentRepository.RollbackSoftDeleteAgent(ctx, orgId, projectId, agentName)
 		if err != nil {
-			s.logger.Error("Critical: Agent exists in database but not in OpenChoreo, manual cleanup required",
+			s.logger.ErrorContext(ctx, "Critical: Agent exists in database but not in OpenChoreo, manual cleanup required",
 				"projectId", projectId, "projectName", projectName, "orgName", orgName, "error", err)
 		}
 		return fmt.Errorf("failed to delete agent %s from OpenChoreo and database: %w", agentName, err)
 	}
-	s.logger.Debug("Agent deleted from OpenChoreo successfully", "orgName", orgName, "agentName", agentName)
+	s.logger.DebugContext(ctx, "Agent deleted from OpenChoreo successfully", "orgName", orgName, "agentName", agentName)
 	// Delete agent from database
 	if err := s.AgentRepository.HardDeleteAgentByName(ctx, orgId, projectId, agentName); err != nil {
-		s.logger.Error("Critical: Agent deleted from OpenChoreo but DB deletion failed, retry required",
+		s.logger.ErrorContext(ctx, "Critical: Agent deleted from OpenChoreo but DB deletion failed, retry required",
 			"projectId", projectId, "projectName", projectName, "orgName", orgName, "error", err)
 		return fmt.Errorf("failed to delete agent %s from repository: %w", agentName, err)
 	}
@@ -484,26 +481,26 @@ func (s *agentManagerService) deleteAgentRecord(ctx context.Context, orgId uuid.
 	// Delete agent record from the database
 	if isSoftDelete {
 		if err := s.AgentRepository.SoftDeleteAgentByName(ctx, orgId, projectId, agentName); err != nil {
-			s.logger.Error("Failed to soft delete agent record", "agentName", agentName, "orgId", orgId, "projectId", projectId, "error", err)
+			s.logger.ErrorContext(ctx, "Failed to soft delete agent record", "agentName", agentName, "orgId", orgId, "projectId", projectId, "error", err)
 			return fmt.Errorf("failed to delete agent record: agentName %s, error: %w", agentName, err)
 		}
 	} else {
 		if err := s.AgentRepository.HardDeleteAgentByName(ctx, orgId, projectId, agentName); err != nil {
-			s.logger.Error("Failed to hard delete agent record", "agentName", agentName, "orgId", orgId, "projectId", projectId, "error", err)
+			s.logger.ErrorContext(ctx, "Failed to hard delete agent record", "agentName", agentName, "orgId", orgId, "projectId", projectId, "error", err)
 			return fmt.Errorf("failed to hard delete agent record: agentName %s, error: %w", agentName, err)
 		}
 	}
-	s.logger.Info("Agent record deleted successfully", "agentName", agentName, "orgId", orgId, "projectId", projectId, "isSoftDelete", isSoftDelete)
+	s.logger.InfoContext(ctx, "Agent record deleted successfully", "agentName", agentName, "orgId", orgId, "projectId", projectId, "isSoftDelete", isSoftDelete)
 	return nil
 }
 
 // BuildAgent triggers a build for an agent.
 func (s *agentManagerService) BuildAgent(ctx context.Context, userIdpId uuid.UUID, orgName string, projectName string, agentName string, commitId string) (*models.BuildResponse, error) {
-	s.logger.Info("Building agent", "agentName", agentName, "orgName", orgName, "projectName", projectName, "commitId", commitId, "userIdpId", userIdpId)
+	s.logger.InfoContext(ctx, "Building agent", "agentName", agentName, "orgName", orgName, "projectName", projectName, "commitId", commitId, "userIdpId", userIdpId)
 	// Validate organization exists
 	org, err := s.OrganizationRepository.GetOrganizationByOrgName(ctx, userIdpId, orgName)
 	if err != nil {
-		s.logger.Error("Failed to find organization", "orgName", orgName, "userIdpId", userIdpId, "error", err)
+		s.logger.ErrorContext(ctx, "Failed to find organization", "orgName", orgName, "userIdpId", userIdpId, "error", err)
 		if db.IsRecordNotFoundError(err) {
 			return nil, utils.ErrOrganizationNotFound
 		}
@@ -511,7 +508,7 @@ func (s *agentManagerService) BuildAgent(ctx context.Context, userIdpId uuid.UUI
 	}
 	project, err := s.ProjectRepository.GetProjectByName(ctx, org.ID, projectName)
 	if err != nil {
-		s.logger.Error("Failed to find project", "projectName", projectName, "orgId", org.ID, "error", err)
+		s.logger.ErrorContext(ctx, "Failed to find project", "projectName", projectName, "orgId", org.ID, "error", err)
 		if db.IsRecordNotFoundError(err) {
 			return nil, utils.ErrProjectNotFound
 		}
@@ -519,7 +516,7 @@ func (s *agentManagerService) BuildAgent(ctx context.Context, userIdpId uuid.UUI
 	}
 	agent, err := s.AgentRepository.GetAgentByName(ctx, org.ID, project.ID, agentName)
 	if err != nil {
-		s.logger.Error("Failed to fetch agent from repository", "agentName", agentName, "error", err)
+		s.logger.ErrorContext(ctx, "Failed to fetch agent from repository", "agentName", agentName, "error", err)
 		if db.IsRecordNotFoundError(err) {
 			return nil, utils.ErrAgentNotFound
 		}
@@ -529,10 +526,10 @@ func (s *agentManagerService) BuildAgent(ctx context.Context, userIdpId uuid.UUI
 		return nil, fmt.Errorf("build operation is not supported for agent type: '%s'", agent.ProvisioningType)
 	}
 	// Trigger build in Open Choreo
-	s.logger.Debug("Triggering build in OpenChoreo", "agentName", agentName, "orgName", orgName, "projectName", projectName, "commitId", commitId)
+	s.logger.DebugContext(ctx, "Triggering build in OpenChoreo", "agentName", agentName, "orgName", orgName, "projectName", projectName, "commitId", commitId)
 	build, err := s.OpenChoreoSvcClient.TriggerBuild(ctx, orgName, projectName, agentName, commitId)
 	if err != nil {
-		s.logger.Error("Failed to trigger build in OpenChoreo", "agentName", agentName, "orgName", orgName, "projectName", projectName, "error", err)
+		s.logger.ErrorContext(ctx, "Failed to trigger build in OpenChoreo", "agentName", agentName, "orgName", orgName, "projectName", projectName, "error", err)
 		if errors.Is(err, utils.ErrAgentNotFound) {
 			return nil, utils.ErrAgentNotFound
 		}
@@ -540,18 +537,18 @@ func (s *agentManagerService) BuildAgent(ctx context.Context, userIdpId uuid.UUI
 	}
 	err = s.AgentRepository.UpdateAgentTimestamp(ctx, org.ID, project.ID, agentName)
 	if err != nil {
-		s.logger.Error("Failed to update agent timestamp after successfully triggering the build", "agentName", agentName, "orgName", orgName, "projectName", projectName, "error", err)
+		s.logger.ErrorContext(ctx, "Failed to update agent timestamp after successfully triggering the build", "agentName", agentName, "orgName", orgName, "projectName", projectName, "error", err)
 	}
-	s.logger.Info("Build triggered successfully", "agentName", agentName, "orgName", orgName, "projectName", projectName, "buildName", build.Name)
+	s.logger.InfoContext(ctx, "Build triggered successfully", "agentName", agentName, "orgName", orgName, "projectName", projectName, "buildName", build.Name)
 	return build, nil
 }
 
 // DeployAgent deploys an agent.
 func (s *agentManagerService) DeployAgent(ctx context.Context, userIdpId uuid.UUID, orgName string, projectName string, agentName string, req *spec.DeployAgentRequest) (string, error) {
-	s.logger.Info("Deploying agent", "agentName", agentName, "orgName", orgName, "projectName", projectName, "imageId", req.ImageId, "userIdpId", userIdpId)
+	s.logger.InfoContext(ctx, "Deploying agent", "agentName", agentName, "orgName", orgName, "projectName", projectName, "imageId", req.ImageId, "userIdpId", userIdpId)
 	org, err := s.OrganizationRepository.GetOrganizationByOrgName(ctx, userIdpId, orgName)
 	if err != nil {
-		s.logger.Error("Failed to find organization", "orgName", orgName, "userIdpId", userIdpId, "error", err)
+		s.logger.ErrorContext(ctx, "Failed to find organization", "orgName", orgName, "userIdpId", userIdpId, "error", err)
 		if db.IsRecordNotFoundError(err) {
 			return "", utils.ErrOrganizationNotFound
 		}
@@ -559,7 +556,7 @@ func (s *agentManagerService) DeployAgent(ctx context.Context, userIdpId uuid.UU
 	}
 	project, err := s.ProjectRepository.GetProjectByName(ctx, org.ID, projectName)
 	if err != nil {
-		s.logger.Error("Failed to find project", "projectName", projectName, "orgId", org.ID, "error", err)
+		s.logger.ErrorContext(ctx, "Failed to find project", "projectName", projectName, "orgId", org.ID, "error", err)
 		if db.IsRecordNotFoundError(err) {
 			return "", utils.ErrProjectNotFound
 		}
@@ -567,7 +564,7 @@ func (s *agentManagerService) DeployAgent(ctx context.Context, userIdpId uuid.UU
 	}
 	agent, err := s.AgentRepository.GetAgentByName(ctx, org.ID, project.ID, agentName)
 	if err != nil {
-		s.logger.Error("Failed to fetch agent from repository", "agentName", agentName, "error", err)
+		s.logger.ErrorContext(ctx, "Failed to fetch agent from repository", "agentName", agentName, "error", err)
 		if db.IsRecordNotFoundError(err) {
 			return "", utils.ErrAgentNotFound
 		}
@@ -584,33 +581,33 @@ func (s *agentManagerService) DeployAgent(ctx context.Context, userIdpId uuid.UU
 	}
 
 	// Deploy agent component in Open Choreo
-	s.logger.Debug("Deploying agent component in OpenChoreo", "agentName", agentName, "orgName", orgName, "projectName", projectName, "imageId", req.ImageId)
+	s.logger.DebugContext(ctx, "Deploying agent component in OpenChoreo", "agentName", agentName, "orgName", orgName, "projectName", projectName, "imageId", req.ImageId)
 	if err := s.OpenChoreoSvcClient.DeployAgentComponent(ctx, orgName, projectName, agentName, deployReq); err != nil {
-		s.logger.Error("Failed to deploy agent component in OpenChoreo", "agentName", agentName, "orgName", orgName, "projectName", projectName, "error", err)
+		s.logger.ErrorContext(ctx, "Failed to deploy agent component in OpenChoreo", "agentName", agentName, "orgName", orgName, "projectName", projectName, "error", err)
 		return "", fmt.Errorf("failed to deploy agent component: agentName %s, error: %w", agentName, err)
 	}
 	openChoreoProject, err := s.OpenChoreoSvcClient.GetProject(ctx, projectName, orgName)
 	if err != nil {
-		s.logger.Error("Failed to fetch OpenChoreo project", "orgName", orgName, "projectName", projectName, "error", err)
+		s.logger.ErrorContext(ctx, "Failed to fetch OpenChoreo project", "orgName", orgName, "projectName", projectName, "error", err)
 		return "", fmt.Errorf("failed to fetch openchoreo project: %w", err)
 	}
 
 	pipelineName := openChoreoProject.DeploymentPipeline
 	if pipelineName == "" {
-		s.logger.Error("Project has no deployment pipeline configured", "orgName", orgName, "projectName", projectName)
+		s.logger.ErrorContext(ctx, "Project has no deployment pipeline configured", "orgName", orgName, "projectName", projectName)
 		return "", fmt.Errorf("project has no deployment pipeline configured")
 	}
 	pipeline, err := s.OpenChoreoSvcClient.GetDeploymentPipeline(ctx, orgName, pipelineName)
 	if err != nil {
-		s.logger.Error("Failed to fetch deployment pipeline", "orgName", orgName, "pipelineName", pipelineName, "error", err)
+		s.logger.ErrorContext(ctx, "Failed to fetch deployment pipeline", "orgName", orgName, "pipelineName", pipelineName, "error", err)
 		return "", fmt.Errorf("failed to fetch deployment pipeline: %w", err)
 	}
 	err = s.AgentRepository.UpdateAgentTimestamp(ctx, org.ID, project.ID, agentName)
 	if err != nil {
-		s.logger.Error("Failed to update agent timestamp after successful deployment", "agentName", agentName, "orgName", orgName, "projectName", projectName, "error", err)
+		s.logger.ErrorContext(ctx, "Failed to update agent timestamp after successful deployment", "agentName", agentName, "orgName", orgName, "projectName", projectName, "error", err)
 	}
 	lowestEnv := findLowestEnvironment(pipeline.PromotionPaths)
-	s.logger.Info("Agent deployed successfully to "+lowestEnv, "agentName", agentName, "orgName", orgName, "projectName", projectName, "environment", lowestEnv)
+	s.logger.InfoContext(ctx, "Agent deployed successfully to "+lowestEnv, "agentName", agentName, "orgName", orgName, "projectName", projectName, "environment", lowestEnv)
 	return lowestEnv, nil
 }
 
@@ -637,11 +634,11 @@ func findLowestEnvironment(promotionPaths []models.PromotionPath) string {
 }
 
 func (s *agentManagerService) GetBuildLogs(ctx context.Context, userIdpId uuid.UUID, orgName string, projectName string, agentName string, buildName string) (*models.BuildLogsResponse, error) {
-	s.logger.Info("Getting build logs", "agentName", agentName, "buildName", buildName, "orgName", orgName, "projectName", projectName, "userIdpId", userIdpId)
+	s.logger.InfoContext(ctx, "Getting build logs", "agentName", agentName, "buildName", buildName, "orgName", orgName, "projectName", projectName, "userIdpId", userIdpId)
 	// Validate organization exists
 	valid, err := s.validateOrganization(ctx, userIdpId, orgName)
 	if err != nil {
-		s.logger.Error("Failed to validate organization", "orgName", orgName, "userIdpId", userIdpId, "error", err)
+		s.logger.ErrorContext(ctx, "Failed to validate organization", "orgName", orgName, "userIdpId", userIdpId, "error", err)
 		return nil, fmt.Errorf("failed to find organization %s: %w", orgName, err)
 	}
 	if !valid {
@@ -651,7 +648,7 @@ func (s *agentManagerService) GetBuildLogs(ctx context.Context, userIdpId uuid.U
 	// Validates the project name by checking its existence
 	_, err = s.OpenChoreoSvcClient.GetProject(ctx, projectName, orgName)
 	if err != nil {
-		s.logger.Error("Failed to get OpenChoreo project", "projectName", projectName, "orgName", orgName, "error", err)
+		s.logger.ErrorContext(ctx, "Failed to get OpenChoreo project", "projectName", projectName, "orgName", orgName, "error", err)
 		return nil, err
 	}
 
@@ -659,10 +656,10 @@ func (s *agentManagerService) GetBuildLogs(ctx context.Context, userIdpId uuid.U
 	_, err = s.OpenChoreoSvcClient.GetAgentComponent(ctx, orgName, projectName, agentName)
 	if err != nil {
 		if errors.Is(err, utils.ErrAgentNotFound) {
-			s.logger.Warn("Agent component not found in OpenChoreo", "agentName", agentName, "orgName", orgName, "projectName", projectName)
+			s.logger.WarnContext(ctx, "Agent component not found in OpenChoreo", "agentName", agentName, "orgName", orgName, "projectName", projectName)
 			return nil, utils.ErrAgentNotFound
 		}
-		s.logger.Error("Failed to check component existence", "agentName", agentName, "orgName", orgName, "projectName", projectName, "error", err)
+		s.logger.ErrorContext(ctx, "Failed to check component existence", "agentName", agentName, "orgName", orgName, "projectName", projectName, "error", err)
 		return nil, fmt.Errorf("failed to check component existence: %w", err)
 	}
 
@@ -670,20 +667,20 @@ func (s *agentManagerService) GetBuildLogs(ctx context.Context, userIdpId uuid.U
 	build, err := s.OpenChoreoSvcClient.GetComponentWorkflow(ctx, orgName, projectName, agentName, buildName)
 	if err != nil {
 		if errors.Is(err, utils.ErrBuildNotFound) {
-			s.logger.Warn("Build not found", "buildName", buildName, "agentName", agentName, "orgName", orgName, "projectName", projectName)
+			s.logger.WarnContext(ctx, "Build not found", "buildName", buildName, "agentName", agentName, "orgName", orgName, "projectName", projectName)
 			return nil, utils.ErrBuildNotFound
 		}
-		s.logger.Error("Failed to get build", "buildName", buildName, "agentName", agentName, "orgName", orgName, "projectName", projectName, "error", err)
+		s.logger.ErrorContext(ctx, "Failed to get build", "buildName", buildName, "agentName", agentName, "orgName", orgName, "projectName", projectName, "error", err)
 		return nil, fmt.Errorf("failed to get build %s for agent %s: %w", buildName, agentName, err)
 	}
 
 	// Fetch the build logs from Observability service
 	buildLogs, err := s.ObservabilitySvcClient.GetBuildLogs(ctx, build.Name)
 	if err != nil {
-		s.logger.Error("Failed to fetch build logs from observability service", "buildName", build.Name, "error", err)
+		s.logger.ErrorContext(ctx, "Failed to fetch build logs from observability service", "buildName", build.Name, "error", err)
 		return nil, fmt.Errorf("failed to fetch build logs: %w", err)
 	}
-	s.logger.Info("Fetched build logs successfully", "agentName", agentName, "orgName", orgName, "projectName", projectName, "buildName", buildName, "logCount", len(buildLogs.Logs))
+	s.logger.InfoContext(ctx, "Fetched build logs successfully", "agentName", agentName, "orgName", orgName, "projectName", projectName, "buildName", buildName, "logCount", len(buildLogs.Logs))
 	return buildLogs, nil
 }
 
@@ -693,7 +690,7 @@ func (s *agentManagerService) validateOrganization(ctx context.Context, userIdpI
 		return false, err
 	}
 	if len(orgs) == 0 {
-		s.logger.Warn("No organizations found for user", "userIdpID", userIdpID)
+		s.logger.WarnContext(ctx, "No organizations found for user", "userIdpID", userIdpID)
 		return false, nil
 	}
 	for _, org := range orgs {
@@ -705,22 +702,22 @@ func (s *agentManagerService) validateOrganization(ctx context.Context, userIdpI
 }
 
 func (s *agentManagerService) ListAgentBuilds(ctx context.Context, userIdpId uuid.UUID, orgName string, projectName string, agentName string, limit int32, offset int32) ([]*models.BuildResponse, int32, error) {
-	s.logger.Info("Listing agent builds", "agentName", agentName, "orgName", orgName, "projectName", projectName, "limit", limit, "offset", offset, "userIdpId", userIdpId)
+	s.logger.InfoContext(ctx, "Listing agent builds", "agentName", agentName, "orgName", orgName, "projectName", projectName, "limit", limit, "offset", offset, "userIdpId", userIdpId)
 	// Validate organization exists
 	valid, err := s.validateOrganization(ctx, userIdpId, orgName)
 	if err != nil {
-		s.logger.Error("Failed to validate organization", "orgName", orgName, "userIdpId", userIdpId, "error", err)
+		s.logger.ErrorContext(ctx, "Failed to validate organization", "orgName", orgName, "userIdpId", userIdpId, "error", err)
 		return nil, 0, fmt.Errorf("failed to find organization %s: %w", orgName, err)
 	}
 	if !valid {
-		s.logger.Warn("Organization not found", "orgName", orgName, "userIdpId", userIdpId)
+		s.logger.WarnContext(ctx, "Organization not found", "orgName", orgName, "userIdpId", userIdpId)
 		return nil, 0, utils.ErrOrganizationNotFound
 	}
 
 	// Validates the project name by checking its existence
 	_, err = s.OpenChoreoSvcClient.GetProject(ctx, projectName, orgName)
 	if err != nil {
-		s.logger.Error("Failed to get OpenChoreo project", "projectName", projectName, "orgName", orgName, "error", err)
+		s.logger.ErrorContext(ctx, "Failed to get OpenChoreo project", "projectName", projectName, "orgName", orgName, "error", err)
 		return nil, 0, err
 	}
 
@@ -728,17 +725,17 @@ func (s *agentManagerService) ListAgentBuilds(ctx context.Context, userIdpId uui
 	_, err = s.OpenChoreoSvcClient.GetAgentComponent(ctx, orgName, projectName, agentName)
 	if err != nil {
 		if errors.Is(err, utils.ErrAgentNotFound) {
-			s.logger.Warn("Agent component not found in OpenChoreo", "agentName", agentName, "orgName", orgName, "projectName", projectName)
+			s.logger.WarnContext(ctx, "Agent component not found in OpenChoreo", "agentName", agentName, "orgName", orgName, "projectName", projectName)
 			return nil, 0, utils.ErrAgentNotFound
 		}
-		s.logger.Error("Failed to check component existence", "agentName", agentName, "orgName", orgName, "projectName", projectName, "error", err)
+		s.logger.ErrorContext(ctx, "Failed to check component existence", "agentName", agentName, "orgName", orgName, "projectName", projectName, "error", err)
 		return nil, 0, fmt.Errorf("failed to check component existence: %w", err)
 	}
 
 	// Fetch all builds from Open Choreo first
 	allBuilds, err := s.OpenChoreoSvcClient.ListComponentWorkflows(ctx, orgName, projectName, agentName)
 	if err != nil {
-		s.logger.Error("Failed to list builds from OpenChoreo", "agentName", agentName, "orgName", orgName, "projectName", projectName, "error", err)
+		s.logger.ErrorContext(ctx, "Failed to list builds from OpenChoreo", "agentName", agentName, "orgName", orgName, "projectName", projectName, "error", err)
 		return nil, 0, fmt.Errorf("failed to list builds for agent %s: %w", agentName, err)
 	}
 
@@ -758,16 +755,16 @@ func (s *agentManagerService) ListAgentBuilds(ctx context.Context, userIdpId uui
 		paginatedBuilds = allBuilds[offset:endIndex]
 	}
 
-	s.logger.Info("Listed builds successfully", "agentName", agentName, "orgName", orgName, "projectName", projectName, "totalBuilds", total, "returnedBuilds", len(paginatedBuilds))
+	s.logger.InfoContext(ctx, "Listed builds successfully", "agentName", agentName, "orgName", orgName, "projectName", projectName, "totalBuilds", total, "returnedBuilds", len(paginatedBuilds))
 	return paginatedBuilds, total, nil
 }
 
 func (s *agentManagerService) GetBuild(ctx context.Context, userIdpId uuid.UUID, orgName string, projectName string, agentName string, buildName string) (*models.BuildDetailsResponse, error) {
-	s.logger.Info("Getting build details", "agentName", agentName, "buildName", buildName, "orgName", orgName, "projectName", projectName, "userIdpId", userIdpId)
+	s.logger.InfoContext(ctx, "Getting build details", "agentName", agentName, "buildName", buildName, "orgName", orgName, "projectName", projectName, "userIdpId", userIdpId)
 	// Validate organization exists
 	org, err := s.OrganizationRepository.GetOrganizationByOrgName(ctx, userIdpId, orgName)
 	if err != nil {
-		s.logger.Error("Failed to find organization", "orgName", orgName, "userIdpId", userIdpId, "error", err)
+		s.logger.ErrorContext(ctx, "Failed to find organization", "orgName", orgName, "userIdpId", userIdpId, "error", err)
 		if db.IsRecordNotFoundError(err) {
 			return nil, utils.ErrOrganizationNotFound
 		}
@@ -775,7 +772,7 @@ func (s *agentManagerService) GetBuild(ctx context.Context, userIdpId uuid.UUID,
 	}
 	project, err := s.ProjectRepository.GetProjectByName(ctx, org.ID, projectName)
 	if err != nil {
-		s.logger.Error("Failed to find project", "projectName", projectName, "orgId", org.ID, "error", err)
+		s.logger.ErrorContext(ctx, "Failed to find project", "projectName", projectName, "orgId", org.ID, "error", err)
 		if db.IsRecordNotFoundError(err) {
 			return nil, utils.ErrProjectNotFound
 		}
@@ -783,7 +780,7 @@ func (s *agentManagerService) GetBuild(ctx context.Context, userIdpId uuid.UUID,
 	}
 	agent, err := s.AgentRepository.GetAgentByName(ctx, org.ID, project.ID, agentName)
 	if err != nil {
-		s.logger.Error("Failed to fetch agent from repository", "agentName", agentName, "error", err)
+		s.logger.ErrorContext(ctx, "Failed to fetch agent from repository", "agentName", agentName, "error", err)
 		if db.IsRecordNotFoundError(err) {
 			return nil, utils.ErrAgentNotFound
 		}
@@ -795,23 +792,23 @@ func (s *agentManagerService) GetBuild(ctx context.Context, userIdpId uuid.UUID,
 	// Fetch the build from Open Choreo
 	build, err := s.OpenChoreoSvcClient.GetComponentWorkflow(ctx, orgName, projectName, agentName, buildName)
 	if err != nil {
-		s.logger.Error("Failed to get build from OpenChoreo", "buildName", buildName, "agentName", agentName, "orgName", orgName, "projectName", projectName, "error", err)
+		s.logger.ErrorContext(ctx, "Failed to get build from OpenChoreo", "buildName", buildName, "agentName", agentName, "orgName", orgName, "projectName", projectName, "error", err)
 		if errors.Is(err, utils.ErrBuildNotFound) {
 			return nil, utils.ErrBuildNotFound
 		}
 		return nil, fmt.Errorf("failed to get build %s for agent %s: %w", buildName, agentName, err)
 	}
 
-	s.logger.Info("Fetched build successfully", "agentName", agentName, "orgName", orgName, "projectName", projectName, "buildName", build.Name)
+	s.logger.InfoContext(ctx, "Fetched build successfully", "agentName", agentName, "orgName", orgName, "projectName", projectName, "buildName", build.Name)
 	return build, nil
 }
 
 func (s *agentManagerService) GetAgentDeployments(ctx context.Context, userIdpId uuid.UUID, orgName string, projectName string, agentName string) ([]*models.DeploymentResponse, error) {
-	s.logger.Info("Getting agent deployments", "agentName", agentName, "orgName", orgName, "projectName", projectName, "userIdpId", userIdpId)
+	s.logger.InfoContext(ctx, "Getting agent deployments", "agentName", agentName, "orgName", orgName, "projectName", projectName, "userIdpId", userIdpId)
 	// Validate organization exists
 	org, err := s.OrganizationRepository.GetOrganizationByOrgName(ctx, userIdpId, orgName)
 	if err != nil {
-		s.logger.Error("Failed to find organization", "orgName", orgName, "userIdpId", userIdpId, "error", err)
+		s.logger.ErrorContext(ctx, "Failed to find organization", "orgName", orgName, "userIdpId", userIdpId, "error", err)
 		if db.IsRecordNotFoundError(err) {
 			return nil, utils.ErrOrganizationNotFound
 		}
@@ -819,7 +816,7 @@ func (s *agentManagerService) GetAgentDeployments(ctx context.Context, userIdpId
 	}
 	project, err := s.ProjectRepository.GetProjectByName(ctx, org.ID, projectName)
 	if err != nil {
-		s.logger.Error("Failed to find project", "projectName", projectName, "orgId", org.ID, "error", err)
+		s.logger.ErrorContext(ctx, "Failed to find project", "projectName", projectName, "orgId", org.ID, "error", err)
 		if db.IsRecordNotFoundError(err) {
 			return nil, utils.ErrProjectNotFound
 		}
@@ -827,7 +824,7 @@ func (s *agentManagerService) GetAgentDeployments(ctx context.Context, userIdpId
 	}
 	agent, err := s.AgentRepository.GetAgentByName(ctx, org.ID, project.ID, agentName)
 	if err != nil {
-		s.logger.Error("Failed to fetch agent from repository", "agentName", agentName, "error", err)
+		s.logger.ErrorContext(ctx, "Failed to fetch agent from repository", "agentName", agentName, "error", err)
 		if db.IsRecordNotFoundError(err) {
 			return nil, utils.ErrAgentNotFound
 		}
@@ -839,26 +836,26 @@ func (s *agentManagerService) GetAgentDeployments(ctx context.Context, userIdpId
 	// Fetch OC project details
 	openChoreoProject, err := s.OpenChoreoSvcClient.GetProject(ctx, projectName, orgName)
 	if err != nil {
-		s.logger.Error("Failed to fetch OpenChoreo project", "projectName", projectName, "orgName", orgName, "error", err)
+		s.logger.ErrorContext(ctx, "Failed to fetch OpenChoreo project", "projectName", projectName, "orgName", orgName, "error", err)
 		return nil, err
 	}
 	pipelineName := openChoreoProject.DeploymentPipeline
 	deployments, err := s.OpenChoreoSvcClient.GetAgentDeployments(ctx, orgName, pipelineName, projectName, agentName)
 	if err != nil {
-		s.logger.Error("Failed to get deployments from OpenChoreo", "agentName", agentName, "pipelineName", pipelineName, "orgName", orgName, "projectName", projectName, "error", err)
+		s.logger.ErrorContext(ctx, "Failed to get deployments from OpenChoreo", "agentName", agentName, "pipelineName", pipelineName, "orgName", orgName, "projectName", projectName, "error", err)
 		return nil, fmt.Errorf("failed to get deployments for agent %s: %w", agentName, err)
 	}
 
-	s.logger.Info("Fetched deployments successfully", "agentName", agentName, "orgName", orgName, "projectName", projectName, "deploymentCount", len(deployments))
+	s.logger.InfoContext(ctx, "Fetched deployments successfully", "agentName", agentName, "orgName", orgName, "projectName", projectName, "deploymentCount", len(deployments))
 	return deployments, nil
 }
 
 func (s *agentManagerService) GetAgentEndpoints(ctx context.Context, userIdpId uuid.UUID, orgName string, projectName string, agentName string, environmentName string) (map[string]models.EndpointsResponse, error) {
-	s.logger.Info("Getting agent endpoints", "agentName", agentName, "orgName", orgName, "projectName", projectName, "environment", environmentName, "userIdpId", userIdpId)
+	s.logger.InfoContext(ctx, "Getting agent endpoints", "agentName", agentName, "orgName", orgName, "projectName", projectName, "environment", environmentName, "userIdpId", userIdpId)
 	// Validate organization exists
 	org, err := s.OrganizationRepository.GetOrganizationByOrgName(ctx, userIdpId, orgName)
 	if err != nil {
-		s.logger.Error("Failed to find organization", "orgName", orgName, "userIdpId", userIdpId, "error", err)
+		s.logger.ErrorContext(ctx, "Failed to find organization", "orgName", orgName, "userIdpId", userIdpId, "error", err)
 		if db.IsRecordNotFoundError(err) {
 			return nil, utils.ErrOrganizationNotFound
 		}
@@ -866,7 +863,7 @@ func (s *agentManagerService) GetAgentEndpoints(ctx context.Context, userIdpId u
 	}
 	project, err := s.ProjectRepository.GetProjectByName(ctx, org.ID, projectName)
 	if err != nil {
-		s.logger.Error("Failed to find project", "projectName", projectName, "orgName", orgName, "error", err)
+		s.logger.ErrorContext(ctx, "Failed to find project", "projectName", projectName, "orgName", orgName, "error", err)
 
 		if db.IsRecordNotFoundError(err) {
 			return nil, utils.ErrProjectNotFound
@@ -875,7 +872,7 @@ func (s *agentManagerService) GetAgentEndpoints(ctx context.Context, userIdpId u
 	}
 	agent, err := s.AgentRepository.GetAgentByName(ctx, org.ID, project.ID, agentName)
 	if err != nil {
-		s.logger.Error("Failed to fetch agent", "agentName", agentName, "projectName", projectName, "orgName", orgName, "error", err)
+		s.logger.ErrorContext(ctx, "Failed to fetch agent", "agentName", agentName, "projectName", projectName, "orgName", orgName, "error", err)
 		if db.IsRecordNotFoundError(err) {
 			return nil, utils.ErrAgentNotFound
 		}
@@ -887,25 +884,25 @@ func (s *agentManagerService) GetAgentEndpoints(ctx context.Context, userIdpId u
 	// Check if environment exists
 	_, err = s.OpenChoreoSvcClient.GetEnvironment(ctx, orgName, environmentName)
 	if err != nil {
-		s.logger.Error("Failed to validate environment", "environment", environmentName, "orgName", orgName, "error", err)
+		s.logger.ErrorContext(ctx, "Failed to validate environment", "environment", environmentName, "orgName", orgName, "error", err)
 		return nil, fmt.Errorf("failed to get environments for organization %s: %w", orgName, err)
 	}
-	s.logger.Debug("Fetching agent endpoints from OpenChoreo", "agentName", agentName, "environment", environmentName, "orgName", orgName, "projectName", projectName)
+	s.logger.DebugContext(ctx, "Fetching agent endpoints from OpenChoreo", "agentName", agentName, "environment", environmentName, "orgName", orgName, "projectName", projectName)
 	endpoints, err := s.OpenChoreoSvcClient.GetAgentEndpoints(ctx, orgName, projectName, agentName, environmentName)
 	if err != nil {
-		s.logger.Error("Failed to fetch endpoints", "agentName", agentName, "environment", environmentName, "orgName", orgName, "projectName", projectName, "error", err)
+		s.logger.ErrorContext(ctx, "Failed to fetch endpoints", "agentName", agentName, "environment", environmentName, "orgName", orgName, "projectName", projectName, "error", err)
 		return nil, fmt.Errorf("failed to get endpoints for agent %s: %w", agentName, err)
 	}
 
-	s.logger.Info("Fetched endpoints successfully", "agentName", agentName, "orgName", orgName, "projectName", projectName, "environment", environmentName, "endpointCount", len(endpoints))
+	s.logger.InfoContext(ctx, "Fetched endpoints successfully", "agentName", agentName, "orgName", orgName, "projectName", projectName, "environment", environmentName, "endpointCount", len(endpoints))
 	return endpoints, nil
 }
 
 func (s *agentManagerService) GetAgentConfigurations(ctx context.Context, userIdpId uuid.UUID, orgName string, projectName string, agentName string, environment string) ([]models.EnvVars, error) {
-	s.logger.Info("Getting agent configurations", "agentName", agentName, "orgName", orgName, "projectName", projectName, "environment", environment, "userIdpId", userIdpId)
+	s.logger.InfoContext(ctx, "Getting agent configurations", "agentName", agentName, "orgName", orgName, "projectName", projectName, "environment", environment, "userIdpId", userIdpId)
 	org, err := s.OrganizationRepository.GetOrganizationByOrgName(ctx, userIdpId, orgName)
 	if err != nil {
-		s.logger.Error("Failed to find organization", "orgName", orgName, "userIdpId", userIdpId, "error", err)
+		s.logger.ErrorContext(ctx, "Failed to find organization", "orgName", orgName, "userIdpId", userIdpId, "error", err)
 		if db.IsRecordNotFoundError(err) {
 			return nil, utils.ErrOrganizationNotFound
 		}
@@ -913,7 +910,7 @@ func (s *agentManagerService) GetAgentConfigurations(ctx context.Context, userId
 	}
 	project, err := s.ProjectRepository.GetProjectByName(ctx, org.ID, projectName)
 	if err != nil {
-		s.logger.Error("Failed to find project", "projectName", projectName, "orgName", orgName, "error", err)
+		s.logger.ErrorContext(ctx, "Failed to find project", "projectName", projectName, "orgName", orgName, "error", err)
 		if db.IsRecordNotFoundError(err) {
 			return nil, utils.ErrProjectNotFound
 		}
@@ -921,31 +918,31 @@ func (s *agentManagerService) GetAgentConfigurations(ctx context.Context, userId
 	}
 	agent, err := s.AgentRepository.GetAgentByName(ctx, org.ID, project.ID, agentName)
 	if err != nil {
-		s.logger.Error("Failed to fetch agent", "agentName", agentName, "projectName", projectName, "orgName", orgName, "error", err)
+		s.logger.ErrorContext(ctx, "Failed to fetch agent", "agentName", agentName, "projectName", projectName, "orgName", orgName, "error", err)
 		if db.IsRecordNotFoundError(err) {
 			return nil, utils.ErrAgentNotFound
 		}
 		return nil, fmt.Errorf("failed to fetch agent: %w", err)
 	}
 	if agent.ProvisioningType != string(utils.InternalAgent) {
-		s.logger.Warn("Configuration operation not supported for agent type", "agentName", agentName, "provisioningType", agent.ProvisioningType, "orgName", orgName, "projectName", projectName)
+		s.logger.WarnContext(ctx, "Configuration operation not supported for agent type", "agentName", agentName, "provisioningType", agent.ProvisioningType, "orgName", orgName, "projectName", projectName)
 		return nil, fmt.Errorf("configuration operation is not supported for agent type: '%s'", agent.ProvisioningType)
 	}
 	// Check if environment exists
 	_, err = s.OpenChoreoSvcClient.GetEnvironment(ctx, orgName, environment)
 	if err != nil {
-		s.logger.Error("Failed to validate environment", "environment", environment, "orgName", orgName, "error", err)
+		s.logger.ErrorContext(ctx, "Failed to validate environment", "environment", environment, "orgName", orgName, "error", err)
 		return nil, fmt.Errorf("failed to get environments for organization %s: %w", orgName, err)
 	}
 
-	s.logger.Debug("Fetching agent configurations from OpenChoreo", "agentName", agentName, "environment", environment, "orgName", orgName, "projectName", projectName)
+	s.logger.DebugContext(ctx, "Fetching agent configurations from OpenChoreo", "agentName", agentName, "environment", environment, "orgName", orgName, "projectName", projectName)
 	configurations, err := s.OpenChoreoSvcClient.GetAgentConfigurations(ctx, orgName, projectName, agentName, environment)
 	if err != nil {
-		s.logger.Error("Failed to fetch configurations", "agentName", agentName, "environment", environment, "orgName", orgName, "projectName", projectName, "error", err)
+		s.logger.ErrorContext(ctx, "Failed to fetch configurations", "agentName", agentName, "environment", environment, "orgName", orgName, "projectName", projectName, "error", err)
 		return nil, fmt.Errorf("failed to get configurations for agent %s: %w", agentName, err)
 	}
 
-	s.logger.Info("Fetched configurations successfully", "agentName", agentName, "orgName", orgName, "projectName", projectName, "environment", environment, "configCount", len(configurations))
+	s.logger.InfoContext(ctx, "Fetched configurations successfully", "agentName", agentName, "orgName", orgName, "projectName", projectName, "environment", environment, "configCount", len(configurations))
 	return configurations, nil
 }
 
@@ -0,0 +1,228 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/db"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/models"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/repositories"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/utils"
+)
+
+type APIKeyService interface {
+	ListAPIKeys(ctx context.Context, userIdpId uuid.UUID, orgName string, limit int, offset int) ([]*models.APIKey, int32, error)
+	// CreateAPIKey returns the stored key together with its secret, which cannot be recovered afterwards
+	CreateAPIKey(ctx context.Context, userIdpId uuid.UUID, orgName string, req *models.APIKeyRequest) (*models.APIKey, string, error)
+	RevokeAPIKey(ctx context.Context, userIdpId uuid.UUID, orgName string, keyId uuid.UUID) error
+	// AuthenticateAPIKey resolves a key presented by a client, unknown, expired and revoked keys are rejected
+	AuthenticateAPIKey(ctx context.Context, key string) (*models.APIKeyPrincipal, error)
+	// FlushLastUsed writes the last use of the keys authenticated since the previous flush
+	FlushLastUsed(ctx context.Context) error
+	// RunLastUsedFlusher flushes last use timestamps every interval until ctx is done
+	RunLastUsedFlusher(ctx context.Context, interval time.Duration)
+}
+
+type apiKeyService struct {
+	OrganizationRepository repositories.OrganizationRepository
+	APIKeyRepository       repositories.APIKeyRepository
+	logger                 *slog.Logger
+
+	// Last use of each key not yet written to the database
+	mu       sync.Mutex
+	lastUsed map[uuid.UUID]time.Time
+}
+
+func NewAPIKeyService(
+	orgRepo repositories.OrganizationRepository,
+	apiKeyRepo repositories.APIKeyRepository,
+	logger *slog.Logger,
+) APIKeyService {
+	return &apiKeyService{
+		OrganizationRepository: orgRepo,
+		APIKeyRepository:       apiKeyRepo,
+		logger:                 logger,
+		lastUsed:               map[uuid.UUID]time.Time{},
+	}
+}
+
+func (s *apiKeyService) getOrganization(ctx context.Context, userIdpId uuid.UUID, orgName string) (*models.Organization, error) {
+	org, err := s.OrganizationRepository.GetOrganizationByOrgName(ctx, userIdpId, orgName)
+	if err != nil {
+		if db.IsRecordNotFoundError(err) {
+			s.logger.ErrorContext(ctx, "Organization not found", "orgName", orgName, "userIdpId", userIdpId)
+			return nil, utils.ErrOrganizationNotFound
+		}
+		s.logger.ErrorContext(ctx, "Failed to find organization", "orgName", orgName, "userIdpId", userIdpId, "error", err)
+		return nil, fmt.Errorf("failed to find organization %s: %w", orgName, err)
+	}
+	return org, nil
+}
+
+func (s *apiKeyService) ListAPIKeys(ctx context.Context, userIdpId uuid.UUID, orgName string, limit int, offset int) ([]*models.APIKey, int32, error) {
+	s.logger.InfoContext(ctx, "Listing api keys", "orgName", orgName, "limit", limit, "offset", offset, "userIdpId", userIdpId)
+	org, err := s.getOrganization(ctx, userIdpId, orgName)
+	if err != nil {
+		return nil, 0, err
+	}
+	apiKeys, total, err := s.APIKeyRepository.ListAPIKeys(ctx, org.ID, userIdpId, limit, offset)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to list api keys", "userIdpId", userIdpId, "error", err)
+		return nil, 0, fmt.Errorf("failed to list api keys: %w", err)
+	}
+	return apiKeys, int32(total), nil
+}
+
+func (s *apiKeyService) CreateAPIKey(ctx context.Context, userIdpId uuid.UUID, orgName string, req *models.APIKeyRequest) (*models.APIKey, string, error) {
+	s.logger.InfoContext(ctx, "Creating api key", "orgName", orgName, "apiKeyName", req.Name, "scopes", req.Scopes, "userIdpId", userIdpId)
+	org, err := s.getOrganization(ctx, userIdpId, orgName)
+	if err != nil {
+		return nil, "", err
+	}
+	_, err = s.APIKeyRepository.GetAPIKeyByName(ctx, org.ID, userIdpId, req.Name)
+	if err == nil {
+		return nil, "", utils.ErrAPIKeyAlreadyExists
+	}
+	if !db.IsRecordNotFoundError(err) {
+		return nil, "", fmt.Errorf("failed to check api key name %s: %w", req.Name, err)
+	}
+
+	key, displayPrefix, hash, err := utils.GenerateAPIKey()
+	if err != nil {
+		return nil, "", err
+	}
+	apiKey := &models.APIKey{
+		ID:        uuid.New(),
+		OrgID:     org.ID,
+		UserIdpId: userIdpId,
+		Name:      req.Name,
+		KeyPrefix: displayPrefix,
+		KeyHash:   hash,
+		Scopes:    req.Scopes,
+		ExpiresAt: req.ExpiresAt,
+		RateLimit: req.RateLimit,
+		CreatedAt: time.Now(),
+	}
+	if err := s.APIKeyRepository.CreateAPIKey(ctx, apiKey); err != nil {
+		if db.IsUniqueViolationError(err) {
+			return nil, "", utils.ErrAPIKeyAlreadyExists
+		}
+		s.logger.ErrorContext(ctx, "Failed to create api key", "apiKeyName", req.Name, "userIdpId", userIdpId, "error", err)
+		return nil, "", fmt.Errorf("failed to create api key %s: %w", req.Name, err)
+	}
+	s.logger.InfoContext(ctx, "API key created successfully", "apiKeyId", apiKey.ID, "apiKeyName", apiKey.Name, "userIdpId", userIdpId)
+	return apiKey, key, nil
+}
+
+func (s *apiKeyService) RevokeAPIKey(ctx context.Context, userIdpId uuid.UUID, orgName string, keyId uuid.UUID) error {
+	s.logger.InfoContext(ctx, "Revoking api key", "apiKeyId", keyId, "orgName", orgName, "userIdpId", userIdpId)
+	org, err := s.getOrganization(ctx, userIdpId, orgName)
+	if err != nil {
+		return err
+	}
+	revoked, err := s.APIKeyRepository.RevokeAPIKey(ctx, org.ID, userIdpId, keyId, time.Now())
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to revoke api key", "apiKeyId", keyId, "userIdpId", userIdpId, "error", err)
+		return fmt.Errorf("failed to revoke api key %s: %w", keyId, err)
+	}
+	if !revoked {
+		return utils.ErrAPIKeyNotFound
+	}
+	s.logger.InfoContext(ctx, "API key revoked successfully", "apiKeyId", keyId, "userIdpId", userIdpId)
+	return nil
+}
+
+func (s *apiKeyService) AuthenticateAPIKey(ctx context.Context, key string) (*models.APIKeyPrincipal, error) {
+	apiKey, err := s.APIKeyRepository.GetAPIKeyByHash(ctx, utils.HashAPIKey(key))
+	if err != nil {
+		if db.IsRecordNotFoundError(err) {
+			return nil, utils.ErrAPIKeyInvalid
+		}
+		return nil, fmt.Errorf("failed to find api key: %w", err)
+	}
+	now := time.Now()
+	if apiKey.RevokedAt != nil {
+		return nil, utils.ErrAPIKeyRevoked
+	}
+	if apiKey.ExpiresAt != nil && !apiKey.ExpiresAt.After(now) {
+		return nil, utils.ErrAPIKeyExpired
+	}
+	org, err := s.OrganizationRepository.GetOrganizationById(ctx, apiKey.OrgID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find organization of api key %s: %w", apiKey.ID, err)
+	}
+
+	s.mu.Lock()
+	s.lastUsed[apiKey.ID] = now
+	s.mu.Unlock()
+
+	return &models.APIKeyPrincipal{
+		KeyID:     apiKey.ID,
+		UserIdpId: apiKey.UserIdpId,
+		OrgName:   org.OrgName,
+		Scopes:    apiKey.Scopes,
+		RateLimit: apiKey.RateLimit,
+	}, nil
+}
+
+func (s *apiKeyService) FlushLastUsed(ctx context.Context) error {
+	s.mu.Lock()
+	pending := s.lastUsed
+	s.lastUsed = map[uuid.UUID]time.Time{}
+	s.mu.Unlock()
+
+	var firstErr error
+	for keyId, lastUsedAt := range pending {
+		if err := s.APIKeyRepository.UpdateLastUsedAt(ctx, keyId, lastUsedAt); err != nil {
+			s.logger.ErrorContext(ctx, "Failed to record api key use", "apiKeyId", keyId, "error", err)
+			s.requeueLastUsed(keyId, lastUsedAt)
+			if firstErr == nil {
+				firstErr = fmt.Errorf("failed to record use of api key %s: %w", keyId, err)
+			}
+		}
+	}
+	return firstErr
+}
+
+// requeueLastUsed keeps a use that failed to flush for the next flush unless the key was used again since
+func (s *apiKeyService) requeueLastUsed(keyId uuid.UUID, lastUsedAt time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if current, ok := s.lastUsed[keyId]; !ok || current.Before(lastUsedAt) {
+		s.lastUsed[keyId] = lastUsedAt
+	}
+}
+
+func (s *apiKeyService) RunLastUsedFlusher(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			_ = s.FlushLastUsed(ctx)
+		}
+	}
+}
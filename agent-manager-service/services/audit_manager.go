@@ -0,0 +1,171 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/google/uuid"
+
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/db"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/models"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/repositories"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/utils"
+)
+
+// auditBatchSize bounds how many queued entries are written in one statement
+const auditBatchSize = 100
+
+type AuditService interface {
+	// Record queues an entry to be written in the background without ever blocking the request,
+	// entries that find the queue full are dropped and logged
+	Record(ctx context.Context, auditLog *models.AuditLog)
+	ListAuditLogs(ctx context.Context, userIdpId uuid.UUID, orgName string, filter models.AuditLogFilter, limit int, offset int) ([]*models.AuditLog, int32, error)
+	// Run writes queued entries until ctx is done, then writes the entries left and returns
+	Run(ctx context.Context)
+}
+
+type auditService struct {
+	OrganizationRepository repositories.OrganizationRepository
+	AuditLogRepository     repositories.AuditLogRepository
+	logger                 *slog.Logger
+	queue                  chan *models.AuditLog
+}
+
+func NewAuditService(
+	orgRepo repositories.OrganizationRepository,
+	auditLogRepo repositories.AuditLogRepository,
+	logger *slog.Logger,
+	queueSize int,
+) AuditService {
+	return &auditService{
+		OrganizationRepository: orgRepo,
+		AuditLogRepository:     auditLogRepo,
+		logger:                 logger,
+		queue:                  make(chan *models.AuditLog, queueSize),
+	}
+}
+
+// withoutAuditLog unbinds the audit entry of the request, for work that must not record into it
+func withoutAuditLog(ctx context.Context) context.Context {
+	return utils.WithAuditLog(ctx, nil)
+}
+
+// recordAuditChanges adds the fields an update changed to the audit entry of the request, if it has one
+func recordAuditChanges(ctx context.Context, logger *slog.Logger, before any, after any) {
+	auditLog, ok := utils.AuditLogFromContext(ctx)
+	if !ok {
+		return
+	}
+	changes, err := utils.AuditDiff(before, after)
+	if err != nil {
+		logger.ErrorContext(ctx, "Failed to compute audited changes", "error", err)
+		return
+	}
+	auditLog.Changes = changes
+}
+
+// recordAuditResource sets the id of the resource a request acted on in its audit entry, for requests whose path does
+// not name it
+func recordAuditResource(ctx context.Context, resourceID string) {
+	if auditLog, ok := utils.AuditLogFromContext(ctx); ok {
+		auditLog.ResourceID = resourceID
+	}
+}
+
+func (s *auditService) Record(ctx context.Context, auditLog *models.AuditLog) {
+	select {
+	case s.queue <- auditLog:
+	default:
+		s.logger.ErrorContext(ctx, "AUDIT LOG LOST: queue is full, dropping entry", auditLogAttrs(auditLog)...)
+	}
+}
+
+func (s *auditService) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			// Requests have stopped, write what they left behind
+			s.writeBatch(context.WithoutCancel(ctx), s.drain(nil, len(s.queue)))
+			return
+		case auditLog := <-s.queue:
+			s.writeBatch(ctx, s.drain([]*models.AuditLog{auditLog}, auditBatchSize-1))
+		}
+	}
+}
+
+// drain appends up to limit entries already queued to batch
+func (s *auditService) drain(batch []*models.AuditLog, limit int) []*models.AuditLog {
+	for i := 0; i < limit; i++ {
+		select {
+		case auditLog := <-s.queue:
+			batch = append(batch, auditLog)
+		default:
+			return batch
+		}
+	}
+	return batch
+}
+
+func (s *auditService) writeBatch(ctx context.Context, batch []*models.AuditLog) {
+	if len(batch) == 0 {
+		return
+	}
+	// A write cut short by shutdown would lose the batch
+	if err := s.AuditLogRepository.CreateAuditLogs(context.WithoutCancel(ctx), batch); err != nil {
+		for _, auditLog := range batch {
+			s.logger.ErrorContext(ctx, "AUDIT LOG LOST: failed to write entry", append(auditLogAttrs(auditLog), "error", err)...)
+		}
+	}
+}
+
+// auditLogAttrs describes an entry in the log, so an entry that could not be written can still be recovered
+func auditLogAttrs(auditLog *models.AuditLog) []any {
+	return []any{
+		"auditLogId", auditLog.ID,
+		"orgId", auditLog.OrgID,
+		"actorIdpId", auditLog.ActorIdpId,
+		"apiKeyId", auditLog.APIKeyID,
+		"action", auditLog.Action,
+		"resourceType", auditLog.ResourceType,
+		"resourceId", auditLog.ResourceID,
+		"route", auditLog.Route,
+		"statusCode", auditLog.StatusCode,
+		"changes", auditLog.Changes,
+		"correlationId", auditLog.CorrelationID,
+		"timestamp", auditLog.CreatedAt,
+	}
+}
+
+func (s *auditService) ListAuditLogs(ctx context.Context, userIdpId uuid.UUID, orgName string, filter models.AuditLogFilter, limit int, offset int) ([]*models.AuditLog, int32, error) {
+	s.logger.InfoContext(ctx, "Listing audit logs", "orgName", orgName, "limit", limit, "offset", offset, "userIdpId", userIdpId)
+	org, err := s.OrganizationRepository.GetOrganizationByOrgName(ctx, userIdpId, orgName)
+	if err != nil {
+		if db.IsRecordNotFoundError(err) {
+			return nil, 0, utils.ErrOrganizationNotFound
+		}
+		return nil, 0, fmt.Errorf("failed to find organization %s: %w", orgName, err)
+	}
+	auditLogs, total, err := s.AuditLogRepository.ListAuditLogs(ctx, org.ID, filter, limit, offset)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to list audit logs", "orgName", orgName, "error", err)
+		return nil, 0, fmt.Errorf("failed to list audit logs: %w", err)
+	}
+	return auditLogs, int32(total), nil
+}
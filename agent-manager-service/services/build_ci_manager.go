@@ -63,7 +63,7 @@ func (b *buildCIManagerService) HandleBuildCallback(ctx context.Context, orgName
 	org, err := b.OrganizationRepo.GetOrganizationByOcName(ctx, orgName)
 	if err != nil {
 		if db.IsRecordNotFoundError(err) {
-			b.logger.Error("Organization not found", "organization", orgName)
+			b.logger.ErrorContext(ctx, "Organization not found", "organization", orgName)
 			return "", fmt.Errorf("organization not found: %s", orgName)
 		}
 		return "", fmt.Errorf("failed to find organization %s: %w", orgName, err)
@@ -73,7 +73,7 @@ func (b *buildCIManagerService) HandleBuildCallback(ctx context.Context, orgName
 	project, err := b.ProjectRepo.GetProjectByName(ctx, org.ID, projectName)
 	if err != nil {
 		if db.IsRecordNotFoundError(err) {
-			b.logger.Error("Project not found", "project", projectName, "organization", orgName)
+			b.logger.ErrorContext(ctx, "Project not found", "project", projectName, "organization", orgName)
 			return "", fmt.Errorf("project not found: %s", projectName)
 		}
 		return "", fmt.Errorf("failed to find project %s: %w", projectName, err)
@@ -83,7 +83,7 @@ func (b *buildCIManagerService) HandleBuildCallback(ctx context.Context, orgName
 	agent, err := b.AgentRepo.GetAgentByName(ctx, org.ID, project.ID, agentName)
 	if err != nil {
 		if db.IsRecordNotFoundError(err) {
-			b.logger.Error("Agent not found", "agentName", agentName, "project", projectName, "organization", orgName)
+			b.logger.ErrorContext(ctx, "Agent not found", "agentName", agentName, "project", projectName, "organization", orgName)
 			return "", fmt.Errorf("agent not found: %s", agentName)
 		}
 		return "", fmt.Errorf("failed to fetch agent: %w", err)
@@ -97,7 +97,7 @@ func (b *buildCIManagerService) HandleBuildCallback(ctx context.Context, orgName
 		return "", err
 	}
 
-	b.logger.Info("Successfully generated workload CR template",
+	b.logger.InfoContext(ctx, "Successfully generated workload CR template",
 		"agentName", agentName,
 		"project", projectName,
 		"organization", org.OrgName)
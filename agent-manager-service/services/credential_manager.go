@@ -0,0 +1,359 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/db"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/models"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/repositories"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/utils"
+)
+
+type CredentialService interface {
+	ListCredentials(ctx context.Context, userIdpId uuid.UUID, orgName string, limit int, offset int) ([]*models.Credential, int32, error)
+	GetCredential(ctx context.Context, userIdpId uuid.UUID, orgName string, credentialId uuid.UUID) (*models.Credential, error)
+	CreateCredential(ctx context.Context, userIdpId uuid.UUID, orgName string, req *models.CredentialRequest) (*models.Credential, error)
+	// UpdateCredential replaces the name and description of a credential, and its secret when the request carries one
+	UpdateCredential(ctx context.Context, userIdpId uuid.UUID, orgName string, credentialId uuid.UUID, req *models.CredentialRequest) (*models.Credential, error)
+	// DeleteCredential fails with a utils.DependentsError while tools authenticate with the credential
+	DeleteCredential(ctx context.Context, userIdpId uuid.UUID, orgName string, credentialId uuid.UUID) error
+	// ResolveCredentialSecret returns a credential together with its decrypted secret, for agent runtimes only
+	ResolveCredentialSecret(ctx context.Context, credentialId uuid.UUID) (*models.Credential, string, error)
+	// RotateCredentialKeys re-encrypts the credentials and webhook secrets of the organization that are not under
+	// the active master key, reporting the active key id and how many of each were moved to it
+	RotateCredentialKeys(ctx context.Context, userIdpId uuid.UUID, orgName string) (*models.CredentialKeyRotationResponse, error)
+}
+
+type credentialService struct {
+	OrganizationRepository repositories.OrganizationRepository
+	CredentialRepository   repositories.CredentialRepository
+	ToolRepository         repositories.ToolRepository
+	WebhookRepository      repositories.WebhookRepository
+	// cipher is nil when no encryption keys are configured
+	cipher *utils.CredentialCipher
+	logger *slog.Logger
+}
+
+func NewCredentialService(
+	orgRepo repositories.OrganizationRepository,
+	credentialRepo repositories.CredentialRepository,
+	toolRepo repositories.ToolRepository,
+	webhookRepo repositories.WebhookRepository,
+	cipher *utils.CredentialCipher,
+	logger *slog.Logger,
+) CredentialService {
+	return &credentialService{
+		OrganizationRepository: orgRepo,
+		CredentialRepository:   credentialRepo,
+		ToolRepository:         toolRepo,
+		WebhookRepository:      webhookRepo,
+		cipher:                 cipher,
+		logger:                 logger,
+	}
+}
+
+func (s *credentialService) getOrganization(ctx context.Context, userIdpId uuid.UUID, orgName string) (*models.Organization, error) {
+	org, err := s.OrganizationRepository.GetOrganizationByOrgName(ctx, userIdpId, orgName)
+	if err != nil {
+		if db.IsRecordNotFoundError(err) {
+			s.logger.ErrorContext(ctx, "Organization not found", "orgName", orgName, "userIdpId", userIdpId)
+			return nil, utils.ErrOrganizationNotFound
+		}
+		s.logger.ErrorContext(ctx, "Failed to find organization", "orgName", orgName, "userIdpId", userIdpId, "error", err)
+		return nil, fmt.Errorf("failed to find organization %s: %w", orgName, err)
+	}
+	return org, nil
+}
+
+func (s *credentialService) getCredential(ctx context.Context, orgId uuid.UUID, credentialId uuid.UUID) (*models.Credential, error) {
+	credential, err := s.CredentialRepository.GetCredentialById(ctx, orgId, credentialId)
+	if err != nil {
+		if db.IsRecordNotFoundError(err) {
+			return nil, utils.ErrCredentialNotFound
+		}
+		s.logger.ErrorContext(ctx, "Failed to find credential", "credentialId", credentialId, "orgId", orgId, "error", err)
+		return nil, fmt.Errorf("failed to find credential %s: %w", credentialId, err)
+	}
+	return credential, nil
+}
+
+// ensureNameAvailable fails with ErrCredentialAlreadyExists when another credential of the organization has the name
+func (s *credentialService) ensureNameAvailable(ctx context.Context, orgId uuid.UUID, name string, credentialId uuid.UUID) error {
+	existing, err := s.CredentialRepository.GetCredentialByName(ctx, orgId, name)
+	if err != nil {
+		if db.IsRecordNotFoundError(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to check credential name %s: %w", name, err)
+	}
+	if existing.ID != credentialId {
+		return utils.ErrCredentialAlreadyExists
+	}
+	return nil
+}
+
+// seal encrypts the secret into the credential
+func (s *credentialService) seal(credential *models.Credential, secret string) error {
+	sealed, err := s.cipher.Seal(credential.ID, secret)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt credential %s: %w", credential.ID, err)
+	}
+	credential.KeyID = sealed.KeyID
+	credential.EncryptedKey = sealed.EncryptedKey
+	credential.EncryptedValue = sealed.EncryptedValue
+	credential.Preview = utils.MaskCredential(secret)
+	return nil
+}
+
+func sealedCredential(credential *models.Credential) utils.SealedCredential {
+	return utils.SealedCredential{
+		KeyID:          credential.KeyID,
+		EncryptedKey:   credential.EncryptedKey,
+		EncryptedValue: credential.EncryptedValue,
+	}
+}
+
+func (s *credentialService) ListCredentials(ctx context.Context, userIdpId uuid.UUID, orgName string, limit int, offset int) ([]*models.Credential, int32, error) {
+	s.logger.InfoContext(ctx, "Listing credentials", "orgName", orgName, "limit", limit, "offset", offset, "userIdpId", userIdpId)
+	org, err := s.getOrganization(ctx, userIdpId, orgName)
+	if err != nil {
+		return nil, 0, err
+	}
+	credentials, total, err := s.CredentialRepository.ListCredentials(ctx, org.ID, limit, offset)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to list credentials", "orgId", org.ID, "error", err)
+		return nil, 0, fmt.Errorf("failed to list credentials: %w", err)
+	}
+	return credentials, int32(total), nil
+}
+
+func (s *credentialService) GetCredential(ctx context.Context, userIdpId uuid.UUID, orgName string, credentialId uuid.UUID) (*models.Credential, error) {
+	s.logger.InfoContext(ctx, "Getting credential", "credentialId", credentialId, "orgName", orgName, "userIdpId", userIdpId)
+	org, err := s.getOrganization(ctx, userIdpId, orgName)
+	if err != nil {
+		return nil, err
+	}
+	return s.getCredential(ctx, org.ID, credentialId)
+}
+
+func (s *credentialService) CreateCredential(ctx context.Context, userIdpId uuid.UUID, orgName string, req *models.CredentialRequest) (*models.Credential, error) {
+	s.logger.InfoContext(ctx, "Creating credential", "credentialName", req.Name, "orgName", orgName, "userIdpId", userIdpId)
+	if s.cipher == nil {
+		return nil, utils.ErrCredentialsNotConfigured
+	}
+	org, err := s.getOrganization(ctx, userIdpId, orgName)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.ensureNameAvailable(ctx, org.ID, req.Name, uuid.Nil); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	credential := &models.Credential{
+		ID:          uuid.New(),
+		OrgID:       org.ID,
+		Name:        req.Name,
+		Description: req.Description,
+		CreatedBy:   &userIdpId,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := s.seal(credential, req.Secret); err != nil {
+		return nil, err
+	}
+	if err := s.CredentialRepository.CreateCredential(ctx, credential); err != nil {
+		if db.IsUniqueViolationError(err) {
+			return nil, utils.ErrCredentialAlreadyExists
+		}
+		s.logger.ErrorContext(ctx, "Failed to create credential", "credentialName", req.Name, "orgId", org.ID, "error", err)
+		return nil, fmt.Errorf("failed to create credential %s: %w", req.Name, err)
+	}
+	s.logger.InfoContext(ctx, "Credential created successfully", "credentialId", credential.ID, "credentialName", credential.Name, "orgName", orgName)
+	return credential, nil
+}
+
+func (s *credentialService) UpdateCredential(ctx context.Context, userIdpId uuid.UUID, orgName string, credentialId uuid.UUID, req *models.CredentialRequest) (*models.Credential, error) {
+	s.logger.InfoContext(ctx, "Updating credential", "credentialId", credentialId, "orgName", orgName, "userIdpId", userIdpId)
+	if req.Secret != "" && s.cipher == nil {
+		return nil, utils.ErrCredentialsNotConfigured
+	}
+	org, err := s.getOrganization(ctx, userIdpId, orgName)
+	if err != nil {
+		return nil, err
+	}
+
+	var before, updated *models.Credential
+	err = db.WithTx(ctx, func(txCtx context.Context) error {
+		current, err := s.getCredential(txCtx, org.ID, credentialId)
+		if err != nil {
+			return err
+		}
+		before = current
+		if req.Name != current.Name {
+			if err := s.ensureNameAvailable(txCtx, org.ID, req.Name, credentialId); err != nil {
+				return err
+			}
+		}
+
+		credential := *current
+		credential.Name = req.Name
+		credential.Description = req.Description
+		credential.UpdatedAt = time.Now()
+		if req.Secret != "" {
+			if err := s.seal(&credential, req.Secret); err != nil {
+				return err
+			}
+		}
+		ok, err := s.CredentialRepository.UpdateCredential(txCtx, &credential)
+		if err != nil {
+			if db.IsUniqueViolationError(err) {
+				return utils.ErrCredentialAlreadyExists
+			}
+			return fmt.Errorf("failed to update credential %s: %w", credentialId, err)
+		}
+		if !ok {
+			return utils.ErrCredentialNotFound
+		}
+		updated = &credential
+		return nil
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to update credential", "credentialId", credentialId, "orgId", org.ID, "error", err)
+		return nil, err
+	}
+	recordAuditChanges(ctx, s.logger, utils.ConvertToCredentialResponse(before), utils.ConvertToCredentialResponse(updated))
+	s.logger.InfoContext(ctx, "Credential updated successfully", "credentialId", credentialId, "secretReplaced", req.Secret != "", "orgName", orgName)
+	return updated, nil
+}
+
+func (s *credentialService) DeleteCredential(ctx context.Context, userIdpId uuid.UUID, orgName string, credentialId uuid.UUID) error {
+	s.logger.InfoContext(ctx, "Deleting credential", "credentialId", credentialId, "orgName", orgName, "userIdpId", userIdpId)
+	org, err := s.getOrganization(ctx, userIdpId, orgName)
+	if err != nil {
+		return err
+	}
+
+	err = db.WithTx(ctx, func(txCtx context.Context) error {
+		tools, err := s.ToolRepository.ListToolsByCredential(txCtx, org.ID, credentialId)
+		if err != nil {
+			return fmt.Errorf("failed to list tools referencing credential %s: %w", credentialId, err)
+		}
+		if len(tools) > 0 {
+			dependents := make([]utils.DependentResource, 0, len(tools))
+			for _, tool := range tools {
+				dependents = append(dependents, utils.DependentResource{Kind: "tool", ID: tool.ID.String(), Name: tool.Name})
+			}
+			return &utils.DependentsError{Err: utils.ErrCredentialInUse, Dependents: dependents}
+		}
+
+		deleted, err := s.CredentialRepository.DeleteCredential(txCtx, org.ID, credentialId)
+		if err != nil {
+			return fmt.Errorf("failed to delete credential %s: %w", credentialId, err)
+		}
+		if !deleted {
+			return utils.ErrCredentialNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to delete credential", "credentialId", credentialId, "orgId", org.ID, "error", err)
+		return err
+	}
+	s.logger.InfoContext(ctx, "Credential deleted successfully", "credentialId", credentialId, "orgName", orgName)
+	return nil
+}
+
+func (s *credentialService) ResolveCredentialSecret(ctx context.Context, credentialId uuid.UUID) (*models.Credential, string, error) {
+	s.logger.InfoContext(ctx, "Resolving credential secret", "credentialId", credentialId)
+	if s.cipher == nil {
+		return nil, "", utils.ErrCredentialsNotConfigured
+	}
+	credential, err := s.CredentialRepository.GetCredential(ctx, credentialId)
+	if err != nil {
+		if db.IsRecordNotFoundError(err) {
+			return nil, "", utils.ErrCredentialNotFound
+		}
+		return nil, "", fmt.Errorf("failed to find credential %s: %w", credentialId, err)
+	}
+	secret, err := s.cipher.Open(credential.ID, sealedCredential(credential))
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to decrypt credential", "credentialId", credentialId, "keyId", credential.KeyID, "error", err)
+		return nil, "", err
+	}
+	return credential, secret, nil
+}
+
+func (s *credentialService) RotateCredentialKeys(ctx context.Context, userIdpId uuid.UUID, orgName string) (*models.CredentialKeyRotationResponse, error) {
+	s.logger.InfoContext(ctx, "Rotating credential keys", "orgName", orgName, "userIdpId", userIdpId)
+	if s.cipher == nil {
+		return nil, utils.ErrCredentialsNotConfigured
+	}
+	org, err := s.getOrganization(ctx, userIdpId, orgName)
+	if err != nil {
+		return nil, err
+	}
+
+	rotation := &models.CredentialKeyRotationResponse{KeyID: s.cipher.ActiveKeyID()}
+	// A failure to re-encrypt any secret rolls back the whole rotation, so it can simply be retried
+	err = db.WithTx(ctx, func(txCtx context.Context) error {
+		credentials, err := s.CredentialRepository.LockCredentialsNotUnderKey(txCtx, org.ID, rotation.KeyID)
+		if err != nil {
+			return fmt.Errorf("failed to list credentials to rotate: %w", err)
+		}
+		for _, credential := range credentials {
+			sealed, err := s.cipher.Rewrap(credential.ID, sealedCredential(credential))
+			if err != nil {
+				return err
+			}
+			if err := s.CredentialRepository.UpdateCredentialKey(txCtx, credential.ID, sealed.KeyID, sealed.EncryptedKey); err != nil {
+				return fmt.Errorf("failed to rotate credential %s: %w", credential.ID, err)
+			}
+		}
+
+		webhooks, err := s.WebhookRepository.LockWebhooksNotUnderKey(txCtx, org.ID, rotation.KeyID)
+		if err != nil {
+			return fmt.Errorf("failed to list webhooks to rotate: %w", err)
+		}
+		for _, webhook := range webhooks {
+			sealed, err := s.cipher.Rewrap(webhook.ID, sealedWebhookSecret(webhook))
+			if err != nil {
+				return err
+			}
+			if err := s.WebhookRepository.UpdateWebhookKey(txCtx, webhook.ID, sealed.KeyID, sealed.EncryptedKey); err != nil {
+				return fmt.Errorf("failed to rotate webhook %s: %w", webhook.ID, err)
+			}
+		}
+		rotation.Rotated = int32(len(credentials))
+		rotation.RotatedWebhooks = int32(len(webhooks))
+		return nil
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to rotate credential keys", "orgId", org.ID, "keyId", rotation.KeyID, "error", err)
+		return nil, err
+	}
+	s.logger.InfoContext(ctx, "Credential keys rotated successfully", "orgName", orgName, "keyId", rotation.KeyID,
+		"rotated", rotation.Rotated, "rotatedWebhooks", rotation.RotatedWebhooks)
+	return rotation, nil
+}
@@ -0,0 +1,397 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/clients/traceobserversvc"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/db"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/models"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/repositories"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/utils"
+)
+
+// Traces read from the trace observer and evaluated between two checkpoints
+const evaluationPageSize = 20
+
+// Most of an http evaluator response read
+const maxEvaluatorResponseBytes = 64 << 10
+
+type EvaluationService interface {
+	// CreateEvaluation queues a run over the traces of a managed agent, a worker evaluates it in the background
+	CreateEvaluation(ctx context.Context, userIdpId uuid.UUID, orgName string, req *models.EvaluationRequest) (*models.EvaluationRun, error)
+	GetEvaluation(ctx context.Context, userIdpId uuid.UUID, orgName string, runId uuid.UUID) (*models.EvaluationRun, error)
+	ListEvaluations(ctx context.Context, userIdpId uuid.UUID, orgName string, limit int, offset int) ([]*models.EvaluationRun, int32, error)
+	ListEvaluationResults(ctx context.Context, userIdpId uuid.UUID, orgName string, runId uuid.UUID, limit int, offset int) ([]*models.EvaluationResult, int32, error)
+	// ProcessEvaluations evaluates unfinished runs one after the other until none is left or ctx is done, runs
+	// interrupted by a restart continue from their last checkpoint
+	ProcessEvaluations(ctx context.Context) error
+	// RunWorker processes evaluations every interval until ctx is done
+	RunWorker(ctx context.Context, interval time.Duration)
+}
+
+type evaluationService struct {
+	OrganizationRepository repositories.OrganizationRepository
+	ManagedAgentRepository repositories.ManagedAgentRepository
+	EvaluationRepository   repositories.EvaluationRepository
+	traceObserverClient    traceobserversvc.TraceObserverClient
+	httpClient             *http.Client
+	logger                 *slog.Logger
+	// How long an http evaluator has to score a trace
+	evaluatorTimeout time.Duration
+	// Whether http evaluators may run on localhost and loopback, private or link-local addresses
+	allowPrivateNetworks bool
+}
+
+func NewEvaluationService(
+	orgRepo repositories.OrganizationRepository,
+	managedAgentRepo repositories.ManagedAgentRepository,
+	evaluationRepo repositories.EvaluationRepository,
+	traceObserverClient traceobserversvc.TraceObserverClient,
+	httpClient *http.Client,
+	logger *slog.Logger,
+	evaluatorTimeout time.Duration,
+	allowPrivateNetworks bool,
+) EvaluationService {
+	return &evaluationService{
+		OrganizationRepository: orgRepo,
+		ManagedAgentRepository: managedAgentRepo,
+		EvaluationRepository:   evaluationRepo,
+		traceObserverClient:    traceObserverClient,
+		httpClient:             httpClient,
+		logger:                 logger,
+		evaluatorTimeout:       evaluatorTimeout,
+		allowPrivateNetworks:   allowPrivateNetworks,
+	}
+}
+
+func (s *evaluationService) getOrganization(ctx context.Context, userIdpId uuid.UUID, orgName string) (*models.Organization, error) {
+	org, err := s.OrganizationRepository.GetOrganizationByOrgName(ctx, userIdpId, orgName)
+	if err != nil {
+		if db.IsRecordNotFoundError(err) {
+			s.logger.ErrorContext(ctx, "Organization not found", "orgName", orgName, "userIdpId", userIdpId)
+			return nil, utils.ErrOrganizationNotFound
+		}
+		s.logger.ErrorContext(ctx, "Failed to find organization", "orgName", orgName, "userIdpId", userIdpId, "error", err)
+		return nil, fmt.Errorf("failed to find organization %s: %w", orgName, err)
+	}
+	return org, nil
+}
+
+func (s *evaluationService) getEvaluation(ctx context.Context, orgId uuid.UUID, runId uuid.UUID) (*models.EvaluationRun, error) {
+	run, err := s.EvaluationRepository.GetEvaluationRun(ctx, orgId, runId)
+	if err != nil {
+		if db.IsRecordNotFoundError(err) {
+			return nil, utils.ErrEvaluationNotFound
+		}
+		s.logger.ErrorContext(ctx, "Failed to find evaluation", "evaluationId", runId, "orgId", orgId, "error", err)
+		return nil, fmt.Errorf("failed to find evaluation %s: %w", runId, err)
+	}
+	return run, nil
+}
+
+func (s *evaluationService) CreateEvaluation(ctx context.Context, userIdpId uuid.UUID, orgName string, req *models.EvaluationRequest) (*models.EvaluationRun, error) {
+	s.logger.InfoContext(ctx, "Creating evaluation", "orgName", orgName, "agentId", req.Filter.AgentID, "evaluator", req.Evaluator.Type, "userIdpId", userIdpId)
+	if req.Evaluator.Type == utils.EvaluatorHTTP && !s.allowPrivateNetworks {
+		if err := utils.ValidateOutboundURL(utils.Field("evaluator").Key("url"), req.Evaluator.URL); err != nil {
+			return nil, err
+		}
+	}
+	org, err := s.getOrganization(ctx, userIdpId, orgName)
+	if err != nil {
+		return nil, err
+	}
+	agentId, err := uuid.Parse(req.Filter.AgentID)
+	if err != nil {
+		return nil, utils.ErrAgentNotFound
+	}
+	agent, err := s.ManagedAgentRepository.GetManagedAgentById(ctx, org.ID, agentId)
+	if err != nil {
+		if db.IsRecordNotFoundError(err) {
+			return nil, utils.ErrAgentNotFound
+		}
+		s.logger.ErrorContext(ctx, "Failed to find managed agent", "agentId", agentId, "orgId", org.ID, "error", err)
+		return nil, fmt.Errorf("failed to find managed agent %s: %w", agentId, err)
+	}
+
+	now := time.Now()
+	filter := models.EvaluationTraceFilter{
+		AgentID:   agent.ID.String(),
+		StartTime: req.Filter.StartTime,
+		EndTime:   req.Filter.EndTime,
+		MaxTraces: req.Filter.MaxTraces,
+	}
+	if filter.EndTime == nil {
+		filter.EndTime = &now
+	}
+	if filter.MaxTraces == 0 {
+		filter.MaxTraces = utils.DefaultEvaluationMaxTraces
+	}
+	run := &models.EvaluationRun{
+		ID:        uuid.New(),
+		OrgID:     org.ID,
+		Name:      req.Name,
+		AgentID:   agent.ID,
+		Filter:    filter,
+		Evaluator: utils.NormalizeEvaluator(req.Evaluator),
+		Status:    utils.EvaluationStatusPending,
+		CreatedBy: userIdpId,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := s.EvaluationRepository.CreateEvaluationRun(ctx, run); err != nil {
+		s.logger.ErrorContext(ctx, "Failed to create evaluation", "agentId", agentId, "error", err)
+		return nil, fmt.Errorf("failed to create evaluation of agent %s: %w", agentId, err)
+	}
+	s.logger.InfoContext(ctx, "Evaluation created successfully", "evaluationId", run.ID, "agentId", agentId, "orgName", orgName)
+	return run, nil
+}
+
+func (s *evaluationService) GetEvaluation(ctx context.Context, userIdpId uuid.UUID, orgName string, runId uuid.UUID) (*models.EvaluationRun, error) {
+	s.logger.InfoContext(ctx, "Getting evaluation", "evaluationId", runId, "orgName", orgName, "userIdpId", userIdpId)
+	org, err := s.getOrganization(ctx, userIdpId, orgName)
+	if err != nil {
+		return nil, err
+	}
+	return s.getEvaluation(ctx, org.ID, runId)
+}
+
+func (s *evaluationService) ListEvaluations(ctx context.Context, userIdpId uuid.UUID, orgName string, limit int, offset int) ([]*models.EvaluationRun, int32, error) {
+	s.logger.InfoContext(ctx, "Listing evaluations", "orgName", orgName, "limit", limit, "offset", offset, "userIdpId", userIdpId)
+	org, err := s.getOrganization(ctx, userIdpId, orgName)
+	if err != nil {
+		return nil, 0, err
+	}
+	runs, total, err := s.EvaluationRepository.ListEvaluationRuns(ctx, org.ID, limit, offset)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to list evaluations", "orgId", org.ID, "error", err)
+		return nil, 0, fmt.Errorf("failed to list evaluations: %w", err)
+	}
+	return runs, int32(total), nil
+}
+
+func (s *evaluationService) ListEvaluationResults(ctx context.Context, userIdpId uuid.UUID, orgName string, runId uuid.UUID, limit int, offset int) ([]*models.EvaluationResult, int32, error) {
+	s.logger.InfoContext(ctx, "Listing evaluation results", "evaluationId", runId, "orgName", orgName, "limit", limit, "offset", offset, "userIdpId", userIdpId)
+	org, err := s.getOrganization(ctx, userIdpId, orgName)
+	if err != nil {
+		return nil, 0, err
+	}
+	run, err := s.getEvaluation(ctx, org.ID, runId)
+	if err != nil {
+		return nil, 0, err
+	}
+	results, total, err := s.EvaluationRepository.ListEvaluationResults(ctx, run.ID, limit, offset)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to list evaluation results", "evaluationId", runId, "error", err)
+		return nil, 0, fmt.Errorf("failed to list results of evaluation %s: %w", runId, err)
+	}
+	return results, int32(total), nil
+}
+
+// leaseDuration outlasts the evaluation of a page, a worker that dies mid page leaves its run claimable afterwards
+func (s *evaluationService) leaseDuration() time.Duration {
+	return evaluationPageSize*s.evaluatorTimeout + time.Minute
+}
+
+func (s *evaluationService) ProcessEvaluations(ctx context.Context) error {
+	for ctx.Err() == nil {
+		leaseId := uuid.New()
+		now := time.Now()
+		run, err := s.EvaluationRepository.ClaimEvaluationRun(ctx, leaseId, now, now.Add(s.leaseDuration()))
+		if err != nil {
+			s.logger.ErrorContext(ctx, "Failed to claim evaluation", "error", err)
+			return fmt.Errorf("failed to claim evaluation: %w", err)
+		}
+		if run == nil {
+			return nil
+		}
+		if err := s.process(ctx, run, leaseId); err != nil {
+			// The run is claimed again on the next poll and continues from its last checkpoint
+			if ctx.Err() == nil {
+				s.logger.ErrorContext(ctx, "Failed to process evaluation", "evaluationId", run.ID, "error", err)
+			}
+			if err := s.EvaluationRepository.ReleaseEvaluationRun(context.WithoutCancel(ctx), run.ID, leaseId); err != nil {
+				s.logger.ErrorContext(ctx, "Failed to release evaluation", "evaluationId", run.ID, "error", err)
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// process evaluates the remaining traces of a run page by page, checkpointing after each page
+func (s *evaluationService) process(ctx context.Context, run *models.EvaluationRun, leaseId uuid.UUID) error {
+	s.logger.InfoContext(ctx, "Processing evaluation", "evaluationId", run.ID, "evaluatedTraces", run.EvaluatedTraces)
+	for {
+		remaining := int(run.Filter.MaxTraces - run.EvaluatedTraces)
+		if remaining <= 0 {
+			return s.finish(ctx, run, leaseId, utils.EvaluationStatusCompleted, "")
+		}
+		page, err := s.traceObserverClient.ListTraces(ctx, traceobserversvc.ListTracesParams{
+			OrgID:     run.OrgID.String(),
+			AgentID:   run.AgentID.String(),
+			StartTime: run.Filter.StartTime.UTC().Format(time.RFC3339Nano),
+			EndTime:   run.Filter.EndTime.UTC().Format(time.RFC3339Nano),
+			Limit:     min(evaluationPageSize, remaining),
+			SortOrder: "asc",
+			Cursor:    run.Cursor,
+		})
+		if err != nil {
+			if traceobserversvc.IsBadRequest(err) {
+				return s.finish(ctx, run, leaseId, utils.EvaluationStatusFailed, fmt.Sprintf("the trace observer rejected the trace filter: %v", err))
+			}
+			return fmt.Errorf("failed to list traces of evaluation %s: %w", run.ID, err)
+		}
+
+		results := make([]*models.EvaluationResult, 0, len(page.Traces))
+		for _, trace := range page.Traces {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			outcome := s.evaluate(ctx, run, trace)
+			results = append(results, &models.EvaluationResult{
+				RunID:       run.ID,
+				TraceID:     trace.TraceID,
+				Score:       outcome.Score,
+				Passed:      outcome.Passed,
+				Reason:      outcome.Reason,
+				Error:       outcome.Error,
+				EvaluatedAt: time.Now(),
+			})
+		}
+		// Traces cut short by a shutdown are evaluated again once the run is resumed
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		run.Cursor = page.NextCursor
+		run.TotalTraces = int32(min(page.TotalCount, int(run.Filter.MaxTraces)))
+		owned, err := s.EvaluationRepository.SaveEvaluationCheckpoint(ctx, run, leaseId, results, time.Now().Add(s.leaseDuration()))
+		if err != nil {
+			return fmt.Errorf("failed to checkpoint evaluation %s: %w", run.ID, err)
+		}
+		if !owned {
+			s.logger.WarnContext(ctx, "Evaluation lease lost, leaving the run to its new worker", "evaluationId", run.ID)
+			return nil
+		}
+		run.EvaluatedTraces += int32(len(results))
+		if page.NextCursor == "" || len(page.Traces) == 0 {
+			return s.finish(ctx, run, leaseId, utils.EvaluationStatusCompleted, "")
+		}
+	}
+}
+
+func (s *evaluationService) finish(ctx context.Context, run *models.EvaluationRun, leaseId uuid.UUID, status string, errMessage string) error {
+	if err := s.EvaluationRepository.FinishEvaluationRun(ctx, run.ID, leaseId, status, errMessage); err != nil {
+		return fmt.Errorf("failed to finish evaluation %s: %w", run.ID, err)
+	}
+	s.logger.InfoContext(ctx, "Evaluation finished", "evaluationId", run.ID, "status", status, "evaluatedTraces", run.EvaluatedTraces, "error", errMessage)
+	return nil
+}
+
+// evaluate scores a single trace, failures are recorded on its result rather than failing the run
+func (s *evaluationService) evaluate(ctx context.Context, run *models.EvaluationRun, trace traceobserversvc.TraceOverview) models.EvaluationOutcome {
+	if run.Evaluator.Type != utils.EvaluatorHTTP {
+		toolErrors := 0
+		if trace.Status != nil {
+			toolErrors = trace.Status.ToolErrorCount
+		}
+		return utils.EvaluateBuiltin(run.Evaluator, trace.Input, trace.Output, toolErrors)
+	}
+	outcome, err := s.callHTTPEvaluator(ctx, run, trace)
+	if err != nil {
+		s.logger.WarnContext(ctx, "HTTP evaluator failed", "evaluationId", run.ID, "traceId", trace.TraceID, "error", err)
+		return models.EvaluationOutcome{Error: err.Error()}
+	}
+	return outcome
+}
+
+// callHTTPEvaluator POSTs the input and output of a trace to the evaluator and reads its score
+func (s *evaluationService) callHTTPEvaluator(ctx context.Context, run *models.EvaluationRun, trace traceobserversvc.TraceOverview) (models.EvaluationOutcome, error) {
+	body, err := json.Marshal(models.HTTPEvaluatorRequest{
+		EvaluationID: run.ID.String(),
+		TraceID:      trace.TraceID,
+		Input:        trace.Input,
+		Output:       trace.Output,
+	})
+	if err != nil {
+		return models.EvaluationOutcome{}, fmt.Errorf("failed to encode trace: %w", err)
+	}
+	reqCtx, cancel := context.WithTimeout(ctx, s.evaluatorTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(reqCtx, http.MethodPost, run.Evaluator.URL, bytes.NewReader(body))
+	if err != nil {
+		return models.EvaluationOutcome{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return models.EvaluationOutcome{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxEvaluatorResponseBytes))
+		return models.EvaluationOutcome{}, fmt.Errorf("evaluator answered with status %d", resp.StatusCode)
+	}
+	var verdict models.HTTPEvaluatorResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxEvaluatorResponseBytes)).Decode(&verdict); err != nil {
+		return models.EvaluationOutcome{}, fmt.Errorf("invalid evaluator response: %w", err)
+	}
+	if verdict.Score == nil {
+		return models.EvaluationOutcome{}, errors.New("invalid evaluator response: score is missing")
+	}
+	if *verdict.Score < 0 || *verdict.Score > 1 {
+		return models.EvaluationOutcome{}, fmt.Errorf("invalid evaluator response: score %v is not from 0 to 1", *verdict.Score)
+	}
+
+	outcome := models.EvaluationOutcome{Score: verdict.Score, Reason: verdict.Reason}
+	if len(outcome.Reason) > utils.MaxEvaluationReasonLength {
+		outcome.Reason = strings.ToValidUTF8(outcome.Reason[:utils.MaxEvaluationReasonLength], "")
+	}
+	if verdict.Passed != nil {
+		outcome.Passed = *verdict.Passed
+	} else {
+		outcome.Passed = *verdict.Score >= *run.Evaluator.PassThreshold
+	}
+	return outcome, nil
+}
+
+func (s *evaluationService) RunWorker(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			_ = s.ProcessEvaluations(ctx)
+		}
+	}
+}
@@ -0,0 +1,163 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/db"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/models"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/repositories"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/utils"
+)
+
+// claimAttempts bounds how often Begin retries a key freed or taken over by a concurrent request
+const claimAttempts = 3
+
+type IdempotencyService interface {
+	// Begin claims a key for a request. A claimed key is returned without a status code and the request runs,
+	// a key completed before is returned with the response to replay. A key in use by a request still running
+	// fails with ErrIdempotencyKeyInProgress and a key used with a different request with ErrIdempotencyKeyReused
+	Begin(ctx context.Context, principal string, key string, route string, requestHash string) (*models.IdempotencyKey, error)
+	// Complete records the response of a request that claimed a key, retries get it back until the key expires
+	Complete(ctx context.Context, key *models.IdempotencyKey, statusCode int, headers map[string]string, body []byte) error
+	// Release frees a key claimed by a request that failed, a retry runs the request again
+	Release(ctx context.Context, key *models.IdempotencyKey) error
+	// PurgeExpired removes the keys past their expiry
+	PurgeExpired(ctx context.Context) error
+	// RunPurger purges expired keys every interval until ctx is done
+	RunPurger(ctx context.Context, interval time.Duration)
+}
+
+type idempotencyService struct {
+	IdempotencyRepository repositories.IdempotencyRepository
+	logger                *slog.Logger
+	// How long the response of a completed request is replayed
+	ttl time.Duration
+	// How long a claim is honoured before the request holding it is presumed lost
+	lockTimeout time.Duration
+}
+
+func NewIdempotencyService(
+	idempotencyRepo repositories.IdempotencyRepository,
+	logger *slog.Logger,
+	ttl time.Duration,
+	lockTimeout time.Duration,
+) IdempotencyService {
+	return &idempotencyService{
+		IdempotencyRepository: idempotencyRepo,
+		logger:                logger,
+		ttl:                   ttl,
+		lockTimeout:           lockTimeout,
+	}
+}
+
+func (s *idempotencyService) Begin(ctx context.Context, principal string, key string, route string, requestHash string) (*models.IdempotencyKey, error) {
+	for attempt := 0; attempt < claimAttempts; attempt++ {
+		// the database keeps microseconds, the claim is matched on created_at when completed or released
+		now := time.Now().UTC().Truncate(time.Microsecond)
+		claim := &models.IdempotencyKey{
+			Principal:   principal,
+			Key:         key,
+			Route:       route,
+			RequestHash: requestHash,
+			CreatedAt:   now,
+			ExpiresAt:   now.Add(s.ttl),
+		}
+		claimed, err := s.IdempotencyRepository.CreateIdempotencyKey(ctx, claim)
+		if err != nil {
+			return nil, fmt.Errorf("failed to claim idempotency key: %w", err)
+		}
+		if claimed {
+			return claim, nil
+		}
+
+		existing, err := s.IdempotencyRepository.GetIdempotencyKey(ctx, principal, key, route)
+		if err != nil {
+			if db.IsRecordNotFoundError(err) {
+				// released by the request holding it, claim it again
+				continue
+			}
+			return nil, fmt.Errorf("failed to find idempotency key: %w", err)
+		}
+		expired := !existing.ExpiresAt.After(now)
+		abandoned := existing.StatusCode == nil && !existing.CreatedAt.Add(s.lockTimeout).After(now)
+		if expired || abandoned {
+			s.logger.InfoContext(ctx, "Replacing stale idempotency key", "route", route, "expired", expired, "abandoned", abandoned)
+			if err := s.IdempotencyRepository.DeleteIdempotencyKey(ctx, principal, key, route, existing.CreatedAt); err != nil {
+				return nil, fmt.Errorf("failed to replace idempotency key: %w", err)
+			}
+			continue
+		}
+		if existing.RequestHash != requestHash {
+			return nil, utils.ErrIdempotencyKeyReused
+		}
+		if existing.StatusCode == nil {
+			return nil, utils.ErrIdempotencyKeyInProgress
+		}
+		return existing, nil
+	}
+	// the key keeps changing hands, let the client retry
+	return nil, utils.ErrIdempotencyKeyInProgress
+}
+
+func (s *idempotencyService) Complete(ctx context.Context, key *models.IdempotencyKey, statusCode int, headers map[string]string, body []byte) error {
+	key.StatusCode = &statusCode
+	key.ResponseHeaders = headers
+	key.ResponseBody = body
+	if err := s.IdempotencyRepository.CompleteIdempotencyKey(ctx, key); err != nil {
+		s.logger.ErrorContext(ctx, "Failed to record idempotent response", "route", key.Route, "error", err)
+		return fmt.Errorf("failed to record response of idempotency key: %w", err)
+	}
+	return nil
+}
+
+func (s *idempotencyService) Release(ctx context.Context, key *models.IdempotencyKey) error {
+	if err := s.IdempotencyRepository.DeleteIdempotencyKey(ctx, key.Principal, key.Key, key.Route, key.CreatedAt); err != nil {
+		s.logger.ErrorContext(ctx, "Failed to release idempotency key", "route", key.Route, "error", err)
+		return fmt.Errorf("failed to release idempotency key: %w", err)
+	}
+	return nil
+}
+
+func (s *idempotencyService) PurgeExpired(ctx context.Context) error {
+	purged, err := s.IdempotencyRepository.DeleteExpiredIdempotencyKeys(ctx, time.Now())
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to purge expired idempotency keys", "error", err)
+		return fmt.Errorf("failed to purge expired idempotency keys: %w", err)
+	}
+	if purged > 0 {
+		s.logger.InfoContext(ctx, "Purged expired idempotency keys", "count", purged)
+	}
+	return nil
+}
+
+func (s *idempotencyService) RunPurger(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			_ = s.PurgeExpired(ctx)
+		}
+	}
+}
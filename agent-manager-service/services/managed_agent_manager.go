@@ -0,0 +1,698 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/clients/traceobserversvc"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/db"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/models"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/repositories"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/utils"
+)
+
+type ManagedAgentService interface {
+	ListManagedAgents(ctx context.Context, userIdpId uuid.UUID, orgName string, filter models.ManagedAgentFilter) ([]*models.ManagedAgent, *models.ListCursor, int32, error)
+	// GetManagedAgent also returns a deleted agent that is not yet purged when includeDeleted is set
+	GetManagedAgent(ctx context.Context, userIdpId uuid.UUID, orgName string, agentId uuid.UUID, includeDeleted bool) (*models.ManagedAgent, error)
+	// GetManagedAgentLabels finds an agent of any organization for internal services, which hold no user identity
+	GetManagedAgentLabels(ctx context.Context, agentId uuid.UUID) (*models.ManagedAgent, error)
+	// FindManagedAgentsByName returns the agents of an organization an observed agent name may refer to, for internal
+	// services. Names are matched ignoring case, so more than one agent means the name is ambiguous
+	FindManagedAgentsByName(ctx context.Context, orgId uuid.UUID, name string) ([]*models.ManagedAgent, error)
+	CreateManagedAgent(ctx context.Context, userIdpId uuid.UUID, orgName string, req *models.ManagedAgentRequest) (*models.ManagedAgent, error)
+	// UpdateManagedAgent replaces the agent configuration, when expectedVersion is set the agent must still be at that version
+	UpdateManagedAgent(ctx context.Context, userIdpId uuid.UUID, orgName string, agentId uuid.UUID, req *models.ManagedAgentRequest, expectedVersion *int32) (*models.ManagedAgent, error)
+	// PatchManagedAgent applies a JSON merge patch to the agent configuration, when expectedVersion is set the agent
+	// must still be at that version
+	PatchManagedAgent(ctx context.Context, userIdpId uuid.UUID, orgName string, agentId uuid.UUID, patch map[string]interface{}, expectedVersion *int32) (*models.ManagedAgent, error)
+	DeleteManagedAgent(ctx context.Context, userIdpId uuid.UUID, orgName string, agentId uuid.UUID) error
+	// RestoreManagedAgent undoes the deletion of an agent that is not yet purged. Its name must still be free
+	// and the prompt and tools it references must still exist
+	RestoreManagedAgent(ctx context.Context, userIdpId uuid.UUID, orgName string, agentId uuid.UUID) (*models.ManagedAgent, error)
+	ListManagedAgentVersions(ctx context.Context, userIdpId uuid.UUID, orgName string, agentId uuid.UUID, limit int, offset int) (*models.ManagedAgent, []*models.ManagedAgentVersion, int32, error)
+	GetManagedAgentVersion(ctx context.Context, userIdpId uuid.UUID, orgName string, agentId uuid.UUID, version int32) (*models.ManagedAgentVersion, error)
+	// RollbackManagedAgent creates a new version with the configuration of an earlier version
+	RollbackManagedAgent(ctx context.Context, userIdpId uuid.UUID, orgName string, agentId uuid.UUID, toVersion int32, expectedVersion *int32) (*models.ManagedAgent, error)
+	// DiffManagedAgentVersions lists the changes from one version of an agent to another
+	DiffManagedAgentVersions(ctx context.Context, userIdpId uuid.UUID, orgName string, agentId uuid.UUID, fromVersion int32, toVersion int32) ([]models.ConfigChange, error)
+	// ListManagedAgentTools resolves the tool references of the agent into tool definitions
+	ListManagedAgentTools(ctx context.Context, userIdpId uuid.UUID, orgName string, agentId uuid.UUID) ([]models.AgentToolDefinition, error)
+	// ExportManagedAgent bundles the agent with the prompt version and the registered tools it references
+	ExportManagedAgent(ctx context.Context, userIdpId uuid.UUID, orgName string, agentId uuid.UUID) (*models.AgentBundle, error)
+	// ImportManagedAgent creates or updates the agent of a bundle, matching its prompt and tools by name. A dry run
+	// returns the plan without applying it, otherwise conflicts fail the import with a utils.ImportConflictError
+	ImportManagedAgent(ctx context.Context, userIdpId uuid.UUID, orgName string, bundle *models.AgentBundle, dryRun bool) (*models.AgentImportPlan, *models.ManagedAgent, error)
+	// ApplyManagedAgentOperation applies an operation of a batch in a transaction of its own and returns the agent
+	// after it, nil once deleted, with the changes made. A dry run rolls the transaction back
+	ApplyManagedAgentOperation(ctx context.Context, userIdpId uuid.UUID, orgName string, agentId uuid.UUID, op models.AgentBatchOperation, dryRun bool) (*models.ManagedAgent, []models.ConfigChange, error)
+}
+
+type managedAgentService struct {
+	OrganizationRepository        repositories.OrganizationRepository
+	ManagedAgentRepository        repositories.ManagedAgentRepository
+	ManagedAgentVersionRepository repositories.ManagedAgentVersionRepository
+	PromptTemplateRepository      repositories.PromptTemplateRepository
+	ToolRepository                repositories.ToolRepository
+	CredentialRepository          repositories.CredentialRepository
+	WebhookService                WebhookService
+	logger                        *slog.Logger
+}
+
+func NewManagedAgentService(
+	orgRepo repositories.OrganizationRepository,
+	managedAgentRepo repositories.ManagedAgentRepository,
+	managedAgentVersionRepo repositories.ManagedAgentVersionRepository,
+	promptTemplateRepo repositories.PromptTemplateRepository,
+	toolRepo repositories.ToolRepository,
+	credentialRepo repositories.CredentialRepository,
+	webhookService WebhookService,
+	logger *slog.Logger,
+) ManagedAgentService {
+	return &managedAgentService{
+		OrganizationRepository:        orgRepo,
+		ManagedAgentRepository:        managedAgentRepo,
+		ManagedAgentVersionRepository: managedAgentVersionRepo,
+		PromptTemplateRepository:      promptTemplateRepo,
+		ToolRepository:                toolRepo,
+		CredentialRepository:          credentialRepo,
+		WebhookService:                webhookService,
+		logger:                        logger,
+	}
+}
+
+func (s *managedAgentService) getOrganization(ctx context.Context, userIdpId uuid.UUID, orgName string) (*models.Organization, error) {
+	org, err := s.OrganizationRepository.GetOrganizationByOrgName(ctx, userIdpId, orgName)
+	if err != nil {
+		if db.IsRecordNotFoundError(err) {
+			s.logger.ErrorContext(ctx, "Organization not found", "orgName", orgName, "userIdpId", userIdpId)
+			return nil, utils.ErrOrganizationNotFound
+		}
+		s.logger.ErrorContext(ctx, "Failed to find organization", "orgName", orgName, "userIdpId", userIdpId, "error", err)
+		return nil, fmt.Errorf("failed to find organization %s: %w", orgName, err)
+	}
+	return org, nil
+}
+
+func (s *managedAgentService) ListManagedAgents(ctx context.Context, userIdpId uuid.UUID, orgName string, filter models.ManagedAgentFilter) ([]*models.ManagedAgent, *models.ListCursor, int32, error) {
+	s.logger.InfoContext(ctx, "Listing managed agents", "orgName", orgName, "search", filter.Search, "labelSelector", filter.Selector, "includeDeleted", filter.IncludeDeleted, "sort", filter.Page.Sort, "sortOrder", filter.Page.Order, "limit", filter.Page.Limit, "offset", filter.Page.Offset, "cursor", filter.Page.Cursor != nil, "userIdpId", userIdpId)
+	org, err := s.getOrganization(ctx, userIdpId, orgName)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	agents, next, total, err := s.ManagedAgentRepository.ListManagedAgents(ctx, org.ID, filter)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to list managed agents", "orgId", org.ID, "error", err)
+		return nil, nil, 0, fmt.Errorf("failed to list managed agents: %w", err)
+	}
+	return agents, next, int32(total), nil
+}
+
+func (s *managedAgentService) GetManagedAgent(ctx context.Context, userIdpId uuid.UUID, orgName string, agentId uuid.UUID, includeDeleted bool) (*models.ManagedAgent, error) {
+	s.logger.InfoContext(ctx, "Getting managed agent", "agentId", agentId, "includeDeleted", includeDeleted, "orgName", orgName, "userIdpId", userIdpId)
+	org, err := s.getOrganization(ctx, userIdpId, orgName)
+	if err != nil {
+		return nil, err
+	}
+	if !includeDeleted {
+		return s.getManagedAgent(ctx, org.ID, agentId)
+	}
+	agent, err := s.ManagedAgentRepository.GetManagedAgentByIdWithDeleted(ctx, org.ID, agentId)
+	if err != nil {
+		if db.IsRecordNotFoundError(err) {
+			return nil, utils.ErrAgentNotFound
+		}
+		s.logger.ErrorContext(ctx, "Failed to find managed agent", "agentId", agentId, "orgId", org.ID, "error", err)
+		return nil, fmt.Errorf("failed to find managed agent %s: %w", agentId, err)
+	}
+	return agent, nil
+}
+
+func (s *managedAgentService) GetManagedAgentLabels(ctx context.Context, agentId uuid.UUID) (*models.ManagedAgent, error) {
+	agent, err := s.ManagedAgentRepository.GetManagedAgentByIdInAnyOrg(ctx, agentId)
+	if err != nil {
+		if db.IsRecordNotFoundError(err) {
+			return nil, utils.ErrAgentNotFound
+		}
+		s.logger.ErrorContext(ctx, "Failed to find managed agent", "agentId", agentId, "error", err)
+		return nil, fmt.Errorf("failed to find managed agent %s: %w", agentId, err)
+	}
+	return agent, nil
+}
+
+func (s *managedAgentService) FindManagedAgentsByName(ctx context.Context, orgId uuid.UUID, name string) ([]*models.ManagedAgent, error) {
+	agents, err := s.ManagedAgentRepository.ListManagedAgentsByNameFold(ctx, orgId, name)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to find managed agents by name", "agentName", name, "orgId", orgId, "error", err)
+		return nil, fmt.Errorf("failed to find managed agents named %s: %w", name, err)
+	}
+	return agents, nil
+}
+
+func (s *managedAgentService) getManagedAgent(ctx context.Context, orgId uuid.UUID, agentId uuid.UUID) (*models.ManagedAgent, error) {
+	agent, err := s.ManagedAgentRepository.GetManagedAgentById(ctx, orgId, agentId)
+	if err != nil {
+		if db.IsRecordNotFoundError(err) {
+			return nil, utils.ErrAgentNotFound
+		}
+		s.logger.ErrorContext(ctx, "Failed to find managed agent", "agentId", agentId, "orgId", orgId, "error", err)
+		return nil, fmt.Errorf("failed to find managed agent %s: %w", agentId, err)
+	}
+	return agent, nil
+}
+
+// ensureNameAvailable fails with ErrAgentAlreadyExists when another agent of the organization has the name
+func (s *managedAgentService) ensureNameAvailable(ctx context.Context, orgId uuid.UUID, name string, agentId uuid.UUID) error {
+	existing, err := s.ManagedAgentRepository.GetManagedAgentByName(ctx, orgId, name)
+	if err != nil {
+		if db.IsRecordNotFoundError(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to check managed agent name %s: %w", name, err)
+	}
+	if existing.ID != agentId {
+		return utils.ErrAgentAlreadyExists
+	}
+	return nil
+}
+
+// resolvePromptReference checks that the prompt version referenced by an agent exists in the organization
+func (s *managedAgentService) resolvePromptReference(ctx context.Context, orgId uuid.UUID, ref *models.PromptReference) (*uuid.UUID, *int32, error) {
+	promptId, promptVersion := utils.ParsePromptReference(ref)
+	if promptId == nil {
+		return nil, nil, nil
+	}
+	errs := &utils.ValidationError{}
+	if _, err := s.PromptTemplateRepository.GetPromptTemplateById(ctx, orgId, *promptId); err != nil {
+		if db.IsRecordNotFoundError(err) {
+			errs.Add("promptRef.promptId", "prompt %s does not exist in the organization", promptId)
+			return nil, nil, errs
+		}
+		return nil, nil, fmt.Errorf("failed to find prompt %s: %w", promptId, err)
+	}
+	if _, err := s.PromptTemplateRepository.GetPromptTemplateVersion(ctx, *promptId, *promptVersion); err != nil {
+		if db.IsRecordNotFoundError(err) {
+			errs.Add("promptRef.version", "prompt %s has no version %d", promptId, *promptVersion)
+			return nil, nil, errs
+		}
+		return nil, nil, fmt.Errorf("failed to find version %d of prompt %s: %w", *promptVersion, promptId, err)
+	}
+	return promptId, promptVersion, nil
+}
+
+// checkToolReferences checks that the registered tools referenced by an agent exist in the organization
+func (s *managedAgentService) checkToolReferences(ctx context.Context, orgId uuid.UUID, tools []models.ToolReference) error {
+	referenced := utils.ParseToolReferenceIds(tools)
+	if len(referenced) == 0 {
+		return nil
+	}
+	toolIds := make([]uuid.UUID, 0, len(referenced))
+	for _, toolId := range referenced {
+		toolIds = append(toolIds, toolId)
+	}
+	found, err := s.ToolRepository.GetToolsByIds(ctx, orgId, toolIds)
+	if err != nil {
+		return fmt.Errorf("failed to find referenced tools: %w", err)
+	}
+	existing := make(map[uuid.UUID]bool, len(found))
+	for _, tool := range found {
+		existing[tool.ID] = true
+	}
+	errs := &utils.ValidationError{}
+	for i := range tools {
+		if toolId, ok := referenced[i]; ok && !existing[toolId] {
+			errs.Add(fmt.Sprintf("tools[%d].toolId", i), "tool %s does not exist in the organization", toolId)
+		}
+	}
+	return errs.OrNil()
+}
+
+func (s *managedAgentService) CreateManagedAgent(ctx context.Context, userIdpId uuid.UUID, orgName string, req *models.ManagedAgentRequest) (*models.ManagedAgent, error) {
+	s.logger.InfoContext(ctx, "Creating managed agent", "agentName", req.Name, "orgName", orgName, "userIdpId", userIdpId)
+	org, err := s.getOrganization(ctx, userIdpId, orgName)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.ensureNameAvailable(ctx, org.ID, req.Name, uuid.Nil); err != nil {
+		return nil, err
+	}
+	promptId, promptVersion, err := s.resolvePromptReference(ctx, org.ID, req.PromptRef)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkToolReferences(ctx, org.ID, req.Tools); err != nil {
+		return nil, err
+	}
+	schemaVersion, err := utils.ValidateAgentModelConfig(*req, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	agent := &models.ManagedAgent{
+		ID:                  uuid.New(),
+		OrgID:               org.ID,
+		Name:                req.Name,
+		Description:         req.Description,
+		Framework:           req.Framework,
+		ModelConfig:         req.ModelConfig,
+		SystemPrompt:        req.SystemPrompt,
+		PromptID:            promptId,
+		PromptVersion:       promptVersion,
+		Tools:               req.Tools,
+		Labels:              req.Labels,
+		Enabled:             req.Enabled == nil || *req.Enabled,
+		Version:             1,
+		CreatedAt:           now,
+		UpdatedAt:           now,
+		ConfigSchemaVersion: schemaVersion,
+		RetentionDays:       agentRetentionDays(nil, req.RetentionDays),
+	}
+	err = db.WithTx(ctx, func(txCtx context.Context) error {
+		if err := s.ManagedAgentRepository.CreateManagedAgent(txCtx, agent); err != nil {
+			if db.IsUniqueViolationError(err) {
+				return utils.ErrAgentAlreadyExists
+			}
+			return fmt.Errorf("failed to create managed agent %s: %w", req.Name, err)
+		}
+		if err := s.ManagedAgentVersionRepository.CreateManagedAgentVersion(txCtx, newManagedAgentVersion(agent, userIdpId, nil)); err != nil {
+			return fmt.Errorf("failed to record version of managed agent %s: %w", req.Name, err)
+		}
+		return nil
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to create managed agent", "agentName", req.Name, "orgId", org.ID, "error", err)
+		return nil, err
+	}
+	s.WebhookService.PublishEvent(ctx, org, utils.WebhookEventAgentCreated, utils.ConvertToManagedAgentResponse(agent))
+	s.logger.InfoContext(ctx, "Managed agent created successfully", "agentId", agent.ID, "agentName", agent.Name, "orgName", orgName)
+	return agent, nil
+}
+
+func (s *managedAgentService) UpdateManagedAgent(ctx context.Context, userIdpId uuid.UUID, orgName string, agentId uuid.UUID, req *models.ManagedAgentRequest, expectedVersion *int32) (*models.ManagedAgent, error) {
+	s.logger.InfoContext(ctx, "Updating managed agent", "agentId", agentId, "orgName", orgName, "userIdpId", userIdpId)
+	org, err := s.getOrganization(ctx, userIdpId, orgName)
+	if err != nil {
+		return nil, err
+	}
+
+	updated, err := s.saveManagedAgentVersion(ctx, userIdpId, org.ID, agentId, expectedVersion, func(txCtx context.Context, current *models.ManagedAgent) (*models.ManagedAgentRequest, *int32, error) {
+		return req, nil, nil
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to update managed agent", "agentId", agentId, "orgId", org.ID, "error", err)
+		return nil, err
+	}
+	s.WebhookService.PublishEvent(ctx, org, utils.WebhookEventAgentUpdated, utils.ConvertToManagedAgentResponse(updated))
+	s.logger.InfoContext(ctx, "Managed agent updated successfully", "agentId", agentId, "version", updated.Version, "orgName", orgName)
+	return updated, nil
+}
+
+func (s *managedAgentService) PatchManagedAgent(ctx context.Context, userIdpId uuid.UUID, orgName string, agentId uuid.UUID, patch map[string]interface{}, expectedVersion *int32) (*models.ManagedAgent, error) {
+	s.logger.InfoContext(ctx, "Patching managed agent", "agentId", agentId, "orgName", orgName, "userIdpId", userIdpId)
+	org, err := s.getOrganization(ctx, userIdpId, orgName)
+	if err != nil {
+		return nil, err
+	}
+
+	updated, err := s.saveManagedAgentVersion(ctx, userIdpId, org.ID, agentId, expectedVersion, func(txCtx context.Context, current *models.ManagedAgent) (*models.ManagedAgentRequest, *int32, error) {
+		config := utils.ManagedAgentConfig(current)
+		// Left to the update, which keeps the schema version unless the patch changes the framework
+		config.ConfigSchemaVersion = nil
+		var req models.ManagedAgentRequest
+		if err := utils.ApplyMergePatch(config, patch, &req); err != nil {
+			return nil, nil, err
+		}
+		if err := utils.ValidateManagedAgentRequest(req); err != nil {
+			return nil, nil, err
+		}
+		return &req, nil, nil
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to patch managed agent", "agentId", agentId, "orgId", org.ID, "error", err)
+		return nil, err
+	}
+	s.WebhookService.PublishEvent(ctx, org, utils.WebhookEventAgentUpdated, utils.ConvertToManagedAgentResponse(updated))
+	s.logger.InfoContext(ctx, "Managed agent patched successfully", "agentId", agentId, "version", updated.Version, "orgName", orgName)
+	return updated, nil
+}
+
+func (s *managedAgentService) RollbackManagedAgent(ctx context.Context, userIdpId uuid.UUID, orgName string, agentId uuid.UUID, toVersion int32, expectedVersion *int32) (*models.ManagedAgent, error) {
+	s.logger.InfoContext(ctx, "Rolling back managed agent", "agentId", agentId, "toVersion", toVersion, "orgName", orgName, "userIdpId", userIdpId)
+	org, err := s.getOrganization(ctx, userIdpId, orgName)
+	if err != nil {
+		return nil, err
+	}
+
+	updated, err := s.saveManagedAgentVersion(ctx, userIdpId, org.ID, agentId, expectedVersion, func(txCtx context.Context, current *models.ManagedAgent) (*models.ManagedAgentRequest, *int32, error) {
+		target, err := s.getManagedAgentVersion(txCtx, agentId, toVersion)
+		if err != nil {
+			return nil, nil, err
+		}
+		config := utils.ManagedAgentVersionConfig(target)
+		return &config, &target.Version, nil
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to roll back managed agent", "agentId", agentId, "toVersion", toVersion, "orgId", org.ID, "error", err)
+		return nil, err
+	}
+	s.WebhookService.PublishEvent(ctx, org, utils.WebhookEventAgentUpdated, utils.ConvertToManagedAgentResponse(updated))
+	s.logger.InfoContext(ctx, "Managed agent rolled back successfully", "agentId", agentId, "toVersion", toVersion, "version", updated.Version, "orgName", orgName)
+	return updated, nil
+}
+
+// saveManagedAgentVersion replaces the configuration of an agent with the one returned by next and records it as a new version
+func (s *managedAgentService) saveManagedAgentVersion(
+	ctx context.Context,
+	userIdpId uuid.UUID,
+	orgId uuid.UUID,
+	agentId uuid.UUID,
+	expectedVersion *int32,
+	next func(txCtx context.Context, current *models.ManagedAgent) (*models.ManagedAgentRequest, *int32, error),
+) (*models.ManagedAgent, error) {
+	var before, updated *models.ManagedAgent
+	err := db.WithTx(ctx, func(txCtx context.Context) error {
+		current, err := s.getManagedAgent(txCtx, orgId, agentId)
+		if err != nil {
+			return err
+		}
+		before = current
+		if expectedVersion != nil && *expectedVersion != current.Version {
+			return &utils.VersionMismatchError{Err: utils.ErrAgentVersionMismatch, Current: current.Version, Resource: utils.ConvertToManagedAgentResponse(current)}
+		}
+		req, rolledBackFrom, err := next(txCtx, current)
+		if err != nil {
+			return err
+		}
+		if req.Name != current.Name {
+			if err := s.ensureNameAvailable(txCtx, orgId, req.Name, agentId); err != nil {
+				return err
+			}
+		}
+		promptId, promptVersion, err := s.resolvePromptReference(txCtx, orgId, req.PromptRef)
+		if err != nil {
+			return err
+		}
+		if err := s.checkToolReferences(txCtx, orgId, req.Tools); err != nil {
+			return err
+		}
+		// Agents stay on the schema version they were validated against until they select another or change framework
+		var pinned *int32
+		if req.Framework == current.Framework {
+			pinned = current.ConfigSchemaVersion
+		}
+		schemaVersion, err := utils.ValidateAgentModelConfig(*req, pinned)
+		if err != nil {
+			return err
+		}
+
+		enabled := current.Enabled
+		if req.Enabled != nil {
+			enabled = *req.Enabled
+		}
+		agent := &models.ManagedAgent{
+			ID:                  current.ID,
+			OrgID:               current.OrgID,
+			Name:                req.Name,
+			Description:         req.Description,
+			Framework:           req.Framework,
+			ModelConfig:         req.ModelConfig,
+			SystemPrompt:        req.SystemPrompt,
+			PromptID:            promptId,
+			PromptVersion:       promptVersion,
+			Tools:               req.Tools,
+			Labels:              req.Labels,
+			Enabled:             enabled,
+			CreatedAt:           current.CreatedAt,
+			UpdatedAt:           time.Now(),
+			ConfigSchemaVersion: schemaVersion,
+			RetentionDays:       agentRetentionDays(current.RetentionDays, req.RetentionDays),
+		}
+		// The version check in the update guards against writes that happened after the read above
+		ok, err := s.ManagedAgentRepository.UpdateManagedAgent(txCtx, agent, current.Version)
+		if err != nil {
+			if db.IsUniqueViolationError(err) {
+				return utils.ErrAgentAlreadyExists
+			}
+			return fmt.Errorf("failed to update managed agent %s: %w", agentId, err)
+		}
+		if !ok {
+			// A concurrent write won, report the version it left
+			latest, err := s.getManagedAgent(txCtx, orgId, agentId)
+			if err != nil {
+				return err
+			}
+			return &utils.VersionMismatchError{Err: utils.ErrAgentVersionMismatch, Current: latest.Version, Resource: utils.ConvertToManagedAgentResponse(latest)}
+		}
+		if err := s.ManagedAgentVersionRepository.CreateManagedAgentVersion(txCtx, newManagedAgentVersion(agent, userIdpId, rolledBackFrom)); err != nil {
+			return fmt.Errorf("failed to record version %d of managed agent %s: %w", agent.Version, agentId, err)
+		}
+		updated = agent
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	recordAuditChanges(ctx, s.logger, utils.ConvertToManagedAgentResponse(before), utils.ConvertToManagedAgentResponse(updated))
+	return updated, nil
+}
+
+func newManagedAgentVersion(agent *models.ManagedAgent, author uuid.UUID, rolledBackFrom *int32) *models.ManagedAgentVersion {
+	return &models.ManagedAgentVersion{
+		AgentID:             agent.ID,
+		Version:             agent.Version,
+		Name:                agent.Name,
+		Description:         agent.Description,
+		Framework:           agent.Framework,
+		ModelConfig:         agent.ModelConfig,
+		SystemPrompt:        agent.SystemPrompt,
+		PromptID:            agent.PromptID,
+		PromptVersion:       agent.PromptVersion,
+		Tools:               agent.Tools,
+		Labels:              agent.Labels,
+		Enabled:             agent.Enabled,
+		RolledBackFrom:      rolledBackFrom,
+		CreatedBy:           &author,
+		CreatedAt:           agent.UpdatedAt,
+		ConfigSchemaVersion: agent.ConfigSchemaVersion,
+	}
+}
+
+func (s *managedAgentService) getManagedAgentVersion(ctx context.Context, agentId uuid.UUID, version int32) (*models.ManagedAgentVersion, error) {
+	agentVersion, err := s.ManagedAgentVersionRepository.GetManagedAgentVersion(ctx, agentId, version)
+	if err != nil {
+		if db.IsRecordNotFoundError(err) {
+			return nil, utils.ErrAgentVersionNotFound
+		}
+		s.logger.ErrorContext(ctx, "Failed to find managed agent version", "agentId", agentId, "version", version, "error", err)
+		return nil, fmt.Errorf("failed to find version %d of managed agent %s: %w", version, agentId, err)
+	}
+	return agentVersion, nil
+}
+
+func (s *managedAgentService) ListManagedAgentVersions(ctx context.Context, userIdpId uuid.UUID, orgName string, agentId uuid.UUID, limit int, offset int) (*models.ManagedAgent, []*models.ManagedAgentVersion, int32, error) {
+	s.logger.InfoContext(ctx, "Listing managed agent versions", "agentId", agentId, "orgName", orgName, "limit", limit, "offset", offset, "userIdpId", userIdpId)
+	org, err := s.getOrganization(ctx, userIdpId, orgName)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	agent, err := s.getManagedAgent(ctx, org.ID, agentId)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	versions, total, err := s.ManagedAgentVersionRepository.ListManagedAgentVersions(ctx, agentId, limit, offset)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to list managed agent versions", "agentId", agentId, "error", err)
+		return nil, nil, 0, fmt.Errorf("failed to list versions of managed agent %s: %w", agentId, err)
+	}
+	return agent, versions, int32(total), nil
+}
+
+func (s *managedAgentService) GetManagedAgentVersion(ctx context.Context, userIdpId uuid.UUID, orgName string, agentId uuid.UUID, version int32) (*models.ManagedAgentVersion, error) {
+	s.logger.InfoContext(ctx, "Getting managed agent version", "agentId", agentId, "version", version, "orgName", orgName, "userIdpId", userIdpId)
+	org, err := s.getOrganization(ctx, userIdpId, orgName)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.getManagedAgent(ctx, org.ID, agentId); err != nil {
+		return nil, err
+	}
+	return s.getManagedAgentVersion(ctx, agentId, version)
+}
+
+func (s *managedAgentService) DiffManagedAgentVersions(ctx context.Context, userIdpId uuid.UUID, orgName string, agentId uuid.UUID, fromVersion int32, toVersion int32) ([]models.ConfigChange, error) {
+	s.logger.InfoContext(ctx, "Diffing managed agent versions", "agentId", agentId, "fromVersion", fromVersion, "toVersion", toVersion, "orgName", orgName, "userIdpId", userIdpId)
+	from, err := s.GetManagedAgentVersion(ctx, userIdpId, orgName, agentId, fromVersion)
+	if err != nil {
+		return nil, err
+	}
+	to, err := s.getManagedAgentVersion(ctx, agentId, toVersion)
+	if err != nil {
+		return nil, err
+	}
+	changes, err := utils.DiffManagedAgentConfigs(utils.ManagedAgentVersionConfig(from), utils.ManagedAgentVersionConfig(to))
+	if err != nil {
+		return nil, fmt.Errorf("failed to diff versions %d and %d of managed agent %s: %w", fromVersion, toVersion, agentId, err)
+	}
+	return changes, nil
+}
+
+func (s *managedAgentService) ListManagedAgentTools(ctx context.Context, userIdpId uuid.UUID, orgName string, agentId uuid.UUID) ([]models.AgentToolDefinition, error) {
+	s.logger.InfoContext(ctx, "Listing managed agent tools", "agentId", agentId, "orgName", orgName, "userIdpId", userIdpId)
+	org, err := s.getOrganization(ctx, userIdpId, orgName)
+	if err != nil {
+		return nil, err
+	}
+	agent, err := s.getManagedAgent(ctx, org.ID, agentId)
+	if err != nil {
+		return nil, err
+	}
+
+	return resolveAgentTools(ctx, s.logger, s.ToolRepository, org.ID, agentId, agent.Tools)
+}
+
+// resolveAgentTools converts the tool references of an agent into tool definitions, references to tools that
+// no longer exist are skipped
+func resolveAgentTools(ctx context.Context, logger *slog.Logger, toolRepo repositories.ToolRepository, orgId uuid.UUID, agentId uuid.UUID, references []models.ToolReference) ([]models.AgentToolDefinition, error) {
+	referenced := utils.ParseToolReferenceIds(references)
+	toolIds := make([]uuid.UUID, 0, len(referenced))
+	for _, toolId := range referenced {
+		toolIds = append(toolIds, toolId)
+	}
+	found, err := toolRepo.GetToolsByIds(ctx, orgId, toolIds)
+	if err != nil {
+		logger.ErrorContext(ctx, "Failed to find agent tools", "agentId", agentId, "orgId", orgId, "error", err)
+		return nil, fmt.Errorf("failed to find tools of managed agent %s: %w", agentId, err)
+	}
+	tools := make(map[uuid.UUID]*models.Tool, len(found))
+	for _, tool := range found {
+		tools[tool.ID] = tool
+	}
+
+	definitions := make([]models.AgentToolDefinition, 0, len(references))
+	for i, reference := range references {
+		toolId, registered := referenced[i]
+		if !registered {
+			definitions = append(definitions, models.AgentToolDefinition{
+				ToolDefinition: traceobserversvc.ToolDefinition{Name: reference.Name},
+			})
+			continue
+		}
+		tool, ok := tools[toolId]
+		if !ok {
+			logger.WarnContext(ctx, "Agent references a tool that no longer exists", "agentId", agentId, "toolId", toolId)
+			continue
+		}
+		definition, err := utils.ConvertToAgentToolDefinition(tool)
+		if err != nil {
+			return nil, err
+		}
+		definitions = append(definitions, definition)
+	}
+	return definitions, nil
+}
+
+func (s *managedAgentService) DeleteManagedAgent(ctx context.Context, userIdpId uuid.UUID, orgName string, agentId uuid.UUID) error {
+	s.logger.InfoContext(ctx, "Deleting managed agent", "agentId", agentId, "orgName", orgName, "userIdpId", userIdpId)
+	org, err := s.getOrganization(ctx, userIdpId, orgName)
+	if err != nil {
+		return err
+	}
+	deleted, err := s.ManagedAgentRepository.DeleteManagedAgent(ctx, org.ID, agentId)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to delete managed agent", "agentId", agentId, "orgId", org.ID, "error", err)
+		return fmt.Errorf("failed to delete managed agent %s: %w", agentId, err)
+	}
+	if !deleted {
+		return utils.ErrAgentNotFound
+	}
+	s.WebhookService.PublishEvent(ctx, org, utils.WebhookEventAgentDeleted, deletedAgentEvent(agentId))
+	s.logger.InfoContext(ctx, "Managed agent deleted successfully", "agentId", agentId, "orgName", orgName)
+	return nil
+}
+
+func (s *managedAgentService) RestoreManagedAgent(ctx context.Context, userIdpId uuid.UUID, orgName string, agentId uuid.UUID) (*models.ManagedAgent, error) {
+	s.logger.InfoContext(ctx, "Restoring managed agent", "agentId", agentId, "orgName", orgName, "userIdpId", userIdpId)
+	org, err := s.getOrganization(ctx, userIdpId, orgName)
+	if err != nil {
+		return nil, err
+	}
+
+	var restored *models.ManagedAgent
+	err = db.WithTx(ctx, func(txCtx context.Context) error {
+		ok, err := s.ManagedAgentRepository.RestoreManagedAgent(txCtx, org.ID, agentId)
+		if err != nil {
+			// Another agent took the name after this one was deleted
+			if db.IsUniqueViolationError(err) {
+				return utils.ErrAgentAlreadyExists
+			}
+			return fmt.Errorf("failed to restore managed agent %s: %w", agentId, err)
+		}
+		if !ok {
+			return utils.ErrAgentNotFound
+		}
+		agent, err := s.getManagedAgent(txCtx, org.ID, agentId)
+		if err != nil {
+			return err
+		}
+		// The prompt may have been deleted and the tools unregistered while the agent was deleted
+		if _, _, err := s.resolvePromptReference(txCtx, org.ID, utils.ConvertToPromptReference(agent.PromptID, agent.PromptVersion)); err != nil {
+			return restoreReferenceError(err)
+		}
+		if err := s.checkToolReferences(txCtx, org.ID, agent.Tools); err != nil {
+			return restoreReferenceError(err)
+		}
+		restored = agent
+		return nil
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to restore managed agent", "agentId", agentId, "orgId", org.ID, "error", err)
+		return nil, err
+	}
+	s.WebhookService.PublishEvent(ctx, org, utils.WebhookEventAgentRestored, utils.ConvertToManagedAgentResponse(restored))
+	s.logger.InfoContext(ctx, "Managed agent restored successfully", "agentId", agentId, "orgName", orgName)
+	return restored, nil
+}
+
+// restoreReferenceError reports a reference of an agent being restored that no longer resolves
+func restoreReferenceError(err error) error {
+	var validationErr *utils.ValidationError
+	if !errors.As(err, &validationErr) {
+		return err
+	}
+	return fmt.Errorf("%w: %s", utils.ErrAgentReferenceDeleted, validationErr.Error())
+}
+
+// agentRetentionDays returns the retention to store for an agent, requests without one keep the current retention
+// and 0 returns the agent to the retention of the traces observer
+func agentRetentionDays(current, requested *int32) *int32 {
+	if requested == nil {
+		return current
+	}
+	if *requested == 0 {
+		return nil
+	}
+	days := *requested
+	return &days
+}
@@ -21,15 +21,24 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
 
 	"github.com/wso2/ai-agent-management-platform/agent-manager-service/clients/openchoreosvc"
 	traceobserversvc "github.com/wso2/ai-agent-management-platform/agent-manager-service/clients/traceobserversvc"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/db"
 	"github.com/wso2/ai-agent-management-platform/agent-manager-service/models"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/repositories"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/utils"
 )
 
 // ErrTraceNotFound is returned when a trace is not found
 var ErrTraceNotFound = errors.New("trace not found")
 
+// ErrTraceQueryRejected is returned when the trace observer rejects a query, e.g. for spanning too many intervals
+var ErrTraceQueryRejected = errors.New("trace query rejected")
+
 // Service-level request/response types (not exposing client types)
 type ListTracesRequest struct {
 	OrgName     string
@@ -51,37 +60,76 @@ type TraceDetailsRequest struct {
 	Environment string
 }
 
+// AgentTracesRequest lists the traces the trace observer linked to a managed agent
+type AgentTracesRequest struct {
+	OrgName   string
+	AgentID   uuid.UUID
+	StartTime string
+	EndTime   string
+	Limit     int
+	Offset    int
+	SortOrder string
+	// Sort is the field traces are sorted by, the start time when empty
+	Sort string
+	// Filters of the trace list, unset when empty
+	Status                string
+	MinDuration           time.Duration
+	Model                 string
+	Framework             string
+	AnnotationLabel       string
+	HasGuardrailViolation *bool
+}
+
+// AgentMetricsRequest summarizes the traces the trace observer linked to a managed agent
+type AgentMetricsRequest struct {
+	OrgName   string
+	AgentID   uuid.UUID
+	StartTime string
+	EndTime   string
+	Interval  string
+}
+
 type ObservabilityManagerService interface {
 	ListTraces(ctx context.Context, req ListTracesRequest) (*models.TraceOverviewResponse, error)
 	GetTraceDetails(ctx context.Context, req TraceDetailsRequest) (*models.TraceResponse, error)
+	// ListAgentTraces lists the traces of a managed agent, deleted agents keep their traces until purged
+	ListAgentTraces(ctx context.Context, userIdpId uuid.UUID, req AgentTracesRequest) (*models.TraceOverviewResponse, error)
+	// GetAgentMetrics returns the latency, error and cost metrics of the traces of a managed agent
+	GetAgentMetrics(ctx context.Context, userIdpId uuid.UUID, req AgentMetricsRequest) (*models.AgentMetricsResponse, error)
 }
 
 type observabilityManagerService struct {
-	traceObserverClient traceobserversvc.TraceObserverClient
-	openChoreoClient    openchoreosvc.OpenChoreoSvcClient
-	logger              *slog.Logger
+	traceObserverClient    traceobserversvc.TraceObserverClient
+	openChoreoClient       openchoreosvc.OpenChoreoSvcClient
+	OrganizationRepository repositories.OrganizationRepository
+	ManagedAgentRepository repositories.ManagedAgentRepository
+	logger                 *slog.Logger
 }
 
 func NewObservabilityManager(
 	traceObserverClient traceobserversvc.TraceObserverClient,
 	openChoreoClient openchoreosvc.OpenChoreoSvcClient,
+	orgRepo repositories.OrganizationRepository,
+	managedAgentRepo repositories.ManagedAgentRepository,
 	logger *slog.Logger,
 ) ObservabilityManagerService {
 	return &observabilityManagerService{
-		traceObserverClient: traceObserverClient,
-		openChoreoClient:    openChoreoClient,
-		logger:              logger,
+		traceObserverClient:    traceObserverClient,
+		openChoreoClient:       openChoreoClient,
+		OrganizationRepository: orgRepo,
+		ManagedAgentRepository: managedAgentRepo,
+		logger:                 logger,
 	}
 }
 
 // ListTraces retrieves trace overviews from the trace observer service
 func (s *observabilityManagerService) ListTraces(ctx context.Context, req ListTracesRequest) (*models.TraceOverviewResponse, error) {
-	s.logger.Info("Listing traces", "agentName", req.AgentName, "limit", req.Limit, "offset", req.Offset)
+	s.logger.InfoContext(ctx, "Listing traces", "agentName", req.AgentName, "limit", req.Limit, "offset", req.Offset)
 
 	// Fetch component to get UID
 	component, err := s.openChoreoClient.GetAgentComponent(ctx, req.OrgName, req.ProjectName, req.AgentName)
 	if err != nil {
-		s.logger.Error("Failed to get agent component", "agentName", req.AgentName, "error", err)
+		s.logger.ErrorContext(ctx, "Failed to get agent component", "agentName", req.AgentName, "error", err)
 		return nil, fmt.Errorf("failed to get agent component: %w", err)
 	}
 
@@ -89,12 +137,17 @@ func (s *observabilityManagerService) ListTraces(ctx context.Context, req ListTr
 
 	environment, err := s.openChoreoClient.GetEnvironment(ctx, req.OrgName, req.Environment)
 	if err != nil {
-		s.logger.Error("Failed to get environment", "environment", req.Environment, "error", err)
+		s.logger.ErrorContext(ctx, "Failed to get environment", "environment", req.Environment, "error", err)
 		return nil, fmt.Errorf("failed to get environment: %w", err)
 	}
 
 	// Convert service request to client params
+	orgId, ok := db.OrgScope(ctx)
+	if !ok {
+		return nil, fmt.Errorf("request is not bound to an organization")
+	}
 	clientParams := traceobserversvc.ListTracesParams{
+		OrgID:          orgId.String(),
 		ServiceName:    req.AgentName,
 		ComponentUid:   component.UUID,
 		EnvironmentUid: environment.UUID,
@@ -108,74 +161,41 @@ func (s *observabilityManagerService) ListTraces(ctx context.Context, req ListTr
 	// Call the trace observer client
 	clientResponse, err := s.traceObserverClient.ListTraces(ctx, clientParams)
 	if err != nil {
-		s.logger.Error("Failed to list traces", "agentName", req.AgentName, "error", err)
+		s.logger.ErrorContext(ctx, "Failed to list traces", "agentName", req.AgentName, "error", err)
 		return nil, fmt.Errorf("failed to list traces: %w", err)
 	}
 
-	s.logger.Info("Successfully listed traces", "agentName", req.AgentName, "traceCount", len(clientResponse.Traces))
-	// Convert client response to service model
-	traces := make([]models.TraceOverview, len(clientResponse.Traces))
-	for i, trace := range clientResponse.Traces {
-		var tokenUsage *models.TokenUsage
-		if trace.TokenUsage != nil {
-			tokenUsage = &models.TokenUsage{
-				InputTokens:  trace.TokenUsage.InputTokens,
-				OutputTokens: trace.TokenUsage.OutputTokens,
-				TotalTokens:  trace.TokenUsage.TotalTokens,
-			}
-		}
-
-		var traceStatus *models.TraceStatus
-		if trace.Status != nil {
-			traceStatus = &models.TraceStatus{
-				ErrorCount: trace.Status.ErrorCount,
-			}
-		}
+	s.logger.InfoContext(ctx, "Successfully listed traces", "agentName", req.AgentName, "traceCount", len(clientResponse.Traces))
+	response := convertTraceOverviews(clientResponse)
 
-		traces[i] = models.TraceOverview{
-			TraceID:         trace.TraceID,
-			RootSpanID:      trace.RootSpanID,
-			RootSpanName:    trace.RootSpanName,
-			RootSpanKind:    trace.RootSpanKind,
-			StartTime:       trace.StartTime,
-			EndTime:         trace.EndTime,
-			DurationInNanos: trace.DurationInNanos,
-			SpanCount:       trace.SpanCount,
-			TokenUsage:      tokenUsage,
-			Status:          traceStatus,
-			Input:           trace.Input,
-			Output:          trace.Output,
-		}
-	}
-
-	response := &models.TraceOverviewResponse{
-		Traces:     traces,
-		TotalCount: clientResponse.TotalCount,
-	}
-
-	s.logger.Info("Retrieved traces successfully", "agentName", req.AgentName, "totalCount", response.TotalCount)
+	s.logger.InfoContext(ctx, "Retrieved traces successfully", "agentName", req.AgentName, "totalCount", response.TotalCount)
 	return response, nil
 }
 
 // GetTraceDetails retrieves detailed trace information by trace ID
 func (s *observabilityManagerService) GetTraceDetails(ctx context.Context, req TraceDetailsRequest) (*models.TraceResponse, error) {
-	s.logger.Info("Getting trace details", "traceId", req.TraceID, "agentName", req.AgentName)
+	s.logger.InfoContext(ctx, "Getting trace details", "traceId", req.TraceID, "agentName", req.AgentName)
 
 	// Fetch component to get UID
 	component, err := s.openChoreoClient.GetAgentComponent(ctx, req.OrgName, req.ProjectName, req.AgentName)
 	if err != nil {
-		s.logger.Error("Failed to get agent component", "agentName", req.AgentName, "error", err)
+		s.logger.ErrorContext(ctx, "Failed to get agent component", "agentName", req.AgentName, "error", err)
 		return nil, fmt.Errorf("failed to get agent component: %w", err)
 	}
 
 	environment, err := s.openChoreoClient.GetEnvironment(ctx, req.OrgName, req.Environment)
 	if err != nil {
-		s.logger.Error("Failed to get environment", "environment", req.Environment, "error", err)
+		s.logger.ErrorContext(ctx, "Failed to get environment", "environment", req.Environment, "error", err)
 		return nil, fmt.Errorf("failed to get environment: %w", err)
 	}
 
 	// Convert service request to client params
+	orgId, ok := db.OrgScope(ctx)
+	if !ok {
+		return nil, fmt.Errorf("request is not bound to an organization")
+	}
 	clientParams := traceobserversvc.TraceDetailsByIdParams{
+		OrgID:          orgId.String(),
 		TraceID:        req.TraceID,
 		ServiceName:    req.AgentName,
 		ComponentUid:   component.UUID,
@@ -187,10 +207,10 @@ func (s *observabilityManagerService) GetTraceDetails(ctx context.Context, req T
 	if err != nil {
 		// Check if it's a 404 error using typed error check
 		if traceobserversvc.IsNotFound(err) {
-			s.logger.Warn("Trace not found", "traceId", req.TraceID, "agentName", req.AgentName)
+			s.logger.WarnContext(ctx, "Trace not found", "traceId", req.TraceID, "agentName", req.AgentName)
 			return nil, ErrTraceNotFound
 		}
-		s.logger.Error("Failed to get trace details", "traceId", req.TraceID, "agentName", req.AgentName, "error", err)
+		s.logger.ErrorContext(ctx, "Failed to get trace details", "traceId", req.TraceID, "agentName", req.AgentName, "error", err)
 		return nil, fmt.Errorf("failed to get trace details: %w", err)
 	}
 
@@ -318,6 +338,166 @@ func (s *observabilityManagerService) GetTraceDetails(ctx context.Context, req T
 		Status:     traceStatus,
 	}
 
-	s.logger.Info("Retrieved trace details successfully", "traceId", req.TraceID, "spanCount", response.TotalCount)
+	s.logger.InfoContext(ctx, "Retrieved trace details successfully", "traceId", req.TraceID, "spanCount", response.TotalCount)
 	return response, nil
 }
+
+// getManagedAgent finds an agent of the organization, deleted or not, so that the traces it left stay readable
+func (s *observabilityManagerService) getManagedAgent(ctx context.Context, userIdpId uuid.UUID, orgName string, agentId uuid.UUID) (*models.ManagedAgent, error) {
+	org, err := s.OrganizationRepository.GetOrganizationByOrgName(ctx, userIdpId, orgName)
+	if err != nil {
+		if db.IsRecordNotFoundError(err) {
+			s.logger.ErrorContext(ctx, "Organization not found", "orgName", orgName, "userIdpId", userIdpId)
+			return nil, utils.ErrOrganizationNotFound
+		}
+		s.logger.ErrorContext(ctx, "Failed to find organization", "orgName", orgName, "userIdpId", userIdpId, "error", err)
+		return nil, fmt.Errorf("failed to find organization %s: %w", orgName, err)
+	}
+	agent, err := s.ManagedAgentRepository.GetManagedAgentByIdWithDeleted(ctx, org.ID, agentId)
+	if err != nil {
+		if db.IsRecordNotFoundError(err) {
+			return nil, utils.ErrAgentNotFound
+		}
+		s.logger.ErrorContext(ctx, "Failed to find managed agent", "agentId", agentId, "orgId", org.ID, "error", err)
+		return nil, fmt.Errorf("failed to find managed agent %s: %w", agentId, err)
+	}
+	return agent, nil
+}
+
+// ListAgentTraces retrieves the trace overviews the trace observer linked to a managed agent
+func (s *observabilityManagerService) ListAgentTraces(ctx context.Context, userIdpId uuid.UUID, req AgentTracesRequest) (*models.TraceOverviewResponse, error) {
+	s.logger.InfoContext(ctx, "Listing agent traces", "agentId", req.AgentID, "limit", req.Limit, "offset", req.Offset, "userIdpId", userIdpId)
+	agent, err := s.getManagedAgent(ctx, userIdpId, req.OrgName, req.AgentID)
+	if err != nil {
+		return nil, err
+	}
+
+	clientResponse, err := s.traceObserverClient.ListTraces(ctx, traceobserversvc.ListTracesParams{
+		OrgID:     agent.OrgID.String(),
+		AgentID:   agent.ID.String(),
+		StartTime: req.StartTime,
+		EndTime:   req.EndTime,
+		Limit:     req.Limit,
+		Offset:    req.Offset,
+		SortOrder: req.SortOrder,
+		Sort:      req.Sort,
+		Filters: traceobserversvc.TraceFilters{
+			Status:                req.Status,
+			MinDuration:           req.MinDuration,
+			Model:                 req.Model,
+			Framework:             req.Framework,
+			AnnotationLabel:       req.AnnotationLabel,
+			HasGuardrailViolation: req.HasGuardrailViolation,
+		},
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to list agent traces", "agentId", req.AgentID, "error", err)
+		return nil, fmt.Errorf("failed to list traces of agent %s: %w", req.AgentID, err)
+	}
+	return convertTraceOverviews(clientResponse), nil
+}
+
+// GetAgentMetrics retrieves the trace metrics of a managed agent per interval and over the whole time range
+func (s *observabilityManagerService) GetAgentMetrics(ctx context.Context, userIdpId uuid.UUID, req AgentMetricsRequest) (*models.AgentMetricsResponse, error) {
+	s.logger.InfoContext(ctx, "Getting agent metrics", "agentId", req.AgentID, "interval", req.Interval, "userIdpId", userIdpId)
+	agent, err := s.getManagedAgent(ctx, userIdpId, req.OrgName, req.AgentID)
+	if err != nil {
+		return nil, err
+	}
+
+	clientResponse, err := s.traceObserverClient.GetTraceMetrics(ctx, traceobserversvc.TraceMetricsParams{
+		OrgID:     agent.OrgID.String(),
+		AgentID:   agent.ID.String(),
+		StartTime: req.StartTime,
+		EndTime:   req.EndTime,
+		Interval:  req.Interval,
+	})
+	if err != nil {
+		// The trace observer bounds the number of intervals a query may span
+		if traceobserversvc.IsBadRequest(err) {
+			return nil, ErrTraceQueryRejected
+		}
+		s.logger.ErrorContext(ctx, "Failed to get agent metrics", "agentId", req.AgentID, "error", err)
+		return nil, fmt.Errorf("failed to get metrics of agent %s: %w", req.AgentID, err)
+	}
+
+	response := &models.AgentMetricsResponse{
+		AgentID:   agent.ID.String(),
+		Interval:  clientResponse.Interval,
+		StartTime: clientResponse.StartTime,
+		EndTime:   clientResponse.EndTime,
+		Points:    []models.AgentMetricsPoint{},
+	}
+	if clientResponse.Summary != nil {
+		response.Summary = convertAgentMetrics(*clientResponse.Summary)
+	}
+	for _, series := range clientResponse.Series {
+		for _, point := range series.Points {
+			response.Points = append(response.Points, models.AgentMetricsPoint{
+				Timestamp:    point.Timestamp,
+				AgentMetrics: convertAgentMetrics(point),
+			})
+		}
+	}
+	return response, nil
+}
+
+// convertAgentMetrics converts a trace metrics point of the trace observer into the service model
+func convertAgentMetrics(point traceobserversvc.TraceMetricsPoint) models.AgentMetrics {
+	return models.AgentMetrics{
+		TraceCount: point.TraceCount,
+		ErrorCount: point.ErrorCount,
+		ErrorRate:  point.ErrorRate,
+		DurationNanos: models.LatencyPercentiles{
+			P50: point.DurationNanos.P50,
+			P95: point.DurationNanos.P95,
+			P99: point.DurationNanos.P99,
+		},
+		InputTokens:  point.InputTokens,
+		OutputTokens: point.OutputTokens,
+		TotalTokens:  point.TotalTokens,
+		Cost:         point.Cost,
+	}
+}
+
+// convertTraceOverviews converts the trace overviews of the trace observer into the service model
+func convertTraceOverviews(clientResponse *traceobserversvc.TraceOverviewResponse) *models.TraceOverviewResponse {
+	traces := make([]models.TraceOverview, len(clientResponse.Traces))
+	for i, trace := range clientResponse.Traces {
+		var tokenUsage *models.TokenUsage
+		if trace.TokenUsage != nil {
+			tokenUsage = &models.TokenUsage{
+				InputTokens:  trace.TokenUsage.InputTokens,
+				OutputTokens: trace.TokenUsage.OutputTokens,
+				TotalTokens:  trace.TokenUsage.TotalTokens,
+			}
+		}
+
+		var traceStatus *models.TraceStatus
+		if trace.Status != nil {
+			traceStatus = &models.TraceStatus{
+				ErrorCount: trace.Status.ErrorCount,
+			}
+		}
+
+		traces[i] = models.TraceOverview{
+			TraceID:         trace.TraceID,
+			RootSpanID:      trace.RootSpanID,
+			RootSpanName:    trace.RootSpanName,
+			RootSpanKind:    trace.RootSpanKind,
+			StartTime:       trace.StartTime,
+			EndTime:         trace.EndTime,
+			DurationInNanos: trace.DurationInNanos,
+			SpanCount:       trace.SpanCount,
+			TokenUsage:      tokenUsage,
+			Status:          traceStatus,
+			Input:           trace.Input,
+			Output:          trace.Output,
+		}
+	}
+
+	return &models.TraceOverviewResponse{
+		Traces:     traces,
+		TotalCount: clientResponse.TotalCount,
+	}
+}
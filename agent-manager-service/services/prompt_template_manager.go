@@ -0,0 +1,428 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/db"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/models"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/repositories"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/utils"
+)
+
+type PromptTemplateService interface {
+	ListPromptTemplates(ctx context.Context, userIdpId uuid.UUID, orgName string, filter models.PromptTemplateFilter) ([]*models.PromptTemplate, *models.ListCursor, int32, error)
+	// GetPromptTemplate also returns a deleted prompt that is not yet purged when includeDeleted is set
+	GetPromptTemplate(ctx context.Context, userIdpId uuid.UUID, orgName string, promptId uuid.UUID, includeDeleted bool) (*models.PromptTemplate, error)
+	CreatePromptTemplate(ctx context.Context, userIdpId uuid.UUID, orgName string, req *models.PromptTemplateRequest) (*models.PromptTemplate, error)
+	// UpdatePromptTemplate records a new version of the prompt, when expectedVersion is set the prompt must still be at that version
+	UpdatePromptTemplate(ctx context.Context, userIdpId uuid.UUID, orgName string, promptId uuid.UUID, req *models.PromptTemplateRequest, expectedVersion *int32) (*models.PromptTemplate, error)
+	// PatchPromptTemplate applies a JSON merge patch to the prompt and records the result as a new version, when
+	// expectedVersion is set the prompt must still be at that version
+	PatchPromptTemplate(ctx context.Context, userIdpId uuid.UUID, orgName string, promptId uuid.UUID, patch map[string]interface{}, expectedVersion *int32) (*models.PromptTemplate, error)
+	// DeletePromptTemplate fails with a utils.DependentsError while agents reference the prompt
+	DeletePromptTemplate(ctx context.Context, userIdpId uuid.UUID, orgName string, promptId uuid.UUID) error
+	// RestorePromptTemplate undoes the deletion of a prompt that is not yet purged, its name must still be free
+	RestorePromptTemplate(ctx context.Context, userIdpId uuid.UUID, orgName string, promptId uuid.UUID) (*models.PromptTemplate, error)
+	ListPromptTemplateVersions(ctx context.Context, userIdpId uuid.UUID, orgName string, promptId uuid.UUID, limit int, offset int) ([]*models.PromptTemplateVersion, int32, error)
+	GetPromptTemplateVersion(ctx context.Context, userIdpId uuid.UUID, orgName string, promptId uuid.UUID, version int32) (*models.PromptTemplateVersion, error)
+	// RenderPromptTemplate renders the requested version of the prompt, the latest one when no version is given
+	RenderPromptTemplate(ctx context.Context, userIdpId uuid.UUID, orgName string, promptId uuid.UUID, req *models.PromptRenderRequest) (*models.PromptTemplateVersion, string, error)
+}
+
+type promptTemplateService struct {
+	OrganizationRepository   repositories.OrganizationRepository
+	PromptTemplateRepository repositories.PromptTemplateRepository
+	ManagedAgentRepository   repositories.ManagedAgentRepository
+	logger                   *slog.Logger
+}
+
+func NewPromptTemplateService(
+	orgRepo repositories.OrganizationRepository,
+	promptTemplateRepo repositories.PromptTemplateRepository,
+	managedAgentRepo repositories.ManagedAgentRepository,
+	logger *slog.Logger,
+) PromptTemplateService {
+	return &promptTemplateService{
+		OrganizationRepository:   orgRepo,
+		PromptTemplateRepository: promptTemplateRepo,
+		ManagedAgentRepository:   managedAgentRepo,
+		logger:                   logger,
+	}
+}
+
+func (s *promptTemplateService) getOrganization(ctx context.Context, userIdpId uuid.UUID, orgName string) (*models.Organization, error) {
+	org, err := s.OrganizationRepository.GetOrganizationByOrgName(ctx, userIdpId, orgName)
+	if err != nil {
+		if db.IsRecordNotFoundError(err) {
+			s.logger.ErrorContext(ctx, "Organization not found", "orgName", orgName, "userIdpId", userIdpId)
+			return nil, utils.ErrOrganizationNotFound
+		}
+		s.logger.ErrorContext(ctx, "Failed to find organization", "orgName", orgName, "userIdpId", userIdpId, "error", err)
+		return nil, fmt.Errorf("failed to find organization %s: %w", orgName, err)
+	}
+	return org, nil
+}
+
+func (s *promptTemplateService) getPromptTemplate(ctx context.Context, orgId uuid.UUID, promptId uuid.UUID) (*models.PromptTemplate, error) {
+	prompt, err := s.PromptTemplateRepository.GetPromptTemplateById(ctx, orgId, promptId)
+	if err != nil {
+		if db.IsRecordNotFoundError(err) {
+			return nil, utils.ErrPromptNotFound
+		}
+		s.logger.ErrorContext(ctx, "Failed to find prompt", "promptId", promptId, "orgId", orgId, "error", err)
+		return nil, fmt.Errorf("failed to find prompt %s: %w", promptId, err)
+	}
+	return prompt, nil
+}
+
+func (s *promptTemplateService) getPromptTemplateVersion(ctx context.Context, promptId uuid.UUID, version int32) (*models.PromptTemplateVersion, error) {
+	promptVersion, err := s.PromptTemplateRepository.GetPromptTemplateVersion(ctx, promptId, version)
+	if err != nil {
+		if db.IsRecordNotFoundError(err) {
+			return nil, utils.ErrPromptVersionNotFound
+		}
+		s.logger.ErrorContext(ctx, "Failed to find prompt version", "promptId", promptId, "version", version, "error", err)
+		return nil, fmt.Errorf("failed to find version %d of prompt %s: %w", version, promptId, err)
+	}
+	return promptVersion, nil
+}
+
+// ensureNameAvailable fails with ErrPromptAlreadyExists when another prompt of the organization has the name
+func (s *promptTemplateService) ensureNameAvailable(ctx context.Context, orgId uuid.UUID, name string, promptId uuid.UUID) error {
+	existing, err := s.PromptTemplateRepository.GetPromptTemplateByName(ctx, orgId, name)
+	if err != nil {
+		if db.IsRecordNotFoundError(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to check prompt name %s: %w", name, err)
+	}
+	if existing.ID != promptId {
+		return utils.ErrPromptAlreadyExists
+	}
+	return nil
+}
+
+func (s *promptTemplateService) ListPromptTemplates(ctx context.Context, userIdpId uuid.UUID, orgName string, filter models.PromptTemplateFilter) ([]*models.PromptTemplate, *models.ListCursor, int32, error) {
+	s.logger.InfoContext(ctx, "Listing prompts", "orgName", orgName, "search", filter.Search, "labelSelector", filter.Selector, "includeDeleted", filter.IncludeDeleted, "sort", filter.Page.Sort, "sortOrder", filter.Page.Order, "limit", filter.Page.Limit, "offset", filter.Page.Offset, "cursor", filter.Page.Cursor != nil, "userIdpId", userIdpId)
+	org, err := s.getOrganization(ctx, userIdpId, orgName)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	prompts, next, total, err := s.PromptTemplateRepository.ListPromptTemplates(ctx, org.ID, filter)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to list prompts", "orgId", org.ID, "error", err)
+		return nil, nil, 0, fmt.Errorf("failed to list prompts: %w", err)
+	}
+	return prompts, next, int32(total), nil
+}
+
+func (s *promptTemplateService) GetPromptTemplate(ctx context.Context, userIdpId uuid.UUID, orgName string, promptId uuid.UUID, includeDeleted bool) (*models.PromptTemplate, error) {
+	s.logger.InfoContext(ctx, "Getting prompt", "promptId", promptId, "includeDeleted", includeDeleted, "orgName", orgName, "userIdpId", userIdpId)
+	org, err := s.getOrganization(ctx, userIdpId, orgName)
+	if err != nil {
+		return nil, err
+	}
+	if !includeDeleted {
+		return s.getPromptTemplate(ctx, org.ID, promptId)
+	}
+	prompt, err := s.PromptTemplateRepository.GetPromptTemplateByIdWithDeleted(ctx, org.ID, promptId)
+	if err != nil {
+		if db.IsRecordNotFoundError(err) {
+			return nil, utils.ErrPromptNotFound
+		}
+		s.logger.ErrorContext(ctx, "Failed to find prompt", "promptId", promptId, "orgId", org.ID, "error", err)
+		return nil, fmt.Errorf("failed to find prompt %s: %w", promptId, err)
+	}
+	return prompt, nil
+}
+
+func (s *promptTemplateService) CreatePromptTemplate(ctx context.Context, userIdpId uuid.UUID, orgName string, req *models.PromptTemplateRequest) (*models.PromptTemplate, error) {
+	s.logger.InfoContext(ctx, "Creating prompt", "promptName", req.Name, "orgName", orgName, "userIdpId", userIdpId)
+	org, err := s.getOrganization(ctx, userIdpId, orgName)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.ensureNameAvailable(ctx, org.ID, req.Name, uuid.Nil); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	prompt := &models.PromptTemplate{
+		ID:          uuid.New(),
+		OrgID:       org.ID,
+		Name:        req.Name,
+		Description: req.Description,
+		Template:    req.Template,
+		Variables:   utils.NormalizePromptVariables(req.Template, req.Variables),
+		Labels:      req.Labels,
+		Version:     1,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	err = db.WithTx(ctx, func(txCtx context.Context) error {
+		if err := s.PromptTemplateRepository.CreatePromptTemplate(txCtx, prompt); err != nil {
+			if db.IsUniqueViolationError(err) {
+				return utils.ErrPromptAlreadyExists
+			}
+			return fmt.Errorf("failed to create prompt %s: %w", req.Name, err)
+		}
+		if err := s.PromptTemplateRepository.CreatePromptTemplateVersion(txCtx, newPromptTemplateVersion(prompt, userIdpId)); err != nil {
+			return fmt.Errorf("failed to record version of prompt %s: %w", req.Name, err)
+		}
+		return nil
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to create prompt", "promptName", req.Name, "orgId", org.ID, "error", err)
+		return nil, err
+	}
+	s.logger.InfoContext(ctx, "Prompt created successfully", "promptId", prompt.ID, "promptName", prompt.Name, "orgName", orgName)
+	return prompt, nil
+}
+
+func (s *promptTemplateService) UpdatePromptTemplate(ctx context.Context, userIdpId uuid.UUID, orgName string, promptId uuid.UUID, req *models.PromptTemplateRequest, expectedVersion *int32) (*models.PromptTemplate, error) {
+	s.logger.InfoContext(ctx, "Updating prompt", "promptId", promptId, "orgName", orgName, "userIdpId", userIdpId)
+	return s.savePromptTemplateVersion(ctx, userIdpId, orgName, promptId, expectedVersion, func(current *models.PromptTemplate) (*models.PromptTemplateRequest, error) {
+		return req, nil
+	})
+}
+
+func (s *promptTemplateService) PatchPromptTemplate(ctx context.Context, userIdpId uuid.UUID, orgName string, promptId uuid.UUID, patch map[string]interface{}, expectedVersion *int32) (*models.PromptTemplate, error) {
+	s.logger.InfoContext(ctx, "Patching prompt", "promptId", promptId, "orgName", orgName, "userIdpId", userIdpId)
+	return s.savePromptTemplateVersion(ctx, userIdpId, orgName, promptId, expectedVersion, func(current *models.PromptTemplate) (*models.PromptTemplateRequest, error) {
+		var req models.PromptTemplateRequest
+		if err := utils.ApplyMergePatch(utils.PromptTemplateConfig(current), patch, &req); err != nil {
+			return nil, err
+		}
+		if err := utils.ValidatePromptTemplateRequest(req); err != nil {
+			return nil, err
+		}
+		return &req, nil
+	})
+}
+
+// savePromptTemplateVersion replaces the template of a prompt with the one returned by next and records it as a new version
+func (s *promptTemplateService) savePromptTemplateVersion(
+	ctx context.Context,
+	userIdpId uuid.UUID,
+	orgName string,
+	promptId uuid.UUID,
+	expectedVersion *int32,
+	next func(current *models.PromptTemplate) (*models.PromptTemplateRequest, error),
+) (*models.PromptTemplate, error) {
+	org, err := s.getOrganization(ctx, userIdpId, orgName)
+	if err != nil {
+		return nil, err
+	}
+
+	var before, updated *models.PromptTemplate
+	err = db.WithTx(ctx, func(txCtx context.Context) error {
+		current, err := s.getPromptTemplate(txCtx, org.ID, promptId)
+		if err != nil {
+			return err
+		}
+		before = current
+		if expectedVersion != nil && *expectedVersion != current.Version {
+			return &utils.VersionMismatchError{Err: utils.ErrPromptVersionMismatch, Current: current.Version, Resource: utils.ConvertToPromptTemplateResponse(current)}
+		}
+		req, err := next(current)
+		if err != nil {
+			return err
+		}
+		if req.Name != current.Name {
+			if err := s.ensureNameAvailable(txCtx, org.ID, req.Name, promptId); err != nil {
+				return err
+			}
+		}
+
+		prompt := &models.PromptTemplate{
+			ID:          current.ID,
+			OrgID:       current.OrgID,
+			Name:        req.Name,
+			Description: req.Description,
+			Template:    req.Template,
+			Variables:   utils.NormalizePromptVariables(req.Template, req.Variables),
+			Labels:      req.Labels,
+			CreatedAt:   current.CreatedAt,
+			UpdatedAt:   time.Now(),
+		}
+		// The version check in the update guards against writes that happened after the read above
+		ok, err := s.PromptTemplateRepository.UpdatePromptTemplate(txCtx, prompt, current.Version)
+		if err != nil {
+			if db.IsUniqueViolationError(err) {
+				return utils.ErrPromptAlreadyExists
+			}
+			return fmt.Errorf("failed to update prompt %s: %w", promptId, err)
+		}
+		if !ok {
+			// A concurrent write won, report the version it left
+			latest, err := s.getPromptTemplate(txCtx, org.ID, promptId)
+			if err != nil {
+				return err
+			}
+			return &utils.VersionMismatchError{Err: utils.ErrPromptVersionMismatch, Current: latest.Version, Resource: utils.ConvertToPromptTemplateResponse(latest)}
+		}
+		if err := s.PromptTemplateRepository.CreatePromptTemplateVersion(txCtx, newPromptTemplateVersion(prompt, userIdpId)); err != nil {
+			return fmt.Errorf("failed to record version %d of prompt %s: %w", prompt.Version, promptId, err)
+		}
+		updated = prompt
+		return nil
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to update prompt", "promptId", promptId, "orgId", org.ID, "error", err)
+		return nil, err
+	}
+	recordAuditChanges(ctx, s.logger, utils.ConvertToPromptTemplateResponse(before), utils.ConvertToPromptTemplateResponse(updated))
+	s.logger.InfoContext(ctx, "Prompt updated successfully", "promptId", promptId, "version", updated.Version, "orgName", orgName)
+	return updated, nil
+}
+
+func newPromptTemplateVersion(prompt *models.PromptTemplate, author uuid.UUID) *models.PromptTemplateVersion {
+	return &models.PromptTemplateVersion{
+		PromptID:    prompt.ID,
+		Version:     prompt.Version,
+		Description: prompt.Description,
+		Template:    prompt.Template,
+		Variables:   prompt.Variables,
+		CreatedBy:   &author,
+		CreatedAt:   prompt.UpdatedAt,
+	}
+}
+
+func (s *promptTemplateService) DeletePromptTemplate(ctx context.Context, userIdpId uuid.UUID, orgName string, promptId uuid.UUID) error {
+	s.logger.InfoContext(ctx, "Deleting prompt", "promptId", promptId, "orgName", orgName, "userIdpId", userIdpId)
+	org, err := s.getOrganization(ctx, userIdpId, orgName)
+	if err != nil {
+		return err
+	}
+
+	agents, err := s.ManagedAgentRepository.ListManagedAgentsByPrompt(ctx, org.ID, promptId)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to list agents referencing prompt", "promptId", promptId, "orgId", org.ID, "error", err)
+		return fmt.Errorf("failed to list agents referencing prompt %s: %w", promptId, err)
+	}
+	if len(agents) > 0 {
+		dependents := make([]utils.DependentResource, 0, len(agents))
+		for _, agent := range agents {
+			dependents = append(dependents, utils.DependentResource{Kind: "agent", ID: agent.ID.String(), Name: agent.Name})
+		}
+		s.logger.WarnContext(ctx, "Prompt is referenced by agents", "promptId", promptId, "orgId", org.ID, "agents", len(agents))
+		return &utils.DependentsError{Err: utils.ErrPromptInUse, Dependents: dependents}
+	}
+
+	deleted, err := s.PromptTemplateRepository.DeletePromptTemplate(ctx, org.ID, promptId)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to delete prompt", "promptId", promptId, "orgId", org.ID, "error", err)
+		return fmt.Errorf("failed to delete prompt %s: %w", promptId, err)
+	}
+	if !deleted {
+		return utils.ErrPromptNotFound
+	}
+	s.logger.InfoContext(ctx, "Prompt deleted successfully", "promptId", promptId, "orgName", orgName)
+	return nil
+}
+
+func (s *promptTemplateService) RestorePromptTemplate(ctx context.Context, userIdpId uuid.UUID, orgName string, promptId uuid.UUID) (*models.PromptTemplate, error) {
+	s.logger.InfoContext(ctx, "Restoring prompt", "promptId", promptId, "orgName", orgName, "userIdpId", userIdpId)
+	org, err := s.getOrganization(ctx, userIdpId, orgName)
+	if err != nil {
+		return nil, err
+	}
+
+	var restored *models.PromptTemplate
+	err = db.WithTx(ctx, func(txCtx context.Context) error {
+		ok, err := s.PromptTemplateRepository.RestorePromptTemplate(txCtx, org.ID, promptId)
+		if err != nil {
+			// Another prompt took the name after this one was deleted
+			if db.IsUniqueViolationError(err) {
+				return utils.ErrPromptAlreadyExists
+			}
+			return fmt.Errorf("failed to restore prompt %s: %w", promptId, err)
+		}
+		if !ok {
+			return utils.ErrPromptNotFound
+		}
+		restored, err = s.getPromptTemplate(txCtx, org.ID, promptId)
+		return err
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to restore prompt", "promptId", promptId, "orgId", org.ID, "error", err)
+		return nil, err
+	}
+	s.logger.InfoContext(ctx, "Prompt restored successfully", "promptId", promptId, "orgName", orgName)
+	return restored, nil
+}
+
+func (s *promptTemplateService) ListPromptTemplateVersions(ctx context.Context, userIdpId uuid.UUID, orgName string, promptId uuid.UUID, limit int, offset int) ([]*models.PromptTemplateVersion, int32, error) {
+	s.logger.InfoContext(ctx, "Listing prompt versions", "promptId", promptId, "orgName", orgName, "limit", limit, "offset", offset, "userIdpId", userIdpId)
+	org, err := s.getOrganization(ctx, userIdpId, orgName)
+	if err != nil {
+		return nil, 0, err
+	}
+	if _, err := s.getPromptTemplate(ctx, org.ID, promptId); err != nil {
+		return nil, 0, err
+	}
+	versions, total, err := s.PromptTemplateRepository.ListPromptTemplateVersions(ctx, promptId, limit, offset)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to list prompt versions", "promptId", promptId, "error", err)
+		return nil, 0, fmt.Errorf("failed to list versions of prompt %s: %w", promptId, err)
+	}
+	return versions, int32(total), nil
+}
+
+func (s *promptTemplateService) GetPromptTemplateVersion(ctx context.Context, userIdpId uuid.UUID, orgName string, promptId uuid.UUID, version int32) (*models.PromptTemplateVersion, error) {
+	s.logger.InfoContext(ctx, "Getting prompt version", "promptId", promptId, "version", version, "orgName", orgName, "userIdpId", userIdpId)
+	org, err := s.getOrganization(ctx, userIdpId, orgName)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.getPromptTemplate(ctx, org.ID, promptId); err != nil {
+		return nil, err
+	}
+	return s.getPromptTemplateVersion(ctx, promptId, version)
+}
+
+func (s *promptTemplateService) RenderPromptTemplate(ctx context.Context, userIdpId uuid.UUID, orgName string, promptId uuid.UUID, req *models.PromptRenderRequest) (*models.PromptTemplateVersion, string, error) {
+	s.logger.InfoContext(ctx, "Rendering prompt", "promptId", promptId, "orgName", orgName, "userIdpId", userIdpId)
+	org, err := s.getOrganization(ctx, userIdpId, orgName)
+	if err != nil {
+		return nil, "", err
+	}
+	prompt, err := s.getPromptTemplate(ctx, org.ID, promptId)
+	if err != nil {
+		return nil, "", err
+	}
+	version := prompt.Version
+	if req.Version != nil {
+		version = *req.Version
+	}
+	promptVersion, err := s.getPromptTemplateVersion(ctx, promptId, version)
+	if err != nil {
+		return nil, "", err
+	}
+	text, err := utils.RenderPromptTemplate(promptVersion.Template, promptVersion.Variables, req.Variables)
+	if err != nil {
+		return nil, "", err
+	}
+	return promptVersion, text, nil
+}
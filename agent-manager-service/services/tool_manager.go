@@ -0,0 +1,336 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/db"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/models"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/repositories"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/utils"
+)
+
+type ToolService interface {
+	ListTools(ctx context.Context, userIdpId uuid.UUID, orgName string, filter models.ToolFilter) ([]*models.Tool, *models.ListCursor, int32, error)
+	GetTool(ctx context.Context, userIdpId uuid.UUID, orgName string, toolId uuid.UUID) (*models.Tool, error)
+	CreateTool(ctx context.Context, userIdpId uuid.UUID, orgName string, req *models.ToolRequest) (*models.Tool, error)
+	// UpdateTool replaces the tool, when expectedVersion is set the tool must still be at that version
+	UpdateTool(ctx context.Context, userIdpId uuid.UUID, orgName string, toolId uuid.UUID, req *models.ToolRequest, expectedVersion *int32) (*models.Tool, error)
+	// PatchTool applies a JSON merge patch to the tool, when expectedVersion is set the tool must still be at that version
+	PatchTool(ctx context.Context, userIdpId uuid.UUID, orgName string, toolId uuid.UUID, patch map[string]interface{}, expectedVersion *int32) (*models.Tool, error)
+	// DeleteTool fails with a utils.DependentsError while agents reference the tool
+	DeleteTool(ctx context.Context, userIdpId uuid.UUID, orgName string, toolId uuid.UUID) error
+}
+
+type toolService struct {
+	OrganizationRepository repositories.OrganizationRepository
+	ToolRepository         repositories.ToolRepository
+	ManagedAgentRepository repositories.ManagedAgentRepository
+	CredentialRepository   repositories.CredentialRepository
+	logger                 *slog.Logger
+}
+
+func NewToolService(
+	orgRepo repositories.OrganizationRepository,
+	toolRepo repositories.ToolRepository,
+	managedAgentRepo repositories.ManagedAgentRepository,
+	credentialRepo repositories.CredentialRepository,
+	logger *slog.Logger,
+) ToolService {
+	return &toolService{
+		OrganizationRepository: orgRepo,
+		ToolRepository:         toolRepo,
+		ManagedAgentRepository: managedAgentRepo,
+		CredentialRepository:   credentialRepo,
+		logger:                 logger,
+	}
+}
+
+func (s *toolService) getOrganization(ctx context.Context, userIdpId uuid.UUID, orgName string) (*models.Organization, error) {
+	org, err := s.OrganizationRepository.GetOrganizationByOrgName(ctx, userIdpId, orgName)
+	if err != nil {
+		if db.IsRecordNotFoundError(err) {
+			s.logger.ErrorContext(ctx, "Organization not found", "orgName", orgName, "userIdpId", userIdpId)
+			return nil, utils.ErrOrganizationNotFound
+		}
+		s.logger.ErrorContext(ctx, "Failed to find organization", "orgName", orgName, "userIdpId", userIdpId, "error", err)
+		return nil, fmt.Errorf("failed to find organization %s: %w", orgName, err)
+	}
+	return org, nil
+}
+
+func (s *toolService) getTool(ctx context.Context, orgId uuid.UUID, toolId uuid.UUID) (*models.Tool, error) {
+	tool, err := s.ToolRepository.GetToolById(ctx, orgId, toolId)
+	if err != nil {
+		if db.IsRecordNotFoundError(err) {
+			return nil, utils.ErrToolNotFound
+		}
+		s.logger.ErrorContext(ctx, "Failed to find tool", "toolId", toolId, "orgId", orgId, "error", err)
+		return nil, fmt.Errorf("failed to find tool %s: %w", toolId, err)
+	}
+	return tool, nil
+}
+
+// ensureNameAvailable fails with ErrToolAlreadyExists when another tool of the organization has the name
+func (s *toolService) ensureNameAvailable(ctx context.Context, orgId uuid.UUID, name string, toolId uuid.UUID) error {
+	existing, err := s.ToolRepository.GetToolByName(ctx, orgId, name)
+	if err != nil {
+		if db.IsRecordNotFoundError(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to check tool name %s: %w", name, err)
+	}
+	if existing.ID != toolId {
+		return utils.ErrToolAlreadyExists
+	}
+	return nil
+}
+
+// ensureCredentialExists rejects endpoints authenticating with a credential the organization does not have
+func (s *toolService) ensureCredentialExists(ctx context.Context, orgId uuid.UUID, endpoint models.ToolEndpoint) error {
+	exists, err := toolCredentialExists(ctx, s.CredentialRepository, orgId, endpoint)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		errs := &utils.ValidationError{}
+		errs.Add("endpoint.auth.credentialId", "credential %s does not exist in the organization", endpoint.Auth.CredentialID)
+		return errs
+	}
+	return nil
+}
+
+// toolCredentialExists reports whether the credential a tool endpoint authenticates with belongs to the organization,
+// endpoints without a credential always pass
+func toolCredentialExists(ctx context.Context, credentialRepo repositories.CredentialRepository, orgId uuid.UUID, endpoint models.ToolEndpoint) (bool, error) {
+	if endpoint.Auth == nil {
+		return true, nil
+	}
+	credentialId, err := uuid.Parse(endpoint.Auth.CredentialID)
+	if err != nil {
+		return false, nil
+	}
+	if _, err := credentialRepo.GetCredentialById(ctx, orgId, credentialId); err != nil {
+		if db.IsRecordNotFoundError(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to find credential %s: %w", credentialId, err)
+	}
+	return true, nil
+}
+
+func (s *toolService) ListTools(ctx context.Context, userIdpId uuid.UUID, orgName string, filter models.ToolFilter) ([]*models.Tool, *models.ListCursor, int32, error) {
+	s.logger.InfoContext(ctx, "Listing tools", "orgName", orgName, "search", filter.Search, "tags", filter.Tags, "sort", filter.Page.Sort, "sortOrder", filter.Page.Order, "limit", filter.Page.Limit, "offset", filter.Page.Offset, "cursor", filter.Page.Cursor != nil, "userIdpId", userIdpId)
+	org, err := s.getOrganization(ctx, userIdpId, orgName)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	tools, next, total, err := s.ToolRepository.ListTools(ctx, org.ID, filter)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to list tools", "orgId", org.ID, "error", err)
+		return nil, nil, 0, fmt.Errorf("failed to list tools: %w", err)
+	}
+	return tools, next, int32(total), nil
+}
+
+func (s *toolService) GetTool(ctx context.Context, userIdpId uuid.UUID, orgName string, toolId uuid.UUID) (*models.Tool, error) {
+	s.logger.InfoContext(ctx, "Getting tool", "toolId", toolId, "orgName", orgName, "userIdpId", userIdpId)
+	org, err := s.getOrganization(ctx, userIdpId, orgName)
+	if err != nil {
+		return nil, err
+	}
+	return s.getTool(ctx, org.ID, toolId)
+}
+
+func (s *toolService) CreateTool(ctx context.Context, userIdpId uuid.UUID, orgName string, req *models.ToolRequest) (*models.Tool, error) {
+	s.logger.InfoContext(ctx, "Creating tool", "toolName", req.Name, "orgName", orgName, "userIdpId", userIdpId)
+	org, err := s.getOrganization(ctx, userIdpId, orgName)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.ensureNameAvailable(ctx, org.ID, req.Name, uuid.Nil); err != nil {
+		return nil, err
+	}
+	if err := s.ensureCredentialExists(ctx, org.ID, req.Endpoint); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	tool := &models.Tool{
+		ID:          uuid.New(),
+		OrgID:       org.ID,
+		Name:        req.Name,
+		Description: req.Description,
+		Parameters:  req.Parameters,
+		Endpoint:    req.Endpoint,
+		Tags:        req.Tags,
+		Labels:      req.Labels,
+		Version:     1,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := s.ToolRepository.CreateTool(ctx, tool); err != nil {
+		if db.IsUniqueViolationError(err) {
+			return nil, utils.ErrToolAlreadyExists
+		}
+		s.logger.ErrorContext(ctx, "Failed to create tool", "toolName", req.Name, "orgId", org.ID, "error", err)
+		return nil, fmt.Errorf("failed to create tool %s: %w", req.Name, err)
+	}
+	s.logger.InfoContext(ctx, "Tool created successfully", "toolId", tool.ID, "toolName", tool.Name, "orgName", orgName)
+	return tool, nil
+}
+
+func (s *toolService) UpdateTool(ctx context.Context, userIdpId uuid.UUID, orgName string, toolId uuid.UUID, req *models.ToolRequest, expectedVersion *int32) (*models.Tool, error) {
+	s.logger.InfoContext(ctx, "Updating tool", "toolId", toolId, "orgName", orgName, "userIdpId", userIdpId)
+	return s.saveTool(ctx, userIdpId, orgName, toolId, expectedVersion, func(current *models.Tool) (*models.ToolRequest, error) {
+		return req, nil
+	})
+}
+
+func (s *toolService) PatchTool(ctx context.Context, userIdpId uuid.UUID, orgName string, toolId uuid.UUID, patch map[string]interface{}, expectedVersion *int32) (*models.Tool, error) {
+	s.logger.InfoContext(ctx, "Patching tool", "toolId", toolId, "orgName", orgName, "userIdpId", userIdpId)
+	return s.saveTool(ctx, userIdpId, orgName, toolId, expectedVersion, func(current *models.Tool) (*models.ToolRequest, error) {
+		var req models.ToolRequest
+		if err := utils.ApplyMergePatch(utils.ToolConfig(current), patch, &req); err != nil {
+			return nil, err
+		}
+		if err := utils.ValidateToolRequest(req); err != nil {
+			return nil, err
+		}
+		return &req, nil
+	})
+}
+
+// saveTool replaces the definition of a tool with the one returned by next
+func (s *toolService) saveTool(
+	ctx context.Context,
+	userIdpId uuid.UUID,
+	orgName string,
+	toolId uuid.UUID,
+	expectedVersion *int32,
+	next func(current *models.Tool) (*models.ToolRequest, error),
+) (*models.Tool, error) {
+	org, err := s.getOrganization(ctx, userIdpId, orgName)
+	if err != nil {
+		return nil, err
+	}
+
+	var before, updated *models.Tool
+	err = db.WithTx(ctx, func(txCtx context.Context) error {
+		current, err := s.getTool(txCtx, org.ID, toolId)
+		if err != nil {
+			return err
+		}
+		before = current
+		if expectedVersion != nil && *expectedVersion != current.Version {
+			return &utils.VersionMismatchError{Err: utils.ErrToolVersionMismatch, Current: current.Version, Resource: utils.ConvertToToolResponse(current)}
+		}
+		req, err := next(current)
+		if err != nil {
+			return err
+		}
+		if req.Name != current.Name {
+			if err := s.ensureNameAvailable(txCtx, org.ID, req.Name, toolId); err != nil {
+				return err
+			}
+		}
+		if err := s.ensureCredentialExists(txCtx, org.ID, req.Endpoint); err != nil {
+			return err
+		}
+
+		tool := &models.Tool{
+			ID:          current.ID,
+			OrgID:       current.OrgID,
+			Name:        req.Name,
+			Description: req.Description,
+			Parameters:  req.Parameters,
+			Endpoint:    req.Endpoint,
+			Tags:        req.Tags,
+			Labels:      req.Labels,
+			CreatedAt:   current.CreatedAt,
+			UpdatedAt:   time.Now(),
+		}
+		// The version check in the update guards against writes that happened after the read above
+		ok, err := s.ToolRepository.UpdateTool(txCtx, tool, current.Version)
+		if err != nil {
+			if db.IsUniqueViolationError(err) {
+				return utils.ErrToolAlreadyExists
+			}
+			return fmt.Errorf("failed to update tool %s: %w", toolId, err)
+		}
+		if !ok {
+			// A concurrent write won, report the version it left
+			latest, err := s.getTool(txCtx, org.ID, toolId)
+			if err != nil {
+				return err
+			}
+			return &utils.VersionMismatchError{Err: utils.ErrToolVersionMismatch, Current: latest.Version, Resource: utils.ConvertToToolResponse(latest)}
+		}
+		updated = tool
+		return nil
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to update tool", "toolId", toolId, "orgId", org.ID, "error", err)
+		return nil, err
+	}
+	recordAuditChanges(ctx, s.logger, utils.ConvertToToolResponse(before), utils.ConvertToToolResponse(updated))
+	s.logger.InfoContext(ctx, "Tool updated successfully", "toolId", toolId, "version", updated.Version, "orgName", orgName)
+	return updated, nil
+}
+
+func (s *toolService) DeleteTool(ctx context.Context, userIdpId uuid.UUID, orgName string, toolId uuid.UUID) error {
+	s.logger.InfoContext(ctx, "Deleting tool", "toolId", toolId, "orgName", orgName, "userIdpId", userIdpId)
+	org, err := s.getOrganization(ctx, userIdpId, orgName)
+	if err != nil {
+		return err
+	}
+
+	err = db.WithTx(ctx, func(txCtx context.Context) error {
+		agents, err := s.ManagedAgentRepository.ListManagedAgentsByTool(txCtx, org.ID, toolId)
+		if err != nil {
+			return fmt.Errorf("failed to list agents referencing tool %s: %w", toolId, err)
+		}
+		if len(agents) > 0 {
+			dependents := make([]utils.DependentResource, 0, len(agents))
+			for _, agent := range agents {
+				dependents = append(dependents, utils.DependentResource{Kind: "agent", ID: agent.ID.String(), Name: agent.Name})
+			}
+			return &utils.DependentsError{Err: utils.ErrToolInUse, Dependents: dependents}
+		}
+
+		deleted, err := s.ToolRepository.DeleteTool(txCtx, org.ID, toolId)
+		if err != nil {
+			return fmt.Errorf("failed to delete tool %s: %w", toolId, err)
+		}
+		if !deleted {
+			return utils.ErrToolNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to delete tool", "toolId", toolId, "orgId", org.ID, "error", err)
+		return err
+	}
+	s.logger.InfoContext(ctx, "Tool deleted successfully", "toolId", toolId, "orgName", orgName)
+	return nil
+}
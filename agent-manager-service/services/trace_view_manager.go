@@ -0,0 +1,164 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/db"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/models"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/repositories"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/utils"
+)
+
+type TraceViewService interface {
+	// ListTraceViews lists the views the user saved and those shared with the organization
+	ListTraceViews(ctx context.Context, userIdpId uuid.UUID, orgName string, limit int, offset int) ([]*models.TraceView, int32, error)
+	GetTraceView(ctx context.Context, userIdpId uuid.UUID, orgName string, viewId uuid.UUID) (*models.TraceView, error)
+	CreateTraceView(ctx context.Context, userIdpId uuid.UUID, orgName string, req *models.TraceViewRequest) (*models.TraceView, error)
+	// DeleteTraceView deletes a view the user saved, views shared by others can only be deleted by them
+	DeleteTraceView(ctx context.Context, userIdpId uuid.UUID, orgName string, viewId uuid.UUID) error
+}
+
+type traceViewService struct {
+	OrganizationRepository repositories.OrganizationRepository
+	TraceViewRepository    repositories.TraceViewRepository
+	logger                 *slog.Logger
+}
+
+func NewTraceViewService(
+	orgRepo repositories.OrganizationRepository,
+	traceViewRepo repositories.TraceViewRepository,
+	logger *slog.Logger,
+) TraceViewService {
+	return &traceViewService{
+		OrganizationRepository: orgRepo,
+		TraceViewRepository:    traceViewRepo,
+		logger:                 logger,
+	}
+}
+
+func (s *traceViewService) getOrganization(ctx context.Context, userIdpId uuid.UUID, orgName string) (*models.Organization, error) {
+	org, err := s.OrganizationRepository.GetOrganizationByOrgName(ctx, userIdpId, orgName)
+	if err != nil {
+		if db.IsRecordNotFoundError(err) {
+			s.logger.ErrorContext(ctx, "Organization not found", "orgName", orgName, "userIdpId", userIdpId)
+			return nil, utils.ErrOrganizationNotFound
+		}
+		s.logger.ErrorContext(ctx, "Failed to find organization", "orgName", orgName, "userIdpId", userIdpId, "error", err)
+		return nil, fmt.Errorf("failed to find organization %s: %w", orgName, err)
+	}
+	return org, nil
+}
+
+func (s *traceViewService) getTraceView(ctx context.Context, orgId uuid.UUID, userIdpId uuid.UUID, viewId uuid.UUID) (*models.TraceView, error) {
+	view, err := s.TraceViewRepository.GetTraceView(ctx, orgId, userIdpId, viewId)
+	if err != nil {
+		if db.IsRecordNotFoundError(err) {
+			return nil, utils.ErrTraceViewNotFound
+		}
+		s.logger.ErrorContext(ctx, "Failed to find trace view", "viewId", viewId, "orgId", orgId, "error", err)
+		return nil, fmt.Errorf("failed to find trace view %s: %w", viewId, err)
+	}
+	return view, nil
+}
+
+func (s *traceViewService) ListTraceViews(ctx context.Context, userIdpId uuid.UUID, orgName string, limit int, offset int) ([]*models.TraceView, int32, error) {
+	s.logger.InfoContext(ctx, "Listing trace views", "orgName", orgName, "limit", limit, "offset", offset, "userIdpId", userIdpId)
+	org, err := s.getOrganization(ctx, userIdpId, orgName)
+	if err != nil {
+		return nil, 0, err
+	}
+	views, total, err := s.TraceViewRepository.ListTraceViews(ctx, org.ID, userIdpId, limit, offset)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to list trace views", "orgName", orgName, "error", err)
+		return nil, 0, fmt.Errorf("failed to list trace views: %w", err)
+	}
+	return views, int32(total), nil
+}
+
+func (s *traceViewService) GetTraceView(ctx context.Context, userIdpId uuid.UUID, orgName string, viewId uuid.UUID) (*models.TraceView, error) {
+	org, err := s.getOrganization(ctx, userIdpId, orgName)
+	if err != nil {
+		return nil, err
+	}
+	return s.getTraceView(ctx, org.ID, userIdpId, viewId)
+}
+
+func (s *traceViewService) CreateTraceView(ctx context.Context, userIdpId uuid.UUID, orgName string, req *models.TraceViewRequest) (*models.TraceView, error) {
+	s.logger.InfoContext(ctx, "Creating trace view", "orgName", orgName, "viewName", req.Name, "userIdpId", userIdpId)
+	org, err := s.getOrganization(ctx, userIdpId, orgName)
+	if err != nil {
+		return nil, err
+	}
+
+	visibility := req.Visibility
+	if visibility == "" {
+		visibility = utils.TraceViewVisibilityPrivate
+	}
+	view := &models.TraceView{
+		ID:         uuid.New(),
+		OrgID:      org.ID,
+		CreatedBy:  userIdpId,
+		Name:       strings.TrimSpace(req.Name),
+		Visibility: visibility,
+		Filters:    req.Filters,
+		Sort:       req.Sort,
+		SortOrder:  req.SortOrder,
+		CreatedAt:  time.Now(),
+	}
+	if view.Filters == nil {
+		view.Filters = map[string]string{}
+	}
+	if err := s.TraceViewRepository.CreateTraceView(ctx, view); err != nil {
+		if db.IsUniqueViolationError(err) {
+			return nil, utils.ErrTraceViewAlreadyExists
+		}
+		s.logger.ErrorContext(ctx, "Failed to create trace view", "viewName", view.Name, "orgName", orgName, "error", err)
+		return nil, fmt.Errorf("failed to create trace view %s: %w", view.Name, err)
+	}
+	s.logger.InfoContext(ctx, "Trace view created successfully", "viewId", view.ID, "viewName", view.Name, "orgName", orgName)
+	return view, nil
+}
+
+func (s *traceViewService) DeleteTraceView(ctx context.Context, userIdpId uuid.UUID, orgName string, viewId uuid.UUID) error {
+	s.logger.InfoContext(ctx, "Deleting trace view", "viewId", viewId, "orgName", orgName, "userIdpId", userIdpId)
+	org, err := s.getOrganization(ctx, userIdpId, orgName)
+	if err != nil {
+		return err
+	}
+	deleted, err := s.TraceViewRepository.DeleteTraceView(ctx, org.ID, userIdpId, viewId)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to delete trace view", "viewId", viewId, "error", err)
+		return fmt.Errorf("failed to delete trace view %s: %w", viewId, err)
+	}
+	if !deleted {
+		// Views shared by others are visible but not theirs to delete
+		if _, err := s.getTraceView(ctx, org.ID, userIdpId, viewId); err != nil {
+			return err
+		}
+		return utils.ErrPermissionDenied
+	}
+	s.logger.InfoContext(ctx, "Trace view deleted successfully", "viewId", viewId, "orgName", orgName)
+	return nil
+}
@@ -0,0 +1,91 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/repositories"
+)
+
+type TrashService interface {
+	// PurgeDeleted removes the agents and prompts deleted longer ago than the retention, they can no longer be restored.
+	// Prompts still referenced by a deleted agent are kept until the agent is purged
+	PurgeDeleted(ctx context.Context) error
+	// RunPurger purges deleted resources every interval until ctx is done
+	RunPurger(ctx context.Context, interval time.Duration)
+}
+
+type trashService struct {
+	ManagedAgentRepository   repositories.ManagedAgentRepository
+	PromptTemplateRepository repositories.PromptTemplateRepository
+	logger                   *slog.Logger
+	// How long deleted resources can be restored
+	retention time.Duration
+}
+
+func NewTrashService(
+	managedAgentRepo repositories.ManagedAgentRepository,
+	promptTemplateRepo repositories.PromptTemplateRepository,
+	logger *slog.Logger,
+	retention time.Duration,
+) TrashService {
+	return &trashService{
+		ManagedAgentRepository:   managedAgentRepo,
+		PromptTemplateRepository: promptTemplateRepo,
+		logger:                   logger,
+		retention:                retention,
+	}
+}
+
+func (s *trashService) PurgeDeleted(ctx context.Context) error {
+	deletedBefore := time.Now().Add(-s.retention)
+	// Agents go first, so the prompts only they referenced are purged in the same run
+	agents, err := s.ManagedAgentRepository.PurgeDeletedManagedAgents(ctx, deletedBefore)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to purge deleted managed agents", "error", err)
+		return fmt.Errorf("failed to purge deleted managed agents: %w", err)
+	}
+	if agents > 0 {
+		s.logger.InfoContext(ctx, "Purged deleted managed agents", "count", agents)
+	}
+	prompts, err := s.PromptTemplateRepository.PurgeDeletedPromptTemplates(ctx, deletedBefore)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to purge deleted prompts", "error", err)
+		return fmt.Errorf("failed to purge deleted prompts: %w", err)
+	}
+	if prompts > 0 {
+		s.logger.InfoContext(ctx, "Purged deleted prompts", "count", prompts)
+	}
+	return nil
+}
+
+func (s *trashService) RunPurger(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			_ = s.PurgeDeleted(ctx)
+		}
+	}
+}
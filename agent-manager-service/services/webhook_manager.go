@@ -0,0 +1,622 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/db"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/models"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/repositories"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/utils"
+)
+
+// How often the dispatcher purges finished deliveries past their retention
+const webhookDeliveryPurgeInterval = time.Hour
+
+// Most of a webhook response body read before the connection is released, the body itself is not kept
+const maxWebhookResponseBytes = 64 << 10
+
+type WebhookService interface {
+	ListWebhooks(ctx context.Context, userIdpId uuid.UUID, orgName string, page models.ListPage) ([]*models.Webhook, *models.ListCursor, int32, error)
+	GetWebhook(ctx context.Context, userIdpId uuid.UUID, orgName string, webhookId uuid.UUID) (*models.Webhook, error)
+	CreateWebhook(ctx context.Context, userIdpId uuid.UUID, orgName string, req *models.WebhookRequest) (*models.Webhook, error)
+	// UpdateWebhook replaces the URL, description and events of a webhook, and its secret and active flag when
+	// the request carries them
+	UpdateWebhook(ctx context.Context, userIdpId uuid.UUID, orgName string, webhookId uuid.UUID, req *models.WebhookRequest) (*models.Webhook, error)
+	// PatchWebhook applies a JSON merge patch to the subscription of a webhook, a secret in the patch replaces the
+	// current one
+	PatchWebhook(ctx context.Context, userIdpId uuid.UUID, orgName string, webhookId uuid.UUID, patch map[string]interface{}) (*models.Webhook, error)
+	// DeleteWebhook removes the webhook together with its deliveries, pending ones are never sent
+	DeleteWebhook(ctx context.Context, userIdpId uuid.UUID, orgName string, webhookId uuid.UUID) error
+	ListWebhookDeliveries(ctx context.Context, userIdpId uuid.UUID, orgName string, webhookId uuid.UUID, limit int, offset int) ([]*models.WebhookDelivery, int32, error)
+	// RedeliverWebhookDelivery queues a failed delivery again as a new delivery with the same event
+	RedeliverWebhookDelivery(ctx context.Context, userIdpId uuid.UUID, orgName string, webhookId uuid.UUID, deliveryId uuid.UUID) (*models.WebhookDelivery, error)
+	// TestWebhook sends a ping event to the webhook right away, once and whether or not it is active
+	TestWebhook(ctx context.Context, userIdpId uuid.UUID, orgName string, webhookId uuid.UUID) (*models.WebhookDelivery, error)
+	// PublishEvent queues the event for every active webhook of the organization subscribed to it. It joins the
+	// transaction of ctx, so events of changes rolled back are never sent, and failures are logged without failing the change
+	PublishEvent(ctx context.Context, org *models.Organization, eventType string, data interface{})
+	// DeliverDue sends the deliveries that are due, retrying failures with exponential backoff
+	DeliverDue(ctx context.Context) error
+	// RunDispatcher delivers due events every interval and purges finished deliveries past their retention until ctx is done
+	RunDispatcher(ctx context.Context, interval time.Duration)
+}
+
+// WebhookDispatchConfig tunes how webhook events are delivered
+type WebhookDispatchConfig struct {
+	// How long a webhook has to answer a delivery
+	Timeout time.Duration
+	// Attempts made before a delivery fails
+	MaxAttempts int
+	// Delay after the first failed attempt, doubling after each further one up to RetryMax
+	RetryBase time.Duration
+	RetryMax  time.Duration
+	// Deliveries sent by one dispatcher run
+	BatchSize int
+	// How long finished deliveries can be queried
+	DeliveryRetention time.Duration
+	// Whether webhooks may target localhost and loopback, private or link-local addresses
+	AllowPrivateNetworks bool
+}
+
+type webhookService struct {
+	OrganizationRepository repositories.OrganizationRepository
+	WebhookRepository      repositories.WebhookRepository
+	// cipher is nil when no encryption keys are configured
+	cipher     *utils.CredentialCipher
+	httpClient *http.Client
+	logger     *slog.Logger
+	config     WebhookDispatchConfig
+}
+
+func NewWebhookService(
+	orgRepo repositories.OrganizationRepository,
+	webhookRepo repositories.WebhookRepository,
+	cipher *utils.CredentialCipher,
+	httpClient *http.Client,
+	logger *slog.Logger,
+	config WebhookDispatchConfig,
+) WebhookService {
+	return &webhookService{
+		OrganizationRepository: orgRepo,
+		WebhookRepository:      webhookRepo,
+		cipher:                 cipher,
+		httpClient:             httpClient,
+		logger:                 logger,
+		config:                 config,
+	}
+}
+
+func (s *webhookService) getOrganization(ctx context.Context, userIdpId uuid.UUID, orgName string) (*models.Organization, error) {
+	org, err := s.OrganizationRepository.GetOrganizationByOrgName(ctx, userIdpId, orgName)
+	if err != nil {
+		if db.IsRecordNotFoundError(err) {
+			s.logger.ErrorContext(ctx, "Organization not found", "orgName", orgName, "userIdpId", userIdpId)
+			return nil, utils.ErrOrganizationNotFound
+		}
+		s.logger.ErrorContext(ctx, "Failed to find organization", "orgName", orgName, "userIdpId", userIdpId, "error", err)
+		return nil, fmt.Errorf("failed to find organization %s: %w", orgName, err)
+	}
+	return org, nil
+}
+
+func (s *webhookService) getWebhook(ctx context.Context, orgId uuid.UUID, webhookId uuid.UUID) (*models.Webhook, error) {
+	webhook, err := s.WebhookRepository.GetWebhookById(ctx, orgId, webhookId)
+	if err != nil {
+		if db.IsRecordNotFoundError(err) {
+			return nil, utils.ErrWebhookNotFound
+		}
+		s.logger.ErrorContext(ctx, "Failed to find webhook", "webhookId", webhookId, "orgId", orgId, "error", err)
+		return nil, fmt.Errorf("failed to find webhook %s: %w", webhookId, err)
+	}
+	return webhook, nil
+}
+
+// seal encrypts the secret into the webhook
+func (s *webhookService) seal(webhook *models.Webhook, secret string) error {
+	sealed, err := s.cipher.Seal(webhook.ID, secret)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt secret of webhook %s: %w", webhook.ID, err)
+	}
+	webhook.KeyID = sealed.KeyID
+	webhook.EncryptedKey = sealed.EncryptedKey
+	webhook.EncryptedSecret = sealed.EncryptedValue
+	return nil
+}
+
+func sealedWebhookSecret(webhook *models.Webhook) utils.SealedCredential {
+	return utils.SealedCredential{
+		KeyID:          webhook.KeyID,
+		EncryptedKey:   webhook.EncryptedKey,
+		EncryptedValue: webhook.EncryptedSecret,
+	}
+}
+
+func (s *webhookService) ListWebhooks(ctx context.Context, userIdpId uuid.UUID, orgName string, page models.ListPage) ([]*models.Webhook, *models.ListCursor, int32, error) {
+	s.logger.InfoContext(ctx, "Listing webhooks", "orgName", orgName, "sort", page.Sort, "sortOrder", page.Order, "limit", page.Limit, "offset", page.Offset, "cursor", page.Cursor != nil, "userIdpId", userIdpId)
+	org, err := s.getOrganization(ctx, userIdpId, orgName)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+	webhooks, next, total, err := s.WebhookRepository.ListWebhooks(ctx, org.ID, page)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to list webhooks", "orgId", org.ID, "error", err)
+		return nil, nil, 0, fmt.Errorf("failed to list webhooks: %w", err)
+	}
+	return webhooks, next, int32(total), nil
+}
+
+func (s *webhookService) GetWebhook(ctx context.Context, userIdpId uuid.UUID, orgName string, webhookId uuid.UUID) (*models.Webhook, error) {
+	s.logger.InfoContext(ctx, "Getting webhook", "webhookId", webhookId, "orgName", orgName, "userIdpId", userIdpId)
+	org, err := s.getOrganization(ctx, userIdpId, orgName)
+	if err != nil {
+		return nil, err
+	}
+	return s.getWebhook(ctx, org.ID, webhookId)
+}
+
+func (s *webhookService) CreateWebhook(ctx context.Context, userIdpId uuid.UUID, orgName string, req *models.WebhookRequest) (*models.Webhook, error) {
+	s.logger.InfoContext(ctx, "Creating webhook", "url", req.URL, "events", req.Events, "orgName", orgName, "userIdpId", userIdpId)
+	if s.cipher == nil {
+		return nil, utils.ErrCredentialsNotConfigured
+	}
+	if err := s.checkTarget(req.URL); err != nil {
+		return nil, err
+	}
+	org, err := s.getOrganization(ctx, userIdpId, orgName)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	webhook := &models.Webhook{
+		ID:          uuid.New(),
+		OrgID:       org.ID,
+		URL:         req.URL,
+		Description: req.Description,
+		Events:      req.Events,
+		Active:      req.Active == nil || *req.Active,
+		CreatedBy:   &userIdpId,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := s.seal(webhook, req.Secret); err != nil {
+		return nil, err
+	}
+	if err := s.WebhookRepository.CreateWebhook(ctx, webhook); err != nil {
+		s.logger.ErrorContext(ctx, "Failed to create webhook", "url", req.URL, "orgId", org.ID, "error", err)
+		return nil, fmt.Errorf("failed to create webhook: %w", err)
+	}
+	s.logger.InfoContext(ctx, "Webhook created successfully", "webhookId", webhook.ID, "orgName", orgName)
+	return webhook, nil
+}
+
+func (s *webhookService) UpdateWebhook(ctx context.Context, userIdpId uuid.UUID, orgName string, webhookId uuid.UUID, req *models.WebhookRequest) (*models.Webhook, error) {
+	s.logger.InfoContext(ctx, "Updating webhook", "webhookId", webhookId, "orgName", orgName, "userIdpId", userIdpId)
+	return s.saveWebhook(ctx, userIdpId, orgName, webhookId, func(current *models.Webhook) (*models.WebhookRequest, error) {
+		return req, nil
+	})
+}
+
+func (s *webhookService) PatchWebhook(ctx context.Context, userIdpId uuid.UUID, orgName string, webhookId uuid.UUID, patch map[string]interface{}) (*models.Webhook, error) {
+	s.logger.InfoContext(ctx, "Patching webhook", "webhookId", webhookId, "orgName", orgName, "userIdpId", userIdpId)
+	return s.saveWebhook(ctx, userIdpId, orgName, webhookId, func(current *models.Webhook) (*models.WebhookRequest, error) {
+		var req models.WebhookRequest
+		if err := utils.ApplyMergePatch(utils.WebhookConfig(current), patch, &req); err != nil {
+			return nil, err
+		}
+		if err := utils.ValidateWebhookRequest(req, false); err != nil {
+			return nil, err
+		}
+		return &req, nil
+	})
+}
+
+// checkTarget rejects webhook URLs on non-public addresses unless private networks are allowed
+func (s *webhookService) checkTarget(rawURL string) error {
+	if s.config.AllowPrivateNetworks {
+		return nil
+	}
+	return utils.ValidateOutboundURL(utils.Field("url"), rawURL)
+}
+
+// saveWebhook replaces the subscription of a webhook with the one returned by next
+func (s *webhookService) saveWebhook(
+	ctx context.Context,
+	userIdpId uuid.UUID,
+	orgName string,
+	webhookId uuid.UUID,
+	next func(current *models.Webhook) (*models.WebhookRequest, error),
+) (*models.Webhook, error) {
+	org, err := s.getOrganization(ctx, userIdpId, orgName)
+	if err != nil {
+		return nil, err
+	}
+
+	var before, updated *models.Webhook
+	secretReplaced := false
+	err = db.WithTx(ctx, func(txCtx context.Context) error {
+		current, err := s.getWebhook(txCtx, org.ID, webhookId)
+		if err != nil {
+			return err
+		}
+		before = current
+		req, err := next(current)
+		if err != nil {
+			return err
+		}
+		if err := s.checkTarget(req.URL); err != nil {
+			return err
+		}
+		if req.Secret != "" && s.cipher == nil {
+			return utils.ErrCredentialsNotConfigured
+		}
+
+		webhook := *current
+		webhook.URL = req.URL
+		webhook.Description = req.Description
+		webhook.Events = req.Events
+		if req.Active != nil {
+			webhook.Active = *req.Active
+		}
+		webhook.UpdatedAt = time.Now()
+		if req.Secret != "" {
+			if err := s.seal(&webhook, req.Secret); err != nil {
+				return err
+			}
+		}
+		ok, err := s.WebhookRepository.UpdateWebhook(txCtx, &webhook)
+		if err != nil {
+			return fmt.Errorf("failed to update webhook %s: %w", webhookId, err)
+		}
+		if !ok {
+			return utils.ErrWebhookNotFound
+		}
+		updated = &webhook
+		secretReplaced = req.Secret != ""
+		return nil
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to update webhook", "webhookId", webhookId, "orgId", org.ID, "error", err)
+		return nil, err
+	}
+	recordAuditChanges(ctx, s.logger, utils.ConvertToWebhookResponse(before), utils.ConvertToWebhookResponse(updated))
+	s.logger.InfoContext(ctx, "Webhook updated successfully", "webhookId", webhookId, "secretReplaced", secretReplaced, "orgName", orgName)
+	return updated, nil
+}
+
+func (s *webhookService) DeleteWebhook(ctx context.Context, userIdpId uuid.UUID, orgName string, webhookId uuid.UUID) error {
+	s.logger.InfoContext(ctx, "Deleting webhook", "webhookId", webhookId, "orgName", orgName, "userIdpId", userIdpId)
+	org, err := s.getOrganization(ctx, userIdpId, orgName)
+	if err != nil {
+		return err
+	}
+	deleted, err := s.WebhookRepository.DeleteWebhook(ctx, org.ID, webhookId)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to delete webhook", "webhookId", webhookId, "orgId", org.ID, "error", err)
+		return fmt.Errorf("failed to delete webhook %s: %w", webhookId, err)
+	}
+	if !deleted {
+		return utils.ErrWebhookNotFound
+	}
+	s.logger.InfoContext(ctx, "Webhook deleted successfully", "webhookId", webhookId, "orgName", orgName)
+	return nil
+}
+
+func (s *webhookService) ListWebhookDeliveries(ctx context.Context, userIdpId uuid.UUID, orgName string, webhookId uuid.UUID, limit int, offset int) ([]*models.WebhookDelivery, int32, error) {
+	s.logger.InfoContext(ctx, "Listing webhook deliveries", "webhookId", webhookId, "orgName", orgName, "limit", limit, "offset", offset, "userIdpId", userIdpId)
+	org, err := s.getOrganization(ctx, userIdpId, orgName)
+	if err != nil {
+		return nil, 0, err
+	}
+	if _, err := s.getWebhook(ctx, org.ID, webhookId); err != nil {
+		return nil, 0, err
+	}
+	deliveries, total, err := s.WebhookRepository.ListWebhookDeliveries(ctx, webhookId, limit, offset)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to list webhook deliveries", "webhookId", webhookId, "error", err)
+		return nil, 0, fmt.Errorf("failed to list deliveries of webhook %s: %w", webhookId, err)
+	}
+	return deliveries, int32(total), nil
+}
+
+func (s *webhookService) RedeliverWebhookDelivery(ctx context.Context, userIdpId uuid.UUID, orgName string, webhookId uuid.UUID, deliveryId uuid.UUID) (*models.WebhookDelivery, error) {
+	s.logger.InfoContext(ctx, "Redelivering webhook delivery", "webhookId", webhookId, "deliveryId", deliveryId, "orgName", orgName, "userIdpId", userIdpId)
+	org, err := s.getOrganization(ctx, userIdpId, orgName)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.getWebhook(ctx, org.ID, webhookId); err != nil {
+		return nil, err
+	}
+	original, err := s.WebhookRepository.GetWebhookDelivery(ctx, webhookId, deliveryId)
+	if err != nil {
+		if db.IsRecordNotFoundError(err) {
+			return nil, utils.ErrWebhookDeliveryNotFound
+		}
+		return nil, fmt.Errorf("failed to find webhook delivery %s: %w", deliveryId, err)
+	}
+	if original.Status != utils.WebhookDeliveryStatusFailed {
+		return nil, utils.ErrWebhookDeliveryNotFailed
+	}
+
+	now := time.Now()
+	delivery := &models.WebhookDelivery{
+		ID:            uuid.New(),
+		WebhookID:     webhookId,
+		EventID:       original.EventID,
+		EventType:     original.EventType,
+		Payload:       original.Payload,
+		Status:        utils.WebhookDeliveryStatusPending,
+		Attempts:      []models.WebhookDeliveryAttempt{},
+		NextAttemptAt: &now,
+		RedeliveryOf:  &original.ID,
+		CreatedAt:     now,
+	}
+	if err := s.WebhookRepository.CreateWebhookDeliveries(ctx, []*models.WebhookDelivery{delivery}); err != nil {
+		s.logger.ErrorContext(ctx, "Failed to queue webhook redelivery", "webhookId", webhookId, "deliveryId", deliveryId, "error", err)
+		return nil, fmt.Errorf("failed to redeliver webhook delivery %s: %w", deliveryId, err)
+	}
+	s.logger.InfoContext(ctx, "Webhook delivery queued for redelivery", "webhookId", webhookId, "deliveryId", delivery.ID, "redeliveryOf", deliveryId)
+	return delivery, nil
+}
+
+func (s *webhookService) TestWebhook(ctx context.Context, userIdpId uuid.UUID, orgName string, webhookId uuid.UUID) (*models.WebhookDelivery, error) {
+	s.logger.InfoContext(ctx, "Testing webhook", "webhookId", webhookId, "orgName", orgName, "userIdpId", userIdpId)
+	if s.cipher == nil {
+		return nil, utils.ErrCredentialsNotConfigured
+	}
+	org, err := s.getOrganization(ctx, userIdpId, orgName)
+	if err != nil {
+		return nil, err
+	}
+	webhook, err := s.getWebhook(ctx, org.ID, webhookId)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := s.cipher.Open(webhook.ID, sealedWebhookSecret(webhook))
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to decrypt webhook secret", "webhookId", webhookId, "keyId", webhook.KeyID, "error", err)
+		return nil, err
+	}
+
+	event, payload, err := newWebhookEvent(org, utils.WebhookEventPing, map[string]string{"webhookId": webhook.ID.String()})
+	if err != nil {
+		return nil, err
+	}
+	// Never due, so the dispatcher leaves it alone while it is sent here
+	delivery := &models.WebhookDelivery{
+		ID:        uuid.New(),
+		WebhookID: webhook.ID,
+		EventID:   event,
+		EventType: utils.WebhookEventPing,
+		Payload:   payload,
+		Status:    utils.WebhookDeliveryStatusPending,
+		Attempts:  []models.WebhookDeliveryAttempt{},
+		CreatedAt: time.Now(),
+	}
+	if err := s.WebhookRepository.CreateWebhookDeliveries(ctx, []*models.WebhookDelivery{delivery}); err != nil {
+		return nil, fmt.Errorf("failed to create test delivery of webhook %s: %w", webhookId, err)
+	}
+	s.completeAttempt(delivery, s.send(ctx, webhook, secret, delivery), false)
+	if err := s.WebhookRepository.UpdateWebhookDelivery(ctx, delivery); err != nil {
+		return nil, fmt.Errorf("failed to record test delivery of webhook %s: %w", webhookId, err)
+	}
+	s.logger.InfoContext(ctx, "Webhook tested", "webhookId", webhookId, "deliveryId", delivery.ID, "status", delivery.Status)
+	return delivery, nil
+}
+
+// newWebhookEvent returns the id and the JSON body of a new event
+func newWebhookEvent(org *models.Organization, eventType string, data interface{}) (uuid.UUID, []byte, error) {
+	event := models.WebhookEvent{
+		ID:         uuid.New().String(),
+		Type:       eventType,
+		OccurredAt: time.Now().UTC(),
+		OrgName:    org.OrgName,
+		Data:       data,
+	}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return uuid.Nil, nil, fmt.Errorf("failed to encode %s event: %w", eventType, err)
+	}
+	return uuid.MustParse(event.ID), payload, nil
+}
+
+func (s *webhookService) PublishEvent(ctx context.Context, org *models.Organization, eventType string, data interface{}) {
+	// A savepoint when ctx carries a transaction, so a failure here cannot abort the change being published
+	err := db.WithTx(ctx, func(txCtx context.Context) error {
+		webhooks, err := s.WebhookRepository.ListSubscribedWebhooks(txCtx, org.ID, eventType)
+		if err != nil {
+			return fmt.Errorf("failed to list webhooks subscribed to %s: %w", eventType, err)
+		}
+		if len(webhooks) == 0 {
+			return nil
+		}
+		eventId, payload, err := newWebhookEvent(org, eventType, data)
+		if err != nil {
+			return err
+		}
+		now := time.Now()
+		deliveries := make([]*models.WebhookDelivery, 0, len(webhooks))
+		for _, webhook := range webhooks {
+			deliveries = append(deliveries, &models.WebhookDelivery{
+				ID:            uuid.New(),
+				WebhookID:     webhook.ID,
+				EventID:       eventId,
+				EventType:     eventType,
+				Payload:       payload,
+				Status:        utils.WebhookDeliveryStatusPending,
+				Attempts:      []models.WebhookDeliveryAttempt{},
+				NextAttemptAt: &now,
+				CreatedAt:     now,
+			})
+		}
+		return s.WebhookRepository.CreateWebhookDeliveries(txCtx, deliveries)
+	})
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to queue webhook event", "eventType", eventType, "orgId", org.ID, "error", err)
+	}
+}
+
+func (s *webhookService) DeliverDue(ctx context.Context) error {
+	now := time.Now()
+	// The lease outlasts the send, a dispatcher that dies mid batch leaves its deliveries due again afterwards
+	deliveries, err := s.WebhookRepository.ClaimDueWebhookDeliveries(ctx, now, now.Add(2*s.config.Timeout), s.config.BatchSize)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to claim due webhook deliveries", "error", err)
+		return fmt.Errorf("failed to claim due webhook deliveries: %w", err)
+	}
+	if len(deliveries) == 0 {
+		return nil
+	}
+
+	webhooks := map[uuid.UUID]*models.Webhook{}
+	var wg sync.WaitGroup
+	for _, delivery := range deliveries {
+		webhook, ok := webhooks[delivery.WebhookID]
+		if !ok {
+			webhook, err = s.WebhookRepository.GetWebhook(ctx, delivery.WebhookID)
+			if err != nil {
+				// Deleting the webhook removes its deliveries, anything else is retried once the lease runs out
+				s.logger.ErrorContext(ctx, "Failed to find webhook of delivery", "webhookId", delivery.WebhookID, "deliveryId", delivery.ID, "error", err)
+				continue
+			}
+			webhooks[delivery.WebhookID] = webhook
+		}
+		wg.Add(1)
+		go func(webhook *models.Webhook, delivery *models.WebhookDelivery) {
+			defer wg.Done()
+			s.deliver(ctx, webhook, delivery)
+		}(webhook, delivery)
+	}
+	wg.Wait()
+	return nil
+}
+
+// deliver makes the next attempt of a claimed delivery and records it
+func (s *webhookService) deliver(ctx context.Context, webhook *models.Webhook, delivery *models.WebhookDelivery) {
+	var attempt models.WebhookDeliveryAttempt
+	if s.cipher == nil {
+		attempt = models.WebhookDeliveryAttempt{AttemptedAt: time.Now(), Error: utils.ErrCredentialsNotConfigured.Error()}
+	} else if secret, err := s.cipher.Open(webhook.ID, sealedWebhookSecret(webhook)); err != nil {
+		s.logger.ErrorContext(ctx, "Failed to decrypt webhook secret", "webhookId", webhook.ID, "keyId", webhook.KeyID, "error", err)
+		attempt = models.WebhookDeliveryAttempt{AttemptedAt: time.Now(), Error: "failed to decrypt the webhook secret"}
+	} else {
+		attempt = s.send(ctx, webhook, secret, delivery)
+	}
+	s.completeAttempt(delivery, attempt, true)
+	if err := s.WebhookRepository.UpdateWebhookDelivery(ctx, delivery); err != nil {
+		s.logger.ErrorContext(ctx, "Failed to record webhook delivery attempt", "webhookId", webhook.ID, "deliveryId", delivery.ID, "error", err)
+		return
+	}
+	if delivery.Status == utils.WebhookDeliveryStatusFailed {
+		s.logger.WarnContext(ctx, "Webhook delivery failed", "webhookId", webhook.ID, "deliveryId", delivery.ID, "attempts", len(delivery.Attempts))
+	}
+}
+
+// send POSTs the delivery payload signed with the secret and reports how the webhook answered
+func (s *webhookService) send(ctx context.Context, webhook *models.Webhook, secret string, delivery *models.WebhookDelivery) models.WebhookDeliveryAttempt {
+	start := time.Now()
+	attempt := models.WebhookDeliveryAttempt{AttemptedAt: start}
+	reqCtx, cancel := context.WithTimeout(ctx, s.config.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(reqCtx, http.MethodPost, webhook.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		attempt.Error = err.Error()
+		return attempt
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(utils.WebhookSignatureHeader, utils.SignWebhookPayload(secret, delivery.Payload))
+	req.Header.Set(utils.WebhookEventHeader, delivery.EventType)
+	req.Header.Set(utils.WebhookDeliveryHeader, delivery.ID.String())
+
+	resp, err := s.httpClient.Do(req)
+	attempt.DurationMs = time.Since(start).Milliseconds()
+	if err != nil {
+		attempt.Error = err.Error()
+		return attempt
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxWebhookResponseBytes))
+	attempt.StatusCode = resp.StatusCode
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		attempt.Error = fmt.Sprintf("webhook answered with status %d", resp.StatusCode)
+	}
+	return attempt
+}
+
+// completeAttempt adds the attempt to the delivery and moves it on, a failed attempt is retried with backoff
+// while retry is set and attempts remain
+func (s *webhookService) completeAttempt(delivery *models.WebhookDelivery, attempt models.WebhookDeliveryAttempt, retry bool) {
+	delivery.Attempts = append(delivery.Attempts, attempt)
+	now := time.Now()
+	switch {
+	case attempt.Error == "":
+		delivery.Status = utils.WebhookDeliveryStatusSucceeded
+		delivery.NextAttemptAt = nil
+		delivery.CompletedAt = &now
+	case !retry || len(delivery.Attempts) >= s.config.MaxAttempts:
+		delivery.Status = utils.WebhookDeliveryStatusFailed
+		delivery.NextAttemptAt = nil
+		delivery.CompletedAt = &now
+	default:
+		next := now.Add(utils.WebhookRetryDelay(len(delivery.Attempts), s.config.RetryBase, s.config.RetryMax))
+		delivery.NextAttemptAt = &next
+	}
+}
+
+// purgeDeliveries removes finished deliveries past their retention
+func (s *webhookService) purgeDeliveries(ctx context.Context) {
+	purged, err := s.WebhookRepository.PurgeWebhookDeliveries(ctx, time.Now().Add(-s.config.DeliveryRetention))
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to purge webhook deliveries", "error", err)
+		return
+	}
+	if purged > 0 {
+		s.logger.InfoContext(ctx, "Purged webhook deliveries", "count", purged)
+	}
+}
+
+func (s *webhookService) RunDispatcher(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	var lastPurge time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			_ = s.DeliverDue(ctx)
+			if time.Since(lastPurge) >= webhookDeliveryPurgeInterval {
+				s.purgeDeliveries(ctx)
+				lastPurge = time.Now()
+			}
+		}
+	}
+}
@@ -44,3 +44,23 @@ func SetupSignalHandler() (stopCh <-chan struct{}) {
 
 	return stop
 }
+
+// SetupReloadHandler returns a channel receiving a value on every SIGHUP, asking the service to reload
+// its runtime settings. Reloads arriving while one is pending are merged; on windows it never receives
+func SetupReloadHandler() <-chan struct{} {
+	reload := make(chan struct{}, 1)
+	if len(reloadSignals) == 0 {
+		return reload
+	}
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, reloadSignals...)
+	go func() {
+		for range c {
+			select {
+			case reload <- struct{}{}:
+			default:
+			}
+		}
+	}()
+	return reload
+}
@@ -23,3 +23,6 @@ import (
 )
 
 var shutdownSignals = []os.Signal{os.Interrupt}
+
+// Windows has no SIGHUP, the log level is changed through the internal API instead
+var reloadSignals []os.Signal
@@ -0,0 +1,230 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/clients/clientmocks"
+	traceobserversvc "github.com/wso2/ai-agent-management-platform/agent-manager-service/clients/traceobserversvc"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/middleware/jwtassertion"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/models"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/repositories"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/services"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/tests/apitestutils"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/utils"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/wiring"
+)
+
+var (
+	testAgentBudgetOrgId     = uuid.New()
+	testAgentBudgetUserIdpId = uuid.New()
+	testAgentBudgetOrgName   = fmt.Sprintf("test-org-%s", uuid.New().String()[:5])
+)
+
+// budgetUsageMock reports the cost and tokens of the current day and month of one agent
+type budgetUsageMock struct {
+	mu           sync.Mutex
+	agentId      string
+	dailyCost    float64
+	monthlyCost  float64
+	dailyTokens  int
+	lastDayStart string
+}
+
+func (m *budgetUsageMock) set(dailyCost float64, monthlyCost float64, dailyTokens int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.dailyCost, m.monthlyCost, m.dailyTokens = dailyCost, monthlyCost, dailyTokens
+}
+
+func (m *budgetUsageMock) getTraceMetrics(ctx context.Context, params traceobserversvc.TraceMetricsParams) (*traceobserversvc.TraceMetricsResponse, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	summary := &traceobserversvc.TraceMetricsPoint{}
+	if params.AgentID == m.agentId {
+		start, _ := time.Parse(time.RFC3339, params.StartTime)
+		end, _ := time.Parse(time.RFC3339, params.EndTime)
+		if end.Sub(start) <= 25*time.Hour {
+			m.lastDayStart = params.StartTime
+			summary.Cost, summary.TotalTokens = m.dailyCost, m.dailyTokens
+		} else {
+			summary.Cost = m.monthlyCost
+		}
+	}
+	return &traceobserversvc.TraceMetricsResponse{Interval: params.Interval, Summary: summary}, nil
+}
+
+func TestAgentBudgets(t *testing.T) {
+	_ = apitestutils.CreateOrganization(t, testAgentBudgetOrgId, testAgentBudgetUserIdpId, testAgentBudgetOrgName)
+	authMiddleware := jwtassertion.NewMockMiddleware(t, testAgentBudgetOrgId, testAgentBudgetUserIdpId)
+	orgURL := fmt.Sprintf("/api/v1/orgs/%s", testAgentBudgetOrgName)
+	secret := "whsec-0123456789abcdef"
+	receiver, server := newWebhookReceiver(t, secret)
+
+	useCredentialKeys(t, map[string]string{"key-a": testCredentialKeyA}, "key-a")
+	allowPrivateWebhookTargets(t)
+	usage := &budgetUsageMock{}
+	traceObserverClient := &clientmocks.TraceObserverClientMock{GetTraceMetricsFunc: usage.getTraceMetrics}
+	app := apitestutils.MakeAppClientWithDeps(t, wiring.TestClients{TraceObserverClient: traceObserverClient}, authMiddleware)
+
+	cipher, err := utils.NewCredentialCipher(map[string]string{"key-a": testCredentialKeyA}, "key-a")
+	require.NoError(t, err)
+	webhookService := services.NewWebhookService(repositories.NewOrganizationRepository(), repositories.NewWebhookRepository(), cipher,
+		server.Client(), slog.Default(), services.WebhookDispatchConfig{Timeout: 5 * time.Second, MaxAttempts: 2, BatchSize: 100, DeliveryRetention: time.Hour})
+	checker := services.NewAgentBudgetService(repositories.NewOrganizationRepository(), repositories.NewManagedAgentRepository(),
+		repositories.NewAgentBudgetRepository(), traceObserverClient, webhookService, slog.Default())
+
+	rr := sendManagedAgentRequest(t, app, http.MethodPost, orgURL+"/webhooks", map[string]interface{}{
+		"url": server.URL, "secret": secret, "events": []string{utils.WebhookEventAgentBudgetThreshold},
+	}, nil)
+	require.Equal(t, http.StatusCreated, rr.Code)
+	var webhook models.WebhookResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &webhook))
+	webhookURL := orgURL + "/webhooks/" + webhook.ID
+
+	rr = sendManagedAgentRequest(t, app, http.MethodPost, orgURL+"/agents", managedAgentPayload("budget-agent", nil), nil)
+	require.Equal(t, http.StatusCreated, rr.Code)
+	var agent models.ManagedAgentResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &agent))
+	usage.agentId = agent.ID
+	budgetURL := orgURL + "/agents/" + agent.ID + "/budget"
+
+	t.Run("Reading the budget of an agent without one should return 404", func(t *testing.T) {
+		rr := sendManagedAgentRequest(t, app, http.MethodGet, budgetURL, nil, nil)
+		require.Equal(t, http.StatusNotFound, rr.Code)
+	})
+
+	t.Run("Saving an invalid budget should return 400", func(t *testing.T) {
+		rr := sendManagedAgentRequest(t, app, http.MethodPut, budgetURL, map[string]interface{}{
+			"timezone": "Mars/Olympus_Mons", "alertThresholds": []int{80, 80}, "enforcement": "block",
+		}, nil)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.ElementsMatch(t, []string{"timezone", "daily", "alertThresholds[1]", "enforcement"}, problemFields(decodeProblem(t, rr)))
+	})
+
+	t.Run("Saving a budget should apply the defaults", func(t *testing.T) {
+		rr := sendManagedAgentRequest(t, app, http.MethodPut, budgetURL, map[string]interface{}{
+			"timezone": "Asia/Kolkata",
+			"daily":    map[string]interface{}{"maxCost": 10, "maxTokens": 1000},
+			"monthly":  map[string]interface{}{"maxCost": 100},
+		}, nil)
+		require.Equal(t, http.StatusOK, rr.Code)
+		var budget models.AgentBudgetResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &budget))
+		require.Equal(t, []int32{80, 100}, budget.AlertThresholds)
+		require.Equal(t, utils.BudgetEnforcementNone, budget.Enforcement)
+		require.Equal(t, 100.0, *budget.Monthly.MaxCost)
+		require.Nil(t, budget.Monthly.MaxTokens)
+		require.Empty(t, budget.Usage)
+	})
+
+	t.Run("Reading a budget should return the usage of the current periods in its time zone", func(t *testing.T) {
+		usage.set(4, 30, 1200)
+		rr := sendManagedAgentRequest(t, app, http.MethodGet, budgetURL, nil, nil)
+		require.Equal(t, http.StatusOK, rr.Code)
+		var budget models.AgentBudgetResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &budget))
+		require.Len(t, budget.Usage, 2)
+
+		day := budget.Usage[0]
+		require.Equal(t, utils.BudgetPeriodDay, day.Period)
+		require.Equal(t, 40.0, *day.CostPercent)
+		require.Equal(t, 120.0, *day.TokensPercent)
+		require.True(t, day.Exceeded)
+		_, offset := day.StartTime.Zone()
+		require.Equal(t, 19800, offset)
+		require.Equal(t, 24*time.Hour, day.EndTime.Sub(day.StartTime))
+		// Midnight in Kolkata is 18:30 the day before in UTC
+		require.True(t, strings.HasSuffix(usage.lastDayStart, "T18:30:00Z"), usage.lastDayStart)
+
+		month := budget.Usage[1]
+		require.Equal(t, utils.BudgetPeriodMonth, month.Period)
+		require.Equal(t, 30.0, *month.CostPercent)
+		require.Nil(t, month.TokensPercent)
+		require.False(t, month.Exceeded)
+	})
+
+	t.Run("Checking budgets should alert once per threshold and period", func(t *testing.T) {
+		usage.set(8.5, 30, 0)
+		require.NoError(t, checker.CheckBudgets(context.Background()))
+		require.NoError(t, checker.CheckBudgets(context.Background()))
+		deliveries := listWebhookDeliveries(t, app, webhookURL)
+		require.Len(t, deliveries, 1)
+		require.Equal(t, utils.WebhookEventAgentBudgetThreshold, deliveries[0].EventType)
+
+		// Usage past both thresholds of the monthly cost raises a single alert for the highest
+		usage.set(8.5, 120, 0)
+		require.NoError(t, checker.CheckBudgets(context.Background()))
+		require.Len(t, listWebhookDeliveries(t, app, webhookURL), 2)
+
+		require.NoError(t, webhookService.DeliverDue(context.Background()))
+		events := receiver.received()
+		require.Len(t, events, 2)
+		alerts := map[string]map[string]interface{}{}
+		for _, event := range events {
+			data := event.Data.(map[string]interface{})
+			require.Equal(t, agent.ID, data["agentId"])
+			require.Equal(t, "budget-agent", data["agentName"])
+			alerts[data["period"].(string)] = data
+		}
+		require.Equal(t, utils.BudgetMetricCost, alerts[utils.BudgetPeriodDay]["metric"])
+		require.Equal(t, 80.0, alerts[utils.BudgetPeriodDay]["threshold"])
+		require.Equal(t, 85.0, alerts[utils.BudgetPeriodDay]["percent"])
+		require.Equal(t, 100.0, alerts[utils.BudgetPeriodMonth]["threshold"])
+		require.Equal(t, 120.0, alerts[utils.BudgetPeriodMonth]["used"])
+	})
+
+	t.Run("Replacing a budget should alert on thresholds still reached again", func(t *testing.T) {
+		rr := sendManagedAgentRequest(t, app, http.MethodPut, budgetURL, map[string]interface{}{
+			"daily": map[string]interface{}{"maxCost": 10}, "alertThresholds": []int{50},
+		}, nil)
+		require.Equal(t, http.StatusOK, rr.Code)
+		require.NoError(t, checker.CheckBudgets(context.Background()))
+		deliveries := listWebhookDeliveries(t, app, webhookURL)
+		require.Len(t, deliveries, 3)
+	})
+
+	t.Run("Deleting a budget should stop its checks", func(t *testing.T) {
+		rr := sendManagedAgentRequest(t, app, http.MethodDelete, budgetURL, nil, nil)
+		require.Equal(t, http.StatusNoContent, rr.Code)
+		rr = sendManagedAgentRequest(t, app, http.MethodDelete, budgetURL, nil, nil)
+		require.Equal(t, http.StatusNotFound, rr.Code)
+
+		usage.set(50, 500, 0)
+		require.NoError(t, checker.CheckBudgets(context.Background()))
+		require.Len(t, listWebhookDeliveries(t, app, webhookURL), 3)
+	})
+
+	t.Run("The budget of an unknown agent should return 404", func(t *testing.T) {
+		rr := sendManagedAgentRequest(t, app, http.MethodPut, orgURL+"/agents/"+uuid.NewString()+"/budget", map[string]interface{}{
+			"daily": map[string]interface{}{"maxCost": 10},
+		}, nil)
+		require.Equal(t, http.StatusNotFound, rr.Code)
+	})
+}
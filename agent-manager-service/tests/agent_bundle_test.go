@@ -0,0 +1,179 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/middleware/jwtassertion"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/models"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/tests/apitestutils"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/utils"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/wiring"
+)
+
+var (
+	testBundleUserIdpId     = uuid.New()
+	testBundleSourceOrgId   = uuid.New()
+	testBundleSourceOrgName = fmt.Sprintf("test-org-%s", uuid.New().String()[:5])
+	testBundleTargetOrgId   = uuid.New()
+	testBundleTargetOrgName = fmt.Sprintf("test-org-%s", uuid.New().String()[:5])
+)
+
+func importChanges(response models.AgentImportResponse) map[string]string {
+	actions := map[string]string{}
+	for _, change := range response.Changes {
+		actions[change.Kind+"/"+change.Name] = change.Action
+	}
+	return actions
+}
+
+func TestAgentBundle(t *testing.T) {
+	_ = apitestutils.CreateOrganization(t, testBundleSourceOrgId, testBundleUserIdpId, testBundleSourceOrgName)
+	_ = apitestutils.CreateOrganization(t, testBundleTargetOrgId, testBundleUserIdpId, testBundleTargetOrgName)
+	sourceApp := apitestutils.MakeAppClientWithDeps(t, wiring.TestClients{}, jwtassertion.NewMockMiddleware(t, testBundleSourceOrgId, testBundleUserIdpId))
+	targetApp := apitestutils.MakeAppClientWithDeps(t, wiring.TestClients{}, jwtassertion.NewMockMiddleware(t, testBundleTargetOrgId, testBundleUserIdpId))
+	sourceURL := fmt.Sprintf("/api/v1/orgs/%s", testBundleSourceOrgName)
+	importURL := fmt.Sprintf("/api/v1/orgs/%s/agents/import", testBundleTargetOrgName)
+
+	var prompt models.PromptTemplateResponse
+	rr := sendManagedAgentRequest(t, sourceApp, http.MethodPost, sourceURL+"/prompts",
+		map[string]interface{}{"name": "support-prompt", "template": "You help {{customer}}"}, nil)
+	require.Equal(t, http.StatusCreated, rr.Code)
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &prompt))
+	var tool models.ToolResponse
+	rr = sendManagedAgentRequest(t, sourceApp, http.MethodPost, sourceURL+"/tools", toolPayload("search_docs"), nil)
+	require.Equal(t, http.StatusCreated, rr.Code)
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &tool))
+
+	payload := managedAgentPayload("bundled-agent", map[string]string{"team": "support"})
+	delete(payload, "systemPrompt")
+	payload["promptRef"] = map[string]interface{}{"promptId": prompt.ID, "version": 1}
+	payload["tools"] = []map[string]interface{}{{"toolId": tool.ID}, {"name": "calculator"}}
+	var agent models.ManagedAgentResponse
+	rr = sendManagedAgentRequest(t, sourceApp, http.MethodPost, sourceURL+"/agents", payload, nil)
+	require.Equal(t, http.StatusCreated, rr.Code)
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &agent))
+
+	var bundle models.AgentBundle
+	t.Run("Exporting an agent should bundle its prompt version and tools", func(t *testing.T) {
+		rr := sendManagedAgentRequest(t, sourceApp, http.MethodGet, sourceURL+"/agents/"+agent.ID+"/export", nil, nil)
+		require.Equal(t, http.StatusOK, rr.Code)
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &bundle))
+		require.Equal(t, models.AgentBundleSchemaVersion, bundle.SchemaVersion)
+		require.Equal(t, "support-prompt", bundle.Agent.Prompt)
+		require.Equal(t, "You help {{customer}}", bundle.Prompt.Template)
+		require.Equal(t, []models.AgentBundleToolReference{{Tool: "search_docs"}, {Name: "calculator"}}, bundle.Agent.Tools)
+		require.Len(t, bundle.Tools, 1)
+	})
+
+	t.Run("A dry run should plan the import without applying it", func(t *testing.T) {
+		rr := sendManagedAgentRequest(t, targetApp, http.MethodPost, importURL+"?dryRun=true", bundle, nil)
+		require.Equal(t, http.StatusOK, rr.Code)
+		var response models.AgentImportResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+		require.True(t, response.DryRun)
+		require.Nil(t, response.Agent)
+		require.Equal(t, map[string]string{
+			"prompt/support-prompt": utils.ImportActionCreate,
+			"tool/search_docs":      utils.ImportActionCreate,
+			"agent/bundled-agent":   utils.ImportActionCreate,
+		}, importChanges(response))
+
+		rr = sendManagedAgentRequest(t, targetApp, http.MethodGet, fmt.Sprintf("/api/v1/orgs/%s/agents", testBundleTargetOrgName), nil, nil)
+		var list models.ManagedAgentListResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &list))
+		require.Empty(t, list.Agents)
+	})
+
+	var imported models.ManagedAgentResponse
+	t.Run("Importing a bundle should create the agent with remapped references", func(t *testing.T) {
+		rr := sendManagedAgentRequest(t, targetApp, http.MethodPost, importURL, bundle, nil)
+		require.Equal(t, http.StatusCreated, rr.Code)
+		var response models.AgentImportResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+		require.NotNil(t, response.Agent)
+		imported = *response.Agent
+		require.Equal(t, "bundled-agent", imported.Name)
+		require.NotEqual(t, agent.ID, imported.ID)
+		require.NotEqual(t, prompt.ID, imported.PromptRef.PromptID)
+		require.NotEqual(t, tool.ID, imported.Tools[0].ToolID)
+		require.Equal(t, "calculator", imported.Tools[1].Name)
+	})
+
+	t.Run("Importing the same bundle again should change nothing", func(t *testing.T) {
+		rr := sendManagedAgentRequest(t, targetApp, http.MethodPost, importURL, bundle, nil)
+		require.Equal(t, http.StatusOK, rr.Code)
+		var response models.AgentImportResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+		require.Equal(t, imported.Version, response.Agent.Version)
+		for _, change := range response.Changes {
+			require.Equal(t, utils.ImportActionUnchanged, change.Action, change.Name)
+		}
+	})
+
+	t.Run("Importing a changed prompt should add a version and update the agent", func(t *testing.T) {
+		changed := bundle
+		changedPrompt := *bundle.Prompt
+		changedPrompt.Template = "You kindly help {{customer}}"
+		changed.Prompt = &changedPrompt
+		rr := sendManagedAgentRequest(t, targetApp, http.MethodPost, importURL, changed, nil)
+		require.Equal(t, http.StatusOK, rr.Code)
+		var response models.AgentImportResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+		require.Equal(t, utils.ImportActionUpdate, importChanges(response)["prompt/support-prompt"])
+		require.Equal(t, int32(2), response.Agent.PromptRef.Version)
+		require.Equal(t, imported.Version+1, response.Agent.Version)
+	})
+
+	t.Run("Importing a tool that differs from the existing one should return 409", func(t *testing.T) {
+		changed := bundle
+		changed.Tools = []models.ToolRequest{bundle.Tools[0]}
+		changed.Tools[0].Description = "Searches something else"
+		rr := sendManagedAgentRequest(t, targetApp, http.MethodPost, importURL, changed, nil)
+		require.Equal(t, http.StatusConflict, rr.Code)
+		require.Equal(t, []string{"tools[0]"}, problemFields(decodeProblem(t, rr)))
+
+		rr = sendManagedAgentRequest(t, targetApp, http.MethodPost, importURL+"?dryRun=true", changed, nil)
+		require.Equal(t, http.StatusOK, rr.Code)
+		var response models.AgentImportResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+		require.Len(t, response.Conflicts, 1)
+	})
+
+	t.Run("Importing a bundle of an unsupported schema version should return 400", func(t *testing.T) {
+		newer := bundle
+		newer.SchemaVersion = models.AgentBundleSchemaVersion + 1
+		rr := sendManagedAgentRequest(t, targetApp, http.MethodPost, importURL, newer, nil)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Equal(t, []string{"schemaVersion"}, problemFields(decodeProblem(t, rr)))
+	})
+
+	t.Run("Importing a bundle referencing a missing tool should return 400", func(t *testing.T) {
+		broken := bundle
+		broken.Tools = nil
+		rr := sendManagedAgentRequest(t, targetApp, http.MethodPost, importURL, broken, nil)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Equal(t, []string{"agent.tools[0].tool"}, problemFields(decodeProblem(t, rr)))
+	})
+}
@@ -0,0 +1,188 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/middleware/jwtassertion"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/models"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/tests/apitestutils"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/utils"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/wiring"
+)
+
+var (
+	testDeploymentOrgId     = uuid.New()
+	testDeploymentUserIdpId = uuid.New()
+	testDeploymentOrgName   = fmt.Sprintf("test-org-%s", uuid.New().String()[:5])
+)
+
+func TestAgentDeployments(t *testing.T) {
+	_ = apitestutils.CreateOrganization(t, testDeploymentOrgId, testDeploymentUserIdpId, testDeploymentOrgName)
+	app := apitestutils.MakeAppClientWithDeps(t, wiring.TestClients{}, jwtassertion.NewMockMiddleware(t, testDeploymentOrgId, testDeploymentUserIdpId))
+	editorApp := apitestutils.MakeAppClientWithDeps(t, wiring.TestClients{},
+		jwtassertion.NewMockMiddlewareWithRoles(t, testDeploymentOrgId, testDeploymentUserIdpId, utils.RoleEditor))
+	orgURL := fmt.Sprintf("/api/v1/orgs/%s", testDeploymentOrgName)
+	environmentsURL := orgURL + "/agent-environments"
+
+	for _, name := range []string{"staging", "prod"} {
+		rr := sendManagedAgentRequest(t, app, http.MethodPost, environmentsURL, map[string]interface{}{"name": name}, nil)
+		require.Equal(t, http.StatusCreated, rr.Code)
+		require.Equal(t, environmentsURL+"/"+name, rr.Header().Get("Location"))
+	}
+
+	rr := sendManagedAgentRequest(t, app, http.MethodPost, orgURL+"/agents", managedAgentPayload("deployed-agent", map[string]string{"team": "support"}), nil)
+	require.Equal(t, http.StatusCreated, rr.Code)
+	var created models.ManagedAgentResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &created))
+	agentURL := orgURL + "/agents/" + created.ID
+
+	payload := managedAgentPayload("deployed-agent", map[string]string{"team": "support"})
+	payload["systemPrompt"] = "You are a careful support agent."
+	rr = sendManagedAgentRequest(t, app, http.MethodPut, agentURL, payload, map[string]string{"If-Match": `"1"`})
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	t.Run("Creating an environment should require environments:manage", func(t *testing.T) {
+		rr := sendManagedAgentRequest(t, editorApp, http.MethodPost, environmentsURL, map[string]interface{}{"name": "dev"}, nil)
+		require.Equal(t, http.StatusForbidden, rr.Code)
+	})
+
+	t.Run("Creating an environment with a taken name should conflict", func(t *testing.T) {
+		rr := sendManagedAgentRequest(t, app, http.MethodPost, environmentsURL, map[string]interface{}{"name": "prod"}, nil)
+		require.Equal(t, http.StatusConflict, rr.Code)
+	})
+
+	t.Run("Listing environments should return them by name", func(t *testing.T) {
+		rr := sendManagedAgentRequest(t, app, http.MethodGet, environmentsURL, nil, nil)
+		require.Equal(t, http.StatusOK, rr.Code)
+		var list models.AgentEnvironmentListResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &list))
+		require.Len(t, list.Environments, 2)
+		require.Equal(t, "prod", list.Environments[0].Name)
+		require.Equal(t, "staging", list.Environments[1].Name)
+	})
+
+	t.Run("Deploying should validate the environment and version", func(t *testing.T) {
+		rr := sendManagedAgentRequest(t, app, http.MethodPost, agentURL+"/deployments", map[string]interface{}{"environment": "qa", "version": 1}, nil)
+		require.Equal(t, http.StatusNotFound, rr.Code)
+		rr = sendManagedAgentRequest(t, app, http.MethodPost, agentURL+"/deployments", map[string]interface{}{"environment": "staging", "version": 9}, nil)
+		require.Equal(t, http.StatusNotFound, rr.Code)
+		rr = sendManagedAgentRequest(t, app, http.MethodPost, agentURL+"/deployments", map[string]interface{}{"environment": "staging"}, nil)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("Promoting an agent not deployed to the source environment should conflict", func(t *testing.T) {
+		rr := sendManagedAgentRequest(t, app, http.MethodPost, agentURL+"/promotions", map[string]interface{}{"from": "staging", "to": "prod"}, nil)
+		require.Equal(t, http.StatusConflict, rr.Code)
+	})
+
+	t.Run("Deploying a version should make it current in the environment", func(t *testing.T) {
+		rr := sendManagedAgentRequest(t, app, http.MethodPost, agentURL+"/deployments", map[string]interface{}{"environment": "staging", "version": 2}, nil)
+		require.Equal(t, http.StatusCreated, rr.Code)
+		var deployment models.AgentDeploymentResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &deployment))
+		require.Equal(t, "staging", deployment.Environment)
+		require.Equal(t, int32(2), deployment.Version)
+		require.Equal(t, testDeploymentUserIdpId.String(), deployment.DeployedBy)
+		require.Empty(t, deployment.PromotedFrom)
+
+		rr = sendManagedAgentRequest(t, app, http.MethodGet, environmentsURL+"/staging/agents", nil, nil)
+		require.Equal(t, http.StatusOK, rr.Code)
+		var list models.EnvironmentAgentListResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &list))
+		require.Equal(t, int32(1), list.Total)
+		require.Equal(t, "deployed-agent", list.Agents[0].Name)
+		require.Equal(t, int32(2), list.Agents[0].Deployment.Version)
+	})
+
+	t.Run("Promoting should deploy the source version and record who promoted it", func(t *testing.T) {
+		rr := sendManagedAgentRequest(t, app, http.MethodPost, agentURL+"/promotions", map[string]interface{}{"from": "staging", "to": "prod", "version": 1}, nil)
+		require.Equal(t, http.StatusConflict, rr.Code)
+
+		rr = sendManagedAgentRequest(t, app, http.MethodPost, agentURL+"/promotions", map[string]interface{}{"from": "staging", "to": "prod", "version": 2}, nil)
+		require.Equal(t, http.StatusCreated, rr.Code)
+		var deployment models.AgentDeploymentResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &deployment))
+		require.Equal(t, "prod", deployment.Environment)
+		require.Equal(t, int32(2), deployment.Version)
+		require.Equal(t, "staging", deployment.PromotedFrom)
+		require.Equal(t, testDeploymentUserIdpId.String(), deployment.DeployedBy)
+	})
+
+	t.Run("Resolving the config should return the deployed version with an ETag", func(t *testing.T) {
+		configURL := environmentsURL + "/prod/agents/" + created.ID
+		rr := sendManagedAgentRequest(t, app, http.MethodGet, configURL, nil, nil)
+		require.Equal(t, http.StatusOK, rr.Code)
+		var config models.ResolvedAgentConfig
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &config))
+		require.Equal(t, "prod", config.Environment)
+		require.Equal(t, int32(2), config.Version)
+		require.Equal(t, "You are a careful support agent.", config.SystemPrompt)
+		require.Len(t, config.Tools, 1)
+		require.Equal(t, "search_docs", config.Tools[0].Name)
+		etag := rr.Header().Get("ETag")
+		require.NotEmpty(t, etag)
+
+		rr = sendManagedAgentRequest(t, app, http.MethodGet, configURL, nil, map[string]string{"If-None-Match": etag})
+		require.Equal(t, http.StatusNotModified, rr.Code)
+	})
+
+	t.Run("Rolling back should be deploying an earlier version", func(t *testing.T) {
+		rr := sendManagedAgentRequest(t, app, http.MethodPost, agentURL+"/deployments", map[string]interface{}{"environment": "prod", "version": 1}, nil)
+		require.Equal(t, http.StatusCreated, rr.Code)
+
+		rr = sendManagedAgentRequest(t, app, http.MethodGet, environmentsURL+"/prod/agents/"+created.ID, nil, nil)
+		require.Equal(t, http.StatusOK, rr.Code)
+		var config models.ResolvedAgentConfig
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &config))
+		require.Equal(t, int32(1), config.Version)
+		require.Equal(t, "You are a helpful support agent.", config.SystemPrompt)
+	})
+
+	t.Run("Listing deployments should return the history newest first", func(t *testing.T) {
+		rr := sendManagedAgentRequest(t, app, http.MethodGet, agentURL+"/deployments", nil, nil)
+		require.Equal(t, http.StatusOK, rr.Code)
+		var list models.AgentDeploymentListResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &list))
+		require.Equal(t, int32(3), list.Total)
+		require.Equal(t, int32(1), list.Deployments[0].Version)
+		require.Equal(t, "prod", list.Deployments[0].Environment)
+
+		rr = sendManagedAgentRequest(t, app, http.MethodGet, agentURL+"/deployments?environment=staging", nil, nil)
+		require.Equal(t, http.StatusOK, rr.Code)
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &list))
+		require.Equal(t, int32(1), list.Total)
+		require.Equal(t, "staging", list.Deployments[0].Environment)
+	})
+
+	t.Run("Resolving the config of an agent not deployed to the environment should return 404", func(t *testing.T) {
+		rr := sendManagedAgentRequest(t, app, http.MethodPost, orgURL+"/agents", managedAgentPayload("undeployed-agent", nil), nil)
+		require.Equal(t, http.StatusCreated, rr.Code)
+		var other models.ManagedAgentResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &other))
+
+		rr = sendManagedAgentRequest(t, app, http.MethodGet, environmentsURL+"/prod/agents/"+other.ID, nil, nil)
+		require.Equal(t, http.StatusNotFound, rr.Code)
+	})
+}
@@ -0,0 +1,101 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/middleware/jwtassertion"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/models"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/tests/apitestutils"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/wiring"
+)
+
+func TestAgentFrameworks(t *testing.T) {
+	orgId, userIdpId := uuid.New(), uuid.New()
+	orgName := fmt.Sprintf("test-org-%s", uuid.New().String()[:5])
+	_ = apitestutils.CreateOrganization(t, orgId, userIdpId, orgName)
+	app := apitestutils.MakeAppClientWithDeps(t, wiring.TestClients{}, jwtassertion.NewMockMiddleware(t, orgId, userIdpId))
+	baseURL := fmt.Sprintf("/api/v1/orgs/%s/agents", orgName)
+
+	t.Run("Listing frameworks should return the schemas of every supported framework", func(t *testing.T) {
+		rr := sendManagedAgentRequest(t, app, http.MethodGet, "/api/v1/frameworks", nil, nil)
+		require.Equal(t, http.StatusOK, rr.Code)
+		var response models.AgentFrameworkListResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+		names := make([]string, 0, len(response.Frameworks))
+		for _, framework := range response.Frameworks {
+			names = append(names, framework.Name)
+			require.NotEmpty(t, framework.Schemas)
+			require.Equal(t, framework.LatestSchemaVersion, framework.Schemas[len(framework.Schemas)-1].Version)
+			require.Equal(t, "object", framework.Schemas[0].Schema["type"])
+		}
+		require.Equal(t, []string{"crewai", "langchain", "langgraph", "openai-agents", "custom"}, names)
+	})
+
+	var created models.ManagedAgentResponse
+	t.Run("Creating an agent should pin it to the latest schema version", func(t *testing.T) {
+		rr := sendManagedAgentRequest(t, app, http.MethodPost, baseURL, managedAgentPayload("schema-agent", nil), nil)
+		require.Equal(t, http.StatusCreated, rr.Code)
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &created))
+		require.NotNil(t, created.ConfigSchemaVersion)
+		require.Equal(t, int32(1), *created.ConfigSchemaVersion)
+	})
+
+	t.Run("Misspelled model settings should be rejected on the setting", func(t *testing.T) {
+		payload := managedAgentPayload("typo-agent", nil)
+		payload["modelConfig"] = map[string]interface{}{"provider": "openai", "model": "gpt-4o", "temprature": 0.2}
+		rr := sendManagedAgentRequest(t, app, http.MethodPost, baseURL, payload, nil)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Equal(t, []string{"modelConfig.temprature"}, problemFields(decodeProblem(t, rr)))
+	})
+
+	t.Run("Updating an agent should validate against its schema version", func(t *testing.T) {
+		payload := managedAgentPayload("schema-agent", nil)
+		payload["modelConfig"] = map[string]interface{}{"provider": "openai", "model": "gpt-4o", "maxIterations": "five"}
+		rr := sendManagedAgentRequest(t, app, http.MethodPut, baseURL+"/"+created.ID, payload, map[string]string{"If-Match": `"1"`})
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Equal(t, []string{"modelConfig.maxIterations"}, problemFields(decodeProblem(t, rr)))
+
+		payload["configSchemaVersion"] = 7
+		rr = sendManagedAgentRequest(t, app, http.MethodPut, baseURL+"/"+created.ID, payload, map[string]string{"If-Match": `"1"`})
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, problemFields(decodeProblem(t, rr)), "configSchemaVersion")
+	})
+
+	t.Run("Unknown frameworks should require allowUnvalidated", func(t *testing.T) {
+		payload := managedAgentPayload("autogen-agent", nil)
+		payload["framework"] = "autogen"
+		rr := sendManagedAgentRequest(t, app, http.MethodPost, baseURL, payload, nil)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Equal(t, []string{"framework"}, problemFields(decodeProblem(t, rr)))
+
+		payload["allowUnvalidated"] = true
+		rr = sendManagedAgentRequest(t, app, http.MethodPost, baseURL, payload, nil)
+		require.Equal(t, http.StatusCreated, rr.Code)
+		var agent models.ManagedAgentResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &agent))
+		require.Equal(t, "autogen", agent.Framework)
+		require.Nil(t, agent.ConfigSchemaVersion)
+	})
+}
@@ -0,0 +1,199 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/clients/clientmocks"
+	traceobserversvc "github.com/wso2/ai-agent-management-platform/agent-manager-service/clients/traceobserversvc"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/config"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/middleware/jwtassertion"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/models"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/tests/apitestutils"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/wiring"
+)
+
+var (
+	testAgentTracesOrgId     = uuid.New()
+	testAgentTracesUserIdpId = uuid.New()
+	testAgentTracesOrgName   = fmt.Sprintf("test-org-%s", uuid.New().String()[:5])
+)
+
+func TestAgentTraces(t *testing.T) {
+	_ = apitestutils.CreateOrganization(t, testAgentTracesOrgId, testAgentTracesUserIdpId, testAgentTracesOrgName)
+	authMiddleware := jwtassertion.NewMockMiddleware(t, testAgentTracesOrgId, testAgentTracesUserIdpId)
+	start := time.Date(2025, 12, 16, 10, 0, 0, 0, time.UTC)
+	traceObserverClient := &clientmocks.TraceObserverClientMock{
+		ListTracesFunc: func(ctx context.Context, params traceobserversvc.ListTracesParams) (*traceobserversvc.TraceOverviewResponse, error) {
+			return &traceobserversvc.TraceOverviewResponse{
+				Traces: []traceobserversvc.TraceOverview{{
+					TraceID:         "trace-id-1",
+					RootSpanName:    "support",
+					StartTime:       "2025-12-16T10:00:00Z",
+					EndTime:         "2025-12-16T10:00:02Z",
+					DurationInNanos: 2000000000,
+					SpanCount:       3,
+				}},
+				TotalCount: 1,
+			}, nil
+		},
+		GetTraceMetricsFunc: func(ctx context.Context, params traceobserversvc.TraceMetricsParams) (*traceobserversvc.TraceMetricsResponse, error) {
+			if params.Interval == "1m" {
+				return nil, &traceobserversvc.HTTPError{StatusCode: http.StatusBadRequest, Message: `{"error":"too many buckets"}`}
+			}
+			point := traceobserversvc.TraceMetricsPoint{
+				Timestamp:     start,
+				TraceCount:    4,
+				ErrorCount:    1,
+				ErrorRate:     0.25,
+				DurationNanos: traceobserversvc.LatencyPercentiles{P50: 1000, P95: 2000, P99: 3000},
+				InputTokens:   400,
+				OutputTokens:  100,
+				TotalTokens:   500,
+				Cost:          0.02,
+			}
+			return &traceobserversvc.TraceMetricsResponse{
+				Interval:  params.Interval,
+				StartTime: start,
+				EndTime:   start.Add(2 * time.Hour),
+				Series:    []traceobserversvc.TraceMetricsSeries{{Points: []traceobserversvc.TraceMetricsPoint{point, {Timestamp: start.Add(time.Hour)}}}},
+				Summary:   &point,
+			}, nil
+		},
+	}
+	app := apitestutils.MakeAppClientWithDeps(t, wiring.TestClients{TraceObserverClient: traceObserverClient}, authMiddleware)
+	orgURL := fmt.Sprintf("/api/v1/orgs/%s", testAgentTracesOrgName)
+
+	createAgent := func(name string) models.ManagedAgentResponse {
+		var agent models.ManagedAgentResponse
+		rr := sendManagedAgentRequest(t, app, http.MethodPost, orgURL+"/agents", managedAgentPayload(name, nil), nil)
+		require.Equal(t, http.StatusCreated, rr.Code)
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &agent))
+		return agent
+	}
+	agent := createAgent("Support-Agent")
+	otherAgent := createAgent("support-agent")
+	billingAgent := createAgent("billing-agent")
+	internalHeaders := map[string]string{config.GetConfig().APIKeyHeader: config.GetConfig().APIKeyValue}
+
+	findAgents := func(t *testing.T, name string) []string {
+		url := fmt.Sprintf("/internal/agents?orgId=%s&name=%s", testAgentTracesOrgId, name)
+		rr := sendManagedAgentRequest(t, app, http.MethodGet, url, nil, internalHeaders)
+		require.Equal(t, http.StatusOK, rr.Code)
+		var response models.ManagedAgentLabelsListResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+		ids := []string{}
+		for _, found := range response.Agents {
+			require.Equal(t, testAgentTracesOrgId.String(), found.OrgID)
+			ids = append(ids, found.AgentID)
+		}
+		return ids
+	}
+
+	t.Run("Finding agents by name through the internal API should match the name ignoring case", func(t *testing.T) {
+		require.Equal(t, []string{billingAgent.ID}, findAgents(t, "Billing-Agent"))
+		require.ElementsMatch(t, []string{agent.ID, otherAgent.ID}, findAgents(t, "SUPPORT-AGENT"))
+		require.Empty(t, findAgents(t, "unmanaged-agent"))
+	})
+
+	t.Run("Finding agents by name through the internal API without an organization should return 400", func(t *testing.T) {
+		rr := sendManagedAgentRequest(t, app, http.MethodGet, "/internal/agents?name=billing-agent", nil, internalHeaders)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("Listing the traces of an agent should query the trace observer by agent id", func(t *testing.T) {
+		rr := sendManagedAgentRequest(t, app, http.MethodGet, orgURL+"/agents/"+agent.ID+"/traces?limit=5&startTime=2025-12-16T00:00:00Z&endTime=2025-12-17T00:00:00Z", nil, nil)
+		require.Equal(t, http.StatusOK, rr.Code)
+		var response models.TraceOverviewResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+		require.Equal(t, 1, response.TotalCount)
+		require.Equal(t, "trace-id-1", response.Traces[0].TraceID)
+
+		calls := traceObserverClient.ListTracesCalls()
+		require.NotEmpty(t, calls)
+		params := calls[len(calls)-1].Params
+		require.Equal(t, agent.ID, params.AgentID)
+		require.Equal(t, testAgentTracesOrgId.String(), params.OrgID)
+		require.Empty(t, params.ComponentUid)
+		require.Equal(t, 5, params.Limit)
+		require.Equal(t, "desc", params.SortOrder)
+	})
+
+	t.Run("Listing the traces of an unknown agent should return 404", func(t *testing.T) {
+		rr := sendManagedAgentRequest(t, app, http.MethodGet, orgURL+"/agents/"+uuid.New().String()+"/traces", nil, nil)
+		require.Equal(t, http.StatusNotFound, rr.Code)
+	})
+
+	t.Run("Listing the traces of an agent with only a start time should return 400", func(t *testing.T) {
+		rr := sendManagedAgentRequest(t, app, http.MethodGet, orgURL+"/agents/"+agent.ID+"/traces?startTime=2025-12-16T00:00:00Z", nil, nil)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("Getting the metrics of an agent should return the summary and one point per interval", func(t *testing.T) {
+		rr := sendManagedAgentRequest(t, app, http.MethodGet, orgURL+"/agents/"+agent.ID+"/metrics?startTime=2025-12-16T10:00:00Z&endTime=2025-12-16T12:00:00Z", nil, nil)
+		require.Equal(t, http.StatusOK, rr.Code)
+		var response models.AgentMetricsResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+		require.Equal(t, agent.ID, response.AgentID)
+		require.Equal(t, "1h", response.Interval)
+		require.Equal(t, 4, response.Summary.TraceCount)
+		require.Equal(t, 0.25, response.Summary.ErrorRate)
+		require.Equal(t, int64(2000), response.Summary.DurationNanos.P95)
+		require.Equal(t, 0.02, response.Summary.Cost)
+		require.Len(t, response.Points, 2)
+		require.Equal(t, start.Add(time.Hour), response.Points[1].Timestamp)
+
+		calls := traceObserverClient.GetTraceMetricsCalls()
+		require.NotEmpty(t, calls)
+		params := calls[len(calls)-1].Params
+		require.Equal(t, agent.ID, params.AgentID)
+		require.Equal(t, testAgentTracesOrgId.String(), params.OrgID)
+	})
+
+	t.Run("Getting the metrics of an agent without a time range should return 400", func(t *testing.T) {
+		rr := sendManagedAgentRequest(t, app, http.MethodGet, orgURL+"/agents/"+agent.ID+"/metrics", nil, nil)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("Getting the metrics of an agent with an unsupported interval should return 400", func(t *testing.T) {
+		rr := sendManagedAgentRequest(t, app, http.MethodGet, orgURL+"/agents/"+agent.ID+"/metrics?startTime=2025-12-16T10:00:00Z&endTime=2025-12-16T12:00:00Z&interval=2h", nil, nil)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("Getting metrics the trace observer rejects should return 400", func(t *testing.T) {
+		rr := sendManagedAgentRequest(t, app, http.MethodGet, orgURL+"/agents/"+agent.ID+"/metrics?startTime=2025-01-01T00:00:00Z&endTime=2025-12-16T12:00:00Z&interval=1m", nil, nil)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("Listing the traces of a deleted agent should return 200", func(t *testing.T) {
+		rr := sendManagedAgentRequest(t, app, http.MethodDelete, orgURL+"/agents/"+billingAgent.ID, nil, nil)
+		require.Equal(t, http.StatusNoContent, rr.Code)
+		require.Empty(t, findAgents(t, "billing-agent"))
+
+		rr = sendManagedAgentRequest(t, app, http.MethodGet, orgURL+"/agents/"+billingAgent.ID+"/traces", nil, nil)
+		require.Equal(t, http.StatusOK, rr.Code)
+	})
+}
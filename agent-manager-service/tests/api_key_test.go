@@ -0,0 +1,132 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/middleware/jwtassertion"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/models"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/tests/apitestutils"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/wiring"
+)
+
+var (
+	testAPIKeyOrgId     = uuid.New()
+	testAPIKeyUserIdpId = uuid.New()
+	testAPIKeyOrgName   = fmt.Sprintf("test-org-%s", uuid.New().String()[:5])
+)
+
+func TestAPIKeys(t *testing.T) {
+	_ = apitestutils.CreateOrganization(t, testAPIKeyOrgId, testAPIKeyUserIdpId, testAPIKeyOrgName)
+	authMiddleware := jwtassertion.NewMockMiddleware(t, testAPIKeyOrgId, testAPIKeyUserIdpId)
+	app := apitestutils.MakeAppClientWithDeps(t, wiring.TestClients{}, authMiddleware)
+	baseURL := fmt.Sprintf("/api/v1/orgs/%s/api-keys", testAPIKeyOrgName)
+	agentsURL := fmt.Sprintf("/api/v1/orgs/%s/agents", testAPIKeyOrgName)
+
+	var created models.APIKeyCreateResponse
+	t.Run("Creating an API key should return the key once", func(t *testing.T) {
+		payload := map[string]interface{}{
+			"name":      "ci-pipeline",
+			"scopes":    []string{"agents:read"},
+			"expiresAt": time.Now().Add(time.Hour),
+		}
+		rr := sendManagedAgentRequest(t, app, http.MethodPost, baseURL, payload, nil)
+		require.Equal(t, http.StatusCreated, rr.Code)
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &created))
+		require.True(t, strings.HasPrefix(created.Key, "amp_"))
+		require.True(t, strings.HasPrefix(created.Key, created.Prefix))
+		require.Equal(t, []string{"agents:read"}, created.Scopes)
+	})
+	bearer := map[string]string{"Authorization": "Bearer " + created.Key}
+
+	t.Run("Listing API keys should not return secrets", func(t *testing.T) {
+		rr := sendManagedAgentRequest(t, app, http.MethodGet, baseURL, nil, nil)
+		require.Equal(t, http.StatusOK, rr.Code)
+		require.NotContains(t, rr.Body.String(), created.Key)
+		var list models.APIKeyListResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &list))
+		require.Equal(t, int32(1), list.Total)
+		require.Equal(t, created.ID, list.APIKeys[0].ID)
+	})
+
+	validationTests := []struct {
+		name       string
+		payload    map[string]interface{}
+		wantFields []string
+	}{
+		{
+			name:       "an unknown scope",
+			payload:    map[string]interface{}{"name": "bad-scope", "scopes": []string{"agents:delete"}},
+			wantFields: []string{"scopes[0]"},
+		},
+		{
+			name:       "an expiry in the past",
+			payload:    map[string]interface{}{"name": "expired", "scopes": []string{"agents:read"}, "expiresAt": time.Now().Add(-time.Hour)},
+			wantFields: []string{"expiresAt"},
+		},
+		{
+			name: "a rate limit granting no requests",
+			payload: map[string]interface{}{
+				"name": "throttled", "scopes": []string{"agents:read"},
+				"rateLimit": map[string]interface{}{"requestsPerSecond": 0, "burst": 0},
+			},
+			wantFields: []string{"rateLimit.requestsPerSecond", "rateLimit.burst"},
+		},
+	}
+	for _, tt := range validationTests {
+		t.Run(fmt.Sprintf("Creating an API key with %s should return 400", tt.name), func(t *testing.T) {
+			rr := sendManagedAgentRequest(t, app, http.MethodPost, baseURL, tt.payload, nil)
+			require.Equal(t, http.StatusBadRequest, rr.Code)
+			require.ElementsMatch(t, tt.wantFields, problemFields(decodeProblem(t, rr)))
+		})
+	}
+
+	t.Run("Requests with an API key should act as the key owner", func(t *testing.T) {
+		rr := sendManagedAgentRequest(t, app, http.MethodGet, agentsURL, nil, bearer)
+		require.Equal(t, http.StatusOK, rr.Code)
+	})
+
+	t.Run("Managing API keys with an API key should return 403", func(t *testing.T) {
+		rr := sendManagedAgentRequest(t, app, http.MethodPost, baseURL, map[string]interface{}{
+			"name": "escalated", "scopes": []string{"agents:write"},
+		}, bearer)
+		require.Equal(t, http.StatusForbidden, rr.Code)
+	})
+
+	t.Run("Requests with an unknown API key should return 401", func(t *testing.T) {
+		rr := sendManagedAgentRequest(t, app, http.MethodGet, agentsURL, nil, map[string]string{"Authorization": "Bearer amp_unknown"})
+		require.Equal(t, http.StatusUnauthorized, rr.Code)
+	})
+
+	t.Run("Requests with a revoked API key should return 401", func(t *testing.T) {
+		rr := sendManagedAgentRequest(t, app, http.MethodDelete, baseURL+"/"+created.ID, nil, nil)
+		require.Equal(t, http.StatusNoContent, rr.Code)
+		rr = sendManagedAgentRequest(t, app, http.MethodGet, agentsURL, nil, bearer)
+		require.Equal(t, http.StatusUnauthorized, rr.Code)
+		rr = sendManagedAgentRequest(t, app, http.MethodDelete, baseURL+"/"+created.ID, nil, nil)
+		require.Equal(t, http.StatusNotFound, rr.Code)
+	})
+}
@@ -34,6 +34,9 @@ func MakeAppClientWithDeps(t *testing.T, testClients wiring.TestClients, authMid
 		t.Fatalf("failed to initialize test app params: %v", err)
 	}
 
+	// Write the audit log of the requests the test sends
+	go appParams.AuditService.Run(t.Context())
+
 	// Create HTTP handler
 	handler := api.MakeHTTPHandler(appParams)
 
@@ -0,0 +1,111 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/middleware/jwtassertion"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/models"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/tests/apitestutils"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/wiring"
+)
+
+var (
+	testAuditOrgId     = uuid.New()
+	testAuditUserIdpId = uuid.New()
+	testAuditOrgName   = fmt.Sprintf("test-org-%s", uuid.New().String()[:5])
+)
+
+func TestAuditLog(t *testing.T) {
+	_ = apitestutils.CreateOrganization(t, testAuditOrgId, testAuditUserIdpId, testAuditOrgName)
+	authMiddleware := jwtassertion.NewMockMiddleware(t, testAuditOrgId, testAuditUserIdpId)
+	app := apitestutils.MakeAppClientWithDeps(t, wiring.TestClients{}, authMiddleware)
+	toolsURL := fmt.Sprintf("/api/v1/orgs/%s/tools", testAuditOrgName)
+	auditURL := fmt.Sprintf("/api/v1/orgs/%s/audit-logs", testAuditOrgName)
+	startedAt := time.Now().Add(-time.Second)
+
+	var tool models.ToolResponse
+	rr := sendManagedAgentRequest(t, app, http.MethodPost, toolsURL, toolPayload("audited_tool"), nil)
+	require.Equal(t, http.StatusCreated, rr.Code)
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &tool))
+
+	update := toolPayload("audited_tool")
+	update["description"] = "Searches the release notes"
+	rr = sendManagedAgentRequest(t, app, http.MethodPut, toolsURL+"/"+tool.ID, update, map[string]string{"If-Match": `"1"`})
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	rr = sendManagedAgentRequest(t, app, http.MethodPut, toolsURL+"/"+uuid.NewString(), update, map[string]string{"If-Match": "*"})
+	require.Equal(t, http.StatusNotFound, rr.Code)
+
+	listAuditLogs := func(t *testing.T, query url.Values) models.AuditLogListResponse {
+		t.Helper()
+		rr := sendManagedAgentRequest(t, app, http.MethodGet, auditURL+"?"+query.Encode(), nil, nil)
+		require.Equal(t, http.StatusOK, rr.Code)
+		var list models.AuditLogListResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &list))
+		return list
+	}
+	toolQuery := url.Values{"resourceType": {"tools"}, "resourceId": {tool.ID}}
+
+	// Entries are written in the background
+	require.Eventually(t, func() bool {
+		return listAuditLogs(t, toolQuery).Total == 2
+	}, 5*time.Second, 50*time.Millisecond)
+
+	t.Run("Mutating requests should be recorded newest first", func(t *testing.T) {
+		list := listAuditLogs(t, toolQuery)
+		require.Len(t, list.AuditLogs, 2)
+		require.Equal(t, "update", list.AuditLogs[0].Action)
+		require.Equal(t, "create", list.AuditLogs[1].Action)
+		require.Equal(t, testAuditUserIdpId.String(), list.AuditLogs[0].Actor)
+		require.Equal(t, "PUT /orgs/{orgName}/tools/{toolId}", list.AuditLogs[0].Route)
+		require.NotEmpty(t, list.AuditLogs[0].CorrelationID)
+	})
+
+	t.Run("An update should record the changed fields", func(t *testing.T) {
+		list := listAuditLogs(t, url.Values{"resourceId": {tool.ID}, "action": {"update"}})
+		require.Len(t, list.AuditLogs, 1)
+		changes := list.AuditLogs[0].Changes
+		require.Equal(t, models.AuditFieldChange{From: "Searches the product documentation", To: "Searches the release notes"}, changes["description"])
+		require.NotContains(t, changes, "name")
+	})
+
+	t.Run("Failed requests should not be recorded", func(t *testing.T) {
+		list := listAuditLogs(t, url.Values{"actor": {testAuditUserIdpId.String()}, "from": {startedAt.Format(time.RFC3339)}})
+		require.Equal(t, int32(2), list.Total)
+	})
+
+	t.Run("Filtering by a time range should exclude entries outside it", func(t *testing.T) {
+		list := listAuditLogs(t, url.Values{"to": {startedAt.Format(time.RFC3339)}})
+		require.Equal(t, int32(0), list.Total)
+	})
+
+	t.Run("Filtering by an invalid actor or time should return 400", func(t *testing.T) {
+		rr := sendManagedAgentRequest(t, app, http.MethodGet, auditURL+"?actor=someone&from=yesterday", nil, nil)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.ElementsMatch(t, []string{"actor", "from"}, problemFields(decodeProblem(t, rr)))
+	})
+}
@@ -0,0 +1,292 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/api"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/config"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/middleware"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/middleware/jwtassertion"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/tests/apitestutils"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/utils"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/wiring"
+)
+
+// Every route of the API together with the permission it requires
+var expectedProtectedRoutes = map[string]utils.Permission{
+	"GET /orgs":                                                   utils.PermissionOrgsRead,
+	"GET /orgs/{orgName}":                                         utils.PermissionOrgsRead,
+	"GET /orgs/{orgName}/data-planes":                             utils.PermissionOrgsRead,
+	"GET /orgs/{orgName}/deployment-pipelines":                    utils.PermissionOrgsRead,
+	"GET /orgs/{orgName}/environments":                            utils.PermissionOrgsRead,
+	"GET /orgs/{orgName}/projects":                                utils.PermissionProjectsRead,
+	"POST /orgs/{orgName}/projects":                               utils.PermissionProjectsWrite,
+	"GET /orgs/{orgName}/projects/{projName}":                     utils.PermissionProjectsRead,
+	"DELETE /orgs/{orgName}/projects/{projName}":                  utils.PermissionProjectsWrite,
+	"GET /orgs/{orgName}/projects/{projName}/deployment-pipeline": utils.PermissionProjectsRead,
+
+	"POST /orgs/{orgName}/utils/generate-name":                                                 utils.PermissionAgentsRead,
+	"POST /orgs/{orgName}/projects/{projName}/agents":                                          utils.PermissionAgentsWrite,
+	"GET /orgs/{orgName}/projects/{projName}/agents":                                           utils.PermissionAgentsRead,
+	"GET /orgs/{orgName}/projects/{projName}/agents/{agentName}":                               utils.PermissionAgentsRead,
+	"DELETE /orgs/{orgName}/projects/{projName}/agents/{agentName}":                            utils.PermissionAgentsWrite,
+	"POST /orgs/{orgName}/projects/{projName}/agents/{agentName}/builds":                       utils.PermissionAgentsWrite,
+	"GET /orgs/{orgName}/projects/{projName}/agents/{agentName}/builds":                        utils.PermissionAgentsRead,
+	"GET /orgs/{orgName}/projects/{projName}/agents/{agentName}/builds/{buildName}":            utils.PermissionAgentsRead,
+	"GET /orgs/{orgName}/projects/{projName}/agents/{agentName}/builds/{buildName}/build-logs": utils.PermissionAgentsRead,
+	"POST /orgs/{orgName}/projects/{projName}/agents/{agentName}/deployments":                  utils.PermissionAgentsWrite,
+	"GET /orgs/{orgName}/projects/{projName}/agents/{agentName}/deployments":                   utils.PermissionAgentsRead,
+	"GET /orgs/{orgName}/projects/{projName}/agents/{agentName}/endpoints":                     utils.PermissionAgentsRead,
+	"GET /orgs/{orgName}/projects/{projName}/agents/{agentName}/configurations":                utils.PermissionAgentsRead,
+	"GET /orgs/{orgName}/projects/{projName}/agents/{agentName}/traces":                        utils.PermissionTracesRead,
+	"GET /orgs/{orgName}/projects/{projName}/agents/{agentName}/trace/{traceId}":               utils.PermissionTracesRead,
+	"GET /orgs/{orgName}/agents/{agentId}/traces":                                              utils.PermissionTracesRead,
+	"GET /orgs/{orgName}/agents/{agentId}/metrics":                                             utils.PermissionTracesRead,
+
+	"POST /orgs/{orgName}/agents":                                                 utils.PermissionAgentsWrite,
+	"GET /orgs/{orgName}/agents":                                                  utils.PermissionAgentsRead,
+	"GET /orgs/{orgName}/agents/{agentId}":                                        utils.PermissionAgentsRead,
+	"PUT /orgs/{orgName}/agents/{agentId}":                                        utils.PermissionAgentsWrite,
+	"PATCH /orgs/{orgName}/agents/{agentId}":                                      utils.PermissionAgentsWrite,
+	"DELETE /orgs/{orgName}/agents/{agentId}":                                     utils.PermissionAgentsWrite,
+	"POST /orgs/{orgName}/agents/{agentId}/restore":                               utils.PermissionTrashManage,
+	"GET /orgs/{orgName}/agents/{agentId}/versions":                               utils.PermissionAgentsRead,
+	"GET /orgs/{orgName}/agents/{agentId}/versions/{version}":                     utils.PermissionAgentsRead,
+	"GET /orgs/{orgName}/agents/{agentId}/versions/{version}/diff/{otherVersion}": utils.PermissionAgentsRead,
+	"GET /orgs/{orgName}/agents/{agentId}/tools":                                  utils.PermissionAgentsRead,
+	"POST /orgs/{orgName}/agents/{agentId}/rollback":                              utils.PermissionAgentsWrite,
+	"GET /orgs/{orgName}/agents/{agentId}/export":                                 utils.PermissionAgentsRead,
+	"POST /orgs/{orgName}/agents/import":                                          utils.PermissionAgentsWrite,
+	"POST /orgs/{orgName}/agents:batchUpdate":                                     utils.PermissionAgentsWrite,
+	"GET /frameworks": utils.PermissionAgentsRead,
+
+	"POST /orgs/{orgName}/prompts":                              utils.PermissionPromptsWrite,
+	"GET /orgs/{orgName}/prompts":                               utils.PermissionPromptsRead,
+	"GET /orgs/{orgName}/prompts/{promptId}":                    utils.PermissionPromptsRead,
+	"PUT /orgs/{orgName}/prompts/{promptId}":                    utils.PermissionPromptsWrite,
+	"PATCH /orgs/{orgName}/prompts/{promptId}":                  utils.PermissionPromptsWrite,
+	"DELETE /orgs/{orgName}/prompts/{promptId}":                 utils.PermissionPromptsWrite,
+	"POST /orgs/{orgName}/prompts/{promptId}/restore":           utils.PermissionTrashManage,
+	"GET /orgs/{orgName}/prompts/{promptId}/versions":           utils.PermissionPromptsRead,
+	"GET /orgs/{orgName}/prompts/{promptId}/versions/{version}": utils.PermissionPromptsRead,
+	"POST /orgs/{orgName}/prompts/{promptId}/render":            utils.PermissionPromptsRead,
+
+	"POST /orgs/{orgName}/tools":            utils.PermissionToolsWrite,
+	"GET /orgs/{orgName}/tools":             utils.PermissionToolsRead,
+	"GET /orgs/{orgName}/tools/{toolId}":    utils.PermissionToolsRead,
+	"PUT /orgs/{orgName}/tools/{toolId}":    utils.PermissionToolsWrite,
+	"PATCH /orgs/{orgName}/tools/{toolId}":  utils.PermissionToolsWrite,
+	"DELETE /orgs/{orgName}/tools/{toolId}": utils.PermissionToolsWrite,
+
+	"POST /orgs/{orgName}/api-keys":              utils.PermissionKeysManage,
+	"GET /orgs/{orgName}/api-keys":               utils.PermissionKeysManage,
+	"DELETE /orgs/{orgName}/api-keys/{apiKeyId}": utils.PermissionKeysManage,
+	"GET /orgs/{orgName}/audit-logs":             utils.PermissionAuditRead,
+
+	"POST /orgs/{orgName}/agent-environments":                           utils.PermissionEnvironmentsManage,
+	"GET /orgs/{orgName}/agent-environments":                            utils.PermissionAgentsRead,
+	"GET /orgs/{orgName}/agent-environments/{envName}":                  utils.PermissionAgentsRead,
+	"GET /orgs/{orgName}/agent-environments/{envName}/agents":           utils.PermissionAgentsRead,
+	"GET /orgs/{orgName}/agent-environments/{envName}/agents/{agentId}": utils.PermissionAgentsRead,
+	"POST /orgs/{orgName}/agents/{agentId}/deployments":                 utils.PermissionAgentsWrite,
+	"GET /orgs/{orgName}/agents/{agentId}/deployments":                  utils.PermissionAgentsRead,
+	"POST /orgs/{orgName}/agents/{agentId}/promotions":                  utils.PermissionAgentsWrite,
+
+	"POST /orgs/{orgName}/credentials":                  utils.PermissionCredentialsWrite,
+	"GET /orgs/{orgName}/credentials":                   utils.PermissionCredentialsRead,
+	"POST /orgs/{orgName}/credentials:rotateKey":        utils.PermissionCredentialsRotate,
+	"GET /orgs/{orgName}/credentials/{credentialId}":    utils.PermissionCredentialsRead,
+	"PUT /orgs/{orgName}/credentials/{credentialId}":    utils.PermissionCredentialsWrite,
+	"DELETE /orgs/{orgName}/credentials/{credentialId}": utils.PermissionCredentialsWrite,
+
+	"POST /orgs/{orgName}/webhooks":                                               utils.PermissionWebhooksManage,
+	"GET /orgs/{orgName}/webhooks":                                                utils.PermissionWebhooksManage,
+	"GET /orgs/{orgName}/webhooks/{webhookId}":                                    utils.PermissionWebhooksManage,
+	"PUT /orgs/{orgName}/webhooks/{webhookId}":                                    utils.PermissionWebhooksManage,
+	"PATCH /orgs/{orgName}/webhooks/{webhookId}":                                  utils.PermissionWebhooksManage,
+	"DELETE /orgs/{orgName}/webhooks/{webhookId}":                                 utils.PermissionWebhooksManage,
+	"POST /orgs/{orgName}/webhooks/{webhookId}/test":                              utils.PermissionWebhooksManage,
+	"GET /orgs/{orgName}/webhooks/{webhookId}/deliveries":                         utils.PermissionWebhooksManage,
+	"POST /orgs/{orgName}/webhooks/{webhookId}/deliveries/{deliveryId}/redeliver": utils.PermissionWebhooksManage,
+
+	"GET /orgs/{orgName}/agents/{agentId}/budget":    utils.PermissionAgentsRead,
+	"PUT /orgs/{orgName}/agents/{agentId}/budget":    utils.PermissionAgentsWrite,
+	"DELETE /orgs/{orgName}/agents/{agentId}/budget": utils.PermissionAgentsWrite,
+
+	"POST /orgs/{orgName}/evaluations":                       utils.PermissionEvaluationsWrite,
+	"GET /orgs/{orgName}/evaluations":                        utils.PermissionEvaluationsRead,
+	"GET /orgs/{orgName}/evaluations/{evaluationId}":         utils.PermissionEvaluationsRead,
+	"GET /orgs/{orgName}/evaluations/{evaluationId}/results": utils.PermissionEvaluationsRead,
+
+	"POST /orgs/{orgName}/trace-views":            utils.PermissionTracesRead,
+	"GET /orgs/{orgName}/trace-views":             utils.PermissionTracesRead,
+	"GET /orgs/{orgName}/trace-views/{viewId}":    utils.PermissionTracesRead,
+	"DELETE /orgs/{orgName}/trace-views/{viewId}": utils.PermissionTracesRead,
+}
+
+var routePathParam = regexp.MustCompile(`\{[^}]+\}`)
+
+// Organization the route matrix is run in, requests are bound to it before the permission is checked
+var (
+	testRouteOrgId     = uuid.New()
+	testRouteUserIdpId = uuid.New()
+	testRouteOrgName   = fmt.Sprintf("test-org-%s", uuid.New().String()[:5])
+)
+
+// makeAuthorizationTestApp builds the API for a caller holding roles, withoutRole grants every permission but one
+func makeAuthorizationTestApp(t *testing.T, roles ...string) (http.Handler, *middleware.Authorizer) {
+	cfg := *config.GetConfig()
+	cfg.RBAC.RolePermissions = map[string][]string{}
+	for _, missing := range utils.AllPermissions {
+		role := "without-" + string(missing)
+		cfg.RBAC.RolePermissions[role] = []string{}
+		for _, permission := range utils.AllPermissions {
+			if permission != missing {
+				cfg.RBAC.RolePermissions[role] = append(cfg.RBAC.RolePermissions[role], string(permission))
+			}
+		}
+	}
+	authMiddleware := jwtassertion.NewMockMiddlewareWithRoles(t, testRouteOrgId, testRouteUserIdpId, roles...)
+	params, err := wiring.InitializeTestAppParamsWithClientMocks(&cfg, authMiddleware, wiring.TestClients{})
+	require.NoError(t, err)
+	return api.MakeHTTPHandler(params), params.Authorizer
+}
+
+func sendToRoute(t *testing.T, app http.Handler, pattern string) (int, string) {
+	method, path, _ := strings.Cut(pattern, " ")
+	path = strings.ReplaceAll(path, "{orgName}", testRouteOrgName)
+	path = routePathParam.ReplaceAllString(path, uuid.NewString())
+	rr := sendManagedAgentRequest(t, app, method, "/api/v1"+path, nil, nil)
+	if rr.Code != http.StatusForbidden {
+		return rr.Code, ""
+	}
+	return rr.Code, decodeProblem(t, rr).Detail
+}
+
+func TestRouteAnnotations(t *testing.T) {
+	_, authz := makeAuthorizationTestApp(t)
+
+	registered := map[string]utils.Permission{}
+	for _, route := range authz.Routes() {
+		registered[route.Pattern] = route.Permission
+	}
+	require.Equal(t, expectedProtectedRoutes, registered)
+}
+
+func TestRoutePermissions(t *testing.T) {
+	_ = apitestutils.CreateOrganization(t, testRouteOrgId, testRouteUserIdpId, testRouteOrgName)
+
+	for pattern, permission := range expectedProtectedRoutes {
+		t.Run(fmt.Sprintf("%s without %s should return 403", pattern, permission), func(t *testing.T) {
+			app, _ := makeAuthorizationTestApp(t, "without-"+string(permission))
+			code, detail := sendToRoute(t, app, pattern)
+			require.Equal(t, http.StatusForbidden, code)
+			require.Equal(t, fmt.Sprintf("Missing permission %s", permission), detail)
+		})
+	}
+
+	builtinRoles := utils.DefaultRolePermissions()
+	for _, role := range []string{utils.RoleViewer, utils.RoleEditor} {
+		granted := map[utils.Permission]bool{}
+		for _, permission := range builtinRoles[role] {
+			granted[permission] = true
+		}
+		app, _ := makeAuthorizationTestApp(t, role)
+		for pattern, permission := range expectedProtectedRoutes {
+			if granted[permission] {
+				continue
+			}
+			t.Run(fmt.Sprintf("%s as %s should return 403", pattern, role), func(t *testing.T) {
+				code, detail := sendToRoute(t, app, pattern)
+				require.Equal(t, http.StatusForbidden, code)
+				require.Equal(t, fmt.Sprintf("Missing permission %s", permission), detail)
+			})
+		}
+	}
+
+	t.Run("Roles unknown to the service should grant nothing", func(t *testing.T) {
+		app, _ := makeAuthorizationTestApp(t, "billing")
+		code, detail := sendToRoute(t, app, "GET /orgs")
+		require.Equal(t, http.StatusForbidden, code)
+		require.Equal(t, "Missing permission orgs:read", detail)
+	})
+}
+
+var (
+	testAuthzOrgId     = uuid.New()
+	testAuthzUserIdpId = uuid.New()
+	testAuthzOrgName   = fmt.Sprintf("test-org-%s", uuid.New().String()[:5])
+)
+
+func TestRoleAccess(t *testing.T) {
+	_ = apitestutils.CreateOrganization(t, testAuthzOrgId, testAuthzUserIdpId, testAuthzOrgName)
+	agentsURL := fmt.Sprintf("/api/v1/orgs/%s/agents", testAuthzOrgName)
+	appFor := func(roles ...string) http.Handler {
+		authMiddleware := jwtassertion.NewMockMiddlewareWithRoles(t, testAuthzOrgId, testAuthzUserIdpId, roles...)
+		return apitestutils.MakeAppClientWithDeps(t, wiring.TestClients{}, authMiddleware)
+	}
+
+	t.Run("Editors should create agents", func(t *testing.T) {
+		rr := sendManagedAgentRequest(t, appFor(utils.RoleEditor), http.MethodPost, agentsURL, managedAgentPayload("rbac-agent", nil), nil)
+		require.Equal(t, http.StatusCreated, rr.Code)
+	})
+
+	t.Run("Viewers should list agents", func(t *testing.T) {
+		rr := sendManagedAgentRequest(t, appFor(utils.RoleViewer), http.MethodGet, agentsURL, nil, nil)
+		require.Equal(t, http.StatusOK, rr.Code)
+	})
+
+	t.Run("Tokens without roles should fall back to the default roles", func(t *testing.T) {
+		rr := sendManagedAgentRequest(t, appFor(), http.MethodPost, agentsURL, managedAgentPayload("rbac-default-agent", nil), nil)
+		require.Equal(t, http.StatusCreated, rr.Code)
+	})
+
+	t.Run("Editors should not grant API keys permissions they lack", func(t *testing.T) {
+		payload := map[string]interface{}{"name": "deploy-key", "scopes": []string{"projects:write"}}
+		rr := sendManagedAgentRequest(t, appFor(utils.RoleEditor), http.MethodPost, fmt.Sprintf("/api/v1/orgs/%s/api-keys", testAuthzOrgName), payload, nil)
+		require.Equal(t, http.StatusForbidden, rr.Code)
+		require.Contains(t, decodeProblem(t, rr).Detail, "projects:write")
+	})
+
+	t.Run("API keys should hold the permissions of their scopes", func(t *testing.T) {
+		payload := map[string]interface{}{"name": "read-key", "scopes": []string{"agents:read"}}
+		rr := sendManagedAgentRequest(t, appFor(utils.RoleEditor), http.MethodPost, fmt.Sprintf("/api/v1/orgs/%s/api-keys", testAuthzOrgName), payload, nil)
+		require.Equal(t, http.StatusCreated, rr.Code)
+		var created struct {
+			Key string `json:"key"`
+		}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &created))
+		bearer := map[string]string{"Authorization": "Bearer " + created.Key}
+
+		app := appFor(utils.RoleEditor)
+		rr = sendManagedAgentRequest(t, app, http.MethodGet, agentsURL, nil, bearer)
+		require.Equal(t, http.StatusOK, rr.Code)
+		rr = sendManagedAgentRequest(t, app, http.MethodPost, agentsURL, managedAgentPayload("rbac-key-agent", nil), bearer)
+		require.Equal(t, http.StatusForbidden, rr.Code)
+		require.Equal(t, "Missing permission agents:write", decodeProblem(t, rr).Detail)
+	})
+}
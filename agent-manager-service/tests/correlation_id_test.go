@@ -0,0 +1,95 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package tests
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/middleware/correlation"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/middleware/jwtassertion"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/tests/apitestutils"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/wiring"
+)
+
+func TestCorrelationID(t *testing.T) {
+	orgId := uuid.New()
+	userIdpId := uuid.New()
+	projId := uuid.New()
+	orgName := fmt.Sprintf("corr-org-%s", uuid.New().String()[:5])
+	projName := fmt.Sprintf("corr-project-%s", uuid.New().String()[:5])
+	_ = apitestutils.CreateOrganization(t, orgId, userIdpId, orgName)
+	_ = apitestutils.CreateProject(t, projId, orgId, projName)
+
+	traceObserverClient := createMockTraceObserverClient()
+	app := apitestutils.MakeAppClientWithDeps(t, wiring.TestClients{
+		OpenChoreoSvcClient: createMockOpenChoreoClient(),
+		TraceObserverClient: traceObserverClient,
+	}, jwtassertion.NewMockMiddleware(t, orgId, userIdpId))
+	tracesURL := fmt.Sprintf("/api/v1/orgs/%s/projects/%s/agents/corr-agent/traces?environment=Development", orgName, projName)
+
+	send := func(correlationID string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, tracesURL, nil)
+		if correlationID != "" {
+			req.Header.Set(correlation.Header, correlationID)
+		}
+		rr := httptest.NewRecorder()
+		app.ServeHTTP(rr, req)
+		return rr
+	}
+
+	t.Run("A correlation id sent by the client should be returned and forwarded", func(t *testing.T) {
+		rr := send("frontend-1234")
+		require.Equal(t, http.StatusOK, rr.Code)
+		require.Equal(t, "frontend-1234", rr.Header().Get(correlation.Header))
+		calls := traceObserverClient.ListTracesCalls()
+		forwarded, ok := correlation.FromContext(calls[len(calls)-1].Ctx)
+		require.True(t, ok)
+		require.Equal(t, "frontend-1234", forwarded)
+	})
+
+	t.Run("Requests without a correlation id should be assigned one", func(t *testing.T) {
+		rr := send("")
+		_, err := uuid.Parse(rr.Header().Get(correlation.Header))
+		require.NoError(t, err)
+	})
+
+	t.Run("Correlation ids that could forge log lines should be replaced", func(t *testing.T) {
+		for _, id := range []string{"bad id\n{\"level\":\"ERROR\"}", strings.Repeat("a", 129)} {
+			rr := send(id)
+			returned := rr.Header().Get(correlation.Header)
+			require.NotEqual(t, id, returned)
+			_, err := uuid.Parse(returned)
+			require.NoError(t, err)
+		}
+	})
+
+	t.Run("Failed requests should carry the correlation id", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/orgs/unknown-org/agents", nil)
+		req.Header.Set(correlation.Header, "frontend-5678")
+		app.ServeHTTP(rr, req)
+		require.Equal(t, http.StatusNotFound, rr.Code)
+		require.Equal(t, "frontend-5678", rr.Header().Get(correlation.Header))
+	})
+}
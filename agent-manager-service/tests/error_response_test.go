@@ -0,0 +1,88 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package tests
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/middleware"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/utils"
+)
+
+func TestErrorProblems(t *testing.T) {
+	writeError := func(err error) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		utils.WriteErrorProblem(rr, httptest.NewRequest(http.MethodGet, "/orgs/acme", nil), err, "Failed to serve the request")
+		return rr
+	}
+
+	statusTests := []struct {
+		name       string
+		err        error
+		wantStatus int
+	}{
+		{name: "not found", err: utils.ErrAgentNotFound, wantStatus: http.StatusNotFound},
+		{name: "conflict", err: utils.ErrToolAlreadyExists, wantStatus: http.StatusConflict},
+		{name: "permission", err: utils.ErrPermissionDenied, wantStatus: http.StatusForbidden},
+		{name: "stale write", err: utils.ErrPromptVersionMismatch, wantStatus: http.StatusPreconditionFailed},
+		{name: "unknown", err: errors.New("connection refused"), wantStatus: http.StatusInternalServerError},
+	}
+	for _, tt := range statusTests {
+		t.Run(fmt.Sprintf("A wrapped %s error should return %d", tt.name, tt.wantStatus), func(t *testing.T) {
+			rr := writeError(fmt.Errorf("service failed: %w", tt.err))
+			require.Equal(t, tt.wantStatus, rr.Code)
+			decodeProblem(t, rr)
+		})
+	}
+
+	t.Run("A validation error should return 400 listing the rejected fields", func(t *testing.T) {
+		validationErr := &utils.ValidationError{}
+		validationErr.Add("name", "is required")
+		rr := writeError(fmt.Errorf("invalid: %w", validationErr))
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Equal(t, []string{"name"}, problemFields(decodeProblem(t, rr)))
+	})
+
+	t.Run("Unknown errors should not be shown to clients", func(t *testing.T) {
+		rr := writeError(errors.New("dial tcp 10.0.0.5:5432: connection refused"))
+		require.Equal(t, "Failed to serve the request", decodeProblem(t, rr).Detail)
+		require.NotContains(t, rr.Body.String(), "10.0.0.5")
+	})
+}
+
+func TestPanicRecovery(t *testing.T) {
+	handler := middleware.RecovererOnPanic()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("nil map assignment")
+	}))
+	handler = middleware.AddCorrelationID()(handler)
+
+	req := httptest.NewRequest(http.MethodGet, "/orgs/acme/agents", nil)
+	req.Header.Set(middleware.CorrelationIDHeader, "report-me-42")
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, req)
+
+	require.Equal(t, http.StatusInternalServerError, rr.Code)
+	problem := decodeProblem(t, rr)
+	require.Equal(t, "report-me-42", problem.CorrelationID)
+	require.NotContains(t, rr.Body.String(), "nil map assignment")
+}
@@ -0,0 +1,274 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/clients/clientmocks"
+	traceobserversvc "github.com/wso2/ai-agent-management-platform/agent-manager-service/clients/traceobserversvc"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/config"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/middleware/jwtassertion"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/models"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/repositories"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/services"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/tests/apitestutils"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/utils"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/wiring"
+)
+
+var (
+	testEvaluationOrgId     = uuid.New()
+	testEvaluationUserIdpId = uuid.New()
+	testEvaluationOrgName   = fmt.Sprintf("test-org-%s", uuid.New().String()[:5])
+)
+
+// evaluationTraces serves a fixed list of traces page by page, the cursor being the index of the next trace
+type evaluationTraces struct {
+	mu     sync.Mutex
+	traces []traceobserversvc.TraceOverview
+	// Cursor whose page fails once, as if the worker had crashed reading it
+	failCursor string
+}
+
+func newEvaluationTraces(count int) *evaluationTraces {
+	traces := make([]traceobserversvc.TraceOverview, 0, count)
+	for i := 0; i < count; i++ {
+		trace := traceobserversvc.TraceOverview{
+			TraceID: fmt.Sprintf("trace-%02d", i),
+			Input:   fmt.Sprintf("question %d", i),
+			Output:  fmt.Sprintf("answer %d", i),
+			Status:  &traceobserversvc.TraceStatus{},
+		}
+		// Every fifth trace has no answer and a failed tool call
+		if i%5 == 4 {
+			trace.Output = ""
+			trace.Status = &traceobserversvc.TraceStatus{ErrorCount: 1, ToolErrorCount: 1}
+		}
+		traces = append(traces, trace)
+	}
+	return &evaluationTraces{traces: traces}
+}
+
+func (e *evaluationTraces) listTraces(ctx context.Context, params traceobserversvc.ListTracesParams) (*traceobserversvc.TraceOverviewResponse, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if params.Cursor != "" && params.Cursor == e.failCursor {
+		e.failCursor = ""
+		return nil, errors.New("trace observer unavailable")
+	}
+	start := 0
+	if params.Cursor != "" {
+		start, _ = strconv.Atoi(params.Cursor)
+	}
+	end := min(start+params.Limit, len(e.traces))
+	response := &traceobserversvc.TraceOverviewResponse{Traces: e.traces[start:end], TotalCount: len(e.traces)}
+	if end < len(e.traces) {
+		response.NextCursor = strconv.Itoa(end)
+	}
+	return response, nil
+}
+
+// newHTTPEvaluator scores answers 0.9 and empty outputs 0.1, and fails on trace-02
+func newHTTPEvaluator(t *testing.T) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req models.HTTPEvaluatorRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		require.NotEmpty(t, req.EvaluationID)
+		if req.TraceID == "trace-02" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		score := 0.1
+		if output, _ := req.Output.(string); strings.HasPrefix(output, "answer") {
+			score = 0.9
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"score": score, "reason": "judged"})
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestEvaluations(t *testing.T) {
+	_ = apitestutils.CreateOrganization(t, testEvaluationOrgId, testEvaluationUserIdpId, testEvaluationOrgName)
+	authMiddleware := jwtassertion.NewMockMiddleware(t, testEvaluationOrgId, testEvaluationUserIdpId)
+	orgURL := fmt.Sprintf("/api/v1/orgs/%s", testEvaluationOrgName)
+
+	traces := newEvaluationTraces(45)
+	traceObserverClient := &clientmocks.TraceObserverClientMock{ListTracesFunc: traces.listTraces}
+	strictApp := apitestutils.MakeAppClientWithDeps(t, wiring.TestClients{TraceObserverClient: traceObserverClient}, authMiddleware)
+	// The evaluator of the tests listens on loopback
+	previous := config.GetConfig().Evaluation.AllowPrivateNetworks
+	t.Cleanup(func() { config.GetConfig().Evaluation.AllowPrivateNetworks = previous })
+	config.GetConfig().Evaluation.AllowPrivateNetworks = true
+	app := apitestutils.MakeAppClientWithDeps(t, wiring.TestClients{TraceObserverClient: traceObserverClient}, authMiddleware)
+	evaluator := newHTTPEvaluator(t)
+	worker := services.NewEvaluationService(repositories.NewOrganizationRepository(), repositories.NewManagedAgentRepository(),
+		repositories.NewEvaluationRepository(), traceObserverClient, evaluator.Client(), slog.Default(), 5*time.Second, true)
+
+	rr := sendManagedAgentRequest(t, app, http.MethodPost, orgURL+"/agents", managedAgentPayload("evaluated-agent", nil), nil)
+	require.Equal(t, http.StatusCreated, rr.Code)
+	var agent models.ManagedAgentResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &agent))
+	startTime := time.Now().Add(-24 * time.Hour).UTC().Format(time.RFC3339)
+
+	getEvaluation := func(t *testing.T, id string) models.EvaluationResponse {
+		rr := sendManagedAgentRequest(t, app, http.MethodGet, orgURL+"/evaluations/"+id, nil, nil)
+		require.Equal(t, http.StatusOK, rr.Code)
+		var evaluation models.EvaluationResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &evaluation))
+		return evaluation
+	}
+
+	t.Run("Creating an invalid evaluation should return 400", func(t *testing.T) {
+		rr := sendManagedAgentRequest(t, app, http.MethodPost, orgURL+"/evaluations", map[string]interface{}{
+			"filter":    map[string]interface{}{"agentId": agent.ID},
+			"evaluator": map[string]interface{}{"type": "regex", "pattern": "("},
+		}, nil)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.ElementsMatch(t, []string{"filter.startTime", "evaluator.pattern"}, problemFields(decodeProblem(t, rr)))
+	})
+
+	t.Run("Evaluating the traces of an unknown agent should return 404", func(t *testing.T) {
+		rr := sendManagedAgentRequest(t, app, http.MethodPost, orgURL+"/evaluations", map[string]interface{}{
+			"filter":    map[string]interface{}{"agentId": uuid.NewString(), "startTime": startTime},
+			"evaluator": map[string]interface{}{"type": "output_non_empty"},
+		}, nil)
+		require.Equal(t, http.StatusNotFound, rr.Code)
+	})
+
+	t.Run("An http evaluator on a non-public address should return 400", func(t *testing.T) {
+		for _, target := range []string{evaluator.URL, "http://169.254.169.254/latest/meta-data/", "http://192.168.1.20/score"} {
+			rr := sendManagedAgentRequest(t, strictApp, http.MethodPost, orgURL+"/evaluations", map[string]interface{}{
+				"filter":    map[string]interface{}{"agentId": agent.ID, "startTime": startTime},
+				"evaluator": map[string]interface{}{"type": "http", "url": target},
+			}, nil)
+			require.Equal(t, http.StatusBadRequest, rr.Code, target)
+			require.Equal(t, []string{"evaluator.url"}, problemFields(decodeProblem(t, rr)))
+		}
+	})
+
+	t.Run("An http evaluator should score every trace and record its failures", func(t *testing.T) {
+		rr := sendManagedAgentRequest(t, app, http.MethodPost, orgURL+"/evaluations", map[string]interface{}{
+			"name":      "judge",
+			"filter":    map[string]interface{}{"agentId": agent.ID, "startTime": startTime, "maxTraces": 10},
+			"evaluator": map[string]interface{}{"type": "http", "url": evaluator.URL},
+		}, nil)
+		require.Equal(t, http.StatusAccepted, rr.Code)
+		var created models.EvaluationResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &created))
+		require.Equal(t, utils.EvaluationStatusPending, created.Status)
+		require.Equal(t, 0.5, *created.Evaluator.PassThreshold)
+		require.NotNil(t, created.Filter.EndTime)
+		require.Equal(t, rr.Header().Get("Location"), orgURL+"/evaluations/"+created.ID)
+
+		require.NoError(t, worker.ProcessEvaluations(context.Background()))
+		evaluation := getEvaluation(t, created.ID)
+		require.Equal(t, utils.EvaluationStatusCompleted, evaluation.Status)
+		require.Equal(t, int32(10), evaluation.Progress.TotalTraces)
+		require.Equal(t, int32(10), evaluation.Progress.EvaluatedTraces)
+		require.Equal(t, 100.0, evaluation.Progress.Percent)
+		// trace-02 errored, trace-04 and trace-09 have no answer
+		require.Equal(t, models.EvaluationSummary{Passed: 7, Failed: 2, Errored: 1, PassRate: floatPtr(0.7778), AverageScore: floatPtr(0.7222)}, evaluation.Summary)
+		require.NotNil(t, evaluation.StartedAt)
+		require.NotNil(t, evaluation.CompletedAt)
+
+		rr = sendManagedAgentRequest(t, app, http.MethodGet, orgURL+"/evaluations/"+created.ID+"/results?limit=20", nil, nil)
+		require.Equal(t, http.StatusOK, rr.Code)
+		var results models.EvaluationResultListResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &results))
+		require.Equal(t, int32(10), results.Total)
+		byTrace := map[string]models.EvaluationResultResponse{}
+		for _, result := range results.Results {
+			byTrace[result.TraceID] = result
+		}
+		require.Nil(t, byTrace["trace-02"].Score)
+		require.Contains(t, byTrace["trace-02"].Error, "status 500")
+		require.False(t, byTrace["trace-04"].Passed)
+		require.Equal(t, 0.1, *byTrace["trace-04"].Score)
+		require.True(t, byTrace["trace-05"].Passed)
+		require.Equal(t, "judged", byTrace["trace-05"].Reason)
+	})
+
+	t.Run("A run interrupted mid way should resume from its checkpoint", func(t *testing.T) {
+		rr := sendManagedAgentRequest(t, app, http.MethodPost, orgURL+"/evaluations", map[string]interface{}{
+			"filter":    map[string]interface{}{"agentId": agent.ID, "startTime": startTime},
+			"evaluator": map[string]interface{}{"type": "no_tool_errors"},
+		}, nil)
+		require.Equal(t, http.StatusAccepted, rr.Code)
+		var created models.EvaluationResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &created))
+
+		traces.failCursor = "40"
+		require.Error(t, worker.ProcessEvaluations(context.Background()))
+		evaluation := getEvaluation(t, created.ID)
+		require.Equal(t, utils.EvaluationStatusRunning, evaluation.Status)
+		require.Equal(t, int32(45), evaluation.Progress.TotalTraces)
+		require.Equal(t, int32(40), evaluation.Progress.EvaluatedTraces)
+		require.Equal(t, 88.89, evaluation.Progress.Percent)
+
+		calls := len(traceObserverClient.ListTracesCalls())
+		require.NoError(t, worker.ProcessEvaluations(context.Background()))
+		resumed := traceObserverClient.ListTracesCalls()[calls:]
+		require.Len(t, resumed, 1)
+		require.Equal(t, "40", resumed[0].Params.Cursor)
+		require.Equal(t, agent.ID, resumed[0].Params.AgentID)
+		require.Equal(t, "asc", resumed[0].Params.SortOrder)
+
+		evaluation = getEvaluation(t, created.ID)
+		require.Equal(t, utils.EvaluationStatusCompleted, evaluation.Status)
+		require.Equal(t, int32(45), evaluation.Progress.EvaluatedTraces)
+		require.Equal(t, int32(36), evaluation.Summary.Passed)
+		require.Equal(t, int32(9), evaluation.Summary.Failed)
+		require.Equal(t, int32(0), evaluation.Summary.Errored)
+	})
+
+	t.Run("Listing evaluations should return the newest first", func(t *testing.T) {
+		rr := sendManagedAgentRequest(t, app, http.MethodGet, orgURL+"/evaluations", nil, nil)
+		require.Equal(t, http.StatusOK, rr.Code)
+		var list models.EvaluationListResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &list))
+		require.Equal(t, int32(2), list.Total)
+		require.Equal(t, utils.EvaluatorNoToolErrors, list.Evaluations[0].Evaluator.Type)
+		require.Equal(t, "judge", list.Evaluations[1].Name)
+	})
+
+	t.Run("Getting an unknown evaluation should return 404", func(t *testing.T) {
+		rr := sendManagedAgentRequest(t, app, http.MethodGet, orgURL+"/evaluations/"+uuid.NewString(), nil, nil)
+		require.Equal(t, http.StatusNotFound, rr.Code)
+		rr = sendManagedAgentRequest(t, app, http.MethodGet, orgURL+"/evaluations/not-a-uuid/results", nil, nil)
+		require.Equal(t, http.StatusNotFound, rr.Code)
+	})
+}
+
+func floatPtr(f float64) *float64 {
+	return &f
+}
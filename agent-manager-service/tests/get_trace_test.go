@@ -160,6 +160,7 @@ func TestGetTrace(t *testing.T) {
 		traceDetailsCall := traceObserverClient.TraceDetailsByIdCalls()[0]
 		require.Equal(t, traceID, traceDetailsCall.Params.TraceID)
 		require.Equal(t, traceDetailsAgentName, traceDetailsCall.Params.ServiceName)
+		require.Equal(t, traceDetailsOrgId.String(), traceDetailsCall.Params.OrgID)
 		// Note: limit and sortOrder are hardcoded internally and not exposed as API parameters
 	})
 
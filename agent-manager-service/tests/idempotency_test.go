@@ -0,0 +1,166 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/db"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/middleware"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/middleware/jwtassertion"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/models"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/repositories"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/services"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/tests/apitestutils"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/wiring"
+)
+
+var (
+	testIdempotencyOrgId     = uuid.New()
+	testIdempotencyUserIdpId = uuid.New()
+	testIdempotencyOrgName   = fmt.Sprintf("test-org-%s", uuid.New().String()[:5])
+)
+
+func TestIdempotencyKey(t *testing.T) {
+	_ = apitestutils.CreateOrganization(t, testIdempotencyOrgId, testIdempotencyUserIdpId, testIdempotencyOrgName)
+	authMiddleware := jwtassertion.NewMockMiddleware(t, testIdempotencyOrgId, testIdempotencyUserIdpId)
+	app := apitestutils.MakeAppClientWithDeps(t, wiring.TestClients{}, authMiddleware)
+	url := fmt.Sprintf("/api/v1/orgs/%s/prompts", testIdempotencyOrgName)
+	key := map[string]string{middleware.IdempotencyKeyHeader: uuid.NewString()}
+	payload := map[string]interface{}{"name": "idempotent-prompt", "template": "Hello {{name}}"}
+
+	var created models.PromptTemplateResponse
+	t.Run("The first request with a key should run", func(t *testing.T) {
+		rr := sendManagedAgentRequest(t, app, http.MethodPost, url, payload, key)
+		require.Equal(t, http.StatusCreated, rr.Code)
+		require.Empty(t, rr.Header().Get(middleware.IdempotentReplayedHeader))
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &created))
+	})
+
+	t.Run("A retry with the same key and body should replay the first response", func(t *testing.T) {
+		rr := sendManagedAgentRequest(t, app, http.MethodPost, url, payload, key)
+		require.Equal(t, http.StatusCreated, rr.Code)
+		require.Equal(t, "true", rr.Header().Get(middleware.IdempotentReplayedHeader))
+		require.Equal(t, `"1"`, rr.Header().Get("ETag"))
+		var replayed models.PromptTemplateResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &replayed))
+		require.Equal(t, created.ID, replayed.ID)
+	})
+
+	t.Run("A retry with the same key and a different body should return 422", func(t *testing.T) {
+		changed := map[string]interface{}{"name": "other-prompt", "template": "Hello {{name}}"}
+		rr := sendManagedAgentRequest(t, app, http.MethodPost, url, changed, key)
+		require.Equal(t, http.StatusUnprocessableEntity, rr.Code)
+		decodeProblem(t, rr)
+	})
+
+	t.Run("A request without a key should run again", func(t *testing.T) {
+		rr := sendManagedAgentRequest(t, app, http.MethodPost, url, payload, nil)
+		require.Equal(t, http.StatusConflict, rr.Code)
+		require.Empty(t, rr.Header().Get(middleware.IdempotentReplayedHeader))
+	})
+}
+
+func TestIdempotencyKeyDoesNotRecordSecrets(t *testing.T) {
+	orgId, userIdpId := uuid.New(), uuid.New()
+	orgName := fmt.Sprintf("test-org-%s", uuid.New().String()[:5])
+	_ = apitestutils.CreateOrganization(t, orgId, userIdpId, orgName)
+	authMiddleware := jwtassertion.NewMockMiddleware(t, orgId, userIdpId)
+	app := apitestutils.MakeAppClientWithDeps(t, wiring.TestClients{}, authMiddleware)
+	url := fmt.Sprintf("/api/v1/orgs/%s/api-keys", orgName)
+	idempotencyKey := uuid.NewString()
+	key := map[string]string{middleware.IdempotencyKeyHeader: idempotencyKey}
+	payload := map[string]interface{}{"name": "idempotent-key", "scopes": []string{"agents:read"}}
+
+	rr := sendManagedAgentRequest(t, app, http.MethodPost, url, payload, key)
+	require.Equal(t, http.StatusCreated, rr.Code)
+	var created models.APIKeyCreateResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &created))
+	require.NotEmpty(t, created.Key)
+
+	t.Run("The response of a created API key should not be stored", func(t *testing.T) {
+		var stored int64
+		require.NoError(t, db.DB(context.Background()).Raw(
+			"SELECT count(*) FROM idempotency_keys WHERE idempotency_key = ? OR position(? in encode(response_body, 'escape')) > 0",
+			idempotencyKey, created.Key).Scan(&stored).Error)
+		require.Zero(t, stored)
+	})
+
+	t.Run("A retry should not replay the secret", func(t *testing.T) {
+		rr := sendManagedAgentRequest(t, app, http.MethodPost, url, payload, key)
+		require.Empty(t, rr.Header().Get(middleware.IdempotentReplayedHeader))
+		require.NotContains(t, rr.Body.String(), created.Key)
+	})
+}
+
+func TestIdempotencyKeyConcurrentRetries(t *testing.T) {
+	service := services.NewIdempotencyService(repositories.NewIdempotencyRepository(), slog.Default(), time.Hour, time.Minute)
+	release := make(chan struct{})
+	started := make(chan struct{})
+	var runs atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /jobs", func(w http.ResponseWriter, r *http.Request) {
+		if runs.Add(1) == 1 {
+			close(started)
+			<-release
+		}
+		w.WriteHeader(http.StatusAccepted)
+	})
+	handler := middleware.Idempotency(mux, service)(mux)
+	authMiddleware := jwtassertion.NewMockMiddleware(t, uuid.New(), uuid.New())
+	app := authMiddleware(handler)
+	send := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/jobs", strings.NewReader(`{}`))
+		req.Header.Set(middleware.IdempotencyKeyHeader, "concurrent-key")
+		rr := httptest.NewRecorder()
+		app.ServeHTTP(rr, req)
+		return rr
+	}
+
+	first := make(chan *httptest.ResponseRecorder, 1)
+	go func() { first <- send() }()
+	<-started
+
+	t.Run("A retry while the first request runs should return 409 with Retry-After", func(t *testing.T) {
+		rr := send()
+		require.Equal(t, http.StatusConflict, rr.Code)
+		require.NotEmpty(t, rr.Header().Get("Retry-After"))
+		decodeProblem(t, rr)
+	})
+
+	close(release)
+	require.Equal(t, http.StatusAccepted, (<-first).Code)
+
+	t.Run("A retry after the first request completed should replay it", func(t *testing.T) {
+		rr := send()
+		require.Equal(t, http.StatusAccepted, rr.Code)
+		require.Equal(t, "true", rr.Header().Get(middleware.IdempotentReplayedHeader))
+		require.Equal(t, int32(1), runs.Load())
+	})
+}
@@ -0,0 +1,194 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/config"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/middleware/jwtassertion"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/models"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/tests/apitestutils"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/wiring"
+)
+
+var (
+	testLabelsOrgId     = uuid.New()
+	testLabelsUserIdpId = uuid.New()
+	testLabelsOrgName   = fmt.Sprintf("test-org-%s", uuid.New().String()[:5])
+)
+
+func TestLabels(t *testing.T) {
+	_ = apitestutils.CreateOrganization(t, testLabelsOrgId, testLabelsUserIdpId, testLabelsOrgName)
+	authMiddleware := jwtassertion.NewMockMiddleware(t, testLabelsOrgId, testLabelsUserIdpId)
+	app := apitestutils.MakeAppClientWithDeps(t, wiring.TestClients{}, authMiddleware)
+	orgURL := fmt.Sprintf("/api/v1/orgs/%s", testLabelsOrgName)
+
+	for _, prompt := range []struct {
+		name   string
+		labels map[string]string
+	}{
+		{name: "billing-prompt", labels: map[string]string{"team": "billing", "env": "prod"}},
+		{name: "support-prompt", labels: map[string]string{"team": "support", "example.com/tier": "gold"}},
+		{name: "draft-prompt", labels: nil},
+	} {
+		payload := map[string]interface{}{"name": prompt.name, "template": "Hello {{customer_name}}", "labels": prompt.labels}
+		rr := sendManagedAgentRequest(t, app, http.MethodPost, orgURL+"/prompts", payload, nil)
+		require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+	}
+	for _, tool := range []struct {
+		name   string
+		labels map[string]string
+	}{
+		{name: "billing_lookup", labels: map[string]string{"team": "billing"}},
+		{name: "search_docs", labels: map[string]string{"team": "support"}},
+	} {
+		payload := toolPayload(tool.name)
+		payload["labels"] = tool.labels
+		rr := sendManagedAgentRequest(t, app, http.MethodPost, orgURL+"/tools", payload, nil)
+		require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+	}
+
+	promptTests := []struct {
+		name      string
+		selector  string
+		wantNames []string
+	}{
+		{name: "equality", selector: "team=billing", wantNames: []string{"billing-prompt"}},
+		{name: "inequality", selector: "team!=billing", wantNames: []string{"draft-prompt", "support-prompt"}},
+		{name: "set", selector: "team%20in%20(billing,support)", wantNames: []string{"billing-prompt", "support-prompt"}},
+		{name: "excluded set", selector: "team%20notin%20(support)", wantNames: []string{"billing-prompt", "draft-prompt"}},
+		{name: "existence", selector: "example.com/tier", wantNames: []string{"support-prompt"}},
+		{name: "absence", selector: "!team", wantNames: []string{"draft-prompt"}},
+		{name: "several requirements", selector: "team,env=prod", wantNames: []string{"billing-prompt"}},
+	}
+	for _, tt := range promptTests {
+		t.Run(fmt.Sprintf("Listing prompts by %s label selector should return 200", tt.name), func(t *testing.T) {
+			rr := sendManagedAgentRequest(t, app, http.MethodGet, orgURL+"/prompts?labelSelector="+tt.selector, nil, nil)
+			require.Equal(t, http.StatusOK, rr.Code)
+			var list models.PromptTemplateListResponse
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &list))
+			names := make([]string, 0, len(list.Prompts))
+			for _, prompt := range list.Prompts {
+				names = append(names, prompt.Name)
+			}
+			require.Equal(t, tt.wantNames, names)
+		})
+	}
+
+	t.Run("Updating the labels of a prompt should change which selectors match it", func(t *testing.T) {
+		rr := sendManagedAgentRequest(t, app, http.MethodGet, orgURL+"/prompts?labelSelector=!team", nil, nil)
+		require.Equal(t, http.StatusOK, rr.Code)
+		var list models.PromptTemplateListResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &list))
+		require.Len(t, list.Prompts, 1)
+		draft := list.Prompts[0]
+		require.Equal(t, map[string]string{}, draft.Labels)
+
+		payload := map[string]interface{}{"name": draft.Name, "template": draft.Template, "labels": map[string]string{"team": "ml"}}
+		rr = sendManagedAgentRequest(t, app, http.MethodPut, orgURL+"/prompts/"+draft.ID, payload, map[string]string{"If-Match": `"1"`})
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+		rr = sendManagedAgentRequest(t, app, http.MethodGet, orgURL+"/prompts?labelSelector=team=ml", nil, nil)
+		require.Equal(t, http.StatusOK, rr.Code)
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &list))
+		require.Len(t, list.Prompts, 1)
+		require.Equal(t, draft.ID, list.Prompts[0].ID)
+		require.Equal(t, map[string]string{"team": "ml"}, list.Prompts[0].Labels)
+	})
+
+	t.Run("Listing tools by label selector should return 200", func(t *testing.T) {
+		rr := sendManagedAgentRequest(t, app, http.MethodGet, orgURL+"/tools?labelSelector=team!=billing", nil, nil)
+		require.Equal(t, http.StatusOK, rr.Code)
+		var list models.ToolListResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &list))
+		require.Len(t, list.Tools, 1)
+		require.Equal(t, "search_docs", list.Tools[0].Name)
+		require.Equal(t, map[string]string{"team": "support"}, list.Tools[0].Labels)
+	})
+
+	for _, url := range []string{orgURL + "/prompts", orgURL + "/tools", orgURL + "/agents"} {
+		t.Run(fmt.Sprintf("Listing %s with an invalid label selector should return 400", url), func(t *testing.T) {
+			rr := sendManagedAgentRequest(t, app, http.MethodGet, url+"?labelSelector=team%20in%20(billing", nil, nil)
+			require.Equal(t, http.StatusBadRequest, rr.Code)
+			decodeProblem(t, rr)
+		})
+	}
+
+	labelValidationTests := []struct {
+		name       string
+		url        string
+		payload    map[string]interface{}
+		wantFields []string
+	}{
+		{
+			name:       "a prompt with an invalid label key prefix",
+			url:        orgURL + "/prompts",
+			payload:    map[string]interface{}{"name": "bad-prompt", "template": "Hi", "labels": map[string]string{"Example.com/team": "x"}},
+			wantFields: []string{"labels.Example.com/team"},
+		},
+		{
+			name: "a tool with an invalid label value",
+			url:  orgURL + "/tools",
+			payload: func() map[string]interface{} {
+				payload := toolPayload("bad_tool")
+				payload["labels"] = map[string]string{"team": "-ml"}
+				return payload
+			}(),
+			wantFields: []string{"labels.team"},
+		},
+	}
+	for _, tt := range labelValidationTests {
+		t.Run(fmt.Sprintf("Creating %s should return 400", tt.name), func(t *testing.T) {
+			rr := sendManagedAgentRequest(t, app, http.MethodPost, tt.url, tt.payload, nil)
+			require.Equal(t, http.StatusBadRequest, rr.Code)
+			require.ElementsMatch(t, tt.wantFields, problemFields(decodeProblem(t, rr)))
+		})
+	}
+
+	var agent models.ManagedAgentResponse
+	rr := sendManagedAgentRequest(t, app, http.MethodPost, orgURL+"/agents", managedAgentPayload("labelled-agent", map[string]string{"team": "support", "env": "prod"}), nil)
+	require.Equal(t, http.StatusCreated, rr.Code)
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &agent))
+	internalHeaders := map[string]string{config.GetConfig().APIKeyHeader: config.GetConfig().APIKeyValue}
+
+	t.Run("Getting the labels of an agent through the internal API should return 200", func(t *testing.T) {
+		rr := sendManagedAgentRequest(t, app, http.MethodGet, "/internal/agents/"+agent.ID+"/labels", nil, internalHeaders)
+		require.Equal(t, http.StatusOK, rr.Code)
+		var labels models.ManagedAgentLabelsResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &labels))
+		require.Equal(t, agent.ID, labels.AgentID)
+		require.Equal(t, testLabelsOrgId.String(), labels.OrgID)
+		require.Equal(t, map[string]string{"team": "support", "env": "prod"}, labels.Labels)
+	})
+
+	t.Run("Getting the labels of an unknown agent through the internal API should return 404", func(t *testing.T) {
+		rr := sendManagedAgentRequest(t, app, http.MethodGet, "/internal/agents/"+uuid.New().String()+"/labels", nil, internalHeaders)
+		require.Equal(t, http.StatusNotFound, rr.Code)
+	})
+
+	t.Run("Getting the labels of an agent without the internal API key should return 401", func(t *testing.T) {
+		rr := sendManagedAgentRequest(t, app, http.MethodGet, "/internal/agents/"+agent.ID+"/labels", nil, nil)
+		require.Equal(t, http.StatusUnauthorized, rr.Code)
+	})
+}
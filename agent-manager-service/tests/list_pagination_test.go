@@ -0,0 +1,208 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/middleware/jwtassertion"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/models"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/tests/apitestutils"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/utils"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/wiring"
+)
+
+var (
+	testPaginationOrgId     = uuid.New()
+	testPaginationUserIdpId = uuid.New()
+	testPaginationOrgName   = fmt.Sprintf("test-org-%s", uuid.New().String()[:5])
+)
+
+// listPage is a page of any listing, its results are kept under the key of the listing
+type listPage struct {
+	items      []map[string]interface{}
+	total      *int32
+	nextCursor string
+}
+
+func getListPage(t *testing.T, app http.Handler, listURL string, listKey string, query url.Values) listPage {
+	t.Helper()
+	rr := sendManagedAgentRequest(t, app, http.MethodGet, listURL+"?"+query.Encode(), nil, nil)
+	require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+	var body map[string]json.RawMessage
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &body))
+	var page listPage
+	require.NoError(t, json.Unmarshal(body[listKey], &page.items))
+	if total, ok := body["total"]; ok {
+		require.NoError(t, json.Unmarshal(total, &page.total))
+	}
+	if cursor, ok := body["nextCursor"]; ok {
+		require.NoError(t, json.Unmarshal(cursor, &page.nextCursor))
+	}
+	return page
+}
+
+// listAll follows the cursors of a listing to its end and returns the given field of every result
+func listAll(t *testing.T, app http.Handler, listURL string, listKey string, field string, query url.Values) []string {
+	t.Helper()
+	values := []string{}
+	for {
+		page := getListPage(t, app, listURL, listKey, query)
+		for _, item := range page.items {
+			values = append(values, item[field].(string))
+		}
+		if page.nextCursor == "" {
+			return values
+		}
+		query.Set("cursor", page.nextCursor)
+	}
+}
+
+func TestListPagination(t *testing.T) {
+	_ = apitestutils.CreateOrganization(t, testPaginationOrgId, testPaginationUserIdpId, testPaginationOrgName)
+	useCredentialKeys(t, map[string]string{"key-a": testCredentialKeyA}, "key-a")
+	authMiddleware := jwtassertion.NewMockMiddleware(t, testPaginationOrgId, testPaginationUserIdpId)
+	app := apitestutils.MakeAppClientWithDeps(t, wiring.TestClients{}, authMiddleware)
+	orgURL := fmt.Sprintf("/api/v1/orgs/%s", testPaginationOrgName)
+	agentsURL := orgURL + "/agents"
+
+	agentIds := map[string]string{}
+	createAgent := func(t *testing.T, name string) {
+		rr := sendManagedAgentRequest(t, app, http.MethodPost, agentsURL, managedAgentPayload(name, nil), nil)
+		require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+		var agent models.ManagedAgentResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &agent))
+		agentIds[name] = agent.ID
+	}
+	for _, name := range []string{"page-c", "page-a", "page-e", "page-b", "page-d"} {
+		createAgent(t, name)
+	}
+
+	t.Run("Listing agents page by page should follow the cursors to the last page", func(t *testing.T) {
+		query := url.Values{"limit": {"2"}}
+		page := getListPage(t, app, agentsURL, "agents", query)
+		require.Len(t, page.items, 2)
+		require.Nil(t, page.total)
+		require.NotEmpty(t, page.nextCursor)
+
+		require.Equal(t, []string{"page-a", "page-b", "page-c", "page-d", "page-e"}, listAll(t, app, agentsURL, "agents", "name", query))
+	})
+
+	t.Run("Listing agents with count should return the total", func(t *testing.T) {
+		page := getListPage(t, app, agentsURL, "agents", url.Values{"limit": {"2"}, "count": {"true"}})
+		require.NotNil(t, page.total)
+		require.Equal(t, int32(5), *page.total)
+	})
+
+	t.Run("Listing agents should sort by creation time in either order", func(t *testing.T) {
+		query := url.Values{"limit": {"2"}, "sort": {models.ListSortCreatedAt}, "sortOrder": {models.ListOrderDesc}}
+		require.Equal(t, []string{"page-d", "page-b", "page-e", "page-a", "page-c"}, listAll(t, app, agentsURL, "agents", "name", query))
+
+		query = url.Values{"limit": {"3"}, "sort": {models.ListSortCreatedAt}}
+		require.Equal(t, []string{"page-c", "page-a", "page-e", "page-b", "page-d"}, listAll(t, app, agentsURL, "agents", "name", query))
+	})
+
+	t.Run("A cursor should stay valid when agents are created and deleted between pages", func(t *testing.T) {
+		query := url.Values{"limit": {"2"}}
+		first := getListPage(t, app, agentsURL, "agents", query)
+		require.Equal(t, "page-b", first.items[1]["name"])
+
+		// The last agent of the page, an agent before it and one after it change meanwhile
+		rr := sendManagedAgentRequest(t, app, http.MethodDelete, agentsURL+"/"+agentIds["page-b"], nil, nil)
+		require.Equal(t, http.StatusNoContent, rr.Code)
+		createAgent(t, "page-aa")
+		createAgent(t, "page-cc")
+
+		query.Set("cursor", first.nextCursor)
+		require.Equal(t, []string{"page-c", "page-cc", "page-d", "page-e"}, listAll(t, app, agentsURL, "agents", "name", query))
+	})
+
+	t.Run("Listing agents sorted by update time should put the updated agent last", func(t *testing.T) {
+		rr := sendManagedAgentRequest(t, app, http.MethodPatch, agentsURL+"/"+agentIds["page-a"], map[string]interface{}{"description": "Updated"}, nil)
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+
+		names := listAll(t, app, agentsURL, "agents", "name", url.Values{"limit": {"2"}, "sort": {models.ListSortUpdatedAt}})
+		require.Len(t, names, 6)
+		require.Equal(t, "page-a", names[len(names)-1])
+	})
+
+	t.Run("Listing prompts and tools should follow the cursors to the last page", func(t *testing.T) {
+		for _, name := range []string{"prompt-b", "prompt-a", "prompt-c"} {
+			rr := sendManagedAgentRequest(t, app, http.MethodPost, orgURL+"/prompts", map[string]interface{}{"name": name, "template": "Hello {{name}}"}, nil)
+			require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+		}
+		for _, name := range []string{"tool_b", "tool_a", "tool_c"} {
+			rr := sendManagedAgentRequest(t, app, http.MethodPost, orgURL+"/tools", toolPayload(name), nil)
+			require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+		}
+
+		query := url.Values{"limit": {"2"}, "sortOrder": {models.ListOrderDesc}}
+		require.Equal(t, []string{"prompt-c", "prompt-b", "prompt-a"}, listAll(t, app, orgURL+"/prompts", "prompts", "name", query))
+		query = url.Values{"limit": {"1"}, "sortOrder": {models.ListOrderDesc}}
+		require.Equal(t, []string{"tool_c", "tool_b", "tool_a"}, listAll(t, app, orgURL+"/tools", "tools", "name", query))
+	})
+
+	t.Run("Listing webhooks should follow the cursors in the order they were created", func(t *testing.T) {
+		var ids []string
+		for i := 0; i < 3; i++ {
+			rr := sendManagedAgentRequest(t, app, http.MethodPost, orgURL+"/webhooks", map[string]interface{}{
+				"url": fmt.Sprintf("https://hooks.example.com/amp/%d", i), "secret": "whsec-0123456789abcdef", "events": []string{utils.WebhookEventAgentCreated},
+			}, nil)
+			require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+			var webhook models.WebhookResponse
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &webhook))
+			ids = append(ids, webhook.ID)
+		}
+
+		require.Equal(t, ids, listAll(t, app, orgURL+"/webhooks", "webhooks", "id", url.Values{"limit": {"2"}}))
+	})
+
+	errorTests := []struct {
+		name  string
+		url   string
+		query url.Values
+	}{
+		{name: "an unknown sort", url: agentsURL, query: url.Values{"sort": {"version"}}},
+		{name: "an unknown sort order", url: agentsURL, query: url.Values{"sortOrder": {"up"}}},
+		{name: "sorting webhooks by name", url: orgURL + "/webhooks", query: url.Values{"sort": {models.ListSortName}}},
+		{name: "a malformed cursor", url: agentsURL, query: url.Values{"cursor": {"not a cursor"}}},
+		{name: "an invalid count", url: agentsURL, query: url.Values{"count": {"maybe"}}},
+		{name: "a limit above the maximum", url: agentsURL, query: url.Values{"limit": {fmt.Sprint(utils.MaxLimit + 1)}}},
+	}
+	for _, tt := range errorTests {
+		t.Run(fmt.Sprintf("Listing with %s should return 400", tt.name), func(t *testing.T) {
+			rr := sendManagedAgentRequest(t, app, http.MethodGet, tt.url+"?"+tt.query.Encode(), nil, nil)
+			require.Equal(t, http.StatusBadRequest, rr.Code)
+			decodeProblem(t, rr)
+		})
+	}
+
+	t.Run("Listing with a cursor of another sort should return 400", func(t *testing.T) {
+		page := getListPage(t, app, agentsURL, "agents", url.Values{"limit": {"1"}})
+		query := url.Values{"cursor": {page.nextCursor}, "sort": {models.ListSortCreatedAt}}
+		rr := sendManagedAgentRequest(t, app, http.MethodGet, agentsURL+"?"+query.Encode(), nil, nil)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Contains(t, decodeProblem(t, rr).Detail, "another sort")
+	})
+}
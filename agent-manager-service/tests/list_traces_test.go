@@ -141,6 +141,7 @@ func TestListTraces(t *testing.T) {
 		// Validate call parameters
 		listTracesCall := traceObserverClient.ListTracesCalls()[0]
 		require.Equal(t, tracesAgentName, listTracesCall.Params.ServiceName)
+		require.Equal(t, tracesOrgId.String(), listTracesCall.Params.OrgID)
 		require.Equal(t, 10, listTracesCall.Params.Limit)         // default limit
 		require.Equal(t, 0, listTracesCall.Params.Offset)         // default offset
 		require.Equal(t, "desc", listTracesCall.Params.SortOrder) // default sort order
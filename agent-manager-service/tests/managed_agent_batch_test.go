@@ -0,0 +1,156 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/middleware/jwtassertion"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/models"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/tests/apitestutils"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/utils"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/wiring"
+)
+
+var (
+	testAgentBatchOrgId     = uuid.New()
+	testAgentBatchUserIdpId = uuid.New()
+	testAgentBatchOrgName   = fmt.Sprintf("test-org-%s", uuid.New().String()[:5])
+)
+
+func TestManagedAgentBatchUpdate(t *testing.T) {
+	_ = apitestutils.CreateOrganization(t, testAgentBatchOrgId, testAgentBatchUserIdpId, testAgentBatchOrgName)
+	authMiddleware := jwtassertion.NewMockMiddleware(t, testAgentBatchOrgId, testAgentBatchUserIdpId)
+	app := apitestutils.MakeAppClientWithDeps(t, wiring.TestClients{}, authMiddleware)
+	baseURL := fmt.Sprintf("/api/v1/orgs/%s/agents", testAgentBatchOrgName)
+	batchURL := baseURL + ":batchUpdate"
+
+	agents := make([]models.ManagedAgentResponse, 4)
+	for i := range agents {
+		rr := sendManagedAgentRequest(t, app, http.MethodPost, baseURL, managedAgentPayload(fmt.Sprintf("batch-agent-%d", i), map[string]string{"team": "support"}), nil)
+		require.Equal(t, http.StatusCreated, rr.Code)
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &agents[i]))
+		require.True(t, agents[i].Enabled)
+	}
+	getAgent := func(t *testing.T, id string) (int, models.ManagedAgentResponse) {
+		rr := sendManagedAgentRequest(t, app, http.MethodGet, baseURL+"/"+id, nil, nil)
+		var agent models.ManagedAgentResponse
+		if rr.Code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &agent))
+		}
+		return rr.Code, agent
+	}
+	operations := []map[string]interface{}{
+		{"agentId": agents[0].ID, "action": utils.BatchActionDisable},
+		{"agentId": agents[1].ID, "action": utils.BatchActionAddLabels, "labels": map[string]string{"env": "prod"}},
+		{"agentId": agents[2].ID, "action": utils.BatchActionSetModel, "modelConfig": map[string]interface{}{"model": "gpt-4o-mini", "temperature": nil}},
+		{"agentId": agents[3].ID, "action": utils.BatchActionDelete},
+		{"agentId": uuid.New().String(), "action": utils.BatchActionEnable},
+	}
+
+	t.Run("A dry run should report the outcome of every operation without applying any", func(t *testing.T) {
+		rr := sendManagedAgentRequest(t, app, http.MethodPost, batchURL, map[string]interface{}{"operations": operations, "dryRun": true}, nil)
+		require.Equal(t, http.StatusOK, rr.Code)
+		var response models.AgentBatchResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+		require.True(t, response.DryRun)
+		require.Equal(t, 4, response.Succeeded)
+		require.Equal(t, 1, response.Failed)
+		require.Equal(t, int32(2), response.Results[0].Version)
+		require.Equal(t, http.StatusNotFound, response.Results[4].StatusCode)
+
+		for _, agent := range agents {
+			code, current := getAgent(t, agent.ID)
+			require.Equal(t, http.StatusOK, code)
+			require.Equal(t, int32(1), current.Version)
+			require.True(t, current.Enabled)
+		}
+	})
+
+	t.Run("Applying a batch should apply each operation and report the failed ones", func(t *testing.T) {
+		rr := sendManagedAgentRequest(t, app, http.MethodPost, batchURL, map[string]interface{}{"operations": operations}, nil)
+		require.Equal(t, http.StatusOK, rr.Code)
+		var response models.AgentBatchResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+		require.Equal(t, 4, response.Succeeded)
+		require.Equal(t, 1, response.Failed)
+		for i, result := range response.Results {
+			require.Equal(t, operations[i]["agentId"], result.AgentID)
+		}
+		require.Equal(t, utils.BatchStatusFailed, response.Results[4].Status)
+		require.Equal(t, "Agent not found", response.Results[4].Error)
+		require.Equal(t, []models.ConfigChange{{Path: "enabled", Type: "changed", From: true, To: false}}, response.Results[0].Changes)
+
+		_, disabled := getAgent(t, agents[0].ID)
+		require.False(t, disabled.Enabled)
+		require.Equal(t, int32(2), disabled.Version)
+		_, labeled := getAgent(t, agents[1].ID)
+		require.Equal(t, map[string]string{"team": "support", "env": "prod"}, labeled.Labels)
+		_, remodeled := getAgent(t, agents[2].ID)
+		require.Equal(t, "gpt-4o-mini", remodeled.ModelConfig["model"])
+		require.NotContains(t, remodeled.ModelConfig, "temperature")
+		code, _ := getAgent(t, agents[3].ID)
+		require.Equal(t, http.StatusNotFound, code)
+	})
+
+	t.Run("An operation leaving the agent invalid should fail without changing it", func(t *testing.T) {
+		rr := sendManagedAgentRequest(t, app, http.MethodPost, batchURL, map[string]interface{}{"operations": []map[string]interface{}{
+			{"agentId": agents[1].ID, "action": utils.BatchActionSetModel, "modelConfig": map[string]interface{}{"provider": nil}},
+			{"agentId": agents[0].ID, "action": utils.BatchActionDisable},
+		}}, nil)
+		require.Equal(t, http.StatusOK, rr.Code)
+		var response models.AgentBatchResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+		require.Equal(t, http.StatusBadRequest, response.Results[0].StatusCode)
+		require.Contains(t, response.Results[0].Error, "modelConfig.provider")
+		// Disabling an agent that already is disabled changes nothing
+		require.Equal(t, utils.BatchStatusSucceeded, response.Results[1].Status)
+		require.Empty(t, response.Results[1].Changes)
+		require.Equal(t, int32(2), response.Results[1].Version)
+
+		_, unchanged := getAgent(t, agents[1].ID)
+		require.Equal(t, "openai", unchanged.ModelConfig["provider"])
+		require.Equal(t, int32(2), unchanged.Version)
+	})
+
+	t.Run("An invalid batch should be rejected as a whole", func(t *testing.T) {
+		rr := sendManagedAgentRequest(t, app, http.MethodPost, batchURL, map[string]interface{}{"operations": []map[string]interface{}{
+			{"agentId": agents[0].ID, "action": utils.BatchActionEnable},
+			{"agentId": agents[0].ID, "action": "rename"},
+		}}, nil)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Equal(t, []string{"operations[1].agentId", "operations[1].action"}, problemFields(decodeProblem(t, rr)))
+		_, current := getAgent(t, agents[0].ID)
+		require.False(t, current.Enabled)
+	})
+
+	t.Run("A batch larger than the maximum should be rejected", func(t *testing.T) {
+		operations := make([]map[string]interface{}, 101)
+		for i := range operations {
+			operations[i] = map[string]interface{}{"agentId": uuid.New().String(), "action": utils.BatchActionEnable}
+		}
+		rr := sendManagedAgentRequest(t, app, http.MethodPost, batchURL, map[string]interface{}{"operations": operations}, nil)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Equal(t, []string{"operations"}, problemFields(decodeProblem(t, rr)))
+	})
+}
@@ -0,0 +1,323 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/middleware/jwtassertion"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/models"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/tests/apitestutils"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/utils"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/wiring"
+)
+
+var (
+	testManagedAgentOrgId     = uuid.New()
+	testManagedAgentUserIdpId = uuid.New()
+	testManagedAgentOrgName   = fmt.Sprintf("test-org-%s", uuid.New().String()[:5])
+)
+
+func managedAgentPayload(name string, labels map[string]string) map[string]interface{} {
+	return map[string]interface{}{
+		"name":        name,
+		"description": "Answers support questions",
+		"framework":   "crewai",
+		"modelConfig": map[string]interface{}{
+			"provider":    "openai",
+			"model":       "gpt-4o",
+			"temperature": 0.2,
+			"maxTokens":   1024,
+		},
+		"systemPrompt": "You are a helpful support agent.",
+		"tools":        []map[string]interface{}{{"name": "search_docs"}},
+		"labels":       labels,
+	}
+}
+
+func sendManagedAgentRequest(t *testing.T, app http.Handler, method string, url string, body interface{}, headers map[string]string) *httptest.ResponseRecorder {
+	reqBody := new(bytes.Buffer)
+	if body != nil {
+		require.NoError(t, json.NewEncoder(reqBody).Encode(body))
+	}
+	req := httptest.NewRequest(method, url, reqBody)
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	rr := httptest.NewRecorder()
+	app.ServeHTTP(rr, req)
+	return rr
+}
+
+func decodeProblem(t *testing.T, rr *httptest.ResponseRecorder) utils.ProblemDetails {
+	require.Equal(t, utils.ProblemContentType, rr.Header().Get("Content-Type"))
+	var problem utils.ProblemDetails
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &problem))
+	require.Equal(t, rr.Code, problem.Status)
+	return problem
+}
+
+func problemFields(problem utils.ProblemDetails) []string {
+	fields := make([]string, 0, len(problem.Errors))
+	for _, fieldErr := range problem.Errors {
+		fields = append(fields, fieldErr.Field)
+	}
+	return fields
+}
+
+func TestManagedAgents(t *testing.T) {
+	_ = apitestutils.CreateOrganization(t, testManagedAgentOrgId, testManagedAgentUserIdpId, testManagedAgentOrgName)
+	authMiddleware := jwtassertion.NewMockMiddleware(t, testManagedAgentOrgId, testManagedAgentUserIdpId)
+	app := apitestutils.MakeAppClientWithDeps(t, wiring.TestClients{}, authMiddleware)
+	baseURL := fmt.Sprintf("/api/v1/orgs/%s/agents", testManagedAgentOrgName)
+
+	var created models.ManagedAgentResponse
+	t.Run("Creating a managed agent should return 201 with version 1", func(t *testing.T) {
+		rr := sendManagedAgentRequest(t, app, http.MethodPost, baseURL, managedAgentPayload("support-agent", map[string]string{"team": "support", "env": "prod"}), nil)
+		require.Equal(t, http.StatusCreated, rr.Code)
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &created))
+		require.Equal(t, "support-agent", created.Name)
+		require.Equal(t, "crewai", created.Framework)
+		require.Equal(t, "gpt-4o", created.ModelConfig["model"])
+		require.Equal(t, []models.ToolReference{{Name: "search_docs"}}, created.Tools)
+		require.Equal(t, int32(1), created.Version)
+		require.False(t, created.CreatedAt.IsZero())
+		require.Equal(t, `"1"`, rr.Header().Get("ETag"))
+		require.Equal(t, baseURL+"/"+created.ID, rr.Header().Get("Location"))
+	})
+
+	t.Run("Creating a second managed agent should return 201", func(t *testing.T) {
+		rr := sendManagedAgentRequest(t, app, http.MethodPost, baseURL, managedAgentPayload("billing-agent", map[string]string{"team": "billing", "env": "prod"}), nil)
+		require.Equal(t, http.StatusCreated, rr.Code)
+	})
+
+	t.Run("Creating a managed agent with a duplicate name should return 409", func(t *testing.T) {
+		rr := sendManagedAgentRequest(t, app, http.MethodPost, baseURL, managedAgentPayload("support-agent", nil), nil)
+		require.Equal(t, http.StatusConflict, rr.Code)
+		problem := decodeProblem(t, rr)
+		require.Equal(t, []string{"name"}, problemFields(problem))
+	})
+
+	validationTests := []struct {
+		name       string
+		mutate     func(payload map[string]interface{})
+		wantFields []string
+	}{
+		{
+			name:       "empty name",
+			mutate:     func(payload map[string]interface{}) { payload["name"] = "" },
+			wantFields: []string{"name"},
+		},
+		{
+			name:       "unknown framework",
+			mutate:     func(payload map[string]interface{}) { payload["framework"] = "skynet" },
+			wantFields: []string{"framework"},
+		},
+		{
+			name:       "model config that is not an object",
+			mutate:     func(payload map[string]interface{}) { payload["modelConfig"] = "gpt-4o" },
+			wantFields: []string{"modelConfig"},
+		},
+		{
+			name: "model config without a model",
+			mutate: func(payload map[string]interface{}) {
+				payload["modelConfig"] = map[string]interface{}{"provider": "openai"}
+			},
+			wantFields: []string{"modelConfig.model"},
+		},
+		{
+			name: "model config with invalid settings",
+			mutate: func(payload map[string]interface{}) {
+				payload["modelConfig"] = map[string]interface{}{"provider": "openai", "model": "gpt-4o", "temperature": 3, "maxTokens": 1.5}
+			},
+			wantFields: []string{"modelConfig.temperature", "modelConfig.maxTokens"},
+		},
+		{
+			name: "duplicate tools",
+			mutate: func(payload map[string]interface{}) {
+				payload["tools"] = []map[string]interface{}{{"name": "search"}, {"name": "search"}}
+			},
+			wantFields: []string{"tools[1].name"},
+		},
+		{
+			name:       "invalid label key",
+			mutate:     func(payload map[string]interface{}) { payload["labels"] = map[string]string{"bad key": "x"} },
+			wantFields: []string{"labels.bad key"},
+		},
+		{
+			name:       "negative retention",
+			mutate:     func(payload map[string]interface{}) { payload["retentionDays"] = -1 },
+			wantFields: []string{"retentionDays"},
+		},
+	}
+	for _, tt := range validationTests {
+		t.Run(fmt.Sprintf("Creating a managed agent with %s should return 400", tt.name), func(t *testing.T) {
+			payload := managedAgentPayload("invalid-agent", nil)
+			tt.mutate(payload)
+			rr := sendManagedAgentRequest(t, app, http.MethodPost, baseURL, payload, nil)
+			require.Equal(t, http.StatusBadRequest, rr.Code)
+			problem := decodeProblem(t, rr)
+			require.ElementsMatch(t, tt.wantFields, problemFields(problem))
+		})
+	}
+
+	t.Run("Rejected fields should be listed with their JSON pointer and constraint", func(t *testing.T) {
+		payload := managedAgentPayload("invalid-agent", map[string]string{"example.com/team": "-"})
+		payload["modelConfig"] = map[string]interface{}{"provider": "openai", "model": "gpt-4o", "temperature": 3}
+		rr := sendManagedAgentRequest(t, app, http.MethodPost, baseURL, payload, nil)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		problem := decodeProblem(t, rr)
+		require.Equal(t, "Invalid agent", problem.Detail)
+		require.Equal(t, []utils.FieldError{
+			{
+				Field:      "modelConfig.temperature",
+				Pointer:    "/modelConfig/temperature",
+				Constraint: utils.ConstraintMaximum,
+				Message:    "must be a number between 0 and 2",
+			},
+			{
+				Field:      "labels.example.com/team",
+				Pointer:    "/labels/example.com~1team",
+				Constraint: utils.ConstraintPattern,
+				Message:    utils.ValidateLabelValue("-").Error(),
+			},
+		}, problem.Errors)
+	})
+
+	t.Run("Getting a managed agent should return 200", func(t *testing.T) {
+		rr := sendManagedAgentRequest(t, app, http.MethodGet, baseURL+"/"+created.ID, nil, nil)
+		require.Equal(t, http.StatusOK, rr.Code)
+		var agent models.ManagedAgentResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &agent))
+		require.Equal(t, created.ID, agent.ID)
+		require.Equal(t, map[string]string{"team": "support", "env": "prod"}, agent.Labels)
+	})
+
+	t.Run("Getting a managed agent with a malformed id should return 404", func(t *testing.T) {
+		rr := sendManagedAgentRequest(t, app, http.MethodGet, baseURL+"/not-a-uuid", nil, nil)
+		require.Equal(t, http.StatusNotFound, rr.Code)
+		decodeProblem(t, rr)
+	})
+
+	listTests := []struct {
+		name      string
+		query     string
+		wantNames []string
+	}{
+		{name: "all agents", query: "", wantNames: []string{"billing-agent", "support-agent"}},
+		{name: "name search", query: "?search=SUPP", wantNames: []string{"support-agent"}},
+		{name: "label selector", query: "?labelSelector=team=billing,env=prod", wantNames: []string{"billing-agent"}},
+		{name: "label inequality", query: "?labelSelector=team!=billing", wantNames: []string{"support-agent"}},
+		{name: "label set", query: "?labelSelector=team%20in%20(billing,support),env%20notin%20(dev)", wantNames: []string{"billing-agent", "support-agent"}},
+		{name: "missing label", query: "?labelSelector=!env", wantNames: []string{}},
+		{name: "search wildcards are literal", query: "?search=%25", wantNames: []string{}},
+		{name: "pagination", query: "?limit=1&offset=1", wantNames: []string{"support-agent"}},
+	}
+	for _, tt := range listTests {
+		t.Run(fmt.Sprintf("Listing managed agents by %s should return 200", tt.name), func(t *testing.T) {
+			rr := sendManagedAgentRequest(t, app, http.MethodGet, baseURL+tt.query, nil, nil)
+			require.Equal(t, http.StatusOK, rr.Code)
+			var list models.ManagedAgentListResponse
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &list))
+			names := make([]string, 0, len(list.Agents))
+			for _, agent := range list.Agents {
+				names = append(names, agent.Name)
+			}
+			require.Equal(t, tt.wantNames, names)
+		})
+	}
+
+	t.Run("Listing managed agents with an invalid label selector should return 400", func(t *testing.T) {
+		rr := sendManagedAgentRequest(t, app, http.MethodGet, baseURL+"?labelSelector=team%20in%20(billing", nil, nil)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		decodeProblem(t, rr)
+	})
+
+	t.Run("Updating a managed agent with a matching If-Match should return 200 with version 2", func(t *testing.T) {
+		payload := managedAgentPayload("support-agent", map[string]string{"team": "support"})
+		payload["systemPrompt"] = "You are a concise support agent."
+		payload["retentionDays"] = 30
+		rr := sendManagedAgentRequest(t, app, http.MethodPut, baseURL+"/"+created.ID, payload, map[string]string{"If-Match": `"1"`})
+		require.Equal(t, http.StatusOK, rr.Code)
+		var agent models.ManagedAgentResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &agent))
+		require.Equal(t, int32(2), agent.Version)
+		require.Equal(t, "You are a concise support agent.", agent.SystemPrompt)
+		require.Nil(t, created.RetentionDays)
+		require.NotNil(t, agent.RetentionDays)
+		require.Equal(t, int32(30), *agent.RetentionDays)
+		require.Equal(t, created.CreatedAt.Unix(), agent.CreatedAt.Unix())
+		require.True(t, agent.UpdatedAt.After(created.UpdatedAt))
+		require.Equal(t, `"2"`, rr.Header().Get("ETag"))
+	})
+
+	t.Run("Updating a managed agent with a stale If-Match should return 412 with the current ETag", func(t *testing.T) {
+		rr := sendManagedAgentRequest(t, app, http.MethodPut, baseURL+"/"+created.ID, managedAgentPayload("support-agent", nil), map[string]string{"If-Match": `"1"`})
+		require.Equal(t, http.StatusPreconditionFailed, rr.Code)
+		require.Equal(t, `"2"`, rr.Header().Get("ETag"))
+		decodeProblem(t, rr)
+	})
+
+	t.Run("Updating a managed agent without If-Match should return 428", func(t *testing.T) {
+		rr := sendManagedAgentRequest(t, app, http.MethodPut, baseURL+"/"+created.ID, managedAgentPayload("support-agent", nil), nil)
+		require.Equal(t, http.StatusPreconditionRequired, rr.Code)
+		decodeProblem(t, rr)
+	})
+
+	t.Run("Getting a managed agent with a matching If-None-Match should return 304", func(t *testing.T) {
+		rr := sendManagedAgentRequest(t, app, http.MethodGet, baseURL+"/"+created.ID, nil, map[string]string{"If-None-Match": `"2"`})
+		require.Equal(t, http.StatusNotModified, rr.Code)
+		require.Equal(t, `"2"`, rr.Header().Get("ETag"))
+		require.Empty(t, rr.Body.Bytes())
+
+		rr = sendManagedAgentRequest(t, app, http.MethodGet, baseURL+"/"+created.ID, nil, map[string]string{"If-None-Match": `"1"`})
+		require.Equal(t, http.StatusOK, rr.Code)
+	})
+
+	t.Run("Renaming a managed agent to an existing name should return 409", func(t *testing.T) {
+		rr := sendManagedAgentRequest(t, app, http.MethodPut, baseURL+"/"+created.ID, managedAgentPayload("billing-agent", nil), map[string]string{"If-Match": `"2"`})
+		require.Equal(t, http.StatusConflict, rr.Code)
+		decodeProblem(t, rr)
+	})
+
+	t.Run("Deleting a managed agent should return 204", func(t *testing.T) {
+		rr := sendManagedAgentRequest(t, app, http.MethodDelete, baseURL+"/"+created.ID, nil, nil)
+		require.Equal(t, http.StatusNoContent, rr.Code)
+
+		rr = sendManagedAgentRequest(t, app, http.MethodGet, baseURL+"/"+created.ID, nil, nil)
+		require.Equal(t, http.StatusNotFound, rr.Code)
+
+		rr = sendManagedAgentRequest(t, app, http.MethodDelete, baseURL+"/"+created.ID, nil, nil)
+		require.Equal(t, http.StatusNotFound, rr.Code)
+	})
+
+	t.Run("Accessing managed agents of an unknown organization should return 404", func(t *testing.T) {
+		rr := sendManagedAgentRequest(t, app, http.MethodGet, "/api/v1/orgs/nonexistent-org/agents", nil, nil)
+		require.Equal(t, http.StatusNotFound, rr.Code)
+		problem := decodeProblem(t, rr)
+		require.Equal(t, "Organization not found", problem.Detail)
+	})
+}
@@ -0,0 +1,159 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/middleware/jwtassertion"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/models"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/tests/apitestutils"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/utils"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/wiring"
+)
+
+var (
+	testAgentVersionOrgId     = uuid.New()
+	testAgentVersionUserIdpId = uuid.New()
+	testAgentVersionOrgName   = fmt.Sprintf("test-org-%s", uuid.New().String()[:5])
+)
+
+func TestManagedAgentVersions(t *testing.T) {
+	_ = apitestutils.CreateOrganization(t, testAgentVersionOrgId, testAgentVersionUserIdpId, testAgentVersionOrgName)
+	authMiddleware := jwtassertion.NewMockMiddleware(t, testAgentVersionOrgId, testAgentVersionUserIdpId)
+	app := apitestutils.MakeAppClientWithDeps(t, wiring.TestClients{}, authMiddleware)
+	baseURL := fmt.Sprintf("/api/v1/orgs/%s/agents", testAgentVersionOrgName)
+
+	rr := sendManagedAgentRequest(t, app, http.MethodPost, baseURL, managedAgentPayload("versioned-agent", map[string]string{"team": "support"}), nil)
+	require.Equal(t, http.StatusCreated, rr.Code)
+	var created models.ManagedAgentResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &created))
+	agentURL := baseURL + "/" + created.ID
+
+	t.Run("Updating a managed agent should record a new version", func(t *testing.T) {
+		payload := managedAgentPayload("versioned-agent", map[string]string{"team": "support", "env": "prod"})
+		payload["modelConfig"].(map[string]interface{})["temperature"] = 0.7
+		rr := sendManagedAgentRequest(t, app, http.MethodPut, agentURL, payload, map[string]string{"If-Match": `"1"`})
+		require.Equal(t, http.StatusOK, rr.Code)
+		require.Equal(t, `"2"`, rr.Header().Get("ETag"))
+	})
+
+	t.Run("Listing versions should return them newest first with their author", func(t *testing.T) {
+		rr := sendManagedAgentRequest(t, app, http.MethodGet, agentURL+"/versions", nil, nil)
+		require.Equal(t, http.StatusOK, rr.Code)
+		var list models.ManagedAgentVersionListResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &list))
+		require.Equal(t, int32(2), list.ActiveVersion)
+		require.Equal(t, int32(2), list.Total)
+		require.Len(t, list.Versions, 2)
+		require.Equal(t, int32(2), list.Versions[0].Version)
+		require.Equal(t, int32(1), list.Versions[1].Version)
+		for _, version := range list.Versions {
+			require.Equal(t, testAgentVersionUserIdpId.String(), version.Author)
+			require.False(t, version.CreatedAt.IsZero())
+			require.Nil(t, version.RolledBackFrom)
+		}
+	})
+
+	t.Run("Getting a version should return its full configuration", func(t *testing.T) {
+		rr := sendManagedAgentRequest(t, app, http.MethodGet, agentURL+"/versions/1", nil, nil)
+		require.Equal(t, http.StatusOK, rr.Code)
+		var version models.ManagedAgentVersionResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &version))
+		require.Equal(t, created.ID, version.AgentID)
+		require.Equal(t, int32(1), version.Version)
+		require.Equal(t, 0.2, version.Config.ModelConfig["temperature"])
+		require.Equal(t, map[string]string{"team": "support"}, version.Config.Labels)
+	})
+
+	t.Run("Diffing two versions should list the changed fields", func(t *testing.T) {
+		rr := sendManagedAgentRequest(t, app, http.MethodGet, agentURL+"/versions/1/diff/2", nil, nil)
+		require.Equal(t, http.StatusOK, rr.Code)
+		var diff models.ManagedAgentVersionDiffResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &diff))
+		require.Equal(t, int32(1), diff.FromVersion)
+		require.Equal(t, int32(2), diff.ToVersion)
+		require.Equal(t, []models.ConfigChange{
+			{Path: "labels.env", Type: utils.ConfigChangeAdded, To: "prod"},
+			{Path: "modelConfig.temperature", Type: utils.ConfigChangeChanged, From: 0.2, To: 0.7},
+		}, diff.Changes)
+	})
+
+	t.Run("Rolling back with a stale If-Match should return 412", func(t *testing.T) {
+		rr := sendManagedAgentRequest(t, app, http.MethodPost, agentURL+"/rollback?to=1", nil, map[string]string{"If-Match": `"1"`})
+		require.Equal(t, http.StatusPreconditionFailed, rr.Code)
+		decodeProblem(t, rr)
+	})
+
+	t.Run("Rolling back should create a new active version with the old configuration", func(t *testing.T) {
+		rr := sendManagedAgentRequest(t, app, http.MethodPost, agentURL+"/rollback?to=1", nil, map[string]string{"If-Match": `"2"`})
+		require.Equal(t, http.StatusOK, rr.Code)
+		require.Equal(t, `"3"`, rr.Header().Get("ETag"))
+		var agent models.ManagedAgentResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &agent))
+		require.Equal(t, int32(3), agent.Version)
+		require.Equal(t, 0.2, agent.ModelConfig["temperature"])
+		require.Equal(t, map[string]string{"team": "support"}, agent.Labels)
+
+		rr = sendManagedAgentRequest(t, app, http.MethodGet, agentURL+"/versions/3", nil, nil)
+		require.Equal(t, http.StatusOK, rr.Code)
+		var version models.ManagedAgentVersionResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &version))
+		require.NotNil(t, version.RolledBackFrom)
+		require.Equal(t, int32(1), *version.RolledBackFrom)
+
+		rr = sendManagedAgentRequest(t, app, http.MethodGet, agentURL+"/versions/1/diff/3", nil, nil)
+		require.Equal(t, http.StatusOK, rr.Code)
+		var diff models.ManagedAgentVersionDiffResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &diff))
+		require.Empty(t, diff.Changes)
+	})
+
+	errorTests := []struct {
+		name       string
+		method     string
+		path       string
+		wantStatus int
+	}{
+		{name: "getting an unknown version", method: http.MethodGet, path: "/versions/99", wantStatus: http.StatusNotFound},
+		{name: "getting a non numeric version", method: http.MethodGet, path: "/versions/latest", wantStatus: http.StatusBadRequest},
+		{name: "diffing against an unknown version", method: http.MethodGet, path: "/versions/1/diff/99", wantStatus: http.StatusNotFound},
+		{name: "rolling back without a target version", method: http.MethodPost, path: "/rollback", wantStatus: http.StatusBadRequest},
+		{name: "rolling back to an unknown version", method: http.MethodPost, path: "/rollback?to=99", wantStatus: http.StatusNotFound},
+		{name: "listing versions with an invalid limit", method: http.MethodGet, path: "/versions?limit=0", wantStatus: http.StatusBadRequest},
+	}
+	for _, tt := range errorTests {
+		t.Run(fmt.Sprintf("%s should return %d", tt.name, tt.wantStatus), func(t *testing.T) {
+			rr := sendManagedAgentRequest(t, app, tt.method, agentURL+tt.path, nil, nil)
+			require.Equal(t, tt.wantStatus, rr.Code)
+			decodeProblem(t, rr)
+		})
+	}
+
+	t.Run("Deleting a managed agent should remove its versions", func(t *testing.T) {
+		rr := sendManagedAgentRequest(t, app, http.MethodDelete, agentURL, nil, nil)
+		require.Equal(t, http.StatusNoContent, rr.Code)
+		rr = sendManagedAgentRequest(t, app, http.MethodGet, agentURL+"/versions", nil, nil)
+		require.Equal(t, http.StatusNotFound, rr.Code)
+	})
+}
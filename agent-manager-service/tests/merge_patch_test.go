@@ -0,0 +1,205 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/middleware/jwtassertion"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/models"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/tests/apitestutils"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/utils"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/wiring"
+)
+
+var (
+	testPatchOrgId     = uuid.New()
+	testPatchUserIdpId = uuid.New()
+	testPatchOrgName   = fmt.Sprintf("test-org-%s", uuid.New().String()[:5])
+)
+
+func TestMergePatch(t *testing.T) {
+	_ = apitestutils.CreateOrganization(t, testPatchOrgId, testPatchUserIdpId, testPatchOrgName)
+	useCredentialKeys(t, map[string]string{"key-a": testCredentialKeyA}, "key-a")
+	authMiddleware := jwtassertion.NewMockMiddleware(t, testPatchOrgId, testPatchUserIdpId)
+	app := apitestutils.MakeAppClientWithDeps(t, wiring.TestClients{}, authMiddleware)
+	orgURL := fmt.Sprintf("/api/v1/orgs/%s", testPatchOrgName)
+
+	payload := managedAgentPayload("patched-agent", map[string]string{"team": "support", "env": "staging"})
+	payload["modelConfig"].(map[string]interface{})["stop"] = []string{"END"}
+	rr := sendManagedAgentRequest(t, app, http.MethodPost, orgURL+"/agents", payload, nil)
+	require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+	var created models.ManagedAgentResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &created))
+	agentURL := orgURL + "/agents/" + created.ID
+
+	t.Run("Patching a nested model config field should keep its siblings", func(t *testing.T) {
+		rr := sendManagedAgentRequest(t, app, http.MethodPatch, agentURL, map[string]interface{}{
+			"modelConfig": map[string]interface{}{"temperature": 0.7, "stop": nil},
+		}, nil)
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		require.Equal(t, `"2"`, rr.Header().Get("ETag"))
+		var agent models.ManagedAgentResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &agent))
+		require.Equal(t, map[string]interface{}{
+			"provider":    "openai",
+			"model":       "gpt-4o",
+			"temperature": 0.7,
+			"maxTokens":   float64(1024),
+		}, agent.ModelConfig)
+		require.Equal(t, "You are a helpful support agent.", agent.SystemPrompt)
+		require.Equal(t, map[string]string{"team": "support", "env": "staging"}, agent.Labels)
+	})
+
+	t.Run("Patching labels should merge them key by key and tools should be replaced", func(t *testing.T) {
+		rr := sendManagedAgentRequest(t, app, http.MethodPatch, agentURL, map[string]interface{}{
+			"labels": map[string]interface{}{"env": nil, "tier": "gold"},
+			"tools":  []map[string]interface{}{{"name": "create_ticket"}},
+		}, map[string]string{"If-Match": `"2"`})
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		var agent models.ManagedAgentResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &agent))
+		require.Equal(t, map[string]string{"team": "support", "tier": "gold"}, agent.Labels)
+		require.Equal(t, []models.ToolReference{{Name: "create_ticket"}}, agent.Tools)
+		require.Equal(t, 0.7, agent.ModelConfig["temperature"])
+	})
+
+	t.Run("Patching should record a version with the merged configuration", func(t *testing.T) {
+		rr := sendManagedAgentRequest(t, app, http.MethodGet, agentURL+"/versions/2/diff/3", nil, nil)
+		require.Equal(t, http.StatusOK, rr.Code)
+		var diff models.ManagedAgentVersionDiffResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &diff))
+		paths := make([]string, 0, len(diff.Changes))
+		for _, change := range diff.Changes {
+			paths = append(paths, change.Path)
+		}
+		require.Contains(t, paths, "labels.env")
+		require.Contains(t, paths, "labels.tier")
+		require.NotContains(t, paths, "modelConfig.temperature")
+	})
+
+	t.Run("A patch whose result is invalid should return 400 and change nothing", func(t *testing.T) {
+		tests := []struct {
+			name       string
+			patch      map[string]interface{}
+			wantFields []string
+		}{
+			{name: "removing a required field", patch: map[string]interface{}{"name": nil}, wantFields: []string{"name"}},
+			{name: "a model config outside the schema", patch: map[string]interface{}{"modelConfig": map[string]interface{}{"temperature": 9}}, wantFields: []string{"modelConfig.temperature"}},
+			{name: "a wrong type", patch: map[string]interface{}{"labels": map[string]interface{}{"team": 7}}, wantFields: []string{"labels.team"}},
+			{name: "an unknown field", patch: map[string]interface{}{"owner": "me"}, wantFields: []string{"owner"}},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				rr := sendManagedAgentRequest(t, app, http.MethodPatch, agentURL, tt.patch, nil)
+				require.Equal(t, http.StatusBadRequest, rr.Code, rr.Body.String())
+				require.Equal(t, tt.wantFields, problemFields(decodeProblem(t, rr)))
+			})
+		}
+		rr := sendManagedAgentRequest(t, app, http.MethodGet, agentURL, nil, nil)
+		require.Equal(t, `"3"`, rr.Header().Get("ETag"))
+	})
+
+	t.Run("A patch based on a stale version should be rejected with the current agent", func(t *testing.T) {
+		rr := sendManagedAgentRequest(t, app, http.MethodPatch, agentURL, map[string]interface{}{"description": "stale", "version": 2}, nil)
+		require.Equal(t, http.StatusConflict, rr.Code)
+		problem := decodeProblem(t, rr)
+		current, ok := problem.Current.(map[string]interface{})
+		require.True(t, ok)
+		require.Equal(t, float64(3), current["version"])
+
+		rr = sendManagedAgentRequest(t, app, http.MethodPatch, agentURL, map[string]interface{}{"description": "stale"}, map[string]string{"If-Match": `"2"`})
+		require.Equal(t, http.StatusPreconditionFailed, rr.Code)
+		require.Equal(t, `"3"`, rr.Header().Get("ETag"))
+	})
+
+	t.Run("A patch that is not an object should return 400", func(t *testing.T) {
+		rr := sendManagedAgentRequest(t, app, http.MethodPatch, agentURL, []interface{}{"name"}, nil)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		decodeProblem(t, rr)
+	})
+
+	t.Run("Patching a prompt should record a new version", func(t *testing.T) {
+		rr := sendManagedAgentRequest(t, app, http.MethodPost, orgURL+"/prompts", map[string]interface{}{
+			"name": "patched-prompt", "template": "Hello {{name}}", "labels": map[string]string{"team": "support"},
+		}, nil)
+		require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+		var prompt models.PromptTemplateResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &prompt))
+
+		rr = sendManagedAgentRequest(t, app, http.MethodPatch, orgURL+"/prompts/"+prompt.ID, map[string]interface{}{
+			"template": "Hi {{name}}, welcome to {{plan}}",
+		}, nil)
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &prompt))
+		require.Equal(t, int32(2), prompt.Version)
+		require.Equal(t, "patched-prompt", prompt.Name)
+		require.Equal(t, map[string]string{"team": "support"}, prompt.Labels)
+		require.Len(t, prompt.Variables, 2)
+
+		rr = sendManagedAgentRequest(t, app, http.MethodPatch, orgURL+"/prompts/"+prompt.ID, map[string]interface{}{"template": nil}, nil)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Equal(t, "Invalid prompt", decodeProblem(t, rr).Detail)
+	})
+
+	t.Run("Patching a tool should merge its endpoint and replace its tags", func(t *testing.T) {
+		rr := sendManagedAgentRequest(t, app, http.MethodPost, orgURL+"/tools", toolPayload("patched_tool"), nil)
+		require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+		var tool models.ToolResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &tool))
+
+		rr = sendManagedAgentRequest(t, app, http.MethodPatch, orgURL+"/tools/"+tool.ID, map[string]interface{}{
+			"endpoint": map[string]interface{}{"url": "https://tools.example.com/v2/search"},
+			"tags":     []string{"docs"},
+		}, map[string]string{"If-Match": `"1"`})
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &tool))
+		require.Equal(t, "http", tool.Endpoint.Type)
+		require.Equal(t, "https://tools.example.com/v2/search", tool.Endpoint.URL)
+		require.Equal(t, []string{"docs"}, tool.Tags)
+		require.Equal(t, "Searches the product documentation", tool.Description)
+	})
+
+	t.Run("Patching a webhook should keep its secret and active flag", func(t *testing.T) {
+		rr := sendManagedAgentRequest(t, app, http.MethodPost, orgURL+"/webhooks", map[string]interface{}{
+			"url": "https://hooks.example.com/amp", "secret": "whsec-0123456789abcdef", "events": []string{utils.WebhookEventAgentCreated},
+		}, nil)
+		require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+		var webhook models.WebhookResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &webhook))
+
+		rr = sendManagedAgentRequest(t, app, http.MethodPatch, orgURL+"/webhooks/"+webhook.ID, map[string]interface{}{
+			"events": []string{utils.WebhookEventAgentUpdated},
+			"active": nil,
+		}, nil)
+		require.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &webhook))
+		require.Equal(t, []string{utils.WebhookEventAgentUpdated}, webhook.Events)
+		require.Equal(t, "https://hooks.example.com/amp", webhook.URL)
+		require.True(t, webhook.Active)
+
+		rr = sendManagedAgentRequest(t, app, http.MethodPatch, orgURL+"/webhooks/"+webhook.ID, map[string]interface{}{"version": 1}, nil)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Equal(t, []string{"version"}, problemFields(decodeProblem(t, rr)))
+	})
+}
@@ -0,0 +1,76 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/tests/apitestutils"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/utils"
+)
+
+func TestOpenAPIDocumentCoversEveryRoute(t *testing.T) {
+	app, authz := makeAuthorizationTestApp(t)
+
+	rr := httptest.NewRecorder()
+	app.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+	require.Equal(t, http.StatusOK, rr.Code)
+	var document struct {
+		OpenAPI string                                       `json:"openapi"`
+		Paths   map[string]map[string]map[string]interface{} `json:"paths"`
+	}
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &document))
+	require.Equal(t, "3.1.0", document.OpenAPI)
+
+	for _, route := range authz.Routes() {
+		method, path, _ := strings.Cut(route.Pattern, " ")
+		operation := document.Paths[path][strings.ToLower(method)]
+		require.NotNil(t, operation, "%s is not described by /openapi.json", route.Pattern)
+		require.Equal(t, string(route.Permission), operation["x-required-permission"], route.Pattern)
+	}
+}
+
+func TestRequestBodiesAreValidatedAgainstTheDocument(t *testing.T) {
+	_ = apitestutils.CreateOrganization(t, testRouteOrgId, testRouteUserIdpId, testRouteOrgName)
+	app, _ := makeAuthorizationTestApp(t, utils.RoleAdmin)
+
+	body := map[string]interface{}{
+		"name":        "validated-agent",
+		"framework":   "langgraph",
+		"modelConfig": map[string]interface{}{},
+		"labels":      map[string]interface{}{"team": 7},
+		"tools":       []interface{}{map[string]interface{}{"toolId": "search", "timeout": 5}},
+		"enabled":     "yes",
+		"owner":       "me",
+	}
+	rr := sendManagedAgentRequest(t, app, http.MethodPost, "/api/v1/orgs/"+testRouteOrgName+"/agents", body, nil)
+	require.Equal(t, http.StatusBadRequest, rr.Code)
+	problem := decodeProblem(t, rr)
+	require.Equal(t, "Invalid request body", problem.Detail)
+	require.Equal(t, []utils.FieldError{
+		{Field: "enabled", Pointer: "/enabled", Constraint: "type", Message: "must be of type boolean"},
+		{Field: "labels.team", Pointer: "/labels/team", Constraint: "type", Message: "must be of type string"},
+		{Field: "owner", Pointer: "/owner", Constraint: "additionalProperties", Message: "is not a known field"},
+		{Field: "tools[0].timeout", Pointer: "/tools/0/timeout", Constraint: "additionalProperties", Message: "is not a known field"},
+	}, problem.Errors)
+}
@@ -0,0 +1,165 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/middleware/jwtassertion"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/tests/apitestutils"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/wiring"
+)
+
+var (
+	testLockingOrgId     = uuid.New()
+	testLockingUserIdpId = uuid.New()
+	testLockingOrgName   = fmt.Sprintf("test-org-%s", uuid.New().String()[:5])
+)
+
+// raceUpdates sends the payloads as concurrent PUTs to url and returns the responses in the order of the payloads
+func raceUpdates(t *testing.T, app http.Handler, url string, payloads ...map[string]interface{}) []*httptest.ResponseRecorder {
+	responses := make([]*httptest.ResponseRecorder, len(payloads))
+	start := make(chan struct{})
+	var wg sync.WaitGroup
+	for i, payload := range payloads {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			responses[i] = sendManagedAgentRequest(t, app, http.MethodPut, url, payload, nil)
+		}()
+	}
+	close(start)
+	wg.Wait()
+	return responses
+}
+
+// requireOneWriteWins checks that exactly one of two racing updates succeeded and the other was rejected with
+// the state the winner left, returning the winning payload index
+func requireOneWriteWins(t *testing.T, responses []*httptest.ResponseRecorder) int {
+	require.Len(t, responses, 2)
+	winner, loser := 0, 1
+	if responses[0].Code != http.StatusOK {
+		winner, loser = 1, 0
+	}
+	require.Equal(t, http.StatusOK, responses[winner].Code, responses[winner].Body.String())
+	require.Equal(t, http.StatusConflict, responses[loser].Code, responses[loser].Body.String())
+	require.Equal(t, `"2"`, responses[loser].Header().Get("ETag"))
+
+	problem := decodeProblem(t, responses[loser])
+	current, ok := problem.Current.(map[string]interface{})
+	require.True(t, ok, "the conflict should carry the current resource")
+	require.Equal(t, float64(2), current["version"])
+
+	var won map[string]interface{}
+	require.NoError(t, json.Unmarshal(responses[winner].Body.Bytes(), &won))
+	require.Equal(t, won["id"], current["id"])
+	require.Equal(t, won["version"], current["version"])
+	return winner
+}
+
+func TestOptimisticLocking(t *testing.T) {
+	_ = apitestutils.CreateOrganization(t, testLockingOrgId, testLockingUserIdpId, testLockingOrgName)
+	authMiddleware := jwtassertion.NewMockMiddleware(t, testLockingOrgId, testLockingUserIdpId)
+	app := apitestutils.MakeAppClientWithDeps(t, wiring.TestClients{}, authMiddleware)
+	orgURL := fmt.Sprintf("/api/v1/orgs/%s", testLockingOrgName)
+
+	create := func(t *testing.T, url string, payload map[string]interface{}) string {
+		rr := sendManagedAgentRequest(t, app, http.MethodPost, url, payload, nil)
+		require.Equal(t, http.StatusCreated, rr.Code, rr.Body.String())
+		var created struct {
+			ID string `json:"id"`
+		}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &created))
+		return url + "/" + created.ID
+	}
+
+	t.Run("Racing agent updates based on the same version should let one win", func(t *testing.T) {
+		agentURL := create(t, orgURL+"/agents", managedAgentPayload("racing-agent", nil))
+		first := managedAgentPayload("racing-agent", map[string]string{"writer": "first"})
+		first["version"] = 1
+		second := managedAgentPayload("racing-agent", map[string]string{"writer": "second"})
+		second["version"] = 1
+
+		winner := requireOneWriteWins(t, raceUpdates(t, app, agentURL, first, second))
+
+		rr := sendManagedAgentRequest(t, app, http.MethodGet, agentURL+"/versions", nil, nil)
+		require.Equal(t, http.StatusOK, rr.Code)
+		var list struct {
+			Total int32 `json:"total"`
+		}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &list))
+		require.Equal(t, int32(2), list.Total, "the losing write should not record a version")
+
+		rr = sendManagedAgentRequest(t, app, http.MethodGet, agentURL, nil, nil)
+		require.Equal(t, http.StatusOK, rr.Code)
+		var agent struct {
+			Labels map[string]string `json:"labels"`
+		}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &agent))
+		require.Equal(t, []map[string]interface{}{first, second}[winner]["labels"], agent.Labels)
+	})
+
+	t.Run("Racing prompt updates based on the same version should let one win", func(t *testing.T) {
+		promptURL := create(t, orgURL+"/prompts", map[string]interface{}{"name": "racing-prompt", "template": "Hello"})
+		first := map[string]interface{}{"name": "racing-prompt", "template": "Hello from the first", "version": 1}
+		second := map[string]interface{}{"name": "racing-prompt", "template": "Hello from the second", "version": 1}
+		requireOneWriteWins(t, raceUpdates(t, app, promptURL, first, second))
+	})
+
+	t.Run("Racing tool updates based on the same version should let one win", func(t *testing.T) {
+		toolURL := create(t, orgURL+"/tools", toolPayload("racing_tool"))
+		first := toolPayload("racing_tool")
+		first["description"] = "Updated by the first"
+		first["version"] = 1
+		second := toolPayload("racing_tool")
+		second["description"] = "Updated by the second"
+		second["version"] = 1
+		requireOneWriteWins(t, raceUpdates(t, app, toolURL, first, second))
+	})
+
+	t.Run("Updating with a stale If-Match should return 412 with the current resource", func(t *testing.T) {
+		promptURL := create(t, orgURL+"/prompts", map[string]interface{}{"name": "stale-prompt", "template": "Hello"})
+		payload := map[string]interface{}{"name": "stale-prompt", "template": "Hello again"}
+		rr := sendManagedAgentRequest(t, app, http.MethodPut, promptURL, payload, map[string]string{"If-Match": `"1"`})
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		rr = sendManagedAgentRequest(t, app, http.MethodPut, promptURL, payload, map[string]string{"If-Match": `"1"`})
+		require.Equal(t, http.StatusPreconditionFailed, rr.Code)
+		problem := decodeProblem(t, rr)
+		current, ok := problem.Current.(map[string]interface{})
+		require.True(t, ok)
+		require.Equal(t, float64(2), current["version"])
+		require.Equal(t, "Hello again", current["template"])
+	})
+
+	t.Run("A body version that differs from If-Match should return 400", func(t *testing.T) {
+		promptURL := create(t, orgURL+"/prompts", map[string]interface{}{"name": "mismatched-prompt", "template": "Hello"})
+		payload := map[string]interface{}{"name": "mismatched-prompt", "template": "Hello again", "version": 2}
+		rr := sendManagedAgentRequest(t, app, http.MethodPut, promptURL, payload, map[string]string{"If-Match": `"1"`})
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Equal(t, []string{"version"}, problemFields(decodeProblem(t, rr)))
+	})
+}
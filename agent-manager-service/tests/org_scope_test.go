@@ -0,0 +1,135 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/db"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/middleware/jwtassertion"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/models"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/repositories"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/tests/apitestutils"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/wiring"
+)
+
+var (
+	testScopeUserIdpId = uuid.New()
+	testScopeOrgAId    = uuid.New()
+	testScopeOrgAName  = fmt.Sprintf("test-org-%s", uuid.New().String()[:5])
+	testScopeOrgBId    = uuid.New()
+	testScopeOrgBName  = fmt.Sprintf("test-org-%s", uuid.New().String()[:5])
+	testScopeOtherUser = uuid.New()
+	testScopeOrgCId    = uuid.New()
+	testScopeOrgCName  = fmt.Sprintf("test-org-%s", uuid.New().String()[:5])
+)
+
+func TestOrganizationScoping(t *testing.T) {
+	// The user owns organizations A and B, organization C belongs to another user
+	_ = apitestutils.CreateOrganization(t, testScopeOrgAId, testScopeUserIdpId, testScopeOrgAName)
+	_ = apitestutils.CreateOrganization(t, testScopeOrgBId, testScopeUserIdpId, testScopeOrgBName)
+	_ = apitestutils.CreateOrganization(t, testScopeOrgCId, testScopeOtherUser, testScopeOrgCName)
+	app := apitestutils.MakeAppClientWithDeps(t, wiring.TestClients{}, jwtassertion.NewMockMiddleware(t, testScopeOrgAId, testScopeUserIdpId))
+	agentsURL := func(orgName string) string { return fmt.Sprintf("/api/v1/orgs/%s/agents", orgName) }
+
+	rr := sendManagedAgentRequest(t, app, http.MethodPost, agentsURL(testScopeOrgBName), managedAgentPayload("org-b-agent", nil), nil)
+	require.Equal(t, http.StatusCreated, rr.Code)
+	var orgBAgent models.ManagedAgentResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &orgBAgent))
+
+	t.Run("Organizations of other users should not be found", func(t *testing.T) {
+		rr := sendManagedAgentRequest(t, app, http.MethodGet, agentsURL(testScopeOrgCName), nil, nil)
+		require.Equal(t, http.StatusNotFound, rr.Code)
+		require.Equal(t, "Organization not found", decodeProblem(t, rr).Detail)
+	})
+
+	t.Run("Ids of another organization should not be found", func(t *testing.T) {
+		for _, method := range []string{http.MethodGet, http.MethodDelete} {
+			rr := sendManagedAgentRequest(t, app, method, agentsURL(testScopeOrgAName)+"/"+orgBAgent.ID, nil, nil)
+			require.Equal(t, http.StatusNotFound, rr.Code, method)
+		}
+		rr := sendManagedAgentRequest(t, app, http.MethodGet, agentsURL(testScopeOrgBName)+"/"+orgBAgent.ID, nil, nil)
+		require.Equal(t, http.StatusOK, rr.Code)
+	})
+
+	t.Run("Tokens issued for an organization should not reach others", func(t *testing.T) {
+		orgAApp := apitestutils.MakeAppClientWithDeps(t, wiring.TestClients{}, jwtassertion.NewMockMiddlewareWithClaims(t, jwtassertion.TokenClaims{
+			Sub: testScopeUserIdpId,
+			Org: testScopeOrgAName,
+		}))
+		rr := sendManagedAgentRequest(t, orgAApp, http.MethodGet, agentsURL(testScopeOrgBName), nil, nil)
+		require.Equal(t, http.StatusNotFound, rr.Code)
+		rr = sendManagedAgentRequest(t, orgAApp, http.MethodGet, agentsURL(testScopeOrgAName), nil, nil)
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		rr = sendManagedAgentRequest(t, orgAApp, http.MethodGet, "/api/v1/orgs", nil, nil)
+		require.Equal(t, http.StatusOK, rr.Code)
+		require.Contains(t, rr.Body.String(), testScopeOrgAName)
+		require.NotContains(t, rr.Body.String(), testScopeOrgBName)
+	})
+
+	t.Run("API keys should be restricted to their organization", func(t *testing.T) {
+		payload := map[string]interface{}{"name": "org-a-key", "scopes": []string{"agents:read"}}
+		rr := sendManagedAgentRequest(t, app, http.MethodPost, fmt.Sprintf("/api/v1/orgs/%s/api-keys", testScopeOrgAName), payload, nil)
+		require.Equal(t, http.StatusCreated, rr.Code)
+		var created models.APIKeyCreateResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &created))
+		bearer := map[string]string{"Authorization": "Bearer " + created.Key}
+
+		rr = sendManagedAgentRequest(t, app, http.MethodGet, agentsURL(testScopeOrgAName), nil, bearer)
+		require.Equal(t, http.StatusOK, rr.Code)
+		rr = sendManagedAgentRequest(t, app, http.MethodGet, agentsURL(testScopeOrgBName), nil, bearer)
+		require.Equal(t, http.StatusNotFound, rr.Code)
+
+		rr = sendManagedAgentRequest(t, app, http.MethodGet, fmt.Sprintf("/api/v1/orgs/%s/api-keys", testScopeOrgBName), nil, nil)
+		require.Equal(t, http.StatusOK, rr.Code)
+		require.NotContains(t, rr.Body.String(), created.ID)
+	})
+
+	t.Run("Statements bound to an organization should not touch rows of another", func(t *testing.T) {
+		orgACtx := db.WithOrgScope(context.Background(), testScopeOrgAId)
+		agentRepo := repositories.NewManagedAgentRepository()
+		agentId := uuid.MustParse(orgBAgent.ID)
+
+		_, err := agentRepo.GetManagedAgentById(orgACtx, testScopeOrgBId, agentId)
+		require.True(t, db.IsRecordNotFoundError(err))
+		deleted, err := agentRepo.DeleteManagedAgent(orgACtx, testScopeOrgBId, agentId)
+		require.NoError(t, err)
+		require.False(t, deleted)
+
+		err = agentRepo.CreateManagedAgent(orgACtx, &models.ManagedAgent{
+			ID:        uuid.New(),
+			OrgID:     testScopeOrgBId,
+			Name:      "smuggled-agent",
+			Framework: "custom",
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+		})
+		require.ErrorIs(t, err, db.ErrCrossOrgWrite)
+
+		_, err = agentRepo.GetManagedAgentById(context.Background(), testScopeOrgBId, agentId)
+		require.NoError(t, err)
+	})
+}
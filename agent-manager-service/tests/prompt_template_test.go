@@ -0,0 +1,205 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/middleware/jwtassertion"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/models"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/tests/apitestutils"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/utils"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/wiring"
+)
+
+var (
+	testPromptOrgId     = uuid.New()
+	testPromptUserIdpId = uuid.New()
+	testPromptOrgName   = fmt.Sprintf("test-org-%s", uuid.New().String()[:5])
+)
+
+func TestPromptTemplates(t *testing.T) {
+	_ = apitestutils.CreateOrganization(t, testPromptOrgId, testPromptUserIdpId, testPromptOrgName)
+	authMiddleware := jwtassertion.NewMockMiddleware(t, testPromptOrgId, testPromptUserIdpId)
+	app := apitestutils.MakeAppClientWithDeps(t, wiring.TestClients{}, authMiddleware)
+	baseURL := fmt.Sprintf("/api/v1/orgs/%s/prompts", testPromptOrgName)
+	agentsURL := fmt.Sprintf("/api/v1/orgs/%s/agents", testPromptOrgName)
+
+	var created models.PromptTemplateResponse
+	t.Run("Creating a prompt should return 201 and declare every placeholder", func(t *testing.T) {
+		payload := map[string]interface{}{
+			"name":     "support-prompt",
+			"template": "You are helping {{customer_name}} on the {{ plan }} plan.",
+			"variables": []map[string]interface{}{
+				{"name": "plan", "description": "Subscription plan", "default": "free"},
+			},
+		}
+		rr := sendManagedAgentRequest(t, app, http.MethodPost, baseURL, payload, nil)
+		require.Equal(t, http.StatusCreated, rr.Code)
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &created))
+		require.Equal(t, int32(1), created.Version)
+		require.Equal(t, `"1"`, rr.Header().Get("ETag"))
+		require.Len(t, created.Variables, 2)
+		require.Equal(t, "customer_name", created.Variables[0].Name)
+		require.Nil(t, created.Variables[0].Default)
+		require.Equal(t, "plan", created.Variables[1].Name)
+	})
+	promptURL := baseURL + "/" + created.ID
+
+	validationTests := []struct {
+		name       string
+		payload    map[string]interface{}
+		wantFields []string
+	}{
+		{
+			name:       "an empty template",
+			payload:    map[string]interface{}{"name": "empty-prompt", "template": " "},
+			wantFields: []string{"template"},
+		},
+		{
+			name:       "a malformed placeholder",
+			payload:    map[string]interface{}{"name": "bad-prompt", "template": "Hello {{customer-name}}"},
+			wantFields: []string{"template"},
+		},
+		{
+			name: "a variable missing from the template",
+			payload: map[string]interface{}{
+				"name":      "unused-prompt",
+				"template":  "Hello {{customer_name}}",
+				"variables": []map[string]interface{}{{"name": "plan"}},
+			},
+			wantFields: []string{"variables[0].name"},
+		},
+	}
+	for _, tt := range validationTests {
+		t.Run(fmt.Sprintf("Creating a prompt with %s should return 400", tt.name), func(t *testing.T) {
+			rr := sendManagedAgentRequest(t, app, http.MethodPost, baseURL, tt.payload, nil)
+			require.Equal(t, http.StatusBadRequest, rr.Code)
+			require.ElementsMatch(t, tt.wantFields, problemFields(decodeProblem(t, rr)))
+		})
+	}
+
+	t.Run("Rejected prompt fields should be listed with their JSON pointer and constraint", func(t *testing.T) {
+		rr := sendManagedAgentRequest(t, app, http.MethodPost, baseURL, map[string]interface{}{
+			"name":      "unused-prompt",
+			"template":  "Hello {{customer_name}}",
+			"variables": []map[string]interface{}{{"name": "customer_name"}, {"name": "customer_name"}},
+		}, nil)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		problem := decodeProblem(t, rr)
+		require.Equal(t, "Invalid prompt", problem.Detail)
+		require.Equal(t, []utils.FieldError{{
+			Field:      "variables[1].name",
+			Pointer:    "/variables/1/name",
+			Constraint: utils.ConstraintUniqueItems,
+			Message:    `duplicate variable "customer_name"`,
+		}}, problem.Errors)
+	})
+
+	t.Run("Rendering a prompt should substitute variables and defaults", func(t *testing.T) {
+		rr := sendManagedAgentRequest(t, app, http.MethodPost, promptURL+"/render", map[string]interface{}{
+			"variables": map[string]string{"customer_name": "Ann"},
+		}, nil)
+		require.Equal(t, http.StatusOK, rr.Code)
+		var rendered models.PromptRenderResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &rendered))
+		require.Equal(t, "You are helping Ann on the free plan.", rendered.Text)
+		require.Equal(t, int32(1), rendered.Version)
+	})
+
+	t.Run("Rendering a prompt without a required variable should return 400", func(t *testing.T) {
+		rr := sendManagedAgentRequest(t, app, http.MethodPost, promptURL+"/render", map[string]interface{}{
+			"variables": map[string]string{"plan": "pro", "region": "eu"},
+		}, nil)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.ElementsMatch(t, []string{"variables.customer_name", "variables.region"}, problemFields(decodeProblem(t, rr)))
+	})
+
+	t.Run("Updating a prompt should record a new version", func(t *testing.T) {
+		payload := map[string]interface{}{"name": "support-prompt", "template": "Hi {{customer_name}}!"}
+		rr := sendManagedAgentRequest(t, app, http.MethodPut, promptURL, payload, map[string]string{"If-Match": `"1"`})
+		require.Equal(t, http.StatusOK, rr.Code)
+		require.Equal(t, `"2"`, rr.Header().Get("ETag"))
+
+		rr = sendManagedAgentRequest(t, app, http.MethodGet, promptURL+"/versions", nil, nil)
+		require.Equal(t, http.StatusOK, rr.Code)
+		var versions models.PromptTemplateVersionListResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &versions))
+		require.Equal(t, int32(2), versions.Total)
+		require.Equal(t, int32(2), versions.Versions[0].Version)
+		require.Equal(t, testPromptUserIdpId.String(), versions.Versions[0].Author)
+
+		rr = sendManagedAgentRequest(t, app, http.MethodPost, promptURL+"/render", map[string]interface{}{
+			"variables": map[string]string{"customer_name": "Ann"},
+			"version":   1,
+		}, nil)
+		require.Equal(t, http.StatusOK, rr.Code)
+		var rendered models.PromptRenderResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &rendered))
+		require.Equal(t, "You are helping Ann on the free plan.", rendered.Text)
+	})
+
+	t.Run("Creating an agent referencing an unknown prompt version should return 400", func(t *testing.T) {
+		payload := managedAgentPayload("prompted-agent", nil)
+		delete(payload, "systemPrompt")
+		payload["promptRef"] = map[string]interface{}{"promptId": created.ID, "version": 9}
+		rr := sendManagedAgentRequest(t, app, http.MethodPost, agentsURL, payload, nil)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Equal(t, []string{"promptRef.version"}, problemFields(decodeProblem(t, rr)))
+	})
+
+	t.Run("Creating an agent with both a system prompt and a prompt reference should return 400", func(t *testing.T) {
+		payload := managedAgentPayload("prompted-agent", nil)
+		payload["promptRef"] = map[string]interface{}{"promptId": created.ID, "version": 1}
+		rr := sendManagedAgentRequest(t, app, http.MethodPost, agentsURL, payload, nil)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Equal(t, []string{"promptRef"}, problemFields(decodeProblem(t, rr)))
+	})
+
+	var agent models.ManagedAgentResponse
+	t.Run("Creating an agent referencing a prompt version should return 201", func(t *testing.T) {
+		payload := managedAgentPayload("prompted-agent", nil)
+		delete(payload, "systemPrompt")
+		payload["promptRef"] = map[string]interface{}{"promptId": created.ID, "version": 1}
+		rr := sendManagedAgentRequest(t, app, http.MethodPost, agentsURL, payload, nil)
+		require.Equal(t, http.StatusCreated, rr.Code)
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &agent))
+		require.Equal(t, &models.PromptReference{PromptID: created.ID, Version: 1}, agent.PromptRef)
+	})
+
+	t.Run("Deleting a prompt referenced by agents should return 409 listing them", func(t *testing.T) {
+		rr := sendManagedAgentRequest(t, app, http.MethodDelete, promptURL, nil, nil)
+		require.Equal(t, http.StatusConflict, rr.Code)
+		problem := decodeProblem(t, rr)
+		require.Equal(t, []utils.DependentResource{{Kind: "agent", ID: agent.ID, Name: "prompted-agent"}}, problem.Dependents)
+	})
+
+	t.Run("Deleting a prompt after its agents are gone should return 204", func(t *testing.T) {
+		rr := sendManagedAgentRequest(t, app, http.MethodDelete, agentsURL+"/"+agent.ID, nil, nil)
+		require.Equal(t, http.StatusNoContent, rr.Code)
+		rr = sendManagedAgentRequest(t, app, http.MethodDelete, promptURL, nil, nil)
+		require.Equal(t, http.StatusNoContent, rr.Code)
+		rr = sendManagedAgentRequest(t, app, http.MethodGet, promptURL, nil, nil)
+		require.Equal(t, http.StatusNotFound, rr.Code)
+	})
+}
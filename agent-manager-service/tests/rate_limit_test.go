@@ -0,0 +1,83 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/middleware/jwtassertion"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/models"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/tests/apitestutils"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/wiring"
+)
+
+var (
+	testRateLimitOrgId     = uuid.New()
+	testRateLimitUserIdpId = uuid.New()
+	testRateLimitOrgName   = fmt.Sprintf("test-org-%s", uuid.New().String()[:5])
+)
+
+func TestRateLimit(t *testing.T) {
+	_ = apitestutils.CreateOrganization(t, testRateLimitOrgId, testRateLimitUserIdpId, testRateLimitOrgName)
+	authMiddleware := jwtassertion.NewMockMiddleware(t, testRateLimitOrgId, testRateLimitUserIdpId)
+	app := apitestutils.MakeAppClientWithDeps(t, wiring.TestClients{}, authMiddleware)
+	keysURL := fmt.Sprintf("/api/v1/orgs/%s/api-keys", testRateLimitOrgName)
+	agentsURL := fmt.Sprintf("/api/v1/orgs/%s/agents", testRateLimitOrgName)
+
+	var created models.APIKeyCreateResponse
+	t.Run("Creating an API key with a rate limit should return it", func(t *testing.T) {
+		rr := sendManagedAgentRequest(t, app, http.MethodPost, keysURL, map[string]interface{}{
+			"name":      "throttled-script",
+			"scopes":    []string{"agents:read"},
+			"rateLimit": map[string]interface{}{"requestsPerSecond": 0.01, "burst": 2},
+		}, nil)
+		require.Equal(t, http.StatusCreated, rr.Code)
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &created))
+		require.Equal(t, &models.RateLimit{RequestsPerSecond: 0.01, Burst: 2}, created.RateLimit)
+	})
+	bearer := map[string]string{"Authorization": "Bearer " + created.Key}
+
+	t.Run("Requests beyond the burst of an API key should return 429", func(t *testing.T) {
+		for i := 0; i < 2; i++ {
+			rr := sendManagedAgentRequest(t, app, http.MethodGet, agentsURL, nil, bearer)
+			require.Equal(t, http.StatusOK, rr.Code)
+		}
+		rr := sendManagedAgentRequest(t, app, http.MethodGet, agentsURL, nil, bearer)
+		require.Equal(t, http.StatusTooManyRequests, rr.Code)
+		require.NotEmpty(t, rr.Header().Get("Retry-After"))
+		decodeProblem(t, rr)
+	})
+
+	t.Run("Limiting an API key should not limit its owner's other requests", func(t *testing.T) {
+		rr := sendManagedAgentRequest(t, app, http.MethodGet, agentsURL, nil, nil)
+		require.Equal(t, http.StatusOK, rr.Code)
+	})
+
+	t.Run("Metrics should count allowed and limited requests", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		app.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+		require.Equal(t, http.StatusOK, rr.Code)
+		require.Contains(t, rr.Body.String(), `agent_manager_rate_limit_requests_total{result="limited"} 1`)
+	})
+}
@@ -0,0 +1,166 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package tests
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/db"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/middleware"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/middleware/correlation"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/middleware/jwtassertion"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/tests/apitestutils"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/wiring"
+)
+
+var (
+	testRequestLimitsOrgId     = uuid.New()
+	testRequestLimitsUserIdpId = uuid.New()
+	testRequestLimitsOrgName   = fmt.Sprintf("test-org-%s", uuid.New().String()[:5])
+)
+
+func TestRequestBodyLimit(t *testing.T) {
+	_ = apitestutils.CreateOrganization(t, testRequestLimitsOrgId, testRequestLimitsUserIdpId, testRequestLimitsOrgName)
+	authMiddleware := jwtassertion.NewMockMiddleware(t, testRequestLimitsOrgId, testRequestLimitsUserIdpId)
+	app := apitestutils.MakeAppClientWithDeps(t, wiring.TestClients{}, authMiddleware)
+	url := fmt.Sprintf("/api/v1/orgs/%s/agents", testRequestLimitsOrgName)
+	oversized := `{"name":"` + strings.Repeat("a", 2*1024*1024) + `"}`
+
+	t.Run("A body announced above the limit should return 413", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, url, strings.NewReader(oversized))
+		req.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+		app.ServeHTTP(rr, req)
+		require.Equal(t, http.StatusRequestEntityTooLarge, rr.Code)
+		decodeProblem(t, rr)
+	})
+
+	t.Run("A streamed body exceeding the limit should return 413", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, url, io.MultiReader(strings.NewReader(oversized)))
+		req.ContentLength = -1
+		req.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+		app.ServeHTTP(rr, req)
+		require.Equal(t, http.StatusRequestEntityTooLarge, rr.Code)
+		decodeProblem(t, rr)
+	})
+}
+
+// serveTimedOut serves a request whose handler runs work until its context is cancelled by the timeout,
+// the returned channel receives the error the work stopped with
+func serveTimedOut(t *testing.T, work func(ctx context.Context) error) (*httptest.ResponseRecorder, <-chan error) {
+	stopped := make(chan error, 1)
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /slow", func(w http.ResponseWriter, r *http.Request) {
+		stopped <- work(r.Context())
+		w.WriteHeader(http.StatusOK)
+	})
+	handler := middleware.LimitRequests(mux, middleware.RequestLimits{Timeout: 200 * time.Millisecond}, nil)(mux)
+
+	rr := httptest.NewRecorder()
+	start := time.Now()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/slow", nil))
+	require.Less(t, time.Since(start), 5*time.Second)
+	require.Equal(t, http.StatusServiceUnavailable, rr.Code)
+	decodeProblem(t, rr)
+	return rr, stopped
+}
+
+func TestRequestTimeout(t *testing.T) {
+	t.Run("A timed out request should cancel its database query", func(t *testing.T) {
+		marker := uuid.NewString()
+		_, stopped := serveTimedOut(t, func(ctx context.Context) error {
+			return db.DB(ctx).Exec(fmt.Sprintf("SELECT pg_sleep(30) /* %s */", marker)).Error
+		})
+		select {
+		case err := <-stopped:
+			require.Error(t, err)
+		case <-time.After(5 * time.Second):
+			t.Fatal("database query kept running after the request timed out")
+		}
+		// The query must stop on the server too, not only on the client side
+		require.Eventually(t, func() bool {
+			var running int64
+			err := db.DB(context.Background()).Raw(
+				"SELECT count(*) FROM pg_stat_activity WHERE state = 'active' AND query LIKE ?", "%"+marker+"%").
+				Scan(&running).Error
+			// The counting query lists itself
+			return err == nil && running <= 1
+		}, 5*time.Second, 100*time.Millisecond)
+	})
+
+	t.Run("A timed out request should cancel its downstream calls", func(t *testing.T) {
+		cancelled := make(chan struct{})
+		downstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case <-r.Context().Done():
+				close(cancelled)
+			case <-time.After(30 * time.Second):
+			}
+		}))
+		defer downstream.Close()
+
+		client := &http.Client{Transport: correlation.NewTransport(nil)}
+		_, stopped := serveTimedOut(t, func(ctx context.Context) error {
+			req, err := http.NewRequestWithContext(ctx, http.MethodGet, downstream.URL, nil)
+			if err != nil {
+				return err
+			}
+			res, err := client.Do(req)
+			if err == nil {
+				res.Body.Close()
+			}
+			return err
+		})
+		select {
+		case err := <-stopped:
+			require.ErrorIs(t, err, context.DeadlineExceeded)
+		case <-time.After(5 * time.Second):
+			t.Fatal("downstream call kept running after the request timed out")
+		}
+		select {
+		case <-cancelled:
+		case <-time.After(5 * time.Second):
+			t.Fatal("downstream service did not see the request cancelled")
+		}
+	})
+
+	t.Run("A route without a timeout should run to completion", func(t *testing.T) {
+		mux := http.NewServeMux()
+		mux.HandleFunc("GET /export", func(w http.ResponseWriter, r *http.Request) {
+			time.Sleep(300 * time.Millisecond)
+			require.NoError(t, r.Context().Err())
+			w.WriteHeader(http.StatusOK)
+		})
+		handler := middleware.LimitRequests(mux, middleware.RequestLimits{Timeout: 100 * time.Millisecond},
+			map[string]middleware.RequestLimits{"GET /export": {}})(mux)
+
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/export", nil))
+		require.Equal(t, http.StatusOK, rr.Code)
+	})
+}
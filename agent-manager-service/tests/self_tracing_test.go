@@ -0,0 +1,107 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package tests
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/middleware/jwtassertion"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/tests/apitestutils"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/wiring"
+)
+
+func TestSelfTracing(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	previousProvider, previousPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previousProvider)
+		otel.SetTextMapPropagator(previousPropagator)
+	})
+
+	orgId := uuid.New()
+	userIdpId := uuid.New()
+	projId := uuid.New()
+	orgName := fmt.Sprintf("trace-org-%s", uuid.New().String()[:5])
+	projName := fmt.Sprintf("trace-project-%s", uuid.New().String()[:5])
+	_ = apitestutils.CreateOrganization(t, orgId, userIdpId, orgName)
+	_ = apitestutils.CreateProject(t, projId, orgId, projName)
+
+	app := apitestutils.MakeAppClientWithDeps(t, wiring.TestClients{
+		OpenChoreoSvcClient: createMockOpenChoreoClient(),
+		TraceObserverClient: createMockTraceObserverClient(),
+	}, jwtassertion.NewMockMiddleware(t, orgId, userIdpId))
+
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	req := httptest.NewRequest(http.MethodGet,
+		fmt.Sprintf("/api/v1/orgs/%s/projects/%s/agents/trace-agent/traces?environment=Development", orgName, projName), nil)
+	req.Header.Set("traceparent", "00-"+traceID+"-00f067aa0ba902b7-01")
+	rr := httptest.NewRecorder()
+	app.ServeHTTP(rr, req)
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	var server sdktrace.ReadOnlySpan
+	var statements []sdktrace.ReadOnlySpan
+	for _, span := range recorder.Ended() {
+		if span.SpanKind() == trace.SpanKindServer {
+			server = span
+		}
+	}
+	require.NotNil(t, server)
+	for _, span := range recorder.Ended() {
+		if span.SpanKind() == trace.SpanKindClient && span.Parent().SpanID() == server.SpanContext().SpanID() {
+			statements = append(statements, span)
+		}
+	}
+
+	t.Run("The server span should be named after the route template", func(t *testing.T) {
+		require.Equal(t, traceID, server.SpanContext().TraceID().String(), "the span should continue the trace of the caller")
+		route := "/api/v1/orgs/{orgName}/projects/{projName}/agents/{agentName}/traces"
+		require.Equal(t, "GET "+route, server.Name())
+		attrs := map[string]string{}
+		for _, attr := range server.Attributes() {
+			attrs[string(attr.Key)] = attr.Value.Emit()
+			require.NotContains(t, attr.Value.Emit(), orgName, "attribute %s should not carry the raw path", attr.Key)
+		}
+		require.Equal(t, route, attrs["http.route"])
+		require.Equal(t, "200", attrs["http.response.status_code"])
+	})
+
+	t.Run("Database spans should carry sanitized statements", func(t *testing.T) {
+		require.NotEmpty(t, statements)
+		for _, span := range statements {
+			for _, attr := range span.Attributes() {
+				if attr.Key == "db.query.text" {
+					require.False(t, strings.Contains(attr.Value.AsString(), orgName), "statement %q should not carry the org name", attr.Value.AsString())
+				}
+			}
+		}
+	})
+}
@@ -0,0 +1,144 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/middleware/jwtassertion"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/models"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/tests/apitestutils"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/utils"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/wiring"
+)
+
+var (
+	testSoftDeleteOrgId     = uuid.New()
+	testSoftDeleteUserIdpId = uuid.New()
+	testSoftDeleteOrgName   = fmt.Sprintf("test-org-%s", uuid.New().String()[:5])
+)
+
+func TestSoftDeleteAndRestore(t *testing.T) {
+	_ = apitestutils.CreateOrganization(t, testSoftDeleteOrgId, testSoftDeleteUserIdpId, testSoftDeleteOrgName)
+	app := apitestutils.MakeAppClientWithDeps(t, wiring.TestClients{}, jwtassertion.NewMockMiddleware(t, testSoftDeleteOrgId, testSoftDeleteUserIdpId))
+	editorApp := apitestutils.MakeAppClientWithDeps(t, wiring.TestClients{},
+		jwtassertion.NewMockMiddlewareWithRoles(t, testSoftDeleteOrgId, testSoftDeleteUserIdpId, utils.RoleEditor))
+	agentsURL := fmt.Sprintf("/api/v1/orgs/%s/agents", testSoftDeleteOrgName)
+	promptsURL := fmt.Sprintf("/api/v1/orgs/%s/prompts", testSoftDeleteOrgName)
+
+	var prompt models.PromptTemplateResponse
+	rr := sendManagedAgentRequest(t, app, http.MethodPost, promptsURL, map[string]interface{}{"name": "trash-prompt", "template": "You are a support agent."}, nil)
+	require.Equal(t, http.StatusCreated, rr.Code)
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &prompt))
+	promptURL := promptsURL + "/" + prompt.ID
+
+	var agent models.ManagedAgentResponse
+	payload := managedAgentPayload("trash-agent", nil)
+	delete(payload, "systemPrompt")
+	payload["promptRef"] = map[string]interface{}{"promptId": prompt.ID, "version": 1}
+	rr = sendManagedAgentRequest(t, app, http.MethodPost, agentsURL, payload, nil)
+	require.Equal(t, http.StatusCreated, rr.Code)
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &agent))
+	agentURL := agentsURL + "/" + agent.ID
+
+	listAgents := func(t *testing.T, query string) []string {
+		rr := sendManagedAgentRequest(t, app, http.MethodGet, agentsURL+query, nil, nil)
+		require.Equal(t, http.StatusOK, rr.Code)
+		var response models.ManagedAgentListResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+		names := make([]string, 0, len(response.Agents))
+		for _, listed := range response.Agents {
+			names = append(names, listed.Name)
+		}
+		return names
+	}
+
+	t.Run("A deleted agent should only be returned when deleted agents are included", func(t *testing.T) {
+		rr := sendManagedAgentRequest(t, app, http.MethodDelete, agentURL, nil, nil)
+		require.Equal(t, http.StatusNoContent, rr.Code)
+
+		rr = sendManagedAgentRequest(t, app, http.MethodGet, agentURL, nil, nil)
+		require.Equal(t, http.StatusNotFound, rr.Code)
+		require.Empty(t, listAgents(t, ""))
+		require.Equal(t, []string{"trash-agent"}, listAgents(t, "?includeDeleted=true"))
+
+		// Readers can still resolve the tombstone of an agent, e.g. one referenced by traces
+		rr = sendManagedAgentRequest(t, editorApp, http.MethodGet, agentURL+"?includeDeleted=true", nil, nil)
+		require.Equal(t, http.StatusOK, rr.Code)
+		var tombstone models.ManagedAgentResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &tombstone))
+		require.Equal(t, "trash-agent", tombstone.Name)
+		require.NotNil(t, tombstone.DeletedAt)
+	})
+
+	t.Run("Listing or restoring deleted resources without trash:manage should return 403", func(t *testing.T) {
+		rr := sendManagedAgentRequest(t, editorApp, http.MethodGet, agentsURL+"?includeDeleted=true", nil, nil)
+		require.Equal(t, http.StatusForbidden, rr.Code)
+		rr = sendManagedAgentRequest(t, editorApp, http.MethodGet, promptsURL+"?includeDeleted=true", nil, nil)
+		require.Equal(t, http.StatusForbidden, rr.Code)
+		rr = sendManagedAgentRequest(t, editorApp, http.MethodPost, agentURL+"/restore", nil, nil)
+		require.Equal(t, http.StatusForbidden, rr.Code)
+	})
+
+	t.Run("Restoring an agent whose prompt was deleted should return 409", func(t *testing.T) {
+		// The deleted agent no longer holds on to the prompt
+		rr := sendManagedAgentRequest(t, app, http.MethodDelete, promptURL, nil, nil)
+		require.Equal(t, http.StatusNoContent, rr.Code)
+
+		rr = sendManagedAgentRequest(t, app, http.MethodPost, agentURL+"/restore", nil, nil)
+		require.Equal(t, http.StatusConflict, rr.Code)
+		rr = sendManagedAgentRequest(t, app, http.MethodGet, agentURL, nil, nil)
+		require.Equal(t, http.StatusNotFound, rr.Code)
+	})
+
+	t.Run("Restoring the prompt and then the agent should bring both back unchanged", func(t *testing.T) {
+		rr := sendManagedAgentRequest(t, app, http.MethodPost, promptURL+"/restore", nil, nil)
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		rr = sendManagedAgentRequest(t, app, http.MethodPost, agentURL+"/restore", nil, nil)
+		require.Equal(t, http.StatusOK, rr.Code)
+		require.Equal(t, `"1"`, rr.Header().Get("ETag"))
+		var restored models.ManagedAgentResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &restored))
+		require.Nil(t, restored.DeletedAt)
+		require.Equal(t, agent.PromptRef, restored.PromptRef)
+		require.Equal(t, []string{"trash-agent"}, listAgents(t, ""))
+	})
+
+	t.Run("Restoring an agent that is not deleted should return 404", func(t *testing.T) {
+		rr := sendManagedAgentRequest(t, app, http.MethodPost, agentURL+"/restore", nil, nil)
+		require.Equal(t, http.StatusNotFound, rr.Code)
+	})
+
+	t.Run("The name of a deleted agent should be reusable and block restoring it", func(t *testing.T) {
+		rr := sendManagedAgentRequest(t, app, http.MethodDelete, agentURL, nil, nil)
+		require.Equal(t, http.StatusNoContent, rr.Code)
+
+		rr = sendManagedAgentRequest(t, app, http.MethodPost, agentsURL, managedAgentPayload("trash-agent", nil), nil)
+		require.Equal(t, http.StatusCreated, rr.Code)
+
+		rr = sendManagedAgentRequest(t, app, http.MethodPost, agentURL+"/restore", nil, nil)
+		require.Equal(t, http.StatusConflict, rr.Code)
+		require.Equal(t, []string{"name"}, problemFields(decodeProblem(t, rr)))
+	})
+}
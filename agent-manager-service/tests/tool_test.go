@@ -0,0 +1,183 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/middleware/jwtassertion"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/models"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/spec"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/tests/apitestutils"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/utils"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/wiring"
+)
+
+var (
+	testToolOrgId     = uuid.New()
+	testToolUserIdpId = uuid.New()
+	testToolOrgName   = fmt.Sprintf("test-org-%s", uuid.New().String()[:5])
+)
+
+func toolPayload(name string) map[string]interface{} {
+	return map[string]interface{}{
+		"name":        name,
+		"description": "Searches the product documentation",
+		"parameters": map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"query": map[string]interface{}{"type": "string"},
+			},
+			"required": []string{"query"},
+		},
+		"endpoint": map[string]interface{}{"type": "http", "url": "https://tools.example.com/search"},
+		"tags":     []string{"search"},
+	}
+}
+
+func TestTools(t *testing.T) {
+	_ = apitestutils.CreateOrganization(t, testToolOrgId, testToolUserIdpId, testToolOrgName)
+	authMiddleware := jwtassertion.NewMockMiddleware(t, testToolOrgId, testToolUserIdpId)
+	app := apitestutils.MakeAppClientWithDeps(t, wiring.TestClients{}, authMiddleware)
+	baseURL := fmt.Sprintf("/api/v1/orgs/%s/tools", testToolOrgName)
+	agentsURL := fmt.Sprintf("/api/v1/orgs/%s/agents", testToolOrgName)
+
+	var created models.ToolResponse
+	t.Run("Registering a tool should return 201", func(t *testing.T) {
+		rr := sendManagedAgentRequest(t, app, http.MethodPost, baseURL, toolPayload("search_docs"), nil)
+		require.Equal(t, http.StatusCreated, rr.Code)
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &created))
+		require.Equal(t, "search_docs", created.Name)
+		require.Equal(t, int32(1), created.Version)
+		require.Equal(t, `"1"`, rr.Header().Get("ETag"))
+	})
+	toolURL := baseURL + "/" + created.ID
+
+	validationTests := []struct {
+		name       string
+		mutate     func(payload map[string]interface{})
+		wantFields []string
+	}{
+		{
+			name: "an invalid parameters schema",
+			mutate: func(payload map[string]interface{}) {
+				payload["parameters"] = map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"query": map[string]interface{}{"type": "text"},
+					},
+				}
+			},
+			wantFields: []string{"parameters.properties.query.type"},
+		},
+		{
+			name: "parameters that are not an object schema",
+			mutate: func(payload map[string]interface{}) {
+				payload["parameters"] = map[string]interface{}{"type": "string"}
+			},
+			wantFields: []string{"parameters.type"},
+		},
+		{
+			name: "an http endpoint without a URL",
+			mutate: func(payload map[string]interface{}) {
+				payload["endpoint"] = map[string]interface{}{"type": "http"}
+			},
+			wantFields: []string{"endpoint.url"},
+		},
+	}
+	for _, tt := range validationTests {
+		t.Run(fmt.Sprintf("Registering a tool with %s should return 400", tt.name), func(t *testing.T) {
+			payload := toolPayload("invalid_tool")
+			tt.mutate(payload)
+			rr := sendManagedAgentRequest(t, app, http.MethodPost, baseURL, payload, nil)
+			require.Equal(t, http.StatusBadRequest, rr.Code)
+			require.ElementsMatch(t, tt.wantFields, problemFields(decodeProblem(t, rr)))
+		})
+	}
+
+	t.Run("Registering a tool with a duplicate name should return 409", func(t *testing.T) {
+		rr := sendManagedAgentRequest(t, app, http.MethodPost, baseURL, toolPayload("search_docs"), nil)
+		require.Equal(t, http.StatusConflict, rr.Code)
+	})
+
+	t.Run("Listing tools by tag should return the tagged tools", func(t *testing.T) {
+		rr := sendManagedAgentRequest(t, app, http.MethodGet, baseURL+"?tags=search&count=true", nil, nil)
+		require.Equal(t, http.StatusOK, rr.Code)
+		var list models.ToolListResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &list))
+		require.Equal(t, spec.PtrInt32(1), list.Total)
+		require.Len(t, list.Tools, 1)
+
+		rr = sendManagedAgentRequest(t, app, http.MethodGet, baseURL+"?tags=billing&count=true", nil, nil)
+		require.Equal(t, http.StatusOK, rr.Code)
+		list = models.ToolListResponse{}
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &list))
+		require.Equal(t, spec.PtrInt32(0), list.Total)
+		require.Empty(t, list.Tools)
+	})
+
+	t.Run("Creating an agent referencing an unknown tool should return 400", func(t *testing.T) {
+		payload := managedAgentPayload("tool-agent", nil)
+		payload["tools"] = []map[string]interface{}{{"toolId": uuid.New().String()}}
+		rr := sendManagedAgentRequest(t, app, http.MethodPost, agentsURL, payload, nil)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Equal(t, []string{"tools[0].toolId"}, problemFields(decodeProblem(t, rr)))
+	})
+
+	var agent models.ManagedAgentResponse
+	t.Run("Resolving the tools of an agent should return tool definitions", func(t *testing.T) {
+		payload := managedAgentPayload("tool-agent", nil)
+		payload["tools"] = []map[string]interface{}{{"toolId": created.ID}, {"name": "calculator"}}
+		rr := sendManagedAgentRequest(t, app, http.MethodPost, agentsURL, payload, nil)
+		require.Equal(t, http.StatusCreated, rr.Code)
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &agent))
+
+		rr = sendManagedAgentRequest(t, app, http.MethodGet, agentsURL+"/"+agent.ID+"/tools", nil, nil)
+		require.Equal(t, http.StatusOK, rr.Code)
+		var tools models.AgentToolsResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &tools))
+		require.Len(t, tools.Tools, 2)
+		require.Equal(t, "search_docs", tools.Tools[0].Name)
+		require.Equal(t, created.ID, tools.Tools[0].ToolID)
+		require.Equal(t, "Searches the product documentation", tools.Tools[0].Description)
+		require.JSONEq(t, `{"type":"object","properties":{"query":{"type":"string"}},"required":["query"]}`, tools.Tools[0].Parameters)
+		require.Equal(t, "calculator", tools.Tools[1].Name)
+		require.Empty(t, tools.Tools[1].ToolID)
+	})
+
+	t.Run("Deleting a tool referenced by agents should return 409 listing them", func(t *testing.T) {
+		rr := sendManagedAgentRequest(t, app, http.MethodDelete, toolURL, nil, nil)
+		require.Equal(t, http.StatusConflict, rr.Code)
+		problem := decodeProblem(t, rr)
+		require.Equal(t, []utils.DependentResource{{Kind: "agent", ID: agent.ID, Name: "tool-agent"}}, problem.Dependents)
+	})
+
+	t.Run("Deleting a tool after its agents are gone should return 204", func(t *testing.T) {
+		rr := sendManagedAgentRequest(t, app, http.MethodDelete, agentsURL+"/"+agent.ID, nil, nil)
+		require.Equal(t, http.StatusNoContent, rr.Code)
+		rr = sendManagedAgentRequest(t, app, http.MethodDelete, toolURL, nil, nil)
+		require.Equal(t, http.StatusNoContent, rr.Code)
+		rr = sendManagedAgentRequest(t, app, http.MethodGet, toolURL, nil, nil)
+		require.Equal(t, http.StatusNotFound, rr.Code)
+	})
+}
@@ -0,0 +1,195 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/clients/clientmocks"
+	traceobserversvc "github.com/wso2/ai-agent-management-platform/agent-manager-service/clients/traceobserversvc"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/middleware/jwtassertion"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/models"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/repositories"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/tests/apitestutils"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/wiring"
+)
+
+var (
+	testTraceViewOrgId     = uuid.New()
+	testTraceViewUserIdpId = uuid.New()
+	testTraceViewOrgName   = fmt.Sprintf("test-org-%s", uuid.New().String()[:5])
+)
+
+func TestTraceViews(t *testing.T) {
+	_ = apitestutils.CreateOrganization(t, testTraceViewOrgId, testTraceViewUserIdpId, testTraceViewOrgName)
+	authMiddleware := jwtassertion.NewMockMiddleware(t, testTraceViewOrgId, testTraceViewUserIdpId)
+	traceObserverClient := &clientmocks.TraceObserverClientMock{
+		ListTracesFunc: func(ctx context.Context, params traceobserversvc.ListTracesParams) (*traceobserversvc.TraceOverviewResponse, error) {
+			return &traceobserversvc.TraceOverviewResponse{Traces: []traceobserversvc.TraceOverview{}}, nil
+		},
+	}
+	app := apitestutils.MakeAppClientWithDeps(t, wiring.TestClients{TraceObserverClient: traceObserverClient}, authMiddleware)
+	orgURL := fmt.Sprintf("/api/v1/orgs/%s", testTraceViewOrgName)
+
+	rr := sendManagedAgentRequest(t, app, http.MethodPost, orgURL+"/agents", managedAgentPayload("viewed-agent", nil), nil)
+	require.Equal(t, http.StatusCreated, rr.Code)
+	var agent models.ManagedAgentResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &agent))
+	tracesURL := orgURL + "/agents/" + agent.ID + "/traces"
+
+	// Views another member of the organization saved
+	traceViewRepo := repositories.NewTraceViewRepository()
+	otherUserIdpId := uuid.New()
+	saveOtherView := func(name string, visibility string, filters map[string]string) *models.TraceView {
+		view := &models.TraceView{
+			ID:         uuid.New(),
+			OrgID:      testTraceViewOrgId,
+			CreatedBy:  otherUserIdpId,
+			Name:       name,
+			Visibility: visibility,
+			Filters:    filters,
+			CreatedAt:  time.Now(),
+		}
+		require.NoError(t, traceViewRepo.CreateTraceView(context.Background(), view))
+		return view
+	}
+	sharedView := saveOtherView("shared errors", "org", map[string]string{"status": "error"})
+	privateView := saveOtherView("private errors", "private", map[string]string{"status": "error"})
+	staleView := saveOtherView("stale", "org", map[string]string{"status": "error", "environment": "prod"})
+
+	lastListTracesParams := func(t *testing.T) traceobserversvc.ListTracesParams {
+		calls := traceObserverClient.ListTracesCalls()
+		require.NotEmpty(t, calls)
+		return calls[len(calls)-1].Params
+	}
+
+	var slowErrors models.TraceViewResponse
+	t.Run("Creating a trace view should store its filters", func(t *testing.T) {
+		rr := sendManagedAgentRequest(t, app, http.MethodPost, orgURL+"/trace-views", map[string]interface{}{
+			"name":      " slow errors ",
+			"filters":   map[string]string{"status": "error", "minDuration": "2s", "model": "gpt-4o"},
+			"sort":      "duration",
+			"sortOrder": "desc",
+		}, nil)
+		require.Equal(t, http.StatusCreated, rr.Code)
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &slowErrors))
+		require.Equal(t, "slow errors", slowErrors.Name)
+		require.Equal(t, "private", slowErrors.Visibility)
+		require.Equal(t, testTraceViewUserIdpId.String(), slowErrors.CreatedBy)
+		require.Equal(t, orgURL+"/trace-views/"+slowErrors.ID, rr.Header().Get("Location"))
+	})
+
+	t.Run("Creating a trace view with a name already used should return 409", func(t *testing.T) {
+		rr := sendManagedAgentRequest(t, app, http.MethodPost, orgURL+"/trace-views", map[string]interface{}{"name": "slow errors"}, nil)
+		require.Equal(t, http.StatusConflict, rr.Code)
+	})
+
+	t.Run("Creating a trace view referencing unknown filters should return 400", func(t *testing.T) {
+		rr := sendManagedAgentRequest(t, app, http.MethodPost, orgURL+"/trace-views", map[string]interface{}{
+			"name":       "broken",
+			"visibility": "team",
+			"filters":    map[string]string{"status": "failed", "environment": "prod"},
+			"sort":       "cost",
+		}, nil)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.ElementsMatch(t, []string{"visibility", "filters.environment", "filters.status", "sort"}, problemFields(decodeProblem(t, rr)))
+	})
+
+	t.Run("Listing trace views should return own views and those shared with the organization", func(t *testing.T) {
+		rr := sendManagedAgentRequest(t, app, http.MethodGet, orgURL+"/trace-views", nil, nil)
+		require.Equal(t, http.StatusOK, rr.Code)
+		var list models.TraceViewListResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &list))
+		require.Equal(t, int32(3), list.Total)
+		names := []string{}
+		for _, view := range list.Views {
+			names = append(names, view.Name)
+		}
+		require.Equal(t, []string{"shared errors", "slow errors", "stale"}, names)
+
+		rr = sendManagedAgentRequest(t, app, http.MethodGet, orgURL+"/trace-views/"+privateView.ID.String(), nil, nil)
+		require.Equal(t, http.StatusNotFound, rr.Code)
+	})
+
+	t.Run("Listing traces with a view should apply its filters and sort", func(t *testing.T) {
+		rr := sendManagedAgentRequest(t, app, http.MethodGet, tracesURL+"?view="+slowErrors.ID, nil, nil)
+		require.Equal(t, http.StatusOK, rr.Code)
+		params := lastListTracesParams(t)
+		require.Equal(t, "duration", params.Sort)
+		require.Equal(t, "desc", params.SortOrder)
+		require.Equal(t, traceobserversvc.TraceFilters{Status: "error", MinDuration: 2 * time.Second, Model: "gpt-4o"}, params.Filters)
+	})
+
+	t.Run("Parameters of the request should override the filters of the view", func(t *testing.T) {
+		rr := sendManagedAgentRequest(t, app, http.MethodGet, tracesURL+"?view="+slowErrors.ID+"&minDuration=500ms&model=&sortOrder=asc&hasGuardrailViolation=true", nil, nil)
+		require.Equal(t, http.StatusOK, rr.Code)
+		params := lastListTracesParams(t)
+		require.Equal(t, "asc", params.SortOrder)
+		hasViolation := true
+		require.Equal(t, traceobserversvc.TraceFilters{Status: "error", MinDuration: 500 * time.Millisecond, HasGuardrailViolation: &hasViolation}, params.Filters)
+	})
+
+	t.Run("Listing traces with a view shared by another member should apply it", func(t *testing.T) {
+		rr := sendManagedAgentRequest(t, app, http.MethodGet, tracesURL+"?view="+sharedView.ID.String(), nil, nil)
+		require.Equal(t, http.StatusOK, rr.Code)
+		require.Equal(t, "error", lastListTracesParams(t).Filters.Status)
+	})
+
+	t.Run("Listing traces with a view that is not visible should return 404", func(t *testing.T) {
+		rr := sendManagedAgentRequest(t, app, http.MethodGet, tracesURL+"?view="+privateView.ID.String(), nil, nil)
+		require.Equal(t, http.StatusNotFound, rr.Code)
+		rr = sendManagedAgentRequest(t, app, http.MethodGet, tracesURL+"?view=not-a-uuid", nil, nil)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("Listing traces with a view referencing a filter that no longer exists should return 400", func(t *testing.T) {
+		calls := len(traceObserverClient.ListTracesCalls())
+		rr := sendManagedAgentRequest(t, app, http.MethodGet, tracesURL+"?view="+staleView.ID.String(), nil, nil)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Equal(t, []string{"view"}, problemFields(decodeProblem(t, rr)))
+		require.Len(t, traceObserverClient.ListTracesCalls(), calls)
+	})
+
+	t.Run("Listing traces with an invalid filter should return 400", func(t *testing.T) {
+		rr := sendManagedAgentRequest(t, app, http.MethodGet, tracesURL+"?status=failed", nil, nil)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		rr = sendManagedAgentRequest(t, app, http.MethodGet, tracesURL+"?sort=cost", nil, nil)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	t.Run("Deleting a view shared by another member should return 403", func(t *testing.T) {
+		rr := sendManagedAgentRequest(t, app, http.MethodDelete, orgURL+"/trace-views/"+sharedView.ID.String(), nil, nil)
+		require.Equal(t, http.StatusForbidden, rr.Code)
+		rr = sendManagedAgentRequest(t, app, http.MethodDelete, orgURL+"/trace-views/"+privateView.ID.String(), nil, nil)
+		require.Equal(t, http.StatusNotFound, rr.Code)
+	})
+
+	t.Run("Deleting own view should remove it", func(t *testing.T) {
+		rr := sendManagedAgentRequest(t, app, http.MethodDelete, orgURL+"/trace-views/"+slowErrors.ID, nil, nil)
+		require.Equal(t, http.StatusNoContent, rr.Code)
+		rr = sendManagedAgentRequest(t, app, http.MethodGet, orgURL+"/trace-views/"+slowErrors.ID, nil, nil)
+		require.Equal(t, http.StatusNotFound, rr.Code)
+	})
+}
@@ -0,0 +1,171 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package tests
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/db"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/models"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/repositories"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/tests/apitestutils"
+)
+
+var (
+	testTxUserIdpId = uuid.New()
+	testTxOrgId     = uuid.New()
+	testTxOrgName   = fmt.Sprintf("test-org-%s", uuid.New().String()[:5])
+)
+
+func newTxTestAgent(name string) *models.ManagedAgent {
+	return &models.ManagedAgent{
+		ID:        uuid.New(),
+		OrgID:     testTxOrgId,
+		Name:      name,
+		Framework: "custom",
+		Enabled:   true,
+		Version:   1,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+}
+
+func TestWithTx(t *testing.T) {
+	_ = apitestutils.CreateOrganization(t, testTxOrgId, testTxUserIdpId, testTxOrgName)
+	agentRepo := repositories.NewManagedAgentRepository()
+	versionRepo := repositories.NewManagedAgentVersionRepository()
+	ctx := context.Background()
+
+	createAgentWithVersion := func(txCtx context.Context, agent *models.ManagedAgent) error {
+		if err := agentRepo.CreateManagedAgent(txCtx, agent); err != nil {
+			return err
+		}
+		return versionRepo.CreateManagedAgentVersion(txCtx, &models.ManagedAgentVersion{
+			AgentID:   agent.ID,
+			Version:   agent.Version,
+			Name:      agent.Name,
+			Framework: agent.Framework,
+			Enabled:   agent.Enabled,
+			CreatedAt: agent.CreatedAt,
+		})
+	}
+
+	t.Run("A failure mid-transaction should roll back the earlier writes", func(t *testing.T) {
+		agent := newTxTestAgent("tx-rollback-agent")
+		failure := errors.New("audit write failed")
+		err := db.WithTx(ctx, func(txCtx context.Context) error {
+			if err := createAgentWithVersion(txCtx, agent); err != nil {
+				return err
+			}
+			// The writes are visible inside the transaction
+			_, err := agentRepo.GetManagedAgentById(txCtx, testTxOrgId, agent.ID)
+			require.NoError(t, err)
+			return failure
+		})
+		require.ErrorIs(t, err, failure)
+
+		_, err = agentRepo.GetManagedAgentById(ctx, testTxOrgId, agent.ID)
+		require.True(t, db.IsRecordNotFoundError(err))
+		_, err = versionRepo.GetManagedAgentVersion(ctx, agent.ID, 1)
+		require.True(t, db.IsRecordNotFoundError(err))
+	})
+
+	t.Run("A failed savepoint should not roll back the enclosing transaction", func(t *testing.T) {
+		agent := newTxTestAgent("tx-savepoint-agent")
+		err := db.WithTx(ctx, func(txCtx context.Context) error {
+			if err := createAgentWithVersion(txCtx, agent); err != nil {
+				return err
+			}
+			nested := db.WithTx(txCtx, func(nestedCtx context.Context) error {
+				if err := agentRepo.CreateManagedAgent(nestedCtx, newTxTestAgent("tx-nested-agent")); err != nil {
+					return err
+				}
+				return errors.New("nested failure")
+			})
+			require.Error(t, nested)
+			return nil
+		})
+		require.NoError(t, err)
+
+		_, err = agentRepo.GetManagedAgentById(ctx, testTxOrgId, agent.ID)
+		require.NoError(t, err)
+		_, err = agentRepo.GetManagedAgentByName(ctx, testTxOrgId, "tx-nested-agent")
+		require.True(t, db.IsRecordNotFoundError(err))
+	})
+
+	t.Run("Transactions aborted by a conflict should run again", func(t *testing.T) {
+		agent := newTxTestAgent("tx-retried-agent")
+		attempts := 0
+		err := db.WithTx(ctx, func(txCtx context.Context) error {
+			attempts++
+			if err := createAgentWithVersion(txCtx, agent); err != nil {
+				return err
+			}
+			if attempts == 1 {
+				return fmt.Errorf("failed to record audit entry: %w", &pgconn.PgError{Code: "40P01", Message: "deadlock detected"})
+			}
+			return nil
+		})
+		require.NoError(t, err)
+		require.Equal(t, 2, attempts)
+		versions, total, err := versionRepo.ListManagedAgentVersions(ctx, agent.ID, 10, 0)
+		require.NoError(t, err)
+		require.EqualValues(t, 1, total)
+		require.Len(t, versions, 1)
+	})
+
+	t.Run("Conflicts should be retried a bounded number of times", func(t *testing.T) {
+		attempts := 0
+		err := db.WithTx(ctx, func(txCtx context.Context) error {
+			attempts++
+			return &pgconn.PgError{Code: "40001", Message: "could not serialize access"}
+		})
+		require.True(t, db.IsTxConflictError(err))
+		require.Equal(t, 3, attempts)
+
+		attempts = 0
+		err = db.WithTx(ctx, func(txCtx context.Context) error {
+			attempts++
+			return &pgconn.PgError{Code: "23505", Message: "duplicate key value"}
+		})
+		require.True(t, db.IsUniqueViolationError(err))
+		require.Equal(t, 1, attempts)
+	})
+
+	t.Run("Cancelled requests should not run their transaction", func(t *testing.T) {
+		cancelled, cancel := context.WithCancel(ctx)
+		cancel()
+		ran := false
+		err := db.WithTx(cancelled, func(txCtx context.Context) error {
+			ran = true
+			return nil
+		})
+		require.ErrorIs(t, err, context.Canceled)
+		require.False(t, ran)
+
+		_, err = agentRepo.GetManagedAgentById(cancelled, testTxOrgId, uuid.New())
+		require.ErrorIs(t, err, context.Canceled)
+	})
+}
@@ -0,0 +1,283 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/config"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/middleware/jwtassertion"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/models"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/repositories"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/services"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/tests/apitestutils"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/utils"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/wiring"
+)
+
+var (
+	testWebhookOrgId     = uuid.New()
+	testWebhookUserIdpId = uuid.New()
+	testWebhookOrgName   = fmt.Sprintf("test-org-%s", uuid.New().String()[:5])
+)
+
+// webhookReceiver records the events POSTed to it and answers with the configured status
+type webhookReceiver struct {
+	t      *testing.T
+	secret string
+	status atomic.Int32
+
+	mu     sync.Mutex
+	events []models.WebhookEvent
+}
+
+func newWebhookReceiver(t *testing.T, secret string) (*webhookReceiver, *httptest.Server) {
+	receiver := &webhookReceiver{t: t, secret: secret}
+	receiver.status.Store(http.StatusOK)
+	server := httptest.NewServer(receiver)
+	t.Cleanup(server.Close)
+	return receiver, server
+}
+
+func (rcv *webhookReceiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	require.NoError(rcv.t, err)
+	require.Equal(rcv.t, utils.SignWebhookPayload(rcv.secret, body), r.Header.Get(utils.WebhookSignatureHeader))
+	require.NotEmpty(rcv.t, r.Header.Get(utils.WebhookDeliveryHeader))
+	var event models.WebhookEvent
+	require.NoError(rcv.t, json.Unmarshal(body, &event))
+	require.Equal(rcv.t, event.Type, r.Header.Get(utils.WebhookEventHeader))
+
+	rcv.mu.Lock()
+	rcv.events = append(rcv.events, event)
+	rcv.mu.Unlock()
+	w.WriteHeader(int(rcv.status.Load()))
+}
+
+func (rcv *webhookReceiver) received() []models.WebhookEvent {
+	rcv.mu.Lock()
+	defer rcv.mu.Unlock()
+	return append([]models.WebhookEvent{}, rcv.events...)
+}
+
+// allowPrivateWebhookTargets lets webhooks target the receivers of the tests, which listen on loopback
+func allowPrivateWebhookTargets(t *testing.T) {
+	previous := config.GetConfig().Webhook.AllowPrivateNetworks
+	t.Cleanup(func() { config.GetConfig().Webhook.AllowPrivateNetworks = previous })
+	config.GetConfig().Webhook.AllowPrivateNetworks = true
+}
+
+func listWebhookDeliveries(t *testing.T, app http.Handler, webhookURL string) []models.WebhookDeliveryResponse {
+	rr := sendManagedAgentRequest(t, app, http.MethodGet, webhookURL+"/deliveries", nil, nil)
+	require.Equal(t, http.StatusOK, rr.Code)
+	var list models.WebhookDeliveryListResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &list))
+	return list.Deliveries
+}
+
+func TestWebhooks(t *testing.T) {
+	_ = apitestutils.CreateOrganization(t, testWebhookOrgId, testWebhookUserIdpId, testWebhookOrgName)
+	authMiddleware := jwtassertion.NewMockMiddleware(t, testWebhookOrgId, testWebhookUserIdpId)
+	orgURL := fmt.Sprintf("/api/v1/orgs/%s", testWebhookOrgName)
+	webhooksURL := orgURL + "/webhooks"
+	secret := "whsec-0123456789abcdef"
+	receiver, server := newWebhookReceiver(t, secret)
+
+	t.Run("Creating a webhook without encryption keys should return 503", func(t *testing.T) {
+		useCredentialKeys(t, nil, "")
+		app := apitestutils.MakeAppClientWithDeps(t, wiring.TestClients{}, authMiddleware)
+		rr := sendManagedAgentRequest(t, app, http.MethodPost, webhooksURL, map[string]interface{}{
+			"url": server.URL, "secret": secret, "events": []string{utils.WebhookEventAgentCreated},
+		}, nil)
+		require.Equal(t, http.StatusServiceUnavailable, rr.Code)
+	})
+
+	useCredentialKeys(t, map[string]string{"key-a": testCredentialKeyA}, "key-a")
+	strictApp := apitestutils.MakeAppClientWithDeps(t, wiring.TestClients{}, authMiddleware)
+
+	t.Run("Creating a webhook on a non-public address should return 400", func(t *testing.T) {
+		for _, target := range []string{server.URL, "http://169.254.169.254/latest/meta-data/", "http://10.0.0.7/hook", "http://localhost:8080/hook"} {
+			rr := sendManagedAgentRequest(t, strictApp, http.MethodPost, webhooksURL, map[string]interface{}{
+				"url": target, "secret": secret, "events": []string{utils.WebhookEventAgentCreated},
+			}, nil)
+			require.Equal(t, http.StatusBadRequest, rr.Code, target)
+			require.Equal(t, []string{"url"}, problemFields(decodeProblem(t, rr)))
+		}
+	})
+
+	allowPrivateWebhookTargets(t)
+	app := apitestutils.MakeAppClientWithDeps(t, wiring.TestClients{}, authMiddleware)
+	cipher, err := utils.NewCredentialCipher(map[string]string{"key-a": testCredentialKeyA}, "key-a")
+	require.NoError(t, err)
+	// Retries are due right away, so the test can run them without waiting
+	dispatcher := services.NewWebhookService(repositories.NewOrganizationRepository(), repositories.NewWebhookRepository(), cipher,
+		server.Client(), slog.Default(), services.WebhookDispatchConfig{Timeout: 5 * time.Second, MaxAttempts: 2, BatchSize: 100, DeliveryRetention: time.Hour})
+
+	t.Run("Editors should not manage webhooks", func(t *testing.T) {
+		editorApp := apitestutils.MakeAppClientWithDeps(t, wiring.TestClients{},
+			jwtassertion.NewMockMiddlewareWithRoles(t, testWebhookOrgId, testWebhookUserIdpId, utils.RoleEditor))
+		rr := sendManagedAgentRequest(t, editorApp, http.MethodGet, webhooksURL, nil, nil)
+		require.Equal(t, http.StatusForbidden, rr.Code)
+	})
+
+	t.Run("Creating a webhook with an unknown event should return 400", func(t *testing.T) {
+		rr := sendManagedAgentRequest(t, app, http.MethodPost, webhooksURL, map[string]interface{}{
+			"url": server.URL, "secret": secret, "events": []string{"agent.renamed"},
+		}, nil)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Equal(t, []string{"events[0]"}, problemFields(decodeProblem(t, rr)))
+	})
+
+	rr := sendManagedAgentRequest(t, app, http.MethodPost, webhooksURL, map[string]interface{}{
+		"url": server.URL, "description": "Agent audit", "secret": secret,
+		"events": []string{utils.WebhookEventAgentCreated, utils.WebhookEventAgentUpdated},
+	}, nil)
+	require.Equal(t, http.StatusCreated, rr.Code)
+	require.NotContains(t, rr.Body.String(), secret)
+	var webhook models.WebhookResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &webhook))
+	require.True(t, webhook.Active)
+	webhookURL := webhooksURL + "/" + webhook.ID
+	require.Equal(t, webhookURL, rr.Header().Get("Location"))
+
+	createOther := func(events []string, active bool) string {
+		rr := sendManagedAgentRequest(t, app, http.MethodPost, webhooksURL, map[string]interface{}{
+			"url": server.URL, "secret": secret, "events": events, "active": active,
+		}, nil)
+		require.Equal(t, http.StatusCreated, rr.Code)
+		var other models.WebhookResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &other))
+		return webhooksURL + "/" + other.ID
+	}
+	t.Run("Moving a webhook to a non-public address should return 400", func(t *testing.T) {
+		rr := sendManagedAgentRequest(t, strictApp, http.MethodPatch, webhookURL, map[string]interface{}{"url": "http://[::1]:9000/hook"}, nil)
+		require.Equal(t, http.StatusBadRequest, rr.Code)
+		require.Equal(t, []string{"url"}, problemFields(decodeProblem(t, rr)))
+	})
+
+	inactiveURL := createOther([]string{utils.WebhookEventAgentCreated}, false)
+	deployedOnlyURL := createOther([]string{utils.WebhookEventAgentDeployed}, true)
+
+	rr = sendManagedAgentRequest(t, app, http.MethodPost, orgURL+"/agents", managedAgentPayload("webhook-agent", nil), nil)
+	require.Equal(t, http.StatusCreated, rr.Code)
+	var agent models.ManagedAgentResponse
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &agent))
+
+	t.Run("Creating an agent should queue a delivery for subscribed webhooks only", func(t *testing.T) {
+		deliveries := listWebhookDeliveries(t, app, webhookURL)
+		require.Len(t, deliveries, 1)
+		require.Equal(t, utils.WebhookEventAgentCreated, deliveries[0].EventType)
+		require.Equal(t, utils.WebhookDeliveryStatusPending, deliveries[0].Status)
+		require.Empty(t, listWebhookDeliveries(t, app, inactiveURL))
+		require.Empty(t, listWebhookDeliveries(t, app, deployedOnlyURL))
+	})
+
+	t.Run("Dispatching should POST the signed event", func(t *testing.T) {
+		require.NoError(t, dispatcher.DeliverDue(context.Background()))
+		events := receiver.received()
+		require.Len(t, events, 1)
+		require.Equal(t, utils.WebhookEventAgentCreated, events[0].Type)
+		require.Equal(t, testWebhookOrgName, events[0].OrgName)
+		require.Equal(t, agent.ID, events[0].Data.(map[string]interface{})["id"])
+
+		deliveries := listWebhookDeliveries(t, app, webhookURL)
+		require.Equal(t, utils.WebhookDeliveryStatusSucceeded, deliveries[0].Status)
+		require.Len(t, deliveries[0].Attempts, 1)
+		require.Equal(t, http.StatusOK, deliveries[0].Attempts[0].StatusCode)
+	})
+
+	t.Run("Failed attempts should be retried up to the max", func(t *testing.T) {
+		receiver.status.Store(http.StatusInternalServerError)
+		t.Cleanup(func() { receiver.status.Store(http.StatusOK) })
+		payload := managedAgentPayload("webhook-agent", nil)
+		payload["description"] = "Answers billing questions"
+		rr := sendManagedAgentRequest(t, app, http.MethodPut, orgURL+"/agents/"+agent.ID, payload, map[string]string{"If-Match": `"1"`})
+		require.Equal(t, http.StatusOK, rr.Code)
+
+		require.NoError(t, dispatcher.DeliverDue(context.Background()))
+		deliveries := listWebhookDeliveries(t, app, webhookURL)
+		require.Equal(t, utils.WebhookEventAgentUpdated, deliveries[0].EventType)
+		require.Equal(t, utils.WebhookDeliveryStatusPending, deliveries[0].Status)
+		require.NotNil(t, deliveries[0].NextAttemptAt)
+
+		require.NoError(t, dispatcher.DeliverDue(context.Background()))
+		deliveries = listWebhookDeliveries(t, app, webhookURL)
+		require.Equal(t, utils.WebhookDeliveryStatusFailed, deliveries[0].Status)
+		require.Len(t, deliveries[0].Attempts, 2)
+		require.Equal(t, http.StatusInternalServerError, deliveries[0].Attempts[1].StatusCode)
+	})
+
+	t.Run("Redelivering a failed delivery should queue it again", func(t *testing.T) {
+		failed := listWebhookDeliveries(t, app, webhookURL)[0]
+		rr := sendManagedAgentRequest(t, app, http.MethodPost, webhookURL+"/deliveries/"+failed.ID+"/redeliver", nil, nil)
+		require.Equal(t, http.StatusAccepted, rr.Code)
+		var redelivery models.WebhookDeliveryResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &redelivery))
+		require.Equal(t, utils.WebhookDeliveryStatusPending, redelivery.Status)
+		require.Equal(t, failed.EventID, redelivery.EventID)
+		require.Equal(t, failed.ID, *redelivery.RedeliveryOf)
+
+		require.NoError(t, dispatcher.DeliverDue(context.Background()))
+		events := receiver.received()
+		require.Equal(t, failed.EventID, events[len(events)-1].ID)
+	})
+
+	t.Run("Redelivering a delivery that has not failed should conflict", func(t *testing.T) {
+		succeeded := listWebhookDeliveries(t, app, webhookURL)[0]
+		require.Equal(t, utils.WebhookDeliveryStatusSucceeded, succeeded.Status)
+		rr := sendManagedAgentRequest(t, app, http.MethodPost, webhookURL+"/deliveries/"+succeeded.ID+"/redeliver", nil, nil)
+		require.Equal(t, http.StatusConflict, rr.Code)
+	})
+
+	t.Run("Testing a webhook should send a ping once", func(t *testing.T) {
+		rr := sendManagedAgentRequest(t, app, http.MethodPost, inactiveURL+"/test", nil, nil)
+		require.Equal(t, http.StatusOK, rr.Code)
+		var delivery models.WebhookDeliveryResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &delivery))
+		require.Equal(t, utils.WebhookEventPing, delivery.EventType)
+		require.Equal(t, utils.WebhookDeliveryStatusSucceeded, delivery.Status)
+
+		receiver.status.Store(http.StatusServiceUnavailable)
+		t.Cleanup(func() { receiver.status.Store(http.StatusOK) })
+		rr = sendManagedAgentRequest(t, app, http.MethodPost, inactiveURL+"/test", nil, nil)
+		require.Equal(t, http.StatusOK, rr.Code)
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &delivery))
+		require.Equal(t, utils.WebhookDeliveryStatusFailed, delivery.Status)
+		require.Len(t, delivery.Attempts, 1)
+		require.Equal(t, http.StatusServiceUnavailable, delivery.Attempts[0].StatusCode)
+	})
+
+	t.Run("Deleting a webhook should return 204", func(t *testing.T) {
+		rr := sendManagedAgentRequest(t, app, http.MethodDelete, webhookURL, nil, nil)
+		require.Equal(t, http.StatusNoContent, rr.Code)
+		rr = sendManagedAgentRequest(t, app, http.MethodGet, webhookURL, nil, nil)
+		require.Equal(t, http.StatusNotFound, rr.Code)
+	})
+}
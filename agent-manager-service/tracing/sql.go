@@ -0,0 +1,43 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package tracing
+
+import (
+	"regexp"
+	"strings"
+)
+
+// maxStatementBytes bounds the statement recorded on a span
+const maxStatementBytes = 4096
+
+// sqlLiteral matches string and number literals, and placeholders which are kept
+var sqlLiteral = regexp.MustCompile(`'(?:[^']|'')*'|\$\d+|\b\d+(?:\.\d+)?\b`)
+
+// SanitizeSQL replaces the literals of a statement by "?", so that spans carry the shape of
+// the statement but none of the values it writes or matches. Placeholders are kept as is
+func SanitizeSQL(statement string) string {
+	sanitized := sqlLiteral.ReplaceAllStringFunc(statement, func(literal string) string {
+		if strings.HasPrefix(literal, "$") {
+			return literal
+		}
+		return "?"
+	})
+	if len(sanitized) > maxStatementBytes {
+		sanitized = sanitized[:maxStatementBytes]
+	}
+	return sanitized
+}
@@ -0,0 +1,34 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package tracing
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSanitizeSQL(t *testing.T) {
+	tests := map[string]string{
+		`SELECT * FROM "agents" WHERE org_id = $1 AND name = $2 LIMIT 20`:    `SELECT * FROM "agents" WHERE org_id = $1 AND name = $2 LIMIT ?`,
+		`UPDATE agents SET name = 'it''s secret', version = 3 WHERE id = $1`: `UPDATE agents SET name = ?, version = ? WHERE id = $1`,
+		`SELECT t1.id FROM budgets t1 WHERE t1.limit > 12.5`:                 `SELECT t1.id FROM budgets t1 WHERE t1.limit > ?`,
+	}
+	for statement, want := range tests {
+		require.Equal(t, want, SanitizeSQL(statement))
+	}
+}
@@ -0,0 +1,136 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package utils
+
+import (
+	"fmt"
+	"sort"
+	"time"
+	// Budget time zones are resolved without relying on the zoneinfo of the host
+	_ "time/tzdata"
+
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/models"
+)
+
+// Periods a budget limits usage over
+const (
+	BudgetPeriodDay   = "day"
+	BudgetPeriodMonth = "month"
+)
+
+// Usage metrics a budget limits
+const (
+	BudgetMetricCost   = "cost"
+	BudgetMetricTokens = "tokens"
+)
+
+// BudgetEnforcementNone raises alerts without blocking the agent, the only enforcement supported for now
+const BudgetEnforcementNone = "none"
+
+// DefaultBudgetTimezone starts the periods of budgets created without a time zone
+const DefaultBudgetTimezone = "UTC"
+
+// DefaultBudgetAlertThresholds are the percentages of a limit alerted on when a budget names none
+var DefaultBudgetAlertThresholds = []int32{80, 100}
+
+// Bounds of the alert thresholds of a budget
+const (
+	MaxBudgetAlertThresholds = 10
+	MaxBudgetAlertThreshold  = 1000
+)
+
+// ValidateAgentBudgetRequest validates a budget and reports every rejected field
+func ValidateAgentBudgetRequest(payload models.AgentBudgetRequest) error {
+	errs := &ValidationError{}
+	if payload.Timezone != "" {
+		// Local would follow the time zone of whichever host checks the budget
+		if _, err := time.LoadLocation(payload.Timezone); err != nil || payload.Timezone == "Local" {
+			errs.Add("timezone", "unknown time zone %q, use an IANA name such as Europe/Berlin", payload.Timezone)
+		}
+	}
+	if !hasBudgetLimit(payload.Daily) && !hasBudgetLimit(payload.Monthly) {
+		errs.Add("daily", "a daily or monthly limit is required")
+	}
+	validateBudgetLimits(errs, "daily", payload.Daily)
+	validateBudgetLimits(errs, "monthly", payload.Monthly)
+	if len(payload.AlertThresholds) > MaxBudgetAlertThresholds {
+		errs.Add("alertThresholds", "must have at most %d thresholds", MaxBudgetAlertThresholds)
+	}
+	seen := make(map[int32]bool, len(payload.AlertThresholds))
+	for i, threshold := range payload.AlertThresholds {
+		field := fmt.Sprintf("alertThresholds[%d]", i)
+		if threshold < 1 || threshold > MaxBudgetAlertThreshold {
+			errs.Add(field, "must be a percentage from 1 to %d", MaxBudgetAlertThreshold)
+		} else if seen[threshold] {
+			errs.Add(field, "duplicate threshold %d", threshold)
+		}
+		seen[threshold] = true
+	}
+	if payload.Enforcement != "" && payload.Enforcement != BudgetEnforcementNone {
+		errs.Add("enforcement", "must be %q, budgets are not enforced yet", BudgetEnforcementNone)
+	}
+	return errs.OrNil()
+}
+
+func hasBudgetLimit(limits *models.BudgetLimits) bool {
+	return limits != nil && (limits.MaxCost != nil || limits.MaxTokens != nil)
+}
+
+func validateBudgetLimits(errs *ValidationError, field string, limits *models.BudgetLimits) {
+	if limits == nil {
+		return
+	}
+	if limits.MaxCost != nil && *limits.MaxCost <= 0 {
+		errs.Add(field+".maxCost", "must be greater than 0")
+	}
+	if limits.MaxTokens != nil && *limits.MaxTokens <= 0 {
+		errs.Add(field+".maxTokens", "must be greater than 0")
+	}
+}
+
+// NormalizeBudgetAlertThresholds returns the thresholds in ascending order, the defaults when there are none
+func NormalizeBudgetAlertThresholds(thresholds []int32) []int32 {
+	if len(thresholds) == 0 {
+		return append([]int32{}, DefaultBudgetAlertThresholds...)
+	}
+	sorted := append([]int32{}, thresholds...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return sorted
+}
+
+// BudgetPeriodBounds returns the start and end of the day or month containing now, both at midnight in loc.
+// Days are not assumed to last 24 hours, so the days daylight saving time starts or ends on are 23 or 25 hours long
+func BudgetPeriodBounds(period string, now time.Time, loc *time.Location) (time.Time, time.Time) {
+	local := now.In(loc)
+	year, month, day := local.Date()
+	if period == BudgetPeriodMonth {
+		return time.Date(year, month, 1, 0, 0, 0, 0, loc), time.Date(year, month+1, 1, 0, 0, 0, 0, loc)
+	}
+	return time.Date(year, month, day, 0, 0, 0, 0, loc), time.Date(year, month, day+1, 0, 0, 0, 0, loc)
+}
+
+// BudgetThresholdsReached returns the thresholds of the ascending list that usage of the limit reaches
+func BudgetThresholdsReached(thresholds []int32, used float64, limit float64) []int32 {
+	percent := used / limit * 100
+	var reached []int32
+	for _, threshold := range thresholds {
+		if percent >= float64(threshold) {
+			reached = append(reached, threshold)
+		}
+	}
+	return reached
+}
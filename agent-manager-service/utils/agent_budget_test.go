@@ -0,0 +1,153 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package utils
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/models"
+)
+
+func TestBudgetPeriodBounds(t *testing.T) {
+	newYork, _ := time.LoadLocation("America/New_York")
+	kolkata, _ := time.LoadLocation("Asia/Kolkata")
+	cases := []struct {
+		name      string
+		period    string
+		now       time.Time
+		loc       *time.Location
+		wantStart string
+		wantEnd   string
+		wantHours float64
+	}{
+		{
+			name: "day in UTC", period: BudgetPeriodDay, now: time.Date(2026, 3, 10, 15, 4, 5, 0, time.UTC), loc: time.UTC,
+			wantStart: "2026-03-10T00:00:00Z", wantEnd: "2026-03-11T00:00:00Z", wantHours: 24,
+		},
+		{
+			// Still the evening before in New York
+			name: "day behind UTC", period: BudgetPeriodDay, now: time.Date(2026, 3, 10, 2, 0, 0, 0, time.UTC), loc: newYork,
+			wantStart: "2026-03-09T00:00:00-04:00", wantEnd: "2026-03-10T00:00:00-04:00", wantHours: 24,
+		},
+		{
+			name: "day with a half hour offset", period: BudgetPeriodDay, now: time.Date(2026, 3, 10, 20, 0, 0, 0, time.UTC), loc: kolkata,
+			wantStart: "2026-03-11T00:00:00+05:30", wantEnd: "2026-03-12T00:00:00+05:30", wantHours: 24,
+		},
+		{
+			name: "day daylight saving time starts", period: BudgetPeriodDay, now: time.Date(2026, 3, 8, 12, 0, 0, 0, time.UTC), loc: newYork,
+			wantStart: "2026-03-08T00:00:00-05:00", wantEnd: "2026-03-09T00:00:00-04:00", wantHours: 23,
+		},
+		{
+			name: "day daylight saving time ends", period: BudgetPeriodDay, now: time.Date(2026, 11, 1, 12, 0, 0, 0, time.UTC), loc: newYork,
+			wantStart: "2026-11-01T00:00:00-04:00", wantEnd: "2026-11-02T00:00:00-05:00", wantHours: 25,
+		},
+		{
+			name: "month", period: BudgetPeriodMonth, now: time.Date(2026, 2, 14, 9, 0, 0, 0, time.UTC), loc: time.UTC,
+			wantStart: "2026-02-01T00:00:00Z", wantEnd: "2026-03-01T00:00:00Z", wantHours: 28 * 24,
+		},
+		{
+			// Already January in Kolkata
+			name: "month across the year in another time zone", period: BudgetPeriodMonth, now: time.Date(2025, 12, 31, 20, 0, 0, 0, time.UTC), loc: kolkata,
+			wantStart: "2026-01-01T00:00:00+05:30", wantEnd: "2026-02-01T00:00:00+05:30", wantHours: 31 * 24,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			start, end := BudgetPeriodBounds(c.period, c.now, c.loc)
+			if got := start.Format(time.RFC3339); got != c.wantStart {
+				t.Errorf("start = %s, want %s", got, c.wantStart)
+			}
+			if got := end.Format(time.RFC3339); got != c.wantEnd {
+				t.Errorf("end = %s, want %s", got, c.wantEnd)
+			}
+			if got := end.Sub(start).Hours(); got != c.wantHours {
+				t.Errorf("period lasts %v hours, want %v", got, c.wantHours)
+			}
+			if c.now.Before(start) || !c.now.Before(end) {
+				t.Errorf("%s is not within [%s, %s)", c.now, start, end)
+			}
+		})
+	}
+}
+
+func TestBudgetThresholdsReached(t *testing.T) {
+	thresholds := []int32{50, 80, 100}
+	cases := []struct {
+		used float64
+		want []int32
+	}{
+		{used: 4.99, want: nil},
+		{used: 5, want: []int32{50}},
+		{used: 9.99, want: []int32{50, 80}},
+		{used: 12, want: []int32{50, 80, 100}},
+	}
+	for _, c := range cases {
+		if got := BudgetThresholdsReached(thresholds, c.used, 10); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("BudgetThresholdsReached(%v of 10) = %v, want %v", c.used, got, c.want)
+		}
+	}
+}
+
+func TestNormalizeBudgetAlertThresholds(t *testing.T) {
+	if got := NormalizeBudgetAlertThresholds(nil); !reflect.DeepEqual(got, []int32{80, 100}) {
+		t.Errorf("default thresholds = %v", got)
+	}
+	if got := NormalizeBudgetAlertThresholds([]int32{100, 50, 90}); !reflect.DeepEqual(got, []int32{50, 90, 100}) {
+		t.Errorf("sorted thresholds = %v", got)
+	}
+}
+
+func TestValidateAgentBudgetRequest(t *testing.T) {
+	cost, tokens := 25.0, int64(1000000)
+	negative := -1.0
+	valid := models.AgentBudgetRequest{
+		Timezone:        "Europe/Berlin",
+		Daily:           &models.BudgetLimits{MaxCost: &cost},
+		Monthly:         &models.BudgetLimits{MaxTokens: &tokens},
+		AlertThresholds: []int32{50, 100},
+	}
+	if err := ValidateAgentBudgetRequest(valid); err != nil {
+		t.Fatalf("valid budget rejected: %v", err)
+	}
+
+	cases := []struct {
+		name   string
+		modify func(req *models.AgentBudgetRequest)
+		field  string
+	}{
+		{"unknown time zone", func(req *models.AgentBudgetRequest) { req.Timezone = "Mars/Olympus_Mons" }, "timezone"},
+		{"host time zone", func(req *models.AgentBudgetRequest) { req.Timezone = "Local" }, "timezone"},
+		{"no limit", func(req *models.AgentBudgetRequest) { req.Daily, req.Monthly = nil, &models.BudgetLimits{} }, "daily"},
+		{"negative cost", func(req *models.AgentBudgetRequest) { req.Daily = &models.BudgetLimits{MaxCost: &negative} }, "daily.maxCost"},
+		{"threshold out of range", func(req *models.AgentBudgetRequest) { req.AlertThresholds = []int32{0} }, "alertThresholds[0]"},
+		{"duplicate threshold", func(req *models.AgentBudgetRequest) { req.AlertThresholds = []int32{80, 80} }, "alertThresholds[1]"},
+		{"enforcement", func(req *models.AgentBudgetRequest) { req.Enforcement = "block" }, "enforcement"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			req := valid
+			c.modify(&req)
+			err := ValidateAgentBudgetRequest(req)
+			if err == nil || !strings.Contains(err.Error(), c.field+":") {
+				t.Errorf("error = %v, want one on %s", err, c.field)
+			}
+		})
+	}
+}
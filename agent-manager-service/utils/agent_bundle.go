@@ -0,0 +1,171 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/models"
+)
+
+const (
+	ImportActionCreate    = "create"
+	ImportActionUpdate    = "update"
+	ImportActionUnchanged = "unchanged"
+)
+
+// ImportConflictError reports the parts of a bundle that conflict with resources of the organization
+type ImportConflictError struct {
+	Conflicts []models.AgentImportConflict
+}
+
+func (e *ImportConflictError) Error() string {
+	messages := make([]string, 0, len(e.Conflicts))
+	for _, conflict := range e.Conflicts {
+		messages = append(messages, fmt.Sprintf("%s: %s", conflict.Field, conflict.Message))
+	}
+	return "import conflicts: " + strings.Join(messages, "; ")
+}
+
+// DecodeAgentBundle reads a bundle of any supported schema version into the current format
+func DecodeAgentBundle(body []byte) (*models.AgentBundle, error) {
+	var header struct {
+		SchemaVersion int `json:"schemaVersion"`
+	}
+	if err := json.Unmarshal(body, &header); err != nil {
+		return nil, err
+	}
+	errs := &ValidationError{}
+	switch {
+	case header.SchemaVersion < 1:
+		errs.Add("schemaVersion", "must be a positive bundle schema version")
+		return nil, errs
+	case header.SchemaVersion > models.AgentBundleSchemaVersion:
+		errs.Add("schemaVersion", "bundles of version %d are newer than the supported version %d",
+			header.SchemaVersion, models.AgentBundleSchemaVersion)
+		return nil, errs
+	}
+	// Bundles of earlier versions are converted to the current format here once it changes
+	var bundle models.AgentBundle
+	if err := json.Unmarshal(body, &bundle); err != nil {
+		return nil, err
+	}
+	bundle.SchemaVersion = models.AgentBundleSchemaVersion
+	return &bundle, nil
+}
+
+// ValidateAgentBundle validates the agent, prompt and tools of a bundle and the references between them
+func ValidateAgentBundle(bundle *models.AgentBundle) error {
+	errs := &ValidationError{}
+
+	agent := bundle.Agent
+	request := models.ManagedAgentRequest{
+		Name:                agent.Name,
+		Description:         agent.Description,
+		Framework:           agent.Framework,
+		ModelConfig:         agent.ModelConfig,
+		SystemPrompt:        agent.SystemPrompt,
+		Labels:              agent.Labels,
+		ConfigSchemaVersion: agent.ConfigSchemaVersion,
+		AllowUnvalidated:    agent.AllowUnvalidated,
+	}
+	if err := ValidateManagedAgentRequest(request); err != nil {
+		addNested(errs, "agent.", err)
+	} else {
+		_, err := ValidateAgentModelConfig(request, nil)
+		addNested(errs, "agent.", err)
+	}
+
+	switch {
+	case bundle.Prompt != nil:
+		addNested(errs, "prompt.", ValidatePromptTemplateRequest(bundle.Prompt.PromptTemplateRequest))
+		if agent.Prompt != bundle.Prompt.Name {
+			errs.Add("agent.prompt", "must name the prompt of the bundle")
+		}
+		if agent.SystemPrompt != "" {
+			errs.Add("agent.prompt", "systemPrompt and prompt cannot both be set")
+		}
+	case agent.Prompt != "":
+		errs.Add("agent.prompt", "the bundle has no prompt %q", agent.Prompt)
+	}
+
+	bundled := make(map[string]bool, len(bundle.Tools))
+	for i, tool := range bundle.Tools {
+		addNested(errs, fmt.Sprintf("tools[%d].", i), ValidateToolRequest(tool))
+		if bundled[tool.Name] {
+			errs.Add(fmt.Sprintf("tools[%d].name", i), "duplicate tool %q", tool.Name)
+		}
+		bundled[tool.Name] = true
+	}
+	referenced := validateBundleToolReferences(errs, agent.Tools, bundled)
+	for i, tool := range bundle.Tools {
+		if !referenced[tool.Name] {
+			errs.Add(fmt.Sprintf("tools[%d]", i), "tool %q is not used by the agent", tool.Name)
+		}
+	}
+
+	return errs.OrNil()
+}
+
+// validateBundleToolReferences checks the tools of a bundled agent and returns the names of the bundled tools it uses
+func validateBundleToolReferences(errs *ValidationError, references []models.AgentBundleToolReference, bundled map[string]bool) map[string]bool {
+	referenced := make(map[string]bool, len(references))
+	if len(references) > MaxAgentTools {
+		errs.Add("agent.tools", "must contain at most %d tools", MaxAgentTools)
+		return bundled
+	}
+	seenNames := make(map[string]bool, len(references))
+	for i, reference := range references {
+		field := fmt.Sprintf("agent.tools[%d]", i)
+		switch {
+		case reference.Tool != "" && reference.Name != "":
+			errs.Add(field, "set either tool for a tool of the bundle or name for an ad hoc tool, not both")
+		case reference.Tool != "":
+			if !bundled[reference.Tool] {
+				errs.Add(field+".tool", "the bundle has no tool %q", reference.Tool)
+			} else if referenced[reference.Tool] {
+				errs.Add(field+".tool", "duplicate tool %q", reference.Tool)
+			}
+			referenced[reference.Tool] = true
+		case strings.TrimSpace(reference.Name) == "":
+			errs.Add(field+".name", "tool name cannot be empty")
+		case seenNames[reference.Name]:
+			errs.Add(field+".name", "duplicate tool %q", reference.Name)
+		default:
+			seenNames[reference.Name] = true
+		}
+	}
+	return referenced
+}
+
+// addNested records the field errors of a nested part of a payload under prefix
+func addNested(errs *ValidationError, prefix string, err error) {
+	if err == nil {
+		return
+	}
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) {
+		errs.Add(strings.TrimSuffix(prefix, "."), "%s", err.Error())
+		return
+	}
+	for _, fieldErr := range validationErr.Errors {
+		errs.Add(prefix+fieldErr.Field, "%s", fieldErr.Message)
+	}
+}
@@ -0,0 +1,62 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package utils
+
+import (
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/models"
+)
+
+// ValidateAgentEnvironmentRequest validates an environment create payload and reports every rejected field
+func ValidateAgentEnvironmentRequest(payload models.AgentEnvironmentRequest) error {
+	errs := &ValidationError{}
+	if err := ValidateResourceName(payload.Name, "environment"); err != nil {
+		errs.Add("name", "%s", err.Error())
+	}
+	if len(payload.Description) > MaxAgentDescriptionLength {
+		errs.Add("description", "must be at most %d characters", MaxAgentDescriptionLength)
+	}
+	return errs.OrNil()
+}
+
+// ValidateAgentDeploymentRequest validates a deployment payload and reports every rejected field
+func ValidateAgentDeploymentRequest(payload models.AgentDeploymentRequest) error {
+	errs := &ValidationError{}
+	if payload.Environment == "" {
+		errs.Add("environment", "is required")
+	}
+	if payload.Version < 1 {
+		errs.Add("version", "must be a positive version number")
+	}
+	return errs.OrNil()
+}
+
+// ValidateAgentPromotionRequest validates a promotion payload and reports every rejected field
+func ValidateAgentPromotionRequest(payload models.AgentPromotionRequest) error {
+	errs := &ValidationError{}
+	if payload.From == "" {
+		errs.Add("from", "is required")
+	}
+	if payload.To == "" {
+		errs.Add("to", "is required")
+	} else if payload.To == payload.From {
+		errs.Add("to", "must differ from the environment the agent is promoted from")
+	}
+	if payload.Version != nil && *payload.Version < 1 {
+		errs.Add("version", "must be a positive version number")
+	}
+	return errs.OrNil()
+}
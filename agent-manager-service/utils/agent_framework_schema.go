@@ -0,0 +1,195 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package utils
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"github.com/santhosh-tekuri/jsonschema/v6/kind"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/models"
+)
+
+// Schemas of the model configuration of each framework, named <framework>.v<version>.json.
+// Published versions must not be changed, a changed schema is added as the next version
+//
+//go:embed framework_schemas/*.json
+var frameworkSchemaFiles embed.FS
+
+var frameworkSchemaFilePattern = regexp.MustCompile(`^([a-z0-9-]+)\.v([1-9][0-9]*)\.json$`)
+
+// AgentFrameworkSchema is a version of the JSON Schema the model configuration of a framework's agents is validated against
+type AgentFrameworkSchema struct {
+	Framework AgentFramework
+	Version   int32
+	Schema    map[string]interface{}
+	compiled  *jsonschema.Schema
+}
+
+// Versions of the schema of each framework, oldest first
+var agentFrameworkSchemas = loadAgentFrameworkSchemas()
+
+func loadAgentFrameworkSchemas() map[AgentFramework][]*AgentFrameworkSchema {
+	entries, err := frameworkSchemaFiles.ReadDir("framework_schemas")
+	if err != nil {
+		panic(fmt.Sprintf("failed to read framework schemas: %v", err))
+	}
+	registry := map[AgentFramework][]*AgentFrameworkSchema{}
+	for _, entry := range entries {
+		match := frameworkSchemaFilePattern.FindStringSubmatch(entry.Name())
+		if match == nil {
+			panic(fmt.Sprintf("framework schema %s is not named <framework>.v<version>.json", entry.Name()))
+		}
+		version, err := strconv.ParseInt(match[2], 10, 32)
+		if err != nil {
+			panic(fmt.Sprintf("framework schema %s: %v", entry.Name(), err))
+		}
+		schema, err := loadAgentFrameworkSchema(entry.Name(), AgentFramework(match[1]), int32(version))
+		if err != nil {
+			panic(fmt.Sprintf("framework schema %s: %v", entry.Name(), err))
+		}
+		registry[schema.Framework] = append(registry[schema.Framework], schema)
+	}
+	for framework, schemas := range registry {
+		sort.Slice(schemas, func(i, j int) bool { return schemas[i].Version < schemas[j].Version })
+		for i, schema := range schemas {
+			if schema.Version != int32(i+1) {
+				panic(fmt.Sprintf("framework %s has no schema version %d", framework, i+1))
+			}
+		}
+	}
+	return registry
+}
+
+func loadAgentFrameworkSchema(name string, framework AgentFramework, version int32) (*AgentFrameworkSchema, error) {
+	data, err := frameworkSchemaFiles.ReadFile(path.Join("framework_schemas", name))
+	if err != nil {
+		return nil, err
+	}
+	var schema map[string]interface{}
+	if err := json.Unmarshal(data, &schema); err != nil {
+		return nil, err
+	}
+	url := fmt.Sprintf("urn:agent-manager:frameworks:%s:v%d", framework, version)
+	compiler := jsonschema.NewCompiler()
+	compiler.DefaultDraft(jsonschema.Draft2020)
+	compiler.UseLoader(jsonschema.SchemeURLLoader{})
+	if err := compiler.AddResource(url, schema); err != nil {
+		return nil, err
+	}
+	compiled, err := compiler.Compile(url)
+	if err != nil {
+		return nil, err
+	}
+	return &AgentFrameworkSchema{Framework: framework, Version: version, Schema: schema, compiled: compiled}, nil
+}
+
+// AgentFrameworkSchemas returns every version of the schema of a framework, oldest first, nil for frameworks without one
+func AgentFrameworkSchemas(framework string) []*AgentFrameworkSchema {
+	return agentFrameworkSchemas[AgentFramework(framework)]
+}
+
+// LatestAgentFrameworkSchema returns the schema new agents of a framework are validated against
+func LatestAgentFrameworkSchema(framework string) (*AgentFrameworkSchema, bool) {
+	schemas := AgentFrameworkSchemas(framework)
+	if len(schemas) == 0 {
+		return nil, false
+	}
+	return schemas[len(schemas)-1], true
+}
+
+// GetAgentFrameworkSchema returns a version of the schema of a framework
+func GetAgentFrameworkSchema(framework string, version int32) (*AgentFrameworkSchema, bool) {
+	schemas := AgentFrameworkSchemas(framework)
+	if version < 1 || int(version) > len(schemas) {
+		return nil, false
+	}
+	return schemas[version-1], true
+}
+
+// ValidateAgentModelConfig validates the model configuration of an agent against the schema of its framework and returns
+// the schema version it passed, nil for agents stored without validation.
+// The version selected by the request wins over pinned, the version the agent passed before, and new agents,
+// as well as agents changing framework, are validated against the latest version
+func ValidateAgentModelConfig(payload models.ManagedAgentRequest, pinned *int32) (*int32, error) {
+	if payload.AllowUnvalidated {
+		return nil, nil
+	}
+	errs := &ValidationError{}
+	version := payload.ConfigSchemaVersion
+	if version == nil {
+		version = pinned
+	}
+	var schema *AgentFrameworkSchema
+	var ok bool
+	if version == nil {
+		schema, ok = LatestAgentFrameworkSchema(payload.Framework)
+	} else {
+		schema, ok = GetAgentFrameworkSchema(payload.Framework, *version)
+	}
+	switch {
+	case !ok && version == nil:
+		errs.Reject(Field("framework"), ConstraintEnum, "framework %q has no schema, set allowUnvalidated to store the agent without validation", payload.Framework)
+		return nil, errs
+	case !ok:
+		errs.Reject(Field("configSchemaVersion"), ConstraintEnum, "framework %q has no schema version %d", payload.Framework, *version)
+		return nil, errs
+	}
+	schema.validate(errs, Field("modelConfig"), payload.ModelConfig)
+	if err := errs.OrNil(); err != nil {
+		return nil, err
+	}
+	return &schema.Version, nil
+}
+
+func (s *AgentFrameworkSchema) validate(errs *ValidationError, path FieldPath, config map[string]interface{}) {
+	err := s.compiled.Validate(map[string]interface{}(config))
+	var validationErr *jsonschema.ValidationError
+	if err == nil || !errors.As(err, &validationErr) {
+		if err != nil {
+			errs.Reject(path, "", "%s", err.Error())
+		}
+		return
+	}
+	printer := message.NewPrinter(language.English)
+	for _, leaf := range schemaLeafErrors(validationErr) {
+		location := schemaInstancePath(path, config, leaf.InstanceLocation)
+		// Unknown and missing settings are reported on the setting, so typos point at themselves
+		switch errorKind := leaf.ErrorKind.(type) {
+		case *kind.AdditionalProperties:
+			for _, property := range errorKind.Properties {
+				errs.Reject(location.Key(property), ConstraintAdditionalProperties, "unknown setting of framework %s schema version %d", s.Framework, s.Version)
+			}
+		case *kind.Required:
+			for _, property := range errorKind.Missing {
+				errs.Reject(location.Key(property), ConstraintRequired, "is required")
+			}
+		default:
+			errs.Reject(location, schemaKeyword(leaf.ErrorKind), "%s", leaf.ErrorKind.LocalizedString(printer))
+		}
+	}
+}
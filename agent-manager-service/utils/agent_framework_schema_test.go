@@ -0,0 +1,128 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package utils
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/models"
+)
+
+func TestSupportedAgentFrameworksHaveSchemas(t *testing.T) {
+	for _, framework := range SupportedAgentFrameworks {
+		if _, ok := LatestAgentFrameworkSchema(string(framework)); !ok {
+			t.Errorf("framework %s has no schema", framework)
+		}
+	}
+}
+
+func TestValidateAgentModelConfig(t *testing.T) {
+	version := func(v int32) *int32 { return &v }
+	cases := []struct {
+		name        string
+		payload     models.ManagedAgentRequest
+		pinned      *int32
+		wantVersion *int32
+		wantFields  []string
+	}{
+		{
+			name: "valid configuration",
+			payload: models.ManagedAgentRequest{Framework: "crewai", ModelConfig: map[string]interface{}{
+				"provider": "openai", "model": "gpt-4o", "temperature": 0.2, "maxTokens": float64(1024), "allowDelegation": true,
+			}},
+			wantVersion: version(1),
+		},
+		{
+			name: "misspelled setting",
+			payload: models.ManagedAgentRequest{Framework: "langchain", ModelConfig: map[string]interface{}{
+				"provider": "openai", "model": "gpt-4o", "temprature": 0.2,
+			}},
+			wantFields: []string{"modelConfig.temprature"},
+		},
+		{
+			name: "settings of the wrong type and missing model",
+			payload: models.ManagedAgentRequest{Framework: "openai-agents", ModelConfig: map[string]interface{}{
+				"provider": "openai", "maxTurns": "10", "parallelToolCalls": "yes",
+			}},
+			wantFields: []string{"modelConfig.maxTurns", "modelConfig.model", "modelConfig.parallelToolCalls"},
+		},
+		{
+			name: "custom frameworks may add settings",
+			payload: models.ManagedAgentRequest{Framework: "custom", ModelConfig: map[string]interface{}{
+				"provider": "vllm", "model": "llama-3", "endpoint": "http://vllm:8000",
+			}},
+			wantVersion: version(1),
+		},
+		{
+			name: "agents stay on their pinned version",
+			payload: models.ManagedAgentRequest{Framework: "langgraph", ModelConfig: map[string]interface{}{
+				"provider": "openai", "model": "gpt-4o", "recursionLimit": float64(50),
+			}},
+			pinned:      version(1),
+			wantVersion: version(1),
+		},
+		{
+			name: "unknown schema version",
+			payload: models.ManagedAgentRequest{Framework: "crewai", ConfigSchemaVersion: version(99), ModelConfig: map[string]interface{}{
+				"provider": "openai", "model": "gpt-4o",
+			}},
+			wantFields: []string{"configSchemaVersion"},
+		},
+		{
+			name: "unknown framework",
+			payload: models.ManagedAgentRequest{Framework: "autogen", ModelConfig: map[string]interface{}{
+				"provider": "openai", "model": "gpt-4o",
+			}},
+			wantFields: []string{"framework"},
+		},
+		{
+			name: "unvalidated agents are stored as they are",
+			payload: models.ManagedAgentRequest{Framework: "autogen", AllowUnvalidated: true, ModelConfig: map[string]interface{}{
+				"provider": "openai", "model": "gpt-4o", "temprature": 0.2,
+			}},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := ValidateAgentModelConfig(c.payload, c.pinned)
+			if len(c.wantFields) == 0 {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if !reflect.DeepEqual(got, c.wantVersion) {
+					t.Fatalf("schema version = %v, want %v", got, c.wantVersion)
+				}
+				return
+			}
+			var validationErr *ValidationError
+			if !errors.As(err, &validationErr) {
+				t.Fatalf("expected a validation error, got %v", err)
+			}
+			fields := make([]string, 0, len(validationErr.Errors))
+			for _, fieldErr := range validationErr.Errors {
+				fields = append(fields, fieldErr.Field)
+			}
+			sort.Strings(fields)
+			if !reflect.DeepEqual(fields, c.wantFields) {
+				t.Fatalf("rejected fields = %v, want %v (%v)", fields, c.wantFields, err)
+			}
+		})
+	}
+}
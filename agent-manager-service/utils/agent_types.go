@@ -43,3 +43,30 @@ type InputInterfaceType string
 const (
 	InputInterfaceTypeHTTP InputInterfaceType = "HTTP"
 )
+
+type AgentFramework string
+
+const (
+	AgentFrameworkCrewAI       AgentFramework = "crewai"
+	AgentFrameworkLangChain    AgentFramework = "langchain"
+	AgentFrameworkLangGraph    AgentFramework = "langgraph"
+	AgentFrameworkOpenAIAgents AgentFramework = "openai-agents"
+	AgentFrameworkCustom       AgentFramework = "custom"
+)
+
+// SupportedAgentFrameworks lists the frameworks a managed agent may declare
+var SupportedAgentFrameworks = []AgentFramework{
+	AgentFrameworkCrewAI,
+	AgentFrameworkLangChain,
+	AgentFrameworkLangGraph,
+	AgentFrameworkOpenAIAgents,
+	AgentFrameworkCustom,
+}
+
+type ToolEndpointType string
+
+const (
+	ToolEndpointTypeHTTP     ToolEndpointType = "http"
+	ToolEndpointTypeMCP      ToolEndpointType = "mcp"
+	ToolEndpointTypeFunction ToolEndpointType = "function"
+)
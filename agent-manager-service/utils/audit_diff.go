@@ -0,0 +1,101 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/models"
+)
+
+// RedactedValue replaces the values of secret fields in audit logs
+const RedactedValue = "[REDACTED]"
+
+// secretFieldName matches the names of fields whose values are never written to audit logs,
+// token only at the end so limits such as maxTokens stay visible
+var secretFieldName = regexp.MustCompile(`(?i)(secret|password|passwd|credential|hash|api_?key|private_?key|token$|^key$)`)
+
+// AuditDiff compares the JSON representations of a resource before and after an update and returns the top level
+// fields that differ. Values of secret fields, at any depth, are replaced by RedactedValue
+func AuditDiff(before any, after any) (map[string]models.AuditFieldChange, error) {
+	beforeFields, err := jsonFields(before)
+	if err != nil {
+		return nil, err
+	}
+	afterFields, err := jsonFields(after)
+	if err != nil {
+		return nil, err
+	}
+	changes := map[string]models.AuditFieldChange{}
+	for name, to := range afterFields {
+		if from, ok := beforeFields[name]; !ok || !reflect.DeepEqual(from, to) {
+			changes[name] = redactedChange(name, beforeFields[name], to)
+		}
+	}
+	for name, from := range beforeFields {
+		if _, ok := afterFields[name]; !ok {
+			changes[name] = redactedChange(name, from, nil)
+		}
+	}
+	return changes, nil
+}
+
+func jsonFields(resource any) (map[string]interface{}, error) {
+	body, err := json.Marshal(resource)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize resource for auditing: %w", err)
+	}
+	fields := map[string]interface{}{}
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return nil, fmt.Errorf("failed to read fields of resource for auditing: %w", err)
+	}
+	return fields, nil
+}
+
+func redactedChange(name string, from interface{}, to interface{}) models.AuditFieldChange {
+	if secretFieldName.MatchString(name) {
+		return models.AuditFieldChange{From: RedactedValue, To: RedactedValue}
+	}
+	return models.AuditFieldChange{From: redactSecrets(from), To: redactSecrets(to)}
+}
+
+// redactSecrets replaces the values of secret fields nested in a decoded JSON value
+func redactSecrets(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		redacted := make(map[string]interface{}, len(v))
+		for name, field := range v {
+			if secretFieldName.MatchString(name) {
+				redacted[name] = RedactedValue
+			} else {
+				redacted[name] = redactSecrets(field)
+			}
+		}
+		return redacted
+	case []interface{}:
+		redacted := make([]interface{}, len(v))
+		for i, item := range v {
+			redacted[i] = redactSecrets(item)
+		}
+		return redacted
+	default:
+		return value
+	}
+}
@@ -0,0 +1,56 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package utils
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/models"
+)
+
+func TestAuditDiff(t *testing.T) {
+	before := map[string]interface{}{
+		"name":      "search",
+		"maxTokens": 100,
+		"keyHash":   "abc",
+		"endpoint":  map[string]interface{}{"url": "https://a.example.com", "apiKey": "old"},
+		"removed":   true,
+	}
+	after := map[string]interface{}{
+		"name":      "search",
+		"maxTokens": 200,
+		"keyHash":   "def",
+		"endpoint":  map[string]interface{}{"url": "https://b.example.com", "apiKey": "new"},
+	}
+	changes, err := AuditDiff(before, after)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]models.AuditFieldChange{
+		"maxTokens": {From: float64(100), To: float64(200)},
+		"keyHash":   {From: RedactedValue, To: RedactedValue},
+		"endpoint": {
+			From: map[string]interface{}{"url": "https://a.example.com", "apiKey": RedactedValue},
+			To:   map[string]interface{}{"url": "https://b.example.com", "apiKey": RedactedValue},
+		},
+		"removed": {From: true, To: nil},
+	}
+	if !reflect.DeepEqual(changes, want) {
+		t.Errorf("AuditDiff() = %v, want %v", changes, want)
+	}
+}
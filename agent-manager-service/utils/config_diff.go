@@ -0,0 +1,122 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/models"
+)
+
+const (
+	ConfigChangeAdded   = "added"
+	ConfigChangeRemoved = "removed"
+	ConfigChangeChanged = "changed"
+)
+
+// DiffManagedAgentConfigs lists the field changes that turn one agent configuration into another,
+// objects are compared key by key and arrays element by element
+func DiffManagedAgentConfigs(from models.ManagedAgentRequest, to models.ManagedAgentRequest) ([]models.ConfigChange, error) {
+	fromDoc, err := toJSONDocument(from)
+	if err != nil {
+		return nil, err
+	}
+	toDoc, err := toJSONDocument(to)
+	if err != nil {
+		return nil, err
+	}
+	changes := []models.ConfigChange{}
+	diffJSONValues("", fromDoc, toDoc, &changes)
+	return changes, nil
+}
+
+func toJSONDocument(v interface{}) (interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode configuration: %w", err)
+	}
+	var doc interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to decode configuration: %w", err)
+	}
+	return doc, nil
+}
+
+func diffJSONValues(path string, from interface{}, to interface{}, changes *[]models.ConfigChange) {
+	switch fromValue := from.(type) {
+	case map[string]interface{}:
+		if toValue, ok := to.(map[string]interface{}); ok {
+			diffJSONObjects(path, fromValue, toValue, changes)
+			return
+		}
+	case []interface{}:
+		if toValue, ok := to.([]interface{}); ok {
+			diffJSONArrays(path, fromValue, toValue, changes)
+			return
+		}
+	}
+	if !reflect.DeepEqual(from, to) {
+		*changes = append(*changes, models.ConfigChange{Path: path, Type: ConfigChangeChanged, From: from, To: to})
+	}
+}
+
+func diffJSONObjects(path string, from map[string]interface{}, to map[string]interface{}, changes *[]models.ConfigChange) {
+	keys := make([]string, 0, len(from)+len(to))
+	for key := range from {
+		keys = append(keys, key)
+	}
+	for key := range to {
+		if _, ok := from[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		keyPath := key
+		if path != "" {
+			keyPath = path + "." + key
+		}
+		fromValue, inFrom := from[key]
+		toValue, inTo := to[key]
+		switch {
+		case !inFrom:
+			*changes = append(*changes, models.ConfigChange{Path: keyPath, Type: ConfigChangeAdded, To: toValue})
+		case !inTo:
+			*changes = append(*changes, models.ConfigChange{Path: keyPath, Type: ConfigChangeRemoved, From: fromValue})
+		default:
+			diffJSONValues(keyPath, fromValue, toValue, changes)
+		}
+	}
+}
+
+func diffJSONArrays(path string, from []interface{}, to []interface{}, changes *[]models.ConfigChange) {
+	for i := 0; i < len(from) || i < len(to); i++ {
+		elementPath := fmt.Sprintf("%s[%d]", path, i)
+		switch {
+		case i >= len(from):
+			*changes = append(*changes, models.ConfigChange{Path: elementPath, Type: ConfigChangeAdded, To: to[i]})
+		case i >= len(to):
+			*changes = append(*changes, models.ConfigChange{Path: elementPath, Type: ConfigChangeRemoved, From: from[i]})
+		default:
+			diffJSONValues(elementPath, from[i], to[i], changes)
+		}
+	}
+}
@@ -41,11 +41,24 @@ const (
 
 // Path parameter names used in HTTP routes
 const (
-	PathParamOrgName   = "orgName"
-	PathParamProjName  = "projName"
-	PathParamAgentName = "agentName"
-	PathParamBuildName = "buildName"
-	PathParamTraceId   = "traceId"
+	PathParamOrgName      = "orgName"
+	PathParamProjName     = "projName"
+	PathParamAgentName    = "agentName"
+	PathParamBuildName    = "buildName"
+	PathParamTraceId      = "traceId"
+	PathParamAgentId      = "agentId"
+	PathParamVersion      = "version"
+	PathParamPromptId     = "promptId"
+	PathParamToolId       = "toolId"
+	PathParamAPIKeyId     = "apiKeyId"
+	PathParamEnvName      = "envName"
+	PathParamCredentialId = "credentialId"
+	PathParamWebhookId    = "webhookId"
+	PathParamDeliveryId   = "deliveryId"
+	PathParamEvaluationId = "evaluationId"
+	PathParamTraceViewId  = "viewId"
+	// Second version of a version comparison
+	PathParamOtherVersion = "otherVersion"
 )
 
 // Pagination constants
@@ -56,3 +69,47 @@ const (
 	DefaultOffset = 0
 	MinOffset     = 0
 )
+
+// Label limits, shared by agents, prompts and tools
+const (
+	MaxLabels               = 64
+	MaxLabelKeyPrefixLength = 253
+	MaxLabelKeyNameLength   = 63
+	MaxLabelValueLength     = 63
+	// Total length of the keys and values of the labels of a resource
+	MaxLabelsSize = 4096
+	// Requirements of a single label selector
+	MaxLabelSelectorRequirements = 32
+)
+
+// Managed agent limits
+const (
+	MaxAgentTools             = 128
+	MaxModelTemperature       = 2
+	MaxModelTopP              = 1
+	MaxSystemPromptLength     = 65536
+	MaxAgentDescriptionLength = 1024
+	MaxFrameworkNameLength    = 50
+	// Ten years, traces are not meant to be kept forever
+	MaxAgentRetentionDays = 3650
+)
+
+// Tool registry limits
+const (
+	MaxToolTags              = 32
+	MaxToolDescriptionLength = 4096
+)
+
+// Prompt template limits
+const (
+	MaxPromptTemplateLength = MaxSystemPromptLength
+	MaxPromptVariables      = 64
+)
+
+// API key settings
+const (
+	// APIKeyPrefix marks a credential in the auth header as an API key rather than a JWT
+	APIKeyPrefix = "amp_"
+	// Characters of the key kept to help users tell their keys apart
+	APIKeyDisplayPrefixLength = 12
+)
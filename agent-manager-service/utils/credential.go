@@ -0,0 +1,43 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package utils
+
+import (
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/models"
+)
+
+// Longest secret a credential can hold
+const MaxCredentialSecretLength = 8192
+
+// ValidateCredentialRequest validates a credential create or update payload and reports every rejected field,
+// the secret may only be omitted on updates
+func ValidateCredentialRequest(payload models.CredentialRequest, requireSecret bool) error {
+	errs := &ValidationError{}
+	if err := ValidateResourceName(payload.Name, "credential"); err != nil {
+		errs.Add("name", "%s", err.Error())
+	}
+	if len(payload.Description) > MaxAgentDescriptionLength {
+		errs.Add("description", "must be at most %d characters", MaxAgentDescriptionLength)
+	}
+	if payload.Secret == "" && requireSecret {
+		errs.Add("secret", "is required")
+	}
+	if len(payload.Secret) > MaxCredentialSecretLength {
+		errs.Add("secret", "must be at most %d bytes", MaxCredentialSecretLength)
+	}
+	return errs.OrNil()
+}
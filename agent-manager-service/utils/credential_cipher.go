@@ -0,0 +1,175 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package utils
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// Length of the data key each credential value is encrypted with
+const credentialDataKeyBytes = 32
+
+// Trailing characters of a credential value shown in its preview
+const credentialPreviewChars = 4
+
+// SealedCredential is a secret encrypted with a data key of its own, which is in turn
+// encrypted with the master key KeyID names
+type SealedCredential struct {
+	KeyID          string
+	EncryptedKey   []byte
+	EncryptedValue []byte
+}
+
+// CredentialCipher encrypts credential values and webhook secrets with AES-GCM. Every value is encrypted with a
+// random data key, so rotating the master key only re-encrypts the data keys and never touches the values
+type CredentialCipher struct {
+	masterKeys  map[string]cipher.AEAD
+	activeKeyID string
+}
+
+// NewCredentialCipher takes base64 encoded 256 bit master keys by key id and the id of the key new values are sealed with
+func NewCredentialCipher(keys map[string]string, activeKeyID string) (*CredentialCipher, error) {
+	masterKeys := make(map[string]cipher.AEAD, len(keys))
+	for keyId, encoded := range keys {
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode credential key %s: %w", keyId, err)
+		}
+		aead, err := newCredentialAEAD(key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid credential key %s: %w", keyId, err)
+		}
+		masterKeys[keyId] = aead
+	}
+	if _, ok := masterKeys[activeKeyID]; !ok {
+		return nil, fmt.Errorf("active credential key %q is not configured", activeKeyID)
+	}
+	return &CredentialCipher{masterKeys: masterKeys, activeKeyID: activeKeyID}, nil
+}
+
+func newCredentialAEAD(key []byte) (cipher.AEAD, error) {
+	if len(key) != credentialDataKeyBytes {
+		return nil, fmt.Errorf("key must be %d bytes, got %d", credentialDataKeyBytes, len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// ActiveKeyID returns the id of the master key new values are sealed with
+func (c *CredentialCipher) ActiveKeyID() string {
+	return c.activeKeyID
+}
+
+// Seal encrypts the value of a secret under the active master key. The id of the credential or webhook owning
+// the secret is bound to both ciphertexts, so they cannot be swapped onto another record
+func (c *CredentialCipher) Seal(ownerId uuid.UUID, value string) (SealedCredential, error) {
+	dataKey := make([]byte, credentialDataKeyBytes)
+	if _, err := rand.Read(dataKey); err != nil {
+		return SealedCredential{}, fmt.Errorf("failed to generate credential data key: %w", err)
+	}
+	valueAEAD, err := newCredentialAEAD(dataKey)
+	if err != nil {
+		return SealedCredential{}, err
+	}
+	encryptedValue, err := sealWithNonce(valueAEAD, []byte(value), ownerId[:])
+	if err != nil {
+		return SealedCredential{}, err
+	}
+	encryptedKey, err := sealWithNonce(c.masterKeys[c.activeKeyID], dataKey, ownerId[:])
+	if err != nil {
+		return SealedCredential{}, err
+	}
+	return SealedCredential{KeyID: c.activeKeyID, EncryptedKey: encryptedKey, EncryptedValue: encryptedValue}, nil
+}
+
+// Open decrypts the value of a secret
+func (c *CredentialCipher) Open(ownerId uuid.UUID, sealed SealedCredential) (string, error) {
+	dataKey, err := c.openDataKey(ownerId, sealed)
+	if err != nil {
+		return "", err
+	}
+	valueAEAD, err := newCredentialAEAD(dataKey)
+	if err != nil {
+		return "", err
+	}
+	value, err := openWithNonce(valueAEAD, sealed.EncryptedValue, ownerId[:])
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt secret of %s: %w", ownerId, err)
+	}
+	return string(value), nil
+}
+
+// Rewrap re-encrypts the data key of a secret under the active master key, the encrypted value is kept as is
+func (c *CredentialCipher) Rewrap(ownerId uuid.UUID, sealed SealedCredential) (SealedCredential, error) {
+	dataKey, err := c.openDataKey(ownerId, sealed)
+	if err != nil {
+		return SealedCredential{}, err
+	}
+	encryptedKey, err := sealWithNonce(c.masterKeys[c.activeKeyID], dataKey, ownerId[:])
+	if err != nil {
+		return SealedCredential{}, err
+	}
+	return SealedCredential{KeyID: c.activeKeyID, EncryptedKey: encryptedKey, EncryptedValue: sealed.EncryptedValue}, nil
+}
+
+func (c *CredentialCipher) openDataKey(ownerId uuid.UUID, sealed SealedCredential) ([]byte, error) {
+	masterKey, ok := c.masterKeys[sealed.KeyID]
+	if !ok {
+		return nil, fmt.Errorf("secret of %s is encrypted with key %q: %w", ownerId, sealed.KeyID, ErrCredentialKeyNotConfigured)
+	}
+	dataKey, err := openWithNonce(masterKey, sealed.EncryptedKey, ownerId[:])
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt data key of %s: %w", ownerId, err)
+	}
+	return dataKey, nil
+}
+
+// sealWithNonce prefixes the ciphertext with the random nonce it was sealed with
+func sealWithNonce(aead cipher.AEAD, plaintext []byte, additionalData []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	return aead.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+func openWithNonce(aead cipher.AEAD, ciphertext []byte, additionalData []byte) ([]byte, error) {
+	if len(ciphertext) < aead.NonceSize() {
+		return nil, fmt.Errorf("ciphertext too short")
+	}
+	nonce, sealed := ciphertext[:aead.NonceSize()], ciphertext[aead.NonceSize():]
+	return aead.Open(nil, nonce, sealed, additionalData)
+}
+
+// MaskCredential returns the preview of a credential value shown in place of the value,
+// short values reveal nothing of themselves
+func MaskCredential(value string) string {
+	runes := []rune(value)
+	if len(runes) < 3*credentialPreviewChars {
+		return "****"
+	}
+	return "****" + string(runes[len(runes)-credentialPreviewChars:])
+}
@@ -18,20 +18,32 @@ package utils
 
 import (
 	"context"
-)
-
-type ctxKey struct{}
 
-var correlationId ctxKey
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/middleware/correlation"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/models"
+)
 
 func CorrelationIdCtxKey() any {
-	return correlationId
+	return correlation.ContextKey()
 }
 
 func GetCorrelationId(ctx context.Context) string {
-	v, ok := ctx.Value(correlationId).(string)
-	if ok {
-		return v
+	if id, ok := correlation.FromContext(ctx); ok {
+		return id
 	}
 	return "-"
 }
+
+type auditLogCtx struct{}
+
+// WithAuditLog binds the entry recorded for a request to its context, so the services serving it can add the
+// changes they made. A nil entry unbinds it
+func WithAuditLog(ctx context.Context, auditLog *models.AuditLog) context.Context {
+	return context.WithValue(ctx, auditLogCtx{}, auditLog)
+}
+
+// AuditLogFromContext returns the audit entry bound to ctx
+func AuditLogFromContext(ctx context.Context) (*models.AuditLog, bool) {
+	auditLog, ok := ctx.Value(auditLogCtx{}).(*models.AuditLog)
+	return auditLog, ok && auditLog != nil
+}
@@ -0,0 +1,155 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package utils
+
+import (
+	"errors"
+	"net/http"
+)
+
+// errorProblem is how a known service error is reported to clients
+type errorProblem struct {
+	err    error
+	status int
+	detail string
+	// Field the error is reported on, conflicts on a unique name point at it
+	field *FieldError
+}
+
+func uniqueName(message string) *FieldError {
+	return &FieldError{Field: "name", Constraint: ConstraintUnique, Message: message}
+}
+
+// errorProblems maps the errors services return to their responses, the first match wins
+var errorProblems = []errorProblem{
+	// not found
+	{err: ErrOrganizationNotFound, status: http.StatusNotFound, detail: "Organization not found"},
+	{err: ErrProjectNotFound, status: http.StatusNotFound, detail: "Project not found"},
+	{err: ErrAgentNotFound, status: http.StatusNotFound, detail: "Agent not found"},
+	{err: ErrAgentVersionNotFound, status: http.StatusNotFound, detail: "Agent version not found"},
+	{err: ErrBuildNotFound, status: http.StatusNotFound, detail: "Build not found"},
+	{err: ErrEnvironmentNotFound, status: http.StatusNotFound, detail: "Environment not found"},
+	{err: ErrAgentNotDeployed, status: http.StatusNotFound, detail: "The agent is not deployed to the environment"},
+	{err: ErrDeploymentPipelineNotFound, status: http.StatusNotFound, detail: "Deployment pipeline not found"},
+	{err: ErrPromptNotFound, status: http.StatusNotFound, detail: "Prompt not found"},
+	{err: ErrPromptVersionNotFound, status: http.StatusNotFound, detail: "Prompt version not found"},
+	{err: ErrToolNotFound, status: http.StatusNotFound, detail: "Tool not found"},
+	{err: ErrCredentialNotFound, status: http.StatusNotFound, detail: "Credential not found"},
+	{err: ErrAPIKeyNotFound, status: http.StatusNotFound, detail: "API key not found"},
+	{err: ErrWebhookNotFound, status: http.StatusNotFound, detail: "Webhook not found"},
+	{err: ErrWebhookDeliveryNotFound, status: http.StatusNotFound, detail: "Webhook delivery not found"},
+	{err: ErrAgentBudgetNotFound, status: http.StatusNotFound, detail: "The agent has no budget"},
+	{err: ErrEvaluationNotFound, status: http.StatusNotFound, detail: "Evaluation not found"},
+	{err: ErrTraceViewNotFound, status: http.StatusNotFound, detail: "Trace view not found"},
+
+	// conflicts
+	{err: ErrOrganizationAlreadyExists, status: http.StatusConflict, detail: "Organization already exists", field: uniqueName("must be unique")},
+	{err: ErrProjectAlreadyExists, status: http.StatusConflict, detail: "Project already exists", field: uniqueName("must be unique within the organization")},
+	{err: ErrAgentAlreadyExists, status: http.StatusConflict, detail: "Agent already exists", field: uniqueName("must be unique")},
+	{err: ErrPromptAlreadyExists, status: http.StatusConflict, detail: "A prompt with this name already exists in the organization", field: uniqueName("must be unique within the organization")},
+	{err: ErrToolAlreadyExists, status: http.StatusConflict, detail: "A tool with this name already exists in the organization", field: uniqueName("must be unique within the organization")},
+	{err: ErrCredentialAlreadyExists, status: http.StatusConflict, detail: "A credential with this name already exists in the organization", field: uniqueName("must be unique within the organization")},
+	{err: ErrAPIKeyAlreadyExists, status: http.StatusConflict, detail: "An API key with this name already exists", field: uniqueName("must be unique among your active API keys")},
+	{err: ErrTraceViewAlreadyExists, status: http.StatusConflict, detail: "You already saved a trace view with this name", field: uniqueName("must be unique among your trace views")},
+	{err: ErrEnvironmentAlreadyExists, status: http.StatusConflict, detail: "An environment with this name already exists in the organization", field: uniqueName("must be unique within the organization")},
+	{err: ErrProjectHasAssociatedAgents, status: http.StatusConflict, detail: "Project has associated agents, delete them first"},
+	{err: ErrPromptInUse, status: http.StatusConflict, detail: "The prompt is referenced by agents, update or delete them first"},
+	{err: ErrToolInUse, status: http.StatusConflict, detail: "The tool is referenced by agents, update or delete them first"},
+	{err: ErrCredentialInUse, status: http.StatusConflict, detail: "The credential is referenced by tools, update or delete them first"},
+	{err: ErrWebhookDeliveryNotFailed, status: http.StatusConflict, detail: "Only failed deliveries can be redelivered"},
+	{err: ErrNothingToPromote, status: http.StatusConflict, detail: "The agent is not deployed to the environment it is promoted from"},
+	{err: ErrPromotionVersionMismatch, status: http.StatusConflict, detail: "Another version was deployed to the environment the agent is promoted from, check the deployment and retry"},
+	{err: ErrAgentReferenceDeleted, status: http.StatusConflict, detail: "The agent references a prompt or tool that was deleted, restore or recreate it first"},
+	{err: ErrIdempotencyKeyInProgress, status: http.StatusConflict, detail: "A request with this Idempotency-Key is still in progress, retry later"},
+	{err: ErrIdempotencyKeyReused, status: http.StatusUnprocessableEntity, detail: "The Idempotency-Key was already used with a different request"},
+
+	// stale writes
+	{err: ErrAgentVersionMismatch, status: http.StatusPreconditionFailed, detail: "The agent was modified since it was read, fetch it again and retry"},
+	{err: ErrPromptVersionMismatch, status: http.StatusPreconditionFailed, detail: "The prompt was modified since it was read, fetch it again and retry"},
+	{err: ErrToolVersionMismatch, status: http.StatusPreconditionFailed, detail: "The tool was modified since it was read, fetch it again and retry"},
+
+	// credentials and permissions
+	{err: ErrAPIKeyInvalid, status: http.StatusUnauthorized, detail: "Invalid API key"},
+	{err: ErrAPIKeyExpired, status: http.StatusUnauthorized, detail: "The API key has expired"},
+	{err: ErrAPIKeyRevoked, status: http.StatusUnauthorized, detail: "The API key has been revoked"},
+	{err: ErrPermissionDenied, status: http.StatusForbidden, detail: "Permission denied"},
+
+	// misconfiguration
+	{err: ErrCredentialsNotConfigured, status: http.StatusServiceUnavailable, detail: "Credentials and webhook secrets cannot be stored until encryption keys are configured"},
+	{err: ErrCredentialKeyNotConfigured, status: http.StatusInternalServerError, detail: "The key the credential is encrypted with is no longer configured"},
+}
+
+// WriteErrorProblem reports an error returned by a service as a problem response, so every handler answers the
+// same error with the same status and shape. Blocked operations are answered with 409 listing the blocking
+// resources, anything else as DescribeError describes it. Stale writes carry the ETag and the state of the current
+// version, with 412 when the version was sent in If-Match and 409 when it was sent in the body
+func WriteErrorProblem(w http.ResponseWriter, r *http.Request, err error, fallback string) {
+	var mismatchErr *VersionMismatchError
+	if errors.As(err, &mismatchErr) {
+		w.Header().Set("ETag", FormatVersionETag(mismatchErr.Current))
+		status, detail, _ := DescribeError(err, fallback)
+		if r.Header.Get("If-Match") == "" {
+			status = http.StatusConflict
+		}
+		problem := newProblem(r, status, detail, nil)
+		problem.Current = mismatchErr.Resource
+		writeProblem(w, problem)
+		return
+	}
+	var dependentsErr *DependentsError
+	if errors.As(err, &dependentsErr) {
+		WriteDependentsProblemResponse(w, r, http.StatusConflict, dependentsDetail, dependentsErr.Dependents)
+		return
+	}
+	status, detail, fieldErrors := DescribeError(err, fallback)
+	WriteProblemResponse(w, r, status, detail, fieldErrors...)
+}
+
+const dependentsDetail = "The resource is referenced by other resources, update or delete them first"
+
+// DescribeError returns the status, detail and rejected fields an error returned by a service is reported with.
+// Validation errors are described as 400 listing the rejected fields, import conflicts as 409 listing the
+// conflicting parts of the bundle, known errors with their status and anything else as 500 with the fallback
+// detail, the error itself is never shown to clients
+func DescribeError(err error, fallback string) (int, string, []FieldError) {
+	var validationErr *ValidationError
+	if errors.As(err, &validationErr) {
+		return http.StatusBadRequest, "Invalid request", validationErr.Errors
+	}
+	var conflictErr *ImportConflictError
+	if errors.As(err, &conflictErr) {
+		conflicts := make([]FieldError, 0, len(conflictErr.Conflicts))
+		for _, conflict := range conflictErr.Conflicts {
+			conflicts = append(conflicts, FieldError{Field: conflict.Field, Message: conflict.Message})
+		}
+		return http.StatusConflict, "The bundle conflicts with resources of the organization, a dry run lists the conflicts", conflicts
+	}
+	var dependentsErr *DependentsError
+	if errors.As(err, &dependentsErr) {
+		return http.StatusConflict, dependentsDetail, nil
+	}
+	for _, problem := range errorProblems {
+		if !errors.Is(err, problem.err) {
+			continue
+		}
+		if problem.field != nil {
+			return problem.status, problem.detail, []FieldError{*problem.field}
+		}
+		return problem.status, problem.detail, nil
+	}
+	return http.StatusInternalServerError, fallback, nil
+}
@@ -25,8 +25,44 @@ var (
 	ErrOrganizationNotFound       = errors.New("organization not found")
 	ErrBuildNotFound              = errors.New("build not found")
 	ErrEnvironmentNotFound        = errors.New("environment not found")
+	ErrEnvironmentAlreadyExists   = errors.New("environment already exists")
+	ErrAgentNotDeployed           = errors.New("agent is not deployed to the environment")
+	ErrNothingToPromote           = errors.New("agent is not deployed to the source environment")
+	ErrPromotionVersionMismatch   = errors.New("deployed version does not match the promoted version")
 	ErrOrganizationAlreadyExists  = errors.New("organization already exists")
 	ErrProjectAlreadyExists       = errors.New("project already exists")
 	ErrDeploymentPipelineNotFound = errors.New("deployment pipeline not found")
 	ErrProjectHasAssociatedAgents = errors.New("project has associated agents")
+	ErrAgentVersionMismatch       = errors.New("agent version does not match")
+	ErrAgentVersionNotFound       = errors.New("agent version not found")
+	ErrPromptNotFound             = errors.New("prompt not found")
+	ErrPromptAlreadyExists        = errors.New("prompt already exists")
+	ErrPromptVersionNotFound      = errors.New("prompt version not found")
+	ErrPromptVersionMismatch      = errors.New("prompt version does not match")
+	ErrPromptInUse                = errors.New("prompt is referenced by agents")
+	ErrAgentReferenceDeleted      = errors.New("agent references a deleted prompt or tool")
+	ErrToolNotFound               = errors.New("tool not found")
+	ErrToolAlreadyExists          = errors.New("tool already exists")
+	ErrToolVersionMismatch        = errors.New("tool version does not match")
+	ErrToolInUse                  = errors.New("tool is referenced by agents")
+	ErrCredentialNotFound         = errors.New("credential not found")
+	ErrCredentialAlreadyExists    = errors.New("credential already exists")
+	ErrCredentialInUse            = errors.New("credential is referenced by tools")
+	ErrCredentialsNotConfigured   = errors.New("credential encryption keys are not configured")
+	ErrCredentialKeyNotConfigured = errors.New("credential encryption key is not configured")
+	ErrWebhookNotFound            = errors.New("webhook not found")
+	ErrAgentBudgetNotFound        = errors.New("agent budget not found")
+	ErrEvaluationNotFound         = errors.New("evaluation not found")
+	ErrTraceViewNotFound          = errors.New("trace view not found")
+	ErrTraceViewAlreadyExists     = errors.New("trace view already exists")
+	ErrWebhookDeliveryNotFound    = errors.New("webhook delivery not found")
+	ErrWebhookDeliveryNotFailed   = errors.New("webhook delivery has not failed")
+	ErrAPIKeyNotFound             = errors.New("api key not found")
+	ErrAPIKeyAlreadyExists        = errors.New("api key already exists")
+	ErrAPIKeyInvalid              = errors.New("api key is invalid")
+	ErrAPIKeyExpired              = errors.New("api key has expired")
+	ErrAPIKeyRevoked              = errors.New("api key has been revoked")
+	ErrPermissionDenied           = errors.New("permission denied")
+	ErrIdempotencyKeyInProgress   = errors.New("request with idempotency key is in progress")
+	ErrIdempotencyKeyReused       = errors.New("idempotency key was used with a different request")
 )
@@ -0,0 +1,97 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package utils
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// VersionMismatchError is returned for a write conditioned on a version that is no longer the current one,
+// it unwraps to the mismatch error of the resource
+type VersionMismatchError struct {
+	Err error
+	// Version of the resource at the time of the write
+	Current int32
+	// Response representation of that version, returned so clients can reapply their change without another read
+	Resource any
+}
+
+func (e *VersionMismatchError) Error() string {
+	return fmt.Sprintf("%s, current version is %d", e.Err, e.Current)
+}
+
+func (e *VersionMismatchError) Unwrap() error {
+	return e.Err
+}
+
+// ParseVersionETag parses an If-Match header holding a resource version written by FormatVersionETag
+func ParseVersionETag(header string) (int32, error) {
+	tag := strings.TrimPrefix(strings.TrimSpace(header), "W/")
+	version, err := strconv.ParseInt(strings.Trim(tag, `"`), 10, 32)
+	if err != nil || version < 1 {
+		return 0, fmt.Errorf("invalid If-Match header %q, expected the version ETag of the resource", header)
+	}
+	return int32(version), nil
+}
+
+// FormatVersionETag formats a resource version as a strong ETag
+func FormatVersionETag(version int32) string {
+	return fmt.Sprintf(`"%d"`, version)
+}
+
+// HashETag derives a strong ETag from the JSON serialization of a resource without a version
+func HashETag(resource any) (string, error) {
+	body, err := json.Marshal(resource)
+	if err != nil {
+		return "", fmt.Errorf("failed to serialize resource for its ETag: %w", err)
+	}
+	sum := sha256.Sum256(body)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`, nil
+}
+
+// WriteNotModified sets the ETag of a GET response and answers it with 304 when the If-None-Match header
+// lists the ETag, it reports whether the response was written
+func WriteNotModified(w http.ResponseWriter, r *http.Request, etag string) bool {
+	w.Header().Set("ETag", etag)
+	ifNoneMatch := r.Header.Get("If-None-Match")
+	if ifNoneMatch == "" || (r.Method != http.MethodGet && r.Method != http.MethodHead) {
+		return false
+	}
+	if !etagListed(ifNoneMatch, etag) {
+		return false
+	}
+	w.WriteHeader(http.StatusNotModified)
+	return true
+}
+
+// etagListed compares the ETags of an If-None-Match header weakly as RFC 9110 requires
+func etagListed(header string, etag string) bool {
+	want := strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == want {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,108 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWriteNotModified(t *testing.T) {
+	tests := []struct {
+		name        string
+		method      string
+		ifNoneMatch string
+		want        int
+	}{
+		{name: "no header", method: http.MethodGet, want: http.StatusOK},
+		{name: "matching ETag", method: http.MethodGet, ifNoneMatch: `"3"`, want: http.StatusNotModified},
+		{name: "matching weak ETag", method: http.MethodGet, ifNoneMatch: `W/"3"`, want: http.StatusNotModified},
+		{name: "ETag in a list", method: http.MethodGet, ifNoneMatch: `"1", "3"`, want: http.StatusNotModified},
+		{name: "any ETag", method: http.MethodGet, ifNoneMatch: "*", want: http.StatusNotModified},
+		{name: "stale ETag", method: http.MethodGet, ifNoneMatch: `"2"`, want: http.StatusOK},
+		{name: "not a read", method: http.MethodPut, ifNoneMatch: `"3"`, want: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/tools/1", nil)
+			if tt.ifNoneMatch != "" {
+				req.Header.Set("If-None-Match", tt.ifNoneMatch)
+			}
+			rr := httptest.NewRecorder()
+			if !WriteNotModified(rr, req, FormatVersionETag(3)) {
+				rr.WriteHeader(http.StatusOK)
+			}
+			if rr.Code != tt.want {
+				t.Errorf("status = %d, want %d", rr.Code, tt.want)
+			}
+			if got := rr.Header().Get("ETag"); got != `"3"` {
+				t.Errorf("ETag = %q, want %q", got, `"3"`)
+			}
+		})
+	}
+}
+
+func TestHashETagFollowsContent(t *testing.T) {
+	first, err := HashETag(map[string]string{"name": "a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	same, _ := HashETag(map[string]string{"name": "a"})
+	other, _ := HashETag(map[string]string{"name": "b"})
+	if first != same || first == other {
+		t.Errorf("ETags %s, %s, %s should only differ with the content", first, same, other)
+	}
+}
+
+func TestWriteErrorProblemReportsStaleWrites(t *testing.T) {
+	tests := []struct {
+		name    string
+		ifMatch string
+		want    int
+	}{
+		{name: "version in If-Match", ifMatch: `"1"`, want: http.StatusPreconditionFailed},
+		{name: "version in the body", want: http.StatusConflict},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPut, "/tools/1", nil)
+			if tt.ifMatch != "" {
+				req.Header.Set("If-Match", tt.ifMatch)
+			}
+			rr := httptest.NewRecorder()
+			err := &VersionMismatchError{Err: ErrToolVersionMismatch, Current: 2, Resource: map[string]any{"version": 2}}
+			WriteErrorProblem(rr, req, fmt.Errorf("update failed: %w", err), "Failed to update tool")
+
+			if rr.Code != tt.want {
+				t.Errorf("status = %d, want %d", rr.Code, tt.want)
+			}
+			if got := rr.Header().Get("ETag"); got != `"2"` {
+				t.Errorf("ETag = %q, want %q", got, `"2"`)
+			}
+			var problem ProblemDetails
+			if err := json.Unmarshal(rr.Body.Bytes(), &problem); err != nil {
+				t.Fatal(err)
+			}
+			if current, _ := problem.Current.(map[string]any); current["version"] != float64(2) {
+				t.Errorf("current = %v, want the current version of the tool", problem.Current)
+			}
+		})
+	}
+}
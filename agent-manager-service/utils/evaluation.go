@@ -0,0 +1,205 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/models"
+)
+
+// Evaluators scoring traces
+const (
+	EvaluatorOutputNonEmpty = "output_non_empty"
+	EvaluatorNoToolErrors   = "no_tool_errors"
+	EvaluatorRegex          = "regex"
+	EvaluatorHTTP           = "http"
+)
+
+// EvaluatorTypes lists the evaluators a run can use
+var EvaluatorTypes = []string{EvaluatorOutputNonEmpty, EvaluatorNoToolErrors, EvaluatorRegex, EvaluatorHTTP}
+
+// Trace fields a regex evaluator can match
+const (
+	EvaluationFieldInput  = "input"
+	EvaluationFieldOutput = "output"
+)
+
+// Evaluation run statuses
+const (
+	EvaluationStatusPending   = "pending"
+	EvaluationStatusRunning   = "running"
+	EvaluationStatusCompleted = "completed"
+	EvaluationStatusFailed    = "failed"
+)
+
+// Evaluation limits
+const (
+	MaxEvaluationNameLength    = 100
+	DefaultEvaluationMaxTraces = 1000
+	MaxEvaluationMaxTraces     = 10000
+	MaxEvaluatorPatternLength  = 1024
+	// Longest reason kept of an http evaluator
+	MaxEvaluationReasonLength = 1024
+)
+
+// DefaultEvaluationPassThreshold is the lowest score an http evaluator passes a trace with when it gives no verdict
+const DefaultEvaluationPassThreshold = 0.5
+
+// ValidateEvaluationRequest validates an evaluation run payload and reports every rejected field
+func ValidateEvaluationRequest(payload models.EvaluationRequest) error {
+	errs := &ValidationError{}
+	if len(payload.Name) > MaxEvaluationNameLength {
+		errs.Add("name", "must be at most %d characters", MaxEvaluationNameLength)
+	}
+
+	filter := payload.Filter
+	if filter.AgentID == "" {
+		errs.Add("filter.agentId", "is required")
+	} else if _, err := uuid.Parse(filter.AgentID); err != nil {
+		errs.Add("filter.agentId", "must be a UUID")
+	}
+	if filter.StartTime == nil {
+		errs.Add("filter.startTime", "is required")
+	} else if filter.EndTime != nil && !filter.EndTime.After(*filter.StartTime) {
+		errs.Add("filter.endTime", "must be after startTime")
+	}
+	if filter.MaxTraces < 0 || filter.MaxTraces > MaxEvaluationMaxTraces {
+		errs.Add("filter.maxTraces", "must be from 1 to %d", MaxEvaluationMaxTraces)
+	}
+
+	evaluator := payload.Evaluator
+	switch evaluator.Type {
+	case EvaluatorOutputNonEmpty, EvaluatorNoToolErrors:
+	case EvaluatorRegex:
+		if evaluator.Pattern == "" {
+			errs.Add("evaluator.pattern", "is required")
+		} else if len(evaluator.Pattern) > MaxEvaluatorPatternLength {
+			errs.Add("evaluator.pattern", "must be at most %d characters", MaxEvaluatorPatternLength)
+		} else if _, err := regexp.Compile(evaluator.Pattern); err != nil {
+			errs.Add("evaluator.pattern", "invalid regular expression: %v", err)
+		}
+		if evaluator.Field != "" && evaluator.Field != EvaluationFieldInput && evaluator.Field != EvaluationFieldOutput {
+			errs.Add("evaluator.field", "must be %q or %q", EvaluationFieldInput, EvaluationFieldOutput)
+		}
+	case EvaluatorHTTP:
+		if len(evaluator.URL) > MaxWebhookURLLength {
+			errs.Add("evaluator.url", "must be at most %d characters", MaxWebhookURLLength)
+		} else if parsed, err := url.Parse(evaluator.URL); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			errs.Add("evaluator.url", "must be an absolute http or https URL")
+		}
+		if evaluator.PassThreshold != nil && (*evaluator.PassThreshold < 0 || *evaluator.PassThreshold > 1) {
+			errs.Add("evaluator.passThreshold", "must be from 0 to 1")
+		}
+	case "":
+		errs.Add("evaluator.type", "is required")
+	default:
+		errs.Add("evaluator.type", "must be one of %s", strings.Join(EvaluatorTypes, ", "))
+	}
+	return errs.OrNil()
+}
+
+// NormalizeEvaluator returns the evaluator with its defaults applied and the settings of other types dropped
+func NormalizeEvaluator(evaluator models.EvaluatorDefinition) models.EvaluatorDefinition {
+	normalized := models.EvaluatorDefinition{Type: evaluator.Type}
+	switch evaluator.Type {
+	case EvaluatorRegex:
+		normalized.Pattern, normalized.Field = evaluator.Pattern, evaluator.Field
+		if normalized.Field == "" {
+			normalized.Field = EvaluationFieldOutput
+		}
+	case EvaluatorHTTP:
+		threshold := DefaultEvaluationPassThreshold
+		if evaluator.PassThreshold != nil {
+			threshold = *evaluator.PassThreshold
+		}
+		normalized.URL, normalized.PassThreshold = evaluator.URL, &threshold
+	}
+	return normalized
+}
+
+// EvaluateBuiltin scores a trace with an evaluator other than http, 1 when it passes and 0 otherwise
+func EvaluateBuiltin(evaluator models.EvaluatorDefinition, input interface{}, output interface{}, toolErrors int) models.EvaluationOutcome {
+	var passed bool
+	var reason string
+	switch evaluator.Type {
+	case EvaluatorOutputNonEmpty:
+		passed = !isEmptyEvaluationValue(output)
+		if !passed {
+			reason = "output is empty"
+		}
+	case EvaluatorNoToolErrors:
+		passed = toolErrors == 0
+		if !passed {
+			reason = fmt.Sprintf("%d tool calls failed", toolErrors)
+		}
+	case EvaluatorRegex:
+		pattern, err := regexp.Compile(evaluator.Pattern)
+		if err != nil {
+			return models.EvaluationOutcome{Error: fmt.Sprintf("invalid regular expression: %v", err)}
+		}
+		value := output
+		if evaluator.Field == EvaluationFieldInput {
+			value = input
+		}
+		passed = pattern.MatchString(EvaluationText(value))
+		if !passed {
+			reason = fmt.Sprintf("%s does not match the pattern", evaluator.Field)
+		}
+	default:
+		return models.EvaluationOutcome{Error: fmt.Sprintf("evaluator %q is not a builtin rule", evaluator.Type)}
+	}
+	score := 0.0
+	if passed {
+		score = 1
+	}
+	return models.EvaluationOutcome{Score: &score, Passed: passed, Reason: reason}
+}
+
+// EvaluationText returns the input or output of a trace as text, strings as they are and anything else as JSON
+func EvaluationText(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	default:
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		return string(encoded)
+	}
+}
+
+// isEmptyEvaluationValue reports whether a trace input or output is missing, blank or an empty list or object
+func isEmptyEvaluationValue(value interface{}) bool {
+	if text, ok := value.(string); ok {
+		return strings.TrimSpace(text) == ""
+	}
+	switch EvaluationText(value) {
+	case "", "null", "[]", "{}":
+		return true
+	}
+	return false
+}
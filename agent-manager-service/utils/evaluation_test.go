@@ -0,0 +1,120 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package utils
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/models"
+)
+
+func TestValidateEvaluationRequest(t *testing.T) {
+	start := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(24 * time.Hour)
+	valid := models.EvaluationRequest{
+		Filter:    models.EvaluationTraceFilter{AgentID: "1b4e28ba-2fa1-11d2-883f-0016d3cca427", StartTime: &start, EndTime: &end},
+		Evaluator: models.EvaluatorDefinition{Type: EvaluatorRegex, Pattern: `(?i)order \d+`},
+	}
+	if err := ValidateEvaluationRequest(valid); err != nil {
+		t.Fatalf("valid request rejected: %v", err)
+	}
+	threshold := 0.8
+	httpEvaluator := valid
+	httpEvaluator.Evaluator = models.EvaluatorDefinition{Type: EvaluatorHTTP, URL: "https://judge.example.com/score", PassThreshold: &threshold}
+	if err := ValidateEvaluationRequest(httpEvaluator); err != nil {
+		t.Fatalf("valid http evaluator rejected: %v", err)
+	}
+
+	badThreshold := 1.5
+	cases := map[string]func(req *models.EvaluationRequest){
+		"name":              func(req *models.EvaluationRequest) { req.Name = strings.Repeat("n", MaxEvaluationNameLength+1) },
+		"filter.agentId":    func(req *models.EvaluationRequest) { req.Filter.AgentID = "agent" },
+		"filter.startTime":  func(req *models.EvaluationRequest) { req.Filter.StartTime = nil },
+		"filter.endTime":    func(req *models.EvaluationRequest) { req.Filter.EndTime = &start },
+		"filter.maxTraces":  func(req *models.EvaluationRequest) { req.Filter.MaxTraces = MaxEvaluationMaxTraces + 1 },
+		"evaluator.type":    func(req *models.EvaluationRequest) { req.Evaluator.Type = "llm" },
+		"evaluator.pattern": func(req *models.EvaluationRequest) { req.Evaluator.Pattern = "(" },
+		"evaluator.field":   func(req *models.EvaluationRequest) { req.Evaluator.Field = "metadata" },
+		"evaluator.url": func(req *models.EvaluationRequest) {
+			req.Evaluator = models.EvaluatorDefinition{Type: EvaluatorHTTP, URL: "judge"}
+		},
+		"evaluator.passThreshold": func(req *models.EvaluationRequest) {
+			req.Evaluator = models.EvaluatorDefinition{Type: EvaluatorHTTP, URL: "https://judge.example.com", PassThreshold: &badThreshold}
+		},
+	}
+	for field, mutate := range cases {
+		req := valid
+		mutate(&req)
+		err := ValidateEvaluationRequest(req)
+		validationErr, ok := err.(*ValidationError)
+		if !ok || len(validationErr.Errors) != 1 || validationErr.Errors[0].Field != field {
+			t.Errorf("%s: got %v, want a single error on the field", field, err)
+		}
+	}
+}
+
+func TestNormalizeEvaluator(t *testing.T) {
+	regex := NormalizeEvaluator(models.EvaluatorDefinition{Type: EvaluatorRegex, Pattern: "ok", URL: "https://ignored.example.com"})
+	if regex.Field != EvaluationFieldOutput || regex.URL != "" {
+		t.Errorf("regex evaluator normalized to %+v", regex)
+	}
+	httpEvaluator := NormalizeEvaluator(models.EvaluatorDefinition{Type: EvaluatorHTTP, URL: "https://judge.example.com"})
+	if httpEvaluator.PassThreshold == nil || *httpEvaluator.PassThreshold != DefaultEvaluationPassThreshold {
+		t.Errorf("http evaluator normalized to %+v", httpEvaluator)
+	}
+}
+
+func TestEvaluateBuiltin(t *testing.T) {
+	cases := []struct {
+		name       string
+		evaluator  models.EvaluatorDefinition
+		input      interface{}
+		output     interface{}
+		toolErrors int
+		want       bool
+	}{
+		{"text output", models.EvaluatorDefinition{Type: EvaluatorOutputNonEmpty}, nil, "Your order shipped", 0, true},
+		{"missing output", models.EvaluatorDefinition{Type: EvaluatorOutputNonEmpty}, "hi", nil, 0, false},
+		{"blank output", models.EvaluatorDefinition{Type: EvaluatorOutputNonEmpty}, "hi", " \n", 0, false},
+		{"empty message list", models.EvaluatorDefinition{Type: EvaluatorOutputNonEmpty}, "hi", []interface{}{}, 0, false},
+		{"message list", models.EvaluatorDefinition{Type: EvaluatorOutputNonEmpty}, "hi", []interface{}{map[string]interface{}{"role": "assistant"}}, 0, true},
+		{"no tool errors", models.EvaluatorDefinition{Type: EvaluatorNoToolErrors}, nil, nil, 0, true},
+		{"tool errors", models.EvaluatorDefinition{Type: EvaluatorNoToolErrors}, nil, "done", 2, false},
+		{"output match", models.EvaluatorDefinition{Type: EvaluatorRegex, Pattern: `order \d+`, Field: EvaluationFieldOutput}, "where is order 12", "order 12 shipped", 0, true},
+		{"output mismatch", models.EvaluatorDefinition{Type: EvaluatorRegex, Pattern: `^order`, Field: EvaluationFieldOutput}, "order 12", "It shipped", 0, false},
+		{"input match", models.EvaluatorDefinition{Type: EvaluatorRegex, Pattern: `"role":"user"`, Field: EvaluationFieldInput}, []interface{}{map[string]interface{}{"role": "user"}}, "", 0, true},
+	}
+	for _, c := range cases {
+		outcome := EvaluateBuiltin(c.evaluator, c.input, c.output, c.toolErrors)
+		if outcome.Error != "" || outcome.Score == nil {
+			t.Errorf("%s: unexpected outcome %+v", c.name, outcome)
+			continue
+		}
+		if outcome.Passed != c.want || (*outcome.Score == 1) != c.want {
+			t.Errorf("%s: passed %v with score %v, want %v", c.name, outcome.Passed, *outcome.Score, c.want)
+		}
+		if !outcome.Passed && outcome.Reason == "" {
+			t.Errorf("%s: failed without a reason", c.name)
+		}
+	}
+
+	if outcome := EvaluateBuiltin(models.EvaluatorDefinition{Type: EvaluatorHTTP}, nil, nil, 0); outcome.Error == "" {
+		t.Error("http evaluator scored as a builtin rule")
+	}
+}
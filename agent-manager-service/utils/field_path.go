@@ -0,0 +1,139 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package utils
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Constraints a rejected field can fail, named after the JSON Schema keyword where there is one so the console can
+// pick a message or highlight without parsing the human readable one
+const (
+	ConstraintRequired             = "required"
+	ConstraintType                 = "type"
+	ConstraintEnum                 = "enum"
+	ConstraintFormat               = "format"
+	ConstraintPattern              = "pattern"
+	ConstraintMinLength            = "minLength"
+	ConstraintMaxLength            = "maxLength"
+	ConstraintMinimum              = "minimum"
+	ConstraintMaximum              = "maximum"
+	ConstraintMaxItems             = "maxItems"
+	ConstraintMaxProperties        = "maxProperties"
+	ConstraintUniqueItems          = "uniqueItems"
+	ConstraintAdditionalProperties = "additionalProperties"
+	// ConstraintUnique rejects a value another resource of the organization already has
+	ConstraintUnique = "unique"
+	// ConstraintMaxSize bounds the combined size of the keys and values of a map
+	ConstraintMaxSize = "maxSize"
+	// ConstraintConflict rejects a field that cannot be set together with another one
+	ConstraintConflict = "conflict"
+	// ConstraintReference rejects a value that does not refer to something it has to, e.g. a prompt variable
+	// missing from the template
+	ConstraintReference = "reference"
+)
+
+// FieldPath locates a value in a request body, from the top level field down through object members and array
+// elements. The zero value is the body itself
+type FieldPath struct {
+	segments []pathSegment
+}
+
+type pathSegment struct {
+	name    string
+	index   int
+	isIndex bool
+}
+
+// Field returns the path of a top level field of the body
+func Field(name string) FieldPath {
+	return FieldPath{}.Key(name)
+}
+
+// Key returns the path of the member name of the object at p
+func (p FieldPath) Key(name string) FieldPath {
+	return p.append(pathSegment{name: name})
+}
+
+// Index returns the path of element i of the array at p
+func (p FieldPath) Index(i int) FieldPath {
+	return p.append(pathSegment{index: i, isIndex: true})
+}
+
+// append copies the segments so paths sharing a parent never overwrite each other
+func (p FieldPath) append(segment pathSegment) FieldPath {
+	segments := make([]pathSegment, len(p.segments), len(p.segments)+1)
+	copy(segments, p.segments)
+	return FieldPath{segments: append(segments, segment)}
+}
+
+// String returns the path in the dotted form of the field of a FieldError, e.g. tools[1].name
+func (p FieldPath) String() string {
+	var b strings.Builder
+	for _, segment := range p.segments {
+		switch {
+		case segment.isIndex:
+			b.WriteString("[" + strconv.Itoa(segment.index) + "]")
+		case b.Len() > 0:
+			b.WriteString("." + segment.name)
+		default:
+			b.WriteString(segment.name)
+		}
+	}
+	return b.String()
+}
+
+// Pointer returns the path as an RFC 6901 JSON pointer, e.g. /tools/1/name
+func (p FieldPath) Pointer() string {
+	var b strings.Builder
+	for _, segment := range p.segments {
+		b.WriteByte('/')
+		if segment.isIndex {
+			b.WriteString(strconv.Itoa(segment.index))
+		} else {
+			b.WriteString(pointerEscaper.Replace(segment.name))
+		}
+	}
+	return b.String()
+}
+
+var pointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+
+// fieldPointer derives the JSON pointer of a dotted field such as tools[1].name. Members whose name contains '.'
+// or '[' cannot be told apart from nesting, the errors of such fields are recorded with an explicit FieldPath
+func fieldPointer(field string) string {
+	if field == "" {
+		return ""
+	}
+	var p FieldPath
+	for _, name := range strings.Split(field, ".") {
+		indexes := ""
+		if open := strings.IndexByte(name, '['); open > 0 && strings.HasSuffix(name, "]") {
+			name, indexes = name[:open], name[open:]
+		}
+		p = p.Key(name)
+		for _, index := range strings.Split(indexes, "[")[1:] {
+			i, err := strconv.Atoi(strings.TrimSuffix(index, "]"))
+			if err != nil {
+				return FieldPath{}.Key(field).Pointer()
+			}
+			p = p.Index(i)
+		}
+	}
+	return p.Pointer()
+}
@@ -0,0 +1,95 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package utils
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestFieldPath(t *testing.T) {
+	tests := []struct {
+		path        FieldPath
+		wantString  string
+		wantPointer string
+	}{
+		{path: FieldPath{}, wantString: "", wantPointer: ""},
+		{path: Field("modelConfig").Key("temperature"), wantString: "modelConfig.temperature", wantPointer: "/modelConfig/temperature"},
+		{path: Field("tools").Index(1).Key("name"), wantString: "tools[1].name", wantPointer: "/tools/1/name"},
+		{path: Field("labels").Key("example.com/team"), wantString: "labels.example.com/team", wantPointer: "/labels/example.com~1team"},
+		{path: Field("variables").Key("a~b"), wantString: "variables.a~b", wantPointer: "/variables/a~0b"},
+	}
+	for _, tt := range tests {
+		if got := tt.path.String(); got != tt.wantString {
+			t.Errorf("String() = %q, want %q", got, tt.wantString)
+		}
+		if got := tt.path.Pointer(); got != tt.wantPointer {
+			t.Errorf("Pointer() = %q, want %q", got, tt.wantPointer)
+		}
+	}
+
+	// Paths sharing a parent do not overwrite each other
+	tools := Field("tools")
+	first, second := tools.Index(0), tools.Index(1)
+	if first.Pointer() != "/tools/0" || second.Pointer() != "/tools/1" {
+		t.Errorf("sibling pointers = %q and %q", first.Pointer(), second.Pointer())
+	}
+}
+
+func TestFieldPointer(t *testing.T) {
+	tests := map[string]string{
+		"":                      "",
+		"name":                  "/name",
+		"modelConfig.maxTokens": "/modelConfig/maxTokens",
+		"operations[2].agentId": "/operations/2/agentId",
+		"matrix[1][0]":          "/matrix/1/0",
+	}
+	for field, want := range tests {
+		if got := fieldPointer(field); got != want {
+			t.Errorf("fieldPointer(%q) = %q, want %q", field, got, want)
+		}
+	}
+}
+
+func TestWriteProblemResponseFillsPointers(t *testing.T) {
+	errs := &ValidationError{}
+	errs.Add("tools[0].name", "is required")
+	errs.Reject(Field("labels").Key("example.com/team"), ConstraintPattern, "is invalid")
+
+	rr := httptest.NewRecorder()
+	WriteProblemResponse(rr, httptest.NewRequest(http.MethodPost, "/agents", nil), http.StatusBadRequest, "Invalid agent", errs.Errors...)
+
+	var body struct {
+		Errors []map[string]string `json:"errors"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	want := []map[string]string{
+		{"field": "tools[0].name", "pointer": "/tools/0/name", "message": "is required"},
+		{"field": "labels.example.com/team", "pointer": "/labels/example.com~1team", "constraint": "pattern", "message": "is invalid"},
+	}
+	if !reflect.DeepEqual(body.Errors, want) {
+		t.Errorf("errors = %v, want %v", body.Errors, want)
+	}
+	if errs.Errors[0].Pointer != "" {
+		t.Errorf("the recorded errors were changed to %+v", errs.Errors[0])
+	}
+}
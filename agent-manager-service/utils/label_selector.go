@@ -0,0 +1,204 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package utils
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/models"
+)
+
+// ParseLabelSelector parses a comma separated list of label requirements in the syntax of Kubernetes label
+// selectors, every requirement must hold for a resource to match:
+//
+//	env=prod, env==prod    the label is set to the value
+//	env!=prod              the label is not set to the value, or not set at all
+//	env in (prod,staging)  the label is set to one of the values
+//	env notin (dev,test)   the label is set to none of the values, or not set at all
+//	env                    the label is set
+//	!env                   the label is not set
+//
+// A blank selector matches every resource
+func ParseLabelSelector(selector string) (models.LabelSelector, error) {
+	if strings.TrimSpace(selector) == "" {
+		return nil, nil
+	}
+	p := &selectorParser{input: selector}
+	var requirements models.LabelSelector
+	for {
+		requirement, err := p.parseRequirement()
+		if err != nil {
+			return nil, err
+		}
+		requirements = append(requirements, requirement)
+		if len(requirements) > MaxLabelSelectorRequirements {
+			return nil, fmt.Errorf("label selectors may contain at most %d requirements", MaxLabelSelectorRequirements)
+		}
+		p.skipSpaces()
+		if p.done() {
+			return requirements, nil
+		}
+		if !p.consume(",") {
+			return nil, p.errorf("expected ',' between requirements")
+		}
+	}
+}
+
+// selectorParser reads a label selector from left to right
+type selectorParser struct {
+	input string
+	pos   int
+}
+
+// selectorDelimiters end keys and values
+const selectorDelimiters = " \t,=!()"
+
+func (p *selectorParser) done() bool {
+	return p.pos >= len(p.input)
+}
+
+func (p *selectorParser) skipSpaces() {
+	for !p.done() && (p.input[p.pos] == ' ' || p.input[p.pos] == '\t') {
+		p.pos++
+	}
+}
+
+// consume skips token when the input continues with it
+func (p *selectorParser) consume(token string) bool {
+	if strings.HasPrefix(p.input[p.pos:], token) {
+		p.pos += len(token)
+		return true
+	}
+	return false
+}
+
+// word reads up to the next delimiter
+func (p *selectorParser) word() string {
+	start := p.pos
+	for !p.done() && !strings.ContainsRune(selectorDelimiters, rune(p.input[p.pos])) {
+		p.pos++
+	}
+	return p.input[start:p.pos]
+}
+
+func (p *selectorParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("invalid label selector at position %d: %s", p.pos+1, fmt.Sprintf(format, args...))
+}
+
+// atRequirementEnd reports whether the current requirement has no more tokens
+func (p *selectorParser) atRequirementEnd() bool {
+	p.skipSpaces()
+	return p.done() || p.input[p.pos] == ','
+}
+
+func (p *selectorParser) key() (string, error) {
+	p.skipSpaces()
+	key := p.word()
+	if key == "" {
+		return "", p.errorf("expected a label key")
+	}
+	if err := ValidateLabelKey(key); err != nil {
+		return "", p.errorf("%s", err.Error())
+	}
+	return key, nil
+}
+
+func (p *selectorParser) value() (string, error) {
+	p.skipSpaces()
+	value := p.word()
+	if err := ValidateLabelValue(value); err != nil {
+		return "", p.errorf("%s", err.Error())
+	}
+	return value, nil
+}
+
+func (p *selectorParser) parseRequirement() (models.LabelRequirement, error) {
+	p.skipSpaces()
+	if p.consume("!") {
+		key, err := p.key()
+		if err != nil {
+			return models.LabelRequirement{}, err
+		}
+		if !p.atRequirementEnd() {
+			return models.LabelRequirement{}, p.errorf("expected ',' after !%s", key)
+		}
+		return models.LabelRequirement{Key: key, Operator: models.LabelOperatorDoesNotExist}, nil
+	}
+
+	key, err := p.key()
+	if err != nil {
+		return models.LabelRequirement{}, err
+	}
+	if p.atRequirementEnd() {
+		return models.LabelRequirement{Key: key, Operator: models.LabelOperatorExists}, nil
+	}
+
+	var operator string
+	switch {
+	case p.consume("=="), p.consume("="):
+		operator = models.LabelOperatorIn
+	case p.consume("!="):
+		operator = models.LabelOperatorNotIn
+	default:
+		switch word := p.word(); word {
+		case models.LabelOperatorIn, models.LabelOperatorNotIn:
+			values, err := p.valueSet()
+			if err != nil {
+				return models.LabelRequirement{}, err
+			}
+			return models.LabelRequirement{Key: key, Operator: word, Values: values}, nil
+		default:
+			return models.LabelRequirement{}, p.errorf("expected =, ==, !=, in or notin after %s", key)
+		}
+	}
+	value, err := p.value()
+	if err != nil {
+		return models.LabelRequirement{}, err
+	}
+	if !p.atRequirementEnd() {
+		return models.LabelRequirement{}, p.errorf("expected ',' after %s", value)
+	}
+	return models.LabelRequirement{Key: key, Operator: operator, Values: []string{value}}, nil
+}
+
+// valueSet reads the parenthesized values of in and notin, dropping duplicates
+func (p *selectorParser) valueSet() ([]string, error) {
+	p.skipSpaces()
+	if !p.consume("(") {
+		return nil, p.errorf("expected '(' to open the values")
+	}
+	var values []string
+	seen := map[string]bool{}
+	for {
+		value, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		if !seen[value] {
+			seen[value] = true
+			values = append(values, value)
+		}
+		p.skipSpaces()
+		if p.consume(")") {
+			return values, nil
+		}
+		if !p.consume(",") {
+			return nil, p.errorf("expected ',' or ')' after %s", value)
+		}
+	}
+}
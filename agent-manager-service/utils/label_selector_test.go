@@ -0,0 +1,171 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package utils
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/models"
+)
+
+func TestParseLabelSelector(t *testing.T) {
+	in := func(key string, values ...string) models.LabelRequirement {
+		return models.LabelRequirement{Key: key, Operator: models.LabelOperatorIn, Values: values}
+	}
+	notIn := func(key string, values ...string) models.LabelRequirement {
+		return models.LabelRequirement{Key: key, Operator: models.LabelOperatorNotIn, Values: values}
+	}
+	exists := models.LabelRequirement{Key: "env", Operator: models.LabelOperatorExists}
+	doesNotExist := models.LabelRequirement{Key: "env", Operator: models.LabelOperatorDoesNotExist}
+
+	tests := []struct {
+		name     string
+		selector string
+		want     models.LabelSelector
+	}{
+		{name: "blank", selector: "  ", want: nil},
+		{name: "equality", selector: "env=prod", want: models.LabelSelector{in("env", "prod")}},
+		{name: "double equality", selector: "env==prod", want: models.LabelSelector{in("env", "prod")}},
+		{name: "inequality", selector: "env!=prod", want: models.LabelSelector{notIn("env", "prod")}},
+		{name: "empty value", selector: "env=", want: models.LabelSelector{in("env", "")}},
+		{name: "set", selector: "env in (prod, staging)", want: models.LabelSelector{in("env", "prod", "staging")}},
+		{name: "set without spaces", selector: "env in(prod,staging)", want: models.LabelSelector{in("env", "prod", "staging")}},
+		{name: "negated set", selector: "env notin (dev,test)", want: models.LabelSelector{notIn("env", "dev", "test")}},
+		{name: "duplicate set values", selector: "env in (prod,prod)", want: models.LabelSelector{in("env", "prod")}},
+		{name: "existence", selector: "env", want: models.LabelSelector{exists}},
+		{name: "non existence", selector: "!env", want: models.LabelSelector{doesNotExist}},
+		{name: "non existence with space", selector: "! env", want: models.LabelSelector{doesNotExist}},
+		{name: "prefixed key", selector: "example.com/team=ml", want: models.LabelSelector{in("example.com/team", "ml")}},
+		{name: "value with slash", selector: "path=a/b", want: models.LabelSelector{in("path", "a/b")}},
+		{
+			name:     "several requirements",
+			selector: " env=prod , team!=ml,tier in (1,2),!legacy,owner ",
+			want: models.LabelSelector{
+				in("env", "prod"),
+				notIn("team", "ml"),
+				in("tier", "1", "2"),
+				{Key: "legacy", Operator: models.LabelOperatorDoesNotExist},
+				{Key: "owner", Operator: models.LabelOperatorExists},
+			},
+		},
+		{
+			name:     "conflicting requirements are kept",
+			selector: "env=prod,env=dev",
+			want:     models.LabelSelector{in("env", "prod"), in("env", "dev")},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseLabelSelector(tt.selector)
+			if err != nil {
+				t.Fatalf("ParseLabelSelector(%q) error = %v", tt.selector, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseLabelSelector(%q) = %+v, want %+v", tt.selector, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseLabelSelectorErrors(t *testing.T) {
+	tooMany := make([]string, MaxLabelSelectorRequirements+1)
+	for i := range tooMany {
+		tooMany[i] = fmt.Sprintf("key%d", i)
+	}
+
+	tests := []struct {
+		name     string
+		selector string
+		wantErr  string
+	}{
+		{name: "missing key", selector: "=prod", wantErr: "position 1: expected a label key"},
+		{name: "trailing comma", selector: "env=prod,", wantErr: "position 10: expected a label key"},
+		{name: "empty requirement", selector: "env=prod,,team=ml", wantErr: "expected a label key"},
+		{name: "unknown operator", selector: "env > 1", wantErr: "expected =, ==, !=, in or notin after env"},
+		{name: "space in value", selector: "env=prod staging", wantErr: "expected ',' after prod"},
+		{name: "value after non existence", selector: "!env=prod", wantErr: "expected ',' after !env"},
+		{name: "set without parentheses", selector: "env in prod", wantErr: "expected '(' to open the values"},
+		{name: "unclosed set", selector: "env in (prod,staging", wantErr: "expected ',' or ')' after staging"},
+		{name: "set after equality", selector: "env=(prod)", wantErr: "expected ',' after "},
+		{name: "invalid key", selector: "-env=prod", wantErr: "label key names must be"},
+		{name: "invalid key prefix", selector: "Example.com/team=ml", wantErr: "label key prefixes must be"},
+		{name: "empty key name", selector: "example.com/=ml", wantErr: "label key names must be"},
+		{name: "key too long", selector: strings.Repeat("k", MaxLabelKeyNameLength+1), wantErr: "label key names must be"},
+		{name: "invalid value", selector: "env=prod-", wantErr: "label values must be"},
+		{name: "invalid set value", selector: "env in (prod,-)", wantErr: "label values must be"},
+		{name: "too many requirements", selector: strings.Join(tooMany, ","), wantErr: "at most 32 requirements"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseLabelSelector(tt.selector)
+			if err == nil {
+				t.Fatalf("ParseLabelSelector(%q) error = nil, want %q", tt.selector, tt.wantErr)
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ParseLabelSelector(%q) error = %q, want it to contain %q", tt.selector, err.Error(), tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateLabels(t *testing.T) {
+	tooMany := map[string]string{}
+	for i := 0; i <= MaxLabels; i++ {
+		tooMany[fmt.Sprintf("key%d", i)] = "value"
+	}
+	tooLarge := map[string]string{}
+	for i := 0; i < MaxLabels; i++ {
+		tooLarge[fmt.Sprintf("%s%02d", strings.Repeat("k", MaxLabelKeyNameLength-2), i)] = strings.Repeat("v", MaxLabelValueLength)
+	}
+
+	tests := []struct {
+		name         string
+		labels       map[string]string
+		wantFields   []string
+		wantPointers []string
+	}{
+		{name: "valid", labels: map[string]string{"env": "prod", "example.com/team": "ml", "empty": ""}},
+		{name: "too many", labels: tooMany, wantFields: []string{"labels"}, wantPointers: []string{"/labels"}},
+		{name: "too large", labels: tooLarge, wantFields: []string{"labels"}, wantPointers: []string{"/labels"}},
+		{
+			name:         "invalid key and value",
+			labels:       map[string]string{"a/b/c": "ok", "env": "-"},
+			wantFields:   []string{"labels.a/b/c", "labels.env"},
+			wantPointers: []string{"/labels/a~1b~1c", "/labels/env"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := &ValidationError{}
+			validateLabels(errs, Field("labels"), tt.labels)
+			var fields, pointers []string
+			for _, fieldErr := range errs.Errors {
+				fields = append(fields, fieldErr.Field)
+				pointers = append(pointers, fieldErr.Pointer)
+			}
+			if !reflect.DeepEqual(fields, tt.wantFields) {
+				t.Errorf("validateLabels() fields = %v, want %v", fields, tt.wantFields)
+			}
+			if !reflect.DeepEqual(pointers, tt.wantPointers) {
+				t.Errorf("validateLabels() pointers = %v, want %v", pointers, tt.wantPointers)
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package utils
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var (
+	// labelPattern matches label values and tool tags
+	labelPattern = regexp.MustCompile(`^[A-Za-z0-9]([A-Za-z0-9_./-]*[A-Za-z0-9])?$`)
+	// labelKeyNamePattern matches the name of a label key, the part after the optional prefix
+	labelKeyNamePattern = regexp.MustCompile(`^[A-Za-z0-9]([A-Za-z0-9_.-]*[A-Za-z0-9])?$`)
+	// labelKeyPrefixPattern matches the optional prefix of a label key, a DNS subdomain such as example.com
+	labelKeyPrefixPattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]*[a-z0-9])?(\.[a-z0-9]([a-z0-9-]*[a-z0-9])?)*$`)
+)
+
+// ValidateLabelKey checks the syntax of a label key, a name optionally preceded by a DNS subdomain prefix and a slash
+// as in example.com/team
+func ValidateLabelKey(key string) error {
+	name := key
+	if prefix, rest, found := strings.Cut(key, "/"); found {
+		if len(prefix) > MaxLabelKeyPrefixLength || !labelKeyPrefixPattern.MatchString(prefix) {
+			return fmt.Errorf("label key prefixes must be DNS subdomains of at most %d lowercase alphanumeric, '-' or '.' characters", MaxLabelKeyPrefixLength)
+		}
+		name = rest
+	}
+	if len(name) > MaxLabelKeyNameLength || !labelKeyNamePattern.MatchString(name) {
+		return fmt.Errorf("label key names must be at most %d alphanumeric, '-', '_' or '.' characters starting and ending with an alphanumeric character", MaxLabelKeyNameLength)
+	}
+	return nil
+}
+
+// ValidateLabelValue checks the syntax of a label value, which may be empty
+func ValidateLabelValue(value string) error {
+	if value != "" && (len(value) > MaxLabelValueLength || !labelPattern.MatchString(value)) {
+		return fmt.Errorf("label values must be empty or at most %d alphanumeric, '-', '_', '.' or '/' characters starting and ending with an alphanumeric character", MaxLabelValueLength)
+	}
+	return nil
+}
+
+// validateLabels checks the labels of an agent, prompt or tool
+func validateLabels(errs *ValidationError, path FieldPath, labels map[string]string) {
+	if len(labels) > MaxLabels {
+		errs.Reject(path, ConstraintMaxProperties, "must contain at most %d labels", MaxLabels)
+		return
+	}
+	keys := make([]string, 0, len(labels))
+	size := 0
+	for key, value := range labels {
+		keys = append(keys, key)
+		size += len(key) + len(value)
+	}
+	if size > MaxLabelsSize {
+		errs.Reject(path, ConstraintMaxSize, "keys and values must total at most %d characters", MaxLabelsSize)
+		return
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		// Keys such as example.com/team are single members, the pointer escapes their '/'
+		if err := ValidateLabelKey(key); err != nil {
+			errs.Reject(path.Key(key), ConstraintPattern, "%s", err.Error())
+		}
+		if err := ValidateLabelValue(labels[key]); err != nil {
+			errs.Reject(path.Key(key), ConstraintPattern, "%s", err.Error())
+		}
+	}
+}
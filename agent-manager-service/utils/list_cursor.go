@@ -0,0 +1,53 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package utils
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/models"
+)
+
+// EncodeListCursor encodes a list cursor into an opaque URL-safe string
+func EncodeListCursor(cursor models.ListCursor) (string, error) {
+	data, err := json.Marshal(cursor)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode cursor: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// DecodeListCursor decodes an opaque list cursor, which must have been issued for the given sort and order
+func DecodeListCursor(value string, sort string, order string) (*models.ListCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("malformed cursor")
+	}
+	var cursor models.ListCursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, fmt.Errorf("malformed cursor")
+	}
+	if cursor.Sort != sort || cursor.Order != order {
+		return nil, fmt.Errorf("cursor was issued for another sort")
+	}
+	if (cursor.Sort == models.ListSortName) == (cursor.Time != nil) {
+		return nil, fmt.Errorf("malformed cursor")
+	}
+	return &cursor, nil
+}
@@ -0,0 +1,74 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package utils
+
+import (
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/models"
+)
+
+func TestListCursorRoundTrip(t *testing.T) {
+	createdAt := time.Date(2025, 3, 4, 5, 6, 7, 123456000, time.UTC)
+	cursors := []models.ListCursor{
+		{Sort: models.ListSortName, Order: models.ListOrderAsc, Name: "support-agent", ID: uuid.New()},
+		{Sort: models.ListSortCreatedAt, Order: models.ListOrderDesc, Time: &createdAt, ID: uuid.New()},
+	}
+	for _, cursor := range cursors {
+		encoded, err := EncodeListCursor(cursor)
+		if err != nil {
+			t.Fatalf("EncodeListCursor(%+v) error = %v", cursor, err)
+		}
+		decoded, err := DecodeListCursor(encoded, cursor.Sort, cursor.Order)
+		if err != nil {
+			t.Fatalf("DecodeListCursor(%q) error = %v", encoded, err)
+		}
+		if decoded.Name != cursor.Name || decoded.ID != cursor.ID || (cursor.Time != nil && !decoded.Time.Equal(*cursor.Time)) {
+			t.Errorf("DecodeListCursor(%q) = %+v, want %+v", encoded, decoded, cursor)
+		}
+	}
+}
+
+func TestDecodeListCursorRejectsInvalidCursors(t *testing.T) {
+	byName, err := EncodeListCursor(models.ListCursor{Sort: models.ListSortName, Order: models.ListOrderAsc, Name: "a", ID: uuid.New()})
+	if err != nil {
+		t.Fatalf("EncodeListCursor() error = %v", err)
+	}
+	tests := []struct {
+		name   string
+		cursor string
+		sort   string
+		order  string
+	}{
+		{name: "not base64", cursor: "not a cursor!", sort: models.ListSortName, order: models.ListOrderAsc},
+		{name: "not JSON", cursor: base64.RawURLEncoding.EncodeToString([]byte("name")), sort: models.ListSortName, order: models.ListOrderAsc},
+		{name: "another sort", cursor: byName, sort: models.ListSortCreatedAt, order: models.ListOrderAsc},
+		{name: "another order", cursor: byName, sort: models.ListSortName, order: models.ListOrderDesc},
+		{name: "time sort without a time", cursor: base64.RawURLEncoding.EncodeToString([]byte(`{"sort":"updatedAt","order":"asc","name":"a"}`)), sort: models.ListSortUpdatedAt, order: models.ListOrderAsc},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := DecodeListCursor(tt.cursor, tt.sort, tt.order); err == nil {
+				t.Errorf("DecodeListCursor(%q) succeeded", tt.cursor)
+			}
+		})
+	}
+}
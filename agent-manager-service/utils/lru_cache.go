@@ -0,0 +1,98 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package utils
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// LRUCache holds at most a fixed number of entries, evicting the least recently used one when full
+// and entries not accessed within the TTL
+type LRUCache[K comparable, V any] struct {
+	mu         sync.Mutex
+	maxEntries int
+	ttl        time.Duration
+	now        func() time.Time
+	order      *list.List // front is the most recently used
+	entries    map[K]*list.Element
+}
+
+type lruEntry[K comparable, V any] struct {
+	key        K
+	value      V
+	accessedAt time.Time
+}
+
+// NewLRUCache returns a cache of maxEntries entries expiring ttl after their last access, now is the clock expiry is measured with
+func NewLRUCache[K comparable, V any](maxEntries int, ttl time.Duration, now func() time.Time) *LRUCache[K, V] {
+	if now == nil {
+		now = time.Now
+	}
+	return &LRUCache[K, V]{
+		maxEntries: maxEntries,
+		ttl:        ttl,
+		now:        now,
+		order:      list.New(),
+		entries:    make(map[K]*list.Element),
+	}
+}
+
+// GetOrAdd returns the live entry of key, storing the value of create when there is none
+func (c *LRUCache[K, V]) GetOrAdd(key K, create func() V) V {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	c.evictExpired(now)
+	if elem, ok := c.entries[key]; ok {
+		entry := elem.Value.(*lruEntry[K, V])
+		entry.accessedAt = now
+		c.order.MoveToFront(elem)
+		return entry.value
+	}
+
+	entry := &lruEntry[K, V]{key: key, value: create(), accessedAt: now}
+	c.entries[key] = c.order.PushFront(entry)
+	for c.order.Len() > c.maxEntries {
+		c.remove(c.order.Back())
+	}
+	return entry.value
+}
+
+// Len returns the number of entries held, including expired ones not evicted yet
+func (c *LRUCache[K, V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// evictExpired drops entries from the least recently used end until one is still live
+func (c *LRUCache[K, V]) evictExpired(now time.Time) {
+	for elem := c.order.Back(); elem != nil; elem = c.order.Back() {
+		if now.Sub(elem.Value.(*lruEntry[K, V]).accessedAt) < c.ttl {
+			return
+		}
+		c.remove(elem)
+	}
+}
+
+func (c *LRUCache[K, V]) remove(elem *list.Element) {
+	c.order.Remove(elem)
+	delete(c.entries, elem.Value.(*lruEntry[K, V]).key)
+}
@@ -17,6 +17,15 @@
 package utils
 
 import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/clients/traceobserversvc"
 	"github.com/wso2/ai-agent-management-platform/agent-manager-service/models"
 	"github.com/wso2/ai-agent-management-platform/agent-manager-service/spec"
 )
@@ -397,3 +406,556 @@ func ConvertToDataPlaneListResponse(dataPlanes []*models.DataPlaneResponse) []sp
 
 	return responses
 }
+
+func ConvertToManagedAgentResponse(agent *models.ManagedAgent) models.ManagedAgentResponse {
+	tools := agent.Tools
+	if tools == nil {
+		tools = []models.ToolReference{}
+	}
+	return models.ManagedAgentResponse{
+		ID:                  agent.ID.String(),
+		Name:                agent.Name,
+		Description:         agent.Description,
+		Framework:           agent.Framework,
+		ModelConfig:         agent.ModelConfig,
+		SystemPrompt:        agent.SystemPrompt,
+		PromptRef:           ConvertToPromptReference(agent.PromptID, agent.PromptVersion),
+		Tools:               tools,
+		Labels:              responseLabels(agent.Labels),
+		Enabled:             agent.Enabled,
+		Version:             agent.Version,
+		CreatedAt:           agent.CreatedAt,
+		UpdatedAt:           agent.UpdatedAt,
+		DeletedAt:           deletedAt(agent.DeletedAt),
+		ConfigSchemaVersion: agent.ConfigSchemaVersion,
+		RetentionDays:       agent.RetentionDays,
+	}
+}
+
+// deletedAt returns when a soft deleted record was deleted, nil for records that are not
+func deletedAt(deleted gorm.DeletedAt) *time.Time {
+	if !deleted.Valid {
+		return nil
+	}
+	at := deleted.Time
+	return &at
+}
+
+// ConvertToAgentFrameworkListResponse lists the supported frameworks with the versions of their schemas
+func ConvertToAgentFrameworkListResponse() models.AgentFrameworkListResponse {
+	frameworks := make([]models.AgentFrameworkResponse, 0, len(SupportedAgentFrameworks))
+	for _, framework := range SupportedAgentFrameworks {
+		schemas := AgentFrameworkSchemas(string(framework))
+		response := models.AgentFrameworkResponse{
+			Name:    string(framework),
+			Schemas: make([]models.AgentFrameworkSchemaResponse, 0, len(schemas)),
+		}
+		for _, schema := range schemas {
+			response.Schemas = append(response.Schemas, models.AgentFrameworkSchemaResponse{Version: schema.Version, Schema: schema.Schema})
+			response.LatestSchemaVersion = schema.Version
+		}
+		frameworks = append(frameworks, response)
+	}
+	return models.AgentFrameworkListResponse{Frameworks: frameworks}
+}
+
+func ConvertToManagedAgentListResponse(agents []*models.ManagedAgent) []models.ManagedAgentResponse {
+	responses := make([]models.ManagedAgentResponse, 0, len(agents))
+	for _, agent := range agents {
+		responses = append(responses, ConvertToManagedAgentResponse(agent))
+	}
+	return responses
+}
+
+func ConvertToManagedAgentVersionSummary(version *models.ManagedAgentVersion) models.ManagedAgentVersionSummary {
+	summary := models.ManagedAgentVersionSummary{
+		Version:        version.Version,
+		RolledBackFrom: version.RolledBackFrom,
+		CreatedAt:      version.CreatedAt,
+	}
+	if version.CreatedBy != nil {
+		summary.Author = version.CreatedBy.String()
+	}
+	return summary
+}
+
+func ConvertToManagedAgentVersionResponse(version *models.ManagedAgentVersion) models.ManagedAgentVersionResponse {
+	return models.ManagedAgentVersionResponse{
+		ManagedAgentVersionSummary: ConvertToManagedAgentVersionSummary(version),
+		AgentID:                    version.AgentID.String(),
+		Config:                     ManagedAgentVersionConfig(version),
+	}
+}
+
+// ManagedAgentVersionConfig returns the configuration recorded in a version in its request form
+func ManagedAgentVersionConfig(version *models.ManagedAgentVersion) models.ManagedAgentRequest {
+	enabled := version.Enabled
+	return models.ManagedAgentRequest{
+		Name:                version.Name,
+		Description:         version.Description,
+		Framework:           version.Framework,
+		ModelConfig:         version.ModelConfig,
+		SystemPrompt:        version.SystemPrompt,
+		PromptRef:           ConvertToPromptReference(version.PromptID, version.PromptVersion),
+		Tools:               version.Tools,
+		Labels:              version.Labels,
+		Enabled:             &enabled,
+		ConfigSchemaVersion: version.ConfigSchemaVersion,
+		AllowUnvalidated:    version.ConfigSchemaVersion == nil,
+	}
+}
+
+// ManagedAgentConfig returns the current configuration of an agent in its request form
+func ManagedAgentConfig(agent *models.ManagedAgent) models.ManagedAgentRequest {
+	enabled := agent.Enabled
+	return models.ManagedAgentRequest{
+		Name:                agent.Name,
+		Description:         agent.Description,
+		Framework:           agent.Framework,
+		ModelConfig:         agent.ModelConfig,
+		SystemPrompt:        agent.SystemPrompt,
+		PromptRef:           ConvertToPromptReference(agent.PromptID, agent.PromptVersion),
+		Tools:               agent.Tools,
+		Labels:              agent.Labels,
+		Enabled:             &enabled,
+		ConfigSchemaVersion: agent.ConfigSchemaVersion,
+		AllowUnvalidated:    agent.ConfigSchemaVersion == nil,
+	}
+}
+
+// ConvertToPromptReference returns the prompt reference stored in the prompt columns of an agent, nil when it has none
+func ConvertToPromptReference(promptId *uuid.UUID, promptVersion *int32) *models.PromptReference {
+	if promptId == nil || promptVersion == nil {
+		return nil
+	}
+	return &models.PromptReference{
+		PromptID: promptId.String(),
+		Version:  *promptVersion,
+	}
+}
+
+// PromptTemplateConfig returns the current template of a prompt in its request form
+func PromptTemplateConfig(prompt *models.PromptTemplate) models.PromptTemplateRequest {
+	return models.PromptTemplateRequest{
+		Name:        prompt.Name,
+		Description: prompt.Description,
+		Template:    prompt.Template,
+		Variables:   prompt.Variables,
+		Labels:      prompt.Labels,
+	}
+}
+
+func ConvertToPromptTemplateResponse(prompt *models.PromptTemplate) models.PromptTemplateResponse {
+	return models.PromptTemplateResponse{
+		ID:          prompt.ID.String(),
+		Name:        prompt.Name,
+		Description: prompt.Description,
+		Template:    prompt.Template,
+		Variables:   promptVariables(prompt.Variables),
+		Labels:      responseLabels(prompt.Labels),
+		Version:     prompt.Version,
+		CreatedAt:   prompt.CreatedAt,
+		UpdatedAt:   prompt.UpdatedAt,
+		DeletedAt:   deletedAt(prompt.DeletedAt),
+	}
+}
+
+func ConvertToPromptTemplateListResponse(prompts []*models.PromptTemplate) []models.PromptTemplateResponse {
+	responses := make([]models.PromptTemplateResponse, 0, len(prompts))
+	for _, prompt := range prompts {
+		responses = append(responses, ConvertToPromptTemplateResponse(prompt))
+	}
+	return responses
+}
+
+func ConvertToPromptTemplateVersionResponse(version *models.PromptTemplateVersion) models.PromptTemplateVersionResponse {
+	response := models.PromptTemplateVersionResponse{
+		PromptID:    version.PromptID.String(),
+		Version:     version.Version,
+		Description: version.Description,
+		Template:    version.Template,
+		Variables:   promptVariables(version.Variables),
+		CreatedAt:   version.CreatedAt,
+	}
+	if version.CreatedBy != nil {
+		response.Author = version.CreatedBy.String()
+	}
+	return response
+}
+
+func promptVariables(variables []models.PromptVariable) []models.PromptVariable {
+	if variables == nil {
+		return []models.PromptVariable{}
+	}
+	return variables
+}
+
+func ConvertToManagedAgentLabelsResponse(agent *models.ManagedAgent) models.ManagedAgentLabelsResponse {
+	return models.ManagedAgentLabelsResponse{
+		AgentID:       agent.ID.String(),
+		OrgID:         agent.OrgID.String(),
+		Labels:        responseLabels(agent.Labels),
+		RetentionDays: agent.RetentionDays,
+	}
+}
+
+func ConvertToManagedAgentLabelsListResponse(agents []*models.ManagedAgent) models.ManagedAgentLabelsListResponse {
+	response := models.ManagedAgentLabelsListResponse{Agents: make([]models.ManagedAgentLabelsResponse, 0, len(agents))}
+	for _, agent := range agents {
+		response.Agents = append(response.Agents, ConvertToManagedAgentLabelsResponse(agent))
+	}
+	return response
+}
+
+// responseLabels lists missing labels as an empty object
+func responseLabels(labels map[string]string) map[string]string {
+	if labels == nil {
+		return map[string]string{}
+	}
+	return labels
+}
+
+// ToolConfig returns the current definition of a tool in its request form
+func ToolConfig(tool *models.Tool) models.ToolRequest {
+	return models.ToolRequest{
+		Name:        tool.Name,
+		Description: tool.Description,
+		Parameters:  tool.Parameters,
+		Endpoint:    tool.Endpoint,
+		Tags:        tool.Tags,
+		Labels:      tool.Labels,
+	}
+}
+
+func ConvertToToolResponse(tool *models.Tool) models.ToolResponse {
+	tags := tool.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+	return models.ToolResponse{
+		ID:          tool.ID.String(),
+		Name:        tool.Name,
+		Description: tool.Description,
+		Parameters:  tool.Parameters,
+		Endpoint:    tool.Endpoint,
+		Tags:        tags,
+		Labels:      responseLabels(tool.Labels),
+		Version:     tool.Version,
+		CreatedAt:   tool.CreatedAt,
+		UpdatedAt:   tool.UpdatedAt,
+	}
+}
+
+func ConvertToToolListResponse(tools []*models.Tool) []models.ToolResponse {
+	responses := make([]models.ToolResponse, 0, len(tools))
+	for _, tool := range tools {
+		responses = append(responses, ConvertToToolResponse(tool))
+	}
+	return responses
+}
+
+// ConvertToAgentToolDefinition returns a registered tool as the traces observer reports tool definitions,
+// with the parameters schema encoded as a JSON string
+func ConvertToAgentToolDefinition(tool *models.Tool) (models.AgentToolDefinition, error) {
+	parameters, err := json.Marshal(tool.Parameters)
+	if err != nil {
+		return models.AgentToolDefinition{}, fmt.Errorf("failed to encode parameters of tool %s: %w", tool.ID, err)
+	}
+	return models.AgentToolDefinition{
+		ToolDefinition: traceobserversvc.ToolDefinition{
+			Name:        tool.Name,
+			Description: tool.Description,
+			Parameters:  string(parameters),
+		},
+		ToolID: tool.ID.String(),
+		Tags:   tool.Tags,
+	}, nil
+}
+
+func ConvertToAPIKeyResponse(apiKey *models.APIKey) models.APIKeyResponse {
+	scopes := apiKey.Scopes
+	if scopes == nil {
+		scopes = []string{}
+	}
+	return models.APIKeyResponse{
+		ID:         apiKey.ID.String(),
+		Name:       apiKey.Name,
+		Prefix:     apiKey.KeyPrefix,
+		Scopes:     scopes,
+		LastUsedAt: apiKey.LastUsedAt,
+		ExpiresAt:  apiKey.ExpiresAt,
+		RateLimit:  apiKey.RateLimit,
+		CreatedAt:  apiKey.CreatedAt,
+	}
+}
+
+func ConvertToAPIKeyListResponse(apiKeys []*models.APIKey) []models.APIKeyResponse {
+	responses := make([]models.APIKeyResponse, 0, len(apiKeys))
+	for _, apiKey := range apiKeys {
+		responses = append(responses, ConvertToAPIKeyResponse(apiKey))
+	}
+	return responses
+}
+
+func ConvertToCredentialResponse(credential *models.Credential) models.CredentialResponse {
+	return models.CredentialResponse{
+		ID:          credential.ID.String(),
+		Name:        credential.Name,
+		Description: credential.Description,
+		Preview:     credential.Preview,
+		KeyID:       credential.KeyID,
+		CreatedAt:   credential.CreatedAt,
+		UpdatedAt:   credential.UpdatedAt,
+	}
+}
+
+func ConvertToCredentialListResponse(credentials []*models.Credential) []models.CredentialResponse {
+	responses := make([]models.CredentialResponse, 0, len(credentials))
+	for _, credential := range credentials {
+		responses = append(responses, ConvertToCredentialResponse(credential))
+	}
+	return responses
+}
+
+// WebhookConfig returns the current subscription of a webhook in its request form, without its secret
+func WebhookConfig(webhook *models.Webhook) models.WebhookRequest {
+	active := webhook.Active
+	return models.WebhookRequest{
+		URL:         webhook.URL,
+		Description: webhook.Description,
+		Events:      webhook.Events,
+		Active:      &active,
+	}
+}
+
+func ConvertToWebhookResponse(webhook *models.Webhook) models.WebhookResponse {
+	return models.WebhookResponse{
+		ID:          webhook.ID.String(),
+		URL:         webhook.URL,
+		Description: webhook.Description,
+		Events:      webhook.Events,
+		Active:      webhook.Active,
+		CreatedAt:   webhook.CreatedAt,
+		UpdatedAt:   webhook.UpdatedAt,
+	}
+}
+
+func ConvertToWebhookListResponse(webhooks []*models.Webhook) []models.WebhookResponse {
+	responses := make([]models.WebhookResponse, 0, len(webhooks))
+	for _, webhook := range webhooks {
+		responses = append(responses, ConvertToWebhookResponse(webhook))
+	}
+	return responses
+}
+
+func ConvertToWebhookDeliveryResponse(delivery *models.WebhookDelivery) models.WebhookDeliveryResponse {
+	response := models.WebhookDeliveryResponse{
+		ID:            delivery.ID.String(),
+		EventID:       delivery.EventID.String(),
+		EventType:     delivery.EventType,
+		Status:        delivery.Status,
+		Attempts:      delivery.Attempts,
+		NextAttemptAt: delivery.NextAttemptAt,
+		Payload:       json.RawMessage(delivery.Payload),
+		CreatedAt:     delivery.CreatedAt,
+		CompletedAt:   delivery.CompletedAt,
+	}
+	if response.Attempts == nil {
+		response.Attempts = []models.WebhookDeliveryAttempt{}
+	}
+	if delivery.RedeliveryOf != nil {
+		redeliveryOf := delivery.RedeliveryOf.String()
+		response.RedeliveryOf = &redeliveryOf
+	}
+	return response
+}
+
+func ConvertToWebhookDeliveryListResponse(deliveries []*models.WebhookDelivery) []models.WebhookDeliveryResponse {
+	responses := make([]models.WebhookDeliveryResponse, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		responses = append(responses, ConvertToWebhookDeliveryResponse(delivery))
+	}
+	return responses
+}
+
+func ConvertToAuditLogResponse(auditLog *models.AuditLog) models.AuditLogResponse {
+	response := models.AuditLogResponse{
+		ID:            auditLog.ID.String(),
+		Actor:         auditLog.ActorIdpId.String(),
+		Action:        auditLog.Action,
+		ResourceType:  auditLog.ResourceType,
+		ResourceID:    auditLog.ResourceID,
+		Route:         auditLog.Route,
+		StatusCode:    auditLog.StatusCode,
+		Changes:       auditLog.Changes,
+		CorrelationID: auditLog.CorrelationID,
+		Timestamp:     auditLog.CreatedAt,
+	}
+	if auditLog.APIKeyID != nil {
+		apiKeyId := auditLog.APIKeyID.String()
+		response.APIKeyID = &apiKeyId
+	}
+	return response
+}
+
+func ConvertToAuditLogListResponse(auditLogs []*models.AuditLog) []models.AuditLogResponse {
+	responses := make([]models.AuditLogResponse, 0, len(auditLogs))
+	for _, auditLog := range auditLogs {
+		responses = append(responses, ConvertToAuditLogResponse(auditLog))
+	}
+	return responses
+}
+
+func ConvertToAgentEnvironmentResponse(environment *models.AgentEnvironment) models.AgentEnvironmentResponse {
+	return models.AgentEnvironmentResponse{
+		Name:        environment.Name,
+		Description: environment.Description,
+		CreatedAt:   environment.CreatedAt,
+	}
+}
+
+func ConvertToAgentEnvironmentListResponse(environments []*models.AgentEnvironment) []models.AgentEnvironmentResponse {
+	responses := make([]models.AgentEnvironmentResponse, 0, len(environments))
+	for _, environment := range environments {
+		responses = append(responses, ConvertToAgentEnvironmentResponse(environment))
+	}
+	return responses
+}
+
+func ConvertToAgentDeploymentResponse(deployment *models.AgentDeployment) models.AgentDeploymentResponse {
+	response := models.AgentDeploymentResponse{
+		ID:          deployment.ID.String(),
+		AgentID:     deployment.AgentID.String(),
+		Environment: deployment.Environment,
+		Version:     deployment.Version,
+		DeployedAt:  deployment.DeployedAt,
+	}
+	if deployment.PromotedFrom != nil {
+		response.PromotedFrom = *deployment.PromotedFrom
+	}
+	if deployment.DeployedBy != nil {
+		response.DeployedBy = deployment.DeployedBy.String()
+	}
+	return response
+}
+
+func ConvertToAgentDeploymentListResponse(deployments []*models.AgentDeployment) []models.AgentDeploymentResponse {
+	responses := make([]models.AgentDeploymentResponse, 0, len(deployments))
+	for _, deployment := range deployments {
+		responses = append(responses, ConvertToAgentDeploymentResponse(deployment))
+	}
+	return responses
+}
+
+func ConvertToEnvironmentAgentListResponse(agents []*models.EnvironmentAgent) []models.EnvironmentAgentResponse {
+	responses := make([]models.EnvironmentAgentResponse, 0, len(agents))
+	for _, agent := range agents {
+		responses = append(responses, models.EnvironmentAgentResponse{
+			AgentID:    agent.AgentID.String(),
+			Name:       agent.AgentName,
+			Deployment: ConvertToAgentDeploymentResponse(&agent.AgentDeployment),
+		})
+	}
+	return responses
+}
+
+// ConvertToAgentBudgetResponse converts a budget, usage is added by the caller when it was read
+func ConvertToAgentBudgetResponse(budget *models.AgentBudget) models.AgentBudgetResponse {
+	response := models.AgentBudgetResponse{
+		AgentID:         budget.AgentID.String(),
+		Timezone:        budget.Timezone,
+		AlertThresholds: budget.AlertThresholds,
+		Enforcement:     budget.Enforcement,
+		CreatedAt:       budget.CreatedAt,
+		UpdatedAt:       budget.UpdatedAt,
+	}
+	if budget.DailyMaxCost != nil || budget.DailyMaxTokens != nil {
+		response.Daily = &models.BudgetLimits{MaxCost: budget.DailyMaxCost, MaxTokens: budget.DailyMaxTokens}
+	}
+	if budget.MonthlyMaxCost != nil || budget.MonthlyMaxTokens != nil {
+		response.Monthly = &models.BudgetLimits{MaxCost: budget.MonthlyMaxCost, MaxTokens: budget.MonthlyMaxTokens}
+	}
+	return response
+}
+
+func ConvertToEvaluationResponse(run *models.EvaluationRun) models.EvaluationResponse {
+	response := models.EvaluationResponse{
+		ID:        run.ID.String(),
+		Name:      run.Name,
+		Status:    run.Status,
+		Filter:    run.Filter,
+		Evaluator: run.Evaluator,
+		Progress: models.EvaluationProgress{
+			TotalTraces:     run.TotalTraces,
+			EvaluatedTraces: run.EvaluatedTraces,
+		},
+		Summary: models.EvaluationSummary{
+			Passed:  run.PassedTraces,
+			Failed:  run.FailedTraces,
+			Errored: run.ErroredTraces,
+		},
+		Error:       run.Error,
+		CreatedAt:   run.CreatedAt,
+		StartedAt:   run.StartedAt,
+		CompletedAt: run.CompletedAt,
+	}
+	if run.Status == EvaluationStatusCompleted {
+		response.Progress.Percent = 100
+	} else if run.TotalTraces > 0 {
+		response.Progress.Percent = math.Round(float64(run.EvaluatedTraces)/float64(run.TotalTraces)*10000) / 100
+	}
+	if scored := run.PassedTraces + run.FailedTraces; scored > 0 {
+		passRate := math.Round(float64(run.PassedTraces)/float64(scored)*10000) / 10000
+		averageScore := math.Round(run.ScoreSum/float64(scored)*10000) / 10000
+		response.Summary.PassRate, response.Summary.AverageScore = &passRate, &averageScore
+	}
+	return response
+}
+
+func ConvertToEvaluationListResponse(runs []*models.EvaluationRun) []models.EvaluationResponse {
+	responses := make([]models.EvaluationResponse, 0, len(runs))
+	for _, run := range runs {
+		responses = append(responses, ConvertToEvaluationResponse(run))
+	}
+	return responses
+}
+
+func ConvertToEvaluationResultListResponse(results []*models.EvaluationResult) []models.EvaluationResultResponse {
+	responses := make([]models.EvaluationResultResponse, 0, len(results))
+	for _, result := range results {
+		responses = append(responses, models.EvaluationResultResponse{
+			TraceID:     result.TraceID,
+			Score:       result.Score,
+			Passed:      result.Passed,
+			Reason:      result.Reason,
+			Error:       result.Error,
+			EvaluatedAt: result.EvaluatedAt,
+		})
+	}
+	return responses
+}
+
+func ConvertToTraceViewResponse(view *models.TraceView) models.TraceViewResponse {
+	filters := view.Filters
+	if filters == nil {
+		filters = map[string]string{}
+	}
+	return models.TraceViewResponse{
+		ID:         view.ID.String(),
+		Name:       view.Name,
+		Visibility: view.Visibility,
+		Filters:    filters,
+		Sort:       view.Sort,
+		SortOrder:  view.SortOrder,
+		CreatedBy:  view.CreatedBy.String(),
+		CreatedAt:  view.CreatedAt,
+	}
+}
+
+func ConvertToTraceViewListResponse(views []*models.TraceView) []models.TraceViewResponse {
+	responses := make([]models.TraceViewResponse, 0, len(views))
+	for _, view := range views {
+		responses = append(responses, ConvertToTraceViewResponse(view))
+	}
+	return responses
+}
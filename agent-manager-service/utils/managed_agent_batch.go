@@ -0,0 +1,151 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package utils
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/models"
+)
+
+const (
+	BatchActionEnable       = "enable"
+	BatchActionDisable      = "disable"
+	BatchActionAddLabels    = "addLabels"
+	BatchActionRemoveLabels = "removeLabels"
+	BatchActionSetModel     = "setModel"
+	BatchActionDelete       = "delete"
+
+	BatchStatusSucceeded = "succeeded"
+	BatchStatusFailed    = "failed"
+)
+
+// ValidateAgentBatchRequest validates a batch of at most maxOperations operations and returns the agent id of
+// each operation. An agent can only be changed by a single operation of a batch, as the operations run concurrently
+func ValidateAgentBatchRequest(req models.AgentBatchRequest, maxOperations int) ([]uuid.UUID, error) {
+	errs := &ValidationError{}
+	switch {
+	case len(req.Operations) == 0:
+		errs.Reject(Field("operations"), ConstraintRequired, "must contain at least one operation")
+	case len(req.Operations) > maxOperations:
+		errs.Reject(Field("operations"), ConstraintMaxItems, "must contain at most %d operations", maxOperations)
+	}
+	if err := errs.OrNil(); err != nil {
+		return nil, err
+	}
+
+	agentIds := make([]uuid.UUID, len(req.Operations))
+	seen := make(map[uuid.UUID]int, len(req.Operations))
+	for i, op := range req.Operations {
+		path := Field("operations").Index(i)
+		agentId, err := uuid.Parse(op.AgentID)
+		if err != nil {
+			errs.Reject(path.Key("agentId"), ConstraintFormat, "must be a valid agent id")
+		} else if first, ok := seen[agentId]; ok {
+			errs.Reject(path.Key("agentId"), ConstraintUniqueItems, "agent is already changed by operations[%d]", first)
+		} else {
+			seen[agentId] = i
+			agentIds[i] = agentId
+		}
+		validateBatchOperation(errs, path, op)
+	}
+	if err := errs.OrNil(); err != nil {
+		return nil, err
+	}
+	return agentIds, nil
+}
+
+func validateBatchOperation(errs *ValidationError, path FieldPath, op models.AgentBatchOperation) {
+	switch op.Action {
+	case BatchActionEnable, BatchActionDisable, BatchActionDelete:
+	case BatchActionAddLabels:
+		if len(op.Labels) == 0 {
+			errs.Reject(path.Key("labels"), ConstraintRequired, "must contain at least one label")
+		}
+		validateLabels(errs, path.Key("labels"), op.Labels)
+	case BatchActionRemoveLabels:
+		if len(op.LabelKeys) == 0 {
+			errs.Reject(path.Key("labelKeys"), ConstraintRequired, "must contain at least one label key")
+		}
+	case BatchActionSetModel:
+		if len(op.ModelConfig) == 0 {
+			errs.Reject(path.Key("modelConfig"), ConstraintRequired, "must contain at least one model setting")
+		}
+	default:
+		errs.Reject(path.Key("action"), ConstraintEnum, "must be one of %s, %s, %s, %s, %s or %s", BatchActionEnable, BatchActionDisable,
+			BatchActionAddLabels, BatchActionRemoveLabels, BatchActionSetModel, BatchActionDelete)
+		return
+	}
+	if op.Labels != nil && op.Action != BatchActionAddLabels {
+		errs.Reject(path.Key("labels"), ConstraintConflict, "only applies to %s", BatchActionAddLabels)
+	}
+	if op.LabelKeys != nil && op.Action != BatchActionRemoveLabels {
+		errs.Reject(path.Key("labelKeys"), ConstraintConflict, "only applies to %s", BatchActionRemoveLabels)
+	}
+	if op.ModelConfig != nil && op.Action != BatchActionSetModel {
+		errs.Reject(path.Key("modelConfig"), ConstraintConflict, "only applies to %s", BatchActionSetModel)
+	}
+}
+
+// ApplyAgentBatchOperation returns the configuration an update operation of a batch turns config into, config
+// itself is left as is. The result is validated like any other agent configuration
+func ApplyAgentBatchOperation(config models.ManagedAgentRequest, op models.AgentBatchOperation) (models.ManagedAgentRequest, error) {
+	switch op.Action {
+	case BatchActionEnable, BatchActionDisable:
+		enabled := op.Action == BatchActionEnable
+		config.Enabled = &enabled
+	case BatchActionAddLabels:
+		labels := make(map[string]string, len(config.Labels)+len(op.Labels))
+		for key, value := range config.Labels {
+			labels[key] = value
+		}
+		for key, value := range op.Labels {
+			labels[key] = value
+		}
+		config.Labels = labels
+	case BatchActionRemoveLabels:
+		labels := make(map[string]string, len(config.Labels))
+		for key, value := range config.Labels {
+			labels[key] = value
+		}
+		for _, key := range op.LabelKeys {
+			delete(labels, key)
+		}
+		config.Labels = labels
+	case BatchActionSetModel:
+		modelConfig := make(map[string]interface{}, len(config.ModelConfig)+len(op.ModelConfig))
+		for key, value := range config.ModelConfig {
+			modelConfig[key] = value
+		}
+		for key, value := range op.ModelConfig {
+			if value == nil {
+				delete(modelConfig, key)
+			} else {
+				modelConfig[key] = value
+			}
+		}
+		config.ModelConfig = modelConfig
+	default:
+		return config, fmt.Errorf("%s is not an update operation", op.Action)
+	}
+	if err := ValidateManagedAgentRequest(config); err != nil {
+		return config, err
+	}
+	return config, nil
+}
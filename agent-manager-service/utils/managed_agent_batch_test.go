@@ -0,0 +1,100 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package utils
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/models"
+)
+
+func TestApplyAgentBatchOperation(t *testing.T) {
+	base := func() models.ManagedAgentRequest {
+		enabled := true
+		return models.ManagedAgentRequest{
+			Name:        "support",
+			Framework:   "crewai",
+			ModelConfig: map[string]interface{}{"provider": "openai", "model": "gpt-4o", "temperature": 0.2},
+			Labels:      map[string]string{"team": "support", "env": "dev"},
+			Enabled:     &enabled,
+		}
+	}
+	tests := []struct {
+		name      string
+		op        models.AgentBatchOperation
+		wantErr   bool
+		check     func(config models.ManagedAgentRequest) bool
+		checkDesc string
+	}{
+		{
+			name:      "disable",
+			op:        models.AgentBatchOperation{Action: BatchActionDisable},
+			check:     func(c models.ManagedAgentRequest) bool { return c.Enabled != nil && !*c.Enabled },
+			checkDesc: "enabled = false",
+		},
+		{
+			name: "add labels overwrites existing keys",
+			op:   models.AgentBatchOperation{Action: BatchActionAddLabels, Labels: map[string]string{"env": "prod", "tier": "1"}},
+			check: func(c models.ManagedAgentRequest) bool {
+				return reflect.DeepEqual(c.Labels, map[string]string{"team": "support", "env": "prod", "tier": "1"})
+			},
+			checkDesc: "labels merged",
+		},
+		{
+			name: "remove labels ignores missing keys",
+			op:   models.AgentBatchOperation{Action: BatchActionRemoveLabels, LabelKeys: []string{"env", "missing"}},
+			check: func(c models.ManagedAgentRequest) bool {
+				return reflect.DeepEqual(c.Labels, map[string]string{"team": "support"})
+			},
+			checkDesc: "env removed",
+		},
+		{
+			name: "set model merges settings and removes nulls",
+			op:   models.AgentBatchOperation{Action: BatchActionSetModel, ModelConfig: map[string]interface{}{"model": "gpt-4o-mini", "temperature": nil}},
+			check: func(c models.ManagedAgentRequest) bool {
+				return reflect.DeepEqual(c.ModelConfig, map[string]interface{}{"provider": "openai", "model": "gpt-4o-mini"})
+			},
+			checkDesc: "model replaced and temperature removed",
+		},
+		{
+			name:    "set model leaving the configuration invalid",
+			op:      models.AgentBatchOperation{Action: BatchActionSetModel, ModelConfig: map[string]interface{}{"temperature": 5}},
+			wantErr: true,
+		},
+		{
+			name:    "delete is not an update",
+			op:      models.AgentBatchOperation{Action: BatchActionDelete},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := base()
+			got, err := ApplyAgentBatchOperation(config, tt.op)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(config, base()) {
+				t.Errorf("the given configuration was modified: %+v", config)
+			}
+			if tt.check != nil && !tt.check(got) {
+				t.Errorf("want %s, got %+v", tt.checkDesc, got)
+			}
+		})
+	}
+}
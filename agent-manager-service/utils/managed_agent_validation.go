@@ -0,0 +1,191 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package utils
+
+import (
+	"math"
+	"regexp"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/models"
+)
+
+// ValidateManagedAgentRequest validates a managed agent create or update payload and reports every rejected field
+func ValidateManagedAgentRequest(payload models.ManagedAgentRequest) error {
+	errs := &ValidationError{}
+
+	validateResourceName(errs, Field("name"), payload.Name, "agent")
+	if len(payload.Description) > MaxAgentDescriptionLength {
+		errs.Reject(Field("description"), ConstraintMaxLength, "must be at most %d characters", MaxAgentDescriptionLength)
+	}
+	if len(payload.SystemPrompt) > MaxSystemPromptLength {
+		errs.Reject(Field("systemPrompt"), ConstraintMaxLength, "must be at most %d characters", MaxSystemPromptLength)
+	}
+	validatePromptReference(errs, payload)
+	validateFramework(errs, payload)
+	validateModelConfig(errs, Field("modelConfig"), payload.ModelConfig)
+	validateToolReferences(errs, payload.Tools)
+	validateLabels(errs, Field("labels"), payload.Labels)
+	if days := payload.RetentionDays; days != nil {
+		switch {
+		case *days < 0:
+			errs.Reject(Field("retentionDays"), ConstraintMinimum, "must be between 1 and %d days, or 0 to use the default retention", MaxAgentRetentionDays)
+		case *days > MaxAgentRetentionDays:
+			errs.Reject(Field("retentionDays"), ConstraintMaximum, "must be between 1 and %d days, or 0 to use the default retention", MaxAgentRetentionDays)
+		}
+	}
+
+	return errs.OrNil()
+}
+
+func validatePromptReference(errs *ValidationError, payload models.ManagedAgentRequest) {
+	if payload.PromptRef == nil {
+		return
+	}
+	path := Field("promptRef")
+	if payload.SystemPrompt != "" {
+		errs.Reject(path, ConstraintConflict, "systemPrompt and promptRef cannot both be set")
+	}
+	if _, err := uuid.Parse(payload.PromptRef.PromptID); err != nil {
+		errs.Reject(path.Key("promptId"), ConstraintFormat, "must be a prompt id")
+	}
+	if payload.PromptRef.Version < 1 {
+		errs.Reject(path.Key("version"), ConstraintMinimum, "must be a positive version number")
+	}
+}
+
+// Names of frameworks stored without validation
+var frameworkNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]*$`)
+
+func validateFramework(errs *ValidationError, payload models.ManagedAgentRequest) {
+	framework := payload.Framework
+	if framework == "" {
+		errs.Reject(Field("framework"), ConstraintRequired, "framework is required")
+		return
+	}
+	if payload.AllowUnvalidated {
+		switch {
+		case len(framework) > MaxFrameworkNameLength:
+			errs.Reject(Field("framework"), ConstraintMaxLength, "must be at most %d lowercase letters, digits, '.', '_' or '-'", MaxFrameworkNameLength)
+		case !frameworkNamePattern.MatchString(framework):
+			errs.Reject(Field("framework"), ConstraintPattern, "must be at most %d lowercase letters, digits, '.', '_' or '-'", MaxFrameworkNameLength)
+		}
+		if payload.ConfigSchemaVersion != nil {
+			errs.Reject(Field("configSchemaVersion"), ConstraintConflict, "cannot be set together with allowUnvalidated")
+		}
+		return
+	}
+	latest, ok := LatestAgentFrameworkSchema(framework)
+	if !ok {
+		names := make([]string, 0, len(SupportedAgentFrameworks))
+		for _, supported := range SupportedAgentFrameworks {
+			names = append(names, string(supported))
+		}
+		errs.Reject(Field("framework"), ConstraintEnum, "unknown framework %q, supported frameworks are %s, set allowUnvalidated to store the agent without validation",
+			framework, strings.Join(names, ", "))
+		return
+	}
+	if version := payload.ConfigSchemaVersion; version != nil {
+		if _, ok := GetAgentFrameworkSchema(framework, *version); !ok {
+			errs.Reject(Field("configSchemaVersion"), ConstraintEnum, "must be a schema version of framework %s between 1 and %d", framework, latest.Version)
+		}
+	}
+}
+
+// validateModelConfig checks the well-known model settings and leaves provider specific settings alone
+func validateModelConfig(errs *ValidationError, path FieldPath, config map[string]interface{}) {
+	if config == nil {
+		errs.Reject(path, ConstraintRequired, "modelConfig is required")
+		return
+	}
+	for _, key := range []string{"provider", "model"} {
+		value, ok := config[key]
+		text, isString := value.(string)
+		switch {
+		case !ok:
+			errs.Reject(path.Key(key), ConstraintRequired, "must be a non-empty string")
+		case !isString:
+			errs.Reject(path.Key(key), ConstraintType, "must be a non-empty string")
+		case strings.TrimSpace(text) == "":
+			errs.Reject(path.Key(key), ConstraintMinLength, "must be a non-empty string")
+		}
+	}
+	if value, ok := config["temperature"]; ok {
+		if constraint := numberConstraint(value, 0, MaxModelTemperature); constraint != "" {
+			errs.Reject(path.Key("temperature"), constraint, "must be a number between 0 and %d", MaxModelTemperature)
+		}
+	}
+	if value, ok := config["topP"]; ok {
+		if constraint := numberConstraint(value, 0, MaxModelTopP); constraint != "" {
+			errs.Reject(path.Key("topP"), constraint, "must be a number between 0 and %d", MaxModelTopP)
+		}
+	}
+	if value, ok := config["maxTokens"]; ok {
+		constraint := numberConstraint(value, 1, math.MaxFloat64)
+		if number, _ := value.(float64); constraint == "" && number != math.Trunc(number) {
+			constraint = ConstraintType
+		}
+		if constraint != "" {
+			errs.Reject(path.Key("maxTokens"), constraint, "must be a positive integer")
+		}
+	}
+}
+
+// numberConstraint returns the constraint value fails as a number between minimum and maximum, empty when it passes
+func numberConstraint(value interface{}, minimum float64, maximum float64) string {
+	number, ok := value.(float64)
+	switch {
+	case !ok:
+		return ConstraintType
+	case number < minimum:
+		return ConstraintMinimum
+	case number > maximum:
+		return ConstraintMaximum
+	}
+	return ""
+}
+
+func validateToolReferences(errs *ValidationError, tools []models.ToolReference) {
+	if len(tools) > MaxAgentTools {
+		errs.Reject(Field("tools"), ConstraintMaxItems, "must contain at most %d tools", MaxAgentTools)
+		return
+	}
+	seenNames := make(map[string]bool, len(tools))
+	seenIds := make(map[string]bool, len(tools))
+	for i, tool := range tools {
+		path := Field("tools").Index(i)
+		switch {
+		case tool.ToolID != "" && tool.Name != "":
+			errs.Reject(path, ConstraintConflict, "set either toolId for a registered tool or name for an ad hoc tool, not both")
+		case tool.ToolID != "":
+			if _, err := uuid.Parse(tool.ToolID); err != nil {
+				errs.Reject(path.Key("toolId"), ConstraintFormat, "must be a tool id")
+			} else if seenIds[tool.ToolID] {
+				errs.Reject(path.Key("toolId"), ConstraintUniqueItems, "duplicate tool %q", tool.ToolID)
+			}
+			seenIds[tool.ToolID] = true
+		case strings.TrimSpace(tool.Name) == "":
+			errs.Reject(path.Key("name"), ConstraintRequired, "tool name cannot be empty")
+		case seenNames[tool.Name]:
+			errs.Reject(path.Key("name"), ConstraintUniqueItems, "duplicate tool %q", tool.Name)
+		default:
+			seenNames[tool.Name] = true
+		}
+	}
+}
@@ -0,0 +1,116 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/models"
+)
+
+// validationErrors returns the field errors of err, failing the test when it is not a ValidationError
+func validationErrors(t *testing.T, err error) []FieldError {
+	t.Helper()
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("expected a validation error, got %v", err)
+	}
+	return validationErr.Errors
+}
+
+func TestValidateManagedAgentRequestErrors(t *testing.T) {
+	payload := models.ManagedAgentRequest{
+		SystemPrompt: "You are helpful",
+		PromptRef:    &models.PromptReference{PromptID: "support", Version: 0},
+		Framework:    "langgraph",
+		ModelConfig:  map[string]interface{}{"provider": "openai", "model": " ", "temperature": 3.5, "maxTokens": 1.5},
+		Tools:        []models.ToolReference{{ToolID: "search"}, {Name: "lookup"}, {Name: "lookup"}},
+		Labels:       map[string]string{"example.com/team": "-"},
+	}
+
+	want := []FieldError{
+		{Field: "name", Pointer: "/name", Constraint: ConstraintRequired, Message: "agent name cannot be empty"},
+		{Field: "promptRef", Pointer: "/promptRef", Constraint: ConstraintConflict, Message: "systemPrompt and promptRef cannot both be set"},
+		{Field: "promptRef.promptId", Pointer: "/promptRef/promptId", Constraint: ConstraintFormat, Message: "must be a prompt id"},
+		{Field: "promptRef.version", Pointer: "/promptRef/version", Constraint: ConstraintMinimum, Message: "must be a positive version number"},
+		{Field: "modelConfig.model", Pointer: "/modelConfig/model", Constraint: ConstraintMinLength, Message: "must be a non-empty string"},
+		{Field: "modelConfig.temperature", Pointer: "/modelConfig/temperature", Constraint: ConstraintMaximum, Message: "must be a number between 0 and 2"},
+		{Field: "modelConfig.maxTokens", Pointer: "/modelConfig/maxTokens", Constraint: ConstraintType, Message: "must be a positive integer"},
+		{Field: "tools[0].toolId", Pointer: "/tools/0/toolId", Constraint: ConstraintFormat, Message: "must be a tool id"},
+		{Field: "tools[2].name", Pointer: "/tools/2/name", Constraint: ConstraintUniqueItems, Message: `duplicate tool "lookup"`},
+		{
+			Field:      "labels.example.com/team",
+			Pointer:    "/labels/example.com~1team",
+			Constraint: ConstraintPattern,
+			Message: fmt.Sprintf("label values must be empty or at most %d alphanumeric, '-', '_', '.' or '/' characters "+
+				"starting and ending with an alphanumeric character", MaxLabelValueLength),
+		},
+	}
+	if got := validationErrors(t, ValidateManagedAgentRequest(payload)); !reflect.DeepEqual(got, want) {
+		t.Errorf("ValidateManagedAgentRequest() errors =\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+func TestValidateAgentModelConfigPointers(t *testing.T) {
+	payload := models.ManagedAgentRequest{Framework: "langgraph", ModelConfig: map[string]interface{}{
+		"provider": "openai", "temperature": 3.0, "stop": []interface{}{"END", float64(7)}, "temprature": 1.0,
+	}}
+	_, err := ValidateAgentModelConfig(payload, nil)
+	got := validationErrors(t, err)
+	// The schema library reports its errors in no particular order
+	sort.Slice(got, func(i, j int) bool { return got[i].Pointer < got[j].Pointer })
+
+	want := []FieldError{
+		{Field: "modelConfig.model", Pointer: "/modelConfig/model", Constraint: ConstraintRequired, Message: "is required"},
+		{Field: "modelConfig.stop[1]", Pointer: "/modelConfig/stop/1", Constraint: ConstraintType, Message: "got number, want string"},
+		{Field: "modelConfig.temperature", Pointer: "/modelConfig/temperature", Constraint: ConstraintMaximum, Message: "maximum: got 3, want 2"},
+		{
+			Field:      "modelConfig.temprature",
+			Pointer:    "/modelConfig/temprature",
+			Constraint: ConstraintAdditionalProperties,
+			Message:    "unknown setting of framework langgraph schema version 1",
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ValidateAgentModelConfig() errors =\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+func TestValidateToolParametersPointers(t *testing.T) {
+	errs := &ValidationError{}
+	validateToolParameters(errs, map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"city": map[string]interface{}{"type": "string"},
+			"days": map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "integer", "minimum": "one"}},
+		},
+	})
+
+	want := []FieldError{{
+		Field:      "parameters.properties.days.items.minimum",
+		Pointer:    "/parameters/properties/days/items/minimum",
+		Constraint: ConstraintType,
+		Message:    "got string, want number",
+	}}
+	if !reflect.DeepEqual(errs.Errors, want) {
+		t.Errorf("validateToolParameters() errors =\n%+v\nwant\n%+v", errs.Errors, want)
+	}
+}
@@ -0,0 +1,79 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ApplyMergePatch applies a JSON merge patch (RFC 7386) to the request form of a resource and decodes the result
+// into patched. Members of the patch replace those of the resource, objects are merged member by member, null
+// removes a member and arrays such as tools or tags are replaced as a whole. Values the patch gives the wrong type
+// are reported as a ValidationError naming the field
+func ApplyMergePatch(current any, patch map[string]interface{}, patched any) error {
+	body, err := json.Marshal(current)
+	if err != nil {
+		return fmt.Errorf("failed to serialize resource to patch: %w", err)
+	}
+	var document interface{}
+	if err := json.Unmarshal(body, &document); err != nil {
+		return fmt.Errorf("failed to decode resource to patch: %w", err)
+	}
+	merged, err := json.Marshal(mergePatch(document, patch))
+	if err != nil {
+		return fmt.Errorf("failed to serialize patched resource: %w", err)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(merged))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(patched); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) && typeErr.Field != "" {
+			return &ValidationError{Errors: []FieldError{{Field: typeErr.Field, Constraint: ConstraintType, Message: fmt.Sprintf("must be of type %s", typeErr.Type)}}}
+		}
+		// The decoder names unknown fields only in its message
+		if field, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+			return &ValidationError{Errors: []FieldError{{Field: strings.Trim(field, `"`), Constraint: ConstraintAdditionalProperties, Message: "is not a known field"}}}
+		}
+		return &ValidationError{Errors: []FieldError{{Field: "", Constraint: ConstraintType, Message: "must be a JSON object"}}}
+	}
+	return nil
+}
+
+// mergePatch merges patch into target as RFC 7386 describes, target is modified in place
+func mergePatch(target interface{}, patch interface{}) interface{} {
+	patchObject, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	targetObject, ok := target.(map[string]interface{})
+	if !ok {
+		targetObject = map[string]interface{}{}
+	}
+	for name, value := range patchObject {
+		if value == nil {
+			delete(targetObject, name)
+			continue
+		}
+		targetObject[name] = mergePatch(targetObject[name], value)
+	}
+	return targetObject
+}
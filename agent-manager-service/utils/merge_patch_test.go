@@ -0,0 +1,138 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/models"
+)
+
+func TestApplyMergePatch(t *testing.T) {
+	enabled := true
+	current := models.ManagedAgentRequest{
+		Name:      "support-agent",
+		Framework: "crewai",
+		ModelConfig: map[string]interface{}{
+			"provider":    "openai",
+			"model":       "gpt-4o",
+			"temperature": 0.2,
+			"retry":       map[string]interface{}{"attempts": 3, "backoff": "exponential"},
+		},
+		SystemPrompt: "You are a helpful support agent.",
+		Tools:        []models.ToolReference{{Name: "search_docs"}, {Name: "create_ticket"}},
+		Labels:       map[string]string{"team": "support", "env": "staging"},
+		Enabled:      &enabled,
+	}
+
+	tests := []struct {
+		name  string
+		patch string
+		check func(t *testing.T, patched models.ManagedAgentRequest)
+	}{
+		{
+			name:  "nested model config",
+			patch: `{"modelConfig":{"temperature":0.7,"retry":{"attempts":5,"backoff":null}}}`,
+			check: func(t *testing.T, patched models.ManagedAgentRequest) {
+				want := map[string]interface{}{
+					"provider":    "openai",
+					"model":       "gpt-4o",
+					"temperature": 0.7,
+					"retry":       map[string]interface{}{"attempts": float64(5)},
+				}
+				if !reflect.DeepEqual(patched.ModelConfig, want) {
+					t.Errorf("modelConfig = %v, want %v", patched.ModelConfig, want)
+				}
+				if patched.Name != "support-agent" || len(patched.Tools) != 2 {
+					t.Errorf("patched = %+v, want the members outside the patch kept", patched)
+				}
+			},
+		},
+		{
+			name:  "null removes a member",
+			patch: `{"systemPrompt":null,"labels":{"env":null,"tier":"gold"}}`,
+			check: func(t *testing.T, patched models.ManagedAgentRequest) {
+				if patched.SystemPrompt != "" {
+					t.Errorf("systemPrompt = %q, want it removed", patched.SystemPrompt)
+				}
+				if want := map[string]string{"team": "support", "tier": "gold"}; !reflect.DeepEqual(patched.Labels, want) {
+					t.Errorf("labels = %v, want %v", patched.Labels, want)
+				}
+			},
+		},
+		{
+			name:  "arrays are replaced",
+			patch: `{"tools":[{"name":"escalate"}]}`,
+			check: func(t *testing.T, patched models.ManagedAgentRequest) {
+				if want := []models.ToolReference{{Name: "escalate"}}; !reflect.DeepEqual(patched.Tools, want) {
+					t.Errorf("tools = %v, want %v", patched.Tools, want)
+				}
+			},
+		},
+		{
+			name:  "a non-object replaces an object",
+			patch: `{"modelConfig":{"retry":false}}`,
+			check: func(t *testing.T, patched models.ManagedAgentRequest) {
+				if patched.ModelConfig["retry"] != false {
+					t.Errorf("modelConfig.retry = %v, want false", patched.ModelConfig["retry"])
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var patch map[string]interface{}
+			if err := json.Unmarshal([]byte(tt.patch), &patch); err != nil {
+				t.Fatal(err)
+			}
+			var patched models.ManagedAgentRequest
+			if err := ApplyMergePatch(current, patch, &patched); err != nil {
+				t.Fatalf("ApplyMergePatch() error = %v", err)
+			}
+			tt.check(t, patched)
+		})
+	}
+
+	if current.ModelConfig["temperature"] != 0.2 || len(current.Labels) != 2 {
+		t.Errorf("current = %+v, want it left as it was", current)
+	}
+}
+
+func TestApplyMergePatchReportsRejectedFields(t *testing.T) {
+	tests := []struct {
+		patch string
+		want  FieldError
+	}{
+		{patch: `{"name":7}`, want: FieldError{Field: "name", Constraint: ConstraintType, Message: "must be of type string"}},
+		{patch: `{"owner":"me"}`, want: FieldError{Field: "owner", Constraint: ConstraintAdditionalProperties, Message: "is not a known field"}},
+	}
+	for _, tt := range tests {
+		var patch map[string]interface{}
+		if err := json.Unmarshal([]byte(tt.patch), &patch); err != nil {
+			t.Fatal(err)
+		}
+		var patched models.ManagedAgentRequest
+		err := ApplyMergePatch(models.ManagedAgentRequest{Name: "a"}, patch, &patched)
+		var validationErr *ValidationError
+		if !errors.As(err, &validationErr) || len(validationErr.Errors) != 1 || validationErr.Errors[0] != tt.want {
+			t.Errorf("ApplyMergePatch(%s) error = %v, want %v", tt.patch, err, tt.want)
+		}
+	}
+}
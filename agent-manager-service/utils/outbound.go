@@ -0,0 +1,113 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// ErrNonPublicTarget is returned for outbound requests to loopback, private, link-local or other non-public addresses
+var ErrNonPublicTarget = errors.New("target is not a public address")
+
+// Ranges that are not reachable on the public internet but are not covered by the netip predicates
+var nonPublicPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+}
+
+// IsPublicAddr reports whether addr is a public unicast address webhooks and evaluators may be called on
+func IsPublicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsGlobalUnicast() || addr.IsPrivate() {
+		return false
+	}
+	for _, prefix := range nonPublicPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// CheckOutboundURL rejects URLs whose host is localhost or a non-public IP address. Host names are checked
+// again when they are resolved, see NewOutboundHTTPClient
+func CheckOutboundURL(rawURL string) error {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+	host := strings.TrimSuffix(strings.ToLower(parsed.Hostname()), ".")
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return fmt.Errorf("%w: %s", ErrNonPublicTarget, host)
+	}
+	if addr, err := netip.ParseAddr(host); err == nil && !IsPublicAddr(addr) {
+		return fmt.Errorf("%w: %s", ErrNonPublicTarget, addr)
+	}
+	return nil
+}
+
+// NewOutboundHTTPClient returns the client webhooks and evaluators are called with. Unless allowPrivateNetworks
+// is set it refuses to connect to non-public addresses, checked after name resolution so a host name cannot be
+// rebound to an internal address after it was validated. Redirects are never followed
+func NewOutboundHTTPClient(timeout time.Duration, allowPrivateNetworks bool) *http.Client {
+	dialer := &net.Dialer{Timeout: timeout}
+	if !allowPrivateNetworks {
+		dialer.Control = func(network, address string, _ syscall.RawConn) error {
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil {
+				return err
+			}
+			if !IsPublicAddr(addrPort.Addr()) {
+				return fmt.Errorf("%w: %s", ErrNonPublicTarget, addrPort.Addr())
+			}
+			return nil
+		}
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// A proxy would be dialed instead of the target and bypass the address check
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// ValidateOutboundURL reports a URL rejected by CheckOutboundURL as a validation error of the field at path
+func ValidateOutboundURL(path FieldPath, rawURL string) error {
+	if err := CheckOutboundURL(rawURL); err != nil {
+		errs := &ValidationError{}
+		errs.Reject(path, ConstraintFormat, "must not point to localhost or a loopback, private or link-local address")
+		return errs
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package utils
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+	"time"
+)
+
+func TestIsPublicAddr(t *testing.T) {
+	cases := map[string]bool{
+		"93.184.216.34":          true,
+		"2606:2800:220:1::":      true,
+		"127.0.0.1":              false,
+		"10.1.2.3":               false,
+		"172.16.0.1":             false,
+		"192.168.1.1":            false,
+		"169.254.169.254":        false,
+		"100.64.0.1":             false,
+		"0.0.0.0":                false,
+		"255.255.255.255":        false,
+		"224.0.0.1":              false,
+		"::1":                    false,
+		"fe80::1":                false,
+		"fd00::1":                false,
+		"::ffff:127.0.0.1":       false,
+		"::ffff:169.254.169.254": false,
+		"64:ff9b::a00:1":         false,
+	}
+	for address, want := range cases {
+		if got := IsPublicAddr(netip.MustParseAddr(address)); got != want {
+			t.Errorf("IsPublicAddr(%s) = %v, want %v", address, got, want)
+		}
+	}
+}
+
+func TestCheckOutboundURL(t *testing.T) {
+	for _, rawURL := range []string{"https://hooks.example.com/amp", "http://93.184.216.34:8080/score"} {
+		if err := CheckOutboundURL(rawURL); err != nil {
+			t.Errorf("CheckOutboundURL(%s) = %v, want nil", rawURL, err)
+		}
+	}
+	for _, rawURL := range []string{
+		"http://127.0.0.1:8080/hook",
+		"http://10.0.0.5/hook",
+		"http://192.168.0.10/hook",
+		"http://169.254.169.254/latest/meta-data/",
+		"http://[::1]/hook",
+		"http://[::ffff:10.0.0.1]/hook",
+		"http://localhost:9000/hook",
+		"http://LOCALHOST./hook",
+		"http://api.localhost/hook",
+	} {
+		if err := CheckOutboundURL(rawURL); !errors.Is(err, ErrNonPublicTarget) {
+			t.Errorf("CheckOutboundURL(%s) = %v, want ErrNonPublicTarget", rawURL, err)
+		}
+	}
+}
+
+func TestValidateOutboundURL(t *testing.T) {
+	err := ValidateOutboundURL(Field("evaluator").Key("url"), "http://169.254.169.254/")
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) || len(validationErr.Errors) != 1 {
+		t.Fatalf("expected one validation error, got %v", err)
+	}
+	if got := validationErr.Errors[0]; got.Field != "evaluator.url" || got.Pointer != "/evaluator/url" || got.Constraint != ConstraintFormat {
+		t.Errorf("unexpected field error %+v", got)
+	}
+	if err := ValidateOutboundURL(Field("url"), "https://hooks.example.com"); err != nil {
+		t.Errorf("public URL rejected: %v", err)
+	}
+}
+
+func TestOutboundHTTPClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/redirect" {
+			http.Redirect(w, r, "/target", http.StatusFound)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	// The test server listens on loopback, which stands in for an internal service
+	_, err := NewOutboundHTTPClient(5*time.Second, false).Get(server.URL)
+	if !errors.Is(err, ErrNonPublicTarget) {
+		t.Fatalf("expected the loopback address to be refused at dial time, got %v", err)
+	}
+
+	client := NewOutboundHTTPClient(5*time.Second, true)
+	resp, err := client.Get(server.URL + "/redirect")
+	if err != nil {
+		t.Fatalf("request with private networks allowed failed: %v", err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusFound {
+		t.Errorf("redirect was followed, got status %d", resp.StatusCode)
+	}
+}
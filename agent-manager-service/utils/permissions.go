@@ -0,0 +1,142 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package utils
+
+// Permission is an action a caller may be authorized to perform
+type Permission string
+
+const (
+	PermissionOrgsRead      Permission = "orgs:read"
+	PermissionProjectsRead  Permission = "projects:read"
+	PermissionProjectsWrite Permission = "projects:write"
+	PermissionAgentsRead    Permission = "agents:read"
+	PermissionAgentsWrite   Permission = "agents:write"
+	PermissionPromptsRead   Permission = "prompts:read"
+	PermissionPromptsWrite  Permission = "prompts:write"
+	PermissionToolsRead     Permission = "tools:read"
+	PermissionToolsWrite    Permission = "tools:write"
+	PermissionTracesRead    Permission = "traces:read"
+	PermissionKeysManage    Permission = "keys:manage"
+	PermissionAuditRead     Permission = "audit:read"
+	// PermissionTrashManage lists and restores deleted resources
+	PermissionTrashManage Permission = "trash:manage"
+	// PermissionEnvironmentsManage creates the environments agents are deployed to
+	PermissionEnvironmentsManage Permission = "environments:manage"
+	// Credential secrets are never readable through the API, reading a credential returns its metadata only
+	PermissionCredentialsRead  Permission = "credentials:read"
+	PermissionCredentialsWrite Permission = "credentials:write"
+	// PermissionCredentialsRotate re-encrypts every credential with the active master key
+	PermissionCredentialsRotate Permission = "credentials:rotate"
+	// PermissionWebhooksManage subscribes URLs to agent events and inspects their deliveries
+	PermissionWebhooksManage Permission = "webhooks:manage"
+	// Evaluations score the stored traces of agents, runs with an http evaluator send trace inputs and outputs to it
+	PermissionEvaluationsRead  Permission = "evaluations:read"
+	PermissionEvaluationsWrite Permission = "evaluations:write"
+)
+
+// AllPermissions lists every permission a route may require
+var AllPermissions = []Permission{
+	PermissionOrgsRead,
+	PermissionProjectsRead,
+	PermissionProjectsWrite,
+	PermissionAgentsRead,
+	PermissionAgentsWrite,
+	PermissionPromptsRead,
+	PermissionPromptsWrite,
+	PermissionToolsRead,
+	PermissionToolsWrite,
+	PermissionTracesRead,
+	PermissionKeysManage,
+	PermissionAuditRead,
+	PermissionTrashManage,
+	PermissionEnvironmentsManage,
+	PermissionCredentialsRead,
+	PermissionCredentialsWrite,
+	PermissionCredentialsRotate,
+	PermissionWebhooksManage,
+	PermissionEvaluationsRead,
+	PermissionEvaluationsWrite,
+}
+
+// SupportedAPIKeyScopes lists the permissions an API key may be granted.
+// Keys cannot manage keys, otherwise a leaked key could outlive its revocation
+var SupportedAPIKeyScopes = []Permission{
+	PermissionOrgsRead,
+	PermissionProjectsRead,
+	PermissionProjectsWrite,
+	PermissionAgentsRead,
+	PermissionAgentsWrite,
+	PermissionPromptsRead,
+	PermissionPromptsWrite,
+	PermissionToolsRead,
+	PermissionToolsWrite,
+	PermissionTracesRead,
+	PermissionAuditRead,
+	PermissionTrashManage,
+	PermissionEnvironmentsManage,
+	PermissionCredentialsRead,
+	PermissionCredentialsWrite,
+	PermissionCredentialsRotate,
+	PermissionWebhooksManage,
+	PermissionEvaluationsRead,
+	PermissionEvaluationsWrite,
+}
+
+// Built in roles
+const (
+	RoleAdmin  = "admin"
+	RoleEditor = "editor"
+	RoleViewer = "viewer"
+)
+
+// DefaultRolePermissions returns the permissions of the built in roles
+func DefaultRolePermissions() map[string][]Permission {
+	viewer := []Permission{
+		PermissionOrgsRead,
+		PermissionProjectsRead,
+		PermissionAgentsRead,
+		PermissionPromptsRead,
+		PermissionToolsRead,
+		PermissionTracesRead,
+		PermissionCredentialsRead,
+		PermissionEvaluationsRead,
+	}
+	// Editors work on agents and their resources, creating and deleting projects is left to admins
+	editor := append(append([]Permission{}, viewer...),
+		PermissionAgentsWrite,
+		PermissionPromptsWrite,
+		PermissionToolsWrite,
+		PermissionKeysManage,
+		PermissionCredentialsWrite,
+		PermissionEvaluationsWrite,
+	)
+	return map[string][]Permission{
+		RoleAdmin:  append([]Permission{}, AllPermissions...),
+		RoleEditor: editor,
+		RoleViewer: viewer,
+	}
+}
+
+// IsKnownPermission reports whether permission is one of AllPermissions
+func IsKnownPermission(permission string) bool {
+	for _, known := range AllPermissions {
+		if string(known) == permission {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,177 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/middleware/correlation"
+)
+
+const ProblemContentType = "application/problem+json"
+
+// ProblemDetails is an RFC 7807 problem document
+type ProblemDetails struct {
+	Type     string       `json:"type"`
+	Title    string       `json:"title"`
+	Status   int          `json:"status"`
+	Detail   string       `json:"detail,omitempty"`
+	Instance string       `json:"instance,omitempty"`
+	Errors   []FieldError `json:"errors,omitempty"`
+	// Resources that block the operation, e.g. agents referencing a prompt that is being deleted
+	Dependents []DependentResource `json:"dependents,omitempty"`
+	// Correlation id of the request, to quote when reporting the error
+	CorrelationID string `json:"correlationId,omitempty"`
+	// Current state of a resource a stale write was rejected for
+	Current any `json:"current,omitempty"`
+}
+
+// FieldError describes why a single request field was rejected
+type FieldError struct {
+	// Dotted path of the field, e.g. tools[1].name
+	Field string `json:"field"`
+	// JSON pointer of the field, e.g. /tools/1/name, filled in from Field when the error is written without one
+	Pointer string `json:"pointer"`
+	// Constraint the value failed, one of the Constraint constants
+	Constraint string `json:"constraint,omitempty"`
+	Message    string `json:"message"`
+}
+
+// ValidationError collects the field errors of an invalid request
+type ValidationError struct {
+	Errors []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, 0, len(e.Errors))
+	for _, fieldErr := range e.Errors {
+		messages = append(messages, fmt.Sprintf("%s: %s", fieldErr.Field, fieldErr.Message))
+	}
+	return "validation failed: " + strings.Join(messages, "; ")
+}
+
+// Add records a rejected field
+func (e *ValidationError) Add(field string, format string, args ...interface{}) {
+	e.Errors = append(e.Errors, FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+// Reject records a field that failed constraint, path gives the exact location of fields whose dotted form is
+// ambiguous such as label keys containing '.' or '/'
+func (e *ValidationError) Reject(path FieldPath, constraint string, format string, args ...interface{}) {
+	e.Errors = append(e.Errors, FieldError{
+		Field:      path.String(),
+		Pointer:    path.Pointer(),
+		Constraint: constraint,
+		Message:    fmt.Sprintf(format, args...),
+	})
+}
+
+// OrNil returns the error if any field was rejected and nil otherwise
+func (e *ValidationError) OrNil() error {
+	if len(e.Errors) == 0 {
+		return nil
+	}
+	return e
+}
+
+// DependentResource identifies a resource that references the target of an operation
+type DependentResource struct {
+	Kind string `json:"kind"`
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// DependentsError reports that an operation is blocked by the resources referencing its target
+type DependentsError struct {
+	Err        error
+	Dependents []DependentResource
+}
+
+func (e *DependentsError) Error() string {
+	names := make([]string, 0, len(e.Dependents))
+	for _, dependent := range e.Dependents {
+		names = append(names, dependent.Name)
+	}
+	return fmt.Sprintf("%s: %s", e.Err.Error(), strings.Join(names, ", "))
+}
+
+func (e *DependentsError) Unwrap() error {
+	return e.Err
+}
+
+// WriteProblemResponse writes an RFC 7807 problem+json error response
+func WriteProblemResponse(w http.ResponseWriter, r *http.Request, statusCode int, detail string, fieldErrors ...FieldError) {
+	writeProblem(w, newProblem(r, statusCode, detail, fieldErrors))
+}
+
+// WriteDependentsProblemResponse writes a problem+json response listing the resources that block the operation
+func WriteDependentsProblemResponse(w http.ResponseWriter, r *http.Request, statusCode int, detail string, dependents []DependentResource) {
+	problem := newProblem(r, statusCode, detail, nil)
+	problem.Dependents = dependents
+	writeProblem(w, problem)
+}
+
+// WriteBodyProblem reports a request body that failed to decode, with 413 when it exceeded the size limit
+// and with the rejected field when a value has the wrong type
+func WriteBodyProblem(w http.ResponseWriter, r *http.Request, err error) {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		WriteProblemResponse(w, r, http.StatusRequestEntityTooLarge,
+			fmt.Sprintf("Request body exceeds the limit of %d bytes", maxBytesErr.Limit))
+		return
+	}
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		WriteProblemResponse(w, r, http.StatusBadRequest, "Invalid request body", FieldError{
+			Field:      typeErr.Field,
+			Constraint: ConstraintType,
+			Message:    fmt.Sprintf("must be of type %s", typeErr.Type),
+		})
+		return
+	}
+	WriteProblemResponse(w, r, http.StatusBadRequest, "Invalid request body")
+}
+
+func newProblem(r *http.Request, statusCode int, detail string, fieldErrors []FieldError) *ProblemDetails {
+	correlationID, _ := correlation.FromContext(r.Context())
+	// Errors recorded with Add only carry the dotted field
+	fieldErrors = append([]FieldError(nil), fieldErrors...)
+	for i := range fieldErrors {
+		if fieldErrors[i].Pointer == "" {
+			fieldErrors[i].Pointer = fieldPointer(fieldErrors[i].Field)
+		}
+	}
+	return &ProblemDetails{
+		Type:          "about:blank",
+		Title:         http.StatusText(statusCode),
+		Status:        statusCode,
+		Detail:        detail,
+		Instance:      r.URL.Path,
+		Errors:        fieldErrors,
+		CorrelationID: correlationID,
+	}
+}
+
+func writeProblem(w http.ResponseWriter, problem *ProblemDetails) {
+	w.Header().Set("Content-Type", ProblemContentType)
+	w.WriteHeader(problem.Status)
+	_ = json.NewEncoder(w).Encode(problem) // Ignore encoding errors for response
+}
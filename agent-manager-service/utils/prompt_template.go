@@ -0,0 +1,156 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package utils
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/models"
+)
+
+var (
+	promptPlaceholderPattern = regexp.MustCompile(`\{\{\s*([A-Za-z_][A-Za-z0-9_]*)\s*\}\}`)
+	promptVariablePattern    = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+)
+
+// ExtractPromptVariables returns the placeholder names of a template in order of first appearance
+func ExtractPromptVariables(template string) []string {
+	var names []string
+	seen := map[string]bool{}
+	for _, match := range promptPlaceholderPattern.FindAllStringSubmatch(template, -1) {
+		if !seen[match[1]] {
+			seen[match[1]] = true
+			names = append(names, match[1])
+		}
+	}
+	return names
+}
+
+// ValidatePromptTemplateRequest validates a prompt template create or update payload and reports every rejected field
+func ValidatePromptTemplateRequest(payload models.PromptTemplateRequest) error {
+	errs := &ValidationError{}
+
+	validateResourceName(errs, Field("name"), payload.Name, "prompt")
+	if len(payload.Description) > MaxAgentDescriptionLength {
+		errs.Reject(Field("description"), ConstraintMaxLength, "must be at most %d characters", MaxAgentDescriptionLength)
+	}
+	switch {
+	case strings.TrimSpace(payload.Template) == "":
+		errs.Reject(Field("template"), ConstraintRequired, "template is required")
+	case len(payload.Template) > MaxPromptTemplateLength:
+		errs.Reject(Field("template"), ConstraintMaxLength, "must be at most %d characters", MaxPromptTemplateLength)
+	case strings.Contains(promptPlaceholderPattern.ReplaceAllString(payload.Template, ""), "{{"):
+		errs.Reject(Field("template"), ConstraintFormat, "contains a malformed placeholder, placeholders look like {{variable_name}}")
+	}
+
+	if len(payload.Variables) > MaxPromptVariables {
+		errs.Reject(Field("variables"), ConstraintMaxItems, "must contain at most %d variables", MaxPromptVariables)
+		return errs.OrNil()
+	}
+	placeholders := map[string]bool{}
+	for _, name := range ExtractPromptVariables(payload.Template) {
+		placeholders[name] = true
+	}
+	seen := make(map[string]bool, len(payload.Variables))
+	for i, variable := range payload.Variables {
+		path := Field("variables").Index(i).Key("name")
+		switch {
+		case !promptVariablePattern.MatchString(variable.Name):
+			errs.Reject(path, ConstraintPattern, "variable names must start with a letter or '_' followed by letters, digits or '_'")
+		case seen[variable.Name]:
+			errs.Reject(path, ConstraintUniqueItems, "duplicate variable %q", variable.Name)
+		case !placeholders[variable.Name]:
+			errs.Reject(path, ConstraintReference, "variable %q is not used in the template", variable.Name)
+		}
+		seen[variable.Name] = true
+	}
+	validateLabels(errs, Field("labels"), payload.Labels)
+
+	return errs.OrNil()
+}
+
+// NormalizePromptVariables declares every placeholder of the template, keeping the descriptions and defaults
+// of declared variables and adding required variables for the undeclared ones
+func NormalizePromptVariables(template string, declared []models.PromptVariable) []models.PromptVariable {
+	byName := make(map[string]models.PromptVariable, len(declared))
+	for _, variable := range declared {
+		byName[variable.Name] = variable
+	}
+	names := ExtractPromptVariables(template)
+	variables := make([]models.PromptVariable, 0, len(names))
+	for _, name := range names {
+		variable, ok := byName[name]
+		if !ok {
+			variable = models.PromptVariable{Name: name}
+		}
+		variables = append(variables, variable)
+	}
+	return variables
+}
+
+// RenderPromptTemplate substitutes the placeholders of a template, variables without a value fall back to their
+// default and the request is rejected when a required variable is missing or an unknown variable is supplied
+func RenderPromptTemplate(template string, variables []models.PromptVariable, values map[string]string) (string, error) {
+	errs := &ValidationError{}
+
+	resolved := make(map[string]string, len(variables))
+	for _, variable := range NormalizePromptVariables(template, variables) {
+		value, ok := values[variable.Name]
+		switch {
+		case ok:
+			resolved[variable.Name] = value
+		case variable.Default != nil:
+			resolved[variable.Name] = *variable.Default
+		default:
+			errs.Reject(Field("variables").Key(variable.Name), ConstraintRequired, "required variable %q was not supplied", variable.Name)
+		}
+	}
+	var unknown []string
+	for name := range values {
+		if _, ok := resolved[name]; !ok {
+			unknown = append(unknown, name)
+		}
+	}
+	sort.Strings(unknown)
+	for _, name := range unknown {
+		errs.Reject(Field("variables").Key(name), ConstraintAdditionalProperties, "%q is not a variable of the prompt", name)
+	}
+	if err := errs.OrNil(); err != nil {
+		return "", err
+	}
+
+	return promptPlaceholderPattern.ReplaceAllStringFunc(template, func(placeholder string) string {
+		return resolved[promptPlaceholderPattern.FindStringSubmatch(placeholder)[1]]
+	}), nil
+}
+
+// ParsePromptReference returns the prompt id and version of a reference, which ValidateManagedAgentRequest has checked
+func ParsePromptReference(ref *models.PromptReference) (*uuid.UUID, *int32) {
+	if ref == nil {
+		return nil, nil
+	}
+	promptId, err := uuid.Parse(ref.PromptID)
+	if err != nil {
+		return nil, nil
+	}
+	version := ref.Version
+	return &promptId, &version
+}
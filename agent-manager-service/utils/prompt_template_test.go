@@ -0,0 +1,65 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package utils
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/models"
+)
+
+func TestValidatePromptTemplateRequestErrors(t *testing.T) {
+	payload := models.PromptTemplateRequest{
+		Name:      "Support",
+		Template:  "Hello {{customer}}, {{ broken",
+		Variables: []models.PromptVariable{{Name: "customer"}, {Name: "1st"}, {Name: "customer"}, {Name: "region"}},
+	}
+
+	want := []FieldError{
+		{Field: "name", Pointer: "/name", Constraint: ConstraintPattern, Message: "prompt name must contain only lowercase alphanumeric characters or '-'"},
+		{Field: "template", Pointer: "/template", Constraint: ConstraintFormat, Message: "contains a malformed placeholder, placeholders look like {{variable_name}}"},
+		{
+			Field:      "variables[1].name",
+			Pointer:    "/variables/1/name",
+			Constraint: ConstraintPattern,
+			Message:    "variable names must start with a letter or '_' followed by letters, digits or '_'",
+		},
+		{Field: "variables[2].name", Pointer: "/variables/2/name", Constraint: ConstraintUniqueItems, Message: `duplicate variable "customer"`},
+		{Field: "variables[3].name", Pointer: "/variables/3/name", Constraint: ConstraintReference, Message: `variable "region" is not used in the template`},
+	}
+	if got := validationErrors(t, ValidatePromptTemplateRequest(payload)); !reflect.DeepEqual(got, want) {
+		t.Errorf("ValidatePromptTemplateRequest() errors =\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+func TestRenderPromptTemplateErrors(t *testing.T) {
+	_, err := RenderPromptTemplate("Hello {{customer}}", nil, map[string]string{"team/region": "eu"})
+
+	want := []FieldError{
+		{Field: "variables.customer", Pointer: "/variables/customer", Constraint: ConstraintRequired, Message: `required variable "customer" was not supplied`},
+		{
+			Field:      "variables.team/region",
+			Pointer:    "/variables/team~1region",
+			Constraint: ConstraintAdditionalProperties,
+			Message:    `"team/region" is not a variable of the prompt`,
+		},
+	}
+	if got := validationErrors(t, err); !reflect.DeepEqual(got, want) {
+		t.Errorf("RenderPromptTemplate() errors =\n%+v\nwant\n%+v", got, want)
+	}
+}
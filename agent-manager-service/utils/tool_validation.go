@@ -0,0 +1,245 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/models"
+)
+
+// Tool names follow the function name rules of the model providers
+var toolNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// Headers the runtime may send a credential in
+var toolAuthHeaderPattern = regexp.MustCompile(`^[A-Za-z0-9-]{1,64}$`)
+
+// Resource location the parameters schema is compiled under, it never leaves the process
+const toolParametersSchemaURL = "urn:agent-manager:tool-parameters"
+
+// ValidateToolRequest validates a tool create or update payload and reports every rejected field
+func ValidateToolRequest(payload models.ToolRequest) error {
+	errs := &ValidationError{}
+
+	if !toolNamePattern.MatchString(payload.Name) {
+		errs.Add("name", "tool names must be 1 to 64 letters, digits, '_' or '-'")
+	}
+	if len(payload.Description) > MaxToolDescriptionLength {
+		errs.Add("description", "must be at most %d characters", MaxToolDescriptionLength)
+	}
+	validateToolParameters(errs, payload.Parameters)
+	validateToolEndpoint(errs, payload.Endpoint)
+	validateToolTags(errs, payload.Tags)
+	validateLabels(errs, Field("labels"), payload.Labels)
+
+	return errs.OrNil()
+}
+
+// validateToolParameters checks that the parameters document is a draft 2020-12 JSON Schema describing an object
+func validateToolParameters(errs *ValidationError, parameters map[string]interface{}) {
+	path := Field("parameters")
+	if parameters == nil {
+		errs.Reject(path, ConstraintRequired, "parameters is required")
+		return
+	}
+	compiler := jsonschema.NewCompiler()
+	compiler.DefaultDraft(jsonschema.Draft2020)
+	// Without loaders $ref cannot reach files or remote URLs
+	compiler.UseLoader(jsonschema.SchemeURLLoader{})
+	if err := compiler.AddResource(toolParametersSchemaURL, parameters); err != nil {
+		errs.Reject(path, "", "%s", err.Error())
+		return
+	}
+	_, err := compiler.Compile(toolParametersSchemaURL)
+	if err == nil {
+		if schemaType, ok := parameters["type"]; ok && schemaType != "object" {
+			errs.Reject(path.Key("type"), ConstraintEnum, "tool parameters must describe an object")
+		}
+		return
+	}
+
+	var schemaErr *jsonschema.SchemaValidationError
+	var validationErr *jsonschema.ValidationError
+	var loadErr *jsonschema.LoadURLError
+	switch {
+	case errors.As(err, &schemaErr) && errors.As(schemaErr.Err, &validationErr):
+		printer := message.NewPrinter(language.English)
+		seen := map[string]bool{}
+		for _, leaf := range schemaLeafErrors(validationErr) {
+			location := schemaInstancePath(path, parameters, leaf.InstanceLocation)
+			// Alternatives of a failed anyOf or oneOf are reported at the same location, keep the first
+			if seen[location.Pointer()] {
+				continue
+			}
+			seen[location.Pointer()] = true
+			errs.Reject(location, schemaKeyword(leaf.ErrorKind), "%s", leaf.ErrorKind.LocalizedString(printer))
+		}
+	case errors.As(err, &loadErr):
+		errs.Reject(path, ConstraintReference, "references to external schemas are not supported: %s", loadErr.URL)
+	default:
+		errs.Reject(path, "", "not a valid JSON Schema: %s", err.Error())
+	}
+}
+
+// schemaInstancePath returns the path of the value at the instance location of a schema error in the document at
+// path, the tokens of array elements become indexes
+func schemaInstancePath(path FieldPath, document interface{}, location []string) FieldPath {
+	value := document
+	for _, token := range location {
+		switch container := value.(type) {
+		case []interface{}:
+			if i, err := strconv.Atoi(token); err == nil && i >= 0 && i < len(container) {
+				path, value = path.Index(i), container[i]
+				continue
+			}
+			path, value = path.Key(token), nil
+		case map[string]interface{}:
+			path, value = path.Key(token), container[token]
+		default:
+			path, value = path.Key(token), nil
+		}
+	}
+	return path
+}
+
+// schemaKeyword returns the JSON Schema keyword a schema error was raised by
+func schemaKeyword(errorKind jsonschema.ErrorKind) string {
+	keywords := errorKind.KeywordPath()
+	if len(keywords) == 0 {
+		return ""
+	}
+	return keywords[len(keywords)-1]
+}
+
+func schemaLeafErrors(err *jsonschema.ValidationError) []*jsonschema.ValidationError {
+	if len(err.Causes) == 0 {
+		return []*jsonschema.ValidationError{err}
+	}
+	var leaves []*jsonschema.ValidationError
+	for _, cause := range err.Causes {
+		leaves = append(leaves, schemaLeafErrors(cause)...)
+	}
+	return leaves
+}
+
+func validateToolEndpoint(errs *ValidationError, endpoint models.ToolEndpoint) {
+	switch ToolEndpointType(endpoint.Type) {
+	case ToolEndpointTypeHTTP, ToolEndpointTypeMCP:
+		parsed, err := url.Parse(endpoint.URL)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			errs.Add("endpoint.url", "must be an absolute http or https URL")
+		}
+	case ToolEndpointTypeFunction:
+		if endpoint.URL != "" {
+			errs.Add("endpoint.url", "function tools run inside the agent and cannot have a URL")
+		}
+	case "":
+		errs.Add("endpoint.type", "endpoint type is required")
+		return
+	default:
+		errs.Add("endpoint.type", "unknown endpoint type %q, supported types are %s, %s and %s",
+			endpoint.Type, ToolEndpointTypeHTTP, ToolEndpointTypeMCP, ToolEndpointTypeFunction)
+		return
+	}
+	if endpoint.Auth != nil {
+		validateToolEndpointAuth(errs, endpoint)
+	}
+	if endpoint.Method == "" {
+		return
+	}
+	if ToolEndpointType(endpoint.Type) != ToolEndpointTypeHTTP {
+		errs.Add("endpoint.method", "only http tools have a method")
+		return
+	}
+	switch endpoint.Method {
+	case http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+	default:
+		errs.Add("endpoint.method", "must be one of GET, POST, PUT, PATCH or DELETE")
+	}
+}
+
+func validateToolEndpointAuth(errs *ValidationError, endpoint models.ToolEndpoint) {
+	if ToolEndpointType(endpoint.Type) == ToolEndpointTypeFunction {
+		errs.Add("endpoint.auth", "function tools run inside the agent and cannot authenticate with a credential")
+		return
+	}
+	if _, err := uuid.Parse(endpoint.Auth.CredentialID); err != nil {
+		errs.Add("endpoint.auth.credentialId", "must be the id of a credential")
+	}
+	if endpoint.Auth.Header != "" && !toolAuthHeaderPattern.MatchString(endpoint.Auth.Header) {
+		errs.Add("endpoint.auth.header", "must be an HTTP header name of at most 64 letters, digits and hyphens")
+	}
+}
+
+func validateToolTags(errs *ValidationError, tags []string) {
+	if len(tags) > MaxToolTags {
+		errs.Add("tags", "must contain at most %d tags", MaxToolTags)
+		return
+	}
+	seen := make(map[string]bool, len(tags))
+	for i, tag := range tags {
+		field := fmt.Sprintf("tags[%d]", i)
+		switch {
+		case len(tag) > MaxLabelValueLength || !labelPattern.MatchString(tag):
+			errs.Add(field, "tags must be at most %d alphanumeric, '-', '_', '.' or '/' characters starting and ending with an alphanumeric character", MaxLabelValueLength)
+		case seen[tag]:
+			errs.Add(field, "duplicate tag %q", tag)
+		}
+		seen[tag] = true
+	}
+}
+
+// ParseToolReferenceIds returns the registry ids referenced by the tools of an agent, which ValidateManagedAgentRequest has checked
+func ParseToolReferenceIds(tools []models.ToolReference) map[int]uuid.UUID {
+	ids := map[int]uuid.UUID{}
+	for i, tool := range tools {
+		if tool.ToolID == "" {
+			continue
+		}
+		if toolId, err := uuid.Parse(tool.ToolID); err == nil {
+			ids[i] = toolId
+		}
+	}
+	return ids
+}
+
+// ParseTagSelector splits a comma separated list of tags
+func ParseTagSelector(selector string) ([]string, error) {
+	var tags []string
+	for _, tag := range strings.Split(selector, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "" {
+			continue
+		}
+		if !labelPattern.MatchString(tag) {
+			return nil, fmt.Errorf("invalid tag %q", tag)
+		}
+		tags = append(tags, tag)
+	}
+	return tags, nil
+}
@@ -0,0 +1,158 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package utils
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/models"
+)
+
+// Filters of the trace list a view can save, named after their query parameters
+const (
+	TraceFilterStatus                = "status"
+	TraceFilterMinDuration           = "minDuration"
+	TraceFilterModel                 = "model"
+	TraceFilterFramework             = "framework"
+	TraceFilterAnnotationLabel       = "annotationLabel"
+	TraceFilterHasGuardrailViolation = "hasGuardrailViolation"
+)
+
+// TraceFilters lists the filters of the trace list, views referencing any other are rejected
+var TraceFilters = []string{
+	TraceFilterStatus,
+	TraceFilterMinDuration,
+	TraceFilterModel,
+	TraceFilterFramework,
+	TraceFilterAnnotationLabel,
+	TraceFilterHasGuardrailViolation,
+}
+
+// TraceSorts lists the fields the trace list can be sorted by
+var TraceSorts = []string{"startTime", "duration", "tokens"}
+
+// Who a trace view is visible to
+const (
+	TraceViewVisibilityPrivate = "private"
+	TraceViewVisibilityOrg     = "org"
+)
+
+// Trace view limits
+const (
+	MaxTraceViewNameLength    = 100
+	MaxTraceFilterValueLength = 256
+)
+
+// TraceViewQueryParam selects the saved view a trace listing starts from
+const TraceViewQueryParam = "view"
+
+// ValidateTraceFilter checks the value of a trace list filter, the error tells why it is rejected
+func ValidateTraceFilter(filter string, value string) error {
+	switch filter {
+	case TraceFilterStatus:
+		if value != "ok" && value != "error" {
+			return fmt.Errorf("must be 'ok' or 'error'")
+		}
+	case TraceFilterMinDuration:
+		if duration, err := time.ParseDuration(value); err != nil || duration < 0 {
+			return fmt.Errorf("must be a non-negative duration such as 500ms or 2s")
+		}
+	case TraceFilterHasGuardrailViolation:
+		if _, err := strconv.ParseBool(value); err != nil {
+			return fmt.Errorf("must be 'true' or 'false'")
+		}
+	case TraceFilterModel, TraceFilterFramework, TraceFilterAnnotationLabel:
+		if len(value) > MaxTraceFilterValueLength {
+			return fmt.Errorf("must be at most %d characters", MaxTraceFilterValueLength)
+		}
+	default:
+		return fmt.Errorf("is not a trace filter, the filters are %s", strings.Join(TraceFilters, ", "))
+	}
+	return nil
+}
+
+// ValidateTraceViewRequest validates a trace view payload and reports every rejected field
+func ValidateTraceViewRequest(payload models.TraceViewRequest) error {
+	errs := &ValidationError{}
+	name := strings.TrimSpace(payload.Name)
+	if name == "" {
+		errs.Add("name", "is required")
+	} else if len(name) > MaxTraceViewNameLength {
+		errs.Add("name", "must be at most %d characters", MaxTraceViewNameLength)
+	}
+	if payload.Visibility != "" && payload.Visibility != TraceViewVisibilityPrivate && payload.Visibility != TraceViewVisibilityOrg {
+		errs.Add("visibility", "must be %q or %q", TraceViewVisibilityPrivate, TraceViewVisibilityOrg)
+	}
+	for _, filter := range sortedFilterNames(payload.Filters) {
+		if err := ValidateTraceFilter(filter, payload.Filters[filter]); err != nil {
+			errs.Add("filters."+filter, "%s", err.Error())
+		}
+	}
+	if payload.Sort != "" && !slices.Contains(TraceSorts, payload.Sort) {
+		errs.Add("sort", "must be one of %s", strings.Join(TraceSorts, ", "))
+	}
+	if payload.SortOrder != "" && payload.SortOrder != "asc" && payload.SortOrder != "desc" {
+		errs.Add("sortOrder", "must be 'asc' or 'desc'")
+	}
+	return errs.OrNil()
+}
+
+// MergeTraceViewQuery returns the trace list query of a saved view overridden by the parameters of the request,
+// a parameter given empty clears the filter of the view. Filters the trace list no longer supports fail the
+// listing rather than being dropped, so stale views do not silently widen to every trace
+func MergeTraceViewQuery(view *models.TraceView, query url.Values) (url.Values, error) {
+	errs := &ValidationError{}
+	merged := url.Values{}
+	for _, filter := range sortedFilterNames(view.Filters) {
+		if err := ValidateTraceFilter(filter, view.Filters[filter]); err != nil {
+			errs.Add(TraceViewQueryParam, "saved filter %s %s, update or delete the view", filter, err.Error())
+			continue
+		}
+		merged.Set(filter, view.Filters[filter])
+	}
+	if err := errs.OrNil(); err != nil {
+		return nil, err
+	}
+	if view.Sort != "" {
+		merged.Set("sort", view.Sort)
+	}
+	if view.SortOrder != "" {
+		merged.Set("sortOrder", view.SortOrder)
+	}
+	for key, values := range query {
+		if key == TraceViewQueryParam {
+			continue
+		}
+		merged[key] = values
+	}
+	return merged, nil
+}
+
+// sortedFilterNames orders the filters of a view so that rejected ones are reported in a stable order
+func sortedFilterNames(filters map[string]string) []string {
+	names := make([]string, 0, len(filters))
+	for name := range filters {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
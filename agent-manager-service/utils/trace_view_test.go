@@ -0,0 +1,81 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package utils
+
+import (
+	"errors"
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/models"
+)
+
+func TestValidateTraceViewRequest(t *testing.T) {
+	valid := models.TraceViewRequest{
+		Name:       "slow errors",
+		Visibility: TraceViewVisibilityOrg,
+		Filters:    map[string]string{"status": "error", "minDuration": "2s", "hasGuardrailViolation": "false"},
+		Sort:       "duration",
+		SortOrder:  "asc",
+	}
+	if err := ValidateTraceViewRequest(valid); err != nil {
+		t.Fatalf("valid request rejected: %v", err)
+	}
+
+	err := ValidateTraceViewRequest(models.TraceViewRequest{
+		Name:       "  ",
+		Visibility: "team",
+		Filters:    map[string]string{"status": "failed", "minDuration": "-1s", "environment": "prod"},
+		Sort:       "cost",
+		SortOrder:  "up",
+	})
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("invalid request accepted: %v", err)
+	}
+	var fields []string
+	for _, fieldErr := range validationErr.Errors {
+		fields = append(fields, fieldErr.Field)
+	}
+	want := []string{"name", "visibility", "filters.environment", "filters.minDuration", "filters.status", "sort", "sortOrder"}
+	if !reflect.DeepEqual(fields, want) {
+		t.Errorf("rejected fields = %v, want %v", fields, want)
+	}
+}
+
+func TestMergeTraceViewQuery(t *testing.T) {
+	view := &models.TraceView{
+		Filters:   map[string]string{"status": "error", "model": "gpt-4o"},
+		Sort:      "duration",
+		SortOrder: "desc",
+	}
+	query := url.Values{"view": {"id"}, "model": {""}, "sortOrder": {"asc"}, "limit": {"5"}}
+	merged, err := MergeTraceViewQuery(view, query)
+	if err != nil {
+		t.Fatalf("MergeTraceViewQuery: %v", err)
+	}
+	want := url.Values{"status": {"error"}, "model": {""}, "sort": {"duration"}, "sortOrder": {"asc"}, "limit": {"5"}}
+	if !reflect.DeepEqual(merged, want) {
+		t.Errorf("merged query = %v, want %v", merged, want)
+	}
+
+	stale := &models.TraceView{Filters: map[string]string{"status": "error", "environment": "prod"}}
+	if _, err := MergeTraceViewQuery(stale, url.Values{}); err == nil {
+		t.Error("view with an unknown filter was applied")
+	}
+}
@@ -150,6 +150,20 @@ func ValidateResourceName(name string, resourceType string) error {
 	return nil
 }
 
+// validateResourceName records a name rejected by ValidateResourceName along with the constraint it failed
+func validateResourceName(errs *ValidationError, path FieldPath, name string, resourceType string) {
+	err := ValidateResourceName(name, resourceType)
+	switch {
+	case err == nil:
+	case name == "":
+		errs.Reject(path, ConstraintRequired, "%s", err.Error())
+	case len(name) > MaxResourceNameLength:
+		errs.Reject(path, ConstraintMaxLength, "%s", err.Error())
+	default:
+		errs.Reject(path, ConstraintPattern, "%s", err.Error())
+	}
+}
+
 func validateRepoDetails(repo *spec.RepositoryConfig) error {
 	if repo == nil {
 		return fmt.Errorf("repository details are required for internal agents")
@@ -291,6 +305,8 @@ func WriteSuccessResponse[T any](w http.ResponseWriter, statusCode int, data T)
 }
 
 // WriteErrorResponse writes an error API response
+//
+// Deprecated: API errors are problem documents, use WriteProblemResponse or WriteErrorProblem
 func WriteErrorResponse(w http.ResponseWriter, statusCode int, message string) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(statusCode)
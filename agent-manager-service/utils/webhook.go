@@ -0,0 +1,148 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/models"
+)
+
+// Agent lifecycle and budget events webhooks can subscribe to
+const (
+	WebhookEventAgentCreated  = "agent.created"
+	WebhookEventAgentUpdated  = "agent.updated"
+	WebhookEventAgentDeleted  = "agent.deleted"
+	WebhookEventAgentDeployed = "agent.deployed"
+	WebhookEventAgentRestored = "agent.restored"
+	// WebhookEventAgentBudgetThreshold is raised when usage of an agent reaches an alert threshold of its budget
+	WebhookEventAgentBudgetThreshold = "agent.budget.threshold_reached"
+	// WebhookEventPing is sent by test deliveries only, webhooks cannot subscribe to it
+	WebhookEventPing = "ping"
+)
+
+// WebhookEventTypes lists the events webhooks can subscribe to
+var WebhookEventTypes = []string{
+	WebhookEventAgentCreated,
+	WebhookEventAgentUpdated,
+	WebhookEventAgentDeleted,
+	WebhookEventAgentDeployed,
+	WebhookEventAgentRestored,
+	WebhookEventAgentBudgetThreshold,
+}
+
+// Webhook delivery statuses
+const (
+	WebhookDeliveryStatusPending   = "pending"
+	WebhookDeliveryStatusSucceeded = "succeeded"
+	WebhookDeliveryStatusFailed    = "failed"
+)
+
+// Headers webhook deliveries are sent with
+const (
+	WebhookSignatureHeader = "X-Amp-Signature"
+	WebhookEventHeader     = "X-Amp-Event"
+	WebhookDeliveryHeader  = "X-Amp-Delivery"
+)
+
+// Prefix of the signature header value naming the algorithm
+const webhookSignaturePrefix = "sha256="
+
+// Bounds of a webhook signing secret
+const (
+	MinWebhookSecretLength = 16
+	MaxWebhookSecretLength = 256
+)
+
+// Longest URL a webhook can be registered with
+const MaxWebhookURLLength = 2048
+
+// ValidateWebhookRequest validates a webhook create or update payload and reports every rejected field,
+// the secret may only be omitted on updates
+func ValidateWebhookRequest(payload models.WebhookRequest, requireSecret bool) error {
+	errs := &ValidationError{}
+	if len(payload.URL) > MaxWebhookURLLength {
+		errs.Add("url", "must be at most %d characters", MaxWebhookURLLength)
+	} else if parsed, err := url.Parse(payload.URL); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		errs.Add("url", "must be an absolute http or https URL")
+	}
+	if len(payload.Description) > MaxAgentDescriptionLength {
+		errs.Add("description", "must be at most %d characters", MaxAgentDescriptionLength)
+	}
+	if payload.Secret == "" {
+		if requireSecret {
+			errs.Add("secret", "is required")
+		}
+	} else if len(payload.Secret) < MinWebhookSecretLength || len(payload.Secret) > MaxWebhookSecretLength {
+		errs.Add("secret", "must be %d to %d bytes", MinWebhookSecretLength, MaxWebhookSecretLength)
+	}
+	if len(payload.Events) == 0 {
+		errs.Add("events", "at least one event type is required")
+	}
+	seen := make(map[string]bool, len(payload.Events))
+	for i, event := range payload.Events {
+		field := fmt.Sprintf("events[%d]", i)
+		if !IsWebhookEventType(event) {
+			errs.Add(field, "unknown event type %q", event)
+			continue
+		}
+		if seen[event] {
+			errs.Add(field, "duplicate event type %q", event)
+		}
+		seen[event] = true
+	}
+	return errs.OrNil()
+}
+
+// IsWebhookEventType reports whether webhooks can subscribe to the event type
+func IsWebhookEventType(eventType string) bool {
+	for _, known := range WebhookEventTypes {
+		if known == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// SignWebhookPayload returns the X-Amp-Signature value of a payload, the hex encoded HMAC-SHA256 of the exact
+// bytes sent keyed with the webhook secret
+func SignWebhookPayload(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return webhookSignaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// WebhookRetryDelay returns how long to wait after the given failed attempt, counted from 1,
+// doubling from base up to max
+func WebhookRetryDelay(attempt int, base time.Duration, max time.Duration) time.Duration {
+	delay := base
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if delay >= max {
+			return max
+		}
+	}
+	if delay > max {
+		return max
+	}
+	return delay
+}
@@ -0,0 +1,87 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package utils
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/models"
+)
+
+func TestSignWebhookPayload(t *testing.T) {
+	// HMAC-SHA256 of the body keyed with the secret, as receivers recompute it
+	got := SignWebhookPayload("It's a Secret to Everybody", []byte("Hello, World!"))
+	want := "sha256=757107ea0eb2509fc211221cce984b8a37570b6d7586c22c46f4379c8b043e17"
+	if got != want {
+		t.Errorf("SignWebhookPayload = %q, want %q", got, want)
+	}
+	if SignWebhookPayload("another secret", []byte("Hello, World!")) == want {
+		t.Error("signature does not depend on the secret")
+	}
+}
+
+func TestWebhookRetryDelay(t *testing.T) {
+	base, max := 30*time.Second, 10*time.Minute
+	cases := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{1, 30 * time.Second},
+		{2, time.Minute},
+		{3, 2 * time.Minute},
+		{5, 8 * time.Minute},
+		{6, 10 * time.Minute},
+		{40, 10 * time.Minute},
+	}
+	for _, c := range cases {
+		if got := WebhookRetryDelay(c.attempt, base, max); got != c.want {
+			t.Errorf("WebhookRetryDelay(%d) = %v, want %v", c.attempt, got, c.want)
+		}
+	}
+}
+
+func TestValidateWebhookRequest(t *testing.T) {
+	valid := models.WebhookRequest{
+		URL:    "https://hooks.example.com/amp",
+		Secret: "0123456789abcdef",
+		Events: []string{WebhookEventAgentCreated, WebhookEventAgentDeployed},
+	}
+	if err := ValidateWebhookRequest(valid, true); err != nil {
+		t.Fatalf("valid request rejected: %v", err)
+	}
+
+	noSecret := valid
+	noSecret.Secret = ""
+	if err := ValidateWebhookRequest(noSecret, false); err != nil {
+		t.Errorf("update without a secret rejected: %v", err)
+	}
+
+	cases := map[string]models.WebhookRequest{
+		"url":       {URL: "ftp://hooks.example.com", Secret: valid.Secret, Events: valid.Events},
+		"secret":    {URL: valid.URL, Secret: "short", Events: valid.Events},
+		"events":    {URL: valid.URL, Secret: valid.Secret},
+		"events[1]": {URL: valid.URL, Secret: valid.Secret, Events: []string{WebhookEventAgentCreated, WebhookEventPing}},
+	}
+	for field, payload := range cases {
+		err := ValidateWebhookRequest(payload, true)
+		if err == nil || !strings.Contains(err.Error(), field) {
+			t.Errorf("expected %s to be rejected, got %v", field, err)
+		}
+	}
+}
@@ -17,20 +17,58 @@
 package wiring
 
 import (
+	"log/slog"
+	"net/http"
+	"time"
+
 	observabilitysvc "github.com/wso2/ai-agent-management-platform/agent-manager-service/clients/observabilitysvc"
 	clients "github.com/wso2/ai-agent-management-platform/agent-manager-service/clients/openchoreosvc"
 	traceobserversvc "github.com/wso2/ai-agent-management-platform/agent-manager-service/clients/traceobserversvc"
 	"github.com/wso2/ai-agent-management-platform/agent-manager-service/config"
 	"github.com/wso2/ai-agent-management-platform/agent-manager-service/controllers"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/middleware"
 	"github.com/wso2/ai-agent-management-platform/agent-manager-service/middleware/jwtassertion"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/repositories"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/services"
+	"github.com/wso2/ai-agent-management-platform/agent-manager-service/utils"
 )
 
 type AppParams struct {
-	AuthMiddleware          jwtassertion.Middleware
-	AgentController         controllers.AgentController
-	InfraResourceController controllers.InfraResourceController
-	BuildCIController       controllers.BuildCIController
-	ObservabilityController controllers.ObservabilityController
+	AuthMiddleware jwtassertion.Middleware
+	Authorizer     *middleware.Authorizer
+	// RateLimiter is nil when rate limiting is disabled
+	RateLimiter               *middleware.RateLimiter
+	AgentController           controllers.AgentController
+	ManagedAgentController    controllers.ManagedAgentController
+	PromptTemplateController  controllers.PromptTemplateController
+	ToolController            controllers.ToolController
+	APIKeyController          controllers.APIKeyController
+	AgentDeploymentController controllers.AgentDeploymentController
+	CredentialController      controllers.CredentialController
+	WebhookController         controllers.WebhookController
+	AgentBudgetController     controllers.AgentBudgetController
+	EvaluationController      controllers.EvaluationController
+	TraceViewController       controllers.TraceViewController
+	InfraResourceController   controllers.InfraResourceController
+	BuildCIController         controllers.BuildCIController
+	ObservabilityController   controllers.ObservabilityController
+	// APIKeyService authenticates API keys and records their use in the background
+	APIKeyService services.APIKeyService
+	// InfraResourceManager resolves the organization requests are bound to
+	InfraResourceManager services.InfraResourceManager
+	// IdempotencyService replays retried requests and purges expired keys in the background
+	IdempotencyService services.IdempotencyService
+	// AuditService writes the audit log in the background
+	AuditService       services.AuditService
+	AuditLogController controllers.AuditLogController
+	// TrashService purges deleted agents and prompts past their retention in the background
+	TrashService services.TrashService
+	// WebhookService delivers agent events to webhooks in the background
+	WebhookService services.WebhookService
+	// AgentBudgetService checks the usage of agents against their budgets in the background
+	AgentBudgetService services.AgentBudgetService
+	// EvaluationService scores the traces of evaluation runs in the background
+	EvaluationService services.EvaluationService
 }
 
 // TestClients contains all mock clients needed for testing
@@ -44,6 +82,91 @@ func ProvideConfigFromPtr(config *config.Config) config.Config {
 	return *config
 }
 
+// ProvideAuthMiddleware verifies bearer tokens against the issuer's JWKS when one is configured,
+// otherwise the claims of tokens already verified by the gateway are trusted as is
 func ProvideAuthMiddleware(config config.Config) jwtassertion.Middleware {
-	return jwtassertion.JWTAuthMiddleware(config.AuthHeader)
+	if config.JWT.JWKSURL == "" {
+		return jwtassertion.JWTAuthMiddleware(config.AuthHeader)
+	}
+	keySet := jwtassertion.NewKeySet(
+		config.JWT.JWKSURL,
+		&http.Client{Timeout: 10 * time.Second},
+		time.Duration(config.JWT.JWKSRefreshIntervalSeconds)*time.Second,
+		jwtassertion.DefaultMinRefetchInterval,
+	)
+	validator := jwtassertion.NewTokenValidator(keySet, config.JWT.Issuer, config.JWT.Audience,
+		time.Duration(config.JWT.ClockSkewSeconds)*time.Second)
+	return jwtassertion.JWKSAuthMiddleware(config.AuthHeader, validator, config.JWT.UnauthenticatedPaths)
+}
+
+// ProvideAuthorizer grants the built in roles extended by the configured role permissions
+func ProvideAuthorizer(config config.Config) (*middleware.Authorizer, error) {
+	return middleware.NewAuthorizer(config.RBAC.RolePermissions, config.RBAC.DefaultRoles)
+}
+
+// ProvideRateLimiter returns nil when rate limiting is disabled
+func ProvideRateLimiter(config config.Config) (*middleware.RateLimiter, error) {
+	if !config.RateLimit.Enabled {
+		return nil, nil
+	}
+	return middleware.NewRateLimiter(middleware.RateLimiterConfig{
+		RequestsPerSecond: config.RateLimit.RequestsPerSecond,
+		Burst:             config.RateLimit.Burst,
+		TrustedProxies:    config.RateLimit.TrustedProxies,
+		MaxClients:        config.RateLimit.MaxClients,
+		ClientTTL:         time.Duration(config.RateLimit.ClientTTLSeconds) * time.Second,
+	}, nil)
+}
+
+// ProvideIdempotencyService keeps the responses of requests sent with an Idempotency-Key for the configured time
+func ProvideIdempotencyService(config config.Config, idempotencyRepo repositories.IdempotencyRepository, logger *slog.Logger) services.IdempotencyService {
+	return services.NewIdempotencyService(
+		idempotencyRepo,
+		logger,
+		time.Duration(config.Idempotency.KeyTTLSeconds)*time.Second,
+		time.Duration(config.Idempotency.LockTimeoutSeconds)*time.Second,
+	)
+}
+
+// ProvideAuditService queues audit log entries for writing up to the configured queue size
+func ProvideAuditService(config config.Config, orgRepo repositories.OrganizationRepository, auditLogRepo repositories.AuditLogRepository, logger *slog.Logger) services.AuditService {
+	return services.NewAuditService(orgRepo, auditLogRepo, logger, config.Audit.QueueSize)
+}
+
+// ProvideTrashService keeps deleted agents and prompts restorable for the configured retention
+func ProvideTrashService(config config.Config, managedAgentRepo repositories.ManagedAgentRepository, promptTemplateRepo repositories.PromptTemplateRepository, logger *slog.Logger) services.TrashService {
+	return services.NewTrashService(managedAgentRepo, promptTemplateRepo, logger, time.Duration(config.Trash.RetentionSeconds)*time.Second)
+}
+
+// ProvideCredentialCipher returns nil when no credential encryption keys are configured
+func ProvideCredentialCipher(config config.Config) (*utils.CredentialCipher, error) {
+	if len(config.Credentials.EncryptionKeys) == 0 {
+		return nil, nil
+	}
+	return utils.NewCredentialCipher(config.Credentials.EncryptionKeys, config.Credentials.ActiveKeyID)
+}
+
+// ProvideWebhookService delivers webhook events with the configured timeout and retries, only to public addresses
+// unless private networks are allowed
+func ProvideWebhookService(config config.Config, orgRepo repositories.OrganizationRepository, webhookRepo repositories.WebhookRepository, cipher *utils.CredentialCipher, logger *slog.Logger) services.WebhookService {
+	timeout := time.Duration(config.Webhook.TimeoutSeconds) * time.Second
+	client := utils.NewOutboundHTTPClient(timeout, config.Webhook.AllowPrivateNetworks)
+	return services.NewWebhookService(orgRepo, webhookRepo, cipher, client, logger, services.WebhookDispatchConfig{
+		Timeout:              timeout,
+		MaxAttempts:          config.Webhook.MaxAttempts,
+		RetryBase:            time.Duration(config.Webhook.RetryBaseSeconds) * time.Second,
+		RetryMax:             time.Duration(config.Webhook.RetryMaxSeconds) * time.Second,
+		BatchSize:            config.Webhook.DispatchBatchSize,
+		DeliveryRetention:    time.Duration(config.Webhook.DeliveryRetentionSeconds) * time.Second,
+		AllowPrivateNetworks: config.Webhook.AllowPrivateNetworks,
+	})
+}
+
+// ProvideEvaluationService gives http evaluators the configured time to score a trace, and calls them only on
+// public addresses unless private networks are allowed
+func ProvideEvaluationService(config config.Config, orgRepo repositories.OrganizationRepository, managedAgentRepo repositories.ManagedAgentRepository, evaluationRepo repositories.EvaluationRepository, traceObserverClient traceobserversvc.TraceObserverClient, logger *slog.Logger) services.EvaluationService {
+	timeout := time.Duration(config.Evaluation.EvaluatorTimeoutSeconds) * time.Second
+	allowPrivate := config.Evaluation.AllowPrivateNetworks
+	return services.NewEvaluationService(orgRepo, managedAgentRepo, evaluationRepo, traceObserverClient,
+		utils.NewOutboundHTTPClient(timeout, allowPrivate), logger, timeout, allowPrivate)
 }
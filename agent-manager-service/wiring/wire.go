@@ -43,6 +43,20 @@ var repositoryProviderSet = wire.NewSet(
 	repositories.NewAgentRepository,
 	repositories.NewProjectRepository,
 	repositories.NewInternalAgentRepository,
+	repositories.NewManagedAgentRepository,
+	repositories.NewManagedAgentVersionRepository,
+	repositories.NewPromptTemplateRepository,
+	repositories.NewToolRepository,
+	repositories.NewAPIKeyRepository,
+	repositories.NewIdempotencyRepository,
+	repositories.NewAuditLogRepository,
+	repositories.NewAgentEnvironmentRepository,
+	repositories.NewAgentDeploymentRepository,
+	repositories.NewCredentialRepository,
+	repositories.NewWebhookRepository,
+	repositories.NewAgentBudgetRepository,
+	repositories.NewEvaluationRepository,
+	repositories.NewTraceViewRepository,
 )
 
 var clientProviderSet = wire.NewSet(
@@ -56,6 +70,14 @@ var serviceProviderSet = wire.NewSet(
 	services.NewBuildCIManager,
 	services.NewInfraResourceManager,
 	services.NewObservabilityManager,
+	services.NewManagedAgentService,
+	services.NewPromptTemplateService,
+	services.NewToolService,
+	services.NewAPIKeyService,
+	services.NewAgentDeploymentService,
+	services.NewCredentialService,
+	services.NewAgentBudgetService,
+	services.NewTraceViewService,
 )
 
 var controllerProviderSet = wire.NewSet(
@@ -63,6 +85,17 @@ var controllerProviderSet = wire.NewSet(
 	controllers.NewBuildCIController,
 	controllers.NewInfraResourceController,
 	controllers.NewObservabilityController,
+	controllers.NewManagedAgentController,
+	controllers.NewPromptTemplateController,
+	controllers.NewToolController,
+	controllers.NewAPIKeyController,
+	controllers.NewAuditLogController,
+	controllers.NewAgentDeploymentController,
+	controllers.NewCredentialController,
+	controllers.NewWebhookController,
+	controllers.NewAgentBudgetController,
+	controllers.NewEvaluationController,
+	controllers.NewTraceViewController,
 )
 
 var testClientProviderSet = wire.NewSet(
@@ -104,6 +137,14 @@ func InitializeAppParams(cfg *config.Config) (*AppParams, error) {
 		serviceProviderSet,
 		controllerProviderSet,
 		ProvideAuthMiddleware,
+		ProvideAuthorizer,
+		ProvideRateLimiter,
+		ProvideCredentialCipher,
+		ProvideIdempotencyService,
+		ProvideAuditService,
+		ProvideTrashService,
+		ProvideWebhookService,
+		ProvideEvaluationService,
 		wire.Struct(new(AppParams), "*"),
 	)
 	return &AppParams{}, nil
@@ -111,11 +152,20 @@ func InitializeAppParams(cfg *config.Config) (*AppParams, error) {
 
 func InitializeTestAppParamsWithClientMocks(cfg *config.Config, authMiddleware jwtassertion.Middleware, testClients TestClients) (*AppParams, error) {
 	wire.Build(
+		configProviderSet,
 		repositoryProviderSet,
 		testClientProviderSet,
 		loggerProviderSet,
 		serviceProviderSet,
 		controllerProviderSet,
+		ProvideAuthorizer,
+		ProvideRateLimiter,
+		ProvideCredentialCipher,
+		ProvideIdempotencyService,
+		ProvideAuditService,
+		ProvideTrashService,
+		ProvideWebhookService,
+		ProvideEvaluationService,
 		wire.Struct(new(AppParams), "*"),
 	)
 	return &AppParams{}, nil
@@ -26,6 +26,14 @@ import (
 func InitializeAppParams(cfg *config.Config) (*AppParams, error) {
 	configConfig := ProvideConfigFromPtr(cfg)
 	middleware := ProvideAuthMiddleware(configConfig)
+	authorizer, err := ProvideAuthorizer(configConfig)
+	if err != nil {
+		return nil, err
+	}
+	rateLimiter, err := ProvideRateLimiter(configConfig)
+	if err != nil {
+		return nil, err
+	}
 	organizationRepository := repositories.NewOrganizationRepository()
 	projectRepository := repositories.NewProjectRepository()
 	agentRepository := repositories.NewAgentRepository()
@@ -43,19 +51,91 @@ func InitializeAppParams(cfg *config.Config) (*AppParams, error) {
 	buildCIManagerService := services.NewBuildCIManager(openChoreoSvcClient, logger, organizationRepository, projectRepository, agentRepository)
 	buildCIController := controllers.NewBuildCIController(buildCIManagerService)
 	traceObserverClient := traceobserversvc.NewTraceObserverClient()
-	observabilityManagerService := services.NewObservabilityManager(traceObserverClient, openChoreoSvcClient, logger)
-	observabilityController := controllers.NewObservabilityController(observabilityManagerService)
+	managedAgentRepository := repositories.NewManagedAgentRepository()
+	observabilityManagerService := services.NewObservabilityManager(traceObserverClient, openChoreoSvcClient, organizationRepository, managedAgentRepository, logger)
+	traceViewRepository := repositories.NewTraceViewRepository()
+	traceViewService := services.NewTraceViewService(organizationRepository, traceViewRepository, logger)
+	observabilityController := controllers.NewObservabilityController(observabilityManagerService, traceViewService)
+	managedAgentVersionRepository := repositories.NewManagedAgentVersionRepository()
+	promptTemplateRepository := repositories.NewPromptTemplateRepository()
+	toolRepository := repositories.NewToolRepository()
+	credentialRepository := repositories.NewCredentialRepository()
+	webhookRepository := repositories.NewWebhookRepository()
+	credentialCipher, err := ProvideCredentialCipher(configConfig)
+	if err != nil {
+		return nil, err
+	}
+	webhookService := ProvideWebhookService(configConfig, organizationRepository, webhookRepository, credentialCipher, logger)
+	managedAgentService := services.NewManagedAgentService(organizationRepository, managedAgentRepository, managedAgentVersionRepository, promptTemplateRepository, toolRepository, credentialRepository, webhookService, logger)
+	managedAgentController := controllers.NewManagedAgentController(managedAgentService, configConfig)
+	promptTemplateService := services.NewPromptTemplateService(organizationRepository, promptTemplateRepository, managedAgentRepository, logger)
+	promptTemplateController := controllers.NewPromptTemplateController(promptTemplateService)
+	toolService := services.NewToolService(organizationRepository, toolRepository, managedAgentRepository, credentialRepository, logger)
+	toolController := controllers.NewToolController(toolService)
+	apiKeyRepository := repositories.NewAPIKeyRepository()
+	apiKeyService := services.NewAPIKeyService(organizationRepository, apiKeyRepository, logger)
+	apiKeyController := controllers.NewAPIKeyController(apiKeyService)
+	agentEnvironmentRepository := repositories.NewAgentEnvironmentRepository()
+	agentDeploymentRepository := repositories.NewAgentDeploymentRepository()
+	agentDeploymentService := services.NewAgentDeploymentService(organizationRepository, agentEnvironmentRepository, agentDeploymentRepository, managedAgentRepository, managedAgentVersionRepository, promptTemplateRepository, toolRepository, webhookService, logger)
+	agentDeploymentController := controllers.NewAgentDeploymentController(agentDeploymentService)
+	credentialService := services.NewCredentialService(organizationRepository, credentialRepository, toolRepository, webhookRepository, credentialCipher, logger)
+	credentialController := controllers.NewCredentialController(credentialService)
+	webhookController := controllers.NewWebhookController(webhookService)
+	agentBudgetRepository := repositories.NewAgentBudgetRepository()
+	agentBudgetService := services.NewAgentBudgetService(organizationRepository, managedAgentRepository, agentBudgetRepository, traceObserverClient, webhookService, logger)
+	agentBudgetController := controllers.NewAgentBudgetController(agentBudgetService)
+	evaluationRepository := repositories.NewEvaluationRepository()
+	evaluationService := ProvideEvaluationService(configConfig, organizationRepository, managedAgentRepository, evaluationRepository, traceObserverClient, logger)
+	evaluationController := controllers.NewEvaluationController(evaluationService)
+	traceViewController := controllers.NewTraceViewController(traceViewService)
+	idempotencyRepository := repositories.NewIdempotencyRepository()
+	idempotencyService := ProvideIdempotencyService(configConfig, idempotencyRepository, logger)
+	auditLogRepository := repositories.NewAuditLogRepository()
+	auditService := ProvideAuditService(configConfig, organizationRepository, auditLogRepository, logger)
+	auditLogController := controllers.NewAuditLogController(auditService)
+	trashService := ProvideTrashService(configConfig, managedAgentRepository, promptTemplateRepository, logger)
 	appParams := &AppParams{
-		AuthMiddleware:          middleware,
-		AgentController:         agentController,
-		InfraResourceController: infraResourceController,
-		BuildCIController:       buildCIController,
-		ObservabilityController: observabilityController,
+		AuthMiddleware:            middleware,
+		Authorizer:                authorizer,
+		RateLimiter:               rateLimiter,
+		AgentController:           agentController,
+		ManagedAgentController:    managedAgentController,
+		PromptTemplateController:  promptTemplateController,
+		ToolController:            toolController,
+		APIKeyController:          apiKeyController,
+		AgentDeploymentController: agentDeploymentController,
+		CredentialController:      credentialController,
+		WebhookController:         webhookController,
+		AgentBudgetController:     agentBudgetController,
+		EvaluationController:      evaluationController,
+		TraceViewController:       traceViewController,
+		InfraResourceController:   infraResourceController,
+		BuildCIController:         buildCIController,
+		ObservabilityController:   observabilityController,
+		APIKeyService:             apiKeyService,
+		InfraResourceManager:      infraResourceManager,
+		IdempotencyService:        idempotencyService,
+		AuditService:              auditService,
+		AuditLogController:        auditLogController,
+		TrashService:              trashService,
+		WebhookService:            webhookService,
+		AgentBudgetService:        agentBudgetService,
+		EvaluationService:         evaluationService,
 	}
 	return appParams, nil
 }
 
 func InitializeTestAppParamsWithClientMocks(cfg *config.Config, authMiddleware jwtassertion.Middleware, testClients TestClients) (*AppParams, error) {
+	configConfig := ProvideConfigFromPtr(cfg)
+	authorizer, err := ProvideAuthorizer(configConfig)
+	if err != nil {
+		return nil, err
+	}
+	rateLimiter, err := ProvideRateLimiter(configConfig)
+	if err != nil {
+		return nil, err
+	}
 	organizationRepository := repositories.NewOrganizationRepository()
 	projectRepository := repositories.NewProjectRepository()
 	agentRepository := repositories.NewAgentRepository()
@@ -70,14 +150,77 @@ func InitializeTestAppParamsWithClientMocks(cfg *config.Config, authMiddleware j
 	buildCIManagerService := services.NewBuildCIManager(openChoreoSvcClient, logger, organizationRepository, projectRepository, agentRepository)
 	buildCIController := controllers.NewBuildCIController(buildCIManagerService)
 	traceObserverClient := ProvideTestTraceObserverClient(testClients)
-	observabilityManagerService := services.NewObservabilityManager(traceObserverClient, openChoreoSvcClient, logger)
-	observabilityController := controllers.NewObservabilityController(observabilityManagerService)
+	managedAgentRepository := repositories.NewManagedAgentRepository()
+	observabilityManagerService := services.NewObservabilityManager(traceObserverClient, openChoreoSvcClient, organizationRepository, managedAgentRepository, logger)
+	traceViewRepository := repositories.NewTraceViewRepository()
+	traceViewService := services.NewTraceViewService(organizationRepository, traceViewRepository, logger)
+	observabilityController := controllers.NewObservabilityController(observabilityManagerService, traceViewService)
+	managedAgentVersionRepository := repositories.NewManagedAgentVersionRepository()
+	promptTemplateRepository := repositories.NewPromptTemplateRepository()
+	toolRepository := repositories.NewToolRepository()
+	credentialRepository := repositories.NewCredentialRepository()
+	webhookRepository := repositories.NewWebhookRepository()
+	credentialCipher, err := ProvideCredentialCipher(configConfig)
+	if err != nil {
+		return nil, err
+	}
+	webhookService := ProvideWebhookService(configConfig, organizationRepository, webhookRepository, credentialCipher, logger)
+	managedAgentService := services.NewManagedAgentService(organizationRepository, managedAgentRepository, managedAgentVersionRepository, promptTemplateRepository, toolRepository, credentialRepository, webhookService, logger)
+	managedAgentController := controllers.NewManagedAgentController(managedAgentService, configConfig)
+	promptTemplateService := services.NewPromptTemplateService(organizationRepository, promptTemplateRepository, managedAgentRepository, logger)
+	promptTemplateController := controllers.NewPromptTemplateController(promptTemplateService)
+	toolService := services.NewToolService(organizationRepository, toolRepository, managedAgentRepository, credentialRepository, logger)
+	toolController := controllers.NewToolController(toolService)
+	apiKeyRepository := repositories.NewAPIKeyRepository()
+	apiKeyService := services.NewAPIKeyService(organizationRepository, apiKeyRepository, logger)
+	apiKeyController := controllers.NewAPIKeyController(apiKeyService)
+	agentEnvironmentRepository := repositories.NewAgentEnvironmentRepository()
+	agentDeploymentRepository := repositories.NewAgentDeploymentRepository()
+	agentDeploymentService := services.NewAgentDeploymentService(organizationRepository, agentEnvironmentRepository, agentDeploymentRepository, managedAgentRepository, managedAgentVersionRepository, promptTemplateRepository, toolRepository, webhookService, logger)
+	agentDeploymentController := controllers.NewAgentDeploymentController(agentDeploymentService)
+	credentialService := services.NewCredentialService(organizationRepository, credentialRepository, toolRepository, webhookRepository, credentialCipher, logger)
+	credentialController := controllers.NewCredentialController(credentialService)
+	webhookController := controllers.NewWebhookController(webhookService)
+	agentBudgetRepository := repositories.NewAgentBudgetRepository()
+	agentBudgetService := services.NewAgentBudgetService(organizationRepository, managedAgentRepository, agentBudgetRepository, traceObserverClient, webhookService, logger)
+	agentBudgetController := controllers.NewAgentBudgetController(agentBudgetService)
+	evaluationRepository := repositories.NewEvaluationRepository()
+	evaluationService := ProvideEvaluationService(configConfig, organizationRepository, managedAgentRepository, evaluationRepository, traceObserverClient, logger)
+	evaluationController := controllers.NewEvaluationController(evaluationService)
+	traceViewController := controllers.NewTraceViewController(traceViewService)
+	idempotencyRepository := repositories.NewIdempotencyRepository()
+	idempotencyService := ProvideIdempotencyService(configConfig, idempotencyRepository, logger)
+	auditLogRepository := repositories.NewAuditLogRepository()
+	auditService := ProvideAuditService(configConfig, organizationRepository, auditLogRepository, logger)
+	auditLogController := controllers.NewAuditLogController(auditService)
+	trashService := ProvideTrashService(configConfig, managedAgentRepository, promptTemplateRepository, logger)
 	appParams := &AppParams{
-		AuthMiddleware:          authMiddleware,
-		AgentController:         agentController,
-		InfraResourceController: infraResourceController,
-		BuildCIController:       buildCIController,
-		ObservabilityController: observabilityController,
+		AuthMiddleware:            authMiddleware,
+		Authorizer:                authorizer,
+		RateLimiter:               rateLimiter,
+		AgentController:           agentController,
+		ManagedAgentController:    managedAgentController,
+		PromptTemplateController:  promptTemplateController,
+		ToolController:            toolController,
+		APIKeyController:          apiKeyController,
+		AgentDeploymentController: agentDeploymentController,
+		CredentialController:      credentialController,
+		WebhookController:         webhookController,
+		AgentBudgetController:     agentBudgetController,
+		EvaluationController:      evaluationController,
+		TraceViewController:       traceViewController,
+		InfraResourceController:   infraResourceController,
+		BuildCIController:         buildCIController,
+		ObservabilityController:   observabilityController,
+		APIKeyService:             apiKeyService,
+		InfraResourceManager:      infraResourceManager,
+		IdempotencyService:        idempotencyService,
+		AuditService:              auditService,
+		AuditLogController:        auditLogController,
+		TrashService:              trashService,
+		WebhookService:            webhookService,
+		AgentBudgetService:        agentBudgetService,
+		EvaluationService:         evaluationService,
 	}
 	return appParams, nil
 }
@@ -88,13 +231,13 @@ var configProviderSet = wire.NewSet(
 	ProvideConfigFromPtr,
 )
 
-var repositoryProviderSet = wire.NewSet(repositories.NewOrganizationRepository, repositories.NewAgentRepository, repositories.NewProjectRepository, repositories.NewInternalAgentRepository)
+var repositoryProviderSet = wire.NewSet(repositories.NewOrganizationRepository, repositories.NewAgentRepository, repositories.NewProjectRepository, repositories.NewInternalAgentRepository, repositories.NewManagedAgentRepository, repositories.NewManagedAgentVersionRepository, repositories.NewPromptTemplateRepository, repositories.NewToolRepository, repositories.NewAPIKeyRepository, repositories.NewIdempotencyRepository, repositories.NewAuditLogRepository, repositories.NewAgentEnvironmentRepository, repositories.NewAgentDeploymentRepository, repositories.NewCredentialRepository, repositories.NewWebhookRepository, repositories.NewAgentBudgetRepository, repositories.NewEvaluationRepository, repositories.NewTraceViewRepository)
 
 var clientProviderSet = wire.NewSet(openchoreosvc.NewOpenChoreoSvcClient, observabilitysvc.NewObservabilitySvcClient, traceobserversvc.NewTraceObserverClient)
 
-var serviceProviderSet = wire.NewSet(services.NewAgentManagerService, services.NewBuildCIManager, services.NewInfraResourceManager, services.NewObservabilityManager)
+var serviceProviderSet = wire.NewSet(services.NewAgentManagerService, services.NewBuildCIManager, services.NewInfraResourceManager, services.NewObservabilityManager, services.NewManagedAgentService, services.NewPromptTemplateService, services.NewToolService, services.NewAPIKeyService, services.NewAgentDeploymentService, services.NewCredentialService, services.NewAgentBudgetService, services.NewTraceViewService)
 
-var controllerProviderSet = wire.NewSet(controllers.NewAgentController, controllers.NewBuildCIController, controllers.NewInfraResourceController, controllers.NewObservabilityController)
+var controllerProviderSet = wire.NewSet(controllers.NewAgentController, controllers.NewBuildCIController, controllers.NewInfraResourceController, controllers.NewObservabilityController, controllers.NewManagedAgentController, controllers.NewPromptTemplateController, controllers.NewToolController, controllers.NewAPIKeyController, controllers.NewAuditLogController, controllers.NewAgentDeploymentController, controllers.NewCredentialController, controllers.NewWebhookController, controllers.NewAgentBudgetController, controllers.NewEvaluationController, controllers.NewTraceViewController)
 
 var testClientProviderSet = wire.NewSet(
 	ProvideTestOpenChoreoSvcClient,
@@ -73,10 +73,25 @@ export interface PromptMessage {
   toolCalls?: ToolCall[];
 }
 
+export interface ToolParameter {
+  name: string;
+  type?: string;
+  description?: string;
+  required: boolean;
+  default?: unknown;
+  enum?: unknown[];
+}
+
+export interface ToolParameters {
+  properties: ToolParameter[];
+  truncated?: boolean;
+}
+
 export interface ToolDefinition {
   name: string;
   description?: string;
   parameters?: string;
+  parameterSchema?: ToolParameters;
 }
 
 export interface LLMData {
@@ -0,0 +1,281 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package compress
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultMinSize is the size below which compressing a response costs more than it saves
+const DefaultMinSize = 1024
+
+const (
+	encodingGzip    = "gzip"
+	encodingDeflate = "deflate"
+)
+
+type Config struct {
+	// Responses shorter than MinSize bytes are sent as they are
+	MinSize int
+}
+
+// encoder is implemented by the gzip and zlib writers, which are reset and reused across responses
+type encoder interface {
+	io.WriteCloser
+	Flush() error
+	Reset(w io.Writer)
+}
+
+var encoders = map[string]*sync.Pool{
+	encodingGzip: {New: func() any {
+		return gzip.NewWriter(io.Discard)
+	}},
+	encodingDeflate: {New: func() any {
+		return zlib.NewWriter(io.Discard)
+	}},
+}
+
+// Responses compresses response bodies with gzip or deflate, as accepted by the client. Bodies are compressed as
+// they are written, so flushed responses keep streaming. Responses below the minimum size, of already compressed
+// content types or with a Content-Encoding of their own are sent as they are.
+func Responses(cfg Config) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			addVary(w.Header(), "Accept-Encoding")
+			encoding := negotiate(r.Header.Get("Accept-Encoding"))
+			if encoding == "" || r.Method == http.MethodHead {
+				next.ServeHTTP(w, r)
+				return
+			}
+			cw := &compressWriter{ResponseWriter: w, encoding: encoding, minSize: cfg.MinSize}
+			// A panicking handler leaves the response to whoever recovers it
+			next.ServeHTTP(cw, r)
+			cw.close()
+		})
+	}
+}
+
+// negotiate picks the encoding of a response from Accept-Encoding, gzip when the client accepts both equally,
+// empty when it accepts neither
+func negotiate(acceptEncoding string) string {
+	gzipQ, deflateQ, anyQ := -1.0, -1.0, -1.0
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		name, params, _ := strings.Cut(part, ";")
+		q := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case encodingGzip, "x-gzip":
+			gzipQ = q
+		case encodingDeflate:
+			deflateQ = q
+		case "*":
+			anyQ = q
+		}
+	}
+	if gzipQ < 0 {
+		gzipQ = anyQ
+	}
+	if deflateQ < 0 {
+		deflateQ = anyQ
+	}
+	switch {
+	case gzipQ > 0 && gzipQ >= deflateQ:
+		return encodingGzip
+	case deflateQ > 0:
+		return encodingDeflate
+	default:
+		return ""
+	}
+}
+
+// addVary adds value to the Vary header unless it is listed already, e.g. next to the values set for CORS
+func addVary(header http.Header, value string) {
+	for _, line := range header.Values("Vary") {
+		for _, existing := range strings.Split(line, ",") {
+			if strings.EqualFold(strings.TrimSpace(existing), value) {
+				return
+			}
+		}
+	}
+	header.Add("Vary", value)
+}
+
+// compressible reports whether a content type is worth compressing, media and archives are compressed already
+func compressible(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	switch {
+	case mediaType == "image/svg+xml":
+		return true
+	case strings.HasPrefix(mediaType, "image/"), strings.HasPrefix(mediaType, "video/"),
+		strings.HasPrefix(mediaType, "audio/"), strings.HasPrefix(mediaType, "font/woff"):
+		return false
+	}
+	switch mediaType {
+	case "application/gzip", "application/x-gzip", "application/zip", "application/zstd", "application/x-bzip2",
+		"application/x-xz", "application/x-7z-compressed", "application/octet-stream":
+		return false
+	}
+	return true
+}
+
+// compressWriter holds back the start of a response until it is known whether it is worth compressing,
+// that is until it reaches the minimum size, is flushed or ends
+type compressWriter struct {
+	http.ResponseWriter
+	encoding string
+	minSize  int
+
+	status  int
+	buf     []byte
+	started bool
+	enc     encoder
+}
+
+func (cw *compressWriter) WriteHeader(status int) {
+	// Informational responses precede the final one
+	if status < http.StatusOK {
+		cw.ResponseWriter.WriteHeader(status)
+		return
+	}
+	if cw.status == 0 {
+		cw.status = status
+	}
+}
+
+func (cw *compressWriter) Write(p []byte) (int, error) {
+	if cw.status == 0 {
+		cw.status = http.StatusOK
+	}
+	if cw.started {
+		if cw.enc != nil {
+			return cw.enc.Write(p)
+		}
+		return cw.ResponseWriter.Write(p)
+	}
+	cw.buf = append(cw.buf, p...)
+	if len(cw.buf) >= cw.minSize {
+		if err := cw.start(false); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// Flush sends what was written so far, a flushed response is compressed whatever its size since more may follow
+func (cw *compressWriter) Flush() {
+	if !cw.started {
+		_ = cw.start(false)
+	}
+	if cw.enc != nil {
+		_ = cw.enc.Flush()
+	}
+	if flusher, ok := cw.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer
+func (cw *compressWriter) Unwrap() http.ResponseWriter {
+	return cw.ResponseWriter
+}
+
+// start writes the headers and the body held back so far, complete tells that the handler returned
+func (cw *compressWriter) start(complete bool) error {
+	cw.started = true
+	if cw.status == 0 {
+		cw.status = http.StatusOK
+	}
+	if cw.shouldCompress(complete) {
+		header := cw.Header()
+		header.Del("Content-Length")
+		header.Set("Content-Encoding", cw.encoding)
+		cw.enc = encoders[cw.encoding].Get().(encoder)
+		cw.enc.Reset(cw.ResponseWriter)
+	}
+	cw.ResponseWriter.WriteHeader(cw.status)
+
+	buf := cw.buf
+	cw.buf = nil
+	if len(buf) == 0 {
+		return nil
+	}
+	var err error
+	if cw.enc != nil {
+		_, err = cw.enc.Write(buf)
+	} else {
+		_, err = cw.ResponseWriter.Write(buf)
+	}
+	return err
+}
+
+func (cw *compressWriter) shouldCompress(complete bool) bool {
+	if complete && len(cw.buf) < cw.minSize {
+		return false
+	}
+	switch cw.status {
+	case http.StatusNoContent, http.StatusNotModified, http.StatusPartialContent:
+		return false
+	}
+	header := cw.Header()
+	if header.Get("Content-Encoding") != "" {
+		return false
+	}
+	if length, err := strconv.Atoi(header.Get("Content-Length")); err == nil && length < cw.minSize {
+		return false
+	}
+	contentType := header.Get("Content-Type")
+	if contentType == "" {
+		if len(cw.buf) == 0 {
+			return false
+		}
+		// The server would otherwise sniff the compressed bytes
+		contentType = http.DetectContentType(cw.buf)
+		header.Set("Content-Type", contentType)
+	}
+	return compressible(contentType)
+}
+
+// close ends the response and returns the encoder to its pool
+func (cw *compressWriter) close() {
+	if !cw.started {
+		if cw.status == 0 {
+			return
+		}
+		_ = cw.start(true)
+	}
+	if cw.enc != nil {
+		_ = cw.enc.Close()
+		encoders[cw.encoding].Put(cw.enc)
+		cw.enc = nil
+	}
+}
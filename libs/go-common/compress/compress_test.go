@@ -0,0 +1,245 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package compress
+
+import (
+	"bufio"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+var largeJSON = `{"traces":[` + strings.Repeat(`{"traceId":"4bf92f3577b34da6a3ce929d0e0e4736","name":"invoke_agent"},`, 100) + `{}]}`
+
+func serve(t *testing.T, acceptEncoding string, handler http.HandlerFunc) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, "/traces", nil)
+	if acceptEncoding != "" {
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+	}
+	rr := httptest.NewRecorder()
+	Responses(Config{MinSize: DefaultMinSize})(handler).ServeHTTP(rr, req)
+	return rr
+}
+
+func writeJSON(body string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		_, _ = io.WriteString(w, body)
+	}
+}
+
+func decode(t *testing.T, encoding string, body io.Reader) string {
+	t.Helper()
+	var reader io.Reader
+	var err error
+	switch encoding {
+	case "gzip":
+		reader, err = gzip.NewReader(body)
+	case "deflate":
+		reader, err = zlib.NewReader(body)
+	default:
+		reader = body
+	}
+	if err != nil {
+		t.Fatalf("failed to open %s body: %v", encoding, err)
+	}
+	decoded, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("failed to read %s body: %v", encoding, err)
+	}
+	return string(decoded)
+}
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		acceptEncoding string
+		want           string
+	}{
+		{"", ""},
+		{"gzip", "gzip"},
+		{"deflate", "deflate"},
+		{"gzip, deflate, br", "gzip"},
+		{"deflate, gzip;q=0.5", "deflate"},
+		{"gzip;q=0, deflate", "deflate"},
+		{"*", "gzip"},
+		{"*;q=0", ""},
+		{"br, identity", ""},
+		{"GZIP", "gzip"},
+	}
+	for _, tt := range tests {
+		if got := negotiate(tt.acceptEncoding); got != tt.want {
+			t.Errorf("negotiate(%q) = %q, want %q", tt.acceptEncoding, got, tt.want)
+		}
+	}
+}
+
+func TestResponsesCompressAcceptedEncodings(t *testing.T) {
+	for _, encoding := range []string{"gzip", "deflate"} {
+		rr := serve(t, encoding, writeJSON(largeJSON))
+		if got := rr.Header().Get("Content-Encoding"); got != encoding {
+			t.Fatalf("Content-Encoding = %q, want %q", got, encoding)
+		}
+		if rr.Body.Len() >= len(largeJSON) {
+			t.Errorf("%s body has %d bytes, not smaller than %d", encoding, rr.Body.Len(), len(largeJSON))
+		}
+		if got := decode(t, encoding, rr.Body); got != largeJSON {
+			t.Errorf("%s body does not decode to the response", encoding)
+		}
+	}
+}
+
+func TestResponsesSentAsIs(t *testing.T) {
+	tests := []struct {
+		name           string
+		acceptEncoding string
+		handler        http.HandlerFunc
+	}{
+		{"not accepted", "br", writeJSON(largeJSON)},
+		{"below the minimum size", "gzip", writeJSON(`{"traces":[]}`)},
+		{"already compressed content type", "gzip", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "image/png")
+			_, _ = io.WriteString(w, largeJSON)
+		}},
+		{"own content encoding", "gzip", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("Content-Encoding", "br")
+			_, _ = io.WriteString(w, largeJSON)
+		}},
+		{"no content", "gzip", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNoContent)
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var want bytesRecorder
+			tt.handler(&want, httptest.NewRequest(http.MethodGet, "/traces", nil))
+
+			rr := serve(t, tt.acceptEncoding, tt.handler)
+			if got := rr.Header().Get("Content-Encoding"); got != want.header.Get("Content-Encoding") {
+				t.Errorf("Content-Encoding = %q, want %q", got, want.header.Get("Content-Encoding"))
+			}
+			if rr.Body.String() != want.body.String() {
+				t.Errorf("body was changed")
+			}
+		})
+	}
+}
+
+// bytesRecorder records what a handler writes without any middleware
+type bytesRecorder struct {
+	header http.Header
+	body   strings.Builder
+}
+
+func (b *bytesRecorder) Header() http.Header {
+	if b.header == nil {
+		b.header = http.Header{}
+	}
+	return b.header
+}
+
+func (b *bytesRecorder) Write(p []byte) (int, error) { return b.body.Write(p) }
+
+func (b *bytesRecorder) WriteHeader(int) {}
+
+func TestResponsesVary(t *testing.T) {
+	cors := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("Vary", "Origin")
+			next.ServeHTTP(w, r)
+		})
+	}
+	rr := serve(t, "gzip", cors(writeJSON(largeJSON)).ServeHTTP)
+	if got := strings.Join(rr.Header().Values("Vary"), ", "); got != "Accept-Encoding, Origin" {
+		t.Errorf("Vary = %q, want Accept-Encoding next to Origin", got)
+	}
+
+	// Caches must tell apart the responses to clients not accepting compression too
+	rr = serve(t, "", writeJSON(largeJSON))
+	if got := rr.Header().Get("Vary"); got != "Accept-Encoding" {
+		t.Errorf("Vary = %q, want Accept-Encoding", got)
+	}
+
+	// A listed value is not repeated
+	header := http.Header{"Vary": []string{"Origin, accept-encoding"}}
+	addVary(header, "Accept-Encoding")
+	if got := header.Values("Vary"); len(got) != 1 {
+		t.Errorf("Vary = %q, want accept-encoding listed once", got)
+	}
+}
+
+func TestResponsesStreamFlushedWrites(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(Responses(Config{MinSize: DefaultMinSize})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		w.WriteHeader(http.StatusOK)
+		for page := 1; page <= 2; page++ {
+			fmt.Fprintf(w, "{\"page\":%d}\n", page)
+			w.(http.Flusher).Flush()
+			if page == 1 {
+				<-release
+			}
+		}
+	})))
+	defer server.Close()
+	defer close(release)
+
+	req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Accept-Encoding", "gzip")
+	// Compression is handled by the test so the client neither asks for nor decodes it
+	client := &http.Client{Transport: &http.Transport{DisableCompression: true}}
+	res, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	if got := res.Header.Get("Content-Encoding"); got != "gzip" {
+		t.Fatalf("Content-Encoding = %q, want gzip", got)
+	}
+
+	// The first page arrives while the handler still holds back the second
+	reader, err := gzip.NewReader(res.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	line, err := bufio.NewReader(reader).ReadString('\n')
+	if err != nil || line != "{\"page\":1}\n" {
+		t.Fatalf("first line = %q, %v", line, err)
+	}
+}
+
+func BenchmarkResponses(b *testing.B) {
+	handler := Responses(Config{MinSize: DefaultMinSize})(writeJSON(largeJSON))
+	req := httptest.NewRequest(http.MethodGet, "/traces", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	b.ReportAllocs()
+	b.SetBytes(int64(len(largeJSON)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+	}
+}
@@ -0,0 +1,131 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package logging
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"strings"
+)
+
+const (
+	// CorrelationIDHeader carries the correlation id between the services of the platform
+	CorrelationIDHeader = "X-Correlation-ID"
+	// TraceparentHeader carries the W3C trace context of a request
+	TraceparentHeader = "traceparent"
+
+	CorrelationIDKey = "correlation_id"
+	TraceIDKey       = "trace_id"
+)
+
+// Longest correlation id accepted from a client
+const maxCorrelationIDLength = 128
+
+type correlationIDKey struct{}
+
+type traceIDKey struct{}
+
+// WithCorrelationID adds the correlation id of a request to the context
+func WithCorrelationID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, correlationIDKey{}, id)
+}
+
+// CorrelationID returns the correlation id of the context
+func CorrelationID(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(correlationIDKey{}).(string)
+	return id, ok && id != ""
+}
+
+// WithTraceID adds the id of the trace a request belongs to to the context
+func WithTraceID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, traceIDKey{}, id)
+}
+
+// TraceID returns the trace id of the context
+func TraceID(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(traceIDKey{}).(string)
+	return id, ok && id != ""
+}
+
+// RequestCorrelationID returns the correlation id sent by a client, or a new one when the client sent
+// none or one that could forge log lines
+func RequestCorrelationID(header string) string {
+	if isValidCorrelationID(header) {
+		return header
+	}
+	id := make([]byte, 16)
+	_, _ = rand.Read(id)
+	return hex.EncodeToString(id)
+}
+
+// TraceIDFromTraceparent returns the trace id of a W3C traceparent header
+func TraceIDFromTraceparent(header string) (string, bool) {
+	parts := strings.Split(strings.TrimSpace(header), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || len(parts[1]) != 32 {
+		return "", false
+	}
+	if _, err := hex.DecodeString(parts[1]); err != nil || parts[1] == strings.Repeat("0", 32) {
+		return "", false
+	}
+	return strings.ToLower(parts[1]), true
+}
+
+func isValidCorrelationID(id string) bool {
+	if id == "" || len(id) > maxCorrelationIDLength {
+		return false
+	}
+	for _, c := range id {
+		isAlphanumeric := (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+		if !isAlphanumeric && c != '-' && c != '_' && c != '.' && c != ':' {
+			return false
+		}
+	}
+	return true
+}
+
+// contextHandler adds the correlation and trace ids of the context to records
+type contextHandler struct {
+	slog.Handler
+	// Set once the id is among the attributes of the logger, as on the request logger
+	hasCorrelationID bool
+	hasTraceID       bool
+}
+
+func (h contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if id, ok := CorrelationID(ctx); ok && !h.hasCorrelationID {
+		record.AddAttrs(slog.String(CorrelationIDKey, id))
+	}
+	if id, ok := TraceID(ctx); ok && !h.hasTraceID {
+		record.AddAttrs(slog.String(TraceIDKey, id))
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	next := contextHandler{Handler: h.Handler.WithAttrs(attrs), hasCorrelationID: h.hasCorrelationID, hasTraceID: h.hasTraceID}
+	for _, attr := range attrs {
+		next.hasCorrelationID = next.hasCorrelationID || attr.Key == CorrelationIDKey
+		next.hasTraceID = next.hasTraceID || attr.Key == TraceIDKey
+	}
+	return next
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{Handler: h.Handler.WithGroup(name), hasCorrelationID: h.hasCorrelationID, hasTraceID: h.hasTraceID}
+}
@@ -0,0 +1,124 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package logging
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// level is shared by every logger of the process so that it can be changed while running
+var level = new(slog.LevelVar)
+
+// sampledOut counts the debug records dropped by sampling
+var sampledOut atomic.Uint64
+
+// Options configures the loggers created by New and NewHandler
+type Options struct {
+	// Level is the initial level, DEBUG, INFO, WARN or ERROR
+	Level string
+	// Sampling bounds the debug records written with the same message
+	Sampling Sampling
+}
+
+// Stats reports the current level and the debug records dropped by sampling
+type Stats struct {
+	Level      string `json:"level"`
+	SampledOut uint64 `json:"sampledOut"`
+}
+
+// New creates a JSON logger writing to w at the shared level
+// Records carry the correlation and trace ids of their context and debug records are sampled
+func New(w io.Writer, opts Options) (*slog.Logger, error) {
+	handler, err := NewHandler(w, opts)
+	if err != nil {
+		return nil, err
+	}
+	return slog.New(contextHandler{Handler: handler}), nil
+}
+
+// NewHandler creates a JSON handler writing to w at the shared level, debug records are sampled
+// Services adding their own context attributes wrap it instead of using New
+func NewHandler(w io.Writer, opts Options) (slog.Handler, error) {
+	initial, err := ParseLevel(opts.Level)
+	if err != nil {
+		return nil, err
+	}
+	level.Set(initial)
+
+	var handler slog.Handler = slog.NewJSONHandler(w, &slog.HandlerOptions{Level: level})
+	if opts.Sampling.Interval > 0 {
+		handler = &samplingHandler{Handler: handler, sampler: newSampler(opts.Sampling, time.Now)}
+	}
+	return handler, nil
+}
+
+// ParseLevel parses a level name, case insensitively
+func ParseLevel(name string) (slog.Level, error) {
+	switch strings.ToUpper(strings.TrimSpace(name)) {
+	case "DEBUG":
+		return slog.LevelDebug, nil
+	case "INFO", "":
+		return slog.LevelInfo, nil
+	case "WARN", "WARNING":
+		return slog.LevelWarn, nil
+	case "ERROR":
+		return slog.LevelError, nil
+	}
+	return slog.LevelInfo, fmt.Errorf("unknown log level %q, use DEBUG, INFO, WARN or ERROR", name)
+}
+
+// Level returns the current level
+func Level() slog.Level {
+	return level.Level()
+}
+
+// SetLevel changes the level of every logger and returns the previous one
+func SetLevel(l slog.Level) slog.Level {
+	previous := level.Level()
+	level.Set(l)
+	return previous
+}
+
+// ReloadLevel sets the level named in file, or fallback when no file is given
+// The file holds a single level name, as mounted from a config map
+func ReloadLevel(file string, fallback string) (slog.Level, error) {
+	name := fallback
+	if file != "" {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return level.Level(), fmt.Errorf("failed to read log level file: %w", err)
+		}
+		name = string(content)
+	}
+	l, err := ParseLevel(name)
+	if err != nil {
+		return level.Level(), err
+	}
+	SetLevel(l)
+	return l, nil
+}
+
+// GetStats returns the current level and the debug records dropped by sampling
+func GetStats() Stats {
+	return Stats{Level: level.Level().String(), SampledOut: sampledOut.Load()}
+}
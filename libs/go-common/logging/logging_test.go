@@ -0,0 +1,208 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func decodeLines(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	t.Helper()
+	var records []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var record map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("log line is not JSON: %q", line)
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+func TestLoggerAddsContextIDsOnce(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := New(&buf, Options{Level: "info"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := WithTraceID(WithCorrelationID(context.Background(), "req-42"), "4bf92f3577b34da6a3ce929d0e0e4736")
+
+	logger.InfoContext(ctx, "from context")
+	logger.With(CorrelationIDKey, "req-42").InfoContext(ctx, "from request logger")
+	logger.Info("without context")
+
+	records := decodeLines(t, &buf)
+	if len(records) != 3 {
+		t.Fatalf("got %d records, want 3", len(records))
+	}
+	if records[0][CorrelationIDKey] != "req-42" || records[0][TraceIDKey] != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("record = %v, want the correlation and trace ids", records[0])
+	}
+	if !strings.Contains(buf.String(), `"msg":"from request logger","correlation_id":"req-42","trace_id"`) {
+		t.Errorf("the correlation id of the request logger was repeated: %s", buf.String())
+	}
+	if _, ok := records[2][CorrelationIDKey]; ok {
+		t.Errorf("record without context has a correlation id: %v", records[2])
+	}
+}
+
+func TestSetLevelAppliesToExistingLoggers(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := New(&buf, Options{Level: "WARN"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger.Info("dropped")
+	if previous := SetLevel(slog.LevelDebug); previous != slog.LevelWarn {
+		t.Errorf("previous level = %v, want WARN", previous)
+	}
+	logger.Debug("written")
+
+	records := decodeLines(t, &buf)
+	if len(records) != 1 || records[0]["msg"] != "written" {
+		t.Errorf("records = %v, want only the debug record written after the change", records)
+	}
+}
+
+func TestReloadLevel(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "level")
+	if err := os.WriteFile(file, []byte("debug\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if level, err := ReloadLevel(file, "INFO"); err != nil || level != slog.LevelDebug {
+		t.Errorf("reload from file = %v, %v, want DEBUG", level, err)
+	}
+	if level, err := ReloadLevel("", "ERROR"); err != nil || level != slog.LevelError {
+		t.Errorf("reload without file = %v, %v, want the fallback", level, err)
+	}
+	if err := os.WriteFile(file, []byte("verbose"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReloadLevel(file, "INFO"); err == nil {
+		t.Error("reload of an unknown level succeeded")
+	}
+	if Level() != slog.LevelError {
+		t.Errorf("level = %v, a failed reload must keep the current level", Level())
+	}
+}
+
+func TestSamplerBoundsDebugRecordsPerMessage(t *testing.T) {
+	now := time.Unix(0, 0)
+	s := newSampler(Sampling{Initial: 3, Thereafter: 5, Interval: time.Second}, func() time.Time { return now })
+
+	written := 0
+	for i := 0; i < 23; i++ {
+		if s.allow(slog.LevelDebug, "Queued exported spans") {
+			written++
+		}
+	}
+	// The first 3, then the 8th, 13th, 18th and 23rd
+	if written != 7 {
+		t.Errorf("written = %d, want 7", written)
+	}
+	for i := 0; i < 10; i++ {
+		if !s.allow(slog.LevelInfo, "Queued exported spans") {
+			t.Fatal("an info record was sampled")
+		}
+	}
+	if !s.allow(slog.LevelDebug, "Bulk request completed") {
+		t.Error("a different message shared the count")
+	}
+
+	now = now.Add(time.Second)
+	if !s.allow(slog.LevelDebug, "Queued exported spans") {
+		t.Error("the count did not start over after the interval")
+	}
+}
+
+func TestHandlerSamplesDebugRecords(t *testing.T) {
+	var buf bytes.Buffer
+	handler, err := NewHandler(&buf, Options{Level: "DEBUG", Sampling: Sampling{Initial: 2, Thereafter: 3, Interval: time.Hour}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger := slog.New(handler).With("component", "test")
+	before := GetStats().SampledOut
+
+	for i := 0; i < 8; i++ {
+		logger.DebugContext(context.Background(), "polling")
+	}
+	for i := 0; i < 4; i++ {
+		logger.Info("served")
+	}
+
+	// The first 2 debug records, then the 5th and the 8th
+	if n := strings.Count(buf.String(), `"msg":"polling"`); n != 4 {
+		t.Errorf("debug records written = %d, want 4", n)
+	}
+	if n := strings.Count(buf.String(), `"msg":"served"`); n != 4 {
+		t.Errorf("info records written = %d, records at INFO and above are never sampled", n)
+	}
+	if n := GetStats().SampledOut - before; n != 4 {
+		t.Errorf("sampled out = %d, want 4", n)
+	}
+}
+
+func TestHandlerOmitsContextIDs(t *testing.T) {
+	var buf bytes.Buffer
+	handler, err := NewHandler(&buf, Options{Level: "INFO"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	slog.New(handler).InfoContext(WithCorrelationID(context.Background(), "req-42"), "plain")
+
+	records := decodeLines(t, &buf)
+	if _, ok := records[0][CorrelationIDKey]; ok {
+		t.Errorf("record = %v, the bare handler must leave context ids to its wrapper", records[0])
+	}
+}
+
+func TestTraceIDFromTraceparent(t *testing.T) {
+	tests := []struct {
+		header string
+		want   string
+		ok     bool
+	}{
+		{"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01", "4bf92f3577b34da6a3ce929d0e0e4736", true},
+		{"00-00000000000000000000000000000000-00f067aa0ba902b7-01", "", false},
+		{"00-not-hex-01", "", false},
+		{"", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := TraceIDFromTraceparent(tt.header)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("TraceIDFromTraceparent(%q) = %q, %v, want %q, %v", tt.header, got, ok, tt.want, tt.ok)
+		}
+	}
+	if id := RequestCorrelationID("abc-123"); id != "abc-123" {
+		t.Errorf("valid correlation id replaced with %q", id)
+	}
+	if id := RequestCorrelationID("forged\n{\"level\":\"ERROR\"}"); len(id) != 32 {
+		t.Errorf("forged correlation id kept as %q", id)
+	}
+}
@@ -0,0 +1,110 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package logging
+
+import (
+	"context"
+	"hash/fnv"
+	"log/slog"
+	"sync/atomic"
+	"time"
+)
+
+// samplerBuckets is the number of message counters, messages sharing a bucket are sampled together
+const samplerBuckets = 4096
+
+// Sampling bounds the debug records written with the same message per interval, so that enabling
+// debug on a busy replica does not flood the output. Records at INFO and above are never sampled
+type Sampling struct {
+	// Initial records with the same message are written per interval
+	Initial int
+	// Thereafter every Thereafter-th further record is written, none when 0
+	Thereafter int
+	// Interval after which the counts start over, 0 disables sampling
+	Interval time.Duration
+}
+
+type sampler struct {
+	config   Sampling
+	now      func() time.Time
+	counters [samplerBuckets]samplerCounter
+}
+
+type samplerCounter struct {
+	resetAt atomic.Int64
+	count   atomic.Uint64
+}
+
+func newSampler(config Sampling, now func() time.Time) *sampler {
+	return &sampler{config: config, now: now}
+}
+
+// allow reports whether a record is written, counting the records dropped
+func (s *sampler) allow(l slog.Level, message string) bool {
+	if l >= slog.LevelInfo {
+		return true
+	}
+	hash := fnv.New32a()
+	_, _ = hash.Write([]byte(message))
+	n := s.counters[hash.Sum32()%samplerBuckets].incr(s.now(), s.config.Interval)
+
+	initial := uint64(s.config.Initial)
+	if n <= initial {
+		return true
+	}
+	if s.config.Thereafter > 0 && (n-initial)%uint64(s.config.Thereafter) == 0 {
+		return true
+	}
+	sampledOut.Add(1)
+	return false
+}
+
+// incr counts a record and returns its position in the current interval
+func (c *samplerCounter) incr(now time.Time, interval time.Duration) uint64 {
+	nanos := now.UnixNano()
+	resetAt := c.resetAt.Load()
+	if resetAt > nanos {
+		return c.count.Add(1)
+	}
+	c.count.Store(1)
+	if !c.resetAt.CompareAndSwap(resetAt, nanos+interval.Nanoseconds()) {
+		// Another record started the interval first
+		return c.count.Add(1)
+	}
+	return 1
+}
+
+// samplingHandler drops the debug records over the sampling bounds before they are formatted
+type samplingHandler struct {
+	slog.Handler
+	sampler *sampler
+}
+
+func (h *samplingHandler) Handle(ctx context.Context, record slog.Record) error {
+	if !h.sampler.allow(record.Level, record.Message) {
+		return nil
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h *samplingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &samplingHandler{Handler: h.Handler.WithAttrs(attrs), sampler: h.sampler}
+}
+
+func (h *samplingHandler) WithGroup(name string) slog.Handler {
+	return &samplingHandler{Handler: h.Handler.WithGroup(name), sampler: h.sampler}
+}
@@ -0,0 +1,108 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type MiddlewareConfig struct {
+	// Path mux is mounted at, prefixed to the route in span names
+	Prefix string
+	// Adds the trace id of the server span to the request context, e.g. for the request logs; optional
+	WithTraceID func(ctx context.Context, traceID string) context.Context
+}
+
+// Middleware starts a server span for every request, continuing the trace of an incoming traceparent
+// Spans are named after the route pattern of mux matching the request rather than its path, so that
+// organization, agent and trace names or IDs in paths do not end up in span names
+func Middleware(mux *http.ServeMux, cfg MiddlewareConfig) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+			route := Route(mux, r)
+			if route != "" {
+				route = cfg.Prefix + route
+			}
+			name := r.Method
+			if route != "" {
+				name += " " + route
+			}
+			ctx, span := Tracer().Start(ctx, name,
+				trace.WithSpanKind(trace.SpanKindServer),
+				trace.WithAttributes(semconv.HTTPRequestMethodKey.String(r.Method), semconv.HTTPRoute(route)),
+			)
+			defer span.End()
+			if spanContext := span.SpanContext(); cfg.WithTraceID != nil && spanContext.IsValid() {
+				ctx = cfg.WithTraceID(ctx, spanContext.TraceID().String())
+			}
+
+			recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(recorder, r.WithContext(ctx))
+
+			span.SetAttributes(semconv.HTTPResponseStatusCode(recorder.status))
+			if recorder.status >= http.StatusInternalServerError {
+				span.SetStatus(codes.Error, http.StatusText(recorder.status))
+			}
+		})
+	}
+}
+
+// Route returns the path of the pattern of mux matching the request, empty when no route matches
+func Route(mux *http.ServeMux, r *http.Request) string {
+	_, pattern := mux.Handler(r)
+	// Patterns registered for a method start with it
+	if i := strings.IndexByte(pattern, ' '); i >= 0 {
+		pattern = pattern[i+1:]
+	}
+	return pattern
+}
+
+// statusRecorder captures the status of a response
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status = status
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Flush streams what was written so far, as streaming responses do between pages
+func (r *statusRecorder) Flush() {
+	if flusher, ok := r.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
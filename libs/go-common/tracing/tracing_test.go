@@ -0,0 +1,154 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// recordSpans routes the spans of the test to a recorder
+func recordSpans(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+	recorder := tracetest.NewSpanRecorder()
+	previousProvider, previousPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previousProvider)
+		otel.SetTextMapPropagator(previousPropagator)
+	})
+	return recorder
+}
+
+type traceIDKey struct{}
+
+func TestMiddleware(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /orgs/{orgName}/agents/{agentName}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+	mux.HandleFunc("POST /orgs/{orgName}/agents", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {})
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		prefix     string
+		wantName   string
+		wantRoute  string
+		wantStatus string
+		wantError  bool
+	}{
+		{"route template", http.MethodGet, "/orgs/acme/agents/support-bot", "", "GET /orgs/{orgName}/agents/{agentName}", "/orgs/{orgName}/agents/{agentName}", "404", false},
+		{"mounted mux", http.MethodGet, "/orgs/acme/agents/support-bot", "/api/v1", "GET /api/v1/orgs/{orgName}/agents/{agentName}", "/api/v1/orgs/{orgName}/agents/{agentName}", "404", false},
+		{"pattern without a method", http.MethodGet, "/healthz", "", "GET /healthz", "/healthz", "200", false},
+		{"server error", http.MethodPost, "/orgs/acme/agents", "", "POST /orgs/{orgName}/agents", "/orgs/{orgName}/agents", "500", true},
+		{"no matching route", http.MethodGet, "/orgs/acme/secrets", "/api/v1", "GET", "", "404", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := recordSpans(t)
+			handler := Middleware(mux, MiddlewareConfig{Prefix: tt.prefix})(mux)
+			handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(tt.method, tt.path, nil))
+
+			spans := recorder.Ended()
+			if len(spans) != 1 {
+				t.Fatalf("got %d spans, want 1", len(spans))
+			}
+			span := spans[0]
+			if span.Name() != tt.wantName {
+				t.Errorf("span name = %q, want %q", span.Name(), tt.wantName)
+			}
+			attrs := map[string]string{}
+			for _, attr := range span.Attributes() {
+				attrs[string(attr.Key)] = attr.Value.Emit()
+			}
+			if attrs["http.route"] != tt.wantRoute {
+				t.Errorf("http.route = %q, want %q", attrs["http.route"], tt.wantRoute)
+			}
+			if attrs["http.response.status_code"] != tt.wantStatus {
+				t.Errorf("http.response.status_code = %q, want %q", attrs["http.response.status_code"], tt.wantStatus)
+			}
+			if got := span.Status().Code == codes.Error; got != tt.wantError {
+				t.Errorf("error status = %v, want %v", got, tt.wantError)
+			}
+		})
+	}
+}
+
+func TestMiddlewareContinuesIncomingTrace(t *testing.T) {
+	recorder := recordSpans(t)
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+
+	var contextTraceID interface{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /traces/{traceId}", func(w http.ResponseWriter, r *http.Request) {
+		contextTraceID = r.Context().Value(traceIDKey{})
+	})
+	handler := Middleware(mux, MiddlewareConfig{
+		WithTraceID: func(ctx context.Context, traceID string) context.Context {
+			return context.WithValue(ctx, traceIDKey{}, traceID)
+		},
+	})(mux)
+
+	req := httptest.NewRequest(http.MethodGet, "/traces/abc", nil)
+	req.Header.Set("traceparent", "00-"+traceID+"-00f067aa0ba902b7-01")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("got %d spans, want 1", len(spans))
+	}
+	if got := spans[0].SpanContext().TraceID().String(); got != traceID {
+		t.Errorf("span trace id = %s, want the trace id of the traceparent", got)
+	}
+	if got := spans[0].Parent().SpanID().String(); got != "00f067aa0ba902b7" {
+		t.Errorf("span parent = %s, want the span id of the traceparent", got)
+	}
+	if contextTraceID != traceID {
+		t.Errorf("context trace id = %v, want %s", contextTraceID, traceID)
+	}
+}
+
+func TestSetupWithoutEndpoint(t *testing.T) {
+	previousPropagator := otel.GetTextMapPropagator()
+	t.Cleanup(func() { otel.SetTextMapPropagator(previousPropagator) })
+
+	shutdown, err := Setup(context.Background(), Config{ServiceName: "test"})
+	if err != nil {
+		t.Fatalf("Setup() error = %v", err)
+	}
+	if err := shutdown(context.Background()); err != nil {
+		t.Errorf("shutdown() error = %v", err)
+	}
+	fields := otel.GetTextMapPropagator().Fields()
+	if len(fields) == 0 || fields[0] != "traceparent" {
+		t.Errorf("propagator fields = %v, want the W3C trace context", fields)
+	}
+}
@@ -0,0 +1,125 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package agentmanager
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// maxResponseBytes bounds the agents read from a response
+const maxResponseBytes = 1024 * 1024
+
+// Agent is a managed agent as reported by the internal API of the agent manager
+type Agent struct {
+	AgentID       string            `json:"agentId"`
+	OrgID         string            `json:"orgId"`
+	Labels        map[string]string `json:"labels"`
+	RetentionDays int               `json:"retentionDays,omitempty"` // Zero for agents following the default retention
+}
+
+// agentList is the answer of the internal API to a lookup of agents by name
+type agentList struct {
+	Agents []Agent `json:"agents"`
+}
+
+// Client reads managed agents from the internal API of the agent manager
+type Client struct {
+	baseURL      string
+	apiKeyHeader string
+	apiKey       string
+	httpClient   *http.Client
+}
+
+// NewClient creates an agent manager client
+func NewClient(baseURL, apiKeyHeader, apiKey string, requestTimeout time.Duration) *Client {
+	return &Client{
+		baseURL:      strings.TrimRight(baseURL, "/"),
+		apiKeyHeader: apiKeyHeader,
+		apiKey:       apiKey,
+		httpClient:   &http.Client{Timeout: requestTimeout},
+	}
+}
+
+// GetAgentLabels returns the organization and labels of a managed agent, nil when the agent manager does not know it
+func (c *Client) GetAgentLabels(ctx context.Context, agentID string) (*Agent, error) {
+	endpoint := c.baseURL + "/internal/agents/" + url.PathEscape(agentID) + "/labels"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create agent labels request: %w", err)
+	}
+	req.Header.Set(c.apiKeyHeader, c.apiKey)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get labels of agent %s: %w", agentID, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	// Agent ids that are not UUIDs are answered with 404 as well
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("agent manager returned %d for labels of agent %s: %s", resp.StatusCode, agentID, strings.TrimSpace(string(body)))
+	}
+	var agent Agent
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxResponseBytes)).Decode(&agent); err != nil {
+		return nil, fmt.Errorf("failed to decode labels of agent %s: %w", agentID, err)
+	}
+	return &agent, nil
+}
+
+// FindAgentsByName returns the managed agents of an organization whose name equals the given one ignoring case
+// More than one agent means the name is ambiguous
+func (c *Client) FindAgentsByName(ctx context.Context, orgID, name string) ([]Agent, error) {
+	endpoint := c.baseURL + "/internal/agents?" + url.Values{"orgId": {orgID}, "name": {name}}.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create agent lookup request: %w", err)
+	}
+	req.Header.Set(c.apiKeyHeader, c.apiKey)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up agents named %s: %w", name, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	// Organization ids that are not UUIDs are answered with 400, no managed agent belongs to them
+	if resp.StatusCode == http.StatusBadRequest {
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("agent manager returned %d for agents named %s: %s", resp.StatusCode, name, strings.TrimSpace(string(body)))
+	}
+	var list agentList
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxResponseBytes)).Decode(&list); err != nil {
+		return nil, fmt.Errorf("failed to decode agents named %s: %w", name, err)
+	}
+	return list.Agents, nil
+}
@@ -0,0 +1,57 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package agentmanager
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// AgentLookup returns a managed agent, nil when it does not exist, implemented by Client
+type AgentLookup interface {
+	GetAgentLabels(ctx context.Context, agentID string) (*Agent, error)
+}
+
+// LabelCache remembers the labels of managed agents so that ingestion asks the agent manager about an agent
+// once per TTL. Unknown agents and failed lookups are remembered as well, concurrent lookups of an agent share
+// a single request
+type LabelCache struct {
+	cache *lookupCache[*Agent]
+}
+
+// NewLabelCache creates a label cache holding up to maxEntries agents
+func NewLabelCache(lookup AgentLookup, ttl time.Duration, maxEntries int) *LabelCache {
+	return &LabelCache{
+		cache: newLookupCache(func(ctx context.Context, agentID string) (*Agent, error) {
+			agent, err := lookup.GetAgentLabels(ctx, agentID)
+			if err != nil {
+				slog.Warn("Failed to look up agent labels", "agentId", agentID, "error", err)
+			}
+			return agent, err
+		}, ttl, maxEntries),
+	}
+}
+
+// Get returns the agent with the given id, nil when it is unknown or could not be looked up
+func (c *LabelCache) Get(ctx context.Context, agentID string) *Agent {
+	agent, err := c.cache.get(ctx, agentID)
+	if err != nil {
+		return nil
+	}
+	return agent
+}
@@ -0,0 +1,123 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package agentmanager
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// failedLookupTTL is how long a failed lookup is remembered before the agent manager is asked again
+const failedLookupTTL = 30 * time.Second
+
+// lookupCache remembers the answers of the agent manager so that ingestion asks it about a key once per TTL.
+// Failed lookups are remembered as well, concurrent lookups of a key share a single request
+type lookupCache[V any] struct {
+	lookup     func(ctx context.Context, key string) (V, error)
+	ttl        time.Duration
+	maxEntries int
+	now        func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]*lookupCacheEntry[V]
+}
+
+type lookupCacheEntry[V any] struct {
+	value   V
+	err     error // Set for failed lookups
+	expires time.Time
+	ready   chan struct{} // Closed once the lookup finished
+}
+
+func newLookupCache[V any](lookup func(ctx context.Context, key string) (V, error), ttl time.Duration, maxEntries int) *lookupCache[V] {
+	return &lookupCache[V]{
+		lookup:     lookup,
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		now:        time.Now,
+		entries:    map[string]*lookupCacheEntry[V]{},
+	}
+}
+
+// get returns the answer for key, looking it up when it is not cached or out of date
+func (c *lookupCache[V]) get(ctx context.Context, key string) (V, error) {
+	c.mu.Lock()
+	entry, ok := c.entries[key]
+	if !ok || c.expired(entry) {
+		entry = &lookupCacheEntry[V]{ready: make(chan struct{})}
+		c.evict()
+		c.entries[key] = entry
+		c.mu.Unlock()
+		c.fill(ctx, key, entry)
+		return entry.value, entry.err
+	}
+	c.mu.Unlock()
+
+	select {
+	case <-entry.ready:
+		return entry.value, entry.err
+	case <-ctx.Done():
+		var zero V
+		return zero, ctx.Err()
+	}
+}
+
+// fill looks the key up, the lookup outlives the export that triggered it so that waiting exports are served
+func (c *lookupCache[V]) fill(ctx context.Context, key string, entry *lookupCacheEntry[V]) {
+	value, err := c.lookup(context.WithoutCancel(ctx), key)
+	ttl := c.ttl
+	if err != nil {
+		ttl = min(ttl, failedLookupTTL)
+	}
+
+	c.mu.Lock()
+	entry.value = value
+	entry.err = err
+	entry.expires = c.now().Add(ttl)
+	c.mu.Unlock()
+	close(entry.ready)
+}
+
+// expired reports whether a finished lookup is out of date, the caller holds the lock
+func (c *lookupCache[V]) expired(entry *lookupCacheEntry[V]) bool {
+	select {
+	case <-entry.ready:
+		return !c.now().Before(entry.expires)
+	default:
+		return false
+	}
+}
+
+// evict makes room for a new entry by dropping expired entries, and arbitrary ones while the cache is still full
+// The caller holds the lock
+func (c *lookupCache[V]) evict() {
+	if len(c.entries) < c.maxEntries {
+		return
+	}
+	for key, entry := range c.entries {
+		if c.expired(entry) {
+			delete(c.entries, key)
+		}
+	}
+	for key := range c.entries {
+		if len(c.entries) < c.maxEntries {
+			return
+		}
+		delete(c.entries, key)
+	}
+}
@@ -0,0 +1,55 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package agentmanager
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// AgentNameLookup returns the managed agents of an organization an agent name may refer to, implemented by Client
+type AgentNameLookup interface {
+	FindAgentsByName(ctx context.Context, orgID, name string) ([]Agent, error)
+}
+
+// NameCache remembers which managed agents the agent names reported by spans refer to, so that ingestion asks the
+// agent manager about a name once per TTL. Names without an agent are remembered as well
+type NameCache struct {
+	cache *lookupCache[[]Agent]
+}
+
+// NewNameCache creates a name cache holding up to maxEntries names
+func NewNameCache(lookup AgentNameLookup, ttl time.Duration, maxEntries int) *NameCache {
+	return &NameCache{
+		cache: newLookupCache(func(ctx context.Context, key string) ([]Agent, error) {
+			orgID, name, _ := strings.Cut(key, "\x00")
+			agents, err := lookup.FindAgentsByName(ctx, orgID, name)
+			if err != nil {
+				slog.Warn("Failed to look up agents by name", "orgId", orgID, "agentName", name, "error", err)
+			}
+			return agents, err
+		}, ttl, maxEntries),
+	}
+}
+
+// Get returns the agents of the organization the name may refer to, an error when they could not be looked up
+// The agent manager matches names ignoring case, so names differing in case share an entry
+func (c *NameCache) Get(ctx context.Context, orgID, name string) ([]Agent, error) {
+	return c.cache.get(ctx, orgID+"\x00"+strings.ToLower(name))
+}
@@ -0,0 +1,478 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package archive
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"log/slog"
+	"os"
+	"path"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/objectstore"
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/opensearch"
+)
+
+// RecordIndex is the index listing the archived indices and their objects
+const RecordIndex = "amp-trace-archives"
+
+// Kinds of archived indices
+const (
+	KindTraces  = "traces"
+	KindRollups = "rollups"
+)
+
+// scrollKeepAlive is the time a scroll context is kept between pages
+const scrollKeepAlive = "5m"
+
+// DefaultPathTemplate places the objects of an index under the day it holds
+const DefaultPathTemplate = "{yyyy}/{mm}/{dd}/{index}"
+
+// Config holds the archival settings
+type Config struct {
+	AfterDays        int           // Daily indices older than this are archived and then deleted
+	Interval         time.Duration // Interval of looking for indices to archive
+	DryRun           bool          // Only log what would be archived, nothing is uploaded or deleted
+	Prefix           string        // Key prefix of every object
+	PathTemplate     string        // Key template of an index with {yyyy}, {mm}, {dd} and {index} placeholders
+	MaxDocsPerObject int           // Documents per gzipped JSONL object
+	ScrollSize       int           // Documents read per scroll page
+	LookupIndices    int           // Most recently archived trace indices searched by trace lookups
+}
+
+// ObjectRecord describes an uploaded object
+type ObjectRecord struct {
+	Key       string `json:"key"`
+	Documents int    `json:"documents"`
+	Bytes     int64  `json:"bytes"`
+	MD5       string `json:"md5"`
+	SHA256    string `json:"sha256"`
+}
+
+// Record describes an archived index
+type Record struct {
+	Index      string         `json:"index"`
+	Kind       string         `json:"kind"` // traces or rollups
+	Day        time.Time      `json:"day"`
+	Bucket     string         `json:"bucket"`
+	Documents  int            `json:"documents"`
+	Objects    []ObjectRecord `json:"objects"`
+	ArchivedAt time.Time      `json:"archivedAt"`
+}
+
+// Stats holds the counters of the archiver
+type Stats struct {
+	DryRun            bool       `json:"dryRun"`
+	ArchivedIndices   uint64     `json:"archivedIndices"`
+	ArchivedDocuments uint64     `json:"archivedDocuments"`
+	LastRunAt         *time.Time `json:"lastRunAt,omitempty"`
+	LastError         string     `json:"lastError,omitempty"`
+}
+
+// archiveLine is a line of an archived object
+type archiveLine struct {
+	ID     string                 `json:"_id"`
+	Source map[string]interface{} `json:"_source"`
+}
+
+// Archiver copies expired daily indices to an S3-compatible bucket and deletes them once the copy is verified
+type Archiver struct {
+	cfg      Config
+	osClient *opensearch.Client
+	objects  *objectstore.S3Client
+
+	archivedIndices   atomic.Uint64
+	archivedDocuments atomic.Uint64
+	lastRunAt         atomic.Pointer[time.Time]
+	lastError         atomic.Pointer[string]
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewArchiver creates an archiver, Start begins the periodic archival
+func NewArchiver(cfg Config, osClient *opensearch.Client, objects *objectstore.S3Client) *Archiver {
+	if cfg.Interval <= 0 {
+		cfg.Interval = time.Hour
+	}
+	if cfg.PathTemplate == "" {
+		cfg.PathTemplate = DefaultPathTemplate
+	}
+	if cfg.MaxDocsPerObject <= 0 {
+		cfg.MaxDocsPerObject = 100000
+	}
+	if cfg.ScrollSize <= 0 {
+		cfg.ScrollSize = 1000
+	}
+	if cfg.LookupIndices <= 0 {
+		cfg.LookupIndices = 7
+	}
+	return &Archiver{
+		cfg:      cfg,
+		osClient: osClient,
+		objects:  objects,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+// buildRecordIndexBody builds the mappings of the archive record index
+func buildRecordIndexBody() map[string]interface{} {
+	return map[string]interface{}{
+		"mappings": map[string]interface{}{
+			"properties": map[string]interface{}{
+				"index":      map[string]interface{}{"type": "keyword"},
+				"kind":       map[string]interface{}{"type": "keyword"},
+				"day":        map[string]interface{}{"type": "date"},
+				"bucket":     map[string]interface{}{"type": "keyword"},
+				"documents":  map[string]interface{}{"type": "long"},
+				"objects":    map[string]interface{}{"type": "object", "enabled": false},
+				"archivedAt": map[string]interface{}{"type": "date"},
+			},
+		},
+	}
+}
+
+// Start creates the record index and starts the periodic archival
+func (a *Archiver) Start(ctx context.Context) error {
+	if err := a.osClient.EnsureIndex(ctx, RecordIndex, buildRecordIndexBody()); err != nil {
+		return err
+	}
+	go a.run()
+	return nil
+}
+
+// Close stops the periodic archival, an index being archived is finished first
+func (a *Archiver) Close() {
+	close(a.stop)
+	<-a.done
+}
+
+// Stats returns the counters of the archiver
+func (a *Archiver) Stats() Stats {
+	stats := Stats{
+		DryRun:            a.cfg.DryRun,
+		ArchivedIndices:   a.archivedIndices.Load(),
+		ArchivedDocuments: a.archivedDocuments.Load(),
+		LastRunAt:         a.lastRunAt.Load(),
+	}
+	if lastError := a.lastError.Load(); lastError != nil {
+		stats.LastError = *lastError
+	}
+	return stats
+}
+
+// run archives expired indices on every interval until the archiver is stopped
+func (a *Archiver) run() {
+	defer close(a.done)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ticker := time.NewTicker(a.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		a.archiveExpired(ctx)
+		select {
+		case <-ticker.C:
+		case <-a.stop:
+			return
+		}
+	}
+}
+
+// archiveExpired archives every daily index older than the configured age
+// A failed index is kept and retried on the next run
+func (a *Archiver) archiveExpired(ctx context.Context) {
+	now := time.Now().UTC()
+	a.lastRunAt.Store(&now)
+
+	cutoff := now.Truncate(24*time.Hour).AddDate(0, 0, -a.cfg.AfterDays)
+	indices, err := a.osClient.DailyIndicesBefore(ctx, cutoff)
+	if err != nil {
+		a.recordError(err)
+		return
+	}
+
+	var lastErr error
+	for _, index := range indices {
+		select {
+		case <-a.stop:
+			return
+		default:
+		}
+		if err := a.archiveIndex(ctx, index); err != nil {
+			slog.Error("Failed to archive index", "index", index, "error", err)
+			lastErr = fmt.Errorf("index %s: %w", index, err)
+		}
+	}
+	a.recordError(lastErr)
+}
+
+// recordError stores the error of the last run, nil clears it
+func (a *Archiver) recordError(err error) {
+	if err == nil {
+		a.lastError.Store(nil)
+		return
+	}
+	message := err.Error()
+	a.lastError.Store(&message)
+}
+
+// archiveIndex uploads the documents of an index, records the objects and deletes the index
+// An index recorded by an earlier run that stopped before deleting it is only deleted
+func (a *Archiver) archiveIndex(ctx context.Context, index string) error {
+	var existing Record
+	found, err := a.osClient.GetDocument(ctx, RecordIndex, index, &existing)
+	if err != nil {
+		return fmt.Errorf("failed to read archive record: %w", err)
+	}
+	if found {
+		if a.cfg.DryRun {
+			slog.Info("Dry run: would delete archived index", "index", index)
+			return nil
+		}
+		return a.deleteIndex(ctx, index)
+	}
+
+	day, _ := opensearch.DailyIndexDay(index)
+	keyPrefix := a.keyPrefix(index, day)
+	if a.cfg.DryRun {
+		response, err := a.osClient.Search(ctx, []string{index}, map[string]interface{}{"size": 0, "track_total_hits": true})
+		if err != nil {
+			return fmt.Errorf("failed to count documents: %w", err)
+		}
+		slog.Info("Dry run: would archive and delete index", "index", index,
+			"documents", response.Hits.Total.Value, "destination", "s3://"+a.objects.Bucket()+"/"+keyPrefix)
+		return nil
+	}
+
+	objects, documents, err := a.uploadIndex(ctx, index, keyPrefix)
+	if err != nil {
+		return err
+	}
+
+	record := Record{
+		Index:      index,
+		Kind:       KindRollups,
+		Day:        day,
+		Bucket:     a.objects.Bucket(),
+		Documents:  documents,
+		Objects:    objects,
+		ArchivedAt: time.Now().UTC(),
+	}
+	if opensearch.IsTraceIndex(index) {
+		record.Kind = KindTraces
+	}
+	if err := a.osClient.PutDocument(ctx, RecordIndex, index, record); err != nil {
+		return fmt.Errorf("failed to store archive record: %w", err)
+	}
+	a.archivedIndices.Add(1)
+	a.archivedDocuments.Add(uint64(documents))
+	slog.Info("Archived index", "index", index, "documents", documents, "objects", len(objects))
+
+	return a.deleteIndex(ctx, index)
+}
+
+// deleteIndex deletes an archived index
+func (a *Archiver) deleteIndex(ctx context.Context, index string) error {
+	if err := a.osClient.DeleteIndices(ctx, []string{index}); err != nil {
+		return fmt.Errorf("failed to delete archived index: %w", err)
+	}
+	slog.Info("Deleted archived index", "index", index)
+	return nil
+}
+
+// keyPrefix renders the path template of an index
+func (a *Archiver) keyPrefix(index string, day time.Time) string {
+	rendered := strings.NewReplacer(
+		"{yyyy}", day.Format("2006"),
+		"{mm}", day.Format("01"),
+		"{dd}", day.Format("02"),
+		"{index}", index,
+	).Replace(a.cfg.PathTemplate)
+	return path.Join(a.cfg.Prefix, rendered)
+}
+
+// uploadIndex scrolls the documents of an index into gzipped JSONL objects of at most MaxDocsPerObject documents
+func (a *Archiver) uploadIndex(ctx context.Context, index, keyPrefix string) ([]ObjectRecord, int, error) {
+	response, err := a.osClient.OpenScroll(ctx, index, a.cfg.ScrollSize, scrollKeepAlive)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to open scroll: %w", err)
+	}
+	scrollID := response.ScrollID
+	defer func() {
+		if scrollID != "" {
+			if err := a.osClient.ClearScroll(context.WithoutCancel(ctx), scrollID); err != nil {
+				slog.Warn("Failed to clear scroll", "index", index, "error", err)
+			}
+		}
+	}()
+
+	var objects []ObjectRecord
+	var part *objectWriter
+	documents := 0
+	defer func() {
+		if part != nil {
+			part.discard()
+		}
+	}()
+
+	for len(response.Hits.Hits) > 0 {
+		for _, hit := range response.Hits.Hits {
+			if part == nil {
+				key := fmt.Sprintf("%s/part-%05d.jsonl.gz", keyPrefix, len(objects))
+				if part, err = newObjectWriter(key); err != nil {
+					return nil, 0, err
+				}
+			}
+			if err := part.write(archiveLine{ID: hit.ID, Source: hit.Source}); err != nil {
+				return nil, 0, err
+			}
+			documents++
+
+			if part.documents >= a.cfg.MaxDocsPerObject {
+				object, err := a.uploadObject(ctx, part)
+				if err != nil {
+					return nil, 0, err
+				}
+				objects = append(objects, *object)
+				part = nil
+			}
+		}
+
+		if response, err = a.osClient.Scroll(ctx, scrollID, scrollKeepAlive); err != nil {
+			return nil, 0, fmt.Errorf("failed to scroll: %w", err)
+		}
+		if response.ScrollID != "" {
+			scrollID = response.ScrollID
+		}
+	}
+
+	if part != nil {
+		object, err := a.uploadObject(ctx, part)
+		if err != nil {
+			return nil, 0, err
+		}
+		objects = append(objects, *object)
+		part = nil
+	}
+	return objects, documents, nil
+}
+
+// uploadObject uploads a finished object and verifies the stored copy against its checksum
+func (a *Archiver) uploadObject(ctx context.Context, part *objectWriter) (*ObjectRecord, error) {
+	defer part.discard()
+	if err := part.close(); err != nil {
+		return nil, err
+	}
+
+	md5Sum := part.md5.Sum(nil)
+	sha256Sum := part.sha256.Sum(nil)
+	if err := a.objects.PutObject(ctx, part.key, "application/gzip", part.file, part.bytes, md5Sum, sha256Sum); err != nil {
+		return nil, err
+	}
+
+	// The ETag of a single-part upload is the MD5 digest of the object
+	info, err := a.objects.HeadObject(ctx, part.key)
+	if err != nil {
+		return nil, err
+	}
+	if info == nil {
+		return nil, fmt.Errorf("uploaded object %s not found", part.key)
+	}
+	if info.Size != part.bytes || !strings.EqualFold(info.ETag, hex.EncodeToString(md5Sum)) {
+		return nil, fmt.Errorf("checksum mismatch of uploaded object %s: etag %s, size %d", part.key, info.ETag, info.Size)
+	}
+
+	return &ObjectRecord{
+		Key:       part.key,
+		Documents: part.documents,
+		Bytes:     part.bytes,
+		MD5:       hex.EncodeToString(md5Sum),
+		SHA256:    hex.EncodeToString(sha256Sum),
+	}, nil
+}
+
+// objectWriter writes a gzipped JSONL object to a temporary file while computing its checksums
+type objectWriter struct {
+	key       string
+	file      *os.File
+	gzip      *gzip.Writer
+	buffer    *bufio.Writer
+	encoder   *json.Encoder
+	md5       hash.Hash
+	sha256    hash.Hash
+	documents int
+	bytes     int64
+}
+
+// newObjectWriter creates the temporary file of an object
+func newObjectWriter(key string) (*objectWriter, error) {
+	file, err := os.CreateTemp("", "trace-archive-*.jsonl.gz")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create archive file: %w", err)
+	}
+	w := &objectWriter{key: key, file: file, md5: md5.New(), sha256: sha256.New()}
+	w.gzip = gzip.NewWriter(io.MultiWriter(file, w.md5, w.sha256))
+	w.buffer = bufio.NewWriter(w.gzip)
+	w.encoder = json.NewEncoder(w.buffer)
+	return w, nil
+}
+
+// write appends a document as a JSON line
+func (w *objectWriter) write(line archiveLine) error {
+	if err := w.encoder.Encode(line); err != nil {
+		return fmt.Errorf("failed to write archived document: %w", err)
+	}
+	w.documents++
+	return nil
+}
+
+// close completes the gzip stream and records the object size
+func (w *objectWriter) close() error {
+	if err := w.buffer.Flush(); err != nil {
+		return fmt.Errorf("failed to write archive file: %w", err)
+	}
+	if err := w.gzip.Close(); err != nil {
+		return fmt.Errorf("failed to write archive file: %w", err)
+	}
+	info, err := w.file.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to write archive file: %w", err)
+	}
+	w.bytes = info.Size()
+	return nil
+}
+
+// discard removes the temporary file
+func (w *objectWriter) discard() {
+	w.file.Close()
+	os.Remove(w.file.Name())
+}
@@ -0,0 +1,379 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package archive
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/config"
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/objectstore"
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/opensearch"
+)
+
+// fakeArchiveOpenSearch holds daily indices and archive records in memory
+// Scroll ids are the index followed by the offset of the next page
+type fakeArchiveOpenSearch struct {
+	mu            sync.Mutex
+	indices       map[string][]string // Span documents by index
+	records       map[string]json.RawMessage
+	deleted       []string
+	recordQueries []string
+	openScrolls   int
+	failDeleteFor string
+}
+
+func (f *fakeArchiveOpenSearch) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	recordPath := "/" + RecordIndex + "/_doc/"
+	body, _ := io.ReadAll(r.Body)
+	switch {
+	case r.URL.Path == "/":
+		fmt.Fprint(w, `{"cluster_name":"test","version":{"distribution":"opensearch","number":"2.11.0"}}`)
+	case strings.HasPrefix(r.URL.Path, recordPath) && r.Method == http.MethodGet:
+		record, ok := f.records[strings.TrimPrefix(r.URL.Path, recordPath)]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"found":false}`)
+			return
+		}
+		fmt.Fprintf(w, `{"found":true,"_source":%s}`, record)
+	case strings.HasPrefix(r.URL.Path, recordPath) && r.Method == http.MethodPut:
+		f.records[strings.TrimPrefix(r.URL.Path, recordPath)] = body
+		fmt.Fprint(w, `{"result":"created"}`)
+	case r.URL.Path == "/"+RecordIndex+"/_search":
+		f.searchRecords(w, body)
+	case r.URL.Path == "/_search/scroll" && r.Method == http.MethodDelete:
+		f.openScrolls--
+		fmt.Fprint(w, `{"succeeded":true}`)
+	case r.URL.Path == "/_search/scroll":
+		var request struct {
+			ScrollID string `json:"scroll_id"`
+		}
+		json.Unmarshal(body, &request)
+		separator := strings.LastIndex(request.ScrollID, "|")
+		offset, _ := strconv.Atoi(request.ScrollID[separator+1:])
+		f.page(w, request.ScrollID[:separator], offset)
+	case strings.HasSuffix(r.URL.Path, "/_search") && r.URL.Query().Get("scroll") != "":
+		f.openScrolls++
+		f.page(w, strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/"), "/_search"), 0)
+	case strings.HasSuffix(r.URL.Path, "/_search"):
+		index := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/"), "/_search")
+		fmt.Fprintf(w, `{"hits":{"total":{"value":%d},"hits":[]}}`, len(f.indices[index]))
+	case r.Method == http.MethodDelete:
+		index := strings.TrimPrefix(r.URL.Path, "/")
+		if index == f.failDeleteFor {
+			w.WriteHeader(http.StatusInternalServerError)
+			fmt.Fprint(w, `{"error":"scripted"}`)
+			return
+		}
+		delete(f.indices, index)
+		f.deleted = append(f.deleted, index)
+		fmt.Fprint(w, `{"acknowledged":true}`)
+	case r.Method == http.MethodGet:
+		prefix := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/"), "*")
+		names := map[string]interface{}{}
+		for index := range f.indices {
+			if strings.HasPrefix(index, prefix) {
+				names[index] = map[string]interface{}{}
+			}
+		}
+		json.NewEncoder(w).Encode(names)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// page answers a scroll request with the two documents of an index starting at offset
+func (f *fakeArchiveOpenSearch) page(w http.ResponseWriter, index string, offset int) {
+	documents := f.indices[index]
+	end := offset + 2
+	if offset > len(documents) {
+		offset = len(documents)
+	}
+	if end > len(documents) {
+		end = len(documents)
+	}
+	hits := make([]string, 0, end-offset)
+	for i, document := range documents[offset:end] {
+		hits = append(hits, fmt.Sprintf(`{"_id":"doc-%d","_source":%s}`, offset+i, document))
+	}
+	fmt.Fprintf(w, `{"_scroll_id":"%s|%d","hits":{"total":{"value":%d},"hits":[%s]}}`, index, end, len(documents), strings.Join(hits, ","))
+}
+
+// searchRecords answers a record search with the trace records, newest day first
+func (f *fakeArchiveOpenSearch) searchRecords(w http.ResponseWriter, body []byte) {
+	f.recordQueries = append(f.recordQueries, string(body))
+	var records []Record
+	for _, raw := range f.records {
+		var record Record
+		json.Unmarshal(raw, &record)
+		if record.Kind == KindTraces {
+			records = append(records, record)
+		}
+	}
+	sort.Slice(records, func(i, j int) bool { return records[i].Day.After(records[j].Day) })
+
+	var query struct {
+		Size int `json:"size"`
+	}
+	json.Unmarshal(body, &query)
+	if len(records) > query.Size {
+		records = records[:query.Size]
+	}
+	hits := make([]string, 0, len(records))
+	for _, record := range records {
+		encoded, _ := json.Marshal(record)
+		hits = append(hits, fmt.Sprintf(`{"_id":%q,"_source":%s}`, record.Index, encoded))
+	}
+	fmt.Fprintf(w, `{"hits":{"total":{"value":%d},"hits":[%s]}}`, len(hits), strings.Join(hits, ","))
+}
+
+// fakeObjectStore keeps objects in memory, corrupt objects report a wrong ETag
+type fakeObjectStore struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+	corrupt bool
+}
+
+func (s *fakeObjectStore) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := strings.TrimPrefix(r.URL.Path, "/archive/")
+	switch r.Method {
+	case http.MethodPut:
+		s.objects[key], _ = io.ReadAll(r.Body)
+	default:
+		body, ok := s.objects[key]
+		if !ok {
+			http.Error(w, "NoSuchKey", http.StatusNotFound)
+			return
+		}
+		sum := md5.Sum(body)
+		if s.corrupt {
+			sum = md5.Sum(nil)
+		}
+		w.Header().Set("ETag", fmt.Sprintf("%q", hex.EncodeToString(sum[:])))
+		w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+		w.Write(body)
+	}
+}
+
+// lines returns the archived documents of an object
+func (s *fakeObjectStore) lines(t *testing.T, key string) []archiveLine {
+	t.Helper()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	reader, err := gzip.NewReader(bytes.NewReader(s.objects[key]))
+	if err != nil {
+		t.Fatalf("object %s is not gzipped: %v", key, err)
+	}
+	var lines []archiveLine
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		var line archiveLine
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			t.Fatalf("object %s holds an invalid line %q", key, scanner.Text())
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// spanDocument returns a span document of a trace
+func spanDocument(traceID, spanID string) string {
+	return fmt.Sprintf(`{"traceId":%q,"spanId":%q,"name":"%s-op","startTime":"2025-01-01T10:00:00Z"}`, traceID, spanID, spanID)
+}
+
+// newTestArchiver creates an archiver writing to the fake stores
+func newTestArchiver(t *testing.T, fake *fakeArchiveOpenSearch, store *fakeObjectStore, cfg Config) *Archiver {
+	t.Helper()
+	if fake.records == nil {
+		fake.records = map[string]json.RawMessage{}
+	}
+	store.objects = map[string][]byte{}
+
+	osServer := httptest.NewServer(fake)
+	t.Cleanup(osServer.Close)
+	osClient, err := opensearch.NewClient(&config.OpenSearchConfig{Address: osServer.URL, RequestTimeout: 5 * time.Second}, nil)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	s3Server := httptest.NewServer(store)
+	t.Cleanup(s3Server.Close)
+	objects, err := objectstore.NewS3Client(objectstore.S3Config{Endpoint: s3Server.URL, Bucket: "archive", AccessKeyID: "key", SecretAccessKey: "secret", PathStyle: true})
+	if err != nil {
+		t.Fatalf("NewS3Client() error = %v", err)
+	}
+	return NewArchiver(cfg, osClient, objects)
+}
+
+func TestArchiverArchivesExpiredIndices(t *testing.T) {
+	today := opensearch.TraceIndexName(time.Now())
+	fake := &fakeArchiveOpenSearch{indices: map[string][]string{
+		"otel-traces-2025-01-01": {
+			spanDocument("trace-1", "a"), spanDocument("trace-1", "b"), spanDocument("trace-2", "c"),
+			spanDocument("trace-2", "d"), spanDocument("trace-3", "e"),
+		},
+		"otel-trace-rollups-2025-01-01": {`{"traceId":"trace-4","spanCount":3}`},
+		today:                           {spanDocument("trace-5", "f")},
+	}}
+	store := &fakeObjectStore{}
+	archiver := newTestArchiver(t, fake, store, Config{AfterDays: 30, Prefix: "amp", MaxDocsPerObject: 2, ScrollSize: 2})
+
+	archiver.archiveExpired(context.Background())
+
+	if stats := archiver.Stats(); stats.ArchivedIndices != 2 || stats.ArchivedDocuments != 6 || stats.LastError != "" || stats.LastRunAt == nil {
+		t.Errorf("stats = %+v, want 2 indices and 6 documents archived", stats)
+	}
+	sort.Strings(fake.deleted)
+	if strings.Join(fake.deleted, ",") != "otel-trace-rollups-2025-01-01,otel-traces-2025-01-01" {
+		t.Errorf("deleted = %v, want only the expired indices", fake.deleted)
+	}
+	if fake.openScrolls != 0 {
+		t.Errorf("open scrolls = %d, want every scroll cleared", fake.openScrolls)
+	}
+
+	var record Record
+	if err := json.Unmarshal(fake.records["otel-traces-2025-01-01"], &record); err != nil {
+		t.Fatalf("record = %s, want the archived index recorded", fake.records["otel-traces-2025-01-01"])
+	}
+	if record.Kind != KindTraces || record.Bucket != "archive" || record.Documents != 5 || !record.Day.Equal(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("record = %+v, want 5 trace documents of 2025-01-01", record)
+	}
+	// Objects are split at MaxDocsPerObject and placed by the path template
+	var keys []string
+	for _, object := range record.Objects {
+		keys = append(keys, fmt.Sprintf("%s:%d", object.Key, object.Documents))
+		sum := md5.Sum(store.objects[object.Key])
+		if object.MD5 != hex.EncodeToString(sum[:]) || object.Bytes != int64(len(store.objects[object.Key])) {
+			t.Errorf("object = %+v, want the checksum and size of the uploaded object", object)
+		}
+	}
+	wantKeys := []string{
+		"amp/2025/01/01/otel-traces-2025-01-01/part-00000.jsonl.gz:2",
+		"amp/2025/01/01/otel-traces-2025-01-01/part-00001.jsonl.gz:2",
+		"amp/2025/01/01/otel-traces-2025-01-01/part-00002.jsonl.gz:1",
+	}
+	if strings.Join(keys, ",") != strings.Join(wantKeys, ",") {
+		t.Errorf("objects = %v, want %v", keys, wantKeys)
+	}
+	lines := store.lines(t, wantKeys[0][:len(wantKeys[0])-2])
+	if len(lines) != 2 || lines[0].ID != "doc-0" || lines[0].Source["spanId"] != "a" {
+		t.Errorf("lines = %+v, want the ids and sources of the first documents", lines)
+	}
+
+	var rollups Record
+	json.Unmarshal(fake.records["otel-trace-rollups-2025-01-01"], &rollups)
+	if rollups.Kind != KindRollups || rollups.Documents != 1 {
+		t.Errorf("rollup record = %+v, want 1 rollup document", rollups)
+	}
+}
+
+func TestArchiverKeepsIndexWhenVerificationFails(t *testing.T) {
+	fake := &fakeArchiveOpenSearch{indices: map[string][]string{"otel-traces-2025-01-01": {spanDocument("trace-1", "a")}}}
+	archiver := newTestArchiver(t, fake, &fakeObjectStore{corrupt: true}, Config{AfterDays: 30})
+
+	archiver.archiveExpired(context.Background())
+
+	if len(fake.deleted) != 0 || len(fake.records) != 0 {
+		t.Errorf("deleted = %v, records = %v, want the index kept", fake.deleted, fake.records)
+	}
+	if stats := archiver.Stats(); !strings.Contains(stats.LastError, "checksum mismatch") || stats.ArchivedIndices != 0 {
+		t.Errorf("stats = %+v, want the checksum mismatch reported", stats)
+	}
+}
+
+func TestArchiverOnlyDeletesRecordedIndices(t *testing.T) {
+	fake := &fakeArchiveOpenSearch{
+		indices:       map[string][]string{"otel-traces-2025-01-01": {spanDocument("trace-1", "a")}},
+		failDeleteFor: "otel-traces-2025-01-01",
+	}
+	store := &fakeObjectStore{}
+	archiver := newTestArchiver(t, fake, store, Config{AfterDays: 30})
+
+	// The index is recorded but the delete fails, the next run deletes it without uploading it again
+	archiver.archiveExpired(context.Background())
+	if stats := archiver.Stats(); stats.ArchivedIndices != 1 || !strings.Contains(stats.LastError, "failed to delete archived index") {
+		t.Fatalf("stats = %+v, want the index archived and the delete failure reported", stats)
+	}
+	uploaded := len(store.objects)
+	fake.failDeleteFor = ""
+
+	archiver.archiveExpired(context.Background())
+
+	if len(fake.deleted) != 1 || len(store.objects) != uploaded {
+		t.Errorf("deleted = %v, objects = %d, want the index deleted without another upload", fake.deleted, len(store.objects))
+	}
+	if stats := archiver.Stats(); stats.ArchivedIndices != 1 || stats.LastError != "" {
+		t.Errorf("stats = %+v, want the error cleared", stats)
+	}
+}
+
+func TestArchiverDryRun(t *testing.T) {
+	fake := &fakeArchiveOpenSearch{indices: map[string][]string{"otel-traces-2025-01-01": {spanDocument("trace-1", "a")}}}
+	store := &fakeObjectStore{}
+	archiver := newTestArchiver(t, fake, store, Config{AfterDays: 30, DryRun: true})
+
+	archiver.archiveExpired(context.Background())
+
+	if len(store.objects) != 0 || len(fake.deleted) != 0 || len(fake.records) != 0 {
+		t.Errorf("objects = %d, deleted = %v, records = %d, want nothing changed in a dry run", len(store.objects), fake.deleted, len(fake.records))
+	}
+	if stats := archiver.Stats(); !stats.DryRun || stats.ArchivedIndices != 0 || stats.LastError != "" {
+		t.Errorf("stats = %+v, want a dry run without errors", stats)
+	}
+}
+
+func TestKeyPrefix(t *testing.T) {
+	day := time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		prefix   string
+		template string
+		want     string
+	}{
+		{"", DefaultPathTemplate, "2025/01/02/otel-traces-2025-01-02"},
+		{"amp/", DefaultPathTemplate, "amp/2025/01/02/otel-traces-2025-01-02"},
+		{"amp", "{index}/{yyyy}-{mm}-{dd}", "amp/otel-traces-2025-01-02/2025-01-02"},
+	}
+	for _, tt := range tests {
+		archiver := NewArchiver(Config{Prefix: tt.prefix, PathTemplate: tt.template}, nil, nil)
+		if got := archiver.keyPrefix("otel-traces-2025-01-02", day); got != tt.want {
+			t.Errorf("keyPrefix() with %q and %q = %s, want %s", tt.prefix, tt.template, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package archive
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/opensearch"
+)
+
+// maxLineBytes bounds a single archived document
+const maxLineBytes = 64 * 1024 * 1024
+
+// FindTrace returns the span documents of a trace from the most recently archived trace indices
+// When indices is not empty only the archives of those indices are searched
+// The spans of a trace may cross a day boundary, so the index before the first match is searched too
+// When ctx is bound to an organization only the spans of the organization are returned
+func (a *Archiver) FindTrace(ctx context.Context, traceID string, indices []string) ([]map[string]interface{}, error) {
+	records, err := a.traceRecords(ctx, indices)
+	if err != nil {
+		return nil, err
+	}
+
+	var sources []map[string]interface{}
+	for _, record := range records {
+		found := len(sources) > 0
+		for _, object := range record.Objects {
+			matches, err := a.scanObject(ctx, object.Key, traceID)
+			if err != nil {
+				return nil, err
+			}
+			sources = append(sources, matches...)
+		}
+		if found {
+			break
+		}
+	}
+	return sources, nil
+}
+
+// traceRecords returns the records of the most recently archived trace indices, newest first
+func (a *Archiver) traceRecords(ctx context.Context, indices []string) ([]Record, error) {
+	filters := []map[string]interface{}{
+		{"term": map[string]interface{}{"kind": KindTraces}},
+	}
+	if len(indices) > 0 {
+		filters = append(filters, map[string]interface{}{"terms": map[string]interface{}{"index": indices}})
+	}
+	query := map[string]interface{}{
+		"size":  a.cfg.LookupIndices,
+		"query": map[string]interface{}{"bool": map[string]interface{}{"filter": filters}},
+		"sort":  []map[string]interface{}{{"day": map[string]interface{}{"order": "desc"}}},
+	}
+
+	// Archive records are shared by all organizations
+	response, err := a.osClient.Search(opensearch.WithoutOrgScope(ctx), []string{RecordIndex}, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search archive records: %w", err)
+	}
+
+	records := make([]Record, 0, len(response.Hits.Hits))
+	for _, hit := range response.Hits.Hits {
+		var record Record
+		if err := opensearch.DecodeSource(hit.Source, &record); err != nil {
+			return nil, fmt.Errorf("failed to decode archive record: %w", err)
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// scanObject returns the documents of a trace from an archived object
+func (a *Archiver) scanObject(ctx context.Context, key, traceID string) ([]map[string]interface{}, error) {
+	body, err := a.objects.GetObject(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	reader, err := gzip.NewReader(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read archived object %s: %w", key, err)
+	}
+	defer reader.Close()
+
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineBytes)
+
+	// Lines not mentioning the trace are skipped without decoding them
+	needle := []byte(traceID)
+
+	var sources []map[string]interface{}
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if !bytes.Contains(line, needle) {
+			continue
+		}
+		var document archiveLine
+		if err := json.Unmarshal(line, &document); err != nil {
+			return nil, fmt.Errorf("failed to decode archived document in %s: %w", key, err)
+		}
+		if id, _ := document.Source["traceId"].(string); id == traceID && opensearch.MatchesOrgScope(ctx, document.Source) {
+			sources = append(sources, document.Source)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read archived object %s: %w", key, err)
+	}
+	return sources, nil
+}
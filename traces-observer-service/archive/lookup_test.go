@@ -0,0 +1,99 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package archive
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/opensearch"
+)
+
+// archivedTraces archives three days of trace indices, trace-1 crosses from the second into the third day
+// and its id is reused on the first day
+func archivedTraces(t *testing.T) (*Archiver, *fakeArchiveOpenSearch) {
+	t.Helper()
+	fake := &fakeArchiveOpenSearch{indices: map[string][]string{
+		"otel-traces-2025-01-01": {spanDocument("trace-1", "reused")},
+		"otel-traces-2025-01-02": {spanDocument("trace-1", "root"), spanDocument("trace-2", "other")},
+		"otel-traces-2025-01-03": {
+			spanDocument("trace-1", "late"),
+			`{"traceId":"trace-1","spanId":"foreign","orgId":"org-2"}`,
+			// Mentions the trace without belonging to it
+			`{"traceId":"trace-3","spanId":"mention","attributes":{"input":"see trace-1"}}`,
+		},
+	}}
+	archiver := newTestArchiver(t, fake, &fakeObjectStore{}, Config{AfterDays: 30, MaxDocsPerObject: 1})
+	archiver.archiveExpired(context.Background())
+	if stats := archiver.Stats(); stats.ArchivedIndices != 3 {
+		t.Fatalf("stats = %+v, want 3 archived indices", stats)
+	}
+	return archiver, fake
+}
+
+// spanIDs returns the sorted span ids of documents
+func spanIDs(sources []map[string]interface{}) string {
+	ids := make([]string, 0, len(sources))
+	for _, source := range sources {
+		ids = append(ids, source["spanId"].(string))
+	}
+	sort.Strings(ids)
+	return strings.Join(ids, ",")
+}
+
+func TestFindTrace(t *testing.T) {
+	archiver, _ := archivedTraces(t)
+
+	sources, err := archiver.FindTrace(context.Background(), "trace-1", nil)
+	if err != nil {
+		t.Fatalf("FindTrace() error = %v", err)
+	}
+	// The day before the first match is searched too, older days are not
+	if got := spanIDs(sources); got != "foreign,late,root" {
+		t.Errorf("spans = %s, want the spans of the two most recent days", got)
+	}
+}
+
+func TestFindTraceInIndices(t *testing.T) {
+	archiver, fake := archivedTraces(t)
+
+	if _, err := archiver.FindTrace(context.Background(), "trace-1", []string{"otel-traces-2025-01-01"}); err != nil {
+		t.Fatalf("FindTrace() error = %v", err)
+	}
+	query := fake.recordQueries[len(fake.recordQueries)-1]
+	if !strings.Contains(query, `{"terms":{"index":["otel-traces-2025-01-01"]}}`) || !strings.Contains(query, `{"term":{"kind":"traces"}}`) {
+		t.Errorf("record query = %s, want the trace records of the given indices", query)
+	}
+}
+
+func TestFindTraceInOrganization(t *testing.T) {
+	archiver, fake := archivedTraces(t)
+
+	sources, err := archiver.FindTrace(opensearch.WithOrgScope(context.Background(), "org-2"), "trace-1", nil)
+	if err != nil {
+		t.Fatalf("FindTrace() error = %v", err)
+	}
+	if got := spanIDs(sources); got != "foreign" {
+		t.Errorf("spans = %s, want only the spans of the organization", got)
+	}
+	// Records are shared by every organization
+	if query := fake.recordQueries[len(fake.recordQueries)-1]; strings.Contains(query, opensearch.OrgIDField) {
+		t.Errorf("record query = %s, want no organization filter", query)
+	}
+}
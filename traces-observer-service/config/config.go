@@ -17,45 +17,638 @@
 package config
 
 import (
+	"errors"
 	"fmt"
+	"net/netip"
+	"net/url"
 	"os"
+	"runtime"
 	"strconv"
+	"strings"
+	"time"
 )
 
 // Config holds all configuration for the tracing service
 type Config struct {
-	Server     ServerConfig
-	OpenSearch OpenSearchConfig
-	LogLevel   string
+	File          string // Optional KEY=VALUE file read by CONFIG_FILE, re-read on reload
+	Server        ServerConfig
+	CORS          CORSConfig
+	Admin         AdminConfig
+	Reload        ReloadConfig
+	OpenSearch    OpenSearchConfig
+	Pricing       PricingConfig
+	Limits        LimitsConfig
+	Passthrough   PassthroughConfig
+	Redaction     RedactionConfig
+	Media         MediaConfig
+	Indexing      IndexingConfig
+	OTLP          OTLPConfig
+	Processing    ProcessingConfig
+	Kafka         KafkaConfig
+	Sampling      SamplingConfig
+	Lifecycle     LifecycleConfig
+	Metrics       MetricsConfig
+	QueryCache    QueryCacheConfig
+	Export        ExportConfig
+	Finalization  FinalizationConfig
+	Notifications NotificationsConfig
+	LiveTraces    LiveTracesConfig
+	Alerts        AlertsConfig
+	Forwarding    ForwardingConfig
+	Langfuse      LangfuseConfig
+	Archive       ArchiveConfig
+	Tenancy       TenancyConfig
+	AgentLabels   AgentLabelsConfig
+	Logging       LoggingConfig
+	Tracing       TracingConfig
 }
 
 // ServerConfig holds HTTP server configuration
 type ServerConfig struct {
-	Port int
+	Port                   int
+	ShutdownTimeout        time.Duration // Deadline for draining receivers, queued spans and bulk requests
+	ReadinessRetryInterval time.Duration // Interval between attempts to verify OpenSearch until the service is ready
+	RequestTimeout         time.Duration // Deadline of an API request, after which it is cancelled with 503
+	MaxRequestBodyBytes    int           // Largest accepted API request body, OTLP requests are bounded by OTLP.MaxRequestBytes
+	CompressionEnabled     bool          // Compress responses with gzip or deflate when the client accepts it
+	CompressionMinBytes    int           // Responses shorter than this are sent uncompressed
 }
 
 // OpenSearchConfig holds OpenSearch connection configuration
 type OpenSearchConfig struct {
-	Address  string
-	Username string
-	Password string
+	Address        string
+	Username       string
+	Password       string
+	ManageMappings bool // Install the trace index template and migrate existing index mappings at startup
+
+	MappingDynamic          string            // Mapping of unknown top-level fields of trace documents: strict, false or true
+	MappingTotalFieldsLimit int               // Maximum number of fields per trace index, the OpenSearch default when zero
+	FieldMappings           map[string]string // Types of custom attributes.* and resource.* fields, from field=type entries
+	invalidFieldMappings    []string
+
+	RequestTimeout          time.Duration // Timeout of a single request attempt
+	MaxRetries              int           // Retries of requests failing with 429, 502 or 503
+	RetryBackoff            time.Duration // Base of the exponential retry backoff
+	MaxRetryBackoff         time.Duration
+	BreakerFailureThreshold int           // Consecutive failures that open the circuit breaker, disabled when zero
+	BreakerOpenTimeout      time.Duration // Time the breaker stays open before probing for recovery
+}
+
+// CORSConfig holds the CORS settings of the HTTP API
+type CORSConfig struct {
+	AllowedOrigins []string // Origins allowed to call the API, "*" allows every origin
+}
+
+// AdminConfig holds configuration of the admin endpoints
+type AdminConfig struct {
+	APIKey string // Key required by POST /admin/reload, which is not served without one
+}
+
+// ReloadConfig holds configuration of applying changed settings without a restart
+type ReloadConfig struct {
+	WatchInterval time.Duration // How often the config, pricing and redaction files are checked for changes, not watched when zero
+}
+
+// PricingConfig holds model pricing configuration
+type PricingConfig struct {
+	TablePath string // Optional JSON/YAML pricing table merged over the built-in defaults
+}
+
+// LimitsConfig holds size limits (in bytes) applied to processed spans
+type LimitsConfig struct {
+	MaxInputBytes        int
+	MaxOutputBytes       int
+	MaxSystemPromptBytes int
+	MaxAttributeBytes    int
+	MaxParseBytes        int // JSON attribute strings above this size are not decoded during extraction
+}
+
+// PassthroughConfig holds configuration of copying raw span attributes into searchable custom attributes
+type PassthroughConfig struct {
+	AllowedPrefixes []string // Attribute key prefixes copied, the passthrough is disabled when empty
+	DeniedPrefixes  []string // Attribute key prefixes never copied, taking precedence over the allowed prefixes
+	MaxValueBytes   int
+	MaxAttributes   int // Attributes copied per span
+}
+
+// RedactionConfig holds PII redaction configuration
+type RedactionConfig struct {
+	Enabled   bool
+	RulesPath string // Optional JSON/YAML file with rules added to the built-in rules
+}
+
+// MediaConfig holds configuration of storing the media payloads removed from span content
+type MediaConfig struct {
+	StoreEnabled    bool // Upload removed payloads to the bucket, otherwise only placeholders are kept
+	Endpoint        string
+	Region          string
+	Bucket          string
+	Prefix          string
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+	PathStyle       bool
+	RequestTimeout  time.Duration
+}
+
+// IndexingConfig holds bulk indexing configuration
+type IndexingConfig struct {
+	BatchSize      int           // Maximum documents per bulk request
+	BatchBytes     int           // Maximum body size of a bulk request
+	FlushInterval  time.Duration // Maximum time a document waits in a partial batch
+	MaxInFlight    int           // Maximum concurrent bulk requests
+	MaxRetries     int           // Retries of documents rejected with 429/503
+	DeadLetterPath string        // File receiving permanently failed documents, logged when empty
+	MaxSpillDocs   int           // Documents held in memory while the OpenSearch circuit breaker is open
+	// Keep permanently failed documents in the dead-letter index, where they can be browsed and retried,
+	// before falling back to DeadLetterPath
+	DeadLetterIndexEnabled bool
+	DeadLetterRetention    time.Duration // Dead letters not retried successfully are deleted afterwards
+}
+
+// OTLPConfig holds OTLP receiver configuration
+type OTLPConfig struct {
+	MaxRequestBytes int // Maximum decompressed size of an export request
+	GRPC            OTLPGRPCConfig
+}
+
+// OTLPGRPCConfig holds OTLP/gRPC receiver configuration
+type OTLPGRPCConfig struct {
+	Enabled         bool
+	Port            int
+	MaxRecvMsgBytes int    // Maximum size of a received message, agent traces often exceed the 4 MB gRPC default
+	TLSCertFile     string // TLS is enabled when both the certificate and key are set
+	TLSKeyFile      string
+	ShutdownTimeout time.Duration // Time allowed for in-flight exports to drain before the server is stopped
+}
+
+// ProcessingConfig holds configuration of the span processing worker pool
+type ProcessingConfig struct {
+	Workers        int
+	MaxQueuedSpans int // Spans queued for processing before export requests are rejected
+}
+
+// KafkaConfig holds configuration of the optional Kafka consumer
+type KafkaConfig struct {
+	Enabled               bool
+	Brokers               []string
+	Topic                 string
+	GroupID               string
+	Encoding              string        // otlp_proto or otlp_json
+	BatchSize             int           // Maximum messages committed together
+	BatchWait             time.Duration // Maximum time spent filling a batch after its first message
+	MaxMessageBytes       int
+	MaxDecodeAttempts     int    // Decoding attempts before a message is sent to the dead-letter topic
+	DeadLetterTopic       string // Undecodable messages are logged and skipped when empty
+	TLSEnabled            bool
+	TLSCAFile             string
+	TLSCertFile           string
+	TLSKeyFile            string
+	TLSInsecureSkipVerify bool
+	SASLMechanism         string // PLAIN, SCRAM-SHA-256 or SCRAM-SHA-512
+	SASLUsername          string
+	SASLPassword          string
+}
+
+// SamplingConfig holds tail sampling configuration
+type SamplingConfig struct {
+	Enabled           bool
+	DecisionWait      time.Duration // Time spans of a trace are buffered before deciding whether to keep it
+	DurationThreshold time.Duration // Keep traces lasting longer, disabled when zero
+	TokenThreshold    int           // Keep traces using more tokens, disabled when zero
+	SamplePercentage  float64       // Percentage of the remaining traces kept at random
+	MaxBufferedBytes  int           // Memory cap of the span buffer
+}
+
+// LifecycleConfig holds daily index lifecycle configuration
+type LifecycleConfig struct {
+	Enabled                    bool          // Write through the daily write alias managed by the service
+	RetentionDays              int           // Delete spans without a retention of their agent and rollups older than this, disabled when zero
+	CheckInterval              time.Duration // Interval of the rollover and retention checks
+	RetentionRequestsPerSecond int           // Spans deleted per second from indices still holding spans kept longer, unthrottled when zero
+	RetentionBatchSize         int           // Spans deleted per batch from indices still holding spans kept longer
+}
+
+// MetricsConfig holds dashboard metrics configuration
+type MetricsConfig struct {
+	MaxBuckets        int  // Maximum number of time buckets per series, disabled when zero
+	PrometheusEnabled bool // Serve Prometheus metrics of the service on /metrics
+}
+
+// QueryCacheConfig holds configuration of caching the results of dashboard aggregations in memory
+type QueryCacheConfig struct {
+	Enabled    bool
+	MaxEntries int           // Maximum number of cached results
+	MaxBytes   int           // Maximum total size of the cached results
+	MetricsTTL time.Duration // Time trace metrics are cached, not cached when zero
+	ToolsTTL   time.Duration // Time tool metrics are cached, not cached when zero
+	ModelsTTL  time.Duration // Time model metrics are cached, not cached when zero
+}
+
+// LoggingConfig holds configuration of the service logs
+type LoggingConfig struct {
+	Level              string        // DEBUG, INFO, WARN or ERROR
+	LevelFile          string        // File holding the level, re-read on SIGHUP
+	SamplingInitial    int           // Debug records with the same message written per interval
+	SamplingThereafter int           // Every n-th further debug record is written, none when zero
+	SamplingInterval   time.Duration // Debug records are not sampled when zero
+}
+
+// TracingConfig holds configuration of the traces the service records about itself
+type TracingConfig struct {
+	OTLPEndpoint string  // OTLP/HTTP traces endpoint, self-tracing is disabled when empty
+	SampleRatio  float64 // Share of requests and batches traced unless the caller sampled the trace
+}
+
+// ExportConfig holds trace export configuration
+type ExportConfig struct {
+	MaxRows int           // Maximum number of traces per export
+	Timeout time.Duration // Deadline of an export, which replaces the request timeout; disabled when zero
+}
+
+// FinalizationConfig holds configuration of deciding when a trace is complete
+type FinalizationConfig struct {
+	QuietPeriod       time.Duration // A trace is finalized once no span arrived for this long
+	MaxAge            time.Duration // A trace is finalized this long after its first span at the latest
+	RollupDelay       time.Duration // A trace is rolled up onto its root span once no span of it was indexed for this long
+	MaxPendingRollups int           // Traces waiting to be rolled up, the oldest are rolled up early when exceeded
+}
+
+// NotificationsConfig holds webhook notification configuration
+type NotificationsConfig struct {
+	Enabled             bool
+	MaxPendingTraces    int           // Memory cap of the pending trace buffer
+	RuleRefreshInterval time.Duration // Interval of reloading rules changed on other replicas
+	Workers             int
+	QueueSize           int
+	MaxRetries          int
+	RetryBackoff        time.Duration
+	RequestTimeout      time.Duration
+	TraceLinkTemplate   string // Deep link of a trace, e.g. https://console.example.com/traces/{traceId}
+}
+
+// LiveTracesConfig holds configuration of the live trace stream
+type LiveTracesConfig struct {
+	Enabled           bool
+	MaxTraces         int           // Memory cap of the traces followed for the stream
+	MaxClients        int           // Concurrent stream clients, further clients are refused with 503
+	ClientBuffer      int           // Events buffered per client, a client falling further behind is disconnected
+	UpdateInterval    time.Duration // Changes to a trace within the interval are sent as one event
+	HeartbeatInterval time.Duration // Interval of the comments keeping idle streams open through proxies
+
+	// Tails of single traces over WebSocket, sharing the client buffer and heartbeat interval of the stream
+	TailMaxConnections          int    // Concurrent tails, further tails are refused with 429
+	TailMaxConnectionsPerClient int    // Concurrent tails of a single client address, further tails are refused with 429
+	TailClientIPHeader          string // Header carrying the client address behind a proxy, empty to use the connection
+	// Proxies whose entries in TailClientIPHeader are trusted, the header is ignored on connections from other peers
+	TailTrustedProxies        []netip.Prefix
+	invalidTailTrustedProxies []string
+	TailMaxJournal            int           // Messages kept per trace for resuming, older positions get a new snapshot
+	TailCatchUpDelay          time.Duration // Delay after which a tailed trace is read again to pick up spans being indexed
+}
+
+// AlertsConfig holds configuration of alerting on aggregate trace metrics
+type AlertsConfig struct {
+	Enabled            bool
+	EvaluationInterval time.Duration // Interval of evaluating every enabled alert rule
+	QueueSize          int
+	MaxRetries         int
+	RetryBackoff       time.Duration
+	RequestTimeout     time.Duration
+}
+
+// ForwardingConfig holds configuration of re-exporting processed spans to downstream OTLP endpoints
+type ForwardingConfig struct {
+	DestinationsPath string        // JSON/YAML file of destinations, forwarding is disabled when empty
+	BatchSize        int           // Maximum spans per export request
+	FlushInterval    time.Duration // Maximum time a span waits in a partial batch
+	QueueSize        int           // Spans waiting per destination, further spans are dropped
+	MaxRetries       int
+	RetryBackoff     time.Duration
+	RequestTimeout   time.Duration
+}
+
+// LangfuseConfig holds configuration of exporting finalized traces to Langfuse
+type LangfuseConfig struct {
+	Enabled        bool
+	Host           string
+	PublicKey      string
+	SecretKey      string
+	PollInterval   time.Duration // Interval of looking for newly finalized traces
+	FinalizeWait   time.Duration // Time after the root span ends before a trace is exported
+	BatchSize      int           // Traces exported per ingestion round
+	Lookback       time.Duration // Traces ending this long before the first start are exported
+	MaxRetries     int
+	RetryBackoff   time.Duration
+	RequestTimeout time.Duration
+}
+
+// TenancyConfig holds configuration of the organizations spans are indexed for
+type TenancyConfig struct {
+	DefaultOrgID      string            // Organization of spans exported without one and of documents indexed before organizations were recorded
+	OrgAttribute      string            // Resource attribute holding the organization of exports without an ingest key
+	IngestKeys        map[string]string // Organization of every ingest key, from key=orgId entries
+	invalidIngestKeys []string
+}
+
+// AgentLabelsConfig holds configuration of copying the labels of managed agents onto indexed spans
+type AgentLabelsConfig struct {
+	ManagerURL      string // Base URL of the agent manager, labels are not copied when empty
+	APIKeyHeader    string
+	APIKey          string        // Key of the internal API of the agent manager
+	AgentAttribute  string        // Resource attribute holding the id of the managed agent
+	CacheTTL        time.Duration // Time the labels of an agent are reused before the agent manager is asked again
+	MaxCachedAgents int
+	RequestTimeout  time.Duration
+}
+
+// ArchiveConfig holds configuration of archiving expired daily indices to an S3-compatible bucket
+type ArchiveConfig struct {
+	Enabled          bool
+	DryRun           bool          // Only log what would be archived, nothing is uploaded or deleted
+	AfterDays        int           // Daily indices older than this are archived and then deleted
+	Interval         time.Duration // Interval of looking for indices to archive
+	Endpoint         string        // S3 endpoint, AWS S3 of the region when empty
+	Region           string
+	Bucket           string
+	Prefix           string // Key prefix of every object
+	PathTemplate     string // Key template of an index with {yyyy}, {mm}, {dd} and {index} placeholders
+	AccessKeyID      string
+	SecretAccessKey  string
+	SessionToken     string
+	PathStyle        bool // Address the bucket in the path instead of the host name
+	MaxDocsPerObject int
+	LookupIndices    int // Most recently archived trace indices searched by trace lookups
+	RequestTimeout   time.Duration
 }
 
 // Load loads configuration from environment variables with defaults
 func Load() (*Config, error) {
+	file := os.Getenv("CONFIG_FILE")
+	values, err := readConfigFile(file)
+	if err != nil {
+		return nil, err
+	}
+	env := &envSource{file: values}
+
 	cfg := &Config{
+		File: file,
 		Server: ServerConfig{
-			Port: getEnvAsInt("TRACES_OBSERVER_PORT", 9098),
+			Port:                   env.getEnvAsInt("TRACES_OBSERVER_PORT", 9098),
+			ShutdownTimeout:        env.getEnvAsDuration("SHUTDOWN_TIMEOUT", 25*time.Second),
+			ReadinessRetryInterval: env.getEnvAsDuration("READINESS_RETRY_INTERVAL", 5*time.Second),
+			RequestTimeout:         env.getEnvAsDuration("REQUEST_TIMEOUT", 30*time.Second),
+			MaxRequestBodyBytes:    env.getEnvAsInt("MAX_REQUEST_BODY_BYTES", 1024*1024),
+			CompressionEnabled:     env.getEnvAsBool("RESPONSE_COMPRESSION_ENABLED", true),
+			CompressionMinBytes:    env.getEnvAsInt("RESPONSE_COMPRESSION_MIN_BYTES", 1024),
+		},
+		CORS: CORSConfig{
+			AllowedOrigins: env.getEnvAsList("CORS_ALLOWED_ORIGINS", []string{"*"}),
+		},
+		Admin: AdminConfig{
+			APIKey: env.getEnv("ADMIN_API_KEY", ""),
+		},
+		Reload: ReloadConfig{
+			WatchInterval: env.getEnvAsDuration("CONFIG_WATCH_INTERVAL", 10*time.Second),
 		},
 		OpenSearch: OpenSearchConfig{
-			Address:  getEnv("OPENSEARCH_ADDRESS", "https://localhost:9200"),
-			Username: getEnv("OPENSEARCH_USERNAME", ""),
-			Password: getEnv("OPENSEARCH_PASSWORD", ""),
+			Address:        env.getEnv("OPENSEARCH_ADDRESS", "https://localhost:9200"),
+			Username:       env.getEnv("OPENSEARCH_USERNAME", ""),
+			Password:       env.getEnv("OPENSEARCH_PASSWORD", ""),
+			ManageMappings: env.getEnvAsBool("OPENSEARCH_MANAGE_MAPPINGS", false),
+
+			MappingDynamic:          env.getEnv("OPENSEARCH_MAPPING_DYNAMIC", "false"),
+			MappingTotalFieldsLimit: env.getEnvAsInt("OPENSEARCH_MAPPING_TOTAL_FIELDS_LIMIT", 2000),
+
+			RequestTimeout:          env.getEnvAsDuration("OPENSEARCH_REQUEST_TIMEOUT", 30*time.Second),
+			MaxRetries:              env.getEnvAsInt("OPENSEARCH_MAX_RETRIES", 3),
+			RetryBackoff:            env.getEnvAsDuration("OPENSEARCH_RETRY_BACKOFF", 100*time.Millisecond),
+			MaxRetryBackoff:         env.getEnvAsDuration("OPENSEARCH_MAX_RETRY_BACKOFF", 5*time.Second),
+			BreakerFailureThreshold: env.getEnvAsInt("OPENSEARCH_BREAKER_FAILURE_THRESHOLD", 5),
+			BreakerOpenTimeout:      env.getEnvAsDuration("OPENSEARCH_BREAKER_OPEN_TIMEOUT", 30*time.Second),
+		},
+		Pricing: PricingConfig{
+			TablePath: env.getEnv("PRICING_TABLE_PATH", ""),
+		},
+		Limits: LimitsConfig{
+			MaxInputBytes:        env.getEnvAsInt("MAX_INPUT_BYTES", 64*1024),
+			MaxOutputBytes:       env.getEnvAsInt("MAX_OUTPUT_BYTES", 64*1024),
+			MaxSystemPromptBytes: env.getEnvAsInt("MAX_SYSTEM_PROMPT_BYTES", 64*1024),
+			MaxAttributeBytes:    env.getEnvAsInt("MAX_ATTRIBUTE_BYTES", 64*1024),
+			MaxParseBytes:        env.getEnvAsInt("MAX_PARSE_BYTES", 1024*1024),
+		},
+		Passthrough: PassthroughConfig{
+			AllowedPrefixes: env.getEnvAsList("PASSTHROUGH_ALLOWED_PREFIXES", nil),
+			DeniedPrefixes:  env.getEnvAsList("PASSTHROUGH_DENIED_PREFIXES", nil),
+			MaxValueBytes:   env.getEnvAsInt("PASSTHROUGH_MAX_VALUE_BYTES", 256),
+			MaxAttributes:   env.getEnvAsInt("PASSTHROUGH_MAX_ATTRIBUTES", 64),
+		},
+		Redaction: RedactionConfig{
+			Enabled:   env.getEnvAsBool("REDACTION_ENABLED", true),
+			RulesPath: env.getEnv("REDACTION_RULES_PATH", ""),
+		},
+		Media: MediaConfig{
+			StoreEnabled:    env.getEnvAsBool("MEDIA_STORE_ENABLED", false),
+			Endpoint:        env.getEnv("MEDIA_S3_ENDPOINT", ""),
+			Region:          env.getEnv("MEDIA_S3_REGION", "us-east-1"),
+			Bucket:          env.getEnv("MEDIA_S3_BUCKET", ""),
+			Prefix:          env.getEnv("MEDIA_PREFIX", "media"),
+			AccessKeyID:     env.getEnv("MEDIA_S3_ACCESS_KEY_ID", os.Getenv("AWS_ACCESS_KEY_ID")),
+			SecretAccessKey: env.getEnv("MEDIA_S3_SECRET_ACCESS_KEY", os.Getenv("AWS_SECRET_ACCESS_KEY")),
+			SessionToken:    env.getEnv("MEDIA_S3_SESSION_TOKEN", os.Getenv("AWS_SESSION_TOKEN")),
+			PathStyle:       env.getEnvAsBool("MEDIA_S3_PATH_STYLE", false),
+			RequestTimeout:  env.getEnvAsDuration("MEDIA_REQUEST_TIMEOUT", 10*time.Second),
+		},
+		Indexing: IndexingConfig{
+			BatchSize:              env.getEnvAsInt("INDEXING_BATCH_SIZE", 500),
+			BatchBytes:             env.getEnvAsInt("INDEXING_BATCH_BYTES", 5*1024*1024),
+			FlushInterval:          env.getEnvAsDuration("INDEXING_FLUSH_INTERVAL", 2*time.Second),
+			MaxInFlight:            env.getEnvAsInt("INDEXING_MAX_IN_FLIGHT", 2),
+			MaxRetries:             env.getEnvAsInt("INDEXING_MAX_RETRIES", 5),
+			DeadLetterPath:         env.getEnv("INDEXING_DEAD_LETTER_PATH", ""),
+			MaxSpillDocs:           env.getEnvAsInt("INDEXING_MAX_SPILL_DOCS", 10000),
+			DeadLetterIndexEnabled: env.getEnvAsBool("INDEXING_DEAD_LETTER_INDEX_ENABLED", true),
+			DeadLetterRetention:    env.getEnvAsDuration("INDEXING_DEAD_LETTER_RETENTION", 7*24*time.Hour),
+		},
+		Processing: ProcessingConfig{
+			Workers:        env.getEnvAsInt("PROCESSING_WORKERS", runtime.NumCPU()),
+			MaxQueuedSpans: env.getEnvAsInt("PROCESSING_MAX_QUEUED_SPANS", 10000),
+		},
+		OTLP: OTLPConfig{
+			MaxRequestBytes: env.getEnvAsInt("OTLP_MAX_REQUEST_BYTES", 32*1024*1024),
+			GRPC: OTLPGRPCConfig{
+				Enabled:         env.getEnvAsBool("OTLP_GRPC_ENABLED", true),
+				Port:            env.getEnvAsInt("OTLP_GRPC_PORT", 4317),
+				MaxRecvMsgBytes: env.getEnvAsInt("OTLP_GRPC_MAX_RECV_MSG_BYTES", 32*1024*1024),
+				TLSCertFile:     env.getEnv("OTLP_GRPC_TLS_CERT_FILE", ""),
+				TLSKeyFile:      env.getEnv("OTLP_GRPC_TLS_KEY_FILE", ""),
+				ShutdownTimeout: env.getEnvAsDuration("OTLP_GRPC_SHUTDOWN_TIMEOUT", 10*time.Second),
+			},
+		},
+		Kafka: KafkaConfig{
+			Enabled:               env.getEnvAsBool("KAFKA_ENABLED", false),
+			Brokers:               env.getEnvAsList("KAFKA_BROKERS", []string{"localhost:9092"}),
+			Topic:                 env.getEnv("KAFKA_TOPIC", "otlp_spans"),
+			GroupID:               env.getEnv("KAFKA_GROUP_ID", "traces-observer-service"),
+			Encoding:              env.getEnv("KAFKA_ENCODING", "otlp_proto"),
+			BatchSize:             env.getEnvAsInt("KAFKA_BATCH_SIZE", 100),
+			BatchWait:             env.getEnvAsDuration("KAFKA_BATCH_WAIT", time.Second),
+			MaxMessageBytes:       env.getEnvAsInt("KAFKA_MAX_MESSAGE_BYTES", 32*1024*1024),
+			MaxDecodeAttempts:     env.getEnvAsInt("KAFKA_MAX_DECODE_ATTEMPTS", 3),
+			DeadLetterTopic:       env.getEnv("KAFKA_DEAD_LETTER_TOPIC", ""),
+			TLSEnabled:            env.getEnvAsBool("KAFKA_TLS_ENABLED", false),
+			TLSCAFile:             env.getEnv("KAFKA_TLS_CA_FILE", ""),
+			TLSCertFile:           env.getEnv("KAFKA_TLS_CERT_FILE", ""),
+			TLSKeyFile:            env.getEnv("KAFKA_TLS_KEY_FILE", ""),
+			TLSInsecureSkipVerify: env.getEnvAsBool("KAFKA_TLS_INSECURE_SKIP_VERIFY", false),
+			SASLMechanism:         env.getEnv("KAFKA_SASL_MECHANISM", ""),
+			SASLUsername:          env.getEnv("KAFKA_SASL_USERNAME", ""),
+			SASLPassword:          env.getEnv("KAFKA_SASL_PASSWORD", ""),
+		},
+		Sampling: SamplingConfig{
+			Enabled:           env.getEnvAsBool("SAMPLING_ENABLED", false),
+			DecisionWait:      env.getEnvAsDuration("SAMPLING_DECISION_WAIT", 30*time.Second),
+			DurationThreshold: env.getEnvAsDuration("SAMPLING_DURATION_THRESHOLD", 30*time.Second),
+			TokenThreshold:    env.getEnvAsInt("SAMPLING_TOKEN_THRESHOLD", 50000),
+			SamplePercentage:  env.getEnvAsFloat("SAMPLING_PERCENTAGE", 10),
+			MaxBufferedBytes:  env.getEnvAsInt("SAMPLING_MAX_BUFFERED_BYTES", 256*1024*1024),
+		},
+		Lifecycle: LifecycleConfig{
+			Enabled:                    env.getEnvAsBool("INDEX_LIFECYCLE_ENABLED", true),
+			RetentionDays:              env.getEnvAsInt("INDEX_RETENTION_DAYS", 0),
+			CheckInterval:              env.getEnvAsDuration("INDEX_LIFECYCLE_CHECK_INTERVAL", time.Hour),
+			RetentionRequestsPerSecond: env.getEnvAsInt("INDEX_RETENTION_REQUESTS_PER_SECOND", 500),
+			RetentionBatchSize:         env.getEnvAsInt("INDEX_RETENTION_BATCH_SIZE", 1000),
+		},
+		Metrics: MetricsConfig{
+			MaxBuckets:        env.getEnvAsInt("METRICS_MAX_BUCKETS", 1440),
+			PrometheusEnabled: env.getEnvAsBool("PROMETHEUS_METRICS_ENABLED", true),
+		},
+		QueryCache: QueryCacheConfig{
+			Enabled:    env.getEnvAsBool("QUERY_CACHE_ENABLED", true),
+			MaxEntries: env.getEnvAsInt("QUERY_CACHE_MAX_ENTRIES", 1000),
+			MaxBytes:   env.getEnvAsInt("QUERY_CACHE_MAX_BYTES", 64<<20),
+			MetricsTTL: env.getEnvAsDuration("QUERY_CACHE_METRICS_TTL", 15*time.Second),
+			ToolsTTL:   env.getEnvAsDuration("QUERY_CACHE_TOOLS_TTL", 60*time.Second),
+			ModelsTTL:  env.getEnvAsDuration("QUERY_CACHE_MODELS_TTL", 60*time.Second),
+		},
+		Export: ExportConfig{
+			MaxRows: env.getEnvAsInt("EXPORT_MAX_ROWS", 10000),
+			Timeout: env.getEnvAsDuration("EXPORT_TIMEOUT", 10*time.Minute),
+		},
+		// The finalization settings default to the notification settings they replace
+		Finalization: FinalizationConfig{
+			QuietPeriod:       env.getEnvAsDuration("TRACE_FINALIZE_QUIET_PERIOD", env.getEnvAsDuration("NOTIFICATIONS_FINALIZE_WAIT", 10*time.Second)),
+			MaxAge:            env.getEnvAsDuration("TRACE_FINALIZE_MAX_AGE", env.getEnvAsDuration("NOTIFICATIONS_PENDING_TRACE_TTL", 10*time.Minute)),
+			RollupDelay:       env.getEnvAsDuration("TRACE_ROLLUP_DELAY", 5*time.Second),
+			MaxPendingRollups: env.getEnvAsInt("TRACE_ROLLUP_MAX_PENDING", 10000),
+		},
+		Notifications: NotificationsConfig{
+			Enabled:             env.getEnvAsBool("NOTIFICATIONS_ENABLED", false),
+			MaxPendingTraces:    env.getEnvAsInt("NOTIFICATIONS_MAX_PENDING_TRACES", 100000),
+			RuleRefreshInterval: env.getEnvAsDuration("NOTIFICATIONS_RULE_REFRESH_INTERVAL", 30*time.Second),
+			Workers:             env.getEnvAsInt("NOTIFICATIONS_WORKERS", 2),
+			QueueSize:           env.getEnvAsInt("NOTIFICATIONS_QUEUE_SIZE", 1000),
+			MaxRetries:          env.getEnvAsInt("NOTIFICATIONS_MAX_RETRIES", 3),
+			RetryBackoff:        env.getEnvAsDuration("NOTIFICATIONS_RETRY_BACKOFF", time.Second),
+			RequestTimeout:      env.getEnvAsDuration("NOTIFICATIONS_REQUEST_TIMEOUT", 10*time.Second),
+			TraceLinkTemplate:   env.getEnv("NOTIFICATIONS_TRACE_LINK_TEMPLATE", ""),
+		},
+		LiveTraces: LiveTracesConfig{
+			Enabled:           env.getEnvAsBool("LIVE_TRACES_ENABLED", true),
+			MaxTraces:         env.getEnvAsInt("LIVE_TRACES_MAX_TRACES", 10000),
+			MaxClients:        env.getEnvAsInt("LIVE_TRACES_MAX_CLIENTS", 1000),
+			ClientBuffer:      env.getEnvAsInt("LIVE_TRACES_CLIENT_BUFFER", 256),
+			UpdateInterval:    env.getEnvAsDuration("LIVE_TRACES_UPDATE_INTERVAL", time.Second),
+			HeartbeatInterval: env.getEnvAsDuration("LIVE_TRACES_HEARTBEAT_INTERVAL", 15*time.Second),
+
+			TailMaxConnections:          env.getEnvAsInt("LIVE_TRACES_TAIL_MAX_CONNECTIONS", 1000),
+			TailMaxConnectionsPerClient: env.getEnvAsInt("LIVE_TRACES_TAIL_MAX_CONNECTIONS_PER_CLIENT", 5),
+			TailClientIPHeader:          env.getEnv("LIVE_TRACES_TAIL_CLIENT_IP_HEADER", ""),
+			TailMaxJournal:              env.getEnvAsInt("LIVE_TRACES_TAIL_MAX_JOURNAL", 10000),
+			TailCatchUpDelay:            env.getEnvAsDuration("LIVE_TRACES_TAIL_CATCH_UP_DELAY", 3*time.Second),
+		},
+		Alerts: AlertsConfig{
+			Enabled:            env.getEnvAsBool("ALERTS_ENABLED", false),
+			EvaluationInterval: env.getEnvAsDuration("ALERTS_EVALUATION_INTERVAL", time.Minute),
+			QueueSize:          env.getEnvAsInt("ALERTS_QUEUE_SIZE", 1000),
+			MaxRetries:         env.getEnvAsInt("ALERTS_MAX_RETRIES", 3),
+			RetryBackoff:       env.getEnvAsDuration("ALERTS_RETRY_BACKOFF", time.Second),
+			RequestTimeout:     env.getEnvAsDuration("ALERTS_REQUEST_TIMEOUT", 10*time.Second),
+		},
+		Forwarding: ForwardingConfig{
+			DestinationsPath: env.getEnv("FORWARDING_DESTINATIONS_PATH", ""),
+			BatchSize:        env.getEnvAsInt("FORWARDING_BATCH_SIZE", 512),
+			FlushInterval:    env.getEnvAsDuration("FORWARDING_FLUSH_INTERVAL", 5*time.Second),
+			QueueSize:        env.getEnvAsInt("FORWARDING_QUEUE_SIZE", 10000),
+			MaxRetries:       env.getEnvAsInt("FORWARDING_MAX_RETRIES", 3),
+			RetryBackoff:     env.getEnvAsDuration("FORWARDING_RETRY_BACKOFF", time.Second),
+			RequestTimeout:   env.getEnvAsDuration("FORWARDING_REQUEST_TIMEOUT", 10*time.Second),
+		},
+		Langfuse: LangfuseConfig{
+			Enabled:        env.getEnvAsBool("LANGFUSE_EXPORT_ENABLED", false),
+			Host:           env.getEnv("LANGFUSE_HOST", "https://cloud.langfuse.com"),
+			PublicKey:      env.getEnv("LANGFUSE_PUBLIC_KEY", ""),
+			SecretKey:      env.getEnv("LANGFUSE_SECRET_KEY", ""),
+			PollInterval:   env.getEnvAsDuration("LANGFUSE_EXPORT_POLL_INTERVAL", 30*time.Second),
+			FinalizeWait:   env.getEnvAsDuration("LANGFUSE_EXPORT_FINALIZE_WAIT", 2*time.Minute),
+			BatchSize:      env.getEnvAsInt("LANGFUSE_EXPORT_BATCH_SIZE", 50),
+			Lookback:       env.getEnvAsDuration("LANGFUSE_EXPORT_LOOKBACK", 0),
+			MaxRetries:     env.getEnvAsInt("LANGFUSE_MAX_RETRIES", 3),
+			RetryBackoff:   env.getEnvAsDuration("LANGFUSE_RETRY_BACKOFF", time.Second),
+			RequestTimeout: env.getEnvAsDuration("LANGFUSE_REQUEST_TIMEOUT", 30*time.Second),
+		},
+		Archive: ArchiveConfig{
+			Enabled:          env.getEnvAsBool("ARCHIVE_ENABLED", false),
+			DryRun:           env.getEnvAsBool("ARCHIVE_DRY_RUN", false),
+			AfterDays:        env.getEnvAsInt("ARCHIVE_AFTER_DAYS", 30),
+			Interval:         env.getEnvAsDuration("ARCHIVE_INTERVAL", time.Hour),
+			Endpoint:         env.getEnv("ARCHIVE_S3_ENDPOINT", ""),
+			Region:           env.getEnv("ARCHIVE_S3_REGION", "us-east-1"),
+			Bucket:           env.getEnv("ARCHIVE_S3_BUCKET", ""),
+			Prefix:           env.getEnv("ARCHIVE_PREFIX", "traces"),
+			PathTemplate:     env.getEnv("ARCHIVE_PATH_TEMPLATE", "{yyyy}/{mm}/{dd}/{index}"),
+			AccessKeyID:      env.getEnv("ARCHIVE_S3_ACCESS_KEY_ID", os.Getenv("AWS_ACCESS_KEY_ID")),
+			SecretAccessKey:  env.getEnv("ARCHIVE_S3_SECRET_ACCESS_KEY", os.Getenv("AWS_SECRET_ACCESS_KEY")),
+			SessionToken:     env.getEnv("ARCHIVE_S3_SESSION_TOKEN", os.Getenv("AWS_SESSION_TOKEN")),
+			PathStyle:        env.getEnvAsBool("ARCHIVE_S3_PATH_STYLE", false),
+			MaxDocsPerObject: env.getEnvAsInt("ARCHIVE_MAX_DOCS_PER_OBJECT", 100000),
+			LookupIndices:    env.getEnvAsInt("ARCHIVE_LOOKUP_INDICES", 7),
+			RequestTimeout:   env.getEnvAsDuration("ARCHIVE_REQUEST_TIMEOUT", 5*time.Minute),
+		},
+		Tenancy: TenancyConfig{
+			DefaultOrgID: env.getEnv("DEFAULT_ORG_ID", "af779290-c22d-4100-aefd-484d81fff60e"),
+			OrgAttribute: env.getEnv("ORG_RESOURCE_ATTRIBUTE", "amp.org.id"),
+		},
+		AgentLabels: AgentLabelsConfig{
+			ManagerURL:      env.getEnv("AGENT_LABELS_MANAGER_URL", ""),
+			APIKeyHeader:    env.getEnv("AGENT_LABELS_API_KEY_HEADER", "X-API-KEY"),
+			APIKey:          env.getEnv("AGENT_LABELS_API_KEY", ""),
+			AgentAttribute:  env.getEnv("AGENT_ID_RESOURCE_ATTRIBUTE", "amp.agent.id"),
+			CacheTTL:        env.getEnvAsDuration("AGENT_LABELS_CACHE_TTL", 5*time.Minute),
+			MaxCachedAgents: env.getEnvAsInt("AGENT_LABELS_MAX_CACHED_AGENTS", 10000),
+			RequestTimeout:  env.getEnvAsDuration("AGENT_LABELS_REQUEST_TIMEOUT", 5*time.Second),
+		},
+		Logging: LoggingConfig{
+			Level:              env.getEnv("LOG_LEVEL", "INFO"),
+			LevelFile:          env.getEnv("LOG_LEVEL_FILE", ""),
+			SamplingInitial:    env.getEnvAsInt("LOG_SAMPLING_INITIAL", 100),
+			SamplingThereafter: env.getEnvAsInt("LOG_SAMPLING_THEREAFTER", 100),
+			SamplingInterval:   env.getEnvAsDuration("LOG_SAMPLING_INTERVAL", time.Second),
+		},
+		Tracing: TracingConfig{
+			OTLPEndpoint: env.getEnv("SELF_TRACING_OTLP_ENDPOINT", ""),
+			SampleRatio:  env.getEnvAsFloat("SELF_TRACING_SAMPLE_RATIO", 0),
 		},
-		LogLevel: getEnv("LOG_LEVEL", "INFO"),
 	}
+	cfg.Tenancy.IngestKeys, cfg.Tenancy.invalidIngestKeys = env.getEnvAsMap("INGEST_API_KEYS")
+	cfg.OpenSearch.FieldMappings, cfg.OpenSearch.invalidFieldMappings = env.getEnvAsMap("OPENSEARCH_FIELD_MAPPINGS")
+	cfg.LiveTraces.TailTrustedProxies, cfg.LiveTraces.invalidTailTrustedProxies = env.getEnvAsPrefixes("LIVE_TRACES_TAIL_TRUSTED_PROXIES")
 
 	// Validate
+	if len(env.errors) > 0 {
+		return nil, errors.Join(env.errors...)
+	}
 	if err := cfg.validate(); err != nil {
 		return nil, err
 	}
@@ -70,25 +663,297 @@ func (c *Config) validate() error {
 	if c.Server.Port <= 0 || c.Server.Port > 65535 {
 		return fmt.Errorf("invalid server port: %d", c.Server.Port)
 	}
+	if len(c.CORS.AllowedOrigins) == 0 {
+		return fmt.Errorf("at least one CORS allowed origin is required")
+	}
+	for _, origin := range c.CORS.AllowedOrigins {
+		if origin == "*" {
+			if len(c.CORS.AllowedOrigins) > 1 {
+				return fmt.Errorf("CORS allowed origin \"*\" cannot be combined with other origins")
+			}
+			continue
+		}
+		if u, err := url.Parse(origin); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || u.Path != "" {
+			return fmt.Errorf("invalid CORS allowed origin %q, use scheme://host[:port]", origin)
+		}
+	}
+	if c.Indexing.DeadLetterIndexEnabled && c.Indexing.DeadLetterRetention <= 0 {
+		return fmt.Errorf("dead letter retention must be positive")
+	}
+	if c.Reload.WatchInterval < 0 {
+		return fmt.Errorf("config watch interval must not be negative")
+	}
+	if c.Server.ShutdownTimeout <= 0 || c.Server.ReadinessRetryInterval <= 0 {
+		return fmt.Errorf("shutdown timeout and readiness retry interval must be positive")
+	}
+	if c.Server.RequestTimeout <= 0 || c.Server.MaxRequestBodyBytes <= 0 {
+		return fmt.Errorf("request timeout and max request body bytes must be positive")
+	}
+	if c.Server.CompressionMinBytes < 0 {
+		return fmt.Errorf("response compression min bytes must not be negative")
+	}
+	if c.Export.Timeout < 0 {
+		return fmt.Errorf("export timeout must not be negative")
+	}
+	if c.Lifecycle.RetentionRequestsPerSecond < 0 || c.Lifecycle.RetentionBatchSize <= 0 {
+		return fmt.Errorf("index retention requests per second must not be negative and batch size must be positive")
+	}
 	if c.OpenSearch.Address == "" {
 		return fmt.Errorf("opensearch address is required")
 	}
+	if c.Sampling.Enabled && (c.Sampling.SamplePercentage < 0 || c.Sampling.SamplePercentage > 100) {
+		return fmt.Errorf("sampling percentage must be between 0 and 100")
+	}
+	if len(c.Passthrough.AllowedPrefixes) > 0 && (c.Passthrough.MaxValueBytes <= 0 || c.Passthrough.MaxAttributes <= 0) {
+		return fmt.Errorf("passthrough max value bytes and max attributes must be positive")
+	}
+	if c.Kafka.Enabled {
+		if len(c.Kafka.Brokers) == 0 || c.Kafka.Topic == "" || c.Kafka.GroupID == "" {
+			return fmt.Errorf("kafka brokers, topic and group id are required")
+		}
+		if c.Kafka.BatchSize <= 0 || c.Kafka.MaxDecodeAttempts <= 0 {
+			return fmt.Errorf("kafka batch size and max decode attempts must be positive")
+		}
+	}
+	if c.Processing.Workers <= 0 || c.Processing.MaxQueuedSpans <= 0 {
+		return fmt.Errorf("processing workers and max queued spans must be positive")
+	}
+	if c.OTLP.GRPC.Enabled {
+		if c.OTLP.GRPC.Port <= 0 || c.OTLP.GRPC.Port > 65535 {
+			return fmt.Errorf("invalid OTLP gRPC port: %d", c.OTLP.GRPC.Port)
+		}
+		if c.OTLP.GRPC.Port == c.Server.Port {
+			return fmt.Errorf("OTLP gRPC port must differ from the server port")
+		}
+		if (c.OTLP.GRPC.TLSCertFile == "") != (c.OTLP.GRPC.TLSKeyFile == "") {
+			return fmt.Errorf("both OTLP gRPC TLS certificate and key files are required")
+		}
+	}
+	if c.Export.MaxRows <= 0 {
+		return fmt.Errorf("export max rows must be positive")
+	}
+	if c.Finalization.QuietPeriod <= 0 {
+		return fmt.Errorf("trace finalization quiet period must be positive")
+	}
+	if c.Finalization.MaxAge > 0 && c.Finalization.MaxAge < c.Finalization.QuietPeriod {
+		return fmt.Errorf("trace finalization max age must not be shorter than the quiet period")
+	}
+	if c.Finalization.RollupDelay <= 0 || c.Finalization.MaxPendingRollups <= 0 {
+		return fmt.Errorf("trace rollup delay and max pending rollups must be positive")
+	}
+	if c.Notifications.Enabled && c.Notifications.MaxRetries < 0 {
+		return fmt.Errorf("notification max retries must not be negative")
+	}
+	if c.LiveTraces.Enabled {
+		if c.LiveTraces.MaxTraces <= 0 || c.LiveTraces.MaxClients <= 0 || c.LiveTraces.ClientBuffer <= 0 {
+			return fmt.Errorf("live trace max traces, max clients and client buffer must be positive")
+		}
+		if c.LiveTraces.UpdateInterval <= 0 || c.LiveTraces.HeartbeatInterval <= 0 {
+			return fmt.Errorf("live trace update and heartbeat intervals must be positive")
+		}
+		if c.LiveTraces.TailMaxConnections <= 0 || c.LiveTraces.TailMaxConnectionsPerClient <= 0 || c.LiveTraces.TailMaxJournal <= 0 {
+			return fmt.Errorf("live trace tail max connections, max connections per client and max journal must be positive")
+		}
+		if c.LiveTraces.TailCatchUpDelay < 0 {
+			return fmt.Errorf("live trace tail catch-up delay must not be negative")
+		}
+		if len(c.LiveTraces.invalidTailTrustedProxies) > 0 {
+			return fmt.Errorf("live trace tail trusted proxies must be addresses or CIDR ranges, %q are not", c.LiveTraces.invalidTailTrustedProxies)
+		}
+		if c.LiveTraces.TailClientIPHeader != "" && len(c.LiveTraces.TailTrustedProxies) == 0 {
+			return fmt.Errorf("live trace tail client IP header requires the trusted proxies setting it")
+		}
+	}
+	switch strings.ToUpper(c.Logging.Level) {
+	case "", "DEBUG", "INFO", "WARN", "WARNING", "ERROR":
+	default:
+		return fmt.Errorf("invalid log level %q, use DEBUG, INFO, WARN or ERROR", c.Logging.Level)
+	}
+	if c.Logging.SamplingInitial < 0 || c.Logging.SamplingThereafter < 0 || c.Logging.SamplingInterval < 0 {
+		return fmt.Errorf("log sampling settings must not be negative")
+	}
+	if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+		return fmt.Errorf("self-tracing sample ratio must be between 0 and 1")
+	}
+	if c.QueryCache.Enabled {
+		if c.QueryCache.MaxEntries <= 0 || c.QueryCache.MaxBytes <= 0 {
+			return fmt.Errorf("query cache max entries and max bytes must be positive")
+		}
+		if c.QueryCache.MetricsTTL < 0 || c.QueryCache.ToolsTTL < 0 || c.QueryCache.ModelsTTL < 0 {
+			return fmt.Errorf("query cache ttls must not be negative")
+		}
+	}
+	if c.Alerts.Enabled {
+		if c.Alerts.EvaluationInterval <= 0 {
+			return fmt.Errorf("alert evaluation interval must be positive")
+		}
+		if c.Alerts.MaxRetries < 0 {
+			return fmt.Errorf("alert max retries must not be negative")
+		}
+	}
+	if c.Forwarding.DestinationsPath != "" && c.Forwarding.MaxRetries < 0 {
+		return fmt.Errorf("forwarding max retries must not be negative")
+	}
+	if c.Langfuse.Enabled {
+		if c.Langfuse.Host == "" || c.Langfuse.PublicKey == "" || c.Langfuse.SecretKey == "" {
+			return fmt.Errorf("langfuse host, public key and secret key are required")
+		}
+		if c.Langfuse.MaxRetries < 0 {
+			return fmt.Errorf("langfuse max retries must not be negative")
+		}
+	}
+	if c.Media.StoreEnabled && (c.Media.Bucket == "" || c.Media.AccessKeyID == "" || c.Media.SecretAccessKey == "") {
+		return fmt.Errorf("media bucket, access key id and secret access key are required")
+	}
+	if c.Archive.Enabled {
+		if c.Archive.Bucket == "" || c.Archive.AccessKeyID == "" || c.Archive.SecretAccessKey == "" {
+			return fmt.Errorf("archive bucket, access key id and secret access key are required")
+		}
+		if c.Archive.AfterDays <= 0 {
+			return fmt.Errorf("archive after days must be positive")
+		}
+		// Retention would delete indices before they are archived
+		if c.Lifecycle.RetentionDays > 0 && c.Lifecycle.RetentionDays <= c.Archive.AfterDays {
+			return fmt.Errorf("index retention days must exceed archive after days or be disabled")
+		}
+	}
+	if c.Tenancy.DefaultOrgID == "" {
+		return fmt.Errorf("default org id is required")
+	}
+	if len(c.OpenSearch.invalidFieldMappings) > 0 {
+		return fmt.Errorf("opensearch field mappings must be in the form field=type, %d entries are not", len(c.OpenSearch.invalidFieldMappings))
+	}
+	if len(c.Tenancy.invalidIngestKeys) > 0 {
+		return fmt.Errorf("ingest api keys must be in the form key=orgId, %d entries are not", len(c.Tenancy.invalidIngestKeys))
+	}
+	if c.AgentLabels.ManagerURL != "" {
+		if c.AgentLabels.APIKey == "" || c.AgentLabels.APIKeyHeader == "" || c.AgentLabels.AgentAttribute == "" {
+			return fmt.Errorf("agent labels api key, api key header and agent attribute are required")
+		}
+		if c.AgentLabels.CacheTTL <= 0 || c.AgentLabels.MaxCachedAgents <= 0 || c.AgentLabels.RequestTimeout <= 0 {
+			return fmt.Errorf("agent labels cache ttl, max cached agents and request timeout must be positive")
+		}
+	}
 	return nil
 }
 
+// envSource reads settings from the config file, falling back to the environment
+// Invalid environment values fall back to their defaults, invalid values of the config file are
+// reported instead so that a reload never applies a mistyped setting
+type envSource struct {
+	file   map[string]string // Settings of CONFIG_FILE, which take precedence over the environment
+	errors []error
+}
+
+// lookup returns the value of a setting, empty when it is not set
+func (e *envSource) lookup(key string) string {
+	if value := e.file[key]; value != "" {
+		return value
+	}
+	return os.Getenv(key)
+}
+
+// invalid records a value of the config file that cannot be parsed
+func (e *envSource) invalid(key string, err error) {
+	if e.file[key] != "" {
+		e.errors = append(e.errors, fmt.Errorf("invalid value of %s in config file: %w", key, err))
+	}
+}
+
 // Helper functions
-func getEnv(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
+func (e *envSource) getEnv(key, defaultValue string) string {
+	if value := e.lookup(key); value != "" {
 		return value
 	}
 	return defaultValue
 }
 
-func getEnvAsInt(key string, defaultValue int) int {
-	if value := os.Getenv(key); value != "" {
-		if intVal, err := strconv.Atoi(value); err == nil {
+func (e *envSource) getEnvAsInt(key string, defaultValue int) int {
+	if value := e.lookup(key); value != "" {
+		intVal, err := strconv.Atoi(value)
+		if err == nil {
 			return intVal
 		}
+		e.invalid(key, err)
+	}
+	return defaultValue
+}
+
+func (e *envSource) getEnvAsFloat(key string, defaultValue float64) float64 {
+	if value := e.lookup(key); value != "" {
+		floatVal, err := strconv.ParseFloat(value, 64)
+		if err == nil {
+			return floatVal
+		}
+		e.invalid(key, err)
 	}
 	return defaultValue
 }
+
+func (e *envSource) getEnvAsBool(key string, defaultValue bool) bool {
+	if value := e.lookup(key); value != "" {
+		boolVal, err := strconv.ParseBool(value)
+		if err == nil {
+			return boolVal
+		}
+		e.invalid(key, err)
+	}
+	return defaultValue
+}
+
+func (e *envSource) getEnvAsDuration(key string, defaultValue time.Duration) time.Duration {
+	if value := e.lookup(key); value != "" {
+		duration, err := time.ParseDuration(value)
+		if err == nil {
+			return duration
+		}
+		e.invalid(key, err)
+	}
+	return defaultValue
+}
+
+func (e *envSource) getEnvAsList(key string, defaultValue []string) []string {
+	if value := e.lookup(key); value != "" {
+		var items []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		return items
+	}
+	return defaultValue
+}
+
+// getEnvAsMap reads comma separated key=value entries, entries not in that form are returned separately
+func (e *envSource) getEnvAsMap(key string) (map[string]string, []string) {
+	values := map[string]string{}
+	var invalid []string
+	for _, item := range e.getEnvAsList(key, nil) {
+		k, v, ok := strings.Cut(item, "=")
+		k, v = strings.TrimSpace(k), strings.TrimSpace(v)
+		if !ok || k == "" || v == "" {
+			invalid = append(invalid, item)
+			continue
+		}
+		values[k] = v
+	}
+	return values, invalid
+}
+
+// getEnvAsPrefixes reads comma separated addresses and CIDR ranges, entries that are neither are returned separately
+func (e *envSource) getEnvAsPrefixes(key string) ([]netip.Prefix, []string) {
+	var prefixes []netip.Prefix
+	var invalid []string
+	for _, item := range e.getEnvAsList(key, nil) {
+		if prefix, err := netip.ParsePrefix(item); err == nil {
+			prefixes = append(prefixes, prefix.Masked())
+		} else if addr, err := netip.ParseAddr(item); err == nil {
+			addr = addr.Unmap()
+			prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+		} else {
+			invalid = append(invalid, item)
+		}
+	}
+	return prefixes, invalid
+}
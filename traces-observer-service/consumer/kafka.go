@@ -0,0 +1,341 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package consumer
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/segmentio/kafka-go/sasl"
+	"github.com/segmentio/kafka-go/sasl/plain"
+	"github.com/segmentio/kafka-go/sasl/scram"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/config"
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/controllers"
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/otlp"
+)
+
+// Message encodings written by OTLP Kafka exporters
+const (
+	EncodingOTLPProto = "otlp_proto"
+	EncodingOTLPJSON  = "otlp_json"
+)
+
+// dead-letter message headers
+const (
+	headerDeadLetterReason    = "x-dead-letter-reason"
+	headerDeadLetterTopic     = "x-original-topic"
+	headerDeadLetterPartition = "x-original-partition"
+	headerDeadLetterOffset    = "x-original-offset"
+)
+
+// messageReader is the part of kafka.Reader used by the consumer
+type messageReader interface {
+	FetchMessage(ctx context.Context) (kafka.Message, error)
+	CommitMessages(ctx context.Context, messages ...kafka.Message) error
+	Close() error
+}
+
+// messageWriter is the part of kafka.Writer used to write dead letters
+type messageWriter interface {
+	WriteMessages(ctx context.Context, messages ...kafka.Message) error
+	Close() error
+}
+
+// KafkaConsumer reads OTLP export requests from a Kafka topic and feeds them to the ingestion controller
+// Offsets are committed only after every span of a batch is indexed or dead-lettered (at-least-once delivery)
+type KafkaConsumer struct {
+	cfg         config.KafkaConfig
+	contentType string
+	reader      messageReader
+	deadLetter  messageWriter // Nil when no dead-letter topic is configured
+	ingestion   *controllers.IngestionController
+}
+
+// NewKafkaConsumer creates a consumer for the configured topic and consumer group
+func NewKafkaConsumer(cfg config.KafkaConfig, ingestion *controllers.IngestionController) (*KafkaConsumer, error) {
+	var contentType string
+	switch cfg.Encoding {
+	case EncodingOTLPProto:
+		contentType = otlp.ContentTypeProtobuf
+	case EncodingOTLPJSON:
+		contentType = otlp.ContentTypeJSON
+	default:
+		return nil, fmt.Errorf("unsupported Kafka message encoding: %s", cfg.Encoding)
+	}
+
+	tlsConfig, err := buildTLSConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	mechanism, err := buildSASLMechanism(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	dialer := &kafka.Dialer{
+		Timeout:       10 * time.Second,
+		DualStack:     true,
+		TLS:           tlsConfig,
+		SASLMechanism: mechanism,
+	}
+
+	consumer := &KafkaConsumer{
+		cfg:         cfg,
+		contentType: contentType,
+		ingestion:   ingestion,
+		reader: kafka.NewReader(kafka.ReaderConfig{
+			Brokers:        cfg.Brokers,
+			GroupID:        cfg.GroupID,
+			Topic:          cfg.Topic,
+			Dialer:         dialer,
+			MaxBytes:       cfg.MaxMessageBytes,
+			CommitInterval: 0, // Commit synchronously after each batch is indexed
+		}),
+	}
+
+	if cfg.DeadLetterTopic != "" {
+		consumer.deadLetter = &kafka.Writer{
+			Addr:         kafka.TCP(cfg.Brokers...),
+			Topic:        cfg.DeadLetterTopic,
+			RequiredAcks: kafka.RequireAll,
+			Transport: &kafka.Transport{
+				TLS:  tlsConfig,
+				SASL: mechanism,
+			},
+		}
+	}
+
+	return consumer, nil
+}
+
+// Run consumes messages until the context is cancelled
+func (c *KafkaConsumer) Run(ctx context.Context) error {
+	slog.Info("Kafka consumer started", "topic", c.cfg.Topic, "group", c.cfg.GroupID)
+
+	for {
+		batch, err := c.fetchBatch(ctx)
+		if len(batch) > 0 {
+			if procErr := c.processBatch(ctx, batch); procErr != nil {
+				if ctx.Err() != nil {
+					return nil
+				}
+				return procErr
+			}
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("failed to fetch Kafka message: %w", err)
+		}
+	}
+}
+
+// Close closes the reader and the dead-letter writer
+func (c *KafkaConsumer) Close() error {
+	err := c.reader.Close()
+	if c.deadLetter != nil {
+		err = errors.Join(err, c.deadLetter.Close())
+	}
+	return err
+}
+
+// fetchBatch fetches up to BatchSize messages, returning early once BatchWait elapses after the first message
+func (c *KafkaConsumer) fetchBatch(ctx context.Context) ([]kafka.Message, error) {
+	message, err := c.reader.FetchMessage(ctx)
+	if err != nil {
+		return nil, err
+	}
+	batch := []kafka.Message{message}
+
+	waitCtx, cancel := context.WithTimeout(ctx, c.cfg.BatchWait)
+	defer cancel()
+
+	for len(batch) < c.cfg.BatchSize {
+		message, err := c.reader.FetchMessage(waitCtx)
+		if err != nil {
+			if errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil {
+				return batch, nil
+			}
+			return batch, err
+		}
+		batch = append(batch, message)
+	}
+	return batch, nil
+}
+
+// processBatch exports the messages of a batch, waits until their spans are indexed and commits the offsets
+func (c *KafkaConsumer) processBatch(ctx context.Context, batch []kafka.Message) error {
+	ack := controllers.NewAcknowledger()
+
+	for _, message := range batch {
+		request, err := c.decode(ctx, message)
+		if err != nil {
+			// Poison messages are moved aside so that they do not block the partition
+			if dlqErr := c.sendToDeadLetter(ctx, message, err); dlqErr != nil {
+				return dlqErr
+			}
+			continue
+		}
+
+		if err := c.export(ctx, message, request, ack); err != nil {
+			return err
+		}
+	}
+
+	if err := ack.Wait(ctx); err != nil {
+		return err
+	}
+	if failed, err := ack.Failed(); failed > 0 {
+		slog.Warn("Spans from Kafka were dead-lettered by the indexer", "count", failed, "error", err)
+	}
+
+	if err := c.reader.CommitMessages(ctx, batch...); err != nil {
+		return fmt.Errorf("failed to commit Kafka offsets: %w", err)
+	}
+	return nil
+}
+
+// busyRetryDelay is the pause before a message rejected because the processing queue is full is exported again
+const busyRetryDelay = 200 * time.Millisecond
+
+// export hands a decoded message to the ingestion controller
+// Consumption pauses while the processing queue is full, a rejected message is exported again once it drains
+func (c *KafkaConsumer) export(ctx context.Context, message kafka.Message, request *coltracepb.ExportTraceServiceRequest, ack *controllers.Acknowledger) error {
+	for {
+		_, err := c.ingestion.ExportWithAck(ctx, request, ack)
+		if err == nil {
+			return nil
+		}
+		if !errors.Is(err, controllers.ErrIngestionBusy) {
+			return fmt.Errorf("failed to export Kafka message at offset %d: %w", message.Offset, err)
+		}
+
+		select {
+		case <-time.After(busyRetryDelay):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// decode decodes a message, retrying up to MaxDecodeAttempts times
+func (c *KafkaConsumer) decode(ctx context.Context, message kafka.Message) (*coltracepb.ExportTraceServiceRequest, error) {
+	var lastErr error
+	for attempt := 1; attempt <= c.cfg.MaxDecodeAttempts; attempt++ {
+		request, err := otlp.DecodeRequest(message.Value, c.contentType)
+		if err == nil {
+			return request, nil
+		}
+		lastErr = err
+		slog.Warn("Failed to decode Kafka message",
+			"partition", message.Partition, "offset", message.Offset, "attempt", attempt, "error", err)
+
+		if attempt < c.cfg.MaxDecodeAttempts {
+			select {
+			case <-time.After(time.Duration(attempt) * 100 * time.Millisecond):
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		}
+	}
+	return nil, lastErr
+}
+
+// sendToDeadLetter writes an undecodable message to the dead-letter topic, or logs it when no topic is configured
+func (c *KafkaConsumer) sendToDeadLetter(ctx context.Context, message kafka.Message, reason error) error {
+	if c.deadLetter == nil {
+		slog.Error("Dropping undecodable Kafka message",
+			"partition", message.Partition, "offset", message.Offset, "error", reason)
+		return nil
+	}
+
+	deadLetter := kafka.Message{
+		Key:   message.Key,
+		Value: message.Value,
+		Headers: append(message.Headers,
+			kafka.Header{Key: headerDeadLetterReason, Value: []byte(reason.Error())},
+			kafka.Header{Key: headerDeadLetterTopic, Value: []byte(message.Topic)},
+			kafka.Header{Key: headerDeadLetterPartition, Value: []byte(fmt.Sprintf("%d", message.Partition))},
+			kafka.Header{Key: headerDeadLetterOffset, Value: []byte(fmt.Sprintf("%d", message.Offset))},
+		),
+	}
+	if err := c.deadLetter.WriteMessages(ctx, deadLetter); err != nil {
+		return fmt.Errorf("failed to write Kafka dead letter: %w", err)
+	}
+
+	slog.Warn("Moved undecodable Kafka message to dead-letter topic",
+		"topic", c.cfg.DeadLetterTopic, "partition", message.Partition, "offset", message.Offset)
+	return nil
+}
+
+// buildTLSConfig builds the TLS configuration of the Kafka connections
+func buildTLSConfig(cfg config.KafkaConfig) (*tls.Config, error) {
+	if !cfg.TLSEnabled {
+		return nil, nil
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: cfg.TLSInsecureSkipVerify,
+	}
+	if cfg.TLSCAFile != "" {
+		caCert, err := os.ReadFile(cfg.TLSCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read Kafka CA file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caCert) {
+			return nil, fmt.Errorf("failed to parse Kafka CA file %s", cfg.TLSCAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if cfg.TLSCertFile != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.TLSCertFile, cfg.TLSKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load Kafka client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return tlsConfig, nil
+}
+
+// buildSASLMechanism builds the SASL mechanism of the Kafka connections
+func buildSASLMechanism(cfg config.KafkaConfig) (sasl.Mechanism, error) {
+	switch strings.ToUpper(cfg.SASLMechanism) {
+	case "":
+		return nil, nil
+	case "PLAIN":
+		return plain.Mechanism{Username: cfg.SASLUsername, Password: cfg.SASLPassword}, nil
+	case "SCRAM-SHA-256":
+		return scram.Mechanism(scram.SHA256, cfg.SASLUsername, cfg.SASLPassword)
+	case "SCRAM-SHA-512":
+		return scram.Mechanism(scram.SHA512, cfg.SASLUsername, cfg.SASLPassword)
+	default:
+		return nil, fmt.Errorf("unsupported Kafka SASL mechanism: %s", cfg.SASLMechanism)
+	}
+}
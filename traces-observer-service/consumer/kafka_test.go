@@ -0,0 +1,300 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package consumer
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/config"
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/controllers"
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/opensearch"
+)
+
+// fakeReader serves its messages in order, then blocks until the context is cancelled
+type fakeReader struct {
+	mu        sync.Mutex
+	messages  []kafka.Message
+	committed []int64
+	onCommit  func()
+}
+
+func (r *fakeReader) FetchMessage(ctx context.Context) (kafka.Message, error) {
+	r.mu.Lock()
+	if len(r.messages) > 0 {
+		message := r.messages[0]
+		r.messages = r.messages[1:]
+		r.mu.Unlock()
+		return message, nil
+	}
+	r.mu.Unlock()
+	<-ctx.Done()
+	return kafka.Message{}, ctx.Err()
+}
+
+func (r *fakeReader) CommitMessages(_ context.Context, messages ...kafka.Message) error {
+	if r.onCommit != nil {
+		r.onCommit()
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, message := range messages {
+		r.committed = append(r.committed, message.Offset)
+	}
+	return nil
+}
+
+func (r *fakeReader) Close() error {
+	return nil
+}
+
+func (r *fakeReader) commits() []int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]int64(nil), r.committed...)
+}
+
+// fakeWriter records the dead letters written to it
+type fakeWriter struct {
+	messages []kafka.Message
+}
+
+func (w *fakeWriter) WriteMessages(_ context.Context, messages ...kafka.Message) error {
+	w.messages = append(w.messages, messages...)
+	return nil
+}
+
+func (w *fakeWriter) Close() error {
+	return nil
+}
+
+// slowIndexer completes every document after a delay, as the bulk indexer does once a batch is flushed
+type slowIndexer struct {
+	mu      sync.Mutex
+	pending int
+	indexed int
+	failing bool
+}
+
+func (i *slowIndexer) Add(_ context.Context, doc opensearch.BulkDocument) error {
+	i.mu.Lock()
+	i.pending++
+	i.mu.Unlock()
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		i.mu.Lock()
+		i.pending--
+		i.indexed++
+		failing := i.failing
+		i.mu.Unlock()
+		if doc.OnDone != nil {
+			var err error
+			if failing {
+				err = errors.New("mapper_parsing_exception")
+			}
+			doc.OnDone(err)
+		}
+	}()
+	return nil
+}
+
+func (i *slowIndexer) Stats() opensearch.BulkIndexerStats {
+	return opensearch.BulkIndexerStats{}
+}
+
+func (i *slowIndexer) counts() (int, int) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	return i.pending, i.indexed
+}
+
+// exportMessage returns a Kafka message holding an OTLP protobuf export request of one span
+func exportMessage(t *testing.T, offset int64) kafka.Message {
+	t.Helper()
+	request := &coltracepb.ExportTraceServiceRequest{ResourceSpans: []*tracepb.ResourceSpans{{
+		ScopeSpans: []*tracepb.ScopeSpans{{Spans: []*tracepb.Span{{
+			TraceId:           []byte("0123456789abcdef"),
+			SpanId:            []byte{1, 2, 3, 4, 5, 6, 7, byte(offset + 1)},
+			Name:              "span",
+			StartTimeUnixNano: 1,
+			EndTimeUnixNano:   2,
+		}}}},
+	}}}
+	value, err := proto.Marshal(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return kafka.Message{Topic: "otlp_spans", Partition: 3, Offset: offset, Key: []byte("key"), Value: value}
+}
+
+// newTestConsumer returns a consumer of OTLP protobuf messages reading from the fake reader
+func newTestConsumer(reader *fakeReader, deadLetter *fakeWriter, indexer controllers.SpanIndexer) *KafkaConsumer {
+	consumer := &KafkaConsumer{
+		cfg: config.KafkaConfig{
+			Topic:             "otlp_spans",
+			BatchSize:         10,
+			BatchWait:         20 * time.Millisecond,
+			MaxDecodeAttempts: 2,
+			DeadLetterTopic:   "otlp_spans_dlq",
+		},
+		contentType: "application/x-protobuf",
+		reader:      reader,
+		ingestion:   controllers.NewIngestionController(indexer, nil, nil, nil, nil, nil, nil, nil, nil, nil, 0, nil),
+	}
+	if deadLetter != nil {
+		consumer.deadLetter = deadLetter
+	}
+	return consumer
+}
+
+func TestProcessBatchCommitsAfterIndexing(t *testing.T) {
+	indexer := &slowIndexer{}
+	reader := &fakeReader{}
+	reader.onCommit = func() {
+		if pending, _ := indexer.counts(); pending != 0 {
+			t.Errorf("committed with %d spans pending, want every span indexed first", pending)
+		}
+	}
+	consumer := newTestConsumer(reader, &fakeWriter{}, indexer)
+
+	batch := []kafka.Message{exportMessage(t, 10), exportMessage(t, 11)}
+	if err := consumer.processBatch(context.Background(), batch); err != nil {
+		t.Fatalf("processBatch() error = %v", err)
+	}
+	if got := reader.commits(); len(got) != 2 || got[0] != 10 || got[1] != 11 {
+		t.Errorf("committed offsets = %v, want [10 11]", got)
+	}
+
+	// Spans dead-lettered by the indexer are not consumed again
+	indexer.mu.Lock()
+	indexer.failing = true
+	indexer.mu.Unlock()
+	if err := consumer.processBatch(context.Background(), []kafka.Message{exportMessage(t, 12)}); err != nil {
+		t.Fatalf("processBatch() error = %v", err)
+	}
+	if got := reader.commits(); len(got) != 3 {
+		t.Errorf("committed offsets = %v, want offset 12 committed", got)
+	}
+}
+
+func TestProcessBatchDeadLettersUndecodableMessages(t *testing.T) {
+	reader := &fakeReader{}
+	deadLetter := &fakeWriter{}
+	consumer := newTestConsumer(reader, deadLetter, &slowIndexer{})
+
+	poison := kafka.Message{Topic: "otlp_spans", Partition: 3, Offset: 21, Key: []byte("key"), Value: []byte("not protobuf"),
+		Headers: []kafka.Header{{Key: "producer", Value: []byte("collector")}}}
+	batch := []kafka.Message{exportMessage(t, 20), poison, exportMessage(t, 22)}
+	if err := consumer.processBatch(context.Background(), batch); err != nil {
+		t.Fatalf("processBatch() error = %v", err)
+	}
+
+	// The poison message does not block the partition
+	if got := reader.commits(); len(got) != 3 {
+		t.Errorf("committed offsets = %v, want all three", got)
+	}
+	if len(deadLetter.messages) != 1 {
+		t.Fatalf("dead letters = %d, want 1", len(deadLetter.messages))
+	}
+	letter := deadLetter.messages[0]
+	headers := map[string]string{}
+	for _, header := range letter.Headers {
+		headers[header.Key] = string(header.Value)
+	}
+	if string(letter.Value) != "not protobuf" || string(letter.Key) != "key" || headers["producer"] != "collector" {
+		t.Errorf("dead letter = %+v, want the original message", letter)
+	}
+	if headers[headerDeadLetterTopic] != "otlp_spans" || headers[headerDeadLetterPartition] != "3" || headers[headerDeadLetterOffset] != "21" || headers[headerDeadLetterReason] == "" {
+		t.Errorf("dead letter headers = %v, want the origin and reason", headers)
+	}
+
+	// Without a dead-letter topic the message is dropped and still committed
+	reader = &fakeReader{}
+	consumer = newTestConsumer(reader, nil, &slowIndexer{})
+	if err := consumer.processBatch(context.Background(), []kafka.Message{poison}); err != nil {
+		t.Fatalf("processBatch() error = %v", err)
+	}
+	if got := reader.commits(); len(got) != 1 {
+		t.Errorf("committed offsets = %v, want the dropped message", got)
+	}
+}
+
+func TestRunConsumesInBatches(t *testing.T) {
+	reader := &fakeReader{}
+	for offset := int64(0); offset < 25; offset++ {
+		reader.messages = append(reader.messages, exportMessage(t, offset))
+	}
+	consumer := newTestConsumer(reader, nil, &slowIndexer{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- consumer.Run(ctx) }()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for len(reader.commits()) < 25 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("Run() error = %v, want nil after cancellation", err)
+	}
+	if got := reader.commits(); len(got) != 25 {
+		t.Errorf("committed %d offsets, want 25", len(got))
+	}
+}
+
+func TestNewKafkaConsumerRejectsInvalidConfig(t *testing.T) {
+	valid := config.KafkaConfig{Brokers: []string{"localhost:9092"}, Topic: "otlp_spans", GroupID: "observer", Encoding: EncodingOTLPProto}
+	tests := []struct {
+		name   string
+		modify func(cfg *config.KafkaConfig)
+		want   string
+	}{
+		{"unknown encoding", func(cfg *config.KafkaConfig) { cfg.Encoding = "avro" }, "unsupported Kafka message encoding"},
+		{"unknown SASL mechanism", func(cfg *config.KafkaConfig) { cfg.SASLMechanism = "GSSAPI" }, "unsupported Kafka SASL mechanism"},
+		{"missing CA file", func(cfg *config.KafkaConfig) { cfg.TLSEnabled, cfg.TLSCAFile = true, "/nonexistent/ca.pem" }, "failed to read Kafka CA file"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := valid
+			tt.modify(&cfg)
+			if _, err := NewKafkaConsumer(cfg, nil); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("NewKafkaConsumer() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+
+	for _, mechanism := range []string{"plain", "SCRAM-SHA-256", "SCRAM-SHA-512"} {
+		cfg := valid
+		cfg.Encoding, cfg.SASLMechanism, cfg.SASLUsername, cfg.SASLPassword = EncodingOTLPJSON, mechanism, "user", "secret"
+		consumer, err := NewKafkaConsumer(cfg, nil)
+		if err != nil {
+			t.Errorf("NewKafkaConsumer() with %s error = %v", mechanism, err)
+			continue
+		}
+		_ = consumer.Close()
+	}
+}
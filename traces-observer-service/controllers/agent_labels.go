@@ -0,0 +1,137 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package controllers
+
+import (
+	"context"
+
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/agentmanager"
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/opensearch"
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/otlp"
+)
+
+// agentNameAttribute is the span attribute agent frameworks report the agent name in
+const agentNameAttribute = "gen_ai.agent.name"
+
+// AgentLabeler links spans to the managed agent that produced them and copies the labels and retention of the agent onto them
+// A span is linked by the agent id resource attribute, and otherwise by its agent name when exactly one agent of
+// the organization has that name. Only agents of the organization the span is indexed for are linked, so that an
+// exporter cannot attach the labels of another organization's agent. Spans that match no agent, or several, are
+// linked to none rather than to a guess
+type AgentLabeler struct {
+	cache     *agentmanager.LabelCache
+	names     *agentmanager.NameCache
+	attribute string // Resource attribute holding the id of the managed agent
+}
+
+// NewAgentLabeler creates an agent labeler
+func NewAgentLabeler(cache *agentmanager.LabelCache, names *agentmanager.NameCache, attribute string) *AgentLabeler {
+	return &AgentLabeler{
+		cache:     cache,
+		names:     names,
+		attribute: attribute,
+	}
+}
+
+// namedAgents is the answer to a lookup of agents by name
+type namedAgents struct {
+	agents []agentmanager.Agent
+	err    error
+}
+
+// resourceAgent returns the managed agent id of a span document, empty when its resource carries none
+func (l *AgentLabeler) resourceAgent(source map[string]interface{}) string {
+	if resource, ok := source["resource"].(map[string]interface{}); ok {
+		if agentID, ok := resource[l.attribute].(string); ok {
+			return agentID
+		}
+	}
+	return ""
+}
+
+// agentName returns the agent name a span document reports, empty when it reports none
+func agentName(source map[string]interface{}) string {
+	if attributes, ok := source["attributes"].(map[string]interface{}); ok {
+		if name, ok := attributes[agentNameAttribute].(string); ok {
+			return name
+		}
+	}
+	return ""
+}
+
+// stamp records the managed agent, how it was resolved and its labels on the span documents of an export, the
+// organization must be stamped first. Each agent and agent name of the export is looked up once
+func (l *AgentLabeler) stamp(ctx context.Context, documents []otlp.SpanDocument) {
+	agents := map[string]*agentmanager.Agent{}
+	named := map[string]namedAgents{}
+	for _, document := range documents {
+		agent, resolution := l.resolve(ctx, document.Source, agents, named)
+		document.Source[opensearch.AgentResolutionField] = resolution
+		if agent == nil {
+			document.Source[opensearch.AgentIDField] = nil
+			continue
+		}
+		document.Source[opensearch.AgentIDField] = agent.AgentID
+		if len(agent.Labels) > 0 {
+			document.Source[opensearch.AgentLabelsField] = opensearch.AgentLabelTerms(agent.Labels)
+		}
+		if agent.RetentionDays > 0 {
+			document.Source[opensearch.RetentionDaysField] = agent.RetentionDays
+		}
+	}
+}
+
+// resolve finds the managed agent of a span document, the agents and named maps hold the lookups of the export
+func (l *AgentLabeler) resolve(ctx context.Context, source map[string]interface{}, agents map[string]*agentmanager.Agent, named map[string]namedAgents) (*agentmanager.Agent, string) {
+	orgID, _ := source[opensearch.OrgIDField].(string)
+
+	// An agent id of another organization is ignored, the span is resolved by its name instead
+	if agentID := l.resourceAgent(source); agentID != "" {
+		agent, ok := agents[agentID]
+		if !ok {
+			agent = l.cache.Get(ctx, agentID)
+			agents[agentID] = agent
+		}
+		if agent != nil && agent.OrgID == orgID {
+			return agent, opensearch.AgentResolutionAttribute
+		}
+	}
+
+	name := agentName(source)
+	if name == "" {
+		return nil, opensearch.AgentResolutionUnmanaged
+	}
+	key := orgID + "\x00" + name
+	result, ok := named[key]
+	if !ok {
+		result.agents, result.err = l.names.Get(ctx, orgID, name)
+		named[key] = result
+	}
+	switch {
+	case result.err != nil:
+		return nil, opensearch.AgentResolutionUnresolved
+	case len(result.agents) == 0:
+		return nil, opensearch.AgentResolutionUnmanaged
+	case len(result.agents) > 1:
+		return nil, opensearch.AgentResolutionAmbiguous
+	}
+	agent := result.agents[0]
+	if agent.OrgID != orgID {
+		return nil, opensearch.AgentResolutionUnmanaged
+	}
+	return &agent, opensearch.AgentResolutionName
+}
@@ -0,0 +1,213 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/agentmanager"
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/opensearch"
+)
+
+func TestExportStampsAgentLabels(t *testing.T) {
+	const orgA, orgB = "org-a", "org-b"
+	var lookups atomic.Int32
+	manager := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lookups.Add(1)
+		if r.Header.Get("X-API-KEY") != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/internal/agents/agent-a/labels":
+			_, _ = w.Write([]byte(`{"agentId":"agent-a","orgId":"org-a","labels":{"team":"billing","env":"prod"},"retentionDays":7}`))
+		case "/internal/agents/agent-b/labels":
+			_, _ = w.Write([]byte(`{"agentId":"agent-b","orgId":"org-b","labels":{"team":"search"}}`))
+		case "/internal/agents/broken/labels":
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer manager.Close()
+
+	client := agentmanager.NewClient(manager.URL+"/", "X-API-KEY", "secret", time.Second)
+	labeler := NewAgentLabeler(agentmanager.NewLabelCache(client, time.Minute, 100), agentmanager.NewNameCache(client, time.Minute, 100), "amp.agent.id")
+	resolver := NewOrgResolver(nil, "amp.org.id", orgA)
+
+	tests := []struct {
+		name          string
+		agentID       string
+		want          []interface{}
+		wantRetention interface{}
+	}{
+		{name: "agent labels", agentID: "agent-a", want: []interface{}{"env=prod", "team=billing"}, wantRetention: float64(7)},
+		{name: "agent of another organization", agentID: "agent-b"},
+		{name: "unknown agent", agentID: "agent-c"},
+		{name: "failed lookup", agentID: "broken"},
+		{name: "no agent id"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			indexer := newMemoryIndexer()
+			controller := NewIngestionController(indexer, nil, nil, nil, nil, nil, nil, resolver, labeler, nil, 0, nil)
+
+			request := exportRequest()
+			if tt.agentID != "" {
+				resource := request.ResourceSpans[0].Resource
+				resource.Attributes = append(resource.Attributes, stringAttribute("amp.agent.id", tt.agentID))
+			}
+			if _, err := controller.Export(context.Background(), request); err != nil {
+				t.Fatalf("Export() error = %v", err)
+			}
+
+			if len(indexer.docs) != 3 {
+				t.Fatalf("indexed %d documents, want 3", len(indexer.docs))
+			}
+			for id, source := range indexer.docs {
+				got, _ := source[opensearch.AgentLabelsField].([]interface{})
+				if !reflect.DeepEqual(got, tt.want) {
+					t.Errorf("document %s agent labels = %v, want %v", id, got, tt.want)
+				}
+				if got := source[opensearch.RetentionDaysField]; got != tt.wantRetention {
+					t.Errorf("document %s retention days = %v, want %v", id, got, tt.wantRetention)
+				}
+			}
+		})
+	}
+
+	// Every agent was looked up once although it produced three spans, a second export is served from the cache
+	if got := lookups.Load(); got != 4 {
+		t.Errorf("agent manager was asked %d times, want 4", got)
+	}
+	controller := NewIngestionController(newMemoryIndexer(), nil, nil, nil, nil, nil, nil, resolver, labeler, nil, 0, nil)
+	request := exportRequest()
+	request.ResourceSpans[0].Resource.Attributes = append(request.ResourceSpans[0].Resource.Attributes, stringAttribute("amp.agent.id", "agent-a"))
+	if _, err := controller.Export(context.Background(), request); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+	if got := lookups.Load(); got != 4 {
+		t.Errorf("agent manager was asked %d times after a cached export, want 4", got)
+	}
+}
+
+func TestExportLinksSpansToManagedAgents(t *testing.T) {
+	const orgA = "org-a"
+	var nameLookups atomic.Int32
+	manager := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/internal/agents/agent-a/labels":
+			_, _ = w.Write([]byte(`{"agentId":"agent-a","orgId":"org-a","labels":{"team":"billing"}}`))
+		case "/internal/agents/agent-b/labels":
+			_, _ = w.Write([]byte(`{"agentId":"agent-b","orgId":"org-b","labels":{}}`))
+		case "/internal/agents":
+			nameLookups.Add(1)
+			if r.URL.Query().Get("orgId") != orgA {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			switch r.URL.Query().Get("name") {
+			case "billing":
+				_, _ = w.Write([]byte(`{"agents":[{"agentId":"agent-a","orgId":"org-a","labels":{"team":"billing"}}]}`))
+			case "support":
+				_, _ = w.Write([]byte(`{"agents":[{"agentId":"agent-s1","orgId":"org-a","labels":{}},{"agentId":"agent-s2","orgId":"org-a","labels":{}}]}`))
+			case "broken":
+				w.WriteHeader(http.StatusInternalServerError)
+			default:
+				_, _ = w.Write([]byte(`{"agents":[]}`))
+			}
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer manager.Close()
+
+	client := agentmanager.NewClient(manager.URL, "X-API-KEY", "secret", time.Second)
+	labeler := NewAgentLabeler(agentmanager.NewLabelCache(client, time.Minute, 100), agentmanager.NewNameCache(client, time.Minute, 100), "amp.agent.id")
+	resolver := NewOrgResolver(nil, "amp.org.id", orgA)
+
+	tests := []struct {
+		name           string
+		agentID        string // Resource attribute of every span
+		agentName      string // Reported by the root span only
+		wantRoot       interface{}
+		wantResolution string
+		wantLabels     []interface{}
+	}{
+		{name: "agent id attribute", agentID: "agent-a", wantRoot: "agent-a", wantResolution: opensearch.AgentResolutionAttribute, wantLabels: []interface{}{"team=billing"}},
+		{name: "agent name", agentName: "Billing", wantRoot: "agent-a", wantResolution: opensearch.AgentResolutionName, wantLabels: []interface{}{"team=billing"}},
+		{name: "agent id of another organization", agentID: "agent-b", agentName: "billing", wantRoot: "agent-a", wantResolution: opensearch.AgentResolutionName, wantLabels: []interface{}{"team=billing"}},
+		{name: "name shared by several agents", agentName: "support", wantResolution: opensearch.AgentResolutionAmbiguous},
+		{name: "unmanaged agent", agentName: "research", wantResolution: opensearch.AgentResolutionUnmanaged},
+		{name: "failed lookup", agentName: "broken", wantResolution: opensearch.AgentResolutionUnresolved},
+		{name: "no agent", wantResolution: opensearch.AgentResolutionUnmanaged},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			indexer := newMemoryIndexer()
+			controller := NewIngestionController(indexer, nil, nil, nil, nil, nil, nil, resolver, labeler, nil, 0, nil)
+
+			request := exportRequest()
+			resourceSpans := request.ResourceSpans[0]
+			if tt.agentID != "" {
+				resourceSpans.Resource.Attributes = append(resourceSpans.Resource.Attributes, stringAttribute("amp.agent.id", tt.agentID))
+			}
+			if tt.agentName != "" {
+				root := resourceSpans.ScopeSpans[0].Spans[0]
+				root.Attributes = append(root.Attributes, stringAttribute("gen_ai.agent.name", tt.agentName))
+			}
+			if _, err := controller.Export(context.Background(), request); err != nil {
+				t.Fatalf("Export() error = %v", err)
+			}
+
+			if len(indexer.docs) != 3 {
+				t.Fatalf("indexed %d documents, want 3", len(indexer.docs))
+			}
+			for id, source := range indexer.docs {
+				// Only the root span reports the agent name, the attribute covers every span of the resource
+				wantAgent, wantResolution, wantLabels := tt.wantRoot, tt.wantResolution, tt.wantLabels
+				if source["spanId"] != "0000000000000001" && tt.wantResolution != opensearch.AgentResolutionAttribute {
+					wantAgent, wantResolution, wantLabels = nil, opensearch.AgentResolutionUnmanaged, nil
+				}
+				agentID, ok := source[opensearch.AgentIDField]
+				if !ok || agentID != wantAgent {
+					t.Errorf("document %s agent id = %v (present %v), want %v", id, agentID, ok, wantAgent)
+				}
+				if got := source[opensearch.AgentResolutionField]; got != wantResolution {
+					t.Errorf("document %s agent resolution = %v, want %v", id, got, wantResolution)
+				}
+				got, _ := source[opensearch.AgentLabelsField].([]interface{})
+				if !reflect.DeepEqual(got, wantLabels) {
+					t.Errorf("document %s agent labels = %v, want %v", id, got, wantLabels)
+				}
+			}
+		})
+	}
+
+	// Names are matched ignoring case, so Billing and billing were looked up once
+	if got := nameLookups.Load(); got != 4 {
+		t.Errorf("agent manager was asked about names %d times, want 4", got)
+	}
+}
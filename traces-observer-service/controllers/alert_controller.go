@@ -0,0 +1,185 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package controllers
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/middleware/logger"
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/notifications"
+)
+
+// ErrAlertRuleNotFound is returned when an alert rule is not found
+var ErrAlertRuleNotFound = errors.New("alert rule not found")
+
+// AlertController manages the alert rules and reports the firing alerts
+type AlertController struct {
+	store     *notifications.AlertStore
+	evaluator *notifications.AlertEvaluator
+}
+
+// NewAlertController creates a new alert controller
+func NewAlertController(store *notifications.AlertStore, evaluator *notifications.AlertEvaluator) *AlertController {
+	return &AlertController{
+		store:     store,
+		evaluator: evaluator,
+	}
+}
+
+// ListRules returns every alert rule without its secret
+func (c *AlertController) ListRules(ctx context.Context) ([]notifications.AlertRule, error) {
+	rules, err := c.store.ListRules(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range rules {
+		rules[i] = rules[i].Redacted()
+	}
+	return rules, nil
+}
+
+// GetRule returns an alert rule without its secret
+func (c *AlertController) GetRule(ctx context.Context, id string) (*notifications.AlertRule, error) {
+	rule, found, err := c.store.GetRule(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get alert rule: %w", err)
+	}
+	if !found {
+		return nil, ErrAlertRuleNotFound
+	}
+	redacted := rule.Redacted()
+	return &redacted, nil
+}
+
+// CreateRule stores a new alert rule, evaluated from the next evaluation on
+// A signing secret is generated when none is given, the returned rule is the only response carrying it
+func (c *AlertController) CreateRule(ctx context.Context, rule notifications.AlertRule) (*notifications.AlertRule, error) {
+	log := logger.GetLogger(ctx)
+
+	rule.Name = strings.TrimSpace(rule.Name)
+	if err := rule.Validate(); err != nil {
+		return nil, err
+	}
+	rule.ID = rand.Text()
+	if rule.Secret == "" {
+		rule.Secret = rand.Text()
+	}
+	rule.CreatedAt = time.Now().UTC()
+	rule.UpdatedAt = rule.CreatedAt
+
+	if err := c.store.PutRule(ctx, &rule); err != nil {
+		return nil, fmt.Errorf("failed to store alert rule: %w", err)
+	}
+
+	log.Info("Created alert rule", "ruleId", rule.ID, "name", rule.Name, "metric", rule.Metric)
+	return &rule, nil
+}
+
+// UpdateRule replaces the condition and target of an alert rule
+// The alerts of the rule are kept and evaluated against the new condition, the secret is kept when the update
+// does not carry one
+func (c *AlertController) UpdateRule(ctx context.Context, id string, rule notifications.AlertRule) (*notifications.AlertRule, error) {
+	log := logger.GetLogger(ctx)
+
+	existing, found, err := c.store.GetRule(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get alert rule: %w", err)
+	}
+	if !found {
+		return nil, ErrAlertRuleNotFound
+	}
+
+	rule.Name = strings.TrimSpace(rule.Name)
+	if err := rule.Validate(); err != nil {
+		return nil, err
+	}
+	rule.ID = existing.ID
+	rule.CreatedAt = existing.CreatedAt
+	rule.UpdatedAt = time.Now().UTC()
+	if rule.Secret == "" {
+		rule.Secret = existing.Secret
+	}
+
+	if err := c.store.PutRule(ctx, &rule); err != nil {
+		return nil, fmt.Errorf("failed to store alert rule: %w", err)
+	}
+
+	log.Info("Updated alert rule", "ruleId", rule.ID, "name", rule.Name, "metric", rule.Metric)
+	redacted := rule.Redacted()
+	return &redacted, nil
+}
+
+// DeleteRule deletes an alert rule together with its alerts, without notifying their resolution
+func (c *AlertController) DeleteRule(ctx context.Context, id string) error {
+	log := logger.GetLogger(ctx)
+
+	deleted, err := c.store.DeleteRule(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete alert rule: %w", err)
+	}
+	if !deleted {
+		return ErrAlertRuleNotFound
+	}
+
+	// Alerts left behind are ignored, since they do not belong to a rule any more
+	states, err := c.store.ListStates(ctx, id)
+	if err != nil {
+		log.Warn("Failed to list the alerts of a deleted alert rule", "ruleId", id, "error", err)
+	}
+	for _, state := range states {
+		if err := c.store.DeleteState(ctx, state.ID); err != nil {
+			log.Warn("Failed to delete an alert of a deleted alert rule", "ruleId", id, "alertId", state.ID, "error", err)
+		}
+	}
+
+	log.Info("Deleted alert rule", "ruleId", id)
+	return nil
+}
+
+// ListFiringAlerts returns the firing alerts of the enabled alert rules, the longest firing first
+func (c *AlertController) ListFiringAlerts(ctx context.Context) ([]notifications.AlertState, error) {
+	rules, err := c.store.ListRules(ctx)
+	if err != nil {
+		return nil, err
+	}
+	enabled := make(map[string]bool, len(rules))
+	for _, rule := range rules {
+		enabled[rule.ID] = rule.Enabled
+	}
+
+	states, err := c.store.ListStates(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	alerts := make([]notifications.AlertState, 0, len(states))
+	for _, state := range states {
+		if state.Status == notifications.AlertStatusFiring && enabled[state.RuleID] {
+			alerts = append(alerts, state)
+		}
+	}
+	return alerts, nil
+}
+
+// Stats returns the alert evaluator counters
+func (c *AlertController) Stats() notifications.AlertStats {
+	return c.evaluator.Stats()
+}
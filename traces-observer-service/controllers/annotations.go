@@ -0,0 +1,199 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package controllers
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/middleware/logger"
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/opensearch"
+)
+
+// ErrAnnotationNotFound is returned when an annotation is not found
+var ErrAnnotationNotFound = errors.New("annotation not found")
+
+// ErrInvalidAnnotation is returned when an annotation fails validation
+var ErrInvalidAnnotation = errors.New("invalid annotation")
+
+// Annotation limits
+const (
+	MinAnnotationScore      = 1
+	MaxAnnotationScore      = 5
+	maxAnnotationLabelLen   = 64
+	maxAnnotationAuthorLen  = 256
+	maxAnnotationCommentLen = 10000
+)
+
+// AnnotationInput holds the fields of a new annotation
+type AnnotationInput struct {
+	Author  string
+	Score   *int
+	Label   string
+	Comment string
+}
+
+// CreateAnnotation stores a new annotation of a trace
+// Each annotation is its own document, so concurrent annotations of a trace do not overwrite each other
+func (s *TracingController) CreateAnnotation(ctx context.Context, traceID string, input AnnotationInput) (*opensearch.Annotation, error) {
+	log := logger.GetLogger(ctx)
+
+	annotation, err := newAnnotation(traceID, input)
+	if err != nil {
+		return nil, err
+	}
+	annotation.OrgID, _ = opensearch.OrgScope(ctx)
+
+	if err := s.osClient.IndexDocument(ctx, opensearch.AnnotationIndex, annotation.ID, annotation); err != nil {
+		return nil, fmt.Errorf("failed to store annotation: %w", err)
+	}
+
+	log.Info("Created trace annotation", "traceId", traceID, "annotationId", annotation.ID, "author", annotation.Author)
+	return annotation, nil
+}
+
+// newAnnotation validates the input and builds an annotation with a generated ID
+func newAnnotation(traceID string, input AnnotationInput) (*opensearch.Annotation, error) {
+	author := strings.TrimSpace(input.Author)
+	label := strings.ToLower(strings.TrimSpace(input.Label))
+	comment := strings.TrimSpace(input.Comment)
+
+	switch {
+	case author == "":
+		return nil, fmt.Errorf("%w: author is required", ErrInvalidAnnotation)
+	case len(author) > maxAnnotationAuthorLen:
+		return nil, fmt.Errorf("%w: author must be at most %d characters", ErrInvalidAnnotation, maxAnnotationAuthorLen)
+	case input.Score == nil && label == "" && comment == "":
+		return nil, fmt.Errorf("%w: one of score, label or comment is required", ErrInvalidAnnotation)
+	case input.Score != nil && (*input.Score < MinAnnotationScore || *input.Score > MaxAnnotationScore):
+		return nil, fmt.Errorf("%w: score must be between %d and %d", ErrInvalidAnnotation, MinAnnotationScore, MaxAnnotationScore)
+	case len(label) > maxAnnotationLabelLen:
+		return nil, fmt.Errorf("%w: label must be at most %d characters", ErrInvalidAnnotation, maxAnnotationLabelLen)
+	case len(comment) > maxAnnotationCommentLen:
+		return nil, fmt.Errorf("%w: comment must be at most %d characters", ErrInvalidAnnotation, maxAnnotationCommentLen)
+	}
+
+	return &opensearch.Annotation{
+		ID:        rand.Text(),
+		TraceID:   traceID,
+		Author:    author,
+		Timestamp: time.Now().UTC(),
+		Score:     input.Score,
+		Label:     label,
+		Comment:   comment,
+	}, nil
+}
+
+// GetAnnotations retrieves the annotations of a trace with their summary
+func (s *TracingController) GetAnnotations(ctx context.Context, traceID string) (*opensearch.TraceAnnotationsResponse, error) {
+	response, err := s.osClient.Search(ctx, []string{opensearch.AnnotationIndex}, opensearch.BuildTraceAnnotationsQuery(traceID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to search annotations: %w", err)
+	}
+	annotations, err := opensearch.ParseAnnotations(response)
+	if err != nil {
+		return nil, err
+	}
+
+	return &opensearch.TraceAnnotationsResponse{
+		TraceID:     traceID,
+		Annotations: annotations,
+		Summary:     opensearch.SummarizeAnnotations(annotations),
+	}, nil
+}
+
+// DeleteAnnotation deletes an annotation of a trace
+func (s *TracingController) DeleteAnnotation(ctx context.Context, traceID, annotationID string) error {
+	log := logger.GetLogger(ctx)
+
+	// Only delete the annotation through the trace it belongs to
+	existing, err := s.GetAnnotations(ctx, traceID)
+	if err != nil {
+		return err
+	}
+	found := false
+	for _, annotation := range existing.Annotations {
+		if annotation.ID == annotationID {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return ErrAnnotationNotFound
+	}
+
+	deleted, err := s.osClient.DeleteDocument(ctx, opensearch.AnnotationIndex, annotationID)
+	if err != nil {
+		return fmt.Errorf("failed to delete annotation: %w", err)
+	}
+	if !deleted {
+		return ErrAnnotationNotFound
+	}
+
+	log.Info("Deleted trace annotation", "traceId", traceID, "annotationId", annotationID)
+	return nil
+}
+
+// getAnnotationSummaries looks up the annotation summaries of the given traces
+// Failures are logged and ignored so that annotations never break trace queries
+func (s *TracingController) getAnnotationSummaries(ctx context.Context, traceIDs []string) map[string]*opensearch.AnnotationSummary {
+	if len(traceIDs) == 0 {
+		return nil
+	}
+
+	response, err := s.osClient.Search(ctx, []string{opensearch.AnnotationIndex}, opensearch.BuildAnnotationSummaryQuery(traceIDs))
+	if err != nil {
+		logger.GetLogger(ctx).Warn("Failed to look up annotation summaries", "error", err)
+		return nil
+	}
+	summaries, err := opensearch.ParseAnnotationSummaries(response)
+	if err != nil {
+		logger.GetLogger(ctx).Warn("Failed to decode annotation summaries", "error", err)
+		return nil
+	}
+	return summaries
+}
+
+// attachAnnotationSummaries adds the annotation summaries to trace overviews
+func (s *TracingController) attachAnnotationSummaries(ctx context.Context, overviews []opensearch.TraceOverview) {
+	traceIDs := make([]string, 0, len(overviews))
+	for _, overview := range overviews {
+		traceIDs = append(traceIDs, overview.TraceID)
+	}
+	summaries := s.getAnnotationSummaries(ctx, traceIDs)
+	for i := range overviews {
+		overviews[i].Annotations = summaries[overviews[i].TraceID]
+	}
+}
+
+// getTraceIDsByAnnotationLabel resolves the traces annotated with a label
+func (s *TracingController) getTraceIDsByAnnotationLabel(ctx context.Context, label string) ([]string, error) {
+	query := opensearch.BuildTraceIDsByAnnotationLabelQuery(strings.ToLower(label))
+	response, err := s.osClient.Search(ctx, []string{opensearch.AnnotationIndex}, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search traces by annotation label: %w", err)
+	}
+	traceIDs, err := response.TermsAggregationKeys("traces")
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode traces by annotation label: %w", err)
+	}
+	return traceIDs, nil
+}
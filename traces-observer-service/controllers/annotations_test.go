@@ -0,0 +1,202 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/config"
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/opensearch"
+)
+
+// fakeAnnotationOpenSearch keeps annotation documents in memory
+type fakeAnnotationOpenSearch struct {
+	mu        sync.Mutex
+	documents map[string]json.RawMessage
+}
+
+func (f *fakeAnnotationOpenSearch) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	docPrefix := "/" + opensearch.AnnotationIndex + "/_doc/"
+	switch {
+	case r.URL.Path == "/":
+		fmt.Fprint(w, `{"cluster_name":"test","version":{"distribution":"opensearch","number":"2.11.0"}}`)
+	case r.Method == http.MethodPut && strings.HasPrefix(r.URL.Path, docPrefix):
+		var document json.RawMessage
+		if err := json.NewDecoder(r.Body).Decode(&document); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		f.documents[strings.TrimPrefix(r.URL.Path, docPrefix)] = document
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, `{"result":"created"}`)
+	case r.Method == http.MethodDelete && strings.HasPrefix(r.URL.Path, docPrefix):
+		id := strings.TrimPrefix(r.URL.Path, docPrefix)
+		if _, ok := f.documents[id]; !ok {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"result":"not_found"}`)
+			return
+		}
+		delete(f.documents, id)
+		fmt.Fprint(w, `{"result":"deleted"}`)
+	case r.Method == http.MethodPost && r.URL.Path == "/"+opensearch.AnnotationIndex+"/_search":
+		var body struct {
+			Query struct {
+				Term map[string]string `json:"term"`
+			} `json:"query"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		var matches []opensearch.Annotation
+		for _, document := range f.documents {
+			var annotation opensearch.Annotation
+			_ = json.Unmarshal(document, &annotation)
+			if annotation.TraceID == body.Query.Term["traceId"] {
+				matches = append(matches, annotation)
+			}
+		}
+		sort.Slice(matches, func(i, j int) bool { return matches[i].Timestamp.Before(matches[j].Timestamp) })
+		hits := []string{}
+		for _, annotation := range matches {
+			source, _ := json.Marshal(annotation)
+			hits = append(hits, fmt.Sprintf(`{"_id":%q,"_source":%s}`, annotation.ID, source))
+		}
+		fmt.Fprintf(w, `{"hits":{"total":{"value":%d},"hits":[%s]}}`, len(hits), strings.Join(hits, ","))
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// newAnnotationTestController returns a tracing controller of an in-memory annotation index
+func newAnnotationTestController(t *testing.T) (*TracingController, *fakeAnnotationOpenSearch) {
+	fake := &fakeAnnotationOpenSearch{documents: map[string]json.RawMessage{}}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	client, err := opensearch.NewClient(&config.OpenSearchConfig{
+		Address:        server.URL,
+		Username:       "admin",
+		Password:       "admin",
+		RequestTimeout: 5 * time.Second,
+	}, nil)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	return NewTracingController(client, nil, TracingOptions{}), fake
+}
+
+func intPtr(v int) *int {
+	return &v
+}
+
+func TestNewAnnotationValidates(t *testing.T) {
+	tests := []struct {
+		name  string
+		input AnnotationInput
+		want  string
+	}{
+		{"missing author", AnnotationInput{Author: "  ", Score: intPtr(3)}, "author is required"},
+		{"author too long", AnnotationInput{Author: strings.Repeat("a", 257), Score: intPtr(3)}, "author must be at most"},
+		{"nothing to annotate", AnnotationInput{Author: "alice", Label: " ", Comment: "\n"}, "one of score, label or comment"},
+		{"score too low", AnnotationInput{Author: "alice", Score: intPtr(0)}, "score must be between 1 and 5"},
+		{"score too high", AnnotationInput{Author: "alice", Score: intPtr(6)}, "score must be between 1 and 5"},
+		{"label too long", AnnotationInput{Author: "alice", Label: strings.Repeat("l", 65)}, "label must be at most"},
+		{"comment too long", AnnotationInput{Author: "alice", Comment: strings.Repeat("c", 10001)}, "comment must be at most"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newAnnotation("trace-1", tt.input)
+			if !errors.Is(err, ErrInvalidAnnotation) || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("newAnnotation() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+
+	annotation, err := newAnnotation("trace-1", AnnotationInput{Author: " alice ", Label: " Thumbs_Down ", Comment: " wrong tool "})
+	if err != nil {
+		t.Fatalf("newAnnotation() error = %v", err)
+	}
+	if annotation.ID == "" || annotation.Author != "alice" || annotation.Label != opensearch.AnnotationLabelThumbsDown || annotation.Comment != "wrong tool" || annotation.Score != nil {
+		t.Errorf("annotation = %+v, want trimmed fields with a lower case label", annotation)
+	}
+}
+
+func TestAnnotationLifecycle(t *testing.T) {
+	controller, fake := newAnnotationTestController(t)
+	ctx := context.Background()
+
+	first, err := controller.CreateAnnotation(ctx, "trace-1", AnnotationInput{Author: "alice", Score: intPtr(2), Label: "thumbs_down"})
+	if err != nil {
+		t.Fatalf("CreateAnnotation() error = %v", err)
+	}
+	second, err := controller.CreateAnnotation(ctx, "trace-1", AnnotationInput{Author: "bob", Score: intPtr(5), Label: "thumbs_up"})
+	if err != nil {
+		t.Fatalf("CreateAnnotation() error = %v", err)
+	}
+	if _, err := controller.CreateAnnotation(ctx, "trace-2", AnnotationInput{Author: "carol", Comment: "other trace"}); err != nil {
+		t.Fatalf("CreateAnnotation() error = %v", err)
+	}
+	if first.ID == second.ID {
+		t.Fatalf("annotations share the ID %s", first.ID)
+	}
+
+	// Annotations of a trace are kept side by side rather than overwriting each other
+	result, err := controller.GetAnnotations(ctx, "trace-1")
+	if err != nil {
+		t.Fatalf("GetAnnotations() error = %v", err)
+	}
+	if len(result.Annotations) != 2 || result.Annotations[0].ID != first.ID || result.Annotations[1].ID != second.ID {
+		t.Fatalf("annotations = %+v, want both annotations of trace-1 in creation order", result.Annotations)
+	}
+	if result.Summary == nil || result.Summary.Count != 2 || *result.Summary.AverageScore != 3.5 || result.Summary.Labels["thumbs_up"] != 1 {
+		t.Errorf("summary = %+v, want 2 annotations averaging 3.5", result.Summary)
+	}
+
+	// An annotation can only be deleted through its own trace
+	if err := controller.DeleteAnnotation(ctx, "trace-2", first.ID); !errors.Is(err, ErrAnnotationNotFound) {
+		t.Errorf("DeleteAnnotation() through another trace error = %v, want %v", err, ErrAnnotationNotFound)
+	}
+	if err := controller.DeleteAnnotation(ctx, "trace-1", first.ID); err != nil {
+		t.Fatalf("DeleteAnnotation() error = %v", err)
+	}
+	if err := controller.DeleteAnnotation(ctx, "trace-1", first.ID); !errors.Is(err, ErrAnnotationNotFound) {
+		t.Errorf("DeleteAnnotation() of a deleted annotation error = %v, want %v", err, ErrAnnotationNotFound)
+	}
+	if len(fake.documents) != 2 {
+		t.Errorf("documents = %d, want 2 left", len(fake.documents))
+	}
+
+	empty, err := controller.GetAnnotations(ctx, "trace-3")
+	if err != nil || len(empty.Annotations) != 0 || empty.Summary != nil {
+		t.Errorf("GetAnnotations() of an unannotated trace = %+v, %v, want no annotations and no summary", empty, err)
+	}
+}
@@ -18,36 +18,81 @@ package controllers
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"sort"
 	"time"
 
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/archive"
 	"github.com/wso2/ai-agent-management-platform/traces-observer-service/middleware/logger"
 	"github.com/wso2/ai-agent-management-platform/traces-observer-service/opensearch"
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/pricing"
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/querycache"
 )
 
 // ErrTraceNotFound is returned when a trace is not found
 var ErrTraceNotFound = errors.New("trace not found")
 
+// TraceNotFoundError names the trace of a request for several traces that was not found, it matches ErrTraceNotFound
+type TraceNotFoundError struct {
+	TraceID string
+}
+
+func (e *TraceNotFoundError) Error() string {
+	return "trace not found: " + e.TraceID
+}
+
+func (e *TraceNotFoundError) Is(target error) bool {
+	return target == ErrTraceNotFound
+}
+
+// ErrTooManyBuckets is returned when a metrics query would produce more buckets than allowed
+var ErrTooManyBuckets = errors.New("too many metrics buckets")
+
+// TracingOptions holds the limits of the tracing queries
+type TracingOptions struct {
+	MaxMetricsBuckets int                           // Maximum number of time buckets per metrics series, disabled when zero
+	MaxExportRows     int                           // Maximum number of traces per export
+	Archive           *archive.Archiver             // Archive searched for traces no longer in OpenSearch, nil when archival is disabled
+	Finalization      opensearch.FinalizationPolicy // Decides whether spans of a listed trace may still arrive
+	QueryCache        QueryCacheOptions             // Caching of dashboard aggregations
+}
+
+// QueryCacheOptions configures caching the results of the dashboard metrics endpoints
+type QueryCacheOptions struct {
+	Cache      *querycache.Cache // Nil when caching is disabled
+	MetricsTTL time.Duration     // Time trace metrics are cached, not cached when zero
+	ToolsTTL   time.Duration     // Time tool metrics are cached, not cached when zero
+	ModelsTTL  time.Duration     // Time model metrics are cached, not cached when zero
+}
+
 // TracingController provides tracing functionality
 type TracingController struct {
-	osClient *opensearch.Client
+	osClient     *opensearch.Client
+	pricingTable *pricing.Table
+	options      TracingOptions
 }
 
 // NewTracingController creates a new tracing service
-func NewTracingController(osClient *opensearch.Client) *TracingController {
+func NewTracingController(osClient *opensearch.Client, pricingTable *pricing.Table, options TracingOptions) *TracingController {
 	return &TracingController{
-		osClient: osClient,
+		osClient:     osClient,
+		pricingTable: pricingTable,
+		options:      options,
 	}
 }
 
-// GetTraceOverviews retrieves unique trace IDs with root span information
+// defaultSessionTracesLimit is the number of traces of a session page when no limit is given
+const defaultSessionTracesLimit = 100
+
+// GetTraceOverviews retrieves the root spans of traces matching the filters with their rolled up metrics
 func (s *TracingController) GetTraceOverviews(ctx context.Context, params opensearch.TraceQueryParams) (*opensearch.TraceOverviewResponse, error) {
 	log := logger.GetLogger(ctx)
 	log.Info("Getting trace overviews",
 		"component", params.ComponentUid,
-		"environment", params.EnvironmentUid, "startTime", params.StartTime, "endTime", params.EndTime)
+		"environment", params.EnvironmentUid, "startTime", params.StartTime, "endTime", params.EndTime,
+		"sortBy", params.SortBy)
 
 	// Set defaults
 	if params.Limit == 0 {
@@ -57,16 +102,6 @@ func (s *TracingController) GetTraceOverviews(ctx context.Context, params opense
 		params.Offset = 0
 	}
 
-	// For trace overview, we need to fetch more spans to ensure we get complete traces
-	// Multiply limit by a factor to get enough spans (each trace typically has multiple spans)
-	originalLimit := params.Limit
-	originalOffset := params.Offset
-	params.Limit = params.Limit * 50 // Fetch more spans to capture complete traces
-	params.Offset = 0                // Start from beginning for grouping
-
-	// Use the existing BuildTraceQuery
-	query := opensearch.BuildTraceQuery(params)
-
 	// Generate indices based on time range
 	indices, err := opensearch.GetIndicesForTimeRange(params.StartTime, params.EndTime)
 	if err != nil {
@@ -74,102 +109,287 @@ func (s *TracingController) GetTraceOverviews(ctx context.Context, params opense
 	}
 	log.Debug("Searching indices", "indices", indices)
 
-	// Execute search
-	response, err := s.osClient.Search(ctx, indices, query)
+	// Resolve the model and status filters to trace ID filters
+	matched, err := s.resolveTraceFilters(ctx, indices, &params)
 	if err != nil {
-		return nil, fmt.Errorf("failed to search trace overviews: %w", err)
+		return nil, err
+	}
+	if !matched {
+		return &opensearch.TraceOverviewResponse{Traces: []opensearch.TraceOverview{}}, nil
 	}
 
-	// Parse all spans
-	spans := opensearch.ParseSpans(response)
+	// Find the root spans of the requested page
+	page, err := s.getRootSpans(ctx, indices, params)
+	if err != nil {
+		return nil, err
+	}
+
+	// Fetch all spans of the traces in the page to compute their metrics
+	traceIDs := make([]string, 0, len(page.roots))
+	for _, root := range page.roots {
+		traceIDs = append(traceIDs, root.TraceID)
+	}
+	spans, err := s.fetchSpansForTraces(ctx, indices, traceIDs, params.ComponentUid, params.EnvironmentUid)
+	if err != nil {
+		return nil, err
+	}
 
-	// Group spans by traceId and find root spans
 	traceMap := make(map[string][]opensearch.Span)
 	for _, span := range spans {
 		traceMap[span.TraceID] = append(traceMap[span.TraceID], span)
 	}
 
-	// Process each trace to find root span
-	allOverviews := []opensearch.TraceOverview{}
-	for traceID, traceSpans := range traceMap {
-		// Find root span (span with no parentSpanId)
-		var rootSpan *opensearch.Span
+	overviews := make([]opensearch.TraceOverview, 0, len(page.roots))
+	for i := range page.roots {
+		rootSpan := &page.roots[i]
+		traceSpans := traceMap[rootSpan.TraceID]
+		if len(traceSpans) == 0 {
+			traceSpans = []opensearch.Span{*rootSpan}
+		}
+		overviews = append(overviews, s.buildTraceOverview(rootSpan, traceSpans))
+	}
+	s.attachAnnotationSummaries(ctx, overviews)
 
-		for i := range traceSpans {
-			if traceSpans[i].ParentSpanID == "" {
-				rootSpan = &traceSpans[i]
-				break
-			}
+	log.Info("Retrieved trace overviews",
+		"traces", len(overviews),
+		"total_spans", len(spans),
+		"total_count", page.totalCount)
+
+	return &opensearch.TraceOverviewResponse{
+		Traces:     overviews,
+		TotalCount: page.totalCount,
+		NextCursor: page.nextCursor,
+	}, nil
+}
+
+// resolveTraceFilters resolves the agent, model, status and annotation filters, which depend on more than the
+// root span of a trace, into trace ID filters on the params
+// Returns false when no trace can match
+func (s *TracingController) resolveTraceFilters(ctx context.Context, indices []string, params *opensearch.TraceQueryParams) (bool, error) {
+	// Resolve the traces linked to the requested managed agent
+	if params.AgentID != "" {
+		traceIDs, err := s.getTraceIDsByAgent(ctx, indices, *params)
+		if err != nil {
+			return false, err
 		}
+		params.TraceIDs = intersectTraceIDs(params.TraceIDs, traceIDs)
+		if len(params.TraceIDs) == 0 {
+			return false, nil
+		}
+	}
 
-		// Skip this trace if no root span found
-		if rootSpan == nil {
-			logger.GetLogger(ctx).Warn("No root span found for trace", "traceId", traceID)
-			continue
+	// Resolve the traces annotated with the requested label
+	if params.AnnotationLabel != "" {
+		traceIDs, err := s.getTraceIDsByAnnotationLabel(ctx, params.AnnotationLabel)
+		if err != nil {
+			return false, err
 		}
+		params.TraceIDs = intersectTraceIDs(params.TraceIDs, traceIDs)
+		if len(params.TraceIDs) == 0 {
+			return false, nil
+		}
+	}
 
-		// Extract token usage from GenAI spans
-		tokenUsage := opensearch.ExtractTokenUsage(traceSpans)
-
-		// Extract trace status and error information
-		traceStatus := opensearch.ExtractTraceStatus(traceSpans)
-
-		// Extract input and output from root span
-		// Check if this is a CrewAI workflow span and delegate to CrewAI processor
-		var input, output interface{}
-		if opensearch.IsCrewAISpan(rootSpan.Attributes) {
-			input, output = opensearch.ExtractCrewAIRootSpanInputOutput(rootSpan)
-		} else {
-			input, output = opensearch.ExtractRootSpanInputOutput(rootSpan)
-		}
-
-		// Add to overviews
-		allOverviews = append(allOverviews, opensearch.TraceOverview{
-			TraceID:         traceID,
-			RootSpanID:      rootSpan.SpanID,
-			RootSpanName:    rootSpan.Name,
-			RootSpanKind:    string(opensearch.DetermineSpanType(*rootSpan)),
-			StartTime:       rootSpan.StartTime.Format(time.RFC3339Nano),
-			EndTime:         rootSpan.EndTime.Format(time.RFC3339Nano),
-			DurationInNanos: rootSpan.DurationInNanos,
-			SpanCount:       len(traceSpans),
-			TokenUsage:      tokenUsage,
-			Status:          traceStatus,
-			Input:           input,
-			Output:          output,
-		})
+	// Resolve the traces in which the requested model was used
+	if params.Model != "" {
+		response, err := s.osClient.Search(ctx, indices, opensearch.BuildTraceIDsByModelQuery(*params))
+		if err != nil {
+			return false, fmt.Errorf("failed to search traces by model: %w", err)
+		}
+		traceIDs, err := response.TermsAggregationKeys("traces")
+		if err != nil {
+			return false, fmt.Errorf("failed to decode traces by model: %w", err)
+		}
+		if len(traceIDs) == 0 {
+			return false, nil
+		}
+		params.TraceIDs = intersectTraceIDs(params.TraceIDs, traceIDs)
+		if len(params.TraceIDs) == 0 {
+			return false, nil
+		}
+	}
+
+	// Resolve the traces carrying the requested custom attributes
+	for _, key := range sortedKeys(params.CustomAttributes) {
+		response, err := s.osClient.Search(ctx, indices, opensearch.BuildTraceIDsByCustomAttributeQuery(*params, key, params.CustomAttributes[key]))
+		if err != nil {
+			return false, fmt.Errorf("failed to search traces by custom attribute %s: %w", key, err)
+		}
+		traceIDs, err := response.TermsAggregationKeys("traces")
+		if err != nil {
+			return false, fmt.Errorf("failed to decode traces by custom attribute %s: %w", key, err)
+		}
+		params.TraceIDs = intersectTraceIDs(params.TraceIDs, traceIDs)
+		if len(params.TraceIDs) == 0 {
+			return false, nil
+		}
 	}
 
-	// Sort by StartTime (descending) for consistent pagination
-	sort.Slice(allOverviews, func(i, j int) bool {
-		return allOverviews[i].StartTime > allOverviews[j].StartTime
+	return true, nil
+}
+
+// getTraceIDsByAgent resolves the traces with a span linked to a managed agent
+func (s *TracingController) getTraceIDsByAgent(ctx context.Context, indices []string, params opensearch.TraceQueryParams) ([]string, error) {
+	response, err := s.osClient.Search(ctx, indices, opensearch.BuildTraceIDsByAgentQuery(params))
+	if err != nil {
+		return nil, fmt.Errorf("failed to search traces by agent: %w", err)
+	}
+	traceIDs, err := response.TermsAggregationKeys("traces")
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode traces by agent: %w", err)
+	}
+	return traceIDs, nil
+}
+
+// sortedKeys returns the keys of a map in sorted order
+func sortedKeys(values map[string]string) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// intersectTraceIDs restricts a trace ID filter to the given trace IDs, treating a nil filter as unrestricted
+func intersectTraceIDs(filter []string, traceIDs []string) []string {
+	if filter == nil {
+		return traceIDs
+	}
+	allowed := make(map[string]bool, len(filter))
+	for _, traceID := range filter {
+		allowed[traceID] = true
+	}
+	result := []string{}
+	for _, traceID := range traceIDs {
+		if allowed[traceID] {
+			result = append(result, traceID)
+		}
+	}
+	return result
+}
+
+// rootSpanPage holds the root spans of a page of traces
+type rootSpanPage struct {
+	roots      []opensearch.Span
+	totalCount int
+	nextCursor string
+}
+
+// getRootSpans pages through root spans sorted by start time or duration
+func (s *TracingController) getRootSpans(ctx context.Context, indices []string, params opensearch.TraceQueryParams) (*rootSpanPage, error) {
+	response, next, err := s.osClient.SearchPage(ctx, opensearch.PageRequest{
+		Indices: indices,
+		Query:   opensearch.BuildTraceQuery(params),
+		Size:    params.Limit,
+		Cursor:  params.Cursor,
 	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to search trace overviews: %w", err)
+	}
 
-	// Apply pagination to the trace overviews
-	totalCount := len(allOverviews)
-	start := originalOffset
-	end := originalOffset + originalLimit
+	page := &rootSpanPage{
+		roots:      opensearch.ParseSpans(response),
+		totalCount: response.Hits.Total.Value,
+	}
+	if next != nil {
+		page.nextCursor, err = opensearch.EncodeCursor(*next)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return page, nil
+}
+
+// buildTraceOverview builds the overview of a trace from its root span and all its spans
+func (s *TracingController) buildTraceOverview(rootSpan *opensearch.Span, traceSpans []opensearch.Span) opensearch.TraceOverview {
+	return newTraceOverview(rootSpan, traceSpans, s.pricingTable, s.options.Finalization.TraceState(traceSpans, time.Now()))
+}
 
-	if start >= len(allOverviews) {
-		start = len(allOverviews)
+// newTraceOverview builds the overview of a trace in the given state, shared by the trace queries and the live stream
+func newTraceOverview(rootSpan *opensearch.Span, traceSpans []opensearch.Span, pricingTable *pricing.Table, state string) opensearch.TraceOverview {
+	// Extract token usage from GenAI spans
+	tokenUsage := opensearch.ExtractTokenUsage(traceSpans)
+
+	// Read the token usage of LLM spans, the errors and the guardrail violations from the rollup of the
+	// root span, root spans indexed before traces were rolled up are rolled up from the spans
+	var aggregatedUsage *opensearch.TraceTokenUsage
+	var traceStatus *opensearch.TraceStatus
+	var guardrailViolations int
+	if rootSpan.Rollup != nil {
+		aggregatedUsage = rootSpan.Rollup.TokenUsage()
+		traceStatus = rootSpan.Rollup.Status()
+		guardrailViolations = rootSpan.Rollup.GuardrailViolations
+	} else {
+		aggregatedUsage = opensearch.AggregateTraceTokenUsage(traceSpans)
+		traceStatus = opensearch.ExtractTraceStatus(traceSpans)
+		guardrailViolations = opensearch.CountGuardrailViolations(traceSpans)
 	}
-	if end > len(allOverviews) {
-		end = len(allOverviews)
+	aggregatedUsage.ApplyPricing(pricingTable)
+
+	// Extract input and output from root span
+	// Check if this is a CrewAI workflow span and delegate to CrewAI processor
+	var input, output interface{}
+	if opensearch.IsCrewAISpan(rootSpan.Attributes) {
+		input, output = opensearch.ExtractCrewAIRootSpanInputOutput(rootSpan)
+	} else {
+		input, output = opensearch.ExtractRootSpanInputOutput(rootSpan)
 	}
 
-	paginatedOverviews := allOverviews[start:end]
+	// Use the first session ID found in the trace
+	sessionID := rootSpan.SessionID
+	for i := 0; sessionID == "" && i < len(traceSpans); i++ {
+		sessionID = traceSpans[i].SessionID
+	}
 
-	log.Info("Retrieved trace overviews",
-		"unique_traces", len(allOverviews),
-		"total_spans", len(spans),
-		"showing_start", start,
-		"showing_end", end,
-		"total_count", totalCount)
+	return opensearch.TraceOverview{
+		TraceID:             rootSpan.TraceID,
+		RootSpanID:          rootSpan.SpanID,
+		RootSpanName:        rootSpan.Name,
+		RootSpanKind:        string(opensearch.DetermineSpanType(*rootSpan)),
+		StartTime:           rootSpan.StartTime.Format(time.RFC3339Nano),
+		EndTime:             rootSpan.EndTime.Format(time.RFC3339Nano),
+		DurationInNanos:     rootSpan.DurationInNanos,
+		SpanCount:           len(traceSpans),
+		TokenUsage:          tokenUsage,
+		Status:              traceStatus,
+		Input:               input,
+		Output:              output,
+		AggregatedUsage:     aggregatedUsage,
+		SessionID:           sessionID,
+		GuardrailViolations: guardrailViolations,
+		State:               state,
+	}
+}
 
-	return &opensearch.TraceOverviewResponse{
-		Traces:     paginatedOverviews,
-		TotalCount: totalCount,
-	}, nil
+// fetchSpansForTraces pages through all spans of the given traces with search_after
+func (s *TracingController) fetchSpansForTraces(ctx context.Context, indices []string, traceIDs []string, componentUid, environmentUid string) ([]opensearch.Span, error) {
+	if len(traceIDs) == 0 {
+		return nil, nil
+	}
+
+	var spans []opensearch.Span
+	var searchAfter []interface{}
+	for {
+		query := opensearch.BuildSpansForTracesQuery(traceIDs, componentUid, environmentUid, searchAfter)
+		response, err := s.osClient.Search(ctx, indices, query)
+		if err != nil {
+			return nil, fmt.Errorf("failed to search trace spans: %w", err)
+		}
+
+		hits := response.Hits.Hits
+		spans = append(spans, opensearch.ParseSpans(response)...)
+		if len(hits) < opensearch.TraceSpansPageSize || len(hits[len(hits)-1].Sort) == 0 {
+			break
+		}
+		searchAfter = hits[len(hits)-1].Sort
+	}
+
+	// Resolve attributes that depend on spans of other pages
+	opensearch.AttributeCrewAIManagerAgents(spans)
+
+	return spans, nil
 }
 
 // GetTraceByIdAndService retrieves spans for a specific trace ID and component UID
@@ -216,6 +436,10 @@ func (s *TracingController) GetTraceByIdAndService(ctx context.Context, params o
 	// Extract token usage from GenAI spans
 	tokenUsage := opensearch.ExtractTokenUsage(spans)
 
+	// Roll up token usage from LLM spans
+	aggregatedUsage := opensearch.AggregateTraceTokenUsage(spans)
+	aggregatedUsage.ApplyPricing(s.pricingTable)
+
 	// Extract trace status and error information
 	traceStatus := opensearch.ExtractTraceStatus(spans)
 
@@ -226,14 +450,530 @@ func (s *TracingController) GetTraceByIdAndService(ctx context.Context, params o
 		"environment", params.EnvironmentUid)
 
 	return &opensearch.TraceResponse{
-		Spans:      spans,
-		TotalCount: len(spans),
-		TokenUsage: tokenUsage,
-		Status:     traceStatus,
+		Spans:               spans,
+		TotalCount:          len(spans),
+		TokenUsage:          tokenUsage,
+		Status:              traceStatus,
+		AggregatedUsage:     aggregatedUsage,
+		Annotations:         s.getAnnotationSummaries(ctx, []string{params.TraceID})[params.TraceID],
+		GuardrailViolations: opensearch.CountGuardrailViolations(spans),
 	}, nil
 }
 
+// GetTraceTree retrieves all spans of a trace and assembles them into a tree
+func (s *TracingController) GetTraceTree(ctx context.Context, params opensearch.TraceTreeParams) (*opensearch.TraceTreeResponse, error) {
+	log := logger.GetLogger(ctx)
+	log.Info("Getting trace tree",
+		"traceId", params.TraceID,
+		"component", params.ComponentUid,
+		"environment", params.EnvironmentUid,
+		"depth", params.Depth)
+
+	// Default to the current day and previous 7 days when no time range is given
+	timeRangeGiven := params.StartTime != "" && params.EndTime != ""
+	if !timeRangeGiven {
+		endTime := time.Now()
+		params.StartTime = endTime.AddDate(0, 0, -7).Format(time.RFC3339)
+		params.EndTime = endTime.Format(time.RFC3339)
+	}
+	indices, err := opensearch.GetIndicesForTimeRange(params.StartTime, params.EndTime)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate indices: %w", err)
+	}
+
+	// Page through the spans with search_after since traces can exceed the result window
+	spans, err := s.fetchSpansForTraces(ctx, indices, []string{params.TraceID}, params.ComponentUid, params.EnvironmentUid)
+	if err != nil {
+		return nil, err
+	}
+
+	archived := false
+	if len(spans) == 0 && params.IncludeArchived && s.options.Archive != nil {
+		// The default range only covers live indices, so every recent archive is searched without a time range
+		var archiveIndices []string
+		if timeRangeGiven {
+			archiveIndices = indices
+		}
+		spans, err = s.fetchArchivedSpans(ctx, params, archiveIndices)
+		if err != nil {
+			return nil, err
+		}
+		archived = len(spans) > 0
+	}
+
+	if len(spans) == 0 {
+		log.Warn("No spans found for trace", "traceId", params.TraceID)
+		return nil, ErrTraceNotFound
+	}
+
+	log.Info("Retrieved trace tree", "traceId", params.TraceID, "span_count", len(spans))
+
+	tree := newTraceTree(params.TraceID, spans, params.Depth, s.pricingTable)
+	tree.Annotations = s.getAnnotationSummaries(ctx, []string{params.TraceID})[params.TraceID]
+	tree.Archived = archived
+	return tree, nil
+}
+
+// newTraceTree assembles the spans of a trace into a tree with its rollups, without its annotations
+func newTraceTree(traceID string, spans []opensearch.Span, depth int, pricingTable *pricing.Table) *opensearch.TraceTreeResponse {
+	aggregatedUsage := opensearch.AggregateTraceTokenUsage(spans)
+	aggregatedUsage.ApplyPricing(pricingTable)
+
+	return &opensearch.TraceTreeResponse{
+		TraceID:         traceID,
+		SpanCount:       len(spans),
+		Root:            opensearch.BuildTraceTree(spans, depth),
+		Breakdown:       opensearch.BuildLatencyBreakdown(spans),
+		TokenUsage:      opensearch.ExtractTokenUsage(spans),
+		Status:          opensearch.ExtractTraceStatus(spans),
+		AggregatedUsage: aggregatedUsage,
+	}
+}
+
+// GetTraceSpans reads the indexed spans of a trace from the last 7 days, for the organization scope of ctx
+func (s *TracingController) GetTraceSpans(ctx context.Context, traceID string) ([]opensearch.Span, error) {
+	endTime := time.Now()
+	indices, err := opensearch.GetIndicesForTimeRange(endTime.AddDate(0, 0, -7).Format(time.RFC3339), endTime.Format(time.RFC3339))
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate indices: %w", err)
+	}
+	return s.fetchSpansForTraces(ctx, indices, []string{traceID}, "", "")
+}
+
+// fetchArchivedSpans reads the spans of a trace from the archive with the component and environment filters applied
+func (s *TracingController) fetchArchivedSpans(ctx context.Context, params opensearch.TraceTreeParams, indices []string) ([]opensearch.Span, error) {
+	sources, err := s.options.Archive.FindTrace(ctx, params.TraceID, indices)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search archive: %w", err)
+	}
+
+	spans := make([]opensearch.Span, 0, len(sources))
+	for _, span := range opensearch.ParseSpanSources(sources) {
+		if params.ComponentUid != "" && span.Resource["openchoreo.dev/component-uid"] != params.ComponentUid {
+			continue
+		}
+		if params.EnvironmentUid != "" && span.Resource["openchoreo.dev/environment-uid"] != params.EnvironmentUid {
+			continue
+		}
+		spans = append(spans, span)
+	}
+	logger.GetLogger(ctx).Info("Searched trace archive", "traceId", params.TraceID, "span_count", len(spans))
+	return spans, nil
+}
+
+// GetSessionTraces retrieves the traces of a session in order with cumulative usage and cost
+func (s *TracingController) GetSessionTraces(ctx context.Context, params opensearch.SessionTracesParams) (*opensearch.SessionTracesResponse, error) {
+	log := logger.GetLogger(ctx)
+	log.Info("Getting session traces",
+		"sessionId", params.SessionID,
+		"component", params.ComponentUid,
+		"environment", params.EnvironmentUid)
+
+	// Default to the current day and previous 7 days when no time range is given
+	if params.StartTime == "" || params.EndTime == "" {
+		endTime := time.Now()
+		params.StartTime = endTime.AddDate(0, 0, -7).Format(time.RFC3339)
+		params.EndTime = endTime.Format(time.RFC3339)
+	}
+	indices, err := opensearch.GetIndicesForTimeRange(params.StartTime, params.EndTime)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate indices: %w", err)
+	}
+
+	// Find the traces of the session
+	response, err := s.osClient.Search(ctx, indices, opensearch.BuildTraceIDsBySessionQuery(params))
+	if err != nil {
+		return nil, fmt.Errorf("failed to search session traces: %w", err)
+	}
+	traceIDs, err := response.TermsAggregationKeys("traces")
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode session traces: %w", err)
+	}
+	if len(traceIDs) == 0 {
+		return &opensearch.SessionTracesResponse{SessionID: params.SessionID, Traces: []opensearch.TraceOverview{}}, nil
+	}
+
+	// Page through the turns of the conversation in start time order
+	limit := params.Limit
+	if limit <= 0 {
+		limit = defaultSessionTracesLimit
+	}
+	rootResponse, next, err := s.osClient.SearchPage(ctx, opensearch.PageRequest{
+		Indices: indices,
+		Query: opensearch.BuildTraceQuery(opensearch.TraceQueryParams{
+			ComponentUid:   params.ComponentUid,
+			EnvironmentUid: params.EnvironmentUid,
+			StartTime:      params.StartTime,
+			EndTime:        params.EndTime,
+			Limit:          limit,
+			SortBy:         opensearch.TraceSortStartTime,
+			SortOrder:      "asc",
+			TraceIDs:       traceIDs,
+		}),
+		Size:   limit,
+		Cursor: params.Cursor,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to search session traces: %w", err)
+	}
+	roots := opensearch.ParseSpans(rootResponse)
+
+	// The cumulative usage covers every trace of the session, so all spans are read with the first page only
+	spanTraceIDs := traceIDs
+	if params.Cursor != nil {
+		spanTraceIDs = make([]string, 0, len(roots))
+		for _, root := range roots {
+			spanTraceIDs = append(spanTraceIDs, root.TraceID)
+		}
+	}
+	spans, err := s.fetchSpansForTraces(ctx, indices, spanTraceIDs, params.ComponentUid, params.EnvironmentUid)
+	if err != nil {
+		return nil, err
+	}
+
+	// Group spans by trace and build the overview of each trace
+	traceMap := make(map[string][]opensearch.Span)
+	for _, span := range spans {
+		traceMap[span.TraceID] = append(traceMap[span.TraceID], span)
+	}
+
+	traces := make([]opensearch.TraceOverview, 0, len(roots))
+	for i := range roots {
+		traceSpans := traceMap[roots[i].TraceID]
+		if len(traceSpans) == 0 {
+			traceSpans = []opensearch.Span{roots[i]}
+		}
+		traces = append(traces, s.buildTraceOverview(&roots[i], traceSpans))
+	}
+
+	s.attachAnnotationSummaries(ctx, traces)
+
+	result := &opensearch.SessionTracesResponse{
+		SessionID:  params.SessionID,
+		Traces:     traces,
+		TotalCount: rootResponse.Hits.Total.Value,
+	}
+	if next != nil {
+		result.NextCursor, err = opensearch.EncodeCursor(*next)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if params.Cursor == nil {
+		// Compute cumulative usage across the session
+		aggregatedUsage := opensearch.AggregateTraceTokenUsage(spans)
+		aggregatedUsage.ApplyPricing(s.pricingTable)
+		result.TokenUsage = opensearch.ExtractTokenUsage(spans)
+		result.AggregatedUsage = aggregatedUsage
+	}
+
+	log.Info("Retrieved session traces", "sessionId", params.SessionID, "traces", len(traces), "total_count", result.TotalCount)
+
+	return result, nil
+}
+
+// SearchSpans runs a full-text search over span inputs, outputs and system prompts
+func (s *TracingController) SearchSpans(ctx context.Context, params opensearch.SpanSearchParams) (*opensearch.SpanSearchResponse, error) {
+	log := logger.GetLogger(ctx)
+	log.Info("Searching spans",
+		"component", params.ComponentUid,
+		"environment", params.EnvironmentUid)
+
+	// Default to the current day and previous 7 days when no time range is given
+	if params.StartTime == "" || params.EndTime == "" {
+		endTime := time.Now()
+		params.StartTime = endTime.AddDate(0, 0, -7).Format(time.RFC3339)
+		params.EndTime = endTime.Format(time.RFC3339)
+	}
+	indices, err := opensearch.GetIndicesForTimeRange(params.StartTime, params.EndTime)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate indices: %w", err)
+	}
+
+	response, err := s.osClient.Search(ctx, indices, opensearch.BuildSpanSearchQuery(params))
+	if err != nil {
+		return nil, fmt.Errorf("failed to search spans: %w", err)
+	}
+
+	// Copies of a span in several daily indices are read once, so highlights are matched by span rather than by hit
+	highlights := make(map[string]map[string][]string, len(response.Hits.Hits))
+	for _, hit := range response.Hits.Hits {
+		traceID, _ := hit.Source["traceId"].(string)
+		spanID, _ := hit.Source["spanId"].(string)
+		id := opensearch.SpanDocumentID(traceID, spanID)
+		if _, ok := highlights[id]; !ok {
+			highlights[id] = hit.Highlight
+		}
+	}
+
+	spans := opensearch.ParseSpans(response)
+	results := make([]opensearch.SpanSearchResult, 0, len(spans))
+	for _, span := range spans {
+		result := opensearch.SpanSearchResult{
+			TraceID:    span.TraceID,
+			SpanID:     span.SpanID,
+			Name:       span.Name,
+			StartTime:  span.StartTime,
+			Highlights: map[string][]string{},
+		}
+		if span.AmpAttributes != nil {
+			result.Kind = span.AmpAttributes.Kind
+		}
+
+		// Group the highlighted snippets by input, output and system prompt
+		for field, snippets := range highlights[opensearch.SpanDocumentID(span.TraceID, span.SpanID)] {
+			group := opensearch.SearchFieldGroup(field)
+			if group == "" {
+				continue
+			}
+			result.Highlights[group] = append(result.Highlights[group], snippets...)
+		}
+		results = append(results, result)
+	}
+
+	log.Info("Searched spans", "results", len(results), "total_count", response.Hits.Total.Value)
+
+	return &opensearch.SpanSearchResponse{
+		Results:    results,
+		TotalCount: response.Hits.Total.Value,
+	}, nil
+}
+
+// CompareTraces aligns the spans of two traces side by side
+// Returns a TraceNotFoundError when either trace has no spans in the time range
+func (s *TracingController) CompareTraces(ctx context.Context, params opensearch.TraceCompareParams) (*opensearch.TraceComparison, error) {
+	log := logger.GetLogger(ctx)
+	log.Info("Comparing traces",
+		"leftTraceId", params.LeftTraceID,
+		"rightTraceId", params.RightTraceID,
+		"component", params.ComponentUid,
+		"environment", params.EnvironmentUid)
+
+	// Default to the current day and previous 7 days when no time range is given
+	if params.StartTime == "" || params.EndTime == "" {
+		endTime := time.Now()
+		params.StartTime = endTime.AddDate(0, 0, -7).Format(time.RFC3339)
+		params.EndTime = endTime.Format(time.RFC3339)
+	}
+	indices, err := opensearch.GetIndicesForTimeRange(params.StartTime, params.EndTime)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate indices: %w", err)
+	}
+
+	traceIDs := []string{params.LeftTraceID}
+	if params.RightTraceID != params.LeftTraceID {
+		traceIDs = append(traceIDs, params.RightTraceID)
+	}
+	spans, err := s.fetchSpansForTraces(ctx, indices, traceIDs, params.ComponentUid, params.EnvironmentUid)
+	if err != nil {
+		return nil, err
+	}
+
+	var left, right []opensearch.Span
+	for _, span := range spans {
+		if span.TraceID == params.LeftTraceID {
+			left = append(left, span)
+		}
+		if span.TraceID == params.RightTraceID {
+			right = append(right, span)
+		}
+	}
+	if len(left) == 0 {
+		return nil, &TraceNotFoundError{TraceID: params.LeftTraceID}
+	}
+	if len(right) == 0 {
+		return nil, &TraceNotFoundError{TraceID: params.RightTraceID}
+	}
+
+	comparison := opensearch.CompareTraces(params.LeftTraceID, left, params.RightTraceID, right, s.pricingTable)
+	log.Info("Compared traces", "pairs", len(comparison.Pairs), "onlyLeft", len(comparison.OnlyLeft), "onlyRight", len(comparison.OnlyRight))
+	return comparison, nil
+}
+
+// GetReplayBundle assembles what is needed to re-run a trace with its recorded tool outputs
+func (s *TracingController) GetReplayBundle(ctx context.Context, params opensearch.TraceReplayParams) (*opensearch.ReplayBundle, error) {
+	log := logger.GetLogger(ctx)
+	log.Info("Getting replay bundle",
+		"traceId", params.TraceID,
+		"component", params.ComponentUid,
+		"environment", params.EnvironmentUid)
+
+	// Default to the current day and previous 7 days when no time range is given
+	if params.StartTime == "" || params.EndTime == "" {
+		endTime := time.Now()
+		params.StartTime = endTime.AddDate(0, 0, -7).Format(time.RFC3339)
+		params.EndTime = endTime.Format(time.RFC3339)
+	}
+	indices, err := opensearch.GetIndicesForTimeRange(params.StartTime, params.EndTime)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate indices: %w", err)
+	}
+
+	spans, err := s.fetchSpansForTraces(ctx, indices, []string{params.TraceID}, params.ComponentUid, params.EnvironmentUid)
+	if err != nil {
+		return nil, err
+	}
+	if len(spans) == 0 {
+		log.Warn("No spans found for trace", "traceId", params.TraceID)
+		return nil, ErrTraceNotFound
+	}
+
+	bundle := opensearch.BuildReplayBundle(params.TraceID, spans)
+	log.Info("Assembled replay bundle", "traceId", params.TraceID, "toolResults", len(bundle.ToolResults), "complete", bundle.Complete)
+	return bundle, nil
+}
+
+// GetTraceMetrics retrieves zero-filled trace metric time series for dashboards
+func (s *TracingController) GetTraceMetrics(ctx context.Context, params opensearch.TraceMetricsParams) (*opensearch.TraceMetricsResponse, error) {
+	interval, ok := opensearch.MetricsIntervals[params.Interval]
+	if !ok {
+		return nil, fmt.Errorf("unsupported metrics interval: %s", params.Interval)
+	}
+	if buckets := opensearch.MetricsBucketCount(params.StartTime, params.EndTime, interval); s.options.MaxMetricsBuckets > 0 && buckets > s.options.MaxMetricsBuckets {
+		return nil, fmt.Errorf("%w: %d buckets requested, at most %d allowed", ErrTooManyBuckets, buckets, s.options.MaxMetricsBuckets)
+	}
+
+	ttl := s.queryCacheTTL(ctx, s.options.QueryCache.MetricsTTL)
+	if ttl > 0 {
+		params.StartTime, params.EndTime = roundTimeRange(params.StartTime, params.EndTime, ttl)
+	}
+	return cachedQuery(ctx, s.options.QueryCache.Cache, ttl, "metrics/traces", params, func(ctx context.Context) (*opensearch.TraceMetricsResponse, error) {
+		return s.getTraceMetrics(ctx, params)
+	})
+}
+
+func (s *TracingController) getTraceMetrics(ctx context.Context, params opensearch.TraceMetricsParams) (*opensearch.TraceMetricsResponse, error) {
+	indices, err := opensearch.GetIndicesForTimeRange(params.StartTime.Format(time.RFC3339), params.EndTime.Format(time.RFC3339))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get indices: %w", err)
+	}
+
+	// The metrics of a managed agent cover every span of the traces linked to it
+	if params.AgentID != "" {
+		traceIDs, err := s.getTraceIDsByAgent(ctx, indices, opensearch.TraceQueryParams{
+			AgentID:   params.AgentID,
+			StartTime: params.StartTime.UTC().Format(time.RFC3339Nano),
+			EndTime:   params.EndTime.UTC().Format(time.RFC3339Nano),
+		})
+		if err != nil {
+			return nil, err
+		}
+		if len(traceIDs) == 0 {
+			return opensearch.ParseTraceMetrics(&opensearch.SearchResponse{}, params, s.pricingTable)
+		}
+		params.TraceIDs = traceIDs
+	}
+
+	response, err := s.osClient.Search(ctx, indices, opensearch.BuildTraceMetricsQuery(params))
+	if err != nil {
+		return nil, fmt.Errorf("failed to search trace metrics: %w", err)
+	}
+
+	return opensearch.ParseTraceMetrics(response, params, s.pricingTable)
+}
+
+// GetToolMetrics retrieves tool invocation counts, failures and latency per tool name
+func (s *TracingController) GetToolMetrics(ctx context.Context, params opensearch.ToolMetricsParams) (*opensearch.ToolMetricsResponse, error) {
+	ttl := s.queryCacheTTL(ctx, s.options.QueryCache.ToolsTTL)
+	if ttl > 0 {
+		params.StartTime, params.EndTime = roundTimeRange(params.StartTime, params.EndTime, ttl)
+	}
+	return cachedQuery(ctx, s.options.QueryCache.Cache, ttl, "metrics/tools", params, func(ctx context.Context) (*opensearch.ToolMetricsResponse, error) {
+		return s.getToolMetrics(ctx, params)
+	})
+}
+
+func (s *TracingController) getToolMetrics(ctx context.Context, params opensearch.ToolMetricsParams) (*opensearch.ToolMetricsResponse, error) {
+	indices, err := opensearch.GetIndicesForTimeRange(params.StartTime.Format(time.RFC3339), params.EndTime.Format(time.RFC3339))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get indices: %w", err)
+	}
+
+	response, err := s.osClient.Search(ctx, indices, opensearch.BuildToolMetricsQuery(params))
+	if err != nil {
+		return nil, fmt.Errorf("failed to search tool metrics: %w", err)
+	}
+
+	return opensearch.ParseToolMetrics(response)
+}
+
+// GetModelMetrics retrieves LLM request counts, token usage, cost and latency per served model
+func (s *TracingController) GetModelMetrics(ctx context.Context, params opensearch.ModelMetricsParams) (*opensearch.ModelMetricsResponse, error) {
+	ttl := s.queryCacheTTL(ctx, s.options.QueryCache.ModelsTTL)
+	if ttl > 0 {
+		params.StartTime, params.EndTime = roundTimeRange(params.StartTime, params.EndTime, ttl)
+	}
+	return cachedQuery(ctx, s.options.QueryCache.Cache, ttl, "metrics/models", params, func(ctx context.Context) (*opensearch.ModelMetricsResponse, error) {
+		return s.getModelMetrics(ctx, params)
+	})
+}
+
+func (s *TracingController) getModelMetrics(ctx context.Context, params opensearch.ModelMetricsParams) (*opensearch.ModelMetricsResponse, error) {
+	indices, err := opensearch.GetIndicesForTimeRange(params.StartTime.Format(time.RFC3339), params.EndTime.Format(time.RFC3339))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get indices: %w", err)
+	}
+
+	response, err := s.osClient.Search(ctx, indices, opensearch.BuildModelMetricsQuery(params))
+	if err != nil {
+		return nil, fmt.Errorf("failed to search model metrics: %w", err)
+	}
+
+	return opensearch.ParseModelMetrics(response, s.pricingTable)
+}
+
+// queryCacheTTL returns the time results of a request may be cached, zero when they are not cached
+func (s *TracingController) queryCacheTTL(ctx context.Context, ttl time.Duration) time.Duration {
+	if s.options.QueryCache.Cache == nil || querycache.Bypassed(ctx) {
+		return 0
+	}
+	return ttl
+}
+
+// roundTimeRange widens a time range to multiples of step, so that dashboards asking for the
+// last hour within the same step share one cached result
+func roundTimeRange(start, end time.Time, step time.Duration) (time.Time, time.Time) {
+	roundedEnd := end.UTC().Truncate(step)
+	if roundedEnd.Before(end) {
+		roundedEnd = roundedEnd.Add(step)
+	}
+	return start.UTC().Truncate(step), roundedEnd
+}
+
+// cachedQuery serves the result of load from the query cache when ttl is positive, keyed on the endpoint,
+// the organization of ctx and the params
+func cachedQuery[T any](ctx context.Context, cache *querycache.Cache, ttl time.Duration, endpoint string, params interface{}, load func(ctx context.Context) (*T, error)) (*T, error) {
+	if ttl <= 0 {
+		return load(ctx)
+	}
+	orgID, _ := opensearch.OrgScope(ctx)
+	key, err := querycache.Key(endpoint, orgID, params)
+	if err != nil {
+		return nil, err
+	}
+	data, _, err := cache.Get(ctx, key, ttl, func(ctx context.Context) ([]byte, error) {
+		result, err := load(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(result)
+	})
+	if err != nil {
+		return nil, err
+	}
+	var result T
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("failed to decode cached %s result: %w", endpoint, err)
+	}
+	return &result, nil
+}
+
 // HealthCheck checks if the service is healthy
 func (s *TracingController) HealthCheck(ctx context.Context) error {
 	return s.osClient.HealthCheck(ctx)
 }
+
+// OpenSearchStats returns the retry and circuit breaker counters of the OpenSearch client
+func (s *TracingController) OpenSearchStats() opensearch.ClientStats {
+	return s.osClient.Stats()
+}
@@ -0,0 +1,229 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/config"
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/opensearch"
+)
+
+// listingTestTrace is a root span holding the rollup of its trace
+type listingTestTrace struct {
+	traceID    string
+	start      time.Time
+	tokens     int
+	ingestedAt time.Time // Left out of the document when zero
+}
+
+// fakeListingOpenSearch serves root spans sorted by the requested sort field and traceId with search_after
+// Span searches find no further spans, so overviews are built from the root spans alone
+type fakeListingOpenSearch struct {
+	traces []listingTestTrace
+}
+
+func (f *fakeListingOpenSearch) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if r.URL.Path == "/" {
+		fmt.Fprint(w, `{"cluster_name":"test","version":{"distribution":"opensearch","number":"2.11.0"}}`)
+		return
+	}
+	if r.Method != http.MethodPost || !strings.HasSuffix(r.URL.Path, "/_search") || strings.Contains(r.URL.Path, opensearch.AnnotationIndex) {
+		http.NotFound(w, r)
+		return
+	}
+
+	var body struct {
+		Size        int                                     `json:"size"`
+		SearchAfter []interface{}                           `json:"search_after"`
+		Sort        []map[string]map[string]json.RawMessage `json:"sort"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if len(body.Sort) != 3 {
+		fmt.Fprint(w, `{"hits":{"total":{"value":0},"hits":[]}}`)
+		return
+	}
+
+	var field, order string
+	for name, options := range body.Sort[0] {
+		field = name
+		_ = json.Unmarshal(options["order"], &order)
+	}
+	value := func(trace listingTestTrace) int64 {
+		switch field {
+		case "startTime":
+			return trace.start.UnixMilli()
+		case opensearch.TraceRollupField + ".totalTokens":
+			return int64(trace.tokens)
+		}
+		panic("unexpected sort field " + field)
+	}
+	before := func(a, b listingTestTrace) bool {
+		if value(a) != value(b) {
+			return (value(a) < value(b)) == (order == "asc")
+		}
+		if a.traceID == b.traceID {
+			return false
+		}
+		return (a.traceID < b.traceID) == (order == "asc")
+	}
+
+	roots := append([]listingTestTrace(nil), f.traces...)
+	sort.Slice(roots, func(i, j int) bool { return before(roots[i], roots[j]) })
+	total := len(roots)
+	if len(body.SearchAfter) > 0 {
+		last := listingTestTrace{traceID: body.SearchAfter[1].(string), start: time.UnixMilli(int64(body.SearchAfter[0].(float64))), tokens: int(body.SearchAfter[0].(float64))}
+		i := sort.Search(len(roots), func(i int) bool { return before(last, roots[i]) })
+		roots = roots[i:]
+	}
+	if len(roots) > body.Size {
+		roots = roots[:body.Size]
+	}
+	hits := []string{}
+	for _, root := range roots {
+		ingestedAt := ""
+		if !root.ingestedAt.IsZero() {
+			ingestedAt = fmt.Sprintf(`,%q:%q`, opensearch.IngestedAtField, root.ingestedAt.Format(time.RFC3339Nano))
+		}
+		source := fmt.Sprintf(`{"traceId":%q,"spanId":"root","name":"agent","startTime":%q,"endTime":%q,%q:{"totalTokens":%d,"models":[{"model":"gpt-4o","totalTokens":%d}]}%s}`,
+			root.traceID, root.start.Format(time.RFC3339Nano), root.start.Add(time.Second).Format(time.RFC3339Nano), opensearch.TraceRollupField, root.tokens, root.tokens, ingestedAt)
+		hits = append(hits, fmt.Sprintf(`{"_source":%s,"sort":[%d,%q,"root"]}`, source, value(root), root.traceID))
+	}
+	fmt.Fprintf(w, `{"hits":{"total":{"value":%d},"hits":[%s]}}`, total, strings.Join(hits, ","))
+}
+
+// newListingTestController returns a tracing controller of a fake OpenSearch holding the given traces
+func newListingTestController(t *testing.T, traces []listingTestTrace, options TracingOptions) *TracingController {
+	server := httptest.NewServer(&fakeListingOpenSearch{traces: traces})
+	t.Cleanup(server.Close)
+
+	client, err := opensearch.NewClient(&config.OpenSearchConfig{Address: server.URL, RequestTimeout: 5 * time.Second}, nil)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	return NewTracingController(client, nil, options)
+}
+
+func TestGetTraceOverviewsSortsByRolledUpTokens(t *testing.T) {
+	start := time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)
+	traces := make([]listingTestTrace, 1001)
+	for i := range traces {
+		traces[i] = listingTestTrace{traceID: fmt.Sprintf("trace-%04d", i), start: start.Add(time.Duration(i) * time.Second), tokens: i % 100}
+	}
+	// The oldest trace is not among the 1000 most recent ones
+	traces[0].tokens = 1_000_000
+	controller := newListingTestController(t, traces, TracingOptions{})
+
+	params := opensearch.TraceQueryParams{
+		StartTime: "2026-10-16T00:00:00Z",
+		EndTime:   "2026-10-16T23:59:59Z",
+		Limit:     10,
+		SortBy:    opensearch.TraceSortTokens,
+		SortOrder: "desc",
+	}
+	first, err := controller.GetTraceOverviews(context.Background(), params)
+	if err != nil {
+		t.Fatalf("GetTraceOverviews() error = %v", err)
+	}
+	if len(first.Traces) != 10 || first.Traces[0].TraceID != "trace-0000" {
+		t.Fatalf("first page = %d traces starting with %v, want the oldest trace first", len(first.Traces), first.Traces)
+	}
+	if first.TotalCount != 1001 {
+		t.Errorf("TotalCount = %d, want 1001", first.TotalCount)
+	}
+	if first.Traces[0].AggregatedUsage == nil || first.Traces[0].AggregatedUsage.TotalTokens != 1_000_000 {
+		t.Errorf("AggregatedUsage = %+v, want the rolled up tokens", first.Traces[0].AggregatedUsage)
+	}
+
+	// The next page continues after the last trace, tokens tie among traces
+	cursor, err := opensearch.DecodeCursor(first.NextCursor)
+	if err != nil {
+		t.Fatalf("DecodeCursor() error = %v", err)
+	}
+	params.Cursor = cursor
+	second, err := controller.GetTraceOverviews(context.Background(), params)
+	if err != nil {
+		t.Fatalf("GetTraceOverviews() error = %v", err)
+	}
+	seen := map[string]bool{}
+	for _, trace := range append(first.Traces, second.Traces...) {
+		if seen[trace.TraceID] {
+			t.Errorf("trace %s listed twice", trace.TraceID)
+		}
+		seen[trace.TraceID] = true
+	}
+	if len(second.Traces) != 10 || second.Traces[0].AggregatedUsage.TotalTokens > first.Traces[9].AggregatedUsage.TotalTokens {
+		t.Errorf("second page = %v, want the next 10 traces by tokens", second.Traces)
+	}
+}
+
+func TestGetTraceOverviewsReportsTraceStates(t *testing.T) {
+	now := time.Now()
+	start := now.Add(-3 * time.Hour)
+	const quietPeriod = 300 * time.Millisecond
+	traces := []listingTestTrace{
+		{traceID: "receiving", start: start, ingestedAt: now},
+		{traceID: "quiet", start: start, ingestedAt: now.Add(-time.Minute)},
+		{traceID: "unrecorded", start: start},
+	}
+	controller := newListingTestController(t, traces, TracingOptions{
+		Finalization: opensearch.FinalizationPolicy{QuietPeriod: quietPeriod},
+	})
+	params := opensearch.TraceQueryParams{
+		StartTime: start.Add(-time.Minute).Format(time.RFC3339),
+		EndTime:   now.Add(time.Minute).Format(time.RFC3339),
+		Limit:     10,
+		SortOrder: "desc",
+	}
+	states := func() map[string]string {
+		t.Helper()
+		response, err := controller.GetTraceOverviews(context.Background(), params)
+		if err != nil {
+			t.Fatalf("GetTraceOverviews() error = %v", err)
+		}
+		states := map[string]string{}
+		for _, trace := range response.Traces {
+			states[trace.TraceID] = trace.State
+		}
+		return states
+	}
+
+	want := map[string]string{"receiving": opensearch.TraceStateOpen, "quiet": opensearch.TraceStateFinalized, "unrecorded": opensearch.TraceStateFinalized}
+	if got := states(); !reflect.DeepEqual(got, want) {
+		t.Fatalf("states = %v, want %v", got, want)
+	}
+
+	// The receiving trace is finalized once quiet for the quiet period
+	time.Sleep(quietPeriod + 100*time.Millisecond)
+	want["receiving"] = opensearch.TraceStateFinalized
+	if got := states(); !reflect.DeepEqual(got, want) {
+		t.Errorf("states after the quiet period = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,182 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/metrics"
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/middleware/logger"
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/opensearch"
+)
+
+// ErrDeadLetterNotFound is returned when a dead letter is not found
+var ErrDeadLetterNotFound = errors.New("dead letter not found")
+
+// MaxDeadLettersPerRetry bounds the number of dead letters retried by a single RetryAll
+const MaxDeadLettersPerRetry = 1000
+
+// DeadLetterStore keeps the documents that could not be indexed, implemented by opensearch.DeadLetterStore
+type DeadLetterStore interface {
+	List(ctx context.Context, filter opensearch.DeadLetterFilter, limit, offset int) (*opensearch.DeadLetterList, error)
+	Get(ctx context.Context, id string) (*opensearch.DeadLetter, bool, error)
+	Delete(ctx context.Context, id string) (bool, error)
+}
+
+// RetryIndexer indexes retried documents, implemented by opensearch.BulkIndexer
+type RetryIndexer interface {
+	Add(ctx context.Context, doc opensearch.BulkDocument) error
+	Flush(ctx context.Context) error
+}
+
+// DeadLetterRetry reports the outcome of retrying a dead letter
+type DeadLetterRetry struct {
+	DeadLetterID string `json:"deadLetterId"`
+	Resolved     bool   `json:"resolved"`        // The document was indexed and its dead letter removed
+	Error        string `json:"error,omitempty"` // Why the document failed again, its dead letter then holds the new failure
+}
+
+// DeadLetterRetryAll reports the outcome of retrying the dead letters matching a filter
+type DeadLetterRetryAll struct {
+	Matched  int               `json:"matched"` // Dead letters matching the filter, only MaxDeadLettersPerRetry are retried at once
+	Retried  int               `json:"retried"`
+	Resolved int               `json:"resolved"`
+	Failed   int               `json:"failed"`
+	Results  []DeadLetterRetry `json:"results"`
+}
+
+// DeadLetterController browses dead letters and pushes them back through the pipeline
+type DeadLetterController struct {
+	store   DeadLetterStore
+	indexer RetryIndexer
+	metrics *metrics.Metrics
+}
+
+// NewDeadLetterController creates a new dead-letter controller
+func NewDeadLetterController(store DeadLetterStore, indexer RetryIndexer, m *metrics.Metrics) *DeadLetterController {
+	return &DeadLetterController{
+		store:   store,
+		indexer: indexer,
+		metrics: m,
+	}
+}
+
+// List returns the dead letters matching a filter, newest first
+func (c *DeadLetterController) List(ctx context.Context, filter opensearch.DeadLetterFilter, limit, offset int) (*opensearch.DeadLetterList, error) {
+	return c.store.List(ctx, filter, limit, offset)
+}
+
+// Retry pushes a dead letter back through the pipeline and waits until its document is indexed or fails again
+func (c *DeadLetterController) Retry(ctx context.Context, id string) (*DeadLetterRetry, error) {
+	letter, found, err := c.store.Get(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dead letter: %w", err)
+	}
+	if !found {
+		return nil, ErrDeadLetterNotFound
+	}
+	results := c.retry(ctx, []opensearch.DeadLetter{*letter})
+	return &results[0], nil
+}
+
+// RetryAll retries the oldest dead letters matching a filter, up to MaxDeadLettersPerRetry at once
+func (c *DeadLetterController) RetryAll(ctx context.Context, filter opensearch.DeadLetterFilter) (*DeadLetterRetryAll, error) {
+	list, err := c.store.List(ctx, filter, MaxDeadLettersPerRetry, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	results := c.retry(ctx, list.DeadLetters)
+	summary := &DeadLetterRetryAll{Matched: list.TotalCount, Retried: len(results), Results: results}
+	for _, result := range results {
+		if result.Resolved {
+			summary.Resolved++
+		} else {
+			summary.Failed++
+		}
+	}
+	return summary, nil
+}
+
+// retry processes the documents of dead letters again and queues them for indexing
+// Span documents are reprocessed so that fixes of the processing pipeline apply, they skip the tail sampler
+// whose decision was made when they first arrived. Documents failing again replace their dead letter.
+func (c *DeadLetterController) retry(ctx context.Context, letters []opensearch.DeadLetter) []DeadLetterRetry {
+	log := logger.GetLogger(ctx)
+
+	results := make([]DeadLetterRetry, len(letters))
+	pending := make([]chan error, len(letters))
+	for i, letter := range letters {
+		results[i].DeadLetterID = letter.EntryID
+
+		var source map[string]interface{}
+		if err := json.Unmarshal(letter.Document, &source); err != nil || source == nil {
+			results[i].Error = "dead letter has no document to retry"
+			continue
+		}
+		if opensearch.IsTraceIndex(letter.Index) {
+			processSpan(source, c.metrics)
+		}
+
+		done := make(chan error, 1)
+		err := c.indexer.Add(ctx, opensearch.BulkDocument{
+			Index:  letter.Index,
+			ID:     letter.ID,
+			Body:   source,
+			OnDone: func(err error) { done <- err },
+		})
+		if err != nil {
+			results[i].Error = fmt.Sprintf("failed to queue document: %v", err)
+			continue
+		}
+		pending[i] = done
+	}
+
+	// Send the retried documents without waiting for the flush interval
+	if err := c.indexer.Flush(ctx); err != nil {
+		log.Warn("Failed to flush retried dead letters", "error", err)
+	}
+
+	for i, done := range pending {
+		if done == nil {
+			continue
+		}
+		select {
+		case err := <-done:
+			if err != nil {
+				results[i].Error = err.Error()
+				continue
+			}
+		case <-ctx.Done():
+			// The document is still queued, its dead letter is removed by the next retry once it is indexed
+			results[i].Error = fmt.Sprintf("document not indexed yet: %v", ctx.Err())
+			continue
+		}
+
+		results[i].Resolved = true
+		if _, err := c.store.Delete(ctx, letters[i].EntryID); err != nil {
+			// The entry expires with its retention, retrying it again only re-indexes the document
+			log.Warn("Failed to remove retried dead letter", "deadLetterId", letters[i].EntryID, "error", err)
+		}
+	}
+
+	log.Info("Retried dead letters", "count", len(letters))
+	return results
+}
@@ -0,0 +1,274 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package controllers
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/config"
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/opensearch"
+)
+
+// deadLetterOpenSearch rejects span documents with a mapping conflict while reject is set
+// and keeps the dead-letter index in memory
+type deadLetterOpenSearch struct {
+	mu          sync.Mutex
+	reject      bool
+	indexed     map[string]map[string]interface{}
+	deadLetters map[string]json.RawMessage
+	indexExists bool
+}
+
+func newDeadLetterOpenSearch() *deadLetterOpenSearch {
+	return &deadLetterOpenSearch{
+		reject:      true,
+		indexed:     map[string]map[string]interface{}{},
+		deadLetters: map[string]json.RawMessage{},
+	}
+}
+
+func (f *deadLetterOpenSearch) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+
+	deadLetterPath := "/" + opensearch.DeadLetterIndex
+	switch {
+	case r.URL.Path == "/_bulk":
+		f.bulk(w, r)
+	case r.URL.Path == deadLetterPath && r.Method == http.MethodHead:
+		if !f.indexExists {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	case r.URL.Path == deadLetterPath && r.Method == http.MethodPut:
+		f.indexExists = true
+		fmt.Fprint(w, `{"acknowledged":true}`)
+	case r.URL.Path == deadLetterPath+"/_search":
+		var hits []string
+		for _, source := range f.deadLetters {
+			hits = append(hits, fmt.Sprintf(`{"_source":%s}`, source))
+		}
+		fmt.Fprintf(w, `{"hits":{"total":{"value":%d},"hits":[%s]}}`, len(hits), strings.Join(hits, ","))
+	case strings.HasPrefix(r.URL.Path, deadLetterPath+"/_doc/"):
+		id := strings.TrimPrefix(r.URL.Path, deadLetterPath+"/_doc/")
+		source, ok := f.deadLetters[id]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"found":false}`)
+			return
+		}
+		if r.Method == http.MethodDelete {
+			delete(f.deadLetters, id)
+			fmt.Fprint(w, `{"result":"deleted"}`)
+			return
+		}
+		fmt.Fprintf(w, `{"found":true,"_source":%s}`, source)
+	default:
+		fmt.Fprint(w, `{"cluster_name":"test","version":{"distribution":"opensearch","number":"2.11.0"}}`)
+	}
+}
+
+func (f *deadLetterOpenSearch) bulk(w http.ResponseWriter, r *http.Request) {
+	var items []string
+	scanner := bufio.NewScanner(r.Body)
+	scanner.Buffer(make([]byte, 1024*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var action map[string]struct {
+			Index string `json:"_index"`
+			ID    string `json:"_id"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &action); err != nil || !scanner.Scan() {
+			http.Error(w, "invalid bulk body", http.StatusBadRequest)
+			return
+		}
+		meta := action["index"]
+		source := append(json.RawMessage(nil), scanner.Bytes()...)
+
+		switch {
+		case meta.Index == opensearch.DeadLetterIndex:
+			f.deadLetters[meta.ID] = source
+		case f.reject:
+			items = append(items, fmt.Sprintf(`{"index":{"_index":%q,"_id":%q,"status":400,"error":{"type":"mapper_parsing_exception","reason":"failed to parse field [attributes.llm.usage]"}}}`, meta.Index, meta.ID))
+			continue
+		default:
+			var document map[string]interface{}
+			_ = json.Unmarshal(source, &document)
+			f.indexed[meta.ID] = document
+		}
+		items = append(items, fmt.Sprintf(`{"index":{"_index":%q,"_id":%q,"status":201}}`, meta.Index, meta.ID))
+	}
+	fmt.Fprintf(w, `{"took":1,"errors":false,"items":[%s]}`, strings.Join(items, ","))
+}
+
+func (f *deadLetterOpenSearch) setReject(reject bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.reject = reject
+}
+
+func (f *deadLetterOpenSearch) counts() (indexed, deadLetters int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.indexed), len(f.deadLetters)
+}
+
+// indexSpan queues a span document and waits until it is indexed or dead-lettered
+func indexSpan(t *testing.T, indexer *opensearch.BulkIndexer, traceID, spanID string) error {
+	t.Helper()
+	done := make(chan error, 1)
+	err := indexer.Add(context.Background(), opensearch.BulkDocument{
+		Index: opensearch.TraceIndexName(time.Now()),
+		ID:    opensearch.SpanDocumentID(traceID, spanID),
+		Body: map[string]interface{}{
+			"traceId":   traceID,
+			"spanId":    spanID,
+			"name":      "chat",
+			"startTime": time.Now().UTC().Format(time.RFC3339Nano),
+		},
+		OnDone: func(err error) { done <- err },
+	})
+	if err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	if err := indexer.Flush(context.Background()); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(5 * time.Second):
+		t.Fatal("span neither indexed nor dead-lettered")
+		return nil
+	}
+}
+
+func newDeadLetterPipeline(t *testing.T) (*deadLetterOpenSearch, *opensearch.BulkIndexer, *opensearch.DeadLetterStore) {
+	t.Helper()
+	fake := newDeadLetterOpenSearch()
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	client, err := opensearch.NewClient(&config.OpenSearchConfig{
+		Address:        server.URL,
+		Username:       "admin",
+		Password:       "admin",
+		RequestTimeout: 5 * time.Second,
+	}, nil)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	store := client.NewDeadLetterStore(time.Hour)
+	indexer, err := client.NewBulkIndexer(opensearch.BulkIndexerConfig{FlushInterval: time.Hour, DeadLetterStore: store})
+	if err != nil {
+		t.Fatalf("NewBulkIndexer() error = %v", err)
+	}
+	t.Cleanup(func() { _ = indexer.Close(context.Background()) })
+	return fake, indexer, store
+}
+
+func TestDeadLetterRetryResolvesFixedDocuments(t *testing.T) {
+	fake, indexer, store := newDeadLetterPipeline(t)
+	controller := NewDeadLetterController(store, indexer, nil)
+	ctx := context.Background()
+
+	traceID := "0af7651916cd43dd8448eb211c80319c"
+	if err := indexSpan(t, indexer, traceID, "b7ad6b7169203331"); err == nil {
+		t.Fatal("span with a mapping conflict was indexed")
+	}
+	list, err := controller.List(ctx, opensearch.DeadLetterFilter{}, 10, 0)
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(list.DeadLetters) != 1 {
+		t.Fatalf("dead letters = %d, want 1", len(list.DeadLetters))
+	}
+	letter := list.DeadLetters[0]
+	if letter.TraceID != traceID || letter.Status != http.StatusBadRequest || !strings.Contains(letter.Reason, "mapper_parsing_exception") {
+		t.Errorf("dead letter = %+v, want the trace, status and reason of the failure", letter)
+	}
+	if letter.ExpiresAt == nil || !letter.ExpiresAt.After(letter.Time) {
+		t.Errorf("dead letter expires at %v, want after %v", letter.ExpiresAt, letter.Time)
+	}
+
+	// Failing again replaces the entry rather than adding one
+	result, err := controller.Retry(ctx, letter.EntryID)
+	if err != nil {
+		t.Fatalf("Retry() error = %v", err)
+	}
+	if result.Resolved || result.Error == "" {
+		t.Errorf("retry of a still failing document = %+v, want unresolved with its error", result)
+	}
+	if _, deadLetters := fake.counts(); deadLetters != 1 {
+		t.Errorf("dead letters after failed retry = %d, want 1", deadLetters)
+	}
+
+	fake.setReject(false)
+	result, err = controller.Retry(ctx, letter.EntryID)
+	if err != nil {
+		t.Fatalf("Retry() error = %v", err)
+	}
+	if !result.Resolved {
+		t.Fatalf("retry after the fix = %+v, want resolved", result)
+	}
+	indexed, deadLetters := fake.counts()
+	if indexed != 1 || deadLetters != 0 {
+		t.Errorf("after retry indexed = %d and dead letters = %d, want 1 and 0", indexed, deadLetters)
+	}
+	// Retried span documents went through processing again
+	fake.mu.Lock()
+	_, processed := fake.indexed[letter.ID][opensearch.IngestedAtField]
+	fake.mu.Unlock()
+	if !processed {
+		t.Error("retried span document was not processed")
+	}
+
+	if _, err := controller.Retry(ctx, letter.EntryID); err != ErrDeadLetterNotFound {
+		t.Errorf("Retry() of a resolved dead letter error = %v, want ErrDeadLetterNotFound", err)
+	}
+}
+
+func TestDeadLetterRetryAll(t *testing.T) {
+	fake, indexer, store := newDeadLetterPipeline(t)
+	controller := NewDeadLetterController(store, indexer, nil)
+
+	for _, spanID := range []string{"b7ad6b7169203331", "b7ad6b7169203332", "b7ad6b7169203333"} {
+		if err := indexSpan(t, indexer, "0af7651916cd43dd8448eb211c80319c", spanID); err == nil {
+			t.Fatal("span with a mapping conflict was indexed")
+		}
+	}
+
+	fake.setReject(false)
+	result, err := controller.RetryAll(context.Background(), opensearch.DeadLetterFilter{Reason: "mapper_parsing_exception"})
+	if err != nil {
+		t.Fatalf("RetryAll() error = %v", err)
+	}
+	if result.Matched != 3 || result.Retried != 3 || result.Resolved != 3 || result.Failed != 0 {
+		t.Errorf("RetryAll() = %+v, want 3 matched, retried and resolved", result)
+	}
+	if indexed, deadLetters := fake.counts(); indexed != 3 || deadLetters != 0 {
+		t.Errorf("after retry indexed = %d and dead letters = %d, want 3 and 0", indexed, deadLetters)
+	}
+}
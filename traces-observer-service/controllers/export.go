@@ -0,0 +1,129 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/middleware/logger"
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/opensearch"
+)
+
+// exportPageSize is the number of traces fetched per page of an export
+const exportPageSize = 200
+
+// exportKeepAlive is how long the point in time of an export is kept between pages
+const exportKeepAlive = "2m"
+
+// TraceExportResult summarises a finished export
+type TraceExportResult struct {
+	Rows      int  // Traces written
+	Truncated bool // Whether more traces matched than MaxExportRows
+	MaxRows   int
+}
+
+// ExportTraces streams the overviews of the traces matching the filters to the visitor, one page at a time
+// Pages are read with SearchPage, so that traces indexed during the export do not shift the pages
+func (s *TracingController) ExportTraces(ctx context.Context, params opensearch.TraceQueryParams, visit func(opensearch.TraceOverview) error) (*TraceExportResult, error) {
+	log := logger.GetLogger(ctx)
+
+	result := &TraceExportResult{MaxRows: s.options.MaxExportRows}
+
+	indices, err := opensearch.GetIndicesForTimeRange(params.StartTime, params.EndTime)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate indices: %w", err)
+	}
+
+	// Nothing is exported when no index of the time range exists
+	existing, err := s.osClient.GetIndices(ctx, strings.Join(indices, ","))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list trace indices: %w", err)
+	}
+	if len(existing) == 0 {
+		return result, nil
+	}
+
+	matched, err := s.resolveTraceFilters(ctx, existing, &params)
+	if err != nil {
+		return nil, err
+	}
+	if !matched {
+		return result, nil
+	}
+
+	params.Offset = 0
+	params.Cursor = nil
+	// Closes the point in time of an export that stops early
+	defer func() {
+		s.osClient.ReleaseCursor(ctx, params.Cursor)
+	}()
+	for {
+		remaining := result.MaxRows - result.Rows
+		// One extra trace tells whether the export is truncated
+		params.Limit = min(exportPageSize, remaining+1)
+
+		response, next, err := s.osClient.SearchPage(ctx, opensearch.PageRequest{
+			Indices:   existing,
+			Query:     opensearch.BuildTraceQuery(params),
+			Size:      params.Limit,
+			Cursor:    params.Cursor,
+			KeepAlive: exportKeepAlive,
+		})
+		if err != nil {
+			return result, fmt.Errorf("failed to search traces: %w", err)
+		}
+		params.Cursor = next
+		roots := opensearch.ParseSpans(response)
+		if len(roots) > remaining {
+			roots = roots[:remaining]
+			result.Truncated = true
+		}
+
+		traceIDs := make([]string, 0, len(roots))
+		for _, root := range roots {
+			traceIDs = append(traceIDs, root.TraceID)
+		}
+		spans, err := s.fetchSpansForTraces(ctx, existing, traceIDs, params.ComponentUid, params.EnvironmentUid)
+		if err != nil {
+			return result, err
+		}
+		traceMap := make(map[string][]opensearch.Span)
+		for _, span := range spans {
+			traceMap[span.TraceID] = append(traceMap[span.TraceID], span)
+		}
+
+		for i := range roots {
+			traceSpans := traceMap[roots[i].TraceID]
+			if len(traceSpans) == 0 {
+				traceSpans = []opensearch.Span{roots[i]}
+			}
+			if err := visit(s.buildTraceOverview(&roots[i], traceSpans)); err != nil {
+				return result, err
+			}
+			result.Rows++
+		}
+
+		if result.Truncated || next == nil {
+			break
+		}
+	}
+
+	log.Info("Exported traces", "rows", result.Rows, "truncated", result.Truncated)
+	return result, nil
+}
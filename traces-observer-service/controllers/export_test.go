@@ -0,0 +1,233 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/config"
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/opensearch"
+)
+
+// fakeExportOpenSearch serves the index lookup, root span pages and trace span queries of an export
+// Traces start one second apart, the newest last
+type fakeExportOpenSearch struct {
+	start  time.Time
+	traces int
+
+	mu        sync.Mutex
+	rootPages int
+}
+
+func (f *fakeExportOpenSearch) traceID(i int) string {
+	return fmt.Sprintf("trace-%04d", i)
+}
+
+func (f *fakeExportOpenSearch) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	switch {
+	case r.URL.Path == "/":
+		fmt.Fprint(w, `{"cluster_name":"test","version":{"distribution":"opensearch","number":"2.11.0"}}`)
+		return
+	case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/otel-traces-"):
+		fmt.Fprintf(w, `{%q:{}}`, opensearch.TraceIndexName(f.start))
+		return
+	case r.Method != http.MethodPost || !strings.HasSuffix(r.URL.Path, "/_search"):
+		// No point in time, pages are read with search_after only
+		http.NotFound(w, r)
+		return
+	}
+
+	var body struct {
+		Size        int                      `json:"size"`
+		SearchAfter []interface{}            `json:"search_after"`
+		Sort        []map[string]interface{} `json:"sort"`
+		Query       json.RawMessage          `json:"query"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	hits := []string{}
+	if len(body.Sort) == 3 {
+		// Root spans, newest first
+		f.mu.Lock()
+		f.rootPages++
+		f.mu.Unlock()
+		for i := f.traces - 1; i >= 0 && len(hits) < body.Size; i-- {
+			start := f.start.Add(time.Duration(i) * time.Second)
+			if len(body.SearchAfter) > 0 && float64(start.UnixMilli()) >= body.SearchAfter[0].(float64) {
+				continue
+			}
+			hits = append(hits, fmt.Sprintf(`{"_source":%s,"sort":[%d,%q,"root"]}`, f.rootSpan(i), start.UnixMilli(), f.traceID(i)))
+		}
+	} else {
+		// Every span of the requested traces
+		var query struct {
+			Bool struct {
+				Must []struct {
+					Terms map[string][]string `json:"terms"`
+				} `json:"must"`
+			} `json:"bool"`
+		}
+		_ = json.Unmarshal(body.Query, &query)
+		for _, traceID := range query.Bool.Must[0].Terms["traceId"] {
+			var i int
+			fmt.Sscanf(traceID, "trace-%d", &i)
+			hits = append(hits, fmt.Sprintf(`{"_source":%s}`, f.rootSpan(i)))
+		}
+	}
+	fmt.Fprintf(w, `{"hits":{"total":{"value":%d},"hits":[%s]}}`, len(hits), strings.Join(hits, ","))
+}
+
+func (f *fakeExportOpenSearch) rootSpan(i int) string {
+	start := f.start.Add(time.Duration(i) * time.Second)
+	return fmt.Sprintf(`{"traceId":%q,"spanId":"root","name":"turn","startTime":%q,"endTime":%q}`,
+		f.traceID(i), start.Format(time.RFC3339Nano), start.Add(time.Second).Format(time.RFC3339Nano))
+}
+
+// newExportTestController returns a tracing controller of a fake OpenSearch holding the given number of traces
+func newExportTestController(t *testing.T, fake *fakeExportOpenSearch, maxRows int) *TracingController {
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	client, err := opensearch.NewClient(&config.OpenSearchConfig{
+		Address:        server.URL,
+		Username:       "admin",
+		Password:       "admin",
+		RequestTimeout: 5 * time.Second,
+	}, nil)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	return NewTracingController(client, nil, TracingOptions{MaxExportRows: maxRows})
+}
+
+func exportTestParams() opensearch.TraceQueryParams {
+	return opensearch.TraceQueryParams{
+		ComponentUid:   "comp-1",
+		EnvironmentUid: "env-1",
+		StartTime:      "2026-10-16T00:00:00Z",
+		EndTime:        "2026-10-16T23:59:59Z",
+		SortBy:         opensearch.TraceSortStartTime,
+	}
+}
+
+func TestExportTracesPagesThroughAllTraces(t *testing.T) {
+	// More traces than fit on one page
+	fake := &fakeExportOpenSearch{start: time.Date(2026, 10, 16, 10, 0, 0, 0, time.UTC), traces: exportPageSize + 50}
+	controller := newExportTestController(t, fake, 1000)
+
+	var exported []string
+	result, err := controller.ExportTraces(context.Background(), exportTestParams(), func(trace opensearch.TraceOverview) error {
+		exported = append(exported, trace.TraceID)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ExportTraces() error = %v", err)
+	}
+	if result.Rows != fake.traces || result.Truncated || len(exported) != fake.traces {
+		t.Fatalf("result = %+v with %d traces, want all %d traces", result, len(exported), fake.traces)
+	}
+	if fake.rootPages != 2 {
+		t.Errorf("root pages = %d, want 2", fake.rootPages)
+	}
+	for i, traceID := range exported {
+		if want := fake.traceID(fake.traces - 1 - i); traceID != want {
+			t.Fatalf("trace %d = %s, want %s in start time order, newest first", i, traceID, want)
+		}
+	}
+}
+
+func TestExportTracesTruncatesAtMaxRows(t *testing.T) {
+	fake := &fakeExportOpenSearch{start: time.Date(2026, 10, 16, 10, 0, 0, 0, time.UTC), traces: 5}
+	controller := newExportTestController(t, fake, 3)
+
+	var exported []string
+	result, err := controller.ExportTraces(context.Background(), exportTestParams(), func(trace opensearch.TraceOverview) error {
+		exported = append(exported, trace.TraceID)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ExportTraces() error = %v", err)
+	}
+	if result.Rows != 3 || !result.Truncated || result.MaxRows != 3 {
+		t.Errorf("result = %+v, want 3 rows truncated", result)
+	}
+	if got := strings.Join(exported, ","); got != "trace-0004,trace-0003,trace-0002" {
+		t.Errorf("exported = %s, want the 3 newest traces", got)
+	}
+
+	// An export of exactly the maximum is not truncated
+	fake.traces = 3
+	result, err = controller.ExportTraces(context.Background(), exportTestParams(), func(opensearch.TraceOverview) error { return nil })
+	if err != nil || result.Rows != 3 || result.Truncated {
+		t.Errorf("result = %+v, %v, want 3 rows not truncated", result, err)
+	}
+}
+
+func TestExportTracesStopsOnVisitorError(t *testing.T) {
+	fake := &fakeExportOpenSearch{start: time.Date(2026, 10, 16, 10, 0, 0, 0, time.UTC), traces: 5}
+	controller := newExportTestController(t, fake, 100)
+
+	errClosed := errors.New("client went away")
+	result, err := controller.ExportTraces(context.Background(), exportTestParams(), func(trace opensearch.TraceOverview) error {
+		if trace.TraceID == "trace-0002" {
+			return errClosed
+		}
+		return nil
+	})
+	if !errors.Is(err, errClosed) || result.Rows != 2 {
+		t.Errorf("ExportTraces() = %+v, %v, want 2 rows and the visitor error", result, err)
+	}
+}
+
+func TestExportTracesWithoutIndices(t *testing.T) {
+	// The traces are on a day outside of the exported range
+	fake := &fakeExportOpenSearch{start: time.Date(2026, 10, 16, 10, 0, 0, 0, time.UTC), traces: 5}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/otel-traces-") {
+			fmt.Fprint(w, `{}`)
+			return
+		}
+		fake.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+	client, err := opensearch.NewClient(&config.OpenSearchConfig{Address: server.URL, RequestTimeout: 5 * time.Second}, nil)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	controller := NewTracingController(client, nil, TracingOptions{MaxExportRows: 100})
+
+	result, err := controller.ExportTraces(context.Background(), exportTestParams(), func(opensearch.TraceOverview) error {
+		t.Error("trace exported without an index")
+		return nil
+	})
+	if err != nil || result.Rows != 0 || fake.rootPages != 0 {
+		t.Errorf("ExportTraces() = %+v, %v after %d searches, want nothing searched", result, err, fake.rootPages)
+	}
+}
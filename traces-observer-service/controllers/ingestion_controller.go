@@ -0,0 +1,325 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+
+	commontracing "github.com/wso2/ai-agent-management-platform/libs/go-common/tracing"
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/forwarding"
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/metrics"
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/middleware/logger"
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/notifications"
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/opensearch"
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/otlp"
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/sampling"
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/tracing"
+)
+
+// SpanIndexer queues span documents for indexing, implemented by opensearch.BulkIndexer
+type SpanIndexer interface {
+	Add(ctx context.Context, doc opensearch.BulkDocument) error
+	Stats() opensearch.BulkIndexerStats
+}
+
+// ErrIngestionStopped is returned by exports arriving after the controller was shut down
+var ErrIngestionStopped = errors.New("span ingestion is shut down")
+
+// IngestionController converts exported spans and queues them for indexing
+type IngestionController struct {
+	indexer         SpanIndexer
+	sampler         *sampling.TailSampler   // Nil when tail sampling is disabled
+	notifier        *notifications.Notifier // Nil when notifications are disabled
+	forwarder       *forwarding.Forwarder   // Nil when no forwarding destination is configured
+	live            *LiveTraces             // Nil when the live trace stream is disabled
+	tails           *TraceTails             // Nil when live trace tails are disabled
+	pool            *ProcessingPool         // Nil to process spans on the calling goroutine
+	orgs            *OrgResolver            // Nil to index spans without an organization
+	labels          *AgentLabeler           // Nil when agent labels are not copied
+	rollups         *TraceRollups           // Nil when traces are not rolled up onto their root span
+	maxRequestBytes int
+	metrics         *metrics.Metrics
+
+	// Exports hold the read lock so that Shutdown waits for the exports in flight
+	shutdownMu sync.RWMutex
+	stopped    bool
+}
+
+// NewIngestionController creates a new ingestion controller
+func NewIngestionController(indexer SpanIndexer, sampler *sampling.TailSampler, notifier *notifications.Notifier, forwarder *forwarding.Forwarder, live *LiveTraces, tails *TraceTails, pool *ProcessingPool, orgs *OrgResolver, labels *AgentLabeler, rollups *TraceRollups, maxRequestBytes int, m *metrics.Metrics) *IngestionController {
+	return &IngestionController{
+		indexer:         indexer,
+		sampler:         sampler,
+		notifier:        notifier,
+		forwarder:       forwarder,
+		live:            live,
+		tails:           tails,
+		pool:            pool,
+		orgs:            orgs,
+		labels:          labels,
+		rollups:         rollups,
+		maxRequestBytes: maxRequestBytes,
+		metrics:         m,
+	}
+}
+
+// MaxRequestBytes returns the maximum decompressed size of an export request
+func (c *IngestionController) MaxRequestBytes() int {
+	return c.maxRequestBytes
+}
+
+// ExportResult reports the spans rejected from an export request
+type ExportResult struct {
+	RejectedSpans int64
+	ErrorMessage  string
+}
+
+// Export processes the spans of an OTLP export request and queues them for indexing
+// Spans failing validation are rejected individually, an error is returned only when queueing fails
+// ErrIngestionBusy is returned before any span is processed when the processing queue is full
+// ErrUnknownIngestKey is returned before any span is processed when the ingest key of the export is not configured
+func (c *IngestionController) Export(ctx context.Context, request *coltracepb.ExportTraceServiceRequest) (*ExportResult, error) {
+	return c.ExportWithAck(ctx, request, nil)
+}
+
+// ExportWithAck works like Export and registers the queued spans with ack so that callers can wait for them to be indexed
+func (c *IngestionController) ExportWithAck(ctx context.Context, request *coltracepb.ExportTraceServiceRequest, ack *Acknowledger) (*ExportResult, error) {
+	ctx, span := commontracing.Tracer().Start(ctx, "ingest spans")
+	defer span.End()
+
+	result, err := c.export(ctx, span, request, ack)
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+	}
+	return result, err
+}
+
+// export runs an export within the pipeline span, which is linked to the ingested traces
+func (c *IngestionController) export(ctx context.Context, pipelineSpan trace.Span, request *coltracepb.ExportTraceServiceRequest, ack *Acknowledger) (*ExportResult, error) {
+	log := logger.GetLogger(ctx)
+
+	c.shutdownMu.RLock()
+	defer c.shutdownMu.RUnlock()
+	if c.stopped {
+		return nil, ErrIngestionStopped
+	}
+
+	documents, rejected := otlp.ConvertResourceSpans(request.GetResourceSpans())
+	if c.orgs != nil {
+		if err := c.orgs.stamp(ctx, documents); err != nil {
+			return nil, err
+		}
+	}
+	if c.labels != nil {
+		c.labels.stamp(ctx, documents)
+	}
+
+	spans, err := c.process(documents)
+	if err != nil {
+		return nil, err
+	}
+	c.metrics.SpansReceived(len(documents) + len(rejected))
+	c.metrics.SpansDropped(metrics.DropReasonInvalid, len(rejected))
+
+	var links tracing.Links
+	for _, document := range documents {
+		links.Add(document.TraceID, document.SpanID)
+	}
+	for _, link := range links.Links() {
+		pipelineSpan.AddLink(link)
+	}
+	pipelineSpan.SetAttributes(
+		attribute.Int("spans.accepted", len(documents)),
+		attribute.Int("spans.rejected", len(rejected)),
+	)
+
+	// Processed spans are queued in the order they were received so that rollups see the spans of a trace in order
+	for i, document := range documents {
+		span := spans[i]
+		// Notifications see every trace, including traces dropped by the tail sampler
+		if c.notifier != nil {
+			c.notifier.Observe(span)
+		}
+		// Live clients see every trace as it arrives, before the tail sampler decides on it
+		c.live.Publish(span, document.Source)
+		c.tails.Publish(span)
+		// Forwarded spans are converted before queueing, the document is not touched afterwards
+		c.forwarder.Forward(document.Source, span)
+
+		// The index and ID depend only on the span, so a re-delivered span overwrites its earlier copy
+		bulkDocument := opensearch.BulkDocument{
+			Index: opensearch.TraceIndexName(document.StartTime),
+			ID:    opensearch.SpanDocumentID(document.TraceID, document.SpanID),
+			Body:  document.Source,
+		}
+		if ack != nil {
+			bulkDocument.OnDone = ack.add()
+		}
+		bulkDocument.OnDone = c.rollups.track(span, bulkDocument.OnDone)
+
+		if err := c.queue(ctx, span, bulkDocument); err != nil {
+			if bulkDocument.OnDone != nil {
+				bulkDocument.OnDone(err)
+			}
+			return nil, fmt.Errorf("failed to queue span %s: %w", document.SpanID, err)
+		}
+	}
+
+	result := &ExportResult{RejectedSpans: int64(len(rejected))}
+	if len(rejected) > 0 {
+		result.ErrorMessage = fmt.Sprintf("%d spans rejected, first error: %v", len(rejected), rejected[0])
+		log.WarnContext(ctx, "Rejected spans in export request", "rejected", len(rejected), "accepted", len(documents), "error", rejected[0])
+	}
+
+	log.DebugContext(ctx, "Queued exported spans", "accepted", len(documents))
+	return result, nil
+}
+
+// process runs the processing pipeline on the span documents, on the worker pool when one is configured
+func (c *IngestionController) process(documents []otlp.SpanDocument) ([]opensearch.Span, error) {
+	if c.pool != nil {
+		return c.pool.Process(documents)
+	}
+	spans := make([]opensearch.Span, len(documents))
+	for i, document := range documents {
+		spans[i] = processSpan(document.Source, c.metrics)
+	}
+	return spans, nil
+}
+
+// queue hands a processed span to the tail sampler, or directly to the indexer when sampling is disabled
+func (c *IngestionController) queue(ctx context.Context, span opensearch.Span, doc opensearch.BulkDocument) error {
+	if c.sampler != nil {
+		return c.sampler.Add(ctx, span, doc)
+	}
+	return c.indexer.Add(ctx, doc)
+}
+
+// Shutdown stops accepting spans, waits for the exports in flight and hands every processed and
+// buffered span to the indexer; the indexer itself is flushed by its owner afterwards
+// The receivers should be stopped first so that no export is rejected
+func (c *IngestionController) Shutdown(ctx context.Context) error {
+	c.shutdownMu.Lock()
+	alreadyStopped := c.stopped
+	c.stopped = true
+	c.shutdownMu.Unlock()
+	if alreadyStopped {
+		return nil
+	}
+
+	if c.pool != nil {
+		c.pool.Close()
+	}
+	// Decide buffered traces so that their spans reach the indexer
+	if c.sampler != nil {
+		if err := c.sampler.Close(ctx); err != nil {
+			return fmt.Errorf("failed to flush tail sampler: %w", err)
+		}
+	}
+	return nil
+}
+
+// SamplerStats returns the tail sampler counters, nil when sampling is disabled
+func (c *IngestionController) SamplerStats() *sampling.Stats {
+	if c.sampler == nil {
+		return nil
+	}
+	stats := c.sampler.Stats()
+	return &stats
+}
+
+// ForwardingStats returns the counters of the forwarding destinations, nil when forwarding is disabled
+func (c *IngestionController) ForwardingStats() []forwarding.DestinationStats {
+	return c.forwarder.Stats()
+}
+
+// ProcessingStats returns the counters of the span processing pool, nil when spans are processed inline
+func (c *IngestionController) ProcessingStats() *ProcessingStats {
+	if c.pool == nil {
+		return nil
+	}
+	stats := c.pool.Stats()
+	return &stats
+}
+
+// IndexerStats returns the document counters of the bulk indexer
+func (c *IngestionController) IndexerStats() opensearch.BulkIndexerStats {
+	return c.indexer.Stats()
+}
+
+// Acknowledger tracks the spans queued by one or more export requests until they are indexed
+type Acknowledger struct {
+	wg       sync.WaitGroup
+	mu       sync.Mutex
+	failed   int
+	firstErr error
+}
+
+// NewAcknowledger creates an empty acknowledger
+func NewAcknowledger() *Acknowledger {
+	return &Acknowledger{}
+}
+
+// add registers a pending span and returns its completion callback
+func (a *Acknowledger) add() func(err error) {
+	a.wg.Add(1)
+	var once sync.Once
+	return func(err error) {
+		once.Do(func() {
+			if err != nil {
+				a.mu.Lock()
+				a.failed++
+				if a.firstErr == nil {
+					a.firstErr = err
+				}
+				a.mu.Unlock()
+			}
+			a.wg.Done()
+		})
+	}
+}
+
+// Wait blocks until every registered span is indexed or dead-lettered
+func (a *Acknowledger) Wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		a.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Failed returns the number of dead-lettered spans and the first failure
+func (a *Acknowledger) Failed() (int, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.failed, a.firstErr
+}
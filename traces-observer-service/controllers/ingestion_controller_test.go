@@ -0,0 +1,193 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/opensearch"
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/sampling"
+)
+
+// memoryIndexer stores documents by index and ID like OpenSearch does for the index action
+type memoryIndexer struct {
+	mu   sync.Mutex
+	docs map[string]map[string]interface{}
+}
+
+func newMemoryIndexer() *memoryIndexer {
+	return &memoryIndexer{docs: make(map[string]map[string]interface{})}
+}
+
+func (m *memoryIndexer) Add(_ context.Context, doc opensearch.BulkDocument) error {
+	encoded, err := json.Marshal(doc.Body)
+	if err != nil {
+		return err
+	}
+	var source map[string]interface{}
+	if err := json.Unmarshal(encoded, &source); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	m.docs[doc.Index+"/"+doc.ID] = source
+	m.mu.Unlock()
+	if doc.OnDone != nil {
+		doc.OnDone(nil)
+	}
+	return nil
+}
+
+func (m *memoryIndexer) Stats() opensearch.BulkIndexerStats {
+	return opensearch.BulkIndexerStats{}
+}
+
+// counts returns the number of span and rollup documents and the total tokens of each
+func (m *memoryIndexer) counts() (spanDocs, spanTokens, rollupDocs, rollupTokens int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var sources []map[string]interface{}
+	for _, source := range m.docs {
+		if source["spanId"] == nil {
+			rollupDocs++
+			if usage, ok := source["tokenUsage"].(map[string]interface{}); ok {
+				total, _ := usage["totalTokens"].(float64)
+				rollupTokens += int(total)
+			}
+			continue
+		}
+		sources = append(sources, source)
+	}
+	spanDocs = len(sources)
+	if usage := opensearch.AggregateTraceTokenUsage(opensearch.ParseSpanSources(sources)); usage != nil {
+		spanTokens = usage.TotalTokens
+	}
+	return spanDocs, spanTokens, rollupDocs, rollupTokens
+}
+
+func stringAttribute(key, value string) *commonpb.KeyValue {
+	return &commonpb.KeyValue{Key: key, Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: value}}}
+}
+
+func intAttribute(key string, value int64) *commonpb.KeyValue {
+	return &commonpb.KeyValue{Key: key, Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_IntValue{IntValue: value}}}
+}
+
+// exportRequest builds an export request of an agent span with two LLM calls
+func exportRequest() *coltracepb.ExportTraceServiceRequest {
+	traceID := []byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16}
+	start := uint64(time.Date(2025, 11, 7, 6, 23, 24, 0, time.UTC).UnixNano())
+	llmSpan := func(id byte) *tracepb.Span {
+		return &tracepb.Span{
+			TraceId:           traceID,
+			SpanId:            []byte{0, 0, 0, 0, 0, 0, 0, id},
+			ParentSpanId:      []byte{0, 0, 0, 0, 0, 0, 0, 1},
+			Name:              "chat gpt-4o",
+			StartTimeUnixNano: start + uint64(id)*uint64(time.Millisecond),
+			EndTimeUnixNano:   start + uint64(id+1)*uint64(time.Millisecond),
+			Attributes: []*commonpb.KeyValue{
+				stringAttribute("gen_ai.operation.name", "chat"),
+				stringAttribute("gen_ai.request.model", "gpt-4o"),
+				intAttribute("gen_ai.usage.input_tokens", 100),
+				intAttribute("gen_ai.usage.output_tokens", 20),
+			},
+		}
+	}
+
+	return &coltracepb.ExportTraceServiceRequest{
+		ResourceSpans: []*tracepb.ResourceSpans{{
+			Resource: &resourcepb.Resource{Attributes: []*commonpb.KeyValue{stringAttribute("service.name", "agent")}},
+			ScopeSpans: []*tracepb.ScopeSpans{{
+				Spans: []*tracepb.Span{
+					{
+						TraceId:           traceID,
+						SpanId:            []byte{0, 0, 0, 0, 0, 0, 0, 1},
+						Name:              "agent",
+						StartTimeUnixNano: start,
+						EndTimeUnixNano:   start + uint64(time.Second),
+					},
+					llmSpan(2),
+					llmSpan(3),
+				},
+			}},
+		}},
+	}
+}
+
+func TestExportRedeliveryIsIdempotent(t *testing.T) {
+	tests := []struct {
+		name    string
+		sampler func(indexer *memoryIndexer) *sampling.TailSampler
+	}{
+		{
+			name:    "without sampling",
+			sampler: func(*memoryIndexer) *sampling.TailSampler { return nil },
+		},
+		{
+			// Every span overflows the buffer, so the trace is dropped on its first span and the
+			// remaining and re-delivered spans arrive as late spans rewriting the rollup
+			name: "dropped by the tail sampler",
+			sampler: func(indexer *memoryIndexer) *sampling.TailSampler {
+				return sampling.NewTailSampler(sampling.Config{DecisionWait: time.Hour, MaxBufferedBytes: 1}, indexer, nil, nil)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			indexer := newMemoryIndexer()
+			sampler := tt.sampler(indexer)
+			controller := NewIngestionController(indexer, sampler, nil, nil, nil, nil, nil, nil, nil, nil, 0, nil)
+
+			export := func() {
+				if _, err := controller.Export(context.Background(), exportRequest()); err != nil {
+					t.Fatalf("Export() error = %v", err)
+				}
+			}
+
+			export()
+			spanDocs, spanTokens, rollupDocs, rollupTokens := indexer.counts()
+			if spanDocs+rollupDocs == 0 || spanTokens+rollupTokens != 240 {
+				t.Fatalf("first export: spans = %d (%d tokens), rollups = %d (%d tokens), want 240 tokens",
+					spanDocs, spanTokens, rollupDocs, rollupTokens)
+			}
+
+			export()
+			gotSpanDocs, gotSpanTokens, gotRollupDocs, gotRollupTokens := indexer.counts()
+			if gotSpanDocs != spanDocs || gotSpanTokens != spanTokens || gotRollupDocs != rollupDocs || gotRollupTokens != rollupTokens {
+				t.Errorf("re-delivery changed counts: spans = %d (%d tokens), rollups = %d (%d tokens), want %d (%d), %d (%d)",
+					gotSpanDocs, gotSpanTokens, gotRollupDocs, gotRollupTokens, spanDocs, spanTokens, rollupDocs, rollupTokens)
+			}
+
+			if sampler != nil {
+				if err := sampler.Close(context.Background()); err != nil {
+					t.Fatalf("Close() error = %v", err)
+				}
+			}
+		})
+	}
+}
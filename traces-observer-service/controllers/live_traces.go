@@ -0,0 +1,613 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"hash/fnv"
+	"log/slog"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/opensearch"
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/pricing"
+)
+
+// Live trace event types
+const (
+	LiveTraceCreated   = "created"   // The root span of the trace was first seen
+	LiveTraceUpdated   = "updated"   // Spans arrived that changed the overview of the trace
+	LiveTraceFinalized = "finalized" // No more spans of the trace are expected
+)
+
+var (
+	// ErrTooManyLiveClients is returned by Subscribe when the live trace stream has reached its max clients
+	ErrTooManyLiveClients = errors.New("too many live trace clients")
+	// ErrLiveTracesClosed is returned by Subscribe once the live trace stream is closed
+	ErrLiveTracesClosed = errors.New("live trace stream closed")
+)
+
+// maxLiveSpansPerTrace bounds the spans followed per trace
+const maxLiveSpansPerTrace = 10000
+
+// liveSpanAttributes are the attributes kept on the spans of a followed trace other than its root,
+// enough for the rollups of the overview and the model filter
+var liveSpanAttributes = []string{
+	"gen_ai.request.model",
+	"gen_ai.response.model",
+	"gen_ai.usage.input_tokens",
+	"gen_ai.usage.output_tokens",
+	"gen_ai.usage.prompt_tokens",
+	"gen_ai.usage.completion_tokens",
+	"error.type",
+	"crewai.tool.error",
+}
+
+// liveAgentNameAttributes lists the root span attributes matched by the agent name filter
+var liveAgentNameAttributes = []string{
+	"gen_ai.agent.name",
+	"crewai.crew.name",
+	"crewai.agent.role",
+	"crewai.flow.name",
+}
+
+// LiveTracesConfig holds the live trace stream settings
+type LiveTracesConfig struct {
+	Finalization   opensearch.FinalizationPolicy // Traces are announced finalized once quiet, traces without a root span are forgotten at the max age
+	MaxTraces      int                           // Memory cap of the followed traces, the oldest traces are forgotten when exceeded
+	MaxClients     int                           // Concurrent clients, further clients are refused
+	ClientBuffer   int                           // Events buffered per client, a client falling further behind is disconnected
+	UpdateInterval time.Duration                 // Changes to a trace within the interval are sent as one event
+}
+
+// LiveTraceEvent is a change to a trace sent to the clients of the live trace stream
+type LiveTraceEvent struct {
+	ID   uint64
+	Type string
+	Data []byte // Overview of the trace as JSON
+}
+
+// LiveTraceStats holds the counters of the live trace stream
+type LiveTraceStats struct {
+	Clients         int    `json:"clients"`
+	Traces          int    `json:"traces"`
+	Events          uint64 `json:"events"`  // Events handed to clients
+	Evicted         uint64 `json:"evicted"` // Clients disconnected because their buffer was full
+	ForgottenTraces uint64 `json:"forgottenTraces"`
+}
+
+// LiveTraceFilter selects the traces streamed to a client, with the semantics of the trace list filters
+type LiveTraceFilter struct {
+	orgID     string
+	params    opensearch.TraceQueryParams
+	startTime time.Time
+	endTime   time.Time
+}
+
+// NewLiveTraceFilter creates the filter of a client of an organization from the trace list filters
+// Annotations are added once a trace is reviewed, so the annotation label filter is rejected
+func NewLiveTraceFilter(orgID string, params opensearch.TraceQueryParams) (LiveTraceFilter, error) {
+	filter := LiveTraceFilter{orgID: orgID, params: params}
+	if params.AnnotationLabel != "" {
+		return LiveTraceFilter{}, errors.New("annotationLabel is not supported by the live trace stream")
+	}
+	// As in the trace list, the time range only applies when both of its ends are given
+	if params.StartTime != "" && params.EndTime != "" {
+		var err error
+		if filter.startTime, err = time.Parse(time.RFC3339Nano, params.StartTime); err != nil {
+			return LiveTraceFilter{}, errors.New("startTime must be an RFC 3339 time")
+		}
+		if filter.endTime, err = time.Parse(time.RFC3339Nano, params.EndTime); err != nil {
+			return LiveTraceFilter{}, errors.New("endTime must be an RFC 3339 time")
+		}
+	}
+	return filter, nil
+}
+
+// matches reports whether a trace passes every filter
+func (f LiveTraceFilter) matches(trace *liveTraceView) bool {
+	root := trace.root
+	params := f.params
+
+	if f.orgID != "" {
+		// Spans without an organization belong to the default organization
+		orgID := root.OrgID
+		if orgID == "" {
+			orgID = opensearch.GetDefaultOrgID()
+		}
+		if orgID != f.orgID {
+			return false
+		}
+	}
+	if params.ComponentUid != "" && stringValue(root.Resource, "openchoreo.dev/component-uid") != params.ComponentUid {
+		return false
+	}
+	if params.EnvironmentUid != "" && stringValue(root.Resource, "openchoreo.dev/environment-uid") != params.EnvironmentUid {
+		return false
+	}
+	if !f.startTime.IsZero() && (root.StartTime.Before(f.startTime) || root.StartTime.After(f.endTime)) {
+		return false
+	}
+	if params.Framework != "" && !usesFramework(root, strings.ToLower(params.Framework)) {
+		return false
+	}
+	if params.AgentName != "" && !hasAgentName(root, params.AgentName) {
+		return false
+	}
+	if params.MinDurationNanos > 0 && root.DurationInNanos < params.MinDurationNanos {
+		return false
+	}
+	if params.Status != "" {
+		hasError := trace.overview.Status != nil && trace.overview.Status.HasError
+		if hasError != (params.Status == opensearch.TraceStatusError) {
+			return false
+		}
+	}
+	if params.HasGuardrailViolation != nil && (trace.overview.GuardrailViolations > 0) != *params.HasGuardrailViolation {
+		return false
+	}
+	if params.Model != "" && !usesModel(trace.spans, params.Model) {
+		return false
+	}
+	for key, value := range params.CustomAttributes {
+		if !trace.customAttributes[key][value] {
+			return false
+		}
+	}
+	if params.AgentID != "" && !trace.agentIDs[params.AgentID] {
+		return false
+	}
+	return true
+}
+
+// usesFramework reports whether the root span names the framework in gen_ai.system or carries attributes of it
+func usesFramework(root *opensearch.Span, framework string) bool {
+	if stringValue(root.Attributes, "gen_ai.system") == framework {
+		return true
+	}
+	for key := range root.Attributes {
+		if strings.HasPrefix(key, framework+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// hasAgentName reports whether any agent name attribute of the root span equals name
+func hasAgentName(root *opensearch.Span, name string) bool {
+	for _, key := range liveAgentNameAttributes {
+		if stringValue(root.Attributes, key) == name {
+			return true
+		}
+	}
+	return false
+}
+
+// usesModel reports whether any span requested or was answered by model
+func usesModel(spans []opensearch.Span, model string) bool {
+	for _, span := range spans {
+		if stringValue(span.Attributes, "gen_ai.request.model") == model || stringValue(span.Attributes, "gen_ai.response.model") == model {
+			return true
+		}
+	}
+	return false
+}
+
+// stringValue returns the string under key, empty when it is missing or not a string
+func stringValue(values map[string]interface{}, key string) string {
+	value, _ := values[key].(string)
+	return value
+}
+
+// LiveTraceSubscription is a client of the live trace stream
+type LiveTraceSubscription struct {
+	filter LiveTraceFilter
+	events chan LiveTraceEvent
+}
+
+// Events returns the events of the client, closed when the client is evicted or the stream is closed
+func (s *LiveTraceSubscription) Events() <-chan LiveTraceEvent {
+	return s.events
+}
+
+// liveTrace holds what is known of a trace being ingested
+type liveTrace struct {
+	firstSeen        time.Time
+	lastSeen         time.Time // Arrival of the latest span, the trace stays open until it is quiet
+	root             *opensearch.Span
+	spans            []opensearch.Span
+	spanIndex        map[string]int             // Position of each span in spans, re-delivered spans replace their earlier copy
+	agentIDs         map[string]bool            // Managed agents the spans are linked to
+	customAttributes map[string]map[string]bool // Values of each custom attribute carried by any span
+	announced        bool                       // Whether the created event was sent
+	changed          bool                       // Whether spans arrived since the last event
+	lastDigest       uint64                     // Digest of the last overview sent, an update leaving it unchanged is not sent
+}
+
+// liveTraceView is a copy of a trace taken for an event, read without the lock
+type liveTraceView struct {
+	traceID          string
+	eventType        string
+	root             *opensearch.Span
+	spans            []opensearch.Span
+	agentIDs         map[string]bool
+	customAttributes map[string]map[string]bool
+	lastDigest       uint64
+	overview         opensearch.TraceOverview
+}
+
+// LiveTraces follows the traces being ingested and streams changes to their overviews to subscribed clients
+// Events are handed to each client without blocking, a client whose buffer is full is disconnected
+// so that a stuck client never holds back ingestion or the other clients
+type LiveTraces struct {
+	cfg          LiveTracesConfig
+	pricingTable *pricing.Table
+
+	mu     sync.Mutex
+	traces map[string]*liveTrace
+	order  []string // Trace IDs in arrival order, used for eviction
+
+	// Recently finalized traces, late spans of which are ignored so that a trace is finalized once
+	finalized      map[string]bool
+	finalizedOrder []string
+
+	clientsMu sync.Mutex
+	clients   map[*LiveTraceSubscription]bool
+	closed    bool
+
+	nextID    atomic.Uint64
+	events    atomic.Uint64
+	evicted   atomic.Uint64
+	forgotten atomic.Uint64
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewLiveTraces creates the live trace stream, Start starts sending events
+func NewLiveTraces(cfg LiveTracesConfig, pricingTable *pricing.Table) *LiveTraces {
+	if cfg.Finalization.QuietPeriod <= 0 {
+		cfg.Finalization.QuietPeriod = 10 * time.Second
+	}
+	if cfg.MaxTraces <= 0 {
+		cfg.MaxTraces = 10000
+	}
+	if cfg.ClientBuffer <= 0 {
+		cfg.ClientBuffer = 256
+	}
+	if cfg.UpdateInterval <= 0 {
+		cfg.UpdateInterval = time.Second
+	}
+	return &LiveTraces{
+		cfg:          cfg,
+		pricingTable: pricingTable,
+		traces:       make(map[string]*liveTrace),
+		finalized:    make(map[string]bool),
+		clients:      make(map[*LiveTraceSubscription]bool),
+		stop:         make(chan struct{}),
+		done:         make(chan struct{}),
+	}
+}
+
+// Start starts sending the changes to the followed traces
+func (l *LiveTraces) Start() {
+	go l.run()
+}
+
+// Close stops sending events and ends the stream of every client
+func (l *LiveTraces) Close() {
+	close(l.stop)
+	<-l.done
+
+	l.clientsMu.Lock()
+	defer l.clientsMu.Unlock()
+	l.closed = true
+	for client := range l.clients {
+		delete(l.clients, client)
+		close(client.events)
+	}
+}
+
+// Subscribe adds a client receiving the events of the traces passing filter
+func (l *LiveTraces) Subscribe(filter LiveTraceFilter) (*LiveTraceSubscription, error) {
+	l.clientsMu.Lock()
+	defer l.clientsMu.Unlock()
+	if l.closed {
+		return nil, ErrLiveTracesClosed
+	}
+	if l.cfg.MaxClients > 0 && len(l.clients) >= l.cfg.MaxClients {
+		return nil, ErrTooManyLiveClients
+	}
+	client := &LiveTraceSubscription{filter: filter, events: make(chan LiveTraceEvent, l.cfg.ClientBuffer)}
+	l.clients[client] = true
+	return client, nil
+}
+
+// Unsubscribe removes a client, a client already evicted is left as it is
+func (l *LiveTraces) Unsubscribe(client *LiveTraceSubscription) {
+	l.clientsMu.Lock()
+	defer l.clientsMu.Unlock()
+	if l.clients[client] {
+		delete(l.clients, client)
+		close(client.events)
+	}
+}
+
+// Publish records a processed span of a trace, the change is sent with the next events
+// Does nothing on a nil stream, which is how the stream is disabled
+func (l *LiveTraces) Publish(span opensearch.Span, source map[string]interface{}) {
+	if l == nil {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.finalized[span.TraceID] {
+		return
+	}
+
+	now := time.Now()
+	trace, ok := l.traces[span.TraceID]
+	if !ok {
+		trace = &liveTrace{
+			firstSeen:        now,
+			spanIndex:        make(map[string]int),
+			agentIDs:         make(map[string]bool),
+			customAttributes: make(map[string]map[string]bool),
+		}
+		l.traces[span.TraceID] = trace
+		l.order = append(l.order, span.TraceID)
+	}
+	trace.lastSeen = now
+	trace.changed = true
+
+	// The root span keeps its attributes and input and output for the overview, other spans only their rollups
+	stored := liveSpan(span)
+	if span.ParentSpanID == "" && (trace.root == nil || trace.root.SpanID == span.SpanID) {
+		root := span
+		trace.root = &root
+		stored = span
+	}
+	if i, ok := trace.spanIndex[span.SpanID]; ok {
+		trace.spans[i] = stored
+	} else if len(trace.spans) < maxLiveSpansPerTrace {
+		trace.spanIndex[span.SpanID] = len(trace.spans)
+		trace.spans = append(trace.spans, stored)
+	}
+
+	if agentID, ok := source[opensearch.AgentIDField].(string); ok && agentID != "" {
+		trace.agentIDs[agentID] = true
+	}
+	if custom, ok := source[opensearch.CustomAttributesField].(map[string]string); ok {
+		for key, value := range custom {
+			if trace.customAttributes[key] == nil {
+				trace.customAttributes[key] = make(map[string]bool)
+			}
+			trace.customAttributes[key][value] = true
+		}
+	}
+
+	// Forget the oldest traces when the buffer exceeds its cap
+	for len(l.traces) > l.cfg.MaxTraces && len(l.order) > 0 {
+		oldest := l.order[0]
+		l.order = l.order[1:]
+		if _, ok := l.traces[oldest]; ok {
+			delete(l.traces, oldest)
+			l.forgotten.Add(1)
+		}
+	}
+}
+
+// liveSpan keeps the fields of a span needed for the overview of its trace and the filters
+func liveSpan(span opensearch.Span) opensearch.Span {
+	light := opensearch.LightweightSpan(span)
+	light.Status = span.Status
+	light.SessionID = span.SessionID
+	light.OrgID = span.OrgID
+	light.IngestedAt = span.IngestedAt
+	if span.AmpAttributes != nil {
+		if guardrailData, ok := span.AmpAttributes.Data.(opensearch.GuardrailData); ok {
+			light.AmpAttributes.Data = guardrailData
+		}
+	}
+	for _, key := range liveSpanAttributes {
+		if value, ok := span.Attributes[key]; ok {
+			if light.Attributes == nil {
+				light.Attributes = make(map[string]interface{})
+			}
+			light.Attributes[key] = value
+		}
+	}
+	return light
+}
+
+// Stats returns the live trace stream counters
+func (l *LiveTraces) Stats() LiveTraceStats {
+	l.mu.Lock()
+	traces := len(l.traces)
+	l.mu.Unlock()
+	l.clientsMu.Lock()
+	clients := len(l.clients)
+	l.clientsMu.Unlock()
+
+	return LiveTraceStats{
+		Clients:         clients,
+		Traces:          traces,
+		Events:          l.events.Load(),
+		Evicted:         l.evicted.Load(),
+		ForgottenTraces: l.forgotten.Load(),
+	}
+}
+
+// run sends the changes to the followed traces every update interval
+func (l *LiveTraces) run() {
+	defer close(l.done)
+
+	ticker := time.NewTicker(l.cfg.UpdateInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			l.sendChanges(time.Now())
+		case <-l.stop:
+			return
+		}
+	}
+}
+
+// sendChanges builds the events of the traces that changed or were finalized and hands them to the clients
+func (l *LiveTraces) sendChanges(now time.Time) {
+	for _, trace := range l.takeChanges(now, l.hasClients()) {
+		state := opensearch.TraceStateOpen
+		if trace.eventType == LiveTraceFinalized {
+			state = opensearch.TraceStateFinalized
+		}
+		trace.overview = newTraceOverview(trace.root, trace.spans, l.pricingTable, state)
+		data, err := json.Marshal(trace.overview)
+		if err != nil {
+			slog.Error("Failed to encode live trace overview", "traceId", trace.traceID, "error", err)
+			continue
+		}
+
+		digest := fnv.New64a()
+		_, _ = digest.Write(data)
+		if trace.eventType == LiveTraceUpdated && digest.Sum64() == trace.lastDigest {
+			continue
+		}
+		if trace.eventType != LiveTraceFinalized {
+			l.mu.Lock()
+			if followed, ok := l.traces[trace.traceID]; ok {
+				followed.lastDigest = digest.Sum64()
+			}
+			l.mu.Unlock()
+		}
+
+		l.broadcast(LiveTraceEvent{ID: l.nextID.Add(1), Type: trace.eventType, Data: data}, trace)
+	}
+}
+
+// hasClients reports whether any client is subscribed
+func (l *LiveTraces) hasClients() bool {
+	l.clientsMu.Lock()
+	defer l.clientsMu.Unlock()
+	return len(l.clients) > 0
+}
+
+// takeChanges returns copies of the traces with an event due, removes the finalized traces and forgets
+// traces whose root span did not arrive within the max age
+// Without clients the traces are only marked as sent, so that a new client is not flooded with the open traces
+func (l *LiveTraces) takeChanges(now time.Time, withClients bool) []*liveTraceView {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var changes []*liveTraceView
+	remaining := l.order[:0]
+	for _, traceID := range l.order {
+		trace, ok := l.traces[traceID]
+		if !ok {
+			continue
+		}
+
+		eventType := ""
+		switch {
+		case trace.root != nil && l.cfg.Finalization.Finalized(trace.firstSeen, trace.lastSeen, now):
+			delete(l.traces, traceID)
+			l.rememberFinalized(traceID)
+			eventType = LiveTraceFinalized
+		case trace.root == nil && l.cfg.Finalization.MaxAge > 0 && now.Sub(trace.firstSeen) >= l.cfg.Finalization.MaxAge:
+			delete(l.traces, traceID)
+			l.forgotten.Add(1)
+			continue
+		case trace.root != nil && !trace.announced:
+			eventType = LiveTraceCreated
+		case trace.root != nil && trace.changed:
+			eventType = LiveTraceUpdated
+		}
+		if eventType != LiveTraceFinalized {
+			remaining = append(remaining, traceID)
+		}
+		if eventType == "" {
+			continue
+		}
+
+		trace.announced = true
+		trace.changed = false
+		if withClients {
+			changes = append(changes, trace.view(traceID, eventType))
+		}
+	}
+	l.order = remaining
+	return changes
+}
+
+// view copies the trace for an event
+// Must be called with the lock held
+func (t *liveTrace) view(traceID, eventType string) *liveTraceView {
+	view := &liveTraceView{
+		traceID:          traceID,
+		eventType:        eventType,
+		root:             t.root,
+		spans:            append([]opensearch.Span(nil), t.spans...),
+		agentIDs:         make(map[string]bool, len(t.agentIDs)),
+		customAttributes: make(map[string]map[string]bool, len(t.customAttributes)),
+		lastDigest:       t.lastDigest,
+	}
+	for agentID := range t.agentIDs {
+		view.agentIDs[agentID] = true
+	}
+	for key, values := range t.customAttributes {
+		view.customAttributes[key] = make(map[string]bool, len(values))
+		for value := range values {
+			view.customAttributes[key][value] = true
+		}
+	}
+	return view
+}
+
+// rememberFinalized records a finalized trace, forgetting the oldest beyond the trace cap
+// Must be called with the lock held
+func (l *LiveTraces) rememberFinalized(traceID string) {
+	l.finalized[traceID] = true
+	l.finalizedOrder = append(l.finalizedOrder, traceID)
+	for len(l.finalizedOrder) > l.cfg.MaxTraces {
+		delete(l.finalized, l.finalizedOrder[0])
+		l.finalizedOrder = l.finalizedOrder[1:]
+	}
+}
+
+// broadcast hands an event to every client whose filter the trace passes, evicting clients whose buffer is full
+func (l *LiveTraces) broadcast(event LiveTraceEvent, trace *liveTraceView) {
+	l.clientsMu.Lock()
+	defer l.clientsMu.Unlock()
+	for client := range l.clients {
+		if !client.filter.matches(trace) {
+			continue
+		}
+		select {
+		case client.events <- event:
+			l.events.Add(1)
+		default:
+			delete(l.clients, client)
+			close(client.events)
+			l.evicted.Add(1)
+		}
+	}
+}
@@ -0,0 +1,304 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/opensearch"
+)
+
+const liveQuietPeriod = 10 * time.Second
+
+func newTestLiveTraces(clientBuffer int) *LiveTraces {
+	return NewLiveTraces(LiveTracesConfig{
+		Finalization: opensearch.FinalizationPolicy{QuietPeriod: liveQuietPeriod},
+		ClientBuffer: clientBuffer,
+	}, nil)
+}
+
+// liveSpanOf builds a span of trace-1 of an agent of component-1 in org-1
+func liveSpanOf(spanID, parentSpanID string) opensearch.Span {
+	start := time.Date(2025, 11, 7, 6, 23, 24, 0, time.UTC)
+	return opensearch.Span{
+		TraceID:         "trace-1",
+		SpanID:          spanID,
+		ParentSpanID:    parentSpanID,
+		Name:            "agent",
+		StartTime:       start,
+		EndTime:         start.Add(2 * time.Second),
+		DurationInNanos: (2 * time.Second).Nanoseconds(),
+		OrgID:           "org-1",
+		Resource: map[string]interface{}{
+			"openchoreo.dev/component-uid":   "component-1",
+			"openchoreo.dev/environment-uid": "environment-1",
+		},
+		Attributes: map[string]interface{}{
+			"gen_ai.system":     "crewai",
+			"gen_ai.agent.name": "planner",
+		},
+	}
+}
+
+// llmSpanOf builds a failed LLM call of trace-1
+func llmSpanOf(spanID string) opensearch.Span {
+	span := liveSpanOf(spanID, "root")
+	span.Attributes = map[string]interface{}{
+		"gen_ai.request.model":      "gpt-4o",
+		"gen_ai.usage.input_tokens": 100,
+		"gen_ai.prompt.0.content":   "not kept",
+	}
+	span.AmpAttributes = &opensearch.AmpAttributes{Kind: "llm", Status: &opensearch.SpanStatus{Error: true}}
+	return span
+}
+
+func subscribe(t *testing.T, live *LiveTraces, orgID string, params opensearch.TraceQueryParams) *LiveTraceSubscription {
+	t.Helper()
+	filter, err := NewLiveTraceFilter(orgID, params)
+	if err != nil {
+		t.Fatalf("NewLiveTraceFilter() error = %v", err)
+	}
+	client, err := live.Subscribe(filter)
+	if err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+	return client
+}
+
+// receive returns the events waiting for a client
+func receive(client *LiveTraceSubscription) []LiveTraceEvent {
+	var events []LiveTraceEvent
+	for {
+		select {
+		case event, ok := <-client.events:
+			if !ok {
+				return events
+			}
+			events = append(events, event)
+		default:
+			return events
+		}
+	}
+}
+
+func decodeOverview(t *testing.T, event LiveTraceEvent) opensearch.TraceOverview {
+	t.Helper()
+	var overview opensearch.TraceOverview
+	if err := json.Unmarshal(event.Data, &overview); err != nil {
+		t.Fatalf("event data is not an overview: %v", err)
+	}
+	return overview
+}
+
+func TestLiveTracesSendsTraceLifecycle(t *testing.T) {
+	live := newTestLiveTraces(16)
+	client := subscribe(t, live, "", opensearch.TraceQueryParams{})
+
+	// A trace is announced once its root span arrives
+	live.Publish(llmSpanOf("llm-1"), nil)
+	live.sendChanges(time.Now())
+	if events := receive(client); len(events) != 0 {
+		t.Fatalf("got %d events before the root span, want none", len(events))
+	}
+
+	live.Publish(liveSpanOf("root", ""), nil)
+	live.sendChanges(time.Now())
+	events := receive(client)
+	if len(events) != 1 || events[0].Type != LiveTraceCreated {
+		t.Fatalf("events after the root span = %+v, want one created event", events)
+	}
+	overview := decodeOverview(t, events[0])
+	if overview.TraceID != "trace-1" || overview.SpanCount != 2 || overview.State != opensearch.TraceStateOpen {
+		t.Errorf("created overview = %+v, want trace-1 open with 2 spans", overview)
+	}
+
+	// Nothing is sent while the trace is unchanged
+	live.sendChanges(time.Now())
+	if events := receive(client); len(events) != 0 {
+		t.Fatalf("got %d events of an unchanged trace, want none", len(events))
+	}
+
+	// A re-delivered span leaves the overview as it was
+	live.Publish(llmSpanOf("llm-1"), nil)
+	live.sendChanges(time.Now())
+	if events := receive(client); len(events) != 0 {
+		t.Fatalf("got %d events of a re-delivered span, want none", len(events))
+	}
+
+	live.Publish(llmSpanOf("llm-2"), nil)
+	live.sendChanges(time.Now())
+	events = receive(client)
+	if len(events) != 1 || events[0].Type != LiveTraceUpdated {
+		t.Fatalf("events after a new span = %+v, want one updated event", events)
+	}
+	if overview := decodeOverview(t, events[0]); overview.SpanCount != 3 || overview.Status == nil || overview.Status.ErrorCount != 2 {
+		t.Errorf("updated overview = %+v, want 3 spans with 2 errors", overview)
+	}
+
+	live.sendChanges(time.Now().Add(liveQuietPeriod))
+	events = receive(client)
+	if len(events) != 1 || events[0].Type != LiveTraceFinalized {
+		t.Fatalf("events after the quiet period = %+v, want one finalized event", events)
+	}
+	if overview := decodeOverview(t, events[0]); overview.State != opensearch.TraceStateFinalized {
+		t.Errorf("finalized overview state = %s, want %s", overview.State, opensearch.TraceStateFinalized)
+	}
+
+	// Late spans of a finalized trace are ignored
+	live.Publish(llmSpanOf("llm-3"), nil)
+	live.sendChanges(time.Now())
+	if events := receive(client); len(events) != 0 {
+		t.Fatalf("got %d events of a late span, want none", len(events))
+	}
+}
+
+func TestLiveTracesFiltersClients(t *testing.T) {
+	yes := true
+	no := false
+	tests := []struct {
+		name   string
+		orgID  string
+		params opensearch.TraceQueryParams
+		want   bool
+	}{
+		{name: "no filter", want: true},
+		{name: "organization", orgID: "org-1", want: true},
+		{name: "other organization", orgID: "org-2", want: false},
+		{name: "component and environment", params: opensearch.TraceQueryParams{ComponentUid: "component-1", EnvironmentUid: "environment-1"}, want: true},
+		{name: "other component", params: opensearch.TraceQueryParams{ComponentUid: "component-2"}, want: false},
+		{name: "time range", params: opensearch.TraceQueryParams{StartTime: "2025-11-07T00:00:00Z", EndTime: "2025-11-08T00:00:00Z"}, want: true},
+		{name: "time range before the trace", params: opensearch.TraceQueryParams{StartTime: "2025-11-06T00:00:00Z", EndTime: "2025-11-07T00:00:00Z"}, want: false},
+		{name: "framework", params: opensearch.TraceQueryParams{Framework: "CrewAI"}, want: true},
+		{name: "other framework", params: opensearch.TraceQueryParams{Framework: "langchain"}, want: false},
+		{name: "agent name", params: opensearch.TraceQueryParams{AgentName: "planner"}, want: true},
+		{name: "other agent name", params: opensearch.TraceQueryParams{AgentName: "writer"}, want: false},
+		{name: "error status", params: opensearch.TraceQueryParams{Status: opensearch.TraceStatusError}, want: true},
+		{name: "ok status", params: opensearch.TraceQueryParams{Status: opensearch.TraceStatusOK}, want: false},
+		{name: "min duration", params: opensearch.TraceQueryParams{MinDurationNanos: time.Second.Nanoseconds()}, want: true},
+		{name: "longer min duration", params: opensearch.TraceQueryParams{MinDurationNanos: time.Minute.Nanoseconds()}, want: false},
+		{name: "model of a child span", params: opensearch.TraceQueryParams{Model: "gpt-4o"}, want: true},
+		{name: "other model", params: opensearch.TraceQueryParams{Model: "claude"}, want: false},
+		{name: "without guardrail violation", params: opensearch.TraceQueryParams{HasGuardrailViolation: &no}, want: true},
+		{name: "with guardrail violation", params: opensearch.TraceQueryParams{HasGuardrailViolation: &yes}, want: false},
+		{name: "custom attribute of a child span", params: opensearch.TraceQueryParams{CustomAttributes: map[string]string{"tenant.id": "acme"}}, want: true},
+		{name: "other custom attribute value", params: opensearch.TraceQueryParams{CustomAttributes: map[string]string{"tenant.id": "globex"}}, want: false},
+		{name: "agent id of a child span", params: opensearch.TraceQueryParams{AgentID: "agent-1"}, want: true},
+		{name: "other agent id", params: opensearch.TraceQueryParams{AgentID: "agent-2"}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			live := newTestLiveTraces(16)
+			client := subscribe(t, live, tt.orgID, tt.params)
+
+			live.Publish(liveSpanOf("root", ""), map[string]interface{}{opensearch.AgentIDField: nil})
+			live.Publish(llmSpanOf("llm-1"), map[string]interface{}{
+				opensearch.AgentIDField:          "agent-1",
+				opensearch.CustomAttributesField: map[string]string{"tenant.id": "acme"},
+			})
+			live.sendChanges(time.Now())
+
+			if got := len(receive(client)) > 0; got != tt.want {
+				t.Errorf("client received the trace = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewLiveTraceFilterRejectsUnsupportedFilters(t *testing.T) {
+	tests := []struct {
+		name   string
+		params opensearch.TraceQueryParams
+	}{
+		{name: "annotation label", params: opensearch.TraceQueryParams{AnnotationLabel: "thumbs_down"}},
+		{name: "invalid start time", params: opensearch.TraceQueryParams{StartTime: "yesterday", EndTime: "2025-11-08T00:00:00Z"}},
+		{name: "invalid end time", params: opensearch.TraceQueryParams{StartTime: "2025-11-07T00:00:00Z", EndTime: "now"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewLiveTraceFilter("org-1", tt.params); err == nil {
+				t.Error("NewLiveTraceFilter() error = nil, want an error")
+			}
+		})
+	}
+}
+
+func TestLiveTracesEvictsSlowClients(t *testing.T) {
+	live := newTestLiveTraces(1)
+	slow := subscribe(t, live, "", opensearch.TraceQueryParams{})
+	fast := subscribe(t, live, "", opensearch.TraceQueryParams{})
+
+	live.Publish(liveSpanOf("root", ""), nil)
+	live.sendChanges(time.Now())
+	receive(fast)
+
+	// The slow client still holds the created event when the update is sent
+	live.Publish(llmSpanOf("llm-1"), nil)
+	live.sendChanges(time.Now())
+	if events := receive(fast); len(events) != 1 {
+		t.Fatalf("fast client received %d events, want 1", len(events))
+	}
+
+	events := receive(slow)
+	if len(events) != 1 || events[0].Type != LiveTraceCreated {
+		t.Fatalf("slow client events = %+v, want only the created event", events)
+	}
+	if _, ok := <-slow.Events(); ok {
+		t.Error("events of the evicted client are still open")
+	}
+	if stats := live.Stats(); stats.Clients != 1 || stats.Evicted != 1 {
+		t.Errorf("stats = %+v, want 1 client and 1 eviction", stats)
+	}
+
+	// Unsubscribing an evicted client does nothing
+	live.Unsubscribe(slow)
+	live.Unsubscribe(fast)
+	if stats := live.Stats(); stats.Clients != 0 {
+		t.Errorf("clients = %d after unsubscribing, want 0", stats.Clients)
+	}
+}
+
+func TestLiveTracesRefusesClientsBeyondTheLimit(t *testing.T) {
+	live := NewLiveTraces(LiveTracesConfig{MaxClients: 1}, nil)
+	subscribe(t, live, "", opensearch.TraceQueryParams{})
+	if _, err := live.Subscribe(LiveTraceFilter{}); err != ErrTooManyLiveClients {
+		t.Errorf("Subscribe() error = %v, want %v", err, ErrTooManyLiveClients)
+	}
+}
+
+func TestExportPublishesSpansToLiveTraces(t *testing.T) {
+	live := newTestLiveTraces(16)
+	client := subscribe(t, live, "", opensearch.TraceQueryParams{Model: "gpt-4o"})
+	controller := NewIngestionController(newMemoryIndexer(), nil, nil, nil, live, nil, nil, nil, nil, nil, 0, nil)
+
+	if _, err := controller.Export(context.Background(), exportRequest()); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+	live.sendChanges(time.Now())
+
+	events := receive(client)
+	if len(events) != 1 || events[0].Type != LiveTraceCreated {
+		t.Fatalf("events = %+v, want one created event", events)
+	}
+	overview := decodeOverview(t, events[0])
+	if overview.SpanCount != 3 || overview.TokenUsage == nil || overview.TokenUsage.InputTokens != 200 {
+		t.Errorf("overview = %+v, want 3 spans and 200 input tokens", overview)
+	}
+}
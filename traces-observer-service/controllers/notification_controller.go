@@ -0,0 +1,157 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package controllers
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/middleware/logger"
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/notifications"
+)
+
+// ErrRuleNotFound is returned when a notification rule is not found
+var ErrRuleNotFound = errors.New("notification rule not found")
+
+// NotificationController manages the notification rules
+type NotificationController struct {
+	store    *notifications.Store
+	notifier *notifications.Notifier
+}
+
+// NewNotificationController creates a new notification controller
+func NewNotificationController(store *notifications.Store, notifier *notifications.Notifier) *NotificationController {
+	return &NotificationController{
+		store:    store,
+		notifier: notifier,
+	}
+}
+
+// ListRules returns every notification rule without its secret
+func (c *NotificationController) ListRules(ctx context.Context) ([]notifications.Rule, error) {
+	rules, err := c.store.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range rules {
+		rules[i] = rules[i].Redacted()
+	}
+	return rules, nil
+}
+
+// GetRule returns a notification rule without its secret
+func (c *NotificationController) GetRule(ctx context.Context, id string) (*notifications.Rule, error) {
+	rule, found, err := c.store.Get(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get notification rule: %w", err)
+	}
+	if !found {
+		return nil, ErrRuleNotFound
+	}
+	redacted := rule.Redacted()
+	return &redacted, nil
+}
+
+// CreateRule stores a new notification rule
+// A signing secret is generated when none is given, the returned rule is the only response carrying it
+func (c *NotificationController) CreateRule(ctx context.Context, rule notifications.Rule) (*notifications.Rule, error) {
+	log := logger.GetLogger(ctx)
+
+	rule.Name = strings.TrimSpace(rule.Name)
+	if err := rule.Validate(); err != nil {
+		return nil, err
+	}
+	rule.ID = rand.Text()
+	if rule.Secret == "" {
+		rule.Secret = rand.Text()
+	}
+	rule.CreatedAt = time.Now().UTC()
+	rule.UpdatedAt = rule.CreatedAt
+
+	if err := c.store.Put(ctx, &rule); err != nil {
+		return nil, fmt.Errorf("failed to store notification rule: %w", err)
+	}
+	c.reload(ctx)
+
+	log.Info("Created notification rule", "ruleId", rule.ID, "name", rule.Name)
+	return &rule, nil
+}
+
+// UpdateRule replaces the conditions and target of a notification rule
+// The secret is kept when the update does not carry one
+func (c *NotificationController) UpdateRule(ctx context.Context, id string, rule notifications.Rule) (*notifications.Rule, error) {
+	log := logger.GetLogger(ctx)
+
+	existing, found, err := c.store.Get(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get notification rule: %w", err)
+	}
+	if !found {
+		return nil, ErrRuleNotFound
+	}
+
+	rule.Name = strings.TrimSpace(rule.Name)
+	if err := rule.Validate(); err != nil {
+		return nil, err
+	}
+	rule.ID = existing.ID
+	rule.CreatedAt = existing.CreatedAt
+	rule.UpdatedAt = time.Now().UTC()
+	if rule.Secret == "" {
+		rule.Secret = existing.Secret
+	}
+
+	if err := c.store.Put(ctx, &rule); err != nil {
+		return nil, fmt.Errorf("failed to store notification rule: %w", err)
+	}
+	c.reload(ctx)
+
+	log.Info("Updated notification rule", "ruleId", rule.ID, "name", rule.Name)
+	redacted := rule.Redacted()
+	return &redacted, nil
+}
+
+// DeleteRule deletes a notification rule
+func (c *NotificationController) DeleteRule(ctx context.Context, id string) error {
+	deleted, err := c.store.Delete(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete notification rule: %w", err)
+	}
+	if !deleted {
+		return ErrRuleNotFound
+	}
+	c.reload(ctx)
+
+	logger.GetLogger(ctx).Info("Deleted notification rule", "ruleId", id)
+	return nil
+}
+
+// Stats returns the notifier counters
+func (c *NotificationController) Stats() notifications.Stats {
+	return c.notifier.Stats()
+}
+
+// reload applies rule changes on this replica right away, other replicas pick them up on their next refresh
+func (c *NotificationController) reload(ctx context.Context) {
+	if err := c.notifier.ReloadRules(ctx); err != nil {
+		logger.GetLogger(ctx).Warn("Failed to reload notification rules", "error", err)
+	}
+}
@@ -0,0 +1,151 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package controllers
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/metrics"
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/opensearch"
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/otlp"
+)
+
+// ErrIngestionBusy is returned when the spans of an export request do not fit into the processing queue
+// Receivers report it as 429 / RESOURCE_EXHAUSTED so that exporters retry, the Kafka consumer pauses
+var ErrIngestionBusy = errors.New("span processing queue is full")
+
+// ProcessingStats holds the counters of the span processing pool
+type ProcessingStats struct {
+	Workers          int    `json:"workers"`
+	QueuedSpans      int64  `json:"queuedSpans"`
+	MaxQueuedSpans   int    `json:"maxQueuedSpans"`
+	RejectedRequests uint64 `json:"rejectedRequests"` // Export requests rejected because the queue was full
+}
+
+// processingJob is a span document processed by a worker
+type processingJob struct {
+	source map[string]interface{}
+	result *opensearch.Span
+	done   *sync.WaitGroup
+}
+
+// ProcessingPool processes span documents on a bounded pool of workers
+// The spans of an export request are fanned out to the workers and handed back in their original order
+type ProcessingPool struct {
+	jobs           chan processingJob
+	workers        int
+	maxQueuedSpans int
+	queued         atomic.Int64
+	rejected       atomic.Uint64
+	metrics        *metrics.Metrics
+	wg             sync.WaitGroup
+	closeOnce      sync.Once
+}
+
+// NewProcessingPool starts a pool of workers processing at most maxQueuedSpans spans at a time
+func NewProcessingPool(workers, maxQueuedSpans int, m *metrics.Metrics) *ProcessingPool {
+	if workers <= 0 {
+		workers = 1
+	}
+	if maxQueuedSpans <= 0 {
+		maxQueuedSpans = workers
+	}
+
+	pool := &ProcessingPool{
+		jobs:           make(chan processingJob, maxQueuedSpans),
+		workers:        workers,
+		maxQueuedSpans: maxQueuedSpans,
+		metrics:        m,
+	}
+	for i := 0; i < workers; i++ {
+		pool.wg.Add(1)
+		go pool.run()
+	}
+	return pool
+}
+
+// run processes queued span documents until the pool is closed
+func (p *ProcessingPool) run() {
+	defer p.wg.Done()
+	for job := range p.jobs {
+		*job.result = processSpan(job.source, p.metrics)
+		job.done.Done()
+	}
+}
+
+// Process processes the span documents and returns the spans in the order of the documents
+// Returns ErrIngestionBusy without processing anything when the documents do not fit into the queue;
+// a request larger than the whole queue is admitted while the queue is empty so that it cannot starve
+func (p *ProcessingPool) Process(documents []otlp.SpanDocument) ([]opensearch.Span, error) {
+	if !p.reserve(len(documents)) {
+		p.rejected.Add(1)
+		return nil, ErrIngestionBusy
+	}
+	defer p.queued.Add(-int64(len(documents)))
+
+	spans := make([]opensearch.Span, len(documents))
+	var done sync.WaitGroup
+	done.Add(len(documents))
+	for i := range documents {
+		p.jobs <- processingJob{source: documents[i].Source, result: &spans[i], done: &done}
+	}
+	done.Wait()
+	return spans, nil
+}
+
+// reserve claims queue capacity for n spans
+func (p *ProcessingPool) reserve(n int) bool {
+	for {
+		queued := p.queued.Load()
+		if queued > 0 && queued+int64(n) > int64(p.maxQueuedSpans) {
+			return false
+		}
+		if p.queued.CompareAndSwap(queued, queued+int64(n)) {
+			return true
+		}
+	}
+}
+
+// Stats returns the counters of the pool
+func (p *ProcessingPool) Stats() ProcessingStats {
+	return ProcessingStats{
+		Workers:          p.workers,
+		QueuedSpans:      p.queued.Load(),
+		MaxQueuedSpans:   p.maxQueuedSpans,
+		RejectedRequests: p.rejected.Load(),
+	}
+}
+
+// Close stops the workers once the queued spans are processed
+// Process must not be called after Close
+func (p *ProcessingPool) Close() {
+	p.closeOnce.Do(func() {
+		close(p.jobs)
+	})
+	p.wg.Wait()
+}
+
+// processSpan runs the processing pipeline on a span document and records its processing time
+func processSpan(source map[string]interface{}, m *metrics.Metrics) opensearch.Span {
+	start := time.Now()
+	span := opensearch.ProcessDocument(source)
+	m.SpanProcessed(opensearch.SpanFramework(span), time.Since(start))
+	return span
+}
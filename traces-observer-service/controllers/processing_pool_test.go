@@ -0,0 +1,174 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"runtime"
+	"sync"
+	"testing"
+
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/opensearch"
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/otlp"
+)
+
+// recordingIndexer records the IDs of the queued documents in order
+type recordingIndexer struct {
+	mu  sync.Mutex
+	ids []string
+}
+
+func (r *recordingIndexer) Add(_ context.Context, doc opensearch.BulkDocument) error {
+	r.mu.Lock()
+	r.ids = append(r.ids, doc.ID)
+	r.mu.Unlock()
+	if doc.OnDone != nil {
+		doc.OnDone(nil)
+	}
+	return nil
+}
+
+func (r *recordingIndexer) Stats() opensearch.BulkIndexerStats {
+	return opensearch.BulkIndexerStats{}
+}
+
+// loadCorpus reads the export request of a CrewAI research crew run from testdata
+func loadCorpus(tb testing.TB) *coltracepb.ExportTraceServiceRequest {
+	payload, err := os.ReadFile("testdata/agent_trace.json")
+	if err != nil {
+		tb.Fatalf("failed to read corpus: %v", err)
+	}
+	request, err := otlp.DecodeRequest(payload, otlp.ContentTypeJSON)
+	if err != nil {
+		tb.Fatalf("failed to decode corpus: %v", err)
+	}
+	return request
+}
+
+// replicateCorpus builds an export request holding the corpus trace the given number of times under distinct trace IDs
+func replicateCorpus(corpus *coltracepb.ExportTraceServiceRequest, traces int) *coltracepb.ExportTraceServiceRequest {
+	request := &coltracepb.ExportTraceServiceRequest{}
+	for i := 0; i < traces; i++ {
+		for _, resourceSpans := range corpus.GetResourceSpans() {
+			copied := proto.Clone(resourceSpans).(*tracepb.ResourceSpans)
+			for _, scopeSpans := range copied.GetScopeSpans() {
+				for _, span := range scopeSpans.GetSpans() {
+					span.TraceId[0], span.TraceId[1] = byte(i>>8), byte(i)
+				}
+			}
+			request.ResourceSpans = append(request.ResourceSpans, copied)
+		}
+	}
+	return request
+}
+
+func TestProcessingPoolPreservesOrder(t *testing.T) {
+	request := replicateCorpus(loadCorpus(t), 4)
+	documents, _ := otlp.ConvertResourceSpans(request.GetResourceSpans())
+
+	indexer := &recordingIndexer{}
+	pool := NewProcessingPool(4, 1000, nil)
+	defer pool.Close()
+	controller := NewIngestionController(indexer, nil, nil, nil, nil, nil, pool, nil, nil, nil, 0, nil)
+
+	if _, err := controller.Export(context.Background(), request); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+	if len(indexer.ids) != len(documents) {
+		t.Fatalf("queued %d documents, want %d", len(indexer.ids), len(documents))
+	}
+	for i, document := range documents {
+		if want := opensearch.SpanDocumentID(document.TraceID, document.SpanID); indexer.ids[i] != want {
+			t.Fatalf("document %d = %s, want %s", i, indexer.ids[i], want)
+		}
+	}
+}
+
+func TestProcessingPoolRejectsWhenFull(t *testing.T) {
+	request := loadCorpus(t)
+	documents, _ := otlp.ConvertResourceSpans(request.GetResourceSpans())
+	pool := NewProcessingPool(1, len(documents), nil)
+	defer pool.Close()
+
+	// Claim the queue as if another request was being processed
+	if !pool.reserve(1) {
+		t.Fatal("reserve() = false on an empty queue")
+	}
+	if _, err := pool.Process(documents); !errors.Is(err, ErrIngestionBusy) {
+		t.Fatalf("Process() error = %v, want ErrIngestionBusy", err)
+	}
+	pool.queued.Add(-1)
+
+	// A request larger than the queue is admitted while the queue is empty
+	spans, err := pool.Process(append(documents, documents...))
+	if err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+	if len(spans) != 2*len(documents) {
+		t.Errorf("Process() returned %d spans, want %d", len(spans), 2*len(documents))
+	}
+	if stats := pool.Stats(); stats.RejectedRequests != 1 || stats.QueuedSpans != 0 {
+		t.Errorf("Stats() = %+v, want 1 rejected request and no queued spans", stats)
+	}
+}
+
+// BenchmarkExport measures the throughput of processing and queueing the spans of agent traces
+// with inline processing and with worker pools of increasing size
+// The pool only pays off with GOMAXPROCS above one, e.g. go test -bench Export -cpu 1,4
+func BenchmarkExport(b *testing.B) {
+	request := replicateCorpus(loadCorpus(b), 8)
+	spanCount := 0
+	for _, resourceSpans := range request.GetResourceSpans() {
+		for _, scopeSpans := range resourceSpans.GetScopeSpans() {
+			spanCount += len(scopeSpans.GetSpans())
+		}
+	}
+
+	workerCounts := []int{0, 1, 4}
+	if cpus := runtime.NumCPU(); cpus > 4 {
+		workerCounts = append(workerCounts, cpus)
+	}
+	for _, workers := range workerCounts {
+		name := "inline"
+		if workers > 0 {
+			name = fmt.Sprintf("workers=%d", workers)
+		}
+		b.Run(name, func(b *testing.B) {
+			var pool *ProcessingPool
+			if workers > 0 {
+				pool = NewProcessingPool(workers, spanCount, nil)
+				defer pool.Close()
+			}
+			controller := NewIngestionController(&recordingIndexer{}, nil, nil, nil, nil, nil, pool, nil, nil, nil, 0, nil)
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := controller.Export(context.Background(), request); err != nil {
+					b.Fatalf("Export() error = %v", err)
+				}
+			}
+			b.ReportMetric(float64(spanCount*b.N)/b.Elapsed().Seconds(), "spans/s")
+		})
+	}
+}
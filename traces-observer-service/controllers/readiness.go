@@ -0,0 +1,129 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/opensearch"
+)
+
+// ReadinessClient is the part of the OpenSearch client the readiness check depends on
+type ReadinessClient interface {
+	HealthCheck(ctx context.Context) error
+	BreakerState() string
+}
+
+// errNotVerified is reported until the OpenSearch connection is verified and the setup has completed
+var errNotVerified = errors.New("OpenSearch connection not verified yet")
+
+// errShuttingDown is reported once the service is shutting down
+var errShuttingDown = errors.New("shutting down")
+
+// Readiness tracks whether the service can accept traffic
+// The service becomes ready once OpenSearch answers and the setup (index templates, indices) has completed,
+// and is not ready while the OpenSearch circuit breaker is open or after shutdown has begun
+type Readiness struct {
+	client        ReadinessClient
+	setup         func(ctx context.Context) error // Nil when there is nothing to set up
+	retryInterval time.Duration
+
+	verified     atomic.Bool
+	shuttingDown atomic.Bool
+	mu           sync.Mutex
+	lastErr      error
+}
+
+// NewReadiness creates a readiness check running setup once the OpenSearch connection is verified
+func NewReadiness(client ReadinessClient, setup func(ctx context.Context) error, retryInterval time.Duration) *Readiness {
+	return &Readiness{
+		client:        client,
+		setup:         setup,
+		retryInterval: retryInterval,
+		lastErr:       errNotVerified,
+	}
+}
+
+// Start verifies the OpenSearch connection and runs the setup in the background, retrying until both succeed
+func (r *Readiness) Start(ctx context.Context) {
+	go func() {
+		for {
+			err := r.verify(ctx)
+			if err == nil {
+				slog.Info("Service is ready")
+				return
+			}
+			r.setLastErr(err)
+			slog.Warn("Service not ready, retrying", "error", err, "retryIn", r.retryInterval)
+
+			select {
+			case <-time.After(r.retryInterval):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// verify checks the OpenSearch connection and runs the setup
+func (r *Readiness) verify(ctx context.Context) error {
+	if err := r.client.HealthCheck(ctx); err != nil {
+		return fmt.Errorf("OpenSearch is not reachable: %w", err)
+	}
+	if r.setup != nil {
+		if err := r.setup(ctx); err != nil {
+			return err
+		}
+	}
+	r.verified.Store(true)
+	r.setLastErr(nil)
+	return nil
+}
+
+// setLastErr records the error of the last verification attempt
+func (r *Readiness) setLastErr(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.lastErr = err
+}
+
+// Check returns nil when the service is ready, otherwise the reason it is not
+func (r *Readiness) Check() error {
+	if r.shuttingDown.Load() {
+		return errShuttingDown
+	}
+	if !r.verified.Load() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		return r.lastErr
+	}
+	if r.client.BreakerState() == opensearch.BreakerOpen {
+		return errors.New("OpenSearch circuit breaker is open")
+	}
+	return nil
+}
+
+// ShuttingDown marks the service as not ready so that no new traffic is routed to it
+func (r *Readiness) ShuttingDown() {
+	r.shuttingDown.Store(true)
+}
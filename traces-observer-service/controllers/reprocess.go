@@ -0,0 +1,325 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package controllers
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/opensearch"
+)
+
+// ErrReprocessRunning is returned when a reprocessing job is started while another one runs
+var ErrReprocessRunning = errors.New("a reprocessing job is already running")
+
+// ErrReprocessJobNotFound is returned when a reprocessing job is not found
+var ErrReprocessJobNotFound = errors.New("reprocessing job not found")
+
+// Reprocessing job states
+const (
+	ReprocessRunning   = "running"
+	ReprocessCompleted = "completed"
+	ReprocessFailed    = "failed"
+	ReprocessCancelled = "cancelled"
+)
+
+// reprocessPageSize is the number of spans read and updated at once
+const reprocessPageSize = 500
+
+// reprocessKeepAlive is how long the point in time of a job is kept between pages
+const reprocessKeepAlive = "5m"
+
+// maxReprocessJobs is the number of finished jobs kept for reporting
+const maxReprocessJobs = 20
+
+// ReprocessJob reports the progress of a reprocessing job
+type ReprocessJob struct {
+	ID         string     `json:"id"`
+	State      string     `json:"state"`
+	StartTime  time.Time  `json:"startTime"` // Time range of the spans reprocessed
+	EndTime    time.Time  `json:"endTime"`
+	StartedAt  time.Time  `json:"startedAt"`
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+	Total      int        `json:"total"`     // Spans in the time range when the job started
+	Scanned    int        `json:"scanned"`   // Spans read so far
+	Updated    int        `json:"updated"`   // Spans whose derived fields were rewritten
+	Unchanged  int        `json:"unchanged"` // Spans whose derived fields were current
+	Conflicts  int        `json:"conflicts"` // Spans written by ingestion since they were read, left as ingestion wrote them
+	Failed     int        `json:"failed"`
+	Error      string     `json:"error,omitempty"`
+}
+
+type reprocessJob struct {
+	ReprocessJob
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// ReprocessController derives the fields of already indexed spans again, e.g. once a framework processor is added
+// One job runs at a time in the background. Only the derived fields are rewritten, each with the sequence number
+// the span was read with, so spans re-delivered meanwhile keep what live ingestion wrote.
+type ReprocessController struct {
+	client   *opensearch.Client
+	rollups  *TraceRollups // Nil when the rollups of reprocessed traces are not rewritten
+	pageSize int
+
+	mu   sync.Mutex
+	jobs []*reprocessJob // Oldest first
+}
+
+// NewReprocessController creates a new reprocessing controller
+// The traces of reprocessed root spans are rolled up again, which also backfills root spans indexed without a rollup
+func NewReprocessController(client *opensearch.Client, rollups *TraceRollups) *ReprocessController {
+	return &ReprocessController{client: client, rollups: rollups, pageSize: reprocessPageSize}
+}
+
+// Start starts reprocessing the spans started within a time range
+func (c *ReprocessController) Start(start, end time.Time) (*ReprocessJob, error) {
+	if start.IsZero() || end.IsZero() || start.After(end) {
+		return nil, fmt.Errorf("start time must be before end time")
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, job := range c.jobs {
+		if job.State == ReprocessRunning {
+			return nil, ErrReprocessRunning
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	job := &reprocessJob{
+		ReprocessJob: ReprocessJob{
+			ID:        strings.ToLower(rand.Text()),
+			State:     ReprocessRunning,
+			StartTime: start.UTC(),
+			EndTime:   end.UTC(),
+			StartedAt: time.Now().UTC(),
+		},
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+	c.jobs = append(c.jobs, job)
+	if len(c.jobs) > maxReprocessJobs {
+		c.jobs = c.jobs[len(c.jobs)-maxReprocessJobs:]
+	}
+
+	go c.run(ctx, job)
+	snapshot := job.ReprocessJob
+	return &snapshot, nil
+}
+
+// Get returns the progress of a job
+func (c *ReprocessController) Get(id string) (*ReprocessJob, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	job := c.find(id)
+	if job == nil {
+		return nil, ErrReprocessJobNotFound
+	}
+	snapshot := job.ReprocessJob
+	return &snapshot, nil
+}
+
+// List returns the progress of the recent jobs, newest first
+func (c *ReprocessController) List() []ReprocessJob {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	jobs := make([]ReprocessJob, 0, len(c.jobs))
+	for i := len(c.jobs) - 1; i >= 0; i-- {
+		jobs = append(jobs, c.jobs[i].ReprocessJob)
+	}
+	return jobs
+}
+
+// Cancel stops a running job after the page being updated and waits for it
+// The spans updated so far keep their new derived fields
+func (c *ReprocessController) Cancel(id string) (*ReprocessJob, error) {
+	c.mu.Lock()
+	job := c.find(id)
+	c.mu.Unlock()
+	if job == nil {
+		return nil, ErrReprocessJobNotFound
+	}
+	job.cancel()
+	<-job.done
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	snapshot := job.ReprocessJob
+	return &snapshot, nil
+}
+
+// Close cancels the running job and waits for it
+func (c *ReprocessController) Close() {
+	c.mu.Lock()
+	jobs := append([]*reprocessJob(nil), c.jobs...)
+	c.mu.Unlock()
+	for _, job := range jobs {
+		job.cancel()
+		<-job.done
+	}
+}
+
+// find returns a job by ID, nil when unknown, the caller holds mu
+func (c *ReprocessController) find(id string) *reprocessJob {
+	for _, job := range c.jobs {
+		if job.ID == id {
+			return job
+		}
+	}
+	return nil
+}
+
+// update applies a change to the progress of a job
+func (c *ReprocessController) update(job *reprocessJob, change func(*ReprocessJob)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	change(&job.ReprocessJob)
+}
+
+// run pages through the spans of the time range and rewrites the derived fields that changed
+func (c *ReprocessController) run(ctx context.Context, job *reprocessJob) {
+	defer close(job.done)
+	defer job.cancel()
+
+	log := slog.With("jobId", job.ID)
+	log.Info("Reprocessing spans", "startTime", job.StartTime, "endTime", job.EndTime)
+
+	err := c.reprocess(ctx, job)
+	var final ReprocessJob
+	c.update(job, func(progress *ReprocessJob) {
+		finishedAt := time.Now().UTC()
+		progress.FinishedAt = &finishedAt
+		switch {
+		case err == nil:
+			progress.State = ReprocessCompleted
+		case ctx.Err() != nil:
+			// Requests aborted by the cancellation fail with errors of their own
+			progress.State = ReprocessCancelled
+		default:
+			progress.State = ReprocessFailed
+			progress.Error = err.Error()
+		}
+		final = *progress
+	})
+
+	if final.State == ReprocessFailed {
+		log.Error("Reprocessing failed", "error", err, "scanned", final.Scanned, "updated", final.Updated)
+		return
+	}
+	log.Info("Reprocessing finished", "state", final.State, "scanned", final.Scanned, "updated", final.Updated,
+		"unchanged", final.Unchanged, "conflicts", final.Conflicts, "failed", final.Failed)
+}
+
+// reprocess updates the spans page by page until the last page or until ctx is cancelled
+func (c *ReprocessController) reprocess(ctx context.Context, job *reprocessJob) error {
+	indices, err := opensearch.GetIndicesForTimeRange(job.StartTime.Format(time.RFC3339), job.EndTime.Format(time.RFC3339))
+	if err != nil {
+		return fmt.Errorf("failed to generate indices: %w", err)
+	}
+	existing, err := c.client.GetIndices(ctx, strings.Join(indices, ","))
+	if err != nil {
+		return fmt.Errorf("failed to list trace indices: %w", err)
+	}
+	if len(existing) == 0 {
+		return nil
+	}
+
+	query := opensearch.BuildReprocessQuery(job.StartTime, job.EndTime)
+	var cursor *opensearch.TraceCursor
+	// Closes the point in time of a job that stops early
+	defer func() {
+		c.client.ReleaseCursor(ctx, cursor)
+	}()
+	for first := true; first || cursor != nil; first = false {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		response, next, err := c.client.SearchPage(ctx, opensearch.PageRequest{
+			Indices:   existing,
+			Query:     query,
+			Size:      c.pageSize,
+			Cursor:    cursor,
+			KeepAlive: reprocessKeepAlive,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to search spans: %w", err)
+		}
+		cursor = next
+
+		var updates []opensearch.DerivedFieldUpdate
+		unchanged, failed := 0, 0
+		for _, hit := range response.Hits.Hits {
+			if hit.PrimaryTerm == 0 {
+				// Without its sequence number the span cannot be updated without overwriting ingestion
+				failed++
+				continue
+			}
+			update, changed := opensearch.ReprocessUpdate(hit.Index, hit.ID, hit.SeqNo, hit.PrimaryTerm, hit.Source)
+			if !changed {
+				unchanged++
+				continue
+			}
+			updates = append(updates, update)
+		}
+
+		result, err := c.client.UpdateDerivedFields(ctx, updates)
+		c.update(job, func(progress *ReprocessJob) {
+			if first {
+				progress.Total = response.Hits.Total.Value
+			}
+			progress.Scanned += len(response.Hits.Hits)
+			progress.Unchanged += unchanged
+			progress.Updated += result.Updated
+			progress.Conflicts += result.Conflicts
+			progress.Failed += failed + result.Failed
+			if progress.Error == "" {
+				progress.Error = result.Error
+			}
+		})
+		if err != nil {
+			return fmt.Errorf("failed to update spans: %w", err)
+		}
+		// Rolled up once the page is updated, so that the rollups are written with the new sequence numbers
+		for _, hit := range response.Hits.Hits {
+			c.observeRoot(hit.Source)
+		}
+	}
+	return nil
+}
+
+// observeRoot hands the trace of a reprocessed root span to the rollups
+func (c *ReprocessController) observeRoot(source map[string]interface{}) {
+	if parentSpanID, _ := source["parentSpanId"].(string); parentSpanID != "" {
+		return
+	}
+	traceID, _ := source["traceId"].(string)
+	startTime, _ := source["startTime"].(string)
+	start, err := time.Parse(time.RFC3339Nano, startTime)
+	if traceID == "" || err != nil {
+		return
+	}
+	orgID, _ := source[opensearch.OrgIDField].(string)
+	c.rollups.Observe(orgID, traceID, start)
+}
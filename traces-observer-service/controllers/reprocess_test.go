@@ -0,0 +1,304 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package controllers
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/config"
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/opensearch"
+)
+
+const reprocessTestIndex = "otel-traces-2026-10-16"
+
+type reprocessDocument struct {
+	id     string
+	seqNo  int64
+	source map[string]interface{}
+}
+
+// reprocessOpenSearch holds span documents sorted by span ID and applies updates checked against their sequence numbers
+// The document in rewrite is re-delivered by ingestion right after it is read
+type reprocessOpenSearch struct {
+	mu        sync.Mutex
+	documents []*reprocessDocument
+	rewrite   string
+	block     bool          // Searches wait until the request is cancelled
+	searching chan struct{} // Signalled when a blocked search arrives
+}
+
+func (f *reprocessOpenSearch) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	switch {
+	case r.URL.Path == "/_bulk":
+		f.bulk(w, r)
+	case strings.HasSuffix(r.URL.Path, "/_search/point_in_time"):
+		// Paged with search_after alone
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `{"error":{"type":"illegal_argument_exception"}}`)
+	case strings.HasSuffix(r.URL.Path, "/_search"):
+		f.search(w, r)
+	case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/otel-traces-"):
+		fmt.Fprintf(w, `{%q:{}}`, reprocessTestIndex)
+	default:
+		fmt.Fprint(w, `{"cluster_name":"test","version":{"distribution":"opensearch","number":"2.11.0"}}`)
+	}
+}
+
+func (f *reprocessOpenSearch) search(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	block := f.block
+	f.mu.Unlock()
+	if block {
+		// The cancellation of the request is only noticed once its body is read
+		_, _ = io.Copy(io.Discard, r.Body)
+		select {
+		case f.searching <- struct{}{}:
+		default:
+		}
+		<-r.Context().Done()
+		return
+	}
+
+	var query struct {
+		Size        int           `json:"size"`
+		SearchAfter []interface{} `json:"search_after"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&query); err != nil {
+		http.Error(w, "invalid query", http.StatusBadRequest)
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	after := ""
+	if len(query.SearchAfter) == 3 {
+		after, _ = query.SearchAfter[2].(string)
+	}
+	var hits []string
+	for _, doc := range f.documents {
+		if doc.id <= after || len(hits) == query.Size {
+			continue
+		}
+		source, _ := json.Marshal(doc.source)
+		hits = append(hits, fmt.Sprintf(`{"_index":%q,"_id":%q,"_seq_no":%d,"_primary_term":1,"_source":%s,"sort":[1,"trace",%q]}`,
+			reprocessTestIndex, doc.id, doc.seqNo, source, doc.id))
+		if doc.id == f.rewrite {
+			doc.seqNo++
+		}
+	}
+	fmt.Fprintf(w, `{"hits":{"total":{"value":%d},"hits":[%s]}}`, len(f.documents), strings.Join(hits, ","))
+}
+
+func (f *reprocessOpenSearch) bulk(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var items []string
+	scanner := bufio.NewScanner(r.Body)
+	for scanner.Scan() {
+		var action map[string]struct {
+			ID    string `json:"_id"`
+			SeqNo int64  `json:"if_seq_no"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &action); err != nil || !scanner.Scan() {
+			http.Error(w, "invalid bulk body", http.StatusBadRequest)
+			return
+		}
+		meta, ok := action["update"]
+		if !ok {
+			http.Error(w, "only updates are expected", http.StatusBadRequest)
+			return
+		}
+		var update struct {
+			Script struct {
+				Params struct {
+					Fields map[string]interface{} `json:"fields"`
+				} `json:"params"`
+			} `json:"script"`
+		}
+		_ = json.Unmarshal(scanner.Bytes(), &update)
+
+		doc := f.find(meta.ID)
+		if doc.seqNo != meta.SeqNo {
+			items = append(items, fmt.Sprintf(`{"update":{"_id":%q,"status":409,"error":{"type":"version_conflict_engine_exception","reason":"version conflict"}}}`, meta.ID))
+			continue
+		}
+		for field, value := range update.Script.Params.Fields {
+			if value == nil {
+				delete(doc.source, field)
+			} else {
+				doc.source[field] = value
+			}
+		}
+		doc.seqNo++
+		items = append(items, fmt.Sprintf(`{"update":{"_id":%q,"status":200}}`, meta.ID))
+	}
+	fmt.Fprintf(w, `{"took":1,"errors":true,"items":[%s]}`, strings.Join(items, ","))
+}
+
+func (f *reprocessOpenSearch) find(id string) *reprocessDocument {
+	for _, doc := range f.documents {
+		if doc.id == id {
+			return doc
+		}
+	}
+	return nil
+}
+
+func newReprocessController(t *testing.T, fake *reprocessOpenSearch) *ReprocessController {
+	t.Helper()
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	client, err := opensearch.NewClient(&config.OpenSearchConfig{
+		Address:        server.URL,
+		Username:       "admin",
+		Password:       "admin",
+		RequestTimeout: 5 * time.Second,
+	}, nil)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	controller := NewReprocessController(client, nil)
+	controller.pageSize = 2
+	t.Cleanup(controller.Close)
+	return controller
+}
+
+func reprocessSpan(spanID string, attributes map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"traceId":    "0af7651916cd43dd8448eb211c80319c",
+		"spanId":     spanID,
+		"name":       "search",
+		"startTime":  "2026-10-16T10:00:00Z",
+		"attributes": attributes,
+	}
+}
+
+func waitForReprocess(t *testing.T, controller *ReprocessController, id string) *ReprocessJob {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		job, err := controller.Get(id)
+		if err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+		if job.State != ReprocessRunning {
+			return job
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("reprocessing job did not finish")
+	return nil
+}
+
+func TestReprocessUpdatesDerivedFields(t *testing.T) {
+	tool := map[string]interface{}{"traceloop.span.kind": "tool", "traceloop.entity.name": "search"}
+	fake := &reprocessOpenSearch{
+		documents: []*reprocessDocument{
+			{id: "a", seqNo: 1, source: reprocessSpan("a", tool)},
+			{id: "b", seqNo: 4, source: reprocessSpan("b", map[string]interface{}{"custom": "value"})},
+			{id: "c", seqNo: 2, source: reprocessSpan("c", tool)},
+		},
+		rewrite: "c",
+	}
+	controller := newReprocessController(t, fake)
+
+	start := time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)
+	job, err := controller.Start(start, start.Add(24*time.Hour))
+	if err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	job = waitForReprocess(t, controller, job.ID)
+
+	if job.State != ReprocessCompleted || job.Error != "" {
+		t.Fatalf("job = %+v, want completed", job)
+	}
+	if job.Total != 3 || job.Scanned != 3 || job.Updated != 1 || job.Unchanged != 1 || job.Conflicts != 1 || job.Failed != 0 {
+		t.Errorf("job = %+v, want 3 scanned with 1 updated, 1 unchanged and 1 conflict", job)
+	}
+	if job.FinishedAt == nil {
+		t.Error("finished job has no finish time")
+	}
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+	invocation, ok := fake.find("a").source[opensearch.ToolInvocationField].(map[string]interface{})
+	if !ok || invocation["name"] != "search" {
+		t.Errorf("tool invocation = %v, want the search tool", fake.find("a").source[opensearch.ToolInvocationField])
+	}
+	if _, ok := fake.find("a").source["attributes"].(map[string]interface{})["traceloop.span.kind"]; !ok {
+		t.Error("raw attributes were rewritten")
+	}
+	// The span re-delivered meanwhile keeps what ingestion wrote
+	if _, ok := fake.find("c").source[opensearch.ToolInvocationField]; ok {
+		t.Error("span rewritten by ingestion was overwritten")
+	}
+}
+
+func TestReprocessCancel(t *testing.T) {
+	fake := &reprocessOpenSearch{block: true, searching: make(chan struct{}, 1)}
+	controller := newReprocessController(t, fake)
+
+	start := time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)
+	job, err := controller.Start(start, start.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	select {
+	case <-fake.searching:
+	case <-time.After(5 * time.Second):
+		t.Fatal("job did not search")
+	}
+
+	if _, err := controller.Start(start, start.Add(time.Hour)); err != ErrReprocessRunning {
+		t.Errorf("Start() while running error = %v, want ErrReprocessRunning", err)
+	}
+
+	cancelled, err := controller.Cancel(job.ID)
+	if err != nil {
+		t.Fatalf("Cancel() error = %v", err)
+	}
+	if cancelled.State != ReprocessCancelled {
+		t.Errorf("state after Cancel() = %q, want %q", cancelled.State, ReprocessCancelled)
+	}
+	if _, err := controller.Cancel("unknown"); err != ErrReprocessJobNotFound {
+		t.Errorf("Cancel() of an unknown job error = %v, want ErrReprocessJobNotFound", err)
+	}
+
+	// A new job can start once the cancelled one stopped
+	fake.mu.Lock()
+	fake.block = false
+	fake.mu.Unlock()
+	next, err := controller.Start(start, start.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("Start() after Cancel() error = %v", err)
+	}
+	if jobs := controller.List(); len(jobs) != 2 || jobs[0].ID != next.ID {
+		t.Errorf("List() = %+v, want both jobs newest first", jobs)
+	}
+}
@@ -0,0 +1,80 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/config"
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/opensearch"
+)
+
+func TestSearchSpansGroupsHighlights(t *testing.T) {
+	// The first span was re-delivered into the index of the next day, its copies come back as two hits
+	hits := []string{
+		`{"_index":"otel-traces-2026-10-16","_source":{"traceId":"t1","spanId":"s1","name":"chat","attributes":{"gen_ai.prompt":"refund"}},` +
+			`"highlight":{"attributes.gen_ai.prompt.search":["<em>refund</em> my order"],"attributes.traceloop.entity.output":["issued a <em>refund</em>"]}}`,
+		`{"_index":"otel-traces-2026-10-17","_source":{"traceId":"t1","spanId":"s1","name":"chat","attributes":{"gen_ai.prompt":"refund"}},` +
+			`"highlight":{"attributes.gen_ai.prompt.search":["<em>refund</em> my order"]}}`,
+		`{"_index":"otel-traces-2026-10-17","_source":{"traceId":"t2","spanId":"s2","name":"tool"},` +
+			`"highlight":{"attributes.tool.input":["<em>refund</em>(order)"],"attributes.unknown":["ignored"]}}`,
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/_search") {
+			fmt.Fprintf(w, `{"hits":{"total":{"value":3},"hits":[%s]}}`, strings.Join(hits, ","))
+			return
+		}
+		fmt.Fprint(w, `{"cluster_name":"test","version":{"distribution":"opensearch","number":"2.11.0"}}`)
+	}))
+	defer server.Close()
+
+	client, err := opensearch.NewClient(&config.OpenSearchConfig{
+		Address:        server.URL,
+		Username:       "admin",
+		Password:       "admin",
+		RequestTimeout: 5 * time.Second,
+	}, nil)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	controller := NewTracingController(client, nil, TracingOptions{})
+
+	response, err := controller.SearchSpans(context.Background(), opensearch.SpanSearchParams{Query: "refund", Limit: 20})
+	if err != nil {
+		t.Fatalf("SearchSpans() error = %v", err)
+	}
+	if len(response.Results) != 2 {
+		t.Fatalf("results = %d, want 2", len(response.Results))
+	}
+	want := []map[string][]string{
+		{"input": {"<em>refund</em> my order"}, "output": {"issued a <em>refund</em>"}},
+		{"input": {"<em>refund</em>(order)"}},
+	}
+	for i, result := range response.Results {
+		if !reflect.DeepEqual(result.Highlights, want[i]) {
+			t.Errorf("span %s highlights = %v, want %v", result.SpanID, result.Highlights, want[i])
+		}
+	}
+}
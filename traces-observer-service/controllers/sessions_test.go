@@ -0,0 +1,251 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/config"
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/opensearch"
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/pricing"
+)
+
+// sessionTestTrace is a trace of a conversation turn made of a root span and one LLM call
+type sessionTestTrace struct {
+	traceID   string
+	sessionID string
+	start     time.Time
+	tokens    int
+}
+
+// fakeSessionOpenSearch serves the session lookup, root span and trace span queries of session traces
+type fakeSessionOpenSearch struct {
+	traces []sessionTestTrace
+}
+
+func (f *fakeSessionOpenSearch) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if r.Method != http.MethodPost || !strings.HasSuffix(r.URL.Path, "/_search") {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, `{"cluster_name":"test","version":{"distribution":"opensearch","number":"2.11.0"}}`)
+		return
+	}
+	if strings.Contains(r.URL.Path, opensearch.AnnotationIndex) {
+		http.NotFound(w, r)
+		return
+	}
+
+	var body struct {
+		Size        int                      `json:"size"`
+		SearchAfter []interface{}            `json:"search_after"`
+		Sort        []map[string]interface{} `json:"sort"`
+		Aggs        map[string]interface{}   `json:"aggs"`
+		Query       json.RawMessage          `json:"query"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	switch {
+	case body.Aggs["traces"] != nil:
+		// Session lookup, the session ID is the first should clause of the query
+		var query struct {
+			Bool struct {
+				Must []struct {
+					Bool struct {
+						Should []struct {
+							Term map[string]string `json:"term"`
+						} `json:"should"`
+					} `json:"bool"`
+				} `json:"must"`
+			} `json:"bool"`
+		}
+		_ = json.Unmarshal(body.Query, &query)
+		sessionID := query.Bool.Must[0].Bool.Should[0].Term["attributes.session.id"]
+		buckets := []string{}
+		// Buckets come ordered by document count, not by time
+		for i := len(f.traces) - 1; i >= 0; i-- {
+			if f.traces[i].sessionID == sessionID {
+				buckets = append(buckets, fmt.Sprintf(`{"key":%q,"doc_count":2}`, f.traces[i].traceID))
+			}
+		}
+		fmt.Fprintf(w, `{"hits":{"total":{"value":0},"hits":[]},"aggregations":{"traces":{"buckets":[%s]}}}`, strings.Join(buckets, ","))
+	case len(body.Sort) == 3:
+		// Root spans of the session traces in start time order
+		var query struct {
+			Bool struct {
+				Must []struct {
+					Terms map[string][]string `json:"terms"`
+				} `json:"must"`
+			} `json:"bool"`
+		}
+		_ = json.Unmarshal(body.Query, &query)
+		wanted := map[string]bool{}
+		for _, must := range query.Bool.Must {
+			for _, traceID := range must.Terms["traceId"] {
+				wanted[traceID] = true
+			}
+		}
+		roots := []sessionTestTrace{}
+		for _, trace := range f.traces {
+			if wanted[trace.traceID] && (len(body.SearchAfter) == 0 || float64(trace.start.UnixMilli()) > body.SearchAfter[0].(float64)) {
+				roots = append(roots, trace)
+			}
+		}
+		sort.Slice(roots, func(i, j int) bool { return roots[i].start.Before(roots[j].start) })
+		total := len(roots)
+		if len(roots) > body.Size {
+			roots = roots[:body.Size]
+		}
+		hits := []string{}
+		for _, root := range roots {
+			hits = append(hits, fmt.Sprintf(`{"_source":%s,"sort":[%d,%q,"root"]}`, f.rootSpan(root), root.start.UnixMilli(), root.traceID))
+		}
+		fmt.Fprintf(w, `{"hits":{"total":{"value":%d},"hits":[%s]}}`, total, strings.Join(hits, ","))
+	default:
+		// Every span of the requested traces
+		var query struct {
+			Bool struct {
+				Must []struct {
+					Terms map[string][]string `json:"terms"`
+				} `json:"must"`
+			} `json:"bool"`
+		}
+		_ = json.Unmarshal(body.Query, &query)
+		wanted := map[string]bool{}
+		for _, traceID := range query.Bool.Must[0].Terms["traceId"] {
+			wanted[traceID] = true
+		}
+		hits := []string{}
+		for _, trace := range f.traces {
+			if wanted[trace.traceID] {
+				hits = append(hits, fmt.Sprintf(`{"_source":%s}`, f.rootSpan(trace)), fmt.Sprintf(`{"_source":%s}`, f.llmSpan(trace)))
+			}
+		}
+		fmt.Fprintf(w, `{"hits":{"total":{"value":%d},"hits":[%s]}}`, len(hits), strings.Join(hits, ","))
+	}
+}
+
+func (f *fakeSessionOpenSearch) rootSpan(trace sessionTestTrace) string {
+	return fmt.Sprintf(`{"traceId":%q,"spanId":"root","name":"turn","startTime":%q,"endTime":%q,"attributes":{"session.id":%q}}`,
+		trace.traceID, trace.start.Format(time.RFC3339Nano), trace.start.Add(time.Second).Format(time.RFC3339Nano), trace.sessionID)
+}
+
+func (f *fakeSessionOpenSearch) llmSpan(trace sessionTestTrace) string {
+	return fmt.Sprintf(`{"traceId":%q,"spanId":"llm","parentSpanId":"root","name":"chat","startTime":%q,"endTime":%q,`+
+		`"attributes":{"gen_ai.operation.name":"chat","gen_ai.request.model":"model-a","gen_ai.usage.input_tokens":%d,"gen_ai.usage.output_tokens":%d}}`,
+		trace.traceID, trace.start.Format(time.RFC3339Nano), trace.start.Add(500*time.Millisecond).Format(time.RFC3339Nano), trace.tokens, trace.tokens)
+}
+
+// newSessionTestController returns a tracing controller of a fake OpenSearch holding the given traces
+func newSessionTestController(t *testing.T, traces []sessionTestTrace) *TracingController {
+	server := httptest.NewServer(&fakeSessionOpenSearch{traces: traces})
+	t.Cleanup(server.Close)
+
+	client, err := opensearch.NewClient(&config.OpenSearchConfig{
+		Address:        server.URL,
+		Username:       "admin",
+		Password:       "admin",
+		RequestTimeout: 5 * time.Second,
+	}, nil)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	table := pricing.NewTable(map[string]pricing.ModelPrice{"model-a": {InputPerMillion: 1, OutputPerMillion: 1}})
+	return NewTracingController(client, table, TracingOptions{})
+}
+
+func TestGetSessionTraces(t *testing.T) {
+	start := time.Date(2026, 10, 16, 10, 0, 0, 0, time.UTC)
+	controller := newSessionTestController(t, []sessionTestTrace{
+		{traceID: "turn-1", sessionID: "session-a", start: start, tokens: 100},
+		{traceID: "turn-2", sessionID: "session-a", start: start.Add(time.Minute), tokens: 200},
+		{traceID: "other", sessionID: "session-b", start: start.Add(30 * time.Second), tokens: 5000},
+		{traceID: "turn-3", sessionID: "session-a", start: start.Add(2 * time.Minute), tokens: 300},
+	})
+	params := opensearch.SessionTracesParams{
+		SessionID: "session-a",
+		StartTime: "2026-10-16T00:00:00Z",
+		EndTime:   "2026-10-16T23:59:59Z",
+		Limit:     2,
+	}
+
+	first, err := controller.GetSessionTraces(context.Background(), params)
+	if err != nil {
+		t.Fatalf("GetSessionTraces() error = %v", err)
+	}
+	if got := sessionTraceIDs(first); got != "turn-1,turn-2" {
+		t.Errorf("first page traces = %s, want turn-1,turn-2", got)
+	}
+	if first.TotalCount != 3 || first.NextCursor == "" {
+		t.Errorf("first page total count and cursor = %d and %q, want 3 and a cursor", first.TotalCount, first.NextCursor)
+	}
+	// The cumulative usage covers the whole session, not only the first page
+	usage := first.AggregatedUsage
+	if usage == nil || usage.TotalTokens != 1200 {
+		t.Fatalf("aggregated usage = %+v, want 1200 tokens", usage)
+	}
+	if usage.Cost == nil || math.Abs(usage.Cost.TotalCost-0.0012) > 1e-12 {
+		t.Errorf("session cost = %+v, want 0.0012", usage.Cost)
+	}
+
+	params.Cursor, err = opensearch.DecodeCursor(first.NextCursor)
+	if err != nil {
+		t.Fatalf("DecodeCursor() error = %v", err)
+	}
+	second, err := controller.GetSessionTraces(context.Background(), params)
+	if err != nil {
+		t.Fatalf("GetSessionTraces() of the second page error = %v", err)
+	}
+	if got := sessionTraceIDs(second); got != "turn-3" {
+		t.Errorf("second page traces = %s, want turn-3", got)
+	}
+	if second.NextCursor != "" || second.AggregatedUsage != nil {
+		t.Errorf("second page cursor and usage = %q and %+v, want neither", second.NextCursor, second.AggregatedUsage)
+	}
+
+	params = opensearch.SessionTracesParams{SessionID: "unknown", StartTime: params.StartTime, EndTime: params.EndTime}
+	empty, err := controller.GetSessionTraces(context.Background(), params)
+	if err != nil {
+		t.Fatalf("GetSessionTraces() of an unknown session error = %v", err)
+	}
+	if len(empty.Traces) != 0 || empty.AggregatedUsage != nil {
+		t.Errorf("unknown session = %+v, want no traces", empty)
+	}
+}
+
+// sessionTraceIDs joins the trace IDs of a session page in order
+func sessionTraceIDs(response *opensearch.SessionTracesResponse) string {
+	ids := make([]string, 0, len(response.Traces))
+	for _, trace := range response.Traces {
+		ids = append(ids, trace.TraceID)
+	}
+	return strings.Join(ids, ",")
+}
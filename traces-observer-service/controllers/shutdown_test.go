@@ -0,0 +1,229 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package controllers
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/config"
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/opensearch"
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/otlp"
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/sampling"
+)
+
+// fakeOpenSearch answers the info and bulk requests of the OpenSearch client and records the indexed document IDs
+type fakeOpenSearch struct {
+	mu  sync.Mutex
+	ids map[string]bool
+}
+
+func (f *fakeOpenSearch) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if r.URL.Path != "/_bulk" {
+		fmt.Fprint(w, `{"cluster_name":"test","version":{"distribution":"opensearch","number":"2.11.0"}}`)
+		return
+	}
+
+	var items []string
+	scanner := bufio.NewScanner(r.Body)
+	scanner.Buffer(make([]byte, 1024*1024), 16*1024*1024)
+	for action := true; scanner.Scan(); action = !action {
+		if !action {
+			continue
+		}
+		var line map[string]struct {
+			Index string `json:"_index"`
+			ID    string `json:"_id"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		meta := line["index"]
+		f.mu.Lock()
+		f.ids[meta.ID] = true
+		f.mu.Unlock()
+		items = append(items, fmt.Sprintf(`{"index":{"_index":%q,"_id":%q,"status":201}}`, meta.Index, meta.ID))
+	}
+	fmt.Fprintf(w, `{"took":1,"errors":false,"items":[%s]}`, strings.Join(items, ","))
+}
+
+func (f *fakeOpenSearch) indexed() map[string]bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	indexed := make(map[string]bool, len(f.ids))
+	for id := range f.ids {
+		indexed[id] = true
+	}
+	return indexed
+}
+
+func TestShutdownIndexesBufferedSpans(t *testing.T) {
+	fake := &fakeOpenSearch{ids: map[string]bool{}}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	client, err := opensearch.NewClient(&config.OpenSearchConfig{
+		Address:        server.URL,
+		Username:       "admin",
+		Password:       "admin",
+		RequestTimeout: 5 * time.Second,
+	}, nil)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	// Nothing is flushed before shutdown, so every span is still buffered in the pipeline
+	indexer, err := client.NewBulkIndexer(opensearch.BulkIndexerConfig{MaxBatchDocs: 10000, FlushInterval: time.Hour})
+	if err != nil {
+		t.Fatalf("NewBulkIndexer() error = %v", err)
+	}
+	sampler := sampling.NewTailSampler(sampling.Config{DecisionWait: time.Hour, SamplePercentage: 100}, indexer, nil, nil)
+	pool := NewProcessingPool(4, 10000, nil)
+	controller := NewIngestionController(indexer, sampler, nil, nil, nil, nil, pool, nil, nil, nil, 0, nil)
+
+	corpus := loadCorpus(t)
+	expected := map[string]bool{}
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		request := replicateCorpus(corpus, 8)
+		for _, resourceSpans := range request.GetResourceSpans() {
+			for _, scopeSpans := range resourceSpans.GetScopeSpans() {
+				for _, span := range scopeSpans.GetSpans() {
+					span.TraceId[2] = byte(i)
+				}
+			}
+		}
+		documents, _ := otlp.ConvertResourceSpans(request.GetResourceSpans())
+		for _, document := range documents {
+			expected[opensearch.SpanDocumentID(document.TraceID, document.SpanID)] = true
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := controller.Export(context.Background(), request); err != nil {
+				t.Errorf("Export() error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if indexed := fake.indexed(); len(indexed) != 0 {
+		t.Fatalf("%d documents indexed before shutdown, want all spans buffered", len(indexed))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := controller.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	if err := indexer.Close(ctx); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	indexed := fake.indexed()
+	for id := range expected {
+		if !indexed[id] {
+			t.Errorf("span %s buffered at shutdown was not indexed", id)
+		}
+	}
+	if len(indexed) != len(expected) {
+		t.Errorf("indexed %d documents, want %d", len(indexed), len(expected))
+	}
+
+	if _, err := controller.Export(context.Background(), corpus); !errors.Is(err, ErrIngestionStopped) {
+		t.Errorf("Export() after shutdown error = %v, want ErrIngestionStopped", err)
+	}
+}
+
+// fakeReadinessClient reports a configurable health and breaker state
+type fakeReadinessClient struct {
+	mu      sync.Mutex
+	healthy bool
+	breaker string
+}
+
+func (f *fakeReadinessClient) HealthCheck(context.Context) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if !f.healthy {
+		return errors.New("connection refused")
+	}
+	return nil
+}
+
+func (f *fakeReadinessClient) BreakerState() string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.breaker
+}
+
+func (f *fakeReadinessClient) set(healthy bool, breaker string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.healthy, f.breaker = healthy, breaker
+}
+
+func TestReadiness(t *testing.T) {
+	client := &fakeReadinessClient{breaker: opensearch.BreakerClosed}
+	setupCalls := 0
+	readiness := NewReadiness(client, func(context.Context) error {
+		setupCalls++
+		return nil
+	}, 10*time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	readiness.Start(ctx)
+
+	if err := readiness.Check(); err == nil {
+		t.Fatal("Check() = nil before OpenSearch is reachable")
+	}
+
+	client.set(true, opensearch.BreakerClosed)
+	deadline := time.Now().Add(5 * time.Second)
+	for readiness.Check() != nil {
+		if time.Now().After(deadline) {
+			t.Fatalf("Check() = %v, want ready once OpenSearch is reachable", readiness.Check())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if setupCalls != 1 {
+		t.Errorf("setup ran %d times, want 1", setupCalls)
+	}
+
+	client.set(true, opensearch.BreakerOpen)
+	if err := readiness.Check(); err == nil {
+		t.Error("Check() = nil while the circuit breaker is open")
+	}
+
+	client.set(true, opensearch.BreakerClosed)
+	readiness.ShuttingDown()
+	if err := readiness.Check(); err == nil {
+		t.Error("Check() = nil while shutting down")
+	}
+}
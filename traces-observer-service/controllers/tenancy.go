@@ -0,0 +1,103 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package controllers
+
+import (
+	"context"
+	"errors"
+
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/opensearch"
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/otlp"
+)
+
+// IngestKeyHeader carries the ingest key of an export, as an HTTP header or gRPC metadata
+const IngestKeyHeader = "X-Ingest-Key"
+
+// ErrUnknownIngestKey is returned for exports presenting an ingest key that is not configured
+var ErrUnknownIngestKey = errors.New("unknown ingest key")
+
+type ingestKeyCtx struct{}
+
+// WithIngestKey records the ingest key an export was sent with
+func WithIngestKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, ingestKeyCtx{}, key)
+}
+
+// ingestKey returns the ingest key an export was sent with, empty when it carried none
+func ingestKey(ctx context.Context) string {
+	key, _ := ctx.Value(ingestKeyCtx{}).(string)
+	return key
+}
+
+// OrgResolver derives the organization of exported spans
+// The organization of the ingest key wins, so that exporters cannot claim another organization with a resource
+// attribute; exports without a key use the resource attribute and then the default organization
+type OrgResolver struct {
+	ingestKeys   map[string]string // Organization of every ingest key
+	attribute    string            // Resource attribute holding the organization
+	defaultOrgID string
+}
+
+// NewOrgResolver creates an organization resolver
+func NewOrgResolver(ingestKeys map[string]string, attribute, defaultOrgID string) *OrgResolver {
+	return &OrgResolver{
+		ingestKeys:   ingestKeys,
+		attribute:    attribute,
+		defaultOrgID: defaultOrgID,
+	}
+}
+
+// keyOrg returns the organization of the ingest key of an export, empty when it carried none
+func (r *OrgResolver) keyOrg(ctx context.Context) (string, error) {
+	key := ingestKey(ctx)
+	if key == "" {
+		return "", nil
+	}
+	orgID, ok := r.ingestKeys[key]
+	if !ok {
+		return "", ErrUnknownIngestKey
+	}
+	return orgID, nil
+}
+
+// resourceOrg returns the organization of a span document from its resource attributes
+func (r *OrgResolver) resourceOrg(source map[string]interface{}) string {
+	if r.attribute != "" {
+		if resource, ok := source["resource"].(map[string]interface{}); ok {
+			if orgID, ok := resource[r.attribute].(string); ok && orgID != "" {
+				return orgID
+			}
+		}
+	}
+	return r.defaultOrgID
+}
+
+// stamp records the organization on the span documents of an export
+func (r *OrgResolver) stamp(ctx context.Context, documents []otlp.SpanDocument) error {
+	keyOrgID, err := r.keyOrg(ctx)
+	if err != nil {
+		return err
+	}
+	for _, document := range documents {
+		orgID := keyOrgID
+		if orgID == "" {
+			orgID = r.resourceOrg(document.Source)
+		}
+		document.Source[opensearch.OrgIDField] = orgID
+	}
+	return nil
+}
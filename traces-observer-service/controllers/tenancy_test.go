@@ -0,0 +1,81 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/opensearch"
+)
+
+func TestExportStampsOrganization(t *testing.T) {
+	const defaultOrg, keyOrg, attributeOrg = "default-org", "key-org", "attribute-org"
+	resolver := NewOrgResolver(map[string]string{"secret": keyOrg}, "amp.org.id", defaultOrg)
+
+	tests := []struct {
+		name      string
+		key       string
+		attribute string
+		want      string
+		wantErr   error
+	}{
+		{name: "default organization", want: defaultOrg},
+		{name: "resource attribute", attribute: attributeOrg, want: attributeOrg},
+		{name: "ingest key", key: "secret", want: keyOrg},
+		{name: "ingest key wins over the resource attribute", key: "secret", attribute: attributeOrg, want: keyOrg},
+		{name: "unknown ingest key", key: "guessed", attribute: attributeOrg, wantErr: ErrUnknownIngestKey},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			indexer := newMemoryIndexer()
+			controller := NewIngestionController(indexer, nil, nil, nil, nil, nil, nil, resolver, nil, nil, 0, nil)
+
+			request := exportRequest()
+			if tt.attribute != "" {
+				resource := request.ResourceSpans[0].Resource
+				resource.Attributes = append(resource.Attributes, stringAttribute("amp.org.id", tt.attribute))
+			}
+			ctx := context.Background()
+			if tt.key != "" {
+				ctx = WithIngestKey(ctx, tt.key)
+			}
+
+			_, err := controller.Export(ctx, request)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Export() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				if len(indexer.docs) != 0 {
+					t.Errorf("indexed %d documents of a rejected export", len(indexer.docs))
+				}
+				return
+			}
+
+			if len(indexer.docs) != 3 {
+				t.Fatalf("indexed %d documents, want 3", len(indexer.docs))
+			}
+			for id, source := range indexer.docs {
+				if got := source[opensearch.OrgIDField]; got != tt.want {
+					t.Errorf("document %s organization = %v, want %s", id, got, tt.want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,275 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package controllers
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/opensearch"
+)
+
+// traceRollupLookback widens the indices searched for the spans of a trace, its root span may start in the day
+// before the spans that were indexed
+const traceRollupLookback = 24 * time.Hour
+
+// TraceRollupWriter rolls up indexed traces onto their root span document, implemented by opensearch.Client
+type TraceRollupWriter interface {
+	RollUpTraces(ctx context.Context, indices []string, traceIDs []string) (opensearch.DerivedFieldUpdateResult, error)
+}
+
+// TraceRollupsConfig holds the settings of rolling up indexed traces
+type TraceRollupsConfig struct {
+	Delay            time.Duration // Traces are rolled up once no span of them was indexed for this long
+	MaxPendingTraces int           // Memory cap of the pending traces, the oldest traces are rolled up early when exceeded
+	BatchSize        int           // Traces rolled up per request
+}
+
+// TraceRollupStats holds the counters of the trace rollups
+type TraceRollupStats struct {
+	PendingTraces int    `json:"pendingTraces"`
+	Updated       uint64 `json:"updated"`   // Root span documents whose rollup was written
+	Conflicts     uint64 `json:"conflicts"` // Root spans re-delivered while their trace was read, rolled up again with the re-delivery
+	Failed        uint64 `json:"failed"`
+	LastError     string `json:"lastError,omitempty"`
+}
+
+// traceRollupKey identifies a pending trace, trace IDs are only unique within an organization
+type traceRollupKey struct {
+	orgID   string
+	traceID string
+}
+
+// pendingRollup holds the indexed spans of a trace since it was last rolled up
+type pendingRollup struct {
+	firstStart time.Time
+	lastStart  time.Time
+	lastSeen   time.Time // Indexing of the latest span, the trace is rolled up once it is quiet
+}
+
+// TraceRollups writes the rollup of a trace onto its root span document once the spans of the trace are indexed
+// Traces are rolled up again whenever more of their spans are indexed, so late spans are picked up as well
+type TraceRollups struct {
+	cfg    TraceRollupsConfig
+	writer TraceRollupWriter
+
+	mu      sync.Mutex
+	pending map[traceRollupKey]*pendingRollup
+
+	updated   atomic.Uint64
+	conflicts atomic.Uint64
+	failed    atomic.Uint64
+	lastError atomic.Pointer[string]
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewTraceRollups creates the trace rollups, Start starts rolling up the indexed traces
+func NewTraceRollups(cfg TraceRollupsConfig, writer TraceRollupWriter) *TraceRollups {
+	if cfg.Delay <= 0 {
+		cfg.Delay = 5 * time.Second
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 100
+	}
+	return &TraceRollups{
+		cfg:     cfg,
+		writer:  writer,
+		pending: make(map[traceRollupKey]*pendingRollup),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+}
+
+// Start starts rolling up the quiet traces in the background
+func (r *TraceRollups) Start() {
+	go r.run()
+}
+
+// Close stops the background rollups and rolls up every pending trace
+// The indexer is closed first so that every indexed span has been observed
+func (r *TraceRollups) Close(ctx context.Context) {
+	close(r.stop)
+	<-r.done
+
+	r.mu.Lock()
+	due := r.pending
+	r.pending = make(map[traceRollupKey]*pendingRollup)
+	r.mu.Unlock()
+	r.rollUp(ctx, due)
+}
+
+// Observe records an indexed span of a trace, the trace is rolled up once no span of it was indexed for the delay
+// Does nothing on nil rollups, which is how rollups are disabled
+func (r *TraceRollups) Observe(orgID, traceID string, startTime time.Time) {
+	if r == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := traceRollupKey{orgID: orgID, traceID: traceID}
+	trace, ok := r.pending[key]
+	if !ok {
+		trace = &pendingRollup{firstStart: startTime, lastStart: startTime}
+		r.pending[key] = trace
+	}
+	if startTime.Before(trace.firstStart) {
+		trace.firstStart = startTime
+	}
+	if startTime.After(trace.lastStart) {
+		trace.lastStart = startTime
+	}
+	trace.lastSeen = time.Now()
+}
+
+// track wraps the callback of a queued span so that its trace is observed once the span is indexed
+func (r *TraceRollups) track(span opensearch.Span, onDone func(error)) func(error) {
+	if r == nil {
+		return onDone
+	}
+	return func(err error) {
+		if err == nil {
+			r.Observe(span.OrgID, span.TraceID, span.StartTime)
+		}
+		if onDone != nil {
+			onDone(err)
+		}
+	}
+}
+
+// Stats returns the counters of the trace rollups
+func (r *TraceRollups) Stats() TraceRollupStats {
+	r.mu.Lock()
+	pending := len(r.pending)
+	r.mu.Unlock()
+
+	stats := TraceRollupStats{
+		PendingTraces: pending,
+		Updated:       r.updated.Load(),
+		Conflicts:     r.conflicts.Load(),
+		Failed:        r.failed.Load(),
+	}
+	if lastError := r.lastError.Load(); lastError != nil {
+		stats.LastError = *lastError
+	}
+	return stats
+}
+
+// run rolls up the quiet traces
+func (r *TraceRollups) run() {
+	defer close(r.done)
+
+	interval := min(max(r.cfg.Delay/4, 100*time.Millisecond), time.Second)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			r.rollUp(context.Background(), r.due(time.Now()))
+		case <-r.stop:
+			return
+		}
+	}
+}
+
+// due takes the traces that were quiet for the delay off the pending traces, along with the traces
+// least recently indexed when the pending traces exceed their cap
+func (r *TraceRollups) due(now time.Time) map[traceRollupKey]*pendingRollup {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	due := map[traceRollupKey]*pendingRollup{}
+	for key, trace := range r.pending {
+		if now.Sub(trace.lastSeen) >= r.cfg.Delay {
+			due[key] = trace
+			delete(r.pending, key)
+		}
+	}
+	if excess := len(r.pending) - r.cfg.MaxPendingTraces; r.cfg.MaxPendingTraces > 0 && excess > 0 {
+		keys := make([]traceRollupKey, 0, len(r.pending))
+		for key := range r.pending {
+			keys = append(keys, key)
+		}
+		sort.Slice(keys, func(i, j int) bool { return r.pending[keys[i]].lastSeen.Before(r.pending[keys[j]].lastSeen) })
+		for _, key := range keys[:excess] {
+			due[key] = r.pending[key]
+			delete(r.pending, key)
+		}
+	}
+	return due
+}
+
+// rollUp writes the rollups of traces, in batches of the traces of one organization
+func (r *TraceRollups) rollUp(ctx context.Context, traces map[traceRollupKey]*pendingRollup) {
+	batches := map[string][]traceRollupKey{}
+	for key := range traces {
+		batches[key.orgID] = append(batches[key.orgID], key)
+	}
+
+	for orgID, keys := range batches {
+		orgCtx := ctx
+		if orgID != "" {
+			orgCtx = opensearch.WithOrgScope(ctx, orgID)
+		}
+		for start := 0; start < len(keys); start += r.cfg.BatchSize {
+			batch := keys[start:min(start+r.cfg.BatchSize, len(keys))]
+			r.rollUpBatch(orgCtx, batch, traces)
+		}
+	}
+}
+
+// rollUpBatch writes the rollups of a batch of traces of one organization
+func (r *TraceRollups) rollUpBatch(ctx context.Context, keys []traceRollupKey, traces map[traceRollupKey]*pendingRollup) {
+	traceIDs := make([]string, len(keys))
+	var firstStart, lastStart time.Time
+	for i, key := range keys {
+		traceIDs[i] = key.traceID
+		trace := traces[key]
+		if i == 0 || trace.firstStart.Before(firstStart) {
+			firstStart = trace.firstStart
+		}
+		if i == 0 || trace.lastStart.After(lastStart) {
+			lastStart = trace.lastStart
+		}
+	}
+
+	indices, err := opensearch.GetIndicesForTimeRange(firstStart.Add(-traceRollupLookback).Format(time.RFC3339), lastStart.Format(time.RFC3339))
+	if err == nil {
+		var result opensearch.DerivedFieldUpdateResult
+		result, err = r.writer.RollUpTraces(ctx, indices, traceIDs)
+		r.updated.Add(uint64(result.Updated))
+		r.conflicts.Add(uint64(result.Conflicts))
+		r.failed.Add(uint64(result.Failed))
+		if err == nil && result.Error != "" {
+			r.lastError.Store(&result.Error)
+			slog.Warn("Failed to write trace rollups", "failed", result.Failed, "error", result.Error)
+		}
+	}
+	if err != nil {
+		// The root spans keep their earlier rollup until more spans of the traces are indexed or they are reprocessed
+		r.failed.Add(uint64(len(keys)))
+		message := err.Error()
+		r.lastError.Store(&message)
+		slog.Warn("Failed to roll up traces", "traces", len(keys), "error", err)
+	}
+}
@@ -0,0 +1,210 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/opensearch"
+)
+
+// rollupCall records a request of the trace rollup writer
+type rollupCall struct {
+	orgID    string
+	indices  []string
+	traceIDs []string
+}
+
+// recordingRollupWriter records the traces rolled up, failing with err when set
+type recordingRollupWriter struct {
+	mu    sync.Mutex
+	calls []rollupCall
+	err   error
+}
+
+func (w *recordingRollupWriter) RollUpTraces(ctx context.Context, indices []string, traceIDs []string) (opensearch.DerivedFieldUpdateResult, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	orgID, _ := opensearch.OrgScope(ctx)
+	sorted := append([]string(nil), traceIDs...)
+	sort.Strings(sorted)
+	w.calls = append(w.calls, rollupCall{orgID: orgID, indices: indices, traceIDs: sorted})
+	if w.err != nil {
+		return opensearch.DerivedFieldUpdateResult{}, w.err
+	}
+	return opensearch.DerivedFieldUpdateResult{Updated: len(traceIDs)}, nil
+}
+
+// rolledUp returns the trace IDs rolled up so far
+func (w *recordingRollupWriter) rolledUp() []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	var traceIDs []string
+	for _, call := range w.calls {
+		traceIDs = append(traceIDs, call.traceIDs...)
+	}
+	sort.Strings(traceIDs)
+	return traceIDs
+}
+
+const testRollupDelay = 5 * time.Second
+
+var rollupStart = time.Date(2025, 11, 3, 10, 0, 0, 0, time.UTC)
+
+func TestTraceRollupsRollUpQuietTraces(t *testing.T) {
+	writer := &recordingRollupWriter{}
+	rollups := NewTraceRollups(TraceRollupsConfig{Delay: testRollupDelay}, writer)
+
+	rollups.Observe("", "trace-1", rollupStart)
+	rollups.Observe("", "trace-1", rollupStart.Add(time.Minute))
+	rollups.Observe("", "trace-2", rollupStart.Add(time.Hour))
+
+	if due := rollups.due(time.Now()); len(due) != 0 {
+		t.Fatalf("due() = %d traces, want none before the delay", len(due))
+	}
+	rollups.rollUp(context.Background(), rollups.due(time.Now().Add(testRollupDelay)))
+
+	if got, want := writer.rolledUp(), []string{"trace-1", "trace-2"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("rolled up = %v, want %v", got, want)
+	}
+	// The day before the first span is searched for a root span started earlier
+	wantIndices := []string{"otel-traces-2025-11-02", "otel-traces-2025-11-03", "otel-traces-2025-11-04"}
+	if got := writer.calls[0].indices; !reflect.DeepEqual(got, wantIndices) {
+		t.Errorf("indices = %v, want %v", got, wantIndices)
+	}
+	if stats := rollups.Stats(); stats.PendingTraces != 0 || stats.Updated != 2 {
+		t.Errorf("Stats() = %+v, want no pending trace and 2 updated", stats)
+	}
+}
+
+func TestTraceRollupsRollUpOldestTracesBeyondCap(t *testing.T) {
+	writer := &recordingRollupWriter{}
+	rollups := NewTraceRollups(TraceRollupsConfig{Delay: testRollupDelay, MaxPendingTraces: 2}, writer)
+
+	for _, traceID := range []string{"trace-1", "trace-2", "trace-3"} {
+		rollups.Observe("", traceID, rollupStart)
+		time.Sleep(time.Millisecond)
+	}
+	// A span of the oldest trace makes it the most recent one
+	rollups.Observe("", "trace-1", rollupStart)
+
+	rollups.rollUp(context.Background(), rollups.due(time.Now()))
+	if got, want := writer.rolledUp(), []string{"trace-2"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("rolled up = %v, want %v", got, want)
+	}
+	if stats := rollups.Stats(); stats.PendingTraces != 2 {
+		t.Errorf("PendingTraces = %d, want 2", stats.PendingTraces)
+	}
+}
+
+func TestTraceRollupsBatchByOrganization(t *testing.T) {
+	writer := &recordingRollupWriter{}
+	rollups := NewTraceRollups(TraceRollupsConfig{Delay: testRollupDelay, BatchSize: 2}, writer)
+	rollups.Start()
+
+	for _, traceID := range []string{"trace-1", "trace-2", "trace-3"} {
+		rollups.Observe("org-1", traceID, rollupStart)
+	}
+	// Trace IDs are only unique within an organization
+	rollups.Observe("org-2", "trace-1", rollupStart)
+	rollups.Close(context.Background())
+
+	batches := map[string][]int{}
+	for _, call := range writer.calls {
+		batches[call.orgID] = append(batches[call.orgID], len(call.traceIDs))
+	}
+	for orgID := range batches {
+		sort.Ints(batches[orgID])
+	}
+	want := map[string][]int{"org-1": {1, 2}, "org-2": {1}}
+	if !reflect.DeepEqual(batches, want) {
+		t.Errorf("batch sizes per organization = %v, want %v", batches, want)
+	}
+}
+
+func TestTraceRollupsTrackIndexedSpans(t *testing.T) {
+	writer := &recordingRollupWriter{}
+	rollups := NewTraceRollups(TraceRollupsConfig{Delay: testRollupDelay}, writer)
+	rollups.Start()
+
+	var acked []error
+	ack := func(err error) { acked = append(acked, err) }
+	indexErr := errors.New("mapping conflict")
+	rollups.track(opensearch.Span{TraceID: "indexed", StartTime: rollupStart}, ack)(nil)
+	rollups.track(opensearch.Span{TraceID: "failed", StartTime: rollupStart}, ack)(indexErr)
+	rollups.track(opensearch.Span{TraceID: "unacked", StartTime: rollupStart}, nil)(nil)
+	rollups.Close(context.Background())
+
+	if got, want := writer.rolledUp(), []string{"indexed", "unacked"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("rolled up = %v, want %v", got, want)
+	}
+	if want := []error{nil, indexErr}; !reflect.DeepEqual(acked, want) {
+		t.Errorf("acknowledged = %v, want %v", acked, want)
+	}
+
+	// Disabled rollups leave the callback as it is
+	var disabled *TraceRollups
+	if got := disabled.track(opensearch.Span{}, nil); got != nil {
+		t.Error("track() on nil rollups wrapped the callback")
+	}
+	disabled.Observe("", "trace-1", rollupStart)
+}
+
+func TestTraceRollupsReportFailures(t *testing.T) {
+	writer := &recordingRollupWriter{err: errors.New("cluster unavailable")}
+	rollups := NewTraceRollups(TraceRollupsConfig{Delay: testRollupDelay}, writer)
+	rollups.Start()
+
+	rollups.Observe("", "trace-1", rollupStart)
+	rollups.Close(context.Background())
+
+	if stats := rollups.Stats(); stats.Failed != 1 || stats.LastError != "cluster unavailable" {
+		t.Errorf("Stats() = %+v, want the failed trace and its error", stats)
+	}
+}
+
+func TestTraceOverviewReadsRootRollup(t *testing.T) {
+	root := liveSpanOf("root", "")
+	llm := llmSpanOf("llm")
+	llm.AmpAttributes.Data = opensearch.LLMData{Model: "gpt-4o", TokenUsage: &opensearch.LLMTokenUsage{InputTokens: 100, TotalTokens: 100}}
+
+	// Root spans indexed before traces were rolled up are rolled up from the spans
+	overview := newTraceOverview(&root, []opensearch.Span{root, llm}, nil, opensearch.TraceStateFinalized)
+	if overview.AggregatedUsage == nil || overview.AggregatedUsage.TotalTokens != 100 {
+		t.Errorf("AggregatedUsage = %+v, want 100 tokens rolled up from the spans", overview.AggregatedUsage)
+	}
+
+	// The stored rollup covers spans that were not read
+	root.Rollup = &opensearch.TraceRollup{
+		LLMTokenUsage: opensearch.LLMTokenUsage{InputTokens: 300, TotalTokens: 300},
+		Models:        []opensearch.ModelRollup{{Model: "gpt-4o", LLMTokenUsage: opensearch.LLMTokenUsage{InputTokens: 300, TotalTokens: 300}}},
+	}
+	overview = newTraceOverview(&root, []opensearch.Span{root, llm}, nil, opensearch.TraceStateFinalized)
+	want := &opensearch.TraceTokenUsage{
+		LLMTokenUsage: opensearch.LLMTokenUsage{InputTokens: 300, TotalTokens: 300},
+		Models:        []opensearch.ModelTokenUsage{{Model: "gpt-4o", LLMTokenUsage: opensearch.LLMTokenUsage{InputTokens: 300, TotalTokens: 300}}},
+	}
+	if !reflect.DeepEqual(overview.AggregatedUsage, want) {
+		t.Errorf("AggregatedUsage = %+v, want %+v", overview.AggregatedUsage, want)
+	}
+}
@@ -0,0 +1,561 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/opensearch"
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/pricing"
+)
+
+// Trace tail message types
+const (
+	TraceTailSnapshot    = "snapshot"     // The span tree of the trace known so far
+	TraceTailSpanAdded   = "span-added"   // A span of the trace was first seen
+	TraceTailSpanUpdated = "span-updated" // A span was delivered again, replacing its earlier copy
+	TraceTailFinalized   = "finalized"    // No more spans of the trace are expected, the last message of a tail
+)
+
+// Reasons a trace tail ends
+const (
+	TraceTailEndFinalized = "finalized" // The trace was finalized, the finalized message was the last message
+	TraceTailEndEvicted   = "evicted"   // The client fell behind, it resumes with the sequence of its last message
+	TraceTailEndClosed    = "closed"    // The tails were closed as the service shuts down
+)
+
+var (
+	// ErrTooManyTraceTails is returned by Watch when the client or the service has reached its max tails
+	ErrTooManyTraceTails = errors.New("too many live trace tails")
+	// ErrTraceTailsClosed is returned by Watch once the tails are closed
+	ErrTraceTailsClosed = errors.New("live trace tails closed")
+)
+
+// traceTailResumeWindow is how long a trace nobody tails is kept, so that a client reconnecting within it resumes
+const traceTailResumeWindow = time.Minute
+
+// traceTailReadTimeout bounds reading the indexed spans of a tailed trace
+const traceTailReadTimeout = 30 * time.Second
+
+// TraceSpanReader reads the indexed spans of a trace for the organization scope of ctx
+type TraceSpanReader func(ctx context.Context, traceID string) ([]opensearch.Span, error)
+
+// TraceTailsConfig holds the live trace tail settings
+type TraceTailsConfig struct {
+	Finalization      opensearch.FinalizationPolicy // A tail ends with the finalized message once its trace is quiet
+	MaxTails          int                           // Concurrent tails, further tails are refused
+	MaxTailsPerClient int                           // Concurrent tails of a single client, further tails are refused
+	Buffer            int                           // Messages buffered per tail, a tail falling further behind is ended
+	MaxJournal        int                           // Messages kept per trace for resuming, older positions get a snapshot
+	CatchUpDelay      time.Duration                 // Delay after which a trace is read again, picking up spans indexed while it was first read
+}
+
+// TraceTailMessage is a message sent to the client tailing a trace
+// Sequences grow across traces and restarts, a client resumes with the sequence of the last message it handled
+type TraceTailMessage struct {
+	Type     string                        `json:"type"`
+	Sequence uint64                        `json:"sequence"`
+	Trace    *opensearch.TraceTreeResponse `json:"trace,omitempty"` // Set on snapshots once a span is known
+	Span     *opensearch.Span              `json:"span,omitempty"`  // Set on span-added and span-updated
+}
+
+// TraceTailStats holds the counters of the live trace tails
+type TraceTailStats struct {
+	Tails    int    `json:"tails"`
+	Traces   int    `json:"traces"`
+	Messages uint64 `json:"messages"` // Messages handed to tails
+	Evicted  uint64 `json:"evicted"`  // Tails ended because their buffer was full
+	Resumed  uint64 `json:"resumed"`  // Tails resumed from the journal instead of a snapshot
+}
+
+// traceTailKey identifies a tailed trace, trace IDs are only unique within an organization
+type traceTailKey struct {
+	orgID   string
+	traceID string
+}
+
+// TraceTail is a client following a trace
+type TraceTail struct {
+	key       traceTailKey
+	client    string
+	messages  chan TraceTailMessage
+	started   bool   // Whether the first messages were queued, later messages are only handed over after them
+	endReason string // Set before messages is closed
+	released  bool   // Whether the tail was taken off the limits
+}
+
+// Messages returns the messages of the tail, closed when the tail ends
+func (t *TraceTail) Messages() <-chan TraceTailMessage {
+	return t.messages
+}
+
+// EndReason returns why the tail ended, valid once its messages are closed
+func (t *TraceTail) EndReason() string {
+	return t.endReason
+}
+
+// tailedTrace holds what is known of a tailed trace
+type tailedTrace struct {
+	ready      chan struct{} // Closed once the indexed spans were read
+	readErr    error
+	read       bool
+	readAt     time.Time
+	catchingUp bool
+	caughtUp   bool // Whether the trace was read again after the catch-up delay, it is only finalized afterwards
+	finalized  bool
+
+	spans     map[string]opensearch.Span // Latest copy of each span
+	firstSeen time.Time
+	lastSeen  time.Time // Ingestion of the latest span, the trace stays open until it is quiet
+
+	journal  []TraceTailMessage // Latest messages, for resuming tails
+	trimmed  uint64             // Sequence of the latest message dropped from the journal, tails resume from after it
+	sequence uint64             // Sequence of the latest message
+
+	tails     map[*TraceTail]bool
+	idleSince time.Time // When the last tail left
+}
+
+// seen extends the ingestion window of the trace with the ingestion time of a span
+func (t *tailedTrace) seen(ingestedAt time.Time) {
+	if ingestedAt.IsZero() {
+		return
+	}
+	if t.firstSeen.IsZero() || ingestedAt.Before(t.firstSeen) {
+		t.firstSeen = ingestedAt
+	}
+	if ingestedAt.After(t.lastSeen) {
+		t.lastSeen = ingestedAt
+	}
+}
+
+// resumeFrom returns the messages after sequence, false when they are no longer all in the journal
+func (t *tailedTrace) resumeFrom(sequence uint64) ([]TraceTailMessage, bool) {
+	if sequence < t.trimmed || sequence > t.sequence {
+		return nil, false
+	}
+	i := sort.Search(len(t.journal), func(i int) bool { return t.journal[i].Sequence > sequence })
+	return t.journal[i:], true
+}
+
+// TraceTails follows single traces for the clients tailing them, sending the spans of a trace as they are ingested
+// Messages are handed to each tail without blocking, a tail whose buffer is full is ended so that
+// a stuck client never holds back ingestion
+type TraceTails struct {
+	cfg          TraceTailsConfig
+	read         TraceSpanReader
+	pricingTable *pricing.Table
+
+	mu       sync.Mutex
+	traces   map[traceTailKey]*tailedTrace
+	clients  map[string]int // Tails per client
+	tails    int
+	sequence uint64
+	closed   bool
+
+	tailed   atomic.Int64 // Number of tailed traces, spans of untailed traces skip the lock
+	messages atomic.Uint64
+	evicted  atomic.Uint64
+	resumed  atomic.Uint64
+
+	catchUps sync.WaitGroup
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// NewTraceTails creates the live trace tails, Start starts finalizing the tailed traces
+func NewTraceTails(cfg TraceTailsConfig, read TraceSpanReader, pricingTable *pricing.Table) *TraceTails {
+	if cfg.Finalization.QuietPeriod <= 0 {
+		cfg.Finalization.QuietPeriod = 10 * time.Second
+	}
+	// Room for a snapshot and the finalized message
+	if cfg.Buffer < 2 {
+		cfg.Buffer = 256
+	}
+	if cfg.MaxJournal <= 0 {
+		cfg.MaxJournal = 10000
+	}
+	return &TraceTails{
+		cfg:          cfg,
+		read:         read,
+		pricingTable: pricingTable,
+		traces:       make(map[traceTailKey]*tailedTrace),
+		clients:      make(map[string]int),
+		// Seeded from the clock so that sequences keep growing across restarts,
+		// a client resuming after a restart gets a snapshot instead of the wrong messages
+		sequence: uint64(time.Now().UnixMicro()),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+// Start starts finalizing the tailed traces
+func (t *TraceTails) Start() {
+	go t.run()
+}
+
+// Close stops following traces and ends every tail
+func (t *TraceTails) Close() {
+	close(t.stop)
+	<-t.done
+	t.catchUps.Wait()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.closed = true
+	for key, trace := range t.traces {
+		for tail := range trace.tails {
+			t.end(trace, tail, TraceTailEndClosed)
+		}
+		delete(t.traces, key)
+	}
+	t.tailed.Store(0)
+}
+
+// Watch starts a tail of a trace for a client, the first messages are queued before it returns
+// Without lastSequence, or when the messages after it are gone, the tail starts with a snapshot of the trace.
+// Otherwise it starts with the messages after lastSequence. Unwatch must be called once the client is done.
+func (t *TraceTails) Watch(ctx context.Context, orgID, traceID, client string, lastSequence *uint64) (*TraceTail, error) {
+	key := traceTailKey{orgID: orgID, traceID: traceID}
+
+	t.mu.Lock()
+	if t.closed {
+		t.mu.Unlock()
+		return nil, ErrTraceTailsClosed
+	}
+	if (t.cfg.MaxTails > 0 && t.tails >= t.cfg.MaxTails) ||
+		(t.cfg.MaxTailsPerClient > 0 && t.clients[client] >= t.cfg.MaxTailsPerClient) {
+		t.mu.Unlock()
+		return nil, ErrTooManyTraceTails
+	}
+	trace, ok := t.traces[key]
+	if !ok {
+		trace = &tailedTrace{
+			ready:    make(chan struct{}),
+			spans:    make(map[string]opensearch.Span),
+			trimmed:  t.sequence,
+			sequence: t.sequence,
+			tails:    make(map[*TraceTail]bool),
+		}
+		t.traces[key] = trace
+		t.tailed.Store(int64(len(t.traces)))
+	}
+	tail := &TraceTail{key: key, client: client, messages: make(chan TraceTailMessage, t.cfg.Buffer)}
+	trace.tails[tail] = true
+	t.tails++
+	t.clients[client]++
+	t.mu.Unlock()
+
+	// The first tail reads the indexed spans, later tails wait for them
+	if !ok {
+		t.readTrace(ctx, key, trace)
+	}
+	select {
+	case <-trace.ready:
+	case <-ctx.Done():
+		t.Unwatch(tail)
+		return nil, ctx.Err()
+	}
+	if trace.readErr != nil {
+		t.Unwatch(tail)
+		return nil, trace.readErr
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if tail.endReason != "" {
+		return tail, nil
+	}
+	tail.started = true
+	if trace.finalized {
+		// Finalized while the spans were read, the tail gets the whole trace at once
+		final := trace.journal[len(trace.journal)-1]
+		snapshot := t.snapshot(traceID, trace)
+		snapshot.Sequence = final.Sequence
+		tail.messages <- snapshot
+		tail.messages <- final
+		t.end(trace, tail, TraceTailEndFinalized)
+		return tail, nil
+	}
+	if lastSequence != nil {
+		if messages, ok := trace.resumeFrom(*lastSequence); ok && len(messages) <= cap(tail.messages) {
+			for _, message := range messages {
+				tail.messages <- message
+			}
+			t.messages.Add(uint64(len(messages)))
+			t.resumed.Add(1)
+			return tail, nil
+		}
+	}
+	tail.messages <- t.snapshot(traceID, trace)
+	t.messages.Add(1)
+	return tail, nil
+}
+
+// Unwatch ends a tail and frees its place in the limits, a tail already ended is only freed
+func (t *TraceTails) Unwatch(tail *TraceTail) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if tail.released {
+		return
+	}
+	tail.released = true
+	t.tails--
+	if t.clients[tail.client]--; t.clients[tail.client] <= 0 {
+		delete(t.clients, tail.client)
+	}
+	if trace, ok := t.traces[tail.key]; ok && trace.tails[tail] {
+		delete(trace.tails, tail)
+		if len(trace.tails) == 0 {
+			trace.idleSince = time.Now()
+		}
+	}
+}
+
+// readTrace reads the indexed spans of a newly tailed trace, spans published meanwhile are newer and kept
+func (t *TraceTails) readTrace(ctx context.Context, key traceTailKey, trace *tailedTrace) {
+	// The spans are shared by every tail of the trace, so the read outlives the request that started it
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), traceTailReadTimeout)
+	defer cancel()
+	spans, err := t.read(ctx, key.traceID)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	defer close(trace.ready)
+	if err != nil {
+		trace.readErr = fmt.Errorf("failed to read trace spans: %w", err)
+		if t.traces[key] == trace {
+			delete(t.traces, key)
+			t.tailed.Store(int64(len(t.traces)))
+		}
+		return
+	}
+
+	now := time.Now()
+	trace.read = true
+	trace.readAt = now
+	for _, span := range spans {
+		if _, ok := trace.spans[span.SpanID]; !ok {
+			trace.spans[span.SpanID] = span
+		}
+		trace.seen(span.IngestedAt)
+	}
+	switch {
+	case len(trace.spans) == 0:
+		// Nothing was ingested yet, the trace is finalized once it stays quiet from now on
+		trace.firstSeen = now
+		trace.lastSeen = now
+	case t.cfg.Finalization.Finalized(trace.firstSeen, trace.lastSeen, now):
+		// A trace that was quiet long before it was tailed needs no catch-up
+		trace.caughtUp = true
+	}
+}
+
+// Publish records a processed span, sending it to the tails of its trace
+// Does nothing on nil tails, which is how tails are disabled
+func (t *TraceTails) Publish(span opensearch.Span) {
+	if t == nil || t.tailed.Load() == 0 {
+		return
+	}
+	// Spans without an organization belong to the default organization
+	orgID := span.OrgID
+	if orgID == "" {
+		orgID = opensearch.GetDefaultOrgID()
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	trace, ok := t.traces[traceTailKey{orgID: orgID, traceID: span.TraceID}]
+	if !ok {
+		return
+	}
+
+	now := time.Now()
+	if trace.firstSeen.IsZero() {
+		trace.firstSeen = now
+	}
+	trace.lastSeen = now
+	messageType := TraceTailSpanAdded
+	if _, ok := trace.spans[span.SpanID]; ok {
+		messageType = TraceTailSpanUpdated
+	}
+	trace.spans[span.SpanID] = span
+	t.send(trace, TraceTailMessage{Type: messageType, Span: &span})
+}
+
+// send journals a message of a trace and hands it to the started tails, ending tails whose buffer is full
+// Must be called with the lock held
+func (t *TraceTails) send(trace *tailedTrace, message TraceTailMessage) {
+	t.sequence++
+	message.Sequence = t.sequence
+	trace.sequence = t.sequence
+
+	trace.journal = append(trace.journal, message)
+	if over := len(trace.journal) - t.cfg.MaxJournal; over > 0 {
+		trace.trimmed = trace.journal[over-1].Sequence
+		trace.journal = trace.journal[over:]
+	}
+
+	for tail := range trace.tails {
+		if !tail.started {
+			continue
+		}
+		select {
+		case tail.messages <- message:
+			t.messages.Add(1)
+		default:
+			t.end(trace, tail, TraceTailEndEvicted)
+			t.evicted.Add(1)
+		}
+	}
+}
+
+// end ends a tail of a trace, it stays counted in the limits until it is unwatched
+// Must be called with the lock held
+func (t *TraceTails) end(trace *tailedTrace, tail *TraceTail, reason string) {
+	delete(trace.tails, tail)
+	if len(trace.tails) == 0 {
+		trace.idleSince = time.Now()
+	}
+	tail.endReason = reason
+	close(tail.messages)
+}
+
+// snapshot builds the snapshot message of a trace
+// Must be called with the lock held
+func (t *TraceTails) snapshot(traceID string, trace *tailedTrace) TraceTailMessage {
+	message := TraceTailMessage{Type: TraceTailSnapshot, Sequence: trace.sequence}
+	if len(trace.spans) > 0 {
+		spans := make([]opensearch.Span, 0, len(trace.spans))
+		for _, span := range trace.spans {
+			spans = append(spans, span)
+		}
+		message.Trace = newTraceTree(traceID, spans, 0, t.pricingTable)
+	}
+	return message
+}
+
+// Stats returns the live trace tail counters
+func (t *TraceTails) Stats() TraceTailStats {
+	t.mu.Lock()
+	tails, traces := t.tails, len(t.traces)
+	t.mu.Unlock()
+
+	return TraceTailStats{
+		Tails:    tails,
+		Traces:   traces,
+		Messages: t.messages.Load(),
+		Evicted:  t.evicted.Load(),
+		Resumed:  t.resumed.Load(),
+	}
+}
+
+// run catches up on and finalizes the tailed traces
+func (t *TraceTails) run() {
+	defer close(t.done)
+
+	interval := min(max(t.cfg.Finalization.QuietPeriod/4, 100*time.Millisecond), time.Second)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			t.check(time.Now())
+		case <-t.stop:
+			return
+		}
+	}
+}
+
+// check starts the catch-up reads that are due, finalizes the quiet traces and forgets traces nobody tails
+func (t *TraceTails) check(now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for key, trace := range t.traces {
+		if !trace.read {
+			continue
+		}
+		if len(trace.tails) == 0 && now.Sub(trace.idleSince) >= traceTailResumeWindow {
+			delete(t.traces, key)
+			continue
+		}
+		if !trace.caughtUp {
+			if !trace.catchingUp && now.Sub(trace.readAt) >= t.cfg.CatchUpDelay {
+				trace.catchingUp = true
+				t.catchUps.Add(1)
+				go t.catchUp(key, trace)
+			}
+			continue
+		}
+		if t.cfg.Finalization.Finalized(trace.firstSeen, trace.lastSeen, now) {
+			t.finalize(key, trace)
+		}
+	}
+	t.tailed.Store(int64(len(t.traces)))
+}
+
+// catchUp reads a trace again, sending the spans that were being indexed when it was first read
+func (t *TraceTails) catchUp(key traceTailKey, trace *tailedTrace) {
+	defer t.catchUps.Done()
+
+	ctx, cancel := context.WithTimeout(opensearch.WithOrgScope(context.Background(), key.orgID), traceTailReadTimeout)
+	defer cancel()
+	spans, err := t.read(ctx, key.traceID)
+	if err != nil {
+		// The trace is finalized on the spans published so far
+		slog.Warn("Failed to catch up on tailed trace", "traceId", key.traceID, "error", err)
+	}
+	sort.SliceStable(spans, func(i, j int) bool { return spans[i].StartTime.Before(spans[j].StartTime) })
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	trace.catchingUp = false
+	trace.caughtUp = true
+	if t.traces[key] != trace {
+		return
+	}
+	for _, span := range spans {
+		if _, ok := trace.spans[span.SpanID]; ok {
+			continue
+		}
+		trace.spans[span.SpanID] = span
+		trace.seen(span.IngestedAt)
+		t.send(trace, TraceTailMessage{Type: TraceTailSpanAdded, Span: &span})
+	}
+}
+
+// finalize sends the finalized message of a trace, ends its tails and forgets it
+// Tails still waiting for the spans keep the trace and finish it themselves
+// Must be called with the lock held
+func (t *TraceTails) finalize(key traceTailKey, trace *tailedTrace) {
+	delete(t.traces, key)
+	trace.finalized = true
+	t.send(trace, TraceTailMessage{Type: TraceTailFinalized})
+	for tail := range trace.tails {
+		if tail.started {
+			t.end(trace, tail, TraceTailEndFinalized)
+		}
+	}
+}
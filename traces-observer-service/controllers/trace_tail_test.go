@@ -0,0 +1,224 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/opensearch"
+)
+
+// staticSpanReader returns the same spans for every read, counting the reads
+type staticSpanReader struct {
+	spans []opensearch.Span
+	err   error
+	reads int
+}
+
+func (r *staticSpanReader) read(ctx context.Context, traceID string) ([]opensearch.Span, error) {
+	r.reads++
+	return r.spans, r.err
+}
+
+func newTestTraceTails(cfg TraceTailsConfig, reader *staticSpanReader) *TraceTails {
+	cfg.Finalization = opensearch.FinalizationPolicy{QuietPeriod: liveQuietPeriod}
+	return NewTraceTails(cfg, reader.read, nil)
+}
+
+// drain returns the queued messages of a tail and whether it ended
+func drain(tail *TraceTail) ([]TraceTailMessage, bool) {
+	var messages []TraceTailMessage
+	for {
+		select {
+		case message, ok := <-tail.Messages():
+			if !ok {
+				return messages, true
+			}
+			messages = append(messages, message)
+		default:
+			return messages, false
+		}
+	}
+}
+
+func watch(t *testing.T, tails *TraceTails, client string, lastSequence *uint64) *TraceTail {
+	t.Helper()
+	tail, err := tails.Watch(context.Background(), "org-1", "trace-1", client, lastSequence)
+	if err != nil {
+		t.Fatalf("Watch() error = %v", err)
+	}
+	return tail
+}
+
+func TestTraceTailsSendsSpansUntilFinalized(t *testing.T) {
+	root := liveSpanOf("root", "")
+	root.IngestedAt = time.Now()
+	late := llmSpanOf("late")
+	reader := &staticSpanReader{spans: []opensearch.Span{root}}
+	tails := newTestTraceTails(TraceTailsConfig{}, reader)
+
+	tail := watch(t, tails, "client-1", nil)
+	messages, _ := drain(tail)
+	if len(messages) != 1 || messages[0].Type != TraceTailSnapshot || messages[0].Trace == nil || messages[0].Trace.SpanCount != 1 {
+		t.Fatalf("first messages = %+v, want a snapshot of the root span", messages)
+	}
+
+	tails.Publish(llmSpanOf("llm-1"))
+	tails.Publish(llmSpanOf("llm-1"))
+	// Spans of the same trace ID in another organization are not part of the trace
+	other := llmSpanOf("llm-2")
+	other.OrgID = "org-2"
+	tails.Publish(other)
+	messages, _ = drain(tail)
+	if len(messages) != 2 || messages[0].Type != TraceTailSpanAdded || messages[1].Type != TraceTailSpanUpdated {
+		t.Fatalf("messages = %+v, want span-added and span-updated of llm-1", messages)
+	}
+	if messages[0].Span.SpanID != "llm-1" || messages[1].Sequence <= messages[0].Sequence {
+		t.Errorf("messages = %+v, want llm-1 in increasing sequences", messages)
+	}
+
+	// The catch-up read sends the spans that were being indexed when the trace was first read
+	reader.spans = []opensearch.Span{root, late}
+	now := time.Now()
+	tails.check(now)
+	tails.catchUps.Wait()
+	messages, _ = drain(tail)
+	if len(messages) != 1 || messages[0].Type != TraceTailSpanAdded || messages[0].Span.SpanID != "late" {
+		t.Fatalf("catch-up messages = %+v, want span-added of the late span", messages)
+	}
+
+	tails.check(now.Add(liveQuietPeriod))
+	messages, ended := drain(tail)
+	if len(messages) != 1 || messages[0].Type != TraceTailFinalized || !ended {
+		t.Fatalf("final messages = %+v ended = %v, want the finalized message and the end of the tail", messages, ended)
+	}
+	if tail.EndReason() != TraceTailEndFinalized {
+		t.Errorf("EndReason() = %q, want %q", tail.EndReason(), TraceTailEndFinalized)
+	}
+	tails.Unwatch(tail)
+	if stats := tails.Stats(); stats.Tails != 0 || stats.Traces != 0 {
+		t.Errorf("Stats() = %+v, want the trace and its tail forgotten", stats)
+	}
+}
+
+func TestTraceTailsResumesFromLastSequence(t *testing.T) {
+	tails := newTestTraceTails(TraceTailsConfig{MaxJournal: 2}, &staticSpanReader{})
+
+	tail := watch(t, tails, "client-1", nil)
+	tails.Publish(llmSpanOf("llm-1"))
+	tails.Publish(llmSpanOf("llm-2"))
+	messages, _ := drain(tail)
+	tails.Unwatch(tail)
+	if len(messages) != 3 {
+		t.Fatalf("messages = %+v, want a snapshot and two spans", messages)
+	}
+
+	// The messages after the last handled one are replayed without a snapshot
+	tail = watch(t, tails, "client-1", &messages[1].Sequence)
+	resumed, _ := drain(tail)
+	tails.Unwatch(tail)
+	if len(resumed) != 1 || resumed[0].Sequence != messages[2].Sequence {
+		t.Fatalf("resumed messages = %+v, want llm-2 only", resumed)
+	}
+
+	// Positions dropped from the journal or unknown to the trace get a new snapshot
+	tails.Publish(llmSpanOf("llm-3"))
+	tails.Publish(llmSpanOf("llm-4"))
+	for _, sequence := range []uint64{messages[1].Sequence, 1} {
+		tail = watch(t, tails, "client-1", &sequence)
+		resumed, _ = drain(tail)
+		tails.Unwatch(tail)
+		if len(resumed) != 1 || resumed[0].Type != TraceTailSnapshot || resumed[0].Trace.SpanCount != 4 {
+			t.Errorf("resume from %d = %+v, want a snapshot of 4 spans", sequence, resumed)
+		}
+	}
+	if stats := tails.Stats(); stats.Resumed != 1 {
+		t.Errorf("Stats().Resumed = %d, want 1", stats.Resumed)
+	}
+}
+
+func TestTraceTailsLimitsTailsPerClient(t *testing.T) {
+	reader := &staticSpanReader{}
+	tails := newTestTraceTails(TraceTailsConfig{MaxTails: 2, MaxTailsPerClient: 1}, reader)
+
+	first := watch(t, tails, "client-1", nil)
+	if _, err := tails.Watch(context.Background(), "org-1", "trace-1", "client-1", nil); !errors.Is(err, ErrTooManyTraceTails) {
+		t.Fatalf("second tail of a client error = %v, want %v", err, ErrTooManyTraceTails)
+	}
+	watch(t, tails, "client-2", nil)
+	if _, err := tails.Watch(context.Background(), "org-1", "trace-1", "client-3", nil); !errors.Is(err, ErrTooManyTraceTails) {
+		t.Fatalf("tail beyond the total error = %v, want %v", err, ErrTooManyTraceTails)
+	}
+
+	tails.Unwatch(first)
+	watch(t, tails, "client-1", nil)
+	if reader.reads != 1 {
+		t.Errorf("reads = %d, want the trace read once for all its tails", reader.reads)
+	}
+}
+
+func TestTraceTailsEvictsSlowTails(t *testing.T) {
+	tails := newTestTraceTails(TraceTailsConfig{Buffer: 2}, &staticSpanReader{})
+
+	slow := watch(t, tails, "client-1", nil)
+	tails.Publish(llmSpanOf("llm-1"))
+	tails.Publish(llmSpanOf("llm-2"))
+
+	messages, ended := drain(slow)
+	if len(messages) != 2 || !ended || slow.EndReason() != TraceTailEndEvicted {
+		t.Fatalf("slow tail messages = %d ended = %v reason = %q, want the buffered messages and eviction", len(messages), ended, slow.EndReason())
+	}
+	if stats := tails.Stats(); stats.Evicted != 1 || stats.Tails != 1 {
+		t.Errorf("Stats() = %+v, want the evicted tail counted until it is unwatched", stats)
+	}
+	tails.Unwatch(slow)
+}
+
+func TestTraceTailsRetriesFailedReads(t *testing.T) {
+	reader := &staticSpanReader{err: errors.New("opensearch unavailable")}
+	tails := newTestTraceTails(TraceTailsConfig{}, reader)
+
+	if _, err := tails.Watch(context.Background(), "org-1", "trace-1", "client-1", nil); err == nil {
+		t.Fatal("Watch() succeeded with a failing read")
+	}
+	if stats := tails.Stats(); stats.Tails != 0 || stats.Traces != 0 {
+		t.Fatalf("Stats() = %+v, want nothing kept of the failed tail", stats)
+	}
+
+	reader.err = nil
+	watch(t, tails, "client-1", nil)
+	if reader.reads != 2 {
+		t.Errorf("reads = %d, want the trace read again", reader.reads)
+	}
+}
+
+func TestTraceTailsCloseEndsTails(t *testing.T) {
+	tails := newTestTraceTails(TraceTailsConfig{}, &staticSpanReader{})
+	tails.Start()
+
+	tail := watch(t, tails, "client-1", nil)
+	tails.Close()
+	if _, ended := drain(tail); !ended || tail.EndReason() != TraceTailEndClosed {
+		t.Errorf("tail ended = %v reason = %q, want it closed", ended, tail.EndReason())
+	}
+	if _, err := tails.Watch(context.Background(), "org-1", "trace-1", "client-1", nil); !errors.Is(err, ErrTraceTailsClosed) {
+		t.Errorf("Watch() after Close error = %v, want %v", err, ErrTraceTailsClosed)
+	}
+}
@@ -0,0 +1,119 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package forwarding
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Destination is a downstream OTLP/HTTP endpoint receiving the processed spans
+type Destination struct {
+	Name               string            `json:"name" yaml:"name"`
+	Enabled            *bool             `json:"enabled,omitempty" yaml:"enabled,omitempty"` // Defaults to true
+	Endpoint           string            `json:"endpoint" yaml:"endpoint"`                   // Full URL, e.g. https://collector.example.com/v1/traces
+	Headers            map[string]string `json:"headers,omitempty" yaml:"headers,omitempty"` // ${VAR} references are expanded from the environment
+	Compression        string            `json:"compression,omitempty" yaml:"compression,omitempty"`
+	AttributeAllowlist []string          `json:"attributeAllowlist,omitempty" yaml:"attributeAllowlist,omitempty"` // Span attribute globs, every attribute is sent when empty
+}
+
+// destinationsFile is the layout of a destinations file
+type destinationsFile struct {
+	Destinations []Destination `json:"destinations" yaml:"destinations"`
+}
+
+// IsEnabled reports whether spans are sent to the destination
+func (d Destination) IsEnabled() bool {
+	return d.Enabled == nil || *d.Enabled
+}
+
+// Validate checks the endpoint, compression and allowlist patterns of a destination
+func (d Destination) Validate() error {
+	if d.Name == "" {
+		return fmt.Errorf("destination name is required")
+	}
+	endpoint, err := url.Parse(d.Endpoint)
+	if err != nil || (endpoint.Scheme != "http" && endpoint.Scheme != "https") || endpoint.Host == "" {
+		return fmt.Errorf("destination %s: endpoint must be an http or https URL", d.Name)
+	}
+	if d.Compression != "" && d.Compression != "gzip" && d.Compression != "none" {
+		return fmt.Errorf("destination %s: compression must be gzip or none", d.Name)
+	}
+	for _, pattern := range d.AttributeAllowlist {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("destination %s: invalid attribute pattern %q", d.Name, pattern)
+		}
+	}
+	return nil
+}
+
+// allows reports whether a span attribute passes the allowlist of the destination
+func (d Destination) allows(key string) bool {
+	if len(d.AttributeAllowlist) == 0 {
+		return true
+	}
+	for _, pattern := range d.AttributeAllowlist {
+		if matched, _ := path.Match(pattern, key); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// LoadDestinations reads the destinations from a JSON or YAML file
+// Environment references in endpoints and header values are expanded so that credentials stay out of the file
+func LoadDestinations(filePath string) ([]Destination, error) {
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read forwarding destinations: %w", err)
+	}
+
+	var file destinationsFile
+	switch strings.ToLower(filepath.Ext(filePath)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(content, &file)
+	default:
+		err = json.Unmarshal(content, &file)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse forwarding destinations %s: %w", filePath, err)
+	}
+
+	names := make(map[string]bool, len(file.Destinations))
+	for i := range file.Destinations {
+		destination := &file.Destinations[i]
+		destination.Endpoint = os.ExpandEnv(destination.Endpoint)
+		for key, value := range destination.Headers {
+			destination.Headers[key] = os.ExpandEnv(value)
+		}
+		if err := destination.Validate(); err != nil {
+			return nil, err
+		}
+		if names[destination.Name] {
+			return nil, fmt.Errorf("duplicate forwarding destination: %s", destination.Name)
+		}
+		names[destination.Name] = true
+	}
+	return file.Destinations, nil
+}
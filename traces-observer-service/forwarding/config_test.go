@@ -0,0 +1,144 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package forwarding
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDestinationValidate(t *testing.T) {
+	tests := []struct {
+		name        string
+		destination Destination
+		wantErr     string
+	}{
+		{name: "valid", destination: Destination{Name: "collector", Endpoint: "https://collector.example.com/v1/traces", Compression: "gzip", AttributeAllowlist: []string{"gen_ai.*"}}},
+		{name: "missing name", destination: Destination{Endpoint: "https://collector.example.com"}, wantErr: "name is required"},
+		{name: "missing endpoint", destination: Destination{Name: "collector"}, wantErr: "http or https URL"},
+		{name: "unsupported scheme", destination: Destination{Name: "collector", Endpoint: "grpc://collector:4317"}, wantErr: "http or https URL"},
+		{name: "missing host", destination: Destination{Name: "collector", Endpoint: "http:///v1/traces"}, wantErr: "http or https URL"},
+		{name: "unsupported compression", destination: Destination{Name: "collector", Endpoint: "http://collector:4318", Compression: "zstd"}, wantErr: "gzip or none"},
+		{name: "invalid pattern", destination: Destination{Name: "collector", Endpoint: "http://collector:4318", AttributeAllowlist: []string{"gen_ai.["}}, wantErr: "invalid attribute pattern"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.destination.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Validate() error = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDestinationAllows(t *testing.T) {
+	destination := Destination{AttributeAllowlist: []string{"gen_ai.*", "amp.kind"}}
+	tests := map[string]bool{
+		"gen_ai.request.model": true,
+		"amp.kind":             true,
+		"amp.input":            false,
+		"http.url":             false,
+	}
+	for key, want := range tests {
+		if got := destination.allows(key); got != want {
+			t.Errorf("allows(%q) = %v, want %v", key, got, want)
+		}
+	}
+	if !(Destination{}).allows("http.url") {
+		t.Error("allows() = false, want every attribute allowed without an allowlist")
+	}
+}
+
+func TestLoadDestinations(t *testing.T) {
+	t.Setenv("COLLECTOR_HOST", "collector.example.com")
+	t.Setenv("COLLECTOR_TOKEN", "secret")
+	path := filepath.Join(t.TempDir(), "destinations.yaml")
+	content := `destinations:
+  - name: collector
+    endpoint: https://${COLLECTOR_HOST}/v1/traces
+    headers:
+      Authorization: Bearer ${COLLECTOR_TOKEN}
+    compression: gzip
+  - name: archive
+    enabled: false
+    endpoint: http://archive:4318/v1/traces
+`
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	destinations, err := LoadDestinations(path)
+	if err != nil {
+		t.Fatalf("LoadDestinations() error = %v", err)
+	}
+	if len(destinations) != 2 {
+		t.Fatalf("destinations = %+v, want 2", destinations)
+	}
+	if destinations[0].Endpoint != "https://collector.example.com/v1/traces" || destinations[0].Headers["Authorization"] != "Bearer secret" {
+		t.Errorf("destination = %+v, want the environment references expanded", destinations[0])
+	}
+	if !destinations[0].IsEnabled() || destinations[1].IsEnabled() {
+		t.Errorf("enabled = %v/%v, want only the first destination enabled", destinations[0].IsEnabled(), destinations[1].IsEnabled())
+	}
+}
+
+func TestLoadDestinationsRejectsInvalidFiles(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		wantErr string
+	}{
+		{
+			name:    "duplicate names",
+			file:    "destinations.json",
+			content: `{"destinations": [{"name": "a", "endpoint": "http://a:4318"}, {"name": "a", "endpoint": "http://b:4318"}]}`,
+			wantErr: "duplicate forwarding destination: a",
+		},
+		{
+			name:    "invalid destination",
+			file:    "destinations.json",
+			content: `{"destinations": [{"name": "a", "endpoint": "ftp://a"}]}`,
+			wantErr: "http or https URL",
+		},
+		{
+			name:    "malformed file",
+			file:    "destinations.yml",
+			content: "destinations: [",
+			wantErr: "failed to parse forwarding destinations",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.file)
+			if err := os.WriteFile(path, []byte(tt.content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := LoadDestinations(path); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("LoadDestinations() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
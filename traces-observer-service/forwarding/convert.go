@@ -0,0 +1,144 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package forwarding
+
+import (
+	"encoding/json"
+
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/opensearch"
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/otlp"
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/pricing"
+)
+
+// Attributes carrying the results of span processing
+const (
+	AttributeKind         = "amp.kind"
+	AttributeFramework    = "amp.framework"
+	AttributeInput        = "amp.input"  // JSON encoded unless the input is a plain string
+	AttributeOutput       = "amp.output" // JSON encoded unless the output is a plain string
+	AttributeModel        = "amp.model"
+	AttributeInputTokens  = "amp.tokens.input"
+	AttributeOutputTokens = "amp.tokens.output"
+	AttributeTotalTokens  = "amp.tokens.total"
+	AttributeCost         = "amp.cost" // USD, only set for priced models
+	AttributeErrorType    = "amp.error.type"
+	AttributeErrorMessage = "amp.error.message"
+	AttributeSessionID    = "amp.session.id"
+	AttributeTruncated    = "amp.truncated"
+	AttributeRedactions   = "amp.redactions"
+)
+
+// BuildSpan converts a processed trace document back into an OTLP span carrying the amp.* attributes
+// The document holds the attributes after redaction and truncation, so forwarded spans never carry more than is indexed
+func BuildSpan(source map[string]interface{}, span opensearch.Span, pricingTable *pricing.Table) (*tracepb.Span, map[string]interface{}, error) {
+	result, resource, err := otlp.DocumentToSpan(source)
+	if err != nil {
+		return nil, nil, err
+	}
+	result.Attributes = append(result.Attributes, ampAttributes(span, pricingTable)...)
+	return result, resource, nil
+}
+
+// ampAttributes returns the amp.* attributes of a processed span
+func ampAttributes(span opensearch.Span, pricingTable *pricing.Table) []*commonpb.KeyValue {
+	values := map[string]interface{}{}
+	if framework := opensearch.SpanFramework(span); framework != "" {
+		values[AttributeFramework] = framework
+	}
+	if span.SessionID != "" {
+		values[AttributeSessionID] = span.SessionID
+	}
+	if span.Truncated {
+		values[AttributeTruncated] = true
+	}
+	if span.Redactions > 0 {
+		values[AttributeRedactions] = span.Redactions
+	}
+
+	if amp := span.AmpAttributes; amp != nil {
+		values[AttributeKind] = amp.Kind
+		if input := encodeValue(amp.Input); input != "" {
+			values[AttributeInput] = input
+		}
+		if output := encodeValue(amp.Output); output != "" {
+			values[AttributeOutput] = output
+		}
+		if amp.Error != nil {
+			if amp.Error.Type != "" {
+				values[AttributeErrorType] = amp.Error.Type
+			}
+			if amp.Error.Message != "" {
+				values[AttributeErrorMessage] = amp.Error.Message
+			}
+		}
+		if llm, ok := amp.Data.(opensearch.LLMData); ok {
+			addLLMAttributes(values, &llm)
+			if cost := llmCost(&llm, pricingTable); cost != nil {
+				// Added as a double explicitly, integral values would otherwise be sent as ints
+				attributes := otlp.MapToKeyValues(values)
+				return append(attributes, &commonpb.KeyValue{
+					Key:   AttributeCost,
+					Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_DoubleValue{DoubleValue: cost.TotalCost}},
+				})
+			}
+		}
+	}
+	return otlp.MapToKeyValues(values)
+}
+
+// addLLMAttributes adds the model and token usage of an LLM span
+func addLLMAttributes(values map[string]interface{}, llm *opensearch.LLMData) {
+	if llm.Model != "" {
+		values[AttributeModel] = llm.Model
+	}
+	if usage := llm.TokenUsage; usage != nil {
+		values[AttributeInputTokens] = usage.InputTokens
+		values[AttributeOutputTokens] = usage.OutputTokens
+		values[AttributeTotalTokens] = usage.TotalTokens
+	}
+}
+
+// llmCost prices the token usage of an LLM span, nil when the model is not priced
+func llmCost(llm *opensearch.LLMData, pricingTable *pricing.Table) *pricing.Cost {
+	if pricingTable == nil || llm.Model == "" || llm.TokenUsage == nil {
+		return nil
+	}
+	return pricingTable.Calculate(llm.Model, pricing.Usage{
+		InputTokens:           llm.TokenUsage.InputTokens,
+		OutputTokens:          llm.TokenUsage.OutputTokens,
+		CacheReadInputTokens:  llm.TokenUsage.CacheReadInputTokens,
+		CacheWriteInputTokens: llm.TokenUsage.CacheWriteInputTokens,
+	})
+}
+
+// encodeValue returns strings as is and JSON encodes other values, empty when there is no value
+func encodeValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil || string(encoded) == "null" {
+		return ""
+	}
+	return string(encoded)
+}
@@ -0,0 +1,182 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package forwarding
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/opensearch"
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/otlp"
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/pricing"
+)
+
+// testSource returns the index document of an LLM span as produced at ingestion
+func testSource(t *testing.T, spanID string) map[string]interface{} {
+	t.Helper()
+	traceID, _ := hex.DecodeString("0af7651916cd43dd8448eb211c80319c")
+	id, _ := hex.DecodeString(spanID)
+	documents, rejected := otlp.ConvertResourceSpans([]*tracepb.ResourceSpans{{
+		Resource: &resourcepb.Resource{Attributes: otlp.MapToKeyValues(map[string]interface{}{"service.name": "agent"})},
+		ScopeSpans: []*tracepb.ScopeSpans{{
+			Scope: &commonpb.InstrumentationScope{Name: "openinference", Version: "1.0.0"},
+			Spans: []*tracepb.Span{{
+				TraceId:           traceID,
+				SpanId:            id,
+				Name:              "chat gpt-4o",
+				Kind:              tracepb.Span_SPAN_KIND_CLIENT,
+				StartTimeUnixNano: 1760600000000000000,
+				EndTimeUnixNano:   1760600001000000000,
+				Attributes: otlp.MapToKeyValues(map[string]interface{}{
+					"gen_ai.request.model": "gpt-4o",
+					"secret.key":           "hidden",
+				}),
+				Status: &tracepb.Status{Code: tracepb.Status_STATUS_CODE_ERROR, Message: "rate limited"},
+			}},
+		}},
+	}})
+	if len(rejected) > 0 || len(documents) != 1 {
+		t.Fatalf("ConvertResourceSpans() = %d documents, rejected %v", len(documents), rejected)
+	}
+	return documents[0].Source
+}
+
+// testSpan returns the processed LLM span matching testSource
+func testSpan() opensearch.Span {
+	return opensearch.Span{
+		SpanID:     "b7ad6b7169203331",
+		SessionID:  "session-1",
+		Truncated:  true,
+		Redactions: 2,
+		AmpAttributes: &opensearch.AmpAttributes{
+			Kind:   string(opensearch.SpanTypeLLM),
+			Input:  []interface{}{map[string]interface{}{"role": "user", "content": "hi"}},
+			Output: "hello",
+			Error:  &opensearch.ErrorData{Type: "RateLimitError", Message: "rate limited"},
+			Data: opensearch.LLMData{
+				Model:      "gpt-4o",
+				TokenUsage: &opensearch.LLMTokenUsage{InputTokens: 1000000, OutputTokens: 1000000, TotalTokens: 2000000},
+			},
+		},
+	}
+}
+
+func TestBuildSpan(t *testing.T) {
+	table := pricing.NewTable(map[string]pricing.ModelPrice{"gpt-4o": {InputPerMillion: 2, OutputPerMillion: 8}})
+
+	span, resource, err := BuildSpan(testSource(t, "b7ad6b7169203331"), testSpan(), table)
+	if err != nil {
+		t.Fatalf("BuildSpan() error = %v", err)
+	}
+
+	if hex.EncodeToString(span.TraceId) != "0af7651916cd43dd8448eb211c80319c" || !bytes.Equal(span.SpanId, []byte{0xb7, 0xad, 0x6b, 0x71, 0x69, 0x20, 0x33, 0x31}) {
+		t.Errorf("ids = %x/%x, want the ids of the document", span.TraceId, span.SpanId)
+	}
+	if span.Name != "chat gpt-4o" || span.Kind != tracepb.Span_SPAN_KIND_CLIENT {
+		t.Errorf("name = %q, kind = %v, want the name and kind of the document", span.Name, span.Kind)
+	}
+	if span.EndTimeUnixNano-span.StartTimeUnixNano != 1000000000 {
+		t.Errorf("duration = %d, want 1s", span.EndTimeUnixNano-span.StartTimeUnixNano)
+	}
+	if span.Status.GetCode() != tracepb.Status_STATUS_CODE_ERROR || span.Status.GetMessage() != "rate limited" {
+		t.Errorf("status = %v, want the error status of the document", span.Status)
+	}
+	if resource["service.name"] != "agent" {
+		t.Errorf("resource = %v, want the resource of the document", resource)
+	}
+
+	attributes := map[string]*commonpb.AnyValue{}
+	for _, attribute := range span.Attributes {
+		attributes[attribute.Key] = attribute.Value
+	}
+	if _, ok := attributes["resource.service.name"]; ok {
+		t.Error("resource attributes merged into the document must not be sent as span attributes")
+	}
+	if attributes["gen_ai.request.model"].GetStringValue() != "gpt-4o" {
+		t.Errorf("gen_ai.request.model = %v, want the original attribute", attributes["gen_ai.request.model"])
+	}
+	texts := map[string]string{
+		AttributeKind:         "llm",
+		AttributeInput:        `[{"content":"hi","role":"user"}]`,
+		AttributeOutput:       "hello",
+		AttributeModel:        "gpt-4o",
+		AttributeErrorType:    "RateLimitError",
+		AttributeErrorMessage: "rate limited",
+		AttributeSessionID:    "session-1",
+	}
+	for key, want := range texts {
+		if got := attributes[key].GetStringValue(); got != want {
+			t.Errorf("%s = %q, want %q", key, got, want)
+		}
+	}
+	ints := map[string]int64{AttributeInputTokens: 1000000, AttributeOutputTokens: 1000000, AttributeTotalTokens: 2000000, AttributeRedactions: 2}
+	for key, want := range ints {
+		if got := attributes[key].GetIntValue(); got != want {
+			t.Errorf("%s = %v, want %d", key, attributes[key], want)
+		}
+	}
+	if !attributes[AttributeTruncated].GetBoolValue() {
+		t.Errorf("%s = %v, want true", AttributeTruncated, attributes[AttributeTruncated])
+	}
+	// An integral cost is still sent as a double
+	if cost, ok := attributes[AttributeCost].GetValue().(*commonpb.AnyValue_DoubleValue); !ok || cost.DoubleValue != 10 {
+		t.Errorf("%s = %v, want the double 10", AttributeCost, attributes[AttributeCost])
+	}
+}
+
+func TestBuildSpanWithoutPrice(t *testing.T) {
+	span, _, err := BuildSpan(testSource(t, "b7ad6b7169203331"), testSpan(), pricing.NewTable(nil))
+	if err != nil {
+		t.Fatalf("BuildSpan() error = %v", err)
+	}
+	for _, attribute := range span.Attributes {
+		if attribute.Key == AttributeCost {
+			t.Errorf("%s = %v, want no cost for an unpriced model", AttributeCost, attribute.Value)
+		}
+	}
+}
+
+func TestBuildSpanRejectsInvalidDocuments(t *testing.T) {
+	source := testSource(t, "b7ad6b7169203331")
+	source["spanId"] = "not-hex"
+	if _, _, err := BuildSpan(source, testSpan(), nil); err == nil {
+		t.Error("BuildSpan() error = nil, want an error for an invalid span id")
+	}
+}
+
+func TestEncodeValue(t *testing.T) {
+	tests := []struct {
+		value interface{}
+		want  string
+	}{
+		{nil, ""},
+		{"plain text", "plain text"},
+		{map[string]interface{}{"query": "weather"}, `{"query":"weather"}`},
+		{[]int{1, 2}, "[1,2]"},
+		{func() {}, ""},
+	}
+	for _, tt := range tests {
+		if got := encodeValue(tt.value); got != tt.want {
+			t.Errorf("encodeValue(%v) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
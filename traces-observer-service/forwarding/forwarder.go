@@ -0,0 +1,391 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package forwarding
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/opensearch"
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/otlp"
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/pricing"
+)
+
+// Config holds the batching and delivery settings shared by all destinations
+type Config struct {
+	BatchSize      int           // Maximum spans per export request
+	FlushInterval  time.Duration // Maximum time a span waits in a partial batch
+	QueueSize      int           // Spans waiting per destination, further spans are dropped
+	MaxRetries     int           // Retries of a failed export request
+	RetryBackoff   time.Duration // Initial delay between retries, doubled on every retry
+	RequestTimeout time.Duration // Timeout of a single export request
+}
+
+// DestinationStats holds the counters of a destination
+type DestinationStats struct {
+	Name     string `json:"name"`
+	Queued   int    `json:"queued"`
+	Exported uint64 `json:"exported"`
+	Failed   uint64 `json:"failed"`  // Spans of export requests that failed after every retry
+	Dropped  uint64 `json:"dropped"` // Spans dropped because the queue was full
+}
+
+// Forwarder re-exports processed spans to downstream OTLP/HTTP endpoints
+// Forwarding never blocks or fails ingestion, spans that cannot be delivered are counted and dropped
+type Forwarder struct {
+	pricingTable *pricing.Table
+	exporters    []*exporter
+}
+
+// queuedSpan is a span waiting to be exported, grouped with the spans of the same resource and scope
+type queuedSpan struct {
+	groupKey string
+	resource map[string]interface{}
+	scope    *commonpb.InstrumentationScope
+	span     *tracepb.Span
+}
+
+// exporter batches the spans of a destination and posts them
+type exporter struct {
+	cfg         Config
+	destination Destination
+	httpClient  *http.Client
+	queue       chan queuedSpan
+
+	exported atomic.Uint64
+	failed   atomic.Uint64
+	dropped  atomic.Uint64
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewForwarder creates a forwarder for the enabled destinations and starts exporting
+func NewForwarder(cfg Config, destinations []Destination, pricingTable *pricing.Table) *Forwarder {
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 512
+	}
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = 5 * time.Second
+	}
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = 10000
+	}
+	if cfg.RetryBackoff <= 0 {
+		cfg.RetryBackoff = time.Second
+	}
+	if cfg.RequestTimeout <= 0 {
+		cfg.RequestTimeout = 10 * time.Second
+	}
+
+	f := &Forwarder{pricingTable: pricingTable}
+	for _, destination := range destinations {
+		if !destination.IsEnabled() {
+			continue
+		}
+		e := &exporter{
+			cfg:         cfg,
+			destination: destination,
+			httpClient:  &http.Client{Timeout: cfg.RequestTimeout},
+			queue:       make(chan queuedSpan, cfg.QueueSize),
+			stop:        make(chan struct{}),
+			done:        make(chan struct{}),
+		}
+		go e.run()
+		f.exporters = append(f.exporters, e)
+	}
+	return f
+}
+
+// Enabled reports whether any destination receives spans
+func (f *Forwarder) Enabled() bool {
+	return f != nil && len(f.exporters) > 0
+}
+
+// Forward queues a processed span for every destination
+func (f *Forwarder) Forward(source map[string]interface{}, span opensearch.Span) {
+	if !f.Enabled() {
+		return
+	}
+
+	result, resource, err := BuildSpan(source, span, f.pricingTable)
+	if err != nil {
+		slog.Warn("Failed to convert span for forwarding", "spanId", span.SpanID, "error", err)
+		return
+	}
+	scope := instrumentationScope(source)
+	groupKey := resourceKey(resource, scope)
+
+	for _, e := range f.exporters {
+		item := queuedSpan{
+			groupKey: groupKey,
+			resource: resource,
+			scope:    scope,
+			span:     withAttributes(result, e.destination.filter(result.Attributes)),
+		}
+		select {
+		case e.queue <- item:
+		default:
+			e.dropped.Add(1)
+		}
+	}
+}
+
+// Close exports the queued spans, giving up when the context is done
+func (f *Forwarder) Close(ctx context.Context) {
+	if f == nil {
+		return
+	}
+	for _, e := range f.exporters {
+		close(e.stop)
+	}
+	for _, e := range f.exporters {
+		select {
+		case <-e.done:
+		case <-ctx.Done():
+			slog.Warn("Timed out exporting queued spans", "destination", e.destination.Name, "queued", len(e.queue))
+		}
+	}
+}
+
+// Stats returns the counters of every destination
+func (f *Forwarder) Stats() []DestinationStats {
+	if f == nil {
+		return nil
+	}
+	stats := make([]DestinationStats, 0, len(f.exporters))
+	for _, e := range f.exporters {
+		stats = append(stats, DestinationStats{
+			Name:     e.destination.Name,
+			Queued:   len(e.queue),
+			Exported: e.exported.Load(),
+			Failed:   e.failed.Load(),
+			Dropped:  e.dropped.Load(),
+		})
+	}
+	return stats
+}
+
+// filter returns the attributes passing the allowlist of the destination
+func (d Destination) filter(attributes []*commonpb.KeyValue) []*commonpb.KeyValue {
+	if len(d.AttributeAllowlist) == 0 {
+		return attributes
+	}
+	filtered := make([]*commonpb.KeyValue, 0, len(attributes))
+	for _, attribute := range attributes {
+		if d.allows(attribute.GetKey()) {
+			filtered = append(filtered, attribute)
+		}
+	}
+	return filtered
+}
+
+// withAttributes returns a copy of a span with other attributes, sharing the remaining fields
+func withAttributes(span *tracepb.Span, attributes []*commonpb.KeyValue) *tracepb.Span {
+	return &tracepb.Span{
+		TraceId:           span.TraceId,
+		SpanId:            span.SpanId,
+		TraceState:        span.TraceState,
+		ParentSpanId:      span.ParentSpanId,
+		Name:              span.Name,
+		Kind:              span.Kind,
+		StartTimeUnixNano: span.StartTimeUnixNano,
+		EndTimeUnixNano:   span.EndTimeUnixNano,
+		Attributes:        attributes,
+		Events:            span.Events,
+		Links:             span.Links,
+		Status:            span.Status,
+	}
+}
+
+// instrumentationScope returns the instrumentation scope recorded in a trace document
+func instrumentationScope(source map[string]interface{}) *commonpb.InstrumentationScope {
+	scope, ok := source["instrumentationScope"].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	name, _ := scope["name"].(string)
+	version, _ := scope["version"].(string)
+	return &commonpb.InstrumentationScope{Name: name, Version: version}
+}
+
+// resourceKey identifies the resource and scope of a span so that spans sharing them are exported together
+func resourceKey(resource map[string]interface{}, scope *commonpb.InstrumentationScope) string {
+	encoded, _ := json.Marshal(resource) // Map keys are sorted, equal resources encode equally
+	if scope != nil {
+		return string(encoded) + "|" + scope.GetName() + "|" + scope.GetVersion()
+	}
+	return string(encoded)
+}
+
+// run batches queued spans and exports them until the exporter is stopped
+func (e *exporter) run() {
+	defer close(e.done)
+
+	ticker := time.NewTicker(e.cfg.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]queuedSpan, 0, e.cfg.BatchSize)
+	flush := func() {
+		if len(batch) > 0 {
+			e.export(batch)
+			batch = batch[:0]
+		}
+	}
+
+	for {
+		select {
+		case item := <-e.queue:
+			batch = append(batch, item)
+			if len(batch) >= e.cfg.BatchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		case <-e.stop:
+			for {
+				select {
+				case item := <-e.queue:
+					batch = append(batch, item)
+					if len(batch) >= e.cfg.BatchSize {
+						flush()
+					}
+				default:
+					flush()
+					return
+				}
+			}
+		}
+	}
+}
+
+// export posts a batch, failures are logged and counted
+func (e *exporter) export(batch []queuedSpan) {
+	body, err := otlp.EncodeMessage(buildRequest(batch), otlp.ContentTypeProtobuf)
+	if err != nil {
+		e.failed.Add(uint64(len(batch)))
+		slog.Error("Failed to encode forwarded spans", "destination", e.destination.Name, "error", err)
+		return
+	}
+	if e.destination.Compression == "gzip" {
+		if body, err = compress(body); err != nil {
+			e.failed.Add(uint64(len(batch)))
+			slog.Error("Failed to compress forwarded spans", "destination", e.destination.Name, "error", err)
+			return
+		}
+	}
+
+	if err := e.deliver(body); err != nil {
+		e.failed.Add(uint64(len(batch)))
+		slog.Error("Failed to forward spans", "destination", e.destination.Name, "spans", len(batch), "error", err)
+		return
+	}
+	e.exported.Add(uint64(len(batch)))
+}
+
+// buildRequest groups a batch into the resource spans of an export request
+func buildRequest(batch []queuedSpan) *coltracepb.ExportTraceServiceRequest {
+	request := &coltracepb.ExportTraceServiceRequest{}
+	groups := make(map[string]*tracepb.ScopeSpans)
+	for _, item := range batch {
+		scopeSpans, ok := groups[item.groupKey]
+		if !ok {
+			scopeSpans = &tracepb.ScopeSpans{Scope: item.scope}
+			groups[item.groupKey] = scopeSpans
+			request.ResourceSpans = append(request.ResourceSpans, &tracepb.ResourceSpans{
+				Resource:   &resourcepb.Resource{Attributes: otlp.MapToKeyValues(item.resource)},
+				ScopeSpans: []*tracepb.ScopeSpans{scopeSpans},
+			})
+		}
+		scopeSpans.Spans = append(scopeSpans.Spans, item.span)
+	}
+	return request
+}
+
+// compress gzips an export request body
+func compress(body []byte) ([]byte, error) {
+	var buffer bytes.Buffer
+	writer := gzip.NewWriter(&buffer)
+	if _, err := writer.Write(body); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// deliver posts an export request, retrying network errors, 429 and 5xx responses with exponential backoff
+func (e *exporter) deliver(body []byte) error {
+	backoff := e.cfg.RetryBackoff
+	var lastErr error
+	for attempt := 0; attempt <= e.cfg.MaxRetries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+
+		retry, err := e.post(body)
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+		if !retry {
+			break
+		}
+	}
+	return lastErr
+}
+
+// post sends a single export request and reports whether a failure is worth retrying
+func (e *exporter) post(body []byte) (bool, error) {
+	req, err := http.NewRequest(http.MethodPost, e.destination.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return false, fmt.Errorf("failed to build export request: %w", err)
+	}
+	req.Header.Set("Content-Type", otlp.ContentTypeProtobuf)
+	if e.destination.Compression == "gzip" {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+	for key, value := range e.destination.Headers {
+		req.Header.Set(key, value)
+	}
+
+	res, err := e.httpClient.Do(req)
+	if err != nil {
+		return true, fmt.Errorf("export request failed: %w", err)
+	}
+	res.Body.Close()
+
+	if res.StatusCode >= 200 && res.StatusCode < 300 {
+		return false, nil
+	}
+	retry := res.StatusCode == http.StatusTooManyRequests || res.StatusCode >= 500
+	return retry, fmt.Errorf("export request failed with status: %s", res.Status)
+}
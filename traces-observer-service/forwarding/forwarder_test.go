@@ -0,0 +1,250 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package forwarding
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/otlp"
+)
+
+// receiver is an OTLP/HTTP endpoint recording the export requests it accepts
+// The statuses are answered in order before requests are accepted
+type receiver struct {
+	mu       sync.Mutex
+	statuses []int
+	attempts int
+	headers  []http.Header
+	requests []*coltracepb.ExportTraceServiceRequest
+}
+
+func (r *receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.attempts++
+	if len(r.statuses) > 0 {
+		status := r.statuses[0]
+		r.statuses = r.statuses[1:]
+		w.WriteHeader(status)
+		return
+	}
+
+	body, err := otlp.ReadBody(req.Body, req.Header.Get("Content-Encoding"), 1<<20)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	request, err := otlp.DecodeRequest(body, req.Header.Get("Content-Type"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	r.headers = append(r.headers, req.Header.Clone())
+	r.requests = append(r.requests, request)
+}
+
+// spans returns the attribute keys of every received span by span name
+func (r *receiver) spans() map[string][]string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	spans := map[string][]string{}
+	for _, request := range r.requests {
+		for _, resourceSpans := range request.ResourceSpans {
+			for _, scopeSpans := range resourceSpans.ScopeSpans {
+				for _, span := range scopeSpans.Spans {
+					keys := []string{}
+					for _, attribute := range span.Attributes {
+						keys = append(keys, attribute.Key)
+					}
+					spans[span.Name] = keys
+				}
+			}
+		}
+	}
+	return spans
+}
+
+// newReceiver starts a receiver and returns its endpoint
+func newReceiver(t *testing.T, statuses ...int) (*receiver, string) {
+	t.Helper()
+	r := &receiver{statuses: statuses}
+	server := httptest.NewServer(r)
+	t.Cleanup(server.Close)
+	return r, server.URL + "/v1/traces"
+}
+
+// testConfig batches until the forwarder is closed and retries without waiting
+var testConfig = Config{BatchSize: 100, FlushInterval: time.Hour, MaxRetries: 2, RetryBackoff: time.Millisecond}
+
+func TestForwarderExportsToEveryDestination(t *testing.T) {
+	full, fullEndpoint := newReceiver(t)
+	filtered, filteredEndpoint := newReceiver(t)
+	disabled, disabledEndpoint := newReceiver(t)
+	off := false
+	forwarder := NewForwarder(testConfig, []Destination{
+		{Name: "full", Endpoint: fullEndpoint, Compression: "gzip", Headers: map[string]string{"Authorization": "Bearer secret"}},
+		{Name: "filtered", Endpoint: filteredEndpoint, AttributeAllowlist: []string{"amp.*"}},
+		{Name: "disabled", Endpoint: disabledEndpoint, Enabled: &off},
+	}, nil)
+
+	forwarder.Forward(testSource(t, "b7ad6b7169203331"), testSpan())
+	forwarder.Forward(testSource(t, "c7ad6b7169203331"), testSpan())
+	forwarder.Close(context.Background())
+
+	if len(full.requests) != 1 || len(full.requests[0].ResourceSpans) != 1 || len(full.requests[0].ResourceSpans[0].ScopeSpans[0].Spans) != 2 {
+		t.Fatalf("requests = %v, want both spans in one resource", full.requests)
+	}
+	scope := full.requests[0].ResourceSpans[0].ScopeSpans[0].Scope
+	if scope.GetName() != "openinference" || scope.GetVersion() != "1.0.0" {
+		t.Errorf("scope = %v, want the instrumentation scope of the documents", scope)
+	}
+	headers := full.headers[0]
+	if headers.Get("Content-Encoding") != "gzip" || headers.Get("Content-Type") != otlp.ContentTypeProtobuf || headers.Get("Authorization") != "Bearer secret" {
+		t.Errorf("headers = %v, want a gzip protobuf request with the destination headers", headers)
+	}
+	if keys := full.spans()["chat gpt-4o"]; !contains(keys, "secret.key") || !contains(keys, AttributeKind) {
+		t.Errorf("attributes = %v, want every attribute", keys)
+	}
+
+	if keys := filtered.spans()["chat gpt-4o"]; contains(keys, "secret.key") || contains(keys, "gen_ai.request.model") || !contains(keys, AttributeKind) {
+		t.Errorf("attributes = %v, want only the allowed attributes", keys)
+	}
+	if filtered.headers[0].Get("Content-Encoding") != "" {
+		t.Errorf("Content-Encoding = %q, want an uncompressed request", filtered.headers[0].Get("Content-Encoding"))
+	}
+
+	if disabled.attempts != 0 {
+		t.Errorf("disabled destination received %d requests", disabled.attempts)
+	}
+	stats := forwarder.Stats()
+	if len(stats) != 2 || stats[0].Name != "full" || stats[0].Exported != 2 || stats[1].Exported != 2 {
+		t.Errorf("stats = %+v, want 2 spans exported to each enabled destination", stats)
+	}
+}
+
+func TestForwarderRetries(t *testing.T) {
+	tests := []struct {
+		name         string
+		statuses     []int
+		wantAttempts int
+		wantExported uint64
+		wantFailed   uint64
+	}{
+		{name: "recovers after unavailable", statuses: []int{http.StatusServiceUnavailable, http.StatusTooManyRequests}, wantAttempts: 3, wantExported: 1},
+		{name: "gives up after retries", statuses: []int{http.StatusBadGateway, http.StatusBadGateway, http.StatusBadGateway}, wantAttempts: 3, wantFailed: 1},
+		{name: "rejection is not retried", statuses: []int{http.StatusBadRequest}, wantAttempts: 1, wantFailed: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, endpoint := newReceiver(t, tt.statuses...)
+			forwarder := NewForwarder(testConfig, []Destination{{Name: "collector", Endpoint: endpoint}}, nil)
+
+			forwarder.Forward(testSource(t, "b7ad6b7169203331"), testSpan())
+			forwarder.Close(context.Background())
+
+			if r.attempts != tt.wantAttempts {
+				t.Errorf("attempts = %d, want %d", r.attempts, tt.wantAttempts)
+			}
+			stats := forwarder.Stats()[0]
+			if stats.Exported != tt.wantExported || stats.Failed != tt.wantFailed {
+				t.Errorf("stats = %+v, want %d exported and %d failed", stats, tt.wantExported, tt.wantFailed)
+			}
+		})
+	}
+}
+
+func TestForwarderFlushesFullBatches(t *testing.T) {
+	r, endpoint := newReceiver(t)
+	cfg := testConfig
+	cfg.BatchSize = 2
+	forwarder := NewForwarder(cfg, []Destination{{Name: "collector", Endpoint: endpoint}}, nil)
+	defer forwarder.Close(context.Background())
+
+	forwarder.Forward(testSource(t, "b7ad6b7169203331"), testSpan())
+	forwarder.Forward(testSource(t, "c7ad6b7169203331"), testSpan())
+
+	deadline := time.Now().Add(5 * time.Second)
+	for forwarder.Stats()[0].Exported != 2 {
+		if time.Now().After(deadline) {
+			t.Fatalf("stats = %+v, want the full batch exported before the flush interval", forwarder.Stats())
+		}
+		time.Sleep(time.Millisecond)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.requests) != 1 {
+		t.Errorf("requests = %d, want 1", len(r.requests))
+	}
+}
+
+func TestForwarderDropsSpansWhenQueueIsFull(t *testing.T) {
+	stop := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { <-stop }))
+	defer server.Close()
+	defer close(stop)
+
+	cfg := testConfig
+	cfg.BatchSize = 1
+	cfg.QueueSize = 1
+	forwarder := NewForwarder(cfg, []Destination{{Name: "collector", Endpoint: server.URL}}, nil)
+
+	// The first span blocks the exporter, the second fills the queue
+	forwarder.Forward(testSource(t, "b7ad6b7169203331"), testSpan())
+	deadline := time.Now().Add(5 * time.Second)
+	for forwarder.Stats()[0].Queued != 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("the exporter did not take the first span")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	forwarder.Forward(testSource(t, "c7ad6b7169203331"), testSpan())
+	forwarder.Forward(testSource(t, "d7ad6b7169203331"), testSpan())
+
+	if stats := forwarder.Stats()[0]; stats.Queued != 1 || stats.Dropped != 1 {
+		t.Errorf("stats = %+v, want 1 queued and 1 dropped", stats)
+	}
+}
+
+func TestDisabledForwarder(t *testing.T) {
+	var forwarder *Forwarder
+	if forwarder.Enabled() || forwarder.Stats() != nil {
+		t.Error("a nil forwarder must be disabled")
+	}
+	forwarder.Forward(nil, testSpan())
+	forwarder.Close(context.Background())
+
+	off := false
+	if NewForwarder(testConfig, []Destination{{Name: "off", Endpoint: "http://collector:4318", Enabled: &off}}, nil).Enabled() {
+		t.Error("Enabled() = true, want false without enabled destinations")
+	}
+}
+
+func contains(values []string, want string) bool {
+	for _, value := range values {
+		if value == want {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,165 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/controllers"
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/middleware/logger"
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/notifications"
+)
+
+// alertRuleRequest is the body of an alert rule create or update request
+type alertRuleRequest struct {
+	Name             string   `json:"name"`
+	Enabled          *bool    `json:"enabled"` // Defaults to true
+	Metric           string   `json:"metric"`
+	WindowMinutes    int      `json:"windowMinutes"`
+	GroupBy          string   `json:"groupBy"`
+	Agent            string   `json:"agent"`
+	ComponentUid     string   `json:"componentUid"`
+	EnvironmentUid   string   `json:"environmentUid"`
+	Comparison       string   `json:"comparison"`
+	Threshold        float64  `json:"threshold"`
+	ResolveThreshold *float64 `json:"resolveThreshold"`
+	Evaluations      int      `json:"evaluations"`
+	MinTraceCount    int      `json:"minTraceCount"`
+	WebhookURL       string   `json:"webhookUrl"`
+	Secret           string   `json:"secret"`
+}
+
+// SetAlerts enables the alert rule and alert endpoints
+func (h *Handler) SetAlerts(alerts *controllers.AlertController) {
+	h.alerts = alerts
+}
+
+// decodeAlertRule decodes an alert rule request body
+// Writes a bad request response and returns false when the body is invalid
+func (h *Handler) decodeAlertRule(w http.ResponseWriter, r *http.Request) (notifications.AlertRule, bool) {
+	var body alertRuleRequest
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRuleBodyBytes))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&body); err != nil {
+		h.writeBodyError(w, err, "Invalid alert rule body")
+		return notifications.AlertRule{}, false
+	}
+
+	rule := notifications.AlertRule{
+		Name:             body.Name,
+		Enabled:          body.Enabled == nil || *body.Enabled,
+		Metric:           body.Metric,
+		WindowMinutes:    body.WindowMinutes,
+		GroupBy:          body.GroupBy,
+		Agent:            body.Agent,
+		ComponentUid:     body.ComponentUid,
+		EnvironmentUid:   body.EnvironmentUid,
+		Comparison:       body.Comparison,
+		Threshold:        body.Threshold,
+		ResolveThreshold: body.ResolveThreshold,
+		Evaluations:      body.Evaluations,
+		MinTraceCount:    body.MinTraceCount,
+		WebhookURL:       body.WebhookURL,
+		Secret:           body.Secret,
+	}
+	return rule, true
+}
+
+// writeAlertRuleError writes the response of a failed alert rule operation
+func (h *Handler) writeAlertRuleError(w http.ResponseWriter, r *http.Request, err error, message string) {
+	switch {
+	case errors.Is(err, notifications.ErrInvalidAlertRule):
+		h.writeError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, controllers.ErrAlertRuleNotFound):
+		h.writeError(w, http.StatusNotFound, "Alert rule not found")
+	default:
+		logger.GetLogger(r.Context()).Error(message, "error", err)
+		h.writeError(w, http.StatusInternalServerError, message)
+	}
+}
+
+// ListAlertRules handles GET /api/v1/alerts/rules
+func (h *Handler) ListAlertRules(w http.ResponseWriter, r *http.Request) {
+	rules, err := h.alerts.ListRules(r.Context())
+	if err != nil {
+		h.writeAlertRuleError(w, r, err, "Failed to list alert rules")
+		return
+	}
+	h.writeJSON(w, http.StatusOK, map[string]interface{}{"rules": rules})
+}
+
+// CreateAlertRule handles POST /api/v1/alerts/rules
+func (h *Handler) CreateAlertRule(w http.ResponseWriter, r *http.Request) {
+	rule, ok := h.decodeAlertRule(w, r)
+	if !ok {
+		return
+	}
+
+	created, err := h.alerts.CreateRule(r.Context(), rule)
+	if err != nil {
+		h.writeAlertRuleError(w, r, err, "Failed to create alert rule")
+		return
+	}
+	h.writeJSON(w, http.StatusCreated, created)
+}
+
+// GetAlertRule handles GET /api/v1/alerts/rules/{ruleId}
+func (h *Handler) GetAlertRule(w http.ResponseWriter, r *http.Request) {
+	rule, err := h.alerts.GetRule(r.Context(), r.PathValue("ruleId"))
+	if err != nil {
+		h.writeAlertRuleError(w, r, err, "Failed to get alert rule")
+		return
+	}
+	h.writeJSON(w, http.StatusOK, rule)
+}
+
+// UpdateAlertRule handles PUT /api/v1/alerts/rules/{ruleId}
+func (h *Handler) UpdateAlertRule(w http.ResponseWriter, r *http.Request) {
+	rule, ok := h.decodeAlertRule(w, r)
+	if !ok {
+		return
+	}
+
+	updated, err := h.alerts.UpdateRule(r.Context(), r.PathValue("ruleId"), rule)
+	if err != nil {
+		h.writeAlertRuleError(w, r, err, "Failed to update alert rule")
+		return
+	}
+	h.writeJSON(w, http.StatusOK, updated)
+}
+
+// DeleteAlertRule handles DELETE /api/v1/alerts/rules/{ruleId}
+func (h *Handler) DeleteAlertRule(w http.ResponseWriter, r *http.Request) {
+	if err := h.alerts.DeleteRule(r.Context(), r.PathValue("ruleId")); err != nil {
+		h.writeAlertRuleError(w, r, err, "Failed to delete alert rule")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListAlerts handles GET /api/v1/alerts, returning the currently firing alerts
+func (h *Handler) ListAlerts(w http.ResponseWriter, r *http.Request) {
+	alerts, err := h.alerts.ListFiringAlerts(r.Context())
+	if err != nil {
+		logger.GetLogger(r.Context()).Error("Failed to list alerts", "error", err)
+		h.writeError(w, http.StatusInternalServerError, "Failed to list alerts")
+		return
+	}
+	h.writeJSON(w, http.StatusOK, map[string]interface{}{"alerts": alerts})
+}
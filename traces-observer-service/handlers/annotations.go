@@ -0,0 +1,118 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/controllers"
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/middleware/logger"
+)
+
+// maxAnnotationBodyBytes bounds the size of an annotation request body
+const maxAnnotationBodyBytes = 64 * 1024
+
+// createAnnotationRequest is the body of an annotation request
+type createAnnotationRequest struct {
+	Author  string `json:"author"`
+	Score   *int   `json:"score"`
+	Label   string `json:"label"`
+	Comment string `json:"comment"`
+}
+
+// CreateAnnotation handles POST /api/v1/traces/{traceId}/annotations
+func (h *Handler) CreateAnnotation(w http.ResponseWriter, r *http.Request) {
+	log := logger.GetLogger(r.Context())
+
+	traceID := r.PathValue("traceId")
+	if traceID == "" {
+		h.writeError(w, http.StatusBadRequest, "traceId is required")
+		return
+	}
+
+	var body createAnnotationRequest
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxAnnotationBodyBytes))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&body); err != nil {
+		h.writeBodyError(w, err, "Invalid annotation body")
+		return
+	}
+
+	annotation, err := h.controllers.CreateAnnotation(r.Context(), traceID, controllers.AnnotationInput{
+		Author:  body.Author,
+		Score:   body.Score,
+		Label:   body.Label,
+		Comment: body.Comment,
+	})
+	if err != nil {
+		if errors.Is(err, controllers.ErrInvalidAnnotation) {
+			h.writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		log.Error("Failed to create annotation", "traceId", traceID, "error", err)
+		h.writeError(w, http.StatusInternalServerError, "Failed to create annotation")
+		return
+	}
+
+	h.writeJSON(w, http.StatusCreated, annotation)
+}
+
+// GetAnnotations handles GET /api/v1/traces/{traceId}/annotations
+func (h *Handler) GetAnnotations(w http.ResponseWriter, r *http.Request) {
+	log := logger.GetLogger(r.Context())
+
+	traceID := r.PathValue("traceId")
+	if traceID == "" {
+		h.writeError(w, http.StatusBadRequest, "traceId is required")
+		return
+	}
+
+	result, err := h.controllers.GetAnnotations(r.Context(), traceID)
+	if err != nil {
+		log.Error("Failed to get annotations", "traceId", traceID, "error", err)
+		h.writeError(w, http.StatusInternalServerError, "Failed to retrieve annotations")
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, result)
+}
+
+// DeleteAnnotation handles DELETE /api/v1/traces/{traceId}/annotations/{annotationId}
+func (h *Handler) DeleteAnnotation(w http.ResponseWriter, r *http.Request) {
+	log := logger.GetLogger(r.Context())
+
+	traceID := r.PathValue("traceId")
+	annotationID := r.PathValue("annotationId")
+	if traceID == "" || annotationID == "" {
+		h.writeError(w, http.StatusBadRequest, "traceId and annotationId are required")
+		return
+	}
+
+	if err := h.controllers.DeleteAnnotation(r.Context(), traceID, annotationID); err != nil {
+		if errors.Is(err, controllers.ErrAnnotationNotFound) {
+			h.writeError(w, http.StatusNotFound, "Annotation not found")
+			return
+		}
+		log.Error("Failed to delete annotation", "traceId", traceID, "annotationId", annotationID, "error", err)
+		h.writeError(w, http.StatusInternalServerError, "Failed to delete annotation")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
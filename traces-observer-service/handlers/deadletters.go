@@ -0,0 +1,154 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/controllers"
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/middleware/logger"
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/opensearch"
+)
+
+// SetDeadLetters enables the dead-letter endpoints
+func (h *Handler) SetDeadLetters(deadLetters *controllers.DeadLetterController) {
+	h.deadLetters = deadLetters
+}
+
+// parseDeadLetterFilter parses the index, traceId, status, reason, startTime and endTime query parameters
+// Writes a bad request response and returns false when a parameter is invalid
+func (h *Handler) parseDeadLetterFilter(w http.ResponseWriter, query url.Values) (opensearch.DeadLetterFilter, bool) {
+	filter := opensearch.DeadLetterFilter{
+		Index:   query.Get("index"),
+		TraceID: query.Get("traceId"),
+		Reason:  query.Get("reason"),
+	}
+	if statusStr := query.Get("status"); statusStr != "" {
+		status, err := strconv.Atoi(statusStr)
+		if err != nil || status < 0 {
+			h.writeError(w, http.StatusBadRequest, "status must be an HTTP status code")
+			return filter, false
+		}
+		filter.Status = status
+	}
+	for _, param := range []struct {
+		name  string
+		value *time.Time
+	}{{"startTime", &filter.From}, {"endTime", &filter.To}} {
+		raw := query.Get(param.name)
+		if raw == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			h.writeError(w, http.StatusBadRequest, param.name+" must be in RFC3339 format")
+			return filter, false
+		}
+		*param.value = parsed
+	}
+	if !filter.From.IsZero() && !filter.To.IsZero() && filter.From.After(filter.To) {
+		h.writeError(w, http.StatusBadRequest, "startTime must be before endTime")
+		return filter, false
+	}
+	return filter, true
+}
+
+// ListDeadLetters handles GET /admin/deadletter, newest first
+func (h *Handler) ListDeadLetters(w http.ResponseWriter, r *http.Request) {
+	if !h.authorizeAdmin(w, r) {
+		return
+	}
+	query := r.URL.Query()
+	filter, ok := h.parseDeadLetterFilter(w, query)
+	if !ok {
+		return
+	}
+
+	limit := 20
+	if limitStr := query.Get("limit"); limitStr != "" {
+		parsedLimit, err := strconv.Atoi(limitStr)
+		if err != nil || parsedLimit <= 0 || parsedLimit > opensearch.MaxResultWindow {
+			h.writeError(w, http.StatusBadRequest, fmt.Sprintf("limit must be an integer between 1 and %d", opensearch.MaxResultWindow))
+			return
+		}
+		limit = parsedLimit
+	}
+	offset := 0
+	if offsetStr := query.Get("offset"); offsetStr != "" {
+		parsedOffset, err := strconv.Atoi(offsetStr)
+		if err != nil || parsedOffset < 0 || parsedOffset+limit > opensearch.MaxResultWindow {
+			h.writeError(w, http.StatusBadRequest, fmt.Sprintf("offset must be a non-negative integer, with limit at most %d", opensearch.MaxResultWindow))
+			return
+		}
+		offset = parsedOffset
+	}
+
+	list, err := h.deadLetters.List(r.Context(), filter, limit, offset)
+	if err != nil {
+		logger.GetLogger(r.Context()).Error("Failed to list dead letters", "error", err)
+		h.writeError(w, http.StatusInternalServerError, "Failed to list dead letters")
+		return
+	}
+	h.writeJSON(w, http.StatusOK, list)
+}
+
+// RetryDeadLetter handles POST /admin/deadletter/{id}/retry
+// Answers 200 once the document is indexed and 422 when it failed again
+func (h *Handler) RetryDeadLetter(w http.ResponseWriter, r *http.Request) {
+	if !h.authorizeAdmin(w, r) {
+		return
+	}
+	result, err := h.deadLetters.Retry(r.Context(), r.PathValue("id"))
+	if err != nil {
+		if errors.Is(err, controllers.ErrDeadLetterNotFound) {
+			h.writeError(w, http.StatusNotFound, "Dead letter not found")
+			return
+		}
+		logger.GetLogger(r.Context()).Error("Failed to retry dead letter", "error", err)
+		h.writeError(w, http.StatusInternalServerError, "Failed to retry dead letter")
+		return
+	}
+	if !result.Resolved {
+		h.writeJSON(w, http.StatusUnprocessableEntity, result)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, result)
+}
+
+// RetryDeadLetters handles POST /admin/deadletter/retryAll with the filter parameters of ListDeadLetters
+func (h *Handler) RetryDeadLetters(w http.ResponseWriter, r *http.Request) {
+	if !h.authorizeAdmin(w, r) {
+		return
+	}
+	filter, ok := h.parseDeadLetterFilter(w, r.URL.Query())
+	if !ok {
+		return
+	}
+
+	result, err := h.deadLetters.RetryAll(r.Context(), filter)
+	if err != nil {
+		logger.GetLogger(r.Context()).Error("Failed to retry dead letters", "error", err)
+		h.writeError(w, http.StatusInternalServerError, "Failed to retry dead letters")
+		return
+	}
+	h.writeJSON(w, http.StatusOK, result)
+}
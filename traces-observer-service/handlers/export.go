@@ -0,0 +1,270 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package handlers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/controllers"
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/middleware/logger"
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/opensearch"
+)
+
+// Trace export formats
+const (
+	ExportFormatCSV   = "csv"
+	ExportFormatJSONL = "jsonl"
+)
+
+// exportColumns are the CSV columns of a trace export
+var exportColumns = []string{
+	"traceId", "rootSpanId", "rootSpanName", "rootSpanKind", "startTime", "endTime", "durationInNanos",
+	"spanCount", "error", "inputTokens", "outputTokens", "totalTokens", "cost", "sessionId", "input", "output",
+}
+
+// unsafeFilenameChars matches characters not kept in export file names
+var unsafeFilenameChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// traceExportWriter writes exported traces in a download format
+type traceExportWriter interface {
+	WriteTrace(trace opensearch.TraceOverview) error
+	// Close writes the trailer describing truncation or a failure that interrupted the export
+	Close(result *controllers.TraceExportResult, exportErr error) error
+}
+
+// DownloadTraces handles GET /api/v1/traces/export
+// Accepts the trace list filters and streams the matching traces as CSV or JSON lines
+func (h *Handler) DownloadTraces(w http.ResponseWriter, r *http.Request) {
+	// Get logger from context
+	log := logger.GetLogger(r.Context())
+
+	// Parse query parameters
+	query := r.URL.Query()
+
+	format := query.Get("format")
+	if format == "" {
+		format = ExportFormatCSV
+	}
+	if format != ExportFormatCSV && format != ExportFormatJSONL {
+		h.writeError(w, http.StatusBadRequest, "format must be 'csv' or 'jsonl'")
+		return
+	}
+
+	params, ok := h.parseTraceFilters(w, query)
+	if !ok {
+		return
+	}
+	if params.StartTime == "" || params.EndTime == "" {
+		h.writeError(w, http.StatusBadRequest, "startTime and endTime are required")
+		return
+	}
+	params.SortBy = opensearch.TraceSortStartTime
+
+	// Exports outlive the server write timeout
+	if err := http.NewResponseController(w).SetWriteDeadline(time.Time{}); err != nil {
+		log.Warn("Failed to clear the write deadline of the export", "error", err)
+	}
+
+	// Exports of an agent's traces may span components, so they are named after the agent
+	subject := params.ComponentUid
+	if params.AgentID != "" {
+		subject = params.AgentID
+	}
+	filename := fmt.Sprintf("traces-%s-%s.%s",
+		unsafeFilenameChars.ReplaceAllString(subject, "_"),
+		time.Now().UTC().Format("20060102T150405Z"),
+		format)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+
+	var writer traceExportWriter
+	if format == ExportFormatJSONL {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		writer = newJSONLExportWriter(w)
+	} else {
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		writer = newCSVExportWriter(w)
+	}
+	w.WriteHeader(http.StatusOK)
+
+	// Each page is flushed so that the download starts before the export completes
+	flusher, _ := w.(http.Flusher)
+	result, err := h.controllers.ExportTraces(r.Context(), params, func(trace opensearch.TraceOverview) error {
+		if err := writer.WriteTrace(trace); err != nil {
+			return err
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+		return nil
+	})
+	if err != nil {
+		// The status has already been sent, so the failure is reported in the trailer
+		log.Error("Failed to export traces", "error", err)
+	}
+	if err := writer.Close(result, err); err != nil {
+		log.Error("Failed to write export trailer", "error", err)
+	}
+}
+
+// csvExportWriter writes traces as CSV rows with a header row
+// encoding/csv quotes fields containing commas, quotes and newlines
+type csvExportWriter struct {
+	out           io.Writer
+	writer        *csv.Writer
+	headerWritten bool
+}
+
+func newCSVExportWriter(w io.Writer) *csvExportWriter {
+	return &csvExportWriter{out: w, writer: csv.NewWriter(w)}
+}
+
+// WriteTrace writes a trace as a CSV row
+func (c *csvExportWriter) WriteTrace(trace opensearch.TraceOverview) error {
+	if err := c.writeHeader(); err != nil {
+		return err
+	}
+
+	var traceError bool
+	if trace.Status != nil {
+		traceError = trace.Status.HasError
+	}
+	var inputTokens, outputTokens, totalTokens int
+	var cost string
+	if trace.AggregatedUsage != nil {
+		inputTokens = trace.AggregatedUsage.InputTokens
+		outputTokens = trace.AggregatedUsage.OutputTokens
+		totalTokens = trace.AggregatedUsage.TotalTokens
+		if trace.AggregatedUsage.Cost != nil {
+			cost = strconv.FormatFloat(trace.AggregatedUsage.Cost.TotalCost, 'f', -1, 64)
+		}
+	}
+
+	input, err := exportValue(trace.Input)
+	if err != nil {
+		return err
+	}
+	output, err := exportValue(trace.Output)
+	if err != nil {
+		return err
+	}
+
+	if err := c.writer.Write([]string{
+		trace.TraceID,
+		trace.RootSpanID,
+		trace.RootSpanName,
+		trace.RootSpanKind,
+		trace.StartTime,
+		trace.EndTime,
+		strconv.FormatInt(trace.DurationInNanos, 10),
+		strconv.Itoa(trace.SpanCount),
+		strconv.FormatBool(traceError),
+		strconv.Itoa(inputTokens),
+		strconv.Itoa(outputTokens),
+		strconv.Itoa(totalTokens),
+		cost,
+		trace.SessionID,
+		input,
+		output,
+	}); err != nil {
+		return err
+	}
+	c.writer.Flush()
+	return c.writer.Error()
+}
+
+// Close writes the header of empty exports and a comment trailer when the export is incomplete
+func (c *csvExportWriter) Close(result *controllers.TraceExportResult, exportErr error) error {
+	if err := c.writeHeader(); err != nil {
+		return err
+	}
+	c.writer.Flush()
+	if err := c.writer.Error(); err != nil {
+		return err
+	}
+
+	// Trailer lines start with # so that notebooks can skip them as comments
+	switch {
+	case exportErr != nil:
+		return c.writeTrailer("# error: export failed, rows above are incomplete")
+	case result != nil && result.Truncated:
+		return c.writeTrailer(fmt.Sprintf("# truncated: export limited to %d rows", result.MaxRows))
+	}
+	return nil
+}
+
+// writeHeader writes the header row once
+func (c *csvExportWriter) writeHeader() error {
+	if c.headerWritten {
+		return nil
+	}
+	c.headerWritten = true
+	return c.writer.Write(exportColumns)
+}
+
+// writeTrailer writes a trailer line as is, since quoting it would hide the leading #
+func (c *csvExportWriter) writeTrailer(line string) error {
+	_, err := io.WriteString(c.out, line+"\n")
+	return err
+}
+
+// jsonlExportWriter writes traces as JSON lines
+type jsonlExportWriter struct {
+	encoder *json.Encoder
+}
+
+func newJSONLExportWriter(w io.Writer) *jsonlExportWriter {
+	return &jsonlExportWriter{encoder: json.NewEncoder(w)}
+}
+
+// WriteTrace writes a trace as a JSON line
+func (j *jsonlExportWriter) WriteTrace(trace opensearch.TraceOverview) error {
+	return j.encoder.Encode(trace)
+}
+
+// Close writes a trailer line when the export is incomplete
+func (j *jsonlExportWriter) Close(result *controllers.TraceExportResult, exportErr error) error {
+	switch {
+	case exportErr != nil:
+		return j.encoder.Encode(map[string]interface{}{"error": "export failed, lines above are incomplete"})
+	case result != nil && result.Truncated:
+		return j.encoder.Encode(map[string]interface{}{"truncated": true, "maxRows": result.MaxRows})
+	}
+	return nil
+}
+
+// exportValue renders an input or output value as a CSV field, encoding structured values as JSON
+func exportValue(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	default:
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return "", fmt.Errorf("failed to encode export value: %w", err)
+		}
+		return string(encoded), nil
+	}
+}
@@ -0,0 +1,166 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package handlers
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/controllers"
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/opensearch"
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/pricing"
+)
+
+func exportTestTraces() []opensearch.TraceOverview {
+	return []opensearch.TraceOverview{
+		{
+			TraceID:         "trace-1",
+			RootSpanID:      "root",
+			RootSpanName:    "answer",
+			RootSpanKind:    "agent",
+			StartTime:       "2026-10-16T10:00:00Z",
+			EndTime:         "2026-10-16T10:00:02Z",
+			DurationInNanos: 2000000000,
+			SpanCount:       3,
+			Status:          &opensearch.TraceStatus{ErrorCount: 1, HasError: true},
+			AggregatedUsage: &opensearch.TraceTokenUsage{
+				LLMTokenUsage: opensearch.LLMTokenUsage{InputTokens: 10, OutputTokens: 5, TotalTokens: 15},
+				Cost:          &pricing.Cost{TotalCost: 0.0125},
+			},
+			SessionID: "session-1",
+			Input:     "refund, \"order\" 42\nplease",
+			Output:    map[string]interface{}{"answer": "done"},
+		},
+		{TraceID: "trace-2", RootSpanID: "root", SpanCount: 1},
+	}
+}
+
+func TestCSVExportWriter(t *testing.T) {
+	var out bytes.Buffer
+	writer := newCSVExportWriter(&out)
+	for _, trace := range exportTestTraces() {
+		if err := writer.WriteTrace(trace); err != nil {
+			t.Fatalf("WriteTrace() error = %v", err)
+		}
+	}
+	if err := writer.Close(&controllers.TraceExportResult{Rows: 2}, nil); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	// Values with commas, quotes and newlines survive a round trip through a CSV reader
+	records, err := csv.NewReader(&out).ReadAll()
+	if err != nil {
+		t.Fatalf("export is not valid CSV: %v\n%s", err, out.String())
+	}
+	if len(records) != 3 || !reflect.DeepEqual(records[0], exportColumns) {
+		t.Fatalf("records = %q, want the header and 2 rows", records)
+	}
+	want := []string{"trace-1", "root", "answer", "agent", "2026-10-16T10:00:00Z", "2026-10-16T10:00:02Z", "2000000000",
+		"3", "true", "10", "5", "15", "0.0125", "session-1", "refund, \"order\" 42\nplease", `{"answer":"done"}`}
+	if !reflect.DeepEqual(records[1], want) {
+		t.Errorf("row = %q, want %q", records[1], want)
+	}
+	// Traces without usage or cost leave the cost empty
+	if row := records[2]; row[8] != "false" || row[9] != "0" || row[12] != "" || row[14] != "" {
+		t.Errorf("row = %q, want no error, usage, cost or input", row)
+	}
+}
+
+func TestCSVExportWriterTrailers(t *testing.T) {
+	tests := []struct {
+		name      string
+		result    *controllers.TraceExportResult
+		exportErr error
+		want      string
+	}{
+		{"empty export", &controllers.TraceExportResult{}, nil, strings.Join(exportColumns, ",") + "\n"},
+		{"truncated", &controllers.TraceExportResult{Truncated: true, MaxRows: 100}, nil, "# truncated: export limited to 100 rows\n"},
+		{"failed", nil, errors.New("opensearch unavailable"), "# error: export failed, rows above are incomplete\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			if err := newCSVExportWriter(&out).Close(tt.result, tt.exportErr); err != nil {
+				t.Fatalf("Close() error = %v", err)
+			}
+			if !strings.HasSuffix(out.String(), tt.want) {
+				t.Errorf("export = %q, want it to end with %q", out.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestJSONLExportWriter(t *testing.T) {
+	var out bytes.Buffer
+	writer := newJSONLExportWriter(&out)
+	for _, trace := range exportTestTraces() {
+		if err := writer.WriteTrace(trace); err != nil {
+			t.Fatalf("WriteTrace() error = %v", err)
+		}
+	}
+	if err := writer.Close(&controllers.TraceExportResult{Rows: 2, Truncated: true, MaxRows: 2}, nil); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("lines = %d, want 2 traces and the trailer", len(lines))
+	}
+	var trace opensearch.TraceOverview
+	if err := json.Unmarshal([]byte(lines[0]), &trace); err != nil || trace.TraceID != "trace-1" || trace.Input != "refund, \"order\" 42\nplease" {
+		t.Errorf("first line = %s, want trace-1", lines[0])
+	}
+	if lines[2] != `{"maxRows":2,"truncated":true}` {
+		t.Errorf("trailer = %s, want the truncation", lines[2])
+	}
+
+	out.Reset()
+	if err := newJSONLExportWriter(&out).Close(nil, errors.New("opensearch unavailable")); err != nil || !strings.Contains(out.String(), `"error"`) {
+		t.Errorf("trailer = %s, %v, want the failure", out.String(), err)
+	}
+}
+
+func TestDownloadTracesRejectsInvalidQueries(t *testing.T) {
+	h := NewHandler(nil, nil, nil)
+	scope := "componentUid=comp&environmentUid=env&"
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{"unknown format", scope + "format=xlsx&startTime=2025-11-03T00:00:00Z&endTime=2025-11-04T00:00:00Z", "format must be"},
+		{"missing component", "environmentUid=env&startTime=2025-11-03T00:00:00Z&endTime=2025-11-04T00:00:00Z", "componentUid is required"},
+		{"missing time range", scope + "format=jsonl", "startTime and endTime are required"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			h.DownloadTraces(w, httptest.NewRequest(http.MethodGet, "/api/v1/traces/export?"+tt.query, nil))
+
+			if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), tt.want) {
+				t.Errorf("response = %d %s, want 400 with %q", w.Code, w.Body, tt.want)
+			}
+		})
+	}
+}
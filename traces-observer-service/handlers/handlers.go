@@ -17,30 +17,66 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
+	"net/netip"
+	"net/url"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/wso2/ai-agent-management-platform/traces-observer-service/controllers"
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/middleware"
 	"github.com/wso2/ai-agent-management-platform/traces-observer-service/middleware/logger"
 	"github.com/wso2/ai-agent-management-platform/traces-observer-service/opensearch"
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/querycache"
 )
 
 // Handler handles HTTP requests for tracing
 type Handler struct {
-	controllers *controllers.TracingController
+	controllers   *controllers.TracingController
+	ingestion     *controllers.IngestionController
+	notifications *controllers.NotificationController // Nil when notifications are disabled
+	alerts        *controllers.AlertController        // Nil when alerting is disabled
+	healthStats   map[string]func() interface{}       // Counters of background workers reported by the health endpoint
+	readiness     *controllers.Readiness              // Nil when readiness is not tracked, the service is then always ready
+	reloader      ConfigReloader                      // Nil when the reload endpoint is not served
+	deadLetters   *controllers.DeadLetterController   // Nil when dead letters are not kept in OpenSearch
+	reprocess     *controllers.ReprocessController    // Nil when the reprocessing endpoints are not served
+	live          *controllers.LiveTraces             // Nil when the live trace stream is disabled
+	liveHeartbeat time.Duration                       // Interval of the comments keeping idle streams open
+	tails         *controllers.TraceTails             // Nil when live trace tails are disabled
+	tailOrigins   middleware.OriginMatcher            // Origins allowed to open a tail from a browser
+	tailIPHeader  string                              // Header carrying the client address when behind a proxy
+	tailProxies   []netip.Prefix                      // Proxies whose entries in tailIPHeader are trusted
+	retention     *opensearch.LifecycleManager        // Nil when index lifecycle management is disabled
+	adminKey      string
 }
 
 // NewHandler creates a new handler
-func NewHandler(controllers *controllers.TracingController) *Handler {
+func NewHandler(controllers *controllers.TracingController, ingestion *controllers.IngestionController, notifications *controllers.NotificationController) *Handler {
 	return &Handler{
-		controllers: controllers,
+		controllers:   controllers,
+		ingestion:     ingestion,
+		notifications: notifications,
+		healthStats:   map[string]func() interface{}{},
 	}
 }
 
+// AddHealthStats reports the counters of a background worker under name in the health response
+func (h *Handler) AddHealthStats(name string, stats func() interface{}) {
+	h.healthStats[name] = stats
+}
+
+// SetReadiness sets the readiness check reported by the readiness endpoint
+func (h *Handler) SetReadiness(readiness *controllers.Readiness) {
+	h.readiness = readiness
+}
+
 // TraceRequest represents the request body for getting traces
 type TraceRequest struct {
 	ComponentUid   string `json:"componentUid"`
@@ -66,7 +102,8 @@ type ErrorResponse struct {
 	Message string `json:"message"`
 }
 
-// GetTraceOverviews handles GET /api/traces with query parameters
+// GetTraceOverviews handles GET /api/v1/traces with query parameters
+// Supports filtering by framework, agent, status, duration and model, and cursor pagination
 func (h *Handler) GetTraceOverviews(w http.ResponseWriter, r *http.Request) {
 	// Get logger from context
 	log := logger.GetLogger(r.Context())
@@ -74,27 +111,17 @@ func (h *Handler) GetTraceOverviews(w http.ResponseWriter, r *http.Request) {
 	// Parse query parameters
 	query := r.URL.Query()
 
-	componentUid := query.Get("componentUid")
-	if componentUid == "" {
-		h.writeError(w, http.StatusBadRequest, "componentUid is required")
+	params, ok := h.parseTraceFilters(w, query)
+	if !ok {
 		return
 	}
 
-	environmentUid := query.Get("environmentUid")
-	if environmentUid == "" {
-		h.writeError(w, http.StatusBadRequest, "environmentUid is required")
-		return
-	}
-
-	startTime := query.Get("startTime")
-	endTime := query.Get("endTime")
-
 	// Parse limit (default: 10)
 	limit := 10
 	if limitStr := query.Get("limit"); limitStr != "" {
 		parsedLimit, err := strconv.Atoi(limitStr)
-		if err != nil || parsedLimit <= 0 {
-			h.writeError(w, http.StatusBadRequest, "limit must be a positive integer")
+		if err != nil || parsedLimit <= 0 || parsedLimit > opensearch.MaxResultWindow {
+			h.writeError(w, http.StatusBadRequest, fmt.Sprintf("limit must be an integer between 1 and %d", opensearch.MaxResultWindow))
 			return
 		}
 		limit = parsedLimit
@@ -111,31 +138,41 @@ func (h *Handler) GetTraceOverviews(w http.ResponseWriter, r *http.Request) {
 		offset = parsedOffset
 	}
 
-	// Parse sortOrder (default: desc for traces - newest first)
-	sortOrder := query.Get("sortOrder")
-	if sortOrder == "" {
-		sortOrder = "desc"
+	// Parse sort field (default: startTime)
+	sortBy := query.Get("sort")
+	if sortBy == "" {
+		sortBy = opensearch.TraceSortStartTime
 	}
-	if sortOrder != "asc" && sortOrder != "desc" {
-		h.writeError(w, http.StatusBadRequest, "sortOrder must be 'asc' or 'desc'")
+	if sortBy != opensearch.TraceSortStartTime && sortBy != opensearch.TraceSortDuration && sortBy != opensearch.TraceSortTokens {
+		h.writeError(w, http.StatusBadRequest, "sort must be 'startTime', 'duration' or 'tokens'")
 		return
 	}
 
-	// Build query parameters
-	params := opensearch.TraceQueryParams{
-		ComponentUid:   componentUid,
-		EnvironmentUid: environmentUid,
-		StartTime:      startTime,
-		EndTime:        endTime,
-		Limit:          limit,
-		Offset:         offset,
-		SortOrder:      sortOrder,
+	params.Limit = limit
+	params.Offset = offset
+	params.SortBy = sortBy
+
+	// Parse cursor from the previous page, which takes precedence over offset
+	if cursorStr := query.Get("cursor"); cursorStr != "" {
+		cursor, err := opensearch.DecodeCursor(cursorStr)
+		if err != nil {
+			h.writeError(w, http.StatusBadRequest, "cursor is invalid")
+			return
+		}
+		params.Cursor = cursor
+	} else if offset+limit > opensearch.MaxResultWindow {
+		h.writeError(w, http.StatusBadRequest, fmt.Sprintf("offset and limit reach at most %d traces, page further with the cursor", opensearch.MaxResultWindow))
+		return
 	}
 
 	// Execute query
 	ctx := r.Context()
 	result, err := h.controllers.GetTraceOverviews(ctx, params)
 	if err != nil {
+		if errors.Is(err, opensearch.ErrInvalidCursor) {
+			h.writeError(w, http.StatusBadRequest, "cursor is invalid")
+			return
+		}
 		log.Error("Failed to get trace overviews", "error", err)
 		h.writeError(w, http.StatusInternalServerError, "Failed to retrieve trace overviews")
 		return
@@ -145,6 +182,95 @@ func (h *Handler) GetTraceOverviews(w http.ResponseWriter, r *http.Request) {
 	h.writeJSON(w, http.StatusOK, result)
 }
 
+// parseTraceFilters parses the trace filters shared by the trace list and export endpoints
+// Writes a bad request response and returns false when a filter is invalid
+func (h *Handler) parseTraceFilters(w http.ResponseWriter, query url.Values) (opensearch.TraceQueryParams, bool) {
+	// The traces of a managed agent may span components and environments, so these are optional then
+	agentID := query.Get("agentId")
+
+	componentUid := query.Get("componentUid")
+	if componentUid == "" && agentID == "" {
+		h.writeError(w, http.StatusBadRequest, "componentUid is required")
+		return opensearch.TraceQueryParams{}, false
+	}
+
+	environmentUid := query.Get("environmentUid")
+	if environmentUid == "" && agentID == "" {
+		h.writeError(w, http.StatusBadRequest, "environmentUid is required")
+		return opensearch.TraceQueryParams{}, false
+	}
+
+	// Parse sortOrder (default: desc for traces - newest first)
+	sortOrder := query.Get("sortOrder")
+	if sortOrder == "" {
+		sortOrder = "desc"
+	}
+	if sortOrder != "asc" && sortOrder != "desc" {
+		h.writeError(w, http.StatusBadRequest, "sortOrder must be 'asc' or 'desc'")
+		return opensearch.TraceQueryParams{}, false
+	}
+
+	// Parse status filter
+	status := query.Get("status")
+	if status != "" && status != opensearch.TraceStatusOK && status != opensearch.TraceStatusError {
+		h.writeError(w, http.StatusBadRequest, "status must be 'ok' or 'error'")
+		return opensearch.TraceQueryParams{}, false
+	}
+
+	// Parse minimum duration filter (e.g. 500ms, 2s)
+	var minDuration time.Duration
+	if minDurationStr := query.Get("minDuration"); minDurationStr != "" {
+		parsedDuration, err := time.ParseDuration(minDurationStr)
+		if err != nil || parsedDuration < 0 {
+			h.writeError(w, http.StatusBadRequest, "minDuration must be a non-negative duration such as 500ms or 2s")
+			return opensearch.TraceQueryParams{}, false
+		}
+		minDuration = parsedDuration
+	}
+
+	// Parse guardrail violation filter
+	var hasGuardrailViolation *bool
+	if hasViolationStr := query.Get("hasGuardrailViolation"); hasViolationStr != "" {
+		hasViolation, err := strconv.ParseBool(hasViolationStr)
+		if err != nil {
+			h.writeError(w, http.StatusBadRequest, "hasGuardrailViolation must be 'true' or 'false'")
+			return opensearch.TraceQueryParams{}, false
+		}
+		hasGuardrailViolation = &hasViolation
+	}
+
+	// Parse custom attribute filters (customAttribute=tenant.id:acme, repeatable)
+	var customAttributes map[string]string
+	for _, filter := range query["customAttribute"] {
+		key, value, found := strings.Cut(filter, ":")
+		if !found || key == "" {
+			h.writeError(w, http.StatusBadRequest, "customAttribute must be in the form key:value")
+			return opensearch.TraceQueryParams{}, false
+		}
+		if customAttributes == nil {
+			customAttributes = map[string]string{}
+		}
+		customAttributes[key] = value
+	}
+
+	return opensearch.TraceQueryParams{
+		ComponentUid:          componentUid,
+		EnvironmentUid:        environmentUid,
+		StartTime:             query.Get("startTime"),
+		EndTime:               query.Get("endTime"),
+		SortOrder:             sortOrder,
+		Framework:             query.Get("framework"),
+		AgentName:             query.Get("agentName"),
+		Status:                status,
+		MinDurationNanos:      minDuration.Nanoseconds(),
+		Model:                 query.Get("model"),
+		AnnotationLabel:       query.Get("annotationLabel"),
+		HasGuardrailViolation: hasGuardrailViolation,
+		CustomAttributes:      customAttributes,
+		AgentID:               agentID,
+	}, true
+}
+
 // GetTraceByIdAndService handles GET /api/trace with query parameters
 func (h *Handler) GetTraceByIdAndService(w http.ResponseWriter, r *http.Request) {
 	// Get logger from context
@@ -220,6 +346,457 @@ func (h *Handler) GetTraceByIdAndService(w http.ResponseWriter, r *http.Request)
 	h.writeJSON(w, http.StatusOK, result)
 }
 
+// GetTraceTree handles GET /api/v1/traces/{traceId} and returns the assembled span tree
+func (h *Handler) GetTraceTree(w http.ResponseWriter, r *http.Request) {
+	// Get logger from context
+	log := logger.GetLogger(r.Context())
+
+	traceID := r.PathValue("traceId")
+	if traceID == "" {
+		h.writeError(w, http.StatusBadRequest, "traceId is required")
+		return
+	}
+
+	// Parse query parameters
+	query := r.URL.Query()
+
+	// Parse depth (default: 0, unlimited)
+	depth := 0
+	if depthStr := query.Get("depth"); depthStr != "" {
+		parsedDepth, err := strconv.Atoi(depthStr)
+		if err != nil || parsedDepth <= 0 {
+			h.writeError(w, http.StatusBadRequest, "depth must be a positive integer")
+			return
+		}
+		depth = parsedDepth
+	}
+
+	// Parse includeArchived (default: false)
+	includeArchived := false
+	if includeArchivedStr := query.Get("includeArchived"); includeArchivedStr != "" {
+		parsed, err := strconv.ParseBool(includeArchivedStr)
+		if err != nil {
+			h.writeError(w, http.StatusBadRequest, "includeArchived must be true or false")
+			return
+		}
+		includeArchived = parsed
+	}
+
+	params := opensearch.TraceTreeParams{
+		TraceID:         traceID,
+		ComponentUid:    query.Get("componentUid"),
+		EnvironmentUid:  query.Get("environmentUid"),
+		StartTime:       query.Get("startTime"),
+		EndTime:         query.Get("endTime"),
+		Depth:           depth,
+		IncludeArchived: includeArchived,
+	}
+
+	// Execute query
+	ctx := r.Context()
+	result, err := h.controllers.GetTraceTree(ctx, params)
+	if err != nil {
+		if errors.Is(err, controllers.ErrTraceNotFound) {
+			h.writeError(w, http.StatusNotFound, "Trace not found")
+			return
+		}
+		log.Error("Failed to get trace tree", "error", err)
+		h.writeError(w, http.StatusInternalServerError, "Failed to retrieve trace")
+		return
+	}
+
+	// Write response
+	h.writeJSON(w, http.StatusOK, result)
+}
+
+// CompareTraces handles GET /api/v1/traces/compare and aligns the spans of the left and right trace
+func (h *Handler) CompareTraces(w http.ResponseWriter, r *http.Request) {
+	// Get logger from context
+	log := logger.GetLogger(r.Context())
+
+	// Parse query parameters
+	query := r.URL.Query()
+	params := opensearch.TraceCompareParams{
+		LeftTraceID:    query.Get("left"),
+		RightTraceID:   query.Get("right"),
+		ComponentUid:   query.Get("componentUid"),
+		EnvironmentUid: query.Get("environmentUid"),
+		StartTime:      query.Get("startTime"),
+		EndTime:        query.Get("endTime"),
+	}
+	if params.LeftTraceID == "" || params.RightTraceID == "" {
+		h.writeError(w, http.StatusBadRequest, "left and right trace IDs are required")
+		return
+	}
+
+	// Execute query
+	ctx := r.Context()
+	result, err := h.controllers.CompareTraces(ctx, params)
+	if err != nil {
+		var notFound *controllers.TraceNotFoundError
+		if errors.As(err, &notFound) {
+			h.writeError(w, http.StatusNotFound, "Trace not found: "+notFound.TraceID)
+			return
+		}
+		log.Error("Failed to compare traces", "error", err)
+		h.writeError(w, http.StatusInternalServerError, "Failed to compare traces")
+		return
+	}
+
+	// Write response
+	h.writeJSON(w, http.StatusOK, result)
+}
+
+// GetReplayBundle handles GET /api/v1/traces/{traceId}/replay
+func (h *Handler) GetReplayBundle(w http.ResponseWriter, r *http.Request) {
+	// Get logger from context
+	log := logger.GetLogger(r.Context())
+
+	traceID := r.PathValue("traceId")
+	if traceID == "" {
+		h.writeError(w, http.StatusBadRequest, "traceId is required")
+		return
+	}
+
+	// Parse query parameters
+	query := r.URL.Query()
+	params := opensearch.TraceReplayParams{
+		TraceID:        traceID,
+		ComponentUid:   query.Get("componentUid"),
+		EnvironmentUid: query.Get("environmentUid"),
+		StartTime:      query.Get("startTime"),
+		EndTime:        query.Get("endTime"),
+	}
+
+	// Execute query
+	ctx := r.Context()
+	result, err := h.controllers.GetReplayBundle(ctx, params)
+	if err != nil {
+		if errors.Is(err, controllers.ErrTraceNotFound) {
+			h.writeError(w, http.StatusNotFound, "Trace not found")
+			return
+		}
+		log.Error("Failed to get replay bundle", "error", err)
+		h.writeError(w, http.StatusInternalServerError, "Failed to retrieve replay bundle")
+		return
+	}
+
+	// Write response
+	h.writeJSON(w, http.StatusOK, result)
+}
+
+// GetSessionTraces handles GET /api/v1/sessions/{sessionId}/traces
+func (h *Handler) GetSessionTraces(w http.ResponseWriter, r *http.Request) {
+	// Get logger from context
+	log := logger.GetLogger(r.Context())
+
+	sessionID := r.PathValue("sessionId")
+	if sessionID == "" {
+		h.writeError(w, http.StatusBadRequest, "sessionId is required")
+		return
+	}
+
+	// Parse query parameters
+	query := r.URL.Query()
+	params := opensearch.SessionTracesParams{
+		SessionID:      sessionID,
+		ComponentUid:   query.Get("componentUid"),
+		EnvironmentUid: query.Get("environmentUid"),
+		StartTime:      query.Get("startTime"),
+		EndTime:        query.Get("endTime"),
+	}
+
+	// Parse limit (default: 100)
+	if limitStr := query.Get("limit"); limitStr != "" {
+		parsedLimit, err := strconv.Atoi(limitStr)
+		if err != nil || parsedLimit <= 0 || parsedLimit > opensearch.MaxResultWindow {
+			h.writeError(w, http.StatusBadRequest, fmt.Sprintf("limit must be an integer between 1 and %d", opensearch.MaxResultWindow))
+			return
+		}
+		params.Limit = parsedLimit
+	}
+
+	// Parse cursor from the previous page
+	if cursorStr := query.Get("cursor"); cursorStr != "" {
+		cursor, err := opensearch.DecodeCursor(cursorStr)
+		if err != nil {
+			h.writeError(w, http.StatusBadRequest, "cursor is invalid")
+			return
+		}
+		params.Cursor = cursor
+	}
+
+	// Execute query
+	ctx := r.Context()
+	result, err := h.controllers.GetSessionTraces(ctx, params)
+	if err != nil {
+		if errors.Is(err, opensearch.ErrInvalidCursor) {
+			h.writeError(w, http.StatusBadRequest, "cursor is invalid")
+			return
+		}
+		log.Error("Failed to get session traces", "error", err)
+		h.writeError(w, http.StatusInternalServerError, "Failed to retrieve session traces")
+		return
+	}
+
+	// Write response
+	h.writeJSON(w, http.StatusOK, result)
+}
+
+// SearchSpans handles GET /api/v1/traces/search with a full-text query
+func (h *Handler) SearchSpans(w http.ResponseWriter, r *http.Request) {
+	// Get logger from context
+	log := logger.GetLogger(r.Context())
+
+	// Parse query parameters
+	query := r.URL.Query()
+
+	q := query.Get("q")
+	if q == "" {
+		h.writeError(w, http.StatusBadRequest, "q is required")
+		return
+	}
+
+	// Parse limit (default: 20)
+	limit := 20
+	if limitStr := query.Get("limit"); limitStr != "" {
+		parsedLimit, err := strconv.Atoi(limitStr)
+		if err != nil || parsedLimit <= 0 || parsedLimit > 100 {
+			h.writeError(w, http.StatusBadRequest, "limit must be an integer between 1 and 100")
+			return
+		}
+		limit = parsedLimit
+	}
+
+	// Parse offset for pagination (default: 0)
+	offset := 0
+	if offsetStr := query.Get("offset"); offsetStr != "" {
+		parsedOffset, err := strconv.Atoi(offsetStr)
+		if err != nil || parsedOffset < 0 {
+			h.writeError(w, http.StatusBadRequest, "offset must be a non-negative integer")
+			return
+		}
+		offset = parsedOffset
+	}
+
+	params := opensearch.SpanSearchParams{
+		Query:          q,
+		ComponentUid:   query.Get("componentUid"),
+		EnvironmentUid: query.Get("environmentUid"),
+		StartTime:      query.Get("startTime"),
+		EndTime:        query.Get("endTime"),
+		Limit:          limit,
+		Offset:         offset,
+	}
+
+	// Execute query
+	ctx := r.Context()
+	result, err := h.controllers.SearchSpans(ctx, params)
+	if err != nil {
+		log.Error("Failed to search spans", "error", err)
+		h.writeError(w, http.StatusInternalServerError, "Failed to search spans")
+		return
+	}
+
+	// Write response
+	h.writeJSON(w, http.StatusOK, result)
+}
+
+// GetTraceMetrics handles GET /api/v1/metrics/traces
+// Returns trace counts, duration percentiles, error rates, token usage and cost per time bucket
+func (h *Handler) GetTraceMetrics(w http.ResponseWriter, r *http.Request) {
+	// Get logger from context
+	log := logger.GetLogger(r.Context())
+
+	// Parse query parameters
+	query := r.URL.Query()
+
+	startTime, err := time.Parse(time.RFC3339, query.Get("startTime"))
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, "startTime is required in RFC3339 format")
+		return
+	}
+	endTime, err := time.Parse(time.RFC3339, query.Get("endTime"))
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, "endTime is required in RFC3339 format")
+		return
+	}
+	if startTime.After(endTime) {
+		h.writeError(w, http.StatusBadRequest, "startTime must be before endTime")
+		return
+	}
+
+	// Parse interval (default: 1h)
+	interval := query.Get("interval")
+	if interval == "" {
+		interval = "1h"
+	}
+	if _, ok := opensearch.MetricsIntervals[interval]; !ok {
+		h.writeError(w, http.StatusBadRequest, "interval must be '1m', '5m', '1h' or '1d'")
+		return
+	}
+
+	groupBy := query.Get("groupBy")
+	if groupBy != "" && !opensearch.IsValidMetricsGroupBy(groupBy) {
+		h.writeError(w, http.StatusBadRequest, "groupBy must be 'agent', 'framework', 'model' or 'label:<key>'")
+		return
+	}
+
+	// Parse summary, the metrics of the whole time range next to the series
+	var summary bool
+	if summaryStr := query.Get("summary"); summaryStr != "" {
+		parsedSummary, err := strconv.ParseBool(summaryStr)
+		if err != nil {
+			h.writeError(w, http.StatusBadRequest, "summary must be 'true' or 'false'")
+			return
+		}
+		summary = parsedSummary
+	}
+	if summary && groupBy != "" {
+		h.writeError(w, http.StatusBadRequest, "summary cannot be combined with groupBy")
+		return
+	}
+
+	params := opensearch.TraceMetricsParams{
+		ComponentUid:   query.Get("componentUid"),
+		EnvironmentUid: query.Get("environmentUid"),
+		StartTime:      startTime,
+		EndTime:        endTime,
+		Interval:       interval,
+		GroupBy:        groupBy,
+		AgentID:        query.Get("agentId"),
+		Summary:        summary,
+	}
+
+	// Execute query
+	ctx := queryCacheContext(r)
+	result, err := h.controllers.GetTraceMetrics(ctx, params)
+	if err != nil {
+		if errors.Is(err, controllers.ErrTooManyBuckets) {
+			h.writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		log.Error("Failed to get trace metrics", "error", err)
+		h.writeError(w, http.StatusInternalServerError, "Failed to retrieve trace metrics")
+		return
+	}
+
+	// Write response
+	h.writeJSON(w, http.StatusOK, result)
+}
+
+// GetToolMetrics handles GET /api/v1/metrics/tools
+// Returns invocation counts, failure counts, latency and the invoking agents per tool name
+func (h *Handler) GetToolMetrics(w http.ResponseWriter, r *http.Request) {
+	// Get logger from context
+	log := logger.GetLogger(r.Context())
+
+	// Parse query parameters
+	query := r.URL.Query()
+
+	startTime, err := time.Parse(time.RFC3339, query.Get("startTime"))
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, "startTime is required in RFC3339 format")
+		return
+	}
+	endTime, err := time.Parse(time.RFC3339, query.Get("endTime"))
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, "endTime is required in RFC3339 format")
+		return
+	}
+	if startTime.After(endTime) {
+		h.writeError(w, http.StatusBadRequest, "startTime must be before endTime")
+		return
+	}
+
+	// Parse sort order (default: invocations)
+	sortBy := query.Get("sort")
+	if sortBy == "" {
+		sortBy = opensearch.ToolSortInvocations
+	}
+	if sortBy != opensearch.ToolSortInvocations && sortBy != opensearch.ToolSortFailures && sortBy != opensearch.ToolSortLatency {
+		h.writeError(w, http.StatusBadRequest, "sort must be 'invocations', 'failures' or 'latency'")
+		return
+	}
+
+	// Parse limit (default: 100)
+	limit := 100
+	if limitStr := query.Get("limit"); limitStr != "" {
+		parsedLimit, err := strconv.Atoi(limitStr)
+		if err != nil || parsedLimit <= 0 || parsedLimit > opensearch.MaxToolMetricsTools {
+			h.writeError(w, http.StatusBadRequest, "limit must be an integer between 1 and 500")
+			return
+		}
+		limit = parsedLimit
+	}
+
+	params := opensearch.ToolMetricsParams{
+		ComponentUid:   query.Get("componentUid"),
+		EnvironmentUid: query.Get("environmentUid"),
+		StartTime:      startTime,
+		EndTime:        endTime,
+		Agent:          query.Get("agent"),
+		SortBy:         sortBy,
+		Limit:          limit,
+	}
+
+	// Execute query
+	ctx := queryCacheContext(r)
+	result, err := h.controllers.GetToolMetrics(ctx, params)
+	if err != nil {
+		log.Error("Failed to get tool metrics", "error", err)
+		h.writeError(w, http.StatusInternalServerError, "Failed to retrieve tool metrics")
+		return
+	}
+
+	// Write response
+	h.writeJSON(w, http.StatusOK, result)
+}
+
+// GetModelMetrics handles GET /api/v1/metrics/models
+// Returns request counts, token usage, cost and latency per served model
+func (h *Handler) GetModelMetrics(w http.ResponseWriter, r *http.Request) {
+	// Get logger from context
+	log := logger.GetLogger(r.Context())
+
+	// Parse query parameters
+	query := r.URL.Query()
+
+	startTime, err := time.Parse(time.RFC3339, query.Get("startTime"))
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, "startTime is required in RFC3339 format")
+		return
+	}
+	endTime, err := time.Parse(time.RFC3339, query.Get("endTime"))
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, "endTime is required in RFC3339 format")
+		return
+	}
+	if startTime.After(endTime) {
+		h.writeError(w, http.StatusBadRequest, "startTime must be before endTime")
+		return
+	}
+
+	params := opensearch.ModelMetricsParams{
+		ComponentUid:   query.Get("componentUid"),
+		EnvironmentUid: query.Get("environmentUid"),
+		StartTime:      startTime,
+		EndTime:        endTime,
+	}
+
+	// Execute query
+	ctx := queryCacheContext(r)
+	result, err := h.controllers.GetModelMetrics(ctx, params)
+	if err != nil {
+		log.Error("Failed to get model metrics", "error", err)
+		h.writeError(w, http.StatusInternalServerError, "Failed to retrieve model metrics")
+		return
+	}
+
+	// Write response
+	h.writeJSON(w, http.StatusOK, result)
+}
+
 // Health handles GET /health
 func (h *Handler) Health(w http.ResponseWriter, r *http.Request) {
 	// Get logger from context
@@ -235,10 +812,52 @@ func (h *Handler) Health(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	h.writeJSON(w, http.StatusOK, map[string]string{
-		"status":    "healthy",
-		"timestamp": time.Now().Format(time.RFC3339),
-	})
+	response := map[string]interface{}{
+		"status":     "healthy",
+		"timestamp":  time.Now().Format(time.RFC3339),
+		"opensearch": h.controllers.OpenSearchStats(),
+	}
+	if h.ingestion != nil {
+		response["indexer"] = h.ingestion.IndexerStats()
+		if samplerStats := h.ingestion.SamplerStats(); samplerStats != nil {
+			response["sampler"] = samplerStats
+		}
+		if processingStats := h.ingestion.ProcessingStats(); processingStats != nil {
+			response["processing"] = processingStats
+		}
+		if forwardingStats := h.ingestion.ForwardingStats(); forwardingStats != nil {
+			response["forwarding"] = forwardingStats
+		}
+	}
+	if h.notifications != nil {
+		response["notifications"] = h.notifications.Stats()
+	}
+	if h.alerts != nil {
+		response["alerts"] = h.alerts.Stats()
+	}
+	for name, stats := range h.healthStats {
+		response[name] = stats()
+	}
+	h.writeJSON(w, http.StatusOK, response)
+}
+
+// Healthz handles GET /healthz, reporting that the process is alive without checking its dependencies
+func (h *Handler) Healthz(w http.ResponseWriter, r *http.Request) {
+	h.writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// Readyz handles GET /readyz, reporting whether the service can accept traffic
+func (h *Handler) Readyz(w http.ResponseWriter, r *http.Request) {
+	if h.readiness != nil {
+		if err := h.readiness.Check(); err != nil {
+			h.writeJSON(w, http.StatusServiceUnavailable, map[string]string{
+				"status": "not ready",
+				"reason": err.Error(),
+			})
+			return
+		}
+	}
+	h.writeJSON(w, http.StatusOK, map[string]string{"status": "ready"})
 }
 
 // Helper functions
@@ -250,9 +869,45 @@ func (h *Handler) writeJSON(w http.ResponseWriter, status int, data interface{})
 	}
 }
 
+// queryCacheContext skips the query cache for requests sent with Cache-Control: no-cache, for debugging stale dashboards
+func queryCacheContext(r *http.Request) context.Context {
+	for _, directive := range strings.Split(r.Header.Get("Cache-Control"), ",") {
+		if strings.EqualFold(strings.TrimSpace(directive), "no-cache") {
+			return querycache.WithBypass(r.Context())
+		}
+	}
+	return r.Context()
+}
+
 func (h *Handler) writeError(w http.ResponseWriter, status int, message string) {
 	h.writeJSON(w, status, ErrorResponse{
 		Error:   "error",
 		Message: message,
 	})
 }
+
+// writeBodyError answers a request body that failed to decode, with 413 when it exceeded its size limit
+func (h *Handler) writeBodyError(w http.ResponseWriter, err error, message string) {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		h.writeError(w, http.StatusRequestEntityTooLarge, "Request body too large")
+		return
+	}
+	h.writeError(w, http.StatusBadRequest, message)
+}
+
+// OrgIDHeader names the organization a trace query is scoped to
+const OrgIDHeader = "X-Org-Id"
+
+// RequireOrg binds the request to the organization of the X-Org-Id header, so that it only reads and annotates
+// the traces of that organization
+func (h *Handler) RequireOrg(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		orgID := strings.TrimSpace(r.Header.Get(OrgIDHeader))
+		if orgID == "" {
+			h.writeError(w, http.StatusBadRequest, OrgIDHeader+" header is required")
+			return
+		}
+		next(w, r.WithContext(opensearch.WithOrgScope(r.Context(), orgID)))
+	}
+}
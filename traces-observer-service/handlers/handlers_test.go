@@ -0,0 +1,250 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/controllers"
+)
+
+func TestGetTraceTreeRejectsInvalidDepth(t *testing.T) {
+	h := NewHandler(nil, nil, nil)
+	for _, depth := range []string{"0", "-1", "two"} {
+		t.Run(depth, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/api/v1/traces/trace-1?depth="+depth, nil)
+			r.SetPathValue("traceId", "trace-1")
+			w := httptest.NewRecorder()
+
+			h.GetTraceTree(w, r)
+
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("status = %d, want %d", w.Code, http.StatusBadRequest)
+			}
+		})
+	}
+}
+
+func TestGetTraceTreeRejectsInvalidIncludeArchived(t *testing.T) {
+	h := NewHandler(nil, nil, nil)
+	r := httptest.NewRequest(http.MethodGet, "/api/v1/traces/trace-1?includeArchived=maybe", nil)
+	r.SetPathValue("traceId", "trace-1")
+	w := httptest.NewRecorder()
+
+	h.GetTraceTree(w, r)
+
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "includeArchived must be true or false") {
+		t.Errorf("response = %d %s, want 400 for an invalid includeArchived", w.Code, w.Body)
+	}
+}
+
+func TestGetTraceMetricsRejectsInvalidQueries(t *testing.T) {
+	h := NewHandler(controllers.NewTracingController(nil, nil, controllers.TracingOptions{MaxMetricsBuckets: 48}), nil, nil)
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{"missing start", "endTime=2025-11-03T00:00:00Z", "startTime is required"},
+		{"start after end", "startTime=2025-11-04T00:00:00Z&endTime=2025-11-03T00:00:00Z", "startTime must be before endTime"},
+		{"unknown interval", "startTime=2025-11-03T00:00:00Z&endTime=2025-11-04T00:00:00Z&interval=2h", "interval must be"},
+		{"unknown group", "startTime=2025-11-03T00:00:00Z&endTime=2025-11-04T00:00:00Z&groupBy=region", "groupBy must be"},
+		{"summary with groups", "startTime=2025-11-03T00:00:00Z&endTime=2025-11-04T00:00:00Z&groupBy=agent&summary=true", "summary cannot be combined"},
+		// Two days and the hour holding the end time make 49 hourly buckets
+		{"more buckets than allowed", "startTime=2025-11-03T00:00:00Z&endTime=2025-11-05T00:00:00Z&interval=1h", "49 buckets requested, at most 48 allowed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			h.GetTraceMetrics(w, httptest.NewRequest(http.MethodGet, "/api/v1/metrics/traces?"+tt.query, nil))
+
+			if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), tt.want) {
+				t.Errorf("response = %d %s, want 400 with %q", w.Code, w.Body, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetTraceOverviewsRejectsInvalidQueries(t *testing.T) {
+	h := NewHandler(nil, nil, nil)
+	scope := "componentUid=comp&environmentUid=env&"
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{"missing component", "environmentUid=env", "componentUid is required"},
+		{"missing environment", "componentUid=comp", "environmentUid is required"},
+		{"unknown sort order", scope + "sortOrder=newest", "sortOrder must be"},
+		{"unknown status", scope + "status=failed", "status must be"},
+		{"invalid duration", scope + "minDuration=2", "minDuration must be"},
+		{"negative duration", scope + "minDuration=-1s", "minDuration must be"},
+		{"zero limit", scope + "limit=0", "limit must be"},
+		{"negative offset", scope + "offset=-1", "offset must be"},
+		{"unknown sort", scope + "sort=name", "sort must be"},
+		{"invalid cursor", scope + "cursor=%25%25", "cursor is invalid"},
+		{"invalid guardrail filter", scope + "hasGuardrailViolation=yes", "hasGuardrailViolation must be"},
+		{"beyond the result window", scope + "offset=9990&limit=20", "page further with the cursor"},
+		{"custom attribute without a colon", scope + "customAttribute=tenant.id", "customAttribute must be in the form key:value"},
+		{"custom attribute with an empty key", scope + "customAttribute=:acme", "customAttribute must be in the form key:value"},
+		{"one malformed custom attribute among valid ones", scope + "customAttribute=tenant.id:acme&customAttribute=tier", "customAttribute must be in the form key:value"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			h.GetTraceOverviews(w, httptest.NewRequest(http.MethodGet, "/api/v1/traces?"+tt.query, nil))
+
+			if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), tt.want) {
+				t.Errorf("response = %d %s, want 400 with %q", w.Code, w.Body, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTraceFiltersCustomAttributes(t *testing.T) {
+	h := NewHandler(nil, nil, nil)
+	tests := []struct {
+		name    string
+		filters []string
+		want    map[string]string
+	}{
+		{"none", nil, nil},
+		{"single attribute", []string{"tenant.id:acme"}, map[string]string{"tenant.id": "acme"}},
+		{"value with colons", []string{"endpoint:http://api:8080"}, map[string]string{"endpoint": "http://api:8080"}},
+		{"empty value", []string{"tenant.id:"}, map[string]string{"tenant.id": ""}},
+		{"repeated attributes", []string{"tenant.id:acme", "tier:gold"}, map[string]string{"tenant.id": "acme", "tier": "gold"}},
+		{"last value of a repeated key wins", []string{"tier:silver", "tier:gold"}, map[string]string{"tier": "gold"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query := url.Values{"componentUid": {"comp"}, "environmentUid": {"env"}, "customAttribute": tt.filters}
+			w := httptest.NewRecorder()
+			params, ok := h.parseTraceFilters(w, query)
+			if !ok {
+				t.Fatalf("parseTraceFilters() rejected the query: %d %s", w.Code, w.Body)
+			}
+			if !reflect.DeepEqual(params.CustomAttributes, tt.want) {
+				t.Errorf("CustomAttributes = %v, want %v", params.CustomAttributes, tt.want)
+			}
+		})
+	}
+}
+
+func TestSearchSpansRejectsInvalidQueries(t *testing.T) {
+	h := NewHandler(nil, nil, nil)
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{"missing query", "limit=10", "q is required"},
+		{"limit too large", "q=refund&limit=101", "limit must be"},
+		{"negative offset", "q=refund&offset=-1", "offset must be"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			h.SearchSpans(w, httptest.NewRequest(http.MethodGet, "/api/v1/spans/search?"+tt.query, nil))
+
+			if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), tt.want) {
+				t.Errorf("response = %d %s, want 400 with %q", w.Code, w.Body, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetToolMetricsRejectsInvalidQueries(t *testing.T) {
+	h := NewHandler(nil, nil, nil)
+	window := "startTime=2025-11-03T00:00:00Z&endTime=2025-11-04T00:00:00Z&"
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{"missing start", "endTime=2025-11-03T00:00:00Z", "startTime is required"},
+		{"missing end", "startTime=2025-11-03T00:00:00Z", "endTime is required"},
+		{"start after end", "startTime=2025-11-04T00:00:00Z&endTime=2025-11-03T00:00:00Z", "startTime must be before endTime"},
+		{"unknown sort", window + "sort=name", "sort must be"},
+		{"limit too large", window + "limit=501", "limit must be"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			h.GetToolMetrics(w, httptest.NewRequest(http.MethodGet, "/api/v1/metrics/tools?"+tt.query, nil))
+
+			if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), tt.want) {
+				t.Errorf("response = %d %s, want 400 with %q", w.Code, w.Body, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetModelMetricsRejectsInvalidQueries(t *testing.T) {
+	h := NewHandler(nil, nil, nil)
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{"missing start", "endTime=2025-11-03T00:00:00Z", "startTime is required"},
+		{"invalid end", "startTime=2025-11-03T00:00:00Z&endTime=yesterday", "endTime is required"},
+		{"start after end", "startTime=2025-11-04T00:00:00Z&endTime=2025-11-03T00:00:00Z", "startTime must be before endTime"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			h.GetModelMetrics(w, httptest.NewRequest(http.MethodGet, "/api/v1/metrics/models?"+tt.query, nil))
+
+			if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), tt.want) {
+				t.Errorf("response = %d %s, want 400 with %q", w.Code, w.Body, tt.want)
+			}
+		})
+	}
+}
+
+func TestCreateAnnotationRejectsInvalidBodies(t *testing.T) {
+	h := NewHandler(controllers.NewTracingController(nil, nil, controllers.TracingOptions{}), nil, nil)
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+		want       string
+	}{
+		{"unknown field", `{"author":"alice","score":3,"rating":5}`, http.StatusBadRequest, "Invalid annotation body"},
+		{"invalid score", `{"author":"alice","score":9}`, http.StatusBadRequest, "score must be between 1 and 5"},
+		{"missing author", `{"label":"thumbs_up"}`, http.StatusBadRequest, "author is required"},
+		{"too large", `{"author":"alice","comment":"` + strings.Repeat("x", 70*1024) + `"}`, http.StatusRequestEntityTooLarge, "too large"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/api/v1/traces/trace-1/annotations", strings.NewReader(tt.body))
+			r.SetPathValue("traceId", "trace-1")
+			w := httptest.NewRecorder()
+
+			h.CreateAnnotation(w, r)
+
+			if w.Code != tt.wantStatus || !strings.Contains(w.Body.String(), tt.want) {
+				t.Errorf("response = %d %s, want %d with %q", w.Code, w.Body, tt.wantStatus, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,108 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/controllers"
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/middleware/logger"
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/opensearch"
+)
+
+// liveWriteTimeout bounds a single write to a stream, a client not reading for this long is disconnected
+const liveWriteTimeout = 30 * time.Second
+
+// SetLiveTraces sets the live trace stream and the interval of its heartbeat comments
+func (h *Handler) SetLiveTraces(live *controllers.LiveTraces, heartbeatInterval time.Duration) {
+	h.live = live
+	h.liveHeartbeat = heartbeatInterval
+}
+
+// StreamTraces handles GET /api/v1/traces/stream
+// Accepts the trace list filters and sends the overview of each matching trace as Server-Sent Events
+// when its root span is first seen, when it changes and when it is finalized
+func (h *Handler) StreamTraces(w http.ResponseWriter, r *http.Request) {
+	// Get logger from context
+	log := logger.GetLogger(r.Context())
+
+	params, ok := h.parseTraceFilters(w, r.URL.Query())
+	if !ok {
+		return
+	}
+	orgID, _ := opensearch.OrgScope(r.Context())
+	filter, err := controllers.NewLiveTraceFilter(orgID, params)
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	client, err := h.live.Subscribe(filter)
+	if err != nil {
+		if errors.Is(err, controllers.ErrTooManyLiveClients) || errors.Is(err, controllers.ErrLiveTracesClosed) {
+			h.writeError(w, http.StatusServiceUnavailable, "Live trace stream is not accepting clients, retry later")
+			return
+		}
+		log.Error("Failed to subscribe to live traces", "error", err)
+		h.writeError(w, http.StatusInternalServerError, "Failed to stream traces")
+		return
+	}
+	defer h.live.Unsubscribe(client)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	// Keeps nginx based proxies from buffering the stream
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	// Every write gets its own deadline, so that a client that stopped reading is let go
+	controller := http.NewResponseController(w)
+	send := func(frame string) bool {
+		_ = controller.SetWriteDeadline(time.Now().Add(liveWriteTimeout))
+		if _, err := fmt.Fprint(w, frame); err != nil {
+			return false
+		}
+		return controller.Flush() == nil
+	}
+	if !send(": connected\n\n") {
+		return
+	}
+
+	heartbeat := time.NewTicker(h.liveHeartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case event, ok := <-client.Events():
+			if !ok {
+				// Evicted for falling behind or the service is shutting down, the client reconnects
+				return
+			}
+			if !send(fmt.Sprintf("id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, event.Data)) {
+				return
+			}
+		case <-heartbeat.C:
+			if !send(": heartbeat\n\n") {
+				return
+			}
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,64 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/wso2/ai-agent-management-platform/libs/go-common/logging"
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/middleware/logger"
+)
+
+// maxLogLevelBodyBytes bounds the body of a log level change
+const maxLogLevelBodyBytes = 1 << 10
+
+// LogLevelRequest is the body of a log level change
+type LogLevelRequest struct {
+	Level string `json:"level"`
+}
+
+// LogLevelResponse reports the log level, and the level it replaced after a change
+type LogLevelResponse struct {
+	Level    string `json:"level"`
+	Previous string `json:"previous,omitempty"`
+}
+
+// GetLogLevel handles GET /admin/log-level
+func (h *Handler) GetLogLevel(w http.ResponseWriter, r *http.Request) {
+	h.writeJSON(w, http.StatusOK, LogLevelResponse{Level: logging.Level().String()})
+}
+
+// SetLogLevel handles PUT /admin/log-level, the level applies until the next change, SIGHUP or restart
+func (h *Handler) SetLogLevel(w http.ResponseWriter, r *http.Request) {
+	var body LogLevelRequest
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxLogLevelBodyBytes))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&body); err != nil {
+		h.writeBodyError(w, err, "Invalid log level body")
+		return
+	}
+	level, err := logging.ParseLevel(body.Level)
+	if err != nil || body.Level == "" {
+		h.writeError(w, http.StatusBadRequest, "level must be one of DEBUG, INFO, WARN or ERROR")
+		return
+	}
+
+	previous := logging.SetLevel(level)
+	logger.GetLogger(r.Context()).Warn("Log level changed", "level", level.String(), "previous", previous.String())
+	h.writeJSON(w, http.StatusOK, LogLevelResponse{Level: level.String(), Previous: previous.String()})
+}
@@ -0,0 +1,138 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/controllers"
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/middleware/logger"
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/notifications"
+)
+
+// maxRuleBodyBytes bounds the size of a notification rule request body
+const maxRuleBodyBytes = 64 * 1024
+
+// notificationRuleRequest is the body of a notification rule create or update request
+type notificationRuleRequest struct {
+	Name          string  `json:"name"`
+	Enabled       *bool   `json:"enabled"` // Defaults to true
+	OnError       bool    `json:"onError"`
+	MinDurationMs int64   `json:"minDurationMs"`
+	MinCost       float64 `json:"minCost"`
+	AgentPattern  string  `json:"agentPattern"`
+	WebhookURL    string  `json:"webhookUrl"`
+	Secret        string  `json:"secret"`
+}
+
+// decodeRule decodes a notification rule request body
+// Writes a bad request response and returns false when the body is invalid
+func (h *Handler) decodeRule(w http.ResponseWriter, r *http.Request) (notifications.Rule, bool) {
+	var body notificationRuleRequest
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRuleBodyBytes))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&body); err != nil {
+		h.writeBodyError(w, err, "Invalid notification rule body")
+		return notifications.Rule{}, false
+	}
+
+	rule := notifications.Rule{
+		Name:          body.Name,
+		Enabled:       body.Enabled == nil || *body.Enabled,
+		OnError:       body.OnError,
+		MinDurationMs: body.MinDurationMs,
+		MinCost:       body.MinCost,
+		AgentPattern:  body.AgentPattern,
+		WebhookURL:    body.WebhookURL,
+		Secret:        body.Secret,
+	}
+	return rule, true
+}
+
+// writeRuleError writes the response of a failed notification rule operation
+func (h *Handler) writeRuleError(w http.ResponseWriter, r *http.Request, err error, message string) {
+	switch {
+	case errors.Is(err, notifications.ErrInvalidRule):
+		h.writeError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, controllers.ErrRuleNotFound):
+		h.writeError(w, http.StatusNotFound, "Notification rule not found")
+	default:
+		logger.GetLogger(r.Context()).Error(message, "error", err)
+		h.writeError(w, http.StatusInternalServerError, message)
+	}
+}
+
+// ListNotificationRules handles GET /api/v1/notifications/rules
+func (h *Handler) ListNotificationRules(w http.ResponseWriter, r *http.Request) {
+	rules, err := h.notifications.ListRules(r.Context())
+	if err != nil {
+		h.writeRuleError(w, r, err, "Failed to list notification rules")
+		return
+	}
+	h.writeJSON(w, http.StatusOK, map[string]interface{}{"rules": rules})
+}
+
+// CreateNotificationRule handles POST /api/v1/notifications/rules
+func (h *Handler) CreateNotificationRule(w http.ResponseWriter, r *http.Request) {
+	rule, ok := h.decodeRule(w, r)
+	if !ok {
+		return
+	}
+
+	created, err := h.notifications.CreateRule(r.Context(), rule)
+	if err != nil {
+		h.writeRuleError(w, r, err, "Failed to create notification rule")
+		return
+	}
+	h.writeJSON(w, http.StatusCreated, created)
+}
+
+// GetNotificationRule handles GET /api/v1/notifications/rules/{ruleId}
+func (h *Handler) GetNotificationRule(w http.ResponseWriter, r *http.Request) {
+	rule, err := h.notifications.GetRule(r.Context(), r.PathValue("ruleId"))
+	if err != nil {
+		h.writeRuleError(w, r, err, "Failed to get notification rule")
+		return
+	}
+	h.writeJSON(w, http.StatusOK, rule)
+}
+
+// UpdateNotificationRule handles PUT /api/v1/notifications/rules/{ruleId}
+func (h *Handler) UpdateNotificationRule(w http.ResponseWriter, r *http.Request) {
+	rule, ok := h.decodeRule(w, r)
+	if !ok {
+		return
+	}
+
+	updated, err := h.notifications.UpdateRule(r.Context(), r.PathValue("ruleId"), rule)
+	if err != nil {
+		h.writeRuleError(w, r, err, "Failed to update notification rule")
+		return
+	}
+	h.writeJSON(w, http.StatusOK, updated)
+}
+
+// DeleteNotificationRule handles DELETE /api/v1/notifications/rules/{ruleId}
+func (h *Handler) DeleteNotificationRule(w http.ResponseWriter, r *http.Request) {
+	if err := h.notifications.DeleteRule(r.Context(), r.PathValue("ruleId")); err != nil {
+		h.writeRuleError(w, r, err, "Failed to delete notification rule")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,120 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"mime"
+	"net/http"
+	"strconv"
+	"time"
+
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	"google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/controllers"
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/middleware/logger"
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/otlp"
+)
+
+// ExportTraces handles POST /v1/traces (OTLP/HTTP)
+func (h *Handler) ExportTraces(w http.ResponseWriter, r *http.Request) {
+	log := logger.GetLogger(r.Context())
+
+	contentType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || (contentType != otlp.ContentTypeProtobuf && contentType != otlp.ContentTypeJSON) {
+		h.writeOTLPError(w, otlp.ContentTypeJSON, http.StatusUnsupportedMediaType, "unsupported content type, expected application/x-protobuf or application/json")
+		return
+	}
+
+	maxBytes := h.ingestion.MaxRequestBytes()
+	payload, err := otlp.ReadBody(http.MaxBytesReader(w, r.Body, int64(maxBytes)), r.Header.Get("Content-Encoding"), maxBytes)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.Is(err, otlp.ErrRequestTooLarge) || errors.As(err, &maxBytesErr) {
+			h.writeOTLPError(w, contentType, http.StatusRequestEntityTooLarge, "request body too large")
+			return
+		}
+		log.Warn("Failed to read export request", "error", err)
+		h.writeOTLPError(w, contentType, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	request, err := otlp.DecodeRequest(payload, contentType)
+	if err != nil {
+		log.Warn("Failed to decode export request", "error", err)
+		h.writeOTLPError(w, contentType, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx := r.Context()
+	if key := r.Header.Get(controllers.IngestKeyHeader); key != "" {
+		ctx = controllers.WithIngestKey(ctx, key)
+	}
+	result, err := h.ingestion.Export(ctx, request)
+	if errors.Is(err, controllers.ErrUnknownIngestKey) {
+		log.Warn("Rejected export request with an unknown ingest key")
+		h.writeOTLPError(w, contentType, http.StatusUnauthorized, err.Error())
+		return
+	}
+	if errors.Is(err, controllers.ErrIngestionBusy) {
+		// Exporters retry 429 responses after the advertised delay
+		log.Warn("Rejected export request, span processing queue is full")
+		w.Header().Set("Retry-After", strconv.Itoa(int(ingestionRetryDelay.Seconds())))
+		h.writeOTLPError(w, contentType, http.StatusTooManyRequests, err.Error())
+		return
+	}
+	if err != nil {
+		log.Error("Failed to export spans", "error", err)
+		h.writeOTLPError(w, contentType, http.StatusServiceUnavailable, "failed to queue spans")
+		return
+	}
+
+	response := &coltracepb.ExportTraceServiceResponse{}
+	if result.RejectedSpans > 0 {
+		response.PartialSuccess = &coltracepb.ExportTracePartialSuccess{
+			RejectedSpans: result.RejectedSpans,
+			ErrorMessage:  result.ErrorMessage,
+		}
+	}
+	h.writeOTLP(w, contentType, http.StatusOK, response)
+}
+
+// ingestionRetryDelay is the delay advertised to exporters whose request was rejected because the queue was full
+const ingestionRetryDelay = time.Second
+
+// writeOTLPError writes an OTLP error response as a google.rpc.Status message
+func (h *Handler) writeOTLPError(w http.ResponseWriter, contentType string, statusCode int, message string) {
+	h.writeOTLP(w, contentType, statusCode, &status.Status{Message: message})
+}
+
+// writeOTLP writes a protobuf message in the content type of the request
+func (h *Handler) writeOTLP(w http.ResponseWriter, contentType string, statusCode int, message proto.Message) {
+	body, err := otlp.EncodeMessage(message, contentType)
+	if err != nil {
+		slog.Error("Failed to encode OTLP response", "error", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(statusCode)
+	if _, err := w.Write(body); err != nil {
+		slog.Error("Failed to write OTLP response", "error", err)
+	}
+}
@@ -0,0 +1,128 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	_ "google.golang.org/grpc/encoding/gzip" // Registers the gzip compressor used by OTLP exporters
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"github.com/wso2/ai-agent-management-platform/libs/go-common/logging"
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/controllers"
+)
+
+// TraceServiceServer implements the OTLP/gRPC TraceService on top of the ingestion controller
+type TraceServiceServer struct {
+	coltracepb.UnimplementedTraceServiceServer
+	ingestion *controllers.IngestionController
+}
+
+// NewTraceServiceServer creates a new OTLP/gRPC trace service
+func NewTraceServiceServer(ingestion *controllers.IngestionController) *TraceServiceServer {
+	return &TraceServiceServer{
+		ingestion: ingestion,
+	}
+}
+
+// Export handles opentelemetry.proto.collector.trace.v1.TraceService/Export
+func (s *TraceServiceServer) Export(ctx context.Context, request *coltracepb.ExportTraceServiceRequest) (*coltracepb.ExportTraceServiceResponse, error) {
+	if keys := metadata.ValueFromIncomingContext(ctx, strings.ToLower(controllers.IngestKeyHeader)); len(keys) > 0 && keys[0] != "" {
+		ctx = controllers.WithIngestKey(ctx, keys[0])
+	}
+	ctx = grpcLogContext(ctx)
+	result, err := s.ingestion.Export(ctx, request)
+	if errors.Is(err, controllers.ErrUnknownIngestKey) {
+		slog.WarnContext(ctx, "Rejected export request with an unknown ingest key")
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+	if errors.Is(err, controllers.ErrIngestionBusy) {
+		// Exporters retry RESOURCE_EXHAUSTED only when the status carries the retry delay
+		slog.WarnContext(ctx, "Rejected export request, span processing queue is full")
+		busy, detailErr := status.New(codes.ResourceExhausted, err.Error()).WithDetails(&errdetails.RetryInfo{
+			RetryDelay: durationpb.New(ingestionRetryDelay),
+		})
+		if detailErr != nil {
+			return nil, status.Error(codes.ResourceExhausted, err.Error())
+		}
+		return nil, busy.Err()
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to export spans", "error", err)
+		return nil, status.Error(codes.Unavailable, "failed to queue spans")
+	}
+
+	response := &coltracepb.ExportTraceServiceResponse{}
+	if result.RejectedSpans > 0 {
+		response.PartialSuccess = &coltracepb.ExportTracePartialSuccess{
+			RejectedSpans: result.RejectedSpans,
+			ErrorMessage:  result.ErrorMessage,
+		}
+	}
+	return response, nil
+}
+
+// grpcLogContext adds the correlation id, trace id and trace context sent in the metadata of an export to its context,
+// so that the logs of the export carry them as the logs of HTTP exports do
+func grpcLogContext(ctx context.Context) context.Context {
+	var correlationID string
+	if ids := metadata.ValueFromIncomingContext(ctx, strings.ToLower(logging.CorrelationIDHeader)); len(ids) > 0 {
+		correlationID = ids[0]
+	}
+	ctx = logging.WithCorrelationID(ctx, logging.RequestCorrelationID(correlationID))
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		// The export span continues the trace of the exporter as HTTP exports do
+		ctx = otel.GetTextMapPropagator().Extract(ctx, metadataCarrier(md))
+	}
+	if parents := metadata.ValueFromIncomingContext(ctx, logging.TraceparentHeader); len(parents) > 0 {
+		if traceID, ok := logging.TraceIDFromTraceparent(parents[0]); ok {
+			ctx = logging.WithTraceID(ctx, traceID)
+		}
+	}
+	return ctx
+}
+
+// metadataCarrier reads the trace context from gRPC metadata, whose keys are lower case
+type metadataCarrier metadata.MD
+
+func (c metadataCarrier) Get(key string) string {
+	if values := metadata.MD(c).Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+func (c metadataCarrier) Set(key, value string) {
+	metadata.MD(c).Set(key, value)
+}
+
+func (c metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for key := range c {
+		keys = append(keys, key)
+	}
+	return keys
+}
@@ -0,0 +1,141 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package handlers
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/encoding/gzip"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/wso2/ai-agent-management-platform/libs/go-common/logging"
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/controllers"
+)
+
+// grpcExportRequest returns an export request of a valid span and a span with a zero trace id
+func grpcExportRequest() *coltracepb.ExportTraceServiceRequest {
+	return &coltracepb.ExportTraceServiceRequest{ResourceSpans: []*tracepb.ResourceSpans{{
+		ScopeSpans: []*tracepb.ScopeSpans{{Spans: []*tracepb.Span{
+			{TraceId: []byte("0123456789abcdef"), SpanId: []byte{1, 2, 3, 4, 5, 6, 7, 8}, Name: "valid", StartTimeUnixNano: 1, EndTimeUnixNano: 2},
+			{TraceId: make([]byte, 16), SpanId: []byte{1, 2, 3, 4, 5, 6, 7, 8}, Name: "zero trace id", StartTimeUnixNano: 1},
+		}}},
+	}}}
+}
+
+// dialTraceService serves the trace service over an in-memory connection and returns a client of it
+func dialTraceService(t *testing.T, orgs *controllers.OrgResolver) coltracepb.TraceServiceClient {
+	listener := bufconn.Listen(1024 * 1024)
+	server := grpc.NewServer()
+	ingestion := controllers.NewIngestionController(discardIndexer{}, nil, nil, nil, nil, nil, nil, orgs, nil, nil, 1024*1024, nil)
+	coltracepb.RegisterTraceServiceServer(server, NewTraceServiceServer(ingestion))
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return coltracepb.NewTraceServiceClient(conn)
+}
+
+func TestTraceServiceExport(t *testing.T) {
+	client := dialTraceService(t, nil)
+
+	// OTLP exporters compress with gzip by default
+	response, err := client.Export(context.Background(), grpcExportRequest(), grpc.UseCompressor(gzip.Name))
+	if err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+	partial := response.GetPartialSuccess()
+	if partial.GetRejectedSpans() != 1 || partial.GetErrorMessage() == "" {
+		t.Errorf("partial success = %v, want 1 rejected span with a message", partial)
+	}
+
+	request := grpcExportRequest()
+	request.ResourceSpans[0].ScopeSpans[0].Spans = request.ResourceSpans[0].ScopeSpans[0].Spans[:1]
+	response, err = client.Export(context.Background(), request)
+	if err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+	if response.GetPartialSuccess() != nil {
+		t.Errorf("partial success = %v, want none when every span is accepted", response.GetPartialSuccess())
+	}
+}
+
+func TestTraceServiceExportChecksIngestKeys(t *testing.T) {
+	client := dialTraceService(t, controllers.NewOrgResolver(map[string]string{"secret": "org-a"}, "amp.org.id", "default"))
+	request := grpcExportRequest()
+	request.ResourceSpans[0].ScopeSpans[0].Spans = request.ResourceSpans[0].ScopeSpans[0].Spans[:1]
+
+	tests := []struct {
+		name string
+		key  string
+		want codes.Code
+	}{
+		{name: "known key", key: "secret", want: codes.OK},
+		{name: "unknown key", key: "guessed", want: codes.Unauthenticated},
+		{name: "no key", want: codes.OK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.key != "" {
+				ctx = metadata.AppendToOutgoingContext(ctx, controllers.IngestKeyHeader, tt.key)
+			}
+			_, err := client.Export(ctx, request)
+			if got := status.Code(err); got != tt.want {
+				t.Errorf("Export() code = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGRPCLogContext(t *testing.T) {
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(
+		logging.CorrelationIDHeader, "export-42",
+		logging.TraceparentHeader, "00-"+traceID+"-00f067aa0ba902b7-01",
+	))
+	ctx = grpcLogContext(ctx)
+	if id, _ := logging.CorrelationID(ctx); id != "export-42" {
+		t.Errorf("correlation id = %q, want export-42", id)
+	}
+	if id, _ := logging.TraceID(ctx); id != traceID {
+		t.Errorf("trace id = %q, want %s", id, traceID)
+	}
+
+	// Exports without metadata get a generated correlation id and no trace id
+	ctx = grpcLogContext(context.Background())
+	if id, ok := logging.CorrelationID(ctx); !ok || len(id) != 32 {
+		t.Errorf("generated correlation id = %q", id)
+	}
+	if _, ok := logging.TraceID(ctx); ok {
+		t.Error("trace id set without a traceparent")
+	}
+}
@@ -0,0 +1,155 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/controllers"
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/opensearch"
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/otlp"
+)
+
+// discardIndexer accepts every document without storing it
+type discardIndexer struct{}
+
+func (discardIndexer) Add(_ context.Context, doc opensearch.BulkDocument) error {
+	if doc.OnDone != nil {
+		doc.OnDone(nil)
+	}
+	return nil
+}
+
+func (discardIndexer) Stats() opensearch.BulkIndexerStats {
+	return opensearch.BulkIndexerStats{}
+}
+
+func newOTLPHandler() *Handler {
+	return NewHandler(nil, controllers.NewIngestionController(discardIndexer{}, nil, nil, nil, nil, nil, nil, nil, nil, nil, 1024*1024, nil), nil)
+}
+
+// otlpJSONSpan returns an OTLP/JSON span with the given trace id
+func otlpJSONSpan(name, traceID string) string {
+	return `{"traceId":"` + traceID + `","spanId":"00f067aa0ba902b7","name":"` + name + `",` +
+		`"startTimeUnixNano":"1730960604000000000","endTimeUnixNano":"1730960605000000000"}`
+}
+
+func exportOTLP(h *Handler, contentType string, body []byte) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodPost, "/v1/traces", strings.NewReader(string(body)))
+	r.Header.Set("Content-Type", contentType)
+	w := httptest.NewRecorder()
+	h.ExportTraces(w, r)
+	return w
+}
+
+func TestExportTracesReportsPartialSuccess(t *testing.T) {
+	tests := []struct {
+		name         string
+		spans        []string
+		wantRejected int64
+	}{
+		{name: "all spans accepted", spans: []string{otlpJSONSpan("valid", "4bf92f3577b34da6a3ce929d0e0e4736")}},
+		{
+			name: "invalid spans are rejected individually",
+			spans: []string{
+				otlpJSONSpan("valid", "4bf92f3577b34da6a3ce929d0e0e4736"),
+				otlpJSONSpan("zero trace id", "00000000000000000000000000000000"),
+			},
+			wantRejected: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := `{"resourceSpans":[{"scopeSpans":[{"spans":[` + strings.Join(tt.spans, ",") + `]}]}]}`
+
+			w := exportOTLP(newOTLPHandler(), otlp.ContentTypeJSON, []byte(body))
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d, body = %s", w.Code, w.Body)
+			}
+			response := &coltracepb.ExportTraceServiceResponse{}
+			if err := protojson.Unmarshal(w.Body.Bytes(), response); err != nil {
+				t.Fatalf("response is not an OTLP/JSON export response: %v", err)
+			}
+			partial := response.GetPartialSuccess()
+			if tt.wantRejected == 0 {
+				if partial != nil {
+					t.Errorf("partial success = %v, want none when every span is accepted", partial)
+				}
+				return
+			}
+			if partial.GetRejectedSpans() != tt.wantRejected || !strings.Contains(partial.GetErrorMessage(), "invalid trace id") {
+				t.Errorf("partial success = %v, want %d rejected spans with the first error", partial, tt.wantRejected)
+			}
+		})
+	}
+}
+
+func TestExportTracesAnswersInTheRequestEncoding(t *testing.T) {
+	request := &coltracepb.ExportTraceServiceRequest{ResourceSpans: []*tracepb.ResourceSpans{{
+		ScopeSpans: []*tracepb.ScopeSpans{{Spans: []*tracepb.Span{{
+			TraceId: make([]byte, 16), SpanId: []byte{1, 2, 3, 4, 5, 6, 7, 8}, Name: "zero trace id", StartTimeUnixNano: 1,
+		}}}},
+	}}}
+	body, err := proto.Marshal(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w := exportOTLP(newOTLPHandler(), otlp.ContentTypeProtobuf, body)
+
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != otlp.ContentTypeProtobuf {
+		t.Fatalf("status = %d, content type = %q", w.Code, w.Header().Get("Content-Type"))
+	}
+	response := &coltracepb.ExportTraceServiceResponse{}
+	if err := proto.Unmarshal(w.Body.Bytes(), response); err != nil {
+		t.Fatalf("response is not a protobuf export response: %v", err)
+	}
+	if response.GetPartialSuccess().GetRejectedSpans() != 1 {
+		t.Errorf("partial success = %v, want 1 rejected span", response.GetPartialSuccess())
+	}
+}
+
+func TestExportTracesRejectsInvalidRequests(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		want        int
+	}{
+		{"unsupported content type", "text/plain", "spans", http.StatusUnsupportedMediaType},
+		{"malformed JSON", otlp.ContentTypeJSON, `{"resourceSpans":`, http.StatusBadRequest},
+		{"invalid hex id", otlp.ContentTypeJSON, `{"resourceSpans":[{"scopeSpans":[{"spans":[{"traceId":"xyz"}]}]}]}`, http.StatusBadRequest},
+		{"body over the limit", otlp.ContentTypeJSON, strings.Repeat(" ", 1024*1024+1), http.StatusRequestEntityTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if w := exportOTLP(newOTLPHandler(), tt.contentType, []byte(tt.body)); w.Code != tt.want {
+				t.Errorf("status = %d, want %d", w.Code, tt.want)
+			}
+		})
+	}
+}
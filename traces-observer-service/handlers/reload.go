@@ -0,0 +1,66 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package handlers
+
+import (
+	"crypto/subtle"
+	"net/http"
+
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/reload"
+)
+
+// AdminKeyHeader carries the key of the admin endpoints that reload the configuration and retry dead letters
+const AdminKeyHeader = "X-API-Key"
+
+// ConfigReloader applies changed settings without a restart, implemented by reload.Manager
+type ConfigReloader interface {
+	Reload(reason string) reload.Result
+}
+
+// SetAdminKey sets the key that requests to the admin endpoints must carry in the AdminKeyHeader
+func (h *Handler) SetAdminKey(adminKey string) {
+	h.adminKey = adminKey
+}
+
+// SetConfigReloader sets the reloader of the reload endpoint
+func (h *Handler) SetConfigReloader(reloader ConfigReloader) {
+	h.reloader = reloader
+}
+
+// authorizeAdmin checks the admin key of a request
+// Writes an unauthorized response and returns false when the key is missing or wrong
+func (h *Handler) authorizeAdmin(w http.ResponseWriter, r *http.Request) bool {
+	key := r.Header.Get(AdminKeyHeader)
+	if h.adminKey == "" || subtle.ConstantTimeCompare([]byte(key), []byte(h.adminKey)) != 1 {
+		h.writeError(w, http.StatusUnauthorized, "a valid "+AdminKeyHeader+" header is required")
+		return false
+	}
+	return true
+}
+
+// ReloadConfig handles POST /admin/reload, 422 reports the errors of a configuration that was rejected
+func (h *Handler) ReloadConfig(w http.ResponseWriter, r *http.Request) {
+	if !h.authorizeAdmin(w, r) {
+		return
+	}
+	result := h.reloader.Reload("admin request")
+	if !result.Applied {
+		h.writeJSON(w, http.StatusUnprocessableEntity, result)
+		return
+	}
+	h.writeJSON(w, http.StatusOK, result)
+}
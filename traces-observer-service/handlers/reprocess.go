@@ -0,0 +1,117 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/controllers"
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/middleware/logger"
+)
+
+// maxReprocessBodyBytes bounds the body of a reprocessing request
+const maxReprocessBodyBytes = 1 << 10
+
+type startReprocessRequest struct {
+	StartTime string `json:"startTime"`
+	EndTime   string `json:"endTime"`
+}
+
+// SetReprocess enables the reprocessing endpoints
+func (h *Handler) SetReprocess(reprocess *controllers.ReprocessController) {
+	h.reprocess = reprocess
+}
+
+// StartReprocess handles POST /admin/reprocess, answering 202 with the job started in the background
+func (h *Handler) StartReprocess(w http.ResponseWriter, r *http.Request) {
+	if !h.authorizeAdmin(w, r) {
+		return
+	}
+
+	var body startReprocessRequest
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxReprocessBodyBytes))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&body); err != nil {
+		h.writeBodyError(w, err, "Invalid reprocessing body")
+		return
+	}
+	start, err := time.Parse(time.RFC3339, body.StartTime)
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, "startTime must be in RFC3339 format")
+		return
+	}
+	end, err := time.Parse(time.RFC3339, body.EndTime)
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, "endTime must be in RFC3339 format")
+		return
+	}
+	if start.After(end) {
+		h.writeError(w, http.StatusBadRequest, "startTime must be before endTime")
+		return
+	}
+
+	job, err := h.reprocess.Start(start, end)
+	if err != nil {
+		if errors.Is(err, controllers.ErrReprocessRunning) {
+			h.writeError(w, http.StatusConflict, err.Error())
+			return
+		}
+		logger.GetLogger(r.Context()).Error("Failed to start reprocessing", "error", err)
+		h.writeError(w, http.StatusInternalServerError, "Failed to start reprocessing")
+		return
+	}
+	logger.GetLogger(r.Context()).Info("Started reprocessing", "jobId", job.ID, "startTime", job.StartTime, "endTime", job.EndTime)
+	h.writeJSON(w, http.StatusAccepted, job)
+}
+
+// ListReprocess handles GET /admin/reprocess, the recent jobs newest first
+func (h *Handler) ListReprocess(w http.ResponseWriter, r *http.Request) {
+	if !h.authorizeAdmin(w, r) {
+		return
+	}
+	h.writeJSON(w, http.StatusOK, map[string]interface{}{"jobs": h.reprocess.List()})
+}
+
+// GetReprocess handles GET /admin/reprocess/{id}
+func (h *Handler) GetReprocess(w http.ResponseWriter, r *http.Request) {
+	if !h.authorizeAdmin(w, r) {
+		return
+	}
+	job, err := h.reprocess.Get(r.PathValue("id"))
+	if err != nil {
+		h.writeError(w, http.StatusNotFound, "Reprocessing job not found")
+		return
+	}
+	h.writeJSON(w, http.StatusOK, job)
+}
+
+// CancelReprocess handles DELETE /admin/reprocess/{id}, answering once the job stopped
+// Cancelling a finished job reports it as it finished
+func (h *Handler) CancelReprocess(w http.ResponseWriter, r *http.Request) {
+	if !h.authorizeAdmin(w, r) {
+		return
+	}
+	job, err := h.reprocess.Cancel(r.PathValue("id"))
+	if err != nil {
+		h.writeError(w, http.StatusNotFound, "Reprocessing job not found")
+		return
+	}
+	h.writeJSON(w, http.StatusOK, job)
+}
@@ -0,0 +1,46 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/middleware/logger"
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/opensearch"
+)
+
+// SetRetention enables the retention report of the lifecycle manager
+func (h *Handler) SetRetention(lifecycle *opensearch.LifecycleManager) {
+	h.retention = lifecycle
+}
+
+// RetentionReport handles GET /admin/retention/report
+// Reports the indices and spans the next retention run would delete and the spans of each agent among them,
+// without deleting anything
+func (h *Handler) RetentionReport(w http.ResponseWriter, r *http.Request) {
+	if !h.authorizeAdmin(w, r) {
+		return
+	}
+
+	report, err := h.retention.RetentionReport(r.Context())
+	if err != nil {
+		logger.GetLogger(r.Context()).Error("Failed to build retention report", "error", err)
+		h.writeError(w, http.StatusInternalServerError, "Failed to build retention report")
+		return
+	}
+	h.writeJSON(w, http.StatusOK, report)
+}
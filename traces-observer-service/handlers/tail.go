@@ -0,0 +1,236 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/coder/websocket"
+
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/controllers"
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/middleware"
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/middleware/logger"
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/opensearch"
+)
+
+// tailMaxClientMessageBytes bounds the messages read from a tail client, which are discarded anyway
+const tailMaxClientMessageBytes = 64 << 10
+
+// SetTraceTails sets the live trace tails, the origins allowed to open them from a browser, the header carrying
+// the client address, empty to use the address of the connection, and the proxies trusted to set it
+func (h *Handler) SetTraceTails(tails *controllers.TraceTails, origins middleware.OriginMatcher, clientIPHeader string, trustedProxies []netip.Prefix) {
+	h.tails = tails
+	h.tailOrigins = origins
+	h.tailIPHeader = clientIPHeader
+	h.tailProxies = trustedProxies
+}
+
+// TailTrace handles GET /api/v1/traces/{traceId}/live
+// Upgrades to a WebSocket that sends the span tree of the trace known so far, then each span added or updated,
+// and ends with a finalized message once the trace is finalized. A client reconnecting with lastSequence
+// gets the messages after it instead of a new snapshot while they are still kept.
+func (h *Handler) TailTrace(w http.ResponseWriter, r *http.Request) {
+	// Get logger from context
+	log := logger.GetLogger(r.Context())
+
+	traceID := r.PathValue("traceId")
+	var lastSequence *uint64
+	if value := r.URL.Query().Get("lastSequence"); value != "" {
+		sequence, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			h.writeError(w, http.StatusBadRequest, "lastSequence must be a non-negative integer")
+			return
+		}
+		lastSequence = &sequence
+	}
+	if !isWebSocketUpgrade(r) {
+		h.writeError(w, http.StatusBadRequest, "WebSocket upgrade required")
+		return
+	}
+	// Browsers send cookies with WebSocket requests from any page, so only the CORS origins may open a tail
+	if origin := r.Header.Get("Origin"); origin != "" && h.tailOrigins != nil && h.tailOrigins.AllowOrigin(origin) == "" {
+		h.writeError(w, http.StatusForbidden, "Origin not allowed")
+		return
+	}
+
+	orgID, _ := opensearch.OrgScope(r.Context())
+	tail, err := h.tails.Watch(r.Context(), orgID, traceID, h.clientAddress(r), lastSequence)
+	if err != nil {
+		switch {
+		case errors.Is(err, controllers.ErrTooManyTraceTails):
+			h.writeError(w, http.StatusTooManyRequests, "Too many live trace connections")
+		case errors.Is(err, controllers.ErrTraceTailsClosed):
+			h.writeError(w, http.StatusServiceUnavailable, "Live trace tails are not accepting clients, retry later")
+		case r.Context().Err() != nil:
+			// The client went away while the trace was read
+		default:
+			log.Error("Failed to tail trace", "traceId", traceID, "error", err)
+			h.writeError(w, http.StatusInternalServerError, "Failed to tail trace")
+		}
+		return
+	}
+	defer h.tails.Unwatch(tail)
+
+	// The deadlines of the server apply to the request, not to the connection taken over
+	controller := http.NewResponseController(w)
+	_ = controller.SetReadDeadline(time.Time{})
+	_ = controller.SetWriteDeadline(time.Time{})
+	// The origin was checked against the CORS origins above
+	conn, err := websocket.Accept(w, r, &websocket.AcceptOptions{InsecureSkipVerify: true})
+	if err != nil {
+		log.Warn("Failed to upgrade live trace connection", "traceId", traceID, "error", err)
+		return
+	}
+	defer conn.CloseNow()
+	conn.SetReadLimit(tailMaxClientMessageBytes)
+
+	// Messages of the client are discarded, reading answers its pings and notices when it goes away.
+	// The request context is not used once the connection is taken over
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		defer cancel()
+		for {
+			if _, _, err := conn.Read(ctx); err != nil {
+				return
+			}
+		}
+	}()
+
+	heartbeat := time.NewTicker(h.liveHeartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case message, ok := <-tail.Messages():
+			if !ok {
+				_ = conn.Close(tailCloseCode(tail.EndReason()))
+				return
+			}
+			data, err := json.Marshal(message)
+			if err != nil {
+				log.Error("Failed to encode live trace message", "traceId", traceID, "error", err)
+				_ = conn.Close(websocket.StatusInternalError, "failed to encode message")
+				return
+			}
+			if err := writeWithTimeout(ctx, func(ctx context.Context) error { return conn.Write(ctx, websocket.MessageText, data) }); err != nil {
+				_ = conn.Close(websocket.StatusGoingAway, "")
+				return
+			}
+		case <-heartbeat.C:
+			// Also keeps proxies from closing an idle connection
+			if err := writeWithTimeout(ctx, conn.Ping); err != nil {
+				_ = conn.Close(websocket.StatusGoingAway, "")
+				return
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// writeWithTimeout runs write with liveWriteTimeout, a client not reading for this long is disconnected
+func writeWithTimeout(ctx context.Context, write func(ctx context.Context) error) error {
+	ctx, cancel := context.WithTimeout(ctx, liveWriteTimeout)
+	defer cancel()
+	return write(ctx)
+}
+
+// tailCloseCode returns the close code and reason of a tail that ended
+func tailCloseCode(endReason string) (websocket.StatusCode, string) {
+	switch endReason {
+	case controllers.TraceTailEndFinalized:
+		return websocket.StatusNormalClosure, "trace finalized"
+	case controllers.TraceTailEndEvicted:
+		return websocket.StatusTryAgainLater, "client too slow, resume with lastSequence"
+	default:
+		return websocket.StatusGoingAway, "service shutting down"
+	}
+}
+
+// isWebSocketUpgrade reports whether r asks to upgrade to a WebSocket
+func isWebSocketUpgrade(r *http.Request) bool {
+	return r.Method == http.MethodGet &&
+		headerHasToken(r.Header, "Connection", "upgrade") &&
+		headerHasToken(r.Header, "Upgrade", "websocket")
+}
+
+// headerHasToken reports whether a comma separated header holds token, ignoring case
+func headerHasToken(header http.Header, name, token string) bool {
+	for _, value := range header.Values(name) {
+		for _, part := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(part), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// clientAddress identifies the client of a request for the per-client tail limit. When the connection comes from a
+// trusted proxy the client is the nearest entry of the configured header not added by a trusted proxy, the entries
+// further left are set by the client and ignored
+func (h *Handler) clientAddress(r *http.Request) string {
+	addr, err := peerAddr(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	if h.tailIPHeader == "" || !h.isTrustedProxy(addr) {
+		return addr.String()
+	}
+	var hops []string
+	for _, value := range r.Header.Values(h.tailIPHeader) {
+		hops = append(hops, strings.Split(value, ",")...)
+	}
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+		if err != nil {
+			break
+		}
+		addr = hop.Unmap()
+		if !h.isTrustedProxy(addr) {
+			break
+		}
+	}
+	return addr.String()
+}
+
+func peerAddr(remoteAddr string) (netip.Addr, error) {
+	if addrPort, err := netip.ParseAddrPort(remoteAddr); err == nil {
+		return addrPort.Addr().Unmap(), nil
+	}
+	addr, err := netip.ParseAddr(remoteAddr)
+	if err != nil {
+		return netip.Addr{}, err
+	}
+	return addr.Unmap(), nil
+}
+
+func (h *Handler) isTrustedProxy(addr netip.Addr) bool {
+	for _, prefix := range h.tailProxies {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
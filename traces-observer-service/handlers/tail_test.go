@@ -0,0 +1,134 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/controllers"
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/opensearch"
+)
+
+func TestClientAddress(t *testing.T) {
+	proxies := []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")}
+	tests := []struct {
+		name       string
+		header     string
+		proxies    []netip.Prefix
+		remoteAddr string
+		forwarded  []string
+		want       string
+	}{
+		{"no header configured", "", proxies, "10.0.0.1:4000", []string{"198.51.100.7"}, "10.0.0.1"},
+		{"peer is the client", "X-Forwarded-For", proxies, "203.0.113.5:4000", nil, "203.0.113.5"},
+		{"untrusted peer cannot set the header", "X-Forwarded-For", proxies, "203.0.113.5:4000", []string{"198.51.100.7"}, "203.0.113.5"},
+		{"no trusted proxies ignores the header", "X-Forwarded-For", nil, "10.0.0.1:4000", []string{"198.51.100.7"}, "10.0.0.1"},
+		{"trusted proxy", "X-Forwarded-For", proxies, "10.0.0.1:4000", []string{"198.51.100.7"}, "198.51.100.7"},
+		{"spoofed entries left of the client are ignored", "X-Forwarded-For", proxies, "10.0.0.1:4000", []string{"1.2.3.4, 198.51.100.7"}, "198.51.100.7"},
+		{"chain of trusted proxies", "X-Forwarded-For", proxies, "10.0.0.1:4000", []string{"1.2.3.4, 198.51.100.7, 10.2.3.4"}, "198.51.100.7"},
+		{"entries across repeated headers", "X-Forwarded-For", proxies, "10.0.0.1:4000", []string{"1.2.3.4", "198.51.100.7, 10.2.3.4"}, "198.51.100.7"},
+		{"invalid entry stops the walk", "X-Forwarded-For", proxies, "10.0.0.1:4000", []string{"198.51.100.7, garbage, 10.2.3.4"}, "10.2.3.4"},
+		{"custom header", "X-Real-IP", proxies, "10.0.0.1:4000", []string{"198.51.100.7"}, "198.51.100.7"},
+		{"mapped IPv4 peer", "X-Forwarded-For", proxies, "[::ffff:10.0.0.1]:4000", []string{"198.51.100.7"}, "198.51.100.7"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &Handler{tailIPHeader: tt.header, tailProxies: tt.proxies}
+			r := httptest.NewRequest(http.MethodGet, "/api/v1/traces/trace-1/live", nil)
+			r.RemoteAddr = tt.remoteAddr
+			for _, value := range tt.forwarded {
+				r.Header.Add(tt.header, value)
+			}
+			if got := h.clientAddress(r); got != tt.want {
+				t.Errorf("clientAddress() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func newTailServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	start := time.Now().Add(-time.Second)
+	span := opensearch.Span{TraceID: "trace-1", SpanID: "root", Name: "agent", StartTime: start, EndTime: start.Add(time.Second), IngestedAt: time.Now()}
+	tails := controllers.NewTraceTails(controllers.TraceTailsConfig{MaxTails: 10, MaxTailsPerClient: 10},
+		func(ctx context.Context, traceID string) ([]opensearch.Span, error) {
+			return []opensearch.Span{span}, nil
+		}, nil)
+	h := &Handler{liveHeartbeat: time.Minute}
+	h.SetTraceTails(tails, nil, "", nil)
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/traces/{traceId}/live", h.TailTrace)
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestTailTraceSendsSnapshot(t *testing.T) {
+	server := newTailServer(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	conn, _, err := websocket.Dial(ctx, "ws"+strings.TrimPrefix(server.URL, "http")+"/api/v1/traces/trace-1/live", nil)
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	defer conn.CloseNow()
+	// Messages of the client are discarded without ending the tail
+	if err := conn.Write(ctx, websocket.MessageText, []byte("hello")); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	messageType, data, err := conn.Read(ctx)
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	var message controllers.TraceTailMessage
+	if err := json.Unmarshal(data, &message); err != nil {
+		t.Fatalf("invalid message %s: %v", data, err)
+	}
+	if messageType != websocket.MessageText || message.Type != controllers.TraceTailSnapshot || message.Trace == nil {
+		t.Errorf("first message = %s, want a snapshot of the trace", data)
+	}
+	// The server answers pings while it waits for the trace to change
+	pingCtx := conn.CloseRead(ctx)
+	if err := conn.Ping(pingCtx); err != nil {
+		t.Errorf("Ping() error = %v", err)
+	}
+	if err := conn.Close(websocket.StatusNormalClosure, ""); err != nil {
+		t.Errorf("Close() error = %v", err)
+	}
+}
+
+func TestTailTraceRejectsPlainRequests(t *testing.T) {
+	server := newTailServer(t)
+	resp, err := http.Get(server.URL + "/api/v1/traces/trace-1/live")
+	if err != nil {
+		t.Fatalf("GET error = %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("status = %d, want %d", resp.StatusCode, http.StatusBadRequest)
+	}
+}
@@ -0,0 +1,278 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package langfuse
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync/atomic"
+	"time"
+
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/opensearch"
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/pricing"
+)
+
+// checkpointName identifies the checkpoint of the Langfuse exporter
+const checkpointName = "langfuse"
+
+// Config holds the export settings
+type Config struct {
+	PollInterval time.Duration // Interval of looking for newly finalized traces
+	FinalizeWait time.Duration // Time after the root span ends before a trace is exported, letting late spans arrive
+	BatchSize    int           // Traces exported per ingestion round
+	Lookback     time.Duration // Traces ending this long before the first start are exported, later starts resume from the checkpoint
+}
+
+// Stats holds the counters of the exporter
+type Stats struct {
+	ExportedTraces  uint64     `json:"exportedTraces"`
+	RejectedEvents  uint64     `json:"rejectedEvents"` // Events rejected individually by Langfuse
+	LastExportedEnd *time.Time `json:"lastExportedEnd,omitempty"`
+	LastError       string     `json:"lastError,omitempty"`
+}
+
+// Exporter exports finalized traces to Langfuse in the background
+// Progress is checkpointed in OpenSearch after every round, so restarts resume where the exporter stopped;
+// a round interrupted before its checkpoint is re-sent, which Langfuse deduplicates by event id
+type Exporter struct {
+	cfg          Config
+	osClient     *opensearch.Client
+	api          *Client
+	pricingTable *pricing.Table
+
+	searchAfter []interface{} // Sort values of the last exported root span, only used by the run loop
+	since       time.Time     // Lower bound of the first round when there is no checkpoint
+
+	exported        atomic.Uint64
+	rejected        atomic.Uint64
+	lastExportedEnd atomic.Pointer[time.Time]
+	lastError       atomic.Pointer[string]
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewExporter creates an exporter, Start loads the checkpoint and starts exporting
+func NewExporter(cfg Config, osClient *opensearch.Client, api *Client, pricingTable *pricing.Table) *Exporter {
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = 30 * time.Second
+	}
+	if cfg.FinalizeWait <= 0 {
+		cfg.FinalizeWait = 2 * time.Minute
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 50
+	}
+	return &Exporter{
+		cfg:          cfg,
+		osClient:     osClient,
+		api:          api,
+		pricingTable: pricingTable,
+		stop:         make(chan struct{}),
+		done:         make(chan struct{}),
+	}
+}
+
+// Start resumes from the stored checkpoint and starts the export loop
+func (e *Exporter) Start(ctx context.Context) error {
+	if err := e.osClient.EnsureCheckpointIndex(ctx); err != nil {
+		return err
+	}
+	checkpoint, err := e.osClient.LoadCheckpoint(ctx, checkpointName)
+	if err != nil {
+		return fmt.Errorf("failed to load langfuse export checkpoint: %w", err)
+	}
+
+	if checkpoint != nil && checkpoint.Cursor != "" {
+		cursor, err := opensearch.DecodeCursor(checkpoint.Cursor)
+		if err != nil {
+			return fmt.Errorf("failed to decode langfuse export checkpoint: %w", err)
+		}
+		e.searchAfter = cursor.SearchAfter
+		slog.Info("Resuming Langfuse export", "checkpointUpdatedAt", checkpoint.UpdatedAt)
+	} else {
+		e.since = time.Now().Add(-e.cfg.Lookback)
+		slog.Info("Starting Langfuse export", "since", e.since)
+	}
+
+	go e.run()
+	return nil
+}
+
+// Close stops the export loop, waiting for the current round until the context is done
+func (e *Exporter) Close(ctx context.Context) {
+	close(e.stop)
+	select {
+	case <-e.done:
+	case <-ctx.Done():
+		slog.Warn("Timed out waiting for the Langfuse export round")
+	}
+}
+
+// Stats returns the counters of the exporter
+func (e *Exporter) Stats() Stats {
+	stats := Stats{
+		ExportedTraces:  e.exported.Load(),
+		RejectedEvents:  e.rejected.Load(),
+		LastExportedEnd: e.lastExportedEnd.Load(),
+	}
+	if lastError := e.lastError.Load(); lastError != nil {
+		stats.LastError = *lastError
+	}
+	return stats
+}
+
+// run exports finalized traces every poll interval until the exporter is stopped
+func (e *Exporter) run() {
+	defer close(e.done)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-e.stop
+		cancel()
+	}()
+
+	ticker := time.NewTicker(e.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		e.exportFinalized(ctx)
+		select {
+		case <-ticker.C:
+		case <-e.stop:
+			return
+		}
+	}
+}
+
+// exportFinalized exports rounds of finalized traces until none are left or a round fails
+func (e *Exporter) exportFinalized(ctx context.Context) {
+	for ctx.Err() == nil {
+		exported, err := e.exportRound(ctx)
+		if err != nil {
+			if ctx.Err() == nil {
+				message := err.Error()
+				e.lastError.Store(&message)
+				slog.Error("Failed to export traces to Langfuse", "error", err)
+			}
+			return
+		}
+		e.lastError.Store(nil)
+		if exported < e.cfg.BatchSize {
+			return
+		}
+	}
+}
+
+// exportRound exports the next batch of finalized traces and advances the checkpoint
+// The checkpoint is left untouched when delivery fails, so the round is retried on the next poll
+func (e *Exporter) exportRound(ctx context.Context) (int, error) {
+	until := time.Now().Add(-e.cfg.FinalizeWait)
+	query := opensearch.BuildFinalizedTracesQuery(e.searchAfter, e.since, until, e.cfg.BatchSize)
+	response, err := e.osClient.Search(ctx, []string{opensearch.TraceIndexPattern}, query)
+	if err != nil {
+		return 0, fmt.Errorf("failed to search finalized traces: %w", err)
+	}
+	hits := response.Hits.Hits
+	if len(hits) == 0 {
+		return 0, nil
+	}
+
+	roots := opensearch.ParseSpans(response)
+	traceIDs := make([]string, 0, len(roots))
+	for _, root := range roots {
+		traceIDs = append(traceIDs, root.TraceID)
+	}
+	traces, err := e.fetchTraces(ctx, traceIDs)
+	if err != nil {
+		return 0, err
+	}
+
+	var events []Event
+	for _, root := range roots {
+		trace, ok := traces[root.TraceID]
+		if !ok {
+			trace = &TraceData{Spans: []opensearch.Span{root}}
+		}
+		trace.Root = root
+		events = append(events, BuildEvents(*trace, e.pricingTable)...)
+	}
+
+	result, err := e.api.Ingest(ctx, events)
+	if err != nil {
+		return 0, err
+	}
+	if len(result.Errors) > 0 {
+		e.rejected.Add(uint64(len(result.Errors)))
+		first := result.Errors[0]
+		slog.Warn("Langfuse rejected exported events",
+			"rejected", len(result.Errors), "event", first.ID, "status", first.Status, "message", first.Message)
+	}
+
+	last := hits[len(hits)-1]
+	e.searchAfter = last.Sort
+	e.exported.Add(uint64(len(roots)))
+	lastEnd := roots[len(roots)-1].EndTime
+	e.lastExportedEnd.Store(&lastEnd)
+	e.saveCheckpoint(ctx)
+	return len(roots), nil
+}
+
+// saveCheckpoint stores the cursor of the last exported trace, failures only risk re-sending a round
+func (e *Exporter) saveCheckpoint(ctx context.Context) {
+	cursor, err := opensearch.EncodeCursor(opensearch.TraceCursor{SearchAfter: e.searchAfter})
+	if err == nil {
+		err = e.osClient.SaveCheckpoint(ctx, opensearch.ExportCheckpoint{
+			Exporter:  checkpointName,
+			Cursor:    cursor,
+			UpdatedAt: time.Now().UTC(),
+		})
+	}
+	if err != nil {
+		slog.Warn("Failed to save Langfuse export checkpoint", "error", err)
+	}
+}
+
+// fetchTraces reads every span of the given traces along with their OTel span events
+func (e *Exporter) fetchTraces(ctx context.Context, traceIDs []string) (map[string]*TraceData, error) {
+	traces := make(map[string]*TraceData, len(traceIDs))
+	var searchAfter []interface{}
+	for {
+		query := opensearch.BuildSpansForTracesQuery(traceIDs, "", "", searchAfter)
+		response, err := e.osClient.Search(ctx, []string{opensearch.TraceIndexPattern}, query)
+		if err != nil {
+			return nil, fmt.Errorf("failed to search trace spans: %w", err)
+		}
+
+		hits := response.Hits.Hits
+		for _, span := range opensearch.ParseSpans(response) {
+			trace, ok := traces[span.TraceID]
+			if !ok {
+				trace = &TraceData{}
+				traces[span.TraceID] = trace
+			}
+			trace.Spans = append(trace.Spans, span)
+		}
+		if len(hits) < opensearch.TraceSpansPageSize || len(hits[len(hits)-1].Sort) == 0 {
+			break
+		}
+		searchAfter = hits[len(hits)-1].Sort
+	}
+	return traces, nil
+}
@@ -0,0 +1,238 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package langfuse
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/config"
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/opensearch"
+)
+
+// fakeExportOpenSearch serves finalized root spans, the spans of their traces and the checkpoint index
+// Finalized roots are only returned to the first page, searches after a cursor find nothing new
+type fakeExportOpenSearch struct {
+	mu         sync.Mutex
+	roots      []string // Root span documents
+	spans      []string // Span documents of every trace
+	checkpoint json.RawMessage
+	finalized  []string // Bodies of the finalized trace searches
+}
+
+func (f *fakeExportOpenSearch) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	checkpointPath := "/" + opensearch.CheckpointIndex + "/_doc/" + checkpointName
+	switch {
+	case r.URL.Path == "/":
+		fmt.Fprint(w, `{"cluster_name":"test","version":{"distribution":"opensearch","number":"2.11.0"}}`)
+	case r.URL.Path == "/"+opensearch.CheckpointIndex:
+		fmt.Fprint(w, `{"acknowledged":true}`)
+	case r.URL.Path == checkpointPath && r.Method == http.MethodGet:
+		if f.checkpoint == nil {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"found":false}`)
+			return
+		}
+		fmt.Fprintf(w, `{"found":true,"_source":%s}`, f.checkpoint)
+	case r.URL.Path == checkpointPath && r.Method == http.MethodPut:
+		f.checkpoint, _ = io.ReadAll(r.Body)
+		fmt.Fprint(w, `{"result":"updated"}`)
+	case strings.HasSuffix(r.URL.Path, "/_search"):
+		body, _ := io.ReadAll(r.Body)
+		if strings.Contains(string(body), `"terms":{"traceId"`) {
+			fmt.Fprint(w, searchResponse(f.spans))
+			return
+		}
+		f.finalized = append(f.finalized, string(body))
+		if strings.Contains(string(body), "search_after") {
+			fmt.Fprint(w, searchResponse(nil))
+			return
+		}
+		fmt.Fprint(w, searchResponse(f.roots))
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (f *fakeExportOpenSearch) state() (json.RawMessage, []string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.checkpoint, append([]string(nil), f.finalized...)
+}
+
+// searchResponse wraps span documents in a search response sorted by end time and trace id
+func searchResponse(sources []string) string {
+	hits := make([]string, 0, len(sources))
+	for _, source := range sources {
+		var span struct {
+			TraceID string `json:"traceId"`
+			EndTime string `json:"endTime"`
+		}
+		json.Unmarshal([]byte(source), &span)
+		endTime, _ := time.Parse(time.RFC3339Nano, span.EndTime)
+		hits = append(hits, fmt.Sprintf(`{"_source":%s,"sort":[%d,%q]}`, source, endTime.UnixMilli(), span.TraceID))
+	}
+	return fmt.Sprintf(`{"hits":{"total":{"value":%d},"hits":[%s]}}`, len(hits), strings.Join(hits, ","))
+}
+
+// spanDocument returns the index document of a span ending a second after it starts
+func spanDocument(traceID, spanID, parentSpanID string, start time.Time, attributes string) string {
+	return fmt.Sprintf(`{"traceId":%q,"spanId":%q,"parentSpanId":%q,"name":"%s-op","startTime":%q,"endTime":%q,"attributes":%s,"resource":{"service.name":"agent"}}`,
+		traceID, spanID, parentSpanID, spanID, start.Format(time.RFC3339Nano), start.Add(time.Second).Format(time.RFC3339Nano), attributes)
+}
+
+// startExporter starts an exporter reading from the fake OpenSearch and ingesting into the API
+func startExporter(t *testing.T, fake *fakeExportOpenSearch, client *Client) *Exporter {
+	t.Helper()
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+	osClient, err := opensearch.NewClient(&config.OpenSearchConfig{Address: server.URL, RequestTimeout: 5 * time.Second}, nil)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	exporter := NewExporter(Config{PollInterval: time.Hour, BatchSize: 10, Lookback: time.Hour}, osClient, client, nil)
+	if err := exporter.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	t.Cleanup(func() { exporter.Close(context.Background()) })
+	return exporter
+}
+
+// waitFor polls a condition until it holds
+func waitFor(t *testing.T, what string, condition func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestExporterExportsFinalizedTraces(t *testing.T) {
+	start := time.Date(2025, 11, 3, 10, 0, 0, 0, time.UTC)
+	fake := &fakeExportOpenSearch{
+		roots: []string{
+			spanDocument("trace-1", "root-1", "", start, `{}`),
+			spanDocument("trace-2", "root-2", "", start.Add(time.Minute), `{}`),
+		},
+		spans: []string{
+			spanDocument("trace-1", "root-1", "", start, `{}`),
+			spanDocument("trace-1", "llm-1", "root-1", start, `{"traceloop.span.kind":"llm","gen_ai.request.model":"gpt-4o"}`),
+		},
+	}
+	api, client := newIngestionAPI(t)
+	exporter := startExporter(t, fake, client)
+
+	waitFor(t, "the checkpoint", func() bool {
+		checkpoint, _ := fake.state()
+		return checkpoint != nil
+	})
+
+	// trace-1 has a trace, a span and a generation, trace-2 is exported from its root alone
+	if received := api.received(); received != 5 {
+		t.Errorf("received events = %d, want 5", received)
+	}
+	api.mu.Lock()
+	var generations int
+	for _, event := range api.batches[0] {
+		if event.Type == EventGenerationCreate {
+			generations++
+		}
+	}
+	api.mu.Unlock()
+	if generations != 1 {
+		t.Errorf("generations = %d, want the LLM span exported as a generation", generations)
+	}
+
+	stats := exporter.Stats()
+	if stats.ExportedTraces != 2 || stats.LastError != "" {
+		t.Errorf("stats = %+v, want 2 exported traces", stats)
+	}
+	if stats.LastExportedEnd == nil || !stats.LastExportedEnd.Equal(start.Add(time.Minute+time.Second)) {
+		t.Errorf("last exported end = %v, want the end of trace-2", stats.LastExportedEnd)
+	}
+
+	checkpoint, finalized := fake.state()
+	var stored opensearch.ExportCheckpoint
+	if err := json.Unmarshal(checkpoint, &stored); err != nil {
+		t.Fatal(err)
+	}
+	cursor, err := opensearch.DecodeCursor(stored.Cursor)
+	if err != nil {
+		t.Fatalf("DecodeCursor() error = %v", err)
+	}
+	if stored.Exporter != checkpointName || fmt.Sprint(cursor.SearchAfter) != fmt.Sprintf("[%d trace-2]", start.Add(time.Minute+time.Second).UnixMilli()) {
+		t.Errorf("checkpoint = %+v with cursor %v, want the sort values of trace-2", stored, cursor.SearchAfter)
+	}
+	// Without a checkpoint the first round looks back from the start
+	if len(finalized) == 0 || !strings.Contains(finalized[0], `"gt":`) {
+		t.Errorf("first search = %v, want the lookback applied", finalized)
+	}
+}
+
+func TestExporterResumesFromCheckpoint(t *testing.T) {
+	cursor, err := opensearch.EncodeCursor(opensearch.TraceCursor{SearchAfter: []interface{}{1762164000000, "trace-9"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	fake := &fakeExportOpenSearch{checkpoint: json.RawMessage(fmt.Sprintf(`{"exporter":"langfuse","cursor":%q}`, cursor))}
+	api, client := newIngestionAPI(t)
+	startExporter(t, fake, client)
+
+	waitFor(t, "the first round", func() bool {
+		_, finalized := fake.state()
+		return len(finalized) > 0
+	})
+
+	_, finalized := fake.state()
+	if !strings.Contains(finalized[0], `"search_after":[1762164000000,"trace-9"]`) || strings.Contains(finalized[0], `"gt":`) {
+		t.Errorf("search = %s, want the round resumed after the checkpoint", finalized[0])
+	}
+	if api.attempts != 0 {
+		t.Errorf("ingestion attempts = %d, want none without new traces", api.attempts)
+	}
+}
+
+func TestExporterKeepsCheckpointWhenDeliveryFails(t *testing.T) {
+	fake := &fakeExportOpenSearch{roots: []string{spanDocument("trace-1", "root-1", "", time.Now().Add(-time.Hour), `{}`)}}
+	_, client := newIngestionAPI(t, http.StatusUnauthorized)
+	exporter := startExporter(t, fake, client)
+
+	waitFor(t, "the failed round", func() bool { return exporter.Stats().LastError != "" })
+
+	if checkpoint, _ := fake.state(); checkpoint != nil {
+		t.Errorf("checkpoint = %s, want none so that the round is retried", checkpoint)
+	}
+	if stats := exporter.Stats(); stats.ExportedTraces != 0 || !strings.Contains(stats.LastError, "401") {
+		t.Errorf("stats = %+v, want the delivery error", stats)
+	}
+}
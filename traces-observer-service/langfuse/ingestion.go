@@ -0,0 +1,225 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package langfuse
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Ingestion event types
+const (
+	EventTraceCreate      = "trace-create"
+	EventSpanCreate       = "span-create"
+	EventGenerationCreate = "generation-create"
+	EventEventCreate      = "event-create"
+)
+
+// Observation levels
+const (
+	LevelDefault = "DEFAULT"
+	LevelError   = "ERROR"
+)
+
+// maxBatchBytes keeps ingestion requests below the 3.5 MB limit of the Langfuse API
+const maxBatchBytes = 3 * 1024 * 1024
+
+// Event is an entry of an ingestion batch
+// Event and body ids are derived from trace and span ids, so re-sent traces are deduplicated by Langfuse
+type Event struct {
+	ID        string      `json:"id"`
+	Type      string      `json:"type"`
+	Timestamp time.Time   `json:"timestamp"`
+	Body      interface{} `json:"body"`
+}
+
+// TraceBody is the body of a trace-create event
+type TraceBody struct {
+	ID        string                 `json:"id"`
+	Name      string                 `json:"name,omitempty"`
+	Timestamp time.Time              `json:"timestamp"`
+	UserID    string                 `json:"userId,omitempty"`
+	SessionID string                 `json:"sessionId,omitempty"`
+	Input     interface{}            `json:"input,omitempty"`
+	Output    interface{}            `json:"output,omitempty"`
+	Metadata  map[string]interface{} `json:"metadata,omitempty"`
+	Tags      []string               `json:"tags,omitempty"`
+}
+
+// ObservationBody is the body of span-create, generation-create and event-create events
+type ObservationBody struct {
+	ID                  string                 `json:"id"`
+	TraceID             string                 `json:"traceId"`
+	ParentObservationID string                 `json:"parentObservationId,omitempty"`
+	Name                string                 `json:"name,omitempty"`
+	StartTime           time.Time              `json:"startTime"`
+	EndTime             *time.Time             `json:"endTime,omitempty"` // Not set for events
+	Input               interface{}            `json:"input,omitempty"`
+	Output              interface{}            `json:"output,omitempty"`
+	Metadata            map[string]interface{} `json:"metadata,omitempty"`
+	Level               string                 `json:"level,omitempty"`
+	StatusMessage       string                 `json:"statusMessage,omitempty"`
+
+	// Generations only
+	CompletionStartTime *time.Time             `json:"completionStartTime,omitempty"` // First streamed chunk
+	Model               string                 `json:"model,omitempty"`
+	ModelParameters     map[string]interface{} `json:"modelParameters,omitempty"`
+	UsageDetails        map[string]int         `json:"usageDetails,omitempty"`
+	CostDetails         map[string]float64     `json:"costDetails,omitempty"` // USD
+}
+
+// IngestionResponse reports the events accepted and rejected by Langfuse
+type IngestionResponse struct {
+	Successes []IngestionStatus `json:"successes"`
+	Errors    []IngestionStatus `json:"errors"`
+}
+
+// IngestionStatus is the result of a single event
+type IngestionStatus struct {
+	ID      string `json:"id"`
+	Status  int    `json:"status"`
+	Message string `json:"message,omitempty"`
+}
+
+// Client posts ingestion batches to the public API of a Langfuse host
+type Client struct {
+	host         string
+	publicKey    string
+	secretKey    string
+	maxRetries   int
+	retryBackoff time.Duration
+	httpClient   *http.Client
+}
+
+// NewClient creates a Langfuse ingestion client
+func NewClient(host, publicKey, secretKey string, requestTimeout time.Duration, maxRetries int, retryBackoff time.Duration) *Client {
+	return &Client{
+		host:         strings.TrimRight(host, "/"),
+		publicKey:    publicKey,
+		secretKey:    secretKey,
+		maxRetries:   maxRetries,
+		retryBackoff: retryBackoff,
+		httpClient:   &http.Client{Timeout: requestTimeout},
+	}
+}
+
+// Ingest sends events in batches below the request size limit
+// An error is returned when a batch could not be delivered, events rejected individually are reported in the response
+func (c *Client) Ingest(ctx context.Context, events []Event) (*IngestionResponse, error) {
+	result := &IngestionResponse{}
+	var batch []json.RawMessage
+	batchBytes := 0
+
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		response, err := c.send(ctx, batch)
+		if err != nil {
+			return err
+		}
+		result.Successes = append(result.Successes, response.Successes...)
+		result.Errors = append(result.Errors, response.Errors...)
+		batch = batch[:0]
+		batchBytes = 0
+		return nil
+	}
+
+	for _, event := range events {
+		encoded, err := json.Marshal(event)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode event %s: %w", event.ID, err)
+		}
+		if batchBytes+len(encoded) > maxBatchBytes {
+			if err := flush(); err != nil {
+				return nil, err
+			}
+		}
+		batch = append(batch, encoded)
+		batchBytes += len(encoded)
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// send posts a batch, retrying network errors, 429 and 5xx responses with exponential backoff
+func (c *Client) send(ctx context.Context, batch []json.RawMessage) (*IngestionResponse, error) {
+	body, err := json.Marshal(map[string]interface{}{"batch": batch})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode ingestion batch: %w", err)
+	}
+
+	backoff := c.retryBackoff
+	var lastErr error
+	for attempt := 0; attempt <= c.maxRetries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-time.After(backoff):
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+			backoff *= 2
+		}
+
+		response, retry, err := c.post(ctx, body)
+		if err == nil {
+			return response, nil
+		}
+		lastErr = err
+		if !retry {
+			break
+		}
+	}
+	return nil, lastErr
+}
+
+// post sends a single ingestion request and reports whether a failure is worth retrying
+func (c *Client) post(ctx context.Context, body []byte) (*IngestionResponse, bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.host+"/api/public/ingestion", bytes.NewReader(body))
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to build ingestion request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.SetBasicAuth(c.publicKey, c.secretKey)
+
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, true, fmt.Errorf("ingestion request failed: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		io.Copy(io.Discard, res.Body)
+		retry := res.StatusCode == http.StatusTooManyRequests || res.StatusCode >= 500
+		return nil, retry, fmt.Errorf("ingestion request failed with status: %s", res.Status)
+	}
+
+	// 207 responses list the events rejected individually
+	var response IngestionResponse
+	if err := json.NewDecoder(res.Body).Decode(&response); err != nil && err != io.EOF {
+		return nil, false, fmt.Errorf("failed to decode ingestion response: %w", err)
+	}
+	return &response, false, nil
+}
@@ -0,0 +1,182 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package langfuse
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// ingestionAPI is a Langfuse ingestion endpoint answering with the scripted statuses before accepting batches
+// Events whose id starts with "invalid" are rejected individually
+type ingestionAPI struct {
+	mu       sync.Mutex
+	statuses []int
+	attempts int
+	batches  [][]Event
+	auth     []string
+}
+
+func (a *ingestionAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if r.Method != http.MethodPost || r.URL.Path != "/api/public/ingestion" {
+		http.NotFound(w, r)
+		return
+	}
+	a.attempts++
+	if len(a.statuses) > 0 {
+		status := a.statuses[0]
+		a.statuses = a.statuses[1:]
+		w.WriteHeader(status)
+		return
+	}
+
+	var body struct {
+		Batch []Event `json:"batch"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	publicKey, secretKey, _ := r.BasicAuth()
+	a.auth = append(a.auth, publicKey+":"+secretKey)
+	a.batches = append(a.batches, body.Batch)
+
+	var response IngestionResponse
+	for _, event := range body.Batch {
+		if strings.HasPrefix(event.ID, "invalid") {
+			response.Errors = append(response.Errors, IngestionStatus{ID: event.ID, Status: http.StatusBadRequest, Message: "invalid event"})
+		} else {
+			response.Successes = append(response.Successes, IngestionStatus{ID: event.ID, Status: http.StatusCreated})
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusMultiStatus)
+	json.NewEncoder(w).Encode(response)
+}
+
+func (a *ingestionAPI) received() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	events := 0
+	for _, batch := range a.batches {
+		events += len(batch)
+	}
+	return events
+}
+
+// newIngestionAPI starts an ingestion endpoint and returns a client posting to it
+func newIngestionAPI(t *testing.T, statuses ...int) (*ingestionAPI, *Client) {
+	t.Helper()
+	api := &ingestionAPI{statuses: statuses}
+	server := httptest.NewServer(api)
+	t.Cleanup(server.Close)
+	return api, NewClient(server.URL+"/", "pk-lf", "sk-lf", 5*time.Second, 2, time.Millisecond)
+}
+
+func TestClientIngest(t *testing.T) {
+	api, client := newIngestionAPI(t)
+	events := []Event{
+		{ID: "trace-1-trace", Type: EventTraceCreate, Body: TraceBody{ID: "trace-1"}},
+		{ID: "invalid-observation", Type: EventSpanCreate, Body: ObservationBody{ID: "span-1", TraceID: "trace-1"}},
+	}
+
+	result, err := client.Ingest(context.Background(), events)
+	if err != nil {
+		t.Fatalf("Ingest() error = %v", err)
+	}
+	if len(result.Successes) != 1 || len(result.Errors) != 1 || result.Errors[0].ID != "invalid-observation" {
+		t.Errorf("result = %+v, want one accepted and one rejected event", result)
+	}
+	if len(api.batches) != 1 || len(api.batches[0]) != 2 {
+		t.Errorf("batches = %v, want both events in one batch", api.batches)
+	}
+	if api.auth[0] != "pk-lf:sk-lf" {
+		t.Errorf("basic auth = %q, want the key pair", api.auth[0])
+	}
+}
+
+func TestClientIngestSplitsLargeBatches(t *testing.T) {
+	api, client := newIngestionAPI(t)
+	large := strings.Repeat("x", maxBatchBytes/3)
+	var events []Event
+	for i := 0; i < 5; i++ {
+		events = append(events, Event{ID: fmt.Sprintf("event-%d", i), Type: EventSpanCreate, Body: ObservationBody{Input: large}})
+	}
+
+	result, err := client.Ingest(context.Background(), events)
+	if err != nil {
+		t.Fatalf("Ingest() error = %v", err)
+	}
+	if len(result.Successes) != 5 {
+		t.Errorf("successes = %d, want 5", len(result.Successes))
+	}
+	if len(api.batches) != 3 {
+		t.Errorf("batches = %d, want every batch below the request size limit", len(api.batches))
+	}
+}
+
+func TestClientIngestRetries(t *testing.T) {
+	tests := []struct {
+		name         string
+		statuses     []int
+		wantAttempts int
+		wantErr      bool
+	}{
+		{name: "recovers after unavailable", statuses: []int{http.StatusServiceUnavailable, http.StatusTooManyRequests}, wantAttempts: 3},
+		{name: "gives up after retries", statuses: []int{http.StatusBadGateway, http.StatusBadGateway, http.StatusBadGateway}, wantAttempts: 3, wantErr: true},
+		{name: "unauthorized is not retried", statuses: []int{http.StatusUnauthorized}, wantAttempts: 1, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api, client := newIngestionAPI(t, tt.statuses...)
+
+			_, err := client.Ingest(context.Background(), []Event{{ID: "trace-1-trace", Type: EventTraceCreate, Body: TraceBody{ID: "trace-1"}}})
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Ingest() error = %v, want error %v", err, tt.wantErr)
+			}
+			if api.attempts != tt.wantAttempts {
+				t.Errorf("attempts = %d, want %d", api.attempts, tt.wantAttempts)
+			}
+		})
+	}
+}
+
+func TestClientIngestStopsRetryingWhenCancelled(t *testing.T) {
+	api := &ingestionAPI{statuses: []int{http.StatusServiceUnavailable, http.StatusServiceUnavailable}}
+	server := httptest.NewServer(api)
+	defer server.Close()
+	client := NewClient(server.URL, "pk-lf", "sk-lf", 5*time.Second, 2, time.Hour)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := client.Ingest(ctx, []Event{{ID: "trace-1-trace", Type: EventTraceCreate}}); err != context.DeadlineExceeded {
+		t.Errorf("Ingest() error = %v, want the context error instead of waiting for the backoff", err)
+	}
+	if api.attempts != 1 {
+		t.Errorf("attempts = %d, want 1", api.attempts)
+	}
+}
@@ -0,0 +1,209 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package langfuse
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/opensearch"
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/pricing"
+)
+
+// TraceData holds a finalized trace read from the trace indices
+type TraceData struct {
+	Root  opensearch.Span
+	Spans []opensearch.Span // Every span of the trace, including the root
+}
+
+// BuildEvents maps a trace to Langfuse ingestion events
+// The trace becomes a trace object, LLM spans become generations with usage and cost,
+// other spans become spans and OTel span events become events under their span
+func BuildEvents(trace TraceData, pricingTable *pricing.Table) []Event {
+	root := trace.Root
+	events := []Event{{
+		ID:        root.TraceID + "-trace",
+		Type:      EventTraceCreate,
+		Timestamp: root.StartTime,
+		Body:      buildTraceBody(root),
+	}}
+
+	for _, span := range trace.Spans {
+		observation := buildObservation(span)
+		eventType := EventSpanCreate
+		if span.AmpAttributes != nil && span.AmpAttributes.Kind == string(opensearch.SpanTypeLLM) {
+			eventType = EventGenerationCreate
+			addGenerationFields(&observation, span, pricingTable)
+		}
+		events = append(events, Event{
+			ID:        span.SpanID + "-observation",
+			Type:      eventType,
+			Timestamp: span.StartTime,
+			Body:      observation,
+		})
+
+		for i, spanEvent := range span.Events {
+			body := ObservationBody{
+				ID:                  fmt.Sprintf("%s-event-%d", span.SpanID, i),
+				TraceID:             span.TraceID,
+				ParentObservationID: span.SpanID,
+				Name:                spanEvent.Name,
+				StartTime:           spanEvent.Time,
+				Metadata:            spanEvent.Attributes,
+				Level:               LevelDefault,
+			}
+			if spanEvent.Name == "exception" {
+				body.Level = LevelError
+				body.StatusMessage, _ = spanEvent.Attributes["exception.message"].(string)
+			}
+			events = append(events, Event{
+				ID:        body.ID,
+				Type:      EventEventCreate,
+				Timestamp: spanEvent.Time,
+				Body:      body,
+			})
+		}
+	}
+	return events
+}
+
+// buildTraceBody builds the trace object from the root span
+func buildTraceBody(root opensearch.Span) TraceBody {
+	input, output := opensearch.ExtractRootSpanInputOutput(&root)
+	body := TraceBody{
+		ID:        root.TraceID,
+		Name:      root.Name,
+		Timestamp: root.StartTime,
+		SessionID: root.SessionID,
+		Input:     valueOrNil(input),
+		Output:    valueOrNil(output),
+		Metadata:  map[string]interface{}{},
+	}
+	body.UserID, _ = root.Attributes["enduser.id"].(string)
+	if agent, ok := root.Resource["service.name"].(string); ok {
+		body.Metadata["agent"] = agent
+	}
+	if componentUid, ok := root.Resource["openchoreo.dev/component-uid"].(string); ok {
+		body.Metadata["componentUid"] = componentUid
+	}
+	if environmentUid, ok := root.Resource["openchoreo.dev/environment-uid"].(string); ok {
+		body.Metadata["environmentUid"] = environmentUid
+	}
+	if framework := opensearch.SpanFramework(root); framework != "" {
+		body.Tags = []string{framework}
+	}
+	return body
+}
+
+// buildObservation builds the fields shared by spans and generations
+func buildObservation(span opensearch.Span) ObservationBody {
+	observation := ObservationBody{
+		ID:                  span.SpanID,
+		TraceID:             span.TraceID,
+		ParentObservationID: span.ParentSpanID,
+		Name:                span.Name,
+		StartTime:           span.StartTime,
+		Level:               LevelDefault,
+		Metadata:            map[string]interface{}{},
+	}
+	if span.Kind != "" {
+		observation.Metadata["spanKind"] = span.Kind
+	}
+	if !span.EndTime.IsZero() {
+		endTime := span.EndTime
+		observation.EndTime = &endTime
+	}
+	if framework := opensearch.SpanFramework(span); framework != "" {
+		observation.Metadata["framework"] = framework
+	}
+
+	amp := span.AmpAttributes
+	if amp == nil {
+		return observation
+	}
+	observation.Input = valueOrNil(amp.Input)
+	observation.Output = valueOrNil(amp.Output)
+	observation.Metadata["kind"] = amp.Kind
+	if amp.Status != nil && amp.Status.Error {
+		observation.Level = LevelError
+		if amp.Error != nil {
+			observation.StatusMessage = amp.Error.Message
+		}
+	}
+	return observation
+}
+
+// addGenerationFields adds the model, parameters, completion start, usage and cost of an LLM span
+func addGenerationFields(observation *ObservationBody, span opensearch.Span, pricingTable *pricing.Table) {
+	llm, ok := span.AmpAttributes.Data.(opensearch.LLMData)
+	if !ok {
+		return
+	}
+	observation.Model = llm.Model
+	if llm.Streaming != nil {
+		completionStart := span.StartTime.Add(time.Duration(llm.Streaming.TTFTMs * float64(time.Millisecond)))
+		observation.CompletionStartTime = &completionStart
+	}
+	if llm.Temperature != nil {
+		observation.ModelParameters = map[string]interface{}{"temperature": *llm.Temperature}
+	}
+
+	usage := llm.TokenUsage
+	if usage == nil {
+		return
+	}
+	observation.UsageDetails = map[string]int{
+		"input":  usage.InputTokens,
+		"output": usage.OutputTokens,
+		"total":  usage.TotalTokens,
+	}
+	if usage.CacheReadInputTokens > 0 {
+		observation.UsageDetails["input_cached_tokens"] = usage.CacheReadInputTokens
+	}
+
+	if pricingTable == nil || llm.Model == "" {
+		return
+	}
+	cost := pricingTable.Calculate(llm.Model, pricing.Usage{
+		InputTokens:           usage.InputTokens,
+		OutputTokens:          usage.OutputTokens,
+		CacheReadInputTokens:  usage.CacheReadInputTokens,
+		CacheWriteInputTokens: usage.CacheWriteInputTokens,
+	})
+	if cost != nil {
+		observation.CostDetails = map[string]float64{
+			"input":  cost.InputCost,
+			"output": cost.OutputCost,
+			"total":  cost.TotalCost,
+		}
+	}
+}
+
+// valueOrNil returns nil for nil slices, maps and pointers held in an interface so that they are omitted
+func valueOrNil(value interface{}) interface{} {
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Invalid:
+		return nil
+	case reflect.Slice, reflect.Map, reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+	}
+	return value
+}
@@ -0,0 +1,205 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package langfuse
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/opensearch"
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/pricing"
+)
+
+var traceStart = time.Date(2025, 11, 3, 10, 0, 0, 0, time.UTC)
+
+// testTrace returns an agent trace with a streamed LLM call and a failed tool call
+func testTrace() TraceData {
+	temperature := 0.2
+	root := opensearch.Span{
+		TraceID:    "trace-1",
+		SpanID:     "root",
+		Name:       "invoke_agent",
+		StartTime:  traceStart,
+		EndTime:    traceStart.Add(3 * time.Second),
+		SessionID:  "session-1",
+		Attributes: map[string]interface{}{"enduser.id": "user-1", "gen_ai.system": "LangChain"},
+		Resource: map[string]interface{}{
+			"service.name":                   "booking-agent",
+			"openchoreo.dev/component-uid":   "comp-1",
+			"openchoreo.dev/environment-uid": "env-1",
+		},
+		AmpAttributes: &opensearch.AmpAttributes{Kind: string(opensearch.SpanTypeAgent)},
+	}
+	llm := opensearch.Span{
+		TraceID:      "trace-1",
+		SpanID:       "llm",
+		ParentSpanID: "root",
+		Name:         "chat gpt-4o",
+		Kind:         "CLIENT",
+		StartTime:    traceStart.Add(time.Second),
+		EndTime:      traceStart.Add(2 * time.Second),
+		AmpAttributes: &opensearch.AmpAttributes{
+			Kind:   string(opensearch.SpanTypeLLM),
+			Input:  []opensearch.PromptMessage{{Role: "user", Content: "book a flight"}},
+			Output: "done",
+			Data: opensearch.LLMData{
+				Model:       "gpt-4o",
+				Temperature: &temperature,
+				Streaming:   &opensearch.StreamingMetrics{TTFTMs: 250, Chunks: 4},
+				TokenUsage:  &opensearch.LLMTokenUsage{InputTokens: 1000000, OutputTokens: 500000, TotalTokens: 1500000, CacheReadInputTokens: 200000},
+			},
+		},
+	}
+	tool := opensearch.Span{
+		TraceID:      "trace-1",
+		SpanID:       "tool",
+		ParentSpanID: "root",
+		Name:         "search_flights",
+		StartTime:    traceStart.Add(2 * time.Second),
+		EndTime:      traceStart.Add(3 * time.Second),
+		AmpAttributes: &opensearch.AmpAttributes{
+			Kind:   string(opensearch.SpanTypeTool),
+			Status: &opensearch.SpanStatus{Error: true},
+			Error:  &opensearch.ErrorData{Type: "TimeoutError", Message: "upstream timed out"},
+		},
+		Events: []opensearch.SpanEvent{{
+			Name:       "exception",
+			Time:       traceStart.Add(3 * time.Second),
+			Attributes: map[string]interface{}{"exception.message": "upstream timed out"},
+		}},
+	}
+	return TraceData{Root: root, Spans: []opensearch.Span{root, llm, tool}}
+}
+
+func TestBuildEvents(t *testing.T) {
+	table := pricing.NewTable(map[string]pricing.ModelPrice{"gpt-4o": {InputPerMillion: 2, OutputPerMillion: 8}})
+
+	events := BuildEvents(testTrace(), table)
+
+	var types, ids []string
+	for _, event := range events {
+		types = append(types, event.Type)
+		ids = append(ids, event.ID)
+	}
+	wantTypes := []string{EventTraceCreate, EventSpanCreate, EventGenerationCreate, EventSpanCreate, EventEventCreate}
+	if !reflect.DeepEqual(types, wantTypes) {
+		t.Fatalf("event types = %v, want %v", types, wantTypes)
+	}
+	// Ids are stable so that re-sent traces are deduplicated
+	wantIDs := []string{"trace-1-trace", "root-observation", "llm-observation", "tool-observation", "tool-event-0"}
+	if !reflect.DeepEqual(ids, wantIDs) {
+		t.Errorf("event ids = %v, want %v", ids, wantIDs)
+	}
+
+	trace := events[0].Body.(TraceBody)
+	if trace.ID != "trace-1" || trace.Name != "invoke_agent" || trace.UserID != "user-1" || trace.SessionID != "session-1" {
+		t.Errorf("trace = %+v, want the id, name, user and session of the root span", trace)
+	}
+	wantMetadata := map[string]interface{}{"agent": "booking-agent", "componentUid": "comp-1", "environmentUid": "env-1"}
+	if !reflect.DeepEqual(trace.Metadata, wantMetadata) {
+		t.Errorf("trace metadata = %v, want %v", trace.Metadata, wantMetadata)
+	}
+	if !reflect.DeepEqual(trace.Tags, []string{"langchain"}) {
+		t.Errorf("trace tags = %v, want the framework", trace.Tags)
+	}
+
+	generation := events[2].Body.(ObservationBody)
+	if generation.ParentObservationID != "root" || generation.Model != "gpt-4o" || generation.Output != "done" {
+		t.Errorf("generation = %+v, want the parent, model and output of the LLM span", generation)
+	}
+	if generation.EndTime == nil || !generation.EndTime.Equal(traceStart.Add(2*time.Second)) {
+		t.Errorf("generation end = %v, want the end of the span", generation.EndTime)
+	}
+	if generation.CompletionStartTime == nil || !generation.CompletionStartTime.Equal(traceStart.Add(1250*time.Millisecond)) {
+		t.Errorf("completion start = %v, want the span start plus the time to first token", generation.CompletionStartTime)
+	}
+	if !reflect.DeepEqual(generation.ModelParameters, map[string]interface{}{"temperature": 0.2}) {
+		t.Errorf("model parameters = %v, want the temperature", generation.ModelParameters)
+	}
+	wantUsage := map[string]int{"input": 1000000, "output": 500000, "total": 1500000, "input_cached_tokens": 200000}
+	if !reflect.DeepEqual(generation.UsageDetails, wantUsage) {
+		t.Errorf("usage = %v, want %v", generation.UsageDetails, wantUsage)
+	}
+	if generation.CostDetails["input"] != 2 || generation.CostDetails["output"] != 4 || generation.CostDetails["total"] != 6 {
+		t.Errorf("cost = %v, want 2 input and 4 output", generation.CostDetails)
+	}
+	if generation.Metadata["kind"] != "llm" || generation.Metadata["spanKind"] != "CLIENT" {
+		t.Errorf("generation metadata = %v, want the span kinds", generation.Metadata)
+	}
+
+	tool := events[3].Body.(ObservationBody)
+	if tool.Level != LevelError || tool.StatusMessage != "upstream timed out" {
+		t.Errorf("tool level = %q, message = %q, want the error of the span", tool.Level, tool.StatusMessage)
+	}
+	if tool.Model != "" || tool.UsageDetails != nil {
+		t.Errorf("tool = %+v, want no generation fields on a span", tool)
+	}
+
+	event := events[4].Body.(ObservationBody)
+	if event.ParentObservationID != "tool" || event.Level != LevelError || event.StatusMessage != "upstream timed out" || event.EndTime != nil {
+		t.Errorf("event = %+v, want the exception under its span", event)
+	}
+}
+
+func TestBuildEventsWithoutPrices(t *testing.T) {
+	for _, table := range []*pricing.Table{nil, pricing.NewTable(nil)} {
+		events := BuildEvents(testTrace(), table)
+		if generation := events[2].Body.(ObservationBody); generation.CostDetails != nil || generation.UsageDetails == nil {
+			t.Errorf("generation = %+v, want the usage without cost", generation)
+		}
+	}
+}
+
+func TestBuildEventsOmitsMissingValues(t *testing.T) {
+	var input []opensearch.PromptMessage
+	trace := testTrace()
+	trace.Spans[2].AmpAttributes.Input = input
+
+	encoded, err := json.Marshal(BuildEvents(trace, nil)[3])
+	if err != nil {
+		t.Fatal(err)
+	}
+	var event struct {
+		Body map[string]interface{} `json:"body"`
+	}
+	if err := json.Unmarshal(encoded, &event); err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"input", "output", "model", "usageDetails"} {
+		if _, ok := event.Body[key]; ok {
+			t.Errorf("body = %v, want %s omitted", event.Body, key)
+		}
+	}
+}
+
+func TestValueOrNil(t *testing.T) {
+	var nilMap map[string]interface{}
+	var nilSlice []string
+	var nilPointer *string
+	for _, value := range []interface{}{nil, nilMap, nilSlice, nilPointer} {
+		if got := valueOrNil(value); got != nil {
+			t.Errorf("valueOrNil(%#v) = %#v, want nil", value, got)
+		}
+	}
+	for _, value := range []interface{}{"text", 0, []string{}, map[string]interface{}{}} {
+		if got := valueOrNil(value); got == nil {
+			t.Errorf("valueOrNil(%#v) = nil, want the value", value)
+		}
+	}
+}
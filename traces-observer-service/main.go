@@ -18,47 +18,137 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+
+	"github.com/wso2/ai-agent-management-platform/libs/go-common/compress"
+	"github.com/wso2/ai-agent-management-platform/libs/go-common/logging"
+	"github.com/wso2/ai-agent-management-platform/libs/go-common/tracing"
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/agentmanager"
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/archive"
 	"github.com/wso2/ai-agent-management-platform/traces-observer-service/config"
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/consumer"
 	"github.com/wso2/ai-agent-management-platform/traces-observer-service/controllers"
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/forwarding"
 	"github.com/wso2/ai-agent-management-platform/traces-observer-service/handlers"
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/langfuse"
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/metrics"
 	"github.com/wso2/ai-agent-management-platform/traces-observer-service/middleware"
 	"github.com/wso2/ai-agent-management-platform/traces-observer-service/middleware/logger"
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/notifications"
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/objectstore"
 	"github.com/wso2/ai-agent-management-platform/traces-observer-service/opensearch"
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/pricing"
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/querycache"
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/redaction"
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/reload"
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/sampling"
+)
+
+// Routes whose request limits differ from the other API routes
+const (
+	otlpTracesRoute   = "POST /v1/traces"
+	exportTracesRoute = "GET /api/v1/traces/export"
+	liveTracesRoute   = "GET /api/v1/traces/stream"
+	traceTailRoute    = "GET /api/v1/traces/{traceId}/live"
 )
 
+// deadLetterPurgeInterval is the longest time an expired dead letter is kept
+const deadLetterPurgeInterval = time.Hour
+
 func setupLogger(cfg *config.Config) {
-	var level slog.Level
-	switch cfg.LogLevel {
-	case "DEBUG":
-		level = slog.LevelDebug
-	case "INFO":
-		level = slog.LevelInfo
-	case "WARN":
-		level = slog.LevelWarn
-	case "ERROR":
-		level = slog.LevelError
-	default:
-		level = slog.LevelInfo // default to INFO
-	}
-
-	// Create handler options
-	opts := &slog.HandlerOptions{
-		Level: level,
-	}
-	handler := slog.NewJSONHandler(os.Stdout, opts)
-	slogger := slog.New(handler)
+	slogger, err := logging.New(os.Stdout, logging.Options{
+		Level: cfg.Logging.Level,
+		Sampling: logging.Sampling{
+			Initial:    cfg.Logging.SamplingInitial,
+			Thereafter: cfg.Logging.SamplingThereafter,
+			Interval:   cfg.Logging.SamplingInterval,
+		},
+	})
+	if err != nil {
+		slog.Error("Failed to configure logger", "error", err)
+		os.Exit(1)
+	}
 	slog.SetDefault(slogger)
 
+	if cfg.Logging.LevelFile != "" {
+		if _, err := logging.ReloadLevel(cfg.Logging.LevelFile, cfg.Logging.Level); err != nil {
+			slog.Warn("Failed to read log level file, keeping the configured level", "file", cfg.Logging.LevelFile, "error", err)
+		}
+	}
+
 	slog.Info("Logger configured",
-		"level", level.String())
+		"level", logging.Level().String())
+}
+
+// notifySIGHUP starts catching SIGHUP, which would otherwise stop the service before the reload handler runs
+func notifySIGHUP() <-chan os.Signal {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	return hup
+}
+
+// reloadOnSIGHUP re-reads the log level file on SIGHUP, or restores the configured level when there
+// is none, undoing changes made through the admin endpoint, and reloads the configuration
+func reloadOnSIGHUP(hup <-chan os.Signal, cfg config.LoggingConfig, configManager *reload.Manager) {
+	for range hup {
+		previous := logging.Level()
+		if level, err := logging.ReloadLevel(cfg.LevelFile, cfg.Level); err != nil {
+			slog.Error("Failed to reload log level", "file", cfg.LevelFile, "error", err)
+		} else {
+			slog.Warn("Log level reloaded", "level", level.String(), "previous", previous.String())
+		}
+		configManager.Reload("SIGHUP")
+	}
+}
+
+// newGRPCServer creates the OTLP/gRPC server with the configured message size limit and TLS
+func newGRPCServer(cfg config.OTLPGRPCConfig, ingestion *controllers.IngestionController) (*grpc.Server, error) {
+	opts := []grpc.ServerOption{
+		grpc.MaxRecvMsgSize(cfg.MaxRecvMsgBytes),
+	}
+	if cfg.TLSCertFile != "" {
+		creds, err := credentials.NewServerTLSFromFile(cfg.TLSCertFile, cfg.TLSKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load TLS credentials: %w", err)
+		}
+		opts = append(opts, grpc.Creds(creds))
+	}
+
+	server := grpc.NewServer(opts...)
+	coltracepb.RegisterTraceServiceServer(server, handlers.NewTraceServiceServer(ingestion))
+	return server, nil
+}
+
+// stopGRPCServer waits for in-flight exports to finish, forcing the server to stop after the timeout
+func stopGRPCServer(server *grpc.Server, timeout time.Duration) {
+	stopped := make(chan struct{})
+	go func() {
+		server.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+		slog.Info("OTLP gRPC server stopped")
+	case <-time.After(timeout):
+		slog.Warn("OTLP gRPC server did not drain in time, forcing stop")
+		server.Stop()
+	}
 }
 
 func main() {
@@ -71,37 +161,530 @@ func main() {
 
 	// Setup structured logging
 	setupLogger(cfg)
+	hup := notifySIGHUP()
+
+	// Rebuild trace indices whose mapping cannot be updated in place, instead of serving
+	if len(os.Args) > 1 && os.Args[1] == "reindex" {
+		os.Exit(runReindex(cfg, os.Args[2:]))
+	}
 
 	slog.Info("Starting tracing service", "port", cfg.Server.Port)
 
+	// Record traces of the service itself when an exporter endpoint is configured
+	shutdownTracing, err := tracing.Setup(context.Background(), tracing.Config{
+		Endpoint:    cfg.Tracing.OTLPEndpoint,
+		SampleRatio: cfg.Tracing.SampleRatio,
+		ServiceName: "traces-observer-service",
+	})
+	if err != nil {
+		slog.Error("Failed to set up self-tracing", "error", err)
+		os.Exit(1)
+	}
+	if cfg.Tracing.OTLPEndpoint != "" {
+		slog.Info("Self-tracing enabled", "endpoint", cfg.Tracing.OTLPEndpoint, "sampleRatio", cfg.Tracing.SampleRatio)
+	}
+
+	// Register the Prometheus metrics of the service, nil metrics record nothing
+	var serviceMetrics *metrics.Metrics
+	registry := prometheus.NewRegistry()
+	if cfg.Metrics.PrometheusEnabled {
+		registry.MustRegister(
+			collectors.NewGoCollector(),
+			collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		)
+		serviceMetrics = metrics.New(registry)
+	}
+
 	// Initialize OpenSearch client
-	osClient, err := opensearch.NewClient(&cfg.OpenSearch)
+	osClient, err := opensearch.NewClient(&cfg.OpenSearch, serviceMetrics)
 	if err != nil {
 		slog.Error("Failed to create OpenSearch client", "error", err)
 		os.Exit(1)
 	}
 
+	// Documents without an organization belong to the default one
+	opensearch.SetDefaultOrgID(cfg.Tenancy.DefaultOrgID)
+
+	// Explicit mapping of the trace indices, installed when mappings are managed
+	if err := opensearch.SetTraceMapping(traceMapping(cfg)); err != nil {
+		slog.Error("Invalid trace index mapping", "error", err)
+		os.Exit(1)
+	}
+
+	// The service becomes ready once OpenSearch answers, the index template is installed (when mappings are managed),
+	// the annotation index exists and existing documents carry an organization; until then the setup is retried in the background
+	readinessCtx, stopReadiness := context.WithCancel(context.Background())
+	defer stopReadiness()
+	readiness := controllers.NewReadiness(osClient, func(ctx context.Context) error {
+		// Install the index template and add missing fields to the mappings of existing indices
+		if cfg.OpenSearch.ManageMappings {
+			if err := osClient.EnsureTraceMappings(ctx); err != nil {
+				// Retrying does not resolve incompatible field types, the listed indices must be reindexed
+				var conflictErr *opensearch.MappingConflictError
+				if errors.As(err, &conflictErr) {
+					slog.Error("Trace index mappings are incompatible", "error", err)
+					os.Exit(1)
+				}
+				return fmt.Errorf("failed to manage OpenSearch mappings: %w", err)
+			}
+		}
+		// Create the trace annotation index
+		if err := osClient.EnsureAnnotationIndex(ctx); err != nil {
+			return fmt.Errorf("failed to create trace annotation index: %w", err)
+		}
+		// Assign the default organization to documents indexed before organizations were recorded
+		if err := osClient.EnsureOrgIDs(ctx); err != nil {
+			return fmt.Errorf("failed to backfill organizations: %w", err)
+		}
+		return nil
+	}, cfg.Server.ReadinessRetryInterval)
+	readiness.Start(readinessCtx)
+
+	// Manage daily trace indices, the write alias and retention
+	var lifecycleManager *opensearch.LifecycleManager
+	if cfg.Lifecycle.Enabled {
+		lifecycleManager = osClient.NewLifecycleManager(opensearch.LifecycleConfig{
+			RetentionDays: cfg.Lifecycle.RetentionDays,
+			CheckInterval: cfg.Lifecycle.CheckInterval,
+			Throttle: opensearch.DeleteByQueryThrottle{
+				RequestsPerSecond: cfg.Lifecycle.RetentionRequestsPerSecond,
+				BatchSize:         cfg.Lifecycle.RetentionBatchSize,
+			},
+		})
+		if err := lifecycleManager.Start(context.Background()); err != nil {
+			slog.Error("Failed to start index lifecycle management", "error", err)
+			os.Exit(1)
+		}
+	}
+
+	// Configure span size limits
+	opensearch.SetLimits(opensearch.Limits{
+		MaxInputBytes:        cfg.Limits.MaxInputBytes,
+		MaxOutputBytes:       cfg.Limits.MaxOutputBytes,
+		MaxSystemPromptBytes: cfg.Limits.MaxSystemPromptBytes,
+		MaxAttributeBytes:    cfg.Limits.MaxAttributeBytes,
+		MaxParseBytes:        cfg.Limits.MaxParseBytes,
+	})
+
+	// Configure the custom attribute passthrough
+	opensearch.SetPassthrough(opensearch.Passthrough{
+		AllowedPrefixes: cfg.Passthrough.AllowedPrefixes,
+		DeniedPrefixes:  cfg.Passthrough.DeniedPrefixes,
+		MaxValueBytes:   cfg.Passthrough.MaxValueBytes,
+		MaxAttributes:   cfg.Passthrough.MaxAttributes,
+	})
+
+	// Configure PII redaction
+	if cfg.Redaction.Enabled {
+		rules := redaction.DefaultRules
+		if cfg.Redaction.RulesPath != "" {
+			rules, err = redaction.LoadRules(cfg.Redaction.RulesPath)
+			if err != nil {
+				slog.Error("Failed to load redaction rules", "path", cfg.Redaction.RulesPath, "error", err)
+				os.Exit(1)
+			}
+		}
+		redactor, err := redaction.NewRegexRedactor(rules)
+		if err != nil {
+			slog.Error("Failed to create redactor", "error", err)
+			os.Exit(1)
+		}
+		opensearch.SetRedactor(redactor)
+		slog.Info("PII redaction enabled", "rules", len(rules))
+	}
+
+	// Keep the images and audio replaced by placeholders in object storage
+	if cfg.Media.StoreEnabled {
+		mediaClient, err := objectstore.NewS3Client(objectstore.S3Config{
+			Endpoint:        cfg.Media.Endpoint,
+			Region:          cfg.Media.Region,
+			Bucket:          cfg.Media.Bucket,
+			AccessKeyID:     cfg.Media.AccessKeyID,
+			SecretAccessKey: cfg.Media.SecretAccessKey,
+			SessionToken:    cfg.Media.SessionToken,
+			PathStyle:       cfg.Media.PathStyle,
+			RequestTimeout:  cfg.Media.RequestTimeout,
+		})
+		if err != nil {
+			slog.Error("Failed to create media store client", "error", err)
+			os.Exit(1)
+		}
+		opensearch.SetMediaStore(objectstore.NewMediaStore(mediaClient, cfg.Media.Prefix))
+		slog.Info("Media store enabled", "bucket", cfg.Media.Bucket)
+	}
+
+	// Load model pricing table
+	pricingTable := pricing.DefaultTable()
+	if cfg.Pricing.TablePath != "" {
+		pricingTable, err = pricing.LoadTable(cfg.Pricing.TablePath)
+		if err != nil {
+			slog.Error("Failed to load pricing table", "path", cfg.Pricing.TablePath, "error", err)
+			os.Exit(1)
+		}
+		slog.Info("Loaded pricing table", "path", cfg.Pricing.TablePath)
+	}
+
+	// Keep documents that cannot be indexed in the dead-letter index so that they can be retried
+	var deadLetterStore *opensearch.DeadLetterStore
+	if cfg.Indexing.DeadLetterIndexEnabled {
+		deadLetterStore = osClient.NewDeadLetterStore(cfg.Indexing.DeadLetterRetention)
+	}
+
+	// Initialize bulk indexer
+	indexer, err := osClient.NewBulkIndexer(opensearch.BulkIndexerConfig{
+		MaxBatchDocs:    cfg.Indexing.BatchSize,
+		MaxBatchBytes:   cfg.Indexing.BatchBytes,
+		FlushInterval:   cfg.Indexing.FlushInterval,
+		MaxInFlight:     cfg.Indexing.MaxInFlight,
+		MaxRetries:      cfg.Indexing.MaxRetries,
+		DeadLetterPath:  cfg.Indexing.DeadLetterPath,
+		DeadLetterStore: deadLetterStore,
+		MaxSpillDocs:    cfg.Indexing.MaxSpillDocs,
+		Metrics:         serviceMetrics,
+	})
+	if err != nil {
+		slog.Error("Failed to create bulk indexer", "error", err)
+		os.Exit(1)
+	}
+
+	// Archive expired daily indices to object storage before deleting them
+	var archiver *archive.Archiver
+	if cfg.Archive.Enabled {
+		objects, err := objectstore.NewS3Client(objectstore.S3Config{
+			Endpoint:        cfg.Archive.Endpoint,
+			Region:          cfg.Archive.Region,
+			Bucket:          cfg.Archive.Bucket,
+			AccessKeyID:     cfg.Archive.AccessKeyID,
+			SecretAccessKey: cfg.Archive.SecretAccessKey,
+			SessionToken:    cfg.Archive.SessionToken,
+			PathStyle:       cfg.Archive.PathStyle,
+			RequestTimeout:  cfg.Archive.RequestTimeout,
+		})
+		if err != nil {
+			slog.Error("Failed to create archive client", "error", err)
+			os.Exit(1)
+		}
+		archiver = archive.NewArchiver(archive.Config{
+			AfterDays:        cfg.Archive.AfterDays,
+			Interval:         cfg.Archive.Interval,
+			DryRun:           cfg.Archive.DryRun,
+			Prefix:           cfg.Archive.Prefix,
+			PathTemplate:     cfg.Archive.PathTemplate,
+			MaxDocsPerObject: cfg.Archive.MaxDocsPerObject,
+			LookupIndices:    cfg.Archive.LookupIndices,
+		}, osClient, objects)
+	}
+
 	// Initialize service
-	tracingController := controllers.NewTracingController(osClient)
+	queryCacheOptions := controllers.QueryCacheOptions{
+		MetricsTTL: cfg.QueryCache.MetricsTTL,
+		ToolsTTL:   cfg.QueryCache.ToolsTTL,
+		ModelsTTL:  cfg.QueryCache.ModelsTTL,
+	}
+	if cfg.QueryCache.Enabled {
+		queryCacheOptions.Cache = querycache.New(cfg.QueryCache.MaxEntries, int64(cfg.QueryCache.MaxBytes))
+	}
+	tracingController := controllers.NewTracingController(osClient, pricingTable, controllers.TracingOptions{
+		MaxMetricsBuckets: cfg.Metrics.MaxBuckets,
+		MaxExportRows:     cfg.Export.MaxRows,
+		Archive:           archiver,
+		Finalization: opensearch.FinalizationPolicy{
+			QuietPeriod: cfg.Finalization.QuietPeriod,
+			MaxAge:      cfg.Finalization.MaxAge,
+		},
+		QueryCache: queryCacheOptions,
+	})
+	var sampler *sampling.TailSampler
+	if cfg.Sampling.Enabled {
+		sampler = sampling.NewTailSampler(sampling.Config{
+			DecisionWait:      cfg.Sampling.DecisionWait,
+			DurationThreshold: cfg.Sampling.DurationThreshold,
+			TokenThreshold:    cfg.Sampling.TokenThreshold,
+			SamplePercentage:  cfg.Sampling.SamplePercentage,
+			MaxBufferedBytes:  cfg.Sampling.MaxBufferedBytes,
+		}, indexer, pricingTable, serviceMetrics)
+		slog.Info("Tail sampling enabled", "decisionWait", cfg.Sampling.DecisionWait, "percentage", cfg.Sampling.SamplePercentage)
+	}
+
+	// Start webhook notifications for failed, slow and costly traces
+	var notifier *notifications.Notifier
+	var notificationController *controllers.NotificationController
+	if cfg.Notifications.Enabled {
+		ruleStore := notifications.NewStore(osClient)
+		notifier = notifications.NewNotifier(notifications.Config{
+			Finalization: opensearch.FinalizationPolicy{
+				QuietPeriod: cfg.Finalization.QuietPeriod,
+				MaxAge:      cfg.Finalization.MaxAge,
+			},
+			MaxPendingTraces:    cfg.Notifications.MaxPendingTraces,
+			RuleRefreshInterval: cfg.Notifications.RuleRefreshInterval,
+			Workers:             cfg.Notifications.Workers,
+			QueueSize:           cfg.Notifications.QueueSize,
+			MaxRetries:          cfg.Notifications.MaxRetries,
+			RetryBackoff:        cfg.Notifications.RetryBackoff,
+			RequestTimeout:      cfg.Notifications.RequestTimeout,
+			TraceLinkTemplate:   cfg.Notifications.TraceLinkTemplate,
+		}, ruleStore, pricingTable)
+		if err := notifier.Start(context.Background()); err != nil {
+			slog.Error("Failed to start notifications", "error", err)
+			os.Exit(1)
+		}
+		notificationController = controllers.NewNotificationController(ruleStore, notifier)
+		slog.Info("Notifications enabled", "rules", notifier.Stats().Rules)
+	}
+
+	// Re-export processed spans to downstream OTLP endpoints
+	var forwarder *forwarding.Forwarder
+	if cfg.Forwarding.DestinationsPath != "" {
+		destinations, err := forwarding.LoadDestinations(cfg.Forwarding.DestinationsPath)
+		if err != nil {
+			slog.Error("Failed to load forwarding destinations", "error", err)
+			os.Exit(1)
+		}
+		forwarder = forwarding.NewForwarder(forwarding.Config{
+			BatchSize:      cfg.Forwarding.BatchSize,
+			FlushInterval:  cfg.Forwarding.FlushInterval,
+			QueueSize:      cfg.Forwarding.QueueSize,
+			MaxRetries:     cfg.Forwarding.MaxRetries,
+			RetryBackoff:   cfg.Forwarding.RetryBackoff,
+			RequestTimeout: cfg.Forwarding.RequestTimeout,
+		}, destinations, pricingTable)
+		slog.Info("Span forwarding enabled", "destinations", len(forwarder.Stats()))
+	}
+
+	// Stream the traces being ingested to live views
+	var liveTraces *controllers.LiveTraces
+	if cfg.LiveTraces.Enabled {
+		liveTraces = controllers.NewLiveTraces(controllers.LiveTracesConfig{
+			Finalization: opensearch.FinalizationPolicy{
+				QuietPeriod: cfg.Finalization.QuietPeriod,
+				MaxAge:      cfg.Finalization.MaxAge,
+			},
+			MaxTraces:      cfg.LiveTraces.MaxTraces,
+			MaxClients:     cfg.LiveTraces.MaxClients,
+			ClientBuffer:   cfg.LiveTraces.ClientBuffer,
+			UpdateInterval: cfg.LiveTraces.UpdateInterval,
+		}, pricingTable)
+		liveTraces.Start()
+	}
+	// Tail single traces over WebSocket along with the live trace stream
+	var traceTails *controllers.TraceTails
+	if cfg.LiveTraces.Enabled {
+		traceTails = controllers.NewTraceTails(controllers.TraceTailsConfig{
+			Finalization: opensearch.FinalizationPolicy{
+				QuietPeriod: cfg.Finalization.QuietPeriod,
+				MaxAge:      cfg.Finalization.MaxAge,
+			},
+			MaxTails:          cfg.LiveTraces.TailMaxConnections,
+			MaxTailsPerClient: cfg.LiveTraces.TailMaxConnectionsPerClient,
+			Buffer:            cfg.LiveTraces.ClientBuffer,
+			MaxJournal:        cfg.LiveTraces.TailMaxJournal,
+			CatchUpDelay:      cfg.LiveTraces.TailCatchUpDelay,
+		}, tracingController.GetTraceSpans, pricingTable)
+		traceTails.Start()
+	}
+
+	// Process received spans on a bounded pool of workers
+	processingPool := controllers.NewProcessingPool(cfg.Processing.Workers, cfg.Processing.MaxQueuedSpans, serviceMetrics)
+	orgResolver := controllers.NewOrgResolver(cfg.Tenancy.IngestKeys, cfg.Tenancy.OrgAttribute, cfg.Tenancy.DefaultOrgID)
+
+	// Link spans to the managed agents that produced them and copy the labels of the agents onto them
+	var agentLabeler *controllers.AgentLabeler
+	if cfg.AgentLabels.ManagerURL != "" {
+		agentClient := agentmanager.NewClient(cfg.AgentLabels.ManagerURL, cfg.AgentLabels.APIKeyHeader, cfg.AgentLabels.APIKey, cfg.AgentLabels.RequestTimeout)
+		labelCache := agentmanager.NewLabelCache(agentClient, cfg.AgentLabels.CacheTTL, cfg.AgentLabels.MaxCachedAgents)
+		nameCache := agentmanager.NewNameCache(agentClient, cfg.AgentLabels.CacheTTL, cfg.AgentLabels.MaxCachedAgents)
+		agentLabeler = controllers.NewAgentLabeler(labelCache, nameCache, cfg.AgentLabels.AgentAttribute)
+		slog.Info("Agent labels enabled", "managerUrl", cfg.AgentLabels.ManagerURL, "agentAttribute", cfg.AgentLabels.AgentAttribute)
+	}
+	// Roll up the token usage, errors and guardrail violations of indexed traces onto their root span
+	traceRollups := controllers.NewTraceRollups(controllers.TraceRollupsConfig{
+		Delay:            cfg.Finalization.RollupDelay,
+		MaxPendingTraces: cfg.Finalization.MaxPendingRollups,
+	}, osClient)
+	traceRollups.Start()
+	ingestionController := controllers.NewIngestionController(indexer, sampler, notifier, forwarder, liveTraces, traceTails, processingPool, orgResolver, agentLabeler, traceRollups, cfg.OTLP.MaxRequestBytes, serviceMetrics)
 
 	// Initialize handlers
-	handler := handlers.NewHandler(tracingController)
+	handler := handlers.NewHandler(tracingController, ingestionController, notificationController)
+	handler.SetReadiness(readiness)
+	handler.SetAdminKey(cfg.Admin.APIKey)
+	if liveTraces != nil {
+		handler.SetLiveTraces(liveTraces, cfg.LiveTraces.HeartbeatInterval)
+		handler.AddHealthStats("liveTraces", func() interface{} { return liveTraces.Stats() })
+	}
+	handler.AddHealthStats("traceRollups", func() interface{} { return traceRollups.Stats() })
+
+	// Browse and retry dead letters, expired ones are purged in the background
+	purgeCtx, stopPurge := context.WithCancel(context.Background())
+	defer stopPurge()
+	if deadLetterStore != nil {
+		handler.SetDeadLetters(controllers.NewDeadLetterController(deadLetterStore, indexer, serviceMetrics))
+		handler.AddHealthStats("deadLetters", func() interface{} { return deadLetterStore.Stats() })
+		go deadLetterStore.RunPurge(purgeCtx, min(cfg.Indexing.DeadLetterRetention, deadLetterPurgeInterval))
+		slog.Info("Dead-letter index enabled", "retention", cfg.Indexing.DeadLetterRetention)
+	}
+
+	// Derive the fields of indexed spans again, e.g. after a framework processor is added
+	reprocessController := controllers.NewReprocessController(osClient, traceRollups)
+	handler.SetReprocess(reprocessController)
+
+	// Evaluate alert rules on aggregate trace metrics in the background
+	var alertEvaluator *notifications.AlertEvaluator
+	if cfg.Alerts.Enabled {
+		alertStore := notifications.NewAlertStore(osClient)
+		alertEvaluator = notifications.NewAlertEvaluator(notifications.AlertConfig{
+			EvaluationInterval: cfg.Alerts.EvaluationInterval,
+			QueueSize:          cfg.Alerts.QueueSize,
+			MaxRetries:         cfg.Alerts.MaxRetries,
+			RetryBackoff:       cfg.Alerts.RetryBackoff,
+			RequestTimeout:     cfg.Alerts.RequestTimeout,
+		}, alertStore, osClient, pricingTable)
+		if err := alertEvaluator.Start(context.Background()); err != nil {
+			slog.Error("Failed to start alerting", "error", err)
+			os.Exit(1)
+		}
+		handler.SetAlerts(controllers.NewAlertController(alertStore, alertEvaluator))
+		slog.Info("Alerting enabled", "evaluationInterval", cfg.Alerts.EvaluationInterval)
+	}
+
+	// Export finalized traces to Langfuse in the background
+	var langfuseExporter *langfuse.Exporter
+	if cfg.Langfuse.Enabled {
+		langfuseClient := langfuse.NewClient(cfg.Langfuse.Host, cfg.Langfuse.PublicKey, cfg.Langfuse.SecretKey,
+			cfg.Langfuse.RequestTimeout, cfg.Langfuse.MaxRetries, cfg.Langfuse.RetryBackoff)
+		langfuseExporter = langfuse.NewExporter(langfuse.Config{
+			PollInterval: cfg.Langfuse.PollInterval,
+			FinalizeWait: cfg.Langfuse.FinalizeWait,
+			BatchSize:    cfg.Langfuse.BatchSize,
+			Lookback:     cfg.Langfuse.Lookback,
+		}, osClient, langfuseClient, pricingTable)
+		if err := langfuseExporter.Start(context.Background()); err != nil {
+			slog.Error("Failed to start Langfuse export", "error", err)
+			os.Exit(1)
+		}
+		handler.AddHealthStats("langfuse", func() interface{} { return langfuseExporter.Stats() })
+		slog.Info("Langfuse export enabled", "host", cfg.Langfuse.Host)
+	}
+
+	if archiver != nil {
+		if err := archiver.Start(context.Background()); err != nil {
+			slog.Error("Failed to start trace archival", "error", err)
+			os.Exit(1)
+		}
+		handler.AddHealthStats("archive", func() interface{} { return archiver.Stats() })
+		slog.Info("Trace archival enabled", "bucket", cfg.Archive.Bucket, "afterDays", cfg.Archive.AfterDays, "dryRun", cfg.Archive.DryRun)
+	}
+
+	if lifecycleManager != nil {
+		handler.SetRetention(lifecycleManager)
+		handler.AddHealthStats("retention", func() interface{} { return lifecycleManager.Stats() })
+	}
+
+	handler.AddHealthStats("logging", func() interface{} { return logging.GetStats() })
+
+	if queryCache := queryCacheOptions.Cache; queryCache != nil {
+		handler.AddHealthStats("queryCache", func() interface{} { return queryCache.Stats() })
+		slog.Info("Query cache enabled", "maxEntries", cfg.QueryCache.MaxEntries, "maxBytes", cfg.QueryCache.MaxBytes)
+	}
+
+	// Apply changed pricing, redaction, CORS and sampling rules without a restart
+	corsOrigins := middleware.NewOrigins(cfg.CORS.AllowedOrigins)
+	reloadComponents := []reload.Component{
+		reload.Pricing(pricingTable),
+		reload.Redaction(opensearch.SetRedactor),
+		reload.CORS(corsOrigins),
+	}
+	if sampler != nil {
+		reloadComponents = append(reloadComponents, reload.Sampling(sampler))
+	}
+	configManager := reload.NewManager(cfg, config.Load, reloadComponents...)
+	handler.SetConfigReloader(configManager)
+	handler.AddHealthStats("configReload", func() interface{} { return configManager.Stats() })
+	if traceTails != nil {
+		// Tails follow the CORS origins, including the ones set by a reload
+		handler.SetTraceTails(traceTails, corsOrigins, cfg.LiveTraces.TailClientIPHeader, cfg.LiveTraces.TailTrustedProxies)
+		handler.AddHealthStats("traceTails", func() interface{} { return traceTails.Stats() })
+	}
+	go reloadOnSIGHUP(hup, cfg.Logging, configManager)
+	reloadCtx, stopReload := context.WithCancel(context.Background())
+	defer stopReload()
+	if cfg.Reload.WatchInterval > 0 {
+		go configManager.Watch(reloadCtx, cfg.Reload.WatchInterval)
+	}
 
 	// Setup routes
 	mux := http.NewServeMux()
-	mux.HandleFunc("/api/v1/traces", handler.GetTraceOverviews)
-	mux.HandleFunc("/api/v1/trace", handler.GetTraceByIdAndService)
+	// Trace queries only reach the documents of the organization named by the request
+	mux.HandleFunc("/api/v1/traces", handler.RequireOrg(handler.GetTraceOverviews))
+	mux.HandleFunc("/api/v1/trace", handler.RequireOrg(handler.GetTraceByIdAndService))
+	mux.HandleFunc("GET /api/v1/traces/search", handler.RequireOrg(handler.SearchSpans))
+	mux.HandleFunc("GET /api/v1/traces/compare", handler.RequireOrg(handler.CompareTraces))
+	mux.HandleFunc(exportTracesRoute, handler.RequireOrg(handler.DownloadTraces))
+	if liveTraces != nil {
+		mux.HandleFunc(liveTracesRoute, handler.RequireOrg(handler.StreamTraces))
+	}
+	mux.HandleFunc("GET /api/v1/traces/{traceId}", handler.RequireOrg(handler.GetTraceTree))
+	if traceTails != nil {
+		mux.HandleFunc(traceTailRoute, handler.RequireOrg(handler.TailTrace))
+	}
+	mux.HandleFunc("GET /api/v1/traces/{traceId}/replay", handler.RequireOrg(handler.GetReplayBundle))
+	mux.HandleFunc("POST /api/v1/traces/{traceId}/annotations", handler.RequireOrg(handler.CreateAnnotation))
+	mux.HandleFunc("GET /api/v1/traces/{traceId}/annotations", handler.RequireOrg(handler.GetAnnotations))
+	mux.HandleFunc("DELETE /api/v1/traces/{traceId}/annotations/{annotationId}", handler.RequireOrg(handler.DeleteAnnotation))
+	mux.HandleFunc("GET /api/v1/sessions/{sessionId}/traces", handler.RequireOrg(handler.GetSessionTraces))
+	mux.HandleFunc("GET /api/v1/metrics/traces", handler.RequireOrg(handler.GetTraceMetrics))
+	mux.HandleFunc("GET /api/v1/metrics/tools", handler.RequireOrg(handler.GetToolMetrics))
+	mux.HandleFunc("GET /api/v1/metrics/models", handler.RequireOrg(handler.GetModelMetrics))
+	if notificationController != nil {
+		mux.HandleFunc("GET /api/v1/notifications/rules", handler.ListNotificationRules)
+		mux.HandleFunc("POST /api/v1/notifications/rules", handler.CreateNotificationRule)
+		mux.HandleFunc("GET /api/v1/notifications/rules/{ruleId}", handler.GetNotificationRule)
+		mux.HandleFunc("PUT /api/v1/notifications/rules/{ruleId}", handler.UpdateNotificationRule)
+		mux.HandleFunc("DELETE /api/v1/notifications/rules/{ruleId}", handler.DeleteNotificationRule)
+	}
+	if alertEvaluator != nil {
+		mux.HandleFunc("GET /api/v1/alerts", handler.ListAlerts)
+		mux.HandleFunc("GET /api/v1/alerts/rules", handler.ListAlertRules)
+		mux.HandleFunc("POST /api/v1/alerts/rules", handler.CreateAlertRule)
+		mux.HandleFunc("GET /api/v1/alerts/rules/{ruleId}", handler.GetAlertRule)
+		mux.HandleFunc("PUT /api/v1/alerts/rules/{ruleId}", handler.UpdateAlertRule)
+		mux.HandleFunc("DELETE /api/v1/alerts/rules/{ruleId}", handler.DeleteAlertRule)
+	}
+	mux.HandleFunc(otlpTracesRoute, handler.ExportTraces)
 	mux.HandleFunc("/health", handler.Health)
+	mux.HandleFunc("GET /healthz", handler.Healthz)
+	// The log level can be raised while running, for instance to debug the ingestion of one agent
+	mux.HandleFunc("GET /admin/log-level", handler.GetLogLevel)
+	mux.HandleFunc("PUT /admin/log-level", handler.SetLogLevel)
+	// Reloading, reprocessing, dead letters, which hold the raw documents, and the retention report are only served
+	// to holders of the admin key
+	if cfg.Admin.APIKey != "" {
+		mux.HandleFunc("POST /admin/reload", handler.ReloadConfig)
+		mux.HandleFunc("POST /admin/reprocess", handler.StartReprocess)
+		mux.HandleFunc("GET /admin/reprocess", handler.ListReprocess)
+		mux.HandleFunc("GET /admin/reprocess/{id}", handler.GetReprocess)
+		mux.HandleFunc("DELETE /admin/reprocess/{id}", handler.CancelReprocess)
+		if deadLetterStore != nil {
+			mux.HandleFunc("GET /admin/deadletter", handler.ListDeadLetters)
+			mux.HandleFunc("POST /admin/deadletter/{id}/retry", handler.RetryDeadLetter)
+			mux.HandleFunc("POST /admin/deadletter/retryAll", handler.RetryDeadLetters)
+		}
+		if lifecycleManager != nil {
+			mux.HandleFunc("GET /admin/retention/report", handler.RetentionReport)
+		}
+	}
+	mux.HandleFunc("GET /readyz", handler.Readyz)
+	if serviceMetrics != nil {
+		mux.Handle("GET /metrics", promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
+	}
 
-	// Apply middleware: Request Logger -> CORS
-	corsConfig := middleware.DefaultCORSConfig()
-	corsHandler := middleware.CORS(corsConfig)(mux)
-	loggerHandler := logger.RequestLogger()(corsHandler)
+	httpHandler := newHTTPHandler(cfg, mux, corsOrigins)
 
 	// Create server
 	server := &http.Server{
 		Addr:         fmt.Sprintf(":%d", cfg.Server.Port),
-		Handler:      loggerHandler,
+		Handler:      httpHandler,
 		ReadTimeout:  30 * time.Second,
 		WriteTimeout: 30 * time.Second,
 	}
@@ -115,21 +698,171 @@ func main() {
 		}
 	}()
 
+	// Start the OTLP/gRPC receiver
+	var grpcServer *grpc.Server
+	if cfg.OTLP.GRPC.Enabled {
+		grpcServer, err = newGRPCServer(cfg.OTLP.GRPC, ingestionController)
+		if err != nil {
+			slog.Error("Failed to create OTLP gRPC server", "error", err)
+			os.Exit(1)
+		}
+		listener, err := net.Listen("tcp", fmt.Sprintf(":%d", cfg.OTLP.GRPC.Port))
+		if err != nil {
+			slog.Error("Failed to listen for OTLP gRPC", "port", cfg.OTLP.GRPC.Port, "error", err)
+			os.Exit(1)
+		}
+		go func() {
+			slog.Info("OTLP gRPC receiver listening", "port", cfg.OTLP.GRPC.Port, "tls", cfg.OTLP.GRPC.TLSCertFile != "")
+			if err := grpcServer.Serve(listener); err != nil {
+				slog.Error("OTLP gRPC server failed", "error", err)
+				os.Exit(1)
+			}
+		}()
+	}
+
+	// Start the Kafka consumer
+	consumerCtx, stopConsumer := context.WithCancel(context.Background())
+	consumerDone := make(chan struct{})
+	if cfg.Kafka.Enabled {
+		kafkaConsumer, err := consumer.NewKafkaConsumer(cfg.Kafka, ingestionController)
+		if err != nil {
+			slog.Error("Failed to create Kafka consumer", "error", err)
+			os.Exit(1)
+		}
+		go func() {
+			defer close(consumerDone)
+			defer kafkaConsumer.Close()
+			if err := kafkaConsumer.Run(consumerCtx); err != nil {
+				slog.Error("Kafka consumer stopped", "error", err)
+			}
+		}()
+	} else {
+		close(consumerDone)
+	}
+
 	// Wait for interrupt signal
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 
-	slog.Info("Shutting down server...")
+	slog.Info("Shutting down server...", "timeout", cfg.Server.ShutdownTimeout)
+
+	// Settings are not swapped while components drain
+	stopReload()
+	stopPurge()
+	// Spans updated by a reprocessing job keep their new fields, the rest of its range is left for a new job
+	reprocessController.Close()
 
-	// Graceful shutdown
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	// Stop routing traffic to this replica while it drains
+	readiness.ShuttingDown()
+
+	// End the live trace streams, which would otherwise hold the server shutdown until its deadline
+	if liveTraces != nil {
+		liveTraces.Close()
+	}
+	// The server does not track the connections taken over by tails, so they are closed here
+	if traceTails != nil {
+		traceTails.Close()
+	}
+
+	// Graceful shutdown, every step shares the same deadline
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
 	defer cancel()
 
+	// Stop accepting requests and wait for the requests in flight, buffered spans are still drained when this times out
 	if err := server.Shutdown(ctx); err != nil {
 		slog.Error("Server forced to shutdown", "error", err)
-		os.Exit(1)
+	}
+
+	// Stop consuming, uncommitted messages are redelivered on restart
+	stopConsumer()
+	<-consumerDone
+
+	// Drain in-flight exports before flushing the indexer
+	if grpcServer != nil {
+		stopGRPCServer(grpcServer, cfg.OTLP.GRPC.ShutdownTimeout)
+	}
+
+	// All receivers are stopped, hand the queued and buffered spans to the indexer
+	if err := ingestionController.Shutdown(ctx); err != nil {
+		slog.Error("Failed to drain span ingestion", "error", err)
+	}
+
+	// Deliver queued notifications
+	if notifier != nil {
+		notifier.Close(ctx)
+	}
+
+	// Deliver queued alert notifications
+	if alertEvaluator != nil {
+		alertEvaluator.Close(ctx)
+	}
+
+	// Stop archival after the index being archived, an index archived but not yet deleted is deleted on the next start
+	if archiver != nil {
+		archiver.Close()
+	}
+
+	// Stop the Langfuse export after its current round, the checkpoint lets the next start resume
+	if langfuseExporter != nil {
+		langfuseExporter.Close(ctx)
+	}
+
+	// Export spans queued for forwarding
+	if forwarder != nil {
+		forwarder.Close(ctx)
+	}
+
+	// Flush queued documents before exiting
+	if err := indexer.Close(ctx); err != nil {
+		slog.Error("Failed to flush bulk indexer", "error", err)
+	}
+	slog.Info("Bulk indexer closed", "stats", indexer.Stats())
+
+	// Roll up the traces of the spans just flushed
+	traceRollups.Close(ctx)
+
+	if lifecycleManager != nil {
+		lifecycleManager.Stop()
+	}
+
+	// Export the spans recorded while draining
+	if err := shutdownTracing(ctx); err != nil {
+		slog.Error("Failed to flush self-tracing spans", "error", err)
 	}
 
 	slog.Info("Server exited")
 }
+
+// newHTTPHandler wraps the routes of the HTTP server in its middleware, from the outermost:
+//   - Tracing starts the span of the request first, so that the request log carries its trace id
+//     and requests rejected by CORS or the request limits are traced too. The span is named after
+//     the route template of mux rather than the request path
+//   - Request Logger logs every request, including rejected ones, with its correlation id
+//   - Compression compresses the responses of all handlers below it, rejections included
+//   - CORS answers preflight requests before they count against the request limits, allowing the
+//     origins of corsOrigins, which a reload can replace
+//   - Request Limits bound the body and duration of each request. Organization checks run in
+//     the handlers, so spans and logs of requests rejected for their organization are kept
+func newHTTPHandler(cfg *config.Config, mux *http.ServeMux, corsOrigins middleware.OriginMatcher) http.Handler {
+	// The OTLP receiver bounds its own bodies, exports stream for longer than an API request may take
+	// and live trace streams and tails stay open until the client leaves
+	limitsHandler := middleware.RequestLimits(mux, middleware.Limits{
+		MaxBodyBytes: int64(cfg.Server.MaxRequestBodyBytes),
+		Timeout:      cfg.Server.RequestTimeout,
+	}, map[string]middleware.Limits{
+		otlpTracesRoute:   {MaxBodyBytes: int64(cfg.OTLP.MaxRequestBytes), Timeout: cfg.Server.RequestTimeout},
+		exportTracesRoute: {MaxBodyBytes: int64(cfg.Server.MaxRequestBodyBytes), Timeout: cfg.Export.Timeout, Streaming: true},
+		liveTracesRoute:   {MaxBodyBytes: int64(cfg.Server.MaxRequestBodyBytes), Streaming: true},
+		traceTailRoute:    {MaxBodyBytes: int64(cfg.Server.MaxRequestBodyBytes), Streaming: true},
+	})(mux)
+	corsConfig := middleware.DefaultCORSConfig()
+	corsConfig.Origins = corsOrigins
+	corsHandler := middleware.CORS(corsConfig)(limitsHandler)
+	compressHandler := corsHandler
+	if cfg.Server.CompressionEnabled {
+		compressHandler = compress.Responses(compress.Config{MinSize: cfg.Server.CompressionMinBytes})(corsHandler)
+	}
+	loggerHandler := logger.RequestLogger()(compressHandler)
+	return tracing.Middleware(mux, tracing.MiddlewareConfig{})(loggerHandler)
+}
@@ -0,0 +1,158 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/wso2/ai-agent-management-platform/libs/go-common/logging"
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/config"
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/middleware"
+)
+
+// recordSpans routes the spans of the service to a recorder for the duration of the test
+func recordSpans(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	previousProvider, previousPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previousProvider)
+		otel.SetTextMapPropagator(previousPropagator)
+	})
+	return recorder
+}
+
+func TestHTTPHandlerNamesSpansAfterRouteTemplate(t *testing.T) {
+	recorder := recordSpans(t)
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	const pathTraceID = "0af7651916cd43dd8448eb211c80319c"
+
+	var loggedTraceID string
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/traces/{traceId}", func(w http.ResponseWriter, r *http.Request) {
+		loggedTraceID, _ = logging.TraceID(r.Context())
+		w.WriteHeader(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/traces/"+pathTraceID, nil)
+	req.Header.Set("traceparent", "00-"+traceID+"-00f067aa0ba902b7-01")
+	rec := httptest.NewRecorder()
+	newHTTPHandler(&config.Config{}, mux, middleware.NewOrigins([]string{"*"})).ServeHTTP(rec, req)
+
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("got %d spans, want 1", len(spans))
+	}
+	span := spans[0]
+	if span.Name() != "GET /api/v1/traces/{traceId}" {
+		t.Errorf("span name = %q, want the route template", span.Name())
+	}
+	attrs := map[string]string{}
+	for _, attr := range span.Attributes() {
+		value := attr.Value.Emit()
+		attrs[string(attr.Key)] = value
+		if strings.Contains(value, pathTraceID) {
+			t.Errorf("attribute %s = %q contains the raw path", attr.Key, value)
+		}
+	}
+	if attrs["http.route"] != "/api/v1/traces/{traceId}" {
+		t.Errorf("http.route = %q, want the route template", attrs["http.route"])
+	}
+	if attrs["http.response.status_code"] != "200" {
+		t.Errorf("http.response.status_code = %q, want 200", attrs["http.response.status_code"])
+	}
+	// The span continues the trace of the caller and the request logs carry its trace id
+	if got := span.SpanContext().TraceID().String(); got != traceID {
+		t.Errorf("span trace id = %s, want the trace id of the traceparent", got)
+	}
+	if got := span.Parent().SpanID().String(); got != "00f067aa0ba902b7" {
+		t.Errorf("span parent = %s, want the span id of the traceparent", got)
+	}
+	if loggedTraceID != traceID {
+		t.Errorf("logged trace id = %q, want %q", loggedTraceID, traceID)
+	}
+}
+
+func TestHTTPHandlerTracesRejectedRequests(t *testing.T) {
+	recorder := recordSpans(t)
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/v1/traces/{traceId}/annotations", func(w http.ResponseWriter, r *http.Request) {
+		t.Error("handler called for a request over the body limit")
+	})
+	cfg := &config.Config{Server: config.ServerConfig{MaxRequestBodyBytes: 8}}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/traces/abc/annotations", strings.NewReader(`{"label":"too long"}`))
+	rec := httptest.NewRecorder()
+	newHTTPHandler(cfg, mux, middleware.NewOrigins([]string{"*"})).ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("status = %d, want 413", rec.Code)
+	}
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("got %d spans, want 1", len(spans))
+	}
+	if spans[0].Name() != "POST /api/v1/traces/{traceId}/annotations" {
+		t.Errorf("span name = %q, want the route template", spans[0].Name())
+	}
+}
+
+func TestHTTPHandlerSpanNames(t *testing.T) {
+	mux := http.NewServeMux()
+	for _, pattern := range []string{"/api/v1/traces", "GET /api/v1/traces/{traceId}", traceTailRoute, "GET /api/v1/sessions/{sessionId}/traces", otlpTracesRoute} {
+		mux.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {})
+	}
+	handler := newHTTPHandler(&config.Config{}, mux, middleware.NewOrigins([]string{"*"}))
+
+	tests := []struct {
+		method string
+		path   string
+		want   string
+	}{
+		{http.MethodGet, "/api/v1/traces?limit=10", "GET /api/v1/traces"},
+		{http.MethodGet, "/api/v1/traces/0af7651916cd43dd8448eb211c80319c", "GET /api/v1/traces/{traceId}"},
+		{http.MethodGet, "/api/v1/traces/0af7651916cd43dd8448eb211c80319c/live", "GET /api/v1/traces/{traceId}/live"},
+		{http.MethodGet, "/api/v1/sessions/session-42/traces", "GET /api/v1/sessions/{sessionId}/traces"},
+		{http.MethodPost, "/v1/traces", "POST /v1/traces"},
+		{http.MethodGet, "/api/v1/unknown/session-42", "GET"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			recorder := recordSpans(t)
+			handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(tt.method, tt.path, nil))
+
+			spans := recorder.Ended()
+			if len(spans) != 1 {
+				t.Fatalf("got %d spans, want 1", len(spans))
+			}
+			if spans[0].Name() != tt.want {
+				t.Errorf("span name = %q, want %q", spans[0].Name(), tt.want)
+			}
+		})
+	}
+}
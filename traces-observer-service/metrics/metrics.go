@@ -0,0 +1,170 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package metrics
+
+import (
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// namespace prefixes the names of all metrics of the service
+const namespace = "traces_observer"
+
+// Reasons of dropped spans
+const (
+	DropReasonInvalid    = "invalid"     // Rejected by OTLP validation
+	DropReasonSampledOut = "sampled_out" // Dropped by the tail sampler, replaced by a rollup
+	DropReasonDeadLetter = "dead_letter" // Could not be indexed and went to the dead-letter log
+)
+
+// Results of bulk indexed documents
+const (
+	BulkResultIndexed = "indexed"
+	BulkResultRetried = "retried"
+	BulkResultFailed  = "failed"
+)
+
+// Metrics holds the Prometheus instrumentation of the ingestion pipeline and the OpenSearch client
+// A nil *Metrics is valid and records nothing, so components can be used without instrumentation
+type Metrics struct {
+	registerer prometheus.Registerer
+
+	spansReceived      prometheus.Counter
+	spansProcessed     *prometheus.CounterVec
+	processingDuration prometheus.Histogram
+	spansDropped       *prometheus.CounterVec
+	bulkDocuments      *prometheus.CounterVec
+	opensearchDuration *prometheus.HistogramVec
+}
+
+// New creates the metrics and registers them with the registerer
+// Tests pass their own prometheus.NewRegistry() to assert on the counters
+func New(registerer prometheus.Registerer) *Metrics {
+	m := &Metrics{
+		registerer: registerer,
+		spansReceived: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "spans_received_total",
+			Help:      "Spans received through OTLP/HTTP, OTLP/gRPC and Kafka, including rejected spans.",
+		}),
+		spansProcessed: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "spans_processed_total",
+			Help:      "Spans converted and enriched for indexing, by agent framework.",
+		}, []string{"framework"}),
+		processingDuration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "span_processing_duration_seconds",
+			Help:      "Time spent enriching a single span.",
+			Buckets:   prometheus.ExponentialBuckets(0.00001, 4, 9),
+		}),
+		spansDropped: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "spans_dropped_total",
+			Help:      "Spans that were not indexed, by reason.",
+		}, []string{"reason"}),
+		bulkDocuments: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "bulk_documents_total",
+			Help:      "Documents written with the bulk API, by result (indexed, retried or failed).",
+		}, []string{"result"}),
+		opensearchDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "opensearch_request_duration_seconds",
+			Help:      "Latency of OpenSearch requests until the response headers, by endpoint and status class.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"endpoint", "status"}),
+	}
+
+	registerer.MustRegister(
+		m.spansReceived,
+		m.spansProcessed,
+		m.processingDuration,
+		m.spansDropped,
+		m.bulkDocuments,
+		m.opensearchDuration,
+	)
+	return m
+}
+
+// SpansReceived counts received spans
+func (m *Metrics) SpansReceived(count int) {
+	if m == nil {
+		return
+	}
+	m.spansReceived.Add(float64(count))
+}
+
+// SpanProcessed counts a processed span and records its processing time
+func (m *Metrics) SpanProcessed(framework string, duration time.Duration) {
+	if m == nil {
+		return
+	}
+	if framework == "" {
+		framework = "unknown"
+	}
+	m.spansProcessed.WithLabelValues(framework).Inc()
+	m.processingDuration.Observe(duration.Seconds())
+}
+
+// SpansDropped counts spans that were not indexed
+func (m *Metrics) SpansDropped(reason string, count int) {
+	if m == nil || count <= 0 {
+		return
+	}
+	m.spansDropped.WithLabelValues(reason).Add(float64(count))
+}
+
+// BulkDocuments counts bulk indexed documents by result
+func (m *Metrics) BulkDocuments(result string, count int) {
+	if m == nil || count <= 0 {
+		return
+	}
+	m.bulkDocuments.WithLabelValues(result).Add(float64(count))
+}
+
+// OpenSearchRequest records the latency of an OpenSearch request
+func (m *Metrics) OpenSearchRequest(endpoint, status string, duration time.Duration) {
+	if m == nil {
+		return
+	}
+	m.opensearchDuration.WithLabelValues(endpoint, status).Observe(duration.Seconds())
+}
+
+// ObserveQueueDepth exports the depth of a queue, read from depth on every scrape
+func (m *Metrics) ObserveQueueDepth(queue string, depth func() int) {
+	if m == nil {
+		return
+	}
+	gauge := prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace:   namespace,
+		Name:        "queue_depth",
+		Help:        "Items waiting in a queue of the ingestion pipeline.",
+		ConstLabels: prometheus.Labels{"queue": queue},
+	}, func() float64 {
+		return float64(depth())
+	})
+	if err := m.registerer.Register(gauge); err != nil {
+		var registered prometheus.AlreadyRegisteredError
+		if !errors.As(err, &registered) {
+			slog.Error("Failed to register queue depth metric", "queue", queue, "error", err)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// gathered returns the values of the counters, gauges and histogram sample counts of a registry
+// keyed by metric name and label values
+func gathered(t *testing.T, registry *prometheus.Registry) map[string]float64 {
+	t.Helper()
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatalf("Gather() error = %v", err)
+	}
+	values := map[string]float64{}
+	for _, family := range families {
+		for _, metric := range family.GetMetric() {
+			key := family.GetName()
+			for _, label := range metric.GetLabel() {
+				key += "," + label.GetValue()
+			}
+			switch {
+			case metric.GetCounter() != nil:
+				values[key] = metric.GetCounter().GetValue()
+			case metric.GetGauge() != nil:
+				values[key] = metric.GetGauge().GetValue()
+			case metric.GetHistogram() != nil:
+				values[key] = float64(metric.GetHistogram().GetSampleCount())
+			}
+		}
+	}
+	return values
+}
+
+func TestMetrics(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	m := New(registry)
+
+	m.SpansReceived(3)
+	m.SpanProcessed("crewai", time.Millisecond)
+	m.SpanProcessed("", time.Millisecond)
+	m.SpansDropped(DropReasonSampledOut, 2)
+	m.SpansDropped(DropReasonInvalid, 0)
+	m.BulkDocuments(BulkResultIndexed, 5)
+	m.BulkDocuments(BulkResultFailed, 1)
+	m.OpenSearchRequest("_bulk", "2xx", 10*time.Millisecond)
+	m.OpenSearchRequest("_bulk", "2xx", 20*time.Millisecond)
+
+	want := map[string]float64{
+		"traces_observer_spans_received_total":                          3,
+		"traces_observer_spans_processed_total,crewai":                  1,
+		"traces_observer_spans_processed_total,unknown":                 1,
+		"traces_observer_span_processing_duration_seconds":              2,
+		"traces_observer_spans_dropped_total,sampled_out":               2,
+		"traces_observer_bulk_documents_total,indexed":                  5,
+		"traces_observer_bulk_documents_total,failed":                   1,
+		"traces_observer_opensearch_request_duration_seconds,_bulk,2xx": 2,
+	}
+	got := gathered(t, registry)
+	for key, value := range want {
+		if got[key] != value {
+			t.Errorf("%s = %v, want %v", key, got[key], value)
+		}
+	}
+	// Empty counts do not create a series
+	if _, ok := got["traces_observer_spans_dropped_total,invalid"]; ok {
+		t.Error("series created for zero dropped spans")
+	}
+}
+
+func TestObserveQueueDepth(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	m := New(registry)
+
+	depth := 4
+	m.ObserveQueueDepth("bulk", func() int { return depth })
+	// A queue observed again, such as after a reload, keeps the first registration
+	m.ObserveQueueDepth("bulk", func() int { return 100 })
+	m.ObserveQueueDepth("processing", func() int { return 1 })
+
+	depth = 7
+	got := gathered(t, registry)
+	if got["traces_observer_queue_depth,bulk"] != 7 || got["traces_observer_queue_depth,processing"] != 1 {
+		t.Errorf("queue depths = %v, want bulk 7 and processing 1", got)
+	}
+}
+
+func TestNilMetricsRecordNothing(t *testing.T) {
+	var m *Metrics
+	m.SpansReceived(1)
+	m.SpanProcessed("crewai", time.Millisecond)
+	m.SpansDropped(DropReasonInvalid, 1)
+	m.BulkDocuments(BulkResultIndexed, 1)
+	m.OpenSearchRequest("_search", "2xx", time.Millisecond)
+	m.ObserveQueueDepth("bulk", func() int { return 1 })
+}
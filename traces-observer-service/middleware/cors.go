@@ -19,11 +19,49 @@ package middleware
 import (
 	"net/http"
 	"strconv"
+	"sync/atomic"
 )
 
+// OriginMatcher returns the Access-Control-Allow-Origin of a request from its origin, empty when the origin is not allowed
+type OriginMatcher interface {
+	AllowOrigin(origin string) string
+}
+
+// Origins allows a list of origins, or every origin when the list holds "*"
+// The list can be replaced while requests are served, each request sees either the old or the new list
+type Origins struct {
+	allowed atomic.Pointer[[]string]
+}
+
+// NewOrigins creates a matcher allowing the given origins
+func NewOrigins(allowed []string) *Origins {
+	origins := &Origins{}
+	origins.Set(allowed)
+	return origins
+}
+
+// Set replaces the allowed origins
+func (o *Origins) Set(allowed []string) {
+	allowed = append([]string(nil), allowed...)
+	o.allowed.Store(&allowed)
+}
+
+// AllowOrigin implements OriginMatcher
+func (o *Origins) AllowOrigin(origin string) string {
+	for _, allowed := range *o.allowed.Load() {
+		if allowed == "*" {
+			return "*"
+		}
+		if origin != "" && allowed == origin {
+			return origin
+		}
+	}
+	return ""
+}
+
 // CORSConfig holds the CORS configuration
 type CORSConfig struct {
-	AllowedOrigins   []string
+	Origins          OriginMatcher
 	AllowedMethods   []string
 	AllowedHeaders   []string
 	ExposedHeaders   []string
@@ -34,7 +72,7 @@ type CORSConfig struct {
 // DefaultCORSConfig returns a default CORS configuration
 func DefaultCORSConfig() CORSConfig {
 	return CORSConfig{
-		AllowedOrigins:   []string{"*"},
+		Origins:          NewOrigins([]string{"*"}),
 		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
 		AllowedHeaders:   []string{"Accept", "Content-Type", "Content-Length", "Authorization"},
 		ExposedHeaders:   []string{},
@@ -49,18 +87,14 @@ func CORS(config CORSConfig) func(http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			origin := r.Header.Get("Origin")
 
-			// Set CORS headers
-			if len(config.AllowedOrigins) > 0 {
-				if config.AllowedOrigins[0] == "*" {
-					w.Header().Set("Access-Control-Allow-Origin", "*")
-				} else {
-					// Check if origin is allowed
-					for _, allowedOrigin := range config.AllowedOrigins {
-						if allowedOrigin == origin {
-							w.Header().Set("Access-Control-Allow-Origin", origin)
-							break
-						}
-					}
+			// Set CORS headers, responses naming the origin differ between origins
+			if config.Origins != nil {
+				allowOrigin := config.Origins.AllowOrigin(origin)
+				if allowOrigin != "" {
+					w.Header().Set("Access-Control-Allow-Origin", allowOrigin)
+				}
+				if allowOrigin != "*" {
+					w.Header().Add("Vary", "Origin")
 				}
 			}
 
@@ -0,0 +1,166 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// timeoutWriteGrace is the time left after a request timeout to write the timeout response
+const timeoutWriteGrace = 5 * time.Second
+
+// Limits bounds the body size and processing time of a request, a zero value disables the bound
+type Limits struct {
+	MaxBodyBytes int64
+	Timeout      time.Duration
+	// Streaming routes write their response as it is produced, the timeout only cancels their context
+	// since a started response cannot be replaced by a 503
+	Streaming bool
+}
+
+// RequestLimits applies the default limits to every request, except for the routes of mux whose
+// pattern has an entry in routes. Oversized bodies are rejected with 413 and requests exceeding the
+// timeout with 503, after which the handler's context is cancelled.
+// The response of a request with a timeout is buffered unless its route is marked as streaming.
+func RequestLimits(mux *http.ServeMux, defaults Limits, routes map[string]Limits) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			limits := defaults
+			if len(routes) > 0 {
+				if _, pattern := mux.Handler(r); pattern != "" {
+					if override, ok := routes[pattern]; ok {
+						limits = override
+					}
+				}
+			}
+
+			if limits.MaxBodyBytes > 0 {
+				// Reject uploads that announce their size before reading any of them
+				if r.ContentLength > limits.MaxBodyBytes {
+					writeLimitError(w, http.StatusRequestEntityTooLarge, "request body too large")
+					return
+				}
+				r.Body = http.MaxBytesReader(w, r.Body, limits.MaxBodyBytes)
+			}
+
+			// Replace the server wide write timeout, which would cut off long running routes
+			controller := http.NewResponseController(w)
+			if limits.Timeout <= 0 {
+				_ = controller.SetWriteDeadline(time.Time{})
+				next.ServeHTTP(w, r)
+				return
+			}
+			_ = controller.SetWriteDeadline(time.Now().Add(limits.Timeout + timeoutWriteGrace))
+			if limits.Streaming {
+				ctx, cancel := context.WithTimeout(r.Context(), limits.Timeout)
+				defer cancel()
+				next.ServeHTTP(w, r.WithContext(ctx))
+				return
+			}
+			serveWithTimeout(next, w, r, limits.Timeout)
+		})
+	}
+}
+
+// serveWithTimeout runs next with a context cancelled after timeout and writes 503 unless it responded by then
+func serveWithTimeout(next http.Handler, w http.ResponseWriter, r *http.Request, timeout time.Duration) {
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	tw := &timeoutWriter{header: make(http.Header)}
+	done := make(chan struct{})
+	panicked := make(chan any, 1)
+	go func() {
+		defer func() {
+			if p := recover(); p != nil {
+				panicked <- p
+			}
+		}()
+		next.ServeHTTP(tw, r.WithContext(ctx))
+		close(done)
+	}()
+
+	select {
+	case p := <-panicked:
+		panic(p)
+	case <-done:
+		tw.mu.Lock()
+		defer tw.mu.Unlock()
+		dst := w.Header()
+		for key, values := range tw.header {
+			dst[key] = values
+		}
+		if tw.status == 0 {
+			tw.status = http.StatusOK
+		}
+		w.WriteHeader(tw.status)
+		_, _ = w.Write(tw.body.Bytes())
+	case <-ctx.Done():
+		tw.mu.Lock()
+		defer tw.mu.Unlock()
+		tw.timedOut = true
+		if ctx.Err() == context.DeadlineExceeded {
+			writeLimitError(w, http.StatusServiceUnavailable, "request timed out")
+		}
+	}
+}
+
+// timeoutWriter buffers the response of a handler so it can be discarded once the request timed out
+type timeoutWriter struct {
+	mu       sync.Mutex
+	header   http.Header
+	body     bytes.Buffer
+	status   int
+	timedOut bool
+}
+
+func (tw *timeoutWriter) Header() http.Header {
+	return tw.header
+}
+
+func (tw *timeoutWriter) Write(p []byte) (int, error) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+	if tw.status == 0 {
+		tw.status = http.StatusOK
+	}
+	return tw.body.Write(p)
+}
+
+func (tw *timeoutWriter) WriteHeader(status int) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.timedOut || tw.status != 0 {
+		return
+	}
+	tw.status = status
+}
+
+// writeLimitError writes an error in the format of the API's error responses
+func writeLimitError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{"error": "error", "message": message})
+}
@@ -0,0 +1,187 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package middleware
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/config"
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/opensearch"
+)
+
+// blockingOpenSearch answers the info request of the OpenSearch client and holds searches until they are cancelled
+type blockingOpenSearch struct {
+	cancelled chan struct{}
+}
+
+func (f *blockingOpenSearch) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if !strings.HasSuffix(r.URL.Path, "/_search") {
+		fmt.Fprint(w, `{"cluster_name":"test","version":{"distribution":"opensearch","number":"2.11.0"}}`)
+		return
+	}
+	// The server notices a closed connection only once the body has been read
+	_, _ = io.Copy(io.Discard, r.Body)
+	select {
+	case <-r.Context().Done():
+		close(f.cancelled)
+	case <-time.After(10 * time.Second):
+		fmt.Fprint(w, `{"hits":{"total":{"value":0},"hits":[]}}`)
+	}
+}
+
+func serveLimited(mux *http.ServeMux, defaults Limits, routes map[string]Limits, req *http.Request) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	RequestLimits(mux, defaults, routes)(mux).ServeHTTP(rec, req)
+	return rec
+}
+
+func TestRequestLimitsRejectsAnnouncedOversizedBody(t *testing.T) {
+	called := false
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /upload", func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/upload", strings.NewReader(strings.Repeat("x", 11)))
+	rec := serveLimited(mux, Limits{MaxBodyBytes: 10}, nil, req)
+
+	if rec.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusRequestEntityTooLarge)
+	}
+	if called {
+		t.Error("handler was called for a body announced above the limit")
+	}
+	var body map[string]string
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil || body["message"] != "request body too large" {
+		t.Errorf("body = %q, want an error response", rec.Body.String())
+	}
+}
+
+func TestRequestLimitsStopsReadingUnannouncedOversizedBody(t *testing.T) {
+	var readErr error
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /upload", func(w http.ResponseWriter, r *http.Request) {
+		_, readErr = io.ReadAll(r.Body)
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/upload", io.MultiReader(strings.NewReader(strings.Repeat("x", 11))))
+	req.ContentLength = -1
+	serveLimited(mux, Limits{MaxBodyBytes: 10}, nil, req)
+
+	var maxBytesErr *http.MaxBytesError
+	if !errors.As(readErr, &maxBytesErr) {
+		t.Fatalf("read error = %v, want *http.MaxBytesError", readErr)
+	}
+}
+
+func TestRequestLimitsTimeoutCancelsOpenSearchSearch(t *testing.T) {
+	fake := &blockingOpenSearch{cancelled: make(chan struct{})}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	client, err := opensearch.NewClient(&config.OpenSearchConfig{
+		Address:                 server.URL,
+		Username:                "admin",
+		Password:                "admin",
+		RequestTimeout:          30 * time.Second,
+		BreakerFailureThreshold: 1,
+		BreakerOpenTimeout:      time.Minute,
+	}, nil)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	returned := make(chan error, 1)
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/traces", func(w http.ResponseWriter, r *http.Request) {
+		_, err := client.Search(r.Context(), []string{"otel-traces-*"}, map[string]interface{}{"size": 10})
+		returned <- err
+		w.WriteHeader(http.StatusOK)
+	})
+
+	start := time.Now()
+	rec := serveLimited(mux, Limits{Timeout: 100 * time.Millisecond}, nil, httptest.NewRequest(http.MethodGet, "/api/v1/traces", nil))
+
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusServiceUnavailable)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("timeout response took %v", elapsed)
+	}
+	select {
+	case <-fake.cancelled:
+	case <-time.After(5 * time.Second):
+		t.Fatal("search request was not cancelled on OpenSearch")
+	}
+	// The handler goroutine must finish instead of waiting for the search result
+	select {
+	case err := <-returned:
+		if err == nil {
+			t.Error("Search() succeeded after the request timed out")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("handler did not return after the request timed out")
+	}
+	if state := client.BreakerState(); state != opensearch.BreakerClosed {
+		t.Errorf("breaker state = %s, a cancelled search must not count as an OpenSearch failure", state)
+	}
+}
+
+func TestRequestLimitsRouteOverrides(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/traces/export", func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := r.Context().Deadline(); !ok {
+			t.Error("export context has no deadline")
+		}
+		// A streaming route keeps writing past the default timeout
+		time.Sleep(50 * time.Millisecond)
+		fmt.Fprint(w, "page 1\n")
+		w.(http.Flusher).Flush()
+	})
+	mux.HandleFunc("POST /v1/traces", func(w http.ResponseWriter, r *http.Request) {
+		if _, err := io.ReadAll(r.Body); err != nil {
+			t.Errorf("read error = %v", err)
+		}
+	})
+	defaults := Limits{MaxBodyBytes: 10, Timeout: 10 * time.Millisecond}
+	routes := map[string]Limits{
+		"GET /api/v1/traces/export": {Timeout: time.Minute, Streaming: true},
+		"POST /v1/traces":           {MaxBodyBytes: 100, Timeout: time.Minute},
+	}
+
+	rec := serveLimited(mux, defaults, routes, httptest.NewRequest(http.MethodGet, "/api/v1/traces/export", nil))
+	if rec.Code != http.StatusOK || rec.Body.String() != "page 1\n" {
+		t.Errorf("export = %d %q, want the streamed page", rec.Code, rec.Body.String())
+	}
+	if !rec.Flushed {
+		t.Error("export response was buffered instead of streamed")
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/traces", strings.NewReader(strings.Repeat("x", 50)))
+	if rec := serveLimited(mux, defaults, routes, req); rec.Code != http.StatusOK {
+		t.Errorf("OTLP status = %d, want the route's larger body limit", rec.Code)
+	}
+}
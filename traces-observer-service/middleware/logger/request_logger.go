@@ -21,6 +21,10 @@ import (
 	"log/slog"
 	"net/http"
 	"time"
+
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/wso2/ai-agent-management-platform/libs/go-common/logging"
 )
 
 type loggerKey struct{}
@@ -50,6 +54,18 @@ func (rw *responseWriter) WriteHeader(code int) {
 	rw.ResponseWriter.WriteHeader(code)
 }
 
+// Flush streams what was written so far, as the export does between pages
+func (rw *responseWriter) Flush() {
+	if flusher, ok := rw.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
 func RequestLogger() func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -61,13 +77,24 @@ func RequestLogger() func(http.Handler) http.Handler {
 				statusCode:     http.StatusOK, // default status
 			}
 
-			// Use the globally configured logger
-			reqLogger := slog.Default().With(
+			// The correlation id of the caller is kept so that the logs of both services can be joined
+			correlationID := logging.RequestCorrelationID(r.Header.Get(logging.CorrelationIDHeader))
+			w.Header().Set(logging.CorrelationIDHeader, correlationID)
+			ctx := logging.WithCorrelationID(r.Context(), correlationID)
+			attrs := []any{
 				slog.String("method", r.Method),
 				slog.String("path", r.URL.Path),
 				slog.String("remote_addr", r.RemoteAddr),
-			)
-			ctx := WithLogger(r.Context(), reqLogger)
+				slog.String(logging.CorrelationIDKey, correlationID),
+			}
+			if traceID, ok := requestTraceID(r); ok {
+				ctx = logging.WithTraceID(ctx, traceID)
+				attrs = append(attrs, slog.String(logging.TraceIDKey, traceID))
+			}
+
+			// Use the globally configured logger
+			reqLogger := slog.Default().With(attrs...)
+			ctx = WithLogger(ctx, reqLogger)
 
 			// Call the next handler
 			next.ServeHTTP(wrapped, r.WithContext(ctx))
@@ -81,3 +108,11 @@ func RequestLogger() func(http.Handler) http.Handler {
 		})
 	}
 }
+
+// requestTraceID returns the trace of the span of the request, or of its traceparent when the request is not traced
+func requestTraceID(r *http.Request) (string, bool) {
+	if spanContext := trace.SpanContextFromContext(r.Context()); spanContext.IsValid() {
+		return spanContext.TraceID().String(), true
+	}
+	return logging.TraceIDFromTraceparent(r.Header.Get(logging.TraceparentHeader))
+}
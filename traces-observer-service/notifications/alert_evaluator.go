@@ -0,0 +1,401 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package notifications
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/opensearch"
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/pricing"
+)
+
+// AlertConfig holds the alert evaluation settings
+type AlertConfig struct {
+	EvaluationInterval time.Duration // Interval of evaluating every enabled alert rule
+	QueueSize          int           // Deliveries waiting to be posted, further deliveries are dropped
+	MaxRetries         int           // Retries of a failed delivery
+	RetryBackoff       time.Duration // Initial delay between retries, doubled on every retry
+	RequestTimeout     time.Duration // Timeout of a single webhook request
+}
+
+// AlertStats holds the counters of the alert evaluator
+type AlertStats struct {
+	Rules               int    `json:"rules"`
+	FiringAlerts        int    `json:"firingAlerts"`
+	Evaluations         uint64 `json:"evaluations"` // Rules evaluated
+	EvaluationErrors    uint64 `json:"evaluationErrors"`
+	Delivered           uint64 `json:"delivered"`
+	Failed              uint64 `json:"failed"`  // Deliveries that failed after every retry
+	Dropped             uint64 `json:"dropped"` // Deliveries dropped because the queue was full
+	LastEvaluationError string `json:"lastEvaluationError,omitempty"`
+}
+
+// AlertPayload is the JSON body posted to the webhook of an alert rule when an alert fires or resolves
+type AlertPayload struct {
+	Event         string     `json:"event"`
+	DeliveryID    string     `json:"deliveryId"`
+	RuleID        string     `json:"ruleId"`
+	RuleName      string     `json:"ruleName"`
+	Metric        string     `json:"metric"`
+	Comparison    string     `json:"comparison"`
+	Threshold     float64    `json:"threshold"`
+	WindowMinutes int        `json:"windowMinutes"`
+	GroupBy       string     `json:"groupBy,omitempty"`
+	Group         string     `json:"group,omitempty"`
+	Value         float64    `json:"value"` // Metric of the evaluation that fired or resolved the alert
+	TraceCount    int        `json:"traceCount"`
+	FiringSince   time.Time  `json:"firingSince"`
+	ResolvedAt    *time.Time `json:"resolvedAt,omitempty"`
+}
+
+// alertDelivery is a payload waiting to be posted to the webhook of an alert rule
+type alertDelivery struct {
+	rule    AlertRule
+	payload AlertPayload
+}
+
+// AlertEvaluator periodically aggregates the metric of every enabled alert rule, tracks the alerts of each group
+// and notifies the webhook of the rule when an alert fires or resolves
+// Rules and states are read from the store on every evaluation so that replicas and restarts continue where the
+// last evaluation left off, deliveries carry stable IDs so that receivers can deduplicate
+type AlertEvaluator struct {
+	cfg          AlertConfig
+	store        *AlertStore
+	client       *opensearch.Client
+	pricingTable *pricing.Table
+	sender       *webhookSender
+
+	mu                  sync.Mutex
+	rules               int
+	firing              int
+	lastEvaluationError string
+
+	queue       chan alertDelivery
+	evaluations atomic.Uint64
+	errors      atomic.Uint64
+	delivered   atomic.Uint64
+	failed      atomic.Uint64
+	dropped     atomic.Uint64
+
+	stop    chan struct{}
+	done    chan struct{}
+	workers sync.WaitGroup
+}
+
+// NewAlertEvaluator creates an alert evaluator, Start creates the indices and starts evaluating
+func NewAlertEvaluator(cfg AlertConfig, store *AlertStore, client *opensearch.Client, pricingTable *pricing.Table) *AlertEvaluator {
+	if cfg.EvaluationInterval <= 0 {
+		cfg.EvaluationInterval = time.Minute
+	}
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = 1000
+	}
+	if cfg.RequestTimeout <= 0 {
+		cfg.RequestTimeout = 10 * time.Second
+	}
+
+	return &AlertEvaluator{
+		cfg:          cfg,
+		store:        store,
+		client:       client,
+		pricingTable: pricingTable,
+		sender:       newWebhookSender(cfg.MaxRetries, cfg.RetryBackoff, cfg.RequestTimeout),
+		queue:        make(chan alertDelivery, cfg.QueueSize),
+		stop:         make(chan struct{}),
+		done:         make(chan struct{}),
+	}
+}
+
+// Start creates the alert indices and starts the evaluation loop and the delivery worker
+func (e *AlertEvaluator) Start(ctx context.Context) error {
+	if err := e.store.EnsureIndices(ctx); err != nil {
+		return err
+	}
+
+	e.workers.Add(1)
+	go e.deliverLoop()
+	go e.run()
+	return nil
+}
+
+// Close stops evaluating and waits for the queued deliveries
+func (e *AlertEvaluator) Close(ctx context.Context) {
+	close(e.stop)
+	<-e.done
+	close(e.queue)
+
+	done := make(chan struct{})
+	go func() {
+		e.workers.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		slog.Warn("Alert deliveries still in flight at shutdown", "queued", len(e.queue))
+	}
+}
+
+// Stats returns the evaluator counters
+func (e *AlertEvaluator) Stats() AlertStats {
+	e.mu.Lock()
+	stats := AlertStats{
+		Rules:               e.rules,
+		FiringAlerts:        e.firing,
+		LastEvaluationError: e.lastEvaluationError,
+	}
+	e.mu.Unlock()
+
+	stats.Evaluations = e.evaluations.Load()
+	stats.EvaluationErrors = e.errors.Load()
+	stats.Delivered = e.delivered.Load()
+	stats.Failed = e.failed.Load()
+	stats.Dropped = e.dropped.Load()
+	return stats
+}
+
+// run evaluates the alert rules every evaluation interval
+func (e *AlertEvaluator) run() {
+	defer close(e.done)
+
+	ticker := time.NewTicker(e.cfg.EvaluationInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := e.Evaluate(context.Background(), time.Now()); err != nil {
+				slog.Error("Failed to evaluate alert rules", "error", err)
+			}
+		case <-e.stop:
+			return
+		}
+	}
+}
+
+// Evaluate evaluates every enabled alert rule over the window ending at now
+// A rule failing to evaluate keeps its alerts as they were and does not stop the other rules
+func (e *AlertEvaluator) Evaluate(ctx context.Context, now time.Time) error {
+	rules, err := e.store.ListRules(ctx)
+	if err != nil {
+		e.recordError(err)
+		return err
+	}
+	states, err := e.store.ListStates(ctx, "")
+	if err != nil {
+		e.recordError(err)
+		return err
+	}
+	statesByRule := make(map[string][]AlertState)
+	for _, state := range states {
+		statesByRule[state.RuleID] = append(statesByRule[state.RuleID], state)
+	}
+
+	var firstErr error
+	firing := 0
+	for i := range rules {
+		rule := &rules[i]
+		if !rule.Enabled {
+			continue
+		}
+		ruleFiring, err := e.evaluateRule(ctx, rule, statesByRule[rule.ID], now)
+		e.evaluations.Add(1)
+		if err != nil {
+			slog.Error("Failed to evaluate alert rule", "rule", rule.ID, "error", err)
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+		firing += ruleFiring
+	}
+
+	e.mu.Lock()
+	e.rules = len(rules)
+	e.firing = firing
+	e.mu.Unlock()
+	if firstErr != nil {
+		e.recordError(firstErr)
+	} else {
+		e.mu.Lock()
+		e.lastEvaluationError = ""
+		e.mu.Unlock()
+	}
+	return firstErr
+}
+
+// recordError counts a failed evaluation and keeps its message for the stats
+func (e *AlertEvaluator) recordError(err error) {
+	e.errors.Add(1)
+	e.mu.Lock()
+	e.lastEvaluationError = err.Error()
+	e.mu.Unlock()
+}
+
+// evaluateRule advances the alerts of every group of a rule and returns the number of firing alerts
+// A state is stored before its notification is queued, so that an alert is never notified without being recorded
+func (e *AlertEvaluator) evaluateRule(ctx context.Context, rule *AlertRule, states []AlertState, now time.Time) (int, error) {
+	params := rule.metricsParams(now)
+	indices, err := opensearch.GetIndicesForTimeRange(params.StartTime.Format(time.RFC3339), params.EndTime.Format(time.RFC3339))
+	if err != nil {
+		return countFiring(states), fmt.Errorf("failed to get indices: %w", err)
+	}
+	response, err := e.client.Search(ctx, indices, opensearch.BuildWindowMetricsQuery(params))
+	if err != nil {
+		return countFiring(states), fmt.Errorf("failed to search alert metrics: %w", err)
+	}
+	points, err := opensearch.ParseWindowMetrics(response, params, e.pricingTable)
+	if err != nil {
+		return countFiring(states), err
+	}
+
+	current := make(map[string]*AlertState, len(states))
+	for i := range states {
+		state := &states[i]
+		// States of a grouping the rule no longer has cannot be evaluated
+		if (state.Group == "") != (rule.GroupBy == "") {
+			if err := e.store.DeleteState(ctx, state.ID); err != nil {
+				return countFiring(states), fmt.Errorf("failed to delete alert state: %w", err)
+			}
+			continue
+		}
+		current[state.Group] = state
+	}
+
+	firing := 0
+	var firstErr error
+	for _, group := range alertGroups(points, current) {
+		previous := current[group]
+		next, event := advance(rule, previous, group, points[group], now)
+
+		var err error
+		switch {
+		case next != nil:
+			err = e.store.PutState(ctx, next)
+		case previous != nil:
+			err = e.store.DeleteState(ctx, previous.ID)
+		}
+		if err != nil {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("failed to store alert state: %w", err)
+			}
+			if previous != nil && previous.Status == AlertStatusFiring {
+				firing++
+			}
+			continue
+		}
+		if next != nil && next.Status == AlertStatusFiring {
+			firing++
+		}
+
+		switch event {
+		case EventAlertFiring:
+			e.enqueue(*rule, e.buildAlertPayload(rule, next, event, nil))
+		case EventAlertResolved:
+			resolvedAt := now
+			resolved := *previous
+			resolved.Value = rule.value(points[group])
+			resolved.TraceCount = points[group].TraceCount
+			e.enqueue(*rule, e.buildAlertPayload(rule, &resolved, event, &resolvedAt))
+		}
+	}
+	return firing, firstErr
+}
+
+// alertGroups returns the groups with metrics in the window and the groups with an alert, in a stable order
+func alertGroups(points map[string]opensearch.TraceMetricsPoint, states map[string]*AlertState) []string {
+	groups := make([]string, 0, len(points)+len(states))
+	seen := make(map[string]bool, len(points)+len(states))
+	for group := range points {
+		seen[group] = true
+		groups = append(groups, group)
+	}
+	for group := range states {
+		if !seen[group] {
+			groups = append(groups, group)
+		}
+	}
+	sort.Strings(groups)
+	return groups
+}
+
+// countFiring counts the firing alerts of a rule that could not be evaluated
+func countFiring(states []AlertState) int {
+	firing := 0
+	for _, state := range states {
+		if state.Status == AlertStatusFiring {
+			firing++
+		}
+	}
+	return firing
+}
+
+// buildAlertPayload builds the notification of an alert that fired or resolved
+// The delivery ID is derived from the alert and the time it started firing, so it is the same on every replica
+func (e *AlertEvaluator) buildAlertPayload(rule *AlertRule, state *AlertState, event string, resolvedAt *time.Time) AlertPayload {
+	return AlertPayload{
+		Event:         event,
+		DeliveryID:    state.ID + "-" + strconv.FormatInt(state.FiringSince.Unix(), 10) + "-" + event,
+		RuleID:        rule.ID,
+		RuleName:      rule.Name,
+		Metric:        rule.Metric,
+		Comparison:    rule.Comparison,
+		Threshold:     rule.Threshold,
+		WindowMinutes: rule.WindowMinutes,
+		GroupBy:       rule.GroupBy,
+		Group:         state.Group,
+		Value:         state.Value,
+		TraceCount:    state.TraceCount,
+		FiringSince:   *state.FiringSince,
+		ResolvedAt:    resolvedAt,
+	}
+}
+
+// enqueue queues a notification, dropping it when the queue is full
+func (e *AlertEvaluator) enqueue(rule AlertRule, payload AlertPayload) {
+	select {
+	case e.queue <- alertDelivery{rule: rule, payload: payload}:
+	default:
+		e.dropped.Add(1)
+		slog.Warn("Alert delivery queue full, dropping notification", "rule", rule.ID, "group", payload.Group, "event", payload.Event)
+	}
+}
+
+// deliverLoop posts queued notifications until the queue is closed
+func (e *AlertEvaluator) deliverLoop() {
+	defer e.workers.Done()
+	for item := range e.queue {
+		body, err := json.Marshal(item.payload)
+		if err == nil {
+			err = e.sender.send(item.rule.WebhookURL, item.rule.Secret, item.payload.Event, item.payload.DeliveryID, body)
+		}
+		if err != nil {
+			e.failed.Add(1)
+			slog.Error("Failed to deliver alert notification",
+				"rule", item.rule.ID, "group", item.payload.Group, "event", item.payload.Event, "error", err)
+			continue
+		}
+		e.delivered.Add(1)
+	}
+}
@@ -0,0 +1,448 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package notifications
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/opensearch"
+)
+
+// Alert indices
+const (
+	AlertRuleIndex  = "amp-alert-rules"
+	AlertStateIndex = "amp-alert-states"
+)
+
+// ErrInvalidAlertRule is returned when an alert rule fails validation
+var ErrInvalidAlertRule = errors.New("invalid alert rule")
+
+// Alert metrics
+const (
+	AlertMetricErrorRate    = "errorRate" // Share of traces with a failed span, between 0 and 1
+	AlertMetricP50LatencyMs = "p50LatencyMs"
+	AlertMetricP95LatencyMs = "p95LatencyMs"
+	AlertMetricP99LatencyMs = "p99LatencyMs"
+	AlertMetricTraceCount   = "traceCount"
+	AlertMetricTotalTokens  = "totalTokens"
+	AlertMetricCost         = "cost" // USD of priced models
+)
+
+// alertMetrics lists the supported alert metrics
+var alertMetrics = []string{
+	AlertMetricErrorRate,
+	AlertMetricP50LatencyMs,
+	AlertMetricP95LatencyMs,
+	AlertMetricP99LatencyMs,
+	AlertMetricTraceCount,
+	AlertMetricTotalTokens,
+	AlertMetricCost,
+}
+
+// Alert comparisons of the metric value against the threshold
+const (
+	AlertComparisonGT  = "gt"
+	AlertComparisonGTE = "gte"
+	AlertComparisonLT  = "lt"
+	AlertComparisonLTE = "lte"
+)
+
+// Alert window bounds
+const (
+	MinAlertWindowMinutes = 1
+	MaxAlertWindowMinutes = 24 * 60
+)
+
+// maxAlertStates bounds the number of alert states loaded from the state index
+const maxAlertStates = 10000
+
+// maxAlertEvaluations bounds the consecutive evaluations an alert waits for before firing or resolving
+const maxAlertEvaluations = 60
+
+// AlertRule fires an alert for every group whose metric, aggregated over the window, crosses the threshold
+// Hysteresis keeps a flapping metric from notifying repeatedly: the alert only fires after the condition held for
+// Evaluations consecutive evaluations and only resolves once the metric is back past ResolveThreshold for as many
+type AlertRule struct {
+	ID               string    `json:"id"`
+	Name             string    `json:"name"`
+	Enabled          bool      `json:"enabled"`
+	Metric           string    `json:"metric"`
+	WindowMinutes    int       `json:"windowMinutes"`
+	GroupBy          string    `json:"groupBy,omitempty"`        // agent, framework or model, a single group when empty
+	Agent            string    `json:"agent,omitempty"`          // Only the traces of this agent, all agents when empty
+	ComponentUid     string    `json:"componentUid,omitempty"`   // Only the traces of this component
+	EnvironmentUid   string    `json:"environmentUid,omitempty"` // Only the traces of this environment
+	Comparison       string    `json:"comparison"`
+	Threshold        float64   `json:"threshold"`
+	ResolveThreshold *float64  `json:"resolveThreshold,omitempty"` // Defaults to the threshold
+	Evaluations      int       `json:"evaluations"`                // Consecutive evaluations to fire or resolve, defaults to 1
+	MinTraceCount    int       `json:"minTraceCount,omitempty"`    // Groups with fewer traces in the window never breach
+	WebhookURL       string    `json:"webhookUrl"`
+	Secret           string    `json:"secret,omitempty"` // HMAC key of the signature header, never returned after creation
+	CreatedAt        time.Time `json:"createdAt"`
+	UpdatedAt        time.Time `json:"updatedAt"`
+}
+
+// Validate checks the condition and target of an alert rule, defaulting the evaluations
+func (r *AlertRule) Validate() error {
+	if strings.TrimSpace(r.Name) == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidAlertRule)
+	}
+	if !isAlertMetric(r.Metric) {
+		return fmt.Errorf("%w: metric must be one of %s", ErrInvalidAlertRule, strings.Join(alertMetrics, ", "))
+	}
+	if r.WindowMinutes < MinAlertWindowMinutes || r.WindowMinutes > MaxAlertWindowMinutes {
+		return fmt.Errorf("%w: windowMinutes must be between %d and %d", ErrInvalidAlertRule, MinAlertWindowMinutes, MaxAlertWindowMinutes)
+	}
+	if r.GroupBy != "" && !opensearch.IsValidWindowMetricsGroupBy(r.GroupBy) {
+		return fmt.Errorf("%w: groupBy must be 'agent', 'framework' or 'model'", ErrInvalidAlertRule)
+	}
+	switch r.Comparison {
+	case AlertComparisonGT, AlertComparisonGTE, AlertComparisonLT, AlertComparisonLTE:
+	default:
+		return fmt.Errorf("%w: comparison must be 'gt', 'gte', 'lt' or 'lte'", ErrInvalidAlertRule)
+	}
+	if math.IsNaN(r.Threshold) || math.IsInf(r.Threshold, 0) || r.Threshold < 0 {
+		return fmt.Errorf("%w: threshold must be a non-negative number", ErrInvalidAlertRule)
+	}
+	if r.Metric == AlertMetricErrorRate && r.Threshold > 1 {
+		return fmt.Errorf("%w: the errorRate threshold must be between 0 and 1", ErrInvalidAlertRule)
+	}
+	if r.ResolveThreshold != nil {
+		resolve := *r.ResolveThreshold
+		if math.IsNaN(resolve) || math.IsInf(resolve, 0) || resolve < 0 {
+			return fmt.Errorf("%w: resolveThreshold must be a non-negative number", ErrInvalidAlertRule)
+		}
+		// The resolve threshold lies on the healthy side of the threshold, otherwise an alert could not resolve
+		// without breaching again
+		above := r.Comparison == AlertComparisonGT || r.Comparison == AlertComparisonGTE
+		if (above && resolve > r.Threshold) || (!above && resolve < r.Threshold) {
+			return fmt.Errorf("%w: resolveThreshold must not be past the threshold", ErrInvalidAlertRule)
+		}
+	}
+	if r.Evaluations == 0 {
+		r.Evaluations = 1
+	}
+	if r.Evaluations < 1 || r.Evaluations > maxAlertEvaluations {
+		return fmt.Errorf("%w: evaluations must be between 1 and %d", ErrInvalidAlertRule, maxAlertEvaluations)
+	}
+	if r.MinTraceCount < 0 {
+		return fmt.Errorf("%w: minTraceCount must not be negative", ErrInvalidAlertRule)
+	}
+	target, err := url.Parse(r.WebhookURL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return fmt.Errorf("%w: webhookUrl must be an absolute http or https URL", ErrInvalidAlertRule)
+	}
+	return nil
+}
+
+// isAlertMetric reports whether a metric is supported by alert rules
+func isAlertMetric(metric string) bool {
+	for _, m := range alertMetrics {
+		if m == metric {
+			return true
+		}
+	}
+	return false
+}
+
+// Redacted returns a copy of the rule without its secret
+func (r AlertRule) Redacted() AlertRule {
+	r.Secret = ""
+	return r
+}
+
+// Window returns the time range the metric is aggregated over
+func (r *AlertRule) Window() time.Duration {
+	return time.Duration(r.WindowMinutes) * time.Minute
+}
+
+// metricsParams returns the window metrics query of the rule ending at now
+func (r *AlertRule) metricsParams(now time.Time) opensearch.TraceMetricsParams {
+	return opensearch.TraceMetricsParams{
+		ComponentUid:   r.ComponentUid,
+		EnvironmentUid: r.EnvironmentUid,
+		Agent:          r.Agent,
+		StartTime:      now.Add(-r.Window()),
+		EndTime:        now,
+		GroupBy:        r.GroupBy,
+	}
+}
+
+// value reads the metric of the rule from the metrics of a group
+func (r *AlertRule) value(point opensearch.TraceMetricsPoint) float64 {
+	switch r.Metric {
+	case AlertMetricErrorRate:
+		return point.ErrorRate
+	case AlertMetricP50LatencyMs:
+		return float64(point.DurationNanos.P50) / float64(time.Millisecond)
+	case AlertMetricP95LatencyMs:
+		return float64(point.DurationNanos.P95) / float64(time.Millisecond)
+	case AlertMetricP99LatencyMs:
+		return float64(point.DurationNanos.P99) / float64(time.Millisecond)
+	case AlertMetricTraceCount:
+		return float64(point.TraceCount)
+	case AlertMetricTotalTokens:
+		return float64(point.TotalTokens)
+	case AlertMetricCost:
+		return point.Cost
+	}
+	return 0
+}
+
+// compare reports whether a value is past a threshold in the direction of the comparison
+func (r *AlertRule) compare(value, threshold float64) bool {
+	switch r.Comparison {
+	case AlertComparisonGT:
+		return value > threshold
+	case AlertComparisonGTE:
+		return value >= threshold
+	case AlertComparisonLT:
+		return value < threshold
+	case AlertComparisonLTE:
+		return value <= threshold
+	}
+	return false
+}
+
+// resolveThreshold returns the threshold the metric has to be back past for the alert to resolve
+func (r *AlertRule) resolveThreshold() float64 {
+	if r.ResolveThreshold != nil {
+		return *r.ResolveThreshold
+	}
+	return r.Threshold
+}
+
+// Alert statuses
+const (
+	AlertStatusPending = "pending" // Breaching, but not yet for the evaluations of the rule
+	AlertStatusFiring  = "firing"
+)
+
+// AlertState is the evaluation state of a group of an alert rule
+// A state only exists while the group is breaching or firing, it is deleted once the group is healthy again
+type AlertState struct {
+	ID              string     `json:"id"`
+	RuleID          string     `json:"ruleId"`
+	RuleName        string     `json:"ruleName"`
+	Group           string     `json:"group"` // Value of the group-by dimension, empty when the rule is not grouped
+	Status          string     `json:"status"`
+	Value           float64    `json:"value"`      // Metric of the latest evaluation
+	TraceCount      int        `json:"traceCount"` // Traces of the window of the latest evaluation
+	Breaches        int        `json:"breaches"`   // Consecutive evaluations past the threshold while pending
+	Clears          int        `json:"clears"`     // Consecutive evaluations past the resolve threshold while firing
+	FiringSince     *time.Time `json:"firingSince,omitempty"`
+	LastEvaluatedAt time.Time  `json:"lastEvaluatedAt"`
+}
+
+// alertStateID derives the document ID of the state of a group, group values are hashed since they are free text
+func alertStateID(ruleID, group string) string {
+	sum := sha256.Sum256([]byte(group))
+	return ruleID + "-" + hex.EncodeToString(sum[:8])
+}
+
+// Alert events
+const (
+	EventAlertFiring   = "alert.firing"
+	EventAlertResolved = "alert.resolved"
+)
+
+// advance applies an evaluation of a group to its state
+// Returns the next state, nil once the group is healthy, and the event to notify, empty when nothing changed
+func advance(rule *AlertRule, state *AlertState, group string, point opensearch.TraceMetricsPoint, now time.Time) (*AlertState, string) {
+	value := rule.value(point)
+	sampled := point.TraceCount >= rule.MinTraceCount
+	breaching := sampled && rule.compare(value, rule.Threshold)
+	cleared := !sampled || !rule.compare(value, rule.resolveThreshold())
+
+	if state == nil {
+		if !breaching {
+			return nil, ""
+		}
+		state = &AlertState{
+			ID:     alertStateID(rule.ID, group),
+			RuleID: rule.ID,
+			Group:  group,
+			Status: AlertStatusPending,
+		}
+	} else {
+		copied := *state
+		state = &copied
+	}
+	state.RuleName = rule.Name
+	state.Value = value
+	state.TraceCount = point.TraceCount
+	state.LastEvaluatedAt = now
+
+	if state.Status == AlertStatusFiring {
+		if !cleared {
+			state.Clears = 0
+			return state, ""
+		}
+		state.Clears++
+		if state.Clears < rule.Evaluations {
+			return state, ""
+		}
+		return nil, EventAlertResolved
+	}
+
+	if !breaching {
+		return nil, ""
+	}
+	state.Breaches++
+	if state.Breaches < rule.Evaluations {
+		return state, ""
+	}
+	firingSince := now
+	state.Status = AlertStatusFiring
+	state.Breaches = 0
+	state.FiringSince = &firingSince
+	return state, EventAlertFiring
+}
+
+// AlertStore persists the alert rules and the state of their groups in OpenSearch, so that every replica shares
+// them and alerts survive restarts
+type AlertStore struct {
+	client *opensearch.Client
+}
+
+// NewAlertStore creates an alert store
+func NewAlertStore(client *opensearch.Client) *AlertStore {
+	return &AlertStore{client: client}
+}
+
+// EnsureIndices creates the alert rule and state indices when they do not exist yet
+func (s *AlertStore) EnsureIndices(ctx context.Context) error {
+	if err := s.client.EnsureIndex(ctx, AlertRuleIndex, map[string]interface{}{
+		"mappings": map[string]interface{}{
+			"dynamic": false,
+			"properties": map[string]interface{}{
+				"id":        map[string]interface{}{"type": "keyword"},
+				"name":      map[string]interface{}{"type": "keyword"},
+				"enabled":   map[string]interface{}{"type": "boolean"},
+				"createdAt": map[string]interface{}{"type": "date"},
+			},
+		},
+	}); err != nil {
+		return err
+	}
+	return s.client.EnsureIndex(ctx, AlertStateIndex, map[string]interface{}{
+		"mappings": map[string]interface{}{
+			"dynamic": false,
+			"properties": map[string]interface{}{
+				"id":          map[string]interface{}{"type": "keyword"},
+				"ruleId":      map[string]interface{}{"type": "keyword"},
+				"group":       map[string]interface{}{"type": "keyword"},
+				"status":      map[string]interface{}{"type": "keyword"},
+				"firingSince": map[string]interface{}{"type": "date"},
+			},
+		},
+	})
+}
+
+// ListRules returns every alert rule in creation order
+func (s *AlertStore) ListRules(ctx context.Context) ([]AlertRule, error) {
+	response, err := s.client.Search(ctx, []string{AlertRuleIndex}, map[string]interface{}{
+		"query": map[string]interface{}{"match_all": map[string]interface{}{}},
+		"size":  maxRules,
+		"sort": []map[string]interface{}{
+			{"createdAt": map[string]string{"order": "asc"}},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to search alert rules: %w", err)
+	}
+
+	rules := make([]AlertRule, 0, len(response.Hits.Hits))
+	for _, hit := range response.Hits.Hits {
+		var rule AlertRule
+		if err := opensearch.DecodeSource(hit.Source, &rule); err != nil {
+			return nil, fmt.Errorf("failed to decode alert rule: %w", err)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// GetRule returns an alert rule, false when it does not exist
+func (s *AlertStore) GetRule(ctx context.Context, id string) (*AlertRule, bool, error) {
+	var rule AlertRule
+	found, err := s.client.GetDocument(ctx, AlertRuleIndex, id, &rule)
+	if err != nil || !found {
+		return nil, found, err
+	}
+	return &rule, true, nil
+}
+
+// PutRule creates or replaces an alert rule
+func (s *AlertStore) PutRule(ctx context.Context, rule *AlertRule) error {
+	return s.client.PutDocument(ctx, AlertRuleIndex, rule.ID, rule)
+}
+
+// DeleteRule deletes an alert rule, false when it does not exist
+func (s *AlertStore) DeleteRule(ctx context.Context, id string) (bool, error) {
+	return s.client.DeleteDocument(ctx, AlertRuleIndex, id)
+}
+
+// ListStates returns the states of the groups of a rule, or of every rule when ruleID is empty
+// States of firing alerts come first, the longest firing first
+func (s *AlertStore) ListStates(ctx context.Context, ruleID string) ([]AlertState, error) {
+	query := map[string]interface{}{"match_all": map[string]interface{}{}}
+	if ruleID != "" {
+		query = map[string]interface{}{"term": map[string]interface{}{"ruleId": ruleID}}
+	}
+	response, err := s.client.Search(ctx, []string{AlertStateIndex}, map[string]interface{}{
+		"query": query,
+		"size":  maxAlertStates,
+		"sort": []map[string]interface{}{
+			{"firingSince": map[string]string{"order": "asc", "missing": "_last"}},
+			{"id": map[string]string{"order": "asc"}},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to search alert states: %w", err)
+	}
+
+	states := make([]AlertState, 0, len(response.Hits.Hits))
+	for _, hit := range response.Hits.Hits {
+		var state AlertState
+		if err := opensearch.DecodeSource(hit.Source, &state); err != nil {
+			return nil, fmt.Errorf("failed to decode alert state: %w", err)
+		}
+		states = append(states, state)
+	}
+	return states, nil
+}
+
+// PutState creates or replaces the state of a group
+func (s *AlertStore) PutState(ctx context.Context, state *AlertState) error {
+	return s.client.PutDocument(ctx, AlertStateIndex, state.ID, state)
+}
+
+// DeleteState deletes the state of a group
+func (s *AlertStore) DeleteState(ctx context.Context, id string) error {
+	_, err := s.client.DeleteDocument(ctx, AlertStateIndex, id)
+	return err
+}
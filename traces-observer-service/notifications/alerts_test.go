@@ -0,0 +1,176 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package notifications
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/opensearch"
+)
+
+func validAlertRule() AlertRule {
+	return AlertRule{
+		ID:            "rule-1",
+		Name:          "support-agent errors",
+		Enabled:       true,
+		Metric:        AlertMetricErrorRate,
+		WindowMinutes: 15,
+		Comparison:    AlertComparisonGT,
+		Threshold:     0.1,
+		WebhookURL:    "https://hooks.example.com/amp",
+	}
+}
+
+func TestAlertRuleValidate(t *testing.T) {
+	rule := validAlertRule()
+	if err := rule.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if rule.Evaluations != 1 {
+		t.Errorf("Evaluations = %d, want the default 1", rule.Evaluations)
+	}
+
+	resolve := func(value float64) *float64 { return &value }
+	tests := map[string]func(r *AlertRule){
+		"missing name":            func(r *AlertRule) { r.Name = " " },
+		"unknown metric":          func(r *AlertRule) { r.Metric = "p90LatencyMs" },
+		"zero window":             func(r *AlertRule) { r.WindowMinutes = 0 },
+		"window over a day":       func(r *AlertRule) { r.WindowMinutes = MaxAlertWindowMinutes + 1 },
+		"label group":             func(r *AlertRule) { r.GroupBy = "label:team" },
+		"unknown comparison":      func(r *AlertRule) { r.Comparison = "eq" },
+		"negative threshold":      func(r *AlertRule) { r.Threshold = -1 },
+		"error rate over one":     func(r *AlertRule) { r.Threshold = 10 },
+		"resolve past threshold":  func(r *AlertRule) { r.ResolveThreshold = resolve(0.2) },
+		"resolve below threshold": func(r *AlertRule) { r.Comparison = AlertComparisonLT; r.ResolveThreshold = resolve(0.05) },
+		"too many evaluations":    func(r *AlertRule) { r.Evaluations = maxAlertEvaluations + 1 },
+		"negative min traces":     func(r *AlertRule) { r.MinTraceCount = -1 },
+		"relative webhook":        func(r *AlertRule) { r.WebhookURL = "/hooks" },
+	}
+	for name, mutate := range tests {
+		t.Run(name, func(t *testing.T) {
+			rule := validAlertRule()
+			mutate(&rule)
+			if err := rule.Validate(); !errors.Is(err, ErrInvalidAlertRule) {
+				t.Errorf("Validate() error = %v, want ErrInvalidAlertRule", err)
+			}
+		})
+	}
+}
+
+func TestAdvanceAlertHysteresis(t *testing.T) {
+	rule := validAlertRule()
+	resolveThreshold := 0.05
+	rule.ResolveThreshold = &resolveThreshold
+	rule.Evaluations = 2
+	rule.MinTraceCount = 10
+
+	point := func(traces, errors int) opensearch.TraceMetricsPoint {
+		return opensearch.TraceMetricsPoint{
+			TraceCount: traces,
+			ErrorCount: errors,
+			ErrorRate:  float64(errors) / float64(traces),
+		}
+	}
+	start := time.Date(2025, 11, 3, 10, 0, 0, 0, time.UTC)
+	at := func(minute int) time.Time { return start.Add(time.Duration(minute) * time.Minute) }
+
+	steps := []struct {
+		name   string
+		point  opensearch.TraceMetricsPoint
+		status string // Empty when the group has no state
+		event  string
+	}{
+		{"healthy", point(100, 1), "", ""},
+		{"too few traces to breach", point(5, 5), "", ""},
+		{"first breach", point(100, 20), AlertStatusPending, ""},
+		{"recovered before firing", point(100, 2), "", ""},
+		{"breach again", point(100, 20), AlertStatusPending, ""},
+		{"second breach fires", point(100, 30), AlertStatusFiring, EventAlertFiring},
+		{"still firing", point(100, 30), AlertStatusFiring, ""},
+		{"between thresholds keeps firing", point(100, 8), AlertStatusFiring, ""},
+		{"first clear", point(100, 1), AlertStatusFiring, ""},
+		{"flaps back", point(100, 8), AlertStatusFiring, ""},
+		{"clear again", point(100, 0), AlertStatusFiring, ""},
+		{"second clear resolves", point(100, 0), "", EventAlertResolved},
+	}
+
+	var state *AlertState
+	for i, step := range steps {
+		next, event := advance(&rule, state, "support-agent", step.point, at(i))
+		if event != step.event {
+			t.Fatalf("%s: event = %q, want %q", step.name, event, step.event)
+		}
+		status := ""
+		if next != nil {
+			status = next.Status
+		}
+		if status != step.status {
+			t.Fatalf("%s: status = %q, want %q", step.name, status, step.status)
+		}
+		if event == EventAlertFiring && (next.FiringSince == nil || !next.FiringSince.Equal(at(i))) {
+			t.Fatalf("%s: firingSince = %v, want %v", step.name, next.FiringSince, at(i))
+		}
+		if next != nil && next.ID != alertStateID(rule.ID, "support-agent") {
+			t.Fatalf("%s: id = %q, want the id derived from the rule and group", step.name, next.ID)
+		}
+		state = next
+	}
+}
+
+func TestAdvanceAlertBelowThreshold(t *testing.T) {
+	rule := validAlertRule()
+	rule.Metric = AlertMetricTraceCount
+	rule.Comparison = AlertComparisonLT
+	rule.Threshold = 10
+
+	// A group without traces is evaluated with zero metrics, so a traffic drop fires
+	state, event := advance(&rule, nil, "", opensearch.TraceMetricsPoint{}, time.Now())
+	if event != EventAlertFiring || state == nil || state.Value != 0 {
+		t.Fatalf("advance() = %+v, %q, want a firing alert", state, event)
+	}
+	state, event = advance(&rule, state, "", opensearch.TraceMetricsPoint{TraceCount: 12}, time.Now())
+	if event != EventAlertResolved || state != nil {
+		t.Fatalf("advance() = %+v, %q, want the alert resolved", state, event)
+	}
+}
+
+func TestAlertRuleValue(t *testing.T) {
+	point := opensearch.TraceMetricsPoint{
+		TraceCount:    40,
+		ErrorRate:     0.25,
+		DurationNanos: opensearch.LatencyPercentiles{P50: 2e9, P95: 31e9, P99: 45e9},
+		TotalTokens:   1200,
+		Cost:          0.75,
+	}
+	want := map[string]float64{
+		AlertMetricErrorRate:    0.25,
+		AlertMetricP50LatencyMs: 2000,
+		AlertMetricP95LatencyMs: 31000,
+		AlertMetricP99LatencyMs: 45000,
+		AlertMetricTraceCount:   40,
+		AlertMetricTotalTokens:  1200,
+		AlertMetricCost:         0.75,
+	}
+	for metric, value := range want {
+		rule := AlertRule{Metric: metric}
+		if got := rule.value(point); got != value {
+			t.Errorf("value(%s) = %v, want %v", metric, got, value)
+		}
+	}
+}
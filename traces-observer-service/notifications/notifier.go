@@ -0,0 +1,399 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package notifications
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/opensearch"
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/pricing"
+)
+
+// Config holds the notification settings
+type Config struct {
+	Finalization        opensearch.FinalizationPolicy // Traces are evaluated once finalized, traces without a root span are forgotten at the max age
+	MaxPendingTraces    int                           // Memory cap of the trace buffer, the oldest traces are forgotten when exceeded
+	RuleRefreshInterval time.Duration                 // Interval of reloading the rules changed by other replicas
+	Workers             int                           // Concurrent webhook deliveries
+	QueueSize           int                           // Deliveries waiting for a worker, further deliveries are dropped
+	MaxRetries          int                           // Retries of a failed delivery
+	RetryBackoff        time.Duration                 // Initial delay between retries, doubled on every retry
+	RequestTimeout      time.Duration                 // Timeout of a single webhook request
+	TraceLinkTemplate   string                        // Deep link of a trace with {traceId}, {componentUid} and {environmentUid} placeholders
+}
+
+// maxSpansPerTrace bounds the spans buffered per trace
+const maxSpansPerTrace = 10000
+
+// defaultMaxFinalizedTraces bounds the remembered evaluated traces when the pending traces are not capped
+const defaultMaxFinalizedTraces = 100000
+
+// Stats holds the counters of the notifier
+type Stats struct {
+	Rules             int    `json:"rules"`
+	PendingTraces     int    `json:"pendingTraces"`
+	EvaluatedTraces   uint64 `json:"evaluatedTraces"`
+	Delivered         uint64 `json:"delivered"`
+	Failed            uint64 `json:"failed"`  // Deliveries that failed after every retry
+	Dropped           uint64 `json:"dropped"` // Deliveries dropped because the queue was full
+	ForgottenTraces   uint64 `json:"forgottenTraces"`
+	LastRuleLoadError string `json:"lastRuleLoadError,omitempty"`
+}
+
+// TraceSummary holds the values of a finished trace that rules are evaluated on
+type TraceSummary struct {
+	TraceID        string
+	Agent          string
+	ComponentUid   string
+	EnvironmentUid string
+	StartTime      time.Time
+	Duration       time.Duration
+	Cost           *float64 // Nil when no model of the trace is priced
+	ErrorCount     int
+	FirstError     string
+}
+
+// pendingTrace holds the spans of a trace until it is evaluated
+type pendingTrace struct {
+	firstSeen time.Time
+	lastSeen  time.Time // Arrival of the latest span, the trace stays open until it is quiet
+	root      *opensearch.Span
+	spans     []opensearch.Span
+	spanIndex map[string]int // Position of each span in spans, re-delivered spans replace their earlier copy
+}
+
+// Notifier evaluates finished traces against the notification rules and delivers webhooks for matches
+type Notifier struct {
+	cfg          Config
+	store        *Store
+	pricingTable *pricing.Table
+	sender       *webhookSender
+
+	mu     sync.Mutex
+	traces map[string]*pendingTrace
+	order  []string // Trace IDs in arrival order, used for eviction
+
+	// Recently evaluated traces, late spans of which are ignored so that a trace never alerts twice
+	finalized      map[string]bool
+	finalizedOrder []string
+
+	rules         atomic.Pointer[[]Rule]
+	ruleLoadError atomic.Pointer[string]
+	queue         chan delivery
+
+	evaluated atomic.Uint64
+	delivered atomic.Uint64
+	failed    atomic.Uint64
+	dropped   atomic.Uint64
+	forgotten atomic.Uint64
+
+	stop    chan struct{}
+	done    chan struct{}
+	workers sync.WaitGroup
+}
+
+// NewNotifier creates a notifier, Start loads the rules and starts evaluating traces
+func NewNotifier(cfg Config, store *Store, pricingTable *pricing.Table) *Notifier {
+	if cfg.Finalization.QuietPeriod <= 0 {
+		cfg.Finalization.QuietPeriod = 10 * time.Second
+	}
+	if cfg.RuleRefreshInterval <= 0 {
+		cfg.RuleRefreshInterval = 30 * time.Second
+	}
+	if cfg.Workers <= 0 {
+		cfg.Workers = 2
+	}
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = 1000
+	}
+	if cfg.RequestTimeout <= 0 {
+		cfg.RequestTimeout = 10 * time.Second
+	}
+
+	n := &Notifier{
+		cfg:          cfg,
+		store:        store,
+		pricingTable: pricingTable,
+		sender:       newWebhookSender(cfg.MaxRetries, cfg.RetryBackoff, cfg.RequestTimeout),
+		traces:       make(map[string]*pendingTrace),
+		finalized:    make(map[string]bool),
+		queue:        make(chan delivery, cfg.QueueSize),
+		stop:         make(chan struct{}),
+		done:         make(chan struct{}),
+	}
+	n.rules.Store(&[]Rule{})
+	return n
+}
+
+// Start creates the rule index, loads the rules and starts the evaluation loop and delivery workers
+func (n *Notifier) Start(ctx context.Context) error {
+	if err := n.store.EnsureIndex(ctx); err != nil {
+		return err
+	}
+	if err := n.ReloadRules(ctx); err != nil {
+		return err
+	}
+
+	for i := 0; i < n.cfg.Workers; i++ {
+		n.workers.Add(1)
+		go n.deliverLoop()
+	}
+	go n.run()
+	return nil
+}
+
+// Close stops evaluating traces and waits for the queued deliveries
+// Traces still waiting for their root span or the finalize wait are not evaluated
+func (n *Notifier) Close(ctx context.Context) {
+	close(n.stop)
+	<-n.done
+	close(n.queue)
+
+	done := make(chan struct{})
+	go func() {
+		n.workers.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		slog.Warn("Notification deliveries still in flight at shutdown", "queued", len(n.queue))
+	}
+}
+
+// ReloadRules replaces the active rules with the rules in the store
+func (n *Notifier) ReloadRules(ctx context.Context) error {
+	rules, err := n.store.List(ctx)
+	if err != nil {
+		message := err.Error()
+		n.ruleLoadError.Store(&message)
+		return err
+	}
+	n.rules.Store(&rules)
+	n.ruleLoadError.Store(nil)
+	return nil
+}
+
+// Observe buffers a processed span of a trace until the trace is finished
+func (n *Notifier) Observe(span opensearch.Span) {
+	if len(*n.rules.Load()) == 0 {
+		return
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if n.finalized[span.TraceID] {
+		return
+	}
+
+	now := time.Now()
+	trace, ok := n.traces[span.TraceID]
+	if !ok {
+		trace = &pendingTrace{firstSeen: now, spanIndex: make(map[string]int)}
+		n.traces[span.TraceID] = trace
+		n.order = append(n.order, span.TraceID)
+	}
+	trace.lastSeen = now
+
+	light := opensearch.LightweightSpan(span)
+	if span.ParentSpanID == "" && trace.root == nil {
+		trace.root = &light
+	}
+	if i, ok := trace.spanIndex[span.SpanID]; ok {
+		trace.spans[i] = light
+	} else if len(trace.spans) < maxSpansPerTrace {
+		trace.spanIndex[span.SpanID] = len(trace.spans)
+		trace.spans = append(trace.spans, light)
+	}
+
+	// Forget the oldest traces when the buffer exceeds its cap
+	for n.cfg.MaxPendingTraces > 0 && len(n.traces) > n.cfg.MaxPendingTraces && len(n.order) > 0 {
+		oldest := n.order[0]
+		n.order = n.order[1:]
+		if _, ok := n.traces[oldest]; ok {
+			delete(n.traces, oldest)
+			n.forgotten.Add(1)
+		}
+	}
+}
+
+// Stats returns the notifier counters
+func (n *Notifier) Stats() Stats {
+	n.mu.Lock()
+	pending := len(n.traces)
+	n.mu.Unlock()
+
+	stats := Stats{
+		Rules:           len(*n.rules.Load()),
+		PendingTraces:   pending,
+		EvaluatedTraces: n.evaluated.Load(),
+		Delivered:       n.delivered.Load(),
+		Failed:          n.failed.Load(),
+		Dropped:         n.dropped.Load(),
+		ForgottenTraces: n.forgotten.Load(),
+	}
+	if message := n.ruleLoadError.Load(); message != nil {
+		stats.LastRuleLoadError = *message
+	}
+	return stats
+}
+
+// run evaluates finished traces and periodically reloads the rules
+func (n *Notifier) run() {
+	defer close(n.done)
+
+	interval := n.cfg.Finalization.QuietPeriod / 4
+	if interval < 100*time.Millisecond {
+		interval = 100 * time.Millisecond
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	refresh := time.NewTicker(n.cfg.RuleRefreshInterval)
+	defer refresh.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			for _, trace := range n.takeFinished(time.Now()) {
+				n.evaluate(n.summarize(trace))
+			}
+		case <-refresh.C:
+			if err := n.ReloadRules(context.Background()); err != nil {
+				slog.Error("Failed to reload notification rules", "error", err)
+			}
+		case <-n.stop:
+			return
+		}
+	}
+}
+
+// takeFinished removes the finalized traces and forgets traces whose root span did not arrive within the max age
+func (n *Notifier) takeFinished(now time.Time) []*pendingTrace {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	var finished []*pendingTrace
+	remaining := n.order[:0]
+	for _, traceID := range n.order {
+		trace, ok := n.traces[traceID]
+		if !ok {
+			continue
+		}
+		// The root span usually ends last, so a trace without it stays open until its max age
+		switch {
+		case trace.root != nil && n.cfg.Finalization.Finalized(trace.firstSeen, trace.lastSeen, now):
+			delete(n.traces, traceID)
+			finished = append(finished, trace)
+			n.rememberFinalized(traceID)
+		case trace.root == nil && n.cfg.Finalization.MaxAge > 0 && now.Sub(trace.firstSeen) >= n.cfg.Finalization.MaxAge:
+			delete(n.traces, traceID)
+			n.forgotten.Add(1)
+		default:
+			remaining = append(remaining, traceID)
+		}
+	}
+	n.order = remaining
+	return finished
+}
+
+// rememberFinalized records an evaluated trace, forgetting the oldest beyond the pending trace cap
+// Must be called with the lock held
+func (n *Notifier) rememberFinalized(traceID string) {
+	limit := n.cfg.MaxPendingTraces
+	if limit <= 0 {
+		limit = defaultMaxFinalizedTraces
+	}
+	n.finalized[traceID] = true
+	n.finalizedOrder = append(n.finalizedOrder, traceID)
+	for len(n.finalizedOrder) > limit {
+		delete(n.finalized, n.finalizedOrder[0])
+		n.finalizedOrder = n.finalizedOrder[1:]
+	}
+}
+
+// summarize computes the duration, errors and cost of a finished trace
+func (n *Notifier) summarize(trace *pendingTrace) *TraceSummary {
+	root := trace.root
+	summary := &TraceSummary{
+		TraceID:   root.TraceID,
+		StartTime: root.StartTime,
+	}
+	summary.Agent, _ = root.Resource["service.name"].(string)
+	summary.ComponentUid, _ = root.Resource["openchoreo.dev/component-uid"].(string)
+	summary.EnvironmentUid, _ = root.Resource["openchoreo.dev/environment-uid"].(string)
+	if root.EndTime.After(root.StartTime) {
+		summary.Duration = root.EndTime.Sub(root.StartTime)
+	} else {
+		summary.Duration = time.Duration(root.DurationInNanos)
+	}
+
+	for _, span := range trace.spans {
+		if span.AmpAttributes == nil || span.AmpAttributes.Status == nil || !span.AmpAttributes.Status.Error {
+			continue
+		}
+		summary.ErrorCount++
+		if summary.FirstError == "" {
+			summary.FirstError = errorMessage(span)
+		}
+	}
+
+	usage := opensearch.AggregateTraceTokenUsage(trace.spans)
+	usage.ApplyPricing(n.pricingTable)
+	if usage != nil && usage.Cost != nil {
+		cost := usage.Cost.TotalCost
+		summary.Cost = &cost
+	}
+	return summary
+}
+
+// errorMessage describes the failure of a span
+func errorMessage(span opensearch.Span) string {
+	parts := []string{span.Name}
+	if data := span.AmpAttributes.Error; data != nil {
+		if data.Type != "" {
+			parts = append(parts, data.Type)
+		}
+		if data.Message != "" {
+			parts = append(parts, data.Message)
+		}
+	} else if span.AmpAttributes.Status.ErrorType != "" {
+		parts = append(parts, span.AmpAttributes.Status.ErrorType)
+	}
+	return strings.Join(parts, ": ")
+}
+
+// evaluate queues a delivery for every rule the trace matches
+func (n *Notifier) evaluate(trace *TraceSummary) {
+	n.evaluated.Add(1)
+	for _, rule := range *n.rules.Load() {
+		if !rule.matches(trace) {
+			continue
+		}
+		select {
+		case n.queue <- delivery{rule: rule, payload: n.buildPayload(rule, trace)}:
+		default:
+			n.dropped.Add(1)
+			slog.Warn("Notification queue full, dropping delivery", "rule", rule.ID, "traceId", trace.TraceID)
+		}
+	}
+}
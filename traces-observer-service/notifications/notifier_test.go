@@ -0,0 +1,307 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package notifications
+
+import (
+	"errors"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/opensearch"
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/pricing"
+)
+
+func validRule() Rule {
+	return Rule{
+		ID:         "rule-1",
+		Name:       "support-agent failures",
+		Enabled:    true,
+		OnError:    true,
+		WebhookURL: "https://hooks.example.com/amp",
+	}
+}
+
+func TestRuleValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*Rule)
+	}{
+		{"missing name", func(r *Rule) { r.Name = " " }},
+		{"no condition", func(r *Rule) { r.OnError = false }},
+		{"negative duration", func(r *Rule) { r.MinDurationMs = -1 }},
+		{"negative cost", func(r *Rule) { r.MinCost = -0.5 }},
+		{"invalid agent pattern", func(r *Rule) { r.AgentPattern = "support-[" }},
+		{"relative webhook", func(r *Rule) { r.WebhookURL = "/hooks/amp" }},
+		{"webhook scheme", func(r *Rule) { r.WebhookURL = "ftp://hooks.example.com/amp" }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := validRule()
+			tt.modify(&rule)
+			if err := rule.Validate(); !errors.Is(err, ErrInvalidRule) {
+				t.Errorf("Validate() error = %v, want %v", err, ErrInvalidRule)
+			}
+		})
+	}
+
+	rule := validRule()
+	if err := rule.Validate(); err != nil {
+		t.Errorf("Validate() error = %v, want a valid rule", err)
+	}
+}
+
+func TestRuleMatches(t *testing.T) {
+	cost := 0.5
+	trace := &TraceSummary{Agent: "support-agent", Duration: 3 * time.Second, Cost: &cost, ErrorCount: 1}
+	tests := []struct {
+		name string
+		rule Rule
+		want bool
+	}{
+		{"failed trace", Rule{Enabled: true, OnError: true}, true},
+		{"disabled rule", Rule{OnError: true}, false},
+		{"agent pattern", Rule{Enabled: true, OnError: true, AgentPattern: "support-*"}, true},
+		{"other agents", Rule{Enabled: true, OnError: true, AgentPattern: "billing-*"}, false},
+		{"slower than the budget", Rule{Enabled: true, MinDurationMs: 2000}, true},
+		{"within the duration budget", Rule{Enabled: true, MinDurationMs: 3000}, false},
+		{"costlier than the budget", Rule{Enabled: true, MinCost: 0.25}, true},
+		{"within the cost budget", Rule{Enabled: true, MinCost: 1}, false},
+		{"any condition", Rule{Enabled: true, MinDurationMs: 60000, MinCost: 0.25}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.rule.matches(trace); got != tt.want {
+				t.Errorf("matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// Unpriced traces never exceed a cost budget
+	if (&Rule{Enabled: true, MinCost: 0.01}).matches(&TraceSummary{}) {
+		t.Error("unpriced trace matched a cost budget")
+	}
+}
+
+func TestBuildPayload(t *testing.T) {
+	n := NewNotifier(Config{TraceLinkTemplate: "https://console.example.com/{componentUid}/traces/{traceId}"}, nil, nil)
+	cost := 2.5
+	trace := &TraceSummary{
+		TraceID:      "trace-1",
+		Agent:        "support-agent",
+		ComponentUid: "comp 1",
+		Duration:     90 * time.Second,
+		Cost:         &cost,
+		ErrorCount:   2,
+		FirstError:   "search: TimeoutError: upstream timed out",
+	}
+	rule := Rule{ID: "rule-1", Name: "budget", Enabled: true, OnError: true, MinDurationMs: 1000, MinCost: 5}
+
+	payload := n.buildPayload(rule, trace)
+	if payload.DeliveryID != "rule-1-trace-1" || payload.Event != EventTraceAlert || payload.DurationMs != 90000 {
+		t.Errorf("payload = %+v", payload)
+	}
+	if want := []string{"error", "duration"}; !reflect.DeepEqual(payload.Reasons, want) {
+		t.Errorf("reasons = %v, want %v", payload.Reasons, want)
+	}
+	if want := "https://console.example.com/comp%201/traces/trace-1"; payload.Link != want {
+		t.Errorf("link = %s, want %s", payload.Link, want)
+	}
+}
+
+func TestSign(t *testing.T) {
+	got := Sign("key", []byte(`{"event":"trace.alert"}`))
+	if want := "sha256=2aaf09284245e2743fc7fd12276bb9a66314f43687e5a41e4c685fa4807fc7af"; got != want {
+		t.Errorf("Sign() = %s, want %s", got, want)
+	}
+}
+
+// webhookReceiver records the webhook requests it receives and answers with the scripted statuses
+type webhookReceiver struct {
+	mu       sync.Mutex
+	statuses []int
+	requests []*http.Request
+	bodies   []string
+}
+
+func (f *webhookReceiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.requests = append(f.requests, r)
+	f.bodies = append(f.bodies, string(body))
+	status := http.StatusOK
+	if len(f.statuses) > 0 {
+		status, f.statuses = f.statuses[0], f.statuses[1:]
+	}
+	w.WriteHeader(status)
+}
+
+func TestWebhookSenderRetries(t *testing.T) {
+	tests := []struct {
+		name         string
+		statuses     []int
+		wantErr      bool
+		wantRequests int
+	}{
+		{"delivered", nil, false, 1},
+		{"retried on 503 and 429", []int{http.StatusServiceUnavailable, http.StatusTooManyRequests}, false, 3},
+		{"client errors are not retried", []int{http.StatusBadRequest}, true, 1},
+		{"retries exhausted", []int{500, 500, 500, 500}, true, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			receiver := &webhookReceiver{statuses: tt.statuses}
+			server := httptest.NewServer(receiver)
+			defer server.Close()
+
+			sender := newWebhookSender(2, time.Millisecond, time.Second)
+			body := []byte(`{"event":"trace.alert"}`)
+			err := sender.send(server.URL, "key", EventTraceAlert, "rule-1-trace-1", body)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("send() error = %v, want error %v", err, tt.wantErr)
+			}
+			if len(receiver.requests) != tt.wantRequests {
+				t.Fatalf("requests = %d, want %d", len(receiver.requests), tt.wantRequests)
+			}
+			// Retries repeat the delivery ID so that receivers can deduplicate them
+			for _, r := range receiver.requests {
+				if r.Header.Get(DeliveryHeader) != "rule-1-trace-1" || r.Header.Get(EventHeader) != EventTraceAlert || r.Header.Get(SignatureHeader) != Sign("key", body) {
+					t.Errorf("headers = %v, want the delivery, event and signature", r.Header)
+				}
+			}
+		})
+	}
+}
+
+// notifierSpan returns a processed span of the support agent
+func notifierSpan(traceID, spanID, parentSpanID string, start time.Time, attributes map[string]interface{}, status map[string]interface{}) opensearch.Span {
+	source := map[string]interface{}{
+		"traceId":      traceID,
+		"spanId":       spanID,
+		"parentSpanId": parentSpanID,
+		"name":         spanID,
+		"startTime":    start.Format(time.RFC3339Nano),
+		"endTime":      start.Add(2 * time.Second).Format(time.RFC3339Nano),
+		"attributes":   attributes,
+		"resource":     map[string]interface{}{"service.name": "support-agent", "openchoreo.dev/component-uid": "comp-1"},
+	}
+	if status != nil {
+		source["status"] = status
+	}
+	return opensearch.ProcessDocument(source)
+}
+
+// newTestNotifier returns a notifier with the given rules whose deliveries are read from its queue
+func newTestNotifier(cfg Config, rules ...Rule) *Notifier {
+	table := pricing.NewTable(map[string]pricing.ModelPrice{"model-a": {InputPerMillion: 1000, OutputPerMillion: 1000}})
+	n := NewNotifier(cfg, nil, table)
+	n.rules.Store(&rules)
+	return n
+}
+
+func TestNotifierEvaluatesFinishedTraces(t *testing.T) {
+	n := newTestNotifier(Config{Finalization: opensearch.FinalizationPolicy{QuietPeriod: time.Minute}},
+		Rule{ID: "errors", Name: "errors", Enabled: true, OnError: true},
+		Rule{ID: "cost", Name: "cost", Enabled: true, MinCost: 1},
+	)
+	start := time.Date(2026, 10, 16, 10, 0, 0, 0, time.UTC)
+
+	n.Observe(notifierSpan("trace-1", "root", "", start, map[string]interface{}{}, nil))
+	n.Observe(notifierSpan("trace-1", "search", "root", start, map[string]interface{}{"traceloop.span.kind": "tool"},
+		map[string]interface{}{"code": "Error", "message": "upstream timed out"}))
+	n.Observe(notifierSpan("trace-1", "chat", "root", start, map[string]interface{}{
+		"gen_ai.operation.name":      "chat",
+		"gen_ai.request.model":       "model-a",
+		"gen_ai.usage.input_tokens":  1000,
+		"gen_ai.usage.output_tokens": 1000,
+	}, nil))
+	// A trace without its root span stays open
+	n.Observe(notifierSpan("trace-2", "child", "missing-root", start, map[string]interface{}{}, nil))
+
+	if finished := n.takeFinished(time.Now()); len(finished) != 0 {
+		t.Fatalf("finished = %d, want none before the quiet period", len(finished))
+	}
+	finished := n.takeFinished(time.Now().Add(time.Minute))
+	if len(finished) != 1 {
+		t.Fatalf("finished = %d, want trace-1 only", len(finished))
+	}
+
+	summary := n.summarize(finished[0])
+	if summary.TraceID != "trace-1" || summary.Agent != "support-agent" || summary.ComponentUid != "comp-1" || summary.Duration != 2*time.Second {
+		t.Errorf("summary = %+v", summary)
+	}
+	if summary.ErrorCount != 1 || summary.FirstError != "search: upstream timed out" {
+		t.Errorf("errors = %d %q, want the failed search span", summary.ErrorCount, summary.FirstError)
+	}
+	if summary.Cost == nil || math.Abs(*summary.Cost-2) > 1e-9 {
+		t.Errorf("cost = %v, want 2", summary.Cost)
+	}
+
+	n.evaluate(summary)
+	if len(n.queue) != 2 {
+		t.Fatalf("deliveries = %d, want one per matching rule", len(n.queue))
+	}
+	for _, want := range []string{"error", "cost"} {
+		if item := <-n.queue; !reflect.DeepEqual(item.payload.Reasons, []string{want}) {
+			t.Errorf("reasons = %v, want %s", item.payload.Reasons, want)
+		}
+	}
+
+	// Late spans of an evaluated trace are ignored, so that it never alerts twice
+	n.Observe(notifierSpan("trace-1", "late", "root", start, map[string]interface{}{}, nil))
+	if stats := n.Stats(); stats.PendingTraces != 1 || stats.EvaluatedTraces != 1 {
+		t.Errorf("stats = %+v, want only trace-2 pending", stats)
+	}
+}
+
+func TestNotifierForgetsTraces(t *testing.T) {
+	n := newTestNotifier(Config{
+		Finalization:     opensearch.FinalizationPolicy{QuietPeriod: time.Minute, MaxAge: time.Hour},
+		MaxPendingTraces: 2,
+	}, Rule{ID: "errors", Name: "errors", Enabled: true, OnError: true})
+	start := time.Date(2026, 10, 16, 10, 0, 0, 0, time.UTC)
+
+	// The oldest trace is forgotten when the buffer exceeds its cap
+	for _, traceID := range []string{"trace-1", "trace-2", "trace-3"} {
+		n.Observe(notifierSpan(traceID, "child", "missing-root", start, map[string]interface{}{}, nil))
+	}
+	if _, ok := n.traces["trace-1"]; ok || len(n.traces) != 2 {
+		t.Errorf("pending traces = %v, want trace-1 evicted", n.order)
+	}
+
+	// Traces whose root span never arrives are forgotten at the max age
+	if finished := n.takeFinished(time.Now().Add(time.Hour)); len(finished) != 0 {
+		t.Errorf("finished = %d, want traces without a root span forgotten rather than evaluated", len(finished))
+	}
+	if stats := n.Stats(); stats.PendingTraces != 0 || stats.ForgottenTraces != 3 {
+		t.Errorf("stats = %+v, want 3 forgotten traces", stats)
+	}
+}
+
+func TestNotifierIgnoresSpansWithoutRules(t *testing.T) {
+	n := newTestNotifier(Config{})
+	n.Observe(notifierSpan("trace-1", "root", "", time.Now(), map[string]interface{}{}, nil))
+	if stats := n.Stats(); stats.PendingTraces != 0 {
+		t.Errorf("pending traces = %d, want none buffered without rules", stats.PendingTraces)
+	}
+}
@@ -0,0 +1,174 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package notifications
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/opensearch"
+)
+
+// RuleIndex is the index holding the notification rules
+const RuleIndex = "amp-notification-rules"
+
+// maxRules bounds the number of rules loaded from the rule index
+const maxRules = 1000
+
+// ErrInvalidRule is returned when a rule fails validation
+var ErrInvalidRule = errors.New("invalid notification rule")
+
+// Rule notifies a webhook about finished traces of matching agents that failed or exceeded a budget
+// A trace matches when its agent matches AgentPattern and any of the enabled conditions holds
+type Rule struct {
+	ID            string    `json:"id"`
+	Name          string    `json:"name"`
+	Enabled       bool      `json:"enabled"`
+	OnError       bool      `json:"onError,omitempty"`       // Match traces with a failed span
+	MinDurationMs int64     `json:"minDurationMs,omitempty"` // Match traces lasting longer, disabled when zero
+	MinCost       float64   `json:"minCost,omitempty"`       // Match traces costing more in USD, disabled when zero
+	AgentPattern  string    `json:"agentPattern,omitempty"`  // Glob matched against the agent name, all agents when empty
+	WebhookURL    string    `json:"webhookUrl"`
+	Secret        string    `json:"secret,omitempty"` // HMAC key of the signature header, never returned after creation
+	CreatedAt     time.Time `json:"createdAt"`
+	UpdatedAt     time.Time `json:"updatedAt"`
+}
+
+// Validate checks the conditions and target of a rule
+func (r *Rule) Validate() error {
+	if strings.TrimSpace(r.Name) == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidRule)
+	}
+	if !r.OnError && r.MinDurationMs <= 0 && r.MinCost <= 0 {
+		return fmt.Errorf("%w: one of onError, minDurationMs or minCost is required", ErrInvalidRule)
+	}
+	if r.MinDurationMs < 0 || r.MinCost < 0 {
+		return fmt.Errorf("%w: minDurationMs and minCost must not be negative", ErrInvalidRule)
+	}
+	if r.AgentPattern != "" {
+		if _, err := path.Match(r.AgentPattern, ""); err != nil {
+			return fmt.Errorf("%w: invalid agentPattern: %v", ErrInvalidRule, err)
+		}
+	}
+	target, err := url.Parse(r.WebhookURL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return fmt.Errorf("%w: webhookUrl must be an absolute http or https URL", ErrInvalidRule)
+	}
+	return nil
+}
+
+// matches reports whether a finished trace satisfies the rule
+func (r *Rule) matches(trace *TraceSummary) bool {
+	if !r.Enabled {
+		return false
+	}
+	if r.AgentPattern != "" {
+		if matched, _ := path.Match(r.AgentPattern, trace.Agent); !matched {
+			return false
+		}
+	}
+	if r.OnError && trace.ErrorCount > 0 {
+		return true
+	}
+	if r.MinDurationMs > 0 && trace.Duration > time.Duration(r.MinDurationMs)*time.Millisecond {
+		return true
+	}
+	if r.MinCost > 0 && trace.Cost != nil && *trace.Cost > r.MinCost {
+		return true
+	}
+	return false
+}
+
+// Redacted returns a copy of the rule without its secret
+func (r Rule) Redacted() Rule {
+	r.Secret = ""
+	return r
+}
+
+// Store persists the notification rules in OpenSearch so that every replica shares them
+type Store struct {
+	client *opensearch.Client
+}
+
+// NewStore creates a rule store
+func NewStore(client *opensearch.Client) *Store {
+	return &Store{client: client}
+}
+
+// EnsureIndex creates the rule index when it does not exist yet
+func (s *Store) EnsureIndex(ctx context.Context) error {
+	return s.client.EnsureIndex(ctx, RuleIndex, map[string]interface{}{
+		"mappings": map[string]interface{}{
+			"dynamic": false,
+			"properties": map[string]interface{}{
+				"id":        map[string]interface{}{"type": "keyword"},
+				"name":      map[string]interface{}{"type": "keyword"},
+				"enabled":   map[string]interface{}{"type": "boolean"},
+				"createdAt": map[string]interface{}{"type": "date"},
+			},
+		},
+	})
+}
+
+// List returns every rule in creation order
+func (s *Store) List(ctx context.Context) ([]Rule, error) {
+	response, err := s.client.Search(ctx, []string{RuleIndex}, map[string]interface{}{
+		"query": map[string]interface{}{"match_all": map[string]interface{}{}},
+		"size":  maxRules,
+		"sort": []map[string]interface{}{
+			{"createdAt": map[string]string{"order": "asc"}},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to search notification rules: %w", err)
+	}
+
+	rules := make([]Rule, 0, len(response.Hits.Hits))
+	for _, hit := range response.Hits.Hits {
+		var rule Rule
+		if err := opensearch.DecodeSource(hit.Source, &rule); err != nil {
+			return nil, fmt.Errorf("failed to decode notification rule: %w", err)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// Get returns a rule, false when it does not exist
+func (s *Store) Get(ctx context.Context, id string) (*Rule, bool, error) {
+	var rule Rule
+	found, err := s.client.GetDocument(ctx, RuleIndex, id, &rule)
+	if err != nil || !found {
+		return nil, found, err
+	}
+	return &rule, true, nil
+}
+
+// Put creates or replaces a rule
+func (s *Store) Put(ctx context.Context, rule *Rule) error {
+	return s.client.PutDocument(ctx, RuleIndex, rule.ID, rule)
+}
+
+// Delete deletes a rule, false when it does not exist
+func (s *Store) Delete(ctx context.Context, id string) (bool, error) {
+	return s.client.DeleteDocument(ctx, RuleIndex, id)
+}
@@ -0,0 +1,204 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package notifications
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Webhook headers
+const (
+	SignatureHeader = "X-AMP-Signature" // sha256=<hex HMAC-SHA256 of the body keyed with the rule secret>
+	EventHeader     = "X-AMP-Event"
+	DeliveryHeader  = "X-AMP-Delivery" // Unique per notification, repeated on retries so receivers can deduplicate
+)
+
+// EventTraceAlert is the event type of trace notifications
+const EventTraceAlert = "trace.alert"
+
+// Payload is the JSON body posted to a webhook
+type Payload struct {
+	Event          string    `json:"event"`
+	DeliveryID     string    `json:"deliveryId"`
+	RuleID         string    `json:"ruleId"`
+	RuleName       string    `json:"ruleName"`
+	TraceID        string    `json:"traceId"`
+	Agent          string    `json:"agent,omitempty"`
+	ComponentUid   string    `json:"componentUid,omitempty"`
+	EnvironmentUid string    `json:"environmentUid,omitempty"`
+	StartTime      time.Time `json:"startTime"`
+	DurationMs     int64     `json:"durationMs"`
+	Cost           *float64  `json:"cost"` // USD, null when no model of the trace is priced
+	ErrorCount     int       `json:"errorCount"`
+	ErrorSummary   string    `json:"errorSummary,omitempty"` // First failed span and its error
+	Link           string    `json:"link,omitempty"`
+	Reasons        []string  `json:"reasons"` // Conditions of the rule the trace matched
+}
+
+// delivery is a payload waiting to be posted to the webhook of a rule
+type delivery struct {
+	rule    Rule
+	payload Payload
+}
+
+// buildPayload builds the notification of a trace matching a rule
+func (n *Notifier) buildPayload(rule Rule, trace *TraceSummary) Payload {
+	payload := Payload{
+		Event:          EventTraceAlert,
+		DeliveryID:     rule.ID + "-" + trace.TraceID,
+		RuleID:         rule.ID,
+		RuleName:       rule.Name,
+		TraceID:        trace.TraceID,
+		Agent:          trace.Agent,
+		ComponentUid:   trace.ComponentUid,
+		EnvironmentUid: trace.EnvironmentUid,
+		StartTime:      trace.StartTime,
+		DurationMs:     trace.Duration.Milliseconds(),
+		Cost:           trace.Cost,
+		ErrorCount:     trace.ErrorCount,
+		ErrorSummary:   trace.FirstError,
+		Reasons:        []string{},
+	}
+	if n.cfg.TraceLinkTemplate != "" {
+		payload.Link = strings.NewReplacer(
+			"{traceId}", url.PathEscape(trace.TraceID),
+			"{componentUid}", url.PathEscape(trace.ComponentUid),
+			"{environmentUid}", url.PathEscape(trace.EnvironmentUid),
+		).Replace(n.cfg.TraceLinkTemplate)
+	}
+
+	if rule.OnError && trace.ErrorCount > 0 {
+		payload.Reasons = append(payload.Reasons, "error")
+	}
+	if rule.MinDurationMs > 0 && trace.Duration > time.Duration(rule.MinDurationMs)*time.Millisecond {
+		payload.Reasons = append(payload.Reasons, "duration")
+	}
+	if rule.MinCost > 0 && trace.Cost != nil && *trace.Cost > rule.MinCost {
+		payload.Reasons = append(payload.Reasons, "cost")
+	}
+	return payload
+}
+
+// Sign computes the signature header value of a body
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// deliverLoop posts queued notifications until the queue is closed
+func (n *Notifier) deliverLoop() {
+	defer n.workers.Done()
+	for item := range n.queue {
+		if err := n.deliver(item); err != nil {
+			n.failed.Add(1)
+			slog.Error("Failed to deliver notification",
+				"rule", item.rule.ID, "traceId", item.payload.TraceID, "error", err)
+			continue
+		}
+		n.delivered.Add(1)
+	}
+}
+
+// deliver posts a notification
+func (n *Notifier) deliver(item delivery) error {
+	body, err := json.Marshal(item.payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode notification: %w", err)
+	}
+	return n.sender.send(item.rule.WebhookURL, item.rule.Secret, EventTraceAlert, item.payload.DeliveryID, body)
+}
+
+// webhookSender posts signed webhook requests
+type webhookSender struct {
+	httpClient     *http.Client
+	maxRetries     int
+	retryBackoff   time.Duration
+	requestTimeout time.Duration
+}
+
+// newWebhookSender creates a sender retrying failed requests up to maxRetries times
+func newWebhookSender(maxRetries int, retryBackoff, requestTimeout time.Duration) *webhookSender {
+	return &webhookSender{
+		httpClient:     &http.Client{Timeout: requestTimeout},
+		maxRetries:     maxRetries,
+		retryBackoff:   retryBackoff,
+		requestTimeout: requestTimeout,
+	}
+}
+
+// send posts a body, retrying network errors, 429 and 5xx responses with exponential backoff
+func (s *webhookSender) send(target, secret, event, deliveryID string, body []byte) error {
+	backoff := s.retryBackoff
+	var lastErr error
+	for attempt := 0; attempt <= s.maxRetries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+
+		retry, err := s.post(target, secret, event, deliveryID, body)
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+		if !retry {
+			break
+		}
+	}
+	return lastErr
+}
+
+// post sends a single webhook request and reports whether a failure is worth retrying
+func (s *webhookSender) post(target, secret, event, deliveryID string, body []byte) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.requestTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return false, fmt.Errorf("failed to build webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, event)
+	req.Header.Set(DeliveryHeader, deliveryID)
+	if secret != "" {
+		req.Header.Set(SignatureHeader, Sign(secret, body))
+	}
+
+	res, err := s.httpClient.Do(req)
+	if err != nil {
+		return true, fmt.Errorf("webhook request failed: %w", err)
+	}
+	res.Body.Close()
+
+	if res.StatusCode >= 200 && res.StatusCode < 300 {
+		return false, nil
+	}
+	retry := res.StatusCode == http.StatusTooManyRequests || res.StatusCode >= 500
+	return retry, fmt.Errorf("webhook request failed with status: %s", res.Status)
+}
@@ -0,0 +1,65 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package objectstore
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"path"
+	"strings"
+
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/opensearch"
+)
+
+// MediaStore keeps the media payloads removed from spans in a bucket
+// Objects are keyed by their SHA-256 digest under the span, so an image repeated across the
+// calls of a conversation is stored once per span and re-ingesting a span overwrites the same object
+type MediaStore struct {
+	client *S3Client
+	prefix string
+}
+
+// NewMediaStore creates a media store writing under the given key prefix
+func NewMediaStore(client *S3Client, prefix string) *MediaStore {
+	return &MediaStore{client: client, prefix: prefix}
+}
+
+// StoreMedia uploads a payload and returns its s3:// reference
+func (s *MediaStore) StoreMedia(traceID, spanID string, part opensearch.MediaPart) (string, error) {
+	md5Sum := md5.Sum(part.Data)
+	sha256Sum := sha256.Sum256(part.Data)
+
+	name := hex.EncodeToString(sha256Sum[:])
+	if _, subtype, ok := strings.Cut(part.MIME, "/"); ok && subtype != "" {
+		name += "." + strings.SplitN(subtype, "+", 2)[0]
+	}
+	key := path.Join(s.prefix, traceID, spanID, name)
+
+	// The request timeout of the client bounds the upload
+	contentType := part.MIME
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	err := s.client.PutObject(context.Background(), key, contentType, bytes.NewReader(part.Data), int64(len(part.Data)), md5Sum[:], sha256Sum[:])
+	if err != nil {
+		return "", err
+	}
+	return "s3://" + s.client.Bucket() + "/" + key, nil
+}
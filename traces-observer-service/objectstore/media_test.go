@@ -0,0 +1,65 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package objectstore
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/opensearch"
+)
+
+func TestMediaStore(t *testing.T) {
+	store, client := newFakeS3Client(t)
+	media := NewMediaStore(client, "media")
+	data := []byte("\x89PNG\r\n\x1a\n")
+	sum := sha256.Sum256(data)
+	digest := hex.EncodeToString(sum[:])
+
+	tests := []struct {
+		mime    string
+		wantKey string
+	}{
+		{"image/png", "media/trace-1/span-1/" + digest + ".png"},
+		{"image/svg+xml", "media/trace-1/span-1/" + digest + ".svg"},
+		{"", "media/trace-1/span-1/" + digest},
+	}
+	for _, tt := range tests {
+		ref, err := media.StoreMedia("trace-1", "span-1", opensearch.MediaPart{Kind: "image", MIME: tt.mime, Data: data})
+		if err != nil {
+			t.Fatalf("StoreMedia() error = %v", err)
+		}
+		if ref != "s3://traces/"+tt.wantKey {
+			t.Errorf("StoreMedia() = %s, want s3://traces/%s", ref, tt.wantKey)
+		}
+		if string(store.objects[tt.wantKey]) != string(data) {
+			t.Errorf("object %s = %q, want the payload", tt.wantKey, store.objects[tt.wantKey])
+		}
+	}
+}
+
+func TestMediaStoreReportsUploadFailures(t *testing.T) {
+	_, client := newFakeS3Client(t)
+	client.cfg.AccessKeyID = "other"
+	media := NewMediaStore(client, "media")
+
+	if _, err := media.StoreMedia("trace-1", "span-1", opensearch.MediaPart{MIME: "image/png", Data: []byte("x")}); err == nil || !strings.Contains(err.Error(), "AccessDenied") {
+		t.Errorf("StoreMedia() error = %v, want the upload failure", err)
+	}
+}
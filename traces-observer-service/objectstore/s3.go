@@ -0,0 +1,266 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package objectstore
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// emptyPayloadHash is the SHA-256 of an empty request body
+const emptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+// S3Config holds the location and credentials of an S3-compatible bucket
+type S3Config struct {
+	Endpoint        string // e.g. https://s3.us-east-1.amazonaws.com or http://minio:9000, derived from the region when empty
+	Region          string
+	Bucket          string
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string // Optional, for temporary credentials
+	PathStyle       bool   // Address the bucket in the path instead of the host name, required by most S3-compatible stores
+	RequestTimeout  time.Duration
+}
+
+// S3Client reads and writes objects of a bucket with Signature Version 4 signed requests
+type S3Client struct {
+	cfg        S3Config
+	endpoint   *url.URL
+	httpClient *http.Client
+}
+
+// ObjectInfo describes a stored object
+type ObjectInfo struct {
+	ETag string
+	Size int64
+}
+
+// NewS3Client creates a client for the configured bucket
+func NewS3Client(cfg S3Config) (*S3Client, error) {
+	if cfg.Bucket == "" {
+		return nil, fmt.Errorf("bucket is required")
+	}
+	if cfg.Region == "" {
+		cfg.Region = "us-east-1"
+	}
+	if cfg.Endpoint == "" {
+		cfg.Endpoint = "https://s3." + cfg.Region + ".amazonaws.com"
+	}
+	endpoint, err := url.Parse(cfg.Endpoint)
+	if err != nil || endpoint.Host == "" {
+		return nil, fmt.Errorf("invalid endpoint: %s", cfg.Endpoint)
+	}
+	if cfg.RequestTimeout <= 0 {
+		cfg.RequestTimeout = 5 * time.Minute
+	}
+	return &S3Client{
+		cfg:        cfg,
+		endpoint:   endpoint,
+		httpClient: &http.Client{Timeout: cfg.RequestTimeout},
+	}, nil
+}
+
+// Bucket returns the name of the bucket
+func (c *S3Client) Bucket() string {
+	return c.cfg.Bucket
+}
+
+// PutObject uploads an object
+// The MD5 digest is sent as Content-MD5 and the SHA-256 digest is signed, so S3 rejects a body corrupted in transit
+func (c *S3Client) PutObject(ctx context.Context, key, contentType string, body io.ReadSeeker, size int64, md5Sum, sha256Sum []byte) error {
+	if _, err := body.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, c.objectURL(key), io.NopCloser(body))
+	if err != nil {
+		return fmt.Errorf("failed to build put request: %w", err)
+	}
+	req.ContentLength = size
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("Content-MD5", base64.StdEncoding.EncodeToString(md5Sum))
+	c.sign(req, hex.EncodeToString(sha256Sum), time.Now())
+
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("put object %s failed: %w", key, err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("put object %s failed with status %s: %s", key, res.Status, readError(res.Body))
+	}
+	return nil
+}
+
+// HeadObject returns the ETag and size of an object, nil when it does not exist
+func (c *S3Client) HeadObject(ctx context.Context, key string) (*ObjectInfo, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, c.objectURL(key), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build head request: %w", err)
+	}
+	c.sign(req, emptyPayloadHash, time.Now())
+
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("head object %s failed: %w", key, err)
+	}
+	res.Body.Close()
+	if res.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("head object %s failed with status: %s", key, res.Status)
+	}
+	return &ObjectInfo{
+		ETag: strings.Trim(res.Header.Get("ETag"), `"`),
+		Size: res.ContentLength,
+	}, nil
+}
+
+// GetObject opens an object for reading, the caller closes the returned body
+func (c *S3Client) GetObject(ctx context.Context, key string) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.objectURL(key), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build get request: %w", err)
+	}
+	c.sign(req, emptyPayloadHash, time.Now())
+
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("get object %s failed: %w", key, err)
+	}
+	if res.StatusCode != http.StatusOK {
+		defer res.Body.Close()
+		return nil, fmt.Errorf("get object %s failed with status %s: %s", key, res.Status, readError(res.Body))
+	}
+	return res.Body, nil
+}
+
+// objectURL returns the URL of an object in path or virtual-hosted style
+func (c *S3Client) objectURL(key string) string {
+	u := *c.endpoint
+	escapedKey := escapePath(key)
+	if c.cfg.PathStyle {
+		base := strings.TrimRight(c.endpoint.Path, "/") + "/" + c.cfg.Bucket + "/"
+		u.Path = base + key
+		u.RawPath = base + escapedKey
+	} else {
+		u.Host = c.cfg.Bucket + "." + u.Host
+		u.Path = "/" + key
+		u.RawPath = "/" + escapedKey
+	}
+	return u.String()
+}
+
+// sign adds the Signature Version 4 authorization of a request
+func (c *S3Client) sign(req *http.Request, payloadHash string, now time.Time) {
+	now = now.UTC()
+	amzDate := now.Format("20060102T150405Z")
+	day := now.Format("20060102")
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	if c.cfg.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", c.cfg.SessionToken)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		lower := strings.ToLower(name)
+		if lower == "content-md5" || lower == "content-type" || strings.HasPrefix(lower, "x-amz-") {
+			headers[lower] = strings.TrimSpace(strings.Join(values, ","))
+		}
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := day + "/" + c.cfg.Region + "/s3/aws4_request"
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		hexSHA256([]byte(canonicalRequest)),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+c.cfg.SecretAccessKey), day)
+	key = hmacSHA256(key, c.cfg.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		c.cfg.AccessKeyID, scope, signedHeaders, signature))
+}
+
+// escapePath URI-encodes an object key as required by Signature Version 4, keeping only unreserved characters and slashes
+func escapePath(key string) string {
+	var escaped strings.Builder
+	for _, b := range []byte(key) {
+		switch {
+		case 'A' <= b && b <= 'Z', 'a' <= b && b <= 'z', '0' <= b && b <= '9',
+			b == '-', b == '_', b == '.', b == '~', b == '/':
+			escaped.WriteByte(b)
+		default:
+			fmt.Fprintf(&escaped, "%%%02X", b)
+		}
+	}
+	return escaped.String()
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+func hexSHA256(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// readError reads the start of an error response body for logging
+func readError(body io.Reader) string {
+	content, _ := io.ReadAll(io.LimitReader(body, 1024))
+	return strings.TrimSpace(string(content))
+}
@@ -0,0 +1,101 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package opensearch
+
+import (
+	"sort"
+	"strings"
+)
+
+// AgentLabelsField holds the labels of the managed agent that produced a span as key=value keywords
+const AgentLabelsField = "agentLabels"
+
+// AgentIDField holds the id of the managed agent a span is linked to, null when it is linked to none
+const AgentIDField = "agentId"
+
+// AgentResolutionField records how a span was linked to a managed agent, or why it was not
+const AgentResolutionField = "agentResolution"
+
+// Agent resolutions recorded in AgentResolutionField
+const (
+	AgentResolutionAttribute  = "attribute"  // The agent id resource attribute names an agent of the span's organization
+	AgentResolutionName       = "name"       // The agent name of the span matches exactly one agent of the organization
+	AgentResolutionUnmanaged  = "unmanaged"  // No managed agent matches the span
+	AgentResolutionAmbiguous  = "ambiguous"  // Several managed agents match the agent name of the span
+	AgentResolutionUnresolved = "unresolved" // The agent manager could not be asked
+)
+
+// MetricsGroupByLabelPrefix groups metrics by the value of an agent label, e.g. label:team
+const MetricsGroupByLabelPrefix = "label:"
+
+// AgentLabelTerms converts agent labels into the sorted key=value keywords indexed in AgentLabelsField
+func AgentLabelTerms(labels map[string]string) []string {
+	terms := make([]string, 0, len(labels))
+	for key, value := range labels {
+		terms = append(terms, key+"="+value)
+	}
+	sort.Strings(terms)
+	return terms
+}
+
+// metricsGroupByLabel returns the label key of a label:<key> group-by dimension
+func metricsGroupByLabel(groupBy string) (string, bool) {
+	key, ok := strings.CutPrefix(groupBy, MetricsGroupByLabelPrefix)
+	if !ok || key == "" || strings.ContainsAny(key, "=, \t") {
+		return "", false
+	}
+	return key, true
+}
+
+// labelValuePattern matches the keywords of a label key, every character but letters and digits is escaped
+// since the include pattern of a terms aggregation is a Lucene regular expression
+func labelValuePattern(key string) string {
+	var b strings.Builder
+	for _, r := range key {
+		if (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') && (r < '0' || r > '9') {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	b.WriteString("=.*")
+	return b.String()
+}
+
+// buildLabelGroupAggs groups the timeline by the values of a label, spans of agents without the label are
+// aggregated separately into the unknown group
+func buildLabelGroupAggs(key string, timeline map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"groups": map[string]interface{}{
+			"terms": map[string]interface{}{
+				"field":   AgentLabelsField,
+				"include": labelValuePattern(key),
+				"size":    MaxMetricsGroups,
+			},
+			"aggs": map[string]interface{}{"timeline": timeline},
+		},
+		"ungrouped": map[string]interface{}{
+			"filter": map[string]interface{}{
+				"bool": map[string]interface{}{
+					"must_not": map[string]interface{}{
+						"prefix": map[string]interface{}{AgentLabelsField: key + "="},
+					},
+				},
+			},
+			"aggs": map[string]interface{}{"timeline": timeline},
+		},
+	}
+}
@@ -0,0 +1,156 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package opensearch
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAgentLabelTerms(t *testing.T) {
+	got := AgentLabelTerms(map[string]string{"team": "billing", "env": "prod", "example.com/tier": "gold"})
+	want := []string{"env=prod", "example.com/tier=gold", "team=billing"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("AgentLabelTerms() = %v, want %v", got, want)
+	}
+}
+
+func TestIsValidMetricsGroupByLabel(t *testing.T) {
+	tests := map[string]bool{
+		"model":                  true,
+		"label:team":             true,
+		"label:example.com/tier": true,
+		"label:":                 false,
+		"label:team=billing":     false,
+		"label:team,env":         false,
+		"team":                   false,
+	}
+	for groupBy, want := range tests {
+		if got := IsValidMetricsGroupBy(groupBy); got != want {
+			t.Errorf("IsValidMetricsGroupBy(%q) = %v, want %v", groupBy, got, want)
+		}
+	}
+}
+
+func TestBuildTraceMetricsQueryGroupsByLabel(t *testing.T) {
+	params := TraceMetricsParams{
+		StartTime: time.Date(2025, 11, 3, 0, 0, 0, 0, time.UTC),
+		EndTime:   time.Date(2025, 11, 3, 1, 59, 59, 0, time.UTC),
+		Interval:  "1h",
+		GroupBy:   "label:example.com/tier",
+	}
+	aggs := BuildTraceMetricsQuery(params)["aggs"].(map[string]interface{})
+
+	terms := aggs["groups"].(map[string]interface{})["terms"].(map[string]interface{})
+	if terms["field"] != AgentLabelsField || terms["include"] != `example\.com\/tier=.*` {
+		t.Errorf("groups terms = %v", terms)
+	}
+	if _, ok := terms["missing"]; ok {
+		t.Error("label groups must not report missing labels as a term")
+	}
+	encoded, _ := json.Marshal(aggs["ungrouped"].(map[string]interface{})["filter"])
+	if want := `{"bool":{"must_not":{"prefix":{"agentLabels":"example.com/tier="}}}}`; string(encoded) != want {
+		t.Errorf("ungrouped filter = %s, want %s", encoded, want)
+	}
+}
+
+func TestParseTraceMetricsGroupsByLabel(t *testing.T) {
+	params := TraceMetricsParams{
+		StartTime: time.Date(2025, 11, 3, 0, 0, 0, 0, time.UTC),
+		EndTime:   time.Date(2025, 11, 3, 1, 59, 59, 0, time.UTC),
+		Interval:  "1h",
+		GroupBy:   "label:team",
+	}
+	timeline := `{"buckets":[{"key":1762128000000,"traces":{"doc_count":2}}]}`
+	response := &SearchResponse{Aggregations: map[string]json.RawMessage{
+		"groups": json.RawMessage(`{"buckets":[{"key":"team=search","timeline":` + timeline + `},` +
+			`{"key":"team=billing","timeline":` + timeline + `}]}`),
+		"ungrouped": json.RawMessage(`{"doc_count":3,"timeline":` + timeline + `}`),
+	}}
+
+	result, err := ParseTraceMetrics(response, params, nil)
+	if err != nil {
+		t.Fatalf("ParseTraceMetrics() error = %v", err)
+	}
+	var groups []string
+	for _, series := range result.Series {
+		groups = append(groups, series.Group)
+		if len(series.Points) != 2 || series.Points[0].TraceCount != 2 {
+			t.Errorf("group %s points = %+v", series.Group, series.Points)
+		}
+	}
+	if want := []string{"billing", "search", "unknown"}; !reflect.DeepEqual(groups, want) {
+		t.Errorf("groups = %v, want %v", groups, want)
+	}
+
+	// Every span carries the label, so there is no unknown group
+	response.Aggregations["ungrouped"] = json.RawMessage(`{"doc_count":0}`)
+	result, err = ParseTraceMetrics(response, params, nil)
+	if err != nil {
+		t.Fatalf("ParseTraceMetrics() error = %v", err)
+	}
+	if len(result.Series) != 2 {
+		t.Errorf("got %d series, want 2", len(result.Series))
+	}
+}
+
+func TestTraceMetricsOfAgentWithSummary(t *testing.T) {
+	params := TraceMetricsParams{
+		StartTime: time.Date(2025, 11, 3, 0, 0, 0, 0, time.UTC),
+		EndTime:   time.Date(2025, 11, 3, 1, 59, 59, 0, time.UTC),
+		Interval:  "1h",
+		AgentID:   "agent-a",
+		TraceIDs:  []string{"trace-1", "trace-2"},
+		Summary:   true,
+	}
+	query := BuildTraceMetricsQuery(params)
+
+	encoded, _ := json.Marshal(query["query"])
+	if want := `{"terms":{"traceId":["trace-1","trace-2"]}}`; !strings.Contains(string(encoded), want) {
+		t.Errorf("query = %s, want a %s filter", encoded, want)
+	}
+	if _, ok := query["aggs"].(map[string]interface{})["summary"]; !ok {
+		t.Error("summary aggregation missing")
+	}
+
+	response := &SearchResponse{Aggregations: map[string]json.RawMessage{
+		"timeline": json.RawMessage(`{"buckets":[{"key":1762128000000,"traces":{"doc_count":2},"errors":{"traces":{"value":1}}}]}`),
+		"summary":  json.RawMessage(`{"traces":{"doc_count":3},"errors":{"traces":{"value":1}}}`),
+	}}
+	result, err := ParseTraceMetrics(response, params, nil)
+	if err != nil {
+		t.Fatalf("ParseTraceMetrics() error = %v", err)
+	}
+	if result.Summary == nil || result.Summary.TraceCount != 3 || result.Summary.ErrorCount != 1 || !result.Summary.Timestamp.Equal(params.StartTime) {
+		t.Errorf("summary = %+v", result.Summary)
+	}
+	if points := result.Series[0].Points; len(points) != 2 || points[0].TraceCount != 2 || points[1].TraceCount != 0 {
+		t.Errorf("points = %+v", points)
+	}
+
+	// An agent without traces still reports an empty summary
+	result, err = ParseTraceMetrics(&SearchResponse{}, params, nil)
+	if err != nil {
+		t.Fatalf("ParseTraceMetrics() error = %v", err)
+	}
+	if result.Summary == nil || result.Summary.TraceCount != 0 {
+		t.Errorf("summary without traces = %+v", result.Summary)
+	}
+}
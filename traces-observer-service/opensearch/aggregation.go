@@ -0,0 +1,172 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package opensearch
+
+import (
+	"sort"
+
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/pricing"
+)
+
+// AggregateTraceTokenUsage rolls up token usage of every LLM span in a trace
+// The span tree is walked from the root spans so each LLM call is counted exactly once,
+// independently of any framework-level usage attributes on agent or crew spans
+func AggregateTraceTokenUsage(spans []Span) *TraceTokenUsage {
+	if len(spans) == 0 {
+		return nil
+	}
+
+	// Build the parent/child index
+	spanIDs := make(map[string]bool, len(spans))
+	for _, span := range spans {
+		spanIDs[span.SpanID] = true
+	}
+	children := make(map[string][]int, len(spans))
+	var roots []int
+	for i, span := range spans {
+		// Spans whose parent was not fetched are treated as roots
+		if span.ParentSpanID == "" || !spanIDs[span.ParentSpanID] {
+			roots = append(roots, i)
+			continue
+		}
+		children[span.ParentSpanID] = append(children[span.ParentSpanID], i)
+	}
+
+	usage := &TraceTokenUsage{}
+	byModel := make(map[string]*ModelTokenUsage)
+	visited := make(map[int]bool, len(spans))
+
+	stack := roots
+	for len(stack) > 0 {
+		idx := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if visited[idx] {
+			continue
+		}
+		visited[idx] = true
+
+		span := spans[idx]
+		stack = append(stack, children[span.SpanID]...)
+
+		llmData, ok := llmDataOf(span)
+		if !ok || llmData.TokenUsage == nil {
+			continue
+		}
+
+		model := llmData.Model
+		if model == "" {
+			model = "unknown"
+		}
+		modelUsage, ok := byModel[model]
+		if !ok {
+			modelUsage = &ModelTokenUsage{Model: model}
+			byModel[model] = modelUsage
+		}
+
+		addLLMTokenUsage(&usage.LLMTokenUsage, llmData.TokenUsage)
+		addLLMTokenUsage(&modelUsage.LLMTokenUsage, llmData.TokenUsage)
+	}
+
+	if len(byModel) == 0 {
+		return nil
+	}
+
+	// Sort models by name for a stable response
+	usage.Models = make([]ModelTokenUsage, 0, len(byModel))
+	for _, modelUsage := range byModel {
+		usage.Models = append(usage.Models, *modelUsage)
+	}
+	sort.Slice(usage.Models, func(i, j int) bool {
+		return usage.Models[i].Model < usage.Models[j].Model
+	})
+
+	return usage
+}
+
+// llmDataOf returns the LLM data of a span if it is an LLM span
+func llmDataOf(span Span) (LLMData, bool) {
+	if span.AmpAttributes == nil || span.AmpAttributes.Kind != string(SpanTypeLLM) {
+		return LLMData{}, false
+	}
+	llmData, ok := span.AmpAttributes.Data.(LLMData)
+	return llmData, ok
+}
+
+// addLLMTokenUsage adds the token counts of src to dst
+func addLLMTokenUsage(dst *LLMTokenUsage, src *LLMTokenUsage) {
+	dst.InputTokens += src.InputTokens
+	dst.OutputTokens += src.OutputTokens
+	dst.CacheReadInputTokens += src.CacheReadInputTokens
+	dst.CacheWriteInputTokens += src.CacheWriteInputTokens
+	dst.ReasoningTokens += src.ReasoningTokens
+	dst.TotalTokens += src.TotalTokens
+}
+
+// ApplyPricing computes the cost of each model's usage and the trace total
+// Unpriced models keep a nil cost, and the total only covers priced models
+func (u *TraceTokenUsage) ApplyPricing(table *pricing.Table) {
+	if u == nil || table == nil {
+		return
+	}
+
+	var total *pricing.Cost
+	for i := range u.Models {
+		model := &u.Models[i]
+		model.Cost = table.Calculate(model.Model, pricing.Usage{
+			InputTokens:           model.InputTokens,
+			OutputTokens:          model.OutputTokens,
+			CacheReadInputTokens:  model.CacheReadInputTokens,
+			CacheWriteInputTokens: model.CacheWriteInputTokens,
+		})
+		if model.Cost == nil {
+			continue
+		}
+		if total == nil {
+			total = &pricing.Cost{}
+		}
+		total.InputCost += model.Cost.InputCost
+		total.OutputCost += model.Cost.OutputCost
+		total.TotalCost += model.Cost.TotalCost
+	}
+	u.Cost = total
+}
+
+// LightweightSpan keeps the fields needed to evaluate and aggregate a trace, dropping inputs, outputs and attributes
+func LightweightSpan(span Span) Span {
+	light := Span{
+		TraceID:         span.TraceID,
+		SpanID:          span.SpanID,
+		ParentSpanID:    span.ParentSpanID,
+		Name:            span.Name,
+		Service:         span.Service,
+		StartTime:       span.StartTime,
+		EndTime:         span.EndTime,
+		DurationInNanos: span.DurationInNanos,
+		Resource:        span.Resource,
+	}
+	if span.AmpAttributes != nil {
+		light.AmpAttributes = &AmpAttributes{
+			Kind:   span.AmpAttributes.Kind,
+			Status: span.AmpAttributes.Status,
+			Error:  span.AmpAttributes.Error,
+		}
+		if llmData, ok := span.AmpAttributes.Data.(LLMData); ok {
+			light.AmpAttributes.Data = LLMData{Model: llmData.Model, TokenUsage: llmData.TokenUsage}
+		}
+	}
+	return light
+}
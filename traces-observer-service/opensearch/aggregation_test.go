@@ -0,0 +1,191 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package opensearch
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/pricing"
+)
+
+// usageSpan creates a span of a kind, LLM spans carry the model and token counts
+func usageSpan(id, parent string, kind SpanType, model string, input, output int) Span {
+	span := Span{SpanID: id, ParentSpanID: parent, AmpAttributes: &AmpAttributes{Kind: string(kind)}}
+	if kind == SpanTypeLLM {
+		span.AmpAttributes.Data = LLMData{
+			Model:      model,
+			TokenUsage: &LLMTokenUsage{InputTokens: input, OutputTokens: output, TotalTokens: input + output},
+		}
+	}
+	return span
+}
+
+// modelUsage summarizes the per-model usage as model to total tokens
+func modelUsage(usage *TraceTokenUsage) map[string]int {
+	if usage == nil {
+		return nil
+	}
+	totals := make(map[string]int, len(usage.Models))
+	for _, model := range usage.Models {
+		totals[model.Model] = model.TotalTokens
+	}
+	return totals
+}
+
+func TestAggregateTraceTokenUsage(t *testing.T) {
+	tests := []struct {
+		name       string
+		spans      []Span
+		wantTotal  int
+		wantModels map[string]int
+	}{
+		{
+			name: "nested LLM spans are counted once",
+			spans: []Span{
+				usageSpan("root", "", SpanTypeAgent, "", 0, 0),
+				usageSpan("llm-1", "root", SpanTypeLLM, "gpt-4o", 100, 20),
+				usageSpan("chain", "root", SpanTypeChain, "", 0, 0),
+				usageSpan("llm-2", "chain", SpanTypeLLM, "gpt-4o", 50, 10),
+				usageSpan("llm-3", "chain", SpanTypeLLM, "claude-3-5-sonnet", 30, 5),
+			},
+			wantTotal:  215,
+			wantModels: map[string]int{"gpt-4o": 180, "claude-3-5-sonnet": 35},
+		},
+		{
+			name: "usage on agent spans is ignored",
+			spans: []Span{
+				{SpanID: "root", AmpAttributes: &AmpAttributes{Kind: string(SpanTypeAgent), Data: LLMData{Model: "gpt-4o", TokenUsage: &LLMTokenUsage{TotalTokens: 999}}}},
+				usageSpan("llm", "root", SpanTypeLLM, "gpt-4o", 10, 5),
+			},
+			wantTotal:  15,
+			wantModels: map[string]int{"gpt-4o": 15},
+		},
+		{
+			name: "spans with a missing parent are roots",
+			spans: []Span{
+				usageSpan("root", "", SpanTypeAgent, "", 0, 0),
+				usageSpan("orphan", "missing", SpanTypeLLM, "gpt-4o", 40, 2),
+			},
+			wantTotal:  42,
+			wantModels: map[string]int{"gpt-4o": 42},
+		},
+		{
+			name: "LLM spans without a model",
+			spans: []Span{
+				usageSpan("llm", "", SpanTypeLLM, "", 7, 3),
+			},
+			wantTotal:  10,
+			wantModels: map[string]int{"unknown": 10},
+		},
+		{
+			name: "parent cycles do not loop",
+			spans: []Span{
+				usageSpan("root", "", SpanTypeAgent, "", 0, 0),
+				usageSpan("a", "b", SpanTypeLLM, "gpt-4o", 1, 1),
+				usageSpan("b", "a", SpanTypeLLM, "gpt-4o", 1, 1),
+			},
+		},
+		{
+			name: "no LLM usage",
+			spans: []Span{
+				usageSpan("root", "", SpanTypeAgent, "", 0, 0),
+				{SpanID: "llm", ParentSpanID: "root", AmpAttributes: &AmpAttributes{Kind: string(SpanTypeLLM), Data: LLMData{Model: "gpt-4o"}}},
+			},
+		},
+		{
+			name: "no spans",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			usage := AggregateTraceTokenUsage(tt.spans)
+			if tt.wantModels == nil {
+				if usage != nil {
+					t.Fatalf("AggregateTraceTokenUsage() = %+v, want nil", usage)
+				}
+				return
+			}
+			if usage == nil {
+				t.Fatal("AggregateTraceTokenUsage() = nil")
+			}
+			if usage.TotalTokens != tt.wantTotal {
+				t.Errorf("total tokens = %d, want %d", usage.TotalTokens, tt.wantTotal)
+			}
+			if got := modelUsage(usage); !reflect.DeepEqual(got, tt.wantModels) {
+				t.Errorf("model usage = %v, want %v", got, tt.wantModels)
+			}
+		})
+	}
+}
+
+func TestAggregateTraceTokenUsageSortsModels(t *testing.T) {
+	usage := AggregateTraceTokenUsage([]Span{
+		usageSpan("a", "", SpanTypeLLM, "o3", 1, 1),
+		usageSpan("b", "", SpanTypeLLM, "claude-3-5-sonnet", 1, 1),
+		usageSpan("c", "", SpanTypeLLM, "gpt-4o", 1, 1),
+	})
+	var models []string
+	for _, model := range usage.Models {
+		models = append(models, model.Model)
+	}
+	if want := []string{"claude-3-5-sonnet", "gpt-4o", "o3"}; !reflect.DeepEqual(models, want) {
+		t.Errorf("models = %v, want %v", models, want)
+	}
+}
+
+func TestApplyPricing(t *testing.T) {
+	table := pricing.NewTable(map[string]pricing.ModelPrice{
+		"priced-a": {InputPerMillion: 1, OutputPerMillion: 2},
+		"priced-b": {InputPerMillion: 10, OutputPerMillion: 20},
+	})
+	usage := AggregateTraceTokenUsage([]Span{
+		usageSpan("a", "", SpanTypeLLM, "priced-a", 1_000_000, 1_000_000),
+		usageSpan("b", "", SpanTypeLLM, "priced-b", 100_000, 100_000),
+		usageSpan("c", "", SpanTypeLLM, "unpriced", 5_000_000, 5_000_000),
+	})
+	usage.ApplyPricing(table)
+
+	wantCosts := map[string]float64{"priced-a": 3, "priced-b": 3}
+	for _, model := range usage.Models {
+		want, priced := wantCosts[model.Model]
+		switch {
+		case !priced && model.Cost != nil:
+			t.Errorf("%s cost = %+v, want nil", model.Model, model.Cost)
+		case priced && model.Cost == nil:
+			t.Errorf("%s cost = nil, want %v", model.Model, want)
+		case priced && math.Abs(model.Cost.TotalCost-want) > 1e-9:
+			t.Errorf("%s cost = %v, want %v", model.Model, model.Cost.TotalCost, want)
+		}
+	}
+	if usage.Cost == nil || math.Abs(usage.Cost.TotalCost-6) > 1e-9 {
+		t.Errorf("trace cost = %+v, want a total of 6", usage.Cost)
+	}
+
+	unpriced := AggregateTraceTokenUsage([]Span{usageSpan("c", "", SpanTypeLLM, "unpriced", 10, 10)})
+	unpriced.ApplyPricing(table)
+	if unpriced.Cost != nil {
+		t.Errorf("trace cost without priced models = %+v, want nil", unpriced.Cost)
+	}
+
+	// Nil usage and nil tables are no-ops
+	var none *TraceTokenUsage
+	none.ApplyPricing(table)
+	usage.ApplyPricing(nil)
+}
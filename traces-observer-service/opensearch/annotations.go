@@ -0,0 +1,223 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package opensearch
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// AnnotationIndex is the index holding human annotations of traces
+// Every annotation is a separate document so that concurrent annotations of a trace never overwrite each other
+const AnnotationIndex = "amp-trace-annotations"
+
+// Common annotation labels
+const (
+	AnnotationLabelThumbsUp   = "thumbs_up"
+	AnnotationLabelThumbsDown = "thumbs_down"
+)
+
+// Annotation is a human label of an agent run
+type Annotation struct {
+	ID        string    `json:"annotationId"`
+	TraceID   string    `json:"traceId"`
+	Author    string    `json:"author"`
+	Timestamp time.Time `json:"timestamp"`
+	Score     *int      `json:"score,omitempty"` // 1 to 5
+	Label     string    `json:"label,omitempty"` // e.g. thumbs_up, thumbs_down
+	Comment   string    `json:"comment,omitempty"`
+	OrgID     string    `json:"orgId,omitempty"` // Organization of the annotated trace
+}
+
+// AnnotationSummary summarises the annotations of a trace
+type AnnotationSummary struct {
+	Count        int            `json:"count"`
+	AverageScore *float64       `json:"averageScore,omitempty"` // Average of the annotations with a score
+	Labels       map[string]int `json:"labels,omitempty"`       // Number of annotations per label
+}
+
+// TraceAnnotationsResponse represents the annotations of a trace
+type TraceAnnotationsResponse struct {
+	TraceID     string             `json:"traceId"`
+	Annotations []Annotation       `json:"annotations"` // Annotations in creation order
+	Summary     *AnnotationSummary `json:"summary,omitempty"`
+}
+
+// maxAnnotationsPerTrace bounds the number of annotations returned for a trace
+const maxAnnotationsPerTrace = 1000
+
+// buildAnnotationIndexBody builds the settings and mappings of the annotation index
+func buildAnnotationIndexBody() map[string]interface{} {
+	return map[string]interface{}{
+		"mappings": map[string]interface{}{
+			"properties": map[string]interface{}{
+				"annotationId": map[string]interface{}{"type": "keyword"},
+				"traceId":      map[string]interface{}{"type": "keyword"},
+				"author":       map[string]interface{}{"type": "keyword"},
+				"timestamp":    map[string]interface{}{"type": "date"},
+				"score":        map[string]interface{}{"type": "integer"},
+				"label":        map[string]interface{}{"type": "keyword"},
+				"comment":      map[string]interface{}{"type": "text"},
+				OrgIDField:     map[string]interface{}{"type": "keyword"},
+			},
+		},
+	}
+}
+
+// EnsureAnnotationIndex creates the annotation index when it does not exist yet
+func (c *Client) EnsureAnnotationIndex(ctx context.Context) error {
+	return c.EnsureIndex(ctx, AnnotationIndex, buildAnnotationIndexBody())
+}
+
+// BuildTraceAnnotationsQuery builds a query for the annotations of a trace in creation order
+func BuildTraceAnnotationsQuery(traceID string) map[string]interface{} {
+	return map[string]interface{}{
+		"query": map[string]interface{}{
+			"term": map[string]interface{}{"traceId": traceID},
+		},
+		"size": maxAnnotationsPerTrace,
+		"sort": []map[string]interface{}{
+			{"timestamp": map[string]string{"order": "asc"}},
+			{"annotationId": map[string]string{"order": "asc"}},
+		},
+	}
+}
+
+// ParseAnnotations decodes the annotations of a search response
+func ParseAnnotations(response *SearchResponse) ([]Annotation, error) {
+	annotations := make([]Annotation, 0, len(response.Hits.Hits))
+	for _, hit := range response.Hits.Hits {
+		var annotation Annotation
+		if err := DecodeSource(hit.Source, &annotation); err != nil {
+			return nil, fmt.Errorf("failed to decode annotation: %w", err)
+		}
+		annotations = append(annotations, annotation)
+	}
+	return annotations, nil
+}
+
+// SummarizeAnnotations computes the summary of a trace's annotations, nil when there are none
+func SummarizeAnnotations(annotations []Annotation) *AnnotationSummary {
+	if len(annotations) == 0 {
+		return nil
+	}
+
+	summary := &AnnotationSummary{Count: len(annotations)}
+	var scoreTotal, scored int
+	for _, annotation := range annotations {
+		if annotation.Score != nil {
+			scoreTotal += *annotation.Score
+			scored++
+		}
+		if annotation.Label != "" {
+			if summary.Labels == nil {
+				summary.Labels = map[string]int{}
+			}
+			summary.Labels[annotation.Label]++
+		}
+	}
+	if scored > 0 {
+		average := float64(scoreTotal) / float64(scored)
+		summary.AverageScore = &average
+	}
+	return summary
+}
+
+// BuildAnnotationSummaryQuery builds an aggregation of annotation counts, average scores and label counts per trace
+func BuildAnnotationSummaryQuery(traceIDs []string) map[string]interface{} {
+	return map[string]interface{}{
+		"query": map[string]interface{}{
+			"terms": map[string]interface{}{"traceId": traceIDs},
+		},
+		"size": 0,
+		"aggs": map[string]interface{}{
+			"traces": map[string]interface{}{
+				"terms": map[string]interface{}{
+					"field": "traceId",
+					"size":  len(traceIDs),
+				},
+				"aggs": map[string]interface{}{
+					"score": map[string]interface{}{
+						"avg": map[string]interface{}{"field": "score"},
+					},
+					"labels": map[string]interface{}{
+						"terms": map[string]interface{}{"field": "label", "size": 20},
+					},
+				},
+			},
+		},
+	}
+}
+
+// ParseAnnotationSummaries decodes an annotation summary aggregation into summaries keyed by trace ID
+func ParseAnnotationSummaries(response *SearchResponse) (map[string]*AnnotationSummary, error) {
+	summaries := map[string]*AnnotationSummary{}
+
+	raw, ok := response.Aggregations["traces"]
+	if !ok {
+		return summaries, nil
+	}
+
+	var traces struct {
+		Buckets []struct {
+			Key      string `json:"key"`
+			DocCount int    `json:"doc_count"`
+			Score    struct {
+				Value *float64 `json:"value"`
+			} `json:"score"`
+			Labels TermsAggregation `json:"labels"`
+		} `json:"buckets"`
+	}
+	if err := json.Unmarshal(raw, &traces); err != nil {
+		return nil, fmt.Errorf("failed to decode annotation summaries: %w", err)
+	}
+
+	for _, bucket := range traces.Buckets {
+		summary := &AnnotationSummary{
+			Count:        bucket.DocCount,
+			AverageScore: bucket.Score.Value,
+		}
+		for _, label := range bucket.Labels.Buckets {
+			if summary.Labels == nil {
+				summary.Labels = map[string]int{}
+			}
+			summary.Labels[fmt.Sprintf("%v", label.Key)] = label.DocCount
+		}
+		summaries[bucket.Key] = summary
+	}
+	return summaries, nil
+}
+
+// BuildTraceIDsByAnnotationLabelQuery builds an aggregation query collecting the traces annotated with a label
+func BuildTraceIDsByAnnotationLabelQuery(label string) map[string]interface{} {
+	return map[string]interface{}{
+		"query": map[string]interface{}{
+			"term": map[string]interface{}{"label": label},
+		},
+		"size": 0,
+		"aggs": map[string]interface{}{
+			"traces": map[string]interface{}{
+				"terms": map[string]interface{}{
+					"field": "traceId",
+					"size":  MaxTraceIDsPerLookup,
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,75 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package opensearch
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestSummarizeAnnotations(t *testing.T) {
+	if got := SummarizeAnnotations(nil); got != nil {
+		t.Errorf("SummarizeAnnotations(nil) = %+v, want nil", got)
+	}
+
+	two, four := 2, 4
+	summary := SummarizeAnnotations([]Annotation{
+		{Score: &two, Label: AnnotationLabelThumbsDown},
+		{Score: &four, Label: AnnotationLabelThumbsDown},
+		{Label: AnnotationLabelThumbsUp},
+		{Comment: "no score or label"},
+	})
+	if summary.Count != 4 || summary.AverageScore == nil || *summary.AverageScore != 3 {
+		t.Errorf("summary = %+v, want 4 annotations averaging the 2 scores", summary)
+	}
+	if want := map[string]int{AnnotationLabelThumbsDown: 2, AnnotationLabelThumbsUp: 1}; !reflect.DeepEqual(summary.Labels, want) {
+		t.Errorf("labels = %v, want %v", summary.Labels, want)
+	}
+
+	// Comments alone have neither an average nor labels
+	summary = SummarizeAnnotations([]Annotation{{Comment: "looks fine"}})
+	if summary.Count != 1 || summary.AverageScore != nil || summary.Labels != nil {
+		t.Errorf("summary = %+v, want a count only", summary)
+	}
+}
+
+func TestParseAnnotationSummaries(t *testing.T) {
+	response := &SearchResponse{Aggregations: map[string]json.RawMessage{
+		"traces": json.RawMessage(`{"buckets": [
+			{"key": "trace-1", "doc_count": 3, "score": {"value": 4.5},
+				"labels": {"buckets": [{"key": "thumbs_up", "doc_count": 2}]}},
+			{"key": "trace-2", "doc_count": 1, "score": {"value": null}, "labels": {"buckets": []}}
+		]}`),
+	}}
+
+	summaries, err := ParseAnnotationSummaries(response)
+	if err != nil {
+		t.Fatalf("ParseAnnotationSummaries() error = %v", err)
+	}
+	first := summaries["trace-1"]
+	if first == nil || first.Count != 3 || *first.AverageScore != 4.5 || first.Labels["thumbs_up"] != 2 {
+		t.Errorf("trace-1 = %+v", first)
+	}
+	second := summaries["trace-2"]
+	if second == nil || second.Count != 1 || second.AverageScore != nil || second.Labels != nil {
+		t.Errorf("trace-2 = %+v, want a count only", second)
+	}
+	if _, ok := summaries["trace-3"]; ok {
+		t.Error("summary of an unannotated trace")
+	}
+}
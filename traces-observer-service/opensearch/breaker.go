@@ -0,0 +1,229 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package opensearch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/metrics"
+)
+
+// ErrCircuitOpen is returned without contacting OpenSearch while the circuit breaker is open
+var ErrCircuitOpen = errors.New("opensearch circuit breaker is open")
+
+// Circuit breaker states
+const (
+	BreakerClosed   = "closed"
+	BreakerOpen     = "open"
+	BreakerHalfOpen = "half-open"
+)
+
+// CircuitBreaker stops sending requests after consecutive failures and probes for recovery once the open timeout elapses
+type CircuitBreaker struct {
+	failureThreshold int
+	openTimeout      time.Duration
+
+	mu                  sync.Mutex
+	state               string
+	consecutiveFailures int
+	openedAt            time.Time
+	probing             bool
+}
+
+// NewCircuitBreaker creates a closed circuit breaker
+// A failure threshold of zero or less disables the breaker
+func NewCircuitBreaker(failureThreshold int, openTimeout time.Duration) *CircuitBreaker {
+	return &CircuitBreaker{
+		failureThreshold: failureThreshold,
+		openTimeout:      openTimeout,
+		state:            BreakerClosed,
+	}
+}
+
+// Allow reports whether a request may be sent
+// Once the open timeout elapses a single probe request is let through in the half-open state
+func (b *CircuitBreaker) Allow() bool {
+	if b.failureThreshold <= 0 {
+		return true
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case BreakerOpen:
+		if time.Since(b.openedAt) < b.openTimeout {
+			return false
+		}
+		b.state = BreakerHalfOpen
+		b.probing = true
+		return true
+	case BreakerHalfOpen:
+		if b.probing {
+			return false
+		}
+		b.probing = true
+		return true
+	default:
+		return true
+	}
+}
+
+// Record records the outcome of a request
+func (b *CircuitBreaker) Record(success bool) {
+	if b.failureThreshold <= 0 {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+	if success {
+		b.state = BreakerClosed
+		b.consecutiveFailures = 0
+		return
+	}
+
+	b.consecutiveFailures++
+	if b.state == BreakerHalfOpen || b.consecutiveFailures >= b.failureThreshold {
+		b.state = BreakerOpen
+		b.openedAt = time.Now()
+	}
+}
+
+// Release ends a request without an outcome, such as one cancelled by its caller
+func (b *CircuitBreaker) Release() {
+	if b.failureThreshold <= 0 {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+}
+
+// State returns the current breaker state
+func (b *CircuitBreaker) State() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == BreakerOpen && time.Since(b.openedAt) >= b.openTimeout {
+		return BreakerHalfOpen
+	}
+	return b.state
+}
+
+// ClientStats holds the resilience counters of the OpenSearch client
+type ClientStats struct {
+	BreakerState string `json:"breakerState"`
+	Retries      uint64 `json:"retries"`
+	Rejected     uint64 `json:"rejected"` // Requests rejected while the breaker was open
+}
+
+// resilientTransport applies a per-request timeout and the circuit breaker to OpenSearch requests
+type resilientTransport struct {
+	next     http.RoundTripper
+	timeout  time.Duration
+	breaker  *CircuitBreaker
+	rejected atomic.Uint64
+	metrics  *metrics.Metrics
+}
+
+// RoundTrip executes a request unless the breaker is open
+// Transport errors and 429/5xx responses count as failures, requests cancelled by their caller do not
+func (t *resilientTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	callerCtx := req.Context()
+	if !t.breaker.Allow() {
+		t.rejected.Add(1)
+		return nil, ErrCircuitOpen
+	}
+
+	cancel := context.CancelFunc(func() {})
+	if t.timeout > 0 {
+		var ctx context.Context
+		ctx, cancel = context.WithTimeout(req.Context(), t.timeout)
+		req = req.WithContext(ctx)
+	}
+
+	start := time.Now()
+	res, err := t.next.RoundTrip(req)
+	if err != nil {
+		cancel()
+		if callerCtx.Err() != nil {
+			t.breaker.Release()
+		} else {
+			t.breaker.Record(false)
+		}
+		t.metrics.OpenSearchRequest(requestEndpoint(req.URL.Path), "error", time.Since(start))
+		return nil, err
+	}
+	t.breaker.Record(res.StatusCode != http.StatusTooManyRequests && res.StatusCode < 500)
+	t.metrics.OpenSearchRequest(requestEndpoint(req.URL.Path), fmt.Sprintf("%dxx", res.StatusCode/100), time.Since(start))
+
+	// The timeout context must live until the body has been read
+	res.Body = &cancelOnClose{ReadCloser: res.Body, cancel: cancel}
+	return res, nil
+}
+
+// requestEndpoint names the API of a request path by its first underscore segment (_bulk, _search, _doc, ...)
+// so that index names do not end up in metric labels
+func requestEndpoint(path string) string {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for _, segment := range segments {
+		if strings.HasPrefix(segment, "_") {
+			return segment
+		}
+	}
+	if segments[0] == "" {
+		return "root"
+	}
+	return "index"
+}
+
+// cancelOnClose releases the request context when the response body is closed
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+// Close closes the body and cancels the request context
+func (c *cancelOnClose) Close() error {
+	err := c.ReadCloser.Close()
+	c.cancel()
+	return err
+}
+
+// retryBackoff returns exponential backoff with full jitter, counting each retry
+func retryBackoff(base, max time.Duration, retries *atomic.Uint64) func(attempt int) time.Duration {
+	return func(attempt int) time.Duration {
+		retries.Add(1)
+		backoff := base << (attempt - 1)
+		if backoff <= 0 || backoff > max {
+			backoff = max
+		}
+		return time.Duration(rand.Int63n(int64(backoff) + 1))
+	}
+}
@@ -0,0 +1,338 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package opensearch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/config"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	breaker := NewCircuitBreaker(2, 20*time.Millisecond)
+
+	breaker.Record(false)
+	if !breaker.Allow() || breaker.State() != BreakerClosed {
+		t.Fatalf("state = %s after one failure, want %s", breaker.State(), BreakerClosed)
+	}
+	breaker.Record(false)
+	if breaker.Allow() || breaker.State() != BreakerOpen {
+		t.Fatalf("state = %s after two failures, want %s", breaker.State(), BreakerOpen)
+	}
+
+	// A single probe is let through once the open timeout elapses, a failed probe opens the breaker again
+	time.Sleep(25 * time.Millisecond)
+	if breaker.State() != BreakerHalfOpen {
+		t.Errorf("state = %s after the open timeout, want %s", breaker.State(), BreakerHalfOpen)
+	}
+	if !breaker.Allow() {
+		t.Fatal("Allow() = false, want the probe let through")
+	}
+	if breaker.Allow() {
+		t.Error("Allow() = true during the probe, want a single probe")
+	}
+	breaker.Record(false)
+	if breaker.State() != BreakerOpen {
+		t.Fatalf("state = %s after a failed probe, want %s", breaker.State(), BreakerOpen)
+	}
+
+	time.Sleep(25 * time.Millisecond)
+	if !breaker.Allow() {
+		t.Fatal("Allow() = false, want the probe let through")
+	}
+	breaker.Record(true)
+	if !breaker.Allow() || breaker.State() != BreakerClosed {
+		t.Errorf("state = %s after a successful probe, want %s", breaker.State(), BreakerClosed)
+	}
+}
+
+func TestCircuitBreakerRelease(t *testing.T) {
+	breaker := NewCircuitBreaker(1, time.Millisecond)
+	breaker.Record(false)
+	time.Sleep(5 * time.Millisecond)
+
+	if !breaker.Allow() {
+		t.Fatal("Allow() = false, want the probe let through")
+	}
+	// A probe cancelled by its caller makes way for another probe
+	breaker.Release()
+	if !breaker.Allow() {
+		t.Error("Allow() = false after a released probe, want another probe")
+	}
+}
+
+func TestCircuitBreakerDisabled(t *testing.T) {
+	breaker := NewCircuitBreaker(0, time.Hour)
+	for i := 0; i < 10; i++ {
+		breaker.Record(false)
+	}
+	if !breaker.Allow() || breaker.State() != BreakerClosed {
+		t.Errorf("state = %s, want a disabled breaker to stay %s", breaker.State(), BreakerClosed)
+	}
+}
+
+func TestRetryBackoff(t *testing.T) {
+	var retries atomic.Uint64
+	backoff := retryBackoff(10*time.Millisecond, 50*time.Millisecond, &retries)
+
+	for attempt, max := range map[int]time.Duration{1: 10 * time.Millisecond, 2: 20 * time.Millisecond, 3: 40 * time.Millisecond, 4: 50 * time.Millisecond, 64: 50 * time.Millisecond} {
+		for i := 0; i < 20; i++ {
+			if got := backoff(attempt); got < 0 || got > max {
+				t.Errorf("backoff(%d) = %v, want at most %v", attempt, got, max)
+			}
+		}
+	}
+	if got := retries.Load(); got != 100 {
+		t.Errorf("retries = %d, want 100", got)
+	}
+}
+
+func TestRequestEndpoint(t *testing.T) {
+	tests := map[string]string{
+		"/":                               "root",
+		"/_bulk":                          "_bulk",
+		"/otel-traces-*/_search":          "_search",
+		"/otel-traces-2025-11-03/_doc/ab": "_doc",
+		"/otel-traces-2025-11-03":         "index",
+	}
+	for path, want := range tests {
+		if got := requestEndpoint(path); got != want {
+			t.Errorf("requestEndpoint(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
+
+// newResilientClient creates a client of a scripted OpenSearch with the given retry and breaker settings
+func newResilientClient(t *testing.T, fake http.Handler, cfg config.OpenSearchConfig) *Client {
+	t.Helper()
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	cfg.Address = server.URL
+	if cfg.RequestTimeout == 0 {
+		cfg.RequestTimeout = 5 * time.Second
+	}
+	cfg.RetryBackoff = time.Millisecond
+	cfg.MaxRetryBackoff = 2 * time.Millisecond
+	client, err := NewClient(&cfg, nil)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	return client
+}
+
+func TestClientRetriesRetriableStatuses(t *testing.T) {
+	statuses := map[int]int{1: http.StatusTooManyRequests, 2: http.StatusBadGateway, 3: http.StatusServiceUnavailable}
+	fake := &scriptedBulk{
+		attempts: map[string]int{},
+		request: func(n int) int {
+			if status, ok := statuses[n]; ok {
+				return status
+			}
+			return http.StatusOK
+		},
+		item: func(string, int) int { return http.StatusCreated },
+	}
+	client := newResilientClient(t, fake, config.OpenSearchConfig{MaxRetries: 3})
+
+	if _, _, err := client.Bulk(context.Background(), bulkBody("a")); err != nil {
+		t.Fatalf("Bulk() error = %v, want success after retries", err)
+	}
+	if stats := client.Stats(); stats.Retries != 3 || stats.BreakerState != BreakerClosed {
+		t.Errorf("Stats() = %+v, want 3 retries and a closed breaker", stats)
+	}
+}
+
+func TestClientDoesNotRetryOtherStatuses(t *testing.T) {
+	fake := &scriptedBulk{
+		attempts: map[string]int{},
+		request:  func(int) int { return http.StatusInternalServerError },
+	}
+	client := newResilientClient(t, fake, config.OpenSearchConfig{MaxRetries: 3})
+
+	_, status, err := client.Bulk(context.Background(), bulkBody("a"))
+	if err == nil || status != http.StatusInternalServerError {
+		t.Fatalf("Bulk() = %d, %v, want the 500 returned", status, err)
+	}
+	if fake.requests != 1 || client.Stats().Retries != 0 {
+		t.Errorf("requests = %d, retries = %d, want a single request", fake.requests, client.Stats().Retries)
+	}
+}
+
+func TestClientOpensBreakerAfterConsecutiveFailures(t *testing.T) {
+	fake := &scriptedBulk{
+		attempts: map[string]int{},
+		request:  func(int) int { return http.StatusInternalServerError },
+	}
+	client := newResilientClient(t, fake, config.OpenSearchConfig{BreakerFailureThreshold: 2, BreakerOpenTimeout: time.Hour})
+
+	for i := 0; i < 2; i++ {
+		if _, _, err := client.Bulk(context.Background(), bulkBody("a")); err == nil {
+			t.Fatal("Bulk() error = nil, want the 500 returned")
+		}
+	}
+	if _, _, err := client.Bulk(context.Background(), bulkBody("a")); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("Bulk() error = %v, want %v", err, ErrCircuitOpen)
+	}
+	if fake.requests != 2 {
+		t.Errorf("requests = %d, want none sent while the breaker is open", fake.requests)
+	}
+	if stats := client.Stats(); stats.BreakerState != BreakerOpen || stats.Rejected != 1 {
+		t.Errorf("Stats() = %+v, want an open breaker with 1 rejected request", stats)
+	}
+}
+
+func TestClientRequestTimeout(t *testing.T) {
+	stop := make(chan struct{})
+	defer close(stop)
+	client := newResilientClient(t, hangingBulk(stop), config.OpenSearchConfig{RequestTimeout: 20 * time.Millisecond, BreakerFailureThreshold: 1, BreakerOpenTimeout: time.Hour})
+
+	start := time.Now()
+	if _, _, err := client.Bulk(context.Background(), bulkBody("a")); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Bulk() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Bulk() took %v, want it cancelled after the request timeout", elapsed)
+	}
+	if state := client.BreakerState(); state != BreakerOpen {
+		t.Errorf("breaker state = %s, want a timeout to count as a failure", state)
+	}
+}
+
+func TestClientCancelledRequestIsNotAFailure(t *testing.T) {
+	stop := make(chan struct{})
+	defer close(stop)
+	client := newResilientClient(t, hangingBulk(stop), config.OpenSearchConfig{BreakerFailureThreshold: 1, BreakerOpenTimeout: time.Hour})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, _, err := client.Bulk(ctx, bulkBody("a")); err == nil {
+		t.Fatal("Bulk() error = nil, want the cancellation returned")
+	}
+	if state := client.BreakerState(); state != BreakerClosed {
+		t.Errorf("breaker state = %s, want requests cancelled by the caller not to count", state)
+	}
+}
+
+func TestBulkIndexerSpillsWhileBreakerOpen(t *testing.T) {
+	var healthy atomic.Bool
+	fake := &scriptedBulk{
+		attempts: map[string]int{},
+		request: func(int) int {
+			if healthy.Load() {
+				return http.StatusOK
+			}
+			return http.StatusServiceUnavailable
+		},
+		item: func(string, int) int { return http.StatusCreated },
+	}
+	client := newResilientClient(t, fake, config.OpenSearchConfig{BreakerFailureThreshold: 1, BreakerOpenTimeout: 50 * time.Millisecond})
+	deadLetterPath := filepath.Join(t.TempDir(), "dead-letters.jsonl")
+	indexer, err := client.NewBulkIndexer(BulkIndexerConfig{
+		FlushInterval:  time.Hour,
+		MaxRetries:     3,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     time.Millisecond,
+		DeadLetterPath: deadLetterPath,
+		MaxSpillDocs:   2,
+	})
+	if err != nil {
+		t.Fatalf("NewBulkIndexer() error = %v", err)
+	}
+
+	done := make(chan string, 3)
+	for _, id := range []string{"a", "b", "c"} {
+		id := id
+		err := indexer.Add(context.Background(), BulkDocument{
+			Index:  "otel-traces-2026-10-16",
+			ID:     id,
+			Body:   map[string]string{"spanId": id},
+			OnDone: func(error) { done <- id },
+		})
+		if err != nil {
+			t.Fatalf("Add() error = %v", err)
+		}
+	}
+	if err := indexer.Flush(context.Background()); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+
+	// The failed request opens the breaker and the retry spills the batch, beyond the spill limit to the dead-letter log
+	select {
+	case id := <-done:
+		if id != "c" {
+			t.Errorf("document %s finished, want the one beyond the spill limit", id)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no document dead-lettered for a full spill queue")
+	}
+	if stats := indexer.Stats(); stats.Spilled != 2 {
+		t.Errorf("Stats().Spilled = %d, want 2", stats.Spilled)
+	}
+
+	// Nothing is resent while the breaker is open, the spill is resent once it probes for recovery
+	if err := indexer.drainSpill(context.Background()); err != nil {
+		t.Fatalf("drainSpill() error = %v", err)
+	}
+	if stats := indexer.Stats(); stats.Spilled != 2 {
+		t.Errorf("Stats().Spilled = %d while the breaker is open, want 2", stats.Spilled)
+	}
+	healthy.Store(true)
+	time.Sleep(60 * time.Millisecond)
+	if err := indexer.drainSpill(context.Background()); err != nil {
+		t.Fatalf("drainSpill() error = %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			t.Fatal("spilled documents not indexed after recovery")
+		}
+	}
+
+	letters := readDeadLetters(t, indexer, deadLetterPath)
+	if len(letters) != 1 || letters[0].ID != "c" {
+		t.Errorf("dead letters = %+v, want only the document beyond the spill limit", letters)
+	}
+	if fake.attemptsOf("a") != 1 || fake.attemptsOf("b") != 1 || client.BreakerState() != BreakerClosed {
+		t.Errorf("attempts = %d, %d, breaker %s, want spilled documents sent once and the breaker closed", fake.attemptsOf("a"), fake.attemptsOf("b"), client.BreakerState())
+	}
+}
+
+// hangingBulk answers bulk requests only once stop is closed
+func hangingBulk(stop <-chan struct{}) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/_bulk" {
+			<-stop
+			return
+		}
+		fmt.Fprint(w, `{"cluster_name":"test","version":{"distribution":"opensearch","number":"2.11.0"}}`)
+	})
+}
+
+// bulkBody returns a bulk request body indexing a single document
+func bulkBody(id string) []byte {
+	return []byte(fmt.Sprintf("{\"index\":{\"_index\":\"otel-traces-2026-10-16\",\"_id\":%q}}\n{\"spanId\":%q}\n", id, id))
+}
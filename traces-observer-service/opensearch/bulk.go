@@ -0,0 +1,635 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package opensearch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	commontracing "github.com/wso2/ai-agent-management-platform/libs/go-common/tracing"
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/metrics"
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/tracing"
+)
+
+// deadLetterStoreTimeout bounds the time spent writing the dead letters of a batch to the dead-letter index
+const deadLetterStoreTimeout = 10 * time.Second
+
+// BulkIndexerConfig holds the batching and retry settings of a BulkIndexer
+type BulkIndexerConfig struct {
+	MaxBatchDocs    int           // Flush when the batch holds this many documents
+	MaxBatchBytes   int           // Flush when the batch body reaches this size
+	FlushInterval   time.Duration // Flush partially filled batches after this interval
+	MaxInFlight     int           // Maximum number of concurrent bulk requests
+	MaxRetries      int           // Retries of items rejected with 429/503
+	InitialBackoff  time.Duration // Backoff before the first retry, doubled on every retry
+	MaxBackoff      time.Duration
+	DeadLetterPath  string           // File receiving permanently failed documents, logged when empty
+	DeadLetterStore *DeadLetterStore // Optional, receives permanently failed documents before the file or log
+	MaxSpillDocs    int              // Documents held in memory while the circuit breaker is open
+	Metrics         *metrics.Metrics // Optional instrumentation
+}
+
+// DefaultBulkIndexerConfig returns the default bulk indexer settings
+func DefaultBulkIndexerConfig() BulkIndexerConfig {
+	return BulkIndexerConfig{
+		MaxBatchDocs:   500,
+		MaxBatchBytes:  5 * 1024 * 1024,
+		FlushInterval:  2 * time.Second,
+		MaxInFlight:    2,
+		MaxRetries:     5,
+		InitialBackoff: 200 * time.Millisecond,
+		MaxBackoff:     10 * time.Second,
+		MaxSpillDocs:   10000,
+	}
+}
+
+// BulkDocument is a document to be indexed
+type BulkDocument struct {
+	Index  string
+	ID     string // Optional, generated by OpenSearch when empty. A document with the ID of an existing one replaces it
+	Body   interface{}
+	OnDone func(err error) // Optional, called once the document is indexed or sent to the dead-letter log
+}
+
+// BulkIndexerStats holds the document counters of a BulkIndexer
+type BulkIndexerStats struct {
+	Queued  uint64 `json:"queued"`  // Documents accepted by Add
+	Flushed uint64 `json:"flushed"` // Documents indexed successfully
+	Retried uint64 `json:"retried"` // Item retries after 429/503 rejections
+	Failed  uint64 `json:"failed"`  // Documents sent to the dead-letter index or log
+	Spilled int    `json:"spilled"` // Documents waiting for the circuit breaker to close
+}
+
+// bulkItem is an encoded document waiting to be indexed
+type bulkItem struct {
+	index  string
+	id     string
+	action []byte
+	source []byte
+	onDone func(err error)
+}
+
+// failedItem is an item that could not be indexed
+type failedItem struct {
+	item   bulkItem
+	status int
+	reason string
+}
+
+// size returns the number of bytes the item adds to a bulk body
+func (i bulkItem) size() int {
+	return len(i.action) + len(i.source) + 2
+}
+
+// BulkIndexer batches documents and writes them with the OpenSearch bulk API
+type BulkIndexer struct {
+	client *Client
+	config BulkIndexerConfig
+
+	mu         sync.Mutex
+	batch      []bulkItem
+	batchBytes int
+	closed     bool
+	spill      []bulkItem
+
+	inFlight chan struct{}
+	wg       sync.WaitGroup
+	stop     chan struct{}
+	done     chan struct{}
+
+	deadLetterMu sync.Mutex
+	deadLetter   *os.File
+
+	queued  atomic.Uint64
+	flushed atomic.Uint64
+	retried atomic.Uint64
+	failed  atomic.Uint64
+}
+
+// NewBulkIndexer creates a bulk indexer and starts its periodic flush
+func (c *Client) NewBulkIndexer(cfg BulkIndexerConfig) (*BulkIndexer, error) {
+	defaults := DefaultBulkIndexerConfig()
+	if cfg.MaxBatchDocs <= 0 {
+		cfg.MaxBatchDocs = defaults.MaxBatchDocs
+	}
+	if cfg.MaxBatchBytes <= 0 {
+		cfg.MaxBatchBytes = defaults.MaxBatchBytes
+	}
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = defaults.FlushInterval
+	}
+	if cfg.MaxInFlight <= 0 {
+		cfg.MaxInFlight = defaults.MaxInFlight
+	}
+	if cfg.MaxRetries < 0 {
+		cfg.MaxRetries = 0
+	}
+	if cfg.InitialBackoff <= 0 {
+		cfg.InitialBackoff = defaults.InitialBackoff
+	}
+	if cfg.MaxBackoff < cfg.InitialBackoff {
+		cfg.MaxBackoff = cfg.InitialBackoff
+	}
+	if cfg.MaxSpillDocs < 0 {
+		cfg.MaxSpillDocs = 0
+	}
+
+	indexer := &BulkIndexer{
+		client:   c,
+		config:   cfg,
+		inFlight: make(chan struct{}, cfg.MaxInFlight),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+
+	if cfg.DeadLetterPath != "" {
+		file, err := os.OpenFile(cfg.DeadLetterPath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open dead-letter file: %w", err)
+		}
+		indexer.deadLetter = file
+	}
+
+	cfg.Metrics.ObserveQueueDepth("bulk_batch", func() int {
+		indexer.mu.Lock()
+		defer indexer.mu.Unlock()
+		return len(indexer.batch)
+	})
+	cfg.Metrics.ObserveQueueDepth("bulk_spill", func() int {
+		indexer.mu.Lock()
+		defer indexer.mu.Unlock()
+		return len(indexer.spill)
+	})
+	cfg.Metrics.ObserveQueueDepth("bulk_in_flight", func() int {
+		return len(indexer.inFlight)
+	})
+
+	go indexer.run()
+	return indexer, nil
+}
+
+// Add queues a document for indexing
+// Blocks while MaxInFlight bulk requests are in progress and the batch is full
+func (b *BulkIndexer) Add(ctx context.Context, doc BulkDocument) error {
+	item, err := encodeBulkItem(doc)
+	if err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return fmt.Errorf("bulk indexer is closed")
+	}
+	b.batch = append(b.batch, item)
+	b.batchBytes += item.size()
+	b.queued.Add(1)
+
+	var full []bulkItem
+	if len(b.batch) >= b.config.MaxBatchDocs || b.batchBytes >= b.config.MaxBatchBytes {
+		full = b.takeBatch()
+	}
+	b.mu.Unlock()
+
+	if full != nil {
+		return b.dispatch(ctx, full)
+	}
+	return nil
+}
+
+// Flush sends the current batch without waiting for the thresholds
+func (b *BulkIndexer) Flush(ctx context.Context) error {
+	b.mu.Lock()
+	batch := b.takeBatch()
+	b.mu.Unlock()
+
+	if batch == nil {
+		return nil
+	}
+	return b.dispatch(ctx, batch)
+}
+
+// Close flushes the remaining documents and waits for in-flight bulk requests to finish
+// Spilled documents that cannot be sent because the circuit breaker is still open go to the dead-letter log
+func (b *BulkIndexer) Close(ctx context.Context) error {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return nil
+	}
+	b.closed = true
+	batch := b.takeBatch()
+	b.mu.Unlock()
+
+	close(b.stop)
+	<-b.done
+
+	if batch != nil {
+		if err := b.dispatch(ctx, batch); err != nil {
+			return err
+		}
+	}
+	if err := b.drainSpill(ctx); err != nil {
+		return err
+	}
+	b.mu.Lock()
+	spilled := b.spill
+	b.spill = nil
+	b.mu.Unlock()
+	b.deadLetterAll(spilled, 0, "not sent before shutdown: "+ErrCircuitOpen.Error())
+
+	finished := make(chan struct{})
+	go func() {
+		b.wg.Wait()
+		close(finished)
+	}()
+
+	select {
+	case <-finished:
+	case <-ctx.Done():
+		return fmt.Errorf("timed out waiting for bulk requests: %w", ctx.Err())
+	}
+
+	if b.deadLetter != nil {
+		return b.deadLetter.Close()
+	}
+	return nil
+}
+
+// Stats returns the document counters
+func (b *BulkIndexer) Stats() BulkIndexerStats {
+	b.mu.Lock()
+	spilled := len(b.spill)
+	b.mu.Unlock()
+
+	return BulkIndexerStats{
+		Queued:  b.queued.Load(),
+		Flushed: b.flushed.Load(),
+		Retried: b.retried.Load(),
+		Failed:  b.failed.Load(),
+		Spilled: spilled,
+	}
+}
+
+// run flushes partially filled batches and resends spilled documents on the flush interval
+func (b *BulkIndexer) run() {
+	defer close(b.done)
+
+	ticker := time.NewTicker(b.config.FlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := b.Flush(context.Background()); err != nil {
+				slog.Error("Periodic bulk flush failed", "error", err)
+			}
+			if err := b.drainSpill(context.Background()); err != nil {
+				slog.Error("Resending spilled documents failed", "error", err)
+			}
+		case <-b.stop:
+			return
+		}
+	}
+}
+
+// takeBatch detaches the current batch, must be called with the lock held
+// The batch is tracked as in progress until dispatch completes so that Close can wait for it
+func (b *BulkIndexer) takeBatch() []bulkItem {
+	if len(b.batch) == 0 {
+		return nil
+	}
+	batch := b.batch
+	b.batch = nil
+	b.batchBytes = 0
+	b.wg.Add(1)
+	return batch
+}
+
+// spillBatch holds a batch rejected by the open circuit breaker until OpenSearch recovers
+// Documents beyond MaxSpillDocs, and all documents once the indexer is closed, go to the dead-letter log
+func (b *BulkIndexer) spillBatch(batch []bulkItem) {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		b.deadLetterAll(batch, 0, ErrCircuitOpen.Error())
+		return
+	}
+	room := b.config.MaxSpillDocs - len(b.spill)
+	if room < 0 {
+		room = 0
+	}
+	var overflow []bulkItem
+	if len(batch) > room {
+		overflow = batch[room:]
+		batch = batch[:room]
+	}
+	b.spill = append(b.spill, batch...)
+	b.mu.Unlock()
+
+	if len(overflow) > 0 {
+		b.deadLetterAll(overflow, 0, "spill queue full while circuit breaker is open")
+	}
+}
+
+// drainSpill resends spilled documents in batches once the circuit breaker lets requests through
+// Only a single batch is sent while the breaker is half-open so that it serves as the recovery probe
+func (b *BulkIndexer) drainSpill(ctx context.Context) error {
+	for {
+		state := b.client.BreakerState()
+		if state == BreakerOpen {
+			return nil
+		}
+
+		b.mu.Lock()
+		if len(b.spill) == 0 {
+			b.mu.Unlock()
+			return nil
+		}
+		n := min(len(b.spill), b.config.MaxBatchDocs)
+		batch := b.spill[:n:n]
+		b.spill = b.spill[n:]
+		b.wg.Add(1)
+		b.mu.Unlock()
+
+		if err := b.dispatch(ctx, batch); err != nil {
+			return err
+		}
+		if state == BreakerHalfOpen {
+			return nil
+		}
+	}
+}
+
+// dispatch sends a batch in the background once an in-flight slot is available
+func (b *BulkIndexer) dispatch(ctx context.Context, batch []bulkItem) error {
+	select {
+	case b.inFlight <- struct{}{}:
+	case <-ctx.Done():
+		b.deadLetterAll(batch, 0, fmt.Sprintf("not sent: %v", ctx.Err()))
+		b.wg.Done()
+		return ctx.Err()
+	}
+
+	go func() {
+		defer b.wg.Done()
+		defer func() { <-b.inFlight }()
+		b.send(batch)
+	}()
+	return nil
+}
+
+// send writes a batch, retrying items rejected with 429/503 using exponential backoff
+func (b *BulkIndexer) send(batch []bulkItem) {
+	backoff := b.config.InitialBackoff
+
+	for attempt := 0; ; attempt++ {
+		retry, status, reason := b.sendOnce(batch)
+		if len(retry) == 0 {
+			return
+		}
+		if attempt >= b.config.MaxRetries {
+			b.deadLetterAll(retry, status, fmt.Sprintf("retries exhausted: %s", reason))
+			return
+		}
+
+		b.retried.Add(uint64(len(retry)))
+		b.config.Metrics.BulkDocuments(metrics.BulkResultRetried, len(retry))
+		time.Sleep(backoff)
+		backoff *= 2
+		if backoff > b.config.MaxBackoff {
+			backoff = b.config.MaxBackoff
+		}
+		batch = retry
+	}
+}
+
+// sendOnce writes a batch and returns the items to be retried
+// Items failing with other errors are sent to the dead-letter log
+func (b *BulkIndexer) sendOnce(batch []bulkItem) ([]bulkItem, int, string) {
+	var body bytes.Buffer
+	for _, item := range batch {
+		body.Write(item.action)
+		body.WriteByte('\n')
+		body.Write(item.source)
+		body.WriteByte('\n')
+	}
+
+	ctx, span := commontracing.Tracer().Start(context.Background(), "bulk index",
+		trace.WithLinks(batchLinks(batch)...),
+		trace.WithAttributes(attribute.Int("documents", len(batch))),
+	)
+	defer span.End()
+
+	response, status, err := b.client.Bulk(ctx, body.Bytes())
+	if errors.Is(err, ErrCircuitOpen) {
+		b.spillBatch(batch)
+		return nil, 0, ""
+	}
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		if status != 0 && !isRetryableStatus(status) {
+			b.deadLetterAll(batch, status, err.Error())
+			return nil, 0, ""
+		}
+		slog.Warn("Bulk request failed, retrying", "documents", len(batch), "status", status, "error", err)
+		return batch, status, err.Error()
+	}
+
+	if len(response.Items) != len(batch) {
+		b.deadLetterAll(batch, 0, fmt.Sprintf("bulk response has %d items for %d documents", len(response.Items), len(batch)))
+		return nil, 0, ""
+	}
+
+	var retry []bulkItem
+	var failed []failedItem
+	var lastStatus int
+	var lastReason string
+	for i, result := range response.Items {
+		item := result.result()
+		switch {
+		case item.Status < 300:
+			b.flushed.Add(1)
+			b.config.Metrics.BulkDocuments(metrics.BulkResultIndexed, 1)
+			if batch[i].onDone != nil {
+				batch[i].onDone(nil)
+			}
+		case isRetryableStatus(item.Status):
+			retry = append(retry, batch[i])
+			lastStatus = item.Status
+			lastReason = item.reason()
+		default:
+			failed = append(failed, failedItem{item: batch[i], status: item.Status, reason: item.reason()})
+		}
+	}
+	if len(failed) > 0 {
+		b.writeDeadLetters(failed)
+	}
+	span.SetAttributes(attribute.Int("documents.retried", len(retry)))
+	slog.Debug("Bulk request completed", "documents", len(batch), "retry", len(retry))
+	return retry, lastStatus, lastReason
+}
+
+// batchLinks links the span of a bulk request to the traces of the span documents it writes
+func batchLinks(batch []bulkItem) []trace.Link {
+	var links tracing.Links
+	for _, item := range batch {
+		// Span documents are identified by their trace and span IDs, see SpanDocumentID
+		if traceID, spanID, ok := strings.Cut(item.id, "-"); ok {
+			links.Add(traceID, spanID)
+		}
+	}
+	return links.Links()
+}
+
+// deadLetterAll sends every item of a batch to the dead letters
+func (b *BulkIndexer) deadLetterAll(batch []bulkItem, status int, reason string) {
+	failed := make([]failedItem, len(batch))
+	for i, item := range batch {
+		failed[i] = failedItem{item: item, status: status, reason: reason}
+	}
+	b.writeDeadLetters(failed)
+}
+
+// writeDeadLetters records permanently failed documents in the dead-letter index
+// Documents the index does not take, e.g. while OpenSearch is unavailable, go to the dead-letter file or log
+func (b *BulkIndexer) writeDeadLetters(failed []failedItem) {
+	b.failed.Add(uint64(len(failed)))
+	b.config.Metrics.BulkDocuments(metrics.BulkResultFailed, len(failed))
+	b.config.Metrics.SpansDropped(metrics.DropReasonDeadLetter, len(failed))
+
+	now := time.Now().UTC()
+	letters := make([]DeadLetter, len(failed))
+	for i, f := range failed {
+		letters[i] = DeadLetter{
+			EntryID:  DeadLetterID(f.item.index, f.item.id),
+			Time:     now,
+			Index:    f.item.index,
+			ID:       f.item.id,
+			Status:   f.status,
+			Reason:   f.reason,
+			Document: f.item.source,
+		}
+		// Span documents are identified by their trace and span IDs, see SpanDocumentID
+		if traceID, _, ok := strings.Cut(f.item.id, "-"); ok && IsTraceIndex(f.item.index) {
+			letters[i].TraceID = traceID
+		}
+	}
+
+	var storeErrs []error
+	if b.config.DeadLetterStore != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), deadLetterStoreTimeout)
+		storeErrs = b.config.DeadLetterStore.Record(ctx, letters)
+		cancel()
+	}
+
+	for i, f := range failed {
+		if storeErrs != nil && storeErrs[i] == nil {
+			slog.Error("Bulk indexing failed, document dead-lettered",
+				"index", f.item.index, "id", f.item.id, "status", f.status, "reason", f.reason, "deadLetterId", letters[i].EntryID)
+		} else {
+			if storeErrs != nil {
+				slog.Warn("Failed to store dead letter", "index", f.item.index, "id", f.item.id, "error", storeErrs[i])
+			}
+			b.writeDeadLetterLog(letters[i])
+		}
+		if f.item.onDone != nil {
+			f.item.onDone(fmt.Errorf("document dead-lettered: %s", f.reason))
+		}
+	}
+}
+
+// writeDeadLetterLog writes a dead letter to the dead-letter file, or logs it with its document without a file
+func (b *BulkIndexer) writeDeadLetterLog(letter DeadLetter) {
+	if b.deadLetter == nil {
+		// Without a dead-letter file the log keeps the document so that it can be replayed
+		slog.Error("Bulk indexing failed, document dead-lettered",
+			"index", letter.Index, "id", letter.ID, "status", letter.Status, "reason", letter.Reason, "document", letter.Document)
+		return
+	}
+	slog.Error("Bulk indexing failed, document dead-lettered", "index", letter.Index, "id", letter.ID, "status", letter.Status, "reason", letter.Reason)
+
+	line, err := json.Marshal(letter)
+	if err != nil {
+		slog.Error("Failed to encode dead letter", "index", letter.Index, "id", letter.ID, "error", err)
+		return
+	}
+	b.deadLetterMu.Lock()
+	defer b.deadLetterMu.Unlock()
+	if _, err := b.deadLetter.Write(append(line, '\n')); err != nil {
+		slog.Error("Failed to write dead letter", "index", letter.Index, "id", letter.ID, "error", err)
+	}
+}
+
+// encodeBulkItem encodes the action and source lines of a document
+func encodeBulkItem(doc BulkDocument) (bulkItem, error) {
+	if doc.Index == "" {
+		return bulkItem{}, fmt.Errorf("document index is required")
+	}
+
+	// The index action creates the document or replaces the document with the same ID,
+	// so re-delivered documents with a deterministic ID overwrite rather than duplicate
+	meta := map[string]string{"_index": doc.Index}
+	if doc.ID != "" {
+		meta["_id"] = doc.ID
+	}
+	action, err := json.Marshal(map[string]interface{}{"index": meta})
+	if err != nil {
+		return bulkItem{}, fmt.Errorf("failed to encode bulk action: %w", err)
+	}
+	source, err := json.Marshal(doc.Body)
+	if err != nil {
+		return bulkItem{}, fmt.Errorf("failed to encode document: %w", err)
+	}
+
+	return bulkItem{index: doc.Index, id: doc.ID, action: action, source: source, onDone: doc.OnDone}, nil
+}
+
+// isRetryableStatus reports whether a bulk rejection is transient
+func isRetryableStatus(status int) bool {
+	return status == http.StatusTooManyRequests || status == http.StatusServiceUnavailable
+}
+
+// result returns the result of the single action of a bulk response item
+func (i BulkResponseItem) result() BulkItemResult {
+	for _, result := range i {
+		return result
+	}
+	return BulkItemResult{}
+}
+
+// reason describes why a bulk action failed
+func (r BulkItemResult) reason() string {
+	if r.Error == nil {
+		return fmt.Sprintf("status %d", r.Status)
+	}
+	return fmt.Sprintf("%s: %s", r.Error.Type, r.Error.Reason)
+}
@@ -0,0 +1,291 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package opensearch
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/config"
+)
+
+// scriptedBulk answers bulk requests with the status its script returns for every document
+// A request status other than 200 fails the whole request
+type scriptedBulk struct {
+	mu       sync.Mutex
+	attempts map[string]int
+	requests int
+	request  func(n int) int
+	item     func(id string, attempt int) int
+}
+
+func (f *scriptedBulk) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if r.URL.Path != "/_bulk" {
+		fmt.Fprint(w, `{"cluster_name":"test","version":{"distribution":"opensearch","number":"2.11.0"}}`)
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.requests++
+	if f.request != nil {
+		if status := f.request(f.requests); status != http.StatusOK {
+			w.WriteHeader(status)
+			fmt.Fprint(w, `{"error":"scripted"}`)
+			return
+		}
+	}
+
+	var items []string
+	scanner := bufio.NewScanner(r.Body)
+	for scanner.Scan() {
+		var action map[string]struct {
+			ID string `json:"_id"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &action); err != nil || !scanner.Scan() {
+			http.Error(w, "invalid bulk body", http.StatusBadRequest)
+			return
+		}
+		id := action["index"].ID
+		f.attempts[id]++
+		status := f.item(id, f.attempts[id])
+		if status < 300 {
+			items = append(items, fmt.Sprintf(`{"index":{"_id":%q,"status":%d}}`, id, status))
+		} else {
+			items = append(items, fmt.Sprintf(`{"index":{"_id":%q,"status":%d,"error":{"type":"scripted_exception","reason":"rejected"}}}`, id, status))
+		}
+	}
+	fmt.Fprintf(w, `{"took":1,"errors":true,"items":[%s]}`, strings.Join(items, ","))
+}
+
+func (f *scriptedBulk) attemptsOf(id string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.attempts[id]
+}
+
+// newScriptedIndexer creates a bulk indexer writing to a scripted OpenSearch, dead letters go to a file
+func newScriptedIndexer(t *testing.T, fake *scriptedBulk, maxRetries int) (*BulkIndexer, string) {
+	t.Helper()
+	fake.attempts = map[string]int{}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	client, err := NewClient(&config.OpenSearchConfig{Address: server.URL, RequestTimeout: 5 * time.Second}, nil)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	deadLetterPath := filepath.Join(t.TempDir(), "dead-letters.jsonl")
+	indexer, err := client.NewBulkIndexer(BulkIndexerConfig{
+		FlushInterval:  time.Hour,
+		MaxRetries:     maxRetries,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     2 * time.Millisecond,
+		DeadLetterPath: deadLetterPath,
+	})
+	if err != nil {
+		t.Fatalf("NewBulkIndexer() error = %v", err)
+	}
+	return indexer, deadLetterPath
+}
+
+// indexAll queues documents, flushes them and returns the outcome of every document once all are done
+func indexAll(t *testing.T, indexer *BulkIndexer, ids ...string) map[string]error {
+	t.Helper()
+	type outcome struct {
+		id  string
+		err error
+	}
+	done := make(chan outcome, len(ids))
+	for _, id := range ids {
+		err := indexer.Add(context.Background(), BulkDocument{
+			Index:  "otel-traces-2026-10-16",
+			ID:     id,
+			Body:   map[string]string{"spanId": id},
+			OnDone: func(err error) { done <- outcome{id: id, err: err} },
+		})
+		if err != nil {
+			t.Fatalf("Add() error = %v", err)
+		}
+	}
+	if err := indexer.Flush(context.Background()); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+
+	results := map[string]error{}
+	for range ids {
+		select {
+		case o := <-done:
+			results[o.id] = o.err
+		case <-time.After(5 * time.Second):
+			t.Fatalf("documents neither indexed nor dead-lettered, got %v", results)
+		}
+	}
+	return results
+}
+
+// readDeadLetters closes the indexer and returns the dead letters written to its file
+func readDeadLetters(t *testing.T, indexer *BulkIndexer, path string) []DeadLetter {
+	t.Helper()
+	if err := indexer.Close(context.Background()); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var letters []DeadLetter
+	for _, line := range strings.Split(strings.TrimSpace(string(content)), "\n") {
+		if line == "" {
+			continue
+		}
+		var letter DeadLetter
+		if err := json.Unmarshal([]byte(line), &letter); err != nil {
+			t.Fatalf("dead letter is not JSON: %q", line)
+		}
+		letters = append(letters, letter)
+	}
+	return letters
+}
+
+func TestBulkIndexerRetriesRejectedItems(t *testing.T) {
+	for _, status := range []int{http.StatusTooManyRequests, http.StatusServiceUnavailable} {
+		t.Run(http.StatusText(status), func(t *testing.T) {
+			fake := &scriptedBulk{item: func(id string, attempt int) int {
+				if id == "busy" && attempt <= 2 {
+					return status
+				}
+				return http.StatusCreated
+			}}
+			indexer, deadLetterPath := newScriptedIndexer(t, fake, 3)
+
+			results := indexAll(t, indexer, "busy", "fine")
+
+			if results["busy"] != nil || results["fine"] != nil {
+				t.Errorf("results = %v, want both documents indexed", results)
+			}
+			if fake.attemptsOf("busy") != 3 || fake.attemptsOf("fine") != 1 {
+				t.Errorf("attempts = %v, only the rejected document is resent", fake.attempts)
+			}
+			stats := indexer.Stats()
+			if stats.Flushed != 2 || stats.Retried != 2 || stats.Failed != 0 {
+				t.Errorf("stats = %+v, want 2 flushed and 2 retries", stats)
+			}
+			if letters := readDeadLetters(t, indexer, deadLetterPath); len(letters) != 0 {
+				t.Errorf("dead letters = %+v, want none", letters)
+			}
+		})
+	}
+}
+
+func TestBulkIndexerDeadLettersAfterRetries(t *testing.T) {
+	fake := &scriptedBulk{item: func(string, int) int { return http.StatusTooManyRequests }}
+	indexer, deadLetterPath := newScriptedIndexer(t, fake, 2)
+
+	results := indexAll(t, indexer, "busy")
+
+	if results["busy"] == nil || !strings.Contains(results["busy"].Error(), "retries exhausted") {
+		t.Errorf("result = %v, want the document dead-lettered once retries are exhausted", results["busy"])
+	}
+	if n := fake.attemptsOf("busy"); n != 3 {
+		t.Errorf("attempts = %d, want the first attempt and 2 retries", n)
+	}
+	letters := readDeadLetters(t, indexer, deadLetterPath)
+	if len(letters) != 1 || letters[0].ID != "busy" || letters[0].Status != http.StatusTooManyRequests {
+		t.Fatalf("dead letters = %+v, want the rejected document", letters)
+	}
+	if string(letters[0].Document) != `{"spanId":"busy"}` {
+		t.Errorf("dead letter document = %s, want the source to replay", letters[0].Document)
+	}
+	if stats := indexer.Stats(); stats.Failed != 1 || stats.Retried != 2 {
+		t.Errorf("stats = %+v, want 1 failed after 2 retries", stats)
+	}
+}
+
+func TestBulkIndexerDeadLettersPermanentFailuresAtOnce(t *testing.T) {
+	fake := &scriptedBulk{item: func(id string, _ int) int {
+		if id == "invalid" {
+			return http.StatusBadRequest
+		}
+		return http.StatusCreated
+	}}
+	indexer, deadLetterPath := newScriptedIndexer(t, fake, 3)
+
+	results := indexAll(t, indexer, "invalid", "fine")
+
+	if results["invalid"] == nil || results["fine"] != nil {
+		t.Errorf("results = %v, want only the invalid document dead-lettered", results)
+	}
+	if n := fake.attemptsOf("invalid"); n != 1 {
+		t.Errorf("attempts = %d, a 400 rejection must not be retried", n)
+	}
+	letters := readDeadLetters(t, indexer, deadLetterPath)
+	if len(letters) != 1 || letters[0].Status != http.StatusBadRequest || letters[0].Reason != "scripted_exception: rejected" {
+		t.Errorf("dead letters = %+v, want the rejection of the invalid document", letters)
+	}
+}
+
+func TestBulkIndexerRetriesFailedRequests(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     int
+		wantErr    bool
+		wantTries  int
+		wantStatus int
+	}{
+		{name: "unavailable cluster is retried", status: http.StatusServiceUnavailable, wantTries: 2},
+		{name: "bad request is dead-lettered", status: http.StatusBadRequest, wantErr: true, wantTries: 1, wantStatus: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &scriptedBulk{
+				request: func(n int) int {
+					if n == 1 {
+						return tt.status
+					}
+					return http.StatusOK
+				},
+				item: func(string, int) int { return http.StatusCreated },
+			}
+			indexer, deadLetterPath := newScriptedIndexer(t, fake, 3)
+
+			results := indexAll(t, indexer, "doc")
+
+			if (results["doc"] != nil) != tt.wantErr {
+				t.Errorf("result = %v, want error %v", results["doc"], tt.wantErr)
+			}
+			if fake.requests != tt.wantTries {
+				t.Errorf("requests = %d, want %d", fake.requests, tt.wantTries)
+			}
+			letters := readDeadLetters(t, indexer, deadLetterPath)
+			if tt.wantErr && (len(letters) != 1 || letters[0].Status != tt.wantStatus) {
+				t.Errorf("dead letters = %+v, want the document with status %d", letters, tt.wantStatus)
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package opensearch
+
+import (
+	"context"
+	"time"
+)
+
+// CheckpointIndex is the index holding the progress of background trace exporters
+const CheckpointIndex = "amp-export-checkpoints"
+
+// ExportCheckpoint records the last trace handed to a downstream system by an exporter
+type ExportCheckpoint struct {
+	Exporter  string    `json:"exporter"`
+	Cursor    string    `json:"cursor"` // Opaque cursor of the last exported trace, see EncodeCursor
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// buildCheckpointIndexBody builds the mappings of the checkpoint index
+func buildCheckpointIndexBody() map[string]interface{} {
+	return map[string]interface{}{
+		"mappings": map[string]interface{}{
+			"properties": map[string]interface{}{
+				"exporter":  map[string]interface{}{"type": "keyword"},
+				"cursor":    map[string]interface{}{"type": "keyword", "index": false},
+				"updatedAt": map[string]interface{}{"type": "date"},
+			},
+		},
+	}
+}
+
+// EnsureCheckpointIndex creates the checkpoint index when it does not exist yet
+func (c *Client) EnsureCheckpointIndex(ctx context.Context) error {
+	return c.EnsureIndex(ctx, CheckpointIndex, buildCheckpointIndexBody())
+}
+
+// LoadCheckpoint returns the checkpoint of an exporter, nil when it has not exported anything yet
+func (c *Client) LoadCheckpoint(ctx context.Context, exporter string) (*ExportCheckpoint, error) {
+	var checkpoint ExportCheckpoint
+	found, err := c.GetDocument(ctx, CheckpointIndex, exporter, &checkpoint)
+	if err != nil || !found {
+		return nil, err
+	}
+	return &checkpoint, nil
+}
+
+// SaveCheckpoint stores the checkpoint of an exporter
+func (c *Client) SaveCheckpoint(ctx context.Context, checkpoint ExportCheckpoint) error {
+	return c.PutDocument(ctx, CheckpointIndex, checkpoint.Exporter, checkpoint)
+}
+
+// BuildFinalizedTracesQuery builds a query for the root spans of traces that ended before until, oldest first
+// Results are sorted by endTime with traceId as a tie breaker so that the sort values of the last hit can be
+// checkpointed and used as a search_after cursor; since is only applied when there is no cursor yet
+func BuildFinalizedTracesQuery(searchAfter []interface{}, since, until time.Time, size int) map[string]interface{} {
+	endTimeRange := map[string]interface{}{
+		"lte": until.UTC().Format(time.RFC3339Nano),
+	}
+	if len(searchAfter) == 0 && !since.IsZero() {
+		endTimeRange["gt"] = since.UTC().Format(time.RFC3339Nano)
+	}
+
+	query := map[string]interface{}{
+		"query": map[string]interface{}{
+			"bool": map[string]interface{}{
+				"must": []map[string]interface{}{
+					rootSpanCondition(),
+					{"range": map[string]interface{}{"endTime": endTimeRange}},
+				},
+			},
+		},
+		"size": size,
+		"sort": []map[string]interface{}{
+			{"endTime": map[string]string{"order": "asc"}},
+			{"traceId": map[string]string{"order": "asc"}},
+		},
+	}
+	if len(searchAfter) > 0 {
+		query["search_after"] = searchAfter
+	}
+	return query
+}
@@ -0,0 +1,76 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package opensearch
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBuildFinalizedTracesQuery(t *testing.T) {
+	since := time.Date(2025, 11, 3, 9, 0, 0, 0, time.UTC)
+	until := time.Date(2025, 11, 3, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name        string
+		searchAfter []interface{}
+		since       time.Time
+		want        []string
+		notWant     []string
+	}{
+		{
+			name:    "first round looks back",
+			since:   since,
+			want:    []string{`"gt":"2025-11-03T09:00:00Z"`, `"lte":"2025-11-03T10:00:00Z"`},
+			notWant: []string{"search_after"},
+		},
+		{
+			name:    "without lookback",
+			want:    []string{`"lte":"2025-11-03T10:00:00Z"`},
+			notWant: []string{`"gt"`, "search_after"},
+		},
+		{
+			name:        "cursor replaces the lookback",
+			searchAfter: []interface{}{1762160400000, "trace-1"},
+			since:       since,
+			want:        []string{`"search_after":[1762160400000,"trace-1"]`},
+			notWant:     []string{`"gt"`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encoded, err := json.Marshal(BuildFinalizedTracesQuery(tt.searchAfter, tt.since, until, 50))
+			if err != nil {
+				t.Fatal(err)
+			}
+			query := string(encoded)
+			// The sort values of the last hit are unique, so they can be checkpointed as a cursor
+			tt.want = append(tt.want, `"size":50`, `"sort":[{"endTime":{"order":"asc"}},{"traceId":{"order":"asc"}}]`)
+			for _, want := range tt.want {
+				if !strings.Contains(query, want) {
+					t.Errorf("query %s does not contain %s", query, want)
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(query, notWant) {
+					t.Errorf("query %s contains %s", query, notWant)
+				}
+			}
+		})
+	}
+}
@@ -21,23 +21,50 @@ import (
 	"context"
 	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
+	"io"
+	"log/slog"
 	"net/http"
+	"net/url"
+	"strings"
+	"sync/atomic"
 
 	"github.com/opensearch-project/opensearch-go"
 	"github.com/opensearch-project/opensearch-go/opensearchapi"
 	"github.com/wso2/ai-agent-management-platform/traces-observer-service/config"
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/metrics"
 )
 
 // Client wraps the OpenSearch client
 type Client struct {
-	client *opensearch.Client
-	config *config.OpenSearchConfig
+	client    *opensearch.Client
+	config    *config.OpenSearchConfig
+	transport *resilientTransport
+	retries   atomic.Uint64
+}
+
+// StatusError is returned for requests OpenSearch answered with an error status
+type StatusError struct {
+	Operation  string
+	StatusCode int
+	Status     string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("%s request failed with status: %s", e.Operation, e.Status)
+}
+
+// IsStatusError reports whether err is an error status of OpenSearch with the given code
+func IsStatusError(err error, statusCode int) bool {
+	var statusErr *StatusError
+	return errors.As(err, &statusErr) && statusErr.StatusCode == statusCode
 }
 
 // NewClient creates a new OpenSearch client
-func NewClient(cfg *config.OpenSearchConfig) (*Client, error) {
+// Requests time out after the configured timeout, retriable statuses (429, 502, 503) are retried with
+// exponential backoff and jitter, and a circuit breaker stops requests after consecutive failures
+func NewClient(cfg *config.OpenSearchConfig, m *metrics.Metrics) (*Client, error) {
 	// Create HTTP transport with TLS verification disabled
 	transport := &http.Transport{
 		TLSClientConfig: &tls.Config{
@@ -45,11 +72,25 @@ func NewClient(cfg *config.OpenSearchConfig) (*Client, error) {
 		},
 	}
 
+	c := &Client{
+		config: cfg,
+		transport: &resilientTransport{
+			next:    transport,
+			timeout: cfg.RequestTimeout,
+			breaker: NewCircuitBreaker(cfg.BreakerFailureThreshold, cfg.BreakerOpenTimeout),
+			metrics: m,
+		},
+	}
+
 	opensearchConfig := opensearch.Config{
-		Addresses: []string{cfg.Address},
-		Transport: transport,
-		Username:  cfg.Username,
-		Password:  cfg.Password,
+		Addresses:     []string{cfg.Address},
+		Transport:     &tracingTransport{next: c.transport},
+		Username:      cfg.Username,
+		Password:      cfg.Password,
+		RetryOnStatus: []int{http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable},
+		MaxRetries:    cfg.MaxRetries,
+		DisableRetry:  cfg.MaxRetries <= 0,
+		RetryBackoff:  retryBackoff(cfg.RetryBackoff, cfg.MaxRetryBackoff, &c.retries),
 	}
 
 	client, err := opensearch.NewClient(opensearchConfig)
@@ -62,17 +103,32 @@ func NewClient(cfg *config.OpenSearchConfig) (*Client, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to OpenSearch: %w", err)
 	} else {
-		log.Printf("Connected to OpenSearch, status: %s", info.Status())
+		slog.Info("Connected to OpenSearch", "status", info.Status())
 	}
 
-	return &Client{
-		client: client,
-		config: cfg,
-	}, nil
+	c.client = client
+	return c, nil
+}
+
+// Stats returns the retry and circuit breaker counters
+func (c *Client) Stats() ClientStats {
+	return ClientStats{
+		BreakerState: c.transport.breaker.State(),
+		Retries:      c.retries.Load(),
+		Rejected:     c.transport.rejected.Load(),
+	}
+}
+
+// BreakerState returns the current circuit breaker state
+func (c *Client) BreakerState() string {
+	return c.transport.breaker.State()
 }
 
 // Search executes a search query against one or more indices
+// When ctx is bound to an organization only the documents of the organization match
 func (c *Client) Search(ctx context.Context, indices []string, query map[string]interface{}) (*SearchResponse, error) {
+	query = scopeQuery(ctx, query)
+
 	// Convert query to JSON
 	var buf bytes.Buffer
 	if err := json.NewEncoder(&buf).Encode(query); err != nil {
@@ -89,14 +145,14 @@ func (c *Client) Search(ctx context.Context, indices []string, query map[string]
 	// Execute search
 	res, err := req.Do(ctx, c.client)
 	if err != nil {
-		log.Printf("Search request failed: %v", err)
+		slog.ErrorContext(ctx, "Search request failed", "indices", indices, "error", err)
 		return nil, fmt.Errorf("search request failed: %w", err)
 	}
 	defer res.Body.Close()
 
 	if res.IsError() {
-		log.Printf("Search request returned error: %s", res.Status())
-		return nil, fmt.Errorf("search request failed with status: %s", res.Status())
+		slog.ErrorContext(ctx, "Search request returned error", "indices", indices, "status", res.StatusCode)
+		return nil, &StatusError{Operation: "search", StatusCode: res.StatusCode, Status: res.Status()}
 	}
 
 	// Parse response
@@ -105,11 +161,507 @@ func (c *Client) Search(ctx context.Context, indices []string, query map[string]
 		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
 
-	log.Printf("Search completed: total_hits=%d, returned_hits=%d", response.Hits.Total.Value, len(response.Hits.Hits))
+	slog.DebugContext(ctx, "Search completed", "totalHits", response.Hits.Total.Value, "returnedHits", len(response.Hits.Hits))
+
+	return &response, nil
+}
+
+// CreatePIT opens a point in time over the given indices for consistent paging with search_after
+// Requires OpenSearch 2.4 or later
+func (c *Client) CreatePIT(ctx context.Context, indices []string, keepAlive string) (string, error) {
+	path := "/" + strings.Join(indices, ",") + "/_search/point_in_time?keep_alive=" + url.QueryEscape(keepAlive)
+	var response struct {
+		PitID string `json:"pit_id"`
+	}
+	if err := c.perform(ctx, http.MethodPost, path, nil, &response, "create point in time"); err != nil {
+		return "", err
+	}
+	if response.PitID == "" {
+		return "", fmt.Errorf("create point in time response has no pit_id")
+	}
+	return response.PitID, nil
+}
+
+// SearchPIT executes a search against an open point in time
+// The query must not name indices, and its pit clause is set from the arguments
+func (c *Client) SearchPIT(ctx context.Context, pitID, keepAlive string, query map[string]interface{}) (*SearchResponse, error) {
+	query = scopeQuery(ctx, query)
+	body := make(map[string]interface{}, len(query)+1)
+	for key, value := range query {
+		body[key] = value
+	}
+	body["pit"] = map[string]interface{}{
+		"id":         pitID,
+		"keep_alive": keepAlive,
+	}
+
+	var response SearchResponse
+	if err := c.perform(ctx, http.MethodPost, "/_search", body, &response, "point in time search"); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// DeletePIT closes a point in time
+func (c *Client) DeletePIT(ctx context.Context, pitID string) error {
+	body := map[string]interface{}{"pit_id": []string{pitID}}
+	return c.perform(ctx, http.MethodDelete, "/_search/point_in_time", body, nil, "delete point in time")
+}
+
+// OpenScroll starts a scroll over every document of an index in index order
+func (c *Client) OpenScroll(ctx context.Context, index string, size int, keepAlive string) (*SearchResponse, error) {
+	path := "/" + index + "/_search?scroll=" + url.QueryEscape(keepAlive)
+	body := map[string]interface{}{
+		"size": size,
+		"sort": []string{"_doc"},
+	}
 
+	var response SearchResponse
+	if err := c.perform(ctx, http.MethodPost, path, body, &response, "open scroll"); err != nil {
+		return nil, err
+	}
 	return &response, nil
 }
 
+// Scroll returns the next page of a scroll
+func (c *Client) Scroll(ctx context.Context, scrollID, keepAlive string) (*SearchResponse, error) {
+	body := map[string]interface{}{
+		"scroll":    keepAlive,
+		"scroll_id": scrollID,
+	}
+
+	var response SearchResponse
+	if err := c.perform(ctx, http.MethodPost, "/_search/scroll", body, &response, "scroll"); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// ClearScroll releases a scroll context
+func (c *Client) ClearScroll(ctx context.Context, scrollID string) error {
+	body := map[string]interface{}{"scroll_id": []string{scrollID}}
+	return c.perform(ctx, http.MethodDelete, "/_search/scroll", body, nil, "clear scroll")
+}
+
+// perform executes a raw JSON request for APIs the OpenSearch client does not provide
+func (c *Client) perform(ctx context.Context, method, path string, body interface{}, result interface{}, operation string) error {
+	var reader io.Reader
+	if body != nil {
+		var buf bytes.Buffer
+		if err := json.NewEncoder(&buf).Encode(body); err != nil {
+			return fmt.Errorf("failed to encode %s request: %w", operation, err)
+		}
+		reader = &buf
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, path, reader)
+	if err != nil {
+		return fmt.Errorf("failed to build %s request: %w", operation, err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	res, err := c.client.Perform(req)
+	if err != nil {
+		return fmt.Errorf("%s request failed: %w", operation, err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= 300 {
+		return &StatusError{Operation: operation, StatusCode: res.StatusCode, Status: res.Status}
+	}
+	if result == nil {
+		return nil
+	}
+	if err := json.NewDecoder(res.Body).Decode(result); err != nil {
+		return fmt.Errorf("failed to decode %s response: %w", operation, err)
+	}
+	return nil
+}
+
+// Bulk executes a bulk request with an NDJSON body
+// The HTTP status is returned alongside request errors so that callers can decide whether to retry
+func (c *Client) Bulk(ctx context.Context, body []byte) (*BulkResponse, int, error) {
+	req := opensearchapi.BulkRequest{
+		Body: bytes.NewReader(body),
+	}
+
+	res, err := req.Do(ctx, c.client)
+	if err != nil {
+		return nil, 0, fmt.Errorf("bulk request failed: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return nil, res.StatusCode, fmt.Errorf("bulk request failed with status: %s", res.Status())
+	}
+
+	var response BulkResponse
+	if err := json.NewDecoder(res.Body).Decode(&response); err != nil {
+		return nil, res.StatusCode, fmt.Errorf("failed to decode bulk response: %w", err)
+	}
+	return &response, res.StatusCode, nil
+}
+
+// EnsureIndex creates an index with the given settings and mappings when it does not exist yet
+func (c *Client) EnsureIndex(ctx context.Context, index string, body map[string]interface{}) error {
+	exists, err := c.IndexExists(ctx, index)
+	if err != nil {
+		return fmt.Errorf("failed to check index %s: %w", index, err)
+	}
+	if exists {
+		return nil
+	}
+
+	if err := c.CreateIndex(ctx, index, body); err != nil {
+		// Another replica may have created the index concurrently
+		if exists, existsErr := c.IndexExists(ctx, index); existsErr == nil && exists {
+			return nil
+		}
+		return fmt.Errorf("failed to create index %s: %w", index, err)
+	}
+	slog.InfoContext(ctx, "Created index", "index", index)
+	return nil
+}
+
+// IndexDocument indexes a single document and waits until it is visible to searches
+func (c *Client) IndexDocument(ctx context.Context, index, id string, document interface{}) error {
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(document); err != nil {
+		return fmt.Errorf("failed to encode document: %w", err)
+	}
+
+	req := opensearchapi.IndexRequest{
+		Index:      index,
+		DocumentID: id,
+		Body:       &buf,
+		OpType:     "create",
+		Refresh:    "wait_for",
+	}
+	return c.do(ctx, req, "index document")
+}
+
+// PutDocument creates or replaces a single document and waits until it is visible to searches
+func (c *Client) PutDocument(ctx context.Context, index, id string, document interface{}) error {
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(document); err != nil {
+		return fmt.Errorf("failed to encode document: %w", err)
+	}
+
+	req := opensearchapi.IndexRequest{
+		Index:      index,
+		DocumentID: id,
+		Body:       &buf,
+		Refresh:    "wait_for",
+	}
+	return c.do(ctx, req, "put document")
+}
+
+// GetDocument decodes the source of a single document into result
+// Returns false when the document does not exist
+func (c *Client) GetDocument(ctx context.Context, index, id string, result interface{}) (bool, error) {
+	req := opensearchapi.GetRequest{
+		Index:      index,
+		DocumentID: id,
+	}
+
+	res, err := req.Do(ctx, c.client)
+	if err != nil {
+		return false, fmt.Errorf("get document request failed: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusNotFound {
+		return false, nil
+	}
+	if res.IsError() {
+		return false, fmt.Errorf("get document request failed with status: %s", res.Status())
+	}
+
+	var document struct {
+		Found  bool            `json:"found"`
+		Source json.RawMessage `json:"_source"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&document); err != nil {
+		return false, fmt.Errorf("failed to decode document: %w", err)
+	}
+	if !document.Found {
+		return false, nil
+	}
+	if err := json.Unmarshal(document.Source, result); err != nil {
+		return false, fmt.Errorf("failed to decode document source: %w", err)
+	}
+	return true, nil
+}
+
+// DeleteDocument deletes a single document and waits until the deletion is visible to searches
+// Returns false when the document does not exist
+func (c *Client) DeleteDocument(ctx context.Context, index, id string) (bool, error) {
+	req := opensearchapi.DeleteRequest{
+		Index:      index,
+		DocumentID: id,
+		Refresh:    "wait_for",
+	}
+
+	res, err := req.Do(ctx, c.client)
+	if err != nil {
+		return false, fmt.Errorf("delete document request failed: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusNotFound {
+		return false, nil
+	}
+	if res.IsError() {
+		return false, fmt.Errorf("delete document request failed with status: %s", res.Status())
+	}
+	return true, nil
+}
+
+// PutIndexTemplate creates or updates a composable index template
+func (c *Client) PutIndexTemplate(ctx context.Context, name string, template map[string]interface{}) error {
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(template); err != nil {
+		return fmt.Errorf("failed to encode index template: %w", err)
+	}
+
+	req := opensearchapi.IndicesPutIndexTemplateRequest{
+		Name: name,
+		Body: &buf,
+	}
+	return c.do(ctx, req, "put index template")
+}
+
+// PutMapping updates the mapping of existing indices
+func (c *Client) PutMapping(ctx context.Context, indices []string, mapping map[string]interface{}) error {
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(mapping); err != nil {
+		return fmt.Errorf("failed to encode mapping: %w", err)
+	}
+
+	req := opensearchapi.IndicesPutMappingRequest{
+		Index: indices,
+		Body:  &buf,
+	}
+	return c.do(ctx, req, "put mapping")
+}
+
+// GetMapping returns the mappings of an index
+func (c *Client) GetMapping(ctx context.Context, index string) (map[string]interface{}, error) {
+	var response map[string]struct {
+		Mappings map[string]interface{} `json:"mappings"`
+	}
+	if err := c.perform(ctx, http.MethodGet, "/"+url.PathEscape(index)+"/_mapping", nil, &response, "get mapping"); err != nil {
+		return nil, err
+	}
+	for _, entry := range response {
+		return entry.Mappings, nil
+	}
+	return nil, fmt.Errorf("get mapping response has no index %s", index)
+}
+
+// Reindex copies every document of an index into another and returns the number of documents copied
+func (c *Client) Reindex(ctx context.Context, source, dest string) (int, error) {
+	body := map[string]interface{}{
+		"source": map[string]interface{}{"index": source},
+		"dest":   map[string]interface{}{"index": dest},
+	}
+	var response struct {
+		Created  int               `json:"created"`
+		Updated  int               `json:"updated"`
+		Failures []json.RawMessage `json:"failures"`
+	}
+	if err := c.perform(ctx, http.MethodPost, "/_reindex?wait_for_completion=true&refresh=true", body, &response, "reindex"); err != nil {
+		return 0, err
+	}
+	if len(response.Failures) > 0 {
+		return 0, fmt.Errorf("reindex from %s to %s failed for %d documents: %s", source, dest, len(response.Failures), response.Failures[0])
+	}
+	return response.Created + response.Updated, nil
+}
+
+// Count returns the number of documents of an index
+func (c *Client) Count(ctx context.Context, index string) (int, error) {
+	var response struct {
+		Count int `json:"count"`
+	}
+	if err := c.perform(ctx, http.MethodGet, "/"+url.PathEscape(index)+"/_count", nil, &response, "count"); err != nil {
+		return 0, err
+	}
+	return response.Count, nil
+}
+
+// UpdateByQuery re-indexes all documents of the given indices in place to pick up mapping changes
+func (c *Client) UpdateByQuery(ctx context.Context, indices []string) error {
+	req := opensearchapi.UpdateByQueryRequest{
+		Index:     indices,
+		Conflicts: "proceed",
+		Refresh:   opensearchapi.BoolPtr(true),
+	}
+	return c.do(ctx, req, "update by query")
+}
+
+// DeleteByQuery deletes the documents of the given indices matching a query and returns the number deleted
+func (c *Client) DeleteByQuery(ctx context.Context, indices []string, query map[string]interface{}) (int, error) {
+	escaped := make([]string, len(indices))
+	for i, index := range indices {
+		escaped[i] = url.PathEscape(index)
+	}
+	var response struct {
+		Deleted int `json:"deleted"`
+	}
+	path := "/" + strings.Join(escaped, ",") + "/_delete_by_query?conflicts=proceed&refresh=true"
+	if err := c.perform(ctx, http.MethodPost, path, map[string]interface{}{"query": query}, &response, "delete by query"); err != nil {
+		return 0, err
+	}
+	return response.Deleted, nil
+}
+
+// GetIndices returns the names of the indices matching a pattern
+func (c *Client) GetIndices(ctx context.Context, pattern string) ([]string, error) {
+	req := opensearchapi.IndicesGetRequest{
+		Index:             []string{pattern},
+		IgnoreUnavailable: opensearchapi.BoolPtr(true),
+		AllowNoIndices:    opensearchapi.BoolPtr(true),
+	}
+
+	res, err := req.Do(ctx, c.client)
+	if err != nil {
+		return nil, fmt.Errorf("get indices request failed: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return nil, fmt.Errorf("get indices request failed with status: %s", res.Status())
+	}
+
+	var indices map[string]interface{}
+	if err := json.NewDecoder(res.Body).Decode(&indices); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	names := make([]string, 0, len(indices))
+	for name := range indices {
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+// IndexExists reports whether an index or alias exists
+func (c *Client) IndexExists(ctx context.Context, name string) (bool, error) {
+	req := opensearchapi.IndicesExistsRequest{
+		Index: []string{name},
+	}
+	return c.exists(ctx, req, "index exists")
+}
+
+// IndexTemplateExists reports whether a composable index template exists
+func (c *Client) IndexTemplateExists(ctx context.Context, name string) (bool, error) {
+	req := opensearchapi.IndicesExistsIndexTemplateRequest{
+		Name: name,
+	}
+	return c.exists(ctx, req, "index template exists")
+}
+
+// CreateIndex creates an index with optional settings, mappings and aliases
+func (c *Client) CreateIndex(ctx context.Context, name string, body map[string]interface{}) error {
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(body); err != nil {
+		return fmt.Errorf("failed to encode index body: %w", err)
+	}
+
+	req := opensearchapi.IndicesCreateRequest{
+		Index: name,
+		Body:  &buf,
+	}
+	return c.do(ctx, req, "create index")
+}
+
+// UpdateAliases applies alias actions atomically
+func (c *Client) UpdateAliases(ctx context.Context, actions []map[string]interface{}) error {
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(map[string]interface{}{"actions": actions}); err != nil {
+		return fmt.Errorf("failed to encode alias actions: %w", err)
+	}
+
+	req := opensearchapi.IndicesUpdateAliasesRequest{
+		Body: &buf,
+	}
+	return c.do(ctx, req, "update aliases")
+}
+
+// GetAliasIndices returns the indices an alias points at
+func (c *Client) GetAliasIndices(ctx context.Context, alias string) ([]string, error) {
+	req := opensearchapi.IndicesGetAliasRequest{
+		Name: []string{alias},
+	}
+
+	res, err := req.Do(ctx, c.client)
+	if err != nil {
+		return nil, fmt.Errorf("get alias request failed: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if res.IsError() {
+		return nil, fmt.Errorf("get alias request failed with status: %s", res.Status())
+	}
+
+	var aliases map[string]interface{}
+	if err := json.NewDecoder(res.Body).Decode(&aliases); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	indices := make([]string, 0, len(aliases))
+	for index := range aliases {
+		indices = append(indices, index)
+	}
+	return indices, nil
+}
+
+// DeleteIndices deletes the given indices
+func (c *Client) DeleteIndices(ctx context.Context, indices []string) error {
+	req := opensearchapi.IndicesDeleteRequest{
+		Index: indices,
+	}
+	return c.do(ctx, req, "delete indices")
+}
+
+// exists executes a HEAD request, treating 404 as a negative answer
+func (c *Client) exists(ctx context.Context, req opensearchapi.Request, operation string) (bool, error) {
+	res, err := req.Do(ctx, c.client)
+	if err != nil {
+		return false, fmt.Errorf("%s request failed: %w", operation, err)
+	}
+	defer res.Body.Close()
+
+	switch {
+	case res.StatusCode == http.StatusNotFound:
+		return false, nil
+	case res.IsError():
+		return false, fmt.Errorf("%s request failed with status: %s", operation, res.Status())
+	default:
+		return true, nil
+	}
+}
+
+// do executes a request that has no response body of interest
+func (c *Client) do(ctx context.Context, req opensearchapi.Request, operation string) error {
+	res, err := req.Do(ctx, c.client)
+	if err != nil {
+		return fmt.Errorf("%s request failed: %w", operation, err)
+	}
+	defer res.Body.Close()
+
+	if res.IsError() {
+		return fmt.Errorf("%s request failed with status: %s", operation, res.Status())
+	}
+	return nil
+}
+
 // HealthCheck checks if OpenSearch is accessible
 func (c *Client) HealthCheck(ctx context.Context) error {
 	_, err := c.client.Info()
@@ -0,0 +1,123 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package opensearch
+
+import (
+	"encoding/json"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Attribute values arrive with different Go types depending on the path a span took: OTLP protobuf
+// ints, OTLP JSON numbers, collector transforms that stringify values and documents read back from
+// OpenSearch. The helpers below accept every scalar encoding of a value so that extraction does not
+// depend on the encoding.
+
+// asString returns a scalar attribute as a string, formatting numbers and booleans
+func asString(value interface{}) (string, bool) {
+	switch v := value.(type) {
+	case string:
+		return v, true
+	case json.Number:
+		return v.String(), true
+	case bool:
+		return strconv.FormatBool(v), true
+	}
+	if f, ok := numberValue(value); ok {
+		return strconv.FormatFloat(f, 'f', -1, 64), true
+	}
+	return "", false
+}
+
+// asFloat returns a numeric attribute as a float64, parsing numeric strings
+func asFloat(value interface{}) (float64, bool) {
+	if f, ok := numberValue(value); ok {
+		return f, true
+	}
+	switch v := value.(type) {
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+			return 0, false
+		}
+		return f, true
+	}
+	return 0, false
+}
+
+// asInt returns a numeric attribute as an int, truncating fractions
+func asInt(value interface{}) (int, bool) {
+	switch v := value.(type) {
+	case int:
+		return v, true
+	case int64:
+		return int(v), true
+	case string:
+		if i, err := strconv.Atoi(strings.TrimSpace(v)); err == nil {
+			return i, true
+		}
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return int(i), true
+		}
+	}
+	f, ok := asFloat(value)
+	if !ok {
+		return 0, false
+	}
+	return int(f), true
+}
+
+// asBool returns a boolean attribute, accepting "true"/"false" strings and 0/1 numbers
+func asBool(value interface{}) (bool, bool) {
+	switch v := value.(type) {
+	case bool:
+		return v, true
+	case string:
+		b, err := strconv.ParseBool(strings.TrimSpace(v))
+		return b, err == nil
+	}
+	if f, ok := asFloat(value); ok && (f == 0 || f == 1) {
+		return f == 1, true
+	}
+	return false, false
+}
+
+// numberValue converts the Go numeric types to a float64
+func numberValue(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case uint32:
+		return float64(v), true
+	}
+	return 0, false
+}
@@ -0,0 +1,165 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package opensearch
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestCoercion(t *testing.T) {
+	tests := []struct {
+		name       string
+		value      interface{}
+		wantString string
+		wantFloat  float64
+		wantInt    int
+		wantBool   bool
+		okString   bool
+		okFloat    bool
+		okInt      bool
+		okBool     bool
+	}{
+		{name: "string", value: "12", wantString: "12", wantFloat: 12, wantInt: 12, okString: true, okFloat: true, okInt: true},
+		{name: "int64", value: int64(12), wantString: "12", wantFloat: 12, wantInt: 12, okString: true, okFloat: true, okInt: true},
+		{name: "float64", value: float64(12), wantString: "12", wantFloat: 12, wantInt: 12, okString: true, okFloat: true, okInt: true},
+		{name: "json number", value: json.Number("12"), wantString: "12", wantFloat: 12, wantInt: 12, okString: true, okFloat: true, okInt: true},
+		{name: "fraction", value: "0.7", wantString: "0.7", wantFloat: 0.7, wantInt: 0, okString: true, okFloat: true, okInt: true},
+		{name: "bool", value: true, wantString: "true", wantBool: true, okString: true, okBool: true},
+		{name: "bool string", value: "false", wantString: "false", okString: true, okBool: true},
+		{name: "one", value: int64(1), wantString: "1", wantFloat: 1, wantInt: 1, wantBool: true, okString: true, okFloat: true, okInt: true, okBool: true},
+		{name: "text", value: "gpt-4o", wantString: "gpt-4o", okString: true},
+		{name: "nil", value: nil},
+		{name: "object", value: map[string]interface{}{"a": 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, ok := asString(tt.value); got != tt.wantString || ok != tt.okString {
+				t.Errorf("asString(%#v) = %q, %v, want %q, %v", tt.value, got, ok, tt.wantString, tt.okString)
+			}
+			if got, ok := asFloat(tt.value); got != tt.wantFloat || ok != tt.okFloat {
+				t.Errorf("asFloat(%#v) = %v, %v, want %v, %v", tt.value, got, ok, tt.wantFloat, tt.okFloat)
+			}
+			if got, ok := asInt(tt.value); got != tt.wantInt || ok != tt.okInt {
+				t.Errorf("asInt(%#v) = %v, %v, want %v, %v", tt.value, got, ok, tt.wantInt, tt.okInt)
+			}
+			if got, ok := asBool(tt.value); got != tt.wantBool || ok != tt.okBool {
+				t.Errorf("asBool(%#v) = %v, %v, want %v, %v", tt.value, got, ok, tt.wantBool, tt.okBool)
+			}
+		})
+	}
+}
+
+// TestExtractionAcrossEncodings feeds the same logical spans with attributes encoded as strings
+// (collector transforms), int64 (OTLP protobuf) and float64 (OTLP JSON and OpenSearch documents)
+func TestExtractionAcrossEncodings(t *testing.T) {
+	encodings := map[string]func(n int) interface{}{
+		"string":  func(n int) interface{} { return strconv.Itoa(n) },
+		"int64":   func(n int) interface{} { return int64(n) },
+		"float64": func(n int) interface{} { return float64(n) },
+	}
+
+	spans := []struct {
+		name       string
+		attributes func(encode func(n int) interface{}) map[string]interface{}
+		data       interface{}
+	}{
+		{
+			name: "llm",
+			attributes: func(encode func(n int) interface{}) map[string]interface{} {
+				return map[string]interface{}{
+					"gen_ai.operation.name":                "chat",
+					"gen_ai.request.model":                 "gpt-4o",
+					"gen_ai.usage.input_tokens":            encode(100),
+					"gen_ai.usage.output_tokens":           encode(20),
+					"gen_ai.usage.cache_read_input_tokens": encode(40),
+					"gen_ai.usage.reasoning_tokens":        encode(5),
+					"http.status_code":                     encode(200),
+				}
+			},
+			data: LLMData{Model: "gpt-4o", TokenUsage: &LLMTokenUsage{InputTokens: 100, OutputTokens: 20, CacheReadInputTokens: 40, ReasoningTokens: 5, TotalTokens: 120}},
+		},
+		{
+			name: "crewai agent",
+			attributes: func(encode func(n int) interface{}) map[string]interface{} {
+				return map[string]interface{}{
+					"gen_ai.system":           "crewai",
+					"traceloop.span.kind":     "agent",
+					"crewai.agent.role":       "Researcher",
+					"crewai.agent.max_iter":   encode(25),
+					"crewai.crew.token_usage": map[string]interface{}{"total_tokens": encode(120), "prompt_tokens": encode(100), "completion_tokens": encode(20)},
+				}
+			},
+			data: AgentData{Name: "Researcher", Framework: "crewai", SystemPrompt: "role: >\n  Researcher", MaxIter: 25, TokenUsage: &LLMTokenUsage{InputTokens: 100, OutputTokens: 20, TotalTokens: 120}},
+		},
+		{
+			name: "crewai tool",
+			attributes: func(encode func(n int) interface{}) map[string]interface{} {
+				return map[string]interface{}{
+					"gen_ai.system":          "crewai",
+					"crewai.tool.name":       "search",
+					"crewai.tool.attempts":   encode(2),
+					"crewai.tool.from_cache": encode(1),
+				}
+			},
+			data: ToolData{Name: "search", Attempts: 2, FromCache: true},
+		},
+		{
+			name: "retriever",
+			attributes: func(encode func(n int) interface{}) map[string]interface{} {
+				return map[string]interface{}{
+					"db.system":             "chroma",
+					"db.operation":          "query",
+					"db.vector.query.top_k": encode(3),
+				}
+			},
+			data: RetrieverData{VectorDB: "chroma", TopK: 3},
+		},
+	}
+
+	for _, span := range spans {
+		t.Run(span.name, func(t *testing.T) {
+			var want *AmpAttributes
+			for _, encoding := range []string{"float64", "int64", "string"} {
+				source := map[string]interface{}{
+					"traceId":    "trace",
+					"spanId":     "span",
+					"name":       span.name,
+					"attributes": span.attributes(encodings[encoding]),
+				}
+				got := parseSpan(source).AmpAttributes
+				if want == nil {
+					want = got
+					continue
+				}
+				if !reflect.DeepEqual(got, want) {
+					gotJSON, _ := json.Marshal(got)
+					wantJSON, _ := json.Marshal(want)
+					t.Errorf("%s encoding: ampAttributes = %s, want %s", encoding, gotJSON, wantJSON)
+				}
+			}
+			if !reflect.DeepEqual(want.Data, span.data) {
+				gotJSON, _ := json.Marshal(want.Data)
+				wantJSON, _ := json.Marshal(span.data)
+				t.Errorf("data = %s, want %s", gotJSON, wantJSON)
+			}
+		})
+	}
+}
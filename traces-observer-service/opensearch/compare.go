@@ -0,0 +1,354 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package opensearch
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/pricing"
+)
+
+// MaxCompareDiffBytes bounds the unified diff of the input or output of a span pair
+const MaxCompareDiffBytes = 16 * 1024
+
+// maxCompareTextBytes bounds the input or output text of a span that is diffed
+const maxCompareTextBytes = 64 * 1024
+
+// TraceCompareParams holds parameters for comparing two traces
+type TraceCompareParams struct {
+	LeftTraceID    string
+	RightTraceID   string
+	ComponentUid   string
+	EnvironmentUid string
+	StartTime      string // Optional, defaults to the last 7 days
+	EndTime        string
+}
+
+// TraceComparison aligns the spans of two traces side by side
+// Spans are paired by agent, name and occurrence, the spans without a counterpart are listed per side
+type TraceComparison struct {
+	Left      ComparedTrace   `json:"left"`
+	Right     ComparedTrace   `json:"right"`
+	Delta     ComparisonDelta `json:"delta"` // Right minus left over the whole traces
+	Pairs     []SpanPair      `json:"pairs"`
+	OnlyLeft  []ComparedSpan  `json:"onlyLeft"`
+	OnlyRight []ComparedSpan  `json:"onlyRight"`
+}
+
+// ComparedTrace summarizes one side of a comparison
+type ComparedTrace struct {
+	TraceID         string        `json:"traceId"`
+	SpanCount       int           `json:"spanCount"`
+	DurationInNanos int64         `json:"durationInNanos"`
+	TokenUsage      LLMTokenUsage `json:"tokenUsage"`
+	Cost            *float64      `json:"cost"` // USD, null when no model of the trace is priced
+}
+
+// ComparedSpan is a span of one side of a comparison
+type ComparedSpan struct {
+	SpanID          string         `json:"spanId"`
+	Name            string         `json:"name"`
+	Agent           string         `json:"agent"`      // Nearest enclosing agent, the service name outside of agents
+	Occurrence      int            `json:"occurrence"` // Position among the spans of the same agent and name, in start order
+	Kind            string         `json:"kind,omitempty"`
+	Status          string         `json:"status,omitempty"`
+	DurationInNanos int64          `json:"durationInNanos"`
+	TokenUsage      *LLMTokenUsage `json:"tokenUsage,omitempty"`
+	Model           string         `json:"model,omitempty"`
+	Cost            *float64       `json:"cost,omitempty"` // USD, absent when the span has no priced usage
+
+	input  string
+	output string
+}
+
+// SpanPair holds the spans of both traces with the same agent, name and occurrence
+type SpanPair struct {
+	Agent      string          `json:"agent"`
+	Name       string          `json:"name"`
+	Occurrence int             `json:"occurrence"`
+	Left       ComparedSpan    `json:"left"`
+	Right      ComparedSpan    `json:"right"`
+	Delta      ComparisonDelta `json:"delta"`                // Right minus left
+	InputDiff  *TextDiff       `json:"inputDiff,omitempty"`  // Absent when the inputs are equal
+	OutputDiff *TextDiff       `json:"outputDiff,omitempty"` // Absent when the outputs are equal
+}
+
+// ComparisonDelta holds the differences of the right side from the left side
+type ComparisonDelta struct {
+	DurationInNanos int64    `json:"durationInNanos"`
+	InputTokens     int      `json:"inputTokens"`
+	OutputTokens    int      `json:"outputTokens"`
+	TotalTokens     int      `json:"totalTokens"`
+	Cost            *float64 `json:"cost"` // Null unless both sides are priced
+}
+
+// TextDiff is a unified diff of the text of the left and right span
+type TextDiff struct {
+	Unified   string `json:"unified"`
+	Truncated bool   `json:"truncated,omitempty"` // Whether the diff was cut at MaxCompareDiffBytes
+}
+
+// spanAlignmentKey identifies the spans that are compared with each other
+type spanAlignmentKey struct {
+	agent      string
+	name       string
+	occurrence int
+}
+
+// CompareTraces aligns the spans of two traces and computes the differences of each pair
+// Spans are numbered per agent and name in start order, so that reordered spans still find their counterpart
+// and repeated spans are paired in the order they ran
+func CompareTraces(leftTraceID string, left []Span, rightTraceID string, right []Span, table *pricing.Table) *TraceComparison {
+	leftSpans := compareSpans(left, table)
+	rightSpans := compareSpans(right, table)
+
+	comparison := &TraceComparison{
+		Left:      summarizeComparedTrace(leftTraceID, left, table),
+		Right:     summarizeComparedTrace(rightTraceID, right, table),
+		Pairs:     []SpanPair{},
+		OnlyLeft:  []ComparedSpan{},
+		OnlyRight: []ComparedSpan{},
+	}
+	comparison.Delta = comparisonDelta(
+		comparison.Left.DurationInNanos, &comparison.Left.TokenUsage, comparison.Left.Cost,
+		comparison.Right.DurationInNanos, &comparison.Right.TokenUsage, comparison.Right.Cost,
+	)
+
+	rightByKey := make(map[spanAlignmentKey]int, len(rightSpans))
+	for i, span := range rightSpans {
+		rightByKey[alignmentKey(span)] = i
+	}
+	paired := make([]bool, len(rightSpans))
+	for _, leftSpan := range leftSpans {
+		i, ok := rightByKey[alignmentKey(leftSpan)]
+		if !ok {
+			comparison.OnlyLeft = append(comparison.OnlyLeft, leftSpan)
+			continue
+		}
+		paired[i] = true
+		comparison.Pairs = append(comparison.Pairs, newSpanPair(leftSpan, rightSpans[i]))
+	}
+	for i, rightSpan := range rightSpans {
+		if !paired[i] {
+			comparison.OnlyRight = append(comparison.OnlyRight, rightSpan)
+		}
+	}
+	return comparison
+}
+
+// alignmentKey returns the key a span is paired on
+func alignmentKey(span ComparedSpan) spanAlignmentKey {
+	return spanAlignmentKey{agent: span.Agent, name: span.Name, occurrence: span.Occurrence}
+}
+
+// newSpanPair builds the pair of two aligned spans
+func newSpanPair(left, right ComparedSpan) SpanPair {
+	return SpanPair{
+		Agent:      left.Agent,
+		Name:       left.Name,
+		Occurrence: left.Occurrence,
+		Left:       left,
+		Right:      right,
+		Delta:      comparisonDelta(left.DurationInNanos, left.TokenUsage, left.Cost, right.DurationInNanos, right.TokenUsage, right.Cost),
+		InputDiff:  diffText(left.input, right.input),
+		OutputDiff: diffText(left.output, right.output),
+	}
+}
+
+// comparisonDelta computes right minus left, missing token usage counts as zero
+func comparisonDelta(leftDuration int64, leftUsage *LLMTokenUsage, leftCost *float64, rightDuration int64, rightUsage *LLMTokenUsage, rightCost *float64) ComparisonDelta {
+	delta := ComparisonDelta{DurationInNanos: rightDuration - leftDuration}
+	if leftUsage != nil {
+		delta.InputTokens -= leftUsage.InputTokens
+		delta.OutputTokens -= leftUsage.OutputTokens
+		delta.TotalTokens -= leftUsage.TotalTokens
+	}
+	if rightUsage != nil {
+		delta.InputTokens += rightUsage.InputTokens
+		delta.OutputTokens += rightUsage.OutputTokens
+		delta.TotalTokens += rightUsage.TotalTokens
+	}
+	if leftCost != nil && rightCost != nil {
+		cost := *rightCost - *leftCost
+		delta.Cost = &cost
+	}
+	return delta
+}
+
+// summarizeComparedTrace computes the duration, token usage and cost of a trace
+func summarizeComparedTrace(traceID string, spans []Span, table *pricing.Table) ComparedTrace {
+	summary := ComparedTrace{TraceID: traceID, SpanCount: len(spans)}
+	if len(spans) == 0 {
+		return summary
+	}
+
+	// The duration covers every span, so that traces without a single root are measured alike
+	start, end := spanInterval(&spans[0]).start, spanInterval(&spans[0]).end
+	for i := range spans[1:] {
+		value := spanInterval(&spans[i+1])
+		if value.start.Before(start) {
+			start = value.start
+		}
+		if value.end.After(end) {
+			end = value.end
+		}
+	}
+	summary.DurationInNanos = end.Sub(start).Nanoseconds()
+
+	if usage := AggregateTraceTokenUsage(spans); usage != nil {
+		usage.ApplyPricing(table)
+		summary.TokenUsage = usage.LLMTokenUsage
+		if usage.Cost != nil {
+			cost := usage.Cost.TotalCost
+			summary.Cost = &cost
+		}
+	}
+	return summary
+}
+
+// compareSpans converts the spans of a trace in start order, numbering the spans of each agent and name
+func compareSpans(spans []Span, table *pricing.Table) []ComparedSpan {
+	ordered, byID := spansInStartOrder(spans)
+
+	agents := make(map[string]string, len(spans))
+	occurrences := make(map[spanAlignmentKey]int)
+	compared := make([]ComparedSpan, 0, len(ordered))
+	for _, span := range ordered {
+		agent := spanAgent(span, byID, agents)
+		key := spanAlignmentKey{agent: agent, name: span.Name}
+		occurrence := occurrences[key]
+		occurrences[key]++
+
+		value := ComparedSpan{
+			SpanID:          span.SpanID,
+			Name:            span.Name,
+			Agent:           agent,
+			Occurrence:      occurrence,
+			Kind:            spanKind(span),
+			Status:          span.Status,
+			DurationInNanos: span.DurationInNanos,
+		}
+		if span.AmpAttributes != nil {
+			value.input = compareText(span.AmpAttributes.Input)
+			value.output = compareText(span.AmpAttributes.Output)
+		}
+		if llmData, ok := llmDataOf(*span); ok && llmData.TokenUsage != nil {
+			usage := *llmData.TokenUsage
+			value.TokenUsage = &usage
+			value.Model = llmData.Model
+			if cost := table.Calculate(llmData.Model, pricing.Usage{
+				InputTokens:           usage.InputTokens,
+				OutputTokens:          usage.OutputTokens,
+				CacheReadInputTokens:  usage.CacheReadInputTokens,
+				CacheWriteInputTokens: usage.CacheWriteInputTokens,
+			}); cost != nil {
+				total := cost.TotalCost
+				value.Cost = &total
+			}
+		}
+		compared = append(compared, value)
+	}
+	return compared
+}
+
+// spansInStartOrder returns the spans ordered by start time and span ID, and the spans by ID
+func spansInStartOrder(spans []Span) ([]*Span, map[string]*Span) {
+	ordered := make([]*Span, len(spans))
+	byID := make(map[string]*Span, len(spans))
+	for i := range spans {
+		ordered[i] = &spans[i]
+		byID[spans[i].SpanID] = &spans[i]
+	}
+	sort.SliceStable(ordered, func(i, j int) bool {
+		if !ordered[i].StartTime.Equal(ordered[j].StartTime) {
+			return ordered[i].StartTime.Before(ordered[j].StartTime)
+		}
+		return ordered[i].SpanID < ordered[j].SpanID
+	})
+	return ordered, byID
+}
+
+// spanAgent returns the name of the nearest agent span enclosing a span, or the span itself when it is an agent
+// Spans outside of any agent belong to their service, resolved names are memoized per span ID
+func spanAgent(span *Span, byID map[string]*Span, agents map[string]string) string {
+	if agent, ok := agents[span.SpanID]; ok {
+		return agent
+	}
+
+	var path []*Span
+	agent := ""
+	visited := make(map[string]bool)
+	for current := span; current != nil && !visited[current.SpanID]; current = byID[current.ParentSpanID] {
+		if known, ok := agents[current.SpanID]; ok {
+			agent = known
+			break
+		}
+		visited[current.SpanID] = true
+		path = append(path, current)
+		if spanKind(current) == string(SpanTypeAgent) {
+			agent = current.Name
+			if agentData, ok := current.AmpAttributes.Data.(AgentData); ok && agentData.Name != "" {
+				agent = agentData.Name
+			}
+			break
+		}
+	}
+	if agent == "" {
+		agent = span.Service
+	}
+	for _, s := range path {
+		agents[s.SpanID] = agent
+	}
+	return agent
+}
+
+// compareText renders the input or output of a span as text, structured values as indented JSON
+func compareText(value interface{}) string {
+	var text string
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		text = v
+	default:
+		encoded, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return ""
+		}
+		text = string(encoded)
+	}
+	return truncateUTF8(text, maxCompareTextBytes)
+}
+
+// diffText returns the unified diff of two texts, nil when they are equal
+func diffText(left, right string) *TextDiff {
+	if left == right {
+		return nil
+	}
+	unified := UnifiedDiff(left, right, "left", "right")
+	diff := &TextDiff{Unified: unified}
+	if len(unified) > MaxCompareDiffBytes {
+		diff.Unified = truncateUTF8(unified, MaxCompareDiffBytes)
+		if cut := strings.LastIndexByte(diff.Unified, '\n'); cut >= 0 {
+			diff.Unified = diff.Unified[:cut+1]
+		}
+		diff.Truncated = true
+	}
+	return diff
+}
@@ -0,0 +1,208 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package opensearch
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// compareTestSpan describes a span of a test trace starting the given seconds into the trace
+type compareTestSpan struct {
+	id     string
+	parent string
+	name   string
+	kind   SpanType
+	start  int
+	millis int64
+	tokens int
+	output string
+}
+
+// buildCompareTestTrace builds the spans of a test trace
+func buildCompareTestTrace(traceID string, specs []compareTestSpan) []Span {
+	base := time.Date(2025, 11, 3, 10, 0, 0, 0, time.UTC)
+	spans := make([]Span, 0, len(specs))
+	for _, spec := range specs {
+		span := Span{
+			TraceID:         traceID,
+			SpanID:          spec.id,
+			ParentSpanID:    spec.parent,
+			Name:            spec.name,
+			Service:         "support-service",
+			StartTime:       base.Add(time.Duration(spec.start) * time.Second),
+			DurationInNanos: spec.millis * int64(time.Millisecond),
+			AmpAttributes:   &AmpAttributes{Kind: string(spec.kind), Output: spec.output},
+		}
+		span.EndTime = span.StartTime.Add(time.Duration(span.DurationInNanos))
+		switch spec.kind {
+		case SpanTypeAgent:
+			span.AmpAttributes.Data = AgentData{Name: spec.name}
+		case SpanTypeLLM:
+			span.AmpAttributes.Data = LLMData{Model: "unpriced-model", TokenUsage: &LLMTokenUsage{
+				InputTokens: spec.tokens, TotalTokens: spec.tokens,
+			}}
+		}
+		spans = append(spans, span)
+	}
+	return spans
+}
+
+// pairKeys renders the pairs of a comparison as agent/name#occurrence=left:right
+func pairKeys(comparison *TraceComparison) []string {
+	keys := make([]string, 0, len(comparison.Pairs))
+	for _, pair := range comparison.Pairs {
+		keys = append(keys, pair.Agent+"/"+pair.Name+"#"+string(rune('0'+pair.Occurrence))+"="+pair.Left.SpanID+":"+pair.Right.SpanID)
+	}
+	return keys
+}
+
+func TestCompareTracesReorderedSpans(t *testing.T) {
+	left := buildCompareTestTrace("left", []compareTestSpan{
+		{id: "a", name: "planner", kind: SpanTypeAgent, start: 0, millis: 9000},
+		{id: "l1", parent: "a", name: "search", kind: SpanTypeTool, start: 1, millis: 500},
+		{id: "l2", parent: "a", name: "summarize", kind: SpanTypeLLM, start: 2, millis: 1500, tokens: 100},
+	})
+	right := buildCompareTestTrace("right", []compareTestSpan{
+		{id: "b", name: "planner", kind: SpanTypeAgent, start: 0, millis: 7000},
+		{id: "r1", parent: "b", name: "summarize", kind: SpanTypeLLM, start: 1, millis: 1000, tokens: 140},
+		{id: "r2", parent: "b", name: "search", kind: SpanTypeTool, start: 3, millis: 800},
+	})
+
+	comparison := CompareTraces("left", left, "right", right, nil)
+
+	want := []string{"planner/planner#0=a:b", "planner/search#0=l1:r2", "planner/summarize#0=l2:r1"}
+	if got := pairKeys(comparison); strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("pairs = %v, want %v", got, want)
+	}
+	if len(comparison.OnlyLeft) != 0 || len(comparison.OnlyRight) != 0 {
+		t.Errorf("unmatched = %v / %v, want none", comparison.OnlyLeft, comparison.OnlyRight)
+	}
+	summarize := comparison.Pairs[2].Delta
+	if summarize.DurationInNanos != -500*int64(time.Millisecond) || summarize.InputTokens != 40 || summarize.TotalTokens != 40 {
+		t.Errorf("summarize delta = %+v", summarize)
+	}
+	if summarize.Cost != nil {
+		t.Errorf("cost delta = %v, want null for unpriced models", *summarize.Cost)
+	}
+	if comparison.Delta.DurationInNanos != -2*int64(time.Second) || comparison.Delta.TotalTokens != 40 {
+		t.Errorf("trace delta = %+v", comparison.Delta)
+	}
+}
+
+func TestCompareTracesRepeatedSpans(t *testing.T) {
+	left := buildCompareTestTrace("left", []compareTestSpan{
+		{id: "a", name: "researcher", kind: SpanTypeAgent, start: 0, millis: 9000},
+		{id: "l1", parent: "a", name: "chat", kind: SpanTypeLLM, start: 1, tokens: 10},
+		{id: "l2", parent: "a", name: "chat", kind: SpanTypeLLM, start: 2, tokens: 20},
+		{id: "l3", parent: "a", name: "chat", kind: SpanTypeLLM, start: 3, tokens: 30},
+		{id: "w", name: "writer", kind: SpanTypeAgent, start: 4, millis: 1000},
+		{id: "l4", parent: "w", name: "chat", kind: SpanTypeLLM, start: 5, tokens: 40},
+	})
+	right := buildCompareTestTrace("right", []compareTestSpan{
+		{id: "b", name: "researcher", kind: SpanTypeAgent, start: 0, millis: 9000},
+		{id: "r1", parent: "b", name: "chat", kind: SpanTypeLLM, start: 1, tokens: 10},
+		{id: "r2", parent: "b", name: "chat", kind: SpanTypeLLM, start: 2, tokens: 25},
+		{id: "x", name: "reviewer", kind: SpanTypeAgent, start: 4, millis: 1000},
+		{id: "r3", parent: "x", name: "chat", kind: SpanTypeLLM, start: 5, tokens: 40},
+	})
+
+	comparison := CompareTraces("left", left, "right", right, nil)
+
+	// Repeated spans pair up in start order, spans of different agents never pair
+	want := []string{"researcher/researcher#0=a:b", "researcher/chat#0=l1:r1", "researcher/chat#1=l2:r2"}
+	if got := pairKeys(comparison); strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("pairs = %v, want %v", got, want)
+	}
+	onlyLeft := []string{}
+	for _, span := range comparison.OnlyLeft {
+		onlyLeft = append(onlyLeft, span.SpanID)
+	}
+	if strings.Join(onlyLeft, ",") != "l3,w,l4" {
+		t.Errorf("onlyLeft = %v, want [l3 w l4]", onlyLeft)
+	}
+	onlyRight := []string{}
+	for _, span := range comparison.OnlyRight {
+		onlyRight = append(onlyRight, span.Agent+"/"+span.SpanID)
+	}
+	if strings.Join(onlyRight, ",") != "reviewer/x,reviewer/r3" {
+		t.Errorf("onlyRight = %v, want [reviewer/x reviewer/r3]", onlyRight)
+	}
+}
+
+func TestCompareTracesOutputDiff(t *testing.T) {
+	left := buildCompareTestTrace("left", []compareTestSpan{
+		{id: "l1", name: "answer", kind: SpanTypeLLM, output: "Hello\nYour order shipped\nBye\n"},
+	})
+	right := buildCompareTestTrace("right", []compareTestSpan{
+		{id: "r1", name: "answer", kind: SpanTypeLLM, output: "Hello\nYour order is delayed\nBye\n"},
+	})
+
+	comparison := CompareTraces("left", left, "right", right, nil)
+	if len(comparison.Pairs) != 1 {
+		t.Fatalf("pairs = %d, want 1", len(comparison.Pairs))
+	}
+	pair := comparison.Pairs[0]
+	if pair.Agent != "support-service" {
+		t.Errorf("agent = %q, want the service outside of agents", pair.Agent)
+	}
+	if pair.InputDiff != nil {
+		t.Errorf("inputDiff = %+v, want none for equal inputs", pair.InputDiff)
+	}
+	want := "--- left\n+++ right\n@@ -1,3 +1,3 @@\n Hello\n-Your order shipped\n+Your order is delayed\n Bye\n"
+	if pair.OutputDiff == nil || pair.OutputDiff.Unified != want {
+		t.Errorf("outputDiff = %+v, want %q", pair.OutputDiff, want)
+	}
+}
+
+func TestUnifiedDiffHunks(t *testing.T) {
+	var left, right []string
+	for i := 0; i < 20; i++ {
+		line := string(rune('a' + i))
+		left = append(left, line)
+		switch i {
+		case 1:
+			right = append(right, "B")
+		case 15:
+		default:
+			right = append(right, line)
+		}
+	}
+	right = append(right, "z")
+
+	got := UnifiedDiff(strings.Join(left, "\n"), strings.Join(right, "\n"), "left", "right")
+	want := "--- left\n+++ right\n" +
+		"@@ -1,5 +1,5 @@\n a\n-b\n+B\n c\n d\n e\n" +
+		"@@ -13,8 +13,8 @@\n m\n n\n o\n-p\n q\n r\n s\n t\n+z\n"
+	if got != want {
+		t.Errorf("UnifiedDiff() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestDiffTextTruncated(t *testing.T) {
+	left := strings.Repeat("same line\n", 10)
+	right := strings.Repeat("changed line that is long enough\n", MaxCompareDiffBytes/16)
+
+	diff := diffText(left, right)
+	if diff == nil || !diff.Truncated {
+		t.Fatalf("diffText() = %+v, want a truncated diff", diff)
+	}
+	if len(diff.Unified) > MaxCompareDiffBytes || !strings.HasSuffix(diff.Unified, "\n") {
+		t.Errorf("truncated diff is %d bytes, want at most %d ending on a whole line", len(diff.Unified), MaxCompareDiffBytes)
+	}
+}
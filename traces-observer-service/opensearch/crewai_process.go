@@ -17,10 +17,48 @@
 package opensearch
 
 import (
+	"encoding/json"
 	"fmt"
 	"strings"
 )
 
+// FrameworkCrewAI is the name the CrewAI processor is registered under
+const FrameworkCrewAI = "crewai"
+
+// CrewAIProcessor populates the attributes of spans emitted by CrewAI crews and flows
+type CrewAIProcessor struct{}
+
+// Detect reports whether the span attributes were emitted by CrewAI
+func (CrewAIProcessor) Detect(attrs map[string]interface{}) bool {
+	return IsCrewAISpan(attrs)
+}
+
+// Populate fills the CrewAI-specific attributes of tool, agent, task and chain spans
+// Other span types are populated from the standard semantic conventions
+func (CrewAIProcessor) Populate(ampAttrs *AmpAttributes, span *Span) {
+	switch SpanType(ampAttrs.Kind) {
+	case SpanTypeTool:
+		if !isCrewAIToolSpan(span.Attributes) {
+			PopulateStandardAttributes(ampAttrs, span)
+			return
+		}
+		PopulateCrewAIToolAttributes(ampAttrs, span.Attributes)
+	case SpanTypeAgent:
+		PopulateCrewAIAgentAttributes(ampAttrs, span.Attributes)
+	case SpanTypeCrewAITask:
+		populateCrewAITaskAttributes(ampAttrs, span.Attributes)
+	case SpanTypeChain:
+		// Flow methods carry flow-specific data such as the routing decision
+		if isCrewAIFlowMethodSpan(span.Attributes) {
+			PopulateCrewAIFlowAttributes(ampAttrs, span.Attributes)
+			return
+		}
+		ampAttrs.Input, ampAttrs.Output = ExtractCrewAISpanInputOutput(span.Attributes)
+	default:
+		PopulateStandardAttributes(ampAttrs, span)
+	}
+}
+
 // IsCrewAISpan checks if a span is from CrewAI framework
 // It verifies both gen_ai.system == "crewai" and the presence of crewai.* attributes
 func IsCrewAISpan(attrs map[string]interface{}) bool {
@@ -29,7 +67,7 @@ func IsCrewAISpan(attrs map[string]interface{}) bool {
 	}
 
 	// Check if gen_ai.system is "crewai"
-	if val, ok := attrs["gen_ai.system"].(string); ok && strings.ToLower(val) == "crewai" {
+	if val, ok := asString(attrs["gen_ai.system"]); ok && strings.ToLower(val) == "crewai" {
 		return true
 	}
 
@@ -43,10 +81,31 @@ func IsCrewAISpan(attrs map[string]interface{}) bool {
 	return false
 }
 
+// isCrewAIFlowSpan checks if a span is emitted by a CrewAI Flow (crewai.flow.* attributes)
+func isCrewAIFlowSpan(attrs map[string]interface{}) bool {
+	for key := range attrs {
+		if strings.HasPrefix(key, "crewai.flow.") {
+			return true
+		}
+	}
+	return false
+}
+
+// isCrewAIFlowMethodSpan checks if a CrewAI Flow span represents a single flow method
+// (a @start, @listen or @router decorated method) rather than the flow itself
+func isCrewAIFlowMethodSpan(attrs map[string]interface{}) bool {
+	if !isCrewAIFlowSpan(attrs) {
+		return false
+	}
+	_, ok := asString(attrs["crewai.flow.method_name"])
+	return ok
+}
+
 // ExtractCrewAISpanInputOutput extracts input and output from CrewAI span attributes
-// This is a generic method that works for any CrewAI span (workflow, task, or agent)
+// This is a generic method that works for any CrewAI span (workflow, task, agent or flow)
 // Input: crewai.crew.tasks_output - contains task outputs
 // Output: crewai.crew.result - contains the result
+// Flow spans are delegated to extractCrewAIFlowInputOutput
 // Returns nil when attributes are not found
 func ExtractCrewAISpanInputOutput(attrs map[string]interface{}) (input interface{}, output interface{}) {
 	// Return nil if no attributes
@@ -54,16 +113,21 @@ func ExtractCrewAISpanInputOutput(attrs map[string]interface{}) (input interface
 		return nil, nil
 	}
 
+	// CrewAI Flows do not carry crew attributes
+	if isCrewAIFlowSpan(attrs) {
+		return extractCrewAIFlowInputOutput(attrs)
+	}
+
 	// Extract input from crewai.crew.tasks_output
 	if tasksVal, ok := attrs["crewai.crew.tasks_output"]; ok {
-		if tasksStr, ok := tasksVal.(string); ok {
+		if tasksStr, ok := asString(tasksVal); ok {
 			input = tasksStr
 		}
 	}
 
 	// Extract output from crewai.crew.result
 	if resultVal, ok := attrs["crewai.crew.result"]; ok {
-		if resultStr, ok := resultVal.(string); ok {
+		if resultStr, ok := asString(resultVal); ok {
 			output = resultStr
 		}
 	}
@@ -96,11 +160,14 @@ func PopulateCrewAIAgentAttributes(ampAttrs *AmpAttributes, attrs map[string]int
 
 	// Extract workflow/agent name
 	// First try crewai.agent.role (for individual agent spans)
-	if name, ok := attrs["crewai.agent.role"].(string); ok {
+	if name, ok := asString(attrs["crewai.agent.role"]); ok {
 		agentData.Name = strings.TrimSpace(name)
-	} else if name, ok := attrs["crewai.crew.name"].(string); ok {
+	} else if name, ok := asString(attrs["crewai.crew.name"]); ok {
 		// Fallback to crewai.crew.name (for crew/workflow spans)
 		agentData.Name = name
+	} else if name, ok := asString(attrs["crewai.flow.name"]); ok {
+		// Fallback to crewai.flow.name (for flow spans)
+		agentData.Name = name
 	}
 
 	// Extract tools from crewai.agent.tools (for agent spans)
@@ -110,26 +177,197 @@ func PopulateCrewAIAgentAttributes(ampAttrs *AmpAttributes, attrs map[string]int
 	agentData.SystemPrompt = extractCrewAISystemPrompt(attrs)
 
 	// Extract max iterations from crewai.agent.max_iter
-	if maxIter, ok := attrs["crewai.agent.max_iter"].(float64); ok {
-		agentData.MaxIter = int(maxIter)
+	if maxIter, ok := asInt(attrs["crewai.agent.max_iter"]); ok {
+		agentData.MaxIter = maxIter
 	}
 
 	// Extract token usage from crewai.crew.token_usage
-	// Format: "total_tokens=57062 prompt_tokens=46376 cached_prompt_tokens=0 completion_tokens=10686 successful_requests=10"
-	if tokenUsageStr, ok := attrs["crewai.crew.token_usage"].(string); ok {
-		agentData.TokenUsage = parseCrewAITokenUsage(tokenUsageStr)
+	// The attribute may already be decoded into an object when stored as JSON
+	switch tokenUsage := attrs["crewai.crew.token_usage"].(type) {
+	case string:
+		agentData.TokenUsage = parseCrewAITokenUsage(tokenUsage)
+	case map[string]interface{}:
+		if tokenUsageJSON, err := json.Marshal(tokenUsage); err == nil {
+			agentData.TokenUsage = parseCrewAITokenUsage(string(tokenUsageJSON))
+		}
 	}
 
 	ampAttrs.Data = agentData
 }
 
+// PopulateCrewAIToolAttributes extracts and populates CrewAI tool invocation attributes
+// Input: crewai.tool.args - parsed into an object when it holds JSON
+// Output: crewai.tool.result - contains the tool result
+func PopulateCrewAIToolAttributes(ampAttrs *AmpAttributes, attrs map[string]interface{}) {
+	// Extract tool arguments
+	if args, ok := asString(attrs["crewai.tool.args"]); ok && args != "" {
+		var parsedArgs map[string]interface{}
+		if err := unmarshalAttribute(args, &parsedArgs); err == nil {
+			ampAttrs.Input = parsedArgs
+		} else {
+			ampAttrs.Input = args // Not valid JSON, use as-is
+		}
+	} else if args, ok := attrs["crewai.tool.args"].(map[string]interface{}); ok {
+		ampAttrs.Input = args
+	}
+
+	// Extract tool result
+	if result, ok := asString(attrs["crewai.tool.result"]); ok {
+		ampAttrs.Output = result
+	}
+
+	toolData := ToolData{}
+
+	if name, ok := asString(attrs["crewai.tool.name"]); ok {
+		toolData.Name = strings.TrimSpace(name)
+	}
+
+	// Link delegation tool calls to the coworker that received the work
+	if isCrewAIDelegationTool(toolData.Name) {
+		toolData.Delegatee = extractCrewAIDelegatee(ampAttrs.Input)
+	}
+
+	// Extract the number of attempts made by the agent
+	if attempts, ok := asInt(attrs["crewai.tool.attempts"]); ok {
+		toolData.Attempts = attempts
+	}
+
+	// Extract whether the result was served from the CrewAI tool cache
+	if fromCache, ok := asBool(attrs["crewai.tool.from_cache"]); ok {
+		toolData.FromCache = fromCache
+	}
+
+	ampAttrs.Data = toolData
+}
+
+// isCrewAIDelegationTool checks if a tool is one of the CrewAI agent delegation tools
+func isCrewAIDelegationTool(name string) bool {
+	switch strings.ToLower(name) {
+	case "delegate work to coworker", "ask question to coworker":
+		return true
+	default:
+		return false
+	}
+}
+
+// extractCrewAIDelegatee extracts the coworker role from delegation tool arguments
+func extractCrewAIDelegatee(args interface{}) string {
+	argsMap, ok := args.(map[string]interface{})
+	if !ok {
+		return ""
+	}
+
+	if coworker, ok := argsMap["coworker"].(string); ok {
+		return strings.TrimSpace(coworker)
+	}
+
+	return ""
+}
+
+// AttributeCrewAIManagerAgents names the manager agent of hierarchical crews
+// Manager spans have no crewai.agent.role, so they are detected by a parent span with
+// crewai.crew.process == "hierarchical" and named after crewai.crew.manager_agent or "Crew Manager"
+func AttributeCrewAIManagerAgents(spans []Span) {
+	spansByID := make(map[string]*Span, len(spans))
+	for i := range spans {
+		spansByID[spans[i].SpanID] = &spans[i]
+	}
+
+	for i := range spans {
+		span := &spans[i]
+		if span.AmpAttributes == nil {
+			continue
+		}
+
+		agentData, ok := span.AmpAttributes.Data.(AgentData)
+		if !ok || agentData.Framework != "crewai" || agentData.Name != "" {
+			continue
+		}
+		if _, hasRole := span.Attributes["crewai.agent.role"]; hasRole {
+			continue
+		}
+
+		parent, ok := spansByID[span.ParentSpanID]
+		if !ok || parent.Attributes == nil {
+			continue
+		}
+		if process, ok := asString(parent.Attributes["crewai.crew.process"]); !ok || strings.ToLower(process) != "hierarchical" {
+			continue
+		}
+
+		agentData.Name = "Crew Manager"
+		if managerAgent, ok := asString(parent.Attributes["crewai.crew.manager_agent"]); ok && strings.TrimSpace(managerAgent) != "" {
+			agentData.Name = strings.TrimSpace(managerAgent)
+		}
+		span.AmpAttributes.Data = agentData
+	}
+}
+
+// isCrewAIToolSpan checks if a CrewAI span represents a tool invocation
+func isCrewAIToolSpan(attrs map[string]interface{}) bool {
+	_, ok := asString(attrs["crewai.tool.name"])
+	return ok
+}
+
+// PopulateCrewAIFlowAttributes extracts and populates CrewAI Flow method attributes
+// Router methods carry the route they returned so the taken branch can be shown
+func PopulateCrewAIFlowAttributes(ampAttrs *AmpAttributes, attrs map[string]interface{}) {
+	ampAttrs.Input, ampAttrs.Output = extractCrewAIFlowInputOutput(attrs)
+
+	flowData := CrewAIFlowData{}
+
+	if name, ok := asString(attrs["crewai.flow.name"]); ok {
+		flowData.Name = name
+	}
+	if methodName, ok := asString(attrs["crewai.flow.method_name"]); ok {
+		flowData.MethodName = methodName
+	}
+	if methodType, ok := asString(attrs["crewai.flow.method_type"]); ok {
+		flowData.MethodType = strings.ToLower(methodType)
+	}
+
+	// Extract the methods/routes a listener was triggered by
+	if trigger, ok := asString(attrs["crewai.flow.method.trigger"]); ok {
+		flowData.Trigger = trigger
+	}
+
+	// Extract the routing decision
+	// Explicit route attribute first, then the return value of a router method
+	if route, ok := asString(attrs["crewai.flow.router.route"]); ok {
+		flowData.Route = route
+	} else if flowData.MethodType == "router" {
+		if result, ok := asString(attrs["crewai.flow.method.result"]); ok {
+			flowData.Route = result
+		}
+	}
+
+	ampAttrs.Data = flowData
+}
+
+// extractCrewAIFlowInputOutput extracts input and output from CrewAI Flow span attributes
+// Input: crewai.flow.inputs - contains the kickoff inputs
+// Output: crewai.flow.state - contains the flow state, falling back to crewai.flow.method.result
+func extractCrewAIFlowInputOutput(attrs map[string]interface{}) (input interface{}, output interface{}) {
+	if inputsVal, ok := asString(attrs["crewai.flow.inputs"]); ok {
+		input = inputsVal
+	}
+
+	if stateVal, ok := asString(attrs["crewai.flow.state"]); ok {
+		output = stateVal
+	} else if resultVal, ok := asString(attrs["crewai.flow.method.result"]); ok {
+		output = resultVal
+	}
+
+	return input, output
+}
+
 // extractCrewAIAgentTools extracts tool definitions from crewai.agent.tools attribute
 // Uses the common parseToolsJSON method to handle multiple formats:
 // - JSON array of tool names: ["tool1", "tool2"]
 // - JSON array of tool objects: [{"name": "tool1", "description": "...", "parameters": "..."}]
 // Returns array of ToolDefinition objects
 func extractCrewAIAgentTools(attrs map[string]interface{}) []ToolDefinition {
-	toolsJSON, ok := attrs["crewai.agent.tools"].(string)
+	toolsJSON, ok := asString(attrs["crewai.agent.tools"])
 	if !ok || toolsJSON == "" {
 		return nil
 	}
@@ -144,17 +382,17 @@ func extractCrewAISystemPrompt(attrs map[string]interface{}) string {
 	var role, goal, backstory string
 
 	// Extract role
-	if r, ok := attrs["crewai.agent.role"].(string); ok {
+	if r, ok := asString(attrs["crewai.agent.role"]); ok {
 		role = strings.TrimSpace(r)
 	}
 
 	// Extract goal
-	if g, ok := attrs["crewai.agent.goal"].(string); ok {
+	if g, ok := asString(attrs["crewai.agent.goal"]); ok {
 		goal = strings.TrimSpace(g)
 	}
 
 	// Extract backstory
-	if b, ok := attrs["crewai.agent.backstory"].(string); ok {
+	if b, ok := asString(attrs["crewai.agent.backstory"]); ok {
 		backstory = strings.TrimSpace(b)
 	}
 
@@ -187,40 +425,54 @@ func extractCrewAISystemPrompt(attrs map[string]interface{}) string {
 }
 
 // parseCrewAITokenUsage parses CrewAI token usage string into LLMTokenUsage struct
-// Format: "total_tokens=57062 prompt_tokens=46376 cached_prompt_tokens=0 completion_tokens=10686 successful_requests=10"
+// Supported formats:
+// - JSON object (newer CrewAI versions): {"total_tokens": 57062, "prompt_tokens": 46376, ...}
+// - Python repr: "UsageMetrics(total_tokens=57062, prompt_tokens=46376, ...)"
+// - Key/value pairs: "total_tokens=57062 prompt_tokens=46376 cached_prompt_tokens=0 completion_tokens=10686 successful_requests=10"
 func parseCrewAITokenUsage(tokenUsageStr string) *LLMTokenUsage {
+	tokenUsageStr = strings.TrimSpace(tokenUsageStr)
 	if tokenUsageStr == "" {
 		return nil
 	}
 
 	usage := &LLMTokenUsage{}
 
-	// Split by space and parse key=value pairs
-	pairs := strings.Fields(tokenUsageStr)
-	for _, pair := range pairs {
-		parts := strings.SplitN(pair, "=", 2)
-		if len(parts) != 2 {
-			continue
+	if strings.HasPrefix(tokenUsageStr, "{") {
+		// Parse JSON object
+		var values map[string]interface{}
+		if err := unmarshalAttribute(tokenUsageStr, &values); err != nil {
+			return nil
+		}
+		for key, value := range values {
+			if numValue, ok := asInt(value); ok {
+				setCrewAITokenUsageField(usage, key, numValue)
+			}
+		}
+	} else {
+		// Strip the Python repr wrapper, e.g. "UsageMetrics(...)"
+		if open := strings.Index(tokenUsageStr, "("); open >= 0 && strings.HasSuffix(tokenUsageStr, ")") {
+			tokenUsageStr = tokenUsageStr[open+1 : len(tokenUsageStr)-1]
+			tokenUsageStr = strings.ReplaceAll(tokenUsageStr, ",", " ")
 		}
 
-		key := parts[0]
-		value := parts[1]
+		// Split by space and parse key=value pairs
+		pairs := strings.Fields(tokenUsageStr)
+		for _, pair := range pairs {
+			parts := strings.SplitN(pair, "=", 2)
+			if len(parts) != 2 {
+				continue
+			}
 
-		// Parse numeric values
-		var numValue int
-		if _, err := fmt.Sscanf(value, "%d", &numValue); err != nil {
-			continue
-		}
+			key := parts[0]
+			value := parts[1]
+
+			// Parse numeric values
+			var numValue int
+			if _, err := fmt.Sscanf(value, "%d", &numValue); err != nil {
+				continue
+			}
 
-		switch key {
-		case "total_tokens":
-			usage.TotalTokens = numValue
-		case "prompt_tokens":
-			usage.InputTokens = numValue
-		case "completion_tokens":
-			usage.OutputTokens = numValue
-		case "cached_prompt_tokens":
-			usage.CacheReadInputTokens = numValue
+			setCrewAITokenUsageField(usage, key, numValue)
 		}
 	}
 
@@ -231,3 +483,17 @@ func parseCrewAITokenUsage(tokenUsageStr string) *LLMTokenUsage {
 
 	return nil
 }
+
+// setCrewAITokenUsageField maps a CrewAI usage metric key onto the LLMTokenUsage struct
+func setCrewAITokenUsageField(usage *LLMTokenUsage, key string, value int) {
+	switch key {
+	case "total_tokens":
+		usage.TotalTokens = value
+	case "prompt_tokens":
+		usage.InputTokens = value
+	case "completion_tokens":
+		usage.OutputTokens = value
+	case "cached_prompt_tokens":
+		usage.CacheReadInputTokens = value
+	}
+}
@@ -0,0 +1,299 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package opensearch
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseCrewAITokenUsage(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected *LLMTokenUsage
+	}{
+		{
+			name:  "key value pairs",
+			input: "total_tokens=57062 prompt_tokens=46376 cached_prompt_tokens=12 completion_tokens=10686 successful_requests=10",
+			expected: &LLMTokenUsage{
+				InputTokens:          46376,
+				OutputTokens:         10686,
+				CacheReadInputTokens: 12,
+				TotalTokens:          57062,
+			},
+		},
+		{
+			name:  "json object",
+			input: `{"total_tokens": 57062, "prompt_tokens": 46376, "cached_prompt_tokens": 12, "completion_tokens": 10686, "successful_requests": 10}`,
+			expected: &LLMTokenUsage{
+				InputTokens:          46376,
+				OutputTokens:         10686,
+				CacheReadInputTokens: 12,
+				TotalTokens:          57062,
+			},
+		},
+		{
+			name:  "json object with string values",
+			input: `{"total_tokens": "57062", "prompt_tokens": "46376", "cached_prompt_tokens": "12", "completion_tokens": "10686"}`,
+			expected: &LLMTokenUsage{
+				InputTokens:          46376,
+				OutputTokens:         10686,
+				CacheReadInputTokens: 12,
+				TotalTokens:          57062,
+			},
+		},
+		{
+			name:  "python repr",
+			input: "UsageMetrics(total_tokens=57062, prompt_tokens=46376, cached_prompt_tokens=12, completion_tokens=10686, successful_requests=10)",
+			expected: &LLMTokenUsage{
+				InputTokens:          46376,
+				OutputTokens:         10686,
+				CacheReadInputTokens: 12,
+				TotalTokens:          57062,
+			},
+		},
+		{
+			name:     "empty string",
+			input:    "",
+			expected: nil,
+		},
+		{
+			name:     "invalid json",
+			input:    `{"total_tokens": `,
+			expected: nil,
+		},
+		{
+			name:     "no token values",
+			input:    "successful_requests=10",
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseCrewAITokenUsage(tt.input)
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("parseCrewAITokenUsage(%q) = %+v, want %+v", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
+
+// crewAISpanSource returns the document of a span with the given attributes
+func crewAISpanSource(spanID, parentSpanID, name string, attributes map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"traceId":      "4bf92f3577b34da6a3ce929d0e0e4736",
+		"spanId":       spanID,
+		"parentSpanId": parentSpanID,
+		"name":         name,
+		"attributes":   attributes,
+	}
+}
+
+func TestCrewAIFlowSpans(t *testing.T) {
+	tests := []struct {
+		name       string
+		attributes map[string]interface{}
+		wantKind   SpanType
+		wantData   interface{}
+		wantInput  interface{}
+		wantOutput interface{}
+	}{
+		{
+			name: "flow",
+			attributes: map[string]interface{}{
+				"crewai.flow.name":   "ResearchFlow",
+				"crewai.flow.inputs": `{"topic":"otel"}`,
+				"crewai.flow.state":  `{"report":"done"}`,
+			},
+			wantKind:   SpanTypeAgent,
+			wantData:   AgentData{Name: "ResearchFlow", Framework: "crewai"},
+			wantInput:  `{"topic":"otel"}`,
+			wantOutput: `{"report":"done"}`,
+		},
+		{
+			name: "router method returning a route",
+			attributes: map[string]interface{}{
+				"crewai.flow.name":          "ResearchFlow",
+				"crewai.flow.method_name":   "classify",
+				"crewai.flow.method_type":   "Router",
+				"crewai.flow.method.result": "needs_review",
+			},
+			wantKind:   SpanTypeChain,
+			wantData:   CrewAIFlowData{Name: "ResearchFlow", MethodName: "classify", MethodType: "router", Route: "needs_review"},
+			wantOutput: "needs_review",
+		},
+		{
+			name: "explicit route takes precedence over the result",
+			attributes: map[string]interface{}{
+				"crewai.flow.name":          "ResearchFlow",
+				"crewai.flow.method_name":   "classify",
+				"crewai.flow.method_type":   "router",
+				"crewai.flow.router.route":  "approved",
+				"crewai.flow.method.result": "ignored",
+			},
+			wantKind:   SpanTypeChain,
+			wantData:   CrewAIFlowData{Name: "ResearchFlow", MethodName: "classify", MethodType: "router", Route: "approved"},
+			wantOutput: "ignored",
+		},
+		{
+			name: "listener keeps its trigger and takes no route from its result",
+			attributes: map[string]interface{}{
+				"crewai.flow.name":           "ResearchFlow",
+				"crewai.flow.method_name":    "review",
+				"crewai.flow.method_type":    "listen",
+				"crewai.flow.method.trigger": "needs_review",
+				"crewai.flow.method.result":  "reviewed",
+			},
+			wantKind:   SpanTypeChain,
+			wantData:   CrewAIFlowData{Name: "ResearchFlow", MethodName: "review", MethodType: "listen", Trigger: "needs_review"},
+			wantOutput: "reviewed",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			span := ProcessDocument(crewAISpanSource("00f067aa0ba902b7", "", "Flow", tt.attributes))
+
+			if span.AmpAttributes.Kind != string(tt.wantKind) {
+				t.Errorf("kind = %q, want %q", span.AmpAttributes.Kind, tt.wantKind)
+			}
+			if !reflect.DeepEqual(span.AmpAttributes.Data, tt.wantData) {
+				t.Errorf("data = %+v, want %+v", span.AmpAttributes.Data, tt.wantData)
+			}
+			if span.AmpAttributes.Input != tt.wantInput || span.AmpAttributes.Output != tt.wantOutput {
+				t.Errorf("input, output = %v, %v, want %v, %v", span.AmpAttributes.Input, span.AmpAttributes.Output, tt.wantInput, tt.wantOutput)
+			}
+		})
+	}
+}
+
+func TestCrewAIToolSpans(t *testing.T) {
+	tests := []struct {
+		name       string
+		attributes map[string]interface{}
+		wantData   ToolData
+		wantInput  interface{}
+		wantOutput interface{}
+	}{
+		{
+			name: "arguments holding JSON are parsed",
+			attributes: map[string]interface{}{
+				"crewai.tool.name":       " Search the internet ",
+				"crewai.tool.args":       `{"query":"otel"}`,
+				"crewai.tool.result":     "3 results",
+				"crewai.tool.attempts":   float64(2),
+				"crewai.tool.from_cache": true,
+			},
+			wantData:   ToolData{Name: "Search the internet", Attempts: 2, FromCache: true},
+			wantInput:  map[string]interface{}{"query": "otel"},
+			wantOutput: "3 results",
+		},
+		{
+			name:       "other arguments are kept as they are",
+			attributes: map[string]interface{}{"crewai.tool.name": "calculator", "crewai.tool.args": "2 + 2", "crewai.tool.result": "4"},
+			wantData:   ToolData{Name: "calculator"},
+			wantInput:  "2 + 2",
+			wantOutput: "4",
+		},
+		{
+			name:       "arguments decoded at ingestion are used as they are",
+			attributes: map[string]interface{}{"crewai.tool.name": "calculator", "crewai.tool.args": map[string]interface{}{"a": float64(2)}},
+			wantData:   ToolData{Name: "calculator"},
+			wantInput:  map[string]interface{}{"a": float64(2)},
+		},
+		{
+			name: "delegation names the coworker",
+			attributes: map[string]interface{}{
+				"crewai.tool.name": "Delegate work to coworker",
+				"crewai.tool.args": `{"task":"write","coworker":" Writer "}`,
+			},
+			wantData:  ToolData{Name: "Delegate work to coworker", Delegatee: "Writer"},
+			wantInput: map[string]interface{}{"task": "write", "coworker": " Writer "},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.attributes["traceloop.span.kind"] = "tool"
+			span := ProcessDocument(crewAISpanSource("00f067aa0ba902b7", "", "Tool", tt.attributes))
+
+			if span.AmpAttributes.Kind != string(SpanTypeTool) {
+				t.Fatalf("kind = %q, want a tool span", span.AmpAttributes.Kind)
+			}
+			if !reflect.DeepEqual(span.AmpAttributes.Data, tt.wantData) {
+				t.Errorf("data = %+v, want %+v", span.AmpAttributes.Data, tt.wantData)
+			}
+			if !reflect.DeepEqual(span.AmpAttributes.Input, tt.wantInput) || span.AmpAttributes.Output != tt.wantOutput {
+				t.Errorf("input, output = %#v, %#v, want %#v, %#v", span.AmpAttributes.Input, span.AmpAttributes.Output, tt.wantInput, tt.wantOutput)
+			}
+		})
+	}
+}
+
+func TestAttributeCrewAIManagerAgents(t *testing.T) {
+	crew := func(attributes map[string]interface{}) map[string]interface{} {
+		attributes["crewai.crew.name"] = "crew"
+		return crewAISpanSource("0000000000000001", "", "Crew Created", attributes)
+	}
+	agent := func(attributes map[string]interface{}) map[string]interface{} {
+		attributes["traceloop.span.kind"] = "agent"
+		attributes["gen_ai.system"] = "crewai"
+		return crewAISpanSource("0000000000000002", "0000000000000001", "Agent", attributes)
+	}
+	tests := []struct {
+		name  string
+		crew  map[string]interface{}
+		agent map[string]interface{}
+		want  string
+	}{
+		{
+			name:  "manager agent of a hierarchical crew",
+			crew:  crew(map[string]interface{}{"crewai.crew.process": "Hierarchical", "crewai.crew.manager_agent": " Lead "}),
+			agent: agent(map[string]interface{}{}),
+			want:  "Lead",
+		},
+		{
+			name:  "default manager name",
+			crew:  crew(map[string]interface{}{"crewai.crew.process": "hierarchical"}),
+			agent: agent(map[string]interface{}{}),
+			want:  "Crew Manager",
+		},
+		{
+			name:  "agents with a role keep it",
+			crew:  crew(map[string]interface{}{"crewai.crew.process": "hierarchical", "crewai.crew.manager_agent": "Lead"}),
+			agent: agent(map[string]interface{}{"crewai.agent.role": "Researcher"}),
+			want:  "Researcher",
+		},
+		{
+			name:  "sequential crews have no manager",
+			crew:  crew(map[string]interface{}{"crewai.crew.process": "sequential"}),
+			agent: agent(map[string]interface{}{}),
+			want:  "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The agent span comes first, the manager is resolved once the whole trace is read
+			spans := ParseSpanSources([]map[string]interface{}{tt.agent, tt.crew})
+
+			data, ok := spans[0].AmpAttributes.Data.(AgentData)
+			if !ok || data.Name != tt.want {
+				t.Errorf("agent = %+v, want the name %q", spans[0].AmpAttributes.Data, tt.want)
+			}
+		})
+	}
+}
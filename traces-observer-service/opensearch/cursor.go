@@ -0,0 +1,57 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package opensearch
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+)
+
+// TraceCursor is the decoded form of an opaque trace listing cursor
+type TraceCursor struct {
+	SearchAfter []interface{} `json:"searchAfter,omitempty"` // Sort values of the last trace of the previous page
+	PitID       string        `json:"pitId,omitempty"`       // Point in time the pages are read from
+	Sort        string        `json:"sort,omitempty"`        // Sort the search_after values belong to
+}
+
+// EncodeCursor encodes a cursor into an opaque URL-safe string
+func EncodeCursor(cursor TraceCursor) (string, error) {
+	data, err := json.Marshal(cursor)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode cursor: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// DecodeCursor decodes an opaque cursor string
+func DecodeCursor(value string) (*TraceCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor: %w", err)
+	}
+
+	var cursor TraceCursor
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	// Keep sort values as numbers exactly as OpenSearch returned them
+	decoder.UseNumber()
+	if err := decoder.Decode(&cursor); err != nil {
+		return nil, fmt.Errorf("invalid cursor: %w", err)
+	}
+	return &cursor, nil
+}
@@ -0,0 +1,307 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package opensearch
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// DeadLetterIndex is the index holding documents that could not be indexed until they are retried or expire
+const DeadLetterIndex = "amp-trace-deadletters"
+
+// DeadLetter records a document that could not be indexed
+type DeadLetter struct {
+	EntryID   string          `json:"deadLetterId,omitempty"` // Derived from the index and ID of the document, see DeadLetterID
+	Time      time.Time       `json:"time"`
+	ExpiresAt *time.Time      `json:"expiresAt,omitempty"` // Entries of the dead-letter index are purged afterwards
+	Index     string          `json:"index"`
+	ID        string          `json:"id,omitempty"`
+	TraceID   string          `json:"traceId,omitempty"` // Set for span documents
+	Status    int             `json:"status,omitempty"`
+	Reason    string          `json:"reason"`
+	Document  json.RawMessage `json:"document"`
+}
+
+// DeadLetterFilter selects dead letters, zero fields match every entry
+type DeadLetterFilter struct {
+	Index   string
+	TraceID string
+	Status  int
+	Reason  string // Words that must appear in the reason, e.g. mapper_parsing_exception
+	From    time.Time
+	To      time.Time
+}
+
+// DeadLetterList is a page of dead letters, newest first
+type DeadLetterList struct {
+	DeadLetters []DeadLetter `json:"deadLetters"`
+	TotalCount  int          `json:"totalCount"`
+}
+
+// DeadLetterStoreStats holds the counters of a DeadLetterStore
+type DeadLetterStoreStats struct {
+	Recorded uint64 `json:"recorded"` // Entries written to the dead-letter index
+	Failed   uint64 `json:"failed"`   // Entries that could not be written and went to the dead-letter log instead
+	Purged   uint64 `json:"purged"`   // Entries deleted after their retention
+}
+
+// DeadLetterStore keeps dead letters in OpenSearch so that they can be browsed and retried
+type DeadLetterStore struct {
+	client    *Client
+	retention time.Duration
+	ensured   atomic.Bool
+
+	recorded atomic.Uint64
+	failed   atomic.Uint64
+	purged   atomic.Uint64
+}
+
+// NewDeadLetterStore creates a dead-letter store whose entries expire after retention
+func (c *Client) NewDeadLetterStore(retention time.Duration) *DeadLetterStore {
+	return &DeadLetterStore{client: c, retention: retention}
+}
+
+// DeadLetterID returns the ID of the dead letter of a document
+// A document failing again replaces its earlier entry, documents without an ID get a random one
+func DeadLetterID(index, id string) string {
+	if id == "" {
+		return strings.ToLower(rand.Text())
+	}
+	sum := sha256.Sum256([]byte(index + "/" + id))
+	return hex.EncodeToString(sum[:16])
+}
+
+// buildDeadLetterIndexBody builds the mappings of the dead-letter index
+// The document is kept but not indexed, so the mapping conflicts that failed it cannot fail its entry
+func buildDeadLetterIndexBody() map[string]interface{} {
+	return map[string]interface{}{
+		"mappings": map[string]interface{}{
+			"dynamic": false,
+			"properties": map[string]interface{}{
+				"deadLetterId": map[string]interface{}{"type": "keyword"},
+				"time":         map[string]interface{}{"type": "date"},
+				"expiresAt":    map[string]interface{}{"type": "date"},
+				"index":        map[string]interface{}{"type": "keyword"},
+				"id":           map[string]interface{}{"type": "keyword"},
+				"traceId":      map[string]interface{}{"type": "keyword"},
+				"status":       map[string]interface{}{"type": "integer"},
+				"reason":       map[string]interface{}{"type": "text"},
+				"document":     map[string]interface{}{"type": "object", "enabled": false},
+			},
+		},
+	}
+}
+
+// EnsureIndex creates the dead-letter index when it does not exist yet
+func (s *DeadLetterStore) EnsureIndex(ctx context.Context) error {
+	if s.ensured.Load() {
+		return nil
+	}
+	if err := s.client.EnsureIndex(ctx, DeadLetterIndex, buildDeadLetterIndexBody()); err != nil {
+		return err
+	}
+	s.ensured.Store(true)
+	return nil
+}
+
+// Record writes dead letters with a single bulk request and returns the error of every entry that was not written
+func (s *DeadLetterStore) Record(ctx context.Context, letters []DeadLetter) []error {
+	errs := make([]error, len(letters))
+	failAll := func(err error) []error {
+		for i := range errs {
+			errs[i] = err
+		}
+		s.failed.Add(uint64(len(letters)))
+		return errs
+	}
+	// The index is created explicitly, created by the bulk request it would map the documents
+	if err := s.EnsureIndex(ctx); err != nil {
+		return failAll(err)
+	}
+
+	expiresAt := time.Now().UTC().Add(s.retention)
+	var body bytes.Buffer
+	for _, letter := range letters {
+		if letter.EntryID == "" {
+			letter.EntryID = DeadLetterID(letter.Index, letter.ID)
+		}
+		letter.ExpiresAt = &expiresAt
+		action, err := json.Marshal(map[string]interface{}{
+			"index": map[string]string{"_index": DeadLetterIndex, "_id": letter.EntryID},
+		})
+		if err != nil {
+			return failAll(fmt.Errorf("failed to encode dead letter action: %w", err))
+		}
+		source, err := json.Marshal(letter)
+		if err != nil {
+			return failAll(fmt.Errorf("failed to encode dead letter: %w", err))
+		}
+		body.Write(action)
+		body.WriteByte('\n')
+		body.Write(source)
+		body.WriteByte('\n')
+	}
+
+	response, _, err := s.client.Bulk(ctx, body.Bytes())
+	if err != nil {
+		return failAll(err)
+	}
+	if len(response.Items) != len(letters) {
+		return failAll(fmt.Errorf("bulk response has %d items for %d dead letters", len(response.Items), len(letters)))
+	}
+	for i, item := range response.Items {
+		result := item.result()
+		if result.Status >= 300 {
+			errs[i] = fmt.Errorf("dead letter rejected: %s", result.reason())
+			s.failed.Add(1)
+			continue
+		}
+		s.recorded.Add(1)
+	}
+	return errs
+}
+
+// BuildDeadLetterQuery builds the query of the dead letters matching a filter
+func BuildDeadLetterQuery(filter DeadLetterFilter) map[string]interface{} {
+	var clauses []map[string]interface{}
+	if filter.Index != "" {
+		clauses = append(clauses, map[string]interface{}{"term": map[string]interface{}{"index": filter.Index}})
+	}
+	if filter.TraceID != "" {
+		clauses = append(clauses, map[string]interface{}{"term": map[string]interface{}{"traceId": filter.TraceID}})
+	}
+	if filter.Status != 0 {
+		clauses = append(clauses, map[string]interface{}{"term": map[string]interface{}{"status": filter.Status}})
+	}
+	if filter.Reason != "" {
+		clauses = append(clauses, map[string]interface{}{
+			"match": map[string]interface{}{"reason": map[string]interface{}{"query": filter.Reason, "operator": "and"}},
+		})
+	}
+	if !filter.From.IsZero() || !filter.To.IsZero() {
+		timeRange := map[string]interface{}{}
+		if !filter.From.IsZero() {
+			timeRange["gte"] = filter.From.UTC().Format(time.RFC3339Nano)
+		}
+		if !filter.To.IsZero() {
+			timeRange["lte"] = filter.To.UTC().Format(time.RFC3339Nano)
+		}
+		clauses = append(clauses, map[string]interface{}{"range": map[string]interface{}{"time": timeRange}})
+	}
+	if len(clauses) == 0 {
+		return map[string]interface{}{"match_all": map[string]interface{}{}}
+	}
+	return map[string]interface{}{"bool": map[string]interface{}{"filter": clauses}}
+}
+
+// List returns the dead letters matching a filter, newest first
+func (s *DeadLetterStore) List(ctx context.Context, filter DeadLetterFilter, limit, offset int) (*DeadLetterList, error) {
+	response, err := s.client.Search(ctx, []string{DeadLetterIndex}, map[string]interface{}{
+		"query":            BuildDeadLetterQuery(filter),
+		"size":             limit,
+		"from":             offset,
+		"track_total_hits": true,
+		"sort": []map[string]interface{}{
+			{"time": map[string]string{"order": "desc"}},
+			{"deadLetterId": map[string]string{"order": "asc"}},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to search dead letters: %w", err)
+	}
+
+	letters := make([]DeadLetter, 0, len(response.Hits.Hits))
+	for _, hit := range response.Hits.Hits {
+		var letter DeadLetter
+		if err := DecodeSource(hit.Source, &letter); err != nil {
+			return nil, fmt.Errorf("failed to decode dead letter: %w", err)
+		}
+		letters = append(letters, letter)
+	}
+	return &DeadLetterList{DeadLetters: letters, TotalCount: response.Hits.Total.Value}, nil
+}
+
+// Get returns a dead letter, false when it does not exist
+func (s *DeadLetterStore) Get(ctx context.Context, id string) (*DeadLetter, bool, error) {
+	var letter DeadLetter
+	found, err := s.client.GetDocument(ctx, DeadLetterIndex, id, &letter)
+	if err != nil || !found {
+		return nil, found, err
+	}
+	return &letter, true, nil
+}
+
+// Delete deletes a dead letter, false when it does not exist
+func (s *DeadLetterStore) Delete(ctx context.Context, id string) (bool, error) {
+	return s.client.DeleteDocument(ctx, DeadLetterIndex, id)
+}
+
+// PurgeExpired deletes the dead letters whose retention ended before now
+func (s *DeadLetterStore) PurgeExpired(ctx context.Context, now time.Time) (int, error) {
+	deleted, err := s.client.DeleteByQuery(ctx, []string{DeadLetterIndex}, map[string]interface{}{
+		"range": map[string]interface{}{"expiresAt": map[string]interface{}{"lte": now.UTC().Format(time.RFC3339Nano)}},
+	})
+	if err != nil {
+		if IsStatusError(err, http.StatusNotFound) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("failed to purge dead letters: %w", err)
+	}
+	s.purged.Add(uint64(deleted))
+	return deleted, nil
+}
+
+// RunPurge purges expired dead letters every interval until ctx is done
+func (s *DeadLetterStore) RunPurge(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			deleted, err := s.PurgeExpired(ctx, time.Now())
+			if err != nil {
+				slog.Error("Failed to purge expired dead letters", "error", err)
+				continue
+			}
+			if deleted > 0 {
+				slog.Info("Purged expired dead letters", "deleted", deleted)
+			}
+		}
+	}
+}
+
+// Stats returns the counters of the store
+func (s *DeadLetterStore) Stats() DeadLetterStoreStats {
+	return DeadLetterStoreStats{
+		Recorded: s.recorded.Load(),
+		Failed:   s.failed.Load(),
+		Purged:   s.purged.Load(),
+	}
+}
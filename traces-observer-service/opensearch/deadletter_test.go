@@ -0,0 +1,58 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package opensearch
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestDeadLetterID(t *testing.T) {
+	id := DeadLetterID("otel-traces-2026-10-16", "trace-span")
+	if id != DeadLetterID("otel-traces-2026-10-16", "trace-span") {
+		t.Error("the dead letter of a document failing again must replace its entry")
+	}
+	if id == DeadLetterID("otel-traces-2026-10-17", "trace-span") {
+		t.Error("documents of different indices share a dead letter")
+	}
+	if DeadLetterID("idx", "") == DeadLetterID("idx", "") {
+		t.Error("documents without an ID share a dead letter")
+	}
+}
+
+func TestBuildDeadLetterQuery(t *testing.T) {
+	all, _ := json.Marshal(BuildDeadLetterQuery(DeadLetterFilter{}))
+	if string(all) != `{"match_all":{}}` {
+		t.Errorf("empty filter query = %s", all)
+	}
+
+	query, _ := json.Marshal(BuildDeadLetterQuery(DeadLetterFilter{
+		Index:  "otel-traces-2026-10-16",
+		Status: 400,
+		Reason: "mapper_parsing_exception",
+		From:   time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC),
+	}))
+	want := `{"bool":{"filter":[` +
+		`{"term":{"index":"otel-traces-2026-10-16"}},` +
+		`{"term":{"status":400}},` +
+		`{"match":{"reason":{"operator":"and","query":"mapper_parsing_exception"}}},` +
+		`{"range":{"time":{"gte":"2026-10-16T00:00:00Z"}}}]}}`
+	if string(query) != want {
+		t.Errorf("query = %s, want %s", query, want)
+	}
+}
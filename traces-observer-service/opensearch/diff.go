@@ -0,0 +1,197 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package opensearch
+
+import (
+	"fmt"
+	"strings"
+)
+
+// diffContextLines is the number of unchanged lines around each change of a unified diff
+const diffContextLines = 3
+
+// maxDiffCells bounds the line comparisons of a diff, larger texts are diffed as a full replacement of the
+// lines between their common prefix and suffix
+const maxDiffCells = 4_000_000
+
+// diffOp is a line of an edit script: ' ' kept, '-' removed from the left, '+' added on the right
+type diffOp struct {
+	kind byte
+	line string
+}
+
+// UnifiedDiff returns the line based unified diff of two texts, empty when they are equal
+func UnifiedDiff(left, right, leftName, rightName string) string {
+	if left == right {
+		return ""
+	}
+	ops := diffLines(splitLines(left), splitLines(right))
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- %s\n+++ %s\n", leftName, rightName)
+	for _, hunk := range diffHunks(ops) {
+		writeHunk(&b, ops, hunk[0], hunk[1])
+	}
+	return b.String()
+}
+
+// splitLines splits a text into lines without their line breaks
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
+
+// diffLines computes an edit script turning the left lines into the right lines
+// The common prefix and suffix are kept as they are, the lines between them are aligned by their longest common
+// subsequence
+func diffLines(left, right []string) []diffOp {
+	prefix := 0
+	for prefix < len(left) && prefix < len(right) && left[prefix] == right[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(left)-prefix && suffix < len(right)-prefix && left[len(left)-1-suffix] == right[len(right)-1-suffix] {
+		suffix++
+	}
+
+	ops := make([]diffOp, 0, len(left)+len(right))
+	for _, line := range left[:prefix] {
+		ops = append(ops, diffOp{kind: ' ', line: line})
+	}
+	ops = append(ops, diffMiddle(left[prefix:len(left)-suffix], right[prefix:len(right)-suffix])...)
+	for _, line := range left[len(left)-suffix:] {
+		ops = append(ops, diffOp{kind: ' ', line: line})
+	}
+	return ops
+}
+
+// diffMiddle aligns two runs of lines by their longest common subsequence
+func diffMiddle(left, right []string) []diffOp {
+	n, m := len(left), len(right)
+	ops := make([]diffOp, 0, n+m)
+	if n*m > maxDiffCells {
+		for _, line := range left {
+			ops = append(ops, diffOp{kind: '-', line: line})
+		}
+		for _, line := range right {
+			ops = append(ops, diffOp{kind: '+', line: line})
+		}
+		return ops
+	}
+
+	// lcs[i][j] is the length of the longest common subsequence of left[i:] and right[j:]
+	lcs := make([][]int, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if left[i] == right[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < n && j < m {
+		switch {
+		case left[i] == right[j]:
+			ops = append(ops, diffOp{kind: ' ', line: left[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			ops = append(ops, diffOp{kind: '-', line: left[i]})
+			i++
+		default:
+			ops = append(ops, diffOp{kind: '+', line: right[j]})
+			j++
+		}
+	}
+	for ; i < n; i++ {
+		ops = append(ops, diffOp{kind: '-', line: left[i]})
+	}
+	for ; j < m; j++ {
+		ops = append(ops, diffOp{kind: '+', line: right[j]})
+	}
+	return ops
+}
+
+// diffHunks groups the changes of an edit script into hunks of [start, end) op indices with their context,
+// changes separated by no more than twice the context share a hunk
+func diffHunks(ops []diffOp) [][2]int {
+	var hunks [][2]int
+	for i := 0; i < len(ops); i++ {
+		if ops[i].kind == ' ' {
+			continue
+		}
+		start := max(i-diffContextLines, 0)
+		end := i + 1
+		for j := i + 1; j < len(ops); j++ {
+			if ops[j].kind == ' ' {
+				if j-end >= 2*diffContextLines {
+					break
+				}
+				continue
+			}
+			end = j + 1
+		}
+		end = min(end+diffContextLines, len(ops))
+		hunks = append(hunks, [2]int{start, end})
+		i = end - 1
+	}
+	return hunks
+}
+
+// writeHunk writes a hunk header with the line ranges of both sides followed by its lines
+func writeHunk(b *strings.Builder, ops []diffOp, start, end int) {
+	leftStart, rightStart := 1, 1
+	for _, op := range ops[:start] {
+		if op.kind != '+' {
+			leftStart++
+		}
+		if op.kind != '-' {
+			rightStart++
+		}
+	}
+	leftCount, rightCount := 0, 0
+	for _, op := range ops[start:end] {
+		if op.kind != '+' {
+			leftCount++
+		}
+		if op.kind != '-' {
+			rightCount++
+		}
+	}
+	// An empty range starts at the line before it
+	if leftCount == 0 {
+		leftStart--
+	}
+	if rightCount == 0 {
+		rightStart--
+	}
+
+	fmt.Fprintf(b, "@@ -%d,%d +%d,%d @@\n", leftStart, leftCount, rightStart, rightCount)
+	for _, op := range ops[start:end] {
+		b.WriteByte(op.kind)
+		b.WriteString(op.line)
+		b.WriteByte('\n')
+	}
+}
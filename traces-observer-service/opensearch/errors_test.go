@@ -0,0 +1,151 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package opensearch
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+// exceptionEvent creates an exception span event with the given attributes
+func exceptionEvent(attrs map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{"name": "exception", "attributes": attrs}
+}
+
+func TestSpanErrorExtraction(t *testing.T) {
+	tests := []struct {
+		name          string
+		source        map[string]interface{}
+		wantError     bool
+		wantErrorType string
+		wantDetails   *ErrorData
+	}{
+		{
+			name: "exception event on an ok span",
+			source: map[string]interface{}{
+				"status": map[string]interface{}{"code": "Unset"},
+				"events": []interface{}{exceptionEvent(map[string]interface{}{
+					"exception.type": "ValueError", "exception.message": "bad input", "exception.stacktrace": "Traceback ...",
+				})},
+			},
+			wantError:     true,
+			wantErrorType: "ValueError",
+			wantDetails:   &ErrorData{Type: "ValueError", Message: "bad input", Stacktrace: "Traceback ..."},
+		},
+		{
+			name: "last exception event wins",
+			source: map[string]interface{}{
+				"events": []interface{}{
+					exceptionEvent(map[string]interface{}{"exception.type": "RetryError", "exception.message": "retrying"}),
+					map[string]interface{}{"name": "log", "attributes": map[string]interface{}{"exception.type": "Ignored"}},
+					exceptionEvent(map[string]interface{}{"exception.type": "TimeoutError", "exception.message": "timed out"}),
+				},
+			},
+			wantError:     true,
+			wantErrorType: "TimeoutError",
+			wantDetails:   &ErrorData{Type: "TimeoutError", Message: "timed out"},
+		},
+		{
+			name:        "status message of a failed span",
+			source:      map[string]interface{}{"status": map[string]interface{}{"code": 2, "message": "upstream unavailable"}},
+			wantError:   true,
+			wantDetails: &ErrorData{Message: "upstream unavailable"},
+		},
+		{
+			name:   "status message of an ok span is not an error",
+			source: map[string]interface{}{"status": map[string]interface{}{"code": "Ok", "message": "done"}},
+		},
+		{
+			name:          "error type attribute wins over the exception type",
+			source:        map[string]interface{}{"attributes": map[string]interface{}{"error.type": "RateLimitError"}, "events": []interface{}{exceptionEvent(map[string]interface{}{"exception.type": "HTTPError"})}},
+			wantError:     true,
+			wantErrorType: "RateLimitError",
+			wantDetails:   &ErrorData{Type: "HTTPError"},
+		},
+		{
+			name:          "CrewAI tool error message",
+			source:        map[string]interface{}{"attributes": map[string]interface{}{"crewai.tool.error": "file not found"}},
+			wantError:     true,
+			wantErrorType: "ToolExecutionError",
+		},
+		{
+			name:          "CrewAI tool error flag",
+			source:        map[string]interface{}{"attributes": map[string]interface{}{"crewai.tool.error": true}},
+			wantError:     true,
+			wantErrorType: "ToolExecutionError",
+		},
+		{
+			name:   "empty CrewAI tool error",
+			source: map[string]interface{}{"attributes": map[string]interface{}{"crewai.tool.error": ""}},
+		},
+		{
+			name:          "HTTP error status",
+			source:        map[string]interface{}{"attributes": map[string]interface{}{"http.status_code": 503}},
+			wantError:     true,
+			wantErrorType: "503",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := map[string]interface{}{"traceId": "trace", "spanId": "span", "name": "step"}
+			for key, value := range tt.source {
+				source[key] = value
+			}
+			span := ProcessDocument(source)
+			status := span.AmpAttributes.Status
+			if status.Error != tt.wantError || status.ErrorType != tt.wantErrorType {
+				t.Errorf("status = %+v, want error %v of type %q", status, tt.wantError, tt.wantErrorType)
+			}
+			if !reflect.DeepEqual(span.AmpAttributes.Error, tt.wantDetails) {
+				t.Errorf("error details = %+v, want %+v", span.AmpAttributes.Error, tt.wantDetails)
+			}
+		})
+	}
+}
+
+func TestStacktraceTruncation(t *testing.T) {
+	// A multibyte rune straddles the limit and must not be split
+	stacktrace := strings.Repeat("a", MaxStacktraceLength-1) + "é" + strings.Repeat("b", 100)
+	span := ProcessDocument(map[string]interface{}{
+		"traceId": "trace",
+		"spanId":  "span",
+		"events":  []interface{}{exceptionEvent(map[string]interface{}{"exception.stacktrace": stacktrace})},
+	})
+	got := span.AmpAttributes.Error.Stacktrace
+	if len(got) != MaxStacktraceLength-1 || !utf8.ValidString(got) {
+		t.Errorf("stacktrace of %d bytes (valid UTF-8 %v), want %d bytes", len(got), utf8.ValidString(got), MaxStacktraceLength-1)
+	}
+}
+
+func TestExtractTraceStatusCountsToolErrors(t *testing.T) {
+	spans := []Span{
+		ProcessDocument(map[string]interface{}{"traceId": "t", "spanId": "root", "name": "agent"}),
+		ProcessDocument(map[string]interface{}{"traceId": "t", "spanId": "tool", "parentSpanId": "root", "name": "search",
+			"attributes": map[string]interface{}{"traceloop.span.kind": "tool", "crewai.tool.error": "no results"}}),
+		ProcessDocument(map[string]interface{}{"traceId": "t", "spanId": "llm", "parentSpanId": "root", "name": "chat",
+			"events": []interface{}{exceptionEvent(map[string]interface{}{"exception.type": "APIError"})}}),
+	}
+	status := ExtractTraceStatus(spans)
+	if status.ErrorCount != 2 || status.ToolErrorCount != 1 || !status.HasError {
+		t.Errorf("trace status = %+v, want 2 errors of which 1 tool error", status)
+	}
+	if ok := ExtractTraceStatus(spans[:1]); ok.HasError || ok.ErrorCount != 0 {
+		t.Errorf("trace status without errors = %+v", ok)
+	}
+}
@@ -0,0 +1,64 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package opensearch
+
+import "time"
+
+// IngestedAtField is the document field holding the time a span was received by the service
+const IngestedAtField = "ingestedAt"
+
+// Trace states
+const (
+	TraceStateOpen      = "open"      // Spans of the trace may still arrive
+	TraceStateFinalized = "finalized" // The trace is complete and its rollups are final
+)
+
+// FinalizationPolicy decides when a trace stops accepting late spans
+// A trace is finalized once no span arrived for the quiet period, or when its first span is older than the max age
+type FinalizationPolicy struct {
+	QuietPeriod time.Duration
+	MaxAge      time.Duration // Finalizes traces that keep receiving spans, disabled when zero
+}
+
+// Finalized reports whether a trace whose spans arrived between firstSeen and lastSeen is finalized at now
+func (p FinalizationPolicy) Finalized(firstSeen, lastSeen, now time.Time) bool {
+	if now.Sub(lastSeen) >= p.QuietPeriod {
+		return true
+	}
+	return p.MaxAge > 0 && now.Sub(firstSeen) >= p.MaxAge
+}
+
+// TraceState returns the state of a trace from the ingestion times of its spans
+// Spans indexed without an ingestion time, such as spans indexed before it was recorded, count as finalized
+func (p FinalizationPolicy) TraceState(spans []Span, now time.Time) string {
+	var firstSeen, lastSeen time.Time
+	for _, span := range spans {
+		if span.IngestedAt.IsZero() {
+			continue
+		}
+		if firstSeen.IsZero() || span.IngestedAt.Before(firstSeen) {
+			firstSeen = span.IngestedAt
+		}
+		if span.IngestedAt.After(lastSeen) {
+			lastSeen = span.IngestedAt
+		}
+	}
+	if lastSeen.IsZero() || p.Finalized(firstSeen, lastSeen, now) {
+		return TraceStateFinalized
+	}
+	return TraceStateOpen
+}
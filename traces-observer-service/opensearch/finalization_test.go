@@ -0,0 +1,86 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package opensearch
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFinalizationPolicyFinalized(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name      string
+		policy    FinalizationPolicy
+		firstSeen time.Time
+		lastSeen  time.Time
+		want      bool
+	}{
+		{"just received", FinalizationPolicy{QuietPeriod: time.Minute}, now, now, false},
+		{"just inside the quiet period", FinalizationPolicy{QuietPeriod: time.Minute}, now.Add(-time.Minute + time.Nanosecond), now.Add(-time.Minute + time.Nanosecond), false},
+		{"quiet for exactly the quiet period", FinalizationPolicy{QuietPeriod: time.Minute}, now.Add(-time.Minute), now.Add(-time.Minute), true},
+		{"quiet for longer", FinalizationPolicy{QuietPeriod: time.Minute}, now.Add(-time.Hour), now.Add(-2 * time.Minute), true},
+		{"a late span keeps the trace open", FinalizationPolicy{QuietPeriod: time.Minute}, now.Add(-time.Hour), now.Add(-time.Second), false},
+		{"still receiving spans without a max age", FinalizationPolicy{QuietPeriod: time.Minute}, now.Add(-24 * time.Hour), now, false},
+		{"still receiving spans within the max age", FinalizationPolicy{QuietPeriod: time.Minute, MaxAge: time.Hour}, now.Add(-time.Hour + time.Nanosecond), now, false},
+		{"still receiving spans at the max age", FinalizationPolicy{QuietPeriod: time.Minute, MaxAge: time.Hour}, now.Add(-time.Hour), now, true},
+		{"still receiving spans beyond the max age", FinalizationPolicy{QuietPeriod: time.Minute, MaxAge: time.Hour}, now.Add(-3 * time.Hour), now.Add(-time.Second), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.policy.Finalized(tt.firstSeen, tt.lastSeen, now); got != tt.want {
+				t.Errorf("Finalized() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFinalizationPolicyTraceState(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	ingested := func(ago ...time.Duration) []Span {
+		spans := make([]Span, len(ago))
+		for i, d := range ago {
+			if d >= 0 {
+				spans[i].IngestedAt = now.Add(-d)
+			}
+		}
+		return spans
+	}
+	const unknown = -1 // Spans indexed before the ingestion time was recorded
+	policy := FinalizationPolicy{QuietPeriod: time.Minute, MaxAge: time.Hour}
+	tests := []struct {
+		name  string
+		spans []Span
+		want  string
+	}{
+		{"no spans", nil, TraceStateFinalized},
+		{"recent span", ingested(time.Second), TraceStateOpen},
+		{"quiet spans", ingested(2*time.Minute, time.Minute), TraceStateFinalized},
+		{"the latest span keeps the trace open", ingested(10*time.Minute, time.Second, 5*time.Minute), TraceStateOpen},
+		{"spans without an ingestion time", ingested(unknown, unknown), TraceStateFinalized},
+		{"spans without an ingestion time are ignored", ingested(unknown, time.Second), TraceStateOpen},
+		{"spans without an ingestion time do not reset the max age", ingested(unknown, 2*time.Hour, time.Second), TraceStateFinalized},
+		{"the earliest span starts the max age", ingested(time.Second, 2*time.Hour, 30*time.Minute), TraceStateFinalized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := policy.TraceState(tt.spans, now); got != tt.want {
+				t.Errorf("TraceState() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
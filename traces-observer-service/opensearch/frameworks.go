@@ -0,0 +1,149 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package opensearch
+
+import (
+	"sort"
+	"sync"
+)
+
+// FrameworkProcessor populates the attributes of spans emitted by an agent framework
+type FrameworkProcessor interface {
+	// Detect reports whether the span attributes were emitted by the framework
+	Detect(attrs map[string]interface{}) bool
+	// Populate fills the input, output and type-specific data of a detected span
+	// Span types the framework does not specialise can be handed to PopulateStandardAttributes
+	Populate(ampAttrs *AmpAttributes, span *Span)
+}
+
+// Priorities of the built-in framework processors
+const (
+	PriorityCrewAI = 100
+)
+
+// registeredProcessor is a framework processor with its registration details
+type registeredProcessor struct {
+	name      string
+	priority  int
+	sequence  uint64
+	processor FrameworkProcessor
+}
+
+// FrameworkRegistry holds the framework processors consulted when a span is processed
+// Processors are consulted in descending priority order, processors of equal priority in registration order,
+// and the first processor detecting a span populates it
+type FrameworkRegistry struct {
+	mu         sync.RWMutex
+	processors []registeredProcessor
+	sequence   uint64
+}
+
+// NewFrameworkRegistry creates an empty framework registry
+func NewFrameworkRegistry() *FrameworkRegistry {
+	return &FrameworkRegistry{}
+}
+
+// Register adds a framework processor under the given name
+// A processor already registered under the name is replaced and the replacement is ordered as a new registration
+func (r *FrameworkRegistry) Register(name string, priority int, processor FrameworkProcessor) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.remove(name)
+	r.sequence++
+	r.processors = append(r.processors, registeredProcessor{
+		name:      name,
+		priority:  priority,
+		sequence:  r.sequence,
+		processor: processor,
+	})
+	sort.SliceStable(r.processors, func(i, j int) bool {
+		if r.processors[i].priority != r.processors[j].priority {
+			return r.processors[i].priority > r.processors[j].priority
+		}
+		return r.processors[i].sequence < r.processors[j].sequence
+	})
+}
+
+// Unregister removes the framework processor registered under the given name
+// Returns false when no processor is registered under the name
+func (r *FrameworkRegistry) Unregister(name string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.remove(name)
+}
+
+// remove deletes the processor registered under the given name; the caller must hold the lock
+func (r *FrameworkRegistry) remove(name string) bool {
+	for i, registered := range r.processors {
+		if registered.name == name {
+			r.processors = append(r.processors[:i:i], r.processors[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Names returns the names of the registered processors in the order they are consulted
+func (r *FrameworkRegistry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, len(r.processors))
+	for i, registered := range r.processors {
+		names[i] = registered.name
+	}
+	return names
+}
+
+// Detect returns the name and processor of the first framework detecting the span attributes
+func (r *FrameworkRegistry) Detect(attrs map[string]interface{}) (string, FrameworkProcessor, bool) {
+	if attrs == nil {
+		return "", nil, false
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, registered := range r.processors {
+		if registered.processor.Detect(attrs) {
+			return registered.name, registered.processor, true
+		}
+	}
+	return "", nil, false
+}
+
+// frameworks is the registry consulted by ProcessDocument, starting out with the built-in processors
+var frameworks = newDefaultFrameworkRegistry()
+
+// newDefaultFrameworkRegistry creates a registry holding the built-in framework processors
+func newDefaultFrameworkRegistry() *FrameworkRegistry {
+	registry := NewFrameworkRegistry()
+	registry.Register(FrameworkCrewAI, PriorityCrewAI, CrewAIProcessor{})
+	return registry
+}
+
+// Frameworks returns the registry consulted when spans are processed
+// Deployments embedding the package register processors for in-house frameworks here
+func Frameworks() *FrameworkRegistry {
+	return frameworks
+}
+
+// RegisterFrameworkProcessor registers a framework processor with the registry consulted when spans are processed
+func RegisterFrameworkProcessor(name string, priority int, processor FrameworkProcessor) {
+	frameworks.Register(name, priority, processor)
+}
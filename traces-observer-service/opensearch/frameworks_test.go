@@ -0,0 +1,141 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package opensearch
+
+import (
+	"reflect"
+	"testing"
+)
+
+// attributeProcessor detects spans carrying an attribute and records itself as the agent name
+type attributeProcessor struct {
+	attribute string
+	name      string
+}
+
+func (p attributeProcessor) Detect(attrs map[string]interface{}) bool {
+	_, ok := attrs[p.attribute]
+	return ok
+}
+
+func (p attributeProcessor) Populate(ampAttrs *AmpAttributes, span *Span) {
+	ampAttrs.Data = AgentData{Name: p.name}
+}
+
+func TestFrameworkRegistryOrder(t *testing.T) {
+	tests := []struct {
+		name     string
+		register func(registry *FrameworkRegistry)
+		expected []string
+	}{
+		{
+			name: "descending priority",
+			register: func(registry *FrameworkRegistry) {
+				registry.Register("low", 10, attributeProcessor{})
+				registry.Register("high", 200, attributeProcessor{})
+				registry.Register("medium", 100, attributeProcessor{})
+			},
+			expected: []string{"high", "medium", "low"},
+		},
+		{
+			name: "equal priority in registration order",
+			register: func(registry *FrameworkRegistry) {
+				registry.Register("first", 100, attributeProcessor{})
+				registry.Register("second", 100, attributeProcessor{})
+				registry.Register("third", 100, attributeProcessor{})
+			},
+			expected: []string{"first", "second", "third"},
+		},
+		{
+			name: "replacement ordered as a new registration",
+			register: func(registry *FrameworkRegistry) {
+				registry.Register("first", 100, attributeProcessor{})
+				registry.Register("second", 100, attributeProcessor{})
+				registry.Register("first", 100, attributeProcessor{})
+			},
+			expected: []string{"second", "first"},
+		},
+		{
+			name: "unregistered",
+			register: func(registry *FrameworkRegistry) {
+				registry.Register("first", 100, attributeProcessor{})
+				registry.Register("second", 100, attributeProcessor{})
+				registry.Unregister("first")
+			},
+			expected: []string{"second"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry := NewFrameworkRegistry()
+			tt.register(registry)
+			if names := registry.Names(); !reflect.DeepEqual(names, tt.expected) {
+				t.Errorf("Names() = %v, want %v", names, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFrameworkRegistryFirstMatchWins(t *testing.T) {
+	registry := NewFrameworkRegistry()
+	registry.Register("generic", 10, attributeProcessor{attribute: "acme.agent.name"})
+	registry.Register("acme", 100, attributeProcessor{attribute: "acme.agent.name"})
+	registry.Register("acme-copy", 100, attributeProcessor{attribute: "acme.agent.name"})
+	registry.Register("other", 500, attributeProcessor{attribute: "other.agent.name"})
+
+	for i := 0; i < 10; i++ {
+		name, _, ok := registry.Detect(map[string]interface{}{"acme.agent.name": "planner"})
+		if !ok || name != "acme" {
+			t.Fatalf("Detect() = %q, %v, want %q, true", name, ok, "acme")
+		}
+	}
+
+	if name, _, ok := registry.Detect(map[string]interface{}{"gen_ai.system": "openai"}); ok {
+		t.Errorf("Detect() = %q, want no match", name)
+	}
+}
+
+func TestDefaultFrameworksRegisterCrewAI(t *testing.T) {
+	if names := Frameworks().Names(); !reflect.DeepEqual(names, []string{FrameworkCrewAI}) {
+		t.Errorf("Names() = %v, want [%s]", names, FrameworkCrewAI)
+	}
+}
+
+func TestProcessDocumentUsesRegisteredProcessor(t *testing.T) {
+	RegisterFrameworkProcessor("acme", PriorityCrewAI+1, attributeProcessor{attribute: "acme.agent.name", name: "acme"})
+	t.Cleanup(func() { Frameworks().Unregister("acme") })
+
+	source := map[string]interface{}{
+		"traceId": "trace",
+		"spanId":  "span",
+		"name":    "invoke_agent planner",
+		"attributes": map[string]interface{}{
+			"traceloop.span.kind": "agent",
+			"gen_ai.system":       "crewai",
+			"acme.agent.name":     "planner",
+		},
+	}
+
+	span := ProcessDocument(source)
+	if data, ok := span.AmpAttributes.Data.(AgentData); !ok || data.Name != "acme" {
+		t.Errorf("data = %+v, want the data populated by the registered processor", span.AmpAttributes.Data)
+	}
+	if framework := SpanFramework(span); framework != "acme" {
+		t.Errorf("SpanFramework() = %q, want %q", framework, "acme")
+	}
+}
@@ -0,0 +1,208 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package opensearch
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// genAIMessageEventRoles maps the gen_ai message events to the role of their message
+var genAIMessageEventRoles = map[string]string{
+	"gen_ai.system.message":    "system",
+	"gen_ai.user.message":      "user",
+	"gen_ai.assistant.message": "assistant",
+	"gen_ai.tool.message":      "tool",
+}
+
+// genAIChoiceEvent is the event holding a completion choice
+const genAIChoiceEvent = "gen_ai.choice"
+
+// genAIEventContentAttribute holds the JSON encoded body of an event when it is not flattened into attributes
+const genAIEventContentAttribute = "gen_ai.event.content"
+
+// applyEventMessages fills the input and output of an LLM span from its gen_ai message and choice events
+// Instrumentations recording prompts as events leave the prompt attributes empty, so events are only used
+// when the attributes produced no messages. The input is the text of the system and user messages, the
+// output the content of the final choice, and the messages are kept on the LLM data for chat rendering.
+func applyEventMessages(ampAttrs *AmpAttributes, events []SpanEvent) {
+	if !isEmptyContent(ampAttrs.Input) || !isEmptyContent(ampAttrs.Output) {
+		return
+	}
+	prompt, completion := extractEventMessages(events)
+	if len(prompt) == 0 && len(completion) == 0 {
+		return
+	}
+
+	var inputs []string
+	for _, message := range prompt {
+		if (message.Role == "system" || message.Role == "user") && message.Content != "" {
+			inputs = append(inputs, message.Content)
+		}
+	}
+	if len(inputs) > 0 {
+		ampAttrs.Input = strings.Join(inputs, "\n\n")
+	}
+	if len(completion) > 0 {
+		final := completion[len(completion)-1]
+		if final.Content != "" {
+			ampAttrs.Output = final.Content
+		} else if len(final.ToolCalls) > 0 {
+			// A choice made of tool calls keeps its structure, as the attribute-based completions do
+			ampAttrs.Output = []PromptMessage{final}
+		}
+	}
+
+	if llmData, ok := ampAttrs.Data.(LLMData); ok {
+		llmData.Messages = append(prompt, completion...)
+		ampAttrs.Data = llmData
+	}
+}
+
+// isEmptyContent reports whether an extracted input or output holds nothing
+func isEmptyContent(content interface{}) bool {
+	switch value := content.(type) {
+	case nil:
+		return true
+	case string:
+		return value == ""
+	case []PromptMessage:
+		return len(value) == 0
+	}
+	return false
+}
+
+// extractEventMessages reconstructs the prompt messages and completion choices of a span from its events
+func extractEventMessages(events []SpanEvent) (prompt []PromptMessage, completion []PromptMessage) {
+	for _, event := range events {
+		if role, ok := genAIMessageEventRoles[event.Name]; ok {
+			prompt = append(prompt, eventMessage(eventBody(event.Attributes), role))
+			continue
+		}
+		if event.Name != genAIChoiceEvent {
+			continue
+		}
+		body := eventBody(event.Attributes)
+		// The choice wraps its message, older instrumentations flatten it into the event
+		if message := decodeJSONObject(body["message"]); message != nil {
+			body = message
+		}
+		completion = append(completion, eventMessage(body, "assistant"))
+	}
+	return prompt, completion
+}
+
+// eventBody returns the attributes of an event merged with its JSON encoded body, if any
+func eventBody(attrs map[string]interface{}) map[string]interface{} {
+	body := make(map[string]interface{}, len(attrs))
+	for key, value := range attrs {
+		body[key] = value
+	}
+	if encoded := decodeJSONObject(attrs[genAIEventContentAttribute]); encoded != nil {
+		for key, value := range encoded {
+			body[key] = value
+		}
+	}
+	return body
+}
+
+// eventMessage builds a message from an event body
+func eventMessage(body map[string]interface{}, defaultRole string) PromptMessage {
+	message := PromptMessage{Role: defaultRole}
+	if role, ok := body["role"].(string); ok && role != "" {
+		message.Role = role
+	}
+	message.Content = eventContentText(body["content"])
+	message.ToolCalls = eventToolCalls(body["tool_calls"])
+	return message
+}
+
+// eventContentText returns the text of a message content, which is a string or a list of parts
+func eventContentText(content interface{}) string {
+	switch value := content.(type) {
+	case string:
+		return value
+	case []interface{}:
+		var texts []string
+		for _, part := range value {
+			if text := eventContentText(part); text != "" {
+				texts = append(texts, text)
+			}
+		}
+		return strings.Join(texts, "\n")
+	case map[string]interface{}:
+		for _, key := range []string{"text", "content"} {
+			if text, ok := value[key].(string); ok {
+				return text
+			}
+		}
+	}
+	return ""
+}
+
+// eventToolCalls returns the tool calls of a message, given as a list or its JSON encoding
+func eventToolCalls(raw interface{}) []ToolCall {
+	items, ok := raw.([]interface{})
+	if !ok {
+		encoded, isString := raw.(string)
+		if !isString || unmarshalAttribute(encoded, &items) != nil {
+			return nil
+		}
+	}
+
+	var toolCalls []ToolCall
+	for _, item := range items {
+		call, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		toolCall := ToolCall{}
+		toolCall.ID, _ = call["id"].(string)
+		function, _ := call["function"].(map[string]interface{})
+		if function == nil {
+			function = call
+		}
+		toolCall.Name, _ = function["name"].(string)
+		switch arguments := function["arguments"].(type) {
+		case string:
+			toolCall.Arguments = arguments
+		case nil:
+		default:
+			if encoded, err := json.Marshal(arguments); err == nil {
+				toolCall.Arguments = string(encoded)
+			}
+		}
+		if toolCall.Name != "" {
+			toolCalls = append(toolCalls, toolCall)
+		}
+	}
+	return toolCalls
+}
+
+// decodeJSONObject returns a map value or decodes a JSON encoded object, nil otherwise
+func decodeJSONObject(value interface{}) map[string]interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return v
+	case string:
+		var decoded map[string]interface{}
+		if unmarshalAttribute(v, &decoded) == nil {
+			return decoded
+		}
+	}
+	return nil
+}
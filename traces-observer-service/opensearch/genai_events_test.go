@@ -0,0 +1,180 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package opensearch
+
+import (
+	"reflect"
+	"testing"
+)
+
+// eventLLMSource returns an LLM span document whose prompt and completion are only recorded as events
+func eventLLMSource(attributes map[string]interface{}, events ...map[string]interface{}) map[string]interface{} {
+	attrs := map[string]interface{}{"traceloop.span.kind": "llm", "gen_ai.request.model": "gpt-4o"}
+	for key, value := range attributes {
+		attrs[key] = value
+	}
+	raw := make([]interface{}, 0, len(events))
+	for _, event := range events {
+		raw = append(raw, event)
+	}
+	return map[string]interface{}{
+		"traceId":    "trace-1",
+		"spanId":     "llm",
+		"name":       "chat gpt-4o",
+		"attributes": attrs,
+		"events":     raw,
+	}
+}
+
+func genAIEvent(name string, attributes map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{"name": name, "time": "2025-11-03T10:00:00Z", "attributes": attributes}
+}
+
+func TestParseSpanExtractsEventMessages(t *testing.T) {
+	span := ProcessDocument(eventLLMSource(nil,
+		genAIEvent("gen_ai.system.message", map[string]interface{}{"content": "You book flights."}),
+		genAIEvent("gen_ai.user.message", map[string]interface{}{"content": []interface{}{
+			map[string]interface{}{"type": "text", "text": "Find a flight"},
+			map[string]interface{}{"type": "text", "text": "to Colombo"},
+		}}),
+		genAIEvent("gen_ai.assistant.message", map[string]interface{}{
+			"tool_calls": []interface{}{map[string]interface{}{
+				"id":       "call-1",
+				"function": map[string]interface{}{"name": "search_flights", "arguments": map[string]interface{}{"to": "CMB"}},
+			}},
+		}),
+		genAIEvent("gen_ai.tool.message", map[string]interface{}{"content": "UL 504", "id": "call-1"}),
+		genAIEvent("exception", map[string]interface{}{"exception.message": "ignored"}),
+		genAIEvent("gen_ai.choice", map[string]interface{}{"index": float64(0), "message": `{"role":"assistant","content":"UL 504 departs at 10:00"}`}),
+	))
+
+	if span.AmpAttributes.Input != "You book flights.\n\nFind a flight\nto Colombo" {
+		t.Errorf("input = %#v, want the system and user messages", span.AmpAttributes.Input)
+	}
+	if span.AmpAttributes.Output != "UL 504 departs at 10:00" {
+		t.Errorf("output = %#v, want the content of the final choice", span.AmpAttributes.Output)
+	}
+	want := []PromptMessage{
+		{Role: "system", Content: "You book flights."},
+		{Role: "user", Content: "Find a flight\nto Colombo"},
+		{Role: "assistant", ToolCalls: []ToolCall{{ID: "call-1", Name: "search_flights", Arguments: `{"to":"CMB"}`}}},
+		{Role: "tool", Content: "UL 504"},
+		{Role: "assistant", Content: "UL 504 departs at 10:00"},
+	}
+	if messages := span.AmpAttributes.Data.(LLMData).Messages; !reflect.DeepEqual(messages, want) {
+		t.Errorf("messages = %+v, want %+v", messages, want)
+	}
+}
+
+func TestParseSpanExtractsToolCallChoice(t *testing.T) {
+	span := ProcessDocument(eventLLMSource(nil,
+		genAIEvent("gen_ai.user.message", map[string]interface{}{"gen_ai.event.content": `{"role":"user","content":"Weather in Kandy?"}`}),
+		// Flattened choice with the tool calls encoded as JSON
+		genAIEvent("gen_ai.choice", map[string]interface{}{
+			"finish_reason": "tool_calls",
+			"tool_calls":    `[{"id":"call-1","type":"function","function":{"name":"get_weather","arguments":"{\"city\":\"Kandy\"}"}}]`,
+		}),
+	))
+
+	if span.AmpAttributes.Input != "Weather in Kandy?" {
+		t.Errorf("input = %#v, want the message of the encoded event body", span.AmpAttributes.Input)
+	}
+	want := []PromptMessage{{Role: "assistant", ToolCalls: []ToolCall{{ID: "call-1", Name: "get_weather", Arguments: `{"city":"Kandy"}`}}}}
+	if !reflect.DeepEqual(span.AmpAttributes.Output, want) {
+		t.Errorf("output = %#v, want the tool call choice kept as a message", span.AmpAttributes.Output)
+	}
+}
+
+func TestParseSpanPrefersPromptAttributes(t *testing.T) {
+	span := ProcessDocument(eventLLMSource(
+		map[string]interface{}{
+			"gen_ai.prompt.0.role":        "user",
+			"gen_ai.prompt.0.content":     "From attributes",
+			"gen_ai.completion.0.role":    "assistant",
+			"gen_ai.completion.0.content": "Answer from attributes",
+		},
+		genAIEvent("gen_ai.user.message", map[string]interface{}{"content": "From events"}),
+		genAIEvent("gen_ai.choice", map[string]interface{}{"message": map[string]interface{}{"content": "Answer from events"}}),
+	))
+
+	if messages := span.AmpAttributes.Data.(LLMData).Messages; messages != nil {
+		t.Errorf("messages = %+v, want events ignored when the attributes hold the prompt", messages)
+	}
+	if input, ok := span.AmpAttributes.Input.([]PromptMessage); !ok || input[0].Content != "From attributes" {
+		t.Errorf("input = %#v, want the prompt attributes", span.AmpAttributes.Input)
+	}
+}
+
+func TestParseSpanWithoutMessageEvents(t *testing.T) {
+	span := ProcessDocument(eventLLMSource(nil, genAIEvent("gen_ai.content.chunk", nil)))
+
+	if !isEmptyContent(span.AmpAttributes.Input) || !isEmptyContent(span.AmpAttributes.Output) || span.AmpAttributes.Data.(LLMData).Messages != nil {
+		t.Errorf("amp attributes = %+v, want no input, output or messages", span.AmpAttributes)
+	}
+}
+
+func TestEventToolCalls(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  interface{}
+		want []ToolCall
+	}{
+		{
+			name: "function calls",
+			raw:  []interface{}{map[string]interface{}{"id": "1", "function": map[string]interface{}{"name": "search", "arguments": `{"q":"x"}`}}},
+			want: []ToolCall{{ID: "1", Name: "search", Arguments: `{"q":"x"}`}},
+		},
+		{
+			name: "flattened calls",
+			raw:  []interface{}{map[string]interface{}{"id": "2", "name": "lookup", "arguments": map[string]interface{}{"id": float64(7)}}},
+			want: []ToolCall{{ID: "2", Name: "lookup", Arguments: `{"id":7}`}},
+		},
+		{
+			name: "calls without a name are skipped",
+			raw:  `[{"id":"3","function":{"arguments":"{}"}}, "not a call", {"name":"ping"}]`,
+			want: []ToolCall{{Name: "ping"}},
+		},
+		{name: "invalid JSON", raw: "[{"},
+		{name: "no calls", raw: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := eventToolCalls(tt.raw); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("eventToolCalls() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEventContentText(t *testing.T) {
+	tests := []struct {
+		content interface{}
+		want    string
+	}{
+		{"plain", "plain"},
+		{map[string]interface{}{"text": "part"}, "part"},
+		{map[string]interface{}{"content": "nested"}, "nested"},
+		{[]interface{}{"a", map[string]interface{}{"type": "image_url"}, map[string]interface{}{"text": "b"}}, "a\nb"},
+		{float64(3), ""},
+		{nil, ""},
+	}
+	for _, tt := range tests {
+		if got := eventContentText(tt.content); got != tt.want {
+			t.Errorf("eventContentText(%#v) = %q, want %q", tt.content, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,174 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package opensearch
+
+import (
+	"strings"
+)
+
+// GuardrailField is the document field holding the queryable summary of a guardrail check
+// It is written at ingestion so that traces with violations can be found without parsing AmpAttributes
+const GuardrailField = "guardrail"
+
+// guardrailAttributePrefixes lists the attribute namespaces of guardrail spans
+var guardrailAttributePrefixes = []string{"guardrail.", "llm.guardrail."}
+
+// guardrailAttribute returns the first attribute set for a field under the guardrail namespaces
+func guardrailAttribute(attrs map[string]interface{}, fields ...string) (interface{}, bool) {
+	for _, field := range fields {
+		for _, prefix := range guardrailAttributePrefixes {
+			if value, ok := attrs[prefix+field]; ok && value != nil {
+				return value, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// hasGuardrailAttributes checks whether a span reports a guardrail check
+func hasGuardrailAttributes(attrs map[string]interface{}) bool {
+	if _, ok := attrs["llm.guardrail"]; ok {
+		return true
+	}
+	for key := range attrs {
+		for _, prefix := range guardrailAttributePrefixes {
+			if strings.HasPrefix(key, prefix) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// populateGuardrailAttributes extracts and populates guardrail-specific attributes
+func populateGuardrailAttributes(ampAttrs *AmpAttributes, attrs map[string]interface{}, spanName string) {
+	guardrailData := GuardrailData{Name: spanName}
+	if name, ok := guardrailAttribute(attrs, "name", "id"); ok {
+		if nameStr, ok := asString(name); ok && nameStr != "" {
+			guardrailData.Name = nameStr
+		}
+	} else if name, ok := asString(attrs["llm.guardrail"]); ok && name != "" {
+		guardrailData.Name = name
+	}
+
+	if rules, ok := guardrailAttribute(attrs, "triggered_rules", "rules_triggered", "violations"); ok {
+		guardrailData.TriggeredRules = stringList(rules)
+	}
+	if category, ok := guardrailAttribute(attrs, "blocked_category", "category"); ok {
+		guardrailData.BlockedCategory, _ = asString(category)
+	}
+	guardrailData.Verdict = guardrailVerdict(attrs, len(guardrailData.TriggeredRules) > 0)
+
+	if input, ok := guardrailAttribute(attrs, "input"); ok {
+		ampAttrs.Input = input
+	}
+	if output, ok := guardrailAttribute(attrs, "output"); ok {
+		ampAttrs.Output = output
+	}
+	ampAttrs.Data = guardrailData
+}
+
+// guardrailVerdict normalises the outcome reported by a guardrail span to pass or fail
+// Guardrails report verdicts, actions or flags, and a check that triggered rules without any of them failed
+func guardrailVerdict(attrs map[string]interface{}, triggered bool) string {
+	if value, ok := guardrailAttribute(attrs, "verdict", "result", "action", "decision", "status"); ok {
+		if verdict, ok := asString(value); ok {
+			switch strings.ToLower(verdict) {
+			case "pass", "passed", "allow", "allowed", "ok", "safe", "none":
+				return GuardrailVerdictPass
+			case "fail", "failed", "block", "blocked", "deny", "denied", "reject", "rejected", "violation", "intervened", "unsafe", "flagged":
+				return GuardrailVerdictFail
+			}
+		}
+	}
+	if value, ok := guardrailAttribute(attrs, "passed"); ok {
+		if passed, ok := asBool(value); ok {
+			if passed {
+				return GuardrailVerdictPass
+			}
+			return GuardrailVerdictFail
+		}
+	}
+	if value, ok := guardrailAttribute(attrs, "triggered", "blocked", "violated", "flagged"); ok {
+		if failed, ok := asBool(value); ok {
+			if failed {
+				return GuardrailVerdictFail
+			}
+			return GuardrailVerdictPass
+		}
+	}
+	if triggered {
+		return GuardrailVerdictFail
+	}
+	return ""
+}
+
+// stringList reads a list attribute given as an array, a JSON encoded array or a comma separated string
+func stringList(value interface{}) []string {
+	var items []interface{}
+	switch v := value.(type) {
+	case []interface{}:
+		items = v
+	case string:
+		if unmarshalAttribute(v, &items) != nil {
+			items = nil
+			for _, item := range strings.Split(v, ",") {
+				items = append(items, strings.TrimSpace(item))
+			}
+		}
+	}
+
+	var result []string
+	for _, item := range items {
+		if str, ok := item.(string); ok && str != "" {
+			result = append(result, str)
+		}
+	}
+	return result
+}
+
+// guardrailDataOf returns the guardrail data of a span if it is a guardrail span
+func guardrailDataOf(span Span) (GuardrailData, bool) {
+	if span.AmpAttributes == nil || span.AmpAttributes.Kind != string(SpanTypeGuardrail) {
+		return GuardrailData{}, false
+	}
+	guardrailData, ok := span.AmpAttributes.Data.(GuardrailData)
+	return guardrailData, ok
+}
+
+// CountGuardrailViolations counts the failed guardrail checks of a trace
+func CountGuardrailViolations(spans []Span) int {
+	count := 0
+	for _, span := range spans {
+		if guardrailData, ok := guardrailDataOf(span); ok && guardrailData.Verdict == GuardrailVerdictFail {
+			count++
+		}
+	}
+	return count
+}
+
+// buildGuardrailMapping builds the mapping of the guardrail field
+func buildGuardrailMapping() map[string]interface{} {
+	return map[string]interface{}{
+		"properties": map[string]interface{}{
+			"name":            map[string]interface{}{"type": "keyword"},
+			"verdict":         map[string]interface{}{"type": "keyword"},
+			"triggeredRules":  map[string]interface{}{"type": "keyword"},
+			"blockedCategory": map[string]interface{}{"type": "keyword"},
+		},
+	}
+}
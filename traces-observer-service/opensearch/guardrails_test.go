@@ -0,0 +1,181 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package opensearch
+
+import (
+	"reflect"
+	"testing"
+)
+
+// guardrailSource returns a span document with the given attributes
+func guardrailSource(spanID string, attributes map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"traceId":    "trace-1",
+		"spanId":     spanID,
+		"name":       "content_filter",
+		"startTime":  "2025-11-03T10:00:00Z",
+		"endTime":    "2025-11-03T10:00:01Z",
+		"attributes": attributes,
+	}
+}
+
+func TestDetermineSpanTypeDetectsGuardrails(t *testing.T) {
+	tests := []struct {
+		name  string
+		attrs map[string]interface{}
+		want  SpanType
+	}{
+		{"guardrail namespace", map[string]interface{}{"guardrail.name": "pii"}, SpanTypeGuardrail},
+		{"llm guardrail namespace", map[string]interface{}{"llm.guardrail.verdict": "pass"}, SpanTypeGuardrail},
+		{"llm guardrail attribute", map[string]interface{}{"llm.guardrail": "toxicity"}, SpanTypeGuardrail},
+		{"guardrail running a model", map[string]interface{}{"traceloop.span.kind": "llm", "guardrail.name": "pii"}, SpanTypeGuardrail},
+		{"llm span", map[string]interface{}{"traceloop.span.kind": "llm"}, SpanTypeLLM},
+		{"similar prefix", map[string]interface{}{"traceloop.span.kind": "llm", "guardrails_version": "1"}, SpanTypeLLM},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DetermineSpanType(Span{Attributes: tt.attrs}); got != tt.want {
+				t.Errorf("DetermineSpanType() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseSpanExtractsGuardrailData(t *testing.T) {
+	tests := []struct {
+		name  string
+		attrs map[string]interface{}
+		want  GuardrailData
+	}{
+		{
+			name: "failed check",
+			attrs: map[string]interface{}{
+				"guardrail.name":             "pii",
+				"guardrail.verdict":          "BLOCKED",
+				"guardrail.triggered_rules":  []interface{}{"email", "phone"},
+				"guardrail.blocked_category": "pii",
+			},
+			want: GuardrailData{Name: "pii", Verdict: GuardrailVerdictFail, TriggeredRules: []string{"email", "phone"}, BlockedCategory: "pii"},
+		},
+		{
+			name:  "passed check",
+			attrs: map[string]interface{}{"llm.guardrail.id": "toxicity", "llm.guardrail.action": "allow"},
+			want:  GuardrailData{Name: "toxicity", Verdict: GuardrailVerdictPass},
+		},
+		{
+			name:  "name from the llm guardrail attribute",
+			attrs: map[string]interface{}{"llm.guardrail": "jailbreak", "guardrail.passed": false},
+			want:  GuardrailData{Name: "jailbreak", Verdict: GuardrailVerdictFail},
+		},
+		{
+			name:  "span name without a guardrail name",
+			attrs: map[string]interface{}{"guardrail.triggered": "false"},
+			want:  GuardrailData{Name: "content_filter", Verdict: GuardrailVerdictPass},
+		},
+		{
+			name:  "rules as a JSON array",
+			attrs: map[string]interface{}{"guardrail.name": "pii", "guardrail.violations": `["ssn"]`},
+			want:  GuardrailData{Name: "pii", Verdict: GuardrailVerdictFail, TriggeredRules: []string{"ssn"}},
+		},
+		{
+			name:  "rules as a comma separated string",
+			attrs: map[string]interface{}{"guardrail.name": "pii", "guardrail.rules_triggered": "ssn, email", "guardrail.category": "pii"},
+			want:  GuardrailData{Name: "pii", Verdict: GuardrailVerdictFail, TriggeredRules: []string{"ssn", "email"}, BlockedCategory: "pii"},
+		},
+		{
+			name:  "verdict outranks triggered rules",
+			attrs: map[string]interface{}{"guardrail.name": "pii", "guardrail.verdict": "pass", "guardrail.triggered_rules": []interface{}{"email"}},
+			want:  GuardrailData{Name: "pii", Verdict: GuardrailVerdictPass, TriggeredRules: []string{"email"}},
+		},
+		{
+			name:  "no verdict",
+			attrs: map[string]interface{}{"guardrail.name": "pii", "guardrail.verdict": "unknown"},
+			want:  GuardrailData{Name: "pii"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			span := parseSpan(guardrailSource("guard", tt.attrs))
+
+			got, ok := guardrailDataOf(span)
+			if !ok {
+				t.Fatalf("guardrailDataOf() found no guardrail data in %+v", span.AmpAttributes)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("guardrail data = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseSpanExtractsGuardrailInputAndOutput(t *testing.T) {
+	span := parseSpan(guardrailSource("guard", map[string]interface{}{
+		"guardrail.name":   "pii",
+		"guardrail.input":  "my email is alice@example.com",
+		"guardrail.output": "my email is [REDACTED]",
+	}))
+
+	if span.AmpAttributes.Input != "my email is alice@example.com" || span.AmpAttributes.Output != "my email is [REDACTED]" {
+		t.Errorf("input, output = %v, %v, want the checked and returned content", span.AmpAttributes.Input, span.AmpAttributes.Output)
+	}
+}
+
+func TestProcessDocumentIndexesGuardrailData(t *testing.T) {
+	tests := []struct {
+		name  string
+		attrs map[string]interface{}
+		want  interface{}
+	}{
+		{
+			name:  "guardrail span",
+			attrs: map[string]interface{}{"guardrail.name": "pii", "guardrail.verdict": "fail"},
+			want:  GuardrailData{Name: "pii", Verdict: GuardrailVerdictFail},
+		},
+		{
+			name:  "llm span",
+			attrs: map[string]interface{}{"traceloop.span.kind": "llm"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := guardrailSource("span", tt.attrs)
+
+			ProcessDocument(source)
+
+			if got := source[GuardrailField]; !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("%s = %+v, want %+v", GuardrailField, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCountGuardrailViolations(t *testing.T) {
+	spans := []Span{
+		parseSpan(guardrailSource("failed", map[string]interface{}{"guardrail.name": "pii", "guardrail.verdict": "fail"})),
+		parseSpan(guardrailSource("passed", map[string]interface{}{"guardrail.name": "toxicity", "guardrail.verdict": "pass"})),
+		parseSpan(guardrailSource("flagged", map[string]interface{}{"llm.guardrail.flagged": true})),
+		parseSpan(guardrailSource("llm", map[string]interface{}{"traceloop.span.kind": "llm"})),
+		{SpanID: "unparsed"},
+	}
+
+	if got := CountGuardrailViolations(spans); got != 2 {
+		t.Errorf("CountGuardrailViolations() = %d, want 2", got)
+	}
+	if got := CountGuardrailViolations(nil); got != 0 {
+		t.Errorf("CountGuardrailViolations(nil) = %d, want 0", got)
+	}
+}
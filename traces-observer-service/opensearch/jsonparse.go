@@ -0,0 +1,151 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package opensearch
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+)
+
+// maxJSONDepth bounds the nesting of JSON attribute values decoded during extraction
+const maxJSONDepth = 100
+
+// maxPooledParseBuffer bounds the size of the parse buffers kept for reuse
+const maxPooledParseBuffer = 1024 * 1024
+
+var (
+	errParseLimit = errors.New("JSON attribute exceeds the parse limit")
+	errParseDepth = errors.New("JSON attribute exceeds the nesting limit")
+)
+
+// parseBuffers holds the buffers JSON attribute strings are copied into for unmarshalling
+var parseBuffers = sync.Pool{
+	New: func() interface{} {
+		buffer := make([]byte, 0, 4096)
+		return &buffer
+	},
+}
+
+// unmarshalAttribute unmarshals a JSON encoded attribute value
+// Values above the parse limit or nested deeper than maxJSONDepth are rejected before anything is allocated,
+// extraction then treats them like values that are not JSON
+func unmarshalAttribute(value string, result interface{}) error {
+	if limit := getLimits().MaxParseBytes; limit > 0 && len(value) > limit {
+		return errParseLimit
+	}
+	if !withinJSONDepth(value, maxJSONDepth) {
+		return errParseDepth
+	}
+
+	bufferPtr := parseBuffers.Get().(*[]byte)
+	buffer := append((*bufferPtr)[:0], value...)
+	err := json.Unmarshal(buffer, result)
+	if cap(buffer) <= maxPooledParseBuffer {
+		*bufferPtr = buffer[:0]
+		parseBuffers.Put(bufferPtr)
+	}
+	return err
+}
+
+// decodeJSONArray decodes the elements of a JSON array one at a time from at most the parse limit
+// Returns false when the value is not an array, is malformed or was cut off by the limit;
+// the elements decoded before that point have been handed to yield
+func decodeJSONArray(value string, yield func(element json.RawMessage)) bool {
+	if limit := getLimits().MaxParseBytes; limit > 0 && len(value) > limit {
+		value = value[:limit]
+	}
+	if !withinJSONDepth(value, maxJSONDepth) {
+		return false
+	}
+
+	decoder := json.NewDecoder(strings.NewReader(value))
+	if token, err := decoder.Token(); err != nil || token != json.Delim('[') {
+		return false
+	}
+	for decoder.More() {
+		var element json.RawMessage
+		if err := decoder.Decode(&element); err != nil {
+			return false
+		}
+		yield(element)
+	}
+	_, err := decoder.Token()
+	return err == nil
+}
+
+// decodeJSONFields decodes the fields of a JSON object in the order they appear
+// Returns false when the value is not an object or is malformed; the fields decoded before that
+// point have been handed to yield
+func decodeJSONFields(value json.RawMessage, yield func(key string, value json.RawMessage)) bool {
+	decoder := json.NewDecoder(bytes.NewReader(value))
+	if token, err := decoder.Token(); err != nil || token != json.Delim('{') {
+		return false
+	}
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return false
+		}
+		key, ok := token.(string)
+		if !ok {
+			return false
+		}
+		var field json.RawMessage
+		if err := decoder.Decode(&field); err != nil {
+			return false
+		}
+		yield(key, field)
+	}
+	_, err := decoder.Token()
+	return err == nil
+}
+
+// withinJSONDepth checks that the arrays and objects of a JSON value are nested at most maxDepth levels deep
+func withinJSONDepth(value string, maxDepth int) bool {
+	depth := 0
+	inString := false
+	escaped := false
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		if inString {
+			switch {
+			case escaped:
+				escaped = false
+			case c == '\\':
+				escaped = true
+			case c == '"':
+				inString = false
+			}
+			continue
+		}
+		switch c {
+		case '"':
+			inString = true
+		case '{', '[':
+			depth++
+			if depth > maxDepth {
+				return false
+			}
+		case '}', ']':
+			depth--
+		}
+	}
+	return true
+}
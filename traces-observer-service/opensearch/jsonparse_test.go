@@ -0,0 +1,184 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package opensearch
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// withLimits applies size limits for the duration of a test or benchmark
+func withLimits(tb testing.TB, limits Limits) {
+	previous := getLimits()
+	SetLimits(limits)
+	tb.Cleanup(func() { SetLimits(previous) })
+}
+
+// hugeToolsJSON builds a JSON array of tool definitions of roughly the given size
+func hugeToolsJSON(size int) string {
+	var builder strings.Builder
+	builder.WriteString("[")
+	description := strings.Repeat("Searches the knowledge base and returns matching documents. ", 16)
+	for i := 0; builder.Len() < size; i++ {
+		if i > 0 {
+			builder.WriteString(",")
+		}
+		fmt.Fprintf(&builder, `{"name":"tool_%d","description":%q,"parameters":{"type":"object","properties":{"query":{"type":"string"}}}}`, i, description)
+	}
+	builder.WriteString("]")
+	return builder.String()
+}
+
+func TestParseToolsJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []ToolDefinition
+	}{
+		{
+			name:     "array of names",
+			input:    `["search", "calculator"]`,
+			expected: []ToolDefinition{{Name: "search"}, {Name: "calculator"}},
+		},
+		{
+			name:  "array of tool objects",
+			input: `[{"name": "search", "description": "Search the web", "parameters": {"type": "object"}}]`,
+			expected: []ToolDefinition{
+				{Name: "search", Description: "Search the web", Parameters: `{"type":"object"}`},
+			},
+		},
+		{
+			name:     "mixed array",
+			input:    `["search", {"name": "calculator"}]`,
+			expected: []ToolDefinition{{Name: "search"}, {Name: "calculator"}},
+		},
+		{
+			name:     "empty array",
+			input:    `[]`,
+			expected: []ToolDefinition{},
+		},
+		{
+			name:     "not json",
+			input:    "search",
+			expected: []ToolDefinition{{Name: "search"}},
+		},
+		{
+			name:     "cut off array",
+			input:    `["search", "calculator", {"name": "wea`,
+			expected: []ToolDefinition{{Name: "search"}, {Name: "calculator"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseToolsJSON(tt.input); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("parseToolsJSON() = %+v, want %+v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseToolsJSONOverParseLimit(t *testing.T) {
+	withLimits(t, Limits{MaxParseBytes: 64 * 1024})
+	toolsJSON := hugeToolsJSON(1024 * 1024)
+
+	for name, tools := range map[string][]ToolDefinition{
+		"tool list":  parseToolsJSON(toolsJSON),
+		"OTEL tools": parseOTELToolDefinitions(toolsJSON),
+	} {
+		if len(tools) == 0 {
+			t.Fatalf("%s: no tools decoded before the parse limit", name)
+		}
+		if tools[0].Name != "tool_0" || tools[0].Parameters == "" {
+			t.Errorf("%s: first tool = %+v, want tool_0 with parameters", name, tools[0])
+		}
+		var all []interface{}
+		if err := json.Unmarshal([]byte(toolsJSON), &all); err != nil {
+			t.Fatal(err)
+		}
+		if len(tools) >= len(all) {
+			t.Errorf("%s: decoded %d of %d tools, want only the tools before the limit", name, len(tools), len(all))
+		}
+	}
+}
+
+func TestUnmarshalAttributeGuards(t *testing.T) {
+	withLimits(t, Limits{MaxParseBytes: 1024})
+
+	var decoded map[string]interface{}
+	if err := unmarshalAttribute(`{"inputs": {"query": "weather"}}`, &decoded); err != nil {
+		t.Errorf("unmarshalAttribute() error = %v", err)
+	}
+	if err := unmarshalAttribute(`{"inputs": "`+strings.Repeat("x", 2048)+`"}`, &decoded); err != errParseLimit {
+		t.Errorf("unmarshalAttribute() error = %v, want errParseLimit", err)
+	}
+	deep := strings.Repeat("[", maxJSONDepth+1) + strings.Repeat("]", maxJSONDepth+1)
+	var items []interface{}
+	if err := unmarshalAttribute(deep, &items); err != errParseDepth {
+		t.Errorf("unmarshalAttribute() error = %v, want errParseDepth", err)
+	}
+	// Brackets inside strings do not count towards the depth
+	if err := unmarshalAttribute(`["`+strings.Repeat("[", maxJSONDepth+1)+`"]`, &items); err != nil {
+		t.Errorf("unmarshalAttribute() error = %v", err)
+	}
+}
+
+// BenchmarkHugeToolPayload compares decoding a pathological 8 MB tool list and tool input in one piece
+// with the limited decoding used during extraction; run with -benchmem to compare allocations
+func BenchmarkHugeToolPayload(b *testing.B) {
+	toolsJSON := hugeToolsJSON(8 * 1024 * 1024)
+	input := `{"inputs": ` + toolsJSON + `}`
+
+	b.Run("tools/unmarshal", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			var tools []map[string]interface{}
+			if err := json.Unmarshal([]byte(toolsJSON), &tools); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("tools/limited", func(b *testing.B) {
+		withLimits(b, DefaultLimits())
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if tools := parseToolsJSON(toolsJSON); len(tools) == 0 {
+				b.Fatal("no tools decoded")
+			}
+		}
+	})
+	b.Run("input/unmarshal", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			var inputMap map[string]interface{}
+			if err := json.Unmarshal([]byte(input), &inputMap); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("input/limited", func(b *testing.B) {
+		withLimits(b, DefaultLimits())
+		attrs := map[string]interface{}{"traceloop.entity.input": input}
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			extractSpanInputOutput(attrs)
+		}
+	})
+}
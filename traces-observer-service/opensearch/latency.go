@@ -0,0 +1,228 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package opensearch
+
+import (
+	"sort"
+	"time"
+)
+
+// interval is the time range of a span
+type interval struct {
+	start time.Time
+	end   time.Time
+}
+
+// spanInterval returns the time range of a span, derived from the duration when the end time is missing
+func spanInterval(span *Span) interval {
+	end := span.EndTime
+	if end.IsZero() {
+		end = span.StartTime.Add(time.Duration(span.DurationInNanos))
+	}
+	return interval{start: span.StartTime, end: end}
+}
+
+// mergedDuration returns the time covered by a set of possibly overlapping intervals
+func mergedDuration(intervals []interval) int64 {
+	if len(intervals) == 0 {
+		return 0
+	}
+	sort.Slice(intervals, func(i, j int) bool {
+		return intervals[i].start.Before(intervals[j].start)
+	})
+
+	var total time.Duration
+	current := intervals[0]
+	for _, next := range intervals[1:] {
+		if !next.start.After(current.end) {
+			if next.end.After(current.end) {
+				current.end = next.end
+			}
+			continue
+		}
+		total += current.end.Sub(current.start)
+		current = next
+	}
+	total += current.end.Sub(current.start)
+	return total.Nanoseconds()
+}
+
+// clipInterval limits an interval to a range, returning false when they do not overlap
+func clipInterval(value, bounds interval) (interval, bool) {
+	if value.start.Before(bounds.start) {
+		value.start = bounds.start
+	}
+	if value.end.After(bounds.end) {
+		value.end = bounds.end
+	}
+	return value, value.end.After(value.start)
+}
+
+// latencyScope collects the spans attributed to an agent span or the root of the trace
+type latencyScope struct {
+	latency  AgentLatency
+	interval interval
+	children []interval
+	llm      []interval
+	tools    []interval
+}
+
+// BuildLatencyBreakdown computes how the time of every agent in a trace was spent
+// Each LLM and tool span is attributed to its nearest agent ancestor, spans nested in an LLM or tool span
+// of the same kind are not counted again. Self time is the duration of the agent not covered by its
+// direct children, so that concurrent children are merged rather than summed.
+func BuildLatencyBreakdown(spans []Span) *LatencyBreakdown {
+	if len(spans) == 0 {
+		return nil
+	}
+
+	byID := make(map[string]*Span, len(spans))
+	for i := range spans {
+		byID[spans[i].SpanID] = &spans[i]
+	}
+	parentOf := func(span *Span) *Span {
+		parent, ok := byID[span.ParentSpanID]
+		if !ok || parent == span {
+			return nil
+		}
+		return parent
+	}
+
+	// Spans without a parent in the trace share the root scope
+	var topLevel []*Span
+	for i := range spans {
+		if parentOf(&spans[i]) == nil {
+			topLevel = append(topLevel, &spans[i])
+		}
+	}
+
+	var root *latencyScope
+	scopes := make(map[string]*latencyScope)
+	var order []*latencyScope
+	addScope := func(span *Span) *latencyScope {
+		scope := &latencyScope{
+			latency:  AgentLatency{SpanID: span.SpanID, Name: span.Name, DurationInNanos: span.DurationInNanos},
+			interval: spanInterval(span),
+		}
+		if span.AmpAttributes != nil {
+			if agentData, ok := span.AmpAttributes.Data.(AgentData); ok {
+				scope.latency.AgentName = agentData.Name
+			}
+		}
+		scopes[span.SpanID] = scope
+		order = append(order, scope)
+		return scope
+	}
+
+	if len(topLevel) == 1 {
+		root = addScope(topLevel[0])
+	} else {
+		root = &latencyScope{latency: AgentLatency{SpanID: SyntheticRootSpanID, Name: "root"}}
+		for i, span := range topLevel {
+			value := spanInterval(span)
+			if i == 0 || value.start.Before(root.interval.start) {
+				root.interval.start = value.start
+			}
+			if value.end.After(root.interval.end) {
+				root.interval.end = value.end
+			}
+			root.children = append(root.children, value)
+		}
+		root.latency.DurationInNanos = root.interval.end.Sub(root.interval.start).Nanoseconds()
+		order = append(order, root)
+	}
+	for i := range spans {
+		if spanKind(&spans[i]) == string(SpanTypeAgent) && scopes[spans[i].SpanID] == nil {
+			addScope(&spans[i])
+		}
+	}
+
+	for i := range spans {
+		span := &spans[i]
+		kind := spanKind(span)
+
+		// Direct children reduce the self time of their parent scope
+		if parent := parentOf(span); parent != nil {
+			if scope, ok := scopes[parent.SpanID]; ok {
+				scope.children = append(scope.children, spanInterval(span))
+			}
+		}
+
+		if kind != string(SpanTypeLLM) && kind != string(SpanTypeTool) {
+			continue
+		}
+
+		// Walk up to the nearest agent, skipping spans nested in a span of the same kind
+		scope := root
+		nested := false
+		for ancestor := parentOf(span); ancestor != nil; ancestor = parentOf(ancestor) {
+			ancestorKind := spanKind(ancestor)
+			if ancestorKind == kind {
+				nested = true
+			}
+			if ancestorKind == string(SpanTypeAgent) {
+				scope = scopes[ancestor.SpanID]
+				break
+			}
+		}
+		if nested {
+			continue
+		}
+
+		if kind == string(SpanTypeLLM) {
+			scope.latency.LLMCalls++
+			scope.llm = append(scope.llm, spanInterval(span))
+		} else {
+			scope.latency.ToolCalls++
+			scope.tools = append(scope.tools, spanInterval(span))
+		}
+	}
+
+	// The root comes first, agents follow in start order
+	sort.SliceStable(order[1:], func(i, j int) bool {
+		return order[i+1].interval.start.Before(order[j+1].interval.start)
+	})
+
+	breakdown := &LatencyBreakdown{Agents: make([]AgentLatency, 0, len(order))}
+	for _, scope := range order {
+		duration := scope.interval.end.Sub(scope.interval.start).Nanoseconds()
+		scope.latency.SelfTimeInNanos = max(duration-mergedDuration(clipIntervals(scope.children, scope.interval)), 0)
+		scope.latency.LLMTimeInNanos = mergedDuration(clipIntervals(scope.llm, scope.interval))
+		scope.latency.ToolTimeInNanos = mergedDuration(clipIntervals(scope.tools, scope.interval))
+		breakdown.Agents = append(breakdown.Agents, scope.latency)
+	}
+	return breakdown
+}
+
+// clipIntervals limits intervals to a range, so that spans running past their agent are not counted beyond it
+func clipIntervals(values []interval, bounds interval) []interval {
+	var clipped []interval
+	for _, value := range values {
+		if inside, ok := clipInterval(value, bounds); ok {
+			clipped = append(clipped, inside)
+		}
+	}
+	return clipped
+}
+
+// spanKind returns the semantic kind of a span
+func spanKind(span *Span) string {
+	if span.AmpAttributes == nil {
+		return ""
+	}
+	return span.AmpAttributes.Kind
+}
@@ -0,0 +1,158 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package opensearch
+
+import (
+	"testing"
+	"time"
+)
+
+var latencyEpoch = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// latencySpan creates a span of a kind running from start to end milliseconds after the epoch
+func latencySpan(id, parent string, kind SpanType, start, end int) Span {
+	span := Span{
+		SpanID:          id,
+		ParentSpanID:    parent,
+		Name:            id,
+		StartTime:       latencyEpoch.Add(time.Duration(start) * time.Millisecond),
+		EndTime:         latencyEpoch.Add(time.Duration(end) * time.Millisecond),
+		DurationInNanos: (time.Duration(end-start) * time.Millisecond).Nanoseconds(),
+		AmpAttributes:   &AmpAttributes{Kind: string(kind)},
+	}
+	if kind == SpanTypeAgent {
+		span.AmpAttributes.Data = AgentData{Name: id + "-agent"}
+	}
+	return span
+}
+
+func ms(n int) int64 {
+	return (time.Duration(n) * time.Millisecond).Nanoseconds()
+}
+
+func TestBuildLatencyBreakdown(t *testing.T) {
+	tests := []struct {
+		name  string
+		spans []Span
+		want  []AgentLatency
+	}{
+		{
+			name: "overlapping children are merged",
+			spans: []Span{
+				latencySpan("a", "", SpanTypeAgent, 0, 100),
+				latencySpan("llm-1", "a", SpanTypeLLM, 10, 50),
+				latencySpan("llm-2", "a", SpanTypeLLM, 20, 60),
+				latencySpan("tool", "a", SpanTypeTool, 55, 70),
+			},
+			want: []AgentLatency{
+				{SpanID: "a", Name: "a", AgentName: "a-agent", DurationInNanos: ms(100), SelfTimeInNanos: ms(40),
+					LLMTimeInNanos: ms(50), LLMCalls: 2, ToolTimeInNanos: ms(15), ToolCalls: 1},
+			},
+		},
+		{
+			name: "fully nested children are counted once",
+			spans: []Span{
+				latencySpan("a", "", SpanTypeAgent, 0, 100),
+				latencySpan("tool", "a", SpanTypeTool, 10, 60),
+				latencySpan("llm-in-tool", "tool", SpanTypeLLM, 20, 30),
+				latencySpan("llm", "a", SpanTypeLLM, 70, 90),
+				latencySpan("llm-in-llm", "llm", SpanTypeLLM, 75, 85),
+			},
+			want: []AgentLatency{
+				{SpanID: "a", Name: "a", AgentName: "a-agent", DurationInNanos: ms(100), SelfTimeInNanos: ms(30),
+					LLMTimeInNanos: ms(30), LLMCalls: 2, ToolTimeInNanos: ms(50), ToolCalls: 1},
+			},
+		},
+		{
+			name: "children out of the bounds of their parent are clipped",
+			spans: []Span{
+				latencySpan("a", "", SpanTypeAgent, 100, 200),
+				latencySpan("llm", "a", SpanTypeLLM, 50, 150),
+				latencySpan("tool", "a", SpanTypeTool, 180, 260),
+				latencySpan("late", "a", SpanTypeTool, 300, 400),
+			},
+			want: []AgentLatency{
+				{SpanID: "a", Name: "a", AgentName: "a-agent", DurationInNanos: ms(100), SelfTimeInNanos: ms(30),
+					LLMTimeInNanos: ms(50), LLMCalls: 1, ToolTimeInNanos: ms(20), ToolCalls: 2},
+			},
+		},
+		{
+			name: "a child covering its parent leaves no self time",
+			spans: []Span{
+				latencySpan("a", "", SpanTypeAgent, 10, 20),
+				latencySpan("llm", "a", SpanTypeLLM, 0, 30),
+			},
+			want: []AgentLatency{
+				{SpanID: "a", Name: "a", AgentName: "a-agent", DurationInNanos: ms(10), SelfTimeInNanos: 0,
+					LLMTimeInNanos: ms(10), LLMCalls: 1},
+			},
+		},
+		{
+			name: "calls are attributed to the nearest agent",
+			spans: []Span{
+				latencySpan("outer", "", SpanTypeAgent, 0, 100),
+				latencySpan("inner", "outer", SpanTypeAgent, 10, 60),
+				latencySpan("inner-llm", "inner", SpanTypeLLM, 20, 30),
+				latencySpan("outer-llm", "outer", SpanTypeLLM, 70, 80),
+			},
+			want: []AgentLatency{
+				{SpanID: "outer", Name: "outer", AgentName: "outer-agent", DurationInNanos: ms(100), SelfTimeInNanos: ms(40),
+					LLMTimeInNanos: ms(10), LLMCalls: 1},
+				{SpanID: "inner", Name: "inner", AgentName: "inner-agent", DurationInNanos: ms(50), SelfTimeInNanos: ms(40),
+					LLMTimeInNanos: ms(10), LLMCalls: 1},
+			},
+		},
+		{
+			name: "spans without a parent in the trace share a synthetic root",
+			spans: []Span{
+				latencySpan("llm", "", SpanTypeLLM, 0, 10),
+				latencySpan("tool", "missing", SpanTypeTool, 20, 50),
+			},
+			want: []AgentLatency{
+				{SpanID: SyntheticRootSpanID, Name: "root", DurationInNanos: ms(50), SelfTimeInNanos: ms(10),
+					LLMTimeInNanos: ms(10), LLMCalls: 1, ToolTimeInNanos: ms(30), ToolCalls: 1},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			breakdown := BuildLatencyBreakdown(tt.spans)
+			if breakdown == nil || len(breakdown.Agents) != len(tt.want) {
+				t.Fatalf("breakdown = %+v, want %d agents", breakdown, len(tt.want))
+			}
+			for i, want := range tt.want {
+				if got := breakdown.Agents[i]; got != want {
+					t.Errorf("agent %d = %+v\nwant %+v", i, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestBuildLatencyBreakdownDerivesMissingEndTimes(t *testing.T) {
+	agent := latencySpan("a", "", SpanTypeAgent, 0, 100)
+	llm := latencySpan("llm", "a", SpanTypeLLM, 10, 40)
+	llm.EndTime = time.Time{}
+
+	breakdown := BuildLatencyBreakdown([]Span{agent, llm})
+	if got := breakdown.Agents[0]; got.LLMTimeInNanos != ms(30) || got.SelfTimeInNanos != ms(70) {
+		t.Errorf("agent = %+v, want the end of the LLM span derived from its duration", got)
+	}
+	if BuildLatencyBreakdown(nil) != nil {
+		t.Error("a breakdown was built for a trace without spans")
+	}
+}
@@ -0,0 +1,325 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package opensearch
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TraceWriteAlias is the alias of the trace index of the current day, moved by the lifecycle manager at midnight UTC
+// The service itself writes spans to the daily index of their start time so that a re-delivered span lands in the same index
+const TraceWriteAlias = "otel-traces-write"
+
+// LifecycleConfig holds the index lifecycle settings
+type LifecycleConfig struct {
+	RetentionDays int                   // Spans without a retention of their own and rollups older than this are deleted, kept when zero
+	CheckInterval time.Duration         // Interval of the rollover and retention checks
+	Throttle      DeleteByQueryThrottle // Pace of the deletion of expired spans from indices still holding other spans
+}
+
+// LifecycleManager creates the daily trace indices, moves the write alias and enforces retention
+// Retention runs apart from the rollover, so that a long throttled deletion does not hold back the write alias
+type LifecycleManager struct {
+	client *Client
+	config LifecycleConfig
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu    sync.Mutex
+	stats RetentionStats
+}
+
+// RetentionStats reports the retention runs of the lifecycle manager
+type RetentionStats struct {
+	Running        bool       `json:"running"`
+	LastRunAt      *time.Time `json:"lastRunAt,omitempty"`
+	IndicesDeleted uint64     `json:"indicesDeleted"`
+	SpansDeleted   uint64     `json:"spansDeleted"`
+	Failures       uint64     `json:"failures"`
+	LastError      string     `json:"lastError,omitempty"`
+}
+
+// NewLifecycleManager creates a lifecycle manager
+func (c *Client) NewLifecycleManager(cfg LifecycleConfig) *LifecycleManager {
+	if cfg.CheckInterval <= 0 {
+		cfg.CheckInterval = time.Hour
+	}
+	return &LifecycleManager{
+		client: c,
+		config: cfg,
+	}
+}
+
+// Start installs the index template if missing, prepares the write index and starts the periodic checks
+// The first retention run starts in the background
+func (m *LifecycleManager) Start(ctx context.Context) error {
+	if err := m.ensureIndexTemplate(ctx); err != nil {
+		return err
+	}
+	if err := m.check(ctx); err != nil {
+		return err
+	}
+	runCtx, cancel := context.WithCancel(context.Background())
+	m.cancel = cancel
+	m.wg.Add(2)
+	go m.run(runCtx)
+	go m.retain(runCtx)
+	return nil
+}
+
+// Stop stops the periodic checks, a running deletion of expired spans is left to complete in OpenSearch
+func (m *LifecycleManager) Stop() {
+	m.cancel()
+	m.wg.Wait()
+}
+
+// run performs the rollover checks on every interval
+func (m *LifecycleManager) run(ctx context.Context) {
+	defer m.wg.Done()
+
+	timer := time.NewTimer(m.nextCheckDelay(time.Now()))
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-timer.C:
+			if err := m.check(ctx); err != nil && ctx.Err() == nil {
+				slog.Error("Index lifecycle check failed", "error", err)
+			}
+			timer.Reset(m.nextCheckDelay(time.Now()))
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// retain enforces retention right away and then on every check interval
+func (m *LifecycleManager) retain(ctx context.Context) {
+	defer m.wg.Done()
+
+	for {
+		if err := m.enforceRetention(ctx, time.Now().UTC()); err != nil && ctx.Err() == nil {
+			slog.Error("Index retention failed", "error", err)
+		}
+		select {
+		case <-time.After(m.config.CheckInterval):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// nextCheckDelay returns the delay until the next check, which also runs right after midnight UTC
+// so that the write alias moves to the new daily index without waiting for the check interval
+func (m *LifecycleManager) nextCheckDelay(now time.Time) time.Duration {
+	untilMidnight := now.UTC().Truncate(24*time.Hour).AddDate(0, 0, 1).Sub(now) + time.Second
+	if untilMidnight < m.config.CheckInterval {
+		return untilMidnight
+	}
+	return m.config.CheckInterval
+}
+
+// check moves the write alias to the index of the current day
+func (m *LifecycleManager) check(ctx context.Context) error {
+	now := time.Now().UTC()
+
+	if err := m.ensureWriteIndex(ctx, now); err != nil {
+		return err
+	}
+	// Create the next index ahead of midnight so that the alias can move without a gap
+	return m.ensureIndex(ctx, TraceIndexName(now.AddDate(0, 0, 1)))
+}
+
+// ensureIndexTemplate installs the trace index template when it does not exist yet
+func (m *LifecycleManager) ensureIndexTemplate(ctx context.Context) error {
+	exists, err := m.client.IndexTemplateExists(ctx, traceIndexTemplateName)
+	if err != nil {
+		return fmt.Errorf("failed to check trace index template: %w", err)
+	}
+	if exists {
+		return nil
+	}
+
+	if err := m.client.PutIndexTemplate(ctx, traceIndexTemplateName, buildTraceIndexTemplate()); err != nil {
+		return fmt.Errorf("failed to install trace index template: %w", err)
+	}
+	slog.InfoContext(ctx, "Installed trace index template", "template", traceIndexTemplateName)
+	return nil
+}
+
+// ensureIndex creates an index when it does not exist yet
+func (m *LifecycleManager) ensureIndex(ctx context.Context, index string) error {
+	exists, err := m.client.IndexExists(ctx, index)
+	if err != nil {
+		return fmt.Errorf("failed to check index %s: %w", index, err)
+	}
+	if exists {
+		return nil
+	}
+
+	if err := m.client.CreateIndex(ctx, index, map[string]interface{}{}); err != nil {
+		// Another replica may have created the index concurrently
+		if exists, existsErr := m.client.IndexExists(ctx, index); existsErr == nil && exists {
+			return nil
+		}
+		return fmt.Errorf("failed to create index %s: %w", index, err)
+	}
+	slog.InfoContext(ctx, "Created trace index", "index", index)
+	return nil
+}
+
+// ensureWriteIndex points the write alias at the index of the given day
+func (m *LifecycleManager) ensureWriteIndex(ctx context.Context, day time.Time) error {
+	index := TraceIndexName(day)
+	if err := m.ensureIndex(ctx, index); err != nil {
+		return err
+	}
+
+	current, err := m.client.GetAliasIndices(ctx, TraceWriteAlias)
+	if err != nil {
+		return err
+	}
+	if len(current) == 1 && current[0] == index {
+		return nil
+	}
+
+	// Moving the alias is a single atomic update so writes never lack a target
+	actions := []map[string]interface{}{}
+	for _, previous := range current {
+		if previous != index {
+			actions = append(actions, map[string]interface{}{
+				"remove": map[string]interface{}{"index": previous, "alias": TraceWriteAlias},
+			})
+		}
+	}
+	actions = append(actions, map[string]interface{}{
+		"add": map[string]interface{}{"index": index, "alias": TraceWriteAlias, "is_write_index": true},
+	})
+	if err := m.client.UpdateAliases(ctx, actions); err != nil {
+		return fmt.Errorf("failed to move write alias to %s: %w", index, err)
+	}
+	slog.InfoContext(ctx, "Moved write alias", "alias", TraceWriteAlias, "index", index)
+	return nil
+}
+
+// enforceRetention deletes the indices whose spans all expired and the expired spans of the other indices
+// Spans of agents with a retention of their own expire after it, the other spans and rollups after the default retention
+func (m *LifecycleManager) enforceRetention(ctx context.Context, now time.Time) error {
+	m.mu.Lock()
+	m.stats.Running = true
+	m.stats.LastRunAt = &now
+	m.mu.Unlock()
+
+	err := m.deleteExpired(ctx, now)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.stats.Running = false
+	if err != nil {
+		m.stats.Failures++
+		m.stats.LastError = err.Error()
+	}
+	return err
+}
+
+// deleteExpired carries out the retention plan at now
+func (m *LifecycleManager) deleteExpired(ctx context.Context, now time.Time) error {
+	plan, err := m.client.PlanRetention(ctx, now, m.config.RetentionDays)
+	if err != nil {
+		return err
+	}
+
+	if len(plan.DeleteIndices) > 0 {
+		if err := m.client.DeleteIndices(ctx, plan.DeleteIndices); err != nil {
+			return fmt.Errorf("failed to delete expired indices: %w", err)
+		}
+		m.mu.Lock()
+		m.stats.IndicesDeleted += uint64(len(plan.DeleteIndices))
+		m.mu.Unlock()
+		slog.InfoContext(ctx, "Deleted expired trace indices", "count", len(plan.DeleteIndices), "indices", plan.DeleteIndices)
+	}
+
+	// Classes are purged one at a time so that only one throttled deletion runs
+	for _, purge := range plan.Purges {
+		deleted, err := m.client.DeleteByQueryThrottled(ctx, purge.Indices, purge.Query(), m.config.Throttle)
+		m.mu.Lock()
+		m.stats.SpansDeleted += uint64(deleted)
+		m.mu.Unlock()
+		if err != nil {
+			return fmt.Errorf("failed to delete expired spans of %d indices: %w", len(purge.Indices), err)
+		}
+		slog.InfoContext(ctx, "Deleted expired spans", "retentionDays", purge.RetentionDays, "cutoff", purge.Cutoff,
+			"spans", deleted, "indices", len(purge.Indices))
+	}
+	return nil
+}
+
+// RetentionReport reports what the next retention run would delete, without deleting anything
+func (m *LifecycleManager) RetentionReport(ctx context.Context) (*RetentionReport, error) {
+	return m.client.RetentionReport(ctx, time.Now().UTC(), m.config.RetentionDays)
+}
+
+// Stats returns the retention runs so far
+func (m *LifecycleManager) Stats() RetentionStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.stats
+}
+
+// DailyIndicesBefore lists the daily trace and rollup indices of days before the cutoff
+func (c *Client) DailyIndicesBefore(ctx context.Context, cutoff time.Time) ([]string, error) {
+	var indices []string
+	for _, prefix := range []string{traceIndexPrefix, traceRollupIndexPrefix} {
+		existing, err := c.GetIndices(ctx, prefix+"*")
+		if err != nil {
+			return nil, fmt.Errorf("failed to list indices: %w", err)
+		}
+		for _, index := range existing {
+			day, ok := DailyIndexDay(index)
+			if ok && day.Before(cutoff) {
+				indices = append(indices, index)
+			}
+		}
+	}
+	return indices, nil
+}
+
+// DailyIndexDay returns the day of a daily trace or rollup index
+func DailyIndexDay(index string) (time.Time, bool) {
+	for _, prefix := range []string{traceIndexPrefix, traceRollupIndexPrefix} {
+		if !strings.HasPrefix(index, prefix) {
+			continue
+		}
+		day, err := time.Parse(traceIndexDateLayout, strings.TrimPrefix(index, prefix))
+		if err != nil {
+			// Not a daily index
+			return time.Time{}, false
+		}
+		return day, true
+	}
+	return time.Time{}, false
+}
+
+// IsTraceIndex reports whether an index is a daily trace index rather than a rollup index
+func IsTraceIndex(index string) bool {
+	return strings.HasPrefix(index, traceIndexPrefix)
+}
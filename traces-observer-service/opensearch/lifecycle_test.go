@@ -0,0 +1,231 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package opensearch
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/config"
+)
+
+// fakeLifecycleOpenSearch keeps the index templates, indices and write alias of a cluster
+// Indices listed in racing are created by another replica while the service creates them
+type fakeLifecycleOpenSearch struct {
+	mu             sync.Mutex
+	templates      map[string]bool
+	indices        map[string]bool
+	alias          []string
+	racing         map[string]bool
+	templatePuts   int
+	aliasUpdates   int
+	lastAliasAdded map[string]interface{}
+}
+
+func (f *fakeLifecycleOpenSearch) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	name := strings.TrimPrefix(r.URL.Path, "/")
+	switch {
+	case strings.HasPrefix(name, "_index_template/"):
+		template := strings.TrimPrefix(name, "_index_template/")
+		if r.Method == http.MethodPut {
+			f.templatePuts++
+			f.templates[template] = true
+			_, _ = w.Write([]byte(`{"acknowledged":true}`))
+			return
+		}
+		if !f.templates[template] {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	case name == "_alias/"+TraceWriteAlias:
+		if len(f.alias) == 0 {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{}`))
+			return
+		}
+		aliases := map[string]interface{}{}
+		for _, index := range f.alias {
+			aliases[index] = map[string]interface{}{"aliases": map[string]interface{}{TraceWriteAlias: map[string]interface{}{}}}
+		}
+		_ = json.NewEncoder(w).Encode(aliases)
+	case name == "_aliases":
+		var body struct {
+			Actions []map[string]map[string]interface{} `json:"actions"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		f.aliasUpdates++
+		for _, action := range body.Actions {
+			if remove, ok := action["remove"]; ok {
+				kept := f.alias[:0]
+				for _, index := range f.alias {
+					if index != remove["index"] {
+						kept = append(kept, index)
+					}
+				}
+				f.alias = kept
+			}
+			if add, ok := action["add"]; ok {
+				f.alias = append(f.alias, add["index"].(string))
+				f.lastAliasAdded = add
+			}
+		}
+		_, _ = w.Write([]byte(`{"acknowledged":true}`))
+	case name == "":
+		_, _ = w.Write([]byte(`{"cluster_name":"test","version":{"distribution":"opensearch","number":"2.11.0"}}`))
+	case r.Method == http.MethodPut:
+		if f.racing[name] {
+			f.indices[name] = true
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":{"type":"resource_already_exists_exception"}}`))
+			return
+		}
+		f.indices[name] = true
+		_, _ = w.Write([]byte(`{"acknowledged":true}`))
+	case r.Method == http.MethodHead:
+		if !f.indices[name] {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+// newLifecycleTestManager returns a lifecycle manager of a fake cluster
+func newLifecycleTestManager(t *testing.T, fake *fakeLifecycleOpenSearch) *LifecycleManager {
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+	client, err := NewClient(&config.OpenSearchConfig{Address: server.URL, RequestTimeout: 5 * time.Second}, nil)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	return client.NewLifecycleManager(LifecycleConfig{})
+}
+
+func newFakeLifecycleOpenSearch() *fakeLifecycleOpenSearch {
+	return &fakeLifecycleOpenSearch{templates: map[string]bool{}, indices: map[string]bool{}, racing: map[string]bool{}}
+}
+
+func TestLifecycleInstallsIndexTemplateOnce(t *testing.T) {
+	fake := newFakeLifecycleOpenSearch()
+	manager := newLifecycleTestManager(t, fake)
+
+	for i := 0; i < 2; i++ {
+		if err := manager.ensureIndexTemplate(context.Background()); err != nil {
+			t.Fatalf("ensureIndexTemplate() error = %v", err)
+		}
+	}
+	if fake.templatePuts != 1 || !fake.templates[traceIndexTemplateName] {
+		t.Errorf("template installed %d times, want once", fake.templatePuts)
+	}
+}
+
+func TestLifecycleMovesWriteAlias(t *testing.T) {
+	now := time.Now().UTC()
+	today, tomorrow := TraceIndexName(now), TraceIndexName(now.AddDate(0, 0, 1))
+
+	fake := newFakeLifecycleOpenSearch()
+	fake.indices["otel-traces-2025-01-01"] = true
+	fake.alias = []string{"otel-traces-2025-01-01"}
+	manager := newLifecycleTestManager(t, fake)
+
+	if err := manager.check(context.Background()); err != nil {
+		t.Fatalf("check() error = %v", err)
+	}
+	if !reflect.DeepEqual(fake.alias, []string{today}) {
+		t.Errorf("write alias = %v, want only %s", fake.alias, today)
+	}
+	if fake.lastAliasAdded["is_write_index"] != true {
+		t.Errorf("alias added as %v, want the write index", fake.lastAliasAdded)
+	}
+	if !fake.indices[today] || !fake.indices[tomorrow] {
+		t.Errorf("indices = %v, want %s and %s created", fake.indices, today, tomorrow)
+	}
+
+	// An alias already on the index of the day is left alone
+	if err := manager.check(context.Background()); err != nil {
+		t.Fatalf("check() error = %v", err)
+	}
+	if fake.aliasUpdates != 1 {
+		t.Errorf("alias updated %d times, want once", fake.aliasUpdates)
+	}
+}
+
+func TestLifecycleToleratesConcurrentIndexCreation(t *testing.T) {
+	now := time.Now().UTC()
+	fake := newFakeLifecycleOpenSearch()
+	fake.racing[TraceIndexName(now)] = true
+	fake.racing[TraceIndexName(now.AddDate(0, 0, 1))] = true
+	manager := newLifecycleTestManager(t, fake)
+
+	if err := manager.check(context.Background()); err != nil {
+		t.Fatalf("check() error = %v, want indices created by another replica to be used", err)
+	}
+	if !reflect.DeepEqual(fake.alias, []string{TraceIndexName(now)}) {
+		t.Errorf("write alias = %v, want %s", fake.alias, TraceIndexName(now))
+	}
+}
+
+func TestLifecycleNextCheckDelay(t *testing.T) {
+	manager := &LifecycleManager{config: LifecycleConfig{CheckInterval: time.Hour}}
+	tests := []struct {
+		now  time.Time
+		want time.Duration
+	}{
+		{now: time.Date(2025, 11, 3, 12, 0, 0, 0, time.UTC), want: time.Hour},
+		// The check runs right after midnight rather than up to an interval later
+		{now: time.Date(2025, 11, 3, 23, 30, 0, 0, time.UTC), want: 30*time.Minute + time.Second},
+		{now: time.Date(2025, 11, 3, 23, 0, 0, 0, time.UTC), want: time.Hour},
+		{now: time.Date(2025, 11, 3, 23, 59, 59, 0, time.FixedZone("CET", 3600)), want: time.Hour},
+	}
+	for _, tt := range tests {
+		if got := manager.nextCheckDelay(tt.now); got != tt.want {
+			t.Errorf("nextCheckDelay(%v) = %v, want %v", tt.now, got, tt.want)
+		}
+	}
+}
+
+func TestDailyIndexDay(t *testing.T) {
+	tests := []struct {
+		index     string
+		wantDay   string
+		wantTrace bool
+	}{
+		{index: "otel-traces-2025-11-03", wantDay: "2025-11-03", wantTrace: true},
+		{index: traceRollupIndexPrefix + "2025-11-03", wantDay: "2025-11-03"},
+		{index: "otel-traces-write", wantTrace: true},
+		{index: "amp-trace-annotations"},
+	}
+	for _, tt := range tests {
+		day, ok := DailyIndexDay(tt.index)
+		if ok != (tt.wantDay != "") || (ok && day.Format(traceIndexDateLayout) != tt.wantDay) {
+			t.Errorf("DailyIndexDay(%q) = %v, %v, want %q", tt.index, day, ok, tt.wantDay)
+		}
+		if got := IsTraceIndex(tt.index); got != tt.wantTrace {
+			t.Errorf("IsTraceIndex(%q) = %v, want %v", tt.index, got, tt.wantTrace)
+		}
+	}
+}
@@ -0,0 +1,198 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package opensearch
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+// DefaultMaxFieldBytes is the default size limit of span inputs, outputs, system prompts and raw attributes
+const DefaultMaxFieldBytes = 64 * 1024
+
+// DefaultMaxParseBytes is the default size limit of JSON attribute strings decoded during extraction
+const DefaultMaxParseBytes = 1024 * 1024
+
+// Limits holds the size limits applied to processed spans
+// A limit of zero or less disables truncation of the corresponding values
+type Limits struct {
+	MaxInputBytes        int
+	MaxOutputBytes       int
+	MaxSystemPromptBytes int
+	MaxAttributeBytes    int
+	MaxParseBytes        int // JSON attribute strings above this size are kept as strings rather than decoded
+}
+
+// DefaultLimits returns the default size limits
+func DefaultLimits() Limits {
+	return Limits{
+		MaxInputBytes:        DefaultMaxFieldBytes,
+		MaxOutputBytes:       DefaultMaxFieldBytes,
+		MaxSystemPromptBytes: DefaultMaxFieldBytes,
+		MaxAttributeBytes:    DefaultMaxFieldBytes,
+		MaxParseBytes:        DefaultMaxParseBytes,
+	}
+}
+
+var (
+	limitsMu      sync.RWMutex
+	currentLimits = DefaultLimits()
+)
+
+// SetLimits configures the size limits applied by ParseSpans
+func SetLimits(limits Limits) {
+	limitsMu.Lock()
+	defer limitsMu.Unlock()
+	currentLimits = limits
+}
+
+// getLimits returns the configured size limits
+func getLimits() Limits {
+	limitsMu.RLock()
+	defer limitsMu.RUnlock()
+	return currentLimits
+}
+
+// truncationMarker starts the suffix appended to truncated values
+const truncationMarker = "...[truncated, original "
+
+// truncationSuffix builds the marker appended to truncated values
+func truncationSuffix(originalBytes int) string {
+	return fmt.Sprintf("%s%d bytes]", truncationMarker, originalBytes)
+}
+
+// truncateValue truncates a string over maxBytes, returning the value and whether it was truncated
+func truncateValue(value string, maxBytes int) (string, bool) {
+	if maxBytes <= 0 || len(value) <= maxBytes {
+		return value, false
+	}
+	return truncateUTF8(value, maxBytes) + truncationSuffix(len(value)), true
+}
+
+// applyLimits truncates oversized values of a span and records their original sizes
+func applyLimits(span *Span, limits Limits) {
+	record := func(field string, originalBytes int) {
+		span.Truncated = true
+		if span.OriginalSizes == nil {
+			span.OriginalSizes = map[string]int{}
+		}
+		span.OriginalSizes[field] = originalBytes
+	}
+
+	// Truncate raw attribute values
+	for key, value := range span.Attributes {
+		strValue, ok := value.(string)
+		if !ok {
+			continue
+		}
+		if truncated, ok := truncateValue(strValue, limits.MaxAttributeBytes); ok {
+			span.Attributes[key] = truncated
+			record("attributes."+key, len(strValue))
+		}
+	}
+
+	// Truncate event attribute values
+	for _, event := range span.Events {
+		for key, value := range event.Attributes {
+			strValue, ok := value.(string)
+			if !ok {
+				continue
+			}
+			if truncated, ok := truncateValue(strValue, limits.MaxAttributeBytes); ok {
+				event.Attributes[key] = truncated
+				record("events."+event.Name+"."+key, len(strValue))
+			}
+		}
+	}
+
+	ampAttrs := span.AmpAttributes
+	if ampAttrs == nil {
+		return
+	}
+
+	var originalBytes int
+	var truncated bool
+	if ampAttrs.Input, originalBytes, truncated = limitContent(ampAttrs.Input, limits.MaxInputBytes); truncated {
+		record("input", originalBytes)
+	}
+	if ampAttrs.Output, originalBytes, truncated = limitContent(ampAttrs.Output, limits.MaxOutputBytes); truncated {
+		record("output", originalBytes)
+	}
+
+	// Truncate system prompts of agent spans
+	if agentData, ok := ampAttrs.Data.(AgentData); ok {
+		if systemPrompt, ok := truncateValue(agentData.SystemPrompt, limits.MaxSystemPromptBytes); ok {
+			record("systemPrompt", len(agentData.SystemPrompt))
+			agentData.SystemPrompt = systemPrompt
+			ampAttrs.Data = agentData
+		}
+	}
+
+	// Messages hold both the prompt and the completion, so they share the input and output budgets
+	if llmData, ok := ampAttrs.Data.(LLMData); ok && len(llmData.Messages) > 0 && limits.MaxInputBytes > 0 && limits.MaxOutputBytes > 0 {
+		var messages interface{}
+		if messages, originalBytes, truncated = limitContent(llmData.Messages, limits.MaxInputBytes+limits.MaxOutputBytes); truncated {
+			record("messages", originalBytes)
+			llmData.Messages = messages.([]PromptMessage)
+			ampAttrs.Data = llmData
+		}
+	}
+}
+
+// limitContent truncates an input or output value over maxBytes
+// Strings and message contents are truncated in place, other values are replaced by their truncated JSON
+func limitContent(content interface{}, maxBytes int) (interface{}, int, bool) {
+	if content == nil || maxBytes <= 0 {
+		return content, 0, false
+	}
+
+	switch value := content.(type) {
+	case string:
+		truncated, ok := truncateValue(value, maxBytes)
+		return truncated, len(value), ok
+	case []PromptMessage:
+		// Keep the message structure and share the budget between the messages in order
+		total := 0
+		for _, message := range value {
+			total += len(message.Content)
+		}
+		if total <= maxBytes {
+			return value, total, false
+		}
+		limited := make([]PromptMessage, len(value))
+		remaining := maxBytes
+		for i, message := range value {
+			limited[i] = message
+			if len(message.Content) > remaining {
+				limited[i].Content = truncateUTF8(message.Content, remaining) + truncationSuffix(len(message.Content))
+			}
+			remaining -= len(limited[i].Content)
+			if remaining < 0 {
+				remaining = 0
+			}
+		}
+		return limited, total, true
+	default:
+		encoded, err := json.Marshal(value)
+		if err != nil || len(encoded) <= maxBytes {
+			return content, len(encoded), false
+		}
+		truncated, _ := truncateValue(string(encoded), maxBytes)
+		return truncated, len(encoded), true
+	}
+}
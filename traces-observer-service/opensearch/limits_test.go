@@ -0,0 +1,170 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package opensearch
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestTruncateValue(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		maxBytes int
+		want     string
+		wantCut  bool
+	}{
+		{name: "within the limit", value: "hello", maxBytes: 5, want: "hello"},
+		{name: "over the limit", value: "hello world", maxBytes: 5, want: "hello...[truncated, original 11 bytes]", wantCut: true},
+		{name: "multibyte rune at the limit", value: "abcé", maxBytes: 4, want: "abc...[truncated, original 5 bytes]", wantCut: true},
+		{name: "disabled", value: "hello world", maxBytes: 0, want: "hello world"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, cut := truncateValue(tt.value, tt.maxBytes)
+			if got != tt.want || cut != tt.wantCut {
+				t.Errorf("truncateValue() = %q, %v, want %q, %v", got, cut, tt.want, tt.wantCut)
+			}
+		})
+	}
+}
+
+func TestApplyLimits(t *testing.T) {
+	limits := Limits{MaxInputBytes: 10, MaxOutputBytes: 10, MaxSystemPromptBytes: 10, MaxAttributeBytes: 10}
+	long := strings.Repeat("x", 25)
+
+	tests := []struct {
+		name          string
+		span          Span
+		wantOriginals map[string]int
+		check         func(t *testing.T, span Span)
+	}{
+		{
+			name: "attributes and event attributes",
+			span: Span{
+				Attributes: map[string]interface{}{"gen_ai.prompt": long, "short": "ok", "count": 12345678901},
+				Events:     []SpanEvent{{Name: "exception", Attributes: map[string]interface{}{"exception.stacktrace": long}}},
+			},
+			wantOriginals: map[string]int{"attributes.gen_ai.prompt": 25, "events.exception.exception.stacktrace": 25},
+			check: func(t *testing.T, span Span) {
+				if span.Attributes["short"] != "ok" || span.Attributes["count"] != 12345678901 {
+					t.Errorf("attributes within the limit changed: %v", span.Attributes)
+				}
+			},
+		},
+		{
+			name: "input and output",
+			span: Span{AmpAttributes: &AmpAttributes{Input: long, Output: map[string]interface{}{"answer": long}}},
+			// Structured values are measured and truncated as JSON
+			wantOriginals: map[string]int{"input": 25, "output": 38},
+			check: func(t *testing.T, span Span) {
+				if output, ok := span.AmpAttributes.Output.(string); !ok || !strings.HasPrefix(output, `{"answer":`) {
+					t.Errorf("output = %#v, want its truncated JSON", span.AmpAttributes.Output)
+				}
+			},
+		},
+		{
+			name: "prompt messages share the budget in order",
+			span: Span{AmpAttributes: &AmpAttributes{Input: []PromptMessage{
+				{Role: "system", Content: "1234567"},
+				{Role: "user", Content: "abcdefgh"},
+			}}},
+			wantOriginals: map[string]int{"input": 15},
+			check: func(t *testing.T, span Span) {
+				messages := span.AmpAttributes.Input.([]PromptMessage)
+				if messages[0].Content != "1234567" || !strings.HasPrefix(messages[1].Content, "abc...[truncated") {
+					t.Errorf("messages = %+v, want the second one cut after 3 bytes", messages)
+				}
+				if messages[1].Role != "user" {
+					t.Errorf("message role = %q, want user", messages[1].Role)
+				}
+			},
+		},
+		{
+			name:          "agent system prompt",
+			span:          Span{AmpAttributes: &AmpAttributes{Data: AgentData{Name: "planner", SystemPrompt: long}}},
+			wantOriginals: map[string]int{"systemPrompt": 25},
+			check: func(t *testing.T, span Span) {
+				data := span.AmpAttributes.Data.(AgentData)
+				if data.Name != "planner" || !strings.HasPrefix(data.SystemPrompt, "xxxxxxxxxx...[truncated") {
+					t.Errorf("agent data = %+v", data)
+				}
+			},
+		},
+		{
+			name:          "LLM messages use the input and output budgets",
+			span:          Span{AmpAttributes: &AmpAttributes{Data: LLMData{Messages: []PromptMessage{{Role: "user", Content: long}}}}},
+			wantOriginals: map[string]int{"messages": 25},
+		},
+		{
+			name: "values within the limits",
+			span: Span{
+				Attributes:    map[string]interface{}{"key": "value"},
+				AmpAttributes: &AmpAttributes{Input: "short", Output: []PromptMessage{{Content: "short"}}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			span := tt.span
+			applyLimits(&span, limits)
+			if span.Truncated != (tt.wantOriginals != nil) || !reflect.DeepEqual(span.OriginalSizes, tt.wantOriginals) {
+				t.Errorf("truncated %v with original sizes %v, want %v", span.Truncated, span.OriginalSizes, tt.wantOriginals)
+			}
+			if tt.check != nil {
+				tt.check(t, span)
+			}
+		})
+	}
+}
+
+func TestApplyLimitsDisabled(t *testing.T) {
+	long := strings.Repeat("é", 100)
+	span := Span{
+		Attributes:    map[string]interface{}{"gen_ai.prompt": long},
+		AmpAttributes: &AmpAttributes{Input: long, Data: AgentData{SystemPrompt: long}},
+	}
+	applyLimits(&span, Limits{})
+	if span.Truncated || span.Attributes["gen_ai.prompt"] != long || span.AmpAttributes.Input != long {
+		t.Errorf("span truncated with limits disabled: %+v", span)
+	}
+}
+
+func TestProcessDocumentAppliesConfiguredLimits(t *testing.T) {
+	withLimits(t, Limits{MaxInputBytes: 16, MaxOutputBytes: 16, MaxSystemPromptBytes: 16, MaxAttributeBytes: 16, MaxParseBytes: DefaultMaxParseBytes})
+	prompt := strings.Repeat("ü", 40)
+	span := ProcessDocument(map[string]interface{}{
+		"traceId": "trace",
+		"spanId":  "span",
+		"name":    "task",
+		"attributes": map[string]interface{}{
+			"traceloop.span.kind":     "task",
+			"traceloop.entity.input":  prompt,
+			"traceloop.entity.output": "done",
+		},
+	})
+	if !span.Truncated || span.OriginalSizes["attributes.traceloop.entity.input"] != len(prompt) {
+		t.Errorf("original sizes = %v, want the input attribute of %d bytes", span.OriginalSizes, len(prompt))
+	}
+	input, _ := span.Attributes["traceloop.entity.input"].(string)
+	if !utf8.ValidString(input) || !strings.Contains(input, "...[truncated, original 80 bytes]") {
+		t.Errorf("truncated input attribute = %q", input)
+	}
+}
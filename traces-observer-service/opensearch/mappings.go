@@ -0,0 +1,484 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package opensearch
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TraceIndexPattern matches the daily trace indices
+const TraceIndexPattern = "otel-traces-*"
+
+// traceIndexPrefix is the prefix of the daily trace indices
+const traceIndexPrefix = "otel-traces-"
+
+// traceIndexDateLayout is the date suffix of the daily trace indices
+const traceIndexDateLayout = "2006-01-02"
+
+// TraceIndexName returns the daily trace index of the given day
+func TraceIndexName(day time.Time) string {
+	return traceIndexPrefix + day.UTC().Format(traceIndexDateLayout)
+}
+
+// traceRollupIndexPrefix is the prefix of the daily indices holding rollups of traces dropped by sampling
+const traceRollupIndexPrefix = "otel-trace-rollups-"
+
+// TraceRollupIndexPattern matches the daily trace rollup indices
+const TraceRollupIndexPattern = traceRollupIndexPrefix + "*"
+
+// TraceRollupIndexName returns the daily rollup index of a trace started at the given time
+func TraceRollupIndexName(startTime time.Time) string {
+	return traceRollupIndexPrefix + startTime.UTC().Format(traceIndexDateLayout)
+}
+
+// traceIndexTemplateName is the name of the index template managed by this service
+const traceIndexTemplateName = "amp-otel-traces"
+
+// SearchSubfield is the text subfield added to keyword attributes of existing indices
+const SearchSubfield = "search"
+
+// SearchableFields lists the span attributes that hold inputs, outputs and system prompts
+var SearchableFields = map[string][]string{
+	"input": {
+		"traceloop.entity.input",
+		"gen_ai.prompt",
+		"gen_ai.input.messages",
+		"crewai.crew.tasks_output",
+		"crewai.flow.inputs",
+		"crewai.tool.args",
+		"tool.input",
+	},
+	"output": {
+		"traceloop.entity.output",
+		"gen_ai.completion",
+		"gen_ai.output.messages",
+		"crewai.crew.result",
+		"crewai.flow.state",
+		"crewai.tool.result",
+		"tool.output",
+	},
+	"systemPrompt": {
+		"gen_ai.system_instructions",
+		"system_prompt",
+		"crewai.agent.goal",
+		"crewai.agent.backstory",
+	},
+}
+
+// searchableAttributeFields returns the document fields of all searchable attributes
+func searchableAttributeFields() []string {
+	fields := []string{}
+	for _, group := range []string{"input", "output", "systemPrompt"} {
+		for _, attr := range SearchableFields[group] {
+			fields = append(fields, "attributes."+attr)
+		}
+	}
+	return fields
+}
+
+// Dynamic mapping of unknown top-level fields of trace documents
+const (
+	MappingDynamicStrict = "strict" // Documents with an unknown top-level field are rejected
+	MappingDynamicFalse  = "false"  // Unknown top-level fields are kept in _source but not indexed
+	MappingDynamicTrue   = "true"   // Unknown top-level fields are mapped from their first value
+)
+
+// CustomFieldTypes lists the types custom fields can be mapped as
+var CustomFieldTypes = []string{"keyword", "text", "long", "integer", "double", "float", "boolean", "date", "ip"}
+
+// keywordIgnoreAbove is the length above which keyword values are kept in _source but not indexed
+const keywordIgnoreAbove = 8191
+
+// TraceMapping configures the mapping of the trace indices
+// Span attributes, resource attributes and event and link attributes are always mapped dynamically,
+// strings as keywords and strings under gen_ai.usage and of duration attributes as numbers
+type TraceMapping struct {
+	Dynamic          string            // Mapping of unknown top-level fields: strict, false or true
+	TotalFieldsLimit int               // Maximum number of fields per index, the OpenSearch default when zero
+	CustomFields     map[string]string // Types of additional attributes.* and resource.* fields by document field
+}
+
+// DefaultTraceMapping returns the trace mapping used until SetTraceMapping is called
+func DefaultTraceMapping() TraceMapping {
+	return TraceMapping{Dynamic: MappingDynamicFalse}
+}
+
+var (
+	traceMappingMu      sync.RWMutex
+	currentTraceMapping = DefaultTraceMapping()
+)
+
+// SetTraceMapping configures the mapping installed by EnsureTraceMappings and used by ReindexTraceIndex
+func SetTraceMapping(mapping TraceMapping) error {
+	switch mapping.Dynamic {
+	case MappingDynamicStrict, MappingDynamicFalse, MappingDynamicTrue:
+	default:
+		return fmt.Errorf("unsupported dynamic mapping %q, expected strict, false or true", mapping.Dynamic)
+	}
+	if mapping.TotalFieldsLimit < 0 {
+		return fmt.Errorf("total fields limit must not be negative")
+	}
+	for field, fieldType := range mapping.CustomFields {
+		if !strings.HasPrefix(field, "attributes.") && !strings.HasPrefix(field, "resource.") {
+			return fmt.Errorf("custom field %s must start with attributes. or resource.", field)
+		}
+		if !slices.Contains(CustomFieldTypes, fieldType) {
+			return fmt.Errorf("custom field %s has unsupported type %s, expected one of %s", field, fieldType, strings.Join(CustomFieldTypes, ", "))
+		}
+	}
+
+	traceMappingMu.Lock()
+	defer traceMappingMu.Unlock()
+	currentTraceMapping = mapping
+	return nil
+}
+
+// getTraceMapping returns the configured trace mapping
+func getTraceMapping() TraceMapping {
+	traceMappingMu.RLock()
+	defer traceMappingMu.RUnlock()
+	return currentTraceMapping
+}
+
+// buildTraceIndexTemplate builds the index template for new trace indices
+func buildTraceIndexTemplate() map[string]interface{} {
+	return map[string]interface{}{
+		"index_patterns": []string{TraceIndexPattern},
+		"priority":       100,
+		"template":       buildTraceIndexBody(getTraceMapping()),
+	}
+}
+
+// buildTraceIndexBody builds the settings and mappings of a new trace index
+func buildTraceIndexBody(mapping TraceMapping) map[string]interface{} {
+	body := map[string]interface{}{
+		"mappings": buildTraceMappings(mapping, nil),
+	}
+	if mapping.TotalFieldsLimit > 0 {
+		body["settings"] = map[string]interface{}{
+			"index.mapping.total_fields.limit": mapping.TotalFieldsLimit,
+		}
+	}
+	return body
+}
+
+// buildTraceMappings builds the mappings of a trace index
+// live holds the field types of an existing index, whose searchable attributes keep the type they were created with
+func buildTraceMappings(mapping TraceMapping, live map[string]string) map[string]interface{} {
+	return map[string]interface{}{
+		"dynamic":           mapping.Dynamic,
+		"dynamic_templates": buildDynamicTemplates(),
+		"properties":        buildTraceProperties(mapping, live),
+	}
+}
+
+// buildDynamicTemplates builds the rules mapping the fields of attribute objects that are not mapped explicitly
+// Dynamic string mapping would otherwise create a text field and a keyword subfield for every attribute,
+// and map token counts and durations sent as strings as text
+func buildDynamicTemplates() []map[string]interface{} {
+	numeric := map[string]interface{}{"type": "double", "ignore_malformed": true}
+	return []map[string]interface{}{
+		{"usage_strings": map[string]interface{}{
+			"path_match":         "attributes.gen_ai.usage.*",
+			"match_mapping_type": "string",
+			"mapping":            numeric,
+		}},
+		{"duration_strings": map[string]interface{}{
+			"path_match":         "attributes.*",
+			"match":              "*duration*",
+			"match_mapping_type": "string",
+			"mapping":            numeric,
+		}},
+		{"doubles": map[string]interface{}{
+			"match_mapping_type": "double",
+			"mapping":            map[string]interface{}{"type": "double"},
+		}},
+		{"strings": map[string]interface{}{
+			"match_mapping_type": "string",
+			"mapping":            map[string]interface{}{"type": "keyword", "ignore_above": keywordIgnoreAbove},
+		}},
+	}
+}
+
+// buildTraceProperties builds the explicit mapping of the known fields of trace documents
+// Dotted field names are expanded into object mappings the way OpenSearch expands them
+func buildTraceProperties(mapping TraceMapping, live map[string]string) map[string]interface{} {
+	typed := func(fieldType string) map[string]interface{} {
+		return map[string]interface{}{"type": fieldType}
+	}
+
+	properties := map[string]interface{}{}
+	// Attribute objects are created first so that the known attributes are added to them
+	for _, field := range []string{"attributes", "resource", "events.attributes", "links.attributes", "originalSizes"} {
+		putField(properties, field, map[string]interface{}{"type": "object", "dynamic": true})
+	}
+	for _, field := range []string{"traceId", "spanId", "parentSpanId", "name", "kind", "traceState",
+		"instrumentationScope.name", "instrumentationScope.version", "events.name", "links.traceId", "links.spanId"} {
+		putField(properties, field, typed("keyword"))
+	}
+	for _, field := range []string{"startTime", "endTime", "events.time", IngestedAtField} {
+		putField(properties, field, typed("date"))
+	}
+	putField(properties, "durationInNanos", typed("long"))
+	putField(properties, "status.code", typed("integer"))
+	putField(properties, "truncated", typed("boolean"))
+	putField(properties, "redactions", typed("integer"))
+	putField(properties, MultimodalField, typed("boolean"))
+
+	// Attributes the queries filter and aggregate on
+	for _, field := range []string{"input_tokens", "output_tokens", "prompt_tokens", "completion_tokens", "total_tokens"} {
+		putField(properties, "attributes.gen_ai.usage."+field, typed("long"))
+	}
+	for _, field := range []string{"attributes.gen_ai.request.model", "attributes.gen_ai.response.model", "attributes.gen_ai.system",
+		"attributes.gen_ai.agent.name", "attributes.error.type", "resource.service.name",
+		"resource.openchoreo.dev/component-uid", "resource.openchoreo.dev/environment-uid"} {
+		putField(properties, field, typed("keyword"))
+	}
+	for _, field := range searchableAttributeFields() {
+		putField(properties, field, buildSearchableFieldMapping(live[field]))
+	}
+
+	putField(properties, ToolInvocationField, buildToolInvocationMapping())
+	putField(properties, StreamingField, buildStreamingMapping())
+	putField(properties, GuardrailField, buildGuardrailMapping())
+	putField(properties, CustomAttributesField, buildCustomAttributesMapping())
+	putField(properties, TraceRollupField, buildTraceRollupMapping())
+	for _, field := range []string{OrgIDField, AgentLabelsField, AgentIDField, AgentResolutionField} {
+		putField(properties, field, typed("keyword"))
+	}
+	putField(properties, RetentionDaysField, typed("integer"))
+
+	for field, fieldType := range mapping.CustomFields {
+		putField(properties, field, typed(fieldType))
+	}
+	return properties
+}
+
+// buildSearchableFieldMapping maps a searchable attribute as text with a keyword subfield so exact-match queries keep working
+// Indices created before the attribute was searchable keep it as a keyword and get a text subfield instead, since a field type cannot be changed in place
+func buildSearchableFieldMapping(liveType string) map[string]interface{} {
+	if liveType == "keyword" {
+		return map[string]interface{}{
+			"type": "keyword",
+			"fields": map[string]interface{}{
+				SearchSubfield: map[string]interface{}{"type": "text"},
+			},
+		}
+	}
+	return map[string]interface{}{
+		"type": "text",
+		"fields": map[string]interface{}{
+			"keyword": map[string]interface{}{
+				"type":         "keyword",
+				"ignore_above": 32766,
+			},
+		},
+	}
+}
+
+// putField adds the mapping of a dotted document field to properties, creating the objects enclosing it
+func putField(properties map[string]interface{}, field string, mapping map[string]interface{}) {
+	names := strings.Split(field, ".")
+	for _, name := range names[:len(names)-1] {
+		object, ok := properties[name].(map[string]interface{})
+		if !ok {
+			object = map[string]interface{}{}
+			properties[name] = object
+		}
+		children, ok := object["properties"].(map[string]interface{})
+		if !ok {
+			children = map[string]interface{}{}
+			object["properties"] = children
+		}
+		properties = children
+	}
+	properties[names[len(names)-1]] = mapping
+}
+
+// flattenMapping returns the type of every field and subfield of mapping properties by dotted field name
+// Object fields have the type object unless the mapping names another one
+func flattenMapping(properties map[string]interface{}) map[string]string {
+	fields := map[string]string{}
+	var flatten func(properties map[string]interface{}, prefix string)
+	flatten = func(properties map[string]interface{}, prefix string) {
+		for name, value := range properties {
+			mapping, ok := value.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			field := prefix + name
+			fieldType, _ := mapping["type"].(string)
+			if fieldType == "" {
+				fieldType = "object"
+			}
+			fields[field] = fieldType
+			if children, ok := mapping["properties"].(map[string]interface{}); ok {
+				flatten(children, field+".")
+			}
+			if subfields, ok := mapping["fields"].(map[string]interface{}); ok {
+				flatten(subfields, field+".")
+			}
+		}
+	}
+	flatten(properties, "")
+	return fields
+}
+
+// MappingConflict is a field of an existing index whose type differs from the expected one
+type MappingConflict struct {
+	Index    string
+	Field    string
+	Live     string // Type of the field in the index
+	Expected string
+}
+
+// MappingConflictError lists the fields of existing indices that cannot be mapped in place
+type MappingConflictError struct {
+	Conflicts []MappingConflict
+}
+
+func (e *MappingConflictError) Error() string {
+	descriptions := make([]string, 0, len(e.Conflicts))
+	indices := []string{}
+	for _, conflict := range e.Conflicts {
+		descriptions = append(descriptions, fmt.Sprintf("%s: %s is mapped as %s, expected %s",
+			conflict.Index, conflict.Field, conflict.Live, conflict.Expected))
+		if !slices.Contains(indices, conflict.Index) {
+			indices = append(indices, conflict.Index)
+		}
+	}
+	return fmt.Sprintf("incompatible trace index mappings (%s); reindex the indices with the reindex command: %s",
+		strings.Join(descriptions, "; "), strings.Join(indices, " "))
+}
+
+// diffMapping compares the expected fields of an index with its live fields
+// It returns the fields whose type cannot be changed in place and the fields that are missing
+func diffMapping(index string, expected, live map[string]string) ([]MappingConflict, []string) {
+	var conflicts []MappingConflict
+	var missing []string
+	for field, expectedType := range expected {
+		liveType, ok := live[field]
+		if !ok {
+			missing = append(missing, field)
+			continue
+		}
+		if !compatibleFieldTypes(liveType, expectedType) {
+			conflicts = append(conflicts, MappingConflict{Index: index, Field: field, Live: liveType, Expected: expectedType})
+		}
+	}
+	sort.Slice(conflicts, func(i, j int) bool { return conflicts[i].Field < conflicts[j].Field })
+	sort.Strings(missing)
+	return conflicts, missing
+}
+
+// compatibleFieldTypes reports whether a field of the live type serves the queries written for the expected type
+// Integral, floating point and date types are interchangeable within their family
+func compatibleFieldTypes(live, expected string) bool {
+	family := func(fieldType string) string {
+		switch fieldType {
+		case "byte", "short", "integer", "long":
+			return "integral"
+		case "half_float", "float", "double", "scaled_float":
+			return "floating"
+		case "date", "date_nanos":
+			return "date"
+		}
+		return fieldType
+	}
+	return family(live) == family(expected)
+}
+
+// EnsureTraceMappings installs the index template for new trace indices and updates the mappings of existing indices
+// Missing fields are added to existing indices, and the documents of indices that gained the search subfield of
+// searchable attributes are re-indexed in place with update_by_query so the subfield is populated.
+// When a field of an existing index has an incompatible type no index is changed and a *MappingConflictError
+// lists the fields, those indices need ReindexTraceIndex
+func (c *Client) EnsureTraceMappings(ctx context.Context) error {
+	mapping := getTraceMapping()
+	if err := c.PutIndexTemplate(ctx, traceIndexTemplateName, buildTraceIndexTemplate()); err != nil {
+		return fmt.Errorf("failed to install trace index template: %w", err)
+	}
+
+	indices, err := c.GetIndices(ctx, TraceIndexPattern)
+	if err != nil {
+		return fmt.Errorf("failed to list trace indices: %w", err)
+	}
+	sort.Strings(indices)
+
+	type indexUpdate struct {
+		index    string
+		mappings map[string]interface{}
+		missing  []string
+	}
+	var updates []indexUpdate
+	var conflicts []MappingConflict
+	for _, index := range indices {
+		liveMappings, err := c.GetMapping(ctx, index)
+		if err != nil {
+			return fmt.Errorf("failed to get mapping of index %s: %w", index, err)
+		}
+		liveProperties, _ := liveMappings["properties"].(map[string]interface{})
+		live := flattenMapping(liveProperties)
+		mappings := buildTraceMappings(mapping, live)
+		indexConflicts, missing := diffMapping(index, flattenMapping(mappings["properties"].(map[string]interface{})), live)
+		conflicts = append(conflicts, indexConflicts...)
+		updates = append(updates, indexUpdate{index: index, mappings: mappings, missing: missing})
+	}
+	if len(conflicts) > 0 {
+		return &MappingConflictError{Conflicts: conflicts}
+	}
+
+	for _, update := range updates {
+		if err := c.PutMapping(ctx, []string{update.index}, update.mappings); err != nil {
+			return fmt.Errorf("failed to update mapping of index %s: %w", update.index, err)
+		}
+		if len(update.missing) == 0 {
+			continue
+		}
+		populate := slices.ContainsFunc(update.missing, func(field string) bool {
+			return strings.HasSuffix(field, "."+SearchSubfield)
+		})
+		if populate {
+			if err := c.UpdateByQuery(ctx, []string{update.index}); err != nil {
+				return fmt.Errorf("failed to populate search fields of index %s: %w", update.index, err)
+			}
+		}
+		slog.InfoContext(ctx, "Added fields to the index mapping", "fields", len(update.missing), "index", update.index)
+	}
+	return nil
+}
+
+// SearchFieldGroup returns the group (input, output or systemPrompt) of a highlighted document field
+func SearchFieldGroup(field string) string {
+	field = strings.TrimSuffix(field, "."+SearchSubfield)
+	field = strings.TrimPrefix(field, "attributes.")
+	for group, attrs := range SearchableFields {
+		for _, attr := range attrs {
+			if attr == field {
+				return group
+			}
+		}
+	}
+	return ""
+}
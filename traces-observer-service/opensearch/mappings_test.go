@@ -0,0 +1,224 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package opensearch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/config"
+)
+
+func TestTraceIndexTemplateMapsKnownFields(t *testing.T) {
+	if err := SetTraceMapping(TraceMapping{
+		Dynamic:          MappingDynamicStrict,
+		TotalFieldsLimit: 1500,
+		CustomFields:     map[string]string{"attributes.app.tier": "keyword", "resource.deployment.environment": "keyword"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = SetTraceMapping(DefaultTraceMapping()) })
+
+	template := buildTraceIndexTemplate()["template"].(map[string]interface{})
+	mappings := template["mappings"].(map[string]interface{})
+	if mappings["dynamic"] != MappingDynamicStrict {
+		t.Errorf("dynamic = %v, want strict", mappings["dynamic"])
+	}
+	settings := template["settings"].(map[string]interface{})
+	if settings["index.mapping.total_fields.limit"] != 1500 {
+		t.Errorf("settings = %v, want a total fields limit of 1500", settings)
+	}
+
+	fields := flattenMapping(mappings["properties"].(map[string]interface{}))
+	want := map[string]string{
+		"traceId":                               "keyword",
+		"durationInNanos":                       "long",
+		"status.code":                           "integer",
+		"attributes":                            "object",
+		"attributes.gen_ai.usage.input_tokens":  "long",
+		"attributes.gen_ai.request.model":       "keyword",
+		"attributes.gen_ai.prompt":              "text",
+		"attributes.gen_ai.prompt.keyword":      "keyword",
+		"resource.openchoreo.dev/component-uid": "keyword",
+		"events.time":                           "date",
+		"guardrail.verdict":                     "keyword",
+		"guardrail.triggeredRules":              "keyword",
+		CustomAttributesField:                   "flat_object",
+		"attributes.app.tier":                   "keyword",
+		"resource.deployment.environment":       "keyword",
+	}
+	for field, fieldType := range want {
+		if fields[field] != fieldType {
+			t.Errorf("%s is mapped as %q, want %q", field, fields[field], fieldType)
+		}
+	}
+
+	// Attribute objects stay dynamic whatever the mapping of unknown top-level fields
+	properties := mappings["properties"].(map[string]interface{})
+	for _, object := range []string{"attributes", "resource"} {
+		if dynamic := properties[object].(map[string]interface{})["dynamic"]; dynamic != true {
+			t.Errorf("%s dynamic = %v, want true", object, dynamic)
+		}
+	}
+}
+
+func TestSetTraceMappingRejectsInvalidMappings(t *testing.T) {
+	t.Cleanup(func() { _ = SetTraceMapping(DefaultTraceMapping()) })
+	tests := []struct {
+		name    string
+		mapping TraceMapping
+	}{
+		{"unknown dynamic", TraceMapping{Dynamic: "runtime"}},
+		{"negative field limit", TraceMapping{Dynamic: MappingDynamicFalse, TotalFieldsLimit: -1}},
+		{"top-level custom field", TraceMapping{Dynamic: MappingDynamicFalse, CustomFields: map[string]string{"tier": "keyword"}}},
+		{"unknown type", TraceMapping{Dynamic: MappingDynamicFalse, CustomFields: map[string]string{"attributes.tier": "string"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := SetTraceMapping(tt.mapping); err == nil {
+				t.Error("SetTraceMapping() succeeded, want an error")
+			}
+		})
+	}
+}
+
+// fakeMappingOpenSearch serves the mappings of trace indices and records mapping updates
+type fakeMappingOpenSearch struct {
+	mu            sync.Mutex
+	mappings      map[string]string // Mappings response of each index
+	templates     int
+	putMappings   map[string]map[string]interface{}
+	updateByQuery []string
+}
+
+func (f *fakeMappingOpenSearch) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	index := strings.Split(strings.TrimPrefix(r.URL.Path, "/"), "/")[0]
+	switch {
+	case r.Method == http.MethodPut && strings.HasPrefix(r.URL.Path, "/_index_template/"):
+		f.templates++
+		fmt.Fprint(w, `{"acknowledged":true}`)
+	case r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/_mapping"):
+		fmt.Fprintf(w, `{%q:{"mappings":%s}}`, index, f.mappings[index])
+	case r.Method == http.MethodPut && strings.HasSuffix(r.URL.Path, "/_mapping"):
+		body, _ := io.ReadAll(r.Body)
+		var mapping map[string]interface{}
+		_ = json.Unmarshal(body, &mapping)
+		f.putMappings[index] = mapping
+		fmt.Fprint(w, `{"acknowledged":true}`)
+	case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/_update_by_query"):
+		f.updateByQuery = append(f.updateByQuery, index)
+		fmt.Fprint(w, `{"updated":0}`)
+	case r.Method == http.MethodGet && r.URL.Path == "/"+TraceIndexPattern:
+		names := []string{}
+		for name := range f.mappings {
+			names = append(names, fmt.Sprintf("%q:{}", name))
+		}
+		fmt.Fprintf(w, `{%s}`, strings.Join(names, ","))
+	default:
+		fmt.Fprint(w, `{"cluster_name":"test","version":{"distribution":"opensearch","number":"2.11.0"}}`)
+	}
+}
+
+func newMappingTestClient(t *testing.T, mappings map[string]string) (*Client, *fakeMappingOpenSearch) {
+	fake := &fakeMappingOpenSearch{mappings: mappings, putMappings: map[string]map[string]interface{}{}}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	client, err := NewClient(&config.OpenSearchConfig{
+		Address:        server.URL,
+		RequestTimeout: 5 * time.Second,
+	}, nil)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	return client, fake
+}
+
+// keywordPromptMapping is an index created before the prompt attribute was searchable
+const keywordPromptMapping = `{"properties":{
+	"traceId":{"type":"keyword"},
+	"durationInNanos":{"type":"long"},
+	"attributes":{"properties":{"gen_ai":{"properties":{"prompt":{"type":"keyword"}}}}}
+}}`
+
+func TestEnsureTraceMappingsAddsMissingFields(t *testing.T) {
+	client, fake := newMappingTestClient(t, map[string]string{"otel-traces-2025-11-03": keywordPromptMapping})
+
+	if err := client.EnsureTraceMappings(t.Context()); err != nil {
+		t.Fatalf("EnsureTraceMappings() error = %v", err)
+	}
+	if fake.templates != 1 {
+		t.Errorf("index template installed %d times, want 1", fake.templates)
+	}
+	update, ok := fake.putMappings["otel-traces-2025-11-03"]
+	if !ok {
+		t.Fatal("mapping of the existing index was not updated")
+	}
+	fields := flattenMapping(update["properties"].(map[string]interface{}))
+	if fields["attributes.gen_ai.prompt"] != "keyword" || fields["attributes.gen_ai.prompt.search"] != "text" {
+		t.Errorf("prompt is mapped as %q with search subfield %q, want a keyword with a text subfield",
+			fields["attributes.gen_ai.prompt"], fields["attributes.gen_ai.prompt.search"])
+	}
+	if fields["status.code"] != "integer" {
+		t.Errorf("status.code is mapped as %q, want integer", fields["status.code"])
+	}
+	if len(fake.updateByQuery) != 1 {
+		t.Errorf("update by query ran on %v, want the index that gained search subfields", fake.updateByQuery)
+	}
+}
+
+func TestEnsureTraceMappingsFailsOnIncompatibleFields(t *testing.T) {
+	client, fake := newMappingTestClient(t, map[string]string{
+		"otel-traces-2025-11-03": keywordPromptMapping,
+		"otel-traces-2025-11-04": `{"properties":{
+			"durationInNanos":{"type":"text","fields":{"keyword":{"type":"keyword"}}},
+			"status":{"properties":{"code":{"type":"long"}}},
+			"attributes":{"properties":{"gen_ai":{"properties":{"usage":{"properties":{"input_tokens":{"type":"text"}}}}}}}
+		}}`,
+	})
+
+	err := client.EnsureTraceMappings(t.Context())
+	var conflictErr *MappingConflictError
+	if !errors.As(err, &conflictErr) {
+		t.Fatalf("EnsureTraceMappings() error = %v, want a mapping conflict", err)
+	}
+	want := []MappingConflict{
+		{Index: "otel-traces-2025-11-04", Field: "attributes.gen_ai.usage.input_tokens", Live: "text", Expected: "long"},
+		{Index: "otel-traces-2025-11-04", Field: "durationInNanos", Live: "text", Expected: "long"},
+	}
+	if fmt.Sprint(conflictErr.Conflicts) != fmt.Sprint(want) {
+		t.Errorf("conflicts = %v, want %v", conflictErr.Conflicts, want)
+	}
+	if !strings.Contains(err.Error(), "reindex") {
+		t.Errorf("error %q does not point at the reindex command", err)
+	}
+	if len(fake.putMappings) != 0 || len(fake.updateByQuery) != 0 {
+		t.Errorf("indices were changed despite the conflict: mappings %v, update by query %v", fake.putMappings, fake.updateByQuery)
+	}
+}
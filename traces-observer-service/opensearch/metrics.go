@@ -0,0 +1,570 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package opensearch
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/pricing"
+)
+
+// MetricsIntervals maps the supported metrics intervals to their bucket width
+var MetricsIntervals = map[string]time.Duration{
+	"1m": time.Minute,
+	"5m": 5 * time.Minute,
+	"1h": time.Hour,
+	"1d": 24 * time.Hour,
+}
+
+// Metrics group-by dimensions
+const (
+	MetricsGroupByAgent     = "agent"
+	MetricsGroupByFramework = "framework"
+	MetricsGroupByModel     = "model"
+)
+
+// metricsGroupByFields maps the group-by dimensions to the span field holding them
+// The agent is identified by the service name, which is present on every span of the agent
+var metricsGroupByFields = map[string]string{
+	MetricsGroupByAgent:     "resource.service.name",
+	MetricsGroupByFramework: "attributes.gen_ai.system",
+	MetricsGroupByModel:     "attributes.gen_ai.request.model",
+}
+
+// IsValidMetricsGroupBy reports whether a group-by dimension is supported
+func IsValidMetricsGroupBy(groupBy string) bool {
+	if _, ok := metricsGroupByFields[groupBy]; ok {
+		return true
+	}
+	_, ok := metricsGroupByLabel(groupBy)
+	return ok
+}
+
+// MaxMetricsGroups bounds the number of series returned by a grouped metrics query
+const MaxMetricsGroups = 50
+
+// metricsUnknownGroup is the group of spans that do not carry the group-by field
+const metricsUnknownGroup = "unknown"
+
+// TraceMetricsParams holds parameters for trace metrics queries
+type TraceMetricsParams struct {
+	ComponentUid   string
+	EnvironmentUid string
+	StartTime      time.Time
+	EndTime        time.Time
+	Interval       string   // 1m, 5m, 1h or 1d
+	GroupBy        string   // Optional: agent, framework, model or label:<key>
+	AgentID        string   // Optional: only the traces linked to this managed agent, resolved into TraceIDs
+	Agent          string   // Optional: only the spans of the agent with this service name
+	TraceIDs       []string // Restricts the metrics to these traces when not nil
+	Summary        bool     // Also aggregate the whole time range, only when not grouped
+}
+
+// MetricsBucketCount returns the number of time buckets a metrics query produces
+// Buckets are aligned to the epoch like the OpenSearch fixed interval histogram
+func MetricsBucketCount(start, end time.Time, interval time.Duration) int {
+	first := start.UTC().Truncate(interval)
+	return int(end.UTC().Sub(first)/interval) + 1
+}
+
+// TraceMetricsResponse represents zero-filled trace metric time series
+type TraceMetricsResponse struct {
+	Interval  string               `json:"interval"`
+	StartTime time.Time            `json:"startTime"`
+	EndTime   time.Time            `json:"endTime"`
+	GroupBy   string               `json:"groupBy,omitempty"`
+	Series    []TraceMetricsSeries `json:"series"`
+	Summary   *TraceMetricsPoint   `json:"summary,omitempty"` // Metrics of the whole time range, timestamped with its start
+}
+
+// TraceMetricsSeries is the time series of a single group, or of all traces when not grouped
+type TraceMetricsSeries struct {
+	Group  string              `json:"group,omitempty"`
+	Points []TraceMetricsPoint `json:"points"`
+}
+
+// TraceMetricsPoint holds the metrics of a single time bucket
+type TraceMetricsPoint struct {
+	Timestamp     time.Time          `json:"timestamp"`
+	TraceCount    int                `json:"traceCount"`
+	ErrorCount    int                `json:"errorCount"` // Traces with at least one failed span
+	ErrorRate     float64            `json:"errorRate"`
+	DurationNanos LatencyPercentiles `json:"durationNanos"` // Trace duration percentiles
+	InputTokens   int                `json:"inputTokens"`
+	OutputTokens  int                `json:"outputTokens"`
+	TotalTokens   int                `json:"totalTokens"`
+	Cost          float64            `json:"cost"`             // Cost of priced models in USD
+	TTFTMs        *MillisPercentiles `json:"ttftMs,omitempty"` // Time-to-first-token percentiles of streamed LLM calls
+}
+
+// MillisPercentiles holds the p50, p95 and p99 of a duration in milliseconds
+type MillisPercentiles struct {
+	P50 float64 `json:"p50"`
+	P95 float64 `json:"p95"`
+	P99 float64 `json:"p99"`
+}
+
+// LatencyPercentiles holds the p50, p95 and p99 of a duration
+type LatencyPercentiles struct {
+	P50 int64 `json:"p50"`
+	P95 int64 `json:"p95"`
+	P99 int64 `json:"p99"`
+}
+
+// tokenUsageCondition matches the LLM call spans that report token usage
+func tokenUsageCondition() map[string]interface{} {
+	return map[string]interface{}{
+		"bool": map[string]interface{}{
+			"should": []map[string]interface{}{
+				{"exists": map[string]interface{}{"field": "attributes.gen_ai.usage.input_tokens"}},
+				{"exists": map[string]interface{}{"field": "attributes.gen_ai.usage.prompt_tokens"}},
+				{"exists": map[string]interface{}{"field": "attributes.gen_ai.usage.output_tokens"}},
+				{"exists": map[string]interface{}{"field": "attributes.gen_ai.usage.completion_tokens"}},
+			},
+			"minimum_should_match": 1,
+		},
+	}
+}
+
+// tokenUsageScript sums the first available token count field of each span
+func tokenUsageScript(fields ...string) map[string]interface{} {
+	return map[string]interface{}{
+		"script": map[string]interface{}{
+			"source": "for (def f : params.fields) { if (doc.containsKey(f) && doc[f].size() > 0) { return doc[f].value; } } return 0;",
+			"params": map[string]interface{}{
+				"fields": fields,
+			},
+		},
+	}
+}
+
+// buildMetricsFilters restricts a metrics query to the time range, component, environment, agent and traces
+func buildMetricsFilters(params TraceMetricsParams) []map[string]interface{} {
+	filters := []map[string]interface{}{
+		{
+			"range": map[string]interface{}{
+				"startTime": map[string]interface{}{
+					"gte": params.StartTime.UTC().Format(time.RFC3339Nano),
+					"lte": params.EndTime.UTC().Format(time.RFC3339Nano),
+				},
+			},
+		},
+	}
+	if params.ComponentUid != "" {
+		filters = append(filters, map[string]interface{}{
+			"term": map[string]interface{}{"resource.openchoreo.dev/component-uid": params.ComponentUid},
+		})
+	}
+	if params.EnvironmentUid != "" {
+		filters = append(filters, map[string]interface{}{
+			"term": map[string]interface{}{"resource.openchoreo.dev/environment-uid": params.EnvironmentUid},
+		})
+	}
+	if params.Agent != "" {
+		filters = append(filters, map[string]interface{}{
+			"term": map[string]interface{}{metricsGroupByFields[MetricsGroupByAgent]: params.Agent},
+		})
+	}
+	if params.TraceIDs != nil {
+		filters = append(filters, map[string]interface{}{
+			"terms": map[string]interface{}{"traceId": params.TraceIDs},
+		})
+	}
+	return filters
+}
+
+// buildMetricsBucketAggs builds the metric aggregations of a time bucket, decoded into a metricsBucket
+func buildMetricsBucketAggs() map[string]interface{} {
+	return map[string]interface{}{
+		"traces": map[string]interface{}{
+			"filter": rootSpanCondition(),
+			"aggs": map[string]interface{}{
+				"latency": map[string]interface{}{
+					"percentiles": map[string]interface{}{
+						"field":    "durationInNanos",
+						"percents": []float64{50, 95, 99},
+					},
+				},
+			},
+		},
+		"errors": map[string]interface{}{
+			"filter": map[string]interface{}{
+				"bool": map[string]interface{}{
+					"should":               errorStatusConditions(),
+					"minimum_should_match": 1,
+				},
+			},
+			"aggs": map[string]interface{}{
+				"traces": map[string]interface{}{
+					"cardinality": map[string]interface{}{"field": "traceId"},
+				},
+			},
+		},
+		"streaming": map[string]interface{}{
+			"filter": map[string]interface{}{
+				"exists": map[string]interface{}{"field": StreamingField + ".ttftMs"},
+			},
+			"aggs": map[string]interface{}{
+				"ttft": map[string]interface{}{
+					"percentiles": map[string]interface{}{
+						"field":    StreamingField + ".ttftMs",
+						"percents": []float64{50, 95, 99},
+					},
+				},
+			},
+		},
+		"llm": map[string]interface{}{
+			"filter": tokenUsageCondition(),
+			"aggs": map[string]interface{}{
+				"models": map[string]interface{}{
+					"terms": map[string]interface{}{
+						"field":   "attributes.gen_ai.request.model",
+						"size":    MaxMetricsGroups,
+						"missing": metricsUnknownGroup,
+					},
+					"aggs": map[string]interface{}{
+						"inputTokens":  map[string]interface{}{"sum": tokenUsageScript("attributes.gen_ai.usage.input_tokens", "attributes.gen_ai.usage.prompt_tokens")},
+						"outputTokens": map[string]interface{}{"sum": tokenUsageScript("attributes.gen_ai.usage.output_tokens", "attributes.gen_ai.usage.completion_tokens")},
+					},
+				},
+			},
+		},
+	}
+}
+
+// BuildTraceMetricsQuery builds a date histogram aggregation of trace counts, duration percentiles,
+// error counts, time-to-first-token percentiles and token usage per model, optionally broken down by a
+// group-by dimension
+func BuildTraceMetricsQuery(params TraceMetricsParams) map[string]interface{} {
+	filters := buildMetricsFilters(params)
+
+	timeline := map[string]interface{}{
+		"date_histogram": map[string]interface{}{
+			"field":          "startTime",
+			"fixed_interval": params.Interval,
+			"min_doc_count":  1,
+		},
+		"aggs": buildMetricsBucketAggs(),
+	}
+
+	aggs := map[string]interface{}{"timeline": timeline}
+	if field, ok := metricsGroupByFields[params.GroupBy]; ok {
+		aggs = map[string]interface{}{
+			"groups": map[string]interface{}{
+				"terms": map[string]interface{}{
+					"field":   field,
+					"size":    MaxMetricsGroups,
+					"missing": metricsUnknownGroup,
+				},
+				"aggs": aggs,
+			},
+		}
+	} else if key, ok := metricsGroupByLabel(params.GroupBy); ok {
+		aggs = buildLabelGroupAggs(key, timeline)
+	} else if params.Summary {
+		// The summary computes the metrics of a bucket over every span of the time range
+		aggs["summary"] = map[string]interface{}{
+			"filter": map[string]interface{}{"match_all": map[string]interface{}{}},
+			"aggs":   timeline["aggs"],
+		}
+	}
+
+	return map[string]interface{}{
+		"query": map[string]interface{}{
+			"bool": map[string]interface{}{
+				"filter": filters,
+			},
+		},
+		"size": 0,
+		"aggs": aggs,
+	}
+}
+
+// metricsTimeline is the decoded date histogram of a metrics query
+type metricsTimeline struct {
+	Buckets []metricsBucket `json:"buckets"`
+}
+
+// metricsBucket holds the decoded metric aggregations of a time bucket or of the summary
+type metricsBucket struct {
+	Key    int64 `json:"key"`
+	Traces struct {
+		DocCount int `json:"doc_count"`
+		Latency  struct {
+			Values map[string]*float64 `json:"values"`
+		} `json:"latency"`
+	} `json:"traces"`
+	Errors struct {
+		Traces struct {
+			Value int `json:"value"`
+		} `json:"traces"`
+	} `json:"errors"`
+	Streaming struct {
+		DocCount int `json:"doc_count"`
+		TTFT     struct {
+			Values map[string]*float64 `json:"values"`
+		} `json:"ttft"`
+	} `json:"streaming"`
+	LLM struct {
+		Models struct {
+			Buckets []struct {
+				Key          interface{} `json:"key"`
+				InputTokens  sumValue    `json:"inputTokens"`
+				OutputTokens sumValue    `json:"outputTokens"`
+			} `json:"buckets"`
+		} `json:"models"`
+	} `json:"llm"`
+}
+
+// sumValue is the result of a sum aggregation
+type sumValue struct {
+	Value float64 `json:"value"`
+}
+
+// ParseTraceMetrics decodes the aggregations of a metrics query into zero-filled time series
+// Token usage is priced per model with the pricing table
+func ParseTraceMetrics(response *SearchResponse, params TraceMetricsParams, table *pricing.Table) (*TraceMetricsResponse, error) {
+	result := &TraceMetricsResponse{
+		Interval:  params.Interval,
+		StartTime: params.StartTime.UTC(),
+		EndTime:   params.EndTime.UTC(),
+		GroupBy:   params.GroupBy,
+		Series:    []TraceMetricsSeries{},
+	}
+
+	if params.GroupBy == "" {
+		var timeline metricsTimeline
+		if raw, ok := response.Aggregations["timeline"]; ok {
+			if err := json.Unmarshal(raw, &timeline); err != nil {
+				return nil, fmt.Errorf("failed to decode metrics timeline: %w", err)
+			}
+		}
+		result.Series = append(result.Series, TraceMetricsSeries{Points: buildMetricsPoints(timeline, params, table)})
+		if params.Summary {
+			result.Summary = &TraceMetricsPoint{Timestamp: result.StartTime}
+			if raw, ok := response.Aggregations["summary"]; ok {
+				var summary metricsBucket
+				if err := json.Unmarshal(raw, &summary); err != nil {
+					return nil, fmt.Errorf("failed to decode metrics summary: %w", err)
+				}
+				fillMetricsPoint(result.Summary, summary, table)
+			}
+		}
+		return result, nil
+	}
+
+	var groups struct {
+		Buckets []struct {
+			Key      interface{}     `json:"key"`
+			Timeline metricsTimeline `json:"timeline"`
+		} `json:"buckets"`
+	}
+	if raw, ok := response.Aggregations["groups"]; ok {
+		if err := json.Unmarshal(raw, &groups); err != nil {
+			return nil, fmt.Errorf("failed to decode metrics groups: %w", err)
+		}
+	}
+	labelKey, byLabel := metricsGroupByLabel(params.GroupBy)
+	for _, group := range groups.Buckets {
+		name := fmt.Sprintf("%v", group.Key)
+		if byLabel {
+			name = strings.TrimPrefix(name, labelKey+"=")
+		}
+		result.Series = append(result.Series, TraceMetricsSeries{
+			Group:  name,
+			Points: buildMetricsPoints(group.Timeline, params, table),
+		})
+	}
+	// Spans of agents without the label are aggregated apart from the label values
+	if raw, ok := response.Aggregations["ungrouped"]; ok && byLabel {
+		var ungrouped struct {
+			DocCount int             `json:"doc_count"`
+			Timeline metricsTimeline `json:"timeline"`
+		}
+		if err := json.Unmarshal(raw, &ungrouped); err != nil {
+			return nil, fmt.Errorf("failed to decode ungrouped metrics: %w", err)
+		}
+		if ungrouped.DocCount > 0 {
+			result.Series = append(result.Series, TraceMetricsSeries{
+				Group:  metricsUnknownGroup,
+				Points: buildMetricsPoints(ungrouped.Timeline, params, table),
+			})
+		}
+	}
+	sort.Slice(result.Series, func(i, j int) bool {
+		return result.Series[i].Group < result.Series[j].Group
+	})
+	return result, nil
+}
+
+// buildMetricsPoints converts histogram buckets into one point per interval of the time range
+func buildMetricsPoints(timeline metricsTimeline, params TraceMetricsParams, table *pricing.Table) []TraceMetricsPoint {
+	interval := MetricsIntervals[params.Interval]
+	first := params.StartTime.UTC().Truncate(interval)
+	count := MetricsBucketCount(params.StartTime, params.EndTime, interval)
+
+	points := make([]TraceMetricsPoint, count)
+	for i := range points {
+		points[i].Timestamp = first.Add(time.Duration(i) * interval)
+	}
+
+	for _, bucket := range timeline.Buckets {
+		idx := int(time.UnixMilli(bucket.Key).UTC().Sub(first) / interval)
+		if idx < 0 || idx >= count {
+			continue
+		}
+		fillMetricsPoint(&points[idx], bucket, table)
+	}
+
+	return points
+}
+
+// fillMetricsPoint sets the metrics of a point from the aggregations of its bucket
+func fillMetricsPoint(point *TraceMetricsPoint, bucket metricsBucket, table *pricing.Table) {
+	point.TraceCount = bucket.Traces.DocCount
+	point.ErrorCount = bucket.Errors.Traces.Value
+	if point.TraceCount > 0 {
+		point.ErrorRate = min(float64(point.ErrorCount)/float64(point.TraceCount), 1)
+	}
+	point.DurationNanos = LatencyPercentiles{
+		P50: percentileValue(bucket.Traces.Latency.Values, 50),
+		P95: percentileValue(bucket.Traces.Latency.Values, 95),
+		P99: percentileValue(bucket.Traces.Latency.Values, 99),
+	}
+
+	if bucket.Streaming.DocCount > 0 {
+		point.TTFTMs = &MillisPercentiles{
+			P50: millisPercentileValue(bucket.Streaming.TTFT.Values, 50),
+			P95: millisPercentileValue(bucket.Streaming.TTFT.Values, 95),
+			P99: millisPercentileValue(bucket.Streaming.TTFT.Values, 99),
+		}
+	}
+
+	for _, model := range bucket.LLM.Models.Buckets {
+		usage := pricing.Usage{
+			InputTokens:  int(model.InputTokens.Value),
+			OutputTokens: int(model.OutputTokens.Value),
+		}
+		point.InputTokens += usage.InputTokens
+		point.OutputTokens += usage.OutputTokens
+		if cost := table.Calculate(fmt.Sprintf("%v", model.Key), usage); cost != nil {
+			point.Cost += cost.TotalCost
+		}
+	}
+	point.TotalTokens = point.InputTokens + point.OutputTokens
+}
+
+// percentileValue reads a percentile from a percentiles aggregation, which is null for empty buckets
+func percentileValue(values map[string]*float64, percent float64) int64 {
+	value := values[strconv.FormatFloat(percent, 'f', 1, 64)]
+	if value == nil {
+		return 0
+	}
+	return int64(*value)
+}
+
+// millisPercentileValue reads a millisecond percentile rounded to microseconds
+func millisPercentileValue(values map[string]*float64, percent float64) float64 {
+	value := values[strconv.FormatFloat(percent, 'f', 1, 64)]
+	if value == nil {
+		return 0
+	}
+	return math.Round(*value*1000) / 1000
+}
+
+// IsValidWindowMetricsGroupBy reports whether the metrics of a time window can be grouped by a dimension
+// Label groups are only supported by the time series
+func IsValidWindowMetricsGroupBy(groupBy string) bool {
+	_, ok := metricsGroupByFields[groupBy]
+	return ok
+}
+
+// BuildWindowMetricsQuery builds an aggregation of the metrics of the whole time range, per group when grouped
+// Interval and Summary are ignored, groups are limited to the agent, framework and model dimensions
+func BuildWindowMetricsQuery(params TraceMetricsParams) map[string]interface{} {
+	aggs := map[string]interface{}{
+		"window": map[string]interface{}{
+			"filter": map[string]interface{}{"match_all": map[string]interface{}{}},
+			"aggs":   buildMetricsBucketAggs(),
+		},
+	}
+	if field, ok := metricsGroupByFields[params.GroupBy]; ok {
+		aggs = map[string]interface{}{
+			"groups": map[string]interface{}{
+				"terms": map[string]interface{}{
+					"field":   field,
+					"size":    MaxMetricsGroups,
+					"missing": metricsUnknownGroup,
+				},
+				"aggs": buildMetricsBucketAggs(),
+			},
+		}
+	}
+
+	return map[string]interface{}{
+		"query": map[string]interface{}{
+			"bool": map[string]interface{}{
+				"filter": buildMetricsFilters(params),
+			},
+		},
+		"size": 0,
+		"aggs": aggs,
+	}
+}
+
+// ParseWindowMetrics decodes the aggregations of a window metrics query into the metrics of each group
+// The metrics of an ungrouped query are keyed by the empty group, groups without spans are absent
+func ParseWindowMetrics(response *SearchResponse, params TraceMetricsParams, table *pricing.Table) (map[string]TraceMetricsPoint, error) {
+	result := map[string]TraceMetricsPoint{}
+
+	if params.GroupBy == "" {
+		point := TraceMetricsPoint{Timestamp: params.StartTime.UTC()}
+		if raw, ok := response.Aggregations["window"]; ok {
+			var window metricsBucket
+			if err := json.Unmarshal(raw, &window); err != nil {
+				return nil, fmt.Errorf("failed to decode window metrics: %w", err)
+			}
+			fillMetricsPoint(&point, window, table)
+		}
+		result[""] = point
+		return result, nil
+	}
+
+	var groups struct {
+		Buckets []struct {
+			Key interface{} `json:"key"`
+			metricsBucket
+		} `json:"buckets"`
+	}
+	if raw, ok := response.Aggregations["groups"]; ok {
+		if err := json.Unmarshal(raw, &groups); err != nil {
+			return nil, fmt.Errorf("failed to decode window metrics groups: %w", err)
+		}
+	}
+	for _, group := range groups.Buckets {
+		point := TraceMetricsPoint{Timestamp: params.StartTime.UTC()}
+		fillMetricsPoint(&point, group.metricsBucket, table)
+		result[fmt.Sprintf("%v", group.Key)] = point
+	}
+	return result, nil
+}
@@ -0,0 +1,161 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package opensearch
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestBuildWindowMetricsQuery(t *testing.T) {
+	params := TraceMetricsParams{
+		StartTime: time.Date(2025, 11, 3, 10, 0, 0, 0, time.UTC),
+		EndTime:   time.Date(2025, 11, 3, 10, 15, 0, 0, time.UTC),
+		Agent:     "support-agent",
+		GroupBy:   MetricsGroupByModel,
+	}
+	query := BuildWindowMetricsQuery(params)
+
+	filters := query["query"].(map[string]interface{})["bool"].(map[string]interface{})["filter"].([]map[string]interface{})
+	agent, _ := json.Marshal(filters[len(filters)-1])
+	if string(agent) != `{"term":{"resource.service.name":"support-agent"}}` {
+		t.Errorf("agent filter = %s", agent)
+	}
+	groups := query["aggs"].(map[string]interface{})["groups"].(map[string]interface{})
+	if field := groups["terms"].(map[string]interface{})["field"]; field != "attributes.gen_ai.request.model" {
+		t.Errorf("groups field = %v, want the model", field)
+	}
+	if _, ok := groups["aggs"].(map[string]interface{})["errors"]; !ok {
+		t.Error("groups are missing the metric aggregations")
+	}
+
+	params.GroupBy = ""
+	if _, ok := BuildWindowMetricsQuery(params)["aggs"].(map[string]interface{})["window"]; !ok {
+		t.Error("ungrouped query is missing the window aggregation")
+	}
+}
+
+func TestParseWindowMetrics(t *testing.T) {
+	params := TraceMetricsParams{
+		StartTime: time.Date(2025, 11, 3, 10, 0, 0, 0, time.UTC),
+		EndTime:   time.Date(2025, 11, 3, 10, 15, 0, 0, time.UTC),
+		GroupBy:   MetricsGroupByAgent,
+	}
+	response := &SearchResponse{Aggregations: map[string]json.RawMessage{
+		"groups": json.RawMessage(`{"buckets": [
+			{"key": "support-agent", "doc_count": 120,
+				"traces": {"doc_count": 40, "latency": {"values": {"50.0": 2000000000, "95.0": 31000000000, "99.0": 45000000000}}},
+				"errors": {"traces": {"value": 10}}},
+			{"key": "billing-agent", "doc_count": 3,
+				"traces": {"doc_count": 0, "latency": {"values": {"50.0": null, "95.0": null, "99.0": null}}},
+				"errors": {"traces": {"value": 0}}}
+		]}`),
+	}}
+
+	points, err := ParseWindowMetrics(response, params, nil)
+	if err != nil {
+		t.Fatalf("ParseWindowMetrics() error = %v", err)
+	}
+	if len(points) != 2 {
+		t.Fatalf("groups = %d, want 2", len(points))
+	}
+	support := points["support-agent"]
+	if support.TraceCount != 40 || support.ErrorRate != 0.25 || support.DurationNanos.P95 != 31e9 {
+		t.Errorf("support-agent = %+v", support)
+	}
+	if billing := points["billing-agent"]; billing.TraceCount != 0 || billing.ErrorRate != 0 {
+		t.Errorf("billing-agent = %+v", billing)
+	}
+
+	params.GroupBy = ""
+	points, err = ParseWindowMetrics(&SearchResponse{}, params, nil)
+	if err != nil {
+		t.Fatalf("ParseWindowMetrics() error = %v", err)
+	}
+	if point, ok := points[""]; !ok || point.TraceCount != 0 {
+		t.Errorf("ungrouped points = %+v, want a single empty group", points)
+	}
+}
+
+func TestMetricsBucketCount(t *testing.T) {
+	tests := []struct {
+		name     string
+		start    string
+		end      string
+		interval time.Duration
+		want     int
+	}{
+		{"aligned range", "2025-11-03T10:00:00Z", "2025-11-03T10:05:00Z", time.Minute, 6},
+		{"unaligned start", "2025-11-03T10:00:30Z", "2025-11-03T10:04:10Z", time.Minute, 5},
+		{"empty range", "2025-11-03T10:00:00Z", "2025-11-03T10:00:00Z", time.Hour, 1},
+		{"daily buckets are aligned to UTC", "2025-11-03T23:00:00+02:00", "2025-11-04T01:00:00+02:00", 24 * time.Hour, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, _ := time.Parse(time.RFC3339, tt.start)
+			end, _ := time.Parse(time.RFC3339, tt.end)
+			if got := MetricsBucketCount(start, end, tt.interval); got != tt.want {
+				t.Errorf("MetricsBucketCount() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTraceMetricsZeroFillsBuckets(t *testing.T) {
+	params := TraceMetricsParams{
+		StartTime: time.Date(2025, 11, 3, 10, 0, 30, 0, time.UTC),
+		EndTime:   time.Date(2025, 11, 3, 10, 4, 10, 0, time.UTC),
+		Interval:  "1m",
+		Summary:   true,
+	}
+	bucketKey := func(minute int) int64 {
+		return time.Date(2025, 11, 3, 10, minute, 0, 0, time.UTC).UnixMilli()
+	}
+	response := &SearchResponse{Aggregations: map[string]json.RawMessage{
+		"timeline": json.RawMessage(fmt.Sprintf(`{"buckets": [
+			{"key": %d, "traces": {"doc_count": 4, "latency": {"values": {"50.0": 1000, "95.0": 2000, "99.0": null}}},
+				"errors": {"traces": {"value": 1}}},
+			{"key": %d, "traces": {"doc_count": 9}}
+		]}`, bucketKey(2), bucketKey(30))),
+	}}
+
+	result, err := ParseTraceMetrics(response, params, nil)
+	if err != nil {
+		t.Fatalf("ParseTraceMetrics() error = %v", err)
+	}
+	points := result.Series[0].Points
+	if len(points) != 5 {
+		t.Fatalf("points = %d, want one per minute from 10:00 to 10:04", len(points))
+	}
+	for i, point := range points {
+		if want := time.Date(2025, 11, 3, 10, i, 0, 0, time.UTC); !point.Timestamp.Equal(want) {
+			t.Errorf("point %d at %v, want %v", i, point.Timestamp, want)
+		}
+		if i != 2 && (point.TraceCount != 0 || point.ErrorCount != 0 || point.DurationNanos != (LatencyPercentiles{})) {
+			t.Errorf("point %d = %+v, want zero-filled", i, point)
+		}
+	}
+	filled := points[2]
+	if filled.TraceCount != 4 || filled.ErrorRate != 0.25 || filled.DurationNanos != (LatencyPercentiles{P50: 1000, P95: 2000}) {
+		t.Errorf("point 2 = %+v, want the bucket with a null percentile read as zero", filled)
+	}
+	if result.Summary == nil || !result.Summary.Timestamp.Equal(params.StartTime) || result.Summary.TraceCount != 0 {
+		t.Errorf("summary = %+v, want an empty summary at the start of the range", result.Summary)
+	}
+}
@@ -0,0 +1,218 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package opensearch
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/pricing"
+)
+
+// MaxModelMetricsModels bounds the number of models returned by a model metrics query
+const MaxModelMetricsModels = 200
+
+// modelFields lists the attributes naming the model of an LLM call in priority order
+// The response model reflects the model actually served, including provider fallbacks
+var modelFields = []string{
+	"attributes.gen_ai.response.model",
+	"attributes.gen_ai.request.model",
+}
+
+// ModelMetricsParams holds parameters for model usage queries
+type ModelMetricsParams struct {
+	ComponentUid   string
+	EnvironmentUid string
+	StartTime      time.Time
+	EndTime        time.Time
+}
+
+// ModelMetricsResponse represents LLM usage and cost aggregated per model
+type ModelMetricsResponse struct {
+	Models []ModelMetrics `json:"models"`
+	Cost   *pricing.Cost  `json:"cost"` // Total cost of priced models (null when no model is priced)
+}
+
+// ModelMetrics holds the usage of a single model
+type ModelMetrics struct {
+	Model                 string        `json:"model"`
+	RequestCount          int           `json:"requestCount"`
+	InputTokens           int           `json:"inputTokens"`
+	OutputTokens          int           `json:"outputTokens"`
+	CacheReadInputTokens  int           `json:"cacheReadInputTokens"`
+	CacheWriteInputTokens int           `json:"cacheWriteInputTokens"`
+	TotalTokens           int           `json:"totalTokens"`
+	AvgDurationNanos      int64         `json:"avgDurationNanos"` // Average latency per call
+	Cost                  *pricing.Cost `json:"cost"`             // Cost of the usage (null when the model is not priced)
+}
+
+// prefixedAttributes returns the document fields of span attributes
+func prefixedAttributes(attrs []string) []string {
+	fields := make([]string, len(attrs))
+	for i, attr := range attrs {
+		fields[i] = "attributes." + attr
+	}
+	return fields
+}
+
+// BuildModelMetricsQuery builds a terms aggregation of LLM calls per served model with token sums and latency
+func BuildModelMetricsQuery(params ModelMetricsParams) map[string]interface{} {
+	modelExists := []map[string]interface{}{}
+	for _, field := range modelFields {
+		modelExists = append(modelExists, map[string]interface{}{
+			"exists": map[string]interface{}{"field": field},
+		})
+	}
+
+	// Agent and workflow spans may name a model too, so only spans reporting token usage are counted as calls
+	filters := []map[string]interface{}{
+		tokenUsageCondition(),
+		{
+			"bool": map[string]interface{}{
+				"should":               modelExists,
+				"minimum_should_match": 1,
+			},
+		},
+		{
+			"range": map[string]interface{}{
+				"startTime": map[string]interface{}{
+					"gte": params.StartTime.UTC().Format(time.RFC3339Nano),
+					"lte": params.EndTime.UTC().Format(time.RFC3339Nano),
+				},
+			},
+		},
+	}
+	if params.ComponentUid != "" {
+		filters = append(filters, map[string]interface{}{
+			"term": map[string]interface{}{"resource.openchoreo.dev/component-uid": params.ComponentUid},
+		})
+	}
+	if params.EnvironmentUid != "" {
+		filters = append(filters, map[string]interface{}{
+			"term": map[string]interface{}{"resource.openchoreo.dev/environment-uid": params.EnvironmentUid},
+		})
+	}
+
+	return map[string]interface{}{
+		"query": map[string]interface{}{
+			"bool": map[string]interface{}{
+				"filter": filters,
+			},
+		},
+		"size": 0,
+		"aggs": map[string]interface{}{
+			"models": map[string]interface{}{
+				"terms": map[string]interface{}{
+					"script": map[string]interface{}{
+						"source": "for (def f : params.fields) { if (doc.containsKey(f) && doc[f].size() > 0) { return doc[f].value; } } return params.missing;",
+						"params": map[string]interface{}{
+							"fields":  modelFields,
+							"missing": metricsUnknownGroup,
+						},
+					},
+					"size": MaxModelMetricsModels,
+				},
+				"aggs": map[string]interface{}{
+					"inputTokens":      map[string]interface{}{"sum": tokenUsageScript("attributes.gen_ai.usage.input_tokens", "attributes.gen_ai.usage.prompt_tokens")},
+					"outputTokens":     map[string]interface{}{"sum": tokenUsageScript("attributes.gen_ai.usage.output_tokens", "attributes.gen_ai.usage.completion_tokens")},
+					"cacheReadTokens":  map[string]interface{}{"sum": tokenUsageScript(prefixedAttributes(cacheReadTokenAttributes)...)},
+					"cacheWriteTokens": map[string]interface{}{"sum": tokenUsageScript(prefixedAttributes(cacheWriteTokenAttributes)...)},
+					"latency":          map[string]interface{}{"avg": map[string]interface{}{"field": "durationInNanos"}},
+				},
+			},
+		},
+	}
+}
+
+// ParseModelMetrics decodes the aggregations of a model metrics query and prices the usage of each model
+// Models are ordered by cost, then by request count
+func ParseModelMetrics(response *SearchResponse, table *pricing.Table) (*ModelMetricsResponse, error) {
+	result := &ModelMetricsResponse{Models: []ModelMetrics{}}
+
+	raw, ok := response.Aggregations["models"]
+	if !ok {
+		return result, nil
+	}
+
+	var models struct {
+		Buckets []struct {
+			Key              interface{} `json:"key"`
+			DocCount         int         `json:"doc_count"`
+			InputTokens      sumValue    `json:"inputTokens"`
+			OutputTokens     sumValue    `json:"outputTokens"`
+			CacheReadTokens  sumValue    `json:"cacheReadTokens"`
+			CacheWriteTokens sumValue    `json:"cacheWriteTokens"`
+			Latency          struct {
+				Value *float64 `json:"value"`
+			} `json:"latency"`
+		} `json:"buckets"`
+	}
+	if err := json.Unmarshal(raw, &models); err != nil {
+		return nil, fmt.Errorf("failed to decode model metrics: %w", err)
+	}
+
+	for _, bucket := range models.Buckets {
+		model := ModelMetrics{
+			Model:                 fmt.Sprintf("%v", bucket.Key),
+			RequestCount:          bucket.DocCount,
+			InputTokens:           int(bucket.InputTokens.Value),
+			OutputTokens:          int(bucket.OutputTokens.Value),
+			CacheReadInputTokens:  int(bucket.CacheReadTokens.Value),
+			CacheWriteInputTokens: int(bucket.CacheWriteTokens.Value),
+		}
+		model.TotalTokens = model.InputTokens + model.OutputTokens
+		if bucket.Latency.Value != nil {
+			model.AvgDurationNanos = int64(*bucket.Latency.Value)
+		}
+
+		model.Cost = table.Calculate(model.Model, pricing.Usage{
+			InputTokens:           model.InputTokens,
+			OutputTokens:          model.OutputTokens,
+			CacheReadInputTokens:  model.CacheReadInputTokens,
+			CacheWriteInputTokens: model.CacheWriteInputTokens,
+		})
+		if model.Cost != nil {
+			if result.Cost == nil {
+				result.Cost = &pricing.Cost{}
+			}
+			result.Cost.InputCost += model.Cost.InputCost
+			result.Cost.OutputCost += model.Cost.OutputCost
+			result.Cost.TotalCost += model.Cost.TotalCost
+		}
+
+		result.Models = append(result.Models, model)
+	}
+
+	sort.SliceStable(result.Models, func(i, j int) bool {
+		ci, cj := modelTotalCost(result.Models[i]), modelTotalCost(result.Models[j])
+		if ci != cj {
+			return ci > cj
+		}
+		return result.Models[i].RequestCount > result.Models[j].RequestCount
+	})
+	return result, nil
+}
+
+// modelTotalCost returns the total cost of a model's usage, zero when the model is not priced
+func modelTotalCost(model ModelMetrics) float64 {
+	if model.Cost == nil {
+		return 0
+	}
+	return model.Cost.TotalCost
+}
@@ -0,0 +1,108 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package opensearch
+
+import (
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/pricing"
+)
+
+func TestBuildModelMetricsQuery(t *testing.T) {
+	encoded, err := json.Marshal(BuildModelMetricsQuery(ModelMetricsParams{
+		ComponentUid:   "comp-1",
+		EnvironmentUid: "env-1",
+		StartTime:      time.Date(2025, 11, 3, 0, 0, 0, 0, time.UTC),
+		EndTime:        time.Date(2025, 11, 4, 0, 0, 0, 0, time.UTC),
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	query := string(encoded)
+	for _, want := range []string{
+		// The served model takes precedence over the requested one
+		`"fields":["attributes.gen_ai.response.model","attributes.gen_ai.request.model"]`,
+		`{"exists":{"field":"attributes.gen_ai.request.model"}}`,
+		`{"term":{"resource.openchoreo.dev/component-uid":"comp-1"}}`,
+		`{"term":{"resource.openchoreo.dev/environment-uid":"env-1"}}`,
+		`"lte":"2025-11-04T00:00:00Z"`,
+		`"size":200`,
+		`"cacheReadTokens"`,
+		`"cacheWriteTokens"`,
+		`"latency":{"avg":{"field":"durationInNanos"}}`,
+	} {
+		if !strings.Contains(query, want) {
+			t.Errorf("query %s does not contain %s", query, want)
+		}
+	}
+}
+
+func TestParseModelMetrics(t *testing.T) {
+	table := pricing.NewTable(map[string]pricing.ModelPrice{
+		"cheap":  {InputPerMillion: 1, OutputPerMillion: 2},
+		"pricey": {InputPerMillion: 10, OutputPerMillion: 20},
+	})
+	response := &SearchResponse{Aggregations: map[string]json.RawMessage{
+		"models": json.RawMessage(`{"buckets": [
+			{"key": "unpriced-busy", "doc_count": 50, "inputTokens": {"value": 100}, "outputTokens": {"value": 10},
+				"cacheReadTokens": {"value": 0}, "cacheWriteTokens": {"value": 0}, "latency": {"value": null}},
+			{"key": "cheap", "doc_count": 20, "inputTokens": {"value": 1000000}, "outputTokens": {"value": 1000000},
+				"cacheReadTokens": {"value": 0}, "cacheWriteTokens": {"value": 0}, "latency": {"value": 250000000}},
+			{"key": "unpriced-quiet", "doc_count": 5, "inputTokens": {"value": 10}, "outputTokens": {"value": 1},
+				"cacheReadTokens": {"value": 0}, "cacheWriteTokens": {"value": 0}, "latency": {"value": null}},
+			{"key": "pricey", "doc_count": 2, "inputTokens": {"value": 1000000}, "outputTokens": {"value": 1000000},
+				"cacheReadTokens": {"value": 400000}, "cacheWriteTokens": {"value": 100000}, "latency": {"value": 900000000}}
+		]}`),
+	}}
+
+	result, err := ParseModelMetrics(response, table)
+	if err != nil {
+		t.Fatalf("ParseModelMetrics() error = %v", err)
+	}
+
+	// Priced models by cost, then unpriced models by request count
+	var order []string
+	for _, model := range result.Models {
+		order = append(order, model.Model)
+	}
+	if got, want := strings.Join(order, ","), "pricey,cheap,unpriced-busy,unpriced-quiet"; got != want {
+		t.Errorf("models = %s, want %s", got, want)
+	}
+
+	pricey := result.Models[0]
+	if pricey.TotalTokens != 2000000 || pricey.CacheReadInputTokens != 400000 || pricey.CacheWriteInputTokens != 100000 || pricey.AvgDurationNanos != 900000000 {
+		t.Errorf("pricey = %+v", pricey)
+	}
+	if cheap := result.Models[1]; cheap.Cost == nil || math.Abs(cheap.Cost.TotalCost-3) > 1e-9 {
+		t.Errorf("cheap cost = %+v, want 3", cheap.Cost)
+	}
+	if busy := result.Models[2]; busy.Cost != nil || busy.AvgDurationNanos != 0 {
+		t.Errorf("unpriced-busy = %+v, want no cost or latency", busy)
+	}
+	if result.Cost == nil || math.Abs(result.Cost.TotalCost-pricey.Cost.TotalCost-3) > 1e-9 {
+		t.Errorf("total cost = %+v, want the sum of priced models", result.Cost)
+	}
+
+	empty, err := ParseModelMetrics(&SearchResponse{}, table)
+	if err != nil || len(empty.Models) != 0 || empty.Cost != nil {
+		t.Errorf("ParseModelMetrics() of no aggregations = %+v, %v, want no models and no cost", empty, err)
+	}
+}
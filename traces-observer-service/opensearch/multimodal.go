@@ -0,0 +1,295 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package opensearch
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"sync"
+)
+
+// MultimodalField is the document field flagging spans whose media payloads were replaced by placeholders
+const MultimodalField = "hasMultimodal"
+
+// MediaPart is a media payload removed from the content of a span
+type MediaPart struct {
+	Kind string // image, audio, video or file
+	MIME string
+	Data []byte
+}
+
+// MediaStore keeps the media payloads removed from spans
+type MediaStore interface {
+	// StoreMedia stores a payload and returns the reference kept in its placeholder
+	StoreMedia(traceID, spanID string, part MediaPart) (string, error)
+}
+
+var (
+	mediaStoreMu      sync.RWMutex
+	currentMediaStore MediaStore
+)
+
+// SetMediaStore registers the store receiving media payloads removed at ingestion
+// Passing nil only replaces payloads by placeholders
+func SetMediaStore(store MediaStore) {
+	mediaStoreMu.Lock()
+	defer mediaStoreMu.Unlock()
+	currentMediaStore = store
+}
+
+// getMediaStore returns the registered media store
+func getMediaStore() MediaStore {
+	mediaStoreMu.RLock()
+	defer mediaStoreMu.RUnlock()
+	return currentMediaStore
+}
+
+// mediaPlaceholder replaces a media payload in message content
+type mediaPlaceholder struct {
+	Type  string `json:"type"`
+	Bytes int    `json:"bytes"`
+	MIME  string `json:"mime,omitempty"`
+	Ref   string `json:"ref,omitempty"` // Reference of the payload in the media store
+}
+
+// mediaReplacer replaces the media payloads of a span
+type mediaReplacer struct {
+	traceID string
+	spanID  string
+	store   MediaStore
+	count   int
+}
+
+// replaceMultimodal replaces the image, audio and other binary parts of the string attributes and event
+// attributes of a document by placeholders, in place, and returns the number of parts replaced
+// Payloads are handed to the media store when one is given. Text is left untouched.
+func replaceMultimodal(source map[string]interface{}, store MediaStore) int {
+	r := &mediaReplacer{store: store}
+	r.traceID, _ = source["traceId"].(string)
+	r.spanID, _ = source["spanId"].(string)
+
+	if attributes, ok := source["attributes"].(map[string]interface{}); ok {
+		r.replaceAttributes(attributes)
+	}
+	if events, ok := source["events"].([]interface{}); ok {
+		for _, item := range events {
+			if event, ok := item.(map[string]interface{}); ok {
+				if attributes, ok := event["attributes"].(map[string]interface{}); ok {
+					r.replaceAttributes(attributes)
+				}
+			}
+		}
+	}
+	return r.count
+}
+
+// replaceAttributes replaces the media payloads of the string values of an attribute map
+func (r *mediaReplacer) replaceAttributes(attributes map[string]interface{}) {
+	for key, value := range attributes {
+		if strValue, ok := value.(string); ok {
+			if replaced, ok := r.replaceString(strValue); ok {
+				attributes[key] = replaced
+			}
+		}
+	}
+}
+
+// replaceString replaces a data URL value, or the media parts of a JSON encoded value
+func (r *mediaReplacer) replaceString(value string) (string, bool) {
+	if !mightContainMedia(value) {
+		return value, false
+	}
+
+	trimmed := strings.TrimSpace(value)
+	if strings.HasPrefix(trimmed, "data:") {
+		placeholder, ok := r.replaceDataURL(trimmed, "file")
+		if !ok {
+			return value, false
+		}
+		return encodeJSON(placeholder), true
+	}
+	if !strings.HasPrefix(trimmed, "{") && !strings.HasPrefix(trimmed, "[") {
+		return value, false
+	}
+
+	// Numbers are kept as written so that only the media parts change
+	decoder := json.NewDecoder(strings.NewReader(trimmed))
+	decoder.UseNumber()
+	var decoded interface{}
+	if err := decoder.Decode(&decoded); err != nil {
+		return value, false
+	}
+	before := r.count
+	replaced := r.walk(decoded)
+	if r.count == before {
+		return value, false
+	}
+	return encodeJSON(replaced), true
+}
+
+// walk replaces the media parts of a decoded JSON value
+func (r *mediaReplacer) walk(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if placeholder, ok := r.replacePart(v); ok {
+			return placeholder
+		}
+		for key, item := range v {
+			v[key] = r.walk(item)
+		}
+		return v
+	case []interface{}:
+		for i, item := range v {
+			v[i] = r.walk(item)
+		}
+		return v
+	case string:
+		if strings.HasPrefix(v, "data:") {
+			if placeholder, ok := r.replaceDataURL(v, "file"); ok {
+				return placeholder
+			}
+		}
+		return v
+	}
+	return value
+}
+
+// replacePart replaces a message content part carrying an inline media payload
+// The part formats of OpenAI, Anthropic, Gemini and the OTel GenAI conventions are recognised;
+// parts referring to media by URL carry no payload and are kept
+func (r *mediaReplacer) replacePart(part map[string]interface{}) (*mediaPlaceholder, bool) {
+	partType, _ := part["type"].(string)
+	switch partType {
+	case "image_url", "input_image":
+		url, _ := part["image_url"].(string)
+		if imageURL, ok := part["image_url"].(map[string]interface{}); ok {
+			url, _ = imageURL["url"].(string)
+		}
+		return r.replaceDataURL(url, "image")
+	case "image", "document", "audio":
+		source, _ := part["source"].(map[string]interface{})
+		if source == nil || source["type"] != "base64" {
+			return nil, false
+		}
+		mime, _ := source["media_type"].(string)
+		data, _ := source["data"].(string)
+		return r.replacePayload(partType, mime, data)
+	case "input_audio":
+		audio, _ := part["input_audio"].(map[string]interface{})
+		if audio == nil {
+			return nil, false
+		}
+		data, _ := audio["data"].(string)
+		mime := ""
+		if format, ok := audio["format"].(string); ok && format != "" {
+			mime = "audio/" + format
+		}
+		return r.replacePayload("audio", mime, data)
+	case "blob":
+		mime, _ := part["mime_type"].(string)
+		data, _ := part["content"].(string)
+		kind, _ := part["modality"].(string)
+		return r.replacePayload(kind, mime, data)
+	}
+
+	for _, key := range []string{"inline_data", "inlineData"} {
+		if inline, ok := part[key].(map[string]interface{}); ok {
+			mime, _ := inline["mime_type"].(string)
+			if mime == "" {
+				mime, _ = inline["mimeType"].(string)
+			}
+			data, _ := inline["data"].(string)
+			return r.replacePayload("", mime, data)
+		}
+	}
+	return nil, false
+}
+
+// replaceDataURL replaces a base64 data URL, returning false for other URLs
+func (r *mediaReplacer) replaceDataURL(url, kind string) (*mediaPlaceholder, bool) {
+	header, data, ok := strings.Cut(url, ",")
+	if !ok || !strings.HasPrefix(header, "data:") || !strings.HasSuffix(header, ";base64") {
+		return nil, false
+	}
+	mime := strings.TrimSuffix(strings.TrimPrefix(header, "data:"), ";base64")
+	// Parameters such as the charset are not part of the media type
+	mime, _, _ = strings.Cut(mime, ";")
+	return r.replacePayload(kind, mime, data)
+}
+
+// replacePayload builds the placeholder of a base64 payload and stores the payload if a store is set
+func (r *mediaReplacer) replacePayload(kind, mime, data string) (*mediaPlaceholder, bool) {
+	if data == "" {
+		return nil, false
+	}
+	decoded, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		decoded, err = base64.RawStdEncoding.DecodeString(strings.TrimRight(data, "="))
+	}
+
+	placeholder := &mediaPlaceholder{Type: mediaKind(kind, mime), MIME: mime}
+	if err == nil {
+		placeholder.Bytes = len(decoded)
+	} else {
+		placeholder.Bytes = base64.StdEncoding.DecodedLen(len(data))
+	}
+	if r.store != nil && err == nil {
+		ref, err := r.store.StoreMedia(r.traceID, r.spanID, MediaPart{Kind: placeholder.Type, MIME: mime, Data: decoded})
+		if err != nil {
+			slog.Warn("Failed to store media of span", "traceId", r.traceID, "spanId", r.spanID, "error", err)
+		} else {
+			placeholder.Ref = ref
+		}
+	}
+	r.count++
+	return placeholder, true
+}
+
+// mediaKind returns the kind of a payload from its media type, falling back to the kind of its part
+func mediaKind(kind, mime string) string {
+	for _, prefix := range []string{"image", "audio", "video"} {
+		if strings.HasPrefix(mime, prefix+"/") {
+			return prefix
+		}
+	}
+	if kind == "" {
+		return "file"
+	}
+	return kind
+}
+
+// mightContainMedia cheaply rules out values without inline media
+func mightContainMedia(value string) bool {
+	return strings.Contains(value, "base64") ||
+		strings.Contains(value, "input_audio") ||
+		strings.Contains(value, "blob") ||
+		strings.Contains(value, "nline")
+}
+
+// encodeJSON encodes a value without escaping HTML characters, so that text reads as it was written
+func encodeJSON(value interface{}) string {
+	var buffer bytes.Buffer
+	encoder := json.NewEncoder(&buffer)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(value); err != nil {
+		return ""
+	}
+	return strings.TrimSuffix(buffer.String(), "\n")
+}
@@ -0,0 +1,178 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package opensearch
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// pngPayload is the base64 payload of a 12 byte image
+var pngPayload = base64.StdEncoding.EncodeToString([]byte("\x89PNG\r\n\x1a\nIHDR"))
+
+// recordingMediaStore keeps the payloads it receives, failing when err is set
+type recordingMediaStore struct {
+	parts []MediaPart
+	err   error
+}
+
+func (s *recordingMediaStore) StoreMedia(traceID, spanID string, part MediaPart) (string, error) {
+	if s.err != nil {
+		return "", s.err
+	}
+	s.parts = append(s.parts, part)
+	return "s3://media/" + traceID + "/" + spanID + "/" + part.Kind, nil
+}
+
+func withMediaStore(t *testing.T, store MediaStore) {
+	previous := getMediaStore()
+	SetMediaStore(store)
+	t.Cleanup(func() { SetMediaStore(previous) })
+}
+
+func TestReplaceMultimodal(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  string
+	}{
+		{
+			name:  "OpenAI image part",
+			value: `[{"role":"user","content":[{"type":"text","text":"What is in <this> image?"},{"type":"image_url","image_url":{"url":"data:image/png;base64,` + pngPayload + `","detail":"high"}}]}]`,
+			want:  `[{"content":[{"text":"What is in <this> image?","type":"text"},{"type":"image","bytes":12,"mime":"image/png"}],"role":"user"}]`,
+		},
+		{
+			name:  "Anthropic image part",
+			value: `{"content":[{"type":"image","source":{"type":"base64","media_type":"image/jpeg","data":"` + pngPayload + `"}}],"max_tokens":1024}`,
+			want:  `{"content":[{"type":"image","bytes":12,"mime":"image/jpeg"}],"max_tokens":1024}`,
+		},
+		{
+			name:  "OpenAI audio part",
+			value: `[{"type":"input_audio","input_audio":{"data":"` + pngPayload + `","format":"wav"}}]`,
+			want:  `[{"type":"audio","bytes":12,"mime":"audio/wav"}]`,
+		},
+		{
+			name:  "Gemini inline data",
+			value: `{"parts":[{"inlineData":{"mimeType":"application/pdf","data":"` + pngPayload + `"}}]}`,
+			want:  `{"parts":[{"type":"file","bytes":12,"mime":"application/pdf"}]}`,
+		},
+		{
+			name:  "OTel blob part",
+			value: `[{"type":"blob","modality":"video","mime_type":"video/mp4","content":"` + pngPayload + `"}]`,
+			want:  `[{"type":"video","bytes":12,"mime":"video/mp4"}]`,
+		},
+		{
+			name:  "data URL attribute",
+			value: "data:image/webp;charset=binary;base64," + pngPayload,
+			want:  `{"type":"image","bytes":12,"mime":"image/webp"}`,
+		},
+		{
+			name:  "unpadded payload",
+			value: `{"url":"data:audio/mpeg;base64,` + strings.TrimRight(base64.StdEncoding.EncodeToString([]byte("ID3a")), "=") + `"}`,
+			want:  `{"url":{"type":"audio","bytes":4,"mime":"audio/mpeg"}}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attributes := map[string]interface{}{"gen_ai.input.messages": tt.value}
+			n := replaceMultimodal(map[string]interface{}{"attributes": attributes}, nil)
+
+			if n != 1 || attributes["gen_ai.input.messages"] != tt.want {
+				t.Errorf("replaceMultimodal() = %d, value %s, want 1 and %s", n, attributes["gen_ai.input.messages"], tt.want)
+			}
+		})
+	}
+}
+
+func TestReplaceMultimodalKeepsText(t *testing.T) {
+	values := []string{
+		`[{"type":"image_url","image_url":{"url":"https://example.com/cat.png"}}]`,
+		`{"type":"image","source":{"type":"url","url":"https://example.com/cat.png"}}`,
+		"Explain base64 encoding to me",
+		`{"note":"base64 is an encoding","count":12345678901234567890}`,
+		`[{"type":"text","text":"blob storage"}`,
+		"data:text/plain,hello",
+	}
+	for _, value := range values {
+		attributes := map[string]interface{}{"gen_ai.prompt": value, "retries": 3}
+		if n := replaceMultimodal(map[string]interface{}{"attributes": attributes}, nil); n != 0 || attributes["gen_ai.prompt"] != value {
+			t.Errorf("replaceMultimodal(%s) = %d, value %v, want the text kept verbatim", value, n, attributes["gen_ai.prompt"])
+		}
+	}
+}
+
+func TestReplaceMultimodalStoresPayloads(t *testing.T) {
+	store := &recordingMediaStore{}
+	source := map[string]interface{}{
+		"traceId":    "trace-1",
+		"spanId":     "span-1",
+		"attributes": map[string]interface{}{"input": "data:image/png;base64," + pngPayload},
+		"events": []interface{}{map[string]interface{}{
+			"name":       "gen_ai.user.message",
+			"attributes": map[string]interface{}{"content": `[{"type":"input_audio","input_audio":{"data":"` + pngPayload + `","format":"mp3"}}]`},
+		}},
+	}
+
+	if n := replaceMultimodal(source, store); n != 2 {
+		t.Fatalf("replaceMultimodal() = %d, want the attribute and event payloads replaced", n)
+	}
+	if len(store.parts) != 2 || store.parts[0].MIME != "image/png" || string(store.parts[0].Data) != "\x89PNG\r\n\x1a\nIHDR" {
+		t.Errorf("stored parts = %+v, want the decoded payloads", store.parts)
+	}
+	if got := source["attributes"].(map[string]interface{})["input"]; got != `{"type":"image","bytes":12,"mime":"image/png","ref":"s3://media/trace-1/span-1/image"}` {
+		t.Errorf("placeholder = %s, want the reference of the stored payload", got)
+	}
+
+	// A payload the store rejects is still replaced, without a reference
+	failing := &recordingMediaStore{err: errors.New("unavailable")}
+	attributes := map[string]interface{}{"input": "data:image/png;base64," + pngPayload}
+	if n := replaceMultimodal(map[string]interface{}{"attributes": attributes}, failing); n != 1 || attributes["input"] != `{"type":"image","bytes":12,"mime":"image/png"}` {
+		t.Errorf("replaceMultimodal() = %d, value %s, want a placeholder without a reference", n, attributes["input"])
+	}
+}
+
+func TestProcessDocumentFlagsMultimodal(t *testing.T) {
+	store := &recordingMediaStore{}
+	withMediaStore(t, store)
+
+	source := map[string]interface{}{
+		"traceId": "trace-1",
+		"spanId":  "llm",
+		"name":    "chat gpt-4o",
+		"attributes": map[string]interface{}{
+			"traceloop.span.kind":     "llm",
+			"gen_ai.prompt.0.role":    "user",
+			"gen_ai.prompt.0.content": `[{"type":"text","text":"Describe it"},{"type":"image_url","image_url":{"url":"data:image/png;base64,` + pngPayload + `"}}]`,
+		},
+	}
+	span := ProcessDocument(source)
+
+	if !span.HasMultimodal || source[MultimodalField] != true || len(store.parts) != 1 {
+		t.Errorf("span flagged %v, field %v, stored %d, want the payload replaced and flagged", span.HasMultimodal, source[MultimodalField], len(store.parts))
+	}
+	input, _ := span.AmpAttributes.Input.([]PromptMessage)
+	if len(input) != 1 || strings.Contains(input[0].Content, pngPayload) || !strings.Contains(input[0].Content, "Describe it") {
+		t.Errorf("input = %+v, want the text kept and the payload replaced", span.AmpAttributes.Input)
+	}
+
+	plain := map[string]interface{}{"traceId": "trace-1", "spanId": "tool", "attributes": map[string]interface{}{"input": "hello"}}
+	if span := ProcessDocument(plain); span.HasMultimodal || plain[MultimodalField] != nil {
+		t.Errorf("span without media flagged as multimodal")
+	}
+}
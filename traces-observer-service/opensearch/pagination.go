@@ -0,0 +1,172 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package opensearch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// MaxResultWindow is the number of hits OpenSearch pages through with from and size
+// Deeper pages are only reachable with a cursor
+const MaxResultWindow = 10000
+
+// CursorKeepAlive is how long the point in time of a listing is kept between pages
+const CursorKeepAlive = "5m"
+
+// ErrInvalidCursor is returned for cursors that were altered or issued for another sort
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// PageRequest describes a page of a query paged with search_after
+type PageRequest struct {
+	Indices   []string
+	Query     map[string]interface{} // Sorted with a unique tie breaker, without search_after
+	Size      int
+	Cursor    *TraceCursor // Position after the previous page, nil for the first page
+	KeepAlive string       // Keep alive of the point in time, CursorKeepAlive when empty
+}
+
+// SearchPage reads a page of a query and returns the cursor of the next page, nil on the last page
+// The first page is a plain search. A point in time is opened once there is a next page, so that documents
+// indexed meanwhile do not shift the following pages, and it is closed with the last page or expires after
+// its keep alive. A point in time that expired is replaced, paging on with search_after alone. Clusters
+// without point in time support are paged with search_after alone.
+func (c *Client) SearchPage(ctx context.Context, request PageRequest) (*SearchResponse, *TraceCursor, error) {
+	keepAlive := request.KeepAlive
+	if keepAlive == "" {
+		keepAlive = CursorKeepAlive
+	}
+	sortKey, sortFields := sortSignature(request.Query)
+
+	query := make(map[string]interface{}, len(request.Query)+2)
+	for key, value := range request.Query {
+		query[key] = value
+	}
+	query["size"] = request.Size
+
+	pitID := ""
+	cursor := request.Cursor
+	if cursor != nil && len(cursor.SearchAfter) > 0 {
+		if err := validateCursor(cursor, sortKey, sortFields); err != nil {
+			return nil, nil, err
+		}
+		// The cursor takes precedence over offset pagination
+		delete(query, "from")
+		query["search_after"] = cursor.SearchAfter
+		pitID = cursor.PitID
+	}
+
+	var response *SearchResponse
+	var err error
+	if pitID != "" {
+		response, err = c.SearchPIT(ctx, pitID, keepAlive, query)
+		if IsStatusError(err, http.StatusNotFound) {
+			slog.WarnContext(ctx, "Point in time of cursor expired, paging on without it")
+			pitID = ""
+		} else if err == nil && response.PitID != "" {
+			pitID = response.PitID
+		}
+	}
+	if pitID == "" {
+		response, err = c.Search(ctx, request.Indices, query)
+	}
+	if err != nil {
+		// The query is built by the service, so a rejected page with a cursor is a rejected cursor
+		if cursor != nil && IsStatusError(err, http.StatusBadRequest) {
+			return nil, nil, fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+		}
+		return nil, nil, err
+	}
+
+	hits := response.Hits.Hits
+	if len(hits) < request.Size || len(hits[len(hits)-1].Sort) == 0 {
+		c.ReleaseCursor(ctx, &TraceCursor{PitID: pitID})
+		return response, nil, nil
+	}
+	if pitID == "" {
+		pitID = c.openPagePIT(ctx, request.Indices, keepAlive)
+	}
+	return response, &TraceCursor{PitID: pitID, SearchAfter: hits[len(hits)-1].Sort, Sort: sortKey}, nil
+}
+
+// ReleaseCursor closes the point in time of a cursor that will not be followed
+// Failures are logged, the point in time then expires after its keep alive
+func (c *Client) ReleaseCursor(ctx context.Context, cursor *TraceCursor) {
+	if cursor == nil || cursor.PitID == "" {
+		return
+	}
+	// The request context may already be cancelled
+	if err := c.DeletePIT(context.WithoutCancel(ctx), cursor.PitID); err != nil {
+		slog.WarnContext(ctx, "Failed to delete point in time", "error", err)
+	}
+}
+
+// openPagePIT opens a point in time over the existing indices, empty when it cannot be opened
+func (c *Client) openPagePIT(ctx context.Context, indices []string, keepAlive string) string {
+	// The point in time can only be opened over indices that exist
+	existing, err := c.GetIndices(ctx, strings.Join(indices, ","))
+	if err != nil || len(existing) == 0 {
+		return ""
+	}
+	sort.Strings(existing)
+	pitID, err := c.CreatePIT(ctx, existing, keepAlive)
+	if err != nil {
+		slog.WarnContext(ctx, "Point in time unavailable, paging with search_after only", "error", err)
+		return ""
+	}
+	return pitID
+}
+
+// sortSignature renders the sort clause of a query as field:order pairs and counts its fields
+func sortSignature(query map[string]interface{}) (string, int) {
+	clauses, ok := query["sort"].([]map[string]interface{})
+	if !ok {
+		return "", 0
+	}
+	parts := make([]string, 0, len(clauses))
+	for _, clause := range clauses {
+		for field, options := range clause {
+			order := ""
+			if values, ok := options.(map[string]string); ok {
+				order = values["order"]
+			}
+			parts = append(parts, field+":"+order)
+		}
+	}
+	return strings.Join(parts, ","), len(parts)
+}
+
+// validateCursor checks that a cursor was issued for the sort of the query and holds one scalar per sort field
+func validateCursor(cursor *TraceCursor, sortKey string, sortFields int) error {
+	if cursor.Sort != sortKey || len(cursor.SearchAfter) != sortFields {
+		return fmt.Errorf("%w: issued for another sort", ErrInvalidCursor)
+	}
+	for _, value := range cursor.SearchAfter {
+		switch value.(type) {
+		case nil, string, bool, float64, json.Number:
+		default:
+			return fmt.Errorf("%w: sort values must be scalars", ErrInvalidCursor)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,236 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package opensearch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/config"
+)
+
+// fakePagingOpenSearch serves root spans sorted by startTime and traceId, with points in time
+// Points in time named "expired" are unknown and those named "malformed" are rejected
+type fakePagingOpenSearch struct {
+	mu         sync.Mutex
+	traces     int
+	pits       int
+	pitSearch  int
+	deletedPIT []string
+}
+
+func (f *fakePagingOpenSearch) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch {
+	case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/_search/point_in_time"):
+		f.pits++
+		fmt.Fprintf(w, `{"pit_id":"pit-%d"}`, f.pits)
+	case r.Method == http.MethodDelete && r.URL.Path == "/_search/point_in_time":
+		var body struct {
+			PitID []string `json:"pit_id"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		f.deletedPIT = append(f.deletedPIT, body.PitID...)
+		fmt.Fprint(w, `{"pits":[]}`)
+	case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/_search"):
+		var body struct {
+			Size        int           `json:"size"`
+			SearchAfter []interface{} `json:"search_after"`
+			Pit         *struct {
+				ID string `json:"id"`
+			} `json:"pit"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if body.Pit != nil {
+			f.pitSearch++
+			switch body.Pit.ID {
+			case "expired":
+				http.Error(w, `{"error":"no such search context"}`, http.StatusNotFound)
+				return
+			case "malformed":
+				http.Error(w, `{"error":"invalid pit id"}`, http.StatusBadRequest)
+				return
+			}
+		}
+		after := -1
+		if len(body.SearchAfter) > 0 {
+			value, ok := body.SearchAfter[0].(float64)
+			if !ok {
+				http.Error(w, `{"error":"failed to parse search_after"}`, http.StatusBadRequest)
+				return
+			}
+			after = int(value)
+		}
+		hits := []string{}
+		for i := after + 1; i < f.traces && len(hits) < body.Size; i++ {
+			hits = append(hits, fmt.Sprintf(`{"_source":{"traceId":"t%d","spanId":"s%d"},"sort":[%d,"t%d","s%d"]}`, i, i, i, i, i))
+		}
+		fmt.Fprintf(w, `{"hits":{"total":{"value":%d},"hits":[%s]}}`, f.traces, strings.Join(hits, ","))
+	case r.Method == http.MethodGet && r.URL.Path != "/":
+		fmt.Fprint(w, `{"otel-traces-2025-11-03":{}}`)
+	default:
+		fmt.Fprint(w, `{"cluster_name":"test","version":{"distribution":"opensearch","number":"2.11.0"}}`)
+	}
+}
+
+// newPagingTestClient returns a client of a fake OpenSearch holding the given number of traces
+func newPagingTestClient(t *testing.T, traces int) (*Client, *fakePagingOpenSearch) {
+	fake := &fakePagingOpenSearch{traces: traces}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	client, err := NewClient(&config.OpenSearchConfig{
+		Address:        server.URL,
+		Username:       "admin",
+		Password:       "admin",
+		RequestTimeout: 5 * time.Second,
+	}, nil)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	return client, fake
+}
+
+// pagingTestRequest returns the page request of a trace listing sorted by start time
+func pagingTestRequest(cursor *TraceCursor) PageRequest {
+	return PageRequest{
+		Indices: []string{"otel-traces-2025-11-03"},
+		Query:   BuildTraceQuery(TraceQueryParams{SortOrder: "asc", Limit: 2}),
+		Size:    2,
+		Cursor:  cursor,
+	}
+}
+
+func TestSearchPagePagesFromPointInTime(t *testing.T) {
+	client, fake := newPagingTestClient(t, 5)
+
+	var traceIDs []string
+	var cursor *TraceCursor
+	for page := 0; page < 5; page++ {
+		response, next, err := client.SearchPage(context.Background(), pagingTestRequest(cursor))
+		if err != nil {
+			t.Fatalf("SearchPage() error = %v", err)
+		}
+		for _, span := range ParseSpans(response) {
+			traceIDs = append(traceIDs, span.TraceID)
+		}
+		if next == nil {
+			break
+		}
+		if next.PitID != "pit-1" {
+			t.Errorf("page %d cursor point in time = %q, want pit-1", page, next.PitID)
+		}
+		// Cursors travel to clients as opaque strings
+		encoded, err := EncodeCursor(*next)
+		if err != nil {
+			t.Fatalf("EncodeCursor() error = %v", err)
+		}
+		if cursor, err = DecodeCursor(encoded); err != nil {
+			t.Fatalf("DecodeCursor() error = %v", err)
+		}
+	}
+
+	if got := strings.Join(traceIDs, ","); got != "t0,t1,t2,t3,t4" {
+		t.Errorf("traces = %s, want every trace once in order", got)
+	}
+	if fake.pits != 1 || fake.pitSearch != 2 {
+		t.Errorf("points in time = %d, point in time searches = %d, want 1 and 2", fake.pits, fake.pitSearch)
+	}
+	if len(fake.deletedPIT) != 1 || fake.deletedPIT[0] != "pit-1" {
+		t.Errorf("deleted points in time = %v, want pit-1 closed with the last page", fake.deletedPIT)
+	}
+}
+
+func TestSearchPageSinglePageOpensNoPointInTime(t *testing.T) {
+	client, fake := newPagingTestClient(t, 1)
+
+	_, next, err := client.SearchPage(context.Background(), pagingTestRequest(nil))
+	if err != nil {
+		t.Fatalf("SearchPage() error = %v", err)
+	}
+	if next != nil || fake.pits != 0 {
+		t.Errorf("next = %+v, points in time = %d, want a last page without point in time", next, fake.pits)
+	}
+}
+
+func TestSearchPageExpiredPointInTime(t *testing.T) {
+	client, fake := newPagingTestClient(t, 5)
+
+	cursor := &TraceCursor{PitID: "expired", SearchAfter: []interface{}{json.Number("1"), "t1", "s1"}, Sort: "startTime:asc,traceId:asc,spanId:asc"}
+	response, next, err := client.SearchPage(context.Background(), pagingTestRequest(cursor))
+	if err != nil {
+		t.Fatalf("SearchPage() error = %v", err)
+	}
+	if spans := ParseSpans(response); len(spans) != 2 || spans[0].TraceID != "t2" {
+		t.Errorf("spans = %+v, want t2 and t3", spans)
+	}
+	if next == nil || next.PitID != "pit-1" || fake.pits != 1 {
+		t.Errorf("next = %+v, want the following pages read from a new point in time", next)
+	}
+}
+
+func TestSearchPageRejectsTamperedCursor(t *testing.T) {
+	client, _ := newPagingTestClient(t, 5)
+
+	tests := []struct {
+		name   string
+		cursor *TraceCursor
+	}{
+		{
+			name:   "other sort",
+			cursor: &TraceCursor{SearchAfter: []interface{}{json.Number("1"), "t1", "s1"}, Sort: "durationInNanos:asc,traceId:asc,spanId:asc"},
+		},
+		{
+			name:   "missing sort values",
+			cursor: &TraceCursor{SearchAfter: []interface{}{json.Number("1")}, Sort: "startTime:asc,traceId:asc,spanId:asc"},
+		},
+		{
+			name:   "structured sort value",
+			cursor: &TraceCursor{SearchAfter: []interface{}{map[string]interface{}{"script": "x"}, "t1", "s1"}, Sort: "startTime:asc,traceId:asc,spanId:asc"},
+		},
+		{
+			name:   "sort value of another type",
+			cursor: &TraceCursor{SearchAfter: []interface{}{"yesterday", "t1", "s1"}, Sort: "startTime:asc,traceId:asc,spanId:asc"},
+		},
+		{
+			name:   "malformed point in time",
+			cursor: &TraceCursor{PitID: "malformed", SearchAfter: []interface{}{json.Number("1"), "t1", "s1"}, Sort: "startTime:asc,traceId:asc,spanId:asc"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := client.SearchPage(context.Background(), pagingTestRequest(tt.cursor))
+			if !errors.Is(err, ErrInvalidCursor) {
+				t.Errorf("SearchPage() error = %v, want ErrInvalidCursor", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,170 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package opensearch
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// CustomAttributesField is the document field holding the span attributes copied by the passthrough
+const CustomAttributesField = "custom_attributes"
+
+// Passthrough configures which raw span attributes are copied into the custom attributes of a document
+// An attribute is copied when its key starts with an allowed prefix and with none of the denied prefixes
+type Passthrough struct {
+	AllowedPrefixes []string
+	DeniedPrefixes  []string
+	MaxValueBytes   int // Values are truncated to this size, not truncated when zero or less
+	MaxAttributes   int // Attributes copied per span, unbounded when zero or less
+}
+
+var (
+	passthroughMu      sync.RWMutex
+	currentPassthrough Passthrough
+)
+
+// SetPassthrough configures the attribute passthrough applied by ProcessDocument
+// The passthrough is disabled while no prefix is allowed
+func SetPassthrough(passthrough Passthrough) {
+	passthroughMu.Lock()
+	defer passthroughMu.Unlock()
+	currentPassthrough = passthrough
+}
+
+// getPassthrough returns the configured attribute passthrough
+func getPassthrough() Passthrough {
+	passthroughMu.RLock()
+	defer passthroughMu.RUnlock()
+	return currentPassthrough
+}
+
+// allows checks whether an attribute key is copied, the denied prefixes taking precedence
+func (p Passthrough) allows(key string) bool {
+	for _, prefix := range p.DeniedPrefixes {
+		if strings.HasPrefix(key, prefix) {
+			return false
+		}
+	}
+	for _, prefix := range p.AllowedPrefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// buildCustomAttributes copies the allowed span attributes as size-capped strings
+// so that a value of a different type or size in another span cannot break the mapping
+// Keys are copied in sorted order so that the attributes kept under the attribute cap are stable
+func buildCustomAttributes(attrs map[string]interface{}, passthrough Passthrough) map[string]string {
+	if len(passthrough.AllowedPrefixes) == 0 || len(attrs) == 0 {
+		return nil
+	}
+
+	keys := make([]string, 0, len(attrs))
+	for key := range attrs {
+		if passthrough.allows(key) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	custom := map[string]string{}
+	for _, key := range keys {
+		if passthrough.MaxAttributes > 0 && len(custom) >= passthrough.MaxAttributes {
+			break
+		}
+		value, ok := customAttributeValue(attrs[key])
+		if !ok {
+			continue
+		}
+		if passthrough.MaxValueBytes > 0 {
+			value = truncateUTF8(value, passthrough.MaxValueBytes)
+		}
+		custom[key] = value
+	}
+	if len(custom) == 0 {
+		return nil
+	}
+	return custom
+}
+
+// customAttributeValue coerces an attribute value to a string, encoding arrays and objects as JSON
+func customAttributeValue(value interface{}) (string, bool) {
+	if value == nil {
+		return "", false
+	}
+	if str, ok := asString(value); ok {
+		return str, true
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return "", false
+	}
+	return string(encoded), true
+}
+
+// buildCustomAttributesMapping builds the mapping of the custom attributes field
+// A flat object keeps arbitrary keys from growing the index mapping
+func buildCustomAttributesMapping() map[string]interface{} {
+	return map[string]interface{}{"type": "flat_object"}
+}
+
+// BuildTraceIDsByCustomAttributeQuery builds an aggregation query collecting the traces with a span
+// whose custom attribute equals the given value
+func BuildTraceIDsByCustomAttributeQuery(params TraceQueryParams, key, value string) map[string]interface{} {
+	mustConditions := []map[string]interface{}{
+		{"term": map[string]interface{}{CustomAttributesField + "." + key: value}},
+	}
+	if params.ComponentUid != "" {
+		mustConditions = append(mustConditions, map[string]interface{}{
+			"term": map[string]interface{}{"resource.openchoreo.dev/component-uid": params.ComponentUid},
+		})
+	}
+	if params.EnvironmentUid != "" {
+		mustConditions = append(mustConditions, map[string]interface{}{
+			"term": map[string]interface{}{"resource.openchoreo.dev/environment-uid": params.EnvironmentUid},
+		})
+	}
+	if params.StartTime != "" && params.EndTime != "" {
+		mustConditions = append(mustConditions, map[string]interface{}{
+			"range": map[string]interface{}{
+				"startTime": map[string]interface{}{"gte": params.StartTime, "lte": params.EndTime},
+			},
+		})
+	}
+
+	return map[string]interface{}{
+		"query": map[string]interface{}{
+			"bool": map[string]interface{}{
+				"must": mustConditions,
+			},
+		},
+		"size": 0,
+		"aggs": map[string]interface{}{
+			"traces": map[string]interface{}{
+				"terms": map[string]interface{}{
+					"field": "traceId",
+					"size":  MaxTraceIDsPerLookup,
+				},
+			},
+		},
+	}
+}
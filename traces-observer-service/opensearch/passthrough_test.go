@@ -0,0 +1,160 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package opensearch
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestPassthroughAllows(t *testing.T) {
+	passthrough := Passthrough{
+		AllowedPrefixes: []string{"tenant.", "app."},
+		DeniedPrefixes:  []string{"tenant.secret", "app.internal."},
+	}
+	tests := []struct {
+		key  string
+		want bool
+	}{
+		{"tenant.id", true},
+		{"app.version", true},
+		{"tenant.secret", false},
+		{"tenant.secret_key", false},
+		{"app.internal.token", false},
+		{"app.internals", true},
+		{"user.id", false},
+		{"tenant", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			if got := passthrough.allows(tt.key); got != tt.want {
+				t.Errorf("allows(%q) = %v, want %v", tt.key, got, tt.want)
+			}
+		})
+	}
+
+	// A denied prefix wins even when it is broader than the allowed one
+	overlapping := Passthrough{AllowedPrefixes: []string{"tenant.id"}, DeniedPrefixes: []string{"tenant."}}
+	if overlapping.allows("tenant.id") {
+		t.Error("allows(tenant.id) = true, want the denied prefix to take precedence")
+	}
+}
+
+func TestBuildCustomAttributes(t *testing.T) {
+	attrs := map[string]interface{}{
+		"tenant.id":     "acme",
+		"tenant.tier":   json.Number("3"),
+		"tenant.paid":   true,
+		"tenant.ratio":  0.25,
+		"tenant.count":  int64(42),
+		"tenant.tags":   []interface{}{"a", "b"},
+		"tenant.meta":   map[string]interface{}{"region": "eu"},
+		"tenant.none":   nil,
+		"tenant.secret": "hunter2",
+		"user.id":       "u-1",
+	}
+	tests := []struct {
+		name        string
+		passthrough Passthrough
+		attrs       map[string]interface{}
+		want        map[string]string
+	}{
+		{
+			name:        "disabled without allowed prefixes",
+			passthrough: Passthrough{DeniedPrefixes: []string{"tenant.secret"}},
+			attrs:       attrs,
+			want:        nil,
+		},
+		{
+			name:        "no attributes",
+			passthrough: Passthrough{AllowedPrefixes: []string{"tenant."}},
+			attrs:       nil,
+			want:        nil,
+		},
+		{
+			name:        "denied prefixes take precedence over allowed ones",
+			passthrough: Passthrough{AllowedPrefixes: []string{"tenant.id", "tenant.secret"}, DeniedPrefixes: []string{"tenant.secret"}},
+			attrs:       attrs,
+			want:        map[string]string{"tenant.id": "acme"},
+		},
+		{
+			name:        "non-string values are coerced and nil values skipped",
+			passthrough: Passthrough{AllowedPrefixes: []string{"tenant."}, DeniedPrefixes: []string{"tenant.secret"}},
+			attrs:       attrs,
+			want: map[string]string{
+				"tenant.id":    "acme",
+				"tenant.tier":  "3",
+				"tenant.paid":  "true",
+				"tenant.ratio": "0.25",
+				"tenant.count": "42",
+				"tenant.tags":  `["a","b"]`,
+				"tenant.meta":  `{"region":"eu"}`,
+			},
+		},
+		{
+			name:        "only nil values",
+			passthrough: Passthrough{AllowedPrefixes: []string{"tenant."}},
+			attrs:       map[string]interface{}{"tenant.none": nil},
+			want:        nil,
+		},
+		{
+			name:        "values are truncated to the byte cap",
+			passthrough: Passthrough{AllowedPrefixes: []string{"tenant."}, MaxValueBytes: 3},
+			attrs:       map[string]interface{}{"tenant.id": "acme", "tenant.tier": "ok"},
+			want:        map[string]string{"tenant.id": "acm", "tenant.tier": "ok"},
+		},
+		{
+			name:        "truncation keeps whole runes",
+			passthrough: Passthrough{AllowedPrefixes: []string{"tenant."}, MaxValueBytes: 4},
+			attrs:       map[string]interface{}{"tenant.city": "Zürich"},
+			want:        map[string]string{"tenant.city": "Zür"},
+		},
+		{
+			name:        "coerced values are truncated too",
+			passthrough: Passthrough{AllowedPrefixes: []string{"tenant."}, MaxValueBytes: 4},
+			attrs:       map[string]interface{}{"tenant.tags": []interface{}{"a", "b"}},
+			want:        map[string]string{"tenant.tags": `["a"`},
+		},
+		{
+			name:        "the attribute cap keeps the first keys in sorted order",
+			passthrough: Passthrough{AllowedPrefixes: []string{"tenant."}, MaxAttributes: 2},
+			attrs:       map[string]interface{}{"tenant.c": "3", "tenant.a": "1", "tenant.b": "2"},
+			want:        map[string]string{"tenant.a": "1", "tenant.b": "2"},
+		},
+		{
+			name:        "skipped values do not count towards the attribute cap",
+			passthrough: Passthrough{AllowedPrefixes: []string{"tenant."}, MaxAttributes: 1},
+			attrs:       map[string]interface{}{"tenant.a": nil, "tenant.b": "2"},
+			want:        map[string]string{"tenant.b": "2"},
+		},
+		{
+			name:        "zero caps leave values and attributes unbounded",
+			passthrough: Passthrough{AllowedPrefixes: []string{"tenant."}},
+			attrs:       map[string]interface{}{"tenant.a": "a long value", "tenant.b": "2", "tenant.c": "3"},
+			want:        map[string]string{"tenant.a": "a long value", "tenant.b": "2", "tenant.c": "3"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := buildCustomAttributes(tt.attrs, tt.passthrough); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("buildCustomAttributes() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
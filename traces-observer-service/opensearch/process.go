@@ -21,20 +21,136 @@ import (
 	"fmt"
 	"strings"
 	"time"
+	"unicode/utf8"
 )
 
 // ParseSpans converts OpenSearch response to Span structs
 func ParseSpans(response *SearchResponse) []Span {
 	spans := make([]Span, 0, len(response.Hits.Hits))
+	seen := make(map[string]bool, len(response.Hits.Hits))
 
 	for _, hit := range response.Hits.Hits {
 		span := parseSpan(hit.Source)
+		// Copies of a re-delivered span written to more than one daily index are read once
+		id := SpanDocumentID(span.TraceID, span.SpanID)
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
 		spans = append(spans, span)
 	}
 
+	// Resolve attributes that depend on the parent span
+	AttributeCrewAIManagerAgents(spans)
+
+	return spans
+}
+
+// ParseSpanSources parses span documents read outside of a search response, such as archived documents
+func ParseSpanSources(sources []map[string]interface{}) []Span {
+	spans := make([]Span, 0, len(sources))
+	seen := make(map[string]bool, len(sources))
+	for _, source := range sources {
+		span := parseSpan(source)
+		id := SpanDocumentID(span.TraceID, span.SpanID)
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		spans = append(spans, span)
+	}
+	AttributeCrewAIManagerAgents(spans)
 	return spans
 }
 
+// SpanDocumentID returns the document ID of a span
+// The ID is derived from the trace and span IDs so that a re-delivered span overwrites its earlier copy
+func SpanDocumentID(traceID, spanID string) string {
+	return traceID + "-" + spanID
+}
+
+// SpanFramework returns the agent framework of a span from the registered framework processors or gen_ai.system
+func SpanFramework(span Span) string {
+	if name, _, ok := frameworks.Detect(span.Attributes); ok {
+		return name
+	}
+	if framework, ok := asString(span.Attributes["gen_ai.system"]); ok {
+		return strings.ToLower(framework)
+	}
+	return ""
+}
+
+// ProcessDocument runs the processing pipeline on a span document before it is indexed
+// Redaction and truncation are applied to the raw attributes in place and recorded on the document,
+// tool and guardrail spans get a queryable summary and streamed LLM spans their streaming metrics.
+// Inline images, audio and other media are replaced by placeholders before anything is extracted.
+// The ingestion time is recorded so that readers can tell whether spans of the trace may still arrive.
+// Span attributes allowed by the passthrough are copied into a searchable custom attributes object.
+// Root spans get a provisional rollup of their trace.
+func ProcessDocument(source map[string]interface{}) Span {
+	// Media payloads are replaced first so that only this pass hands them to the media store
+	multimodal := replaceMultimodal(source, getMediaStore()) > 0
+	span := parseSpan(source)
+	span.IngestedAt = time.Now().UTC()
+	source[IngestedAtField] = span.IngestedAt.Format(time.RFC3339Nano)
+	if multimodal {
+		span.HasMultimodal = true
+	}
+
+	if span.Truncated {
+		source["truncated"] = true
+		source["originalSizes"] = span.OriginalSizes
+	}
+	if span.Redactions > 0 {
+		source["redactions"] = span.Redactions
+	}
+	for field, value := range derivedFields(span) {
+		source[field] = value
+	}
+	if span.HasMultimodal {
+		source[MultimodalField] = true
+	}
+	// A root span is indexed with the rollup of itself until the rest of its trace is rolled up
+	if span.ParentSpanID == "" {
+		source[TraceRollupField] = BuildTraceRollup([]Span{span})
+	}
+	return span
+}
+
+// DerivedFieldNames lists the queryable summaries ProcessDocument derives from the attributes of a span
+var DerivedFieldNames = []string{ToolInvocationField, GuardrailField, StreamingField, CustomAttributesField}
+
+// derivedFields returns the queryable summaries of a parsed span, summaries that do not apply are left out
+func derivedFields(span Span) map[string]interface{} {
+	fields := map[string]interface{}{}
+	if invocation := buildToolInvocation(span); invocation != nil {
+		fields[ToolInvocationField] = invocation
+	}
+	if guardrailData, ok := guardrailDataOf(span); ok {
+		fields[GuardrailField] = guardrailData
+	}
+	if llmData, ok := llmDataOf(span); ok && llmData.Streaming != nil {
+		fields[StreamingField] = llmData.Streaming
+	}
+	if custom := buildCustomAttributes(span.Attributes, getPassthrough()); custom != nil {
+		fields[CustomAttributesField] = custom
+	}
+	return fields
+}
+
+// DeriveFields derives the queryable summaries of an indexed span document again with the current framework
+// processors, redaction, limits and passthrough, the raw attributes of the document are left as they were indexed
+// Every name of DerivedFieldNames is returned, nil for summaries that no longer apply
+func DeriveFields(source map[string]interface{}) map[string]interface{} {
+	fields := derivedFields(parseSpan(source))
+	for _, field := range DerivedFieldNames {
+		if _, ok := fields[field]; !ok {
+			fields[field] = nil
+		}
+	}
+	return fields
+}
+
 // parseSpan extracts span information from a source document
 func parseSpan(source map[string]interface{}) Span {
 	span := Span{}
@@ -55,6 +171,9 @@ func parseSpan(source map[string]interface{}) Span {
 	if kind, ok := source["kind"].(string); ok {
 		span.Kind = kind
 	}
+	if orgID, ok := source[OrgIDField].(string); ok {
+		span.OrgID = orgID
+	}
 
 	// Extract component UID from resource
 	if resource, ok := source["resource"].(map[string]interface{}); ok {
@@ -79,7 +198,7 @@ func parseSpan(source map[string]interface{}) Span {
 	}
 
 	// Parse duration - try durationInNanos field first
-	if duration, ok := source["durationInNanos"].(float64); ok {
+	if duration, ok := asFloat(source["durationInNanos"]); ok {
 		span.DurationInNanos = int64(duration)
 	} else if !span.StartTime.IsZero() && !span.EndTime.IsZero() {
 		// Fallback: calculate duration from timestamps if durationInNanos not present
@@ -90,16 +209,26 @@ func parseSpan(source map[string]interface{}) Span {
 	if status, ok := source["status"].(map[string]interface{}); ok {
 		if code, ok := status["code"].(string); ok {
 			span.Status = code
-		} else if code, ok := status["code"].(float64); ok {
+		} else if code, ok := asFloat(status["code"]); ok {
 			span.Status = fmt.Sprintf("%d", int(code))
 		}
 	}
 
+	// Replace media payloads of documents indexed before they were replaced at ingestion
+	if replaceMultimodal(source, nil) > 0 {
+		span.HasMultimodal = true
+	}
+
 	// Parse attributes
 	if attributes, ok := source["attributes"].(map[string]interface{}); ok {
 		span.Attributes = attributes
 	}
 
+	// Extract the session/conversation ID
+	span.SessionID = ExtractSessionID(span.Attributes)
+
+	span.Events = parseSpanEvents(source)
+
 	// Determine and add the semantic span type to AmpAttributes
 	spanType := DetermineSpanType(span)
 
@@ -107,39 +236,86 @@ func parseSpan(source map[string]interface{}) Span {
 		Kind: string(spanType),
 	}
 
-	// Populate span-type-specific attributes
+	// Populate span-type-specific attributes, delegating framework spans to their processor
 	if span.Attributes != nil {
-		switch spanType {
-		case SpanTypeLLM:
-			populateLLMAttributes(ampAttrs, span.Attributes)
-		case SpanTypeTool:
-			populateToolAttributes(ampAttrs, span.Attributes, span.Status)
-		case SpanTypeEmbedding:
-			populateEmbeddingAttributes(ampAttrs, span.Attributes)
-		case SpanTypeRetriever:
-			populateRetrieverAttributes(ampAttrs, span.Attributes)
-		case SpanTypeAgent:
-			// Check if this is a CrewAI workflow span and delegate to CrewAI processor
-			if IsCrewAISpan(span.Attributes) {
-				PopulateCrewAIAgentAttributes(ampAttrs, span.Attributes)
-			} else {
-				populateAgentAttributes(ampAttrs, span.Attributes)
-			}
-		case SpanTypeCrewAITask:
-			populateCrewAITaskAttributes(ampAttrs, span.Attributes)
-		case SpanTypeChain:
-			populateChainAttributes(ampAttrs, span.Attributes)
+		if _, processor, ok := frameworks.Detect(span.Attributes); ok {
+			processor.Populate(ampAttrs, &span)
+		} else {
+			PopulateStandardAttributes(ampAttrs, &span)
 		}
-
 	}
 
 	// Extract error status for all span types
 	ampAttrs.Status = extractSpanStatus(span.Attributes, span.Status)
+
+	// Extract error details from the status message and exception events
+	ampAttrs.Error = extractErrorData(source, ampAttrs.Status)
+	if ampAttrs.Error != nil && !ampAttrs.Status.Error {
+		// A recorded exception marks the span as failed even without an error status
+		ampAttrs.Status.Error = true
+	}
+	if ampAttrs.Error != nil && ampAttrs.Status.ErrorType == "" {
+		ampAttrs.Status.ErrorType = ampAttrs.Error.Type
+	}
 	span.AmpAttributes = ampAttrs
 
+	// Keep the processing results recorded when the document was indexed
+	restoreProcessingFlags(&span, source)
+	span.Rollup = parseTraceRollup(source)
+
+	// Redact sensitive data before truncation so that partial matches are not left behind
+	applyRedaction(&span, getRedactor())
+
+	// Truncate oversized values
+	applyLimits(&span, getLimits())
+
 	return span
 }
 
+// PopulateStandardAttributes populates the span-type-specific attributes of a span
+// from the OpenTelemetry GenAI and Traceloop semantic conventions
+func PopulateStandardAttributes(ampAttrs *AmpAttributes, span *Span) {
+	switch SpanType(ampAttrs.Kind) {
+	case SpanTypeLLM:
+		populateLLMAttributes(ampAttrs, span.Attributes)
+		applyEventMessages(ampAttrs, span.Events)
+		if llmData, ok := ampAttrs.Data.(LLMData); ok {
+			llmData.Streaming = buildStreamingMetrics(*span, llmData.TokenUsage)
+			ampAttrs.Data = llmData
+		}
+	case SpanTypeTool:
+		populateToolAttributes(ampAttrs, span.Attributes, span.Status)
+	case SpanTypeEmbedding:
+		populateEmbeddingAttributes(ampAttrs, span.Attributes)
+	case SpanTypeRetriever:
+		populateRetrieverAttributes(ampAttrs, span.Attributes)
+	case SpanTypeAgent:
+		populateAgentAttributes(ampAttrs, span.Attributes)
+	case SpanTypeChain:
+		populateChainAttributes(ampAttrs, span.Attributes)
+	case SpanTypeGuardrail:
+		populateGuardrailAttributes(ampAttrs, span.Attributes, span.Name)
+	}
+}
+
+// SessionIDAttributes lists the attributes carrying the session ID in priority order
+// Different SDKs use different keys for the same concept
+var SessionIDAttributes = []string{
+	"session.id",
+	"gen_ai.conversation.id",
+	"thread.id",
+}
+
+// ExtractSessionID extracts the session/conversation ID from span attributes
+func ExtractSessionID(attrs map[string]interface{}) string {
+	for _, key := range SessionIDAttributes {
+		if sessionID, ok := asString(attrs[key]); ok && sessionID != "" {
+			return sessionID
+		}
+	}
+	return ""
+}
+
 // populateLLMAttributes extracts and populates LLM-specific attributes
 func populateLLMAttributes(ampAttrs *AmpAttributes, attrs map[string]interface{}) {
 	// Set common Input/Output fields
@@ -152,19 +328,19 @@ func populateLLMAttributes(ampAttrs *AmpAttributes, attrs map[string]interface{}
 	}
 
 	// Extract model information
-	if responseModel, ok := attrs["gen_ai.response.model"].(string); ok {
+	if responseModel, ok := asString(attrs["gen_ai.response.model"]); ok {
 		llmData.Model = responseModel
-	} else if requestModel, ok := attrs["gen_ai.request.model"].(string); ok {
+	} else if requestModel, ok := asString(attrs["gen_ai.request.model"]); ok {
 		llmData.Model = requestModel
 	}
 
 	// Extract vendor (gen_ai.system)
-	if vendor, ok := attrs["gen_ai.system"].(string); ok {
+	if vendor, ok := asString(attrs["gen_ai.system"]); ok {
 		llmData.Vendor = vendor
 	}
 
 	// Extract temperature
-	if temp, ok := attrs["gen_ai.request.temperature"].(float64); ok {
+	if temp, ok := asFloat(attrs["gen_ai.request.temperature"]); ok {
 		llmData.Temperature = &temp
 	}
 
@@ -199,14 +375,14 @@ func populateEmbeddingAttributes(ampAttrs *AmpAttributes, attrs map[string]inter
 	embeddingData := EmbeddingData{}
 
 	// Extract model information
-	if responseModel, ok := attrs["gen_ai.response.model"].(string); ok {
+	if responseModel, ok := asString(attrs["gen_ai.response.model"]); ok {
 		embeddingData.Model = responseModel
-	} else if requestModel, ok := attrs["gen_ai.request.model"].(string); ok {
+	} else if requestModel, ok := asString(attrs["gen_ai.request.model"]); ok {
 		embeddingData.Model = requestModel
 	}
 
 	// Extract vendor (gen_ai.system)
-	if vendor, ok := attrs["gen_ai.system"].(string); ok {
+	if vendor, ok := asString(attrs["gen_ai.system"]); ok {
 		embeddingData.Vendor = vendor
 	}
 
@@ -221,13 +397,13 @@ func populateRetrieverAttributes(ampAttrs *AmpAttributes, attrs map[string]inter
 	retrieverData := RetrieverData{}
 
 	// Extract vector DB system
-	if dbSystem, ok := attrs["db.system"].(string); ok {
+	if dbSystem, ok := asString(attrs["db.system"]); ok {
 		retrieverData.VectorDB = dbSystem
 	}
 
 	// Extract top_k parameter
-	if topK, ok := attrs["db.vector.query.top_k"].(float64); ok {
-		retrieverData.TopK = int(topK)
+	if topK, ok := asInt(attrs["db.vector.query.top_k"]); ok {
+		retrieverData.TopK = topK
 	}
 
 	ampAttrs.Data = retrieverData
@@ -236,10 +412,10 @@ func populateRetrieverAttributes(ampAttrs *AmpAttributes, attrs map[string]inter
 // populateAgentAttributes extracts and populates agent-specific attributes
 func populateAgentAttributes(ampAttrs *AmpAttributes, attrs map[string]interface{}) {
 	// For standard agent spans, use traceloop.entity attributes
-	if input, ok := attrs["traceloop.entity.input"].(string); ok {
+	if input, ok := asString(attrs["traceloop.entity.input"]); ok {
 		ampAttrs.Input = input
 	}
-	if output, ok := attrs["traceloop.entity.output"].(string); ok {
+	if output, ok := asString(attrs["traceloop.entity.output"]); ok {
 		ampAttrs.Output = output
 	}
 
@@ -247,7 +423,7 @@ func populateAgentAttributes(ampAttrs *AmpAttributes, attrs map[string]interface
 	agentData := AgentData{}
 
 	// Extract agent name from gen_ai.agent.name
-	if name, ok := attrs["gen_ai.agent.name"].(string); ok {
+	if name, ok := asString(attrs["gen_ai.agent.name"]); ok {
 		agentData.Name = name
 	}
 
@@ -255,12 +431,12 @@ func populateAgentAttributes(ampAttrs *AmpAttributes, attrs map[string]interface
 	agentData.Tools = extractAgentTools(attrs)
 
 	// Extract model from gen_ai.request.model
-	if model, ok := attrs["gen_ai.request.model"].(string); ok {
+	if model, ok := asString(attrs["gen_ai.request.model"]); ok {
 		agentData.Model = model
 	}
 
 	// Extract framework from gen_ai.system
-	if framework, ok := attrs["gen_ai.system"].(string); ok {
+	if framework, ok := asString(attrs["gen_ai.system"]); ok {
 		agentData.Framework = framework
 	}
 
@@ -275,13 +451,6 @@ func populateAgentAttributes(ampAttrs *AmpAttributes, attrs map[string]interface
 
 // populateChainAttributes extracts and populates chain/task/workflow-specific attributes
 func populateChainAttributes(ampAttrs *AmpAttributes, attrs map[string]interface{}) {
-	// Check if this is a CrewAI chain/task span and delegate to CrewAI processor
-	if IsCrewAISpan(attrs) {
-		// Extract input and output using CrewAI extraction
-		ampAttrs.Input, ampAttrs.Output = ExtractCrewAISpanInputOutput(attrs)
-		return
-	}
-
 	// For standard chain/task spans, extract from traceloop.entity attributes
 	ampAttrs.Input, ampAttrs.Output = extractSpanInputOutput(attrs)
 }
@@ -292,7 +461,7 @@ func populateCrewAITaskAttributes(ampAttrs *AmpAttributes, attrs map[string]inte
 	ampAttrs.Input = nil
 
 	// Extract output from traceloop.entity.output
-	if output, ok := attrs["traceloop.entity.output"].(string); ok {
+	if output, ok := asString(attrs["traceloop.entity.output"]); ok {
 		ampAttrs.Output = output
 	}
 
@@ -300,17 +469,17 @@ func populateCrewAITaskAttributes(ampAttrs *AmpAttributes, attrs map[string]inte
 	taskData := CrewAITaskData{}
 
 	// Extract task name from crewai.task.name
-	if name, ok := attrs["crewai.task.name"].(string); ok {
+	if name, ok := asString(attrs["crewai.task.name"]); ok {
 		taskData.Name = name
 	}
 
 	// Extract task description from crewai.task.description
-	if description, ok := attrs["crewai.task.description"].(string); ok {
+	if description, ok := asString(attrs["crewai.task.description"]); ok {
 		taskData.Description = description
 	}
 
 	// Extract task tools from crewai.task.tools
-	if toolsJSON, ok := attrs["crewai.task.tools"].(string); ok && toolsJSON != "" {
+	if toolsJSON, ok := asString(attrs["crewai.task.tools"]); ok && toolsJSON != "" {
 		taskData.Tools = parseToolsJSON(toolsJSON)
 	}
 
@@ -321,32 +490,36 @@ func populateCrewAITaskAttributes(ampAttrs *AmpAttributes, attrs map[string]inte
 // Supports two formats:
 // 1. Array of strings: ["tool1", "tool2"] -> creates ToolDefinition with just name
 // 2. Array of tool objects: [{"name": "tool1", "description": "...", "parameters": "..."}]
+// Tool objects may also be OpenAI function tools, LangChain tools or CrewAI tools, see toolDefinitionFromJSON
+// The array is decoded one tool at a time from at most the parse limit, so an oversized array
+// still yields the tools before the limit
 // Returns array of ToolDefinition objects
 func parseToolsJSON(toolsJSON string) []ToolDefinition {
 	if toolsJSON == "" {
 		return nil
 	}
 
-	// First, try to parse as array of ToolDefinition objects
-	var toolDefs []ToolDefinition
-	if err := json.Unmarshal([]byte(toolsJSON), &toolDefs); err == nil && len(toolDefs) > 0 {
-		// Successfully parsed as ToolDefinition array
-		return toolDefs
-	}
-
-	// If that fails, try to parse as array of strings
-	var toolNames []string
-	if err := json.Unmarshal([]byte(toolsJSON), &toolNames); err == nil {
-		// Successfully parsed as string array, convert to ToolDefinition
-		result := make([]ToolDefinition, len(toolNames))
-		for i, name := range toolNames {
-			result[i] = ToolDefinition{Name: name}
+	var tools []ToolDefinition
+	complete := decodeJSONArray(toolsJSON, func(element json.RawMessage) {
+		var name string
+		if json.Unmarshal(element, &name) == nil {
+			tools = append(tools, ToolDefinition{Name: name})
+			return
 		}
-		return result
+		if tool := toolDefinitionFromJSON(element); tool.Name != "" {
+			tools = append(tools, tool)
+		}
+	})
+	if complete && tools == nil {
+		return []ToolDefinition{}
+	}
+	if tools != nil {
+		return tools
 	}
 
-	// If both fail, return the raw string as a single ToolDefinition
-	return []ToolDefinition{{Name: toolsJSON}}
+	// If the value is not a JSON array, return the raw string as a single ToolDefinition
+	name, _ := truncateValue(toolsJSON, getLimits().MaxParseBytes)
+	return []ToolDefinition{{Name: name}}
 }
 
 // extractAgentTools extracts tool definitions from gen_ai.agent.tools attribute
@@ -355,7 +528,7 @@ func parseToolsJSON(toolsJSON string) []ToolDefinition {
 // - JSON array of tool objects: [{"name": "tool1", "description": "...", "parameters": "..."}]
 // Returns array of ToolDefinition objects
 func extractAgentTools(attrs map[string]interface{}) []ToolDefinition {
-	toolsJSON, ok := attrs["gen_ai.agent.tools"].(string)
+	toolsJSON, ok := asString(attrs["gen_ai.agent.tools"])
 	if !ok || toolsJSON == "" {
 		return nil
 	}
@@ -369,10 +542,10 @@ func extractAgentSystemPrompt(attrs map[string]interface{}) string {
 
 	// First check gen_ai.system_instructions (OTEL format)
 	// Can be a JSON array of instruction parts
-	if systemInstructions, ok := attrs["gen_ai.system_instructions"].(string); ok && systemInstructions != "" {
+	if systemInstructions, ok := asString(attrs["gen_ai.system_instructions"]); ok && systemInstructions != "" {
 		// Try to parse as JSON array first
 		var instructions []map[string]interface{}
-		if err := json.Unmarshal([]byte(systemInstructions), &instructions); err == nil {
+		if err := unmarshalAttribute(systemInstructions, &instructions); err == nil {
 			// Extract text content from parts
 			var parts []string
 			for _, instruction := range instructions {
@@ -391,14 +564,14 @@ func extractAgentSystemPrompt(attrs map[string]interface{}) string {
 	}
 
 	// Check gen_ai.prompt.0.content with role=system (Traceloop format)
-	if role, ok := attrs["gen_ai.prompt.0.role"].(string); ok && role == "system" {
-		if content, ok := attrs["gen_ai.prompt.0.content"].(string); ok {
+	if role, ok := asString(attrs["gen_ai.prompt.0.role"]); ok && role == "system" {
+		if content, ok := asString(attrs["gen_ai.prompt.0.content"]); ok {
 			return content
 		}
 	}
 
 	// Check for a dedicated system_prompt attribute if it exists
-	if systemPrompt, ok := attrs["system_prompt"].(string); ok {
+	if systemPrompt, ok := asString(attrs["system_prompt"]); ok {
 		return systemPrompt
 	}
 
@@ -412,21 +585,37 @@ func extractSpanStatus(attrs map[string]interface{}, spanStatus string) *SpanSta
 	}
 
 	if attrs != nil {
-		if errorType, ok := attrs["error.type"].(string); ok {
+		if errorType, ok := asString(attrs["error.type"]); ok {
 			status.Error = true
 			status.ErrorType = errorType
 			return status
 		}
 
-		if toolStatus, ok := attrs["gen_ai.tool.status"].(string); ok && isErrorStatus(toolStatus) {
+		// CrewAI tool invocations record the failure reason in crewai.tool.error
+		switch toolError := attrs["crewai.tool.error"].(type) {
+		case string:
+			if toolError != "" {
+				status.Error = true
+				status.ErrorType = "ToolExecutionError"
+				return status
+			}
+		case bool:
+			if toolError {
+				status.Error = true
+				status.ErrorType = "ToolExecutionError"
+				return status
+			}
+		}
+
+		if toolStatus, ok := asString(attrs["gen_ai.tool.status"]); ok && isErrorStatus(toolStatus) {
 			status.Error = true
 			status.ErrorType = "ToolExecutionError"
 			return status
 		}
 
-		if httpStatus, ok := attrs["http.status_code"].(float64); ok && int(httpStatus) >= 400 {
+		if httpStatus, ok := asInt(attrs["http.status_code"]); ok && httpStatus >= 400 {
 			status.Error = true
-			status.ErrorType = fmt.Sprintf("%d", int(httpStatus))
+			status.ErrorType = fmt.Sprintf("%d", httpStatus)
 			return status
 		}
 	}
@@ -439,41 +628,283 @@ func extractSpanStatus(attrs map[string]interface{}, spanStatus string) *SpanSta
 	return status
 }
 
+// MaxStacktraceLength is the maximum length in bytes of an exception stacktrace kept on a span
+const MaxStacktraceLength = 4096
+
+// extractErrorData extracts error details from the span status message and the exception events
+// The last exception event wins since it is usually the one that ended the span
+func extractErrorData(source map[string]interface{}, status *SpanStatus) *ErrorData {
+	errorData := &ErrorData{}
+
+	// Extract exception details from span events
+	if events, ok := source["events"].([]interface{}); ok {
+		for _, event := range events {
+			eventMap, ok := event.(map[string]interface{})
+			if !ok || eventMap["name"] != "exception" {
+				continue
+			}
+			eventAttrs, ok := eventMap["attributes"].(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if excType, ok := eventAttrs["exception.type"].(string); ok {
+				errorData.Type = excType
+			}
+			if excMessage, ok := eventAttrs["exception.message"].(string); ok {
+				errorData.Message = excMessage
+			}
+			if stacktrace, ok := eventAttrs["exception.stacktrace"].(string); ok {
+				errorData.Stacktrace = truncateUTF8(stacktrace, MaxStacktraceLength)
+			}
+		}
+	}
+
+	// Fall back to the span status message for failed spans
+	if errorData.Message == "" && status != nil && status.Error {
+		if spanStatus, ok := source["status"].(map[string]interface{}); ok {
+			if message, ok := spanStatus["message"].(string); ok {
+				errorData.Message = message
+			}
+		}
+	}
+
+	if errorData.Type == "" && errorData.Message == "" && errorData.Stacktrace == "" {
+		return nil
+	}
+	return errorData
+}
+
+// restoreProcessingFlags restores the truncation and redaction records stored on an indexed document
+func restoreProcessingFlags(span *Span, source map[string]interface{}) {
+	if truncated, ok := source["truncated"].(bool); ok && truncated {
+		span.Truncated = true
+		if sizes, ok := source["originalSizes"].(map[string]interface{}); ok {
+			span.OriginalSizes = make(map[string]int, len(sizes))
+			for field, size := range sizes {
+				if value, ok := size.(float64); ok {
+					span.OriginalSizes[field] = int(value)
+				}
+			}
+		}
+	}
+	if redactions, ok := source["redactions"].(float64); ok {
+		span.Redactions = int(redactions)
+	}
+	if multimodal, ok := source[MultimodalField].(bool); ok && multimodal {
+		span.HasMultimodal = true
+	}
+	if ingestedAt, ok := source[IngestedAtField].(string); ok {
+		if t, err := time.Parse(time.RFC3339Nano, ingestedAt); err == nil {
+			span.IngestedAt = t
+		}
+	}
+}
+
+// truncateUTF8 truncates a string to at most maxBytes bytes without splitting a multibyte rune
+func truncateUTF8(value string, maxBytes int) string {
+	if len(value) <= maxBytes {
+		return value
+	}
+	cut := maxBytes
+	for cut > 0 && !utf8.RuneStart(value[cut]) {
+		cut--
+	}
+	return value[:cut]
+}
+
 // extractTokenUsageFromAttributes extracts token usage from span attributes
-// Supports both standard gen_ai.usage.* and legacy prompt_tokens/completion_tokens attributes
+// The counts come from the first usage report of the span, see readTokenCounts. Reports of the standard
+// attributes fall back to the other known cache token attributes.
 func extractTokenUsageFromAttributes(attrs map[string]interface{}) *LLMTokenUsage {
-	var inputTokens, outputTokens, cacheReadTokens int
+	counts, standard, ok := readTokenCounts(attrs)
+	if !ok {
+		return nil
+	}
 
-	// Try to extract input tokens (gen_ai.usage.input_tokens or gen_ai.usage.prompt_tokens)
-	if val, ok := attrs["gen_ai.usage.input_tokens"].(float64); ok {
-		inputTokens = int(val)
-	} else if val, ok := attrs["gen_ai.usage.prompt_tokens"].(float64); ok {
-		inputTokens = int(val)
+	if standard {
+		if val, ok := firstFloatAttribute(attrs, cacheReadTokenAttributes); ok {
+			counts.cacheRead = int(val)
+		}
+		if val, ok := firstFloatAttribute(attrs, cacheWriteTokenAttributes); ok {
+			counts.cacheWrite = int(val)
+		}
 	}
 
-	// Try to extract output tokens (gen_ai.usage.output_tokens or gen_ai.usage.completion_tokens)
-	if val, ok := attrs["gen_ai.usage.output_tokens"].(float64); ok {
-		outputTokens = int(val)
-	} else if val, ok := attrs["gen_ai.usage.completion_tokens"].(float64); ok {
-		outputTokens = int(val)
+	return &LLMTokenUsage{
+		InputTokens:           counts.input,
+		OutputTokens:          counts.output,
+		CacheReadInputTokens:  counts.cacheRead,
+		CacheWriteInputTokens: counts.cacheWrite,
+		ReasoningTokens:       extractReasoningTokens(attrs),
+		TotalTokens:           counts.input + counts.output,
 	}
+}
+
+// tokenCounts are the token counts of a usage report, input tokens include the cached ones
+type tokenCounts struct {
+	input      int
+	output     int
+	cacheRead  int
+	cacheWrite int
+}
+
+// usageKeys names the token counts of a usage report, each in priority order
+type usageKeys struct {
+	input      []string
+	output     []string
+	cacheRead  []string
+	cacheWrite []string
+	// The input count leaves out cached tokens, as the Anthropic and Bedrock APIs report it
+	cacheExcluded bool
+}
 
-	// Try to extract cache read tokens
-	if val, ok := attrs["gen_ai.usage.cache_read_input_tokens"].(float64); ok {
-		cacheReadTokens = int(val)
+var (
+	// The gen_ai.usage.* semantic conventions and the legacy prompt and completion token names
+	standardUsageKeys = usageKeys{
+		input:  []string{"input_tokens", "prompt_tokens"},
+		output: []string{"output_tokens", "completion_tokens"},
+	}
+	// The usage of the Anthropic Messages API
+	anthropicUsageKeys = usageKeys{
+		input:         []string{"input_tokens"},
+		output:        []string{"output_tokens"},
+		cacheRead:     []string{"cache_read_input_tokens"},
+		cacheWrite:    []string{"cache_creation_input_tokens"},
+		cacheExcluded: true,
+	}
+	// The camelCase usage of the Bedrock Converse API
+	converseUsageKeys = usageKeys{
+		input:         []string{"inputTokens"},
+		output:        []string{"outputTokens"},
+		cacheRead:     []string{"cacheReadInputTokens"},
+		cacheWrite:    []string{"cacheWriteInputTokens"},
+		cacheExcluded: true,
+	}
+	// The usage of the OpenAI Chat Completions API
+	openAIUsageKeys = usageKeys{
+		input:  []string{"prompt_tokens"},
+		output: []string{"completion_tokens"},
 	}
+)
 
-	// Only return token usage if we found some tokens
-	if inputTokens > 0 || outputTokens > 0 {
-		return &LLMTokenUsage{
-			InputTokens:          inputTokens,
-			OutputTokens:         outputTokens,
-			CacheReadInputTokens: cacheReadTokens,
-			TotalTokens:          inputTokens + outputTokens,
+// read returns the token counts a usage report holds, false when it reports no input or output tokens
+func (k usageKeys) read(lookup func(key string) interface{}) (tokenCounts, bool) {
+	first := func(keys []string) int {
+		for _, key := range keys {
+			if val, ok := asInt(lookup(key)); ok {
+				return val
+			}
 		}
+		return 0
+	}
+	counts := tokenCounts{
+		input:      first(k.input),
+		output:     first(k.output),
+		cacheRead:  first(k.cacheRead),
+		cacheWrite: first(k.cacheWrite),
+	}
+	if counts.input <= 0 && counts.output <= 0 {
+		return tokenCounts{}, false
 	}
+	if k.cacheExcluded {
+		counts.input += counts.cacheRead + counts.cacheWrite
+	}
+	return counts, true
+}
 
-	return nil
+// readTokenCounts returns the counts of the first usage report of a span, in priority order:
+//  1. the flattened gen_ai.usage.* attributes of the semantic conventions
+//  2. their camelCase form of Bedrock Converse, e.g. gen_ai.usage.inputTokens
+//  3. the flattened anthropic.usage.* attributes of Anthropic SDK instrumentations
+//  4. the usage object in gen_ai.usage, then in anthropic.usage, as an object or a JSON string, read as the
+//     Anthropic, Bedrock Converse or OpenAI usage by the keys it has
+//
+// Counts of several reports are never mixed, instrumentations reporting the same call twice are not added up.
+// The second result reports whether the counts come from the standard attributes.
+func readTokenCounts(attrs map[string]interface{}) (tokenCounts, bool, bool) {
+	flattened := func(prefix string) func(string) interface{} {
+		return func(key string) interface{} { return attrs[prefix+key] }
+	}
+	if counts, ok := standardUsageKeys.read(flattened("gen_ai.usage.")); ok {
+		return counts, true, true
+	}
+	if counts, ok := converseUsageKeys.read(flattened("gen_ai.usage.")); ok {
+		return counts, false, true
+	}
+	if counts, ok := anthropicUsageKeys.read(flattened("anthropic.usage.")); ok {
+		return counts, false, true
+	}
+
+	for _, key := range []string{"gen_ai.usage", "anthropic.usage"} {
+		usage := decodeJSONObject(attrs[key])
+		if usage == nil {
+			continue
+		}
+		nested := func(key string) interface{} { return usage[key] }
+		for _, keys := range []usageKeys{anthropicUsageKeys, converseUsageKeys, openAIUsageKeys} {
+			if counts, ok := keys.read(nested); ok {
+				return counts, false, true
+			}
+		}
+	}
+	return tokenCounts{}, false, false
+}
+
+// cacheReadTokenAttributes lists the attributes carrying cache-read input tokens in priority order
+var cacheReadTokenAttributes = []string{
+	"gen_ai.usage.cache_read_input_tokens",
+	"gen_ai.usage.cache_read_tokens",
+	"llm.usage.cache_read_input_tokens",
+	"llm.token_count.prompt_details.cache_read",
+}
+
+// cacheWriteTokenAttributes lists the attributes carrying cache-write input tokens in priority order
+var cacheWriteTokenAttributes = []string{
+	"gen_ai.usage.cache_creation_input_tokens",
+	"gen_ai.usage.cache_write_tokens",
+	"llm.usage.cache_creation_input_tokens",
+	"llm.token_count.prompt_details.cache_write",
+}
+
+// firstFloatAttribute returns the first numeric attribute found among the given keys
+func firstFloatAttribute(attrs map[string]interface{}, keys []string) (float64, bool) {
+	for _, key := range keys {
+		if val, ok := asFloat(attrs[key]); ok {
+			return val, true
+		}
+	}
+	return 0, false
+}
+
+// extractReasoningTokens extracts reasoning/thinking token counts (o1/o3, Claude extended thinking)
+// Checks the flat gen_ai.usage.reasoning_tokens attribute first, then the completion token details
+// which are emitted either flattened or as a nested JSON object
+func extractReasoningTokens(attrs map[string]interface{}) int {
+	if val, ok := asInt(attrs["gen_ai.usage.reasoning_tokens"]); ok {
+		return val
+	}
+	if val, ok := asInt(attrs["gen_ai.usage.completion_tokens_details.reasoning_tokens"]); ok {
+		return val
+	}
+
+	for _, key := range []string{"gen_ai.usage.completion_tokens_details", "llm.usage.completion_tokens_details"} {
+		var details map[string]interface{}
+		switch val := attrs[key].(type) {
+		case string:
+			if err := unmarshalAttribute(val, &details); err != nil {
+				continue
+			}
+		case map[string]interface{}:
+			details = val
+		default:
+			continue
+		}
+		if reasoning, ok := asInt(details["reasoning_tokens"]); ok {
+			return reasoning
+		}
+	}
+
+	return 0
 }
 
 // ExtractTokenUsage aggregates token usage from GenAI spans in a trace
@@ -505,18 +936,26 @@ func ExtractTokenUsage(spans []Span) *TokenUsage {
 
 // ExtractTraceStatus analyzes spans to determine trace status and error information
 func ExtractTraceStatus(spans []Span) *TraceStatus {
-	var errorCount int
+	var errorCount, toolErrorCount int
 
 	for _, span := range spans {
-		// Use extractSpanStatus to check for errors
+		// Prefer the status computed during parsing, which includes exception events
 		spanStatus := extractSpanStatus(span.Attributes, span.Status)
+		if span.AmpAttributes != nil && span.AmpAttributes.Status != nil {
+			spanStatus = span.AmpAttributes.Status
+		}
 		if spanStatus.Error {
 			errorCount++
+			if span.AmpAttributes != nil && span.AmpAttributes.Kind == string(SpanTypeTool) {
+				toolErrorCount++
+			}
 		}
 	}
 
 	return &TraceStatus{
-		ErrorCount: errorCount,
+		ErrorCount:     errorCount,
+		ToolErrorCount: toolErrorCount,
+		HasError:       errorCount > 0,
 	}
 }
 
@@ -548,7 +987,7 @@ func extractSpanInputOutput(attrs map[string]interface{}) (input interface{}, ou
 		if inputStr, ok := inputVal.(string); ok {
 			// Try to parse as JSON
 			var inputMap map[string]interface{}
-			if err := json.Unmarshal([]byte(inputStr), &inputMap); err == nil {
+			if err := unmarshalAttribute(inputStr, &inputMap); err == nil {
 				// Check if metadata exists
 				metadata, hasMetadata := inputMap["metadata"]
 				nestedInputs, hasInputs := inputMap["inputs"]
@@ -588,7 +1027,7 @@ func extractSpanInputOutput(attrs map[string]interface{}) (input interface{}, ou
 		if outputStr, ok := outputVal.(string); ok {
 			// Try to parse as JSON
 			var outputMap map[string]interface{}
-			if err := json.Unmarshal([]byte(outputStr), &outputMap); err == nil {
+			if err := unmarshalAttribute(outputStr, &outputMap); err == nil {
 				// Navigate to outputs field
 				if outputs, ok := outputMap["outputs"]; ok {
 					// Try to navigate to messages[-1] -> kwargs -> content
@@ -660,7 +1099,7 @@ func ExtractRootSpanInputOutput(rootSpan *Span) (input interface{}, output inter
 // 2. Traceloop format: gen_ai.prompt.{index}.{field}
 func ExtractPromptMessages(attrs map[string]interface{}) []PromptMessage {
 	// First, try OTEL format (gen_ai.input.messages)
-	if messagesJSON, ok := attrs["gen_ai.input.messages"].(string); ok && messagesJSON != "" {
+	if messagesJSON, ok := asString(attrs["gen_ai.input.messages"]); ok && messagesJSON != "" {
 		messages := parseOTELMessages(messagesJSON)
 		if len(messages) > 0 {
 			return messages
@@ -680,7 +1119,7 @@ func parseOTELMessages(messagesJSON string) []PromptMessage {
 
 	// Parse JSON array
 	var rawMessages []map[string]interface{}
-	if err := json.Unmarshal([]byte(messagesJSON), &rawMessages); err != nil {
+	if err := unmarshalAttribute(messagesJSON, &rawMessages); err != nil {
 		return nil
 	}
 
@@ -738,6 +1177,12 @@ func parseOTELMessages(messagesJSON string) []PromptMessage {
 						if result, ok := partMap["result"].(string); ok && result != "" {
 							contentParts = append(contentParts, result)
 						}
+
+					case "image", "audio", "video", "file":
+						// Media placeholders show where the payload was in the message
+						if _, ok := partMap["bytes"]; ok {
+							contentParts = append(contentParts, encodeJSON(partMap))
+						}
 					}
 				}
 			}
@@ -888,7 +1333,7 @@ func extractTraceloopPromptMessages(attrs map[string]interface{}) []PromptMessag
 // 2. Traceloop format: gen_ai.completion.{index}.{field}
 func ExtractCompletionMessages(attrs map[string]interface{}) []PromptMessage {
 	// First, try OTEL format (gen_ai.output.messages)
-	if messagesJSON, ok := attrs["gen_ai.output.messages"].(string); ok && messagesJSON != "" {
+	if messagesJSON, ok := asString(attrs["gen_ai.output.messages"]); ok && messagesJSON != "" {
 		messages := parseOTELMessages(messagesJSON)
 		if len(messages) > 0 {
 			return messages
@@ -1025,7 +1470,7 @@ func extractTraceloopCompletionMessages(attrs map[string]interface{}) []PromptMe
 // 2. Traceloop format: llm.request.functions.{index}.{field}
 func ExtractToolDefinitions(attrs map[string]interface{}) []ToolDefinition {
 	// First, try OTEL format (gen_ai.tool.definitions)
-	if toolsJSON, ok := attrs["gen_ai.tool.definitions"].(string); ok && toolsJSON != "" {
+	if toolsJSON, ok := asString(attrs["gen_ai.tool.definitions"]); ok && toolsJSON != "" {
 		tools := parseOTELToolDefinitions(toolsJSON)
 		if len(tools) > 0 {
 			return tools
@@ -1038,49 +1483,22 @@ func ExtractToolDefinitions(attrs map[string]interface{}) []ToolDefinition {
 
 // parseOTELToolDefinitions parses OTEL format tool definitions from JSON string
 // Format: [{"type": "function", "name": "...", "description": "...", "parameters": {...}}]
+// The array is decoded one tool at a time from at most the parse limit
 func parseOTELToolDefinitions(toolsJSON string) []ToolDefinition {
 	if toolsJSON == "" {
 		return nil
 	}
 
-	// Parse JSON array
-	var rawTools []map[string]interface{}
-	if err := json.Unmarshal([]byte(toolsJSON), &rawTools); err != nil {
-		return nil
-	}
-
-	tools := make([]ToolDefinition, 0, len(rawTools))
-	for _, rawTool := range rawTools {
-		tool := ToolDefinition{}
-
-		// Extract name
-		if name, ok := rawTool["name"].(string); ok {
-			tool.Name = name
-		}
-
-		// Extract description
-		if desc, ok := rawTool["description"].(string); ok {
-			tool.Description = desc
-		}
-
-		// Extract parameters (convert to JSON string)
-		if params, ok := rawTool["parameters"]; ok {
-			if paramsStr, ok := params.(string); ok {
-				tool.Parameters = paramsStr
-			} else {
-				// Convert to JSON string
-				if paramsBytes, err := json.Marshal(params); err == nil {
-					tool.Parameters = string(paramsBytes)
-				}
-			}
-		}
-
+	tools := []ToolDefinition{}
+	complete := decodeJSONArray(toolsJSON, func(element json.RawMessage) {
 		// Only add tool if it has a name
-		if tool.Name != "" {
+		if tool := toolDefinitionFromJSON(element); tool.Name != "" {
 			tools = append(tools, tool)
 		}
+	})
+	if !complete && len(tools) == 0 {
+		return nil
 	}
-
 	return tools
 }
 
@@ -1126,6 +1544,7 @@ func extractTraceloopToolDefinitions(attrs map[string]interface{}) []ToolDefinit
 					} else if fieldName == "parameters" {
 						if params, ok := value.(string); ok {
 							toolMap[index].Parameters = params
+							toolMap[index].ParameterSchema = parseToolSchema(params)
 						}
 					}
 				}
@@ -1154,23 +1573,23 @@ func ExtractToolExecutionDetails(attrs map[string]interface{}, spanStatus string
 	var name, input, output, status string
 
 	// Extract tool name - prioritize traceloop.entity.name
-	if entityName, ok := attrs["traceloop.entity.name"].(string); ok {
+	if entityName, ok := asString(attrs["traceloop.entity.name"]); ok {
 		name = entityName
-	} else if toolName, ok := attrs["tool.name"].(string); ok {
+	} else if toolName, ok := asString(attrs["tool.name"]); ok {
 		name = toolName
-	} else if toolID, ok := attrs["tool_name"].(string); ok { // crewai legacy
+	} else if toolID, ok := asString(attrs["tool_name"]); ok { // crewai legacy
 		name = toolID
-	} else if funcName, ok := attrs["function.name"].(string); ok {
+	} else if funcName, ok := asString(attrs["function.name"]); ok {
 		name = funcName
-	} else if genAIName, ok := attrs["gen_ai.tool.name"].(string); ok {
+	} else if genAIName, ok := asString(attrs["gen_ai.tool.name"]); ok {
 		name = genAIName
 	}
 
 	// Extract tool input - prioritize traceloop.entity.input with "inputs" extraction
-	if traceloopInput, ok := attrs["traceloop.entity.input"].(string); ok && traceloopInput != "" {
+	if traceloopInput, ok := asString(attrs["traceloop.entity.input"]); ok && traceloopInput != "" {
 		// Try to parse as JSON and extract "inputs" field
 		var inputMap map[string]interface{}
-		if err := json.Unmarshal([]byte(traceloopInput), &inputMap); err == nil {
+		if err := unmarshalAttribute(traceloopInput, &inputMap); err == nil {
 			if inputs, ok := inputMap["inputs"]; ok {
 				// Convert inputs to JSON string
 				if inputsJSON, err := json.Marshal(inputs); err == nil {
@@ -1184,28 +1603,28 @@ func ExtractToolExecutionDetails(attrs map[string]interface{}, spanStatus string
 		} else {
 			input = traceloopInput // Not valid JSON, use as-is
 		}
-	} else if toolInput, ok := attrs["tool.input"].(string); ok {
+	} else if toolInput, ok := asString(attrs["tool.input"]); ok {
 		input = toolInput
-	} else if toolArgs, ok := attrs["tool.arguments"].(string); ok {
+	} else if toolArgs, ok := asString(attrs["tool.arguments"]); ok {
 		input = toolArgs
-	} else if funcArgs, ok := attrs["function.arguments"].(string); ok {
+	} else if funcArgs, ok := asString(attrs["function.arguments"]); ok {
 		input = funcArgs
 	}
 
 	// Extract tool output - prioritize traceloop.entity.output
-	if entityOutput, ok := attrs["traceloop.entity.output"].(string); ok {
+	if entityOutput, ok := asString(attrs["traceloop.entity.output"]); ok {
 		output = entityOutput
-	} else if toolOutput, ok := attrs["tool.output"].(string); ok {
+	} else if toolOutput, ok := asString(attrs["tool.output"]); ok {
 		output = toolOutput
-	} else if toolResult, ok := attrs["tool.result"].(string); ok {
+	} else if toolResult, ok := asString(attrs["tool.result"]); ok {
 		output = toolResult
-	} else if funcResult, ok := attrs["function.result"].(string); ok {
+	} else if funcResult, ok := asString(attrs["function.result"]); ok {
 		output = funcResult
 	}
 
 	// Determine status
 	// First check if there's an explicit tool status attribute
-	if toolStatus, ok := attrs["tool.status"].(string); ok {
+	if toolStatus, ok := asString(attrs["tool.status"]); ok {
 		status = toolStatus
 	} else {
 		// Fall back to span status
@@ -1277,8 +1696,22 @@ func DetermineSpanType(span Span) SpanType {
 		return SpanTypeCrewAITask
 	}
 
+	// Check for CrewAI Flow operations
+	// Flow methods are steps of the flow, the flow itself orchestrates like an agent
+	if isCrewAIFlowSpan(span.Attributes) {
+		if isCrewAIFlowMethodSpan(span.Attributes) {
+			return SpanTypeChain
+		}
+		return SpanTypeAgent
+	}
+
+	// Guardrail checks may carry LLM attributes of the model they run, so they are detected first
+	if hasGuardrailAttributes(span.Attributes) {
+		return SpanTypeGuardrail
+	}
+
 	// First, check if Traceloop has already set the span kind
-	if traceloopKind, ok := span.Attributes["traceloop.span.kind"].(string); ok {
+	if traceloopKind, ok := asString(span.Attributes["traceloop.span.kind"]); ok {
 		switch traceloopKind {
 		case "llm":
 			return SpanTypeLLM
@@ -1380,7 +1813,7 @@ func determineSpanTypeFromName(name string) SpanType {
 
 func hasLLMAttributes(attrs map[string]interface{}) bool {
 	// Check for gen_ai.operation.name (as requested)
-	if opName, ok := attrs["gen_ai.operation.name"].(string); ok {
+	if opName, ok := asString(attrs["gen_ai.operation.name"]); ok {
 		if opName == "chat" || opName == "completion" || opName == "text_completion" {
 			return true
 		}
@@ -1397,7 +1830,7 @@ func hasLLMAttributes(attrs map[string]interface{}) bool {
 	}
 
 	// Traceloop / Legacy compatibility (excluding embeddings)
-	if reqType, ok := attrs["llm.request.type"].(string); ok {
+	if reqType, ok := asString(attrs["llm.request.type"]); ok {
 		return reqType != "embedding"
 	}
 
@@ -1407,19 +1840,19 @@ func hasLLMAttributes(attrs map[string]interface{}) bool {
 // hasEmbeddingAttributes checks if span has embedding generation attributes
 func hasEmbeddingAttributes(attrs map[string]interface{}) bool {
 	// Check for gen_ai.operation.name = embedding
-	if opName, ok := attrs["gen_ai.operation.name"].(string); ok {
+	if opName, ok := asString(attrs["gen_ai.operation.name"]); ok {
 		if opName == "embedding" || opName == "embeddings" {
 			return true
 		}
 	}
 
 	// Check for embedding-specific attributes
-	if _, ok := attrs["gen_ai.embedding.dimension"].(float64); ok {
+	if _, ok := asFloat(attrs["gen_ai.embedding.dimension"]); ok {
 		return true
 	}
 
 	// Traceloop specific
-	if reqType, ok := attrs["llm.request.type"].(string); ok {
+	if reqType, ok := asString(attrs["llm.request.type"]); ok {
 		if reqType == "embedding" {
 			return true
 		}
@@ -1431,22 +1864,27 @@ func hasEmbeddingAttributes(attrs map[string]interface{}) bool {
 // hasToolAttributes checks if span has tool/function call attributes
 func hasToolAttributes(attrs map[string]interface{}) bool {
 	// Check for tool call attributes
-	if _, ok := attrs["gen_ai.tool.name"].(string); ok {
+	if _, ok := asString(attrs["gen_ai.tool.name"]); ok {
 		return true
 	}
 
 	// Check for function call attributes
-	if _, ok := attrs["function.name"].(string); ok {
+	if _, ok := asString(attrs["function.name"]); ok {
 		return true
 	}
 
 	// Traceloop specific: tool.* namespace
-	if _, ok := attrs["tool.name"].(string); ok {
+	if _, ok := asString(attrs["tool.name"]); ok {
 		return true
 	}
 
 	// CrewAI specific: function.* namespace
-	if _, ok := attrs["tool_name"].(string); ok {
+	if _, ok := asString(attrs["tool_name"]); ok {
+		return true
+	}
+
+	// CrewAI specific: crewai.tool.* namespace
+	if _, ok := asString(attrs["crewai.tool.name"]); ok {
 		return true
 	}
 
@@ -1468,7 +1906,7 @@ func hasRetrieverAttributes(attrs map[string]interface{}) bool {
 	}
 
 	// Check for vector database system
-	if dbSystem, ok := attrs["db.system"].(string); ok {
+	if dbSystem, ok := asString(attrs["db.system"]); ok {
 		vectorDBs := []string{"pinecone", "weaviate", "qdrant", "milvus", "chroma", "chromadb"}
 		for _, vdb := range vectorDBs {
 			if dbSystem == vdb {
@@ -1478,7 +1916,7 @@ func hasRetrieverAttributes(attrs map[string]interface{}) bool {
 	}
 
 	// Check for retrieval-specific operations
-	if opName, ok := attrs["db.operation"].(string); ok {
+	if opName, ok := asString(attrs["db.operation"]); ok {
 		if opName == "query" || opName == "search" || opName == "retrieve" {
 			return true
 		}
@@ -1490,19 +1928,19 @@ func hasRetrieverAttributes(attrs map[string]interface{}) bool {
 // hasRerankAttributes checks if span has reranking attributes
 func hasRerankAttributes(attrs map[string]interface{}) bool {
 	// Check for rerank operation
-	if opName, ok := attrs["gen_ai.operation.name"].(string); ok {
+	if opName, ok := asString(attrs["gen_ai.operation.name"]); ok {
 		if opName == "rerank" || opName == "reranking" {
 			return true
 		}
 	}
 
 	// Traceloop specific
-	if _, ok := attrs["rerank.model"].(string); ok {
+	if _, ok := asString(attrs["rerank.model"]); ok {
 		return true
 	}
 
 	// Check for reranker model names
-	if model, ok := attrs["gen_ai.request.model"].(string); ok {
+	if model, ok := asString(attrs["gen_ai.request.model"]); ok {
 		// Common reranker models - check if model name contains these patterns
 		if strings.Contains(model, "rerank-english") || strings.Contains(model, "rerank-multilingual") {
 			return true
@@ -1529,7 +1967,7 @@ func hasCrewAITaskAttributes(attrs map[string]interface{}) bool {
 	// Check if any attribute starts with "crewai.task"
 	for key := range attrs {
 		if strings.HasPrefix(key, "crewai.task") {
-			if kind, ok := attrs["traceloop.span.kind"].(string); ok {
+			if kind, ok := asString(attrs["traceloop.span.kind"]); ok {
 				if strings.ToLower(kind) == "task" {
 					return true
 				}
@@ -1543,7 +1981,7 @@ func hasCrewAITaskAttributes(attrs map[string]interface{}) bool {
 // hasTaskAttributes checks if span has task/workflow attributes
 func hasTaskAttributes(attrs map[string]interface{}, spanName string) bool {
 	// Check traceloop.span.kind attribute
-	if kind, ok := attrs["traceloop.span.kind"].(string); ok {
+	if kind, ok := asString(attrs["traceloop.span.kind"]); ok {
 		kindLower := strings.ToLower(kind)
 		if kindLower == "task" || kindLower == "workflow" {
 			return true
@@ -1563,7 +2001,7 @@ func hasTaskAttributes(attrs map[string]interface{}, spanName string) bool {
 	}
 
 	// Check for workflow-related attributes as fallback
-	if _, ok := attrs["workflow.name"].(string); ok {
+	if _, ok := asString(attrs["workflow.name"]); ok {
 		return true
 	}
 
@@ -0,0 +1,294 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package opensearch
+
+import (
+	"encoding/json"
+	"os"
+	"reflect"
+	"testing"
+)
+
+// loadSpanFixture parses the span documents of a testdata file
+func loadSpanFixture(t *testing.T, name string) map[string]Span {
+	t.Helper()
+	payload, err := os.ReadFile("testdata/" + name)
+	if err != nil {
+		t.Fatalf("read fixture: %v", err)
+	}
+	var sources []map[string]interface{}
+	if err := json.Unmarshal(payload, &sources); err != nil {
+		t.Fatalf("decode fixture: %v", err)
+	}
+	spans := map[string]Span{}
+	for _, span := range ParseSpanSources(sources) {
+		spans[span.SpanID] = span
+	}
+	return spans
+}
+
+func TestTokenUsageOfAnthropicAndBedrockSpans(t *testing.T) {
+	tests := []struct {
+		fixture string
+		spanID  string
+		want    LLMTokenUsage
+	}{
+		// Anthropic reports input tokens without the cached ones, they are added back
+		{
+			fixture: "anthropic_sdk_spans.json",
+			spanID:  "a1e2c3d4e5f60718",
+			want:    LLMTokenUsage{InputTokens: 2100, OutputTokens: 350, CacheReadInputTokens: 800, CacheWriteInputTokens: 100, TotalTokens: 2450},
+		},
+		{
+			fixture: "anthropic_sdk_spans.json",
+			spanID:  "a2e2c3d4e5f60718",
+			want:    LLMTokenUsage{InputTokens: 2073, OutputTokens: 90, CacheReadInputTokens: 2048, TotalTokens: 2163},
+		},
+		// The standard attributes win over the Anthropic ones reporting the same call
+		{
+			fixture: "anthropic_sdk_spans.json",
+			spanID:  "a3e2c3d4e5f60718",
+			want:    LLMTokenUsage{InputTokens: 1500, OutputTokens: 200, CacheReadInputTokens: 1000, TotalTokens: 1700},
+		},
+		{
+			fixture: "bedrock_converse_spans.json",
+			spanID:  "b1f7651916cd43dd",
+			want:    LLMTokenUsage{InputTokens: 1536, OutputTokens: 128, CacheReadInputTokens: 1024, TotalTokens: 1664},
+		},
+		{
+			fixture: "bedrock_converse_spans.json",
+			spanID:  "b2f7651916cd43dd",
+			want:    LLMTokenUsage{InputTokens: 310, OutputTokens: 42, TotalTokens: 352},
+		},
+	}
+
+	spans := map[string]map[string]Span{}
+	for _, tt := range tests {
+		t.Run(tt.spanID, func(t *testing.T) {
+			if spans[tt.fixture] == nil {
+				spans[tt.fixture] = loadSpanFixture(t, tt.fixture)
+			}
+			span, ok := spans[tt.fixture][tt.spanID]
+			if !ok {
+				t.Fatalf("span %s not in %s", tt.spanID, tt.fixture)
+			}
+			data, ok := span.AmpAttributes.Data.(LLMData)
+			if !ok {
+				t.Fatalf("span data = %T, want LLMData", span.AmpAttributes.Data)
+			}
+			if data.TokenUsage == nil || !reflect.DeepEqual(*data.TokenUsage, tt.want) {
+				t.Errorf("token usage = %+v, want %+v", data.TokenUsage, tt.want)
+			}
+		})
+	}
+}
+
+func TestTokenUsagePrecedence(t *testing.T) {
+	tests := []struct {
+		name  string
+		attrs map[string]interface{}
+		want  *LLMTokenUsage
+	}{
+		{
+			name:  "camelCase attributes before anthropic attributes",
+			attrs: map[string]interface{}{"gen_ai.usage.inputTokens": 10, "gen_ai.usage.outputTokens": 5, "anthropic.usage.input_tokens": 99},
+			want:  &LLMTokenUsage{InputTokens: 10, OutputTokens: 5, TotalTokens: 15},
+		},
+		{
+			name:  "flattened attributes before the usage object",
+			attrs: map[string]interface{}{"anthropic.usage.input_tokens": 7, "anthropic.usage.output_tokens": 3, "gen_ai.usage": `{"input_tokens": 99}`},
+			want:  &LLMTokenUsage{InputTokens: 7, OutputTokens: 3, TotalTokens: 10},
+		},
+		{
+			name:  "OpenAI usage object",
+			attrs: map[string]interface{}{"gen_ai.usage": map[string]interface{}{"prompt_tokens": 40, "completion_tokens": 8}},
+			want:  &LLMTokenUsage{InputTokens: 40, OutputTokens: 8, TotalTokens: 48},
+		},
+		{
+			name:  "usage object in anthropic.usage",
+			attrs: map[string]interface{}{"anthropic.usage": `{"input_tokens": 4, "output_tokens": 6}`},
+			want:  &LLMTokenUsage{InputTokens: 4, OutputTokens: 6, TotalTokens: 10},
+		},
+		{
+			name:  "zero counts",
+			attrs: map[string]interface{}{"gen_ai.usage.inputTokens": 0, "gen_ai.usage": "not json"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := extractTokenUsageFromAttributes(tt.attrs); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("extractTokenUsageFromAttributes() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtractReasoningTokens(t *testing.T) {
+	tests := []struct {
+		name  string
+		attrs map[string]interface{}
+		want  int
+	}{
+		{
+			name:  "flat attribute",
+			attrs: map[string]interface{}{"gen_ai.usage.reasoning_tokens": 64, "gen_ai.usage.completion_tokens_details.reasoning_tokens": 1},
+			want:  64,
+		},
+		{
+			name:  "flattened completion token details",
+			attrs: map[string]interface{}{"gen_ai.usage.completion_tokens_details.reasoning_tokens": "32"},
+			want:  32,
+		},
+		{
+			name:  "completion token details as JSON",
+			attrs: map[string]interface{}{"gen_ai.usage.completion_tokens_details": `{"reasoning_tokens": 128}`},
+			want:  128,
+		},
+		{
+			name:  "completion token details as an object",
+			attrs: map[string]interface{}{"llm.usage.completion_tokens_details": map[string]interface{}{"reasoning_tokens": 16}},
+			want:  16,
+		},
+		{
+			name:  "invalid details",
+			attrs: map[string]interface{}{"gen_ai.usage.completion_tokens_details": "not json"},
+		},
+		{
+			name: "no reasoning tokens",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := extractReasoningTokens(tt.attrs); got != tt.want {
+				t.Errorf("extractReasoningTokens() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTokenUsageIncludesReasoningTokens(t *testing.T) {
+	got := extractTokenUsageFromAttributes(map[string]interface{}{
+		"gen_ai.usage.input_tokens":     100,
+		"gen_ai.usage.output_tokens":    300,
+		"gen_ai.usage.reasoning_tokens": 250,
+	})
+	want := &LLMTokenUsage{InputTokens: 100, OutputTokens: 300, ReasoningTokens: 250, TotalTokens: 400}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("extractTokenUsageFromAttributes() = %+v, want %+v", got, want)
+	}
+
+	usage := AggregateTraceTokenUsage([]Span{
+		{SpanID: "a", AmpAttributes: &AmpAttributes{Kind: string(SpanTypeLLM), Data: LLMData{Model: "o3", TokenUsage: want}}},
+		{SpanID: "b", ParentSpanID: "a", AmpAttributes: &AmpAttributes{Kind: string(SpanTypeLLM), Data: LLMData{Model: "o3", TokenUsage: &LLMTokenUsage{ReasoningTokens: 50}}}},
+	})
+	if usage == nil || usage.ReasoningTokens != 300 || usage.Models[0].ReasoningTokens != 300 {
+		t.Errorf("aggregated reasoning tokens = %+v, want 300", usage)
+	}
+}
+
+func TestTokenUsageCacheWriteTokens(t *testing.T) {
+	tests := []struct {
+		name  string
+		attrs map[string]interface{}
+		want  *LLMTokenUsage
+	}{
+		{
+			name: "standard attributes",
+			attrs: map[string]interface{}{
+				"gen_ai.usage.input_tokens": 1200, "gen_ai.usage.output_tokens": 100,
+				"gen_ai.usage.cache_creation_input_tokens": 1000, "gen_ai.usage.cache_write_tokens": 1,
+			},
+			want: &LLMTokenUsage{InputTokens: 1200, OutputTokens: 100, CacheWriteInputTokens: 1000, TotalTokens: 1300},
+		},
+		{
+			name: "OpenInference prompt details",
+			attrs: map[string]interface{}{
+				"gen_ai.usage.input_tokens": 500, "gen_ai.usage.output_tokens": 50,
+				"llm.token_count.prompt_details.cache_write": 300, "llm.token_count.prompt_details.cache_read": 100,
+			},
+			want: &LLMTokenUsage{InputTokens: 500, OutputTokens: 50, CacheReadInputTokens: 100, CacheWriteInputTokens: 300, TotalTokens: 550},
+		},
+		// Anthropic leaves cache writes out of the input tokens, they are added back
+		{
+			name: "flattened anthropic attributes",
+			attrs: map[string]interface{}{
+				"anthropic.usage.input_tokens": 20, "anthropic.usage.output_tokens": 10,
+				"anthropic.usage.cache_creation_input_tokens": 400,
+			},
+			want: &LLMTokenUsage{InputTokens: 420, OutputTokens: 10, CacheWriteInputTokens: 400, TotalTokens: 430},
+		},
+		{
+			name:  "Bedrock Converse usage object",
+			attrs: map[string]interface{}{"gen_ai.usage": `{"inputTokens": 30, "outputTokens": 5, "cacheWriteInputTokens": 70}`},
+			want:  &LLMTokenUsage{InputTokens: 100, OutputTokens: 5, CacheWriteInputTokens: 70, TotalTokens: 105},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := extractTokenUsageFromAttributes(tt.attrs); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("extractTokenUsageFromAttributes() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtractSessionID(t *testing.T) {
+	tests := []struct {
+		name  string
+		attrs map[string]interface{}
+		want  string
+	}{
+		{"session attribute first", map[string]interface{}{"session.id": "s-1", "gen_ai.conversation.id": "c-1", "thread.id": "t-1"}, "s-1"},
+		{"conversation", map[string]interface{}{"gen_ai.conversation.id": "c-1", "thread.id": "t-1"}, "c-1"},
+		{"thread", map[string]interface{}{"thread.id": "t-1"}, "t-1"},
+		{"empty values are skipped", map[string]interface{}{"session.id": "", "thread.id": "t-1"}, "t-1"},
+		{"numeric values", map[string]interface{}{"session.id": 42}, "42"},
+		{"no session", nil, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ExtractSessionID(tt.attrs); got != tt.want {
+				t.Errorf("ExtractSessionID() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	span := ProcessDocument(map[string]interface{}{"traceId": "t", "spanId": "s", "attributes": map[string]interface{}{"gen_ai.conversation.id": "c-1"}})
+	if span.SessionID != "c-1" {
+		t.Errorf("span session ID = %q, want c-1", span.SessionID)
+	}
+}
+
+func TestSpanFramework(t *testing.T) {
+	tests := []struct {
+		name       string
+		attributes map[string]interface{}
+		want       string
+	}{
+		{"crewai attributes", map[string]interface{}{"crewai.agent.role": "Researcher", "gen_ai.system": "openai"}, "crewai"},
+		{"gen_ai system", map[string]interface{}{"gen_ai.system": "LangChain"}, "langchain"},
+		{"unknown", map[string]interface{}{"http.method": "GET"}, ""},
+		{"no attributes", nil, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SpanFramework(Span{Attributes: tt.attributes}); got != tt.want {
+				t.Errorf("SpanFramework() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
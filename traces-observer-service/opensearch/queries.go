@@ -18,6 +18,7 @@ package opensearch
 
 import (
 	"fmt"
+	"strings"
 	"time"
 )
 
@@ -45,29 +46,93 @@ func GetIndicesForTimeRange(startTime, endTime string) ([]string, error) {
 	}
 
 	// Generate indices for each day in the range
+	// The day after the range is included since spans are written to the index of the day they are received,
+	// which may follow the day they started
 	indices := []string{}
-	indexMap := make(map[string]bool) // To avoid duplicates
-
-	// Iterate through each day from start to end
-	currentDay := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, start.Location())
-	endDay := time.Date(end.Year(), end.Month(), end.Day(), 0, 0, 0, 0, end.Location())
+	currentDay := start.UTC().Truncate(24 * time.Hour)
+	endDay := end.UTC().Truncate(24*time.Hour).AddDate(0, 0, 1)
 
 	for !currentDay.After(endDay) {
-		indexName := fmt.Sprintf("otel-traces-%04d-%02d-%02d", currentDay.Year(), currentDay.Month(), currentDay.Day())
-		if !indexMap[indexName] {
-			indices = append(indices, indexName)
-			indexMap[indexName] = true
-		}
-		currentDay = currentDay.AddDate(0, 0, 1) // Add one day
+		indices = append(indices, TraceIndexName(currentDay))
+		currentDay = currentDay.AddDate(0, 0, 1)
 	}
 
 	return indices, nil
 }
 
-// BuildTraceQuery builds an OpenSearch query for traces
+// BuildTraceQuery builds an OpenSearch query for the root spans of traces
+// Results are sorted by the requested field with traceId and spanId as tie breakers so that
+// the sort values of the last hit are unique and can be used as a search_after cursor
 func BuildTraceQuery(params TraceQueryParams) map[string]interface{} {
 	// Build the must conditions
-	mustConditions := []map[string]interface{}{}
+	mustConditions := buildTraceFilterConditions(params)
+
+	// Set default limit if not provided
+	limit := params.Limit
+	if limit == 0 {
+		limit = 100
+	}
+
+	// Set default offset
+	offset := params.Offset
+	if offset < 0 {
+		offset = 0
+	}
+
+	// Set default sort order
+	sortOrder := params.SortOrder
+	if sortOrder == "" {
+		sortOrder = "desc"
+	}
+
+	// Resolve the sort field
+	sortField := "startTime"
+	sortOptions := map[string]string{"order": sortOrder}
+	switch params.SortBy {
+	case TraceSortDuration:
+		sortField = "durationInNanos"
+	case TraceSortTokens:
+		// Traces are ranked by the tokens rolled up onto their root span, root spans without a rollup count as none
+		sortField = TraceRollupField + ".totalTokens"
+		sortOptions = map[string]string{"order": sortOrder, "missing": "0", "unmapped_type": "long"}
+	}
+
+	// Build the complete query
+	query := map[string]interface{}{
+		"query": map[string]interface{}{
+			"bool": map[string]interface{}{
+				"must": mustConditions,
+			},
+		},
+		"size":             limit,
+		"track_total_hits": true,
+		"sort": []map[string]interface{}{
+			{
+				sortField: sortOptions,
+			},
+			{
+				"traceId": map[string]string{
+					"order": sortOrder,
+				},
+			},
+			{
+				"spanId": map[string]string{
+					"order": sortOrder,
+				},
+			},
+		},
+		"from": offset,
+	}
+
+	return query
+}
+
+// buildTraceFilterConditions builds the root span filter conditions for trace listing
+func buildTraceFilterConditions(params TraceQueryParams) []map[string]interface{} {
+	mustConditions := []map[string]interface{}{
+		// Only root spans (no parent span) represent a trace
+		rootSpanCondition(),
+	}
 
 	// Add component UID filter
 	if params.ComponentUid != "" {
@@ -99,43 +164,209 @@ func BuildTraceQuery(params TraceQueryParams) map[string]interface{} {
 		})
 	}
 
-	// Set default limit if not provided
-	limit := params.Limit
-	if limit == 0 {
-		limit = 100
+	// Add framework filter (gen_ai.system or framework-specific attributes such as crewai.*)
+	if params.Framework != "" {
+		framework := strings.ToLower(params.Framework)
+		mustConditions = append(mustConditions, map[string]interface{}{
+			"bool": map[string]interface{}{
+				"should": []map[string]interface{}{
+					{"term": map[string]interface{}{"attributes.gen_ai.system": framework}},
+					{"exists": map[string]interface{}{"field": "attributes." + framework + ".*"}},
+				},
+				"minimum_should_match": 1,
+			},
+		})
 	}
 
-	// Set default offset
-	offset := params.Offset
-	if offset < 0 {
-		offset = 0
+	// Add agent name filter
+	if params.AgentName != "" {
+		should := []map[string]interface{}{}
+		for _, field := range agentNameFields {
+			should = append(should, map[string]interface{}{
+				"term": map[string]interface{}{field: params.AgentName},
+			})
+		}
+		mustConditions = append(mustConditions, map[string]interface{}{
+			"bool": map[string]interface{}{
+				"should":               should,
+				"minimum_should_match": 1,
+			},
+		})
 	}
 
-	// Set default sort order
-	sortOrder := params.SortOrder
-	if sortOrder == "" {
-		sortOrder = "desc"
+	// Add minimum duration filter
+	if params.MinDurationNanos > 0 {
+		mustConditions = append(mustConditions, map[string]interface{}{
+			"range": map[string]interface{}{
+				"durationInNanos": map[string]interface{}{
+					"gte": params.MinDurationNanos,
+				},
+			},
+		})
 	}
 
-	// Build the complete query
-	query := map[string]interface{}{
+	// Filter on the errors rolled up from all spans of the trace, root spans indexed before traces were
+	// rolled up have no status and match neither status
+	switch params.Status {
+	case TraceStatusError:
+		mustConditions = append(mustConditions, map[string]interface{}{
+			"term": map[string]interface{}{TraceRollupField + ".hasError": true},
+		})
+	case TraceStatusOK:
+		mustConditions = append(mustConditions, map[string]interface{}{
+			"term": map[string]interface{}{TraceRollupField + ".hasError": false},
+		})
+	}
+
+	// Filter on the failed guardrail checks rolled up from all spans of the trace
+	if params.HasGuardrailViolation != nil {
+		violations := map[string]interface{}{"gt": 0}
+		if !*params.HasGuardrailViolation {
+			violations = map[string]interface{}{"lte": 0}
+		}
+		mustConditions = append(mustConditions, map[string]interface{}{
+			"range": map[string]interface{}{TraceRollupField + ".guardrailViolations": violations},
+		})
+	}
+
+	// Restrict to a precomputed set of traces (e.g. traces that used a model)
+	if params.TraceIDs != nil {
+		mustConditions = append(mustConditions, map[string]interface{}{
+			"terms": map[string]interface{}{
+				"traceId": params.TraceIDs,
+			},
+		})
+	}
+
+	return mustConditions
+}
+
+// agentNameFields lists the attributes that carry the agent name on root spans
+var agentNameFields = []string{
+	"attributes.gen_ai.agent.name",
+	"attributes.crewai.crew.name",
+	"attributes.crewai.agent.role",
+	"attributes.crewai.flow.name",
+}
+
+// rootSpanCondition matches the root spans that represent a trace
+func rootSpanCondition() map[string]interface{} {
+	return map[string]interface{}{
+		"bool": map[string]interface{}{
+			"should": []map[string]interface{}{
+				{"term": map[string]interface{}{"parentSpanId": ""}},
+				{"bool": map[string]interface{}{
+					"must_not": map[string]interface{}{
+						"exists": map[string]interface{}{"field": "parentSpanId"},
+					},
+				}},
+			},
+			"minimum_should_match": 1,
+		},
+	}
+}
+
+// errorStatusConditions matches spans that ended with an error or recorded an exception
+func errorStatusConditions() []map[string]interface{} {
+	return []map[string]interface{}{
+		{"term": map[string]interface{}{"status.code": 2}},
+		{"exists": map[string]interface{}{"field": "attributes.error.type"}},
+		{"term": map[string]interface{}{"events.name": "exception"}},
+	}
+}
+
+// MaxTraceIDsPerLookup bounds the number of trace IDs collected by a trace ID lookup aggregation
+const MaxTraceIDsPerLookup = 10000
+
+// BuildTraceIDsByModelQuery builds an aggregation query collecting the traces in which a model was used
+func BuildTraceIDsByModelQuery(params TraceQueryParams) map[string]interface{} {
+	mustConditions := []map[string]interface{}{
+		{
+			"bool": map[string]interface{}{
+				"should": []map[string]interface{}{
+					{"term": map[string]interface{}{"attributes.gen_ai.request.model": params.Model}},
+					{"term": map[string]interface{}{"attributes.gen_ai.response.model": params.Model}},
+				},
+				"minimum_should_match": 1,
+			},
+		},
+	}
+
+	// Add component UID filter
+	if params.ComponentUid != "" {
+		mustConditions = append(mustConditions, map[string]interface{}{
+			"term": map[string]interface{}{
+				"resource.openchoreo.dev/component-uid": params.ComponentUid,
+			},
+		})
+	}
+
+	// Add environment UID filter
+	if params.EnvironmentUid != "" {
+		mustConditions = append(mustConditions, map[string]interface{}{
+			"term": map[string]interface{}{
+				"resource.openchoreo.dev/environment-uid": params.EnvironmentUid,
+			},
+		})
+	}
+
+	return map[string]interface{}{
 		"query": map[string]interface{}{
 			"bool": map[string]interface{}{
 				"must": mustConditions,
 			},
 		},
-		"size": limit,
-		"from": offset,
-		"sort": []map[string]interface{}{
-			{
-				"startTime": map[string]string{
-					"order": sortOrder,
+		"size": 0,
+		"aggs": map[string]interface{}{
+			"traces": map[string]interface{}{
+				"terms": map[string]interface{}{
+					"field": "traceId",
+					"size":  MaxTraceIDsPerLookup,
 				},
 			},
 		},
 	}
+}
 
-	return query
+// BuildTraceIDsByAgentQuery builds an aggregation query collecting the traces with a span linked to a managed agent
+func BuildTraceIDsByAgentQuery(params TraceQueryParams) map[string]interface{} {
+	mustConditions := []map[string]interface{}{
+		{"term": map[string]interface{}{AgentIDField: params.AgentID}},
+	}
+	if params.ComponentUid != "" {
+		mustConditions = append(mustConditions, map[string]interface{}{
+			"term": map[string]interface{}{"resource.openchoreo.dev/component-uid": params.ComponentUid},
+		})
+	}
+	if params.EnvironmentUid != "" {
+		mustConditions = append(mustConditions, map[string]interface{}{
+			"term": map[string]interface{}{"resource.openchoreo.dev/environment-uid": params.EnvironmentUid},
+		})
+	}
+	if params.StartTime != "" && params.EndTime != "" {
+		mustConditions = append(mustConditions, map[string]interface{}{
+			"range": map[string]interface{}{
+				"startTime": map[string]interface{}{"gte": params.StartTime, "lte": params.EndTime},
+			},
+		})
+	}
+
+	return map[string]interface{}{
+		"query": map[string]interface{}{
+			"bool": map[string]interface{}{
+				"must": mustConditions,
+			},
+		},
+		"size": 0,
+		"aggs": map[string]interface{}{
+			"traces": map[string]interface{}{
+				"terms": map[string]interface{}{
+					"field": "traceId",
+					"size":  MaxTraceIDsPerLookup,
+				},
+			},
+		},
+	}
 }
 
 // BuildTraceByIdAndServiceQuery builds a query to get spans by both traceId and componentUid
@@ -198,3 +429,188 @@ func BuildTraceByIdAndServiceQuery(params TraceByIdAndServiceParams) map[string]
 
 	return query
 }
+
+// TraceSpansPageSize is the number of spans fetched per page when reading all spans of a trace
+const TraceSpansPageSize = 1000
+
+// BuildSpansForTracesQuery builds a query that pages through all spans of the given traces with search_after
+// Spans are sorted by startTime and spanId so that the sort values are unique per span
+func BuildSpansForTracesQuery(traceIDs []string, componentUid, environmentUid string, searchAfter []interface{}) map[string]interface{} {
+	mustConditions := []map[string]interface{}{
+		{
+			"terms": map[string]interface{}{
+				"traceId": traceIDs,
+			},
+		},
+	}
+
+	// Add component UID filter
+	if componentUid != "" {
+		mustConditions = append(mustConditions, map[string]interface{}{
+			"term": map[string]interface{}{
+				"resource.openchoreo.dev/component-uid": componentUid,
+			},
+		})
+	}
+
+	// Add environment UID filter
+	if environmentUid != "" {
+		mustConditions = append(mustConditions, map[string]interface{}{
+			"term": map[string]interface{}{
+				"resource.openchoreo.dev/environment-uid": environmentUid,
+			},
+		})
+	}
+
+	query := map[string]interface{}{
+		"query": map[string]interface{}{
+			"bool": map[string]interface{}{
+				"must": mustConditions,
+			},
+		},
+		"size": TraceSpansPageSize,
+		"sort": []map[string]interface{}{
+			{
+				"startTime": map[string]string{
+					"order": "asc",
+				},
+			},
+			{
+				"spanId": map[string]string{
+					"order": "asc",
+				},
+			},
+		},
+	}
+
+	if len(searchAfter) > 0 {
+		query["search_after"] = searchAfter
+	}
+
+	return query
+}
+
+// BuildTraceIDsBySessionQuery builds an aggregation query collecting the traces of a session
+func BuildTraceIDsBySessionQuery(params SessionTracesParams) map[string]interface{} {
+	should := []map[string]interface{}{}
+	for _, key := range SessionIDAttributes {
+		should = append(should, map[string]interface{}{
+			"term": map[string]interface{}{"attributes." + key: params.SessionID},
+		})
+	}
+
+	mustConditions := []map[string]interface{}{
+		{
+			"bool": map[string]interface{}{
+				"should":               should,
+				"minimum_should_match": 1,
+			},
+		},
+	}
+
+	// Add component UID filter
+	if params.ComponentUid != "" {
+		mustConditions = append(mustConditions, map[string]interface{}{
+			"term": map[string]interface{}{
+				"resource.openchoreo.dev/component-uid": params.ComponentUid,
+			},
+		})
+	}
+
+	// Add environment UID filter
+	if params.EnvironmentUid != "" {
+		mustConditions = append(mustConditions, map[string]interface{}{
+			"term": map[string]interface{}{
+				"resource.openchoreo.dev/environment-uid": params.EnvironmentUid,
+			},
+		})
+	}
+
+	return map[string]interface{}{
+		"query": map[string]interface{}{
+			"bool": map[string]interface{}{
+				"must": mustConditions,
+			},
+		},
+		"size": 0,
+		"aggs": map[string]interface{}{
+			"traces": map[string]interface{}{
+				"terms": map[string]interface{}{
+					"field": "traceId",
+					"size":  MaxTraceIDsPerLookup,
+				},
+			},
+		},
+	}
+}
+
+// BuildSpanSearchQuery builds a full-text query over span inputs, outputs and system prompts
+// Both the fields and their search subfields are queried so indices created before the
+// text mapping was introduced are matched as well
+func BuildSpanSearchQuery(params SpanSearchParams) map[string]interface{} {
+	fields := []string{}
+	highlightFields := map[string]interface{}{}
+	for _, field := range searchableAttributeFields() {
+		fields = append(fields, field, field+"."+SearchSubfield)
+		highlightFields[field] = map[string]interface{}{}
+		highlightFields[field+"."+SearchSubfield] = map[string]interface{}{}
+	}
+
+	mustConditions := []map[string]interface{}{
+		{
+			"multi_match": map[string]interface{}{
+				"query":   params.Query,
+				"fields":  fields,
+				"lenient": true,
+			},
+		},
+	}
+
+	// Add component UID filter
+	if params.ComponentUid != "" {
+		mustConditions = append(mustConditions, map[string]interface{}{
+			"term": map[string]interface{}{
+				"resource.openchoreo.dev/component-uid": params.ComponentUid,
+			},
+		})
+	}
+
+	// Add environment UID filter
+	if params.EnvironmentUid != "" {
+		mustConditions = append(mustConditions, map[string]interface{}{
+			"term": map[string]interface{}{
+				"resource.openchoreo.dev/environment-uid": params.EnvironmentUid,
+			},
+		})
+	}
+
+	// Add time range filter
+	if params.StartTime != "" && params.EndTime != "" {
+		mustConditions = append(mustConditions, map[string]interface{}{
+			"range": map[string]interface{}{
+				"startTime": map[string]interface{}{
+					"gte": params.StartTime,
+					"lte": params.EndTime,
+				},
+			},
+		})
+	}
+
+	return map[string]interface{}{
+		"query": map[string]interface{}{
+			"bool": map[string]interface{}{
+				"must": mustConditions,
+			},
+		},
+		"size":             params.Limit,
+		"from":             params.Offset,
+		"track_total_hits": true,
+		"highlight": map[string]interface{}{
+			"fields":              highlightFields,
+			"fragment_size":       150,
+			"number_of_fragments": 3,
+			"pre_tags":            []string{"<em>"},
+			"post_tags":           []string{"</em>"},
+		},
+	}
+}
@@ -0,0 +1,225 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package opensearch
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestBuildTraceQuerySort(t *testing.T) {
+	tests := []struct {
+		name   string
+		params TraceQueryParams
+		want   string
+	}{
+		{
+			name: "newest first by default",
+			want: `[{"startTime":{"order":"desc"}},{"traceId":{"order":"desc"}},{"spanId":{"order":"desc"}}]`,
+		},
+		{
+			name:   "duration ascending",
+			params: TraceQueryParams{SortBy: TraceSortDuration, SortOrder: "asc"},
+			want:   `[{"durationInNanos":{"order":"asc"}},{"traceId":{"order":"asc"}},{"spanId":{"order":"asc"}}]`,
+		},
+		{
+			name:   "tokens rolled up onto the root span",
+			params: TraceQueryParams{SortBy: TraceSortTokens, SortOrder: "desc"},
+			want:   `[{"traceRollup.totalTokens":{"missing":"0","order":"desc","unmapped_type":"long"}},{"traceId":{"order":"desc"}},{"spanId":{"order":"desc"}}]`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query := BuildTraceQuery(tt.params)
+			sort, _ := json.Marshal(query["sort"])
+			if string(sort) != tt.want {
+				t.Errorf("sort = %s, want %s", sort, tt.want)
+			}
+		})
+	}
+
+	query := BuildTraceQuery(TraceQueryParams{Offset: -5})
+	if query["size"] != 100 || query["from"] != 0 {
+		t.Errorf("size and from = %v and %v, want 100 and 0", query["size"], query["from"])
+	}
+}
+
+func TestBuildTraceQueryFilters(t *testing.T) {
+	violated, passed := true, false
+	tests := []struct {
+		name   string
+		params TraceQueryParams
+		want   []string
+	}{
+		{
+			name:   "component and environment",
+			params: TraceQueryParams{ComponentUid: "comp", EnvironmentUid: "env"},
+			want: []string{
+				`{"term":{"resource.openchoreo.dev/component-uid":"comp"}}`,
+				`{"term":{"resource.openchoreo.dev/environment-uid":"env"}}`,
+			},
+		},
+		{
+			name:   "framework names are lowercased",
+			params: TraceQueryParams{Framework: "CrewAI"},
+			want: []string{
+				`{"term":{"attributes.gen_ai.system":"crewai"}}`,
+				`{"exists":{"field":"attributes.crewai.*"}}`,
+			},
+		},
+		{
+			name:   "agent name",
+			params: TraceQueryParams{AgentName: "planner"},
+			want: []string{
+				`{"term":{"attributes.gen_ai.agent.name":"planner"}}`,
+				`{"term":{"attributes.crewai.crew.name":"planner"}}`,
+			},
+		},
+		{
+			name:   "minimum duration",
+			params: TraceQueryParams{MinDurationNanos: 2_000_000_000},
+			want:   []string{`{"range":{"durationInNanos":{"gte":2000000000}}}`},
+		},
+		{
+			name:   "time range",
+			params: TraceQueryParams{StartTime: "2025-11-03T00:00:00Z", EndTime: "2025-11-04T00:00:00Z"},
+			want:   []string{`{"range":{"startTime":{"gte":"2025-11-03T00:00:00Z","lte":"2025-11-04T00:00:00Z"}}}`},
+		},
+		{
+			name:   "traces with errors",
+			params: TraceQueryParams{Status: TraceStatusError},
+			want:   []string{`{"term":{"traceRollup.hasError":true}}`},
+		},
+		{
+			name:   "traces without errors",
+			params: TraceQueryParams{Status: TraceStatusOK},
+			want:   []string{`{"term":{"traceRollup.hasError":false}}`},
+		},
+		{
+			name:   "traces with guardrail violations",
+			params: TraceQueryParams{HasGuardrailViolation: &violated},
+			want:   []string{`{"range":{"traceRollup.guardrailViolations":{"gt":0}}}`},
+		},
+		{
+			name:   "traces without guardrail violations",
+			params: TraceQueryParams{HasGuardrailViolation: &passed},
+			want:   []string{`{"range":{"traceRollup.guardrailViolations":{"lte":0}}}`},
+		},
+		{
+			name:   "included traces",
+			params: TraceQueryParams{TraceIDs: []string{"a"}},
+			want:   []string{`{"terms":{"traceId":["a"]}}`},
+		},
+		// An empty set of traces matches nothing rather than every trace
+		{
+			name:   "empty trace set",
+			params: TraceQueryParams{TraceIDs: []string{}},
+			want:   []string{`{"terms":{"traceId":[]}}`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, _ := json.Marshal(BuildTraceQuery(tt.params)["query"])
+			if !strings.Contains(string(query), `{"term":{"parentSpanId":""}}`) {
+				t.Errorf("query = %s, want only root spans", query)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(string(query), want) {
+					t.Errorf("query = %s, want %s", query, want)
+				}
+			}
+		})
+	}
+
+	query, _ := json.Marshal(BuildTraceQuery(TraceQueryParams{})["query"])
+	for _, unwanted := range []string{"component-uid", "durationInNanos", "traceId", "traceRollup"} {
+		if strings.Contains(string(query), unwanted) {
+			t.Errorf("unfiltered query = %s, want no %s filter", query, unwanted)
+		}
+	}
+}
+
+func TestTraceCursor(t *testing.T) {
+	cursor := TraceCursor{SearchAfter: []interface{}{json.Number("1762128000123"), "trace-1", "span-1"}, PitID: "pit", Sort: TraceSortStartTime}
+	encoded, err := EncodeCursor(cursor)
+	if err != nil {
+		t.Fatalf("EncodeCursor() error = %v", err)
+	}
+	if strings.ContainsAny(encoded, "+/=") {
+		t.Errorf("cursor %q is not URL safe", encoded)
+	}
+	decoded, err := DecodeCursor(encoded)
+	if err != nil {
+		t.Fatalf("DecodeCursor() error = %v", err)
+	}
+	// Sort values keep their exact numeric form
+	if decoded.SearchAfter[0] != json.Number("1762128000123") || decoded.PitID != "pit" || decoded.Sort != TraceSortStartTime {
+		t.Errorf("decoded cursor = %+v, want %+v", decoded, cursor)
+	}
+
+	for _, invalid := range []string{"%%%", "bm90IGpzb24"} {
+		if _, err := DecodeCursor(invalid); err == nil {
+			t.Errorf("DecodeCursor(%q) succeeded", invalid)
+		}
+	}
+}
+
+func TestBuildSpanSearchQuery(t *testing.T) {
+	query := BuildSpanSearchQuery(SpanSearchParams{
+		Query:        "refund",
+		ComponentUid: "comp",
+		StartTime:    "2025-11-03T00:00:00Z",
+		EndTime:      "2025-11-04T00:00:00Z",
+		Limit:        20,
+		Offset:       40,
+	})
+	encoded, _ := json.Marshal(query)
+	for _, want := range []string{
+		`"query":"refund"`,
+		// Keyword fields of older indices are searched through their text subfield
+		`"attributes.gen_ai.prompt","attributes.gen_ai.prompt.search"`,
+		`"attributes.crewai.agent.backstory.search"`,
+		`{"term":{"resource.openchoreo.dev/component-uid":"comp"}}`,
+		`{"range":{"startTime":{"gte":"2025-11-03T00:00:00Z","lte":"2025-11-04T00:00:00Z"}}}`,
+		`"from":40`,
+		`"size":20`,
+	} {
+		if !strings.Contains(string(encoded), want) {
+			t.Errorf("query = %s, want %s", encoded, want)
+		}
+	}
+	if strings.Contains(string(encoded), "environment-uid") {
+		t.Errorf("query = %s, want no environment filter", encoded)
+	}
+}
+
+func TestSearchFieldGroup(t *testing.T) {
+	tests := map[string]string{
+		"attributes.gen_ai.prompt":                  "input",
+		"attributes.gen_ai.prompt.search":           "input",
+		"attributes.crewai.tool.result":             "output",
+		"attributes.gen_ai.system_instructions":     "systemPrompt",
+		"attributes.gen_ai.request.model":           "",
+		"attributes.traceloop.entity.output.search": "output",
+	}
+	for field, want := range tests {
+		if got := SearchFieldGroup(field); got != want {
+			t.Errorf("SearchFieldGroup(%q) = %q, want %q", field, got, want)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package opensearch
+
+import (
+	"sync"
+
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/redaction"
+)
+
+var (
+	redactorMu      sync.RWMutex
+	currentRedactor redaction.Redactor
+)
+
+// SetRedactor registers the redactor applied by ParseSpans
+// Passing nil disables redaction
+func SetRedactor(redactor redaction.Redactor) {
+	redactorMu.Lock()
+	defer redactorMu.Unlock()
+	currentRedactor = redactor
+}
+
+// getRedactor returns the registered redactor
+func getRedactor() redaction.Redactor {
+	redactorMu.RLock()
+	defer redactorMu.RUnlock()
+	return currentRedactor
+}
+
+// applyRedaction redacts the input, output, system prompt, messages, tool arguments, raw string attributes
+// and event attributes of a span and records the number of redactions made
+func applyRedaction(span *Span, redactor redaction.Redactor) {
+	if redactor == nil {
+		return
+	}
+
+	count := 0
+	// Raw attributes carry the same prompts and tool arguments as the extracted values
+	for key, value := range span.Attributes {
+		if strValue, ok := value.(string); ok {
+			redacted, n := redactor.Redact(strValue)
+			if n > 0 {
+				span.Attributes[key] = redacted
+				count += n
+			}
+		}
+	}
+
+	// Events carry prompts, completions and exception messages
+	for _, event := range span.Events {
+		for key, value := range event.Attributes {
+			if strValue, ok := value.(string); ok {
+				redacted, n := redactor.Redact(strValue)
+				if n > 0 {
+					event.Attributes[key] = redacted
+					count += n
+				}
+			}
+		}
+	}
+
+	ampAttrs := span.AmpAttributes
+	if ampAttrs == nil {
+		span.Redactions += count
+		return
+	}
+
+	ampAttrs.Input = redactValue(ampAttrs.Input, redactor, &count)
+	ampAttrs.Output = redactValue(ampAttrs.Output, redactor, &count)
+
+	if agentData, ok := ampAttrs.Data.(AgentData); ok && agentData.SystemPrompt != "" {
+		var redacted int
+		agentData.SystemPrompt, redacted = redactor.Redact(agentData.SystemPrompt)
+		if redacted > 0 {
+			count += redacted
+			ampAttrs.Data = agentData
+		}
+	}
+	if llmData, ok := ampAttrs.Data.(LLMData); ok && len(llmData.Messages) > 0 {
+		llmData.Messages = redactValue(llmData.Messages, redactor, &count).([]PromptMessage)
+		ampAttrs.Data = llmData
+	}
+
+	span.Redactions += count
+}
+
+// redactValue redacts all strings within an input or output value, including tool call arguments
+func redactValue(value interface{}, redactor redaction.Redactor, count *int) interface{} {
+	switch v := value.(type) {
+	case string:
+		redacted, n := redactor.Redact(v)
+		*count += n
+		return redacted
+	case []PromptMessage:
+		messages := make([]PromptMessage, len(v))
+		for i, message := range v {
+			messages[i] = message
+			messages[i].Content = redactValue(message.Content, redactor, count).(string)
+			if len(message.ToolCalls) > 0 {
+				messages[i].ToolCalls = make([]ToolCall, len(message.ToolCalls))
+				for j, toolCall := range message.ToolCalls {
+					toolCall.Arguments = redactValue(toolCall.Arguments, redactor, count).(string)
+					messages[i].ToolCalls[j] = toolCall
+				}
+			}
+		}
+		return messages
+	case map[string]interface{}:
+		redacted := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			redacted[key] = redactValue(item, redactor, count)
+		}
+		return redacted
+	case []interface{}:
+		redacted := make([]interface{}, len(v))
+		for i, item := range v {
+			redacted[i] = redactValue(item, redactor, count)
+		}
+		return redacted
+	default:
+		return value
+	}
+}
@@ -0,0 +1,111 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package opensearch
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/redaction"
+)
+
+// withRedactor registers a redactor for the duration of a test
+func withRedactor(t *testing.T, redactor redaction.Redactor) {
+	previous := getRedactor()
+	SetRedactor(redactor)
+	t.Cleanup(func() { SetRedactor(previous) })
+}
+
+func TestApplyRedaction(t *testing.T) {
+	const email = "jane@example.com"
+	redactor := redaction.DefaultRedactor()
+
+	span := Span{
+		Attributes: map[string]interface{}{"gen_ai.prompt": "mail " + email, "gen_ai.request.model": "gpt-4o", "retries": 2},
+		Events:     []SpanEvent{{Name: "exception", Attributes: map[string]interface{}{"exception.message": "no user " + email}}},
+		AmpAttributes: &AmpAttributes{
+			Input: []PromptMessage{
+				{Role: "user", Content: "I am " + email},
+				{Role: "assistant", ToolCalls: []ToolCall{{ID: "call-1", Name: "lookup", Arguments: `{"email":"` + email + `"}`}}},
+			},
+			Output: map[string]interface{}{"result": []interface{}{"sent to " + email, 3}},
+			Data:   LLMData{Model: "gpt-4o", Messages: []PromptMessage{{Role: "user", Content: email}}},
+		},
+	}
+	applyRedaction(&span, redactor)
+
+	if span.Redactions != 6 {
+		t.Errorf("redactions = %d, want 6", span.Redactions)
+	}
+	if span.Attributes["gen_ai.request.model"] != "gpt-4o" || span.Attributes["retries"] != 2 {
+		t.Errorf("attributes without sensitive data changed: %v", span.Attributes)
+	}
+	input := span.AmpAttributes.Input.([]PromptMessage)
+	if input[1].ToolCalls[0].Name != "lookup" || input[1].ToolCalls[0].Arguments != `{"email":"[REDACTED:email]"}` {
+		t.Errorf("tool call = %+v, want redacted arguments", input[1].ToolCalls[0])
+	}
+	wantOutput := map[string]interface{}{"result": []interface{}{"sent to [REDACTED:email]", 3}}
+	if !reflect.DeepEqual(span.AmpAttributes.Output, wantOutput) {
+		t.Errorf("output = %v, want %v", span.AmpAttributes.Output, wantOutput)
+	}
+	for _, value := range []string{
+		span.Attributes["gen_ai.prompt"].(string),
+		span.Events[0].Attributes["exception.message"].(string),
+		input[0].Content,
+		span.AmpAttributes.Data.(LLMData).Messages[0].Content,
+	} {
+		if strings.Contains(value, email) || !strings.Contains(value, redaction.MarkerPrefix) {
+			t.Errorf("value %q was not redacted", value)
+		}
+	}
+}
+
+func TestApplyRedactionOfAgentSystemPrompt(t *testing.T) {
+	span := Span{AmpAttributes: &AmpAttributes{Input: "hello", Data: AgentData{Name: "support", SystemPrompt: "Escalate to ops@example.com"}}}
+	applyRedaction(&span, redaction.DefaultRedactor())
+
+	data := span.AmpAttributes.Data.(AgentData)
+	if data.SystemPrompt != "Escalate to [REDACTED:email]" || data.Name != "support" || span.Redactions != 1 {
+		t.Errorf("agent data = %+v with %d redactions", data, span.Redactions)
+	}
+
+	// Without a redactor the span is left as is
+	untouched := Span{Attributes: map[string]interface{}{"gen_ai.prompt": "ops@example.com"}}
+	applyRedaction(&untouched, nil)
+	if untouched.Attributes["gen_ai.prompt"] != "ops@example.com" || untouched.Redactions != 0 {
+		t.Errorf("span redacted without a redactor: %+v", untouched)
+	}
+}
+
+func TestProcessDocumentRedactsBeforeTruncating(t *testing.T) {
+	withRedactor(t, redaction.DefaultRedactor())
+	withLimits(t, Limits{MaxInputBytes: 32, MaxOutputBytes: 32, MaxSystemPromptBytes: 32, MaxAttributeBytes: 32, MaxParseBytes: DefaultMaxParseBytes})
+
+	// The email straddles the size limit, truncating first would leave part of it behind
+	input := strings.Repeat("x", 25) + " jane@example.com"
+	span := ProcessDocument(map[string]interface{}{
+		"traceId":    "trace",
+		"spanId":     "span",
+		"name":       "task",
+		"attributes": map[string]interface{}{"traceloop.span.kind": "task", "traceloop.entity.input": input},
+	})
+	got, _ := span.Attributes["traceloop.entity.input"].(string)
+	if strings.Contains(got, "jane") || span.Redactions == 0 {
+		t.Errorf("input attribute = %q with %d redactions, want the email redacted", got, span.Redactions)
+	}
+}
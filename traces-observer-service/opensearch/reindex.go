@@ -0,0 +1,113 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package opensearch
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+)
+
+// reindexStagingPrefix names the index holding the documents of a trace index while it is rebuilt,
+// outside of TraceIndexPattern so that neither the template nor the queries match it
+const reindexStagingPrefix = "reindex-"
+
+// ReindexResult reports a rebuilt trace index
+type ReindexResult struct {
+	Index     string
+	Documents int
+}
+
+// ReindexTraceIndex rebuilds a trace index with the configured mapping, for mapping changes that cannot be
+// applied in place. The documents are copied to a staging index with the new mapping, which fails before anything
+// is deleted when a document does not fit it, then the index is recreated and the documents are copied back.
+// Spans written to the index while it is rebuilt are lost, so the index of the current day is refused
+func (c *Client) ReindexTraceIndex(ctx context.Context, index string, now time.Time) (*ReindexResult, error) {
+	if !strings.HasPrefix(index, traceIndexPrefix) {
+		return nil, fmt.Errorf("%s is not a trace index", index)
+	}
+	if index == TraceIndexName(now) {
+		return nil, fmt.Errorf("%s is the index of the current day and still receives spans", index)
+	}
+	exists, err := c.IndexExists(ctx, index)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check index %s: %w", index, err)
+	}
+	if !exists {
+		return nil, fmt.Errorf("index %s does not exist", index)
+	}
+	aliased, err := c.GetAliasIndices(ctx, TraceWriteAlias)
+	if err != nil {
+		return nil, err
+	}
+
+	expected, err := c.Count(ctx, index)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count documents of index %s: %w", index, err)
+	}
+	body := buildTraceIndexBody(getTraceMapping())
+	staging := reindexStagingPrefix + index
+	if err := c.CreateIndex(ctx, staging, body); err != nil {
+		return nil, fmt.Errorf("failed to create staging index %s: %w", staging, err)
+	}
+	if err := c.copyIndex(ctx, index, staging, expected); err != nil {
+		_ = c.DeleteIndices(ctx, []string{staging})
+		return nil, err
+	}
+	slog.InfoContext(ctx, "Copied index documents", "documents", expected, "index", index, "dest", staging)
+
+	// From here on the documents are only held by the staging index, which is kept when a step fails
+	if err := c.DeleteIndices(ctx, []string{index}); err != nil {
+		return nil, fmt.Errorf("failed to delete index %s, its documents are also in %s: %w", index, staging, err)
+	}
+	if err := c.CreateIndex(ctx, index, body); err != nil {
+		return nil, fmt.Errorf("failed to recreate index %s, its documents are kept in %s: %w", index, staging, err)
+	}
+	if err := c.copyIndex(ctx, staging, index, expected); err != nil {
+		return nil, fmt.Errorf("%w, the documents are kept in %s", err, staging)
+	}
+	if slices.Contains(aliased, index) {
+		actions := []map[string]interface{}{
+			{"add": map[string]interface{}{"index": index, "alias": TraceWriteAlias, "is_write_index": true}},
+		}
+		if err := c.UpdateAliases(ctx, actions); err != nil {
+			return nil, fmt.Errorf("failed to restore write alias of index %s: %w", index, err)
+		}
+	}
+	if err := c.DeleteIndices(ctx, []string{staging}); err != nil {
+		return nil, fmt.Errorf("failed to delete staging index %s: %w", staging, err)
+	}
+	return &ReindexResult{Index: index, Documents: expected}, nil
+}
+
+// copyIndex copies the documents of an index into another and checks that none went missing
+func (c *Client) copyIndex(ctx context.Context, source, dest string, expected int) error {
+	if _, err := c.Reindex(ctx, source, dest); err != nil {
+		return fmt.Errorf("failed to copy index %s to %s: %w", source, dest, err)
+	}
+	copied, err := c.Count(ctx, dest)
+	if err != nil {
+		return fmt.Errorf("failed to count documents of index %s: %w", dest, err)
+	}
+	if copied != expected {
+		return fmt.Errorf("copied %d of the %d documents of index %s to %s", copied, expected, source, dest)
+	}
+	return nil
+}
@@ -0,0 +1,272 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package opensearch
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/redaction"
+)
+
+// genAIRequestPrefix prefixes the request parameters of LLM call attributes
+const genAIRequestPrefix = "gen_ai.request."
+
+// TraceReplayParams holds parameters for assembling the replay bundle of a trace
+type TraceReplayParams struct {
+	TraceID        string
+	ComponentUid   string
+	EnvironmentUid string
+	StartTime      string
+	EndTime        string
+}
+
+// ReplayBundle holds what is needed to re-run a trace with its recorded tool outputs
+// The bundle is built from the spans alone, so the same trace always yields the same bundle
+type ReplayBundle struct {
+	TraceID         string             `json:"traceId"`
+	Input           interface{}        `json:"input"` // Input of the root span
+	Agents          []ReplayAgent      `json:"agents"`
+	LLMCalls        []ReplayLLMCall    `json:"llmCalls"`
+	ToolResults     []ReplayToolResult `json:"toolResults"`
+	Complete        bool               `json:"complete"`        // False when any field of the bundle was redacted or truncated
+	RedactedFields  []string           `json:"redactedFields"`  // Paths of the fields holding redacted values, e.g. toolResults[2].result
+	TruncatedFields []string           `json:"truncatedFields"` // Paths of the fields holding truncated values
+}
+
+// ReplayAgent is an agent of a trace, in the order the agents first ran
+// Spans outside of any agent belong to an agent named after their service
+type ReplayAgent struct {
+	Name         string           `json:"name"`
+	Framework    string           `json:"framework,omitempty"`
+	Model        string           `json:"model,omitempty"`
+	SystemPrompt string           `json:"systemPrompt,omitempty"` // From the agent span, else the system messages of its first LLM call
+	Tools        []ToolDefinition `json:"tools"`                  // Tools declared to the agent and its LLM calls
+}
+
+// ReplayLLMCall holds the model parameters of an LLM call
+type ReplayLLMCall struct {
+	SpanID      string                 `json:"spanId"`
+	Agent       string                 `json:"agent"`
+	Model       string                 `json:"model,omitempty"` // gen_ai.request.model, the served model when not given
+	Vendor      string                 `json:"vendor,omitempty"`
+	Temperature *float64               `json:"temperature,omitempty"`
+	Parameters  map[string]interface{} `json:"parameters,omitempty"` // The other gen_ai.request.* attributes, e.g. max_tokens
+}
+
+// ReplayToolResult is a recorded tool call, numbered per agent and tool in the order the calls ran
+type ReplayToolResult struct {
+	SpanID     string      `json:"spanId"`
+	Agent      string      `json:"agent"`
+	Name       string      `json:"name"`
+	Occurrence int         `json:"occurrence"`
+	Arguments  interface{} `json:"arguments,omitempty"`
+	Result     interface{} `json:"result,omitempty"`
+	Error      string      `json:"error,omitempty"`
+}
+
+// BuildReplayBundle assembles the replay bundle of the spans of a trace
+func BuildReplayBundle(traceID string, spans []Span) *ReplayBundle {
+	ordered, byID := spansInStartOrder(spans)
+	bundle := &ReplayBundle{
+		TraceID:         traceID,
+		Agents:          []ReplayAgent{},
+		LLMCalls:        []ReplayLLMCall{},
+		ToolResults:     []ReplayToolResult{},
+		RedactedFields:  []string{},
+		TruncatedFields: []string{},
+	}
+
+	agentNames := make(map[string]string, len(spans))
+	agentIndex := make(map[string]int)
+	// agentFor returns the bundle entry of the agent of a span, adding it on first use
+	agentFor := func(span *Span) (string, *ReplayAgent) {
+		name := spanAgent(span, byID, agentNames)
+		i, ok := agentIndex[name]
+		if !ok {
+			i = len(bundle.Agents)
+			agentIndex[name] = i
+			bundle.Agents = append(bundle.Agents, ReplayAgent{Name: name, Tools: []ToolDefinition{}})
+		}
+		return name, &bundle.Agents[i]
+	}
+
+	rootFound := false
+	toolOccurrences := make(map[spanAlignmentKey]int)
+	for _, span := range ordered {
+		if !rootFound && (span.ParentSpanID == "" || byID[span.ParentSpanID] == nil) {
+			rootFound = true
+			if span.AmpAttributes != nil {
+				bundle.Input = span.AmpAttributes.Input
+			}
+		}
+		if span.AmpAttributes == nil {
+			continue
+		}
+
+		switch data := span.AmpAttributes.Data.(type) {
+		case AgentData:
+			if spanKind(span) != string(SpanTypeAgent) {
+				continue
+			}
+			_, agent := agentFor(span)
+			if agent.Framework == "" {
+				agent.Framework = data.Framework
+			}
+			if agent.Model == "" {
+				agent.Model = data.Model
+			}
+			if agent.SystemPrompt == "" {
+				agent.SystemPrompt = data.SystemPrompt
+			}
+			agent.Tools = appendToolDefinitions(agent.Tools, data.Tools)
+		case LLMData:
+			if spanKind(span) != string(SpanTypeLLM) {
+				continue
+			}
+			name, agent := agentFor(span)
+			if agent.SystemPrompt == "" {
+				agent.SystemPrompt = systemPromptOf(span.AmpAttributes.Input, data.Messages)
+			}
+			agent.Tools = appendToolDefinitions(agent.Tools, data.Tools)
+			bundle.LLMCalls = append(bundle.LLMCalls, newReplayLLMCall(span, name, data))
+		case ToolData:
+			if spanKind(span) != string(SpanTypeTool) {
+				continue
+			}
+			name, _ := agentFor(span)
+			toolName := data.Name
+			if toolName == "" {
+				toolName = span.Name
+			}
+			key := spanAlignmentKey{agent: name, name: toolName}
+			result := ReplayToolResult{
+				SpanID:     span.SpanID,
+				Agent:      name,
+				Name:       toolName,
+				Occurrence: toolOccurrences[key],
+				Arguments:  span.AmpAttributes.Input,
+				Result:     span.AmpAttributes.Output,
+			}
+			toolOccurrences[key]++
+			if span.AmpAttributes.Error != nil {
+				result.Error = span.AmpAttributes.Error.Message
+			}
+			bundle.ToolResults = append(bundle.ToolResults, result)
+		}
+	}
+
+	markIncompleteFields(bundle)
+	return bundle
+}
+
+// newReplayLLMCall reads the request parameters of an LLM span
+func newReplayLLMCall(span *Span, agent string, data LLMData) ReplayLLMCall {
+	call := ReplayLLMCall{
+		SpanID:      span.SpanID,
+		Agent:       agent,
+		Model:       data.Model,
+		Vendor:      data.Vendor,
+		Temperature: data.Temperature,
+	}
+	if model, ok := asString(span.Attributes[genAIRequestPrefix+"model"]); ok && model != "" {
+		call.Model = model
+	}
+	for key, value := range span.Attributes {
+		parameter, ok := strings.CutPrefix(key, genAIRequestPrefix)
+		if !ok || parameter == "model" || parameter == "temperature" {
+			continue
+		}
+		if call.Parameters == nil {
+			call.Parameters = map[string]interface{}{}
+		}
+		call.Parameters[parameter] = value
+	}
+	return call
+}
+
+// systemPromptOf joins the system messages of the prompt of an LLM call
+func systemPromptOf(input interface{}, messages []PromptMessage) string {
+	if prompt, ok := input.([]PromptMessage); ok && len(messages) == 0 {
+		messages = prompt
+	}
+	var parts []string
+	for _, message := range messages {
+		if message.Role == "system" && message.Content != "" {
+			parts = append(parts, message.Content)
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+// appendToolDefinitions appends the tools not declared yet, a tool is known by its name
+func appendToolDefinitions(tools []ToolDefinition, declared []ToolDefinition) []ToolDefinition {
+	for _, tool := range declared {
+		known := false
+		for _, existing := range tools {
+			if existing.Name == tool.Name {
+				known = true
+				break
+			}
+		}
+		if !known {
+			tools = append(tools, tool)
+		}
+	}
+	return tools
+}
+
+// markIncompleteFields records the fields of a bundle holding redacted or truncated values
+func markIncompleteFields(bundle *ReplayBundle) {
+	mark := func(path string, value interface{}) {
+		if containsMarker(value, redaction.MarkerPrefix) {
+			bundle.RedactedFields = append(bundle.RedactedFields, path)
+		}
+		if containsMarker(value, truncationMarker) {
+			bundle.TruncatedFields = append(bundle.TruncatedFields, path)
+		}
+	}
+
+	mark("input", bundle.Input)
+	for i, agent := range bundle.Agents {
+		mark(fmt.Sprintf("agents[%d].systemPrompt", i), agent.SystemPrompt)
+		mark(fmt.Sprintf("agents[%d].tools", i), agent.Tools)
+	}
+	for i, call := range bundle.LLMCalls {
+		mark(fmt.Sprintf("llmCalls[%d].parameters", i), call.Parameters)
+	}
+	for i, result := range bundle.ToolResults {
+		mark(fmt.Sprintf("toolResults[%d].arguments", i), result.Arguments)
+		mark(fmt.Sprintf("toolResults[%d].result", i), result.Result)
+		mark(fmt.Sprintf("toolResults[%d].error", i), result.Error)
+	}
+	bundle.Complete = len(bundle.RedactedFields) == 0 && len(bundle.TruncatedFields) == 0
+}
+
+// containsMarker reports whether any string within a value contains the marker
+func containsMarker(value interface{}, marker string) bool {
+	switch v := value.(type) {
+	case nil:
+		return false
+	case string:
+		return strings.Contains(v, marker)
+	}
+	// Markers hold no characters escaped by JSON, so they are found in the encoded value as is
+	encoded, err := json.Marshal(value)
+	return err == nil && strings.Contains(string(encoded), marker)
+}
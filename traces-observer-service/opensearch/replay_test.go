@@ -0,0 +1,138 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package opensearch
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// buildReplayTestTrace builds a trace of an agent calling an LLM twice and a tool in between
+func buildReplayTestTrace() []Span {
+	base := time.Date(2025, 11, 3, 10, 0, 0, 0, time.UTC)
+	temperature := 0.2
+	span := func(id, parent, name string, second int, attrs *AmpAttributes) Span {
+		return Span{
+			TraceID:       "trace",
+			SpanID:        id,
+			ParentSpanID:  parent,
+			Name:          name,
+			Service:       "support-service",
+			StartTime:     base.Add(time.Duration(second) * time.Second),
+			AmpAttributes: attrs,
+		}
+	}
+	spans := []Span{
+		span("root", "", "POST /chat", 0, &AmpAttributes{Kind: string(SpanTypeChain), Input: "Where is order 42?"}),
+		span("agent", "root", "invoke_agent", 1, &AmpAttributes{Kind: string(SpanTypeAgent), Data: AgentData{
+			Name: "support", Framework: "strands-agents", Model: "gpt-4o",
+			Tools: []ToolDefinition{{Name: "lookup_order"}},
+		}}),
+		span("llm1", "agent", "chat", 2, &AmpAttributes{Kind: string(SpanTypeLLM),
+			Input: []PromptMessage{{Role: "system", Content: "You are a support agent"}, {Role: "user", Content: "Where is order 42?"}},
+			Data:  LLMData{Model: "gpt-4o-2024-08-06", Temperature: &temperature, Tools: []ToolDefinition{{Name: "lookup_order"}, {Name: "refund"}}},
+		}),
+		span("tool1", "agent", "execute_tool", 3, &AmpAttributes{Kind: string(SpanTypeTool),
+			Input: map[string]interface{}{"orderId": "42"}, Output: "shipped, contact [REDACTED:email]",
+			Data: ToolData{Name: "lookup_order"},
+		}),
+		span("llm2", "agent", "chat", 4, &AmpAttributes{Kind: string(SpanTypeLLM), Data: LLMData{Model: "gpt-4o-2024-08-06"}}),
+	}
+	spans[2].Attributes = map[string]interface{}{
+		"gen_ai.request.model":      "gpt-4o",
+		"gen_ai.request.max_tokens": float64(512),
+		"gen_ai.request.top_p":      0.9,
+		"gen_ai.usage.input_tokens": float64(120),
+	}
+	return spans
+}
+
+func TestBuildReplayBundle(t *testing.T) {
+	bundle := BuildReplayBundle("trace", buildReplayTestTrace())
+
+	if bundle.Input != "Where is order 42?" {
+		t.Errorf("input = %v, want the root input", bundle.Input)
+	}
+	if len(bundle.Agents) != 1 {
+		t.Fatalf("agents = %+v, want the support agent only", bundle.Agents)
+	}
+	agent := bundle.Agents[0]
+	if agent.Name != "support" || agent.SystemPrompt != "You are a support agent" || agent.Model != "gpt-4o" {
+		t.Errorf("agent = %+v, want the system prompt of its first LLM call", agent)
+	}
+	if len(agent.Tools) != 2 || agent.Tools[0].Name != "lookup_order" || agent.Tools[1].Name != "refund" {
+		t.Errorf("tools = %+v, want lookup_order and refund once each", agent.Tools)
+	}
+
+	if len(bundle.LLMCalls) != 2 {
+		t.Fatalf("llm calls = %+v, want 2", bundle.LLMCalls)
+	}
+	call := bundle.LLMCalls[0]
+	if call.Model != "gpt-4o" || call.Temperature == nil || *call.Temperature != 0.2 {
+		t.Errorf("call = %+v, want the requested model and temperature", call)
+	}
+	wantParameters := map[string]interface{}{"max_tokens": float64(512), "top_p": 0.9}
+	if !reflect.DeepEqual(call.Parameters, wantParameters) {
+		t.Errorf("parameters = %v, want %v", call.Parameters, wantParameters)
+	}
+	if bundle.LLMCalls[1].Model != "gpt-4o-2024-08-06" {
+		t.Errorf("model = %s, want the served model when none was requested", bundle.LLMCalls[1].Model)
+	}
+
+	if len(bundle.ToolResults) != 1 || bundle.ToolResults[0].Name != "lookup_order" || bundle.ToolResults[0].Agent != "support" {
+		t.Fatalf("tool results = %+v, want the lookup_order call of the support agent", bundle.ToolResults)
+	}
+	if bundle.Complete || !reflect.DeepEqual(bundle.RedactedFields, []string{"toolResults[0].result"}) || len(bundle.TruncatedFields) != 0 {
+		t.Errorf("complete = %v, redacted = %v, truncated = %v, want the redacted tool result marked",
+			bundle.Complete, bundle.RedactedFields, bundle.TruncatedFields)
+	}
+}
+
+func TestBuildReplayBundleDeterministic(t *testing.T) {
+	spans := buildReplayTestTrace()
+	want, err := json.Marshal(BuildReplayBundle("trace", spans))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Spans are returned by OpenSearch in no particular order
+	reversed := make([]Span, len(spans))
+	for i := range spans {
+		reversed[len(spans)-1-i] = spans[i]
+	}
+	got, err := json.Marshal(BuildReplayBundle("trace", reversed))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != string(want) {
+		t.Errorf("bundle of reordered spans differs:\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestBuildReplayBundleTruncatedFields(t *testing.T) {
+	spans := buildReplayTestTrace()
+	spans[0].AmpAttributes.Input = "Where is" + truncationSuffix(4096)
+	spans[3].AmpAttributes.Output = "shipped"
+
+	bundle := BuildReplayBundle("trace", spans)
+	if bundle.Complete || !reflect.DeepEqual(bundle.TruncatedFields, []string{"input"}) || len(bundle.RedactedFields) != 0 {
+		t.Errorf("complete = %v, redacted = %v, truncated = %v, want the truncated input marked",
+			bundle.Complete, bundle.RedactedFields, bundle.TruncatedFields)
+	}
+}
@@ -0,0 +1,169 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package opensearch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"time"
+)
+
+// DerivedFieldUpdate is a partial update of the derived fields of an indexed span document
+// The update only applies while the document still has the sequence number and primary term it was read with
+type DerivedFieldUpdate struct {
+	Index       string
+	ID          string
+	SeqNo       int64
+	PrimaryTerm int64
+	Fields      map[string]interface{}
+}
+
+// derivedFieldsScript replaces derived fields as a whole, a partial document would merge into the stored objects
+// and keep keys that no longer apply. Fields without a value are removed.
+const derivedFieldsScript = `for (entry in params.fields.entrySet()) {
+  if (entry.getValue() == null) { ctx._source.remove(entry.getKey()) } else { ctx._source[entry.getKey()] = entry.getValue() }
+}`
+
+// DerivedFieldUpdateResult counts the outcome of the updates of a bulk request
+type DerivedFieldUpdateResult struct {
+	Updated   int
+	Conflicts int // Documents changed since they were read, e.g. re-delivered by live ingestion
+	Failed    int
+	Error     string // First failure
+}
+
+// BuildReprocessQuery builds a query of the span documents started within a time range, in a stable order
+// Hits carry the sequence number and primary term needed to update them without overwriting concurrent writes
+func BuildReprocessQuery(start, end time.Time) map[string]interface{} {
+	return map[string]interface{}{
+		"query": map[string]interface{}{
+			"range": map[string]interface{}{
+				"startTime": map[string]interface{}{
+					"gte": start.UTC().Format(time.RFC3339Nano),
+					"lte": end.UTC().Format(time.RFC3339Nano),
+				},
+			},
+		},
+		"seq_no_primary_term": true,
+		"track_total_hits":    true,
+		"sort": []map[string]interface{}{
+			{"startTime": map[string]string{"order": "asc"}},
+			{"traceId": map[string]string{"order": "asc"}},
+			{"spanId": map[string]string{"order": "asc"}},
+		},
+	}
+}
+
+// ReprocessUpdate derives the fields of an indexed span document again and returns the update of the fields
+// that changed, false when every derived field is current
+// A summary that no longer applies is removed, the document reads then as if it was indexed without it
+func ReprocessUpdate(index, id string, seqNo, primaryTerm int64, source map[string]interface{}) (DerivedFieldUpdate, bool) {
+	// Compare against the stored values as they would be read back
+	stored := make(map[string]interface{}, len(DerivedFieldNames))
+	for _, field := range DerivedFieldNames {
+		stored[field] = source[field]
+	}
+
+	changed := map[string]interface{}{}
+	for field, value := range DeriveFields(source) {
+		if !reflect.DeepEqual(stored[field], normalizeJSON(value)) {
+			changed[field] = value
+		}
+	}
+	if len(changed) == 0 {
+		return DerivedFieldUpdate{}, false
+	}
+	return DerivedFieldUpdate{Index: index, ID: id, SeqNo: seqNo, PrimaryTerm: primaryTerm, Fields: changed}, true
+}
+
+// normalizeJSON returns a value as decoded from its JSON encoding
+func normalizeJSON(value interface{}) interface{} {
+	if value == nil {
+		return nil
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return value
+	}
+	var decoded interface{}
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		return value
+	}
+	return decoded
+}
+
+// UpdateDerivedFields applies partial updates of derived fields with a single bulk request
+// Updates of documents changed since they were read are counted as conflicts and not applied
+func (c *Client) UpdateDerivedFields(ctx context.Context, updates []DerivedFieldUpdate) (DerivedFieldUpdateResult, error) {
+	var result DerivedFieldUpdateResult
+	if len(updates) == 0 {
+		return result, nil
+	}
+
+	var body bytes.Buffer
+	for _, update := range updates {
+		action, err := json.Marshal(map[string]interface{}{
+			"update": map[string]interface{}{
+				"_index":          update.Index,
+				"_id":             update.ID,
+				"if_seq_no":       update.SeqNo,
+				"if_primary_term": update.PrimaryTerm,
+			},
+		})
+		if err != nil {
+			return result, fmt.Errorf("failed to encode update action: %w", err)
+		}
+		script, err := json.Marshal(map[string]interface{}{
+			"script": map[string]interface{}{
+				"lang":   "painless",
+				"source": derivedFieldsScript,
+				"params": map[string]interface{}{"fields": update.Fields},
+			},
+		})
+		if err != nil {
+			return result, fmt.Errorf("failed to encode update of %s: %w", update.ID, err)
+		}
+		body.Write(action)
+		body.WriteByte('\n')
+		body.Write(script)
+		body.WriteByte('\n')
+	}
+
+	response, _, err := c.Bulk(ctx, body.Bytes())
+	if err != nil {
+		return result, err
+	}
+	for _, item := range response.Items {
+		itemResult := item.result()
+		switch {
+		case itemResult.Status < 300:
+			result.Updated++
+		case itemResult.Status == http.StatusConflict:
+			result.Conflicts++
+		default:
+			result.Failed++
+			if result.Error == "" {
+				result.Error = itemResult.reason()
+			}
+		}
+	}
+	return result, nil
+}
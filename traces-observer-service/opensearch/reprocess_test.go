@@ -0,0 +1,96 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package opensearch
+
+import (
+	"testing"
+	"time"
+)
+
+func toolSpanSource() map[string]interface{} {
+	return map[string]interface{}{
+		"traceId":   "0af7651916cd43dd8448eb211c80319c",
+		"spanId":    "b7ad6b7169203331",
+		"name":      "search",
+		"startTime": "2026-10-16T10:00:00Z",
+		"attributes": map[string]interface{}{
+			"traceloop.span.kind":   "tool",
+			"traceloop.entity.name": "search",
+		},
+		"resource": map[string]interface{}{"service.name": "support-agent"},
+	}
+}
+
+func TestReprocessUpdate(t *testing.T) {
+	// A span indexed before its tool summary was derived
+	source := toolSpanSource()
+	update, changed := ReprocessUpdate("otel-traces-2026-10-16", "doc-1", 7, 1, source)
+	if !changed {
+		t.Fatal("span without its tool summary is not updated")
+	}
+	if update.Index != "otel-traces-2026-10-16" || update.ID != "doc-1" || update.SeqNo != 7 || update.PrimaryTerm != 1 {
+		t.Errorf("update = %+v, want the index, ID, sequence number and primary term of the hit", update)
+	}
+	invocation, ok := update.Fields[ToolInvocationField].(*ToolInvocation)
+	if !ok || invocation.Name != "search" || invocation.Agent != "support-agent" {
+		t.Fatalf("tool invocation = %#v, want the search tool of support-agent", update.Fields[ToolInvocationField])
+	}
+	if len(update.Fields) != 1 {
+		t.Errorf("updated fields = %v, want only the tool invocation", update.Fields)
+	}
+	if _, ok := source["attributes"].(map[string]interface{})["traceloop.span.kind"]; !ok {
+		t.Error("raw attributes were changed")
+	}
+
+	// A span whose summary is current, as read back from the index
+	current := toolSpanSource()
+	current[ToolInvocationField] = map[string]interface{}{"name": "search", "agent": "support-agent", "failed": false}
+	if update, changed := ReprocessUpdate("otel-traces-2026-10-16", "doc-1", 8, 1, current); changed {
+		t.Errorf("current span updated with %v", update.Fields)
+	}
+
+	// A summary that no longer applies is removed
+	stale := map[string]interface{}{
+		"traceId":           "0af7651916cd43dd8448eb211c80319c",
+		"spanId":            "b7ad6b7169203332",
+		"name":              "chat",
+		"startTime":         "2026-10-16T10:00:00Z",
+		ToolInvocationField: map[string]interface{}{"name": "chat"},
+	}
+	update, changed = ReprocessUpdate("otel-traces-2026-10-16", "doc-2", 3, 1, stale)
+	if !changed {
+		t.Fatal("stale tool summary is kept")
+	}
+	if value, ok := update.Fields[ToolInvocationField]; !ok || value != nil {
+		t.Errorf("tool invocation update = %v, want removal", update.Fields)
+	}
+}
+
+func TestBuildReprocessQuery(t *testing.T) {
+	start := time.Date(2026, 10, 15, 0, 0, 0, 0, time.UTC)
+	query := BuildReprocessQuery(start, start.Add(24*time.Hour))
+	if query["seq_no_primary_term"] != true {
+		t.Error("query does not request sequence numbers")
+	}
+	rangeQuery := query["query"].(map[string]interface{})["range"].(map[string]interface{})["startTime"].(map[string]interface{})
+	if rangeQuery["gte"] != "2026-10-15T00:00:00Z" || rangeQuery["lte"] != "2026-10-16T00:00:00Z" {
+		t.Errorf("range = %v, want the time range", rangeQuery)
+	}
+	if key, fields := sortSignature(query); key != "startTime:asc,traceId:asc,spanId:asc" || fields != 3 {
+		t.Errorf("sort = %q with %d fields, want a unique tie breaker", key, fields)
+	}
+}
@@ -0,0 +1,409 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package opensearch
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RetentionDaysField holds the days the spans of a managed agent are kept, missing for spans following the
+// default retention. The field is set when the span is linked to its agent, so a changed retention applies
+// to the spans ingested afterwards.
+const RetentionDaysField = "retentionDays"
+
+// maxRetentionReportAgents bounds the agents counted by a retention report per retention class
+const maxRetentionReportAgents = 10000
+
+// retentionTaskPollInterval is how often the task of a running purge is checked
+var retentionTaskPollInterval = 5 * time.Second
+
+// DeleteByQueryThrottle paces a delete by query so that it does not starve the searches of the cluster
+type DeleteByQueryThrottle struct {
+	RequestsPerSecond int // Documents deleted per second, unthrottled when zero
+	BatchSize         int // Documents deleted per batch, the default of OpenSearch when zero
+}
+
+// IndexRetention lists the retention classes of the spans of a daily trace index
+type IndexRetention struct {
+	Index   string
+	Day     time.Time
+	Classes []int // Retention days of the spans of the index, 0 for the spans following the default retention
+}
+
+// RetentionPurge deletes the spans of a retention class from the indices in which they expired
+type RetentionPurge struct {
+	RetentionDays int       `json:"retentionDays"` // 0 for the spans following the default retention
+	Cutoff        time.Time `json:"cutoff"`        // Spans of days before the cutoff are expired
+	Indices       []string  `json:"indices"`
+}
+
+// Query matches the spans of the retention class of the purge
+func (p RetentionPurge) Query() map[string]interface{} {
+	if p.RetentionDays == 0 {
+		return map[string]interface{}{
+			"bool": map[string]interface{}{
+				"must_not": []interface{}{
+					map[string]interface{}{"exists": map[string]interface{}{"field": RetentionDaysField}},
+				},
+			},
+		}
+	}
+	return map[string]interface{}{"term": map[string]interface{}{RetentionDaysField: p.RetentionDays}}
+}
+
+// RetentionPlan is what a retention run deletes
+// Indices whose spans all expired are deleted whole, the expired spans of the other indices are deleted by query
+type RetentionPlan struct {
+	DeleteIndices []string
+	Purges        []RetentionPurge
+}
+
+// PlanRetention decides which indices and spans are expired at now
+// defaultDays applies to the spans without a retention of their own and to the rollup indices, zero keeps them forever
+func PlanRetention(now time.Time, defaultDays int, traces []IndexRetention, rollups []string) RetentionPlan {
+	today := now.UTC().Truncate(24 * time.Hour)
+	cutoff := func(days int) time.Time {
+		return today.AddDate(0, 0, -days)
+	}
+	expired := func(class int, day time.Time) bool {
+		days := class
+		if days == 0 {
+			days = defaultDays
+		}
+		return days > 0 && day.Before(cutoff(days))
+	}
+
+	plan := RetentionPlan{}
+	purges := map[int]*RetentionPurge{}
+	for _, index := range traces {
+		var expiredClasses []int
+		for _, class := range index.Classes {
+			if expired(class, index.Day) {
+				expiredClasses = append(expiredClasses, class)
+			}
+		}
+		allExpired := len(expiredClasses) == len(index.Classes)
+		if len(index.Classes) == 0 {
+			// Empty indices follow the default retention
+			allExpired = expired(0, index.Day)
+		}
+		if allExpired {
+			plan.DeleteIndices = append(plan.DeleteIndices, index.Index)
+			continue
+		}
+		for _, class := range expiredClasses {
+			purge, ok := purges[class]
+			if !ok {
+				days := class
+				if days == 0 {
+					days = defaultDays
+				}
+				purge = &RetentionPurge{RetentionDays: class, Cutoff: cutoff(days)}
+				purges[class] = purge
+			}
+			purge.Indices = append(purge.Indices, index.Index)
+		}
+	}
+	for _, index := range rollups {
+		if day, ok := DailyIndexDay(index); ok && expired(0, day) {
+			plan.DeleteIndices = append(plan.DeleteIndices, index)
+		}
+	}
+
+	sort.Strings(plan.DeleteIndices)
+	for _, purge := range purges {
+		sort.Strings(purge.Indices)
+		plan.Purges = append(plan.Purges, *purge)
+	}
+	sort.Slice(plan.Purges, func(i, j int) bool { return plan.Purges[i].RetentionDays < plan.Purges[j].RetentionDays })
+	return plan
+}
+
+// PlanRetention reads the retention classes of the daily indices and plans a retention run at now
+func (c *Client) PlanRetention(ctx context.Context, now time.Time, defaultDays int) (RetentionPlan, error) {
+	traces, err := c.indexRetentions(ctx, now)
+	if err != nil {
+		return RetentionPlan{}, err
+	}
+	var rollups []string
+	if defaultDays > 0 {
+		rollups, err = c.GetIndices(ctx, traceRollupIndexPrefix+"*")
+		if err != nil {
+			return RetentionPlan{}, fmt.Errorf("failed to list rollup indices: %w", err)
+		}
+	}
+	return PlanRetention(now, defaultDays, traces, rollups), nil
+}
+
+// indexRetentions returns the retention classes of the daily trace indices of the days before now
+// Spans are kept at least a day, so the indices of the current and next day are never expired
+func (c *Client) indexRetentions(ctx context.Context, now time.Time) ([]IndexRetention, error) {
+	existing, err := c.GetIndices(ctx, TraceIndexPattern)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list trace indices: %w", err)
+	}
+	today := now.UTC().Truncate(24 * time.Hour)
+	byIndex := map[string]*IndexRetention{}
+	for _, index := range existing {
+		day, ok := DailyIndexDay(index)
+		if ok && IsTraceIndex(index) && day.Before(today) {
+			byIndex[index] = &IndexRetention{Index: index, Day: day}
+		}
+	}
+	if len(byIndex) == 0 {
+		return nil, nil
+	}
+
+	query := map[string]interface{}{
+		"size": 0,
+		"aggs": map[string]interface{}{
+			"indices": map[string]interface{}{
+				"terms": map[string]interface{}{"field": "_index", "size": len(existing)},
+				"aggs": map[string]interface{}{
+					"classes": map[string]interface{}{"terms": map[string]interface{}{"field": RetentionDaysField, "size": 1000}},
+					"default": map[string]interface{}{"missing": map[string]interface{}{"field": RetentionDaysField}},
+				},
+			},
+		},
+	}
+	response, err := c.Search(ctx, []string{TraceIndexPattern}, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read retention classes: %w", err)
+	}
+	var indices struct {
+		Buckets []struct {
+			Key     string `json:"key"`
+			Classes struct {
+				Buckets []struct {
+					Key float64 `json:"key"`
+				} `json:"buckets"`
+			} `json:"classes"`
+			Default struct {
+				DocCount int `json:"doc_count"`
+			} `json:"default"`
+		} `json:"buckets"`
+	}
+	if raw, ok := response.Aggregations["indices"]; ok {
+		if err := json.Unmarshal(raw, &indices); err != nil {
+			return nil, fmt.Errorf("failed to decode retention classes: %w", err)
+		}
+	}
+	for _, bucket := range indices.Buckets {
+		index, ok := byIndex[bucket.Key]
+		if !ok {
+			continue
+		}
+		if bucket.Default.DocCount > 0 {
+			index.Classes = append(index.Classes, 0)
+		}
+		for _, class := range bucket.Classes.Buckets {
+			if days := int(class.Key); days > 0 {
+				index.Classes = append(index.Classes, days)
+			}
+		}
+	}
+
+	retentions := make([]IndexRetention, 0, len(byIndex))
+	for _, index := range byIndex {
+		retentions = append(retentions, *index)
+	}
+	sort.Slice(retentions, func(i, j int) bool { return retentions[i].Index < retentions[j].Index })
+	return retentions, nil
+}
+
+// DeleteByQueryThrottled deletes the documents matching a query with a task of OpenSearch paced by throttle,
+// and waits for the task to complete. The task is polled with requests of their own, so the deletion is not
+// bound by the request timeout. The task keeps running in OpenSearch when ctx ends.
+func (c *Client) DeleteByQueryThrottled(ctx context.Context, indices []string, query map[string]interface{}, throttle DeleteByQueryThrottle) (int, error) {
+	escaped := make([]string, len(indices))
+	for i, index := range indices {
+		escaped[i] = url.PathEscape(index)
+	}
+	params := url.Values{}
+	params.Set("conflicts", "proceed")
+	params.Set("wait_for_completion", "false")
+	if throttle.RequestsPerSecond > 0 {
+		params.Set("requests_per_second", strconv.Itoa(throttle.RequestsPerSecond))
+	}
+	if throttle.BatchSize > 0 {
+		params.Set("scroll_size", strconv.Itoa(throttle.BatchSize))
+	}
+	var started struct {
+		Task string `json:"task"`
+	}
+	path := "/" + strings.Join(escaped, ",") + "/_delete_by_query?" + params.Encode()
+	if err := c.perform(ctx, http.MethodPost, path, map[string]interface{}{"query": query}, &started, "delete by query"); err != nil {
+		return 0, err
+	}
+	if started.Task == "" {
+		return 0, fmt.Errorf("delete by query response has no task")
+	}
+
+	ticker := time.NewTicker(retentionTaskPollInterval)
+	defer ticker.Stop()
+	for {
+		var task struct {
+			Completed bool `json:"completed"`
+			Response  struct {
+				Deleted  int               `json:"deleted"`
+				Failures []json.RawMessage `json:"failures"`
+			} `json:"response"`
+			Error json.RawMessage `json:"error"`
+		}
+		if err := c.perform(ctx, http.MethodGet, "/_tasks/"+url.PathEscape(started.Task), nil, &task, "get task"); err != nil {
+			return 0, err
+		}
+		if task.Completed {
+			if len(task.Error) > 0 {
+				return task.Response.Deleted, fmt.Errorf("delete by query task %s failed: %s", started.Task, task.Error)
+			}
+			if len(task.Response.Failures) > 0 {
+				return task.Response.Deleted, fmt.Errorf("delete by query task %s failed for %d documents: %s", started.Task, len(task.Response.Failures), task.Response.Failures[0])
+			}
+			return task.Response.Deleted, nil
+		}
+		select {
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// RetentionReport is what the next retention run would delete, without deleting anything
+type RetentionReport struct {
+	GeneratedAt          time.Time              `json:"generatedAt"`
+	DefaultRetentionDays int                    `json:"defaultRetentionDays"` // Zero keeps spans without a retention of their own
+	DeleteIndices        []string               `json:"deleteIndices"`        // Deleted whole
+	Purges               []RetentionPurgeReport `json:"purges"`
+	Agents               []AgentRetentionReport `json:"agents"` // Spans of trace indices deleted whole or purged, per agent
+	UnlinkedSpans        int                    `json:"unlinkedSpans"`
+	Spans                int                    `json:"spans"`
+}
+
+// RetentionPurgeReport is a purge of a retention report with the number of spans it deletes
+type RetentionPurgeReport struct {
+	RetentionPurge
+	Spans int `json:"spans"`
+}
+
+// AgentRetentionReport counts the spans of an agent a retention run would delete
+type AgentRetentionReport struct {
+	AgentID string `json:"agentId"`
+	Spans   int    `json:"spans"`
+}
+
+// RetentionReport plans a retention run at now and counts the spans it would delete per agent
+func (c *Client) RetentionReport(ctx context.Context, now time.Time, defaultDays int) (*RetentionReport, error) {
+	plan, err := c.PlanRetention(ctx, now, defaultDays)
+	if err != nil {
+		return nil, err
+	}
+	report := &RetentionReport{
+		GeneratedAt:          now,
+		DefaultRetentionDays: defaultDays,
+		DeleteIndices:        []string{},
+		Purges:               []RetentionPurgeReport{},
+		Agents:               []AgentRetentionReport{},
+	}
+	agents := map[string]int{}
+
+	// Rollup indices hold no spans of agents
+	var traceIndices []string
+	for _, index := range plan.DeleteIndices {
+		report.DeleteIndices = append(report.DeleteIndices, index)
+		if IsTraceIndex(index) {
+			traceIndices = append(traceIndices, index)
+		}
+	}
+	if len(traceIndices) > 0 {
+		spans, unlinked, err := c.countSpansByAgent(ctx, traceIndices, map[string]interface{}{"match_all": map[string]interface{}{}}, agents)
+		if err != nil {
+			return nil, err
+		}
+		report.Spans += spans
+		report.UnlinkedSpans += unlinked
+	}
+	for _, purge := range plan.Purges {
+		spans, unlinked, err := c.countSpansByAgent(ctx, purge.Indices, purge.Query(), agents)
+		if err != nil {
+			return nil, err
+		}
+		report.Purges = append(report.Purges, RetentionPurgeReport{RetentionPurge: purge, Spans: spans})
+		report.Spans += spans
+		report.UnlinkedSpans += unlinked
+	}
+
+	for agentID, spans := range agents {
+		report.Agents = append(report.Agents, AgentRetentionReport{AgentID: agentID, Spans: spans})
+	}
+	sort.Slice(report.Agents, func(i, j int) bool {
+		if report.Agents[i].Spans != report.Agents[j].Spans {
+			return report.Agents[i].Spans > report.Agents[j].Spans
+		}
+		return report.Agents[i].AgentID < report.Agents[j].AgentID
+	})
+	return report, nil
+}
+
+// countSpansByAgent counts the spans of indices matching a query, adding the spans of each agent to agents
+// and returning the total and the spans linked to no agent
+func (c *Client) countSpansByAgent(ctx context.Context, indices []string, query map[string]interface{}, agents map[string]int) (int, int, error) {
+	response, err := c.Search(ctx, indices, map[string]interface{}{
+		"size":             0,
+		"track_total_hits": true,
+		"query":            query,
+		"aggs": map[string]interface{}{
+			"agents":   map[string]interface{}{"terms": map[string]interface{}{"field": AgentIDField, "size": maxRetentionReportAgents}},
+			"unlinked": map[string]interface{}{"missing": map[string]interface{}{"field": AgentIDField}},
+		},
+	})
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to count expired spans: %w", err)
+	}
+	var byAgent struct {
+		Buckets []struct {
+			Key      string `json:"key"`
+			DocCount int    `json:"doc_count"`
+		} `json:"buckets"`
+	}
+	var unlinked struct {
+		DocCount int `json:"doc_count"`
+	}
+	if raw, ok := response.Aggregations["agents"]; ok {
+		if err := json.Unmarshal(raw, &byAgent); err != nil {
+			return 0, 0, fmt.Errorf("failed to decode expired spans of agents: %w", err)
+		}
+	}
+	if raw, ok := response.Aggregations["unlinked"]; ok {
+		if err := json.Unmarshal(raw, &unlinked); err != nil {
+			return 0, 0, fmt.Errorf("failed to decode expired spans without agent: %w", err)
+		}
+	}
+	for _, bucket := range byAgent.Buckets {
+		agents[bucket.Key] += bucket.DocCount
+	}
+	return response.Hits.Total.Value, unlinked.DocCount, nil
+}
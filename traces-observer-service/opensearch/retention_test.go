@@ -0,0 +1,126 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package opensearch
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/config"
+)
+
+func retentionIndex(day string, classes ...int) IndexRetention {
+	parsed, _ := time.Parse(traceIndexDateLayout, day)
+	return IndexRetention{Index: traceIndexPrefix + day, Day: parsed, Classes: classes}
+}
+
+func TestPlanRetention(t *testing.T) {
+	now := time.Date(2025, 11, 20, 15, 0, 0, 0, time.UTC)
+	traces := []IndexRetention{
+		retentionIndex("2025-11-01", 0, 7),      // Every span expired
+		retentionIndex("2025-11-05", 0, 7, 180), // The spans of the 180 day agent are kept
+		retentionIndex("2025-11-12", 7),         // Only spans of the 7 day agent, expired
+		retentionIndex("2025-11-13", 0, 7),      // Nothing expired
+		retentionIndex("2025-11-02"),            // Empty
+	}
+	rollups := []string{traceRollupIndexPrefix + "2025-11-01", traceRollupIndexPrefix + "2025-11-15"}
+
+	plan := PlanRetention(now, 14, traces, rollups)
+	wantDeleted := []string{"otel-trace-rollups-2025-11-01", "otel-traces-2025-11-01", "otel-traces-2025-11-02", "otel-traces-2025-11-12"}
+	if !reflect.DeepEqual(plan.DeleteIndices, wantDeleted) {
+		t.Errorf("DeleteIndices = %v, want %v", plan.DeleteIndices, wantDeleted)
+	}
+	wantPurges := []RetentionPurge{
+		{RetentionDays: 0, Cutoff: time.Date(2025, 11, 6, 0, 0, 0, 0, time.UTC), Indices: []string{"otel-traces-2025-11-05"}},
+		{RetentionDays: 7, Cutoff: time.Date(2025, 11, 13, 0, 0, 0, 0, time.UTC), Indices: []string{"otel-traces-2025-11-05"}},
+	}
+	if !reflect.DeepEqual(plan.Purges, wantPurges) {
+		t.Errorf("Purges = %+v, want %+v", plan.Purges, wantPurges)
+	}
+
+	// Without a default retention only the spans of agents with a retention of their own expire
+	plan = PlanRetention(now, 0, traces, rollups)
+	if want := []string{"otel-traces-2025-11-12"}; !reflect.DeepEqual(plan.DeleteIndices, want) {
+		t.Errorf("DeleteIndices without default = %v, want %v", plan.DeleteIndices, want)
+	}
+	if len(plan.Purges) != 1 || plan.Purges[0].RetentionDays != 7 || len(plan.Purges[0].Indices) != 2 {
+		t.Errorf("Purges without default = %+v, want the 7 day spans of two indices", plan.Purges)
+	}
+}
+
+func TestRetentionPurgeQuery(t *testing.T) {
+	if got := (RetentionPurge{RetentionDays: 7}).Query(); !reflect.DeepEqual(got, map[string]interface{}{"term": map[string]interface{}{RetentionDaysField: 7}}) {
+		t.Errorf("Query() = %v, want a term query of the class", got)
+	}
+	if got := (RetentionPurge{}).Query(); got["bool"] == nil {
+		t.Errorf("Query() = %v, want the spans without a retention of their own", got)
+	}
+}
+
+// fakeDeleteTaskOpenSearch runs delete by query as a task that completes on the second status check
+type fakeDeleteTaskOpenSearch struct {
+	started atomic.Value // Query string of the delete by query request
+	checks  atomic.Int32
+}
+
+func (f *fakeDeleteTaskOpenSearch) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	switch {
+	case r.Method == http.MethodPost && r.URL.Path == "/otel-traces-2025-11-05/_delete_by_query":
+		f.started.Store(r.URL.RawQuery)
+		fmt.Fprint(w, `{"task":"node-1:42"}`)
+	case r.Method == http.MethodGet && r.URL.Path == "/_tasks/node-1:42":
+		if f.checks.Add(1) < 2 {
+			fmt.Fprint(w, `{"completed":false,"task":{"status":{"deleted":10}}}`)
+			return
+		}
+		fmt.Fprint(w, `{"completed":true,"response":{"deleted":25,"failures":[]}}`)
+	default:
+		fmt.Fprint(w, `{"cluster_name":"test","version":{"distribution":"opensearch","number":"2.11.0"}}`)
+	}
+}
+
+func TestDeleteByQueryThrottledWaitsForTask(t *testing.T) {
+	defer func(interval time.Duration) { retentionTaskPollInterval = interval }(retentionTaskPollInterval)
+	retentionTaskPollInterval = time.Millisecond
+
+	fake := &fakeDeleteTaskOpenSearch{}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+	client, err := NewClient(&config.OpenSearchConfig{Address: server.URL, RequestTimeout: 5 * time.Second}, nil)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	purge := RetentionPurge{RetentionDays: 7, Indices: []string{"otel-traces-2025-11-05"}}
+	deleted, err := client.DeleteByQueryThrottled(context.Background(), purge.Indices, purge.Query(), DeleteByQueryThrottle{RequestsPerSecond: 500, BatchSize: 100})
+	if err != nil {
+		t.Fatalf("DeleteByQueryThrottled() error = %v", err)
+	}
+	if deleted != 25 || fake.checks.Load() != 2 {
+		t.Errorf("deleted = %d after %d checks, want 25 after 2", deleted, fake.checks.Load())
+	}
+	if want := "conflicts=proceed&requests_per_second=500&scroll_size=100&wait_for_completion=false"; fake.started.Load() != want {
+		t.Errorf("delete by query parameters = %v, want %s", fake.started.Load(), want)
+	}
+}
@@ -0,0 +1,205 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package opensearch
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// TraceRollupField is the field of root span documents holding the rollup of their whole trace
+// It is kept apart from the usage of the root span itself so that no span is counted twice
+const TraceRollupField = "traceRollup"
+
+// TraceRollup summarizes all spans of a trace on its root span document, so that traces can be
+// filtered, sorted and aggregated without reading their spans
+// The root span is indexed with the rollup of itself, which is replaced once the rest of the trace is indexed.
+type TraceRollup struct {
+	LLMTokenUsage
+	Models              []ModelRollup `json:"models,omitempty"` // Usage broken down per model name
+	HasError            bool          `json:"hasError"`         // Whether any span of the trace has an error, filtered on by the trace list
+	ErrorCount          int           `json:"errorCount"`
+	ToolErrorCount      int           `json:"toolErrorCount"`
+	GuardrailViolations int           `json:"guardrailViolations"` // Failed guardrail checks, filtered on by the trace list
+}
+
+// ModelRollup is the token usage of a single model within a trace rollup
+type ModelRollup struct {
+	Model string `json:"model"`
+	LLMTokenUsage
+}
+
+// BuildTraceRollup rolls up the spans of a trace
+func BuildTraceRollup(spans []Span) *TraceRollup {
+	status := ExtractTraceStatus(spans)
+	rollup := &TraceRollup{
+		HasError:            status.HasError,
+		ErrorCount:          status.ErrorCount,
+		ToolErrorCount:      status.ToolErrorCount,
+		GuardrailViolations: CountGuardrailViolations(spans),
+	}
+	if usage := AggregateTraceTokenUsage(spans); usage != nil {
+		rollup.LLMTokenUsage = usage.LLMTokenUsage
+		for _, model := range usage.Models {
+			rollup.Models = append(rollup.Models, ModelRollup{Model: model.Model, LLMTokenUsage: model.LLMTokenUsage})
+		}
+	}
+	return rollup
+}
+
+// TokenUsage returns the token usage of the rollup without costs, nil when no LLM span reported usage
+func (r *TraceRollup) TokenUsage() *TraceTokenUsage {
+	if r == nil || len(r.Models) == 0 {
+		return nil
+	}
+	usage := &TraceTokenUsage{LLMTokenUsage: r.LLMTokenUsage}
+	for _, model := range r.Models {
+		usage.Models = append(usage.Models, ModelTokenUsage{Model: model.Model, LLMTokenUsage: model.LLMTokenUsage})
+	}
+	return usage
+}
+
+// Status returns the error status of the trace
+func (r *TraceRollup) Status() *TraceStatus {
+	return &TraceStatus{ErrorCount: r.ErrorCount, ToolErrorCount: r.ToolErrorCount, HasError: r.HasError}
+}
+
+// parseTraceRollup reads the rollup of a root span document, nil when the document has none
+func parseTraceRollup(source map[string]interface{}) *TraceRollup {
+	value, ok := source[TraceRollupField].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return nil
+	}
+	var rollup TraceRollup
+	if err := json.Unmarshal(encoded, &rollup); err != nil {
+		return nil
+	}
+	return &rollup
+}
+
+// buildTraceRollupMapping builds the mapping of the trace rollup field
+func buildTraceRollupMapping() map[string]interface{} {
+	usage := map[string]interface{}{}
+	for _, field := range []string{"inputTokens", "outputTokens", "cacheReadInputTokens", "cacheWriteInputTokens", "reasoningTokens", "totalTokens"} {
+		usage[field] = map[string]interface{}{"type": "long"}
+	}
+	models := map[string]interface{}{"model": map[string]interface{}{"type": "keyword"}}
+	properties := map[string]interface{}{
+		"models":              map[string]interface{}{"properties": models},
+		"hasError":            map[string]interface{}{"type": "boolean"},
+		"errorCount":          map[string]interface{}{"type": "integer"},
+		"toolErrorCount":      map[string]interface{}{"type": "integer"},
+		"guardrailViolations": map[string]interface{}{"type": "integer"},
+	}
+	for field, mapping := range usage {
+		models[field] = mapping
+		properties[field] = mapping
+	}
+	return map[string]interface{}{"properties": properties}
+}
+
+// rootDocument is a root span document read with its sequence number
+type rootDocument struct {
+	index       string
+	id          string
+	traceID     string
+	seqNo       int64
+	primaryTerm int64
+	rollup      interface{} // Stored rollup as read back
+}
+
+// RollUpTraces reads all spans of the given traces and rewrites the rollup of their root span documents
+// Only rollups that changed are written, each with the sequence number the root span was read with, so a root
+// span re-delivered meanwhile keeps what ingestion wrote and is counted as a conflict. Traces whose root span
+// is not indexed yet are left out.
+func (c *Client) RollUpTraces(ctx context.Context, indices []string, traceIDs []string) (DerivedFieldUpdateResult, error) {
+	if len(traceIDs) == 0 {
+		return DerivedFieldUpdateResult{}, nil
+	}
+
+	var roots []rootDocument
+	spans := make(map[string][]Span, len(traceIDs))
+	seen := map[string]bool{}
+	var searchAfter []interface{}
+	for {
+		query := BuildSpansForTracesQuery(traceIDs, "", "", searchAfter)
+		query["seq_no_primary_term"] = true
+		response, err := c.Search(ctx, indices, query)
+		if err != nil {
+			return DerivedFieldUpdateResult{}, fmt.Errorf("failed to search trace spans: %w", err)
+		}
+
+		hits := response.Hits.Hits
+		for _, hit := range hits {
+			span := parseSpan(hit.Source)
+			// Every copy of a root span written to more than one daily index gets the rollup
+			if span.ParentSpanID == "" {
+				roots = append(roots, rootDocument{
+					index:       hit.Index,
+					id:          hit.ID,
+					traceID:     span.TraceID,
+					seqNo:       hit.SeqNo,
+					primaryTerm: hit.PrimaryTerm,
+					rollup:      hit.Source[TraceRollupField],
+				})
+			}
+			id := SpanDocumentID(span.TraceID, span.SpanID)
+			if !seen[id] {
+				seen[id] = true
+				spans[span.TraceID] = append(spans[span.TraceID], span)
+			}
+		}
+		if len(hits) < TraceSpansPageSize || len(hits[len(hits)-1].Sort) == 0 {
+			break
+		}
+		searchAfter = hits[len(hits)-1].Sort
+	}
+
+	var result DerivedFieldUpdateResult
+	var updates []DerivedFieldUpdate
+	for _, root := range roots {
+		if root.primaryTerm == 0 {
+			// Without its sequence number the root span cannot be updated without overwriting ingestion
+			result.Failed++
+			continue
+		}
+		rollup := BuildTraceRollup(spans[root.traceID])
+		if reflect.DeepEqual(root.rollup, normalizeJSON(rollup)) {
+			continue
+		}
+		updates = append(updates, DerivedFieldUpdate{
+			Index:       root.index,
+			ID:          root.id,
+			SeqNo:       root.seqNo,
+			PrimaryTerm: root.primaryTerm,
+			Fields:      map[string]interface{}{TraceRollupField: rollup},
+		})
+	}
+
+	updated, err := c.UpdateDerivedFields(ctx, updates)
+	result.Updated = updated.Updated
+	result.Conflicts = updated.Conflicts
+	result.Failed += updated.Failed
+	result.Error = updated.Error
+	return result, err
+}
@@ -0,0 +1,292 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package opensearch
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/config"
+)
+
+// rollupSource creates a span document of trace-1, LLM spans report the model and token counts
+func rollupSource(spanID, parentSpanID, model string, input, output int) map[string]interface{} {
+	attributes := map[string]interface{}{}
+	if model != "" {
+		attributes["traceloop.span.kind"] = "llm"
+		attributes["gen_ai.request.model"] = model
+		attributes["gen_ai.usage.input_tokens"] = input
+		attributes["gen_ai.usage.output_tokens"] = output
+	}
+	source := map[string]interface{}{
+		"traceId":    "trace-1",
+		"spanId":     spanID,
+		"name":       spanID,
+		"startTime":  "2025-11-03T10:00:00Z",
+		"endTime":    "2025-11-03T10:00:01Z",
+		"attributes": attributes,
+	}
+	if parentSpanID != "" {
+		source["parentSpanId"] = parentSpanID
+	}
+	return source
+}
+
+// failedSpan creates a span of a kind that ended with an error
+func failedSpan(id, parent string, kind SpanType) Span {
+	span := usageSpan(id, parent, kind, "", 0, 0)
+	span.AmpAttributes.Status = &SpanStatus{Error: true}
+	return span
+}
+
+func TestBuildTraceRollup(t *testing.T) {
+	tests := []struct {
+		name  string
+		spans []Span
+		want  *TraceRollup
+	}{
+		{
+			name: "usage per model",
+			spans: []Span{
+				usageSpan("root", "", SpanTypeAgent, "", 0, 0),
+				usageSpan("llm-1", "root", SpanTypeLLM, "gpt-4o", 100, 20),
+				usageSpan("llm-2", "root", SpanTypeLLM, "claude-3-5-sonnet", 30, 5),
+				usageSpan("llm-3", "llm-1", SpanTypeLLM, "gpt-4o", 50, 10),
+			},
+			want: &TraceRollup{
+				LLMTokenUsage: LLMTokenUsage{InputTokens: 180, OutputTokens: 35, TotalTokens: 215},
+				Models: []ModelRollup{
+					{Model: "claude-3-5-sonnet", LLMTokenUsage: LLMTokenUsage{InputTokens: 30, OutputTokens: 5, TotalTokens: 35}},
+					{Model: "gpt-4o", LLMTokenUsage: LLMTokenUsage{InputTokens: 150, OutputTokens: 30, TotalTokens: 180}},
+				},
+			},
+		},
+		{
+			name:  "no LLM usage",
+			spans: []Span{usageSpan("root", "", SpanTypeAgent, "", 0, 0)},
+			want:  &TraceRollup{},
+		},
+		{
+			name: "errors of any span",
+			spans: []Span{
+				usageSpan("root", "", SpanTypeAgent, "", 0, 0),
+				failedSpan("tool", "root", SpanTypeTool),
+				failedSpan("chain", "root", SpanTypeChain),
+			},
+			want: &TraceRollup{HasError: true, ErrorCount: 2, ToolErrorCount: 1},
+		},
+		{
+			name: "failed guardrail checks",
+			spans: []Span{
+				parseSpan(guardrailSource("failed", map[string]interface{}{"guardrail.name": "pii", "guardrail.verdict": "fail"})),
+				parseSpan(guardrailSource("passed", map[string]interface{}{"guardrail.name": "toxicity", "guardrail.verdict": "pass"})),
+			},
+			want: &TraceRollup{GuardrailViolations: 1},
+		},
+		{
+			name: "no spans",
+			want: &TraceRollup{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := BuildTraceRollup(tt.spans); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("BuildTraceRollup() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTraceRollupUsageAndStatus(t *testing.T) {
+	spans := []Span{
+		usageSpan("root", "", SpanTypeAgent, "", 0, 0),
+		usageSpan("llm-1", "root", SpanTypeLLM, "gpt-4o", 100, 20),
+		usageSpan("llm-2", "root", SpanTypeLLM, "claude-3-5-sonnet", 30, 5),
+	}
+
+	// The rollup is read back from the stored document
+	stored := map[string]interface{}{TraceRollupField: normalizeJSON(BuildTraceRollup(spans))}
+	rollup := parseTraceRollup(stored)
+	if got, want := rollup.TokenUsage(), AggregateTraceTokenUsage(spans); !reflect.DeepEqual(got, want) {
+		t.Errorf("TokenUsage() = %+v, want %+v", got, want)
+	}
+
+	if got, want := rollup.Status(), ExtractTraceStatus(spans); !reflect.DeepEqual(got, want) {
+		t.Errorf("Status() = %+v, want %+v", got, want)
+	}
+
+	if got := BuildTraceRollup(spans[:1]).TokenUsage(); got != nil {
+		t.Errorf("TokenUsage() without LLM usage = %+v, want nil", got)
+	}
+	if got := parseTraceRollup(map[string]interface{}{}); got != nil {
+		t.Errorf("parseTraceRollup() without a rollup = %+v, want nil", got)
+	}
+}
+
+func TestProcessDocumentRollsUpRootSpans(t *testing.T) {
+	root := rollupSource("root", "", "gpt-4o", 10, 5)
+	span := ProcessDocument(root)
+	rollup, ok := root[TraceRollupField].(*TraceRollup)
+	if !ok || rollup.TotalTokens != 15 {
+		t.Fatalf("%s = %#v, want the rollup of the root span", TraceRollupField, root[TraceRollupField])
+	}
+	// The processed span is not read back from the index, its trace is rolled up from its spans
+	if span.Rollup != nil {
+		t.Errorf("Rollup = %+v, want nil on the processed span", span.Rollup)
+	}
+
+	child := rollupSource("llm", "root", "gpt-4o", 10, 5)
+	ProcessDocument(child)
+	if value, ok := child[TraceRollupField]; ok {
+		t.Errorf("%s = %#v, want no rollup on a child span", TraceRollupField, value)
+	}
+}
+
+// fakeRollupOpenSearch serves the spans of traces and records the bulk updates
+type fakeRollupOpenSearch struct {
+	mu          sync.Mutex
+	hits        []string
+	seqNoQuery  bool
+	updates     []map[string]interface{} // Update actions
+	updateParam []map[string]interface{} // Fields of each update
+}
+
+func (f *fakeRollupOpenSearch) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch {
+	case strings.HasSuffix(r.URL.Path, "/_search"):
+		var body map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		f.seqNoQuery = body["seq_no_primary_term"] == true
+		fmt.Fprintf(w, `{"hits":{"total":{"value":%d},"hits":[%s]}}`, len(f.hits), strings.Join(f.hits, ","))
+	case r.URL.Path == "/_bulk":
+		var items []string
+		scanner := bufio.NewScanner(r.Body)
+		for scanner.Scan() {
+			var action map[string]map[string]interface{}
+			if err := json.Unmarshal(scanner.Bytes(), &action); err != nil || action["update"] == nil {
+				http.Error(w, "expected an update action", http.StatusBadRequest)
+				return
+			}
+			f.updates = append(f.updates, action["update"])
+			scanner.Scan()
+			var script struct {
+				Script struct {
+					Params struct {
+						Fields map[string]interface{} `json:"fields"`
+					} `json:"params"`
+				} `json:"script"`
+			}
+			_ = json.Unmarshal(scanner.Bytes(), &script)
+			f.updateParam = append(f.updateParam, script.Script.Params.Fields)
+			items = append(items, `{"update":{"status":200}}`)
+		}
+		fmt.Fprintf(w, `{"errors":false,"items":[%s]}`, strings.Join(items, ","))
+	default:
+		fmt.Fprint(w, `{"cluster_name":"test","version":{"distribution":"opensearch","number":"2.11.0"}}`)
+	}
+}
+
+// rollupHit encodes a search hit of a span document
+func rollupHit(t *testing.T, index string, seqNo int, source map[string]interface{}) string {
+	t.Helper()
+	encoded, err := json.Marshal(source)
+	if err != nil {
+		t.Fatalf("failed to encode source: %v", err)
+	}
+	id := SpanDocumentID(source["traceId"].(string), source["spanId"].(string))
+	return fmt.Sprintf(`{"_index":%q,"_id":%q,"_seq_no":%d,"_primary_term":1,"_source":%s,"sort":["2025-11-03T10:00:00Z",%q]}`,
+		index, id, seqNo, encoded, source["spanId"])
+}
+
+func TestRollUpTracesUpdatesChangedRootSpans(t *testing.T) {
+	root := rollupSource("root", "", "", 0, 0)
+	root[TraceRollupField] = normalizeJSON(BuildTraceRollup([]Span{parseSpan(root)}))
+	llm := rollupSource("llm", "root", "gpt-4o", 100, 20)
+	// The same trace in a second index, e.g. a root span re-delivered the next day
+	current := rollupSource("root", "", "", 0, 0)
+
+	fake := &fakeRollupOpenSearch{hits: []string{
+		rollupHit(t, "otel-traces-2025-11-03", 7, root),
+		rollupHit(t, "otel-traces-2025-11-03", 8, llm),
+		rollupHit(t, "otel-traces-2025-11-04", 2, current),
+		rollupHit(t, "otel-traces-2025-11-04", 3, llm),
+	}}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+	client, err := NewClient(&config.OpenSearchConfig{Address: server.URL, RequestTimeout: 5 * time.Second}, nil)
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+
+	result, err := client.RollUpTraces(context.Background(), []string{"otel-traces-2025-11-03", "otel-traces-2025-11-04"}, []string{"trace-1"})
+	if err != nil {
+		t.Fatalf("RollUpTraces() error = %v", err)
+	}
+	if result.Updated != 2 {
+		t.Errorf("Updated = %d, want 2", result.Updated)
+	}
+	if !fake.seqNoQuery {
+		t.Error("seq_no_primary_term = false, want the sequence numbers of the root spans")
+	}
+
+	wantUpdates := []map[string]interface{}{
+		{"_index": "otel-traces-2025-11-03", "_id": "trace-1-root", "if_seq_no": float64(7), "if_primary_term": float64(1)},
+		{"_index": "otel-traces-2025-11-04", "_id": "trace-1-root", "if_seq_no": float64(2), "if_primary_term": float64(1)},
+	}
+	if !reflect.DeepEqual(fake.updates, wantUpdates) {
+		t.Errorf("updates = %v, want %v", fake.updates, wantUpdates)
+	}
+	// The LLM span indexed twice is counted once
+	wantRollup := normalizeJSON(BuildTraceRollup([]Span{parseSpan(root), parseSpan(llm)}))
+	for _, fields := range fake.updateParam {
+		if !reflect.DeepEqual(fields[TraceRollupField], wantRollup) {
+			t.Errorf("%s = %v, want %v", TraceRollupField, fields[TraceRollupField], wantRollup)
+		}
+	}
+
+	// Root spans holding the current rollup are left alone
+	fake.hits = []string{
+		rollupHit(t, "otel-traces-2025-11-03", 9, map[string]interface{}{
+			"traceId": "trace-1", "spanId": "root", "startTime": "2025-11-03T10:00:00Z", TraceRollupField: wantRollup,
+		}),
+		rollupHit(t, "otel-traces-2025-11-03", 8, llm),
+	}
+	fake.updates = nil
+	result, err = client.RollUpTraces(context.Background(), []string{"otel-traces-2025-11-03"}, []string{"trace-1"})
+	if err != nil {
+		t.Fatalf("RollUpTraces() error = %v", err)
+	}
+	if result.Updated != 0 || len(fake.updates) != 0 {
+		t.Errorf("Updated = %d with %d updates, want no update of a current rollup", result.Updated, len(fake.updates))
+	}
+}
@@ -0,0 +1,110 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package opensearch
+
+import (
+	"math"
+	"time"
+)
+
+// StreamingField is the document field holding the streaming metrics of an LLM span
+// It is written at ingestion so that time-to-first-token can be aggregated as a number
+const StreamingField = "streaming"
+
+// streamingEventNames lists the span events marking a streamed chunk or the first byte of a response
+var streamingEventNames = map[string]bool{
+	"gen_ai.content.chunk":         true,
+	"gen_ai.choice.chunk":          true,
+	"gen_ai.first_token":           true,
+	"llm.content.completion.chunk": true,
+	"first_token":                  true,
+}
+
+// StreamingMetrics holds the metrics of a streamed LLM response
+type StreamingMetrics struct {
+	TTFTMs          float64  `json:"ttftMs"`                    // Time from the start of the span to the first chunk
+	TokensPerSecond *float64 `json:"tokensPerSecond,omitempty"` // Output tokens over the time from the first chunk to the end of the span
+	Chunks          int      `json:"chunks"`
+}
+
+// parseSpanEvents reads the span events of a document
+func parseSpanEvents(source map[string]interface{}) []SpanEvent {
+	raw, ok := source["events"].([]interface{})
+	if !ok {
+		return nil
+	}
+	events := make([]SpanEvent, 0, len(raw))
+	for _, item := range raw {
+		event, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		spanEvent := SpanEvent{}
+		spanEvent.Name, _ = event["name"].(string)
+		if value, ok := event["time"].(string); ok {
+			spanEvent.Time, _ = time.Parse(time.RFC3339Nano, value)
+		}
+		spanEvent.Attributes, _ = event["attributes"].(map[string]interface{})
+		events = append(events, spanEvent)
+	}
+	return events
+}
+
+// buildStreamingMetrics computes the streaming metrics of an LLM span from its chunk events
+// Returns nil for spans without chunk events, so that non-streamed calls do not report a zero
+func buildStreamingMetrics(span Span, usage *LLMTokenUsage) *StreamingMetrics {
+	var first time.Time
+	chunks := 0
+	for _, event := range span.Events {
+		if !streamingEventNames[event.Name] || event.Time.IsZero() {
+			continue
+		}
+		chunks++
+		if first.IsZero() || event.Time.Before(first) {
+			first = event.Time
+		}
+	}
+	if chunks == 0 || span.StartTime.IsZero() || first.Before(span.StartTime) {
+		return nil
+	}
+
+	metrics := &StreamingMetrics{
+		TTFTMs: roundMillis(first.Sub(span.StartTime)),
+		Chunks: chunks,
+	}
+	if usage != nil && usage.OutputTokens > 0 && span.EndTime.After(first) {
+		tokensPerSecond := math.Round(float64(usage.OutputTokens)/span.EndTime.Sub(first).Seconds()*100) / 100
+		metrics.TokensPerSecond = &tokensPerSecond
+	}
+	return metrics
+}
+
+// roundMillis converts a duration to milliseconds with microsecond precision
+func roundMillis(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
+
+// buildStreamingMapping builds the mapping of the streaming metrics field
+func buildStreamingMapping() map[string]interface{} {
+	return map[string]interface{}{
+		"properties": map[string]interface{}{
+			"ttftMs":          map[string]interface{}{"type": "double"},
+			"tokensPerSecond": map[string]interface{}{"type": "double"},
+			"chunks":          map[string]interface{}{"type": "integer"},
+		},
+	}
+}
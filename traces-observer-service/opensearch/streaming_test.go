@@ -0,0 +1,179 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package opensearch
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+var streamStart = time.Date(2025, 11, 3, 10, 0, 0, 0, time.UTC)
+
+// chunkEvents returns span events named name at the given offsets from streamStart
+func chunkEvents(name string, offsets ...time.Duration) []SpanEvent {
+	events := make([]SpanEvent, 0, len(offsets))
+	for _, offset := range offsets {
+		events = append(events, SpanEvent{Name: name, Time: streamStart.Add(offset)})
+	}
+	return events
+}
+
+func TestBuildStreamingMetrics(t *testing.T) {
+	tokensPerSecond := func(value float64) *float64 { return &value }
+	tests := []struct {
+		name   string
+		events []SpanEvent
+		usage  *LLMTokenUsage
+		want   *StreamingMetrics
+	}{
+		{
+			name:   "chunks",
+			events: chunkEvents("gen_ai.content.chunk", 350*time.Millisecond, 900*time.Millisecond, 1500*time.Millisecond),
+			usage:  &LLMTokenUsage{OutputTokens: 330},
+			want:   &StreamingMetrics{TTFTMs: 350, Chunks: 3, TokensPerSecond: tokensPerSecond(200)},
+		},
+		{
+			name:   "first token event",
+			events: chunkEvents("gen_ai.first_token", 1234567*time.Microsecond),
+			want:   &StreamingMetrics{TTFTMs: 1234.567, Chunks: 1},
+		},
+		{
+			name:   "events out of order",
+			events: append(chunkEvents("llm.content.completion.chunk", 800*time.Millisecond), chunkEvents("gen_ai.choice.chunk", 200*time.Millisecond)...),
+			usage:  &LLMTokenUsage{OutputTokens: 0},
+			want:   &StreamingMetrics{TTFTMs: 200, Chunks: 2},
+		},
+		{
+			name:   "other events are ignored",
+			events: append(chunkEvents("exception", 100*time.Millisecond), chunkEvents("first_token", 400*time.Millisecond)...),
+			want:   &StreamingMetrics{TTFTMs: 400, Chunks: 1},
+		},
+		{name: "no chunk events", events: chunkEvents("exception", 100*time.Millisecond)},
+		{name: "no events"},
+		{name: "chunk before the span started", events: chunkEvents("gen_ai.content.chunk", -time.Millisecond)},
+		{name: "chunk without time", events: []SpanEvent{{Name: "gen_ai.content.chunk"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			span := Span{StartTime: streamStart, EndTime: streamStart.Add(2 * time.Second), Events: tt.events}
+			got := buildStreamingMetrics(span, tt.usage)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("buildStreamingMetrics() = %s, want %s", describeStreaming(got), describeStreaming(tt.want))
+			}
+		})
+	}
+}
+
+func describeStreaming(metrics *StreamingMetrics) string {
+	if metrics == nil {
+		return "nil"
+	}
+	encoded, _ := json.Marshal(metrics)
+	return string(encoded)
+}
+
+// streamedLLMSource returns an LLM span document with the given span events
+func streamedLLMSource(events ...interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"traceId":   "trace-1",
+		"spanId":    "llm",
+		"name":      "chat gpt-4o",
+		"startTime": streamStart.Format(time.RFC3339Nano),
+		"endTime":   streamStart.Add(2 * time.Second).Format(time.RFC3339Nano),
+		"attributes": map[string]interface{}{
+			"traceloop.span.kind":        "llm",
+			"gen_ai.request.model":       "gpt-4o",
+			"gen_ai.usage.output_tokens": float64(100),
+		},
+		"events": events,
+	}
+}
+
+func TestProcessDocumentIndexesStreamingMetrics(t *testing.T) {
+	source := streamedLLMSource(
+		map[string]interface{}{"name": "gen_ai.content.chunk", "time": streamStart.Add(time.Second).Format(time.RFC3339Nano)},
+		map[string]interface{}{"name": "gen_ai.content.chunk", "time": streamStart.Add(1500 * time.Millisecond).Format(time.RFC3339Nano), "attributes": map[string]interface{}{"index": float64(1)}},
+		"not an event",
+	)
+
+	span := ProcessDocument(source)
+
+	if len(span.Events) != 2 || span.Events[1].Attributes["index"] != float64(1) {
+		t.Errorf("events = %+v, want the two chunk events kept", span.Events)
+	}
+	metrics, ok := source[StreamingField].(*StreamingMetrics)
+	if !ok {
+		t.Fatalf("%s = %#v, want the streaming metrics indexed", StreamingField, source[StreamingField])
+	}
+	if metrics.TTFTMs != 1000 || metrics.Chunks != 2 || metrics.TokensPerSecond == nil || *metrics.TokensPerSecond != 100 {
+		t.Errorf("streaming = %s, want 1000ms to the first of 2 chunks at 100 tokens per second", describeStreaming(metrics))
+	}
+	if llm, ok := span.AmpAttributes.Data.(LLMData); !ok || llm.Streaming != metrics {
+		t.Errorf("span data = %#v, want the streaming metrics on the LLM data", span.AmpAttributes.Data)
+	}
+}
+
+func TestProcessDocumentOmitsStreamingMetricsWithoutChunks(t *testing.T) {
+	source := streamedLLMSource()
+	ProcessDocument(source)
+
+	if _, ok := source[StreamingField]; ok {
+		t.Errorf("%s = %v, want no field for a call that was not streamed", StreamingField, source[StreamingField])
+	}
+}
+
+func TestBuildTraceMetricsQueryAggregatesTTFT(t *testing.T) {
+	encoded, err := json.Marshal(BuildTraceMetricsQuery(TraceMetricsParams{
+		StartTime: streamStart,
+		EndTime:   streamStart.Add(time.Hour),
+		Interval:  "5m",
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `"streaming":{"aggs":{"ttft":{"percentiles":{"field":"streaming.ttftMs","percents":[50,95,99]}}},"filter":{"exists":{"field":"streaming.ttftMs"}}}`
+	if !strings.Contains(string(encoded), want) {
+		t.Errorf("query %s does not contain %s", encoded, want)
+	}
+}
+
+func TestParseTraceMetricsTTFT(t *testing.T) {
+	params := TraceMetricsParams{StartTime: streamStart, EndTime: streamStart.Add(2 * time.Minute), Interval: "1m"}
+	response := &SearchResponse{Aggregations: map[string]json.RawMessage{
+		"timeline": json.RawMessage(fmt.Sprintf(`{"buckets": [
+			{"key": %d, "traces": {"doc_count": 2}, "streaming": {"doc_count": 3, "ttft": {"values": {"50.0": 350.12345, "95.0": 900, "99.0": null}}}},
+			{"key": %d, "traces": {"doc_count": 1}, "streaming": {"doc_count": 0, "ttft": {"values": {"50.0": null, "95.0": null, "99.0": null}}}}
+		]}`, streamStart.UnixMilli(), streamStart.Add(time.Minute).UnixMilli())),
+	}}
+
+	result, err := ParseTraceMetrics(response, params, nil)
+	if err != nil {
+		t.Fatalf("ParseTraceMetrics() error = %v", err)
+	}
+	points := result.Series[0].Points
+	if got := points[0].TTFTMs; got == nil || *got != (MillisPercentiles{P50: 350.123, P95: 900}) {
+		t.Errorf("ttft = %+v, want the percentiles rounded to microseconds", got)
+	}
+	// Buckets without streamed calls report no time to first token rather than zero
+	if points[1].TTFTMs != nil {
+		t.Errorf("ttft = %+v, want nil without streamed calls", points[1].TTFTMs)
+	}
+}
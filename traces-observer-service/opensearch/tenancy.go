@@ -0,0 +1,182 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package opensearch
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"sync"
+)
+
+// OrgIDField holds the organization a span or annotation belongs to
+const OrgIDField = "orgId"
+
+// DefaultOrgID is the organization of spans exported without an organization and of documents indexed before
+// organizations were recorded
+const DefaultOrgID = "af779290-c22d-4100-aefd-484d81fff60e"
+
+var (
+	defaultOrgMu sync.RWMutex
+	defaultOrgID = DefaultOrgID
+)
+
+// SetDefaultOrgID configures the organization of documents without one
+func SetDefaultOrgID(orgID string) {
+	defaultOrgMu.Lock()
+	defer defaultOrgMu.Unlock()
+	defaultOrgID = orgID
+}
+
+// GetDefaultOrgID returns the organization of documents without one
+func GetDefaultOrgID() string {
+	defaultOrgMu.RLock()
+	defer defaultOrgMu.RUnlock()
+	return defaultOrgID
+}
+
+type orgScopeKey struct{}
+
+// WithOrgScope binds ctx to an organization, searches run with it only match documents of the organization
+func WithOrgScope(ctx context.Context, orgID string) context.Context {
+	return context.WithValue(ctx, orgScopeKey{}, orgID)
+}
+
+// OrgScope returns the organization ctx is bound to
+func OrgScope(ctx context.Context) (string, bool) {
+	orgID, ok := ctx.Value(orgScopeKey{}).(string)
+	return orgID, ok && orgID != ""
+}
+
+// WithoutOrgScope unbinds ctx from its organization, for searches of indices shared by all organizations
+func WithoutOrgScope(ctx context.Context) context.Context {
+	return context.WithValue(ctx, orgScopeKey{}, "")
+}
+
+// orgFilter matches the documents of an organization, documents without one belong to the default organization
+func orgFilter(orgID string) map[string]interface{} {
+	term := map[string]interface{}{"term": map[string]interface{}{OrgIDField: orgID}}
+	if orgID != GetDefaultOrgID() {
+		return term
+	}
+	return map[string]interface{}{
+		"bool": map[string]interface{}{
+			"should": []map[string]interface{}{
+				term,
+				{"bool": map[string]interface{}{
+					"must_not": map[string]interface{}{"exists": map[string]interface{}{"field": OrgIDField}},
+				}},
+			},
+			"minimum_should_match": 1,
+		},
+	}
+}
+
+// scopeQuery restricts a search to the organization ctx is bound to, the query of the caller is not modified
+func scopeQuery(ctx context.Context, query map[string]interface{}) map[string]interface{} {
+	orgID, ok := OrgScope(ctx)
+	if !ok {
+		return query
+	}
+
+	scoped := make(map[string]interface{}, len(query)+1)
+	for key, value := range query {
+		scoped[key] = value
+	}
+	boolQuery := map[string]interface{}{
+		"filter": []map[string]interface{}{orgFilter(orgID)},
+	}
+	if original, ok := query["query"]; ok {
+		boolQuery["must"] = []interface{}{original}
+	}
+	scoped["query"] = map[string]interface{}{"bool": boolQuery}
+	return scoped
+}
+
+// MatchesOrgScope reports whether a document read outside of a search, such as an archived one, belongs to the
+// organization ctx is bound to
+func MatchesOrgScope(ctx context.Context, source map[string]interface{}) bool {
+	orgID, ok := OrgScope(ctx)
+	if !ok {
+		return true
+	}
+	documentOrgID, _ := source[OrgIDField].(string)
+	if documentOrgID == "" {
+		documentOrgID = GetDefaultOrgID()
+	}
+	return documentOrgID == orgID
+}
+
+// buildOrgMapping maps the organization as a keyword so that it is matched exactly
+func buildOrgMapping() map[string]interface{} {
+	return map[string]interface{}{
+		"properties": map[string]interface{}{
+			OrgIDField: map[string]interface{}{"type": "keyword"},
+		},
+	}
+}
+
+// EnsureOrgIDs maps the organization of existing trace and annotation indices and assigns the default
+// organization to their documents without one
+func (c *Client) EnsureOrgIDs(ctx context.Context) error {
+	indices, err := c.GetIndices(ctx, TraceIndexPattern)
+	if err != nil {
+		return fmt.Errorf("failed to list trace indices: %w", err)
+	}
+	indices = append(indices, AnnotationIndex)
+
+	for _, index := range indices {
+		if err := c.PutMapping(ctx, []string{index}, buildOrgMapping()); err != nil {
+			return fmt.Errorf("failed to map organization of index %s: %w", index, err)
+		}
+		updated, err := c.backfillOrgID(ctx, index, GetDefaultOrgID())
+		if err != nil {
+			return fmt.Errorf("failed to backfill organization of index %s: %w", index, err)
+		}
+		if updated > 0 {
+			slog.InfoContext(ctx, "Assigned the default organization to documents", "documents", updated, "index", index)
+		}
+	}
+	return nil
+}
+
+// backfillOrgID sets the organization of the documents of an index without one
+func (c *Client) backfillOrgID(ctx context.Context, index, orgID string) (int, error) {
+	body := map[string]interface{}{
+		"query": map[string]interface{}{
+			"bool": map[string]interface{}{
+				"must_not": map[string]interface{}{"exists": map[string]interface{}{"field": OrgIDField}},
+			},
+		},
+		"script": map[string]interface{}{
+			"source": "ctx._source." + OrgIDField + " = params.orgId",
+			"lang":   "painless",
+			"params": map[string]interface{}{"orgId": orgID},
+		},
+	}
+	path := "/" + url.PathEscape(index) + "/_update_by_query?conflicts=proceed&refresh=true"
+
+	var response struct {
+		Updated int `json:"updated"`
+	}
+	if err := c.perform(ctx, http.MethodPost, path, body, &response, "backfill organization"); err != nil {
+		return 0, err
+	}
+	return response.Updated, nil
+}
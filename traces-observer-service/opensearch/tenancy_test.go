@@ -0,0 +1,81 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package opensearch
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestScopeQuery(t *testing.T) {
+	query := map[string]interface{}{
+		"query": map[string]interface{}{"term": map[string]interface{}{"traceId": "abc"}},
+		"size":  10,
+	}
+
+	if got := scopeQuery(context.Background(), query); !reflect.DeepEqual(got, query) {
+		t.Errorf("unscoped query was changed: %v", got)
+	}
+	if got := scopeQuery(WithoutOrgScope(WithOrgScope(context.Background(), "org-a")), query); !reflect.DeepEqual(got, query) {
+		t.Errorf("query without org scope was changed: %v", got)
+	}
+
+	scoped := scopeQuery(WithOrgScope(context.Background(), "org-a"), query)
+	encoded, _ := json.Marshal(scoped)
+	want := `{"query":{"bool":{"filter":[{"term":{"orgId":"org-a"}}],"must":[{"term":{"traceId":"abc"}}]}},"size":10}`
+	if string(encoded) != want {
+		t.Errorf("scoped query = %s, want %s", encoded, want)
+	}
+	if _, ok := query["query"].(map[string]interface{})["bool"]; ok {
+		t.Error("the query of the caller was modified")
+	}
+
+	// Documents without an organization belong to the default one
+	encoded, _ = json.Marshal(scopeQuery(WithOrgScope(context.Background(), GetDefaultOrgID()), map[string]interface{}{"size": 0}))
+	want = `{"query":{"bool":{"filter":[{"bool":{"minimum_should_match":1,"should":[{"term":{"orgId":"` + GetDefaultOrgID() +
+		`"}},{"bool":{"must_not":{"exists":{"field":"orgId"}}}}]}}]}},"size":0}`
+	if string(encoded) != want {
+		t.Errorf("default organization query = %s, want %s", encoded, want)
+	}
+}
+
+func TestMatchesOrgScope(t *testing.T) {
+	orgA := WithOrgScope(context.Background(), "org-a")
+	defaultOrg := WithOrgScope(context.Background(), GetDefaultOrgID())
+
+	tests := []struct {
+		name   string
+		ctx    context.Context
+		source map[string]interface{}
+		want   bool
+	}{
+		{name: "unscoped", ctx: context.Background(), source: map[string]interface{}{OrgIDField: "org-b"}, want: true},
+		{name: "same organization", ctx: orgA, source: map[string]interface{}{OrgIDField: "org-a"}, want: true},
+		{name: "other organization", ctx: orgA, source: map[string]interface{}{OrgIDField: "org-b"}, want: false},
+		{name: "no organization", ctx: orgA, source: map[string]interface{}{}, want: false},
+		{name: "no organization in the default organization", ctx: defaultOrg, source: map[string]interface{}{}, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MatchesOrgScope(tt.ctx, tt.source); got != tt.want {
+				t.Errorf("MatchesOrgScope() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,276 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package opensearch
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+)
+
+// maxToolSchemaBytes bounds the parsed parameters of a tool, parameters past it are dropped whole
+const maxToolSchemaBytes = 16 * 1024
+
+// toolSchemaFields lists the fields of a tool object holding the JSON schema of its parameters in priority order:
+// OpenAI and OTEL tools use parameters, LangChain tools args_schema and Anthropic tools input_schema
+var toolSchemaFields = []string{"parameters", "args_schema", "input_schema"}
+
+// crewAIToolArguments and crewAIToolDescription delimit the arguments in the description CrewAI generates for a tool:
+// "Tool Name: search\nTool Arguments: {'query': {'description': '...', 'type': 'str'}}\nTool Description: ..."
+const (
+	crewAIToolArguments   = "Tool Arguments:"
+	crewAIToolDescription = "\nTool Description:"
+)
+
+// toolDefinitionFromJSON builds a tool definition from a tool object
+// Besides flat tools it accepts OpenAI function tools nesting the tool under "function", LangChain tools with
+// an args_schema or only the args properties, and CrewAI tools whose description embeds their arguments.
+// Parameters keeps the schema as JSON, ParameterSchema holds its properties in the order they were declared
+func toolDefinitionFromJSON(element json.RawMessage) ToolDefinition {
+	var fields map[string]json.RawMessage
+	if json.Unmarshal(element, &fields) != nil {
+		return ToolDefinition{}
+	}
+	if function, ok := fields["function"]; ok {
+		var nested map[string]json.RawMessage
+		if json.Unmarshal(function, &nested) == nil && nested != nil {
+			fields = nested
+		}
+	}
+
+	tool := ToolDefinition{}
+	_ = json.Unmarshal(fields["name"], &tool.Name)
+	_ = json.Unmarshal(fields["description"], &tool.Description)
+
+	for _, field := range toolSchemaFields {
+		if schema, ok := fields[field]; ok {
+			tool.Parameters = schemaJSON(schema)
+			tool.ParameterSchema = parseToolSchema(tool.Parameters)
+			break
+		}
+	}
+	if tool.ParameterSchema == nil {
+		if args, ok := fields["args"]; ok {
+			tool.ParameterSchema = parseToolProperties(args, nil)
+		}
+	}
+	if tool.ParameterSchema == nil {
+		applyCrewAIToolSignature(&tool)
+	}
+	return tool
+}
+
+// schemaJSON returns a schema given as an object or as a JSON encoded string as JSON text
+// Objects are compacted rather than re-encoded so their fields keep their order
+func schemaJSON(schema json.RawMessage) string {
+	var text string
+	if json.Unmarshal(schema, &text) == nil {
+		return text
+	}
+	var compacted bytes.Buffer
+	if json.Compact(&compacted, schema) != nil {
+		return string(schema)
+	}
+	return compacted.String()
+}
+
+// parseToolSchema parses the properties of a JSON schema of tool parameters
+// Returns nil for schemas that are not JSON objects or declare no properties
+func parseToolSchema(schema string) *ToolParameters {
+	var fields map[string]json.RawMessage
+	if unmarshalAttribute(schema, &fields) != nil {
+		return nil
+	}
+	properties, ok := fields["properties"]
+	if !ok {
+		return nil
+	}
+	var required []string
+	_ = json.Unmarshal(fields["required"], &required)
+	return parseToolProperties(properties, required)
+}
+
+// parseToolProperties parses the properties object of a schema in declaration order
+// Properties past maxToolSchemaBytes are dropped whole and the parameters marked as truncated
+func parseToolProperties(properties json.RawMessage, required []string) *ToolParameters {
+	isRequired := make(map[string]bool, len(required))
+	for _, name := range required {
+		isRequired[name] = true
+	}
+
+	parameters := &ToolParameters{Properties: []ToolParameter{}}
+	size := 0
+	complete := decodeJSONFields(properties, func(name string, property json.RawMessage) {
+		size += len(name) + len(property)
+		if parameters.Truncated || size > maxToolSchemaBytes {
+			parameters.Truncated = true
+			return
+		}
+		parameters.Properties = append(parameters.Properties, toolParameterFromJSON(name, property, isRequired[name]))
+	})
+	if !complete {
+		if len(parameters.Properties) == 0 {
+			return nil
+		}
+		parameters.Truncated = true
+	}
+	return parameters
+}
+
+// toolParameterFromJSON builds a tool parameter from the schema of a property
+func toolParameterFromJSON(name string, property json.RawMessage, required bool) ToolParameter {
+	parameter := ToolParameter{Name: name, Required: required}
+	var schema map[string]interface{}
+	if json.Unmarshal(property, &schema) != nil {
+		return parameter
+	}
+	parameter.Type = schemaType(schema)
+	parameter.Description, _ = schema["description"].(string)
+	parameter.Default = schema["default"]
+	parameter.Enum, _ = schema["enum"].([]interface{})
+	return parameter
+}
+
+// schemaType describes the type of a property schema
+// Arrays name the type of their items, unions of types such as Optional fields are joined with " | "
+// and references to definitions are named after the definition
+func schemaType(schema map[string]interface{}) string {
+	switch value := schema["type"].(type) {
+	case string:
+		if items, ok := schema["items"].(map[string]interface{}); ok && value == "array" {
+			if itemType := schemaType(items); itemType != "" {
+				return "array<" + itemType + ">"
+			}
+		}
+		return value
+	case []interface{}:
+		var types []string
+		for _, option := range value {
+			if name, ok := option.(string); ok {
+				types = append(types, name)
+			}
+		}
+		return strings.Join(types, " | ")
+	}
+	for _, key := range []string{"anyOf", "oneOf"} {
+		options, ok := schema[key].([]interface{})
+		if !ok {
+			continue
+		}
+		var types []string
+		for _, option := range options {
+			if optionSchema, ok := option.(map[string]interface{}); ok {
+				if optionType := schemaType(optionSchema); optionType != "" {
+					types = append(types, optionType)
+				}
+			}
+		}
+		return strings.Join(types, " | ")
+	}
+	if ref, ok := schema["$ref"].(string); ok {
+		return ref[strings.LastIndex(ref, "/")+1:]
+	}
+	return ""
+}
+
+// applyCrewAIToolSignature takes the parameters of a CrewAI tool from the arguments embedded in its description,
+// leaving the description without the generated name and arguments
+// CrewAI writes the arguments as a Python dict of the argument name to its description and type
+func applyCrewAIToolSignature(tool *ToolDefinition) {
+	prefix, rest, found := strings.Cut(tool.Description, crewAIToolArguments)
+	if !found {
+		return
+	}
+	arguments, description, _ := strings.Cut(rest, crewAIToolDescription)
+	arguments = strings.TrimSpace(arguments)
+	if !json.Valid([]byte(arguments)) {
+		converted, ok := pythonLiteralToJSON(arguments)
+		if !ok {
+			return
+		}
+		arguments = converted
+	}
+	parameters := parseToolProperties(json.RawMessage(arguments), nil)
+	if parameters == nil {
+		return
+	}
+
+	tool.Parameters = arguments
+	tool.ParameterSchema = parameters
+	tool.Description = strings.TrimSpace(description)
+	if name, found := strings.CutPrefix(strings.TrimSpace(prefix), "Tool Name:"); found && tool.Name == "" {
+		tool.Name = strings.TrimSpace(name)
+	}
+}
+
+// pythonLiteralToJSON converts the repr of a Python dict or list of literals to JSON
+// Returns false for values holding anything but strings, numbers, booleans and None
+func pythonLiteralToJSON(value string) (string, bool) {
+	var converted strings.Builder
+	for i := 0; i < len(value); {
+		c := value[i]
+		switch {
+		case c == '\'' || c == '"':
+			var text strings.Builder
+			j := i + 1
+			for ; j < len(value) && value[j] != c; j++ {
+				if value[j] != '\\' || j+1 == len(value) {
+					text.WriteByte(value[j])
+					continue
+				}
+				j++
+				switch value[j] {
+				case 'n':
+					text.WriteByte('\n')
+				case 't':
+					text.WriteByte('\t')
+				case '\\', '\'', '"':
+					text.WriteByte(value[j])
+				default:
+					text.WriteByte('\\')
+					text.WriteByte(value[j])
+				}
+			}
+			if j == len(value) {
+				return "", false
+			}
+			quoted, _ := json.Marshal(text.String())
+			converted.Write(quoted)
+			i = j + 1
+		case c == '_' || c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z':
+			j := i
+			for j < len(value) && (value[j] == '_' || value[j] >= 'A' && value[j] <= 'Z' || value[j] >= 'a' && value[j] <= 'z' || value[j] >= '0' && value[j] <= '9') {
+				j++
+			}
+			switch value[i:j] {
+			case "None":
+				converted.WriteString("null")
+			case "True":
+				converted.WriteString("true")
+			case "False":
+				converted.WriteString("false")
+			default:
+				return "", false
+			}
+			i = j
+		default:
+			converted.WriteByte(c)
+			i++
+		}
+	}
+	return converted.String(), json.Valid([]byte(converted.String()))
+}
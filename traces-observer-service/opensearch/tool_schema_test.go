@@ -0,0 +1,153 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package opensearch
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseToolsJSONParameterSchemas(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		description string
+		parameters  *ToolParameters
+	}{
+		{
+			name: "OpenAI function tool",
+			input: `[{"type": "function", "function": {"name": "search", "description": "Search the web", "parameters": {
+				"type": "object",
+				"properties": {
+					"query": {"type": "string", "description": "Search terms"},
+					"sites": {"type": "array", "items": {"type": "string"}},
+					"limit": {"type": "integer", "default": 10},
+					"mode": {"type": "string", "enum": ["fast", "deep"]}
+				},
+				"required": ["query"]}}}]`,
+			description: "Search the web",
+			parameters: &ToolParameters{Properties: []ToolParameter{
+				{Name: "query", Type: "string", Description: "Search terms", Required: true},
+				{Name: "sites", Type: "array<string>"},
+				{Name: "limit", Type: "integer", Default: float64(10)},
+				{Name: "mode", Type: "string", Enum: []interface{}{"fast", "deep"}},
+			}},
+		},
+		{
+			name: "LangChain args_schema",
+			input: `[{"name": "search", "description": "Search the web", "args_schema": "{\"title\": \"SearchInput\", \"type\": \"object\", ` +
+				`\"properties\": {\"query\": {\"title\": \"Query\", \"type\": \"string\"}, \"site\": {\"anyOf\": [{\"type\": \"string\"}, {\"type\": \"null\"}]}}, ` +
+				`\"required\": [\"query\"]}"}]`,
+			description: "Search the web",
+			parameters: &ToolParameters{Properties: []ToolParameter{
+				{Name: "query", Type: "string", Required: true},
+				{Name: "site", Type: "string | null"},
+			}},
+		},
+		{
+			name:        "LangChain args",
+			input:       `[{"name": "search", "description": "Search the web", "args": {"query": {"type": "string"}, "page": {"type": "integer"}}}]`,
+			description: "Search the web",
+			parameters: &ToolParameters{Properties: []ToolParameter{
+				{Name: "query", Type: "string"},
+				{Name: "page", Type: "integer"},
+			}},
+		},
+		{
+			name: "CrewAI description signature",
+			input: `[{"name": "search", "description": "Tool Name: search\nTool Arguments: {'query': {'description': 'What to look for', 'type': 'str'}, ` +
+				`'page': {'description': None, 'type': 'int'}}\nTool Description: Search the web"}]`,
+			description: "Search the web",
+			parameters: &ToolParameters{Properties: []ToolParameter{
+				{Name: "query", Type: "str", Description: "What to look for"},
+				{Name: "page", Type: "int"},
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tools := parseToolsJSON(tt.input)
+			if len(tools) != 1 || tools[0].Name != "search" {
+				t.Fatalf("parseToolsJSON() = %+v, want the search tool", tools)
+			}
+			if tools[0].Description != tt.description {
+				t.Errorf("description = %q, want %q", tools[0].Description, tt.description)
+			}
+			if !reflect.DeepEqual(tools[0].ParameterSchema, tt.parameters) {
+				t.Errorf("parameter schema = %+v, want %+v", tools[0].ParameterSchema, tt.parameters)
+			}
+		})
+	}
+}
+
+func TestParseToolsJSONKeepsSchemaOrder(t *testing.T) {
+	tools := parseToolsJSON(`[{"name": "lookup", "parameters": {"properties": {"zone": {"type": "string"}, "area": {"type": "string"}}, "type": "object"}}]`)
+	if len(tools) != 1 {
+		t.Fatalf("parseToolsJSON() = %+v, want one tool", tools)
+	}
+	if want := `{"properties":{"zone":{"type":"string"},"area":{"type":"string"}},"type":"object"}`; tools[0].Parameters != want {
+		t.Errorf("parameters = %s, want %s", tools[0].Parameters, want)
+	}
+	if schema := tools[0].ParameterSchema; schema == nil || len(schema.Properties) != 2 || schema.Properties[0].Name != "zone" {
+		t.Errorf("parameter schema = %+v, want zone before area", schema)
+	}
+}
+
+func TestParseToolSchemaTruncatesWholeProperties(t *testing.T) {
+	var properties []string
+	for i := 0; i < 400; i++ {
+		properties = append(properties, fmt.Sprintf(`"field_%d": {"type": "string", "description": %q}`, i, strings.Repeat("x", 80)))
+	}
+	parameters := parseToolSchema(`{"type": "object", "properties": {` + strings.Join(properties, ", ") + `}, "required": ["field_0"]}`)
+
+	if parameters == nil || !parameters.Truncated {
+		t.Fatalf("parseToolSchema() = %+v, want truncated parameters", parameters)
+	}
+	if len(parameters.Properties) == 0 || len(parameters.Properties) >= 400 {
+		t.Fatalf("kept %d of 400 properties, want only the properties within the limit", len(parameters.Properties))
+	}
+	for i, parameter := range parameters.Properties {
+		if parameter.Name != fmt.Sprintf("field_%d", i) || parameter.Type != "string" || len(parameter.Description) != 80 {
+			t.Fatalf("property %d = %+v, want field_%d whole", i, parameter, i)
+		}
+	}
+	if !parameters.Properties[0].Required {
+		t.Error("field_0 is not required")
+	}
+}
+
+func TestPythonLiteralToJSON(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+		ok    bool
+	}{
+		{`{'a': 'it\'s', "b": None, 'c': [True, False, 1.5]}`, `{"a": "it's", "b": null, "c": [true, false, 1.5]}`, true},
+		{`{'quote': 'say "hi"'}`, `{"quote": "say \"hi\""}`, true},
+		{`{'type': <class 'str'>}`, "", false},
+		{`{'open': 'unterminated}`, "", false},
+	}
+	for _, tt := range tests {
+		got, ok := pythonLiteralToJSON(tt.input)
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("pythonLiteralToJSON(%s) = %s, %v, want %s, %v", tt.input, got, ok, tt.want, tt.ok)
+		}
+	}
+}
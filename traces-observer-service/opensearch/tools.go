@@ -0,0 +1,252 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package opensearch
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ToolInvocationField is the document field holding the queryable summary of a tool invocation
+// It is written at ingestion so that tool usage can be aggregated without parsing AmpAttributes
+const ToolInvocationField = "toolInvocation"
+
+// ToolInvocation summarises a tool span for aggregation
+type ToolInvocation struct {
+	Name   string `json:"name"`
+	Agent  string `json:"agent,omitempty"` // Agent that invoked the tool, falling back to the service name
+	Failed bool   `json:"failed"`
+}
+
+// toolAgentAttributes lists the span attributes naming the agent that invoked a tool in priority order
+var toolAgentAttributes = []string{
+	"gen_ai.agent.name",
+	"crewai.agent.role",
+}
+
+// buildToolInvocation builds the tool invocation summary of a processed tool span
+// Returns nil for other span kinds and for tools without a name
+func buildToolInvocation(span Span) *ToolInvocation {
+	if span.AmpAttributes == nil || span.AmpAttributes.Kind != string(SpanTypeTool) {
+		return nil
+	}
+	toolData, ok := span.AmpAttributes.Data.(ToolData)
+	if !ok || toolData.Name == "" {
+		return nil
+	}
+
+	invocation := &ToolInvocation{Name: toolData.Name}
+	if span.AmpAttributes.Status != nil {
+		invocation.Failed = span.AmpAttributes.Status.Error
+	}
+	for _, key := range toolAgentAttributes {
+		if agent, ok := asString(span.Attributes[key]); ok && agent != "" {
+			invocation.Agent = agent
+			break
+		}
+	}
+	if invocation.Agent == "" {
+		if service, ok := asString(span.Resource["service.name"]); ok {
+			invocation.Agent = service
+		}
+	}
+	return invocation
+}
+
+// buildToolInvocationMapping builds the mapping of the tool invocation field
+func buildToolInvocationMapping() map[string]interface{} {
+	return map[string]interface{}{
+		"properties": map[string]interface{}{
+			"name":   map[string]interface{}{"type": "keyword"},
+			"agent":  map[string]interface{}{"type": "keyword"},
+			"failed": map[string]interface{}{"type": "boolean"},
+		},
+	}
+}
+
+// Tool metrics sort orders
+const (
+	ToolSortInvocations = "invocations"
+	ToolSortFailures    = "failures"
+	ToolSortLatency     = "latency"
+)
+
+// MaxToolMetricsTools bounds the number of tools returned by a tool metrics query
+const MaxToolMetricsTools = 500
+
+// maxToolAgents bounds the number of agents listed per tool
+const maxToolAgents = 50
+
+// ToolMetricsParams holds parameters for tool usage queries
+type ToolMetricsParams struct {
+	ComponentUid   string
+	EnvironmentUid string
+	StartTime      time.Time
+	EndTime        time.Time
+	Agent          string // Only invocations by this agent
+	SortBy         string // invocations, failures or latency
+	Limit          int
+}
+
+// ToolMetricsResponse represents tool usage aggregated per tool name
+type ToolMetricsResponse struct {
+	Tools []ToolMetrics `json:"tools"`
+}
+
+// ToolMetrics holds the usage of a single tool
+type ToolMetrics struct {
+	Name               string   `json:"name"`
+	InvocationCount    int      `json:"invocationCount"`
+	FailureCount       int      `json:"failureCount"`
+	FailureRate        float64  `json:"failureRate"`
+	AvgDurationNanos   int64    `json:"avgDurationNanos"`
+	TotalDurationNanos int64    `json:"totalDurationNanos"` // Time spent in the tool across all invocations
+	Agents             []string `json:"agents"`
+}
+
+// BuildToolMetricsQuery builds a terms aggregation over tool invocations with failure counts and latency
+func BuildToolMetricsQuery(params ToolMetricsParams) map[string]interface{} {
+	filters := []map[string]interface{}{
+		{"exists": map[string]interface{}{"field": ToolInvocationField + ".name"}},
+		{
+			"range": map[string]interface{}{
+				"startTime": map[string]interface{}{
+					"gte": params.StartTime.UTC().Format(time.RFC3339Nano),
+					"lte": params.EndTime.UTC().Format(time.RFC3339Nano),
+				},
+			},
+		},
+	}
+	if params.ComponentUid != "" {
+		filters = append(filters, map[string]interface{}{
+			"term": map[string]interface{}{"resource.openchoreo.dev/component-uid": params.ComponentUid},
+		})
+	}
+	if params.EnvironmentUid != "" {
+		filters = append(filters, map[string]interface{}{
+			"term": map[string]interface{}{"resource.openchoreo.dev/environment-uid": params.EnvironmentUid},
+		})
+	}
+	if params.Agent != "" {
+		filters = append(filters, map[string]interface{}{
+			"term": map[string]interface{}{ToolInvocationField + ".agent": params.Agent},
+		})
+	}
+
+	limit := params.Limit
+	if limit <= 0 || limit > MaxToolMetricsTools {
+		limit = MaxToolMetricsTools
+	}
+
+	order := map[string]string{"_count": "desc"}
+	switch params.SortBy {
+	case ToolSortFailures:
+		order = map[string]string{"failures": "desc"}
+	case ToolSortLatency:
+		order = map[string]string{"latency": "desc"}
+	}
+
+	return map[string]interface{}{
+		"query": map[string]interface{}{
+			"bool": map[string]interface{}{
+				"filter": filters,
+			},
+		},
+		"size": 0,
+		"aggs": map[string]interface{}{
+			"tools": map[string]interface{}{
+				"terms": map[string]interface{}{
+					"field": ToolInvocationField + ".name",
+					"size":  limit,
+					"order": order,
+				},
+				"aggs": map[string]interface{}{
+					"failures": map[string]interface{}{
+						"filter": map[string]interface{}{
+							"term": map[string]interface{}{ToolInvocationField + ".failed": true},
+						},
+					},
+					"latency": map[string]interface{}{
+						"avg": map[string]interface{}{"field": "durationInNanos"},
+					},
+					"totalLatency": map[string]interface{}{
+						"sum": map[string]interface{}{"field": "durationInNanos"},
+					},
+					"agents": map[string]interface{}{
+						"terms": map[string]interface{}{
+							"field": ToolInvocationField + ".agent",
+							"size":  maxToolAgents,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// ParseToolMetrics decodes the aggregations of a tool metrics query
+func ParseToolMetrics(response *SearchResponse) (*ToolMetricsResponse, error) {
+	result := &ToolMetricsResponse{Tools: []ToolMetrics{}}
+
+	raw, ok := response.Aggregations["tools"]
+	if !ok {
+		return result, nil
+	}
+
+	var tools struct {
+		Buckets []struct {
+			Key      interface{} `json:"key"`
+			DocCount int         `json:"doc_count"`
+			Failures struct {
+				DocCount int `json:"doc_count"`
+			} `json:"failures"`
+			Latency struct {
+				Value *float64 `json:"value"`
+			} `json:"latency"`
+			TotalLatency sumValue         `json:"totalLatency"`
+			Agents       TermsAggregation `json:"agents"`
+		} `json:"buckets"`
+	}
+	if err := json.Unmarshal(raw, &tools); err != nil {
+		return nil, fmt.Errorf("failed to decode tool metrics: %w", err)
+	}
+
+	for _, bucket := range tools.Buckets {
+		tool := ToolMetrics{
+			Name:               fmt.Sprintf("%v", bucket.Key),
+			InvocationCount:    bucket.DocCount,
+			FailureCount:       bucket.Failures.DocCount,
+			TotalDurationNanos: int64(bucket.TotalLatency.Value),
+			Agents:             make([]string, 0, len(bucket.Agents.Buckets)),
+		}
+		if tool.InvocationCount > 0 {
+			tool.FailureRate = float64(tool.FailureCount) / float64(tool.InvocationCount)
+		}
+		if bucket.Latency.Value != nil {
+			tool.AvgDurationNanos = int64(*bucket.Latency.Value)
+		}
+		for _, agent := range bucket.Agents.Buckets {
+			if name := strings.TrimSpace(fmt.Sprintf("%v", agent.Key)); name != "" {
+				tool.Agents = append(tool.Agents, name)
+			}
+		}
+		result.Tools = append(result.Tools, tool)
+	}
+	return result, nil
+}
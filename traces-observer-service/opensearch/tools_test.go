@@ -0,0 +1,168 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package opensearch
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestProcessDocumentDerivesToolInvocation(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(source map[string]interface{})
+		want  *ToolInvocation
+	}{
+		{
+			name:  "agent falls back to the service name",
+			setup: func(map[string]interface{}) {},
+			want:  &ToolInvocation{Name: "search", Agent: "support-agent"},
+		},
+		{
+			name: "gen_ai agent name",
+			setup: func(source map[string]interface{}) {
+				source["attributes"].(map[string]interface{})["gen_ai.agent.name"] = "triage"
+				source["attributes"].(map[string]interface{})["crewai.agent.role"] = "Researcher"
+			},
+			want: &ToolInvocation{Name: "search", Agent: "triage"},
+		},
+		{
+			name: "crewai agent role",
+			setup: func(source map[string]interface{}) {
+				source["attributes"].(map[string]interface{})["crewai.agent.role"] = "Researcher"
+			},
+			want: &ToolInvocation{Name: "search", Agent: "Researcher"},
+		},
+		{
+			name: "failed tool",
+			setup: func(source map[string]interface{}) {
+				source["status"] = map[string]interface{}{"code": "Error", "message": "timeout"}
+			},
+			want: &ToolInvocation{Name: "search", Agent: "support-agent", Failed: true},
+		},
+		{
+			name: "other span kinds",
+			setup: func(source map[string]interface{}) {
+				source["attributes"].(map[string]interface{})["traceloop.span.kind"] = "task"
+			},
+		},
+		{
+			name: "tool without a name",
+			setup: func(source map[string]interface{}) {
+				source["name"] = ""
+				delete(source["attributes"].(map[string]interface{}), "traceloop.entity.name")
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := toolSpanSource()
+			tt.setup(source)
+			ProcessDocument(source)
+
+			got, _ := source[ToolInvocationField].(*ToolInvocation)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("tool invocation = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildToolMetricsQuery(t *testing.T) {
+	params := ToolMetricsParams{
+		ComponentUid:   "comp-1",
+		EnvironmentUid: "env-1",
+		StartTime:      time.Date(2025, 11, 3, 0, 0, 0, 0, time.UTC),
+		EndTime:        time.Date(2025, 11, 4, 0, 0, 0, 0, time.UTC),
+		Agent:          "support-agent",
+		SortBy:         ToolSortFailures,
+		Limit:          10,
+	}
+	encoded, err := json.Marshal(BuildToolMetricsQuery(params))
+	if err != nil {
+		t.Fatal(err)
+	}
+	query := string(encoded)
+	for _, want := range []string{
+		`{"exists":{"field":"toolInvocation.name"}}`,
+		`{"term":{"resource.openchoreo.dev/component-uid":"comp-1"}}`,
+		`{"term":{"resource.openchoreo.dev/environment-uid":"env-1"}}`,
+		`{"term":{"toolInvocation.agent":"support-agent"}}`,
+		`"gte":"2025-11-03T00:00:00Z"`,
+		`"order":{"failures":"desc"},"size":10`,
+		`{"term":{"toolInvocation.failed":true}}`,
+	} {
+		if !strings.Contains(query, want) {
+			t.Errorf("query %s does not contain %s", query, want)
+		}
+	}
+
+	tests := []struct {
+		sortBy string
+		limit  int
+		want   string
+	}{
+		{sortBy: ToolSortInvocations, limit: 0, want: `"order":{"_count":"desc"},"size":500`},
+		{sortBy: ToolSortLatency, limit: 1000, want: `"order":{"latency":"desc"},"size":500`},
+	}
+	for _, tt := range tests {
+		encoded, _ := json.Marshal(BuildToolMetricsQuery(ToolMetricsParams{SortBy: tt.sortBy, Limit: tt.limit}))
+		if !strings.Contains(string(encoded), tt.want) {
+			t.Errorf("query for sort %s and limit %d = %s, want %s", tt.sortBy, tt.limit, encoded, tt.want)
+		}
+		if strings.Contains(string(encoded), "toolInvocation.agent\":") {
+			t.Errorf("query without an agent filters by agent: %s", encoded)
+		}
+	}
+}
+
+func TestParseToolMetrics(t *testing.T) {
+	response := &SearchResponse{Aggregations: map[string]json.RawMessage{
+		"tools": json.RawMessage(`{"buckets": [
+			{"key": "search", "doc_count": 8,
+				"failures": {"doc_count": 2},
+				"latency": {"value": 1500000},
+				"totalLatency": {"value": 12000000},
+				"agents": {"buckets": [{"key": "support-agent", "doc_count": 6}, {"key": " ", "doc_count": 2}]}},
+			{"key": "lookup", "doc_count": 0,
+				"failures": {"doc_count": 0},
+				"latency": {"value": null},
+				"totalLatency": {"value": 0},
+				"agents": {"buckets": []}}
+		]}`),
+	}}
+
+	result, err := ParseToolMetrics(response)
+	if err != nil {
+		t.Fatalf("ParseToolMetrics() error = %v", err)
+	}
+	want := []ToolMetrics{
+		{Name: "search", InvocationCount: 8, FailureCount: 2, FailureRate: 0.25, AvgDurationNanos: 1500000, TotalDurationNanos: 12000000, Agents: []string{"support-agent"}},
+		{Name: "lookup", Agents: []string{}},
+	}
+	if !reflect.DeepEqual(result.Tools, want) {
+		t.Errorf("tools = %+v, want %+v", result.Tools, want)
+	}
+
+	empty, err := ParseToolMetrics(&SearchResponse{})
+	if err != nil || empty.Tools == nil || len(empty.Tools) != 0 {
+		t.Errorf("ParseToolMetrics() of no aggregations = %+v, %v, want an empty list", empty, err)
+	}
+}
@@ -0,0 +1,154 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package opensearch
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/wso2/ai-agent-management-platform/libs/go-common/tracing"
+)
+
+// maxStatementBytes bounds the query recorded on a span
+const maxStatementBytes = 4096
+
+// statementEndpoints are the APIs whose request body is a query worth recording, bulk bodies are documents
+var statementEndpoints = map[string]bool{
+	"_search":          true,
+	"_count":           true,
+	"_update_by_query": true,
+	"_delete_by_query": true,
+}
+
+// statementKeys keep their values in recorded queries, they name fields and shape results rather than match data
+var statementKeys = map[string]bool{
+	"field":             true,
+	"order":             true,
+	"format":            true,
+	"calendar_interval": true,
+	"fixed_interval":    true,
+	"size":              true,
+	"from":              true,
+	"track_total_hits":  true,
+}
+
+// tracingTransport records a client span for every OpenSearch request, retries included
+type tracingTransport struct {
+	next http.RoundTripper
+}
+
+func (t *tracingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	endpoint := requestEndpoint(req.URL.Path)
+	ctx, span := tracing.Tracer().Start(req.Context(), "opensearch "+endpoint,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemOpensearch,
+			semconv.DBOperationName(endpoint),
+			semconv.HTTPRequestMethodKey.String(req.Method),
+			semconv.ServerAddress(req.URL.Hostname()),
+		),
+	)
+	defer span.End()
+	if span.IsRecording() {
+		if index := requestIndex(req.URL.Path); index != "" {
+			span.SetAttributes(semconv.DBCollectionName(index))
+		}
+		if statement := requestStatement(req, endpoint); statement != "" {
+			span.SetAttributes(semconv.DBQueryText(statement))
+		}
+	}
+
+	res, err := t.next.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+	span.SetAttributes(semconv.HTTPResponseStatusCode(res.StatusCode))
+	if res.StatusCode >= http.StatusBadRequest {
+		span.SetStatus(codes.Error, res.Status)
+	}
+	return res, nil
+}
+
+// requestIndex returns the index or index pattern a request path starts with
+func requestIndex(path string) string {
+	segment, _, _ := strings.Cut(strings.TrimPrefix(path, "/"), "/")
+	if segment == "" || strings.HasPrefix(segment, "_") {
+		return ""
+	}
+	return segment
+}
+
+// requestStatement returns the query of a search request with the values it matches replaced by "?",
+// so that span attributes carry the shape of the query but no trace content or identifiers
+func requestStatement(req *http.Request, endpoint string) string {
+	if !statementEndpoints[endpoint] || req.GetBody == nil {
+		return ""
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return ""
+	}
+	defer body.Close()
+	var query interface{}
+	if err := json.NewDecoder(io.LimitReader(body, 1<<20)).Decode(&query); err != nil {
+		return ""
+	}
+	encoded, err := json.Marshal(sanitizeStatement(query, ""))
+	if err != nil {
+		return ""
+	}
+	if len(encoded) > maxStatementBytes {
+		encoded = encoded[:maxStatementBytes]
+	}
+	return string(encoded)
+}
+
+// sanitizeStatement replaces the scalar values of a decoded query by "?", except for those of statementKeys
+func sanitizeStatement(value interface{}, key string) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		sanitized := make(map[string]interface{}, len(v))
+		for k, field := range v {
+			sanitized[k] = sanitizeStatement(field, k)
+		}
+		return sanitized
+	case []interface{}:
+		sanitized := make([]interface{}, 0, len(v))
+		for _, item := range v {
+			item = sanitizeStatement(item, key)
+			// Lists of terms collapse into a single placeholder
+			if item == "?" && len(sanitized) > 0 && sanitized[len(sanitized)-1] == "?" {
+				continue
+			}
+			sanitized = append(sanitized, item)
+		}
+		return sanitized
+	default:
+		if statementKeys[key] {
+			return v
+		}
+		return "?"
+	}
+}
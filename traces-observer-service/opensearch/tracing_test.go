@@ -0,0 +1,66 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package opensearch
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestRequestStatementHidesMatchedValues(t *testing.T) {
+	body := `{"size":10,"query":{"bool":{"filter":[{"term":{"traceId":"4bf92f3577b34da6"}},` +
+		`{"terms":{"service.name":["checkout","billing"]}},{"range":{"startTime":{"gte":"2025-01-01T00:00:00Z"}}}]}},` +
+		`"sort":[{"startTime":{"order":"desc"}}],"aggs":{"tokens":{"sum":{"field":"attributes.gen_ai.usage.input_tokens"}}}}`
+	// The client sets GetBody, which lets the transport read the body without consuming it
+	req, err := http.NewRequest(http.MethodPost, "http://opensearch:9200/otel-traces-*/_search", strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	statement := requestStatement(req, requestEndpoint(req.URL.Path))
+
+	want := `{"aggs":{"tokens":{"sum":{"field":"attributes.gen_ai.usage.input_tokens"}}},` +
+		`"query":{"bool":{"filter":[{"term":{"traceId":"?"}},{"terms":{"service.name":["?"]}},{"range":{"startTime":{"gte":"?"}}}]}},` +
+		`"size":10,"sort":[{"startTime":{"order":"desc"}}]}`
+	if statement != want {
+		t.Errorf("statement = %s\nwant %s", statement, want)
+	}
+}
+
+func TestRequestStatementSkipsWrites(t *testing.T) {
+	req, err := http.NewRequest(http.MethodPost, "http://opensearch:9200/_bulk", strings.NewReader(`{"index":{"_id":"a"}}`+"\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if statement := requestStatement(req, requestEndpoint(req.URL.Path)); statement != "" {
+		t.Errorf("statement = %q, want none for bulk requests", statement)
+	}
+}
+
+func TestRequestIndex(t *testing.T) {
+	tests := map[string]string{
+		"/otel-traces-2025-01-01/_search": "otel-traces-2025-01-01",
+		"/_bulk":                          "",
+		"/":                               "",
+	}
+	for path, want := range tests {
+		if got := requestIndex(path); got != want {
+			t.Errorf("requestIndex(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package opensearch
+
+import (
+	"sort"
+)
+
+// SyntheticRootSpanID is the span ID of the synthetic root created for traces without a single root span
+const SyntheticRootSpanID = "synthetic-root"
+
+// BuildTraceTree assembles the spans of a trace into a parent/child tree
+// Spans whose parent was not ingested are attached under a synthetic root along with any other top-level spans.
+// Children are sorted by start time. A maxDepth greater than zero limits the depth of the returned tree,
+// with the root at depth 1.
+func BuildTraceTree(spans []Span, maxDepth int) *TraceTreeNode {
+	if len(spans) == 0 {
+		return nil
+	}
+
+	nodes := make(map[string]*TraceTreeNode, len(spans))
+	for i := range spans {
+		nodes[spans[i].SpanID] = newTraceTreeNode(&spans[i])
+	}
+
+	// Link children to their parents
+	var topLevel []*TraceTreeNode
+	hasOrphans := false
+	for i := range spans {
+		node := nodes[spans[i].SpanID]
+		if spans[i].ParentSpanID == "" {
+			topLevel = append(topLevel, node)
+			continue
+		}
+		parent, ok := nodes[spans[i].ParentSpanID]
+		if !ok || parent == node {
+			node.Orphaned = true
+			hasOrphans = true
+			topLevel = append(topLevel, node)
+			continue
+		}
+		parent.Children = append(parent.Children, node)
+	}
+
+	var root *TraceTreeNode
+	if len(topLevel) == 1 && !hasOrphans {
+		root = topLevel[0]
+	} else {
+		root = newSyntheticRootNode(topLevel)
+	}
+
+	sortTraceTree(root)
+	limitTraceTreeDepth(root, maxDepth, 1)
+
+	return root
+}
+
+// newTraceTreeNode creates a tree node from a span
+func newTraceTreeNode(span *Span) *TraceTreeNode {
+	return &TraceTreeNode{
+		SpanID:          span.SpanID,
+		ParentSpanID:    span.ParentSpanID,
+		Name:            span.Name,
+		Service:         span.Service,
+		StartTime:       span.StartTime,
+		EndTime:         span.EndTime,
+		DurationInNanos: span.DurationInNanos,
+		Status:          span.Status,
+		AmpAttributes:   span.AmpAttributes,
+	}
+}
+
+// newSyntheticRootNode creates a root node spanning the given top-level nodes
+func newSyntheticRootNode(children []*TraceTreeNode) *TraceTreeNode {
+	root := &TraceTreeNode{
+		SpanID:    SyntheticRootSpanID,
+		Name:      "root",
+		Synthetic: true,
+		Children:  children,
+	}
+
+	// The synthetic root covers the time range of its children
+	for _, child := range children {
+		if root.StartTime.IsZero() || (!child.StartTime.IsZero() && child.StartTime.Before(root.StartTime)) {
+			root.StartTime = child.StartTime
+		}
+		if child.EndTime.After(root.EndTime) {
+			root.EndTime = child.EndTime
+		}
+	}
+	if !root.StartTime.IsZero() && !root.EndTime.IsZero() {
+		root.DurationInNanos = root.EndTime.Sub(root.StartTime).Nanoseconds()
+	}
+
+	return root
+}
+
+// sortTraceTree sorts the children of every node by start time
+func sortTraceTree(root *TraceTreeNode) {
+	stack := []*TraceTreeNode{root}
+	for len(stack) > 0 {
+		node := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		sort.SliceStable(node.Children, func(i, j int) bool {
+			return node.Children[i].StartTime.Before(node.Children[j].StartTime)
+		})
+		stack = append(stack, node.Children...)
+	}
+}
+
+// limitTraceTreeDepth drops the children of nodes at maxDepth, recording how many were dropped
+func limitTraceTreeDepth(node *TraceTreeNode, maxDepth, depth int) {
+	if maxDepth <= 0 {
+		return
+	}
+	if depth >= maxDepth {
+		node.TruncatedChildren = len(node.Children)
+		node.Children = nil
+		return
+	}
+	for _, child := range node.Children {
+		limitTraceTreeDepth(child, maxDepth, depth+1)
+	}
+}
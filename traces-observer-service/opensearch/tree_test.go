@@ -0,0 +1,131 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package opensearch
+
+import (
+	"strconv"
+	"testing"
+	"time"
+)
+
+// treeSpan creates a span starting the given seconds after the epoch and running for a second
+func treeSpan(id, parent string, start int) Span {
+	startTime := time.Date(2025, 1, 1, 0, 0, start, 0, time.UTC)
+	return Span{SpanID: id, ParentSpanID: parent, Name: id, StartTime: startTime, EndTime: startTime.Add(time.Second)}
+}
+
+// treeShape renders a tree as nested span ids, marking orphans with ! and truncated children with +n
+func treeShape(node *TraceTreeNode) string {
+	shape := node.SpanID
+	if node.Orphaned {
+		shape += "!"
+	}
+	if node.TruncatedChildren > 0 {
+		shape += "+" + strconv.Itoa(node.TruncatedChildren)
+	}
+	if len(node.Children) == 0 {
+		return shape
+	}
+	shape += "("
+	for i, child := range node.Children {
+		if i > 0 {
+			shape += " "
+		}
+		shape += treeShape(child)
+	}
+	return shape + ")"
+}
+
+func TestBuildTraceTree(t *testing.T) {
+	tests := []struct {
+		name     string
+		spans    []Span
+		maxDepth int
+		want     string
+	}{
+		{
+			name:  "children are sorted by start time",
+			spans: []Span{treeSpan("root", "", 0), treeSpan("b", "root", 3), treeSpan("a", "root", 1), treeSpan("a1", "a", 2)},
+			want:  "root(a(a1) b)",
+		},
+		{
+			name:  "orphans are attached under a synthetic root",
+			spans: []Span{treeSpan("root", "", 0), treeSpan("a", "root", 1), treeSpan("lost", "never-ingested", 2), treeSpan("c", "lost", 3)},
+			want:  SyntheticRootSpanID + "(root(a) lost!(c))",
+		},
+		{
+			name:  "a single orphan still gets a synthetic root",
+			spans: []Span{treeSpan("lost", "never-ingested", 0), treeSpan("c", "lost", 1)},
+			want:  SyntheticRootSpanID + "(lost!(c))",
+		},
+		{
+			name:  "a span that is its own parent is an orphan",
+			spans: []Span{treeSpan("root", "", 0), treeSpan("loop", "loop", 1)},
+			want:  SyntheticRootSpanID + "(root loop!)",
+		},
+		{
+			name:  "several top-level spans share a synthetic root",
+			spans: []Span{treeSpan("second", "", 1), treeSpan("first", "", 0)},
+			want:  SyntheticRootSpanID + "(first second)",
+		},
+		{
+			name:     "depth truncates the tree and counts the dropped children",
+			spans:    []Span{treeSpan("root", "", 0), treeSpan("a", "root", 1), treeSpan("a1", "a", 2), treeSpan("a2", "a", 3), treeSpan("b", "root", 4)},
+			maxDepth: 2,
+			want:     "root(a+2 b)",
+		},
+		{
+			name:     "a depth of one keeps only the root",
+			spans:    []Span{treeSpan("root", "", 0), treeSpan("a", "root", 1), treeSpan("b", "root", 2)},
+			maxDepth: 1,
+			want:     "root+2",
+		},
+		{
+			name:     "the synthetic root counts as the first level",
+			spans:    []Span{treeSpan("lost", "never-ingested", 0), treeSpan("c", "lost", 1)},
+			maxDepth: 2,
+			want:     SyntheticRootSpanID + "(lost!+1)",
+		},
+		{
+			name:     "a depth beyond the tree changes nothing",
+			spans:    []Span{treeSpan("root", "", 0), treeSpan("a", "root", 1)},
+			maxDepth: 5,
+			want:     "root(a)",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := treeShape(BuildTraceTree(tt.spans, tt.maxDepth)); got != tt.want {
+				t.Errorf("tree = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildTraceTreeSyntheticRootCoversChildren(t *testing.T) {
+	root := BuildTraceTree([]Span{treeSpan("b", "", 5), treeSpan("a", "missing", 2)}, 0)
+
+	if !root.Synthetic || root.SpanID != SyntheticRootSpanID {
+		t.Fatalf("root = %+v, want a synthetic root", root)
+	}
+	if !root.StartTime.Equal(treeSpan("a", "", 2).StartTime) || root.DurationInNanos != (4*time.Second).Nanoseconds() {
+		t.Errorf("root covers %v for %dns, want the range of its children", root.StartTime, root.DurationInNanos)
+	}
+	if BuildTraceTree(nil, 0) != nil {
+		t.Error("a tree was built for a trace without spans")
+	}
+}
@@ -16,19 +16,50 @@
 
 package opensearch
 
-import "time"
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/pricing"
+)
 
 // TraceQueryParams holds parameters for trace queries
 type TraceQueryParams struct {
-	ComponentUid   string
-	EnvironmentUid string
-	StartTime      string
-	EndTime        string
-	Limit          int
-	Offset         int
-	SortOrder      string
+	ComponentUid          string
+	EnvironmentUid        string
+	StartTime             string
+	EndTime               string
+	Limit                 int
+	Offset                int
+	SortOrder             string
+	SortBy                string // startTime, duration or tokens
+	Framework             string // Framework of the root span (crewai, langchain, ...)
+	AgentName             string
+	Status                string // ok or error
+	MinDurationNanos      int64
+	Model                 string            // Only traces in which this model was used
+	Cursor                *TraceCursor      // Position after the previous page, read with SearchPage
+	TraceIDs              []string          // Restricts the query to these traces when not nil
+	AnnotationLabel       string            // Only traces annotated with this label (thumbs_down, ...)
+	HasGuardrailViolation *bool             // Only traces with (true) or without (false) a failed guardrail check
+	CustomAttributes      map[string]string // Only traces with a span carrying each of these custom attributes
+	AgentID               string            // Only traces with a span linked to this managed agent
 }
 
+// Trace listing sort fields
+const (
+	TraceSortStartTime = "startTime"
+	TraceSortDuration  = "duration"
+	TraceSortTokens    = "tokens"
+)
+
+// Trace listing status filters
+const (
+	TraceStatusOK    = "ok"
+	TraceStatusError = "error"
+)
+
 // TraceByIdAndServiceParams holds parameters for querying by both traceId and componentUid
 type TraceByIdAndServiceParams struct {
 	TraceID        string
@@ -52,30 +83,59 @@ type Span struct {
 	Status          string                 `json:"status,omitempty"`
 	Attributes      map[string]interface{} `json:"attributes,omitempty"`
 	Resource        map[string]interface{} `json:"resource,omitempty"`
+	SessionID       string                 `json:"sessionId,omitempty"`     // Session/conversation the span belongs to
 	AmpAttributes   *AmpAttributes         `json:"ampAttributes,omitempty"` // Custom AMP-specific attributes
+	Truncated       bool                   `json:"truncated,omitempty"`     // Whether any value was truncated by the size limits
+	OriginalSizes   map[string]int         `json:"originalSizes,omitempty"` // Original size in bytes of each truncated value
+	Redactions      int                    `json:"redactions,omitempty"`    // Number of sensitive values redacted from the span
+	Events          []SpanEvent            `json:"events,omitempty"`        // OTel span events, such as exceptions and streamed chunks
+	HasMultimodal   bool                   `json:"hasMultimodal,omitempty"` // Whether media payloads were replaced by placeholders
+	OrgID           string                 `json:"orgId,omitempty"`         // Organization the span was exported for
+	IngestedAt      time.Time              `json:"-"`                       // Time the span was received, zero for spans indexed before it was recorded
+	Rollup          *TraceRollup           `json:"-"`                       // Rollup of the trace stored on a root span, nil for other spans and older documents
+}
+
+// SpanEvent is an OTel span event
+type SpanEvent struct {
+	Name       string                 `json:"name"`
+	Time       time.Time              `json:"time"`
+	Attributes map[string]interface{} `json:"attributes,omitempty"`
 }
 
 // AmpAttributes holds custom attributes added by the AMP platform
 type AmpAttributes struct {
-	Kind   string      `json:"kind"`             // Semantic span kind: llm, tool, embedding, retriever, rerank, agent, task, unknown
+	Kind   string      `json:"kind"`             // Semantic span kind: llm, tool, embedding, retriever, rerank, agent, task, guardrail, unknown
 	Input  interface{} `json:"input,omitempty"`  // Input data (type varies by kind)
 	Output interface{} `json:"output,omitempty"` // Output data (type varies by kind)
 	Status *SpanStatus `json:"status,omitempty"` // Execution status with error information
+	Error  *ErrorData  `json:"error,omitempty"`  // Error details from the span status and exception events
 	Data   interface{} `json:"data,omitempty"`   // Kind-specific data: *LLMData, *ToolData, *EmbeddingData, *RetrieverData, etc.
 }
 
+// ErrorData contains the details of a span failure
+type ErrorData struct {
+	Type       string `json:"type,omitempty"`       // Exception type (from exception.type)
+	Message    string `json:"message,omitempty"`    // Exception or span status message
+	Stacktrace string `json:"stacktrace,omitempty"` // Exception stacktrace, truncated to MaxStacktraceLength bytes
+}
+
 // LLMData contains LLM-specific span information
 type LLMData struct {
-	Tools       []ToolDefinition `json:"tools,omitempty"`       // Available tools/functions
-	Model       string           `json:"model,omitempty"`       // Model name (gen_ai.response.model or gen_ai.request.model)
-	Vendor      string           `json:"vendor,omitempty"`      // LLM vendor/provider (gen_ai.system)
-	Temperature *float64         `json:"temperature,omitempty"` // Temperature parameter
-	TokenUsage  *LLMTokenUsage   `json:"tokenUsage,omitempty"`  // Token usage details
+	Tools       []ToolDefinition  `json:"tools,omitempty"`       // Available tools/functions
+	Model       string            `json:"model,omitempty"`       // Model name (gen_ai.response.model or gen_ai.request.model)
+	Vendor      string            `json:"vendor,omitempty"`      // LLM vendor/provider (gen_ai.system)
+	Temperature *float64          `json:"temperature,omitempty"` // Temperature parameter
+	TokenUsage  *LLMTokenUsage    `json:"tokenUsage,omitempty"`  // Token usage details
+	Streaming   *StreamingMetrics `json:"streaming,omitempty"`   // Time-to-first-token of streamed responses
+	Messages    []PromptMessage   `json:"messages,omitempty"`    // Conversation reconstructed from gen_ai message and choice events
 }
 
 // ToolData contains tool execution span information
 type ToolData struct {
-	Name string `json:"name,omitempty"` // Tool/function name
+	Name      string `json:"name,omitempty"`      // Tool/function name
+	Attempts  int    `json:"attempts,omitempty"`  // Number of attempts made to run the tool (from crewai.tool.attempts)
+	FromCache bool   `json:"fromCache,omitempty"` // Whether the result was served from the tool cache (from crewai.tool.from_cache)
+	Delegatee string `json:"delegatee,omitempty"` // Role of the coworker receiving delegated work (CrewAI delegation tools)
 }
 
 // EmbeddingData contains embedding generation span information
@@ -92,6 +152,20 @@ type RetrieverData struct {
 	TopK     int    `json:"topK,omitempty"`     // Number of top results requested
 }
 
+// Guardrail verdicts
+const (
+	GuardrailVerdictPass = "pass"
+	GuardrailVerdictFail = "fail"
+)
+
+// GuardrailData contains guardrail check span information
+type GuardrailData struct {
+	Name            string   `json:"name,omitempty"`            // Guardrail name (from guardrail.name)
+	Verdict         string   `json:"verdict,omitempty"`         // pass or fail, empty when the span does not report one
+	TriggeredRules  []string `json:"triggeredRules,omitempty"`  // Rules that matched the checked content
+	BlockedCategory string   `json:"blockedCategory,omitempty"` // Content category that was blocked (e.g., hate, pii)
+}
+
 // AgentData contains agent execution span information
 type AgentData struct {
 	Name         string           `json:"name,omitempty"`         // Agent name (from gen_ai.agent.name)
@@ -110,6 +184,15 @@ type CrewAITaskData struct {
 	Tools       []ToolDefinition `json:"tools,omitempty"`       // Available tools for the task (from crewai.task.tools)
 }
 
+// CrewAIFlowData contains CrewAI Flow method span information
+type CrewAIFlowData struct {
+	Name       string `json:"name,omitempty"`       // Flow name (from crewai.flow.name)
+	MethodName string `json:"methodName,omitempty"` // Flow method name (from crewai.flow.method_name)
+	MethodType string `json:"methodType,omitempty"` // Decorator type: start, listen or router (from crewai.flow.method_type)
+	Trigger    string `json:"trigger,omitempty"`    // Method or route that triggered a listener (from crewai.flow.method.trigger)
+	Route      string `json:"route,omitempty"`      // Routing decision taken by a router method
+}
+
 // SpanStatus represents the execution status of a span
 type SpanStatus struct {
 	Error     bool   `json:"error"`               // Whether the span has an error
@@ -118,10 +201,12 @@ type SpanStatus struct {
 
 // LLMTokenUsage represents token usage for a single LLM span
 type LLMTokenUsage struct {
-	InputTokens          int `json:"inputTokens"`
-	OutputTokens         int `json:"outputTokens"`
-	CacheReadInputTokens int `json:"cacheReadInputTokens,omitempty"`
-	TotalTokens          int `json:"totalTokens"`
+	InputTokens           int `json:"inputTokens"`
+	OutputTokens          int `json:"outputTokens"`
+	CacheReadInputTokens  int `json:"cacheReadInputTokens,omitempty"`
+	CacheWriteInputTokens int `json:"cacheWriteInputTokens,omitempty"` // Input tokens written to the prompt cache
+	ReasoningTokens       int `json:"reasoningTokens,omitempty"`       // Reasoning/thinking tokens, included in OutputTokens
+	TotalTokens           int `json:"totalTokens"`
 }
 
 // PromptMessage represents a single message in a conversation
@@ -140,17 +225,98 @@ type ToolCall struct {
 
 // ToolDefinition represents a tool/function available to the LLM
 type ToolDefinition struct {
-	Name        string `json:"name"`                  // Function name
-	Description string `json:"description,omitempty"` // Function description
-	Parameters  string `json:"parameters,omitempty"`  // JSON schema of parameters
+	Name            string          `json:"name"`                      // Function name
+	Description     string          `json:"description,omitempty"`     // Function description
+	Parameters      string          `json:"parameters,omitempty"`      // JSON schema of parameters
+	ParameterSchema *ToolParameters `json:"parameterSchema,omitempty"` // Parameters parsed from the schema
+}
+
+// ToolParameters is the parsed parameter schema of a tool
+type ToolParameters struct {
+	Properties []ToolParameter `json:"properties"`          // Parameters in the order the schema declares them
+	Truncated  bool            `json:"truncated,omitempty"` // Parameters past the size limit were dropped
+}
+
+// ToolParameter is a parameter of a tool
+type ToolParameter struct {
+	Name        string        `json:"name"`
+	Type        string        `json:"type,omitempty"` // JSON schema type, e.g. "string", "array<string>" or "string | null"
+	Description string        `json:"description,omitempty"`
+	Required    bool          `json:"required"`
+	Default     interface{}   `json:"default,omitempty"`
+	Enum        []interface{} `json:"enum,omitempty"`
 }
 
 // TraceResponse represents the response for trace queries
 type TraceResponse struct {
-	Spans      []Span       `json:"spans"`
-	TotalCount int          `json:"totalCount"`
-	TokenUsage *TokenUsage  `json:"tokenUsage,omitempty"` // Aggregated token usage from GenAI spans
-	Status     *TraceStatus `json:"status,omitempty"`     // Trace status including error information
+	Spans               []Span             `json:"spans"`
+	TotalCount          int                `json:"totalCount"`
+	TokenUsage          *TokenUsage        `json:"tokenUsage,omitempty"`          // Aggregated token usage from GenAI spans
+	Status              *TraceStatus       `json:"status,omitempty"`              // Trace status including error information
+	AggregatedUsage     *TraceTokenUsage   `json:"aggregatedUsage,omitempty"`     // Token usage rolled up from LLM spans
+	Annotations         *AnnotationSummary `json:"annotations,omitempty"`         // Summary of the human annotations of the trace
+	GuardrailViolations int                `json:"guardrailViolations,omitempty"` // Guardrail checks of the trace that failed
+}
+
+// TraceTreeParams holds parameters for reconstructing a trace tree
+type TraceTreeParams struct {
+	TraceID         string
+	ComponentUid    string
+	EnvironmentUid  string
+	StartTime       string
+	EndTime         string
+	Depth           int  // Maximum depth of the returned tree (0 means unlimited)
+	IncludeArchived bool // Fall back to the archive when the trace is no longer indexed
+}
+
+// TraceTreeNode represents a span and its children in a reconstructed trace tree
+type TraceTreeNode struct {
+	SpanID            string           `json:"spanId"`
+	ParentSpanID      string           `json:"parentSpanId,omitempty"`
+	Name              string           `json:"name"`
+	Service           string           `json:"service,omitempty"`
+	StartTime         time.Time        `json:"startTime"`
+	EndTime           time.Time        `json:"endTime"`
+	DurationInNanos   int64            `json:"durationInNanos"`
+	Status            string           `json:"status,omitempty"`
+	AmpAttributes     *AmpAttributes   `json:"ampAttributes,omitempty"`
+	Synthetic         bool             `json:"synthetic,omitempty"`         // True for the root created to hold orphaned spans
+	Orphaned          bool             `json:"orphaned,omitempty"`          // True when the parent span was not ingested
+	TruncatedChildren int              `json:"truncatedChildren,omitempty"` // Number of children dropped by the depth limit
+	Children          []*TraceTreeNode `json:"children,omitempty"`
+}
+
+// TraceTreeResponse represents the response for trace tree queries
+type TraceTreeResponse struct {
+	TraceID         string             `json:"traceId"`
+	SpanCount       int                `json:"spanCount"`
+	Root            *TraceTreeNode     `json:"root"`
+	TokenUsage      *TokenUsage        `json:"tokenUsage,omitempty"`
+	Status          *TraceStatus       `json:"status,omitempty"`
+	AggregatedUsage *TraceTokenUsage   `json:"aggregatedUsage,omitempty"`
+	Annotations     *AnnotationSummary `json:"annotations,omitempty"`
+	Breakdown       *LatencyBreakdown  `json:"breakdown,omitempty"` // Where the time of each agent went
+	Archived        bool               `json:"archived,omitempty"`  // Whether the spans were read from the archive
+}
+
+// LatencyBreakdown splits the time of a trace between the agents in it
+type LatencyBreakdown struct {
+	Agents []AgentLatency `json:"agents"`
+}
+
+// AgentLatency is the time of an agent span spent in LLM calls, tool calls and the agent itself
+// LLM and tool times are wall-clock times within the agent span, with concurrent calls counted once.
+// Spans outside of any agent are attributed to the root span of the trace.
+type AgentLatency struct {
+	SpanID          string `json:"spanId"`
+	Name            string `json:"name"`
+	AgentName       string `json:"agentName,omitempty"`
+	DurationInNanos int64  `json:"durationInNanos"`
+	SelfTimeInNanos int64  `json:"selfTimeInNanos"` // Time not covered by any child span
+	LLMTimeInNanos  int64  `json:"llmTimeInNanos"`
+	LLMCalls        int    `json:"llmCalls"`
+	ToolTimeInNanos int64  `json:"toolTimeInNanos"`
+	ToolCalls       int    `json:"toolCalls"`
 }
 
 // TraceDetailResponse represents detailed information for a single trace
@@ -164,23 +330,78 @@ type TraceDetailResponse struct {
 
 // TraceOverview represents a single trace overview with root span info
 type TraceOverview struct {
-	TraceID         string       `json:"traceId"`
-	RootSpanID      string       `json:"rootSpanId"`
-	RootSpanName    string       `json:"rootSpanName"`
-	RootSpanKind    string       `json:"rootSpanKind"` // Semantic kind of the root span (llm, tool, etc.)
-	StartTime       string       `json:"startTime"`
-	EndTime         string       `json:"endTime"`
-	DurationInNanos int64        `json:"durationInNanos"` // Total trace duration in nanoseconds
-	SpanCount       int          `json:"spanCount"`
-	TokenUsage      *TokenUsage  `json:"tokenUsage,omitempty"` // Aggregated token usage from GenAI spans
-	Status          *TraceStatus `json:"status,omitempty"`     // Trace status including error information
-	Input           interface{}  `json:"input,omitempty"`      // Input from root span (nil if not found)
-	Output          interface{}  `json:"output,omitempty"`     // Output from root span (nil if not found)
+	TraceID             string             `json:"traceId"`
+	RootSpanID          string             `json:"rootSpanId"`
+	RootSpanName        string             `json:"rootSpanName"`
+	RootSpanKind        string             `json:"rootSpanKind"` // Semantic kind of the root span (llm, tool, etc.)
+	StartTime           string             `json:"startTime"`
+	EndTime             string             `json:"endTime"`
+	DurationInNanos     int64              `json:"durationInNanos"` // Total trace duration in nanoseconds
+	SpanCount           int                `json:"spanCount"`
+	TokenUsage          *TokenUsage        `json:"tokenUsage,omitempty"`          // Aggregated token usage from GenAI spans
+	Status              *TraceStatus       `json:"status,omitempty"`              // Trace status including error information
+	Input               interface{}        `json:"input,omitempty"`               // Input from root span (nil if not found)
+	Output              interface{}        `json:"output,omitempty"`              // Output from root span (nil if not found)
+	AggregatedUsage     *TraceTokenUsage   `json:"aggregatedUsage,omitempty"`     // Token usage rolled up from LLM spans
+	SessionID           string             `json:"sessionId,omitempty"`           // Session/conversation the trace belongs to
+	Annotations         *AnnotationSummary `json:"annotations,omitempty"`         // Summary of the human annotations of the trace
+	GuardrailViolations int                `json:"guardrailViolations,omitempty"` // Guardrail checks of the trace that failed
+	State               string             `json:"state"`                         // open while spans may still arrive, then finalized
+}
+
+// SessionTracesParams holds parameters for querying the traces of a session
+type SessionTracesParams struct {
+	SessionID      string
+	ComponentUid   string
+	EnvironmentUid string
+	StartTime      string
+	EndTime        string
+	Limit          int
+	Cursor         *TraceCursor // Position after the previous page, read with SearchPage
+}
+
+// SessionTracesResponse represents the traces of a session with cumulative usage
+type SessionTracesResponse struct {
+	SessionID       string           `json:"sessionId"`
+	Traces          []TraceOverview  `json:"traces"` // Traces ordered by start time
+	TotalCount      int              `json:"totalCount"`
+	NextCursor      string           `json:"nextCursor,omitempty"`      // Cursor for the next page (empty on the last page)
+	TokenUsage      *TokenUsage      `json:"tokenUsage,omitempty"`      // Cumulative token usage across the session, first page only
+	AggregatedUsage *TraceTokenUsage `json:"aggregatedUsage,omitempty"` // Cumulative LLM usage and cost across the session, first page only
+}
+
+// SpanSearchParams holds parameters for full-text span search
+type SpanSearchParams struct {
+	Query          string
+	ComponentUid   string
+	EnvironmentUid string
+	StartTime      string
+	EndTime        string
+	Limit          int
+	Offset         int
+}
+
+// SpanSearchResult represents a span matching a full-text search
+type SpanSearchResult struct {
+	TraceID    string              `json:"traceId"`
+	SpanID     string              `json:"spanId"`
+	Name       string              `json:"name"`
+	Kind       string              `json:"kind,omitempty"` // Semantic span kind
+	StartTime  time.Time           `json:"startTime"`
+	Highlights map[string][]string `json:"highlights"` // Highlighted snippets keyed by input, output or systemPrompt
+}
+
+// SpanSearchResponse represents the response for full-text span search
+type SpanSearchResponse struct {
+	Results    []SpanSearchResult `json:"results"`
+	TotalCount int                `json:"totalCount"`
 }
 
 // TraceStatus represents the status of a trace
 type TraceStatus struct {
-	ErrorCount int `json:"errorCount"` // Number of spans with errors (0 means no errors)
+	ErrorCount     int  `json:"errorCount"`     // Number of spans with errors (0 means no errors)
+	ToolErrorCount int  `json:"toolErrorCount"` // Number of tool spans with errors
+	HasError       bool `json:"hasError"`       // Whether any span of the trace has an error
 }
 
 // SpanType represents the semantic type/kind of a span
@@ -195,6 +416,7 @@ const (
 	SpanTypeAgent      SpanType = "agent"      // Agent orchestration
 	SpanTypeChain      SpanType = "chain"      // Generic tasks/workflows
 	SpanTypeCrewAITask SpanType = "crewaitask" // CrewAI task operations
+	SpanTypeGuardrail  SpanType = "guardrail"  // Guardrail checks (content filters, schema validators)
 	SpanTypeUnknown    SpanType = "unknown"    // Unknown/unclassified spans
 )
 
@@ -205,10 +427,26 @@ type TokenUsage struct {
 	TotalTokens  int `json:"totalTokens"`
 }
 
+// TraceTokenUsage represents token usage rolled up from all LLM spans of a trace
+// It is kept separate from the span-level usage so framework-level totals are not double counted
+type TraceTokenUsage struct {
+	LLMTokenUsage
+	Cost   *pricing.Cost     `json:"cost"`             // Total cost of priced models (null when no model is priced)
+	Models []ModelTokenUsage `json:"models,omitempty"` // Usage broken down per model name
+}
+
+// ModelTokenUsage represents token usage of a single model within a trace
+type ModelTokenUsage struct {
+	Model string `json:"model"`
+	LLMTokenUsage
+	Cost *pricing.Cost `json:"cost"` // Cost of the usage (null when the model is not priced)
+}
+
 // TraceOverviewResponse represents the response for trace overview queries
 type TraceOverviewResponse struct {
 	Traces     []TraceOverview `json:"traces"`
 	TotalCount int             `json:"totalCount"`
+	NextCursor string          `json:"nextCursor,omitempty"` // Cursor for the next page (empty on the last page)
 }
 
 // SearchResponse represents OpenSearch search response
@@ -218,7 +456,72 @@ type SearchResponse struct {
 			Value int `json:"value"`
 		} `json:"total"`
 		Hits []struct {
-			Source map[string]interface{} `json:"_source"`
+			Index       string                 `json:"_index,omitempty"`
+			ID          string                 `json:"_id,omitempty"`
+			Source      map[string]interface{} `json:"_source"`
+			Sort        []interface{}          `json:"sort,omitempty"`          // Sort values used for search_after pagination
+			Highlight   map[string][]string    `json:"highlight,omitempty"`     // Highlighted snippets per field
+			SeqNo       int64                  `json:"_seq_no,omitempty"`       // Returned when the query sets seq_no_primary_term
+			PrimaryTerm int64                  `json:"_primary_term,omitempty"` // Zero when not returned
 		} `json:"hits"`
 	} `json:"hits"`
+	Aggregations map[string]json.RawMessage `json:"aggregations,omitempty"`
+	PitID        string                     `json:"pit_id,omitempty"`     // Point in time of PIT searches, which may change between pages
+	ScrollID     string                     `json:"_scroll_id,omitempty"` // Scroll context of scroll searches
+}
+
+// DecodeSource decodes the source of a search hit into a typed document
+func DecodeSource(source map[string]interface{}, result interface{}) error {
+	encoded, err := json.Marshal(source)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(encoded, result)
+}
+
+// TermsAggregation represents the result of a terms aggregation
+type TermsAggregation struct {
+	Buckets []struct {
+		Key      interface{} `json:"key"`
+		DocCount int         `json:"doc_count"`
+	} `json:"buckets"`
+}
+
+// TermsAggregationKeys decodes a terms aggregation and returns its bucket keys in order
+func (r *SearchResponse) TermsAggregationKeys(name string) ([]string, error) {
+	raw, ok := r.Aggregations[name]
+	if !ok {
+		return nil, nil
+	}
+
+	var agg TermsAggregation
+	if err := json.Unmarshal(raw, &agg); err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(agg.Buckets))
+	for _, bucket := range agg.Buckets {
+		keys = append(keys, fmt.Sprintf("%v", bucket.Key))
+	}
+	return keys, nil
+}
+
+// BulkResponse represents the response of the bulk API
+type BulkResponse struct {
+	Errors bool               `json:"errors"`
+	Items  []BulkResponseItem `json:"items"`
+}
+
+// BulkResponseItem maps the action name (index, create, ...) to its result
+type BulkResponseItem map[string]BulkItemResult
+
+// BulkItemResult is the result of a single bulk action
+type BulkItemResult struct {
+	Index  string `json:"_index"`
+	ID     string `json:"_id"`
+	Status int    `json:"status"`
+	Error  *struct {
+		Type   string `json:"type"`
+		Reason string `json:"reason"`
+	} `json:"error,omitempty"`
 }
@@ -0,0 +1,395 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package otlp
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+)
+
+// ResourceAttributePrefix is prepended to resource attributes merged into span attributes
+const ResourceAttributePrefix = "resource."
+
+// SpanDocument is a span converted into the trace index document format
+type SpanDocument struct {
+	TraceID   string
+	SpanID    string
+	StartTime time.Time
+	Source    map[string]interface{}
+}
+
+// ConvertResourceSpans converts OTLP resource spans into trace index documents
+// Spans failing validation are skipped and reported in the returned errors
+func ConvertResourceSpans(resourceSpans []*tracepb.ResourceSpans) ([]SpanDocument, []error) {
+	var documents []SpanDocument
+	var rejected []error
+
+	for _, rs := range resourceSpans {
+		resourceAttrs := KeyValuesToMap(rs.GetResource().GetAttributes())
+
+		for _, ss := range rs.GetScopeSpans() {
+			scope := ss.GetScope()
+
+			for _, span := range ss.GetSpans() {
+				if err := validateSpan(span); err != nil {
+					rejected = append(rejected, err)
+					continue
+				}
+				documents = append(documents, convertSpan(span, resourceAttrs, scope))
+			}
+		}
+	}
+
+	return documents, rejected
+}
+
+// convertSpan builds the index document of a single span
+func convertSpan(span *tracepb.Span, resourceAttrs map[string]interface{}, scope *commonpb.InstrumentationScope) SpanDocument {
+	startTime := time.Unix(0, int64(span.GetStartTimeUnixNano())).UTC()
+	endTime := time.Unix(0, int64(span.GetEndTimeUnixNano())).UTC()
+
+	// Span attributes take precedence over resource attributes with the same prefixed key
+	attributes := make(map[string]interface{}, len(span.GetAttributes())+len(resourceAttrs))
+	for key, value := range resourceAttrs {
+		attributes[ResourceAttributePrefix+key] = value
+	}
+	for key, value := range KeyValuesToMap(span.GetAttributes()) {
+		attributes[key] = value
+	}
+
+	traceID := hex.EncodeToString(span.GetTraceId())
+	spanID := hex.EncodeToString(span.GetSpanId())
+
+	source := map[string]interface{}{
+		"traceId":         traceID,
+		"spanId":          spanID,
+		"parentSpanId":    hex.EncodeToString(span.GetParentSpanId()),
+		"name":            span.GetName(),
+		"kind":            span.GetKind().String(),
+		"startTime":       startTime.Format(time.RFC3339Nano),
+		"endTime":         endTime.Format(time.RFC3339Nano),
+		"durationInNanos": jsonNumber(endTime.Sub(startTime).Nanoseconds()),
+		"status": map[string]interface{}{
+			"code":    jsonNumber(int64(span.GetStatus().GetCode())),
+			"message": span.GetStatus().GetMessage(),
+		},
+		"attributes": attributes,
+		"resource":   resourceAttrs,
+	}
+	if span.GetTraceState() != "" {
+		source["traceState"] = span.GetTraceState()
+	}
+	if scope != nil {
+		source["instrumentationScope"] = map[string]interface{}{
+			"name":    scope.GetName(),
+			"version": scope.GetVersion(),
+		}
+	}
+	if events := span.GetEvents(); len(events) > 0 {
+		source["events"] = convertEvents(events)
+	}
+	if links := span.GetLinks(); len(links) > 0 {
+		source["links"] = convertLinks(links)
+	}
+
+	return SpanDocument{
+		TraceID:   traceID,
+		SpanID:    spanID,
+		StartTime: startTime,
+		Source:    source,
+	}
+}
+
+// convertEvents converts span events into documents
+func convertEvents(events []*tracepb.Span_Event) []interface{} {
+	result := make([]interface{}, 0, len(events))
+	for _, event := range events {
+		result = append(result, map[string]interface{}{
+			"name":       event.GetName(),
+			"time":       time.Unix(0, int64(event.GetTimeUnixNano())).UTC().Format(time.RFC3339Nano),
+			"attributes": KeyValuesToMap(event.GetAttributes()),
+		})
+	}
+	return result
+}
+
+// convertLinks converts span links into documents
+func convertLinks(links []*tracepb.Span_Link) []interface{} {
+	result := make([]interface{}, 0, len(links))
+	for _, link := range links {
+		result = append(result, map[string]interface{}{
+			"traceId":    hex.EncodeToString(link.GetTraceId()),
+			"spanId":     hex.EncodeToString(link.GetSpanId()),
+			"attributes": KeyValuesToMap(link.GetAttributes()),
+		})
+	}
+	return result
+}
+
+// validateSpan checks the fields required to index a span
+func validateSpan(span *tracepb.Span) error {
+	if len(span.GetTraceId()) != 16 || isZero(span.GetTraceId()) {
+		return fmt.Errorf("span %q: invalid trace id", span.GetName())
+	}
+	if len(span.GetSpanId()) != 8 || isZero(span.GetSpanId()) {
+		return fmt.Errorf("span %q: invalid span id", span.GetName())
+	}
+	if parent := span.GetParentSpanId(); len(parent) != 0 && len(parent) != 8 {
+		return fmt.Errorf("span %q: invalid parent span id", span.GetName())
+	}
+	if span.GetName() == "" {
+		return fmt.Errorf("span %s: name is required", hex.EncodeToString(span.GetSpanId()))
+	}
+	if span.GetStartTimeUnixNano() == 0 {
+		return fmt.Errorf("span %q: start time is required", span.GetName())
+	}
+	if span.GetEndTimeUnixNano() < span.GetStartTimeUnixNano() {
+		return fmt.Errorf("span %q: end time is before start time", span.GetName())
+	}
+	return nil
+}
+
+// isZero reports whether all bytes of an id are zero
+func isZero(id []byte) bool {
+	for _, b := range id {
+		if b != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// KeyValuesToMap converts OTLP attributes into a map
+func KeyValuesToMap(attributes []*commonpb.KeyValue) map[string]interface{} {
+	result := make(map[string]interface{}, len(attributes))
+	for _, kv := range attributes {
+		result[kv.GetKey()] = AnyValueToInterface(kv.GetValue())
+	}
+	return result
+}
+
+// AnyValueToInterface converts an OTLP AnyValue into its Go representation
+// Arrays become []interface{}, key-value lists become maps and bytes are base64 encoded
+func AnyValueToInterface(value *commonpb.AnyValue) interface{} {
+	if value == nil {
+		return nil
+	}
+
+	switch v := value.GetValue().(type) {
+	case *commonpb.AnyValue_StringValue:
+		return v.StringValue
+	case *commonpb.AnyValue_BoolValue:
+		return v.BoolValue
+	case *commonpb.AnyValue_IntValue:
+		return jsonNumber(v.IntValue)
+	case *commonpb.AnyValue_DoubleValue:
+		return v.DoubleValue
+	case *commonpb.AnyValue_ArrayValue:
+		values := v.ArrayValue.GetValues()
+		result := make([]interface{}, 0, len(values))
+		for _, item := range values {
+			result = append(result, AnyValueToInterface(item))
+		}
+		return result
+	case *commonpb.AnyValue_KvlistValue:
+		return KeyValuesToMap(v.KvlistValue.GetValues())
+	case *commonpb.AnyValue_BytesValue:
+		return base64.StdEncoding.EncodeToString(v.BytesValue)
+	default:
+		return nil
+	}
+}
+
+// maxSafeInteger is the largest integer exactly representable as a float64
+const maxSafeInteger = 1<<53 - 1
+
+// jsonNumber returns integers in the float64 safe range as float64
+// so that processing at ingestion sees the same types as documents read back from OpenSearch
+func jsonNumber(value int64) interface{} {
+	if value > maxSafeInteger || value < -maxSafeInteger {
+		return value
+	}
+	return float64(value)
+}
+
+// DocumentToSpan converts a trace index document back into an OTLP span and its resource attributes
+// Attributes merged from the resource are dropped from the span attributes, they travel with the resource
+func DocumentToSpan(source map[string]interface{}) (*tracepb.Span, map[string]interface{}, error) {
+	traceID, err := decodeHexID(source["traceId"], 16)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid trace id: %w", err)
+	}
+	spanID, err := decodeHexID(source["spanId"], 8)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid span id: %w", err)
+	}
+	parentSpanID, err := decodeHexID(source["parentSpanId"], 8)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid parent span id: %w", err)
+	}
+
+	span := &tracepb.Span{
+		TraceId:           traceID,
+		SpanId:            spanID,
+		ParentSpanId:      parentSpanID,
+		Name:              stringField(source, "name"),
+		TraceState:        stringField(source, "traceState"),
+		StartTimeUnixNano: timeField(source, "startTime"),
+		EndTimeUnixNano:   timeField(source, "endTime"),
+	}
+	if kind, ok := tracepb.Span_SpanKind_value[stringField(source, "kind")]; ok {
+		span.Kind = tracepb.Span_SpanKind(kind)
+	}
+	if status, ok := source["status"].(map[string]interface{}); ok {
+		code, _ := status["code"].(float64)
+		message, _ := status["message"].(string)
+		span.Status = &tracepb.Status{Code: tracepb.Status_StatusCode(code), Message: message}
+	}
+
+	if attributes, ok := source["attributes"].(map[string]interface{}); ok {
+		own := make(map[string]interface{}, len(attributes))
+		for key, value := range attributes {
+			if !strings.HasPrefix(key, ResourceAttributePrefix) {
+				own[key] = value
+			}
+		}
+		span.Attributes = MapToKeyValues(own)
+	}
+
+	if events, ok := source["events"].([]interface{}); ok {
+		for _, raw := range events {
+			event, ok := raw.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			attributes, _ := event["attributes"].(map[string]interface{})
+			span.Events = append(span.Events, &tracepb.Span_Event{
+				Name:         stringField(event, "name"),
+				TimeUnixNano: timeField(event, "time"),
+				Attributes:   MapToKeyValues(attributes),
+			})
+		}
+	}
+	if links, ok := source["links"].([]interface{}); ok {
+		for _, raw := range links {
+			link, ok := raw.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			linkTraceID, traceErr := decodeHexID(link["traceId"], 16)
+			linkSpanID, spanErr := decodeHexID(link["spanId"], 8)
+			if traceErr != nil || spanErr != nil {
+				continue
+			}
+			attributes, _ := link["attributes"].(map[string]interface{})
+			span.Links = append(span.Links, &tracepb.Span_Link{
+				TraceId:    linkTraceID,
+				SpanId:     linkSpanID,
+				Attributes: MapToKeyValues(attributes),
+			})
+		}
+	}
+
+	resource, _ := source["resource"].(map[string]interface{})
+	return span, resource, nil
+}
+
+// decodeHexID decodes a hex encoded id of the given length, empty ids decode to nil
+func decodeHexID(value interface{}, length int) ([]byte, error) {
+	encoded, _ := value.(string)
+	if encoded == "" {
+		return nil, nil
+	}
+	id, err := hex.DecodeString(encoded)
+	if err != nil {
+		return nil, err
+	}
+	if len(id) != length {
+		return nil, fmt.Errorf("expected %d bytes, got %d", length, len(id))
+	}
+	return id, nil
+}
+
+// stringField returns a string field of a document, empty when missing
+func stringField(source map[string]interface{}, key string) string {
+	value, _ := source[key].(string)
+	return value
+}
+
+// timeField returns an RFC 3339 time field of a document in Unix nanoseconds, zero when missing
+func timeField(source map[string]interface{}, key string) uint64 {
+	parsed, err := time.Parse(time.RFC3339Nano, stringField(source, key))
+	if err != nil {
+		return 0
+	}
+	return uint64(parsed.UnixNano())
+}
+
+// MapToKeyValues converts a map into OTLP attributes sorted by key
+func MapToKeyValues(values map[string]interface{}) []*commonpb.KeyValue {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	result := make([]*commonpb.KeyValue, 0, len(values))
+	for _, key := range keys {
+		result = append(result, &commonpb.KeyValue{Key: key, Value: InterfaceToAnyValue(values[key])})
+	}
+	return result
+}
+
+// InterfaceToAnyValue converts a Go value into an OTLP AnyValue, reversing AnyValueToInterface
+// Integral numbers become ints since integers are held as float64 after conversion
+func InterfaceToAnyValue(value interface{}) *commonpb.AnyValue {
+	switch v := value.(type) {
+	case nil:
+		return &commonpb.AnyValue{}
+	case string:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: v}}
+	case bool:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_BoolValue{BoolValue: v}}
+	case int:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_IntValue{IntValue: int64(v)}}
+	case int64:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_IntValue{IntValue: v}}
+	case float64:
+		if v == math.Trunc(v) && math.Abs(v) <= maxSafeInteger {
+			return &commonpb.AnyValue{Value: &commonpb.AnyValue_IntValue{IntValue: int64(v)}}
+		}
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_DoubleValue{DoubleValue: v}}
+	case []interface{}:
+		values := make([]*commonpb.AnyValue, 0, len(v))
+		for _, item := range v {
+			values = append(values, InterfaceToAnyValue(item))
+		}
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_ArrayValue{ArrayValue: &commonpb.ArrayValue{Values: values}}}
+	case map[string]interface{}:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_KvlistValue{KvlistValue: &commonpb.KeyValueList{Values: MapToKeyValues(v)}}}
+	default:
+		return &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: fmt.Sprint(v)}}
+	}
+}
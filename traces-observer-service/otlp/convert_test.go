@@ -0,0 +1,168 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package otlp
+
+import (
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+)
+
+func stringValue(s string) *commonpb.AnyValue {
+	return &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: s}}
+}
+
+func intValue(i int64) *commonpb.AnyValue {
+	return &commonpb.AnyValue{Value: &commonpb.AnyValue_IntValue{IntValue: i}}
+}
+
+func TestAnyValueToInterface(t *testing.T) {
+	tests := []struct {
+		name  string
+		value *commonpb.AnyValue
+		want  interface{}
+	}{
+		{"nil", nil, nil},
+		{"unset", &commonpb.AnyValue{}, nil},
+		{"string", stringValue("gpt-4o"), "gpt-4o"},
+		{"bool", &commonpb.AnyValue{Value: &commonpb.AnyValue_BoolValue{BoolValue: true}}, true},
+		{"int", intValue(42), float64(42)},
+		{"int beyond the float64 safe range", intValue(math.MaxInt64), int64(math.MaxInt64)},
+		{"negative int beyond the float64 safe range", intValue(-(1 << 53)), int64(-(1 << 53))},
+		{"double", &commonpb.AnyValue{Value: &commonpb.AnyValue_DoubleValue{DoubleValue: 0.5}}, 0.5},
+		{"bytes", &commonpb.AnyValue{Value: &commonpb.AnyValue_BytesValue{BytesValue: []byte("hi")}}, "aGk="},
+		{
+			"array",
+			&commonpb.AnyValue{Value: &commonpb.AnyValue_ArrayValue{ArrayValue: &commonpb.ArrayValue{
+				Values: []*commonpb.AnyValue{stringValue("a"), intValue(1)},
+			}}},
+			[]interface{}{"a", float64(1)},
+		},
+		{
+			"empty array",
+			&commonpb.AnyValue{Value: &commonpb.AnyValue_ArrayValue{ArrayValue: &commonpb.ArrayValue{}}},
+			[]interface{}{},
+		},
+		{
+			"nested key-value list",
+			&commonpb.AnyValue{Value: &commonpb.AnyValue_KvlistValue{KvlistValue: &commonpb.KeyValueList{
+				Values: []*commonpb.KeyValue{
+					{Key: "role", Value: stringValue("user")},
+					{Key: "usage", Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_KvlistValue{KvlistValue: &commonpb.KeyValueList{
+						Values: []*commonpb.KeyValue{{Key: "tokens", Value: intValue(7)}},
+					}}}},
+				},
+			}}},
+			map[string]interface{}{"role": "user", "usage": map[string]interface{}{"tokens": float64(7)}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := AnyValueToInterface(tt.value); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("AnyValueToInterface() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAnyValueRoundTrip(t *testing.T) {
+	values := map[string]interface{}{
+		"model":  "gpt-4o",
+		"stream": true,
+		"tokens": float64(12),
+		"ratio":  0.25,
+		"tags":   []interface{}{"a", "b"},
+		"usage":  map[string]interface{}{"input": float64(3)},
+	}
+	if got := KeyValuesToMap(MapToKeyValues(values)); !reflect.DeepEqual(got, values) {
+		t.Errorf("round trip = %#v, want %#v", got, values)
+	}
+}
+
+// validSpan returns a span passing validation
+func validSpan() *tracepb.Span {
+	return &tracepb.Span{
+		TraceId:           []byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16},
+		SpanId:            []byte{1, 2, 3, 4, 5, 6, 7, 8},
+		Name:              "chat",
+		StartTimeUnixNano: 1_730_960_604_000_000_000,
+		EndTimeUnixNano:   1_730_960_605_000_000_000,
+	}
+}
+
+func TestConvertResourceSpansRejectsInvalidSpans(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(span *tracepb.Span)
+		want   string
+	}{
+		{"short trace id", func(s *tracepb.Span) { s.TraceId = s.TraceId[:8] }, "invalid trace id"},
+		{"zero trace id", func(s *tracepb.Span) { s.TraceId = make([]byte, 16) }, "invalid trace id"},
+		{"zero span id", func(s *tracepb.Span) { s.SpanId = make([]byte, 8) }, "invalid span id"},
+		{"short parent span id", func(s *tracepb.Span) { s.ParentSpanId = []byte{1} }, "invalid parent span id"},
+		{"missing name", func(s *tracepb.Span) { s.Name = "" }, "name is required"},
+		{"missing start time", func(s *tracepb.Span) { s.StartTimeUnixNano = 0 }, "start time is required"},
+		{"end before start", func(s *tracepb.Span) { s.EndTimeUnixNano = s.StartTimeUnixNano - 1 }, "end time is before start time"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			invalid := validSpan()
+			tt.modify(invalid)
+			request := []*tracepb.ResourceSpans{{ScopeSpans: []*tracepb.ScopeSpans{{Spans: []*tracepb.Span{validSpan(), invalid}}}}}
+
+			documents, rejected := ConvertResourceSpans(request)
+
+			if len(documents) != 1 {
+				t.Errorf("documents = %d, the valid span of the request must still be converted", len(documents))
+			}
+			if len(rejected) != 1 || !strings.Contains(rejected[0].Error(), tt.want) {
+				t.Errorf("rejected = %v, want %q", rejected, tt.want)
+			}
+		})
+	}
+}
+
+func TestConvertResourceSpansMergesResourceAttributes(t *testing.T) {
+	span := validSpan()
+	span.Attributes = []*commonpb.KeyValue{{Key: "resource.service.name", Value: stringValue("from-span")}}
+	request := []*tracepb.ResourceSpans{{
+		Resource: &resourcepb.Resource{Attributes: []*commonpb.KeyValue{
+			{Key: "service.name", Value: stringValue("agent")},
+			{Key: "deployment.environment", Value: stringValue("dev")},
+		}},
+		ScopeSpans: []*tracepb.ScopeSpans{{Scope: &commonpb.InstrumentationScope{Name: "openllmetry"}, Spans: []*tracepb.Span{span}}},
+	}}
+
+	documents, _ := ConvertResourceSpans(request)
+
+	source := documents[0].Source
+	attributes := source["attributes"].(map[string]interface{})
+	if attributes["resource.service.name"] != "from-span" || attributes["resource.deployment.environment"] != "dev" {
+		t.Errorf("attributes = %v, want resource attributes prefixed and span attributes taking precedence", attributes)
+	}
+	if source["traceId"] != "0102030405060708090a0b0c0d0e0f10" || source["durationInNanos"] != float64(1e9) {
+		t.Errorf("source = %v, want hex ids and the duration", source)
+	}
+	if scope := source["instrumentationScope"].(map[string]interface{}); scope["name"] != "openllmetry" {
+		t.Errorf("scope = %v", scope)
+	}
+}
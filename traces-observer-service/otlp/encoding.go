@@ -0,0 +1,151 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package otlp
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// Content types supported by the OTLP/HTTP receiver
+const (
+	ContentTypeProtobuf = "application/x-protobuf"
+	ContentTypeJSON     = "application/json"
+)
+
+// idFields are the OTLP/JSON fields encoded as hex instead of the protobuf JSON base64
+var idFields = map[string]bool{
+	"traceId":      true,
+	"spanId":       true,
+	"parentSpanId": true,
+}
+
+// ErrRequestTooLarge is returned when a decompressed request body exceeds the size limit
+var ErrRequestTooLarge = errors.New("request body too large")
+
+// ReadBody reads a request body of at most maxBytes, decompressing it when the content encoding is gzip
+// The limit applies to the decompressed body so that compressed payloads cannot bypass it
+func ReadBody(body io.Reader, contentEncoding string, maxBytes int) ([]byte, error) {
+	switch contentEncoding {
+	case "", "identity":
+	case "gzip":
+		reader, err := gzip.NewReader(body)
+		if err != nil {
+			return nil, fmt.Errorf("invalid gzip body: %w", err)
+		}
+		defer reader.Close()
+		body = reader
+	default:
+		return nil, fmt.Errorf("unsupported content encoding: %s", contentEncoding)
+	}
+
+	payload, err := io.ReadAll(io.LimitReader(body, int64(maxBytes)+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(payload) > maxBytes {
+		return nil, ErrRequestTooLarge
+	}
+	return payload, nil
+}
+
+// DecodeRequest decodes an export request encoded as protobuf or OTLP/JSON
+func DecodeRequest(body []byte, contentType string) (*coltracepb.ExportTraceServiceRequest, error) {
+	request := &coltracepb.ExportTraceServiceRequest{}
+
+	switch contentType {
+	case ContentTypeProtobuf:
+		if err := proto.Unmarshal(body, request); err != nil {
+			return nil, fmt.Errorf("invalid protobuf payload: %w", err)
+		}
+	case ContentTypeJSON:
+		converted, err := hexIDsToBase64(body)
+		if err != nil {
+			return nil, fmt.Errorf("invalid JSON payload: %w", err)
+		}
+		if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(converted, request); err != nil {
+			return nil, fmt.Errorf("invalid JSON payload: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported content type: %s", contentType)
+	}
+
+	return request, nil
+}
+
+// EncodeMessage encodes a response message in the content type of the request
+func EncodeMessage(message proto.Message, contentType string) ([]byte, error) {
+	if contentType == ContentTypeJSON {
+		return protojson.Marshal(message)
+	}
+	return proto.Marshal(message)
+}
+
+// hexIDsToBase64 rewrites the hex encoded ids of an OTLP/JSON payload into the base64 form expected by protojson
+func hexIDsToBase64(body []byte) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+
+	var payload interface{}
+	if err := decoder.Decode(&payload); err != nil {
+		return nil, err
+	}
+	if err := rewriteIDs(payload); err != nil {
+		return nil, err
+	}
+	return json.Marshal(payload)
+}
+
+// rewriteIDs walks a decoded JSON value and converts id fields in place
+func rewriteIDs(value interface{}) error {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			if id, ok := item.(string); ok && idFields[key] {
+				if id == "" {
+					continue
+				}
+				raw, err := hex.DecodeString(id)
+				if err != nil {
+					return fmt.Errorf("invalid %s %q: %w", key, id, err)
+				}
+				v[key] = base64.StdEncoding.EncodeToString(raw)
+				continue
+			}
+			if err := rewriteIDs(item); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		for _, item := range v {
+			if err := rewriteIDs(item); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
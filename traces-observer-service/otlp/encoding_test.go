@@ -0,0 +1,79 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package otlp
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func gzipped(t *testing.T, content string) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	if _, err := writer.Write([]byte(content)); err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return &buf
+}
+
+func TestReadBody(t *testing.T) {
+	if body, err := ReadBody(gzipped(t, "spans"), "gzip", 5); err != nil || string(body) != "spans" {
+		t.Errorf("gzip body = %q, %v", body, err)
+	}
+	// The limit applies to the decompressed body
+	if _, err := ReadBody(gzipped(t, strings.Repeat("a", 100)), "gzip", 50); !errors.Is(err, ErrRequestTooLarge) {
+		t.Errorf("error = %v, want ErrRequestTooLarge", err)
+	}
+	if _, err := ReadBody(strings.NewReader("spans"), "br", 50); err == nil {
+		t.Error("unsupported content encoding accepted")
+	}
+	if _, err := ReadBody(strings.NewReader("not gzip"), "gzip", 50); err == nil {
+		t.Error("invalid gzip body accepted")
+	}
+}
+
+func TestDecodeRequestReadsHexIDs(t *testing.T) {
+	body := `{"resourceSpans":[{"scopeSpans":[{"spans":[{"traceId":"4bf92f3577b34da6a3ce929d0e0e4736",` +
+		`"spanId":"00f067aa0ba902b7","parentSpanId":"","name":"chat","unknownField":1}]}]}]}`
+
+	request, err := DecodeRequest([]byte(body), ContentTypeJSON)
+	if err != nil {
+		t.Fatalf("DecodeRequest() error = %v", err)
+	}
+	span := request.GetResourceSpans()[0].GetScopeSpans()[0].GetSpans()[0]
+	if hex.EncodeToString(span.GetTraceId()) != "4bf92f3577b34da6a3ce929d0e0e4736" || hex.EncodeToString(span.GetSpanId()) != "00f067aa0ba902b7" {
+		t.Errorf("ids = %x, %x", span.GetTraceId(), span.GetSpanId())
+	}
+	if len(span.GetParentSpanId()) != 0 {
+		t.Errorf("parent span id = %x, want none for an empty id", span.GetParentSpanId())
+	}
+
+	if _, err := DecodeRequest([]byte(`{"resourceSpans":[{"scopeSpans":[{"spans":[{"spanId":"zz"}]}]}]}`), ContentTypeJSON); err == nil {
+		t.Error("invalid hex id accepted")
+	}
+	if _, err := DecodeRequest([]byte("garbage"), ContentTypeProtobuf); err == nil {
+		t.Error("invalid protobuf accepted")
+	}
+}
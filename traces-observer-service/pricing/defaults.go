@@ -0,0 +1,54 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package pricing
+
+func ptr(v float64) *float64 {
+	return &v
+}
+
+// defaultPrices holds list prices in USD per one million tokens
+var defaultPrices = map[string]ModelPrice{
+	// OpenAI
+	"gpt-4o":        {InputPerMillion: 2.50, OutputPerMillion: 10.00, CacheReadPerMillion: ptr(1.25)},
+	"gpt-4o-mini":   {InputPerMillion: 0.15, OutputPerMillion: 0.60, CacheReadPerMillion: ptr(0.075)},
+	"gpt-4.1":       {InputPerMillion: 2.00, OutputPerMillion: 8.00, CacheReadPerMillion: ptr(0.50)},
+	"gpt-4.1-mini":  {InputPerMillion: 0.40, OutputPerMillion: 1.60, CacheReadPerMillion: ptr(0.10)},
+	"gpt-4.1-nano":  {InputPerMillion: 0.10, OutputPerMillion: 0.40, CacheReadPerMillion: ptr(0.025)},
+	"gpt-4-turbo":   {InputPerMillion: 10.00, OutputPerMillion: 30.00},
+	"gpt-3.5-turbo": {InputPerMillion: 0.50, OutputPerMillion: 1.50},
+	"o1":            {InputPerMillion: 15.00, OutputPerMillion: 60.00, CacheReadPerMillion: ptr(7.50)},
+	"o1-mini":       {InputPerMillion: 1.10, OutputPerMillion: 4.40, CacheReadPerMillion: ptr(0.55)},
+	"o3":            {InputPerMillion: 2.00, OutputPerMillion: 8.00, CacheReadPerMillion: ptr(0.50)},
+	"o3-mini":       {InputPerMillion: 1.10, OutputPerMillion: 4.40, CacheReadPerMillion: ptr(0.55)},
+	"o4-mini":       {InputPerMillion: 1.10, OutputPerMillion: 4.40, CacheReadPerMillion: ptr(0.275)},
+
+	// Anthropic
+	"claude-3-5-sonnet": {InputPerMillion: 3.00, OutputPerMillion: 15.00, CacheReadMultiplier: ptr(0.1), CacheWriteMultiplier: ptr(1.25)},
+	"claude-3-7-sonnet": {InputPerMillion: 3.00, OutputPerMillion: 15.00, CacheReadMultiplier: ptr(0.1), CacheWriteMultiplier: ptr(1.25)},
+	"claude-sonnet-4":   {InputPerMillion: 3.00, OutputPerMillion: 15.00, CacheReadMultiplier: ptr(0.1), CacheWriteMultiplier: ptr(1.25)},
+	"claude-3-5-haiku":  {InputPerMillion: 0.80, OutputPerMillion: 4.00, CacheReadMultiplier: ptr(0.1), CacheWriteMultiplier: ptr(1.25)},
+	"claude-3-haiku":    {InputPerMillion: 0.25, OutputPerMillion: 1.25, CacheReadMultiplier: ptr(0.1), CacheWriteMultiplier: ptr(1.25)},
+	"claude-3-opus":     {InputPerMillion: 15.00, OutputPerMillion: 75.00, CacheReadMultiplier: ptr(0.1), CacheWriteMultiplier: ptr(1.25)},
+	"claude-opus-4":     {InputPerMillion: 15.00, OutputPerMillion: 75.00, CacheReadMultiplier: ptr(0.1), CacheWriteMultiplier: ptr(1.25)},
+
+	// Google Gemini
+	"gemini-1.5-pro":   {InputPerMillion: 1.25, OutputPerMillion: 5.00},
+	"gemini-1.5-flash": {InputPerMillion: 0.075, OutputPerMillion: 0.30},
+	"gemini-2.0-flash": {InputPerMillion: 0.10, OutputPerMillion: 0.40, CacheReadPerMillion: ptr(0.025)},
+	"gemini-2.5-pro":   {InputPerMillion: 1.25, OutputPerMillion: 10.00, CacheReadPerMillion: ptr(0.31)},
+	"gemini-2.5-flash": {InputPerMillion: 0.30, OutputPerMillion: 2.50, CacheReadPerMillion: ptr(0.075)},
+}
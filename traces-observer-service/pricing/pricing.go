@@ -0,0 +1,193 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package pricing
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ModelPrice holds the price of a model in USD per one million tokens
+// Cache-read tokens use CacheReadPerMillion when set, otherwise CacheReadMultiplier times the input price
+// Cache-write tokens use CacheWriteMultiplier times the input price
+type ModelPrice struct {
+	InputPerMillion      float64  `json:"inputPerMillion" yaml:"inputPerMillion"`
+	OutputPerMillion     float64  `json:"outputPerMillion" yaml:"outputPerMillion"`
+	CacheReadPerMillion  *float64 `json:"cacheReadPerMillion,omitempty" yaml:"cacheReadPerMillion,omitempty"`   // Discounted rate for cache-read input tokens
+	CacheReadMultiplier  *float64 `json:"cacheReadMultiplier,omitempty" yaml:"cacheReadMultiplier,omitempty"`   // e.g. 0.1 for Anthropic
+	CacheWriteMultiplier *float64 `json:"cacheWriteMultiplier,omitempty" yaml:"cacheWriteMultiplier,omitempty"` // e.g. 1.25 for Anthropic
+}
+
+// Usage holds the token counts to be priced
+// Cache-read and cache-write tokens are expected to be included in InputTokens
+type Usage struct {
+	InputTokens           int
+	OutputTokens          int
+	CacheReadInputTokens  int
+	CacheWriteInputTokens int
+}
+
+// Cost represents the dollar cost of token usage
+type Cost struct {
+	InputCost  float64 `json:"inputCost"`
+	OutputCost float64 `json:"outputCost"`
+	TotalCost  float64 `json:"totalCost"`
+}
+
+// Table maps model names to their prices
+// The prices can be replaced while the table is in use, every lookup sees either the old or the new prices
+type Table struct {
+	models atomic.Pointer[map[string]ModelPrice]
+}
+
+// tableFile is the on-disk format of a pricing table
+type tableFile struct {
+	Models map[string]ModelPrice `json:"models" yaml:"models"`
+}
+
+// NewTable creates a pricing table from the given model prices
+func NewTable(models map[string]ModelPrice) *Table {
+	prices := make(map[string]ModelPrice, len(models))
+	for name, price := range models {
+		prices[strings.ToLower(name)] = price
+	}
+	table := &Table{}
+	table.models.Store(&prices)
+	return table
+}
+
+// DefaultTable returns the built-in pricing table for common OpenAI, Anthropic and Gemini models
+func DefaultTable() *Table {
+	return NewTable(defaultPrices)
+}
+
+// LoadTable loads a pricing table from a JSON or YAML file and merges it over the built-in defaults
+func LoadTable(path string) (*Table, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pricing table: %w", err)
+	}
+
+	var file tableFile
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(content, &file)
+	default:
+		err = json.Unmarshal(content, &file)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse pricing table %s: %w", path, err)
+	}
+
+	models := make(map[string]ModelPrice, len(defaultPrices)+len(file.Models))
+	for name, price := range defaultPrices {
+		models[strings.ToLower(name)] = price
+	}
+	for name, price := range file.Models {
+		models[strings.ToLower(name)] = price
+	}
+	return NewTable(models), nil
+}
+
+// Replace swaps the prices of the table for those of other, lookups in progress keep the prices they started with
+func (t *Table) Replace(other *Table) {
+	t.models.Store(other.models.Load())
+}
+
+// Lookup finds the price of a model
+// Versioned model names (e.g. gpt-4o-2024-08-06) resolve to the longest matching table entry
+func (t *Table) Lookup(model string) (ModelPrice, bool) {
+	if t == nil || model == "" {
+		return ModelPrice{}, false
+	}
+	loaded := t.models.Load()
+	if loaded == nil {
+		return ModelPrice{}, false
+	}
+	models := *loaded
+	name := strings.ToLower(model)
+	// Drop provider prefixes such as "openai/gpt-4o"
+	if idx := strings.LastIndex(name, "/"); idx >= 0 {
+		name = name[idx+1:]
+	}
+
+	if price, ok := models[name]; ok {
+		return price, true
+	}
+
+	var match string
+	for candidate := range models {
+		if strings.HasPrefix(name, candidate) && len(candidate) > len(match) {
+			match = candidate
+		}
+	}
+	if match == "" {
+		return ModelPrice{}, false
+	}
+	return models[match], true
+}
+
+// Calculate computes the cost of token usage for a model
+// Returns nil when the model is not priced so that unpriced usage can be told apart from free usage
+func (t *Table) Calculate(model string, usage Usage) *Cost {
+	price, ok := t.Lookup(model)
+	if !ok {
+		return nil
+	}
+
+	// Cached tokens are part of the input tokens but may be billed at a different rate
+	uncached := usage.InputTokens
+	var cacheCost float64
+
+	if cacheReadRate, ok := price.cacheReadRate(); ok && usage.CacheReadInputTokens > 0 {
+		uncached -= usage.CacheReadInputTokens
+		cacheCost += float64(usage.CacheReadInputTokens) * cacheReadRate / 1_000_000
+	}
+	if price.CacheWriteMultiplier != nil && usage.CacheWriteInputTokens > 0 {
+		uncached -= usage.CacheWriteInputTokens
+		cacheCost += float64(usage.CacheWriteInputTokens) * price.InputPerMillion * (*price.CacheWriteMultiplier) / 1_000_000
+	}
+	if uncached < 0 {
+		uncached = 0
+	}
+
+	inputCost := float64(uncached)*price.InputPerMillion/1_000_000 + cacheCost
+	outputCost := float64(usage.OutputTokens) * price.OutputPerMillion / 1_000_000
+
+	return &Cost{
+		InputCost:  inputCost,
+		OutputCost: outputCost,
+		TotalCost:  inputCost + outputCost,
+	}
+}
+
+// cacheReadRate returns the per-million rate for cache-read tokens if the price defines one
+func (p ModelPrice) cacheReadRate() (float64, bool) {
+	if p.CacheReadPerMillion != nil {
+		return *p.CacheReadPerMillion, true
+	}
+	if p.CacheReadMultiplier != nil {
+		return p.InputPerMillion * (*p.CacheReadMultiplier), true
+	}
+	return 0, false
+}
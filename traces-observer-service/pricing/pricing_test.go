@@ -0,0 +1,212 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package pricing
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLookup(t *testing.T) {
+	table := NewTable(map[string]ModelPrice{
+		"gpt-4o":      {InputPerMillion: 2.5},
+		"gpt-4o-mini": {InputPerMillion: 0.15},
+		"Claude-3":    {InputPerMillion: 3},
+	})
+
+	tests := []struct {
+		model     string
+		wantInput float64
+		wantOK    bool
+	}{
+		{model: "gpt-4o", wantInput: 2.5, wantOK: true},
+		{model: "GPT-4o", wantInput: 2.5, wantOK: true},
+		{model: "claude-3", wantInput: 3, wantOK: true},
+		// Versioned names resolve to the longest matching entry
+		{model: "gpt-4o-2024-08-06", wantInput: 2.5, wantOK: true},
+		{model: "gpt-4o-mini-2024-07-18", wantInput: 0.15, wantOK: true},
+		// Provider prefixes are dropped
+		{model: "openai/gpt-4o-mini", wantInput: 0.15, wantOK: true},
+		{model: "llama-3"},
+		{model: "gpt-4"},
+		{model: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.model, func(t *testing.T) {
+			price, ok := table.Lookup(tt.model)
+			if ok != tt.wantOK || price.InputPerMillion != tt.wantInput {
+				t.Errorf("Lookup(%q) = %v, %v, want %v, %v", tt.model, price.InputPerMillion, ok, tt.wantInput, tt.wantOK)
+			}
+		})
+	}
+
+	var none *Table
+	if _, ok := none.Lookup("gpt-4o"); ok {
+		t.Error("Lookup() on a nil table found a price")
+	}
+}
+
+func TestCalculate(t *testing.T) {
+	table := NewTable(map[string]ModelPrice{
+		"plain":        {InputPerMillion: 2, OutputPerMillion: 8},
+		"cache-rate":   {InputPerMillion: 2, OutputPerMillion: 8, CacheReadPerMillion: ptr(0.5)},
+		"prompt-cache": {InputPerMillion: 3, OutputPerMillion: 15, CacheReadMultiplier: ptr(0.1), CacheWriteMultiplier: ptr(1.25)},
+		"both-rates":   {InputPerMillion: 3, CacheReadPerMillion: ptr(1), CacheReadMultiplier: ptr(0.1)},
+	})
+
+	tests := []struct {
+		name       string
+		model      string
+		usage      Usage
+		wantInput  float64
+		wantOutput float64
+	}{
+		{
+			name:       "input and output tokens",
+			model:      "plain",
+			usage:      Usage{InputTokens: 1_000_000, OutputTokens: 500_000},
+			wantInput:  2,
+			wantOutput: 4,
+		},
+		{
+			name:       "cache reads billed as input without a cache rate",
+			model:      "plain",
+			usage:      Usage{InputTokens: 1_000_000, CacheReadInputTokens: 400_000},
+			wantInput:  2,
+			wantOutput: 0,
+		},
+		{
+			name:      "cache reads at the cache rate",
+			model:     "cache-rate",
+			usage:     Usage{InputTokens: 1_000_000, CacheReadInputTokens: 400_000},
+			wantInput: 0.6*2 + 0.4*0.5,
+		},
+		{
+			name:      "cache reads exceeding the input tokens",
+			model:     "cache-rate",
+			usage:     Usage{InputTokens: 100_000, CacheReadInputTokens: 1_000_000},
+			wantInput: 0.5,
+		},
+		{
+			name:      "cache writes billed as input without a multiplier",
+			model:     "cache-rate",
+			usage:     Usage{InputTokens: 1_000_000, CacheWriteInputTokens: 1_000_000},
+			wantInput: 2,
+		},
+		{
+			name:       "cache reads and writes at the multipliers",
+			model:      "prompt-cache",
+			usage:      Usage{InputTokens: 1_000_000, OutputTokens: 100_000, CacheReadInputTokens: 500_000, CacheWriteInputTokens: 200_000},
+			wantInput:  0.3*3 + 0.5*3*0.1 + 0.2*3*1.25,
+			wantOutput: 1.5,
+		},
+		{
+			name:      "cache read rate wins over the multiplier",
+			model:     "both-rates",
+			usage:     Usage{InputTokens: 1_000_000, CacheReadInputTokens: 1_000_000},
+			wantInput: 1,
+		},
+		{
+			name:  "no usage",
+			model: "plain",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cost := table.Calculate(tt.model, tt.usage)
+			if cost == nil {
+				t.Fatalf("Calculate(%q) = nil", tt.model)
+			}
+			if !closeTo(cost.InputCost, tt.wantInput) || !closeTo(cost.OutputCost, tt.wantOutput) {
+				t.Errorf("cost = %+v, want input %v and output %v", cost, tt.wantInput, tt.wantOutput)
+			}
+			if !closeTo(cost.TotalCost, tt.wantInput+tt.wantOutput) {
+				t.Errorf("total cost = %v, want %v", cost.TotalCost, tt.wantInput+tt.wantOutput)
+			}
+		})
+	}
+
+	if cost := table.Calculate("unpriced", Usage{InputTokens: 10}); cost != nil {
+		t.Errorf("Calculate() of an unpriced model = %+v, want nil", cost)
+	}
+}
+
+func TestLoadTableMergesOverDefaults(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+	}{
+		{
+			name:    "yaml",
+			file:    "pricing.yaml",
+			content: "models:\n  gpt-4o:\n    inputPerMillion: 1\n    outputPerMillion: 2\n  custom-model:\n    inputPerMillion: 5\n    outputPerMillion: 6\n",
+		},
+		{
+			name:    "json",
+			file:    "pricing.json",
+			content: `{"models": {"GPT-4o": {"inputPerMillion": 1, "outputPerMillion": 2}, "custom-model": {"inputPerMillion": 5, "outputPerMillion": 6}}}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.file)
+			if err := os.WriteFile(path, []byte(tt.content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			table, err := LoadTable(path)
+			if err != nil {
+				t.Fatalf("LoadTable() error = %v", err)
+			}
+			if price, _ := table.Lookup("gpt-4o"); price.InputPerMillion != 1 {
+				t.Errorf("overridden gpt-4o input price = %v, want 1", price.InputPerMillion)
+			}
+			if price, _ := table.Lookup("custom-model"); price.OutputPerMillion != 6 {
+				t.Errorf("custom-model output price = %v, want 6", price.OutputPerMillion)
+			}
+			if _, ok := table.Lookup("gemini-2.5-pro"); !ok {
+				t.Error("default gemini-2.5-pro price missing")
+			}
+		})
+	}
+
+	path := filepath.Join(t.TempDir(), "pricing.json")
+	if err := os.WriteFile(path, []byte("{"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadTable(path); err == nil {
+		t.Error("LoadTable() of an invalid file succeeded")
+	}
+	if _, err := LoadTable(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Error("LoadTable() of a missing file succeeded")
+	}
+}
+
+func TestReplace(t *testing.T) {
+	table := NewTable(map[string]ModelPrice{"model": {InputPerMillion: 1}})
+	table.Replace(NewTable(map[string]ModelPrice{"model": {InputPerMillion: 2}}))
+	if price, _ := table.Lookup("model"); price.InputPerMillion != 2 {
+		t.Errorf("input price after Replace() = %v, want 2", price.InputPerMillion)
+	}
+}
+
+// closeTo reports whether two costs are equal up to rounding errors
+func closeTo(got, want float64) bool {
+	return math.Abs(got-want) < 1e-9
+}
@@ -0,0 +1,182 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package querycache
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Cache keeps the encoded results of expensive queries for a short time so that dashboards
+// refreshing the same panels are answered from memory. Entries are bounded by count and total
+// size and evicted least recently used first. Concurrent requests for a key share a single query,
+// failed queries are not cached
+type Cache struct {
+	maxEntries int
+	maxBytes   int64
+	now        func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]*entry
+	lru     *list.List // Loaded entries, most recently used first
+	bytes   int64
+	stats   Stats
+}
+
+type entry struct {
+	key     string
+	value   []byte
+	err     error // Set for failed queries, which are dropped once loaded
+	expires time.Time
+	ready   chan struct{} // Closed once the query finished
+	element *list.Element // Nil while loading
+}
+
+// Stats holds the counters of the cache
+type Stats struct {
+	Entries   int   `json:"entries"`
+	Bytes     int64 `json:"bytes"`
+	Hits      int64 `json:"hits"`
+	Misses    int64 `json:"misses"`
+	Shared    int64 `json:"shared"` // Requests that waited for the query of a concurrent identical request
+	Evictions int64 `json:"evictions"`
+}
+
+// New creates a cache holding at most maxEntries results of at most maxBytes in total
+func New(maxEntries int, maxBytes int64) *Cache {
+	return &Cache{
+		maxEntries: maxEntries,
+		maxBytes:   maxBytes,
+		now:        time.Now,
+		entries:    map[string]*entry{},
+		lru:        list.New(),
+	}
+}
+
+// Key derives a cache key from the name of a query and its normalized parameters,
+// which are encoded as JSON so that equal parameters always produce the same key
+func Key(name string, parts ...interface{}) (string, error) {
+	hash := sha256.New()
+	hash.Write([]byte(name))
+	for _, part := range parts {
+		encoded, err := json.Marshal(part)
+		if err != nil {
+			return "", fmt.Errorf("failed to encode cache key: %w", err)
+		}
+		hash.Write([]byte{0})
+		hash.Write(encoded)
+	}
+	return name + ":" + hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// Get returns the result cached for key, running load when it is missing or older than ttl.
+// hit reports whether the result was served without running load for this request
+func (c *Cache) Get(ctx context.Context, key string, ttl time.Duration, load func(ctx context.Context) ([]byte, error)) (value []byte, hit bool, err error) {
+	c.mu.Lock()
+	e, ok := c.entries[key]
+	if ok && e.element != nil && !c.now().Before(e.expires) {
+		c.remove(e)
+		ok = false
+	}
+	if !ok {
+		e = &entry{key: key, ready: make(chan struct{})}
+		c.entries[key] = e
+		c.stats.Misses++
+		c.mu.Unlock()
+		c.fill(ctx, e, ttl, load)
+		return e.value, false, e.err
+	}
+	if e.element != nil {
+		c.stats.Hits++
+		c.lru.MoveToFront(e.element)
+		c.mu.Unlock()
+		return e.value, true, nil
+	}
+	c.stats.Shared++
+	c.mu.Unlock()
+
+	select {
+	case <-e.ready:
+		return e.value, true, e.err
+	case <-ctx.Done():
+		return nil, false, ctx.Err()
+	}
+}
+
+// fill runs the query of a missing entry, the query outlives the request that triggered it
+// so that the requests waiting for it are served
+func (c *Cache) fill(ctx context.Context, e *entry, ttl time.Duration, load func(ctx context.Context) ([]byte, error)) {
+	value, err := load(context.WithoutCancel(ctx))
+
+	c.mu.Lock()
+	e.value = value
+	e.err = err
+	if err != nil || int64(len(value)) > c.maxBytes {
+		delete(c.entries, e.key)
+	} else {
+		e.expires = c.now().Add(ttl)
+		e.element = c.lru.PushFront(e)
+		c.bytes += int64(len(value))
+		c.evict()
+	}
+	c.mu.Unlock()
+	close(e.ready)
+}
+
+// evict drops the least recently used entries until the cache is within its bounds, the caller holds the lock
+func (c *Cache) evict() {
+	for c.lru.Len() > c.maxEntries || c.bytes > c.maxBytes {
+		c.remove(c.lru.Back().Value.(*entry))
+		c.stats.Evictions++
+	}
+}
+
+// remove drops a loaded entry, the caller holds the lock
+func (c *Cache) remove(e *entry) {
+	c.lru.Remove(e.element)
+	c.bytes -= int64(len(e.value))
+	delete(c.entries, e.key)
+}
+
+// Stats returns the counters of the cache
+func (c *Cache) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	stats := c.stats
+	stats.Entries = c.lru.Len()
+	stats.Bytes = c.bytes
+	return stats
+}
+
+type bypassKey struct{}
+
+// WithBypass marks ctx to skip the cache, its queries always run and their results are not cached
+func WithBypass(ctx context.Context) context.Context {
+	return context.WithValue(ctx, bypassKey{}, true)
+}
+
+// Bypassed reports whether ctx skips the cache
+func Bypassed(ctx context.Context) bool {
+	bypass, _ := ctx.Value(bypassKey{}).(bool)
+	return bypass
+}
@@ -0,0 +1,168 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package querycache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestGetCachesUntilExpiry(t *testing.T) {
+	cache := New(10, 1024)
+	now := time.Unix(0, 0)
+	cache.now = func() time.Time { return now }
+
+	var loads atomic.Int32
+	load := func(context.Context) ([]byte, error) {
+		loads.Add(1)
+		return []byte("result"), nil
+	}
+	ctx := context.Background()
+
+	if _, hit, err := cache.Get(ctx, "k", 15*time.Second, load); err != nil || hit {
+		t.Fatalf("first get: hit=%v err=%v, want a miss", hit, err)
+	}
+	now = now.Add(14 * time.Second)
+	value, hit, err := cache.Get(ctx, "k", 15*time.Second, load)
+	if err != nil || !hit || string(value) != "result" {
+		t.Fatalf("second get: value=%q hit=%v err=%v, want a cached result", value, hit, err)
+	}
+	now = now.Add(time.Second)
+	if _, hit, _ := cache.Get(ctx, "k", 15*time.Second, load); hit {
+		t.Fatal("get after the ttl was served from the cache")
+	}
+	if got := loads.Load(); got != 2 {
+		t.Errorf("loads = %d, want 2", got)
+	}
+	stats := cache.Stats()
+	if stats.Hits != 1 || stats.Misses != 2 || stats.Entries != 1 || stats.Bytes != 6 {
+		t.Errorf("stats = %+v", stats)
+	}
+}
+
+func TestGetSharesConcurrentLoads(t *testing.T) {
+	cache := New(10, 1024)
+	release := make(chan struct{})
+	var loads atomic.Int32
+	load := func(context.Context) ([]byte, error) {
+		loads.Add(1)
+		<-release
+		return []byte("result"), nil
+	}
+
+	const requests = 8
+	var wg sync.WaitGroup
+	results := make(chan string, requests)
+	for i := 0; i < requests; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			value, _, err := cache.Get(context.Background(), "k", time.Minute, load)
+			if err != nil {
+				t.Error(err)
+			}
+			results <- string(value)
+		}()
+	}
+	for cache.Stats().Misses+cache.Stats().Shared < requests {
+		time.Sleep(time.Millisecond)
+	}
+	close(release)
+	wg.Wait()
+	close(results)
+
+	if got := loads.Load(); got != 1 {
+		t.Errorf("loads = %d, want 1", got)
+	}
+	for value := range results {
+		if value != "result" {
+			t.Errorf("value = %q, want result", value)
+		}
+	}
+}
+
+func TestGetDoesNotCacheFailures(t *testing.T) {
+	cache := New(10, 1024)
+	failure := errors.New("search failed")
+	if _, _, err := cache.Get(context.Background(), "k", time.Minute, func(context.Context) ([]byte, error) {
+		return nil, failure
+	}); !errors.Is(err, failure) {
+		t.Fatalf("err = %v, want the load error", err)
+	}
+	value, hit, err := cache.Get(context.Background(), "k", time.Minute, func(context.Context) ([]byte, error) {
+		return []byte("result"), nil
+	})
+	if err != nil || hit || string(value) != "result" {
+		t.Errorf("value=%q hit=%v err=%v, want a fresh result", value, hit, err)
+	}
+}
+
+func TestEvictionBoundsEntriesAndBytes(t *testing.T) {
+	cache := New(2, 10)
+	ctx := context.Background()
+	put := func(key, value string) {
+		_, _, _ = cache.Get(ctx, key, time.Minute, func(context.Context) ([]byte, error) { return []byte(value), nil })
+	}
+	cached := func(key string) bool {
+		_, hit, _ := cache.Get(ctx, key, time.Minute, func(context.Context) ([]byte, error) { return []byte("x"), nil })
+		return hit
+	}
+
+	put("a", "aaaa")
+	put("b", "bbbb")
+	cached("a") // a becomes the most recently used entry
+	put("c", "cccc")
+	if stats := cache.Stats(); stats.Entries != 2 || stats.Evictions != 1 {
+		t.Fatalf("stats = %+v, want 2 entries after 1 eviction", stats)
+	}
+	if !cached("a") || !cached("c") {
+		t.Error("recently used entries were evicted")
+	}
+
+	put("d", "dddddddd")
+	if stats := cache.Stats(); stats.Bytes > 10 {
+		t.Errorf("bytes = %d, want at most 10", stats.Bytes)
+	}
+	put("e", "this result is too large")
+	if cached("e") {
+		t.Error("result larger than the cache was cached")
+	}
+}
+
+func TestKeyIsStableForEqualParams(t *testing.T) {
+	type params struct {
+		Interval string
+		Start    time.Time
+	}
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	a, err := Key("metrics", "org-a", params{Interval: "1h", Start: start})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := Key("metrics", "org-a", params{Interval: "1h", Start: start})
+	other, _ := Key("metrics", "org-b", params{Interval: "1h", Start: start})
+	if a != b {
+		t.Errorf("keys of equal params differ: %s, %s", a, b)
+	}
+	if a == other {
+		t.Error("keys of different organizations are equal")
+	}
+}
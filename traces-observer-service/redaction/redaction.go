@@ -0,0 +1,170 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package redaction
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Redactor removes sensitive data from a value
+// Implementations return the redacted value and the number of redactions made
+type Redactor interface {
+	Redact(value string) (string, int)
+}
+
+// Rule is a named regular expression whose matches are redacted
+type Rule struct {
+	Name    string `json:"name" yaml:"name"`
+	Pattern string `json:"pattern" yaml:"pattern"`
+}
+
+// DefaultRules are the built-in redaction rules
+// Credit cards are matched before phone numbers so that card numbers are labelled correctly
+// Phone numbers require separators so that epoch timestamps and IDs are not redacted
+var DefaultRules = []Rule{
+	{Name: "email", Pattern: `[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`},
+	{Name: "bearer_token", Pattern: `(?i)bearer\s+[A-Za-z0-9\-._~+/]+=*`},
+	{Name: "api_key", Pattern: `\b(?:sk|pk|rk)-[A-Za-z0-9_-]{16,}\b|\bAKIA[0-9A-Z]{16}\b`},
+	{Name: "credit_card", Pattern: `\b(?:\d{4}[ -]?){3}\d{4}\b|\b3[47]\d{2}[ -]?\d{6}[ -]?\d{5}\b`},
+	{Name: "phone", Pattern: `(?:\+\d{1,3}[\s.-]?)?\(?\b\d{3}\)?[\s.-]\d{3}[\s.-]\d{4}\b`},
+}
+
+// MarkerPrefix starts the value written in place of every redacted match
+const MarkerPrefix = "[REDACTED:"
+
+// compiledRule is a rule with its compiled pattern
+type compiledRule struct {
+	name        string
+	regex       *regexp.Regexp
+	replacement string
+}
+
+// RegexRedactor replaces matches of its rules with "[REDACTED:<rule>]"
+type RegexRedactor struct {
+	rules []compiledRule
+}
+
+// rulesFile is the on-disk format of custom redaction rules
+type rulesFile struct {
+	Rules []Rule `json:"rules" yaml:"rules"`
+}
+
+// NewRegexRedactor compiles the given rules into a redactor
+func NewRegexRedactor(rules []Rule) (*RegexRedactor, error) {
+	redactor := &RegexRedactor{rules: make([]compiledRule, 0, len(rules))}
+	for _, rule := range rules {
+		if rule.Name == "" {
+			return nil, fmt.Errorf("redaction rule name is required")
+		}
+		regex, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern for redaction rule %s: %w", rule.Name, err)
+		}
+		redactor.rules = append(redactor.rules, compiledRule{
+			name:        rule.Name,
+			regex:       regex,
+			replacement: MarkerPrefix + rule.Name + "]",
+		})
+	}
+	return redactor, nil
+}
+
+// DefaultRedactor returns a redactor with the built-in rules
+func DefaultRedactor() *RegexRedactor {
+	redactor, err := NewRegexRedactor(DefaultRules)
+	if err != nil {
+		panic(err)
+	}
+	return redactor
+}
+
+// LoadRules loads redaction rules from a JSON or YAML file and appends them to the built-in rules
+// A rule with the same name as a built-in rule replaces it
+func LoadRules(path string) ([]Rule, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read redaction rules: %w", err)
+	}
+
+	var file rulesFile
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(content, &file)
+	default:
+		err = json.Unmarshal(content, &file)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse redaction rules %s: %w", path, err)
+	}
+
+	rules := make([]Rule, 0, len(DefaultRules)+len(file.Rules))
+	overridden := make(map[string]Rule, len(file.Rules))
+	for _, rule := range file.Rules {
+		overridden[rule.Name] = rule
+	}
+	for _, rule := range DefaultRules {
+		if custom, ok := overridden[rule.Name]; ok {
+			rules = append(rules, custom)
+			delete(overridden, rule.Name)
+			continue
+		}
+		rules = append(rules, rule)
+	}
+	for _, rule := range file.Rules {
+		if _, ok := overridden[rule.Name]; ok {
+			rules = append(rules, rule)
+		}
+	}
+	return rules, nil
+}
+
+// Redact replaces every rule match in the value
+func (r *RegexRedactor) Redact(value string) (string, int) {
+	if r == nil || value == "" {
+		return value, 0
+	}
+
+	count := 0
+	for _, rule := range r.rules {
+		value = rule.regex.ReplaceAllStringFunc(value, func(string) string {
+			count++
+			return rule.replacement
+		})
+	}
+	return value, count
+}
+
+// Chain runs several redactors in order
+type Chain []Redactor
+
+// Redact applies each redactor in the chain to the value
+func (c Chain) Redact(value string) (string, int) {
+	total := 0
+	for _, redactor := range c {
+		var count int
+		value, count = redactor.Redact(value)
+		total += count
+	}
+	return value, total
+}
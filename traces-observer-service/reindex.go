@@ -0,0 +1,78 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/config"
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/opensearch"
+)
+
+// traceMapping returns the mapping of the trace indices configured for OpenSearch
+func traceMapping(cfg *config.Config) opensearch.TraceMapping {
+	return opensearch.TraceMapping{
+		Dynamic:          cfg.OpenSearch.MappingDynamic,
+		TotalFieldsLimit: cfg.OpenSearch.MappingTotalFieldsLimit,
+		CustomFields:     cfg.OpenSearch.FieldMappings,
+	}
+}
+
+// runReindex rebuilds the named trace indices with the configured mapping and returns the exit code
+// Usage: traces-observer-service reindex [-timeout 1h] <index>...
+func runReindex(cfg *config.Config, args []string) int {
+	flags := flag.NewFlagSet("reindex", flag.ContinueOnError)
+	timeout := flags.Duration("timeout", time.Hour, "deadline of copying a single index")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: traces-observer-service reindex [-timeout 1h] <index>...")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return 2
+	}
+
+	if err := opensearch.SetTraceMapping(traceMapping(cfg)); err != nil {
+		slog.Error("Invalid trace index mapping", "error", err)
+		return 1
+	}
+	// Copying an index takes a single long request
+	openSearchConfig := cfg.OpenSearch
+	openSearchConfig.RequestTimeout = *timeout
+	osClient, err := opensearch.NewClient(&openSearchConfig, nil)
+	if err != nil {
+		slog.Error("Failed to create OpenSearch client", "error", err)
+		return 1
+	}
+
+	for _, index := range flags.Args() {
+		result, err := osClient.ReindexTraceIndex(context.Background(), index, time.Now())
+		if err != nil {
+			slog.Error("Failed to reindex trace index", "index", index, "error", err)
+			return 1
+		}
+		slog.Info("Reindexed trace index", "index", result.Index, "documents", result.Documents)
+	}
+	return 0
+}
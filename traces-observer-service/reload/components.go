@@ -0,0 +1,123 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package reload
+
+import (
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/config"
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/middleware"
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/pricing"
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/redaction"
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/sampling"
+)
+
+// RuleSetter is the part of the tail sampler whose rules can be replaced, implemented by sampling.TailSampler
+type RuleSetter interface {
+	SetRules(rules sampling.Rules)
+}
+
+// pricingComponent replaces the prices of the table shared by the service
+type pricingComponent struct {
+	table *pricing.Table
+}
+
+// Pricing reloads the pricing table from PRICING_TABLE_PATH into table
+func Pricing(table *pricing.Table) Component {
+	return pricingComponent{table: table}
+}
+
+func (c pricingComponent) Name() string { return "pricing" }
+
+func (c pricingComponent) Prepare(cfg *config.Config) (func(), error) {
+	next := pricing.DefaultTable()
+	if cfg.Pricing.TablePath != "" {
+		var err error
+		if next, err = pricing.LoadTable(cfg.Pricing.TablePath); err != nil {
+			return nil, err
+		}
+	}
+	return func() { c.table.Replace(next) }, nil
+}
+
+// redactionComponent replaces the redactor applied to ingested spans
+type redactionComponent struct {
+	set func(redaction.Redactor)
+}
+
+// Redaction reloads the redaction rules from REDACTION_RULES_PATH and passes the redactor to set,
+// nil when redaction is disabled
+func Redaction(set func(redaction.Redactor)) Component {
+	return redactionComponent{set: set}
+}
+
+func (c redactionComponent) Name() string { return "redaction" }
+
+func (c redactionComponent) Prepare(cfg *config.Config) (func(), error) {
+	if !cfg.Redaction.Enabled {
+		return func() { c.set(nil) }, nil
+	}
+	rules := redaction.DefaultRules
+	if cfg.Redaction.RulesPath != "" {
+		var err error
+		if rules, err = redaction.LoadRules(cfg.Redaction.RulesPath); err != nil {
+			return nil, err
+		}
+	}
+	redactor, err := redaction.NewRegexRedactor(rules)
+	if err != nil {
+		return nil, err
+	}
+	return func() { c.set(redactor) }, nil
+}
+
+// corsComponent replaces the origins allowed to call the API
+type corsComponent struct {
+	origins *middleware.Origins
+}
+
+// CORS reloads the origins of CORS_ALLOWED_ORIGINS into origins
+func CORS(origins *middleware.Origins) Component {
+	return corsComponent{origins: origins}
+}
+
+func (c corsComponent) Name() string { return "cors" }
+
+func (c corsComponent) Prepare(cfg *config.Config) (func(), error) {
+	// The origins were validated with the configuration
+	allowed := cfg.CORS.AllowedOrigins
+	return func() { c.origins.Set(allowed) }, nil
+}
+
+// samplingComponent replaces the rules of the tail sampler
+type samplingComponent struct {
+	sampler RuleSetter
+}
+
+// Sampling reloads the duration, token and percentage rules of the tail sampler
+func Sampling(sampler RuleSetter) Component {
+	return samplingComponent{sampler: sampler}
+}
+
+func (c samplingComponent) Name() string { return "sampling" }
+
+func (c samplingComponent) Prepare(cfg *config.Config) (func(), error) {
+	rules := sampling.Rules{
+		DurationThreshold: cfg.Sampling.DurationThreshold,
+		TokenThreshold:    cfg.Sampling.TokenThreshold,
+		SamplePercentage:  cfg.Sampling.SamplePercentage,
+	}
+	return func() { c.sampler.SetRules(rules) }, nil
+}
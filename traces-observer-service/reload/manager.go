@@ -0,0 +1,190 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package reload
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/config"
+)
+
+// Component is a part of the service whose settings can change without a restart
+type Component interface {
+	Name() string
+	// Prepare builds the state of the component for cfg without applying it, apply swaps it in
+	// An error rejects the whole configuration, no component is changed then
+	Prepare(cfg *config.Config) (apply func(), err error)
+}
+
+// Result reports the outcome of a reload
+type Result struct {
+	Time    time.Time `json:"time"`
+	Reason  string    `json:"reason"`
+	Applied bool      `json:"applied"`
+	// Errors of the rejected configuration, the previous settings stay active
+	Errors []string `json:"errors,omitempty"`
+	// Settings changed that only take effect on restart
+	RestartRequired bool `json:"restartRequired"`
+}
+
+// Stats holds the counters of the manager
+type Stats struct {
+	Reloads  uint64  `json:"reloads"`
+	Rejected uint64  `json:"rejected"`
+	Last     *Result `json:"last,omitempty"`
+}
+
+// Manager applies changed settings to the components of the service without a restart
+// A new configuration is loaded and prepared by every component before any of them is changed,
+// so that an invalid configuration keeps the previous one active
+type Manager struct {
+	load       func() (*config.Config, error)
+	components []Component
+
+	// Reloads are serialized so that components are never swapped by two reloads at once
+	mu      sync.Mutex
+	current *config.Config
+	stats   Stats
+}
+
+// NewManager creates a manager for the components configured from current, load reads the configuration again
+func NewManager(current *config.Config, load func() (*config.Config, error), components ...Component) *Manager {
+	return &Manager{
+		load:       load,
+		components: components,
+		current:    current,
+	}
+}
+
+// Reload loads the configuration again and applies it when every component accepts it
+func (m *Manager) Reload(reason string) Result {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	result := Result{Time: time.Now().UTC(), Reason: reason}
+	cfg, err := m.load()
+	if err != nil {
+		result.Errors = []string{err.Error()}
+		return m.reject(result)
+	}
+
+	applies := make([]func(), 0, len(m.components))
+	for _, component := range m.components {
+		apply, err := component.Prepare(cfg)
+		if err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", component.Name(), err))
+			continue
+		}
+		applies = append(applies, apply)
+	}
+	if len(result.Errors) > 0 {
+		return m.reject(result)
+	}
+
+	for _, apply := range applies {
+		apply()
+	}
+	result.Applied = true
+	result.RestartRequired = !reflect.DeepEqual(withoutReloadable(*m.current), withoutReloadable(*cfg))
+	m.current = cfg
+	m.stats.Reloads++
+	m.stats.Last = &result
+
+	slog.Warn("Configuration reloaded", "reason", reason, "restartRequired", result.RestartRequired)
+	if result.RestartRequired {
+		slog.Warn("Configuration changes outside pricing, redaction, CORS and sampling rules take effect on restart")
+	}
+	return result
+}
+
+// reject records a configuration that was not applied
+func (m *Manager) reject(result Result) Result {
+	m.stats.Rejected++
+	m.stats.Last = &result
+	slog.Error("Rejected configuration reload, the previous configuration stays active", "reason", result.Reason, "errors", result.Errors)
+	return result
+}
+
+// Stats returns the counters of the manager
+func (m *Manager) Stats() Stats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.stats
+}
+
+// Watch reloads the configuration whenever the config file, pricing table or redaction rules change,
+// checking every interval until ctx is done
+func (m *Manager) Watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	seen := m.fileVersions()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if current := m.fileVersions(); !reflect.DeepEqual(current, seen) {
+				m.Reload("file changed")
+				// A reload can change the watched paths
+				seen = m.fileVersions()
+			}
+		}
+	}
+}
+
+// fileVersion identifies the content of a watched file, missing files have the zero version
+type fileVersion struct {
+	modTime time.Time
+	size    int64
+}
+
+// fileVersions returns the versions of the files the current configuration is read from
+func (m *Manager) fileVersions() map[string]fileVersion {
+	m.mu.Lock()
+	paths := []string{m.current.File, m.current.Pricing.TablePath, m.current.Redaction.RulesPath}
+	m.mu.Unlock()
+
+	versions := make(map[string]fileVersion, len(paths))
+	for _, path := range paths {
+		if path == "" {
+			continue
+		}
+		var version fileVersion
+		if info, err := os.Stat(path); err == nil {
+			version = fileVersion{modTime: info.ModTime(), size: info.Size()}
+		}
+		versions[path] = version
+	}
+	return versions
+}
+
+// withoutReloadable clears the settings components apply, what remains needs a restart to change
+func withoutReloadable(cfg config.Config) config.Config {
+	cfg.Pricing = config.PricingConfig{}
+	cfg.Redaction = config.RedactionConfig{}
+	cfg.CORS = config.CORSConfig{}
+	cfg.Sampling.DurationThreshold = 0
+	cfg.Sampling.TokenThreshold = 0
+	cfg.Sampling.SamplePercentage = 0
+	return cfg
+}
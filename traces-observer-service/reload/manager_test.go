@@ -0,0 +1,260 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package reload
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/config"
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/middleware"
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/pricing"
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/redaction"
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/sampling"
+)
+
+// recordingSampler keeps the last rules it was given
+type recordingSampler struct {
+	mu    sync.Mutex
+	rules sampling.Rules
+}
+
+func (s *recordingSampler) SetRules(rules sampling.Rules) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rules = rules
+}
+
+// testService holds the reloadable components of a service configured from a config file
+type testService struct {
+	dir       string
+	file      string
+	manager   *Manager
+	table     *pricing.Table
+	origins   *middleware.Origins
+	sampler   *recordingSampler
+	redactor  func() redaction.Redactor
+	setConfig func(content string)
+}
+
+func newTestService(t *testing.T, content string) *testService {
+	t.Helper()
+	dir := t.TempDir()
+	svc := &testService{dir: dir, file: filepath.Join(dir, "observer.env")}
+	svc.setConfig = func(content string) {
+		t.Helper()
+		if err := os.WriteFile(svc.file, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	svc.setConfig(content)
+	t.Setenv("CONFIG_FILE", svc.file)
+	t.Setenv("OPENSEARCH_USERNAME", "admin")
+	t.Setenv("OPENSEARCH_PASSWORD", "admin")
+
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("initial config: %v", err)
+	}
+	svc.table = pricing.DefaultTable()
+	svc.origins = middleware.NewOrigins(cfg.CORS.AllowedOrigins)
+	svc.sampler = &recordingSampler{}
+	var mu sync.Mutex
+	var current redaction.Redactor
+	svc.redactor = func() redaction.Redactor {
+		mu.Lock()
+		defer mu.Unlock()
+		return current
+	}
+	svc.manager = NewManager(cfg, config.Load,
+		Pricing(svc.table),
+		Redaction(func(r redaction.Redactor) {
+			mu.Lock()
+			defer mu.Unlock()
+			current = r
+		}),
+		CORS(svc.origins),
+		Sampling(svc.sampler),
+	)
+	return svc
+}
+
+func (s *testService) writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(s.dir, name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestReloadAppliesValidConfig(t *testing.T) {
+	svc := newTestService(t, "CORS_ALLOWED_ORIGINS=https://console.example.com\n")
+	pricingPath := svc.writeFile(t, "pricing.json", `{"models":{"acme-1":{"inputPerMillion":1,"outputPerMillion":2}}}`)
+	rulesPath := svc.writeFile(t, "rules.json", `{"rules":[{"name":"ticket","pattern":"TICKET-[0-9]+"}]}`)
+	svc.setConfig(strings.Join([]string{
+		"# Edited while running",
+		"CORS_ALLOWED_ORIGINS=https://console.example.com,https://admin.example.com",
+		"PRICING_TABLE_PATH=" + pricingPath,
+		"REDACTION_RULES_PATH=" + rulesPath,
+		"SAMPLING_PERCENTAGE=25",
+		"SAMPLING_TOKEN_THRESHOLD=1000",
+	}, "\n"))
+
+	result := svc.manager.Reload("test")
+	if !result.Applied || len(result.Errors) > 0 {
+		t.Fatalf("reload = %+v, want it applied", result)
+	}
+	if result.RestartRequired {
+		t.Error("only reloadable settings changed, no restart should be required")
+	}
+	if got := svc.origins.AllowOrigin("https://admin.example.com"); got != "https://admin.example.com" {
+		t.Errorf("new origin not allowed, got %q", got)
+	}
+	if _, ok := svc.table.Lookup("acme-1"); !ok {
+		t.Error("model of the new pricing table not priced")
+	}
+	if _, ok := svc.table.Lookup("gpt-4o"); !ok {
+		t.Error("built-in prices lost by the reload")
+	}
+	if redacted, n := svc.redactor().Redact("see TICKET-42"); n != 1 || redacted != "see [REDACTED:ticket]" {
+		t.Errorf("redaction = %q (%d), want the new rule applied", redacted, n)
+	}
+	if want := (sampling.Rules{DurationThreshold: 30 * time.Second, TokenThreshold: 1000, SamplePercentage: 25}); svc.sampler.rules != want {
+		t.Errorf("sampling rules = %+v, want %+v", svc.sampler.rules, want)
+	}
+	if stats := svc.manager.Stats(); stats.Reloads != 1 || stats.Rejected != 0 {
+		t.Errorf("stats = %+v", stats)
+	}
+}
+
+func TestReloadRejectsInvalidConfig(t *testing.T) {
+	svc := newTestService(t, "CORS_ALLOWED_ORIGINS=https://console.example.com\n")
+	brokenPricing := svc.writeFile(t, "pricing.json", `{"models":`)
+	brokenRules := svc.writeFile(t, "rules.json", `{"rules":[{"name":"ticket","pattern":"TICKET-[0-9"}]}`)
+
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+	}{
+		{
+			name:    "unparsable value",
+			content: "CORS_ALLOWED_ORIGINS=https://admin.example.com\nSAMPLING_PERCENTAGE=ten\n",
+			want:    []string{"SAMPLING_PERCENTAGE"},
+		},
+		{
+			name:    "invalid origin",
+			content: "CORS_ALLOWED_ORIGINS=admin.example.com\n",
+			want:    []string{"invalid CORS allowed origin"},
+		},
+		{
+			name:    "broken pricing table and redaction rules",
+			content: "CORS_ALLOWED_ORIGINS=https://admin.example.com\nPRICING_TABLE_PATH=" + brokenPricing + "\nREDACTION_RULES_PATH=" + brokenRules + "\n",
+			want:    []string{"pricing:", "redaction:"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc.setConfig(tt.content)
+			result := svc.manager.Reload("test")
+			if result.Applied {
+				t.Fatal("invalid configuration applied")
+			}
+			errors := strings.Join(result.Errors, "\n")
+			for _, want := range tt.want {
+				if !strings.Contains(errors, want) {
+					t.Errorf("errors %q do not mention %q", errors, want)
+				}
+			}
+			// No component is changed by a rejected configuration
+			if got := svc.origins.AllowOrigin("https://admin.example.com"); got != "" {
+				t.Errorf("origin of the rejected configuration allowed")
+			}
+			if got := svc.origins.AllowOrigin("https://console.example.com"); got != "https://console.example.com" {
+				t.Errorf("origin of the active configuration no longer allowed")
+			}
+			if svc.redactor() != nil {
+				t.Errorf("redactor of the rejected configuration applied")
+			}
+		})
+	}
+}
+
+func TestReloadIsRaceFree(t *testing.T) {
+	svc := newTestService(t, "CORS_ALLOWED_ORIGINS=https://a.example.com\n")
+	pricingPath := svc.writeFile(t, "pricing.json", `{"models":{"acme-1":{"inputPerMillion":1,"outputPerMillion":2}}}`)
+
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				// Requests see either configuration, never a mix within one lookup
+				if origin := svc.origins.AllowOrigin("https://a.example.com"); origin == "" {
+					if svc.origins.AllowOrigin("https://b.example.com") == "" && svc.origins.AllowOrigin("https://a.example.com") == "" {
+						t.Error("no configuration active")
+						return
+					}
+				}
+				svc.table.Calculate("acme-1-mini", pricing.Usage{InputTokens: 10, OutputTokens: 10})
+			}
+		}()
+	}
+	for i := 0; i < 50; i++ {
+		if i%2 == 0 {
+			svc.setConfig("CORS_ALLOWED_ORIGINS=https://b.example.com\nPRICING_TABLE_PATH=" + pricingPath + "\n")
+		} else {
+			svc.setConfig("CORS_ALLOWED_ORIGINS=https://a.example.com\n")
+		}
+		if result := svc.manager.Reload("test"); !result.Applied {
+			t.Fatalf("reload %d rejected: %v", i, result.Errors)
+		}
+	}
+	close(stop)
+	wg.Wait()
+}
+
+func TestWatchReloadsChangedFiles(t *testing.T) {
+	svc := newTestService(t, "CORS_ALLOWED_ORIGINS=https://a.example.com\n")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go svc.manager.Watch(ctx, 10*time.Millisecond)
+
+	// The modification time alone may not change within the resolution of the file system
+	time.Sleep(20 * time.Millisecond)
+	svc.setConfig("CORS_ALLOWED_ORIGINS=https://b.example.com,https://c.example.com\n")
+	deadline := time.Now().Add(5 * time.Second)
+	for svc.origins.AllowOrigin("https://b.example.com") == "" {
+		if time.Now().After(deadline) {
+			t.Fatal("changed config file not reloaded")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
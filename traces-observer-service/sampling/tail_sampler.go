@@ -0,0 +1,501 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package sampling
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/metrics"
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/opensearch"
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/pricing"
+)
+
+// Config holds the tail sampling policy
+type Config struct {
+	DecisionWait      time.Duration // Time spans of a trace are buffered before the keep/drop decision
+	DurationThreshold time.Duration // Traces lasting longer are kept, disabled when zero
+	TokenThreshold    int           // Traces using more tokens are kept, disabled when zero
+	SamplePercentage  float64       // Percentage of the remaining traces kept at random
+	MaxBufferedBytes  int           // Memory cap of the buffer, the oldest traces are decided early when exceeded
+	DecisionCacheSize int           // Number of recent decisions remembered for late spans, with the spans of dropped traces
+}
+
+// Rules decide which traces are kept, they can be replaced while the sampler runs
+type Rules struct {
+	DurationThreshold time.Duration // Traces lasting longer are kept, disabled when zero
+	TokenThreshold    int           // Traces using more tokens are kept, disabled when zero
+	SamplePercentage  float64       // Percentage of the remaining traces kept at random
+}
+
+// Indexer receives the documents of kept traces and rollups of dropped traces
+type Indexer interface {
+	Add(ctx context.Context, doc opensearch.BulkDocument) error
+}
+
+// Stats holds the counters of the tail sampler
+type Stats struct {
+	BufferedTraces int    `json:"bufferedTraces"`
+	BufferedBytes  int    `json:"bufferedBytes"`
+	KeptTraces     uint64 `json:"keptTraces"`
+	DroppedTraces  uint64 `json:"droppedTraces"`
+	EvictedTraces  uint64 `json:"evictedTraces"` // Traces decided early because of the memory cap
+}
+
+// bufferedSpan is a processed span waiting for the decision of its trace
+type bufferedSpan struct {
+	span     opensearch.Span
+	document opensearch.BulkDocument
+	size     int
+}
+
+// traceBuffer holds the spans of one trace
+type traceBuffer struct {
+	firstSeen time.Time
+	spans     []bufferedSpan
+	spanIndex map[string]int // Position of each span in spans, re-delivered spans replace their earlier copy
+	size      int
+}
+
+// decision is the remembered outcome of a decided trace
+// Dropped traces keep lightweight copies of their spans so that late spans recompute the rollup instead of adding to it
+type decision struct {
+	kept        bool
+	rollupIndex string
+	spans       map[string]opensearch.Span
+}
+
+// decidedTrace is a trace removed from the buffer together with its decision
+type decidedTrace struct {
+	buffer      *traceBuffer
+	spans       []opensearch.Span
+	summary     traceSummary
+	kept        bool
+	rollupIndex string
+}
+
+// TailSampler buffers spans per trace and indexes whole traces that match the sampling policy
+// Dropped traces are replaced by a rollup document so that aggregate metrics stay accurate
+type TailSampler struct {
+	cfg          Config
+	rules        atomic.Pointer[Rules] // Replaces the rules of cfg
+	indexer      Indexer
+	pricingTable *pricing.Table
+	metrics      *metrics.Metrics
+
+	mu            sync.Mutex
+	traces        map[string]*traceBuffer
+	order         []string // Trace IDs in arrival order, used for eviction
+	bufferedBytes int
+	decisions     map[string]*decision // Recent decisions
+	decisionOrder []string
+
+	kept    atomic.Uint64
+	dropped atomic.Uint64
+	evicted atomic.Uint64
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewTailSampler creates a tail sampler and starts its decision loop
+func NewTailSampler(cfg Config, indexer Indexer, pricingTable *pricing.Table, m *metrics.Metrics) *TailSampler {
+	if cfg.DecisionWait <= 0 {
+		cfg.DecisionWait = 10 * time.Second
+	}
+	if cfg.DecisionCacheSize <= 0 {
+		cfg.DecisionCacheSize = 10000
+	}
+
+	sampler := &TailSampler{
+		cfg:          cfg,
+		indexer:      indexer,
+		pricingTable: pricingTable,
+		metrics:      m,
+		traces:       make(map[string]*traceBuffer),
+		decisions:    make(map[string]*decision),
+		stop:         make(chan struct{}),
+		done:         make(chan struct{}),
+	}
+	sampler.SetRules(Rules{
+		DurationThreshold: cfg.DurationThreshold,
+		TokenThreshold:    cfg.TokenThreshold,
+		SamplePercentage:  cfg.SamplePercentage,
+	})
+	m.ObserveQueueDepth("sampler_traces", func() int {
+		sampler.mu.Lock()
+		defer sampler.mu.Unlock()
+		return len(sampler.traces)
+	})
+
+	go sampler.run()
+	return sampler
+}
+
+// Add buffers a processed span until its trace is decided
+// Spans arriving after the decision of their trace follow that decision
+func (s *TailSampler) Add(ctx context.Context, span opensearch.Span, doc opensearch.BulkDocument) error {
+	size := documentSize(doc)
+
+	s.mu.Lock()
+	if decided, ok := s.decisions[span.TraceID]; ok {
+		if decided.kept {
+			s.mu.Unlock()
+			return s.indexer.Add(ctx, doc)
+		}
+		// Late spans of dropped traces are not indexed, the rollup of their trace is rewritten with them
+		decided.spans[span.SpanID] = opensearch.LightweightSpan(span)
+		spans := make([]opensearch.Span, 0, len(decided.spans))
+		for _, decidedSpan := range decided.spans {
+			spans = append(spans, decidedSpan)
+		}
+		rollupIndex := decided.rollupIndex
+		s.mu.Unlock()
+
+		s.metrics.SpansDropped(metrics.DropReasonSampledOut, 1)
+		if doc.OnDone != nil {
+			doc.OnDone(nil)
+		}
+		return s.indexer.Add(ctx, opensearch.BulkDocument{
+			Index: rollupIndex,
+			ID:    span.TraceID,
+			Body:  s.buildRollup(span.TraceID, spans, summarize(spans)),
+		})
+	}
+
+	trace, ok := s.traces[span.TraceID]
+	if !ok {
+		trace = &traceBuffer{firstSeen: time.Now(), spanIndex: make(map[string]int)}
+		s.traces[span.TraceID] = trace
+		s.order = append(s.order, span.TraceID)
+	}
+	if i, ok := trace.spanIndex[span.SpanID]; ok {
+		// A re-delivered span replaces its earlier copy, which is acknowledged as it is never indexed
+		previous := trace.spans[i]
+		trace.spans[i] = bufferedSpan{span: span, document: doc, size: size}
+		trace.size += size - previous.size
+		s.bufferedBytes += size - previous.size
+		if previous.document.OnDone != nil {
+			defer previous.document.OnDone(nil)
+		}
+	} else {
+		trace.spanIndex[span.SpanID] = len(trace.spans)
+		trace.spans = append(trace.spans, bufferedSpan{span: span, document: doc, size: size})
+		trace.size += size
+		s.bufferedBytes += size
+	}
+
+	// Decide the oldest traces early when the buffer exceeds its memory cap
+	var evicted map[string]*decidedTrace
+	for s.cfg.MaxBufferedBytes > 0 && s.bufferedBytes > s.cfg.MaxBufferedBytes && len(s.order) > 0 {
+		traceID := s.order[0]
+		s.order = s.order[1:]
+		buffer, ok := s.traces[traceID]
+		if !ok {
+			continue
+		}
+		if evicted == nil {
+			evicted = make(map[string]*decidedTrace)
+		}
+		evicted[traceID] = s.removeTrace(traceID, buffer)
+		s.evicted.Add(1)
+	}
+	s.mu.Unlock()
+
+	return s.decideAll(ctx, evicted)
+}
+
+// Close decides every buffered trace and stops the decision loop
+func (s *TailSampler) Close(ctx context.Context) error {
+	close(s.stop)
+	<-s.done
+
+	s.mu.Lock()
+	remaining := make(map[string]*decidedTrace, len(s.traces))
+	for traceID, buffer := range s.traces {
+		remaining[traceID] = s.removeTrace(traceID, buffer)
+	}
+	s.order = nil
+	s.mu.Unlock()
+
+	return s.decideAll(ctx, remaining)
+}
+
+// Stats returns the sampler counters
+func (s *TailSampler) Stats() Stats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return Stats{
+		BufferedTraces: len(s.traces),
+		BufferedBytes:  s.bufferedBytes,
+		KeptTraces:     s.kept.Load(),
+		DroppedTraces:  s.dropped.Load(),
+		EvictedTraces:  s.evicted.Load(),
+	}
+}
+
+// run decides traces whose decision wait has elapsed
+func (s *TailSampler) run() {
+	defer close(s.done)
+
+	interval := s.cfg.DecisionWait / 4
+	if interval < 100*time.Millisecond {
+		interval = 100 * time.Millisecond
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := s.decideExpired(context.Background()); err != nil {
+				slog.Error("Failed to index sampled traces", "error", err)
+			}
+		case <-s.stop:
+			return
+		}
+	}
+}
+
+// decideExpired decides the traces buffered for longer than the decision wait
+func (s *TailSampler) decideExpired(ctx context.Context) error {
+	deadline := time.Now().Add(-s.cfg.DecisionWait)
+
+	s.mu.Lock()
+	expired := make(map[string]*decidedTrace)
+	for len(s.order) > 0 {
+		traceID := s.order[0]
+		buffer, ok := s.traces[traceID]
+		if ok && buffer.firstSeen.After(deadline) {
+			break
+		}
+		s.order = s.order[1:]
+		if ok {
+			expired[traceID] = s.removeTrace(traceID, buffer)
+		}
+	}
+	s.mu.Unlock()
+
+	return s.decideAll(ctx, expired)
+}
+
+// removeTrace removes a trace from the buffer and decides it, must be called with the lock held
+// The decision is recorded before the lock is released so that late spans follow it instead of starting a new buffer
+func (s *TailSampler) removeTrace(traceID string, buffer *traceBuffer) *decidedTrace {
+	delete(s.traces, traceID)
+	s.bufferedBytes -= buffer.size
+
+	spans := make([]opensearch.Span, len(buffer.spans))
+	for i, buffered := range buffer.spans {
+		spans[i] = buffered.span
+	}
+	summary := summarize(spans)
+	decided := &decidedTrace{
+		buffer:      buffer,
+		spans:       spans,
+		summary:     summary,
+		kept:        s.shouldKeep(traceID, summary),
+		rollupIndex: opensearch.TraceRollupIndexName(summary.startTime),
+	}
+	s.remember(traceID, decided.kept, decided.rollupIndex, spans)
+	return decided
+}
+
+// decideAll indexes the spans or rollups of a set of decided traces
+func (s *TailSampler) decideAll(ctx context.Context, traces map[string]*decidedTrace) error {
+	var firstErr error
+	for traceID, decided := range traces {
+		if err := s.decide(ctx, traceID, decided); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// decide indexes the spans of a kept trace or the rollup of a dropped trace
+func (s *TailSampler) decide(ctx context.Context, traceID string, decided *decidedTrace) error {
+	buffer := decided.buffer
+	if decided.kept {
+		s.kept.Add(1)
+		for _, buffered := range buffer.spans {
+			if err := s.indexer.Add(ctx, buffered.document); err != nil {
+				return fmt.Errorf("failed to index span of sampled trace %s: %w", traceID, err)
+			}
+		}
+		return nil
+	}
+
+	s.dropped.Add(1)
+	s.metrics.SpansDropped(metrics.DropReasonSampledOut, len(buffer.spans))
+	for _, buffered := range buffer.spans {
+		if buffered.document.OnDone != nil {
+			buffered.document.OnDone(nil)
+		}
+	}
+	return s.indexer.Add(ctx, opensearch.BulkDocument{
+		Index: decided.rollupIndex,
+		ID:    traceID,
+		Body:  s.buildRollup(traceID, decided.spans, decided.summary),
+	})
+}
+
+// SetRules replaces the rules deciding traces, traces being decided keep the rules they started with
+func (s *TailSampler) SetRules(rules Rules) {
+	s.rules.Store(&rules)
+}
+
+// Rules returns the rules deciding traces
+func (s *TailSampler) Rules() Rules {
+	return *s.rules.Load()
+}
+
+// shouldKeep reports whether a trace matches any keep rule of the policy
+func (s *TailSampler) shouldKeep(traceID string, summary traceSummary) bool {
+	if summary.hasError {
+		return true
+	}
+	rules := s.rules.Load()
+	if rules.DurationThreshold > 0 && summary.duration >= rules.DurationThreshold {
+		return true
+	}
+	if rules.TokenThreshold > 0 && summary.totalTokens >= rules.TokenThreshold {
+		return true
+	}
+	return sampledAtRandom(traceID, rules.SamplePercentage)
+}
+
+// remember records a decision for late spans, forgetting the oldest decisions beyond the cache size
+// It must be called with the lock held
+func (s *TailSampler) remember(traceID string, keep bool, rollupIndex string, spans []opensearch.Span) {
+	decided := &decision{kept: keep}
+	if !keep {
+		decided.rollupIndex = rollupIndex
+		decided.spans = make(map[string]opensearch.Span, len(spans))
+		for _, span := range spans {
+			decided.spans[span.SpanID] = opensearch.LightweightSpan(span)
+		}
+	}
+
+	if _, ok := s.decisions[traceID]; !ok {
+		s.decisionOrder = append(s.decisionOrder, traceID)
+	}
+	s.decisions[traceID] = decided
+
+	for len(s.decisionOrder) > s.cfg.DecisionCacheSize {
+		delete(s.decisions, s.decisionOrder[0])
+		s.decisionOrder = s.decisionOrder[1:]
+	}
+}
+
+// traceSummary holds the values the sampling policy is evaluated on
+type traceSummary struct {
+	startTime   time.Time
+	endTime     time.Time
+	duration    time.Duration
+	hasError    bool
+	totalTokens int
+	usage       *opensearch.TraceTokenUsage
+}
+
+// summarize computes the duration, error status and token usage of the buffered spans of a trace
+func summarize(spans []opensearch.Span) traceSummary {
+	summary := traceSummary{}
+	for _, span := range spans {
+		if summary.startTime.IsZero() || span.StartTime.Before(summary.startTime) {
+			summary.startTime = span.StartTime
+		}
+		if span.EndTime.After(summary.endTime) {
+			summary.endTime = span.EndTime
+		}
+		if span.AmpAttributes != nil && span.AmpAttributes.Status != nil && span.AmpAttributes.Status.Error {
+			summary.hasError = true
+		}
+	}
+	if !summary.startTime.IsZero() && summary.endTime.After(summary.startTime) {
+		summary.duration = summary.endTime.Sub(summary.startTime)
+	}
+
+	summary.usage = opensearch.AggregateTraceTokenUsage(spans)
+	if summary.usage != nil {
+		summary.totalTokens = summary.usage.TotalTokens
+	}
+	return summary
+}
+
+// buildRollup builds the aggregate document written in place of a dropped trace
+func (s *TailSampler) buildRollup(traceID string, spans []opensearch.Span, summary traceSummary) map[string]interface{} {
+	rollup := map[string]interface{}{
+		"traceId":         traceID,
+		"startTime":       summary.startTime.Format(time.RFC3339Nano),
+		"endTime":         summary.endTime.Format(time.RFC3339Nano),
+		"durationInNanos": summary.duration.Nanoseconds(),
+		"spanCount":       len(spans),
+		"sampled":         false,
+	}
+
+	// Copy the organization, component and environment of the trace so rollups can be filtered like spans
+	for _, span := range spans {
+		if span.OrgID != "" {
+			rollup[opensearch.OrgIDField] = span.OrgID
+			break
+		}
+	}
+	for _, span := range spans {
+		if span.Resource != nil {
+			rollup["resource"] = map[string]interface{}{
+				"openchoreo.dev/component-uid":   span.Resource["openchoreo.dev/component-uid"],
+				"openchoreo.dev/environment-uid": span.Resource["openchoreo.dev/environment-uid"],
+			}
+			break
+		}
+	}
+
+	if summary.usage != nil {
+		summary.usage.ApplyPricing(s.pricingTable)
+		rollup["tokenUsage"] = summary.usage
+	}
+	return rollup
+}
+
+// sampledAtRandom deterministically selects a percentage of traces by hashing the trace ID
+// so that every replica makes the same decision for a trace
+func sampledAtRandom(traceID string, percentage float64) bool {
+	if percentage <= 0 {
+		return false
+	}
+	if percentage >= 100 {
+		return true
+	}
+	hash := fnv.New64a()
+	hash.Write([]byte(traceID))
+	return float64(hash.Sum64()%10000) < percentage*100
+}
+
+// documentSize estimates the memory held by a buffered document from its encoded size
+func documentSize(doc opensearch.BulkDocument) int {
+	encoded, err := json.Marshal(doc.Body)
+	if err != nil {
+		return 0
+	}
+	return len(encoded)
+}
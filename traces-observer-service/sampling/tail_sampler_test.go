@@ -0,0 +1,197 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package sampling
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/wso2/ai-agent-management-platform/traces-observer-service/opensearch"
+)
+
+var traceStart = time.Date(2025, 11, 3, 10, 0, 0, 0, time.UTC)
+
+// recordingIndexer records the documents handed to the indexer in order
+type recordingIndexer struct {
+	mu   sync.Mutex
+	docs []opensearch.BulkDocument
+}
+
+func (r *recordingIndexer) Add(_ context.Context, doc opensearch.BulkDocument) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.docs = append(r.docs, doc)
+	return nil
+}
+
+// ids returns the IDs of the recorded documents
+func (r *recordingIndexer) ids() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	ids := make([]string, 0, len(r.docs))
+	for _, doc := range r.docs {
+		ids = append(ids, doc.ID)
+	}
+	return ids
+}
+
+// spanDocument returns a span and its document, every document has the same encoded size
+func spanDocument(traceID, spanID string) (opensearch.Span, opensearch.BulkDocument) {
+	span := opensearch.Span{
+		TraceID:   traceID,
+		SpanID:    spanID,
+		StartTime: traceStart,
+		EndTime:   traceStart.Add(time.Second),
+	}
+	return span, opensearch.BulkDocument{
+		Index: "otel-traces-2025-11-03",
+		ID:    spanID,
+		Body:  map[string]string{"traceId": traceID, "spanId": spanID},
+	}
+}
+
+// newTestSampler creates a tail sampler that only decides traces on eviction or close
+func newTestSampler(t *testing.T, cfg Config) (*TailSampler, *recordingIndexer) {
+	t.Helper()
+	cfg.DecisionWait = time.Hour
+	indexer := &recordingIndexer{}
+	sampler := NewTailSampler(cfg, indexer, nil, nil)
+	t.Cleanup(func() { _ = sampler.Close(context.Background()) })
+	return sampler, indexer
+}
+
+func addSpan(t *testing.T, sampler *TailSampler, traceID, spanID string) {
+	t.Helper()
+	span, doc := spanDocument(traceID, spanID)
+	if err := sampler.Add(context.Background(), span, doc); err != nil {
+		t.Fatalf("Add(%s) error = %v", spanID, err)
+	}
+}
+
+func TestTailSamplerEvictsOldestTracesBeyondMemoryCap(t *testing.T) {
+	_, doc := spanDocument("a", "a1")
+	size := documentSize(doc)
+	// Two spans fit in the buffer, a third one exceeds its memory cap
+	sampler, indexer := newTestSampler(t, Config{SamplePercentage: 100, MaxBufferedBytes: 2*size + size/2})
+
+	addSpan(t, sampler, "a", "a1")
+	addSpan(t, sampler, "b", "b1")
+	if ids := indexer.ids(); len(ids) != 0 {
+		t.Fatalf("indexed = %v, want traces buffered below the memory cap", ids)
+	}
+
+	addSpan(t, sampler, "c", "c1")
+	addSpan(t, sampler, "d", "d1")
+
+	if ids := indexer.ids(); !reflect.DeepEqual(ids, []string{"a1", "b1"}) {
+		t.Errorf("indexed = %v, want the oldest traces decided first", ids)
+	}
+	stats := sampler.Stats()
+	if stats.BufferedTraces != 2 || stats.BufferedBytes != 2*size || stats.EvictedTraces != 2 || stats.KeptTraces != 2 {
+		t.Errorf("stats = %+v, want 2 traces evicted and 2 buffered", stats)
+	}
+}
+
+func TestTailSamplerEvictsWholeTraces(t *testing.T) {
+	_, doc := spanDocument("a", "a1")
+	size := documentSize(doc)
+	sampler, indexer := newTestSampler(t, Config{SamplePercentage: 100, MaxBufferedBytes: 3*size + size/2})
+
+	addSpan(t, sampler, "a", "a1")
+	addSpan(t, sampler, "b", "b1")
+	addSpan(t, sampler, "a", "a2")
+	// Re-delivered spans replace their earlier copy and do not grow the buffer
+	addSpan(t, sampler, "a", "a2")
+	if ids := indexer.ids(); len(ids) != 0 {
+		t.Fatalf("indexed = %v, want nothing evicted below the memory cap", ids)
+	}
+
+	addSpan(t, sampler, "c", "c1")
+
+	if ids := indexer.ids(); !reflect.DeepEqual(ids, []string{"a1", "a2"}) {
+		t.Errorf("indexed = %v, want every span of the oldest trace", ids)
+	}
+	if stats := sampler.Stats(); stats.BufferedTraces != 2 || stats.BufferedBytes != 2*size {
+		t.Errorf("stats = %+v, want traces b and c buffered", stats)
+	}
+}
+
+func TestTailSamplerLateSpansFollowDecision(t *testing.T) {
+	tests := []struct {
+		name       string
+		percentage float64
+		wantIDs    []string
+		wantSpans  int
+	}{
+		{name: "kept trace", percentage: 100, wantIDs: []string{"a1", "a2"}},
+		{name: "dropped trace", percentage: 0, wantIDs: []string{"a", "a"}, wantSpans: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sampler, indexer := newTestSampler(t, Config{SamplePercentage: tt.percentage, MaxBufferedBytes: 1})
+
+			// The memory cap decides the trace as soon as its first span arrives
+			addSpan(t, sampler, "a", "a1")
+			addSpan(t, sampler, "a", "a2")
+
+			if ids := indexer.ids(); !reflect.DeepEqual(ids, tt.wantIDs) {
+				t.Errorf("indexed = %v, want %v", ids, tt.wantIDs)
+			}
+			if tt.wantSpans > 0 {
+				rollup := indexer.docs[len(indexer.docs)-1].Body.(map[string]interface{})
+				if rollup["spanCount"] != tt.wantSpans {
+					t.Errorf("rollup spanCount = %v, want %d", rollup["spanCount"], tt.wantSpans)
+				}
+			}
+			stats := sampler.Stats()
+			if stats.BufferedTraces != 0 || stats.KeptTraces+stats.DroppedTraces != 1 {
+				t.Errorf("stats = %+v, want the late span to follow the decision without a new buffer", stats)
+			}
+		})
+	}
+}
+
+func TestTailSamplerDecidesTracesOnceUnderConcurrentSpans(t *testing.T) {
+	sampler, indexer := newTestSampler(t, Config{SamplePercentage: 100, MaxBufferedBytes: 1})
+
+	const traces, spansPerTrace = 50, 10
+	var wg sync.WaitGroup
+	for i := 0; i < traces; i++ {
+		for j := 0; j < spansPerTrace; j++ {
+			wg.Add(1)
+			go func(traceID, spanID string) {
+				defer wg.Done()
+				span, doc := spanDocument(traceID, spanID)
+				if err := sampler.Add(context.Background(), span, doc); err != nil {
+					t.Errorf("Add(%s) error = %v", spanID, err)
+				}
+			}(fmt.Sprintf("trace-%d", i), fmt.Sprintf("span-%d-%d", i, j))
+		}
+	}
+	wg.Wait()
+
+	if stats := sampler.Stats(); stats.KeptTraces != traces || stats.BufferedTraces != 0 {
+		t.Errorf("stats = %+v, want every trace decided once", stats)
+	}
+	if ids := indexer.ids(); len(ids) != traces*spansPerTrace {
+		t.Errorf("indexed %d spans, want %d", len(ids), traces*spansPerTrace)
+	}
+}
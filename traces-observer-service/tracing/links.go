@@ -0,0 +1,63 @@
+// Copyright (c) 2025, WSO2 LLC. (https://www.wso2.com).
+//
+// WSO2 LLC. licenses this file to you under the Apache License,
+// Version 2.0 (the "License"); you may not use this file except
+// in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package tracing
+
+import (
+	"go.opentelemetry.io/otel/trace"
+)
+
+// MaxLinks bounds the links of a pipeline span, the default link limit of the SDK
+const MaxLinks = 128
+
+// Links collects links from a pipeline span to the distinct traces of the spans it processed,
+// so that the ingestion of an agent trace can be found from that trace
+type Links struct {
+	seen  map[trace.TraceID]struct{}
+	links []trace.Link
+}
+
+// Add links the span with the given hex IDs unless its trace is linked already, invalid IDs are ignored
+func (l *Links) Add(traceID, spanID string) {
+	if len(l.links) >= MaxLinks {
+		return
+	}
+	tid, err := trace.TraceIDFromHex(traceID)
+	if err != nil {
+		return
+	}
+	sid, err := trace.SpanIDFromHex(spanID)
+	if err != nil {
+		return
+	}
+	if _, ok := l.seen[tid]; ok {
+		return
+	}
+	if l.seen == nil {
+		l.seen = make(map[trace.TraceID]struct{})
+	}
+	l.seen[tid] = struct{}{}
+	l.links = append(l.links, trace.Link{SpanContext: trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: tid,
+		SpanID:  sid,
+		Remote:  true,
+	})})
+}
+
+// Links returns the collected links
+func (l *Links) Links() []trace.Link {
+	return l.links
+}